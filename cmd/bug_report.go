@@ -0,0 +1,332 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/state"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/version"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+const (
+	// bugReportMaxLogFiles is the number of the most recent log files included in a bug report.
+	bugReportMaxLogFiles = 5
+	// bugReportMaxLogSize is the number of bytes included from the end of each log file.
+	bugReportMaxLogSize = 1 << 20
+)
+
+const bugReportReadme = `This archive was created by 'pulumi bug-report' to help diagnose a problem.  It contains:
+
+    environment.json         the CLI version, platform, backend, and Pulumi-related environment variables
+    plugins.json             the installed plugins
+    logs/                    the most recent Pulumi log files
+    stack/deployment.json    the stack's current deployment, with secret and sensitive values redacted
+    stack/last-update.json   a summary of the stack's most recent update
+    errors.txt               any problems encountered while gathering the above
+
+Detailed logs are only written when Pulumi is run with verbose logging, e.g. 'pulumi up -v=9'.  Reproduce the
+problem that way before creating a bug report to include them.  Please review the contents before sharing them.
+`
+
+func newBugReportCmd() *cobra.Command {
+	var out string
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "bug-report",
+		Short: "Gather diagnostic information into an archive to attach to an issue",
+		Long: "Gather diagnostic information into an archive to attach to an issue.\n" +
+			"\n" +
+			"This command collects the CLI's version and environment, the installed plugins, recent\n" +
+			"log files, and the current stack's redacted deployment and most recent update into a\n" +
+			"single zip file.  Secret configuration values and properties whose names suggest that\n" +
+			"they are sensitive are redacted, but please review the archive before sharing it.\n" +
+			"\n" +
+			"Logs are most useful when the problem is first reproduced with verbose logging enabled,\n" +
+			"e.g. `pulumi up -v=9`.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if out == "" {
+				out = fmt.Sprintf("pulumi-bug-report-%s.zip", time.Now().Format("20060102-150405"))
+			}
+
+			f, err := os.Create(out)
+			if err != nil {
+				return errors.Wrap(err, "creating bug report")
+			}
+			defer contract.IgnoreClose(f)
+
+			r := &bugReport{zip: zip.NewWriter(f)}
+			r.add("README.txt", []byte(bugReportReadme))
+			r.gather(stackName)
+			if err = r.close(); err != nil {
+				return errors.Wrap(err, "writing bug report")
+			}
+
+			fmt.Printf("Wrote bug report to %s\n", out)
+			if len(r.errs) > 0 {
+				fmt.Printf("Some information could not be gathered; see errors.txt in the report for details.\n")
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&out, "out", "o", "", "The file to write the report to. Defaults to a timestamped file in this directory")
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to report on. Defaults to the current stack")
+
+	return cmd
+}
+
+// bugReport accumulates the files of a bug report.  Problems gathering information are recorded rather than returned,
+// so that as much as possible is reported.
+type bugReport struct {
+	zip  *zip.Writer
+	errs []string
+	err  error // the first error writing the archive itself.
+}
+
+// bugReportEnvironment describes the environment in which the CLI is running.
+type bugReportEnvironment struct {
+	Version    string            `json:"version"`
+	GoVersion  string            `json:"goVersion"`
+	OS         string            `json:"os"`
+	Arch       string            `json:"arch"`
+	Backend    string            `json:"backend,omitempty"`
+	Project    string            `json:"project,omitempty"`
+	Runtime    string            `json:"runtime,omitempty"`
+	Stack      string            `json:"stack,omitempty"`
+	LogDir     string            `json:"logDir"`
+	Variables  map[string]string `json:"variables,omitempty"`
+	Executable string            `json:"executable,omitempty"`
+}
+
+// gather adds every section of the report.
+func (r *bugReport) gather(stackName string) {
+	env := bugReportEnvironment{
+		Version:   version.Version,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		LogDir:    logging.LogDir(),
+		Variables: bugReportVariables(os.Environ()),
+	}
+	if exe, err := os.Executable(); err == nil {
+		env.Executable = exe
+	}
+	if creds, err := workspace.GetStoredCredentials(); err != nil {
+		r.fail("reading credentials", err)
+	} else {
+		env.Backend = creds.Current
+	}
+	if proj, err := workspace.DetectProject(); err == nil {
+		env.Project, env.Runtime = proj.Name.String(), proj.RuntimeInfo.Name()
+	}
+
+	s, err := bugReportStack(stackName)
+	if err != nil {
+		r.fail("loading stack", err)
+	} else if s != nil {
+		env.Stack = s.Name().String()
+	}
+
+	r.addJSON("environment.json", env)
+	r.addPlugins()
+	r.addLogs()
+	if s != nil {
+		r.addStack(s)
+	}
+}
+
+// bugReportStack returns the indicated stack, or the current stack if none is indicated.  Unlike requireStack, it
+// never prompts, and returns nil if there is no current stack.
+func bugReportStack(stackName string) (backend.Stack, error) {
+	if stackName != "" {
+		return requireStack(stackName, false, backend.DisplayOptions{}, false /*setCurrent*/)
+	}
+	b, err := currentBackend(backend.DisplayOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return state.CurrentStack(commandContext(), b)
+}
+
+// bugReportVariables returns the Pulumi-related environment variables, with the values of those that may hold
+// credentials redacted.
+func bugReportVariables(environ []string) map[string]string {
+	vars := make(map[string]string)
+	for _, kv := range environ {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], "PULUMI_") {
+			continue
+		}
+		name, value := parts[0], parts[1]
+		lower := strings.ToLower(strings.Replace(name, "_", "", -1))
+		for _, fragment := range stack.DefaultRedactedNames {
+			if strings.Contains(lower, fragment) {
+				value = stack.RedactedValue
+				break
+			}
+		}
+		vars[name] = value
+	}
+	return vars
+}
+
+func (r *bugReport) addPlugins() {
+	plugins, err := workspace.GetPlugins()
+	if err != nil {
+		r.fail("loading plugins", err)
+		return
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].String() < plugins[j].String() })
+	r.addJSON("plugins.json", plugins)
+}
+
+func (r *bugReport) addLogs() {
+	dir := logging.LogDir()
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		r.fail("listing log files", err)
+		return
+	}
+
+	// Pick the most recent log files written by the CLI or its plugins, skipping the links to the latest files.
+	var logs []os.FileInfo
+	for _, info := range infos {
+		name := info.Name()
+		if strings.HasPrefix(name, "pulumi") && strings.Contains(name, ".log.") && info.Mode().IsRegular() {
+			logs = append(logs, info)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].ModTime().After(logs[j].ModTime()) })
+	if len(logs) > bugReportMaxLogFiles {
+		logs = logs[:bugReportMaxLogFiles]
+	}
+
+	for _, info := range logs {
+		data, err := readTail(filepath.Join(dir, info.Name()), bugReportMaxLogSize)
+		if err != nil {
+			r.fail("reading log file "+info.Name(), err)
+			continue
+		}
+		r.add("logs/"+info.Name(), data)
+	}
+}
+
+func (r *bugReport) addStack(s backend.Stack) {
+	deployment, err := s.ExportDeployment(commandContext())
+	if err != nil {
+		r.fail("exporting deployment", err)
+	} else {
+		redacted, err := stack.RedactUntypedDeployment(deployment, stack.RedactOptions{Secrets: secretConfigKeys(s)})
+		if err != nil {
+			r.fail("redacting deployment", err)
+		} else {
+			r.addJSON("stack/deployment.json", redacted)
+		}
+	}
+
+	updates, err := s.Backend().GetHistory(commandContext(), s.Name())
+	if err != nil {
+		r.fail("getting update history", err)
+	} else if len(updates) > 0 {
+		// The configuration is omitted, as it is already reflected in the deployment.
+		last := updates[0]
+		last.Config = nil
+		r.addJSON("stack/last-update.json", last)
+	}
+}
+
+// fail records a problem gathering part of the report.
+func (r *bugReport) fail(what string, err error) {
+	r.errs = append(r.errs, fmt.Sprintf("%s: %v", what, err))
+}
+
+func (r *bugReport) addJSON(name string, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		r.fail("encoding "+name, err)
+		return
+	}
+	r.add(name, data)
+}
+
+func (r *bugReport) add(name string, data []byte) {
+	if r.err != nil {
+		return
+	}
+	w, err := r.zip.Create(name)
+	if err == nil {
+		_, err = w.Write(data)
+	}
+	r.err = err
+}
+
+// close writes any problems encountered to the report and finishes it.
+func (r *bugReport) close() error {
+	if len(r.errs) > 0 {
+		r.add("errors.txt", []byte(strings.Join(r.errs, "\n")+"\n"))
+	}
+	if err := r.zip.Close(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// readTail reads at most the last max bytes of the given file.
+func readTail(path string, max int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer contract.IgnoreClose(f)
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > max {
+		if _, err = f.Seek(info.Size()-max, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, io.LimitReader(f, max))
+	return buf.Bytes(), err
+}
@@ -21,7 +21,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/backend"
-	"github.com/pulumi/pulumi/pkg/backend/cloud"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 )
@@ -56,10 +55,11 @@ func newCancelCmd() *cobra.Command {
 				return err
 			}
 
-			// Ensure that we are targeting the Pulumi cloud.
-			backend, ok := s.Backend().(cloud.Backend)
-			if !ok {
-				return errors.New("the `cancel` command is not supported for local stacks")
+			// Ensure that the stack's backend is able to cancel updates.
+			be := s.Backend()
+			canceler, ok := be.(backend.UpdateCanceler)
+			if !ok || !be.Capabilities().CancelUpdates {
+				return errors.Errorf("the %s backend does not support canceling updates", be.Name())
 			}
 
 			// Ensure the user really wants to do this.
@@ -69,7 +69,7 @@ func newCancelCmd() *cobra.Command {
 			}
 
 			// Cancel the update.
-			if err := backend.CancelCurrentUpdate(commandContext(), s.Name()); err != nil {
+			if err := canceler.CancelCurrentUpdate(commandContext(), s.Name()); err != nil {
 				return err
 			}
 
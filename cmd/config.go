@@ -15,7 +15,10 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"regexp"
@@ -29,6 +32,7 @@ import (
 	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
@@ -76,15 +80,36 @@ func newConfigCmd() *cobra.Command {
 }
 
 func newConfigGetCmd(stack *string) *cobra.Command {
+	var jsonOut bool
+	var rawOut bool
+
 	getCmd := &cobra.Command{
-		Use:   "get <key>",
+		Use:   "get <key> [path]",
 		Short: "Get a single configuration value",
-		Args:  cmdutil.SpecificArgs([]string{"key"}),
+		Long: "Get a single configuration value.\n" +
+			"\n" +
+			"If a path is given, the value is parsed as JSON and the path selects a value nested within it;\n" +
+			"for example, 'pulumi config get db servers[0].host'.  A path may optionally begin with '$.'.\n" +
+			"\n" +
+			"By default, strings are printed as they are and structured values as indented JSON.  Use --json\n" +
+			"to print every value as JSON, or --raw to print structured values as compact JSON without a\n" +
+			"trailing newline.",
+		Args: cmdutil.RangeArgs(1, 2),
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := backend.DisplayOptions{
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
+			output := configOutputDefault
+			switch {
+			case jsonOut && rawOut:
+				return errors.New("only one of --json and --raw may be given")
+			case jsonOut:
+				output = configOutputJSON
+			case rawOut:
+				output = configOutputRaw
+			}
+
 			s, err := requireStack(*stack, true, opts, true /*setCurrent*/)
 			if err != nil {
 				return err
@@ -95,14 +120,26 @@ func newConfigGetCmd(stack *string) *cobra.Command {
 				return errors.Wrap(err, "invalid configuration key")
 			}
 
-			return getConfig(s, key)
+			var path string
+			if len(args) > 1 {
+				path = args[1]
+			}
+
+			return getConfig(s, key, path, output)
 		}),
 	}
 
+	getCmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit the value as JSON")
+	getCmd.PersistentFlags().BoolVar(
+		&rawOut, "raw", false, "Emit the value without formatting structured values or adding a trailing newline")
+
 	return getCmd
 }
 
 func newConfigRmCmd(stack *string) *cobra.Command {
+	var project bool
+
 	rmCmd := &cobra.Command{
 		Use:   "rm <key>",
 		Short: "Remove configuration value",
@@ -112,14 +149,23 @@ func newConfigRmCmd(stack *string) *cobra.Command {
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
-			s, err := requireStack(*stack, true, opts, true /*setCurrent*/)
+			key, err := parseConfigKey(args[0])
 			if err != nil {
-				return err
+				return errors.Wrap(err, "invalid configuration key")
 			}
 
-			key, err := parseConfigKey(args[0])
+			if project {
+				proj, projErr := workspace.DetectProject()
+				if projErr != nil {
+					return projErr
+				}
+				delete(proj.ConfigDefaults, key)
+				return workspace.SaveProject(proj)
+			}
+
+			s, err := requireStack(*stack, true, opts, true /*setCurrent*/)
 			if err != nil {
-				return errors.Wrap(err, "invalid configuration key")
+				return err
 			}
 
 			ps, err := workspace.DetectProjectStack(s.Name().StackName())
@@ -135,6 +181,10 @@ func newConfigRmCmd(stack *string) *cobra.Command {
 		}),
 	}
 
+	rmCmd.PersistentFlags().BoolVar(
+		&project, "project", false,
+		"Remove the value from the project's configuration, which every stack shares, instead of the stack's")
+
 	return rmCmd
 }
 
@@ -170,6 +220,18 @@ func newConfigRefreshCmd(stack *string) *cobra.Command {
 				return err
 			}
 
+			// The latest configuration includes the project's defaults, which belong in the project rather than in
+			// the stack; leave out any values that the stack does not override.
+			proj, err := workspace.DetectProject()
+			if err != nil {
+				return err
+			}
+			for k, v := range proj.ConfigDefaults {
+				if c[k] == v {
+					delete(c, k)
+				}
+			}
+
 			ps.Config = c
 
 			// If the configuration file doesn't exist, or force has been passed, save it in place.
@@ -211,6 +273,7 @@ func newConfigRefreshCmd(stack *string) *cobra.Command {
 
 func newConfigSetCmd(stack *string) *cobra.Command {
 	var plaintext bool
+	var project bool
 	var secret bool
 
 	setCmd := &cobra.Command{
@@ -218,17 +281,28 @@ func newConfigSetCmd(stack *string) *cobra.Command {
 		Short: "Set configuration value",
 		Long: "Configuration values can be accessed when a stack is being deployed and used to configure behavior. \n" +
 			"If a value is not present on the command line, pulumi will prompt for the value. Multi-line values\n" +
-			"may be set by piping a file to standard in.",
+			"may be set by piping a file to standard in.\n" +
+			"\n" +
+			"With --project, the value is saved in the project rather than in the stack, and is shared by every\n" +
+			"stack of the project that does not set the same key itself; for example, 'pulumi config set\n" +
+			"aws:region us-west-2 --project' sets the default region for all stacks.  Secrets may not be shared.",
 		Args: cmdutil.RangeArgs(1, 2),
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := backend.DisplayOptions{
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
-			// Ensure the stack exists.
-			s, err := requireStack(*stack, true, opts, true /*setCurrent*/)
-			if err != nil {
-				return err
+			if project && secret {
+				return errors.New("secrets may only be set on a stack; each stack encrypts them with its own key")
+			}
+
+			// Ensure the stack exists, unless the value belongs to the project.
+			var s backend.Stack
+			var err error
+			if !project {
+				if s, err = requireStack(*stack, true, opts, true /*setCurrent*/); err != nil {
+					return err
+				}
 			}
 
 			key, err := parseConfigKey(args[0])
@@ -282,6 +356,18 @@ func newConfigSetCmd(stack *string) *cobra.Command {
 				}
 			}
 
+			if project {
+				proj, projErr := workspace.DetectProject()
+				if projErr != nil {
+					return projErr
+				}
+				if proj.ConfigDefaults == nil {
+					proj.ConfigDefaults = make(config.Map)
+				}
+				proj.ConfigDefaults[key] = v
+				return workspace.SaveProject(proj)
+			}
+
 			ps, err := workspace.DetectProjectStack(s.Name().StackName())
 			if err != nil {
 				return err
@@ -296,6 +382,9 @@ func newConfigSetCmd(stack *string) *cobra.Command {
 	setCmd.PersistentFlags().BoolVar(
 		&plaintext, "plaintext", false,
 		"Save the value as plaintext (unencrypted)")
+	setCmd.PersistentFlags().BoolVar(
+		&project, "project", false,
+		"Save the value in the project, as a default for every stack, instead of in the stack")
 	setCmd.PersistentFlags().BoolVar(
 		&secret, "secret", false,
 		"Encrypt the value instead of storing it in plaintext")
@@ -336,13 +425,11 @@ func prettyKeyForProject(k config.Key, proj *workspace.Project) string {
 }
 
 func listConfig(stack backend.Stack, showSecrets bool) error {
-	ps, err := workspace.DetectProjectStack(stack.Name().StackName())
+	cfg, err := workspace.DetectStackConfig(stack.Name().StackName())
 	if err != nil {
 		return err
 	}
 
-	cfg := ps.Config
-
 	// By default, we will use a blinding decrypter to show '******'.  If requested, display secrets in plaintext.
 	var decrypter config.Decrypter
 	if cfg.HasSecureValue() && showSecrets {
@@ -386,14 +473,12 @@ func listConfig(stack backend.Stack, showSecrets bool) error {
 	return nil
 }
 
-func getConfig(stack backend.Stack, key config.Key) error {
-	ps, err := workspace.DetectProjectStack(stack.Name().StackName())
+func getConfig(stack backend.Stack, key config.Key, path string, output configOutput) error {
+	cfg, err := workspace.DetectStackConfig(stack.Name().StackName())
 	if err != nil {
 		return err
 	}
 
-	cfg := ps.Config
-
 	if v, ok := cfg[key]; ok {
 		var d config.Decrypter
 		if v.Secure() {
@@ -408,7 +493,11 @@ func getConfig(stack backend.Stack, key config.Key) error {
 		if err != nil {
 			return errors.Wrap(err, "could not decrypt configuration value")
 		}
-		fmt.Printf("%v\n", raw)
+		formatted, err := formatConfigValue(raw, path, output)
+		if err != nil {
+			return errors.Wrapf(err, "configuration key '%s'", prettyKey(key))
+		}
+		fmt.Print(formatted)
 		return nil
 	}
 
@@ -416,6 +505,119 @@ func getConfig(stack backend.Stack, key config.Key) error {
 		"configuration key '%s' not found for stack '%s'", prettyKey(key), stack.Name())
 }
 
+// configOutput selects how `pulumi config get` prints a value.
+type configOutput int
+
+const (
+	configOutputDefault configOutput = iota // strings as they are, structured values as indented JSON.
+	configOutputJSON                        // every value as indented JSON.
+	configOutputRaw                         // strings as they are, structured values as compact JSON, no newline.
+)
+
+// formatConfigValue formats a configuration value for display.  If a path is given, the value must be JSON, and the
+// result is the value nested within it at that path.
+func formatConfigValue(value string, path string, output configOutput) (string, error) {
+	var v interface{} = value
+	if path != "" || output == configOutputJSON {
+		structured, err := parseConfigJSON(value)
+		switch {
+		case err == nil:
+			v = structured
+		case path != "":
+			return "", errors.Wrap(err, "value is not valid JSON, so a path may not be used")
+		}
+	}
+
+	if path != "" {
+		p, err := parseConfigPath(path)
+		if err != nil {
+			return "", err
+		}
+		nested, ok := getConfigPath(v, p)
+		if !ok {
+			return "", errors.Errorf("value has nothing at path '%s'", path)
+		}
+		v = nested
+	}
+
+	if s, isString := v.(string); isString && output != configOutputJSON {
+		if output == configOutputRaw {
+			return s, nil
+		}
+		return s + "\n", nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if output != configOutputRaw {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	if output == configOutputRaw {
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	}
+	return buf.String(), nil
+}
+
+// parseConfigJSON parses a configuration value as a single JSON value, preserving the exact text of any numbers.
+func parseConfigJSON(value string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the end of the JSON value")
+	}
+	return v, nil
+}
+
+// parseConfigPath parses a path into a structured configuration value.  Paths use the same syntax as property paths,
+// e.g. `servers[0].host`, optionally preceded by `$` or `$.` in the style of JSONPath; a path of just `$` selects the
+// entire value.
+func parseConfigPath(path string) (resource.PropertyPath, error) {
+	trimmed := strings.TrimPrefix(path, "$")
+	if trimmed != path {
+		trimmed = strings.TrimPrefix(trimmed, ".")
+		if trimmed == "" {
+			return nil, nil
+		}
+	}
+	p, err := resource.ParsePropertyPath(trimmed)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid path '%s'", path)
+	}
+	return p, nil
+}
+
+// getConfigPath returns the value at the given path within a value parsed from JSON, if there is one.
+func getConfigPath(v interface{}, path resource.PropertyPath) (interface{}, bool) {
+	for _, elem := range path {
+		switch elem := elem.(type) {
+		case string:
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if v, ok = obj[elem]; !ok {
+				return nil, false
+			}
+		case int:
+			arr, ok := v.([]interface{})
+			if !ok || elem >= len(arr) {
+				return nil, false
+			}
+			v = arr[elem]
+		}
+	}
+	return v, true
+}
+
 var (
 	// keyPattern is the regular expression a configuration key must match before we check (and error) if we think
 	// it is a password
@@ -435,7 +637,13 @@ const (
 // that suffers from false positives, but is better (a) than our prior approach of unconditionally printing a warning
 // for all plaintext values, and (b)  to be paranoid about such things. Inspired by the gas linter and securego project.
 func looksLikeSecret(k config.Key, v string) bool {
-	if !keyPattern.MatchString(k.Name()) {
+	return looksLikeSecretValue(k.Name(), v)
+}
+
+// looksLikeSecretValue returns true if a value with the given name, e.g. that of a configuration key or stack output,
+// "looks" like a secret.
+func looksLikeSecretValue(name, v string) bool {
+	if !keyPattern.MatchString(name) {
 		return false
 	}
 
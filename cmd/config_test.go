@@ -41,3 +41,49 @@ func TestSecretDetection(t *testing.T) {
 	// The key name does not match the, so even though this "looks like" a secret, we say it is not.
 	assert.False(t, looksLikeSecret(config.MustMakeKey("test", "okay"), "1415fc1f4eaeb5e096ee58c1480016638fff29bf"))
 }
+
+func TestFormatConfigValue(t *testing.T) {
+	const structured = `{"servers": [{"host": "a.example.com", "port": 8080}], "name": "<db>"}`
+
+	cases := []struct {
+		value    string
+		path     string
+		output   configOutput
+		expected string
+	}{
+		// Values print as they are unless a path or JSON output is requested.
+		{"hello", "", configOutputDefault, "hello\n"},
+		{"hello", "", configOutputRaw, "hello"},
+		{"hello", "", configOutputJSON, "\"hello\"\n"},
+		{"42", "", configOutputJSON, "42\n"},
+		{structured, "", configOutputDefault, structured + "\n"},
+
+		// Paths select nested values, with or without a JSONPath-style prefix.
+		{structured, "servers[0].host", configOutputDefault, "a.example.com\n"},
+		{structured, "$.servers[0].host", configOutputJSON, "\"a.example.com\"\n"},
+		{structured, "servers[0].port", configOutputRaw, "8080"},
+		{structured, "name", configOutputJSON, "\"<db>\"\n"},
+		{structured, "servers[0]", configOutputRaw, `{"host":"a.example.com","port":8080}`},
+		{structured, "servers", configOutputDefault,
+			"[\n  {\n    \"host\": \"a.example.com\",\n    \"port\": 8080\n  }\n]\n"},
+		{"[1, 2]", "$[1]", configOutputDefault, "2\n"},
+		{"[1, 2]", "$", configOutputRaw, "[1,2]"},
+	}
+	for _, c := range cases {
+		actual, err := formatConfigValue(c.value, c.path, c.output)
+		assert.NoError(t, err, "%s %s", c.value, c.path)
+		assert.Equal(t, c.expected, actual, "%s %s", c.value, c.path)
+	}
+
+	// Paths require JSON values and must exist.
+	_, err := formatConfigValue("hello", "a", configOutputDefault)
+	assert.Error(t, err)
+	_, err = formatConfigValue(`{"a": 1} {"b": 2}`, "a", configOutputDefault)
+	assert.Error(t, err)
+	_, err = formatConfigValue(structured, "servers[1].host", configOutputDefault)
+	assert.Error(t, err)
+	_, err = formatConfigValue(structured, "name.first", configOutputDefault)
+	assert.Error(t, err)
+	_, err = formatConfigValue(structured, "servers[", configOutputDefault)
+	assert.Error(t, err)
+}
@@ -0,0 +1,418 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/skratchdot/open-golang/open"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newConsoleCmd() *cobra.Command {
+	var stackName string
+	var version int
+	var urn string
+	var output string
+	var printOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "console",
+		Args:  cmdutil.NoArgs,
+		Short: "Open the current stack in a web console",
+		Long: "Open the current stack in a web console.\n" +
+			"\n" +
+			"This command opens the stack's page in the web console of its backend, or the page of one of\n" +
+			"its updates or resources when given --update or --resource, and prints the page's URL.  Pass\n" +
+			"--print to only print the URL.\n" +
+			"\n" +
+			"Backends without a web console, such as the local one, instead get a read-only HTML page\n" +
+			"generated from the stack's checkpoint and history, which lists the stack's outputs, resources,\n" +
+			"and updates.  The page is written to a temporary directory unless --output names a file.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			if version < 0 {
+				return errors.Errorf("invalid update version %d", version)
+			}
+
+			var link string
+			if s.Backend().Capabilities().Console {
+				if output != "" {
+					return errors.New("--output may only be used with backends that do not have a web console")
+				}
+				link, err = getConsoleURL(s, version, resource.URN(urn))
+			} else {
+				link, err = writeConsolePage(s, version, resource.URN(urn), output)
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(link)
+			if !printOnly {
+				if openErr := open.Run(link); openErr != nil {
+					fmt.Printf("We couldn't launch your browser for some reason; please visit the URL above.\n")
+				}
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().IntVar(
+		&version, "update", 0,
+		"Open the page of the update that produced the given version of the stack")
+	cmd.PersistentFlags().StringVar(
+		&urn, "resource", "",
+		"Open the page of the resource with the given URN")
+	cmd.PersistentFlags().StringVar(
+		&output, "output", "",
+		"Write the generated page to the given file, for backends without a web console")
+	cmd.PersistentFlags().BoolVar(
+		&printOnly, "print", false,
+		"Print the URL without opening it in a browser")
+
+	return cmd
+}
+
+// getConsoleURL returns the URL of the console page of a stack, of the update that produced the given version of it,
+// or of its resource with the given URN.
+func getConsoleURL(s backend.Stack, version int, urn resource.URN) (string, error) {
+	cs := s.(backend.ConsoleLinkedStack)
+	switch {
+	case version != 0 && urn != "":
+		return "", errors.New("--update and --resource may not be used together")
+	case version != 0:
+		return cs.UpdateConsoleURL(version)
+	case urn != "":
+		snap, err := s.Snapshot(commandContext())
+		if err != nil {
+			return "", err
+		}
+		if findConsoleResource(snap, urn) < 0 {
+			return "", errors.Errorf("stack '%s' has no resource '%s'", s.Name(), urn)
+		}
+		return cs.ResourceConsoleURL(urn)
+	default:
+		return cs.ConsoleURL()
+	}
+}
+
+// writeConsolePage generates a read-only HTML page describing a stack from its checkpoint and history, writes it to
+// the given file or else to a temporary one, and returns a file URL that links to the page, or to the part of the
+// page that describes the update that produced the given version of the stack or the resource with the given URN.
+func writeConsolePage(s backend.Stack, version int, urn resource.URN, output string) (string, error) {
+	if version != 0 && urn != "" {
+		return "", errors.New("--update and --resource may not be used together")
+	}
+
+	snap, err := s.Snapshot(commandContext())
+	if err != nil {
+		return "", err
+	}
+	var updates []backend.UpdateInfo
+	if s.Backend().Capabilities().History {
+		if updates, err = s.Backend().GetHistory(commandContext(), s.Name()); err != nil {
+			return "", errors.Wrap(err, "getting stack history")
+		}
+	}
+
+	var anchor string
+	switch {
+	case version != 0:
+		found := false
+		for _, update := range updates {
+			if update.Version == version {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", errors.Errorf("stack '%s' has no update %d", s.Name(), version)
+		}
+		anchor = updateAnchor(version)
+	case urn != "":
+		i := findConsoleResource(snap, urn)
+		if i < 0 {
+			return "", errors.Errorf("stack '%s' has no resource '%s'", s.Name(), urn)
+		}
+		anchor = resourceAnchor(i)
+	}
+
+	if output == "" {
+		dir, err := ioutil.TempDir("", "pulumi-console")
+		if err != nil {
+			return "", err
+		}
+		output = filepath.Join(dir, s.Name().String()+".html")
+	}
+	if output, err = filepath.Abs(output); err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+	if err = renderConsolePage(f, s.Name().String(), snap, updates); err != nil {
+		_ = f.Close()
+		return "", errors.Wrapf(err, "writing %s", output)
+	}
+	if err = f.Close(); err != nil {
+		return "", err
+	}
+
+	link := filepath.ToSlash(output)
+	if !strings.HasPrefix(link, "/") {
+		link = "/" + link
+	}
+	link = "file://" + link
+	if anchor != "" {
+		link += "#" + anchor
+	}
+	return link, nil
+}
+
+// findConsoleResource returns the index of the resource with the given URN in the snapshot, or -1 if there is none.
+func findConsoleResource(snap *deploy.Snapshot, urn resource.URN) int {
+	if snap != nil {
+		for i, res := range snap.Resources {
+			if res.URN == urn {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// resourceAnchor returns the ID of the element that describes the resource at the given index in a console page.
+func resourceAnchor(i int) string {
+	return fmt.Sprintf("resource-%d", i)
+}
+
+// updateAnchor returns the ID of the element that describes the update with the given version in a console page.
+func updateAnchor(version int) string {
+	return fmt.Sprintf("update-%d", version)
+}
+
+// consolePage holds the values that are rendered by consolePageTemplate.
+type consolePage struct {
+	Stack     string
+	Updated   string
+	Version   string
+	Outputs   []consoleOutput
+	Resources []consoleResource
+	Updates   []consoleUpdate
+}
+
+type consoleOutput struct {
+	Name  string
+	Value string
+}
+
+type consoleResource struct {
+	Anchor       string
+	URN          resource.URN
+	Type         string
+	Name         string
+	ID           string
+	ParentAnchor string
+	Parent       resource.URN
+	Protect      bool
+	Delete       bool
+	Inputs       string
+	Outputs      string
+}
+
+type consoleUpdate struct {
+	Anchor  string
+	Version int
+	Kind    string
+	Result  string
+	Started string
+	Message string
+	Changes string
+}
+
+// renderConsolePage renders a read-only HTML page that describes a stack's outputs, resources, and updates.
+func renderConsolePage(w io.Writer, stackName string, snap *deploy.Snapshot, updates []backend.UpdateInfo) error {
+	page := consolePage{Stack: stackName}
+
+	if snap != nil {
+		if t := snap.Manifest.Time; !t.IsZero() {
+			page.Updated = t.Format(time.RFC1123)
+		}
+		page.Version = snap.Manifest.Version
+		if snap.Manifest.OS != "" {
+			page.Version += fmt.Sprintf(" (%s/%s)", snap.Manifest.OS, snap.Manifest.Arch)
+		}
+
+		page.Outputs = consoleOutputs(snap)
+
+		anchors := make(map[resource.URN]string)
+		for i, res := range snap.Resources {
+			anchors[res.URN] = resourceAnchor(i)
+			page.Resources = append(page.Resources, consoleResource{
+				Anchor:       resourceAnchor(i),
+				URN:          res.URN,
+				Type:         string(res.Type),
+				Name:         string(res.URN.Name()),
+				ID:           string(res.ID),
+				ParentAnchor: anchors[res.Parent],
+				Parent:       res.Parent,
+				Protect:      res.Protect,
+				Delete:       res.Delete,
+				Inputs:       consolePropertyJSON(res.Inputs()),
+				Outputs:      consolePropertyJSON(res.Outputs()),
+			})
+		}
+	}
+
+	for _, update := range updates {
+		page.Updates = append(page.Updates, newConsoleUpdate(update))
+	}
+
+	return consolePageTemplate.Execute(w, page)
+}
+
+// consoleOutputs returns the outputs of the stack whose snapshot is given, sorted by name.
+func consoleOutputs(snap *deploy.Snapshot) []consoleOutput {
+	_, outputs := stack.GetRootStackResource(snap)
+	var keys []string
+	for key := range outputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result []consoleOutput
+	for _, key := range keys {
+		result = append(result, consoleOutput{Name: key, Value: stringifyOutput(outputs[key])})
+	}
+	return result
+}
+
+// newConsoleUpdate describes an update from a stack's history for presentation in a console page.
+func newConsoleUpdate(update backend.UpdateInfo) consoleUpdate {
+	var started string
+	if update.StartTime != 0 {
+		started = time.Unix(update.StartTime, 0).Format(time.RFC1123)
+	}
+	var changes []string
+	for _, op := range deploy.StepOps {
+		if c := update.ResourceChanges[op]; c > 0 && op != deploy.OpSame {
+			changes = append(changes, fmt.Sprintf("%d %s", c, op))
+		}
+	}
+	return consoleUpdate{
+		Anchor:  updateAnchor(update.Version),
+		Version: update.Version,
+		Kind:    string(update.Kind),
+		Result:  string(update.Result),
+		Started: started,
+		Message: update.Message,
+		Changes: strings.Join(changes, ", "),
+	}
+}
+
+// consolePropertyJSON formats a resource's properties as indented JSON for presentation in a console page.
+func consolePropertyJSON(props resource.PropertyMap) string {
+	if len(props) == 0 {
+		return ""
+	}
+	// The page's template escapes the JSON, so the encoder need not.
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(props.Mappable()); err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+var consolePageTemplate = template.Must(template.New("console").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Stack {{.Stack}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+pre { margin: 0; }
+:target { background: #fff3c4; }
+</style>
+</head>
+<body>
+<h1>Stack {{.Stack}}</h1>
+<p>{{if .Updated}}Last updated {{.Updated}}{{else}}Last update time unknown{{end}}{{if .Version}}
+by Pulumi {{.Version}}{{end}}.</p>
+
+<h2>Outputs</h2>
+{{if .Outputs}}<table>
+<tr><th>Output</th><th>Value</th></tr>
+{{range .Outputs}}<tr><td>{{.Name}}</td><td><pre>{{.Value}}</pre></td></tr>
+{{end}}</table>{{else}}<p>No outputs.</p>{{end}}
+
+<h2>Resources</h2>
+{{if .Resources}}<table>
+<tr><th>Name</th><th>Type</th><th>ID</th><th>Parent</th><th>Properties</th></tr>
+{{range .Resources}}<tr id="{{.Anchor}}">
+<td><a href="#{{.Anchor}}">{{.Name}}</a>{{if .Protect}} (protected){{end}}{{if .Delete}} (pending deletion){{end}}
+<br><small>{{.URN}}</small></td>
+<td>{{.Type}}</td>
+<td>{{.ID}}</td>
+<td>{{if .ParentAnchor}}<a href="#{{.ParentAnchor}}">{{.Parent}}</a>{{else}}{{.Parent}}{{end}}</td>
+<td>{{if .Inputs}}<details><summary>Inputs</summary><pre>{{.Inputs}}</pre></details>{{end}}
+{{if .Outputs}}<details><summary>Outputs</summary><pre>{{.Outputs}}</pre></details>{{end}}</td>
+</tr>
+{{end}}</table>{{else}}<p>No resources.</p>{{end}}
+
+<h2>Updates</h2>
+{{if .Updates}}<table>
+<tr><th>Version</th><th>Kind</th><th>Result</th><th>Started</th><th>Changes</th><th>Message</th></tr>
+{{range .Updates}}<tr id="{{.Anchor}}">
+<td><a href="#{{.Anchor}}">{{.Version}}</a></td><td>{{.Kind}}</td><td>{{.Result}}</td><td>{{.Started}}</td>
+<td>{{.Changes}}</td><td>{{.Message}}</td>
+</tr>
+{{end}}</table>{{else}}<p>No updates.</p>{{end}}
+</body>
+</html>
+`))
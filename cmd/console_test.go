@@ -0,0 +1,71 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestRenderConsolePage(t *testing.T) {
+	stackURN := resource.NewURN("dev", "proj", "", resource.RootStackType, "proj-dev")
+	stackRes := resource.NewState(resource.RootStackType, stackURN, false, false, "", resource.PropertyMap{},
+		resource.NewPropertyMapFromMap(map[string]interface{}{"url": "http://example.com"}), "", false, false, nil,
+		nil, "")
+	urn := resource.NewURN("dev", "proj", resource.RootStackType, "pkgA:m:typA", tokens.QName("resA"))
+	res := resource.NewState("pkgA:m:typA", urn, true, false, "id-1",
+		resource.NewPropertyMapFromMap(map[string]interface{}{"size": "small"}),
+		resource.NewPropertyMapFromMap(map[string]interface{}{"html": "<script>alert(1)</script>"}), stackURN, true,
+		false, nil, nil, "")
+	snap := deploy.NewSnapshot(deploy.Manifest{Version: "v0.15.0", OS: "linux", Arch: "amd64"},
+		[]*resource.State{stackRes, res}, nil)
+	updates := []backend.UpdateInfo{{
+		Version:         2,
+		Kind:            apitype.UpdateUpdate,
+		Result:          backend.SucceededResult,
+		Message:         "grow resA",
+		ResourceChanges: map[deploy.StepOp]int{deploy.OpUpdate: 1, deploy.OpSame: 1},
+	}}
+
+	assert.Equal(t, 1, findConsoleResource(snap, urn))
+	assert.Equal(t, -1, findConsoleResource(snap, "urn:pulumi:dev::proj::pkgA:m:typA::missing"))
+
+	var out bytes.Buffer
+	assert.NoError(t, renderConsolePage(&out, "dev", snap, updates))
+	page := out.String()
+
+	assert.Contains(t, page, "<title>Stack dev</title>")
+	assert.Contains(t, page, "by Pulumi v0.15.0 (linux/amd64)")
+	assert.Contains(t, page, "<td>url</td><td><pre>http://example.com</pre></td>")
+
+	// Each resource and update may be linked to, and resources link to their parents.
+	assert.Contains(t, page, `<tr id="resource-1">`)
+	assert.Contains(t, page, `<a href="#resource-0">`+string(stackURN)+`</a>`)
+	assert.Contains(t, page, "(protected)")
+	assert.Contains(t, page, `<tr id="update-2">`)
+	assert.Contains(t, page, "<td>1 update</td><td>grow resA</td>")
+
+	// Property values are escaped.
+	assert.NotContains(t, page, "<script>")
+	assert.Contains(t, page, "&lt;script&gt;")
+}
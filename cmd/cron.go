@@ -0,0 +1,164 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// cronAliases are the shorthands that may be given in place of a five-field cron schedule.
+var cronAliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule is a parsed cron schedule.  Each field is the set of values, as a bitset, at which the schedule runs.
+type cronSchedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// As in cron, if both the day of the month and the day of the week are restricted, a day that matches either runs.
+	anyDay     bool
+	anyWeekday bool
+}
+
+// parseCronSchedule parses a schedule of five space-separated fields, in the manner of cron: the minute (0-59), the
+// hour (0-23), the day of the month (1-31), the month (1-12), and the day of the week (0-6, or 7, from Sunday).  Each
+// field is `*` or a comma-separated list of values and ranges, e.g. `1-5`, any of which may be followed by a step,
+// e.g. `*/15`.  The aliases @yearly, @monthly, @weekly, @daily, and @hourly may be used as well.
+func parseCronSchedule(spec string) (*cronSchedule, error) {
+	if alias, ok := cronAliases[spec]; ok {
+		spec = alias
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron schedule %q must have five fields: minute, hour, day, month, and weekday",
+			spec)
+	}
+
+	var sched cronSchedule
+	var err error
+	if sched.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, errors.Wrapf(err, "cron schedule %q: minute", spec)
+	}
+	if sched.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, errors.Wrapf(err, "cron schedule %q: hour", spec)
+	}
+	if sched.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, errors.Wrapf(err, "cron schedule %q: day", spec)
+	}
+	if sched.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, errors.Wrapf(err, "cron schedule %q: month", spec)
+	}
+	if sched.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, errors.Wrapf(err, "cron schedule %q: weekday", spec)
+	}
+
+	// Sunday may be written as either 0 or 7.
+	if sched.weekdays&(1<<7) != 0 {
+		sched.weekdays |= 1
+	}
+	sched.anyDay, sched.anyWeekday = strings.HasPrefix(fields[2], "*"), strings.HasPrefix(fields[4], "*")
+	return &sched, nil
+}
+
+// parseCronField parses a single field of a cron schedule whose values range from min to max, inclusive.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		lo, hi, step := min, max, 1
+		rng := part
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s <= 0 {
+				return 0, errors.Errorf("invalid step in %q", part)
+			}
+			rng, step = part[:i], s
+		}
+
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, errors.Errorf("invalid value in %q", part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, errors.Errorf("invalid value in %q", part)
+				}
+			} else if step != 1 {
+				// As in cron, a single value with a step runs from that value to the end of the range.
+				hi = max
+			}
+			if lo < min || hi > max || lo > hi {
+				return 0, errors.Errorf("%q is not within %d-%d", part, min, max)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// cronSearchLimit bounds the search for the next time at which a schedule runs, so that schedules that never run,
+// such as one for the 31st of February, do not search forever.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// next returns the first minute after the given time at which the schedule runs, in the given time's location, or the
+// zero time if it never does.
+func (sched *cronSchedule) next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+	for t.Before(limit) {
+		switch {
+		case sched.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !sched.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case sched.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case sched.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDay returns true if the schedule runs on the day of the given time.
+func (sched *cronSchedule) matchesDay(t time.Time) bool {
+	day := sched.days&(1<<uint(t.Day())) != 0
+	weekday := sched.weekdays&(1<<uint(t.Weekday())) != 0
+	if sched.anyDay || sched.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
@@ -0,0 +1,667 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/skratchdot/open-golang/open"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+func newDashboardCmd() *cobra.Command {
+	var port int
+	var noBrowser bool
+
+	cmd := &cobra.Command{
+		Use:   "dashboard",
+		Args:  cmdutil.NoArgs,
+		Short: "Serve a local web dashboard for the current backend",
+		Long: "Serve a local web dashboard for the current backend.\n" +
+			"\n" +
+			"This command serves a read-only web UI on the loopback interface that lists the backend's stacks,\n" +
+			"shows the tree of each stack's resources and the changes made by its recent updates, and follows\n" +
+			"the progress of an update while it runs.  It is most useful with backends that have no web console\n" +
+			"of their own, such as the local one.  The dashboard is opened in a browser unless --no-browser is\n" +
+			"passed, and is served until the command is interrupted.  The dashboard's URL holds a token that\n" +
+			"the browser must present, and which is then kept in a cookie, so that only those with the URL may\n" +
+			"view it.\n" +
+			"\n" +
+			"Following updates as they run requires a backend that records their events, which the local backend\n" +
+			"does.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			b, err := currentBackend(opts)
+			if err != nil {
+				return err
+			}
+
+			tokenBytes := make([]byte, 32)
+			_, err = cryptorand.Read(tokenBytes)
+			contract.AssertNoErrorf(err, "could not get random bytes")
+			token := hex.EncodeToString(tokenBytes)
+
+			l, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+			if err != nil {
+				return errors.Wrap(err, "listening for dashboard requests")
+			}
+			addr := l.Addr().String()
+			_, listenPort, err := net.SplitHostPort(addr)
+			contract.AssertNoError(err)
+			link := "http://" + addr + "/?token=" + token
+
+			fmt.Printf("Serving the dashboard for %s at %s; press ^C to stop.\n", b.Name(), link)
+			if !noBrowser {
+				if openErr := open.Run(link); openErr != nil {
+					fmt.Printf("We couldn't launch your browser for some reason; please visit the URL above.\n")
+				}
+			}
+			return http.Serve(l, newDashboardHandler(b, token, []string{addr, "localhost:" + listenPort}))
+		}),
+	}
+
+	cmd.PersistentFlags().IntVarP(
+		&port, "port", "p", 0,
+		"The port on which to serve the dashboard.  Defaults to a free port")
+	cmd.PersistentFlags().BoolVar(
+		&noBrowser, "no-browser", false,
+		"Print the dashboard's URL without opening it in a browser")
+
+	return cmd
+}
+
+// dashboardServer serves the pages of the dashboard for a backend.
+type dashboardServer struct {
+	b backend.Backend
+}
+
+// dashboardTokenCookie is the cookie in which browsers present the dashboard's token once they have opened its URL.
+const dashboardTokenCookie = "pulumi-dashboard-token"
+
+// newDashboardHandler returns a handler that serves the pages of the dashboard for the given backend.  The root page
+// lists the backend's stacks; /stack?name=NAME shows a stack's outputs, resources, updates, and the progress of its
+// latest update, which it polls /events?stack=NAME for; and /update?stack=NAME&version=N shows the changes made by
+// the update that produced a version of a stack.
+//
+// Only requests for one of the given hosts are served, so that other sites cannot reach the dashboard by rebinding
+// their names to the loopback interface.  Requests must also present the given token, either in a `token` query
+// parameter, which is exchanged for a cookie, or in that cookie.
+func newDashboardHandler(b backend.Backend, token string, hosts []string) http.Handler {
+	s := &dashboardServer{b: b}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serveStacks)
+	mux.HandleFunc("/stack", s.serveStack)
+	mux.HandleFunc("/update", s.serveUpdate)
+	mux.HandleFunc("/events", s.serveEvents)
+
+	valid := func(presented string) bool {
+		return subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed := false
+		for _, host := range hosts {
+			allowed = allowed || r.Host == host
+		}
+		if !allowed {
+			http.Error(w, fmt.Sprintf("invalid host '%s'", r.Host), http.StatusForbidden)
+			return
+		}
+
+		// A token in the URL is kept in a cookie, and removed from the URL so that it does not linger in links.
+		query := r.URL.Query()
+		if presented := query.Get("token"); presented != "" {
+			if !valid(presented) {
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: dashboardTokenCookie, Value: token, Path: "/", HttpOnly: true})
+			query.Del("token")
+			u := *r.URL
+			u.RawQuery = query.Encode()
+			http.Redirect(w, r, u.RequestURI(), http.StatusFound)
+			return
+		}
+		if cookie, err := r.Cookie(dashboardTokenCookie); err != nil || !valid(cookie.Value) {
+			http.Error(w, "missing or invalid token; open the URL that `pulumi dashboard` printed",
+				http.StatusUnauthorized)
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// dashboardError reports an error to a dashboard request.
+func dashboardError(w http.ResponseWriter, status int, err error) {
+	http.Error(w, err.Error(), status)
+}
+
+// dashboardRender renders a page of the dashboard.
+func dashboardRender(w http.ResponseWriter, name string, page interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplates.ExecuteTemplate(w, name, page); err != nil {
+		dashboardError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// getStack returns the stack named by the given query parameter of a request, or an error and the status with which
+// to report it.
+func (s *dashboardServer) getStack(r *http.Request, param string) (backend.Stack, int, error) {
+	name := r.URL.Query().Get(param)
+	if name == "" {
+		return nil, http.StatusBadRequest, errors.Errorf("missing '%s' parameter", param)
+	}
+	ref, err := s.b.ParseStackReference(name)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	st, err := s.b.GetStack(r.Context(), ref)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if st == nil {
+		return nil, http.StatusNotFound, errors.Errorf("no stack named '%s' found", name)
+	}
+	return st, http.StatusOK, nil
+}
+
+// dashboardStack summarizes a stack in the dashboard's list of stacks.
+type dashboardStack struct {
+	Name      string
+	Resources int
+	Updated   string
+}
+
+type dashboardStacksPage struct {
+	Backend string
+	Stacks  []dashboardStack
+}
+
+func (s *dashboardServer) serveStacks(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	stacks, err := s.b.ListStacks(r.Context(), nil)
+	if err != nil {
+		dashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	page := dashboardStacksPage{Backend: s.b.Name()}
+	for _, st := range stacks {
+		summary := dashboardStack{Name: st.Name().String()}
+		snap, err := st.Snapshot(r.Context())
+		if err != nil {
+			dashboardError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if snap != nil {
+			summary.Resources = len(snap.Resources)
+			if t := snap.Manifest.Time; !t.IsZero() {
+				summary.Updated = t.Format(time.RFC1123)
+			}
+		}
+		page.Stacks = append(page.Stacks, summary)
+	}
+
+	dashboardRender(w, "stacks", page)
+}
+
+// dashboardResource is a node in the tree of a stack's resources, each of which is the parent of those beneath it.
+type dashboardResource struct {
+	URN      resource.URN
+	Type     string
+	Name     string
+	ID       string
+	Protect  bool
+	Delete   bool
+	Inputs   string
+	Outputs  string
+	Children []*dashboardResource
+}
+
+type dashboardStackPage struct {
+	Stack     string
+	Outputs   []consoleOutput
+	Resources []*dashboardResource
+	Updates   []consoleUpdate
+	Live      bool
+}
+
+func (s *dashboardServer) serveStack(w http.ResponseWriter, r *http.Request) {
+	st, status, err := s.getStack(r, "name")
+	if err != nil {
+		dashboardError(w, status, err)
+		return
+	}
+
+	snap, err := st.Snapshot(r.Context())
+	if err != nil {
+		dashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+	page := dashboardStackPage{
+		Stack:     st.Name().String(),
+		Outputs:   consoleOutputs(snap),
+		Resources: dashboardResourceTree(snap),
+		Live:      s.b.Capabilities().UpdateEvents,
+	}
+
+	if s.b.Capabilities().History {
+		updates, err := s.b.GetHistory(r.Context(), st.Name())
+		if err != nil {
+			dashboardError(w, http.StatusInternalServerError, err)
+			return
+		}
+		for _, update := range updates {
+			page.Updates = append(page.Updates, newConsoleUpdate(update))
+		}
+	}
+
+	dashboardRender(w, "stack", page)
+}
+
+// dashboardResourceTree arranges the resources of a snapshot into trees by their parents.  Resources whose parents
+// are not in the snapshot are the roots of trees of their own.
+func dashboardResourceTree(snap *deploy.Snapshot) []*dashboardResource {
+	if snap == nil {
+		return nil
+	}
+
+	var roots []*dashboardResource
+	nodes := make(map[resource.URN]*dashboardResource)
+	for _, res := range snap.Resources {
+		node := &dashboardResource{
+			URN:     res.URN,
+			Type:    string(res.Type),
+			Name:    string(res.URN.Name()),
+			ID:      string(res.ID),
+			Protect: res.Protect,
+			Delete:  res.Delete,
+			Inputs:  consolePropertyJSON(res.Inputs()),
+			Outputs: consolePropertyJSON(res.Outputs()),
+		}
+		// A resource that is pending deletion shares its URN with its replacement, which should remain the parent of
+		// the resource's children.
+		if _, has := nodes[res.URN]; !has || !res.Delete {
+			nodes[res.URN] = node
+		}
+
+		if parent, has := nodes[res.Parent]; has && res.Parent != "" {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+// dashboardChange describes a resource that an update changed.
+type dashboardChange struct {
+	Op      deploy.StepOp
+	URN     resource.URN
+	Type    string
+	Name    string
+	Details string
+}
+
+type dashboardUpdatePage struct {
+	Stack   string
+	Update  consoleUpdate
+	Changes []dashboardChange
+	Same    int
+}
+
+func (s *dashboardServer) serveUpdate(w http.ResponseWriter, r *http.Request) {
+	st, status, err := s.getStack(r, "stack")
+	if err != nil {
+		dashboardError(w, status, err)
+		return
+	}
+	version, err := strconv.Atoi(r.URL.Query().Get("version"))
+	if err != nil || version < 1 {
+		dashboardError(w, http.StatusBadRequest, errors.Errorf("invalid version '%s'", r.URL.Query().Get("version")))
+		return
+	}
+	if !s.b.Capabilities().History {
+		dashboardError(w, http.StatusNotFound,
+			errors.Errorf("the backend for stack '%s' does not keep the history of its updates", st.Name()))
+		return
+	}
+
+	updates, err := s.b.GetHistory(r.Context(), st.Name())
+	if err != nil {
+		dashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+	page := dashboardUpdatePage{Stack: st.Name().String()}
+	found := false
+	for _, update := range updates {
+		if update.Version == version {
+			page.Update, found = newConsoleUpdate(update), true
+			break
+		}
+	}
+	if !found {
+		dashboardError(w, http.StatusNotFound, errors.Errorf("stack '%s' has no update %d", st.Name(), version))
+		return
+	}
+
+	// The update's changes are those between the version of the stack that it produced and the one before it.
+	var base *deploy.Snapshot
+	if version > 1 {
+		if base, err = s.getHistoricalSnapshot(r, st, version-1); err != nil {
+			dashboardError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	other, err := s.getHistoricalSnapshot(r, st, version)
+	if err != nil {
+		dashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	change := func(op deploy.StepOp, res *resource.State, details string) dashboardChange {
+		return dashboardChange{
+			Op:      op,
+			URN:     res.URN,
+			Type:    string(res.Type),
+			Name:    string(res.URN.Name()),
+			Details: details,
+		}
+	}
+	diff, err := backend.DiffSnapshots(base, other)
+	if err != nil {
+		dashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+	for _, res := range diff.OnlyInOther {
+		page.Changes = append(page.Changes, change(deploy.OpCreate, res, ""))
+	}
+	for _, d := range diff.Changed {
+		details := strings.TrimRight(colors.Never.Colorize(resourceDiffDetails(d)), "\n")
+		page.Changes = append(page.Changes, change(deploy.OpUpdate, d.Other, details))
+	}
+	for _, res := range diff.OnlyInBase {
+		page.Changes = append(page.Changes, change(deploy.OpDelete, res, ""))
+	}
+	page.Same = len(diff.Same)
+
+	dashboardRender(w, "update", page)
+}
+
+// getHistoricalSnapshot loads the snapshot produced by the given version of a stack.
+func (s *dashboardServer) getHistoricalSnapshot(r *http.Request, st backend.Stack,
+	version int) (*deploy.Snapshot, error) {
+
+	deployment, err := s.b.GetHistoricalDeployment(r.Context(), st.Name(), version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting version %d of stack '%s'", version, st.Name())
+	}
+	snap, err := stack.DeserializeUntypedDeployment(deployment)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading version %d of stack '%s'", version, st.Name())
+	}
+	return snap, nil
+}
+
+// dashboardEvents is the progress of a stack's latest update, as returned to the dashboard's stack pages.  Pages pass
+// back the update's start time and the sequence number of the next event they need with each request, and start over
+// whenever Reset is set, which it is once a new update has begun.
+type dashboardEvents struct {
+	Started int64    `json:"started"`
+	Reset   bool     `json:"reset"`
+	Lines   []string `json:"lines"`
+	Next    int      `json:"next"`
+	Done    bool     `json:"done"`
+}
+
+func (s *dashboardServer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	st, status, err := s.getStack(r, "stack")
+	if err != nil {
+		dashboardError(w, status, err)
+		return
+	}
+	reader, ok := s.b.(backend.UpdateEventsReader)
+	if !ok || !s.b.Capabilities().UpdateEvents {
+		dashboardError(w, http.StatusNotFound,
+			errors.Errorf("the backend for stack '%s' does not record the events of its updates", st.Name()))
+		return
+	}
+
+	events, done, err := reader.GetLatestUpdateEvents(r.Context(), st.Name())
+	if err != nil {
+		dashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := dashboardEvents{Lines: []string{}, Done: done}
+	if len(events) > 0 {
+		result.Started = events[0].Timestamp
+	}
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil || strconv.FormatInt(result.Started, 10) != r.URL.Query().Get("started") {
+		from, result.Reset = 0, true
+	}
+	result.Next = from
+	for _, e := range events {
+		if e.Sequence < from {
+			continue
+		}
+		if line := dashboardEventLine(e); line != "" {
+			result.Lines = append(result.Lines, line)
+		}
+		result.Next = e.Sequence + 1
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		dashboardError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// dashboardEventLine describes an engine event as a line of plain text, or returns "" if the event is not worth
+// showing.
+func dashboardEventLine(e apitype.EngineEvent) string {
+	step := func(md apitype.StepEventMetadata, status string) string {
+		if md.Op == string(deploy.OpSame) {
+			return ""
+		}
+		return fmt.Sprintf("%s %s %s%s", md.Op, md.Type, md.URN.Name(), status)
+	}
+
+	switch {
+	case e.Prelude != nil:
+		if e.Prelude.IsPreview {
+			return "Previewing changes..."
+		}
+		return "Performing changes..."
+	case e.Stdout != nil:
+		return strings.TrimRight(colors.Never.Colorize(e.Stdout.Message), "\n")
+	case e.Diagnostic != nil:
+		if e.Diagnostic.Ephemeral || e.Diagnostic.Severity == "debug" {
+			return ""
+		}
+		msg := strings.TrimRight(colors.Never.Colorize(e.Diagnostic.Message), "\n")
+		if e.Diagnostic.URN != "" {
+			msg = fmt.Sprintf("%s: %s", e.Diagnostic.URN.Name(), msg)
+		}
+		return fmt.Sprintf("%s: %s", e.Diagnostic.Severity, msg)
+	case e.ResourcePre != nil:
+		return step(e.ResourcePre.Metadata, "...")
+	case e.ResourceOutputs != nil:
+		return step(e.ResourceOutputs.Metadata, " done")
+	case e.ResourceOperationFailed != nil:
+		return step(e.ResourceOperationFailed.Metadata, " failed")
+	case e.Summary != nil:
+		var changes []string
+		for _, op := range deploy.StepOps {
+			if c := e.Summary.ResourceChanges[string(op)]; c > 0 && op != deploy.OpSame {
+				changes = append(changes, fmt.Sprintf("%d %s", c, op))
+			}
+		}
+		summary := "no changes"
+		if len(changes) > 0 {
+			summary = strings.Join(changes, ", ")
+		}
+		return fmt.Sprintf("Finished in %ds: %s", e.Summary.DurationSeconds, summary)
+	default:
+		return ""
+	}
+}
+
+var dashboardTemplates = template.Must(template.New("dashboard").Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+pre { margin: 0; }
+ul.tree { list-style: none; padding-left: 1.5em; }
+#progress { background: #f4f4f4; padding: 0.5em; max-height: 30em; overflow: auto; }
+</style>
+</head>
+<body>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "stacks"}}{{template "header" "Pulumi dashboard"}}
+<h1>Stacks in {{.Backend}}</h1>
+{{if .Stacks}}<table>
+<tr><th>Stack</th><th>Resources</th><th>Last updated</th></tr>
+{{range .Stacks}}<tr><td><a href="/stack?name={{.Name}}">{{.Name}}</a></td><td>{{.Resources}}</td>
+<td>{{if .Updated}}{{.Updated}}{{else}}n/a{{end}}</td></tr>
+{{end}}</table>{{else}}<p>No stacks.</p>{{end}}
+{{template "footer"}}{{end}}
+
+{{define "tree"}}<ul class="tree">
+{{range .}}<li><b>{{.Name}}</b> <small>{{.Type}}</small>{{if .ID}} <small>id {{.ID}}</small>{{end}}
+{{- if .Protect}} (protected){{end}}{{if .Delete}} (pending deletion){{end}}
+{{if or .Inputs .Outputs}}<details><summary><small>{{.URN}}</small></summary>
+{{if .Inputs}}<p>Inputs:</p><pre>{{.Inputs}}</pre>{{end}}
+{{if .Outputs}}<p>Outputs:</p><pre>{{.Outputs}}</pre>{{end}}</details>{{else}}<br><small>{{.URN}}</small>{{end}}
+{{if .Children}}{{template "tree" .Children}}{{end}}</li>
+{{end}}</ul>{{end}}
+
+{{define "stack"}}{{template "header" (printf "Stack %s" .Stack)}}
+<p><a href="/">All stacks</a></p>
+<h1>Stack {{.Stack}}</h1>
+
+{{if .Live}}<h2>Latest update</h2>
+<p id="status">Loading...</p>
+<pre id="progress"></pre>
+<script>
+(function() {
+	var stack = {{.Stack}};
+	var status = document.getElementById("status");
+	var progress = document.getElementById("progress");
+	var started = -1, from = 0, running = false;
+	function poll() {
+		var req = new XMLHttpRequest();
+		req.open("GET", "/events?stack=" + encodeURIComponent(stack) + "&from=" + from + "&started=" + started);
+		req.onload = function() {
+			if (req.status !== 200) {
+				status.textContent = req.responseText;
+				setTimeout(poll, 5000);
+				return;
+			}
+			var res = JSON.parse(req.responseText);
+			if (res.reset) {
+				progress.textContent = "";
+			}
+			res.lines.forEach(function(line) { progress.textContent += line + "\n"; });
+			started = res.started;
+			from = res.next;
+			if (running && res.done) {
+				// Show the state that the update left the stack in.
+				location.reload();
+				return;
+			}
+			running = !res.done;
+			status.textContent = running ? "An update is in progress." : "No update is in progress.";
+			setTimeout(poll, 1000);
+		};
+		req.onerror = function() { setTimeout(poll, 5000); };
+		req.send();
+	}
+	poll();
+})();
+</script>
+{{end}}
+
+<h2>Outputs</h2>
+{{if .Outputs}}<table>
+<tr><th>Output</th><th>Value</th></tr>
+{{range .Outputs}}<tr><td>{{.Name}}</td><td><pre>{{.Value}}</pre></td></tr>
+{{end}}</table>{{else}}<p>No outputs.</p>{{end}}
+
+<h2>Resources</h2>
+{{if .Resources}}{{template "tree" .Resources}}{{else}}<p>No resources.</p>{{end}}
+
+<h2>Updates</h2>
+{{$stack := .Stack}}{{if .Updates}}<table>
+<tr><th>Version</th><th>Kind</th><th>Result</th><th>Started</th><th>Changes</th><th>Message</th></tr>
+{{range .Updates}}<tr><td><a href="/update?stack={{$stack}}&version={{.Version}}">{{.Version}}</a></td>
+<td>{{.Kind}}</td><td>{{.Result}}</td><td>{{.Started}}</td><td>{{.Changes}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>{{else}}<p>No updates.</p>{{end}}
+{{template "footer"}}{{end}}
+
+{{define "update"}}{{template "header" (printf "Stack %s, update %d" .Stack .Update.Version)}}
+<p><a href="/">All stacks</a> / <a href="/stack?name={{.Stack}}">{{.Stack}}</a></p>
+<h1>Update {{.Update.Version}} of stack {{.Stack}}</h1>
+<p>{{.Update.Kind}} {{.Update.Result}}{{if .Update.Started}}, started {{.Update.Started}}{{end}}.
+{{if .Update.Message}}<br>{{.Update.Message}}{{end}}</p>
+
+<h2>Changes</h2>
+{{if .Changes}}<table>
+<tr><th>Operation</th><th>Name</th><th>Type</th><th>Differences</th></tr>
+{{range .Changes}}<tr><td>{{.Op}}</td><td>{{.Name}}<br><small>{{.URN}}</small></td><td>{{.Type}}</td>
+<td>{{if .Details}}<pre>{{.Details}}</pre>{{end}}</td></tr>
+{{end}}</table>{{else}}<p>No resources changed.</p>{{end}}
+<p>{{.Same}} resources were unchanged.</p>
+{{template "footer"}}{{end}}
+`))
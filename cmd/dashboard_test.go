@@ -0,0 +1,258 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+type dashboardTestRef tokens.QName
+
+func (r dashboardTestRef) String() string          { return string(r) }
+func (r dashboardTestRef) StackName() tokens.QName { return tokens.QName(r) }
+
+type dashboardTestStack struct {
+	backend.Stack
+	b    *dashboardTestBackend
+	snap *deploy.Snapshot
+}
+
+func (s *dashboardTestStack) Name() backend.StackReference { return dashboardTestRef("dev") }
+func (s *dashboardTestStack) Backend() backend.Backend     { return s.b }
+
+func (s *dashboardTestStack) Snapshot(ctx context.Context) (*deploy.Snapshot, error) {
+	return s.snap, nil
+}
+
+// dashboardTestBackend is a backend with a single stack, "dev", that implements only what the dashboard needs.
+type dashboardTestBackend struct {
+	backend.Backend
+	versions []*deploy.Snapshot
+	events   []apitype.EngineEvent
+	done     bool
+}
+
+func (b *dashboardTestBackend) Name() string { return "test" }
+
+func (b *dashboardTestBackend) Capabilities() backend.Capabilities {
+	return backend.Capabilities{History: true, UpdateEvents: true}
+}
+
+func (b *dashboardTestBackend) ParseStackReference(s string) (backend.StackReference, error) {
+	return dashboardTestRef(s), nil
+}
+
+func (b *dashboardTestBackend) GetStack(ctx context.Context, ref backend.StackReference) (backend.Stack, error) {
+	if ref.String() != "dev" {
+		return nil, nil
+	}
+	return &dashboardTestStack{b: b, snap: b.versions[len(b.versions)-1]}, nil
+}
+
+func (b *dashboardTestBackend) ListStacks(ctx context.Context, _ *tokens.PackageName) ([]backend.Stack, error) {
+	s, err := b.GetStack(ctx, dashboardTestRef("dev"))
+	return []backend.Stack{s}, err
+}
+
+func (b *dashboardTestBackend) GetHistory(ctx context.Context, ref backend.StackReference) ([]backend.UpdateInfo,
+	error) {
+
+	var updates []backend.UpdateInfo
+	for i := len(b.versions); i > 0; i-- {
+		updates = append(updates, backend.UpdateInfo{
+			Version: i,
+			Kind:    apitype.UpdateUpdate,
+			Result:  backend.SucceededResult,
+		})
+	}
+	return updates, nil
+}
+
+func (b *dashboardTestBackend) GetHistoricalDeployment(ctx context.Context, ref backend.StackReference,
+	version int) (*apitype.UntypedDeployment, error) {
+
+	bytes, err := json.Marshal(stack.SerializeDeployment(b.versions[version-1]))
+	if err != nil {
+		return nil, err
+	}
+	return &apitype.UntypedDeployment{Version: apitype.DeploymentSchemaVersionCurrent, Deployment: bytes}, nil
+}
+
+func (b *dashboardTestBackend) GetLatestUpdateEvents(ctx context.Context,
+	ref backend.StackReference) ([]apitype.EngineEvent, bool, error) {
+
+	return b.events, b.done, nil
+}
+
+func newDashboardTestState(t resource.URN, parent resource.URN, inputs map[string]interface{}) *resource.State {
+	return resource.NewState(t.Type(), t, true, false, "id-"+resource.ID(t.Name()),
+		resource.NewPropertyMapFromMap(inputs), resource.PropertyMap{}, parent, false, false, nil, nil, "")
+}
+
+func TestDashboardAuthorization(t *testing.T) {
+	b := &dashboardTestBackend{versions: []*deploy.Snapshot{deploy.NewSnapshot(deploy.Manifest{}, nil, nil)}}
+	handler := newDashboardHandler(b, "secret", []string{"127.0.0.1:1234", "localhost:1234"})
+	serve := func(host, path string, cookie string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Host = host
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: dashboardTokenCookie, Value: cookie})
+		}
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The token in the dashboard's URL is exchanged for a cookie.
+	rec := serve("localhost:1234", "/stack?name=dev&token=secret", "")
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/stack?name=dev", rec.Header().Get("Location"))
+	if cookies := rec.Result().Cookies(); assert.Len(t, cookies, 1) {
+		assert.Equal(t, dashboardTokenCookie, cookies[0].Name)
+		assert.Equal(t, "secret", cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+	}
+	assert.Equal(t, http.StatusOK, serve("localhost:1234", "/", "secret").Code)
+
+	// Requests without the token, or for other hosts, are refused.
+	assert.Equal(t, http.StatusUnauthorized, serve("127.0.0.1:1234", "/", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("127.0.0.1:1234", "/", "guess").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("127.0.0.1:1234", "/?token=guess", "").Code)
+	assert.Equal(t, http.StatusForbidden, serve("attacker.example.com:1234", "/", "secret").Code)
+	assert.Equal(t, http.StatusForbidden, serve("attacker.example.com:1234", "/?token=secret", "").Code)
+}
+
+func TestDashboard(t *testing.T) {
+	stackURN := resource.NewURN("dev", "proj", "", resource.RootStackType, "proj-dev")
+	stackRes := resource.NewState(resource.RootStackType, stackURN, false, false, "", resource.PropertyMap{},
+		resource.NewPropertyMapFromMap(map[string]interface{}{"url": "http://example.com"}), "", false, false, nil,
+		nil, "")
+	urnA := resource.NewURN("dev", "proj", resource.RootStackType, "pkgA:m:typA", "resA")
+	urnB := resource.NewURN("dev", "proj", "pkgA:m:typA", "pkgA:m:typB", "resB")
+	urnC := resource.NewURN("dev", "proj", resource.RootStackType, "pkgA:m:typC", "resC")
+
+	v1 := deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{
+		stackRes,
+		newDashboardTestState(urnA, stackURN, map[string]interface{}{"size": "small"}),
+		newDashboardTestState(urnC, stackURN, nil),
+	}, nil)
+	v2 := deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{
+		stackRes,
+		newDashboardTestState(urnA, stackURN, map[string]interface{}{"size": "large"}),
+		newDashboardTestState(urnB, urnA, nil),
+	}, nil)
+
+	b := &dashboardTestBackend{versions: []*deploy.Snapshot{v1, v2}}
+	handler := newDashboardHandler(b, "secret", []string{"127.0.0.1:1234", "localhost:1234"})
+	get := func(path string) (int, string) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Host = "127.0.0.1:1234"
+		req.AddCookie(&http.Cookie{Name: dashboardTokenCookie, Value: "secret"})
+		handler.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	// The resources of a stack are shown as a tree.
+	tree := dashboardResourceTree(v2)
+	if assert.Len(t, tree, 1) && assert.Len(t, tree[0].Children, 1) && assert.Len(t, tree[0].Children[0].Children, 1) {
+		assert.Equal(t, urnB, tree[0].Children[0].Children[0].URN)
+	}
+
+	status, body := get("/")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `<a href="/stack?name=dev">dev</a></td><td>3</td>`)
+
+	status, body = get("/stack?name=dev")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "<td>url</td><td><pre>http://example.com</pre></td>")
+	assert.Contains(t, body, "<b>resB</b>")
+	assert.Contains(t, body, `<a href="/update?stack=dev&version=2">2</a>`)
+	assert.Contains(t, body, `id="progress"`)
+
+	status, _ = get("/stack?name=prod")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// An update's page shows the resources that it created, changed, and deleted.
+	status, body = get("/update?stack=dev&version=2")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "<td>create</td><td>resB")
+	assert.Contains(t, body, "<td>update</td><td>resA")
+	assert.Contains(t, body, "<td>delete</td><td>resC")
+	assert.Contains(t, body, "small")
+	assert.Contains(t, body, "large")
+	assert.Contains(t, body, "<p>1 resources were unchanged.</p>")
+
+	status, _ = get("/update?stack=dev&version=3")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// The progress of the latest update is followed from its events.
+	b.events = []apitype.EngineEvent{
+		{Sequence: 0, Timestamp: 100, Prelude: &apitype.PreludeEvent{}},
+		{Sequence: 1, Timestamp: 100, ResourcePre: &apitype.ResourcePreEvent{
+			Metadata: apitype.StepEventMetadata{Op: "create", URN: urnB, Type: "pkgA:m:typB"}}},
+		{Sequence: 2, Timestamp: 100, ResourcePre: &apitype.ResourcePreEvent{
+			Metadata: apitype.StepEventMetadata{Op: "same", URN: urnA, Type: "pkgA:m:typA"}}},
+	}
+	events := func(query string) dashboardEvents {
+		status, body := get("/events?stack=dev&" + query)
+		assert.Equal(t, http.StatusOK, status)
+		var result dashboardEvents
+		assert.NoError(t, json.Unmarshal([]byte(body), &result))
+		return result
+	}
+	assert.Equal(t, dashboardEvents{
+		Started: 100,
+		Reset:   true,
+		Lines:   []string{"Performing changes...", "create pkgA:m:typB resB..."},
+		Next:    3,
+	}, events("from=0&started=-1"))
+
+	b.events = append(b.events,
+		apitype.EngineEvent{Sequence: 3, Timestamp: 101, Diagnostic: &apitype.DiagnosticEvent{
+			URN: urnB, Message: "<{%fg 1%}>oops<{%reset%}>\n", Severity: "error"}},
+		apitype.EngineEvent{Sequence: 4, Timestamp: 101, Summary: &apitype.SummaryEvent{
+			DurationSeconds: 1, ResourceChanges: map[string]int{"create": 1, "same": 2}}},
+		apitype.EngineEvent{Sequence: 5, Timestamp: 101, Cancel: &apitype.CancelEvent{}})
+	b.done = true
+	assert.Equal(t, dashboardEvents{
+		Started: 100,
+		Lines:   []string{"error: resB: oops", "Finished in 1s: 1 create"},
+		Next:    6,
+		Done:    true,
+	}, events("from=3&started=100"))
+
+	// A new update starts its events over.
+	b.events, b.done = []apitype.EngineEvent{{Sequence: 0, Timestamp: 200, Prelude: &apitype.PreludeEvent{}}}, false
+	assert.Equal(t, dashboardEvents{
+		Started: 200,
+		Reset:   true,
+		Lines:   []string{"Performing changes..."},
+		Next:    1,
+	}, events("from=6&started=100"))
+}
@@ -0,0 +1,93 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+// stepDebugger pauses an update before each step, showing the step's pending changes and asking whether to apply it,
+// skip it, or abort the update.
+type stepDebugger struct {
+	lock     sync.Mutex
+	opts     backend.DisplayOptions
+	finished bool // true once the user has chosen to run the remaining steps without pausing.
+}
+
+// setStepDebugging configures an update to pause before each of its steps.  Steps are applied one at a time, and the
+// progress display is not redrawn in place, so that the prompts are not disturbed.
+func setStepDebugging(opts *backend.UpdateOptions) {
+	debugger := &stepDebugger{opts: opts.Display}
+	opts.Engine.StepHook = debugger.step
+	opts.Engine.Parallel = 1
+	opts.Display.IsInteractive = false
+}
+
+func (d *stepDebugger) step(step engine.StepEventMetadata) deploy.StepAction {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.finished {
+		return deploy.StepContinue
+	}
+
+	fmt.Println()
+	fmt.Print(d.opts.Color.Colorize(fmt.Sprintf("%s%s %s%s\n", step.Op.Prefix(), step.Op, step.URN, colors.Reset)))
+	for _, k := range step.Keys {
+		reason := step.Reasons[k]
+		if reason == "" {
+			reason = "changed"
+		}
+		fmt.Printf("    [replace because `%s` %s]\n", k, reason)
+	}
+	fmt.Print(d.opts.Color.Colorize(
+		engine.GetResourcePropertiesDetails(step, 0, false /*planning*/, false /*summary*/, d.opts.Debug)))
+
+	choices := "[c]ontinue, [r]un remaining steps, [a]bort"
+	canSkip := deploy.CanSkip(step.Op)
+	if canSkip {
+		choices = "[c]ontinue, [s]kip, [r]un remaining steps, [a]bort"
+	}
+	for {
+		answer, err := cmdutil.ReadConsole(choices)
+		if err != nil {
+			// If we can no longer ask, it is not safe to go on.
+			return deploy.StepAbort
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "c", "continue", "":
+			return deploy.StepContinue
+		case "s", "skip":
+			if canSkip {
+				return deploy.StepSkip
+			}
+			fmt.Printf("A %s step cannot be skipped, as later steps depend upon it.\n", step.Op)
+		case "r", "run":
+			d.finished = true
+			return deploy.StepContinue
+		case "a", "abort":
+			return deploy.StepAbort
+		}
+	}
+}
@@ -0,0 +1,414 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/fsutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// defaultDeployParallel is the number of stacks that `pulumi deploy` operates on at once by default.
+const defaultDeployParallel = 4
+
+func newDeployCmd() *cobra.Command {
+	var all bool
+	var manifestPath string
+	var parallel int
+	var preview bool
+	var destroy bool
+	var yes bool
+
+	var cmd = &cobra.Command{
+		Use:   "deploy [<name>...]",
+		Short: "Deploy many stacks together, in dependency order",
+		Long: "Deploy many stacks together, in dependency order\n" +
+			"\n" +
+			"This command updates a set of stacks, possibly of many projects, described by a deployment\n" +
+			"manifest.  The manifest, " + workspace.DeployManifestFile + ", is found by searching upwards from the\n" +
+			"current directory unless --manifest is given.  It names each stack, the directory of its project,\n" +
+			"and the stacks whose outputs it reads through stack references:\n" +
+			"\n" +
+			"    stacks:\n" +
+			"      network:\n" +
+			"        project: infra/network\n" +
+			"        stack: dev\n" +
+			"      api:\n" +
+			"        project: services/api\n" +
+			"        stack: dev\n" +
+			"        dependsOn: [network]\n" +
+			"\n" +
+			"A stack is only updated once all of the stacks it depends on have been, and stacks that do not\n" +
+			"depend on each other are updated in parallel.  If a stack fails, the stacks that depend on it are\n" +
+			"skipped.  Pass --all to deploy every stack of the manifest, or the names of the stacks to deploy\n" +
+			"along with their dependencies.\n" +
+			"\n" +
+			"With --preview, the stacks are previewed instead.  With --destroy, their resources are destroyed,\n" +
+			"in the reverse order: a stack is only destroyed once every stack that depends on it has been.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return errors.New("pass either --all or the names of the stacks to deploy")
+			}
+			if preview && destroy {
+				return errors.New("only one of --preview and --destroy may be given")
+			}
+			if parallel < 1 {
+				return errors.New("--parallel must be at least 1")
+			}
+
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			manifest, err := loadDeployManifest(manifestPath)
+			if err != nil {
+				return err
+			}
+			if all {
+				for name := range manifest.Stacks {
+					args = append(args, name)
+				}
+			}
+			order, waits, err := planDeployment(manifest, args, destroy)
+			if err != nil {
+				return err
+			}
+
+			op := "up"
+			switch {
+			case preview:
+				op = "preview"
+			case destroy:
+				op = "destroy"
+			}
+
+			// Each stack is run non-interactively, so the user must approve all of them up front.
+			if !preview && !yes {
+				if !isInteractive(false) {
+					return errors.New("--yes must be passed in non-interactive mode")
+				}
+				fmt.Println(opts.Color.Colorize(fmt.Sprintf("%sThis will run `pulumi %s` on %d stack(s):%s",
+					colors.SpecAttention, op, len(order), colors.Reset)))
+				for _, name := range order {
+					fmt.Printf("    %s\n", name)
+				}
+				if !confirmPrompt("", "yes", opts) {
+					return errors.New("confirmation declined")
+				}
+			}
+
+			results := runDeploymentStacks(manifest, order, waits, parallel, op)
+			return printDeploymentResults(manifest, order, results)
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&all, "all", "a", false,
+		"Deploy every stack of the manifest")
+	cmd.PersistentFlags().StringVarP(
+		&manifestPath, "manifest", "m", "",
+		"The deployment manifest to use. Defaults to the nearest "+workspace.DeployManifestFile)
+	cmd.PersistentFlags().IntVarP(
+		&parallel, "parallel", "p", defaultDeployParallel,
+		"The number of stacks to operate on at once")
+	cmd.PersistentFlags().BoolVar(
+		&preview, "preview", false,
+		"Preview the stacks rather than updating them")
+	cmd.PersistentFlags().BoolVar(
+		&destroy, "destroy", false,
+		"Destroy the resources of the stacks rather than updating them")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Automatically approve and perform the operation on every stack")
+
+	return cmd
+}
+
+// loadDeployManifest loads the deployment manifest at the given path or, if none is given, the nearest one found by
+// searching upwards from the current directory.
+func loadDeployManifest(path string) (*workspace.DeployManifest, error) {
+	if path == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		path, err = fsutil.WalkUp(cwd, func(s string) bool {
+			return filepath.Base(s) == workspace.DeployManifestFile
+		}, nil)
+		if err != nil {
+			return nil, err
+		} else if path == "" {
+			return nil, errors.Errorf("no %s found (searching upwards from %s)", workspace.DeployManifestFile, cwd)
+		}
+	}
+	return workspace.LoadDeployManifest(path)
+}
+
+// planDeployment returns the stacks to operate on, in the order in which to start them, along with the stacks that
+// each must wait for.  The named stacks are accompanied by the stacks they depend on or, when destroying, the stacks
+// that depend on them, and so must be destroyed first.
+func planDeployment(manifest *workspace.DeployManifest, names []string,
+	destroy bool) ([]string, map[string][]string, error) {
+
+	order, err := manifest.Order()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Work out which stacks each one must wait for.
+	waits := make(map[string][]string)
+	for _, name := range order {
+		for _, dep := range manifest.Stacks[name].DependsOn {
+			if destroy {
+				waits[dep] = append(waits[dep], name)
+			} else {
+				waits[name] = append(waits[name], dep)
+			}
+		}
+	}
+	if destroy {
+		for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+			order[i], order[j] = order[j], order[i]
+		}
+	}
+
+	// Select the named stacks, and everything that they must wait for.
+	selected := make(map[string]bool)
+	var selectStack func(name string)
+	selectStack = func(name string) {
+		if selected[name] {
+			return
+		}
+		selected[name] = true
+		for _, wait := range waits[name] {
+			selectStack(wait)
+		}
+	}
+	for _, name := range names {
+		if _, has := manifest.Stacks[name]; !has {
+			return nil, nil, errors.Errorf("the manifest does not describe a stack named '%s'", name)
+		}
+		selectStack(name)
+	}
+
+	var plan []string
+	for _, name := range order {
+		if selected[name] {
+			plan = append(plan, name)
+		}
+	}
+	return plan, waits, nil
+}
+
+// deployResult is the outcome of operating on one of the stacks of a deployment.
+type deployResult struct {
+	name     string        // the name of the stack.
+	err      error         // the error the operation failed with, if any.
+	skipped  bool          // true if the stack was skipped because a stack it waited for did not succeed.
+	duration time.Duration // how long the operation took.
+}
+
+// runDeployment runs the given function on each stack, starting them in the given order but no sooner than all of the
+// stacks they wait for have succeeded, with at most parallel of them running at once.  A stack is skipped if any that
+// it waits for fails or is skipped itself.  The order must place each stack after those it waits for.
+func runDeployment(order []string, waits map[string][]string, parallel int,
+	run func(name string) error) map[string]*deployResult {
+
+	results := make(map[string]*deployResult)
+	started := make(map[string]bool)
+	done := make(chan *deployResult)
+	running := 0
+	for {
+		for _, name := range order {
+			if started[name] {
+				continue
+			}
+			ready, blocked := true, false
+			for _, wait := range waits[name] {
+				r, has := results[wait]
+				if !has {
+					ready = false
+					break
+				}
+				blocked = blocked || r.err != nil || r.skipped
+			}
+			if !ready || !blocked && running >= parallel {
+				continue
+			}
+
+			started[name] = true
+			if blocked {
+				results[name] = &deployResult{name: name, skipped: true}
+				continue
+			}
+			running++
+			go func(name string) {
+				start := time.Now()
+				err := run(name)
+				done <- &deployResult{name: name, err: err, duration: time.Since(start)}
+			}(name)
+		}
+
+		if running == 0 {
+			return results
+		}
+		r := <-done
+		running--
+		results[r.name] = r
+	}
+}
+
+// runDeploymentStacks runs a Pulumi operation on the given stacks of a manifest, as runDeployment does, with the output
+// of each prefixed by the stack's name.
+func runDeploymentStacks(manifest *workspace.DeployManifest, order []string, waits map[string][]string, parallel int,
+	op string) map[string]*deployResult {
+
+	var lock sync.Mutex
+	return runDeployment(order, waits, parallel, func(name string) error {
+		prefix := fmt.Sprintf("[%s] ", name)
+		stdout := &linePrefixWriter{prefix: prefix, w: os.Stdout, lock: &lock}
+		stderr := &linePrefixWriter{prefix: prefix, w: os.Stderr, lock: &lock}
+		defer func() {
+			contract.IgnoreError(stdout.Flush())
+			contract.IgnoreError(stderr.Flush())
+		}()
+		return runDeployStack(manifest.Stacks[name], op, stdout, stderr)
+	})
+}
+
+// runDeployStack runs a Pulumi operation on one of the stacks of a deployment, in a process of its own so that it may
+// run alongside the others.
+func runDeployStack(s *workspace.DeployManifestStack, op string, stdout, stderr io.Writer) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"--cwd", s.Project, op, "--stack", s.Stack, "--non-interactive"}
+	if op != "preview" {
+		args = append(args, "--yes")
+	}
+	// nolint: gas, intentionally running the CLI itself.
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if projectCacheDirSet {
+		// The cache directory was chosen for the project in the current directory; the stack's project gets its own.
+		for _, v := range os.Environ() {
+			if !strings.HasPrefix(v, workspace.ProjectCacheDirEnvVar+"=") {
+				cmd.Env = append(cmd.Env, v)
+			}
+		}
+	}
+	return cmd.Run()
+}
+
+// printDeploymentResults prints the outcome of every stack of a deployment, returning an error if any of them did not
+// succeed.
+func printDeploymentResults(manifest *workspace.DeployManifest, order []string,
+	results map[string]*deployResult) error {
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPROJECT\tSTACK\tRESULT\tDURATION")
+	failed := 0
+	for _, name := range order {
+		s, r := manifest.Stacks[name], results[name]
+		project, err := filepath.Rel(cwd, s.Project)
+		if err != nil {
+			project = s.Project
+		}
+
+		result := "succeeded"
+		switch {
+		case r.skipped:
+			result = "skipped"
+			failed++
+		case r.err != nil:
+			result = "failed"
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, project, s.Stack, result, r.duration.Round(time.Second))
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d of %d stacks did not succeed", failed, len(order))
+	}
+	return nil
+}
+
+// linePrefixWriter writes whole lines to an underlying writer, each preceded by a prefix, so that the output of many
+// concurrent processes can share a terminal and still be told apart.
+type linePrefixWriter struct {
+	prefix string      // the prefix to write before each line.
+	w      io.Writer   // the writer to write lines to.
+	lock   *sync.Mutex // a lock, shared by every writer of w, held while writing a line.
+	buf    []byte      // any partial line that has yet to be written.
+}
+
+func (w *linePrefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := w.writeLine(w.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush writes any partial line that remains.
+func (w *linePrefixWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := append(w.buf, '\n')
+	w.buf = nil
+	return w.writeLine(line)
+}
+
+func (w *linePrefixWriter) writeLine(line []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, err := fmt.Fprintf(w.w, "%s%s", w.prefix, line)
+	return err
+}
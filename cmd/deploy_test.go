@@ -0,0 +1,96 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newTestDeployManifest() *workspace.DeployManifest {
+	return &workspace.DeployManifest{
+		Stacks: map[string]*workspace.DeployManifestStack{
+			"network":  {Project: "infra/network", Stack: "dev"},
+			"database": {Project: "infra/database", Stack: "dev", DependsOn: []string{"network"}},
+			"api":      {Project: "services/api", Stack: "dev", DependsOn: []string{"database"}},
+			"dns":      {Project: "infra/dns", Stack: "dev"},
+		},
+	}
+}
+
+func TestPlanDeployment(t *testing.T) {
+	manifest := newTestDeployManifest()
+
+	// Deploying a stack deploys the stacks it depends on first.
+	order, waits, err := planDeployment(manifest, []string{"api"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"network", "database", "api"}, order)
+	assert.Equal(t, []string{"database"}, waits["api"])
+
+	// Destroying a stack destroys the stacks that depend on it first.
+	order, waits, err = planDeployment(manifest, []string{"database"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api", "database"}, order)
+	assert.Equal(t, []string{"api"}, waits["database"])
+
+	_, _, err = planDeployment(manifest, []string{"missing"}, false)
+	assert.Error(t, err)
+}
+
+func TestRunDeployment(t *testing.T) {
+	manifest := newTestDeployManifest()
+	order, waits, err := planDeployment(manifest, []string{"api", "dns"}, false)
+	assert.NoError(t, err)
+
+	var lock sync.Mutex
+	var ran []string
+	results := runDeployment(order, waits, 2, func(name string) error {
+		lock.Lock()
+		defer lock.Unlock()
+		ran = append(ran, name)
+		if name == "database" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	// The stack that depends on the failed one is skipped, but those that do not are still run.
+	assert.Len(t, results, 4)
+	assert.NoError(t, results["network"].err)
+	assert.Error(t, results["database"].err)
+	assert.True(t, results["api"].skipped)
+	assert.NoError(t, results["dns"].err)
+	assert.NotContains(t, ran, "api")
+	assert.Len(t, ran, 3)
+}
+
+func TestLinePrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &linePrefixWriter{prefix: "[a] ", w: &buf, lock: &sync.Mutex{}}
+
+	_, err := w.Write([]byte("one\ntw"))
+	assert.NoError(t, err)
+	assert.Equal(t, "[a] one\n", buf.String())
+	_, err = w.Write([]byte("o\nthree"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "[a] one\n[a] two\n[a] three\n", buf.String())
+}
@@ -16,29 +16,41 @@ package cmd
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
 )
 
 func newDestroyCmd() *cobra.Command {
 	var debug bool
+	var eventLog string
 	var stack string
 
 	var message string
+	var metadata []string
+	var overrideGuard string
 
 	// Flags for engine.UpdateOptions.
 	var analyzers []string
 	var diffDisplay bool
 	var parallel int
+	var stepDecider string
 	var refresh bool
 	var showConfig bool
+	var noDedupe bool
+	var slowStepThreshold time.Duration
+	var showReads bool
 	var showReplacementSteps bool
 	var showSames bool
+	var showSecrets bool
+	var suppressSamesFlag bool
 	var nonInteractive bool
 	var skipPreview bool
 	var yes bool
@@ -69,13 +81,23 @@ func newDestroyCmd() *cobra.Command {
 
 			opts.Display = backend.DisplayOptions{
 				Color:                cmdutil.GetGlobalColorization(),
-				ShowConfig:           showConfig,
-				ShowReplacementSteps: showReplacementSteps,
-				ShowSameResources:    showSames,
+				ShowConfig:           displayFlag(cmd, "show-config", showConfig),
+				ShowReads:            displayFlag(cmd, "show-reads", showReads),
+				ShowReplacementSteps: displayFlag(cmd, "show-replacement-steps", showReplacementSteps),
+				ShowSameResources:    displayFlag(cmd, "show-sames", showSames),
+				SuppressSames:        suppressSames(cmd, suppressSamesFlag),
+				NoDedupe:             noDedupe,
+				SlowStepThreshold:    slowStepThreshold,
 				IsInteractive:        interactive,
 				DiffDisplay:          diffDisplay,
 				Debug:                debug,
 			}
+			if eventLog != "" {
+				if opts.Display.EventLog, err = backend.NewEventLog(eventLog); err != nil {
+					return err
+				}
+				defer contract.IgnoreClose(opts.Display.EventLog)
+			}
 
 			s, err := requireStack(stack, false, opts.Display, true /*setCurrent*/)
 			if err != nil {
@@ -90,15 +112,24 @@ func newDestroyCmd() *cobra.Command {
 			if err != nil {
 				return errors.Wrap(err, "gathering environment metadata")
 			}
+			if err = addUserMetadataToEnvironment(metadata, m.Environment); err != nil {
+				return err
+			}
 
 			opts.Engine = engine.UpdateOptions{
-				Analyzers: analyzers,
-				Parallel:  parallel,
-				Debug:     debug,
-				Refresh:   refresh,
+				Analyzers:       analyzers,
+				Parallel:        parallel,
+				Debug:           debug,
+				ShowSecrets:     showSecrets,
+				Refresh:         refresh,
+				ProviderOptions: providerOptions,
 			}
+			setGuardOptions(m, overrideGuard, &opts.Engine)
+			opts.Engine.StepDecider = newStepDecider(stepDecider)
 
-			_, err = s.Destroy(commandContext(), proj, root, m, opts, cancellationScopes)
+			tel := beginOperationTelemetry(apitype.DestroyUpdate, &opts.Engine)
+			changes, err := s.Destroy(commandContext(), proj, root, m, opts, cancellationScopes)
+			tel.report(changes, err)
 			if err == context.Canceled {
 				return errors.New("destroy cancelled")
 			}
@@ -109,12 +140,22 @@ func newDestroyCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVar(
+		&eventLog, "event-log", "",
+		"Log the engine's events as JSON to the given file, including each resource's properties before and after "+
+			"every step")
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
 		"The name of the stack to operate on. Defaults to the current stack")
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
 		"Optional message to associate with the destroy operation")
+	cmd.PersistentFlags().StringArrayVar(
+		&metadata, "metadata", []string{},
+		"Attach a key=value pair to the update, e.g. to link it to a ticket; may be given more than once")
+	cmd.PersistentFlags().StringVar(
+		&overrideGuard, "override-guard", "",
+		"Proceed even if the stack's guard forbids this operation, giving the reason for doing so")
 
 	// Flags for engine.UpdateOptions.
 	cmd.PersistentFlags().StringSliceVar(
@@ -125,21 +166,40 @@ func newDestroyCmd() *cobra.Command {
 		"Display operation as a rich diff showing the overall change")
 	cmd.PersistentFlags().BoolVar(
 		&nonInteractive, "non-interactive", false, "Disable interactive mode")
+	cmd.PersistentFlags().StringVar(
+		&stepDecider, "step-decider", "",
+		"Consult the given executable about the steps chosen, which may e.g. replace rather than update a resource "+
+			"or deny a deletion")
 	cmd.PersistentFlags().IntVarP(
 		&parallel, "parallel", "p", defaultParallel,
 		"Allow P resource operations to run in parallel at once (<=1 for no parallelism)")
 	cmd.PersistentFlags().BoolVarP(
 		&refresh, "refresh", "r", false,
 		"Refresh the state of the stack's resources before this update")
+	cmd.PersistentFlags().BoolVar(
+		&noDedupe, "no-dedupe", false,
+		"Show every warning, rather than only the first of those that are repeated along with a count")
+	cmd.PersistentFlags().DurationVar(
+		&slowStepThreshold, "slow-step-threshold", backend.DefaultSlowStepThreshold,
+		"Warn about resource operations still running after this long (0 to disable)")
 	cmd.PersistentFlags().BoolVar(
 		&showConfig, "show-config", false,
 		"Show configuration keys and variables")
+	cmd.PersistentFlags().BoolVar(
+		&showReads, "show-reads", false,
+		"Show resources that are being read in, alongside those being managed directly in the stack")
 	cmd.PersistentFlags().BoolVar(
 		&showReplacementSteps, "show-replacement-steps", false,
 		"Show detailed resource replacement creates and deletes instead of a single step")
 	cmd.PersistentFlags().BoolVar(
 		&showSames, "show-sames", false,
 		"Show resources that don't need to be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&showSecrets, "show-secrets", false,
+		"Show the values of secret configuration in resources' properties, rather than redacting them")
+	cmd.PersistentFlags().BoolVar(
+		&suppressSamesFlag, "suppress-sames", true,
+		"Show only a count of unchanged resources in progress output (the default unless -v is given)")
 	cmd.PersistentFlags().BoolVar(
 		&skipPreview, "skip-preview", false,
 		"Do not perform a preview before performing the destroy")
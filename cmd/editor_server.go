@@ -0,0 +1,340 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/version"
+)
+
+func newEditorServerCmd() *cobra.Command {
+	var port int
+	var parallel int
+
+	cmd := &cobra.Command{
+		Use:   "editor-server",
+		Args:  cmdutil.NoArgs,
+		Short: "Serve previews of Pulumi programs to editors",
+		Long: "Serve previews of Pulumi programs to editors.\n" +
+			"\n" +
+			"This command serves a small JSON API on the loopback interface, so that editor plugins can preview\n" +
+			"a program each time that one of its files is saved, and show what would change without running\n" +
+			"the CLI themselves.  Once the server is listening, it writes a single line of JSON to stdout that\n" +
+			"holds its address and the token that each request must present in an `Authorization: token`\n" +
+			"header.  The output of each preview is then written to stdout as it runs.\n" +
+			"\n" +
+			"POST /preview previews a stack.  Its JSON body may name the `stack` to preview, which defaults to\n" +
+			"the selected one, and the `dir` from which to look for the program's project, which defaults to the\n" +
+			"server's working directory.  The response holds the changes that the preview found, both per\n" +
+			"resource and in summary, and its diagnostics.  A preview that fails still responds with its\n" +
+			"diagnostics, along with the error.  Previews run one at a time, and never install plugins.\n" +
+			"\n" +
+			"GET /version returns the version of the CLI.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			tokenBytes := make([]byte, 32)
+			_, err := cryptorand.Read(tokenBytes)
+			contract.AssertNoErrorf(err, "could not get random bytes")
+			token := hex.EncodeToString(tokenBytes)
+
+			l, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+			if err != nil {
+				return errors.Wrap(err, "listening for editor requests")
+			}
+
+			handshake, err := json.Marshal(editorHandshake{Address: "http://" + l.Addr().String(), Token: token})
+			contract.AssertNoError(err)
+			fmt.Println(string(handshake))
+
+			p := &editorPreviewer{parallel: parallel}
+			return http.Serve(l, newEditorHandler(token, p.preview))
+		}),
+	}
+
+	cmd.PersistentFlags().IntVar(
+		&port, "port", 0,
+		"The port on which to serve requests.  Defaults to a free port")
+	cmd.PersistentFlags().IntVarP(
+		&parallel, "parallel", "p", defaultParallel,
+		"Allow P resource operations to run in parallel at once (<=1 for no parallelism)")
+
+	return cmd
+}
+
+// editorHandshake is written to stdout by `pulumi editor-server` once it is ready to serve requests.
+type editorHandshake struct {
+	Address string `json:"address"` // the base URL of the server.
+	Token   string `json:"token"`   // the token that requests must present.
+}
+
+// editorPreviewRequest is the body of a request to preview a stack.
+type editorPreviewRequest struct {
+	Stack string `json:"stack,omitempty"` // the stack to preview; defaults to the selected stack.
+	Dir   string `json:"dir,omitempty"`   // an absolute directory within the program's project.
+}
+
+// editorPreviewResponse describes the outcome of a preview.
+type editorPreviewResponse struct {
+	Stack       string                 `json:"stack,omitempty"`
+	Succeeded   bool                   `json:"succeeded"`
+	Error       string                 `json:"error,omitempty"`
+	Changes     map[string]int         `json:"changes"`         // the number of resources per operation.
+	Outputs     []string               `json:"outputChanges"`   // the names of the outputs that would change.
+	Resources   []editorResourceChange `json:"resources"`       // the resources that would change.
+	Diagnostics []editorDiagnostic     `json:"diagnostics"`     // the preview's errors, warnings, and messages.
+	Duration    int                    `json:"durationSeconds"` // how long the preview took.
+}
+
+// editorResourceChange describes a change that a preview found to a single resource.
+type editorResourceChange struct {
+	Op      string   `json:"op"`
+	URN     string   `json:"urn"`
+	Type    string   `json:"type"`
+	Diffs   []string `json:"diffs,omitempty"`   // the input properties that differ.
+	Replace []string `json:"replace,omitempty"` // the input properties that require a replacement.
+}
+
+// editorDiagnostic is a diagnostic reported during a preview.
+type editorDiagnostic struct {
+	URN      string `json:"urn,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// newEditorHandler returns the handler for the requests of editors, which must present the given token.  Previews are
+// performed by the given function.
+func newEditorHandler(token string,
+	preview func(req editorPreviewRequest) editorPreviewResponse) http.Handler {
+
+	respond := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+	authorized := func(w http.ResponseWriter, r *http.Request, method string) bool {
+		presented := strings.TrimPrefix(r.Header.Get("Authorization"), "token ")
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+			return false
+		}
+		if r.Method != method {
+			http.Error(w, fmt.Sprintf("%s must be used", method), http.StatusMethodNotAllowed)
+			return false
+		}
+		return true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r, "GET") {
+			respond(w, map[string]string{"version": version.Version})
+		}
+	})
+	mux.HandleFunc("/preview", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r, "POST") {
+			return
+		}
+		var req editorPreviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Dir != "" && !filepath.IsAbs(req.Dir) {
+			http.Error(w, "dir must be an absolute path", http.StatusBadRequest)
+			return
+		}
+		respond(w, preview(req))
+	})
+	return mux
+}
+
+// editorPreviewer previews programs for editors, one at a time.
+type editorPreviewer struct {
+	lock     sync.Mutex
+	parallel int
+}
+
+// preview previews the stack named by the request, and describes what it found.
+func (p *editorPreviewer) preview(req editorPreviewRequest) editorPreviewResponse {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var events bytes.Buffer
+	stackName, err := p.previewEvents(req, &events)
+
+	resp := summarizeEditorPreview(&events)
+	resp.Stack, resp.Succeeded = stackName, err == nil
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+// previewEvents previews the stack named by the request, writing its engine events to the given writer, and returns
+// the name of the stack that it previewed.
+func (p *editorPreviewer) previewEvents(req editorPreviewRequest, w io.Writer) (string, error) {
+	// The project and its selected stack are found relative to the working directory, as they are by other commands.
+	if req.Dir != "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		if err = os.Chdir(req.Dir); err != nil {
+			return "", err
+		}
+		defer func() { contract.IgnoreError(os.Chdir(cwd)) }()
+	}
+
+	opts := backend.UpdateOptions{
+		Engine: engine.UpdateOptions{Parallel: p.parallel, ProviderOptions: providerOptions},
+		Display: backend.DisplayOptions{
+			Color:         colors.Never,
+			SuppressSames: true,
+			EventLog:      backend.NewEventLogWriter(nopWriteCloser{w}),
+		},
+	}
+
+	s, err := requireStack(req.Stack, false, opts.Display, false /*setCurrent*/)
+	if err != nil {
+		return "", err
+	}
+	proj, root, err := readProject()
+	if err != nil {
+		return s.Name().String(), err
+	}
+	m, err := getUpdateMetadata("", root)
+	if err != nil {
+		return s.Name().String(), errors.Wrap(err, "gathering environment metadata")
+	}
+	setRandomSeed(&opts.Engine)
+
+	_, err = s.Preview(commandContext(), proj, root, m, opts, cancellationScopes)
+	return s.Name().String(), PrintEngineError(err)
+}
+
+// nopWriteCloser is a writer whose Close method does nothing.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// summarizeEditorPreview describes the changes and diagnostics of a preview from the engine events in the given event
+// log.
+func summarizeEditorPreview(log io.Reader) editorPreviewResponse {
+	resp := editorPreviewResponse{
+		Changes:     map[string]int{},
+		Outputs:     []string{},
+		Resources:   []editorResourceChange{},
+		Diagnostics: []editorDiagnostic{},
+	}
+
+	dec := json.NewDecoder(log)
+	for {
+		var e apitype.EngineEvent
+		if err := dec.Decode(&e); err != nil {
+			if err != io.EOF {
+				resp.Diagnostics = append(resp.Diagnostics, editorDiagnostic{
+					Severity: "error",
+					Message:  fmt.Sprintf("reading the preview's events: %v", err),
+				})
+			}
+			break
+		}
+
+		switch {
+		case e.Diagnostic != nil:
+			if e.Diagnostic.Ephemeral || e.Diagnostic.Severity == "debug" {
+				continue
+			}
+			resp.Diagnostics = append(resp.Diagnostics, editorDiagnostic{
+				URN:      string(e.Diagnostic.URN),
+				Severity: e.Diagnostic.Severity,
+				Message:  strings.TrimRight(colors.Never.Colorize(e.Diagnostic.Message), "\n"),
+			})
+		case e.ResourcePre != nil:
+			md := e.ResourcePre.Metadata
+			if md.Op == string(deploy.OpSame) {
+				continue
+			}
+			resp.Resources = append(resp.Resources, editorResourceChange{
+				Op:      md.Op,
+				URN:     string(md.URN),
+				Type:    string(md.Type),
+				Diffs:   changedInputs(md.Old, md.New),
+				Replace: md.Keys,
+			})
+		case e.Summary != nil:
+			for op, c := range e.Summary.ResourceChanges {
+				resp.Changes[op] = c
+			}
+			if e.Summary.OutputChanges != nil {
+				resp.Outputs = e.Summary.OutputChanges
+			}
+			resp.Duration = e.Summary.DurationSeconds
+		}
+	}
+	return resp
+}
+
+// changedInputs returns the sorted names of the input properties whose values differ between a resource's old and new
+// states.  There are none unless the resource has both.
+func changedInputs(old, new *apitype.StepEventStateMetadata) []string {
+	if old == nil || new == nil {
+		return nil
+	}
+
+	var keys []string
+	for k, v := range new.Inputs {
+		if ov, has := old.Inputs[k]; !has || !reflect.DeepEqual(ov, v) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range old.Inputs {
+		if _, has := new.Inputs[k]; !has {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
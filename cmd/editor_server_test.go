@@ -0,0 +1,140 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+func TestEditorHandler(t *testing.T) {
+	var previewed []editorPreviewRequest
+	handler := newEditorHandler("secret", func(req editorPreviewRequest) editorPreviewResponse {
+		previewed = append(previewed, req)
+		return editorPreviewResponse{Stack: "dev", Succeeded: true}
+	})
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "token "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Requests must present the server's token.
+	assert.Equal(t, http.StatusUnauthorized, do("POST", "/preview", "", "{}").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("POST", "/preview", "guess", "{}").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, do("GET", "/preview", "secret", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/preview", "secret", `{"dir": "relative"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/preview", "secret", `{`).Code)
+	assert.Empty(t, previewed)
+	assert.Equal(t, http.StatusOK, do("GET", "/version", "secret", "").Code)
+
+	rec := do("POST", "/preview", "secret", `{"stack": "dev"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []editorPreviewRequest{{Stack: "dev"}}, previewed)
+	var resp editorPreviewResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, editorPreviewResponse{Stack: "dev", Succeeded: true}, resp)
+
+	// An empty body previews the selected stack.
+	assert.Equal(t, http.StatusOK, do("POST", "/preview", "secret", "").Code)
+	assert.Equal(t, editorPreviewRequest{}, previewed[1])
+}
+
+func TestSummarizeEditorPreview(t *testing.T) {
+	var log bytes.Buffer
+	enc := json.NewEncoder(&log)
+	for _, e := range []apitype.EngineEvent{
+		{Prelude: &apitype.PreludeEvent{IsPreview: true}},
+		{ResourcePre: &apitype.ResourcePreEvent{Planning: true, Metadata: apitype.StepEventMetadata{
+			Op:   "update",
+			URN:  "urn:pulumi:dev::proj::pkgA:m:typA::resA",
+			Type: "pkgA:m:typA",
+			Old:  &apitype.StepEventStateMetadata{Inputs: map[string]interface{}{"a": 1, "b": "x", "c": true}},
+			New:  &apitype.StepEventStateMetadata{Inputs: map[string]interface{}{"a": 1, "b": "y", "d": true}},
+		}}},
+		{ResourcePre: &apitype.ResourcePreEvent{Planning: true, Metadata: apitype.StepEventMetadata{
+			Op:   "same",
+			URN:  "urn:pulumi:dev::proj::pkgA:m:typA::resB",
+			Type: "pkgA:m:typA",
+		}}},
+		{ResourcePre: &apitype.ResourcePreEvent{Planning: true, Metadata: apitype.StepEventMetadata{
+			Op:   "replace",
+			URN:  "urn:pulumi:dev::proj::pkgA:m:typA::resC",
+			Type: "pkgA:m:typA",
+			Keys: []string{"zone"},
+		}}},
+		{Diagnostic: &apitype.DiagnosticEvent{Severity: "debug", Message: "noise"}},
+		{Diagnostic: &apitype.DiagnosticEvent{Severity: "info", Message: "progress", Ephemeral: true}},
+		{Diagnostic: &apitype.DiagnosticEvent{
+			URN:      "urn:pulumi:dev::proj::pkgA:m:typA::resC",
+			Severity: "warning",
+			Message:  "<{%fg 3%}>zone is deprecated<{%reset%}>\n",
+		}},
+		{Summary: &apitype.SummaryEvent{
+			IsPreview:       true,
+			DurationSeconds: 2,
+			ResourceChanges: map[string]int{"update": 1, "replace": 1, "same": 1},
+			OutputChanges:   []string{"url"},
+		}},
+		{Cancel: &apitype.CancelEvent{}},
+	} {
+		assert.NoError(t, enc.Encode(e))
+	}
+
+	assert.Equal(t, editorPreviewResponse{
+		Changes: map[string]int{"update": 1, "replace": 1, "same": 1},
+		Outputs: []string{"url"},
+		Resources: []editorResourceChange{
+			{
+				Op:    "update",
+				URN:   "urn:pulumi:dev::proj::pkgA:m:typA::resA",
+				Type:  "pkgA:m:typA",
+				Diffs: []string{"b", "c", "d"},
+			},
+			{
+				Op:      "replace",
+				URN:     "urn:pulumi:dev::proj::pkgA:m:typA::resC",
+				Type:    "pkgA:m:typA",
+				Replace: []string{"zone"},
+			},
+		},
+		Diagnostics: []editorDiagnostic{{
+			URN:      "urn:pulumi:dev::proj::pkgA:m:typA::resC",
+			Severity: "warning",
+			Message:  "zone is deprecated",
+		}},
+		Duration: 2,
+	}, summarizeEditorPreview(&log))
+
+	// A preview that produced no events is summarized as changing nothing.
+	assert.Equal(t, editorPreviewResponse{
+		Changes:     map[string]int{},
+		Outputs:     []string{},
+		Resources:   []editorResourceChange{},
+		Diagnostics: []editorDiagnostic{},
+	}, summarizeEditorPreview(&bytes.Buffer{}))
+}
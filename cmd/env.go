@@ -0,0 +1,408 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/encoding"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newEnvCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Manage environments of configuration shared by stacks",
+		Long: "Manage environments of configuration shared by stacks\n" +
+			"\n" +
+			"An environment is a named set of configuration values that may be attached to any number of\n" +
+			"stacks, rather than copying the values into each stack's configuration.  When a stack is\n" +
+			"previewed or updated, the values of its environments are read and added to its configuration,\n" +
+			"which overrides them.  An environment may import other environments, whose values its own\n" +
+			"override.\n" +
+			"\n" +
+			"So that secrets need not be written down, a value may instead be read when it is used: from an\n" +
+			"environment variable, a file, or the output of a command, which may e.g. fetch it from a cloud\n" +
+			"provider's secret store.  An environment may also fetch short-lived credentials whenever an\n" +
+			"operation starts: by assuming an AWS role, impersonating a GCP service account, or reading\n" +
+			"a HashiCorp Vault lease.  These are given to the stack's program and providers in environment\n" +
+			"variables or configuration, and never stored.  They are defined in the environment's file:\n" +
+			"\n" +
+			"    credentials:\n" +
+			"      - aws:\n" +
+			"          roleArn: arn:aws:iam::123456789012:role/deploy\n" +
+			"          duration: 1h\n" +
+			"      - vault:\n" +
+			"          path: database/creds/app\n" +
+			"          config:\n" +
+			"            app:dbPassword: password\n" +
+			"\n" +
+			"Environments are stored in ~/.pulumi/environments, or in the directory named by\n" +
+			"PULUMI_ENVIRONMENT_PATH, which may e.g. be a checkout of a repository that a team shares.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newEnvAttachCmd())
+	cmd.AddCommand(newEnvDetachCmd())
+	cmd.AddCommand(newEnvGetCmd())
+	cmd.AddCommand(newEnvInitCmd())
+	cmd.AddCommand(newEnvLsCmd())
+	cmd.AddCommand(newEnvRmCmd())
+	cmd.AddCommand(newEnvSetCmd())
+	cmd.AddCommand(newEnvUnsetCmd())
+
+	return cmd
+}
+
+func newEnvInitCmd() *cobra.Command {
+	var imports []string
+
+	cmd := &cobra.Command{
+		Use:   "init <name>",
+		Short: "Create an empty environment",
+		Args:  cmdutil.SpecificArgs([]string{"name"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if _, err := workspace.LoadEnvironment(name); err == nil {
+				return errors.Errorf("an environment named '%s' already exists", name)
+			}
+			for _, imported := range imports {
+				if _, err := workspace.LoadEnvironment(imported); err != nil {
+					return err
+				}
+			}
+
+			if err := workspace.SaveEnvironment(name, &workspace.Environment{Imports: imports}); err != nil {
+				return err
+			}
+			fmt.Printf("Created environment '%s'\n", name)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringSliceVar(
+		&imports, "import", nil,
+		"Import the values of the given environments, which the new environment's own values override")
+
+	return cmd
+}
+
+func newEnvLsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "List environments",
+		Args:  cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			names, err := workspace.ListEnvironments()
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				fmt.Println("No environments exist; create one with `pulumi env init`")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tIMPORTS\tVALUES")
+			for _, name := range names {
+				env, err := workspace.LoadEnvironment(name)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\n", name, describeImports(env.Imports), len(env.Values))
+			}
+			return w.Flush()
+		}),
+	}
+}
+
+func newEnvGetCmd() *cobra.Command {
+	var resolve bool
+	var showSecrets bool
+
+	cmd := &cobra.Command{
+		Use:   "get <name>",
+		Short: "Show the definition of an environment, or the values it resolves to",
+		Args:  cmdutil.SpecificArgs([]string{"name"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if !resolve {
+				env, err := workspace.LoadEnvironment(args[0])
+				if err != nil {
+					return err
+				}
+				b, err := encoding.YAML.Marshal(env)
+				if err != nil {
+					return err
+				}
+				fmt.Print(string(b))
+				return nil
+			}
+
+			// Resolve the environment exactly as a stack to which it is attached would.
+			envs, err := backend.ResolveEnvironments(args[0:1], config.Map{}, config.NewPanicCrypter())
+			if err != nil {
+				return err
+			}
+			decrypter := envs.Decrypter
+			if !showSecrets {
+				decrypter = config.NewBlindingDecrypter()
+			}
+
+			var keys config.KeyArray
+			for key := range envs.Config {
+				keys = append(keys, key)
+			}
+			sort.Sort(keys)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KEY\tVALUE")
+			for _, key := range keys {
+				v, err := envs.Config[key].Value(decrypter)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, "%s\t%s\n", key, v)
+			}
+
+			// Environment variables only ever hold credentials, so they are always blinded unless asked for.
+			var names []string
+			for name := range envs.EnvVars {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				v := "[secret]"
+				if showSecrets {
+					v = envs.EnvVars[name]
+				}
+				fmt.Fprintf(w, "$%s\t%s\n", name, v)
+			}
+			return w.Flush()
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVar(
+		&resolve, "resolve", false,
+		"Read the environment's values, including those it imports, rather than showing its definition")
+	cmd.PersistentFlags().BoolVar(
+		&showSecrets, "show-secrets", false,
+		"With --resolve, show secret values instead of displaying blinded values")
+
+	return cmd
+}
+
+func newEnvSetCmd() *cobra.Command {
+	var secret bool
+	var fromEnv string
+	var fromFile string
+	var fromCommand string
+
+	cmd := &cobra.Command{
+		Use:   "set <name> <key> [value]",
+		Short: "Set a value of an environment",
+		Long: "Set a value of an environment\n" +
+			"\n" +
+			"The key must be fully qualified, e.g. aws:region, since an environment may be attached to the\n" +
+			"stacks of any project.  The value is given literally, or else read when the environment is\n" +
+			"used with one of --from-env, --from-file, or --from-command.  Secrets must be read rather than\n" +
+			"given literally, so that they are never stored in the environment.",
+		Args: cmdutil.RangeArgs(2, 3),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			key, err := config.ParseKey(args[1])
+			if err != nil {
+				return errors.Wrap(err, "invalid configuration key")
+			}
+
+			v := workspace.EnvironmentValue{
+				Secret:      secret,
+				FromEnv:     fromEnv,
+				FromFile:    fromFile,
+				FromCommand: fromCommand,
+			}
+			if len(args) == 3 {
+				v.Value = args[2]
+			} else if fromEnv == "" && fromFile == "" && fromCommand == "" {
+				return errors.New("a value, or one of --from-env, --from-file, or --from-command, must be given")
+			}
+			if err = v.Validate(); err != nil {
+				return err
+			}
+
+			env, err := workspace.LoadEnvironment(args[0])
+			if err != nil {
+				return err
+			}
+			if env.Values == nil {
+				env.Values = make(map[config.Key]workspace.EnvironmentValue)
+			}
+			env.Values[key] = v
+			return workspace.SaveEnvironment(args[0], env)
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVar(
+		&secret, "secret", false, "Treat the value as a secret")
+	cmd.PersistentFlags().StringVar(
+		&fromEnv, "from-env", "", "Read the value from the given environment variable")
+	cmd.PersistentFlags().StringVar(
+		&fromFile, "from-file", "", "Read the value from the given file")
+	cmd.PersistentFlags().StringVar(
+		&fromCommand, "from-command", "",
+		"Read the value from the output of the given shell command, e.g. one that fetches it from a secret store")
+
+	return cmd
+}
+
+func newEnvUnsetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset <name> <key>",
+		Short: "Remove a value from an environment",
+		Args:  cmdutil.SpecificArgs([]string{"name", "key"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			key, err := config.ParseKey(args[1])
+			if err != nil {
+				return errors.Wrap(err, "invalid configuration key")
+			}
+
+			env, err := workspace.LoadEnvironment(args[0])
+			if err != nil {
+				return err
+			}
+			if _, has := env.Values[key]; !has {
+				return errors.Errorf("environment '%s' has no value for %s", args[0], key)
+			}
+			delete(env.Values, key)
+			return workspace.SaveEnvironment(args[0], env)
+		}),
+	}
+}
+
+func newEnvRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm <name>",
+		Short: "Remove an environment",
+		Long: "Remove an environment\n" +
+			"\n" +
+			"Stacks to which the environment is attached, and environments that import it, can no longer\n" +
+			"be used until it is detached from them.",
+		Args: cmdutil.SpecificArgs([]string{"name"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if err := workspace.RemoveEnvironment(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Removed environment '%s'\n", args[0])
+			return nil
+		}),
+	}
+}
+
+func newEnvAttachCmd() *cobra.Command {
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "attach <name>",
+		Short: "Attach an environment to a stack",
+		Long: "Attach an environment to a stack\n" +
+			"\n" +
+			"The stack's own configuration overrides the environment's values, and environments attached\n" +
+			"later override those attached earlier.",
+		Args: cmdutil.SpecificArgs([]string{"name"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if _, err := workspace.LoadEnvironment(args[0]); err != nil {
+				return err
+			}
+
+			return updateStackEnvironments(stackName, func(names []string) ([]string, error) {
+				for _, name := range names {
+					if name == args[0] {
+						return nil, errors.Errorf("environment '%s' is already attached", args[0])
+					}
+				}
+				return append(names, args[0]), nil
+			})
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+func newEnvDetachCmd() *cobra.Command {
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "detach <name>",
+		Short: "Detach an environment from a stack",
+		Args:  cmdutil.SpecificArgs([]string{"name"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			return updateStackEnvironments(stackName, func(names []string) ([]string, error) {
+				for i, name := range names {
+					if name == args[0] {
+						return append(names[:i], names[i+1:]...), nil
+					}
+				}
+				return nil, errors.Errorf("environment '%s' is not attached", args[0])
+			})
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+// updateStackEnvironments replaces the environments attached to the indicated stack with those that the given
+// function returns when passed the environments currently attached.
+func updateStackEnvironments(stackName string, update func(names []string) ([]string, error)) error {
+	opts := backend.DisplayOptions{
+		Color: cmdutil.GetGlobalColorization(),
+	}
+
+	s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+	if err != nil {
+		return err
+	}
+
+	name := s.Name().StackName()
+	ps, err := workspace.DetectProjectStack(name)
+	if err != nil {
+		return err
+	}
+	if ps.Environments, err = update(ps.Environments); err != nil {
+		return err
+	}
+	return workspace.SaveProjectStack(name, ps)
+}
+
+// describeImports returns a comma-separated list of the environments that an environment imports.
+func describeImports(imports []string) string {
+	if len(imports) == 0 {
+		return "-"
+	}
+	return strings.Join(imports, ", ")
+}
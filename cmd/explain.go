@@ -0,0 +1,111 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+// explanationJSON is the JSON form of an explanation of a class of errors.
+type explanationJSON struct {
+	Code        string   `json:"code"`
+	Title       string   `json:"title"`
+	Causes      []string `json:"causes,omitempty"`
+	Remediation []string `json:"remediation,omitempty"`
+}
+
+func newExplainCmd() *cobra.Command {
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "explain [code]",
+		Short: "Explain an error code",
+		Long: "Explain an error code.\n" +
+			"\n" +
+			"Errors of well-known classes are reported with a stable code, such as " + diag.PlanApplyFailedID.Code() +
+			",\n" +
+			"which is also included in the diagnostic events of an update.  This command prints what an error\n" +
+			"code means, its common causes, and how to remedy it.  Without a code, it lists all of the codes.",
+		Args: cmdutil.MaximumNArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			var explanations []diag.Explanation
+			if len(args) == 0 {
+				explanations = diag.Explanations()
+			} else {
+				id, err := diag.ParseCode(args[0])
+				if err != nil {
+					return err
+				}
+				e, has := diag.Explain(id)
+				if !has {
+					return errors.Errorf("unknown error code '%s'; run 'pulumi explain' to list them", args[0])
+				}
+				explanations = []diag.Explanation{e}
+			}
+
+			if jsonOut {
+				var result []explanationJSON
+				for _, e := range explanations {
+					result = append(result, explanationJSON{
+						Code:        e.ID.Code(),
+						Title:       e.Title,
+						Causes:      e.Causes,
+						Remediation: e.Remediation,
+					})
+				}
+				var v interface{} = result
+				if len(args) == 1 {
+					v = result[0]
+				}
+				b, err := json.MarshalIndent(v, "", "    ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			if len(args) == 0 {
+				for _, e := range explanations {
+					fmt.Printf("%s  %s\n", e.ID.Code(), e.Title)
+				}
+				return nil
+			}
+
+			e := explanations[0]
+			fmt.Printf("%s: %s\n", e.ID.Code(), e.Title)
+			fmt.Printf("\nCommon causes:\n")
+			for _, cause := range e.Causes {
+				fmt.Printf("  * %s\n", cause)
+			}
+			fmt.Printf("\nRemediation:\n")
+			for _, step := range e.Remediation {
+				fmt.Printf("  * %s\n", step)
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
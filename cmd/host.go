@@ -0,0 +1,82 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newHostCmd() *cobra.Command {
+	var idleTimeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "host",
+		Args:  cmdutil.NoArgs,
+		Short: "Run a daemon that keeps plugins resident between commands",
+		Long: "Run a daemon that keeps plugins resident between commands.\n" +
+			"\n" +
+			"While the daemon is running, other Pulumi commands obtain their language and resource\n" +
+			"provider plugins from it rather than launching them, which avoids paying for each\n" +
+			"plugin's startup on every preview or update.  The daemon keeps a spare, already-started\n" +
+			"instance of each plugin it has been asked for; plugins are never shared between\n" +
+			"commands, so each command still gets a fresh instance.\n" +
+			"\n" +
+			"The daemon exits after it has been idle for --idle-timeout, or when interrupted.  Set\n" +
+			"the " + plugin.DisableHostDaemonEnvVar + " environment variable to stop commands from\n" +
+			"attaching to a running daemon.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			path, err := workspace.GetHostDaemonFilePath()
+			if err != nil {
+				return err
+			}
+			if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return err
+			}
+
+			daemon, err := plugin.NewDaemon(path, idleTimeout)
+			if err != nil {
+				return err
+			}
+
+			sigint := make(chan os.Signal, 1)
+			signal.Notify(sigint, os.Interrupt)
+			defer signal.Stop(sigint)
+			go func() {
+				<-sigint
+				daemon.Shutdown()
+			}()
+
+			info := daemon.Info()
+			fmt.Printf("Plugin host daemon listening on %s (pid %d)\n", info.Addr, info.PID)
+			return daemon.Serve()
+		}),
+	}
+
+	cmd.PersistentFlags().DurationVar(
+		&idleTimeout, "idle-timeout", plugin.DefaultDaemonIdleTimeout,
+		"How long the daemon waits while no plugins are in use before exiting")
+
+	return cmd
+}
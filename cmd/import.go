@@ -0,0 +1,293 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/discovery"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newImportCmd() *cobra.Command {
+	var discover bool
+	var provider string
+	var types []string
+	var tags []string
+	var resourceGroup string
+	var vpc string
+	var manifestFile string
+	var force bool
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "import [manifest]",
+		Short: "Bring existing cloud resources under management",
+		Long: "Bring existing cloud resources under management\n" +
+			"\n" +
+			"With --discover, asks the provider given by --provider to find the existing resources that match\n" +
+			"the filters given by --type, --tag, --resource-group and --vpc, and writes two files: an import\n" +
+			"manifest that lists them (import.json, or the file given by --manifest), and the skeleton of a\n" +
+			"program that declares them, in the language of the current project.  Review both, then import the\n" +
+			"program skeleton into your program.  The provider is configured from the current stack.\n" +
+			"\n" +
+			"Given a manifest, adds the resources it lists to the state of the stack given by --stack, or else\n" +
+			"the current stack.  The resources themselves are not changed.  Run `pulumi refresh` afterwards to\n" +
+			"read their complete state, and then `pulumi preview` to compare them with your program.",
+		Args: cmdutil.MaximumNArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			proj, _, err := readProject()
+			if err != nil {
+				return err
+			}
+
+			if discover {
+				if len(args) > 0 {
+					return errors.New("a manifest may not be given with --discover")
+				}
+				if provider == "" {
+					return errors.New("--discover requires the provider to ask to be given by --provider")
+				}
+				filter, err := parseDiscoverFilter(types, tags, resourceGroup, vpc)
+				if err != nil {
+					return err
+				}
+				return discoverResources(s, proj, tokens.Package(provider), filter, manifestFile, force)
+			}
+
+			if len(args) == 0 {
+				return errors.New("missing manifest; give one, or rerun with --discover to write one")
+			}
+			return importManifest(s, proj, args[0])
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVar(
+		&discover, "discover", false, "Ask the provider to find existing resources and write a manifest of them")
+	cmd.PersistentFlags().StringVar(
+		&provider, "provider", "", "The provider to ask to find existing resources, such as 'aws'")
+	cmd.PersistentFlags().StringSliceVar(
+		&types, "type", []string{}, "Only find resources of this type, such as 'aws:s3/bucket:Bucket'")
+	cmd.PersistentFlags().StringArrayVar(
+		&tags, "tag", []string{}, "Only find resources that have this tag, given as 'key=value'")
+	cmd.PersistentFlags().StringVar(
+		&resourceGroup, "resource-group", "", "Only find resources in this resource group")
+	cmd.PersistentFlags().StringVar(
+		&vpc, "vpc", "", "Only find resources in this VPC or virtual network")
+	cmd.PersistentFlags().StringVar(
+		&manifestFile, "manifest", "import.json", "The file to write the manifest of discovered resources to")
+	cmd.PersistentFlags().BoolVarP(
+		&force, "force", "f", false, "Overwrite the manifest and program skeleton if they already exist")
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+// parseDiscoverFilter builds the filter for a discovery request out of command line flags.
+func parseDiscoverFilter(types, tags []string, resourceGroup, vpc string) (plugin.DiscoverFilter, error) {
+	filter := plugin.DiscoverFilter{
+		ResourceGroup: resourceGroup,
+		VPC:           vpc,
+	}
+	for _, t := range types {
+		filter.Types = append(filter.Types, tokens.Type(t))
+	}
+	for _, tag := range tags {
+		eq := strings.Index(tag, "=")
+		if eq <= 0 {
+			return plugin.DiscoverFilter{}, errors.Errorf("tag '%s' must be given as 'key=value'", tag)
+		}
+		if filter.Tags == nil {
+			filter.Tags = make(map[string]string)
+		}
+		filter.Tags[tag[:eq]] = tag[eq+1:]
+	}
+	return filter, nil
+}
+
+// discoverResources asks the given provider, configured as it would be for an update of the given stack, to find the
+// resources that match a filter, and writes a manifest of them along with a program skeleton that declares them.
+func discoverResources(s backend.Stack, proj *workspace.Project, pkg tokens.Package,
+	filter plugin.DiscoverFilter, manifestFile string, force bool) error {
+
+	programFile, err := discovery.ProgramFile(proj.RuntimeInfo.Name())
+	if err != nil {
+		return err
+	}
+	programFile = filepath.Join(filepath.Dir(manifestFile), programFile)
+	if !force {
+		for _, file := range []string{manifestFile, programFile} {
+			if _, err := os.Stat(file); err == nil {
+				return errors.Errorf("%s already exists; rerun with --force to overwrite it", file)
+			}
+		}
+	}
+
+	inputs, err := getProviderConfig(s, proj, pkg)
+	if err != nil {
+		return err
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	ctx, err := plugin.NewContext(cmdutil.Diag(), cmdutil.Diag(), nil, nil, nil, pwd, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer contract.IgnoreClose(ctx)
+
+	prov, err := ctx.Host.Provider(pkg, nil)
+	if err != nil {
+		return errors.Wrapf(err, "loading the %s provider", pkg)
+	}
+	if err = prov.Configure(inputs); err != nil {
+		return errors.Wrapf(err, "configuring the %s provider", pkg)
+	}
+	discovered, err := prov.Discover(filter)
+	if err != nil {
+		return err
+	}
+
+	m := discovery.NewManifest(discovered)
+	bytes, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(manifestFile, append(bytes, '\n'), 0644); err != nil {
+		return errors.Wrap(err, "could not write manifest")
+	}
+	program, err := discovery.GenerateProgram(m, proj.RuntimeInfo.Name())
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(programFile, program, 0644); err != nil {
+		return errors.Wrap(err, "could not write program skeleton")
+	}
+
+	fmt.Printf("Discovered %d %s; wrote the manifest to %s and a program skeleton to %s.\n",
+		len(m.Resources), pluralizeResources(len(m.Resources)), manifestFile, programFile)
+	if len(m.Resources) > 0 {
+		fmt.Printf("Review both, then run `pulumi import %s` to add the resources to stack '%s'.\n",
+			manifestFile, s.Name())
+	}
+	return nil
+}
+
+// getProviderConfig returns the configuration of the given package's default provider for an update of the given
+// stack: the project's provider defaults overlaid with the stack's configuration in the package's namespace.
+func getProviderConfig(s backend.Stack, proj *workspace.Project, pkg tokens.Package) (resource.PropertyMap, error) {
+	inputs := make(resource.PropertyMap)
+	for k, v := range proj.ProviderConfig(string(pkg)) {
+		inputs[resource.PropertyKey(k)] = resource.NewStringProperty(v)
+	}
+
+	cfg, err := workspace.DetectStackConfig(s.Name().StackName())
+	if err != nil {
+		return nil, err
+	}
+	var decrypter config.Decrypter = config.NewPanicCrypter()
+	if cfg.HasSecureValue() {
+		if decrypter, err = backend.GetStackCrypter(s); err != nil {
+			return nil, err
+		}
+	}
+	for k, c := range cfg {
+		if tokens.Package(k.Namespace()) != pkg {
+			continue
+		}
+		v, err := c.Value(decrypter)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decrypt configuration value")
+		}
+		inputs[resource.PropertyKey(k.Name())] = resource.NewStringProperty(v)
+	}
+	return inputs, nil
+}
+
+// importManifest adds the resources listed by the given manifest file to the state of a stack.
+func importManifest(s backend.Stack, proj *workspace.Project, manifestFile string) error {
+	data, err := ioutil.ReadFile(manifestFile)
+	if err != nil {
+		return errors.Wrap(err, "could not read manifest")
+	}
+	m, err := discovery.ParseManifest(data)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.ExportDeployment(commandContext())
+	if err != nil {
+		return errors.Wrap(err, "could not export deployment")
+	}
+	base, err := stack.DeserializeUntypedDeployment(existing)
+	if err != nil {
+		return errors.Wrap(err, "could not deserialize deployment")
+	}
+	snap, err := m.Snapshot(base, s.Name().StackName(), proj.Name)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := json.Marshal(stack.SerializeDeployment(snap))
+	if err != nil {
+		return err
+	}
+	dep := apitype.UntypedDeployment{
+		Version:    apitype.DeploymentSchemaVersionCurrent,
+		Deployment: bytes,
+	}
+	if err = s.ImportDeployment(commandContext(), &dep); err != nil {
+		return errors.Wrap(err, "could not import deployment")
+	}
+
+	fmt.Printf("Added %d %s to stack '%s'. Run `pulumi refresh` to read their complete state.\n",
+		len(m.Resources), pluralizeResources(len(m.Resources)), s.Name())
+	return nil
+}
+
+// pluralizeResources returns "resource" or "resources", depending on the given count.
+func pluralizeResources(n int) string {
+	if n == 1 {
+		return "resource"
+	}
+	return "resources"
+}
@@ -17,6 +17,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/backend"
@@ -29,11 +30,22 @@ func newLoginCmd() *cobra.Command {
 	var cloudURL string
 
 	cmd := &cobra.Command{
-		Use:   "login",
+		Use:   "login [url]",
 		Short: "Log into the Pulumi Cloud",
-		Long:  "Log into the Pulumi Cloud.  You can script by using PULUMI_ACCESS_TOKEN environment variable.",
-		Args:  cmdutil.NoArgs,
+		Long: "Log into the Pulumi Cloud.  You can script by using PULUMI_ACCESS_TOKEN environment variable.\n" +
+			"\n" +
+			"The URL may instead be given as an argument.  A `local://` URL keeps the state of stacks on this\n" +
+			"machine, and a URL with any other scheme besides http and https keeps it with the backend plugin\n" +
+			"named for that scheme, e.g. `pulumi login consul://localhost:8500` uses `pulumi-backend-consul`.",
+		Args: cmdutil.MaximumNArgs(1),
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				if cloudURL != "" {
+					return errors.New("only one of --cloud-url or a URL argument may be given")
+				}
+				cloudURL = args[0]
+			}
+
 			displayOptions := backend.DisplayOptions{
 				Color: cmdutil.GetGlobalColorization(),
 			}
@@ -50,6 +62,7 @@ func newLoginCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			defer closeBackend(b)
 
 			if currentUser, err := b.CurrentUser(); err == nil {
 				fmt.Printf("Logged into %s as %s\n", b.Name(), currentUser)
@@ -88,8 +88,7 @@ func newLogsCmd() *cobra.Command {
 
 				for _, logEntry := range logs {
 					if _, shownAlready := shown[logEntry]; !shownAlready {
-						eventTime := time.Unix(0, logEntry.Timestamp*1000000)
-						fmt.Printf("%30.30s[%30.30s] %v\n", eventTime.Format(timeFormat), logEntry.ID, logEntry.Message)
+						printLogEntry(logEntry)
 						shown[logEntry] = true
 					}
 				}
@@ -120,6 +119,12 @@ func newLogsCmd() *cobra.Command {
 	return logsCmd
 }
 
+// printLogEntry prints a single log entry, prefixed by its time and the ID of the resource that produced it.
+func printLogEntry(logEntry operations.LogEntry) {
+	eventTime := time.Unix(0, logEntry.Timestamp*1000000)
+	fmt.Printf("%30.30s[%30.30s] %v\n", eventTime.Format(timeFormat), logEntry.ID, logEntry.Message)
+}
+
 func parseSince(since string, reference time.Time) (*time.Time, error) {
 	startTimestamp, err := mobytime.GetTimestamp(since, reference)
 	if err != nil {
@@ -422,8 +422,11 @@ func runUpOrPrintNextSteps(
 		}
 		opts.Display = displayOpts
 		opts.Engine = engine.UpdateOptions{
-			Parallel: defaultParallel,
+			Parallel:        defaultParallel,
+			InstallPlugins:  installMissingPlugins,
+			ProviderOptions: providerOptions,
 		}
+		setRandomSeed(&opts.Engine)
 
 		m, err := getUpdateMetadata("", root)
 		if err != nil {
@@ -0,0 +1,38 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newPackageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "package",
+		Short: "Work with resource provider packages",
+		Long: "Work with resource provider packages.\n" +
+			"\n" +
+			"The package family of commands inspects the resource providers that Pulumi programs\n" +
+			"use to manage cloud resources, and generates SDKs for them.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newPackageGenSDKCmd())
+	cmd.AddCommand(newPackageGetSchemaCmd())
+
+	return cmd
+}
@@ -0,0 +1,129 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/codegen/golang"
+	"github.com/pulumi/pulumi/pkg/codegen/schema"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newPackageGenSDKCmd() *cobra.Command {
+	var language string
+	var out string
+	var schemaFile string
+	var version string
+
+	cmd := &cobra.Command{
+		Use:   "gen-sdk [provider]",
+		Args:  cmdutil.MaximumNArgs(1),
+		Short: "Generate an SDK for a resource provider from its schema",
+		Long: "Generate an SDK for a resource provider from its schema.\n" +
+			"\n" +
+			"This command generates typed bindings for the resources and functions described by a\n" +
+			"provider's schema, so that a provider can be used from a Pulumi program without a\n" +
+			"hand-written SDK.  The schema is fetched from the provider's installed plugin, or read\n" +
+			"from a file with --schema.\n" +
+			"\n" +
+			"Currently only Go SDKs can be generated.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if language != "go" {
+				return errors.Errorf("unsupported language %q; only 'go' is currently supported", language)
+			}
+
+			var data []byte
+			var err error
+			switch {
+			case schemaFile != "" && len(args) > 0:
+				return errors.New("only one of a provider name or --schema may be given")
+			case schemaFile != "":
+				if data, err = ioutil.ReadFile(schemaFile); err != nil {
+					return errors.Wrap(err, "reading schema")
+				}
+			case len(args) > 0:
+				var ver *semver.Version
+				if version != "" {
+					v, verr := semver.ParseTolerant(version)
+					if verr != nil {
+						return errors.Wrap(verr, "invalid provider version")
+					}
+					ver = &v
+				}
+				if data, err = getProviderSchema(tokens.Package(args[0]), ver, true); err != nil {
+					return err
+				}
+			default:
+				return errors.New("missing provider name or --schema")
+			}
+
+			pkg, err := schema.Parse(data)
+			if err != nil {
+				return errors.Wrap(err, "invalid schema")
+			}
+			if out == "" {
+				out = pkg.Name
+			}
+
+			files, err := golang.GeneratePackage("the Pulumi SDK Generator", pkg)
+			if err != nil {
+				return err
+			}
+			return writeSDKFiles(out, files)
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&language, "language", "l", "go", "The language of the SDK to generate")
+	cmd.PersistentFlags().StringVarP(
+		&out, "out", "o", "", "The directory into which the SDK is written. Defaults to the package's name")
+	cmd.PersistentFlags().StringVar(
+		&schemaFile, "schema", "", "Read the schema from a file rather than from the provider's plugin")
+	cmd.PersistentFlags().StringVar(
+		&version, "version", "", "The version of the provider to use. Defaults to the newest installed version")
+
+	return cmd
+}
+
+// writeSDKFiles writes the generated files, which are keyed by their paths relative to dir, into dir.
+func writeSDKFiles(dir string, files map[string][]byte) error {
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return errors.Wrapf(err, "creating directory for %s", target)
+		}
+		if err := ioutil.WriteFile(target, files[path], 0644); err != nil {
+			return errors.Wrapf(err, "writing %s", target)
+		}
+		fmt.Println(target)
+	}
+	return nil
+}
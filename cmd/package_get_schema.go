@@ -0,0 +1,133 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newPackageGetSchemaCmd() *cobra.Command {
+	var noCache bool
+	var version string
+
+	cmd := &cobra.Command{
+		Use:   "get-schema <provider>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Print the schema of a resource provider as JSON",
+		Long: "Print the schema of a resource provider as JSON.\n" +
+			"\n" +
+			"The schema describes the resource and function types that the provider implements, along\n" +
+			"with the configuration it accepts.  This is useful for editor tooling, validating\n" +
+			"configuration, and generating documentation.\n" +
+			"\n" +
+			"The provider's plugin must already be installed.  Schemas are cached in ~/.pulumi/schemas,\n" +
+			"keyed by the provider's version, so that later requests need not load the plugin.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			pkg := tokens.Package(args[0])
+
+			var ver *semver.Version
+			if version != "" {
+				v, err := semver.ParseTolerant(version)
+				if err != nil {
+					return errors.Wrap(err, "invalid provider version")
+				}
+				ver = &v
+			}
+
+			schema, err := getProviderSchema(pkg, ver, !noCache)
+			if err != nil {
+				return err
+			}
+
+			var out bytes.Buffer
+			if err = json.Indent(&out, schema, "", "    "); err != nil {
+				return errors.Wrapf(err, "the %s provider returned an invalid schema", pkg)
+			}
+			fmt.Println(out.String())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&version, "version", "", "The version of the provider to describe. Defaults to the newest installed version")
+	cmd.PersistentFlags().BoolVar(
+		&noCache, "no-cache", false, "Ignore any cached schema and ask the provider for a fresh copy")
+
+	return cmd
+}
+
+// getProviderSchema returns the schema for the given provider, consulting and populating the local schema cache if
+// useCache is true.
+func getProviderSchema(pkg tokens.Package, version *semver.Version, useCache bool) ([]byte, error) {
+	// If an exact version was requested and we've seen it before, there's no need to load the plugin at all.
+	if useCache && version != nil {
+		info := workspace.PluginInfo{Name: string(pkg), Kind: workspace.ResourcePlugin, Version: version}
+		if schema, err := workspace.GetCachedSchema(info); err != nil || schema != nil {
+			return schema, err
+		}
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := plugin.NewContext(cmdutil.Diag(), cmdutil.Diag(), nil, nil, nil, pwd, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer contract.IgnoreClose(ctx)
+
+	prov, err := ctx.Host.Provider(pkg, version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading the %s provider", pkg)
+	}
+	info, err := prov.GetPluginInfo()
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting information about the %s provider", pkg)
+	}
+
+	if useCache && info.Version != nil {
+		schema, cacheErr := workspace.GetCachedSchema(info)
+		if cacheErr != nil || schema != nil {
+			return schema, cacheErr
+		}
+	}
+
+	schema, err := prov.GetSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	// Unversioned plugins (e.g. local development builds) can change at any time, so only cache versioned ones.
+	if info.Version != nil {
+		if err = workspace.CacheSchema(info, schema); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
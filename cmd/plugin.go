@@ -15,6 +15,8 @@
 package cmd
 
 import (
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/engine"
@@ -45,6 +47,7 @@ func newPluginCmd() *cobra.Command {
 
 	cmd.AddCommand(newPluginInstallCmd())
 	cmd.AddCommand(newPluginLsCmd())
+	cmd.AddCommand(newPluginResolveCmd())
 	cmd.AddCommand(newPluginRmCmd())
 
 	return cmd
@@ -52,6 +55,42 @@ func newPluginCmd() *cobra.Command {
 
 // getProjectPlugins fetches a list of plugins used by this project.
 func getProjectPlugins() ([]workspace.PluginInfo, error) {
+	proj, _, err := readProject()
+	if err != nil {
+		return nil, err
+	}
+	plugins, err := getRequiredProjectPlugins()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the required plugins have metadata populated about them.  Because it's possible a plugin required by the
+	// project hasn't yet been installed, we will simply skip any errors we encounter.
+	var results []workspace.PluginInfo
+	for _, plugin := range plugins {
+		// The project may pin or constrain the versions of its providers' plugins.
+		if plugin.Kind == workspace.ResourcePlugin {
+			res, err := resolveProjectPlugin(proj, plugin)
+			if err != nil {
+				return nil, err
+			}
+			plugin.Version = res.Version
+		}
+		if _, path, _ := workspace.GetPluginPath(plugin.Kind, plugin.Name, plugin.Version); path != "" {
+			err = plugin.SetFileMetadata(path)
+			if err != nil {
+				return nil, err
+			}
+			contract.IgnoreError(err)
+		}
+		results = append(results, plugin)
+	}
+	return results, nil
+}
+
+// getRequiredProjectPlugins fetches the list of plugins that this project's program asks for, at the versions that it
+// asks for.
+func getRequiredProjectPlugins() ([]workspace.PluginInfo, error) {
 	proj, root, err := readProject()
 	if err != nil {
 		return nil, err
@@ -64,26 +103,83 @@ func getProjectPlugins() ([]workspace.PluginInfo, error) {
 		return nil, err
 	}
 
-	// Get the required plugins and then ensure they have metadata populated about them.  Because it's possible
-	// a plugin required by the project hasn't yet been installed, we will simply skip any errors we encounter.
-	var results []workspace.PluginInfo
-	plugins, err := ctx.Host.GetRequiredPlugins(plugin.ProgInfo{
+	return ctx.Host.GetRequiredPlugins(plugin.ProgInfo{
 		Proj:    proj,
 		Pwd:     pwd,
 		Program: main,
 	}, plugin.AllPlugins)
+}
+
+// pluginResolution records how the version of a plugin was chosen.
+type pluginResolution struct {
+	Kind       workspace.PluginKind // the plugin's kind.
+	Name       string               // the plugin's name.
+	Constraint string               // the version or range of versions that was asked for, if any.
+	Source     string               // where the constraint came from.
+	Version    *semver.Version      // the version chosen, if any.
+	Path       string               // the path of the installed plugin that will be loaded, if any.
+}
+
+// resolveProjectPlugin resolves the version of a plugin that the current project's program requires, honoring the
+// version or range of versions that the project's `providers` settings ask for.
+func resolveProjectPlugin(proj *workspace.Project, plugin workspace.PluginInfo) (pluginResolution, error) {
+	if plugin.Kind == workspace.ResourcePlugin && proj.Providers[plugin.Name] != nil &&
+		proj.Providers[plugin.Name].Version != "" {
+		return resolvePlugin(plugin.Kind, plugin.Name, proj.Providers[plugin.Name].Version, "project")
+	}
+
+	var constraint string
+	if plugin.Version != nil {
+		constraint = plugin.Version.String()
+	}
+	return resolvePlugin(plugin.Kind, plugin.Name, constraint, "program")
+}
+
+// resolvePlugin resolves the version of a plugin that satisfies the given version or range of versions.  A range
+// resolves to the newest installed version within it or, if none is installed, to the lowest version that it admits,
+// which is the version that `pulumi plugin install` downloads.  A single version is a minimum, as usual.
+func resolvePlugin(kind workspace.PluginKind, name, constraint, source string) (pluginResolution, error) {
+	res := pluginResolution{Kind: kind, Name: name, Constraint: constraint, Source: source}
+	if workspace.IsPluginVersionRange(constraint) {
+		rng, err := workspace.ParsePluginVersionRange(constraint)
+		if err != nil {
+			return res, err
+		}
+		if res.Version, err = workspace.ResolvePluginVersion(kind, name, rng); err != nil {
+			return res, err
+		}
+		if res.Version == nil {
+			if res.Version = rng.Min(); res.Version == nil {
+				return res, errors.Errorf("no installed version of the %s plugin satisfies '%s', "+
+					"and the range has no lower bound from which to pick one to download", name, constraint)
+			}
+			return res, nil
+		}
+	} else if constraint != "" {
+		version, err := semver.ParseTolerant(constraint)
+		if err != nil {
+			return res, errors.Wrapf(err, "invalid version '%s' for the %s plugin", constraint, name)
+		}
+		res.Version = &version
+	}
+
+	_, path, err := workspace.GetPluginPath(kind, name, res.Version)
+	if err != nil || path == "" {
+		return res, err
+	}
+	res.Path = path
+
+	// A single version is only a minimum, so report the version of the installed plugin that will actually be loaded.
+	plugins, err := workspace.GetPlugins()
 	if err != nil {
-		return nil, err
+		return res, err
 	}
 	for _, plugin := range plugins {
-		if _, path, _ := workspace.GetPluginPath(plugin.Kind, plugin.Name, plugin.Version); path != "" {
-			err = plugin.SetFileMetadata(path)
-			if err != nil {
-				return nil, err
+		if plugin.Kind == kind && plugin.Name == name {
+			if pluginPath, err := plugin.FilePath(); err == nil && pluginPath == path {
+				res.Version = plugin.Version
 			}
-			contract.IgnoreError(err)
 		}
-		results = append(results, plugin)
 	}
-	return results, nil
+	return res, nil
 }
@@ -17,7 +17,11 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
@@ -27,6 +31,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/backend/cloud"
 	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
@@ -46,16 +51,22 @@ func newPluginInstallCmd() *cobra.Command {
 			"This command is used manually install plugins required by your program.  It may\n" +
 			"be run either with a specific KIND, NAME, and VERSION, or by omitting these and\n" +
 			"letting Pulumi compute the set of plugins that may be required by the current\n" +
-			"project.  VERSION cannot be a range: it must be a specific number.\n" +
+			"project.  VERSION may be a specific number or a range such as '>=4.0,<5.0'; a range\n" +
+			"is satisfied by any installed version within it, and otherwise its lowest version\n" +
+			"is downloaded.\n" +
 			"\n" +
 			"If you let Pulumi compute the set to download, it is conservative and may end up\n" +
-			"downloading more plugins than is strictly necessary.",
+			"downloading more plugins than is strictly necessary.  Resource plugins are installed\n" +
+			"at the versions that the project's `providers` settings pin, and are downloaded from\n" +
+			"their `pluginDownloadURL`s, if any.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			displayOpts := backend.DisplayOptions{
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
 			// Parse the kind, name, and version, if specified.
+			downloadURLs := make(map[string]string)
+			ranges := make(map[string]bool)
 			var installs []workspace.PluginInfo
 			if len(args) > 0 {
 				if !workspace.IsPluginKind(args[0]) {
@@ -65,15 +76,21 @@ func newPluginInstallCmd() *cobra.Command {
 				} else if len(args) < 3 {
 					return errors.New("missing plugin version argument")
 				}
-				version, err := semver.ParseTolerant(args[2])
-				if err != nil {
-					return errors.Wrap(err, "invalid plugin semver")
+				kind, name := workspace.PluginKind(args[0]), args[1]
+				if workspace.IsPluginVersionRange(args[2]) {
+					res, err := resolvePlugin(kind, name, args[2], "command line")
+					if err != nil {
+						return err
+					}
+					ranges[name] = true
+					installs = append(installs, workspace.PluginInfo{Kind: kind, Name: name, Version: res.Version})
+				} else {
+					version, err := semver.ParseTolerant(args[2])
+					if err != nil {
+						return errors.Wrap(err, "invalid plugin semver")
+					}
+					installs = append(installs, workspace.PluginInfo{Kind: kind, Name: name, Version: &version})
 				}
-				installs = append(installs, workspace.PluginInfo{
-					Kind:    workspace.PluginKind(args[0]),
-					Name:    args[1],
-					Version: &version,
-				})
 			} else {
 				if file != "" {
 					return errors.New("--file (-f) is only valid if a specific package is being installed")
@@ -84,6 +101,18 @@ func newPluginInstallCmd() *cobra.Command {
 				if err != nil {
 					return err
 				}
+				proj, _, err := readProject()
+				if err != nil {
+					return err
+				}
+				for name, defaults := range proj.Providers {
+					if defaults != nil && defaults.PluginDownloadURL != "" {
+						downloadURLs[name] = defaults.PluginDownloadURL
+					}
+					if defaults != nil && workspace.IsPluginVersionRange(defaults.Version) {
+						ranges[name] = true
+					}
+				}
 				for _, plugin := range plugins {
 					// Skip language plugins; by definition, we already have one installed.
 					// TODO[pulumi/pulumi#956]: eventually we will want to honor and install these in the usual way.
@@ -111,8 +140,9 @@ func newPluginInstallCmd() *cobra.Command {
 
 				// If the plugin already exists, don't download it unless --reinstall was passed.  Note that
 				// by default we accept plugins with >= constraints, unless --exact was passed which requires ==.
+				// A version resolved from a range is always exact: it is either installed or must be downloaded.
 				if !reinstall {
-					if exact {
+					if exact || ranges[install.Name] {
 						if workspace.HasPlugin(install) {
 							if verbose {
 								cmdutil.Diag().Infoerrf(
@@ -136,12 +166,16 @@ func newPluginInstallCmd() *cobra.Command {
 				var tarball io.ReadCloser
 				var err error
 				if file == "" {
-					source = releases.CloudURL()
+					var downloadURL string
+					if install.Kind == workspace.ResourcePlugin {
+						downloadURL = downloadURLs[install.Name]
+					}
+					source = pluginSource(downloadURL, releases)
 					if verbose {
 						cmdutil.Diag().Infoerrf(
 							diag.Message("", "%s downloading from %s"), label, source)
 					}
-					if tarball, err = releases.DownloadPlugin(commandContext(), install, true, displayOpts); err != nil {
+					if tarball, err = downloadPlugin(install, downloadURL, releases, true, displayOpts); err != nil {
 						return errors.Wrapf(err, "%s downloading from %s", label, source)
 					}
 				} else {
@@ -158,7 +192,7 @@ func newPluginInstallCmd() *cobra.Command {
 					cmdutil.Diag().Infoerrf(
 						diag.Message("", "%s installing tarball ..."), label)
 				}
-				if err = install.Install(tarball); err != nil {
+				if err = install.InstallFrom(tarball, source); err != nil {
 					return errors.Wrapf(err, "installing %s from %s", label, source)
 				}
 			}
@@ -180,3 +214,75 @@ func newPluginInstallCmd() *cobra.Command {
 
 	return cmd
 }
+
+// installMissingPlugins downloads and installs plugins that an update requires but that are not installed.  Resource
+// plugins are downloaded from the `pluginDownloadURL`s in the project's `providers` settings, if any.
+func installMissingPlugins(plugins []workspace.PluginInfo) error {
+	proj, _, err := readProject()
+	if err != nil {
+		return err
+	}
+	releases, err := cloud.New(cmdutil.Diag(), cloud.ValueOrDefaultURL(""))
+	if err != nil {
+		return errors.Wrap(err, "creating API client")
+	}
+
+	for _, install := range plugins {
+		var downloadURL string
+		if defaults := proj.Providers[install.Name]; defaults != nil && install.Kind == workspace.ResourcePlugin {
+			downloadURL = defaults.PluginDownloadURL
+		}
+		label := fmt.Sprintf("[%s plugin %s]", install.Kind, install)
+		source := pluginSource(downloadURL, releases)
+		tarball, err := downloadPlugin(install, downloadURL, releases, false, backend.DisplayOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "%s downloading from %s", label, source)
+		}
+		if err = install.InstallFrom(tarball, source); err != nil {
+			return errors.Wrapf(err, "installing %s", label)
+		}
+	}
+	return nil
+}
+
+// pluginSource returns the place from which downloadPlugin downloads a plugin.
+func pluginSource(downloadURL string, releases cloud.Backend) string {
+	if downloadURL != "" {
+		return downloadURL
+	}
+	return releases.CloudURL()
+}
+
+// downloadPlugin downloads the tarball for a plugin: from downloadURL if it is set, and otherwise from the releases
+// service, optionally showing the download's progress.
+func downloadPlugin(info workspace.PluginInfo, downloadURL string, releases cloud.Backend, progress bool,
+	displayOpts backend.DisplayOptions) (io.ReadCloser, error) {
+	if downloadURL != "" {
+		return downloadPluginFromURL(downloadURL, info)
+	}
+	return releases.DownloadPlugin(commandContext(), info, progress, displayOpts)
+}
+
+// pluginDownloadClient is the client used to download plugins from URLs.  Its timeout bounds the whole download, so
+// that a server that stops responding does not hang the command, while leaving time for large plugins to arrive.
+var pluginDownloadClient = &http.Client{Timeout: 10 * time.Minute}
+
+// downloadPluginFromURL downloads the tarball for a plugin from the given base URL, under the same file name that the
+// Pulumi releases service uses, e.g. `pulumi-resource-aws-v1.2.3-linux-amd64.tar.gz`.
+func downloadPluginFromURL(baseURL string, info workspace.PluginInfo) (io.ReadCloser, error) {
+	if info.Version == nil {
+		return nil, errors.New("a version is required to download a plugin from a URL")
+	}
+
+	url := fmt.Sprintf("%s/pulumi-%s-%s-v%s-%s-%s.tar.gz", strings.TrimSuffix(baseURL, "/"),
+		info.Kind, info.Name, info.Version, runtime.GOOS, runtime.GOARCH)
+	resp, err := pluginDownloadClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		contract.IgnoreClose(resp.Body)
+		return nil, errors.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newPluginResolveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resolve [KIND NAME VERSION]",
+		Args:  cmdutil.MaximumNArgs(3),
+		Short: "Show how plugin versions are resolved",
+		Long: "Show how plugin versions are resolved.\n" +
+			"\n" +
+			"This command prints the version of each plugin that the current project requires,\n" +
+			"where that requirement came from, and which installed plugin will be loaded for it.\n" +
+			"It may instead be run with a specific KIND, NAME, and VERSION, where VERSION may be\n" +
+			"a single version or a range such as '>=4.0,<5.0'.\n" +
+			"\n" +
+			"A range resolves to the newest installed version within it.  If no installed version\n" +
+			"satisfies a range, the lowest version that it admits is shown, which is the version\n" +
+			"that `pulumi plugin install` downloads.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			var resolutions []pluginResolution
+			if len(args) > 0 {
+				if !workspace.IsPluginKind(args[0]) {
+					return errors.Errorf("unrecognized plugin kind: %s", args[0])
+				} else if len(args) < 2 {
+					return errors.New("missing plugin name argument")
+				} else if len(args) < 3 {
+					return errors.New("missing plugin version argument")
+				}
+				res, err := resolvePlugin(workspace.PluginKind(args[0]), args[1], args[2], "command line")
+				if err != nil {
+					return err
+				}
+				resolutions = append(resolutions, res)
+			} else {
+				plugins, err := getRequiredProjectPlugins()
+				if err != nil {
+					return errors.Wrapf(err, "loading project plugins")
+				}
+				proj, _, err := readProject()
+				if err != nil {
+					return err
+				}
+				for _, plugin := range plugins {
+					res, err := resolveProjectPlugin(proj, plugin)
+					if err != nil {
+						return err
+					}
+					resolutions = append(resolutions, res)
+				}
+				sort.Slice(resolutions, func(i, j int) bool {
+					ri, rj := resolutions[i], resolutions[j]
+					return ri.Kind < rj.Kind || (ri.Kind == rj.Kind && ri.Name < rj.Name)
+				})
+			}
+
+			printPluginResolutions(resolutions)
+			return nil
+		}),
+	}
+
+	return cmd
+}
+
+// printPluginResolutions pretty-prints a table of plugin version resolutions.
+func printPluginResolutions(resolutions []pluginResolution) {
+	// Devote 26 characters to the name width, unless there is a longer name.
+	maxname := 26
+	for _, res := range resolutions {
+		if len(res.Name) > maxname {
+			maxname = len(res.Name)
+		}
+	}
+
+	format := "%-" + strconv.Itoa(maxname) + "s %-12s %-20s %-14s %-16s %s\n"
+	fmt.Printf(format, "NAME", "KIND", "REQUESTED", "SOURCE", "RESOLVED", "PATH")
+	for _, res := range resolutions {
+		requested := res.Constraint
+		if requested == "" {
+			requested = "latest"
+		}
+		resolved := naString
+		if res.Version != nil {
+			resolved = res.Version.String()
+		}
+		path := res.Path
+		if path == "" {
+			path = "(not installed)"
+		}
+		fmt.Printf(format, res.Name, res.Kind, requested, res.Source, resolved, path)
+	}
+}
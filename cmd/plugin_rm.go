@@ -40,7 +40,8 @@ func newPluginRmCmd() *cobra.Command {
 			"Specify KIND, NAME, and/or VERSION to narrow down what will be removed.\n" +
 			"If none are specified, the entire cache will be cleared.  If only KIND and\n" +
 			"NAME are specified, but not VERSION, all versions of the plugin with the\n" +
-			"given KIND and NAME will be removed.  VERSION may be a range.\n" +
+			"given KIND and NAME will be removed.  VERSION may be a range.  Plugins in\n" +
+			"shared, read-only plugin caches are never removed.\n" +
 			"\n" +
 			"This removal cannot be undone.  If a deleted plugin is subsequently required\n" +
 			"in order to execute a Pulumi program, it must be re-downloaded and installed\n" +
@@ -80,7 +81,7 @@ func newPluginRmCmd() *cobra.Command {
 				return errors.Wrap(err, "loading plugins")
 			}
 			for _, plugin := range plugins {
-				if (kind == "" || plugin.Kind == kind) &&
+				if !plugin.Shared && (kind == "" || plugin.Kind == kind) &&
 					(name == "" || plugin.Name == name) &&
 					(version == nil || (plugin.Version != nil && (*version)(*plugin.Version))) {
 					deletes = append(deletes, plugin)
@@ -15,17 +15,32 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
 )
 
 func newPreviewCmd() *cobra.Command {
+	var against string
 	var debug bool
+	var eventLog string
 	var expectNop bool
+	var expectNoOutputChanges []string
+	var cascade bool
 	var message string
 	var stack string
 
@@ -34,9 +49,19 @@ func newPreviewCmd() *cobra.Command {
 	var diffDisplay bool
 	var nonInteractive bool
 	var parallel int
+	var refresh string
+	var refreshSince string
+	var stepDecider string
+	var strictDiff bool
+	var showFullDiff bool
 	var showConfig bool
+	var noDedupe bool
+	var slowStepThreshold time.Duration
+	var showReads bool
 	var showReplacementSteps bool
 	var showSames bool
+	var showSecrets bool
+	var suppressSamesFlag bool
 
 	var cmd = &cobra.Command{
 		Use:        "preview",
@@ -53,20 +78,47 @@ func newPreviewCmd() *cobra.Command {
 			"actually take place.\n" +
 			"\n" +
 			"The program to run is loaded from the project in the current directory. Use the `-C` or\n" +
-			"`--cwd` flag to use a different directory.",
+			"`--cwd` flag to use a different directory.\n" +
+			"\n" +
+			"By default, the preview is computed relative to the stack's current checkpoint. Use the\n" +
+			"`--against` flag to compute it relative to a deployment exported with `pulumi stack export`\n" +
+			"instead; this is useful for reviewing what an import followed by an update would do.\n" +
+			"\n" +
+			"Use the `--refresh` flag to read the live state of the stack's resources first, so that the\n" +
+			"preview is computed against the actual state of the world. Changes found by the refresh are\n" +
+			"summarized separately from the changes that the program would make.\n" +
+			"\n" +
+			"Use the `--expect-no-output-changes` flag to fail the preview if any of the named stack outputs\n" +
+			"would change, e.g. to guard endpoints that other systems consume. Outputs whose new values\n" +
+			"are not known until the update runs are counted as changing.\n" +
+			"\n" +
+			"Stacks that read this stack's outputs may list them under `stackReferences` in their settings,\n" +
+			"by the name of this stack.  Once such a stack has been updated, previews of this one show which\n" +
+			"of them any output changes would affect, and `--cascade-preview` previews them as well.",
 		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := backend.UpdateOptions{
 				Engine: engine.UpdateOptions{
-					Analyzers: analyzers,
-					Parallel:  parallel,
-					Debug:     debug,
+					Analyzers:             analyzers,
+					Parallel:              parallel,
+					Debug:                 debug,
+					ShowSecrets:           showSecrets,
+					StepDecider:           newStepDecider(stepDecider),
+					StrictDiff:            strictDiff,
+					ShowFullDiff:          showFullDiff,
+					ExpectNoOutputChanges: expectNoOutputChanges,
+					InstallPlugins:        installMissingPlugins,
+					ProviderOptions:       providerOptions,
 				},
 				Display: backend.DisplayOptions{
 					Color:                cmdutil.GetGlobalColorization(),
-					ShowConfig:           showConfig,
-					ShowReplacementSteps: showReplacementSteps,
-					ShowSameResources:    showSames,
+					ShowConfig:           displayFlag(cmd, "show-config", showConfig),
+					ShowReads:            displayFlag(cmd, "show-reads", showReads),
+					ShowReplacementSteps: displayFlag(cmd, "show-replacement-steps", showReplacementSteps),
+					ShowSameResources:    displayFlag(cmd, "show-sames", showSames),
+					SuppressSames:        suppressSames(cmd, suppressSamesFlag),
+					NoDedupe:             noDedupe,
+					SlowStepThreshold:    slowStepThreshold,
 					IsInteractive:        isInteractive(nonInteractive),
 					DiffDisplay:          diffDisplay,
 					Debug:                debug,
@@ -78,34 +130,78 @@ func newPreviewCmd() *cobra.Command {
 				return err
 			}
 
+			if eventLog != "" {
+				if opts.Display.EventLog, err = backend.NewEventLog(eventLog); err != nil {
+					return err
+				}
+				defer contract.IgnoreClose(opts.Display.EventLog)
+			}
+
+			if opts.Display.StackConsumers, err = getStackConsumers(s); err != nil {
+				return errors.Wrap(err, "reading stack references")
+			}
+			setRandomSeed(&opts.Engine)
+
+			var outputChanges []string
+			opts.Engine.OnOutputChanges = func(outputs []string) {
+				outputChanges = outputs
+			}
+
+			if against != "" {
+				if opts.PreviewAgainst, err = readProposedSnapshot(against, s.Name().StackName()); err != nil {
+					return err
+				}
+			}
+
 			proj, root, err := readProject()
 			if err != nil {
 				return err
 			}
 
+			if err = setRefreshOptions(refresh, refreshSince, proj, &opts.Engine); err != nil {
+				return err
+			}
+
 			m, err := getUpdateMetadata("", root)
 			if err != nil {
 				return errors.Wrap(err, "gathering environment metadata")
 			}
 
+			tel := beginOperationTelemetry(apitype.PreviewUpdate, &opts.Engine)
 			changes, err := s.Preview(commandContext(), proj, root, m, opts, cancellationScopes)
+			tel.report(changes, err)
 			switch {
 			case err != nil:
 				return PrintEngineError(err)
 			case expectNop && changes != nil && changes.HasChanges():
 				return errors.New("error: no changes were expected but changes were proposed")
+			case cascade:
+				return cascadePreview(backend.AffectedStackConsumers(opts.Display.StackConsumers, outputChanges))
 			default:
 				return nil
 			}
 		}),
 	}
 
+	cmd.PersistentFlags().StringVar(
+		&against, "against", "",
+		"Compute the preview against the deployment in the given file rather than the stack's current checkpoint")
+	cmd.PersistentFlags().BoolVar(
+		&cascade, "cascade-preview", false,
+		"Also preview the stacks that reference any stack outputs that this preview finds may change")
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVar(
+		&eventLog, "event-log", "",
+		"Log the engine's events as JSON to the given file, including each resource's properties before and after "+
+			"every step")
 	cmd.PersistentFlags().BoolVar(
 		&expectNop, "expect-no-changes", false,
 		"Return an error if any changes are proposed by this preview")
+	cmd.PersistentFlags().StringSliceVar(
+		&expectNoOutputChanges, "expect-no-output-changes", nil,
+		"Return an error if this preview would change any of the named stack outputs, in which `*` matches anything")
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
 		"The name of the stack to operate on. Defaults to the current stack")
@@ -123,18 +219,93 @@ func newPreviewCmd() *cobra.Command {
 		"Display operation as a rich diff showing the overall change")
 	cmd.PersistentFlags().BoolVar(
 		&nonInteractive, "non-interactive", false, "Disable interactive mode")
+	cmd.PersistentFlags().StringVarP(
+		&refresh, "refresh", "r", refreshNone,
+		"Refresh the state of the stack's resources before computing the preview; =changed-only reads only the "+
+			"resources whose types are listed in the project's driftProneTypes, or that their providers cannot "+
+			"report as unmodified since --refresh-since")
+	cmd.PersistentFlags().Lookup("refresh").NoOptDefVal = refreshAll
+	cmd.PersistentFlags().StringVar(
+		&refreshSince, "refresh-since", "",
+		"With --refresh=changed-only, an RFC3339 time or a duration ago (e.g. 24h) since which providers are asked "+
+			"whether resources were modified")
+	cmd.PersistentFlags().StringVar(
+		&stepDecider, "step-decider", "",
+		"Consult the given executable about the steps chosen, which may e.g. replace rather than update a resource "+
+			"or deny a deletion")
+	cmd.PersistentFlags().BoolVar(
+		&showFullDiff, "show-full-diff", false,
+		"Diff every input property, including those whose values providers fill in when programs do not give them")
+	cmd.PersistentFlags().BoolVar(
+		&strictDiff, "strict-diff", false,
+		"Have providers check and diff every resource, including those whose inputs have not changed since the "+
+			"last update")
 	cmd.PersistentFlags().IntVarP(
 		&parallel, "parallel", "p", defaultParallel,
 		"Allow P resource operations to run in parallel at once (<=1 for no parallelism)")
+	cmd.PersistentFlags().BoolVar(
+		&noDedupe, "no-dedupe", false,
+		"Show every warning, rather than only the first of those that are repeated along with a count")
+	cmd.PersistentFlags().DurationVar(
+		&slowStepThreshold, "slow-step-threshold", backend.DefaultSlowStepThreshold,
+		"Warn about resource operations still running after this long (0 to disable)")
 	cmd.PersistentFlags().BoolVar(
 		&showConfig, "show-config", false,
 		"Show configuration keys and variables")
+	cmd.PersistentFlags().BoolVar(
+		&showReads, "show-reads", false,
+		"Show resources that are being read in, alongside those being managed directly in the stack")
 	cmd.PersistentFlags().BoolVar(
 		&showReplacementSteps, "show-replacement-steps", false,
 		"Show detailed resource replacement creates and deletes instead of a single step")
 	cmd.PersistentFlags().BoolVar(
 		&showSames, "show-sames", false,
 		"Show resources that needn't be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&showSecrets, "show-secrets", false,
+		"Show the values of secret configuration in resources' properties, rather than redacting them")
+	cmd.PersistentFlags().BoolVar(
+		&suppressSamesFlag, "suppress-sames", true,
+		"Show only a count of unchanged resources in progress output (the default unless -v is given)")
 
 	return cmd
 }
+
+// readProposedSnapshot reads a deployment previously exported with `pulumi stack export` from the given file and
+// returns the snapshot it represents, so that a preview may be computed against it.
+func readProposedSnapshot(file string, stackName tokens.QName) (*deploy.Snapshot, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open file")
+	}
+	defer contract.IgnoreClose(f)
+
+	var deployment apitype.UntypedDeployment
+	if err = json.NewDecoder(f).Decode(&deployment); err != nil {
+		return nil, errors.Wrapf(err, "could not read deployment from %s", file)
+	}
+	snap, err := stack.DeserializeUntypedDeployment(&deployment)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not deserialize deployment")
+	}
+	if err = snap.VerifyIntegrity(); err != nil {
+		return nil, errors.Wrapf(err, "%s: snapshot integrity failure", file)
+	}
+
+	// Resources from another stack will never match the program's resources, so warn about them up front.
+	for _, res := range snap.Resources {
+		if res.URN.Stack() != stackName {
+			cmdutil.Diag().Warningf(diag.Message("" /*urn*/, fmt.Sprintf(
+				"resource '%s' is from a different stack (%s != %s)", res.URN, res.URN.Stack(), stackName)))
+		}
+	}
+
+	// Plans refuse to operate on snapshots with pending operations, so clear them out as `stack import` would.
+	for _, op := range snap.PendingOperations {
+		cmdutil.Diag().Warningf(diag.Message(op.Resource.URN, fmt.Sprintf(
+			"ignoring pending operation '%s' on '%s'", op.Type, op.Resource.URN)))
+	}
+	snap.PendingOperations = nil
+
+	return snap, nil
+}
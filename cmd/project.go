@@ -0,0 +1,56 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newProjectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "project",
+		Aliases: []string{"projects"},
+		Short:   "Manage projects",
+		Long: "Manage projects\n" +
+			"\n" +
+			"A project is a collection of stacks that all run the same Pulumi program.  The project family\n" +
+			"of commands lists the projects known to the current backend, renames them, and removes them\n" +
+			"once none of their stacks have any resources left.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newProjectLsCmd())
+	cmd.AddCommand(newProjectRenameCmd())
+	cmd.AddCommand(newProjectRmCmd())
+
+	return cmd
+}
+
+// currentProjectManager returns the current backend, provided that it is able to manage projects.
+func currentProjectManager(opts backend.DisplayOptions) (backend.ProjectManager, error) {
+	b, err := currentBackend(opts)
+	if err != nil {
+		return nil, err
+	}
+	pm, ok := b.(backend.ProjectManager)
+	if !ok || !b.Capabilities().Projects {
+		return nil, errors.Errorf("the %s backend does not support managing projects", b.Name())
+	}
+	return pm, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newProjectLsCmd() *cobra.Command {
+	var discover bool
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List projects and their stacks",
+		Long: "List projects and their stacks\n" +
+			"\n" +
+			"This command lists every project known to the current backend, along with the names of its\n" +
+			"stacks.  The local backend learns a stack's project from its resources, so stacks that have\n" +
+			"never been updated are listed under an unknown project.\n" +
+			"\n" +
+			"With --discover, the projects whose Pulumi.yaml files lie anywhere within the current git\n" +
+			"repository, or the current directory if it is not in one, are listed instead.  This is useful\n" +
+			"in a monorepo, where any of them can then be operated on with `pulumi -C <path>`.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if discover {
+				return listDiscoveredProjects()
+			}
+
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			pm, err := currentProjectManager(opts)
+			if err != nil {
+				return err
+			}
+			projects, err := pm.ListProjects(commandContext())
+			if err != nil {
+				return err
+			}
+			if len(projects) == 0 {
+				fmt.Println("No projects found")
+				return nil
+			}
+
+			var names []string
+			for project := range projects {
+				names = append(names, string(project))
+			}
+			sort.Strings(names)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PROJECT\tSTACKS")
+			for _, name := range names {
+				var stacks []string
+				for _, ref := range projects[tokens.PackageName(name)] {
+					stacks = append(stacks, ref.String())
+				}
+				sort.Strings(stacks)
+
+				if name == "" {
+					name = "<unknown>"
+				}
+				fmt.Fprintf(w, "%s\t%s\n", name, strings.Join(stacks, ", "))
+			}
+			return w.Flush()
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVar(
+		&discover, "discover", false,
+		"List the projects found on disk within the current repository, rather than those the backend knows of")
+
+	return cmd
+}
+
+// listDiscoveredProjects lists every project within the current repository, along with its location relative to the
+// current directory.
+func listDiscoveredProjects() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, err := workspace.DetectRepositoryRoot(cwd)
+	if err != nil {
+		return err
+	}
+	paths, err := workspace.DetectProjectsUnder(root)
+	if err != nil {
+		return errors.Wrapf(err, "searching %s for projects", root)
+	}
+	if len(paths) == 0 {
+		fmt.Printf("No projects found under %s\n", root)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tRUNTIME\tPATH")
+	for _, path := range paths {
+		proj, err := workspace.LoadProject(path)
+		if err != nil {
+			return errors.Wrapf(err, "loading %s", path)
+		}
+		dir, err := filepath.Rel(cwd, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", proj.Name, proj.RuntimeInfo.Name(), dir)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,126 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newProjectRenameCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <old-name> <new-name>",
+		Short: "Rename a project",
+		Long: "Rename a project\n" +
+			"\n" +
+			"This command moves every stack of a project into a project with a new name, rewriting the\n" +
+			"URNs of their resources to match.  No resources are changed by the rename itself.\n" +
+			"\n" +
+			"If the current directory holds the project being renamed, its Pulumi.yaml and the config of\n" +
+			"its stacks are updated as well; otherwise the project's Pulumi.yaml must be updated by hand\n" +
+			"before its stacks are next updated.",
+		Args: cmdutil.ExactArgs(2),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			oldName, newName := tokens.PackageName(args[0]), tokens.PackageName(args[1])
+			if !tokens.IsPackageName(string(newName)) {
+				return errors.Errorf("'%s' is not a valid project name", newName)
+			}
+			if oldName == newName {
+				return errors.Errorf("project '%s' already has that name", oldName)
+			}
+
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			pm, err := currentProjectManager(opts)
+			if err != nil {
+				return err
+			}
+			projects, err := pm.ListProjects(commandContext())
+			if err != nil {
+				return err
+			}
+			if _, has := projects[newName]; has {
+				return errors.Errorf("project '%s' already exists", newName)
+			}
+			if err = pm.RenameProject(commandContext(), oldName, newName); err != nil {
+				return err
+			}
+
+			msg := fmt.Sprintf("%sProject '%s' has been renamed to '%s'%s",
+				colors.SpecAttention, oldName, newName, colors.Reset)
+			fmt.Println(opts.Color.Colorize(msg))
+
+			// Keep the project on disk in step with the backend, if it is the one that was renamed.
+			proj, err := workspace.DetectProject()
+			if err != nil || proj.Name != oldName {
+				fmt.Printf("Update the name in the project's %s to '%s' before its next update\n",
+					workspace.ProjectFile+".yaml", newName)
+				return nil
+			}
+			return renameWorkspaceProject(proj, projects[oldName], newName)
+		}),
+	}
+}
+
+// renameWorkspaceProject renames the project in the current directory, moving the config that its stacks set in the
+// project's namespace into the new one.
+func renameWorkspaceProject(proj *workspace.Project, stacks []backend.StackReference,
+	newName tokens.PackageName) error {
+
+	for _, ref := range stacks {
+		ps, err := workspace.DetectProjectStack(ref.StackName())
+		if err != nil {
+			return err
+		}
+		if cfg, changed := renameConfigNamespace(ps.Config, string(proj.Name), string(newName)); changed {
+			ps.Config = cfg
+			if err = workspace.SaveProjectStack(ref.StackName(), ps); err != nil {
+				return err
+			}
+		}
+	}
+
+	proj.ConfigDefaults, _ = renameConfigNamespace(proj.ConfigDefaults, string(proj.Name), string(newName))
+	return workspace.RenameProject(proj, newName)
+}
+
+// renameConfigNamespace returns a copy of cfg with the keys in the oldNamespace moved to newNamespace, and whether any
+// keys were moved at all.
+func renameConfigNamespace(cfg config.Map, oldNamespace, newNamespace string) (config.Map, bool) {
+	changed := false
+	result := make(config.Map, len(cfg))
+	for k, v := range cfg {
+		if k.Namespace() == oldNamespace {
+			k = config.MustMakeKey(newNamespace, k.Name())
+			changed = true
+		}
+		result[k] = v
+	}
+	if !changed {
+		return cfg, false
+	}
+	return result, true
+}
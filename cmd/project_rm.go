@@ -0,0 +1,101 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/state"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newProjectRmCmd() *cobra.Command {
+	var yes bool
+	var cmd = &cobra.Command{
+		Use:   "rm <project-name>",
+		Short: "Remove an empty project and its stacks",
+		Long: "Remove an empty project and its stacks\n" +
+			"\n" +
+			"This command removes every stack of a project, along with their configuration.  It is\n" +
+			"rejected unless all of the project's stacks are empty; use `pulumi destroy` to remove\n" +
+			"their resources first.",
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			name := tokens.PackageName(args[0])
+
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			pm, err := currentProjectManager(opts)
+			if err != nil {
+				return err
+			}
+			projects, err := pm.ListProjects(commandContext())
+			if err != nil {
+				return err
+			}
+			stacks, has := projects[name]
+			if !has || name == "" {
+				return errors.Errorf("project '%s' not found", name)
+			}
+
+			// Ensure the user really wants to do this.
+			prompt := fmt.Sprintf("This will permanently remove the '%s' project and its %d stack(s)!",
+				name, len(stacks))
+			if !yes && !confirmPrompt(prompt, string(name), opts) {
+				return errors.New("confirmation declined")
+			}
+
+			if err = pm.RemoveProject(commandContext(), name); err != nil {
+				return err
+			}
+
+			// Blow away stack specific settings if they exist, and forget the current stack if it was removed.
+			if proj, err := workspace.DetectProject(); err == nil && proj.Name == name {
+				for _, ref := range stacks {
+					path, err := workspace.DetectProjectStackPath(ref.StackName())
+					if err != nil {
+						return err
+					}
+					if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+						return err
+					}
+				}
+				if err = state.SetCurrentStack(""); err != nil {
+					return err
+				}
+			}
+
+			msg := fmt.Sprintf("%sProject '%s' has been removed!%s", colors.SpecAttention, name, colors.Reset)
+			fmt.Println(opts.Color.Colorize(msg))
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and proceed with removal anyway")
+
+	return cmd
+}
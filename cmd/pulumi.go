@@ -33,7 +33,6 @@ import (
 	"github.com/pulumi/pulumi/pkg/workspace"
 
 	"github.com/blang/semver"
-	"github.com/golang/glog"
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/backend"
@@ -42,6 +41,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/backend/local"
 	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/version"
@@ -52,35 +52,59 @@ func NewPulumiCmd() *cobra.Command {
 	var cwd string
 	var logFlow bool
 	var logToStderr bool
+	var logToFile string
+	var logFormat string
+	var logModules string
 	var tracing string
 	var tracingHeaderFlag string
 	var profiling string
 	var verbose int
 	var color colorFlag
+	var theme string
+	var recordDir string
+	var replayDir string
+	var chaosFile string
 
 	cmd := &cobra.Command{
 		Use: "pulumi",
 		PersistentPreRun: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
-			// For all commands, attempt to grab out the --color value provided so we
-			// can set the GlobalColorization value to be used by any code that doesn't
-			// get DisplayOptions passed in.
-			cmdFlag := cmd.Flag("color")
-			if cmdFlag != nil {
-				err := color.Set(cmdFlag.Value.String())
-				if err != nil {
+			if cwd != "" {
+				if err := os.Chdir(cwd); err != nil {
 					return err
 				}
+			}
 
-				cmdutil.SetGlobalColorization(color.Colorization())
+			if err := setProjectCacheDir(); err != nil {
+				return err
 			}
 
-			if cwd != "" {
-				if err := os.Chdir(cwd); err != nil {
+			// For all commands, set the GlobalColorization value and display theme to be used by any code,
+			// including code that doesn't get DisplayOptions passed in.
+			if err := initDisplay(cmd, &color, theme); err != nil {
+				return err
+			}
+
+			if recordDir != "" && replayDir != "" {
+				return errors.New("only one of --record and --replay may be given")
+			}
+			providerOptions = plugin.ProviderOptions{RecordDir: recordDir, ReplayDir: replayDir}
+			if chaosFile != "" {
+				chaos, err := plugin.LoadChaosConfig(chaosFile)
+				if err != nil {
 					return err
 				}
+				providerOptions.Chaos = chaos
 			}
 
 			logging.InitLogging(logToStderr, verbose, logFlow)
+			levels, err := logging.ParseModuleLevels(logModules)
+			if err != nil {
+				return errors.Wrap(err, "invalid --logmodules")
+			}
+			logging.SetModuleLevels(levels)
+			if err = logging.InitOutput(logToFile, logging.Format(logFormat)); err != nil {
+				return err
+			}
 			cmdutil.InitTracing("pulumi-cli", "pulumi", tracing)
 			if tracingHeaderFlag != "" {
 				tracingHeader = tracingHeaderFlag
@@ -97,6 +121,7 @@ func NewPulumiCmd() *cobra.Command {
 			return nil
 		}),
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			closeBackends()
 			logging.Flush()
 			cmdutil.CloseTracing()
 
@@ -114,7 +139,7 @@ func NewPulumiCmd() *cobra.Command {
 	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
 		defaultHelp(cmd, args)
 		fmt.Println("")
-		fmt.Println("Additional documentation available at https://pulumi.io")
+		fmt.Println(diag.Localize("Additional documentation available at https://pulumi.io"))
 	})
 
 	cmd.PersistentFlags().StringVarP(&cwd, "cwd", "C", "",
@@ -123,33 +148,67 @@ func NewPulumiCmd() *cobra.Command {
 		"Enable emojis in the output")
 	cmd.PersistentFlags().BoolVar(&local.DisableIntegrityChecking, "disable-integrity-checking", false,
 		"Disable integrity checking of checkpoint files")
+	cmd.PersistentFlags().StringVar(&recordDir, "record", "",
+		"Record every request made of resource providers, and their responses, into the given directory")
+	cmd.PersistentFlags().StringVar(&replayDir, "replay", "",
+		"Answer requests made of resource providers from a recording made with --record, rather than running them")
+	cmd.PersistentFlags().StringVar(&chaosFile, "chaos", "",
+		"Inject the failures described by the given JSON file into requests made of resource providers")
 	cmd.PersistentFlags().BoolVar(&logFlow, "logflow", false,
 		"Flow log settings to child processes (like plugins)")
 	cmd.PersistentFlags().BoolVar(&logToStderr, "logtostderr", false,
 		"Log to stderr instead of to files")
+	cmd.PersistentFlags().StringVar(&logToFile, "logtofile", "",
+		"Log to the given file instead of to stderr or the default log files")
+	cmd.PersistentFlags().StringVar(&logFormat, "logformat", string(logging.TextFormat),
+		"The format of log messages. Choices are: text, json")
+	cmd.PersistentFlags().StringVar(&logModules, "logmodules", "",
+		"Set the verbosity of individual subsystems, overriding --verbose (e.g., engine=9,backend=3). "+
+			"Subsystems are: engine, provider, backend, display")
 	cmd.PersistentFlags().StringVar(&tracing, "tracing", "",
 		"Emit tracing to a Zipkin-compatible tracing endpoint")
 	cmd.PersistentFlags().StringVar(&profiling, "profiling", "",
-		"Emit CPU and memory profiles and an execution trace to '[filename].[pid].{cpu,mem,trace}', respectively")
+		"Emit CPU and memory profiles, an execution trace, and a timing summary to "+
+			"'[filename].[pid].{cpu,mem,trace,timing}', respectively")
 	cmd.PersistentFlags().IntVarP(&verbose, "verbose", "v", 0,
 		"Enable verbose logging (e.g., v=3); anything >3 is very verbose")
 	cmd.PersistentFlags().Var(
 		&color, "color", "Colorize output. Choices are: always, never, raw, auto")
+	cmd.PersistentFlags().StringVar(&theme, "theme", "",
+		"Display theme for colors and step glyphs; built in are default, high-contrast, and colorblind")
 
 	// Common commands:
+	cmd.AddCommand(newBugReportCmd())
 	cmd.AddCommand(newCancelCmd())
 	cmd.AddCommand(newConfigCmd())
+	cmd.AddCommand(newConsoleCmd())
+	cmd.AddCommand(newDashboardCmd())
+	cmd.AddCommand(newDeployCmd())
 	cmd.AddCommand(newDestroyCmd())
+	cmd.AddCommand(newEditorServerCmd())
+	cmd.AddCommand(newEnvCmd())
+	cmd.AddCommand(newExplainCmd())
+	cmd.AddCommand(newHostCmd())
+	cmd.AddCommand(newImportCmd())
 	cmd.AddCommand(newLoginCmd())
 	cmd.AddCommand(newLogoutCmd())
 	cmd.AddCommand(newLogsCmd())
 	cmd.AddCommand(newNewCmd())
+	cmd.AddCommand(newPackageCmd())
 	cmd.AddCommand(newPluginCmd())
 	cmd.AddCommand(newPreviewCmd())
+	cmd.AddCommand(newProjectCmd())
+	cmd.AddCommand(newReconcileCmd())
 	cmd.AddCommand(newRefreshCmd())
+	cmd.AddCommand(newSettingsCmd())
 	cmd.AddCommand(newStackCmd())
+	cmd.AddCommand(newStateCmd())
+	cmd.AddCommand(newTelemetryCmd())
+	cmd.AddCommand(newTriggerCmd())
+	cmd.AddCommand(newTTLCmd())
 	cmd.AddCommand(newUpCmd())
 	cmd.AddCommand(newVersionCmd())
+	cmd.AddCommand(newWatchCmd())
 	cmd.AddCommand(newWhoAmICmd())
 
 	// Less common, and thus hidden, commands:
@@ -165,15 +224,54 @@ func NewPulumiCmd() *cobra.Command {
 			"Include the tracing header with the given contents.")
 	}
 
+	// Translate the help for all commands into the user's language, if there is a message catalog for it.
+	if err := initLocale(); err != nil {
+		logging.Warningf("could not load message catalogs: %v", err)
+	}
+	cmdutil.LocalizeCommand(cmd)
+
 	return cmd
 }
 
+// initLocale selects the locale for user-facing messages and loads the message catalogs for it, which are kept in
+// the locales directory under the Pulumi home directory.
+func initLocale() error {
+	home, err := workspace.GetPulumiHomeDir()
+	if err != nil {
+		return err
+	}
+	return cmdutil.InitLocale(filepath.Join(home, workspace.LocaleDir))
+}
+
+// projectCacheDirSet records whether the project cache directory was chosen by setProjectCacheDir, rather than by the
+// user, and so only applies to the project in the current directory.
+var projectCacheDirSet bool
+
+// setProjectCacheDir tells the plugins that this process launches where they may cache data for the current project,
+// unless the user has already chosen a directory for them.
+func setProjectCacheDir() error {
+	if os.Getenv(workspace.ProjectCacheDirEnvVar) != "" {
+		return nil
+	}
+	path, err := workspace.DetectProjectPath()
+	if err != nil || path == "" {
+		// Commands that run outside of a project have nothing to cache.
+		return nil
+	}
+	dir, err := workspace.GetProjectCacheDir(path)
+	if err != nil {
+		return err
+	}
+	projectCacheDirSet = true
+	return os.Setenv(workspace.ProjectCacheDirEnvVar, dir)
+}
+
 // checkForUpdate checks to see if the CLI needs to be updated, and if so emits a warning, as well as information
 // as to how it can be upgraded.
 func checkForUpdate() {
 	curVer, err := semver.ParseTolerant(version.Version)
 	if err != nil {
-		glog.V(3).Infof("error parsing current version: %s", err)
+		logging.V(3).Infof("error parsing current version: %s", err)
 	}
 
 	// We don't care about warning for you to update if you have installed a developer version
@@ -183,7 +281,7 @@ func checkForUpdate() {
 
 	latestVer, oldestAllowedVer, err := getCLIVersionInfo()
 	if err != nil {
-		glog.V(3).Infof("error fetching latest version information: %s", err)
+		logging.V(3).Infof("error fetching latest version information: %s", err)
 	}
 
 	if oldestAllowedVer.GT(curVer) {
@@ -207,7 +305,7 @@ func getCLIVersionInfo() (semver.Version, semver.Version, error) {
 
 	err = cacheVersionInfo(latest, oldest)
 	if err != nil {
-		glog.V(3).Infof("failed to cache version info: %s", err)
+		logging.V(3).Infof("failed to cache version info: %s", err)
 	}
 
 	return latest, oldest, err
@@ -0,0 +1,551 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/cloud"
+	"github.com/pulumi/pulumi/pkg/backend/local"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/encoding"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/gitutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newReconcileCmd() *cobra.Command {
+	var statusPath string
+	var parallel int
+
+	cmd := &cobra.Command{
+		Use:   "reconcile <stack-resource-file>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Update a stack to match a declarative stack spec, as a Kubernetes operator does",
+		Long: "Update a stack to match a declarative stack spec, as a Kubernetes operator does.\n" +
+			"\n" +
+			"This command lets a Kubernetes operator, or any other GitOps workflow, drive a stack from a Stack\n" +
+			"custom resource.  The file holds either the whole resource or just its spec, and is read as JSON\n" +
+			"unless its name ends in .yaml or .yml; `-` reads JSON from stdin.  The spec names the stack and its\n" +
+			"backend, the Git repository, commit or branch, and directory of its program, and the configuration\n" +
+			"to set on it.  Secrets, such as the backend's access token, secret configuration values, and the\n" +
+			"environment variables that hold a cloud provider's credentials, are not written in the spec; instead,\n" +
+			"it refers to the environment variables or files, such as those of mounted Kubernetes Secrets, that\n" +
+			"hold them.\n" +
+			"\n" +
+			"The program is cloned into a new temporary directory, which is removed afterwards, and the backend is\n" +
+			"logged into without using or changing this machine's stored credentials.  The stack is then\n" +
+			"configured and updated, without prompting.\n" +
+			"\n" +
+			"Afterwards, the resource's status is written as JSON to the --status file, or to stdout if none is\n" +
+			"given; within a pod, `--status /dev/termination-log` hands it to the operator.  Its Ready condition\n" +
+			"is True if the update succeeded, and its Stalled condition is True if the spec must change before a\n" +
+			"retry could succeed.  If the resource already holds a status, the transition times of its conditions\n" +
+			"are kept where their statuses are unchanged.  The command fails unless the stack is ready.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			res, err := readStackResource(args[0])
+			if err != nil {
+				return err
+			}
+
+			r := &stackReconciler{parallel: parallel, color: cmdutil.GetGlobalColorization()}
+			result, rerr := r.reconcile(res.Spec)
+			if err = writeStackStatus(statusPath, newStackStatus(res, result, rerr, time.Now())); err != nil {
+				return errors.Wrap(err, "writing the stack's status")
+			}
+			return rerr
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&statusPath, "status", "",
+		"The file to which to write the stack's status.  Defaults to stdout")
+	cmd.PersistentFlags().IntVarP(
+		&parallel, "parallel", "p", defaultParallel,
+		"Allow P resource operations to run in parallel at once (<=1 for no parallelism)")
+
+	return cmd
+}
+
+// readStackResource reads a stack custom resource, or just its spec, from the given file, or from stdin if the path is
+// `-`.
+func readStackResource(path string) (*apitype.StackResource, error) {
+	m := encoding.JSON
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		if m, _ = encoding.Detect(path); m == nil {
+			return nil, errors.Errorf("could not read the stack resource from %s: unknown file extension", path)
+		}
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the stack resource")
+	}
+
+	var fields map[string]interface{}
+	if err = m.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrapf(err, "could not read the stack resource from %s", path)
+	}
+	var res apitype.StackResource
+	if _, ok := fields["spec"]; ok {
+		err = m.Unmarshal(data, &res)
+	} else {
+		err = m.Unmarshal(data, &res.Spec)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the stack resource from %s", path)
+	}
+	return &res, nil
+}
+
+// writeStackStatus writes the given status as JSON to the given file, or to stdout if the path is empty.
+func writeStackStatus(path string, status apitype.StackStatus) error {
+	b, err := json.MarshalIndent(status, "", "    ")
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		fmt.Println(string(b))
+		return nil
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0600)
+}
+
+// commitHashRegexp matches the full hashes of Git commits.
+var commitHashRegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// validateStackSpec returns an error if the given spec is not well-formed.
+func validateStackSpec(spec apitype.StackSpec) error {
+	if spec.Stack == "" {
+		return errors.New("the spec must name a stack")
+	}
+	if spec.ProjectRepo == "" {
+		return errors.New("the spec must name the repository of the stack's program in projectRepo")
+	}
+	if spec.Commit != "" && spec.Branch != "" {
+		return errors.New("only one of commit and branch may be given")
+	}
+	if spec.Commit != "" && !commitHashRegexp.MatchString(spec.Commit) {
+		return errors.Errorf("commit '%s' is not a full commit hash", spec.Commit)
+	}
+	if dir := filepath.Clean(filepath.FromSlash(spec.RepoDir)); filepath.IsAbs(dir) ||
+		dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+		return errors.Errorf("repoDir '%s' must be a directory within the repository", spec.RepoDir)
+	}
+
+	refs := map[string]apitype.SecretReference{}
+	if spec.AccessToken != nil {
+		refs["accessToken"] = *spec.AccessToken
+	}
+	for k, ref := range spec.Secrets {
+		refs["secret "+k] = ref
+	}
+	for name, ref := range spec.EnvRefs {
+		refs["environment variable "+name] = ref
+	}
+	for what, ref := range refs {
+		if (ref.Env == "") == (ref.File == "") {
+			return errors.Errorf("the reference for %s must give exactly one of env and file", what)
+		}
+	}
+	return nil
+}
+
+// readSecretReference returns the secret value that the given reference refers to.
+func readSecretReference(ref apitype.SecretReference) (string, error) {
+	if ref.Env != "" {
+		v, ok := os.LookupEnv(ref.Env)
+		if !ok {
+			return "", errors.Errorf("environment variable %s is not set", ref.Env)
+		}
+		return v, nil
+	}
+	b, err := ioutil.ReadFile(ref.File)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
+
+// setEnvVars sets the given environment variables in the current process, and returns a function that restores them.
+func setEnvVars(vars map[string]string) (func(), error) {
+	old := make(map[string]*string)
+	restore := func() {
+		for name, v := range old {
+			if v == nil {
+				contract.IgnoreError(os.Unsetenv(name))
+			} else {
+				contract.IgnoreError(os.Setenv(name, *v))
+			}
+		}
+	}
+	for name, value := range vars {
+		if v, ok := os.LookupEnv(name); ok {
+			old[name] = &v
+		} else {
+			old[name] = nil
+		}
+		if err := os.Setenv(name, value); err != nil {
+			restore()
+			return nil, err
+		}
+	}
+	return restore, nil
+}
+
+// branchReferenceName returns the name of the Git reference that a spec's branch names.
+func branchReferenceName(branch string) plumbing.ReferenceName {
+	switch {
+	case branch == "":
+		return plumbing.ReferenceName("refs/heads/master")
+	case strings.HasPrefix(branch, "refs/"):
+		return plumbing.ReferenceName(branch)
+	default:
+		return plumbing.ReferenceName("refs/heads/" + branch)
+	}
+}
+
+// fetchProgram clones the commit or branch of the program that the given spec names into the given directory, and
+// returns the hash of the commit that it checked out.
+func fetchProgram(spec apitype.StackSpec, dir string) (string, error) {
+	var err error
+	if spec.Commit != "" {
+		err = gitutil.GitCloneAndCheckoutCommit(spec.ProjectRepo, plumbing.NewHash(spec.Commit), dir)
+	} else {
+		err = gitutil.GitCloneOrPull(spec.ProjectRepo, branchReferenceName(spec.Branch), dir, true /*shallow*/)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+// reconcileError is an error that kept a stack from being reconciled, along with the reason for it to report in the
+// stack's conditions.
+type reconcileError struct {
+	reason  string
+	stalled bool // true if retrying cannot succeed until the spec changes.
+	err     error
+}
+
+func (e *reconcileError) Error() string {
+	return e.err.Error()
+}
+
+func reconcileFailure(reason string, stalled bool, err error) error {
+	return &reconcileError{reason: reason, stalled: stalled, err: err}
+}
+
+// reconcileResult is the outcome of reconciling a stack.
+type reconcileResult struct {
+	commit  string                 // the commit of the program that was checked out, if it was.
+	changes engine.ResourceChanges // the changes that the update made, if it ran.
+	outputs map[string]interface{} // the stack's outputs, if the update succeeded.
+}
+
+// stackReconciler updates stacks to match their specs.
+type stackReconciler struct {
+	parallel int
+	color    colors.Colorization
+}
+
+// reconcile updates the stack that the given spec names to match it, in a sandbox from which the program and the
+// backend's credentials are removed afterwards.  The returned error, if any, is a *reconcileError unless the update
+// itself failed.
+func (r *stackReconciler) reconcile(spec apitype.StackSpec) (reconcileResult, error) {
+	var result reconcileResult
+	if err := validateStackSpec(spec); err != nil {
+		return result, reconcileFailure(apitype.ReasonInvalidSpec, true, err)
+	}
+
+	// Read every secret before doing anything else, so that a missing one fails fast.
+	env := make(map[string]string)
+	for name, ref := range spec.EnvRefs {
+		v, err := readSecretReference(ref)
+		if err != nil {
+			return result, reconcileFailure(apitype.ReasonSecretUnavailable, false,
+				errors.Wrapf(err, "reading environment variable %s", name))
+		}
+		env[name] = v
+	}
+	if spec.AccessToken != nil {
+		v, err := readSecretReference(*spec.AccessToken)
+		if err != nil {
+			return result, reconcileFailure(apitype.ReasonSecretUnavailable, false,
+				errors.Wrap(err, "reading the access token"))
+		}
+		env[cloud.AccessTokenEnvVar] = v
+	}
+	secrets := make(map[string]string)
+	for k, ref := range spec.Secrets {
+		v, err := readSecretReference(ref)
+		if err != nil {
+			return result, reconcileFailure(apitype.ReasonSecretUnavailable, false,
+				errors.Wrapf(err, "reading secret %s", k))
+		}
+		secrets[k] = v
+	}
+
+	sandbox, err := ioutil.TempDir("", "pulumi-reconcile")
+	if err != nil {
+		return result, errors.Wrap(err, "creating a sandbox")
+	}
+	defer func() { contract.IgnoreError(os.RemoveAll(sandbox)) }()
+
+	// The backend's credentials are kept in the sandbox, rather than alongside this machine's.
+	env[workspace.PulumiCredentialsPathEnvVar] = sandbox
+	restore, err := setEnvVars(env)
+	if err != nil {
+		return result, err
+	}
+	defer restore()
+
+	programDir := filepath.Join(sandbox, "program")
+	if result.commit, err = fetchProgram(spec, programDir); err != nil {
+		return result, reconcileFailure(apitype.ReasonSourceUnavailable, false,
+			errors.Wrapf(err, "fetching %s", spec.ProjectRepo))
+	}
+
+	// The project and its stack's configuration are found relative to the working directory, as they are by other
+	// commands.
+	cwd, err := os.Getwd()
+	if err != nil {
+		return result, err
+	}
+	if err = os.Chdir(filepath.Join(programDir, filepath.FromSlash(spec.RepoDir))); err != nil {
+		return result, reconcileFailure(apitype.ReasonSourceUnavailable, false, err)
+	}
+	defer func() { contract.IgnoreError(os.Chdir(cwd)) }()
+
+	s, err := r.getStack(spec)
+	if err != nil {
+		return result, err
+	}
+	if err = setStackConfig(s, spec.Config, secrets); err != nil {
+		return result, reconcileFailure(apitype.ReasonInvalidConfig, true, err)
+	}
+
+	if result.changes, err = r.update(s, spec, result.commit); err != nil {
+		return result, reconcileFailure(apitype.ReasonUpdateFailed, false, err)
+	}
+
+	snap, err := s.Snapshot(commandContext())
+	if err != nil {
+		return result, err
+	}
+	if res, outputs := stack.GetRootStackResource(snap); res != nil {
+		result.outputs, _ = hideSecretOutputs(outputs)
+	}
+	return result, nil
+}
+
+// getStack logs into the backend that the given spec names, and returns its stack, creating it if the spec says to.
+func (r *stackReconciler) getStack(spec apitype.StackSpec) (backend.Stack, error) {
+	var b backend.Backend
+	var err error
+	if local.IsLocalBackendURL(spec.Backend) {
+		b, err = local.Login(cmdutil.Diag(), spec.Backend)
+	} else {
+		// Without an access token, logging in would prompt for one.
+		if os.Getenv(cloud.AccessTokenEnvVar) == "" {
+			return nil, reconcileFailure(apitype.ReasonLoginFailed, true,
+				errors.Errorf("logging into %s requires an access token; set the spec's accessToken",
+					cloud.ValueOrDefaultURL(spec.Backend)))
+		}
+		b, err = cloud.Login(commandContext(), cmdutil.Diag(), spec.Backend, backend.DisplayOptions{Color: r.color})
+	}
+	if err != nil {
+		return nil, reconcileFailure(apitype.ReasonLoginFailed, false, err)
+	}
+
+	ref, err := b.ParseStackReference(spec.Stack)
+	if err != nil {
+		return nil, reconcileFailure(apitype.ReasonInvalidSpec, true, err)
+	}
+	s, err := b.GetStack(commandContext(), ref)
+	if err != nil || s != nil {
+		return s, err
+	}
+	if !spec.CreateStack {
+		return nil, reconcileFailure(apitype.ReasonStackNotFound, true,
+			errors.Errorf("no stack named '%s' found; set the spec's createStack to create it", spec.Stack))
+	}
+	return createStack(b, ref, nil, false /*setCurrent*/)
+}
+
+// setStackConfig sets the given configuration values, and the given secrets, on the given stack.
+func setStackConfig(s backend.Stack, values map[string]string, secrets map[string]string) error {
+	ps, err := workspace.DetectProjectStack(s.Name().StackName())
+	if err != nil {
+		return err
+	}
+
+	for k, v := range values {
+		key, err := parseConfigKey(k)
+		if err != nil {
+			return errors.Wrapf(err, "invalid configuration key '%s'", k)
+		}
+		ps.Config[key] = config.NewValue(v)
+	}
+	if len(secrets) > 0 {
+		c, err := backend.GetStackCrypter(s)
+		if err != nil {
+			return err
+		}
+		for k, v := range secrets {
+			key, err := parseConfigKey(k)
+			if err != nil {
+				return errors.Wrapf(err, "invalid configuration key '%s'", k)
+			}
+			enc, err := c.EncryptValue(v)
+			if err != nil {
+				return err
+			}
+			ps.Config[key] = config.NewSecureValue(enc)
+		}
+	}
+
+	return workspace.SaveProjectStack(s.Name().StackName(), ps)
+}
+
+// update updates the given stack, without prompting, to the program in the working directory.
+func (r *stackReconciler) update(s backend.Stack, spec apitype.StackSpec,
+	commit string) (engine.ResourceChanges, error) {
+
+	proj, root, err := readProject()
+	if err != nil {
+		return nil, err
+	}
+	m, err := getUpdateMetadata(fmt.Sprintf("Reconciled to commit %s", commit), root)
+	if err != nil {
+		return nil, errors.Wrap(err, "gathering environment metadata")
+	}
+
+	opts := backend.UpdateOptions{
+		AutoApprove: true,
+		SkipPreview: true,
+		Engine: engine.UpdateOptions{
+			Parallel:        r.parallel,
+			Refresh:         spec.Refresh,
+			InstallPlugins:  installMissingPlugins,
+			ProviderOptions: providerOptions,
+		},
+		Display: backend.DisplayOptions{Color: r.color},
+	}
+	setGuardOptions(m, "", &opts.Engine)
+	setRandomSeed(&opts.Engine)
+
+	tel := beginOperationTelemetry(apitype.UpdateUpdate, &opts.Engine)
+	changes, err := s.Update(commandContext(), proj, root, m, opts, cancellationScopes)
+	tel.report(changes, err)
+	if err != nil {
+		return changes, PrintEngineError(err)
+	}
+	recordStackReferences(s)
+	return changes, nil
+}
+
+// newStackStatus returns the status of the given stack resource after an attempt to reconcile it had the given result
+// and error.  The transition times of the resource's existing conditions are kept where their statuses are unchanged.
+func newStackStatus(res *apitype.StackResource, result reconcileResult, err error,
+	now time.Time) apitype.StackStatus {
+
+	status := apitype.StackStatus{
+		ObservedGeneration:  res.Metadata.Generation,
+		LastAttemptedCommit: result.commit,
+	}
+	var conditions []apitype.StackCondition
+	if res.Status != nil {
+		status.LastSuccessfulCommit = res.Status.LastSuccessfulCommit
+		conditions = append(conditions, res.Status.Conditions...)
+	}
+	if result.changes != nil {
+		status.ResourceChanges = make(map[string]int)
+		for op, n := range result.changes {
+			status.ResourceChanges[string(op)] = n
+		}
+	}
+
+	ready := apitype.StackCondition{Type: apitype.StackReady}
+	stalled := apitype.StackCondition{Type: apitype.StackStalled, Status: apitype.ConditionFalse}
+	if err == nil {
+		status.LastSuccessfulCommit, status.Outputs = result.commit, result.outputs
+		ready.Status, ready.Reason = apitype.ConditionTrue, apitype.ReasonSucceeded
+		ready.Message = fmt.Sprintf("stack %s was updated to commit %s", res.Spec.Stack, result.commit)
+		stalled.Reason = apitype.ReasonSucceeded
+	} else {
+		reason, isStalled := apitype.ReasonUpdateFailed, false
+		if rerr, ok := err.(*reconcileError); ok {
+			reason, isStalled = rerr.reason, rerr.stalled
+		}
+		ready.Status, ready.Reason, ready.Message = apitype.ConditionFalse, reason, err.Error()
+		stalled.Reason = reason
+		if isStalled {
+			stalled.Status, stalled.Message = apitype.ConditionTrue, err.Error()
+		}
+	}
+
+	conditions = setStackCondition(conditions, ready, now)
+	status.Conditions = setStackCondition(conditions, stalled, now)
+	return status
+}
+
+// setStackCondition sets the given condition among the given conditions, keeping the transition time of the
+// condition that it replaces if its status is unchanged, and otherwise transitioning at the given time.
+func setStackCondition(conditions []apitype.StackCondition, c apitype.StackCondition,
+	now time.Time) []apitype.StackCondition {
+
+	c.LastTransitionTime = now.UTC().Format(time.RFC3339)
+	for i, old := range conditions {
+		if old.Type == c.Type {
+			if old.Status == c.Status {
+				c.LastTransitionTime = old.LastTransitionTime
+			}
+			conditions[i] = c
+			return conditions
+		}
+	}
+	return append(conditions, c)
+}
@@ -0,0 +1,265 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestReadStackResource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-reconcile-test")
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+		return path
+	}
+
+	res, err := readStackResource(write("stack.json", `{
+		"apiVersion": "pulumi.com/v1alpha1",
+		"kind": "Stack",
+		"metadata": {"name": "web", "generation": 3},
+		"spec": {
+			"stack": "acme/web/prod",
+			"projectRepo": "https://github.com/acme/web",
+			"config": {"aws:region": "us-west-2"}
+		},
+		"status": {"lastSuccessfulCommit": "abc"}
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), res.Metadata.Generation)
+	assert.Equal(t, "acme/web/prod", res.Spec.Stack)
+	assert.Equal(t, map[string]string{"aws:region": "us-west-2"}, res.Spec.Config)
+	assert.Equal(t, "abc", res.Status.LastSuccessfulCommit)
+
+	// A file may hold just the spec.
+	res, err = readStackResource(write("spec.json", `{"stack": "dev", "projectRepo": "https://github.com/acme/web"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", res.Spec.Stack)
+	assert.Nil(t, res.Status)
+
+	res, err = readStackResource(write("stack.yaml", "kind: Stack\n"+
+		"metadata:\n  generation: 2\n"+
+		"spec:\n  stack: dev\n  projectRepo: https://github.com/acme/web\n  branch: main\n"+
+		"  secrets:\n    dbPassword:\n      file: /etc/secrets/db\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), res.Metadata.Generation)
+	assert.Equal(t, "main", res.Spec.Branch)
+	assert.Equal(t, map[string]apitype.SecretReference{"dbPassword": {File: "/etc/secrets/db"}}, res.Spec.Secrets)
+
+	_, err = readStackResource(write("stack.txt", "{}"))
+	assert.Error(t, err)
+	_, err = readStackResource(write("bad.json", "{"))
+	assert.Error(t, err)
+	_, err = readStackResource(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestValidateStackSpec(t *testing.T) {
+	valid := func() apitype.StackSpec {
+		return apitype.StackSpec{
+			Stack:       "dev",
+			ProjectRepo: "https://github.com/acme/web",
+			RepoDir:     "infra/web",
+			AccessToken: &apitype.SecretReference{Env: "TOKEN"},
+			EnvRefs:     map[string]apitype.SecretReference{"AWS_ACCESS_KEY_ID": {File: "/etc/aws/key"}},
+		}
+	}
+	assert.NoError(t, validateStackSpec(valid()))
+
+	cases := map[string]func(spec *apitype.StackSpec){
+		"stack":     func(spec *apitype.StackSpec) { spec.Stack = "" },
+		"repo":      func(spec *apitype.StackSpec) { spec.ProjectRepo = "" },
+		"both refs": func(spec *apitype.StackSpec) { spec.Commit, spec.Branch = strings.Repeat("0", 40), "x" },
+		"commit":    func(spec *apitype.StackSpec) { spec.Commit = "0123abc" },
+		"absolute":  func(spec *apitype.StackSpec) { spec.RepoDir = "/etc" },
+		"escapes":   func(spec *apitype.StackSpec) { spec.RepoDir = "infra/../../etc" },
+		"no ref":    func(spec *apitype.StackSpec) { spec.AccessToken = &apitype.SecretReference{} },
+		"two refs": func(spec *apitype.StackSpec) {
+			spec.Secrets = map[string]apitype.SecretReference{"password": {Env: "PASSWORD", File: "/etc/password"}}
+		},
+	}
+	for name, invalidate := range cases {
+		spec := valid()
+		invalidate(&spec)
+		assert.Error(t, validateStackSpec(spec), name)
+	}
+
+	spec := valid()
+	spec.Commit = "0123456789abcdef0123456789abcdef01234567"
+	assert.NoError(t, validateStackSpec(spec))
+}
+
+func TestReadSecretReference(t *testing.T) {
+	restore, err := setEnvVars(map[string]string{"PULUMI_TEST_RECONCILE_SECRET": "hunter2"})
+	assert.NoError(t, err)
+	v, err := readSecretReference(apitype.SecretReference{Env: "PULUMI_TEST_RECONCILE_SECRET"})
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+
+	restore()
+	_, ok := os.LookupEnv("PULUMI_TEST_RECONCILE_SECRET")
+	assert.False(t, ok)
+	_, err = readSecretReference(apitype.SecretReference{Env: "PULUMI_TEST_RECONCILE_SECRET"})
+	assert.Error(t, err)
+
+	f, err := ioutil.TempFile("", "pulumi-reconcile-secret")
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.Remove(f.Name())) }()
+	_, err = f.WriteString("correct horse\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	v, err = readSecretReference(apitype.SecretReference{File: f.Name()})
+	assert.NoError(t, err)
+	assert.Equal(t, "correct horse", v)
+	_, err = readSecretReference(apitype.SecretReference{File: f.Name() + ".missing"})
+	assert.Error(t, err)
+}
+
+func TestFetchProgram(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-reconcile-test")
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	// Make a repository with two commits on master.
+	origin := filepath.Join(dir, "origin")
+	repo, err := git.PlainInit(origin, false)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	commit := func(contents string) plumbing.Hash {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(origin, "Pulumi.yaml"), []byte(contents), 0600))
+		_, err := w.Add("Pulumi.yaml")
+		assert.NoError(t, err)
+		hash, err := w.Commit(contents, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		assert.NoError(t, err)
+		return hash
+	}
+	first := commit("name: first\n")
+	second := commit("name: second\n")
+
+	head, err := fetchProgram(apitype.StackSpec{ProjectRepo: origin}, filepath.Join(dir, "head"))
+	assert.NoError(t, err)
+	assert.Equal(t, second.String(), head)
+
+	pinned, err := fetchProgram(apitype.StackSpec{ProjectRepo: origin, Commit: first.String()},
+		filepath.Join(dir, "pinned"))
+	assert.NoError(t, err)
+	assert.Equal(t, first.String(), pinned)
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "pinned", "Pulumi.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "name: first\n", string(contents))
+
+	_, err = fetchProgram(apitype.StackSpec{ProjectRepo: origin, Branch: "missing"}, filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestBranchReferenceName(t *testing.T) {
+	assert.Equal(t, plumbing.ReferenceName("refs/heads/master"), branchReferenceName(""))
+	assert.Equal(t, plumbing.ReferenceName("refs/heads/prod"), branchReferenceName("prod"))
+	assert.Equal(t, plumbing.ReferenceName("refs/tags/v1.0"), branchReferenceName("refs/tags/v1.0"))
+}
+
+func TestNewStackStatus(t *testing.T) {
+	then := time.Date(2018, time.June, 15, 10, 0, 0, 0, time.UTC)
+	now := then.Add(time.Hour)
+	res := &apitype.StackResource{
+		Metadata: apitype.StackResourceMetadata{Generation: 4},
+		Spec:     apitype.StackSpec{Stack: "dev"},
+	}
+
+	// A successful update makes the stack ready.
+	status := newStackStatus(res, reconcileResult{
+		commit:  "abc",
+		changes: engine.ResourceChanges{deploy.OpCreate: 2, deploy.OpSame: 1},
+		outputs: map[string]interface{}{"url": "https://example.com"},
+	}, nil, then)
+	assert.Equal(t, int64(4), status.ObservedGeneration)
+	assert.Equal(t, "abc", status.LastAttemptedCommit)
+	assert.Equal(t, "abc", status.LastSuccessfulCommit)
+	assert.Equal(t, map[string]int{"create": 2, "same": 1}, status.ResourceChanges)
+	assert.Equal(t, map[string]interface{}{"url": "https://example.com"}, status.Outputs)
+	assert.Equal(t, []apitype.StackCondition{
+		{Type: apitype.StackReady, Status: apitype.ConditionTrue, Reason: apitype.ReasonSucceeded,
+			Message: "stack dev was updated to commit abc", LastTransitionTime: "2018-06-15T10:00:00Z"},
+		{Type: apitype.StackStalled, Status: apitype.ConditionFalse, Reason: apitype.ReasonSucceeded,
+			LastTransitionTime: "2018-06-15T10:00:00Z"},
+	}, status.Conditions)
+
+	// A failed update keeps the last successful commit, and the time at which Stalled last changed.
+	prev := status
+	res.Status = &prev
+	status = newStackStatus(res, reconcileResult{commit: "def", changes: engine.ResourceChanges{}},
+		reconcileFailure(apitype.ReasonUpdateFailed, false, errors.New("boom")), now)
+	assert.Equal(t, "def", status.LastAttemptedCommit)
+	assert.Equal(t, "abc", status.LastSuccessfulCommit)
+	assert.Nil(t, status.Outputs)
+	assert.Equal(t, []apitype.StackCondition{
+		{Type: apitype.StackReady, Status: apitype.ConditionFalse, Reason: apitype.ReasonUpdateFailed,
+			Message: "boom", LastTransitionTime: "2018-06-15T11:00:00Z"},
+		{Type: apitype.StackStalled, Status: apitype.ConditionFalse, Reason: apitype.ReasonUpdateFailed,
+			LastTransitionTime: "2018-06-15T10:00:00Z"},
+	}, status.Conditions)
+	// The resource's own status is left alone.
+	assert.Equal(t, apitype.ConditionTrue, res.Status.Conditions[0].Status)
+
+	// A spec that cannot succeed stalls the stack; other errors are reported as failed updates.
+	status = newStackStatus(res, reconcileResult{},
+		reconcileFailure(apitype.ReasonInvalidSpec, true, errors.New("the spec must name a stack")), now)
+	assert.Nil(t, status.ResourceChanges)
+	assert.Equal(t, apitype.StackCondition{Type: apitype.StackStalled, Status: apitype.ConditionTrue,
+		Reason: apitype.ReasonInvalidSpec, Message: "the spec must name a stack",
+		LastTransitionTime: "2018-06-15T11:00:00Z"}, status.Conditions[1])
+
+	status = newStackStatus(res, reconcileResult{}, errors.New("oops"), now)
+	assert.Equal(t, apitype.ReasonUpdateFailed, status.Conditions[0].Reason)
+	assert.Equal(t, apitype.ConditionFalse, status.Conditions[1].Status)
+}
+
+func TestReconcileInvalidSpec(t *testing.T) {
+	r := &stackReconciler{}
+	_, err := r.reconcile(apitype.StackSpec{Stack: "dev"})
+	if assert.IsType(t, &reconcileError{}, err) {
+		assert.Equal(t, apitype.ReasonInvalidSpec, err.(*reconcileError).reason)
+		assert.True(t, err.(*reconcileError).stalled)
+	}
+
+	_, err = r.reconcile(apitype.StackSpec{Stack: "dev", ProjectRepo: "https://github.com/acme/web",
+		EnvRefs: map[string]apitype.SecretReference{"AWS_SECRET_ACCESS_KEY": {Env: "PULUMI_TEST_RECONCILE_MISSING"}}})
+	if assert.IsType(t, &reconcileError{}, err) {
+		assert.Equal(t, apitype.ReasonSecretUnavailable, err.(*reconcileError).reason)
+		assert.False(t, err.(*reconcileError).stalled)
+	}
+}
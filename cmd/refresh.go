@@ -16,28 +16,92 @@ package cmd
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
+// The modes of refresh that may be given to a --refresh flag.
+const (
+	refreshAll         = "true"
+	refreshNone        = "false"
+	refreshChangedOnly = "changed-only"
+)
+
+// setRefreshOptions applies a refresh mode and an optional --refresh-since time to the given engine options.  Besides
+// the time, a changed-only refresh is given the project's drift-prone resource types, which it always reads.
+func setRefreshOptions(mode, since string, proj *workspace.Project, opts *engine.UpdateOptions) error {
+	switch mode {
+	case refreshNone, "":
+		opts.Refresh = false
+	case refreshAll:
+		opts.Refresh = true
+	case refreshChangedOnly:
+		opts.Refresh, opts.RefreshChangedOnly = true, true
+		opts.RefreshDriftProneTypes = proj.DriftProneTypes
+	default:
+		return errors.Errorf("unrecognized refresh mode %q; expected one of %s, %s, or %s",
+			mode, refreshAll, refreshNone, refreshChangedOnly)
+	}
+
+	if since != "" {
+		if !opts.RefreshChangedOnly {
+			return errors.New("--refresh-since may only be used with a changed-only refresh")
+		}
+		t, err := parseRefreshSince(since, time.Now())
+		if err != nil {
+			return err
+		}
+		opts.RefreshSince = t
+	}
+	return nil
+}
+
+// parseRefreshSince parses the value of a --refresh-since flag, which is either an RFC3339 timestamp or a duration
+// before the given current time.
+func parseRefreshSince(since string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(since); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, errors.Errorf("--refresh-since %q must be an RFC3339 time or a positive duration, e.g. 24h",
+		since)
+}
+
 func newRefreshCmd() *cobra.Command {
 	var debug bool
+	var eventLog string
 	var expectNop bool
 	var message string
+	var metadata []string
+	var overrideGuard string
 	var stack string
 
 	// Flags for engine.UpdateOptions.
 	var analyzers []string
 	var diffDisplay bool
 	var parallel int
+	var rateLimits []string
+	var changedOnly bool
+	var refreshSince string
 	var showConfig bool
+	var noDedupe bool
+	var slowStepThreshold time.Duration
+	var showReads bool
 	var showReplacementSteps bool
 	var showSames bool
+	var showSecrets bool
+	var suppressSamesFlag bool
 	var nonInteractive bool
 	var skipPreview bool
 	var yes bool
@@ -68,13 +132,23 @@ func newRefreshCmd() *cobra.Command {
 
 			opts.Display = backend.DisplayOptions{
 				Color:                cmdutil.GetGlobalColorization(),
-				ShowConfig:           showConfig,
-				ShowReplacementSteps: showReplacementSteps,
-				ShowSameResources:    showSames,
+				ShowConfig:           displayFlag(cmd, "show-config", showConfig),
+				ShowReads:            displayFlag(cmd, "show-reads", showReads),
+				ShowReplacementSteps: displayFlag(cmd, "show-replacement-steps", showReplacementSteps),
+				ShowSameResources:    displayFlag(cmd, "show-sames", showSames),
+				SuppressSames:        suppressSames(cmd, suppressSamesFlag),
+				NoDedupe:             noDedupe,
+				SlowStepThreshold:    slowStepThreshold,
 				IsInteractive:        interactive,
 				DiffDisplay:          diffDisplay,
 				Debug:                debug,
 			}
+			if eventLog != "" {
+				if opts.Display.EventLog, err = backend.NewEventLog(eventLog); err != nil {
+					return err
+				}
+				defer contract.IgnoreClose(opts.Display.EventLog)
+			}
 
 			s, err := requireStack(stack, true, opts.Display, true /*setCurrent*/)
 			if err != nil {
@@ -90,14 +164,32 @@ func newRefreshCmd() *cobra.Command {
 			if err != nil {
 				return errors.Wrap(err, "gathering environment metadata")
 			}
+			if err = addUserMetadataToEnvironment(metadata, m.Environment); err != nil {
+				return err
+			}
 
 			opts.Engine = engine.UpdateOptions{
-				Analyzers: analyzers,
-				Parallel:  parallel,
-				Debug:     debug,
+				Analyzers:       analyzers,
+				Parallel:        parallel,
+				Debug:           debug,
+				ShowSecrets:     showSecrets,
+				ProviderOptions: providerOptions,
+			}
+			setGuardOptions(m, overrideGuard, &opts.Engine)
+			if opts.Engine.RefreshRateLimits, err = parseRateLimits(rateLimits); err != nil {
+				return err
+			}
+			mode := refreshAll
+			if changedOnly {
+				mode = refreshChangedOnly
+			}
+			if err = setRefreshOptions(mode, refreshSince, proj, &opts.Engine); err != nil {
+				return err
 			}
 
+			tel := beginOperationTelemetry(apitype.RefreshUpdate, &opts.Engine)
 			changes, err := s.Refresh(commandContext(), proj, root, m, opts, cancellationScopes)
+			tel.report(changes, err)
 			switch {
 			case err == context.Canceled:
 				return errors.New("refresh cancelled")
@@ -114,6 +206,10 @@ func newRefreshCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVar(
+		&eventLog, "event-log", "",
+		"Log the engine's events as JSON to the given file, including each resource's properties before and after "+
+			"every step")
 	cmd.PersistentFlags().BoolVar(
 		&expectNop, "expect-no-changes", false,
 		"Return an error if any changes occur during this update")
@@ -124,6 +220,12 @@ func newRefreshCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
 		"Optional message to associate with the update operation")
+	cmd.PersistentFlags().StringArrayVar(
+		&metadata, "metadata", []string{},
+		"Attach a key=value pair to the update, e.g. to link it to a ticket; may be given more than once")
+	cmd.PersistentFlags().StringVar(
+		&overrideGuard, "override-guard", "",
+		"Proceed even if the stack's guard forbids this operation, giving the reason for doing so")
 
 	// Flags for engine.UpdateOptions.
 	cmd.PersistentFlags().StringSliceVar(
@@ -137,12 +239,39 @@ func newRefreshCmd() *cobra.Command {
 	cmd.PersistentFlags().IntVarP(
 		&parallel, "parallel", "p", defaultParallel,
 		"Allow P resource operations to run in parallel at once (<=1 for no parallelism)")
+	cmd.PersistentFlags().StringArrayVar(
+		&rateLimits, "rate-limit", nil,
+		"Limit how fast a provider's resources are read, as <package>=<requests per second>"+
+			"[,retries=N][,backoff=D][,max-backoff=D]; may be given more than once")
+	cmd.PersistentFlags().BoolVar(
+		&changedOnly, "changed-only", false,
+		"Only read resources whose types are listed in the project's driftProneTypes, or that their providers "+
+			"cannot report as unmodified since --refresh-since")
+	cmd.PersistentFlags().StringVar(
+		&refreshSince, "refresh-since", "",
+		"With --changed-only, an RFC3339 time or a duration ago (e.g. 24h) since which providers are asked "+
+			"whether resources were modified")
+	cmd.PersistentFlags().BoolVar(
+		&noDedupe, "no-dedupe", false,
+		"Show every warning, rather than only the first of those that are repeated along with a count")
+	cmd.PersistentFlags().DurationVar(
+		&slowStepThreshold, "slow-step-threshold", backend.DefaultSlowStepThreshold,
+		"Warn about resource operations still running after this long (0 to disable)")
+	cmd.PersistentFlags().BoolVar(
+		&showReads, "show-reads", false,
+		"Show resources that are being read in, alongside those being managed directly in the stack")
 	cmd.PersistentFlags().BoolVar(
 		&showReplacementSteps, "show-replacement-steps", false,
 		"Show detailed resource replacement creates and deletes instead of a single step")
 	cmd.PersistentFlags().BoolVar(
 		&showSames, "show-sames", false,
 		"Show resources that needn't be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&showSecrets, "show-secrets", false,
+		"Show the values of secret configuration in resources' properties, rather than redacting them")
+	cmd.PersistentFlags().BoolVar(
+		&suppressSamesFlag, "suppress-sames", true,
+		"Show only a count of unchanged resources in progress output (the default unless -v is given)")
 	cmd.PersistentFlags().BoolVar(
 		&skipPreview, "skip-preview", false,
 		"Do not perform a preview before performing the refresh")
@@ -0,0 +1,83 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestParseRefreshSince(t *testing.T) {
+	now := time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	since, err := parseRefreshSince("2018-08-31T12:00:00Z", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-24*time.Hour), since.UTC())
+
+	since, err = parseRefreshSince("90m", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-90*time.Minute), since)
+
+	for _, bad := range []string{"yesterday", "-1h", "2018-08-31"} {
+		_, err = parseRefreshSince(bad, now)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSetRefreshOptions(t *testing.T) {
+	proj := &workspace.Project{DriftProneTypes: []string{"aws:ec2/*"}}
+
+	var opts engine.UpdateOptions
+	assert.NoError(t, setRefreshOptions(refreshAll, "", proj, &opts))
+	assert.True(t, opts.Refresh)
+	assert.False(t, opts.RefreshChangedOnly)
+
+	opts = engine.UpdateOptions{}
+	assert.NoError(t, setRefreshOptions(refreshChangedOnly, "24h", proj, &opts))
+	assert.True(t, opts.Refresh)
+	assert.True(t, opts.RefreshChangedOnly)
+	assert.Equal(t, []string{"aws:ec2/*"}, opts.RefreshDriftProneTypes)
+	assert.False(t, opts.RefreshSince.IsZero())
+
+	assert.Error(t, setRefreshOptions(refreshAll, "24h", proj, &engine.UpdateOptions{}))
+	assert.Error(t, setRefreshOptions("sometimes", "", proj, &engine.UpdateOptions{}))
+}
+
+func TestSuppressSames(t *testing.T) {
+	defer func(v int) { logging.Verbose = v }(logging.Verbose)
+
+	newCmd := func(args ...string) *cobra.Command {
+		var flag bool
+		cmd := &cobra.Command{}
+		cmd.PersistentFlags().BoolVar(&flag, "suppress-sames", true, "")
+		assert.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+
+	logging.Verbose = 0
+	assert.True(t, suppressSames(newCmd(), true))
+	assert.False(t, suppressSames(newCmd("--suppress-sames=false"), false))
+
+	logging.Verbose = 3
+	assert.False(t, suppressSames(newCmd(), true))
+	assert.True(t, suppressSames(newCmd("--suppress-sames"), true))
+}
@@ -0,0 +1,178 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// displaySettings are the display flags whose defaults may be set in the workspace's settings.
+var displaySettings = []string{"show-config", "show-reads", "show-replacement-steps", "show-sames"}
+
+func newSettingsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "settings",
+		Short: "Manage the current workspace's settings",
+		Long: "Manage the current workspace's settings.\n" +
+			"\n" +
+			"Lists the settings of the workspace for the current project, which are kept on this machine\n" +
+			"rather than in the project.  These include the defaults for the display flags of preview, up,\n" +
+			"destroy, and refresh (" + strings.Join(displaySettings, ", ") + "), which apply\n" +
+			"unless the flags are given explicitly, as well as the default for --color and the display theme.\n" +
+			"Use 'pulumi settings set' and 'pulumi settings rm' to change them.\n" +
+			"\n" +
+			"Besides the built-in themes (" + strings.Join(colors.ThemeNames(nil), ", ") + "), themes may\n" +
+			"be defined under 'themes' in the workspace's settings file.  A theme may be based on another, and\n" +
+			"maps logical colors (such as create, update, delete, error, and warning) to space-separated color\n" +
+			"names (such as 'bold bright-blue', or a number from 0 to 255), and step kinds to the one- or\n" +
+			"two-character glyphs that mark them.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			w, err := workspace.New()
+			if err != nil {
+				return err
+			}
+
+			settings := w.Settings()
+			if settings.Stack != "" {
+				fmt.Printf("stack: %s\n", settings.Stack)
+			}
+			if settings.Color != "" {
+				fmt.Printf("color: %s\n", settings.Color)
+			}
+			if settings.Theme != "" {
+				fmt.Printf("theme: %s\n", settings.Theme)
+			}
+			var names []string
+			for name := range settings.Display {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Printf("%s: %v\n", name, settings.Display[name])
+			}
+			return nil
+		}),
+	}
+
+	cmd.AddCommand(newSettingsSetCmd())
+	cmd.AddCommand(newSettingsRmCmd())
+
+	return cmd
+}
+
+func newSettingsSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <name> <value>",
+		Short: "Set the default for a display flag, --color, or the display theme in the current workspace",
+		Args:  cmdutil.ExactArgs(2),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			name, value := args[0], args[1]
+			if err := validateDisplaySetting(name); err != nil {
+				return err
+			}
+
+			w, err := workspace.New()
+			if err != nil {
+				return err
+			}
+			settings := w.Settings()
+
+			switch name {
+			case colorSetting:
+				var color colorFlag
+				if err = color.Set(value); err != nil {
+					return err
+				}
+				settings.Color = value
+			case themeSetting:
+				if _, err = colors.ResolveTheme(value, settings.Themes); err != nil {
+					return err
+				}
+				settings.Theme = value
+			default:
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return errors.Errorf("invalid value '%s' for %s; expected true or false", value, name)
+				}
+				if settings.Display == nil {
+					settings.Display = make(map[string]bool)
+				}
+				settings.Display[name] = b
+			}
+			return w.Save()
+		}),
+	}
+}
+
+func newSettingsRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm <name>",
+		Short: "Remove the default for a display flag, --color, or the display theme from the current workspace",
+		Args:  cmdutil.ExactArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if err := validateDisplaySetting(args[0]); err != nil {
+				return err
+			}
+
+			w, err := workspace.New()
+			if err != nil {
+				return err
+			}
+			settings := w.Settings()
+			switch args[0] {
+			case colorSetting:
+				settings.Color = ""
+			case themeSetting:
+				settings.Theme = ""
+			default:
+				delete(settings.Display, args[0])
+			}
+			return w.Save()
+		}),
+	}
+}
+
+// The names of the settings for the default colorization and the display theme.
+const (
+	colorSetting = "color"
+	themeSetting = "theme"
+)
+
+// validateDisplaySetting returns an error if the given name isn't that of a setting that may be set: a display flag
+// whose default may be set, the default colorization, or the display theme.
+func validateDisplaySetting(name string) error {
+	if name == colorSetting || name == themeSetting {
+		return nil
+	}
+	for _, setting := range displaySettings {
+		if name == setting {
+			return nil
+		}
+	}
+	names := append([]string{colorSetting}, displaySettings...)
+	names = append(names, themeSetting)
+	return errors.Errorf("unknown setting '%s'; expected one of %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,94 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestDisplayFlagDefaults(t *testing.T) {
+	// Use a scratch project and Pulumi home, so that the workspace's settings are our own.
+	dir, err := ioutil.TempDir("", "pulumi-settings")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	proj := []byte("name: test\nruntime: nodejs\n")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Pulumi.yaml"), proj, 0600))
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer func() { assert.NoError(t, os.Chdir(cwd)) }()
+	assert.NoError(t, os.Setenv(workspace.PulumiHomeEnvVar, filepath.Join(dir, ".pulumi-home")))
+	defer func() { assert.NoError(t, os.Unsetenv(workspace.PulumiHomeEnvVar)) }()
+
+	var showSames bool
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().BoolVar(&showSames, "show-sames", false, "")
+		return cmd
+	}
+
+	// Without a default in the settings, the flag's own value is used.
+	assert.False(t, displayFlag(newCmd(), "show-sames", false))
+
+	// With one, the default applies unless the flag is given explicitly.
+	w, err := workspace.New()
+	assert.NoError(t, err)
+	w.Settings().Display = map[string]bool{"show-sames": true}
+	assert.NoError(t, w.Save())
+	assert.True(t, displayFlag(newCmd(), "show-sames", false))
+
+	cmd := newCmd()
+	assert.NoError(t, cmd.Flags().Set("show-sames", "false"))
+	assert.False(t, displayFlag(cmd, "show-sames", showSames))
+
+	assert.NoError(t, validateDisplaySetting("show-reads"))
+	assert.NoError(t, validateDisplaySetting("theme"))
+	assert.Error(t, validateDisplaySetting("show-everything"))
+}
+
+func TestColorFlag(t *testing.T) {
+	noColor := os.Getenv(cmdutil.NoColorEnvVar)
+	defer func() { assert.NoError(t, os.Setenv(cmdutil.NoColorEnvVar, noColor)) }()
+
+	// An empty NO_COLOR doesn't count, but a non-empty one turns colors off unless they're asked for explicitly.
+	var color colorFlag
+	assert.NoError(t, os.Setenv(cmdutil.NoColorEnvVar, ""))
+	assert.Equal(t, colors.Always, color.Colorization())
+	assert.NoError(t, os.Setenv(cmdutil.NoColorEnvVar, "1"))
+	assert.Equal(t, colors.Never, color.Colorization())
+	assert.NoError(t, color.Set("always"))
+	assert.Equal(t, colors.Always, color.Colorization())
+
+	// Auto colorizes only when writing to a terminal.
+	isTerminal := stdoutIsTerminal
+	defer func() { stdoutIsTerminal = isTerminal }()
+	stdoutIsTerminal = func() bool { return true }
+	assert.NoError(t, color.Set("auto"))
+	assert.Equal(t, colors.Always, color.Colorization())
+	stdoutIsTerminal = func() bool { return false }
+	assert.NoError(t, color.Set("auto"))
+	assert.Equal(t, colors.Never, color.Colorization())
+	assert.Error(t, color.Set("sometimes"))
+}
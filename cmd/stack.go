@@ -25,6 +25,7 @@ import (
 
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/cloud"
+	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 )
@@ -32,6 +33,8 @@ import (
 func newStackCmd() *cobra.Command {
 	var showIDs bool
 	var showURNs bool
+	var showSources bool
+	var showAges bool
 
 	cmd := &cobra.Command{
 		Use:   "stack",
@@ -82,6 +85,9 @@ func newStackCmd() *cobra.Command {
 				} else {
 					cliver = snap.Manifest.Version
 				}
+				if snap.Manifest.OS != "" {
+					cliver = fmt.Sprintf("%s (%s/%s)", cliver, snap.Manifest.OS, snap.Manifest.Arch)
+				}
 				fmt.Printf("    Pulumi version: %s\n", cliver)
 				for _, plugin := range snap.Manifest.Plugins {
 					var plugver string
@@ -123,6 +129,12 @@ func newStackCmd() *cobra.Command {
 					if showIDs && res.ID != "" {
 						fmt.Printf("        ID: %s\n", res.ID)
 					}
+					if showSources && res.SourcePosition != "" {
+						fmt.Printf("        Source: %s\n", res.SourcePosition)
+					}
+					if showAges {
+						printResourceAge(res)
+					}
 				}
 
 				// Print out the output properties for the stack, if present.
@@ -133,8 +145,8 @@ func newStackCmd() *cobra.Command {
 			}
 
 			// Add a link to the pulumi.com console page for this stack, if it has one.
-			if cs, ok := s.(cloud.Stack); ok {
-				if consoleURL, err := cs.ConsoleURL(); err == nil {
+			if be.Capabilities().Console {
+				if consoleURL, err := s.(backend.ConsoleLinkedStack).ConsoleURL(); err == nil {
 					fmt.Printf("\n")
 					fmt.Printf("More information at: %s\n", consoleURL)
 				}
@@ -152,13 +164,26 @@ func newStackCmd() *cobra.Command {
 		&showIDs, "show-ids", "i", false, "Display each resource's provider-assigned unique ID")
 	cmd.PersistentFlags().BoolVarP(
 		&showURNs, "show-urns", "u", false, "Display each resource's Pulumi-assigned globally unique URN")
-
+	cmd.PersistentFlags().BoolVar(
+		&showSources, "show-sources", false, "Display the position in the program's source that declared each resource")
+	cmd.PersistentFlags().BoolVar(
+		&showAges, "show-ages", false, "Display when each resource was created and last modified, and by which update")
+
+	cmd.AddCommand(newStackAccessCmd())
+	cmd.AddCommand(newStackAuditCmd())
+	cmd.AddCommand(newStackDiffCmd())
+	cmd.AddCommand(newStackHistoryCmd())
 	cmd.AddCommand(newStackExportCmd())
+	cmd.AddCommand(newStackGCCmd())
 	cmd.AddCommand(newStackGraphCmd())
 	cmd.AddCommand(newStackImportCmd())
 	cmd.AddCommand(newStackInitCmd())
 	cmd.AddCommand(newStackLsCmd())
 	cmd.AddCommand(newStackOutputCmd())
+	cmd.AddCommand(newStackOutputTokenCmd())
+	cmd.AddCommand(newStackRecoverCmd())
+	cmd.AddCommand(newStackRepairCmd())
+	cmd.AddCommand(newStackReportCmd())
 	cmd.AddCommand(newStackRmCmd())
 	cmd.AddCommand(newStackSelectCmd())
 
@@ -201,3 +226,17 @@ func stringifyOutput(v interface{}) string {
 
 	return string(b)
 }
+
+// printResourceAge prints when a resource was created and last modified, and by which version of the stack, if known.
+func printResourceAge(res *resource.State) {
+	if !res.Created.IsZero() {
+		fmt.Printf("        Created: %s (%v)\n", humanize.Time(res.Created), res.Created)
+	}
+	if !res.Modified.IsZero() {
+		var by string
+		if res.UpdateVersion > 0 {
+			by = fmt.Sprintf(" by update %d", res.UpdateVersion)
+		}
+		fmt.Printf("        Modified: %s (%v)%s\n", humanize.Time(res.Modified), res.Modified, by)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStackAccessCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "access",
+		Short: "Manage which users and teams may access a stack",
+		Long: "Manage which users and teams may access a stack\n" +
+			"\n" +
+			"These commands list, grant, and revoke access to a stack.  Each user or team is granted one of\n" +
+			"the roles 'read', 'write', or 'admin'.  Access control is only available for backends that\n" +
+			"support it, such as the Pulumi Service.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newStackAccessLsCmd())
+	cmd.AddCommand(newStackAccessSetCmd())
+	cmd.AddCommand(newStackAccessRmCmd())
+
+	return cmd
+}
+
+func newStackAccessLsCmd() *cobra.Command {
+	var jsonOut bool
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List the users and teams that may access a stack",
+		Args:  cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			s, access, err := requireStackAccessManager(stackName)
+			if err != nil {
+				return err
+			}
+
+			perms, err := access.ListStackAccess(commandContext(), s.Name())
+			if err != nil {
+				return errors.Wrap(err, "listing stack access")
+			}
+			sortStackPermissions(perms)
+
+			if jsonOut {
+				if perms == nil {
+					perms = []apitype.StackPermission{}
+				}
+				b, err := json.MarshalIndent(perms, "", "    ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			if len(perms) == 0 {
+				fmt.Printf("No users or teams have been granted access to stack '%s'\n", s.Name())
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tKIND\tROLE")
+			for _, perm := range perms {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", perm.Name, perm.Kind, perm.Role)
+			}
+			return w.Flush()
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+func newStackAccessSetCmd() *cobra.Command {
+	var stackName string
+	var team bool
+
+	cmd := &cobra.Command{
+		Use:   "set <name> <role>",
+		Short: "Grant a user or team a role on a stack",
+		Long: "Grant a user or team a role on a stack\n" +
+			"\n" +
+			"The role must be one of 'read', 'write', or 'admin', and replaces any role previously\n" +
+			"granted.  The name is that of a user unless --team is passed.",
+		Args: cmdutil.SpecificArgs([]string{"name", "role"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			role, err := parseStackRole(args[1])
+			if err != nil {
+				return err
+			}
+
+			s, access, err := requireStackAccessManager(stackName)
+			if err != nil {
+				return err
+			}
+
+			kind := stackPermissionKind(team)
+			if err = access.SetStackAccess(commandContext(), s.Name(), kind, args[0], role); err != nil {
+				return errors.Wrapf(err, "granting %s '%s' access", kind, args[0])
+			}
+			fmt.Printf("Granted %s '%s' the %s role on stack '%s'\n", kind, args[0], role, s.Name())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&team, "team", false, "The name is that of a team rather than a user")
+
+	return cmd
+}
+
+func newStackAccessRmCmd() *cobra.Command {
+	var stackName string
+	var team bool
+
+	cmd := &cobra.Command{
+		Use:   "rm <name>",
+		Short: "Revoke a user's or team's access to a stack",
+		Args:  cmdutil.SpecificArgs([]string{"name"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			s, access, err := requireStackAccessManager(stackName)
+			if err != nil {
+				return err
+			}
+
+			kind := stackPermissionKind(team)
+			if err = access.RemoveStackAccess(commandContext(), s.Name(), kind, args[0]); err != nil {
+				return errors.Wrapf(err, "revoking %s '%s' access", kind, args[0])
+			}
+			fmt.Printf("Revoked %s '%s' access to stack '%s'\n", kind, args[0], s.Name())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&team, "team", false, "The name is that of a team rather than a user")
+
+	return cmd
+}
+
+// requireStackAccessManager returns the indicated stack along with its backend's access control, or an error if the
+// backend does not support access control.
+func requireStackAccessManager(stackName string) (backend.Stack, backend.StackAccessManager, error) {
+	opts := backend.DisplayOptions{
+		Color: cmdutil.GetGlobalColorization(),
+	}
+
+	s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	am, ok := s.Backend().(backend.StackAccessManager)
+	if !ok || !s.Backend().Capabilities().Teams {
+		return nil, nil, errors.Errorf("the backend for stack '%s' does not support access control", s.Name())
+	}
+	return s, am, nil
+}
+
+// parseStackRole validates a role given on the command line.
+func parseStackRole(role string) (apitype.StackRole, error) {
+	switch r := apitype.StackRole(role); r {
+	case apitype.StackRoleRead, apitype.StackRoleWrite, apitype.StackRoleAdmin:
+		return r, nil
+	default:
+		return "", errors.Errorf("unknown role '%s'; expected 'read', 'write', or 'admin'", role)
+	}
+}
+
+// stackPermissionKind returns the kind of principal named by a stack access command.
+func stackPermissionKind(team bool) apitype.StackPermissionKind {
+	if team {
+		return apitype.StackPermissionTeam
+	}
+	return apitype.StackPermissionUser
+}
+
+// sortStackPermissions orders permissions by kind, listing users before teams, and then by name.
+func sortStackPermissions(perms []apitype.StackPermission) {
+	sort.Slice(perms, func(i, j int) bool {
+		if perms[i].Kind != perms[j].Kind {
+			return perms[i].Kind == apitype.StackPermissionUser
+		}
+		return perms[i].Name < perms[j].Name
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStackAuditCmd() *cobra.Command {
+	var jsonOut bool
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show the audit log of a stack",
+		Long: "Show the audit log of a stack\n" +
+			"\n" +
+			"This command lists the operations that changed, or tried to change, the stack, newest first:\n" +
+			"who ran each one, when, the command they ran, whether it succeeded, and a digest of the\n" +
+			"stack's configuration at the time.  The log is only ever appended to, and is kept on disk even\n" +
+			"after the stack is removed.\n" +
+			"\n" +
+			"Audit logs are kept by the local backend, alongside its checkpoints.  Stacks managed by the\n" +
+			"Pulumi Service should use the audit log in its console instead.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			reader, ok := s.Backend().(backend.StackAuditLogReader)
+			if !ok || !s.Backend().Capabilities().AuditLog {
+				return errors.Errorf("the backend for stack '%s' does not keep an audit log", s.Name())
+			}
+			entries, err := reader.GetAuditLog(commandContext(), s.Name())
+			if err != nil {
+				return errors.Wrap(err, "reading audit log")
+			}
+
+			if jsonOut {
+				if entries == nil {
+					entries = []backend.AuditLogEntry{}
+				}
+				b, err := json.MarshalIndent(entries, "", "    ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			if len(entries) == 0 {
+				fmt.Printf("Stack '%s' has no audit log entries\n", s.Name())
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tUSER\tKIND\tRESULT\tCONFIG\tCOMMAND")
+			for _, entry := range entries {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", time.Unix(entry.Time, 0).Format(time.RFC3339),
+					entry.User, entry.Kind, entry.Result, shortConfigDigest(entry.ConfigDigest), entry.Command)
+			}
+			return w.Flush()
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+// shortConfigDigest abbreviates a configuration digest for display, much as git abbreviates commit hashes.
+func shortConfigDigest(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
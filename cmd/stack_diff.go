@@ -0,0 +1,138 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStackDiffCmd() *cobra.Command {
+	var expectNop bool
+	var showSames bool
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "diff <other-stack>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Show the resource differences between this stack and another",
+		Long: "Show the resource differences between this stack and another.\n" +
+			"\n" +
+			"This command compares the current stack's checkpoint with that of another stack in the\n" +
+			"same project.  Resources are matched up by their URNs, ignoring the stack name.  The output\n" +
+			"lists resources that exist in only one of the two stacks, along with the input property\n" +
+			"differences for resources that exist in both.  This is useful for verifying that two\n" +
+			"stacks (e.g., staging and production) are at parity.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			other, err := requireStack(args[0], false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			base, err := s.Snapshot(commandContext())
+			if err != nil {
+				return err
+			}
+			otherSnap, err := other.Snapshot(commandContext())
+			if err != nil {
+				return err
+			}
+
+			diff, err := backend.DiffSnapshots(base, otherSnap)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Comparing stack '%s' with stack '%s':\n", s.Name(), other.Name())
+			printStackDiff(s.Name().String(), other.Name().String(), diff, showSames, opts)
+
+			if expectNop && diff.HasChanges() {
+				return errors.New("error: no differences were expected but differences were found")
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&expectNop, "expect-no-changes", false,
+		"Return an error if any differences are found between the two stacks")
+	cmd.PersistentFlags().BoolVar(
+		&showSames, "show-sames", false,
+		"Show resources that are identical in both stacks, alongside those that differ")
+
+	return cmd
+}
+
+// printStackDiff renders the differences between two stack snapshots to standard out.
+func printStackDiff(baseName, otherName string, diff *backend.SnapshotDiff, showSames bool,
+	opts backend.DisplayOptions) {
+
+	printRes := func(op deploy.StepOp, res *resource.State, suffix string) {
+		fmt.Print(opts.Color.Colorize(fmt.Sprintf("%s%s %s%s%s\n",
+			op.Prefix(), res.Type, res.URN.Name(), suffix, colors.Reset)))
+	}
+
+	for _, res := range diff.OnlyInBase {
+		printRes(deploy.OpDelete, res, fmt.Sprintf(" (only in %s)", baseName))
+	}
+	for _, res := range diff.OnlyInOther {
+		printRes(deploy.OpCreate, res, fmt.Sprintf(" (only in %s)", otherName))
+	}
+	for _, d := range diff.Changed {
+		printRes(deploy.OpUpdate, d.Base, "")
+		fmt.Print(opts.Color.Colorize(resourceDiffDetails(d)))
+	}
+	if showSames {
+		for _, d := range diff.Same {
+			printRes(deploy.OpSame, d.Base, "")
+		}
+	}
+
+	fmt.Printf("\n%d only in %s, %d only in %s, %d differing, %d identical\n",
+		len(diff.OnlyInBase), baseName, len(diff.OnlyInOther), otherName, len(diff.Changed), len(diff.Same))
+}
+
+// resourceDiffDetails renders the differences between the inputs of a resource's two states, using the same machinery
+// as update diffs.  The result contains color markup.
+func resourceDiffDetails(d backend.ResourceDiff) string {
+	old, new := engine.NewStepEventStateMetadata(d.Base, false), engine.NewStepEventStateMetadata(d.Other, false)
+	old.Outputs, new.Outputs = nil, nil
+	return engine.GetResourcePropertiesDetails(engine.StepEventMetadata{
+		Op:   deploy.OpUpdate,
+		URN:  d.Base.URN,
+		Type: d.Base.Type,
+		Old:  old,
+		New:  new,
+		Res:  new,
+	}, 0, false /*planning*/, true /*summary*/, false /*debug*/)
+}
@@ -15,18 +15,28 @@
 package cmd
 
 import (
+	"crypto/rand"
 	"encoding/json"
 	"os"
+	"sort"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/discovery"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 func newStackExportCmd() *cobra.Command {
 	var file string
+	var generateCode string
+	var hash bool
+	var redact bool
+	var redactNames []string
 	var stackName string
 
 	cmd := &cobra.Command{
@@ -38,7 +48,17 @@ func newStackExportCmd() *cobra.Command {
 			"The deployment can then be hand-edited and used to update the stack via\n" +
 			"`pulumi stack import`. This process may be used to correct inconsistencies\n" +
 			"in a stack's state due to failed deployments, manual changes to cloud\n" +
-			"resources, etc.",
+			"resources, etc.\n" +
+			"\n" +
+			"Pass --redact to produce a deployment that is safe to share, e.g. with support or in a\n" +
+			"bug report.  The values of the stack's secret configuration, and of any property whose\n" +
+			"name suggests that it is sensitive or matches --redact-name, are replaced while the\n" +
+			"structure of the deployment is preserved.  A redacted deployment should not be imported.\n" +
+			"\n" +
+			"Pass --generate-code with a language (yaml, nodejs, or python) to write, instead of the\n" +
+			"deployment, a program in that language that declares the stack's resources with their\n" +
+			"current inputs and options.  This is useful after importing resources, or to recover a\n" +
+			"program whose source was lost.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := backend.DisplayOptions{
 				Color: cmdutil.GetGlobalColorization(),
@@ -55,6 +75,41 @@ func newStackExportCmd() *cobra.Command {
 				return err
 			}
 
+			if redact {
+				redactOpts := stack.RedactOptions{
+					Names:   redactNames,
+					Secrets: secretConfigKeys(s),
+				}
+				if hash {
+					// Salt the hashes so that low-entropy secrets cannot be recovered by guessing.
+					redactOpts.HashSalt = make([]byte, 16)
+					if _, err = rand.Read(redactOpts.HashSalt); err != nil {
+						return err
+					}
+				}
+				if deployment, err = stack.RedactUntypedDeployment(deployment, redactOpts); err != nil {
+					return errors.Wrap(err, "could not redact deployment")
+				}
+			} else if hash || len(redactNames) > 0 {
+				return errors.New("--hash and --redact-name may only be used along with --redact")
+			}
+
+			// Generate a program in place of the deployment if asked to.
+			var program []byte
+			if generateCode != "" {
+				snap, err := stack.DeserializeUntypedDeployment(deployment)
+				if err != nil {
+					return errors.Wrap(err, "could not deserialize deployment")
+				}
+				proj, _, err := readProject()
+				if err != nil {
+					return err
+				}
+				if program, err = discovery.GenerateStackProgram(snap, proj.Name, generateCode); err != nil {
+					return err
+				}
+			}
+
 			// Read from stdin or a specified file.
 			writer := os.Stdout
 			if file != "" {
@@ -64,6 +119,13 @@ func newStackExportCmd() *cobra.Command {
 				}
 			}
 
+			if program != nil {
+				if _, err = writer.Write(program); err != nil {
+					return errors.Wrap(err, "could not write program")
+				}
+				return nil
+			}
+
 			// Write the deployment.
 			enc := json.NewEncoder(writer)
 			enc.SetIndent("", "    ")
@@ -77,5 +139,41 @@ func newStackExportCmd() *cobra.Command {
 		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
 	cmd.PersistentFlags().StringVarP(
 		&file, "file", "", "", "A filename to write stack output to")
+	cmd.PersistentFlags().StringVar(
+		&generateCode, "generate-code", "",
+		"Write a program in the given language (yaml, nodejs, or python) that declares the stack's resources")
+	cmd.PersistentFlags().BoolVar(
+		&redact, "redact", false, "Replace secret and sensitive property values so that the output is safe to share")
+	cmd.PersistentFlags().StringSliceVar(
+		&redactNames, "redact-name", []string{},
+		"Also redact the values of properties whose names contain the given text; may be given more than once")
+	cmd.PersistentFlags().BoolVar(
+		&hash, "hash", false,
+		"Replace redacted values with salted hashes, so that equal values can still be recognized")
 	return cmd
 }
+
+// secretConfigKeys returns the keys of the stack's secret configuration values, including those in the current
+// project's settings for the stack.
+func secretConfigKeys(s backend.Stack) []config.Key {
+	secrets := make(map[config.Key]bool)
+	for k, v := range s.Config() {
+		if v.Secure() {
+			secrets[k] = true
+		}
+	}
+	if stackConfig, err := workspace.DetectStackConfig(s.Name().StackName()); err == nil {
+		for k, v := range stackConfig {
+			if v.Secure() {
+				secrets[k] = true
+			}
+		}
+	}
+
+	keys := make([]config.Key, 0, len(secrets))
+	for k := range secrets {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys
+}
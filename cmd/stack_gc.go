@@ -0,0 +1,149 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/state"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newStackGCCmd() *cobra.Command {
+	var nonInteractive bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Args:  cmdutil.NoArgs,
+		Short: "Destroy and remove expired ephemeral stacks",
+		Long: "Destroy and remove expired ephemeral stacks.\n" +
+			"\n" +
+			"This command finds every stack of the current project that was initialized with\n" +
+			"`pulumi stack init --ephemeral` and has outlived its time-to-live.  After a single\n" +
+			"confirmation, each such stack's resources are destroyed, and the stack and its\n" +
+			"configuration are removed.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			interactive := isInteractive(nonInteractive)
+			opts, err := updateFlagsToOptions(interactive, false /*skipPreview*/, yes)
+			if err != nil {
+				return err
+			}
+			opts.Display = backend.DisplayOptions{
+				Color:         cmdutil.GetGlobalColorization(),
+				IsInteractive: interactive,
+			}
+
+			proj, root, err := readProject()
+			if err != nil {
+				return err
+			}
+			stacks, err := projectStacks("", proj, opts.Display)
+			if err != nil {
+				return err
+			}
+
+			// Find the expired ephemeral stacks.
+			var expired []backend.Stack
+			now := time.Now()
+			for _, s := range stacks {
+				ps, err := workspace.DetectProjectStack(s.Name().StackName())
+				if err != nil {
+					return err
+				}
+				if ps.TTL == nil || !ps.TTL.Ephemeral {
+					continue
+				}
+				snap, err := s.Snapshot(commandContext())
+				if err != nil {
+					return err
+				}
+				expiry, err := findExpired(ps.TTL, snap, now)
+				if err != nil {
+					return errors.Wrapf(err, "checking the time-to-live of stack '%s'", s.Name())
+				}
+				if expiry.Stack {
+					printExpired(s.Name().String(), expiry, now)
+					expired = append(expired, s)
+				}
+			}
+			if len(expired) == 0 {
+				fmt.Println("No ephemeral stacks have expired.")
+				return nil
+			}
+
+			// Ensure the user really wants to do this.  Having confirmed it once, the individual destroys proceed
+			// without asking again.
+			prompt := fmt.Sprintf("This will permanently destroy and remove %d stack(s)!", len(expired))
+			if !yes && !confirmPrompt(prompt, "yes", opts.Display) {
+				return errors.New("confirmation declined")
+			}
+			opts.AutoApprove = true
+
+			var current string
+			if w, err := workspace.New(); err == nil {
+				current = w.Settings().Stack
+			}
+			for _, s := range expired {
+				m, err := getUpdateMetadata("Destroyed after its time-to-live expired", root)
+				if err != nil {
+					return errors.Wrap(err, "gathering environment metadata")
+				}
+				opts.Engine = engine.UpdateOptions{Parallel: defaultParallel, ProviderOptions: providerOptions}
+				setGuardOptions(m, "", &opts.Engine)
+				if _, err = s.Destroy(commandContext(), proj, root, m, opts, cancellationScopes); err != nil {
+					return PrintEngineError(err)
+				}
+
+				if _, err = s.Remove(commandContext(), false); err != nil {
+					return errors.Wrapf(err, "removing stack '%s'", s.Name())
+				}
+				path, err := workspace.DetectProjectStackPath(s.Name().StackName())
+				if err != nil {
+					return err
+				}
+				if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+				if current == s.Name().String() {
+					if err = state.SetCurrentStack(""); err != nil {
+						return err
+					}
+				}
+
+				msg := fmt.Sprintf("%sStack '%s' has been removed!%s", colors.SpecAttention, s.Name(), colors.Reset)
+				fmt.Println(opts.Display.Color.Colorize(msg))
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVar(
+		&nonInteractive, "non-interactive", false, "Disable interactive mode")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and proceed with destruction and removal anyway")
+
+	return cmd
+}
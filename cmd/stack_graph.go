@@ -15,15 +15,19 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/graph"
 	"github.com/pulumi/pulumi/pkg/graph/dotconv"
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
-	"github.com/spf13/cobra"
 )
 
 // Whether or not we should ignore parent edges when building up our graph.
@@ -40,17 +44,25 @@ var parentEdgeColor string
 
 func newStackGraphCmd() *cobra.Command {
 	var stackName string
+	var explain string
 
 	cmd := &cobra.Command{
-		Use:   "graph",
-		Args:  cmdutil.ExactArgs(1),
+		Use:   "graph [filename]",
+		Args:  cmdutil.MaximumNArgs(1),
 		Short: "Export a stack's dependency graph to a file",
 		Long: "Export a stack's dependency graph to a file.\n" +
 			"\n" +
 			"This command can be used to view the dependency graph that a Pulumi program\n" +
 			"admitted when it was ran. This graph is output in the DOT format. This command operates\n" +
-			"on your stack's most recent deployment.",
+			"on your stack's most recent deployment.\n" +
+			"\n" +
+			"Pass --explain with a resource's URN to print, instead, every edge that touches the resource\n" +
+			"and why the engine believes it exists.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if explain == "" && len(args) != 1 {
+				return errors.New("a filename to write the graph to is required unless --explain is given")
+			}
+
 			opts := backend.DisplayOptions{
 				Color: cmdutil.GetGlobalColorization(),
 			}
@@ -64,6 +76,10 @@ func newStackGraphCmd() *cobra.Command {
 				return err
 			}
 
+			if explain != "" {
+				return printDependencyExplanation(snap, resource.URN(explain))
+			}
+
 			dg := makeDependencyGraph(snap)
 			file, err := os.Create(args[0])
 			if err != nil {
@@ -90,9 +106,118 @@ func newStackGraphCmd() *cobra.Command {
 		"Sets the color of dependency edges in the graph")
 	cmd.PersistentFlags().StringVar(&parentEdgeColor, "parent-edge-color", "#AA6639",
 		"Sets the color of parent edges in the graph")
+	cmd.PersistentFlags().StringVar(&explain, "explain", "",
+		"Print why the engine believes each dependency edge of the resource with the given URN exists")
 	return cmd
 }
 
+// dependencyExplanation records why the engine believes that one resource depends upon another.
+type dependencyExplanation struct {
+	From    resource.URN // the dependent resource.
+	To      resource.URN // the resource that it depends upon.
+	Reasons []string     // the reasons that the edge exists.
+}
+
+// explainDependencies returns an explanation of every edge in the snapshot's dependency graph that either starts or
+// ends at the live resource with the given URN.  The first list holds the resource's own dependencies, and the second
+// those of the resources that depend upon it.
+func explainDependencies(snap *deploy.Snapshot,
+	urn resource.URN) ([]dependencyExplanation, []dependencyExplanation, error) {
+
+	var target *resource.State
+	if snap != nil {
+		for _, res := range snap.Resources {
+			if res.URN == urn && !res.Delete {
+				target = res
+			}
+		}
+	}
+	if target == nil {
+		return nil, nil, errors.Errorf("no resource with URN %s was found in the stack", urn)
+	}
+
+	var dependencies, dependents []dependencyExplanation
+	for _, res := range snap.Resources {
+		if res.Delete {
+			continue
+		}
+		for _, edge := range explainEdges(res) {
+			if edge.From == urn {
+				dependencies = append(dependencies, edge)
+			} else if edge.To == urn {
+				dependents = append(dependents, edge)
+			}
+		}
+	}
+	return dependencies, dependents, nil
+}
+
+// explainEdges returns an explanation of each of the given resource's outgoing dependency edges, in the order that
+// the resources it depends upon are first mentioned by its state.
+func explainEdges(res *resource.State) []dependencyExplanation {
+	var edges []dependencyExplanation
+	index := make(map[resource.URN]int)
+	add := func(to resource.URN, reason string) {
+		i, has := index[to]
+		if !has {
+			i = len(edges)
+			index[to] = i
+			edges = append(edges, dependencyExplanation{From: res.URN, To: to})
+		}
+		edges[i].Reasons = append(edges[i].Reasons, reason)
+	}
+
+	name := res.URN.Name()
+	if res.Parent != "" {
+		add(res.Parent, fmt.Sprintf("%s is the parent of %s", res.Parent.Name(), name))
+	}
+	if res.Provider != "" {
+		if ref, err := providers.ParseReference(res.Provider); err == nil {
+			add(ref.URN(), fmt.Sprintf("%s is the provider that manages %s", ref.URN().Name(), name))
+		}
+	}
+	explicit := make(map[resource.URN]bool)
+	for _, dep := range res.DependsOn {
+		explicit[dep] = true
+	}
+	for _, dep := range res.Dependencies {
+		if explicit[dep] {
+			add(dep, fmt.Sprintf("%s lists %s in its dependsOn resource option", name, dep.Name()))
+		} else {
+			add(dep, fmt.Sprintf("the program reported that %s depends on %s, because one of its inputs was "+
+				"computed from one of the outputs of %s", name, dep.Name(), dep.Name()))
+		}
+	}
+	return edges
+}
+
+// printDependencyExplanation prints why the engine believes that each dependency edge of the given resource exists.
+func printDependencyExplanation(snap *deploy.Snapshot, urn resource.URN) error {
+	dependencies, dependents, err := explainDependencies(snap, urn)
+	if err != nil {
+		return err
+	}
+
+	printEdges := func(title string, edges []dependencyExplanation, other func(dependencyExplanation) resource.URN) {
+		fmt.Printf("%s:\n", title)
+		if len(edges) == 0 {
+			fmt.Printf("    (none)\n")
+		}
+		for _, edge := range edges {
+			fmt.Printf("    %s\n", other(edge))
+			for _, reason := range edge.Reasons {
+				fmt.Printf("        - %s\n", reason)
+			}
+		}
+	}
+	printEdges(fmt.Sprintf("Resources that %s depends on", urn), dependencies,
+		func(edge dependencyExplanation) resource.URN { return edge.To })
+	fmt.Println()
+	printEdges(fmt.Sprintf("Resources that depend on %s", urn), dependents,
+		func(edge dependencyExplanation) resource.URN { return edge.From })
+	return nil
+}
+
 // All of the types and code within this file are to provide implementations of the interfaces
 // in the `graph` package, so that we can use the `dotconv` package to output our graph in the
 // DOT format.
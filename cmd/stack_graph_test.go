@@ -0,0 +1,64 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestExplainDependencies(t *testing.T) {
+	urn := func(name string) resource.URN {
+		return resource.NewURN("test", "test", "", "pkgA:m:typA", tokens.QName(name))
+	}
+	newState := func(name string, parent resource.URN, deps ...resource.URN) *resource.State {
+		return resource.NewState("pkgA:m:typA", urn(name), false, false, "", resource.PropertyMap{}, nil, parent,
+			false, false, deps, nil, "")
+	}
+
+	resA := newState("resA", "")
+	resB := newState("resB", resA.URN)
+	resC := newState("resC", "", resA.URN, resB.URN)
+	resC.DependsOn = []resource.URN{resB.URN}
+	snap := deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{resA, resB, resC}, nil)
+
+	dependencies, dependents, err := explainDependencies(snap, resB.URN)
+	assert.NoError(t, err)
+	assert.Equal(t, []dependencyExplanation{{
+		From:    resB.URN,
+		To:      resA.URN,
+		Reasons: []string{"resA is the parent of resB"},
+	}}, dependencies)
+	assert.Equal(t, []dependencyExplanation{{
+		From:    resC.URN,
+		To:      resB.URN,
+		Reasons: []string{"resC lists resB in its dependsOn resource option"},
+	}}, dependents)
+
+	dependencies, dependents, err = explainDependencies(snap, resC.URN)
+	assert.NoError(t, err)
+	assert.Len(t, dependencies, 2)
+	assert.Equal(t, resA.URN, dependencies[0].To)
+	assert.Contains(t, dependencies[0].Reasons[0], "inputs was computed from one of the outputs of resA")
+	assert.Empty(t, dependents)
+
+	_, _, err = explainDependencies(snap, urn("missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,311 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStackHistoryCmd() *cobra.Command {
+	var diff bool
+	var showSames bool
+	var stackName string
+	var timings bool
+
+	cmd := &cobra.Command{
+		Use:   "history [--diff <from-version> <to-version>]",
+		Args:  cmdutil.MaximumNArgs(2),
+		Short: "Show the update history of a stack",
+		Long: "Show the update history of a stack.\n" +
+			"\n" +
+			"This command lists the stack's previous updates, newest first, along with who made them and\n" +
+			"a summary of the resources each one changed.  Pass --timings to also list the slowest resource\n" +
+			"operations of each update, to see which resources dominate deployment time.\n" +
+			"\n" +
+			"Pass --diff along with two versions to compare the stack as it was after each of them.  The\n" +
+			"output lists the updates that happened in between, followed by the resources that differ.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			if !s.Backend().Capabilities().History {
+				return errors.Errorf("the backend for stack '%s' does not keep a history of updates", s.Name())
+			}
+			updates, err := s.Backend().GetHistory(commandContext(), s.Name())
+			if err != nil {
+				return errors.Wrap(err, "getting stack history")
+			}
+
+			if !diff {
+				if len(args) != 0 {
+					return errors.New("versions may only be given along with --diff")
+				}
+				if len(updates) == 0 {
+					fmt.Printf("Stack '%s' has no updates\n", s.Name())
+					return nil
+				}
+				for _, update := range updates {
+					printUpdateSummary(update, opts)
+					if timings {
+						printUpdateTimings(update, opts)
+					}
+				}
+				return nil
+			}
+
+			if timings {
+				return errors.New("--timings may not be used along with --diff")
+			}
+			if len(args) != 2 {
+				return errors.New("--diff requires two versions to compare")
+			}
+			from, err := strconv.Atoi(args[0])
+			if err != nil {
+				return errors.Errorf("invalid version '%s'", args[0])
+			}
+			to, err := strconv.Atoi(args[1])
+			if err != nil {
+				return errors.Errorf("invalid version '%s'", args[1])
+			}
+
+			base, err := getHistoricalSnapshot(s, from)
+			if err != nil {
+				return err
+			}
+			other, err := getHistoricalSnapshot(s, to)
+			if err != nil {
+				return err
+			}
+
+			// Show the updates that took the stack from one version to the other, oldest first.
+			low, high := from, to
+			if low > high {
+				low, high = high, low
+			}
+			fmt.Printf("Updates between version %d and version %d:\n", low, high)
+			for i := len(updates) - 1; i >= 0; i-- {
+				if updates[i].Version > low && updates[i].Version <= high {
+					printUpdateSummary(updates[i], opts)
+				}
+			}
+
+			fromName, toName := fmt.Sprintf("version %d", from), fmt.Sprintf("version %d", to)
+			fmt.Printf("\nComparing %s with %s:\n", fromName, toName)
+			diff, err := backend.DiffSnapshots(base, other)
+			if err != nil {
+				return err
+			}
+			printStackDiff(fromName, toName, diff, showSames, opts)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&diff, "diff", false,
+		"Compare the resources of two versions of the stack")
+	cmd.PersistentFlags().BoolVar(
+		&showSames, "show-sames", false,
+		"When comparing versions, also show resources that did not change")
+	cmd.PersistentFlags().BoolVar(
+		&timings, "timings", false,
+		"Show the slowest resource operations of each update")
+
+	return cmd
+}
+
+// getHistoricalSnapshot loads the snapshot produced by the given version of a stack.
+func getHistoricalSnapshot(s backend.Stack, version int) (*deploy.Snapshot, error) {
+	deployment, err := s.Backend().GetHistoricalDeployment(commandContext(), s.Name(), version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting version %d of stack '%s'", version, s.Name())
+	}
+	snap, err := stack.DeserializeUntypedDeployment(deployment)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading version %d of stack '%s'", version, s.Name())
+	}
+	return snap, nil
+}
+
+// printUpdateSummary prints a single line describing an update from a stack's history.
+func printUpdateSummary(update backend.UpdateInfo, opts backend.DisplayOptions) {
+	when := "n/a"
+	if update.StartTime != 0 {
+		when = humanize.Time(time.Unix(update.StartTime, 0))
+	}
+
+	var changes []string
+	for _, op := range deploy.StepOps {
+		if op == deploy.OpSame {
+			continue
+		}
+		if c := update.ResourceChanges[op]; c > 0 {
+			changes = append(changes, fmt.Sprintf("%s%s%d%s", op.Color(), strings.TrimSpace(op.RawPrefix()), c, colors.Reset))
+		}
+	}
+	summary := strings.Join(changes, " ")
+	if summary == "" {
+		summary = "no changes"
+	}
+
+	line := fmt.Sprintf("%-4d %-8s %-10s %-16s %-20s %s", update.Version, update.Kind, update.Result, when,
+		updateAuthor(update), summary)
+	if update.Message != "" {
+		line += "  " + strings.SplitN(update.Message, "\n", 2)[0]
+	}
+	fmt.Println(opts.Color.Colorize(line))
+
+	// Follow the summary with any metadata the update was annotated with.
+	for _, kv := range updateAnnotations(update) {
+		fmt.Printf("     %s: %s\n", kv[0], kv[1])
+	}
+}
+
+// slowestUpdateSteps is the number of the slowest resource operations that --timings lists for each update.
+const slowestUpdateSteps = 10
+
+// printUpdateTimings prints the slowest resource operations of an update from a stack's history, along with the
+// total time that all of its operations took.
+func printUpdateTimings(update backend.UpdateInfo, opts backend.DisplayOptions) {
+	if len(update.StepTimings) == 0 {
+		if update.Kind != apitype.PreviewUpdate {
+			fmt.Println("     no timings recorded")
+		}
+		return
+	}
+
+	var total time.Duration
+	for _, t := range update.StepTimings {
+		total += t.Duration
+	}
+	operations := "operations"
+	if len(update.StepTimings) == 1 {
+		operations = "operation"
+	}
+	fmt.Printf("     %d %s took %v in total; slowest:\n", len(update.StepTimings), operations, roundTiming(total))
+
+	for _, t := range engine.SlowestSteps(update.StepTimings, slowestUpdateSteps) {
+		line := fmt.Sprintf("       %-10v %s%-20s%s %s %s", roundTiming(t.Duration), t.Op.Color(), t.Op, colors.Reset,
+			t.URN.Type(), t.URN.Name())
+		if t.Failed {
+			line += colors.SpecError + " (failed)" + colors.Reset
+		}
+		fmt.Println(opts.Color.Colorize(line))
+	}
+}
+
+// roundTiming rounds a duration to the millisecond, which is as precise as is useful when reading timings.
+func roundTiming(d time.Duration) time.Duration {
+	return d / time.Millisecond * time.Millisecond
+}
+
+// updateAnnotations returns the user metadata attached to an update, sorted by key, along with the reason its stack's
+// guard was overridden and the stack outputs it changed, if any.
+func updateAnnotations(update backend.UpdateInfo) [][2]string {
+	var keys []string
+	for key := range update.Environment {
+		if strings.HasPrefix(key, backend.UserMetadataPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var annotations [][2]string
+	for _, key := range keys {
+		annotations = append(annotations,
+			[2]string{strings.TrimPrefix(key, backend.UserMetadataPrefix), update.Environment[key]})
+	}
+	if reason := update.Environment[backend.GuardOverrideReason]; reason != "" {
+		annotations = append(annotations, [2]string{"guard overridden", reason})
+	}
+	if len(update.OutputChanges) > 0 {
+		annotations = append(annotations, [2]string{"outputs changed", strings.Join(update.OutputChanges, ", ")})
+	}
+	if cli := updateCLIVersion(update); cli != "" {
+		annotations = append(annotations, [2]string{"cli", cli})
+	}
+	if plugins := updatePluginVersions(update); len(plugins) > 0 {
+		annotations = append(annotations, [2]string{"plugins", strings.Join(plugins, ", ")})
+	}
+	return annotations
+}
+
+// updateCLIVersion returns the version of the CLI that performed an update and the platform on which it ran, as
+// recorded in its environment, or "" if they were not recorded.
+func updateCLIVersion(update backend.UpdateInfo) string {
+	ver := update.Environment[backend.PulumiVersion]
+	if ver == "" {
+		return ""
+	}
+	if goos, goarch := update.Environment[backend.PulumiOS], update.Environment[backend.PulumiArch]; goos != "" {
+		ver = fmt.Sprintf("%s (%s/%s)", ver, goos, goarch)
+	}
+	return ver
+}
+
+// updatePluginVersions returns the plugins that an update loaded, along with their versions if known, as recorded in
+// its environment, sorted by kind and name.
+func updatePluginVersions(update backend.UpdateInfo) []string {
+	var keys []string
+	for key := range update.Environment {
+		if strings.HasPrefix(key, backend.PluginVersionPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var plugins []string
+	for _, key := range keys {
+		plugin := strings.Replace(strings.TrimPrefix(key, backend.PluginVersionPrefix), ".", " ", 1)
+		if ver := update.Environment[key]; ver != "" {
+			plugin += " " + ver
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins
+}
+
+// updateAuthor returns the person responsible for an update, as recorded in its environment.
+func updateAuthor(update backend.UpdateInfo) string {
+	for _, key := range []string{backend.GitAuthor, backend.GitCommitter, backend.GitHubLogin} {
+		if who := update.Environment[key]; who != "" {
+			return who
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,66 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/version"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestUpdateAnnotations(t *testing.T) {
+	env := map[string]string{backend.GitHead: "abc123"}
+	err := addUserMetadataToEnvironment([]string{"ticket=OPS-42", "reviewer=alice", "note=a=b"}, env)
+	assert.NoError(t, err)
+	env[backend.GuardOverrideReason] = "hotfix"
+
+	annotations := updateAnnotations(backend.UpdateInfo{Environment: env})
+	assert.Equal(t, [][2]string{
+		{"note", "a=b"},
+		{"reviewer", "alice"},
+		{"ticket", "OPS-42"},
+		{"guard overridden", "hotfix"},
+	}, annotations)
+
+	assert.Error(t, addUserMetadataToEnvironment([]string{"ticket"}, env))
+	assert.Error(t, addUserMetadataToEnvironment([]string{"=value"}, env))
+}
+
+func TestUpdateAnnotationsVersions(t *testing.T) {
+	env := map[string]string{}
+	addCLIMetadataToEnvironment(env)
+	assert.Equal(t, version.Version, env[backend.PulumiVersion])
+
+	awsVersion := semver.MustParse("0.15.2")
+	backend.AddPluginsToEnvironment([]workspace.PluginInfo{
+		{Name: "nodejs", Kind: workspace.LanguagePlugin},
+		{Name: "aws", Kind: workspace.ResourcePlugin, Version: &awsVersion},
+	}, env)
+	env[backend.PulumiVersion], env[backend.PulumiOS], env[backend.PulumiArch] = "v0.15.0", "linux", "amd64"
+
+	annotations := updateAnnotations(backend.UpdateInfo{Environment: env})
+	assert.Equal(t, [][2]string{
+		{"cli", "v0.15.0 (linux/amd64)"},
+		{"plugins", "language nodejs, resource aws 0.15.2"},
+	}, annotations)
+
+	// Updates that did not record versions show none.
+	assert.Empty(t, updateAnnotations(backend.UpdateInfo{}))
+}
@@ -15,16 +15,26 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/cloud"
+	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
+// defaultEphemeralTTL is the time-to-live of an ephemeral stack whose time-to-live is not given.
+const defaultEphemeralTTL = "7d"
+
 func newStackInitCmd() *cobra.Command {
 	var ppc string
+	var copyConfigFrom string
+	var ephemeral bool
+	var ttl string
 	cmd := &cobra.Command{
 		Use:   "init <stack-name>",
 		Args:  cmdutil.MaximumNArgs(1),
@@ -32,7 +42,12 @@ func newStackInitCmd() *cobra.Command {
 		Long: "Create an empty stack with the given name, ready for updates\n" +
 			"\n" +
 			"This command creates an empty stack with the given name.  It has no resources,\n" +
-			"but afterwards it can become the target of a deployment using the `update` command.",
+			"but afterwards it can become the target of a deployment using the `update` command.\n" +
+			"\n" +
+			"Short-lived stacks, e.g. those that review a pull request, are easily made by copying the\n" +
+			"configuration of another stack with --copy-config-from and passing --ephemeral.  An ephemeral\n" +
+			"stack expires once --ttl has passed since its last update, after which `pulumi stack gc`\n" +
+			"destroys and removes it.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := backend.DisplayOptions{
 				Color: cmdutil.GetGlobalColorization(),
@@ -70,11 +85,104 @@ func newStackInitCmd() *cobra.Command {
 				return err
 			}
 
-			_, err = createStack(b, stackRef, createOpts, true /*setCurrent*/)
-			return err
+			if ttl != "" {
+				if _, err = parseTTL(ttl); err != nil {
+					return err
+				}
+			} else if ephemeral {
+				ttl = defaultEphemeralTTL
+			}
+
+			// Look up the stack whose configuration is to be copied before creating the new one.
+			var source backend.Stack
+			if copyConfigFrom != "" {
+				if source, err = requireStack(copyConfigFrom, false, opts, false /*setCurrent*/); err != nil {
+					return err
+				}
+			}
+
+			s, err := createStack(b, stackRef, createOpts, true /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			if source == nil && ttl == "" {
+				return nil
+			}
+
+			// Copy the configuration before loading the new stack's settings, since getting its key to encrypt
+			// secrets may itself save them.
+			var cfg config.Map
+			if source != nil {
+				if cfg, err = copyStackConfig(source, s); err != nil {
+					return errors.Wrapf(err, "copying the configuration of stack '%s'", source.Name())
+				}
+			}
+			ps, err := workspace.DetectProjectStack(s.Name().StackName())
+			if err != nil {
+				return err
+			}
+			for k, v := range cfg {
+				ps.Config[k] = v
+			}
+			if ttl != "" {
+				if ps.TTL == nil {
+					ps.TTL = &workspace.StackTTL{}
+				}
+				ps.TTL.Stack, ps.TTL.Ephemeral = ttl, ephemeral
+				ps.TTL.Initialized = time.Now().UTC().Format(time.RFC3339)
+			}
+			return workspace.SaveProjectStack(s.Name().StackName(), ps)
 		}),
 	}
 	cmd.PersistentFlags().StringVarP(
 		&ppc, "ppc", "p", "", "An optional Pulumi Private Cloud (PPC) name to initialize this stack in")
+	cmd.PersistentFlags().StringVar(
+		&copyConfigFrom, "copy-config-from", "",
+		"Copy the configuration of the given stack, re-encrypting its secrets for the new stack")
+	cmd.PersistentFlags().BoolVar(
+		&ephemeral, "ephemeral", false,
+		"Mark the stack as short-lived, so that `pulumi stack gc` destroys and removes it once it expires")
+	cmd.PersistentFlags().StringVar(
+		&ttl, "ttl", "",
+		"How long after its last update, e.g. 72h or 7d, the stack expires (defaults to "+defaultEphemeralTTL+
+			" for an ephemeral stack)")
 	return cmd
 }
+
+// copyStackConfig returns a copy of the configuration of the source stack for use by the destination stack.  Secrets
+// are decrypted with the source stack's key and encrypted anew with that of the destination.
+func copyStackConfig(source, dest backend.Stack) (config.Map, error) {
+	ps, err := workspace.DetectProjectStack(source.Name().StackName())
+	if err != nil {
+		return nil, err
+	}
+
+	var decrypter, encrypter config.Crypter
+	c := make(config.Map)
+	for k, v := range ps.Config {
+		if !v.Secure() {
+			c[k] = v
+			continue
+		}
+
+		// Lazily get the crypters, only if needed, to avoid prompting for a password with the local backend.
+		if decrypter == nil {
+			if decrypter, err = backend.GetStackCrypter(source); err != nil {
+				return nil, err
+			}
+			if encrypter, err = backend.GetStackCrypter(dest); err != nil {
+				return nil, err
+			}
+		}
+		plaintext, err := v.Value(decrypter)
+		if err != nil {
+			return nil, err
+		}
+		enc, err := encrypter.EncryptValue(plaintext)
+		if err != nil {
+			return nil, err
+		}
+		c[k] = config.NewSecureValue(enc)
+	}
+	return c, nil
+}
@@ -15,16 +15,20 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
-	"github.com/pulumi/pulumi/pkg/backend/cloud"
 	"github.com/pulumi/pulumi/pkg/backend/state"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
@@ -34,10 +38,31 @@ import (
 
 func newStackLsCmd() *cobra.Command {
 	var allStacks bool
+	var filters []string
+	var jsonOut bool
+	var maxResults int
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List all known stacks",
-		Args:  cmdutil.NoArgs,
+		Long: "List all known stacks\n" +
+			"\n" +
+			"By default, only the stacks of the current project are listed; pass --all to list every stack.\n" +
+			"\n" +
+			"Pass --json to print the stacks as a JSON array, in the same form for every backend: each\n" +
+			"stack's name, whether it is current, when it was last updated, whether an update is in\n" +
+			"progress, its resource count, URL, and tags.  Stacks are fetched from the backend a page at\n" +
+			"a time, so that even very large organizations can be listed.\n" +
+			"\n" +
+			"Pass --filter, any number of times, to list only the stacks that match every filter; backends\n" +
+			"that can, such as the Pulumi Service, apply the filters themselves.  The filters are:\n" +
+			"\n" +
+			"    project=<name>           stacks of the given project\n" +
+			"    tag=<name>[=<value>]     stacks with the given tag, optionally with the given value\n" +
+			"    updated-before=<time>    stacks last updated before an RFC3339 time or YYYY-MM-DD date,\n" +
+			"                             or longer ago than a duration such as 720h\n" +
+			"\n" +
+			"Pass --max-results to stop listing after that many stacks.",
+		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			// Ensure we are in a project; if not, we will fail.
 			projPath, err := workspace.DetectProjectPath()
@@ -69,51 +94,102 @@ func newStackLsCmd() *cobra.Command {
 				current = s.Name().String()
 			}
 
-			var packageFilter *tokens.PackageName
+			var filter backend.StackFilter
 			if !allStacks {
-				packageFilter = &proj.Name
+				filter.Project = &proj.Name
 			}
-
-			// Now produce a list of summaries, and enumerate them sorted by name.
-			var result error
-			var stackNames []string
-			stacks := make(map[string]backend.Stack)
-			bs, err := b.ListStacks(commandContext(), packageFilter)
-			if err != nil {
+			if err = parseStackFilters(filters, &filter, time.Now()); err != nil {
 				return err
 			}
-			_, showURLColumn := b.(cloud.Backend)
+			if filter.TagName != "" && !b.Capabilities().Tags {
+				return errors.Errorf("the %s backend does not support tagging stacks", b.Name())
+			}
 
-			for _, stack := range bs {
-				name := stack.Name().String()
-				stacks[name] = stack
-				stackNames = append(stackNames, name)
+			// Listings are fetched a page at a time, and filtered by the backend, so they are used whenever only
+			// some of the stacks are wanted.  Otherwise, the stacks are fetched all at once, and listed from their
+			// snapshots.
+			var listings []apitype.StackListing
+			stacks := make(map[string]backend.Stack)
+			if jsonOut || len(filters) > 0 || maxResults > 0 {
+				lister, ok := b.(backend.StackLister)
+				if !ok {
+					return errors.Errorf("the %s backend does not support listing stacks by page", b.Name())
+				}
+				listings, err = collectStackListings(
+					func(token string, pageSize int) (*apitype.ListStackListingsResponse, error) {
+						return lister.ListStackListings(commandContext(), filter, token, pageSize)
+					}, maxResults)
+				if err != nil {
+					return err
+				}
+
+				if jsonOut {
+					for i := range listings {
+						listings[i].Current = listings[i].Name == current
+					}
+					out, err := json.MarshalIndent(listings, "", "    ")
+					if err != nil {
+						return err
+					}
+					fmt.Println(string(out))
+					return nil
+				}
+			} else {
+				bs, err := b.ListStacks(commandContext(), filter.Project)
+				if err != nil {
+					return err
+				}
+				for _, stack := range bs {
+					listing := stackListing(commandContext(), stack)
+					stacks[listing.Name] = stack
+					listings = append(listings, listing)
+				}
 			}
-			sort.Strings(stackNames)
+			sort.Slice(listings, func(i, j int) bool { return listings[i].Name < listings[j].Name })
+			showURLColumn := b.Capabilities().Console
 
 			// Devote 48 characters to the name width, unless there is a longer name.
 			maxname := 48
-			for _, name := range stackNames {
-				if len(name) > maxname {
-					maxname = len(name)
+			for _, listing := range listings {
+				if len(listing.Name) > maxname {
+					maxname = len(listing.Name)
 				}
 			}
 
-			// We have to fault in snapshots for all the stacks we are going to list here, because that's the easiest
-			// way to get the last update time and the resource count.  Since this is an expensive operation, we'll
-			// do it before printing any output so the latency happens all at once instead of line by line.
-			//
-			// TODO[pulumi/pulumi-service#1530]: We need a lighterweight way of fetching just the specific information
-			// we want to display here.
-			for _, name := range stackNames {
-				stack := stacks[name]
-				_, err := stack.Snapshot(commandContext())
-				contract.IgnoreError(err) // If we couldn't get snapshot for the stack don't fail the overall listing.
+			// Describe the time-to-live of each stack of the current project that has one.  The settings of stacks
+			// from other projects are not at hand.  Only the stacks with a time-to-live need their snapshots.
+			ttls := make(map[string]string)
+			showTTLColumn := false
+			if !allStacks {
+				now := time.Now()
+				for _, listing := range listings {
+					stack := stacks[listing.Name]
+					if stack == nil {
+						stack, err = lookupListedStack(b, listing.Name)
+						if err != nil || stack == nil {
+							continue // If we couldn't find the stack don't fail the overall listing.
+						}
+					}
+					ps, err := workspace.DetectProjectStack(stack.Name().StackName())
+					if err != nil || ps.TTL == nil {
+						continue // If we couldn't load the stack's settings don't fail the overall listing.
+					}
+					snap, err := stack.Snapshot(commandContext())
+					contract.IgnoreError(err)
+					if ttls[listing.Name] = describeTTL(ps.TTL, snap, now); ttls[listing.Name] != "" {
+						showTTLColumn = true
+					}
+				}
 			}
 
 			formatDirective := "%-" + strconv.Itoa(maxname) + "s %-24s %-18s"
 			headers := []interface{}{"NAME", "LAST UPDATE", "RESOURCE COUNT"}
 
+			if showTTLColumn {
+				formatDirective += " %-32s"
+				headers = append(headers, "EXPIRES")
+			}
+
 			if showURLColumn {
 				formatDirective += " %s"
 				headers = append(headers, "URL")
@@ -122,9 +198,9 @@ func newStackLsCmd() *cobra.Command {
 			formatDirective = formatDirective + "\n"
 
 			fmt.Printf(formatDirective, headers...)
-			for _, name := range stackNames {
+			for _, listing := range listings {
 				// Mark the name as current '*' if we've selected it.
-				stack := stacks[name]
+				name := listing.Name
 				if name == current {
 					name += "*"
 				}
@@ -132,25 +208,24 @@ func newStackLsCmd() *cobra.Command {
 				// Get last deployment info, provided that it exists.
 				none := "n/a"
 				lastUpdate := none
+				if t, err := time.Parse(time.RFC3339, listing.LastUpdate); err == nil {
+					lastUpdate = humanize.Time(t)
+				}
 				resourceCount := none
-				snap, err := stack.Snapshot(commandContext())
-				contract.IgnoreError(err) // If we couldn't get snapshot for the stack don't fail the overall listing.
-
-				if snap != nil {
-					if t := snap.Manifest.Time; !t.IsZero() {
-						lastUpdate = humanize.Time(t)
-					}
-					resourceCount = strconv.Itoa(len(snap.Resources))
+				if listing.ResourceCount != nil {
+					resourceCount = strconv.Itoa(*listing.ResourceCount)
 				}
 
 				values := []interface{}{name, lastUpdate, resourceCount}
-				if showURLColumn {
-					var url string
-					if cs, ok := stack.(cloud.Stack); ok {
-						if u, urlErr := cs.ConsoleURL(); urlErr == nil {
-							url = u
-						}
+				if showTTLColumn {
+					ttl := ttls[listing.Name]
+					if ttl == "" {
+						ttl = none
 					}
+					values = append(values, ttl)
+				}
+				if showURLColumn {
+					url := listing.URL
 					if url == "" {
 						url = none
 					}
@@ -160,11 +235,126 @@ func newStackLsCmd() *cobra.Command {
 				fmt.Printf(formatDirective, values...)
 			}
 
-			return result
+			return nil
 		}),
 	}
 	cmd.PersistentFlags().BoolVarP(
 		&allStacks, "all", "a", false, "List all stacks instead of just stacks for the current project")
+	cmd.PersistentFlags().StringArrayVar(
+		&filters, "filter", nil, "Only list stacks that match a filter, e.g. 'tag=env=prod'; may be repeated")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+	cmd.PersistentFlags().IntVar(
+		&maxResults, "max-results", 0, "List at most this many stacks")
 
 	return cmd
 }
+
+// parseStackFilters adds the filters given to `pulumi stack ls --filter` to the given stack filter.  Durations given
+// for updated-before are measured back from now.
+func parseStackFilters(filters []string, filter *backend.StackFilter, now time.Time) error {
+	for _, f := range filters {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return errors.Errorf("invalid filter '%s': expected <kind>=<value>", f)
+		}
+		switch kind, value := kv[0], kv[1]; kind {
+		case "project":
+			project := tokens.PackageName(value)
+			filter.Project = &project
+		case "tag":
+			tag := strings.SplitN(value, "=", 2)
+			filter.TagName = tag[0]
+			filter.TagValue = nil
+			if len(tag) == 2 {
+				filter.TagValue = &tag[1]
+			}
+		case "updated-before":
+			before, err := parseUpdatedBefore(value, now)
+			if err != nil {
+				return errors.Wrapf(err, "invalid filter '%s'", f)
+			}
+			filter.LastUpdatedBefore = &before
+		default:
+			return errors.Errorf("unknown filter '%s': expected project, tag, or updated-before", kind)
+		}
+	}
+	return nil
+}
+
+// parseUpdatedBefore parses an RFC3339 time, a YYYY-MM-DD date, or a duration before now.
+func parseUpdatedBefore(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, errors.Errorf("'%s' is not a time, a date, or a positive duration", value)
+}
+
+// collectStackListings fetches pages of a backend's stack listings, following continuation tokens until there are no
+// more pages or, if maxResults is positive, until that many stacks have been listed.
+func collectStackListings(
+	fetch func(continuationToken string, pageSize int) (*apitype.ListStackListingsResponse, error),
+	maxResults int) ([]apitype.StackListing, error) {
+
+	listings := []apitype.StackListing{}
+	var token string
+	for {
+		// Ask for no more stacks than are still wanted, so that the backend need not produce more.
+		var pageSize int
+		if maxResults > 0 {
+			pageSize = maxResults - len(listings)
+		}
+
+		page, err := fetch(token, pageSize)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing stacks")
+		}
+		listings = append(listings, page.Stacks...)
+		if maxResults > 0 && len(listings) >= maxResults {
+			return listings[:maxResults], nil
+		}
+		if page.ContinuationToken == "" {
+			return listings, nil
+		}
+		if page.ContinuationToken == token {
+			return nil, errors.New("listing stacks: the backend returned the same page twice")
+		}
+		token = page.ContinuationToken
+	}
+}
+
+// stackListing describes a stack in the same way that a backend's listings do, faulting in its snapshot to find when
+// it was last updated and how many resources it has.
+func stackListing(ctx context.Context, stack backend.Stack) apitype.StackListing {
+	listing := apitype.StackListing{Name: stack.Name().String()}
+	snap, err := stack.Snapshot(ctx)
+	contract.IgnoreError(err) // If we couldn't get snapshot for the stack don't fail the overall listing.
+	if snap != nil {
+		if t := snap.Manifest.Time; !t.IsZero() {
+			listing.LastUpdate = t.UTC().Format(time.RFC3339)
+		}
+		count := len(snap.Resources)
+		listing.ResourceCount = &count
+	}
+	if cs, ok := stack.(backend.ConsoleLinkedStack); ok {
+		if url, err := cs.ConsoleURL(); err == nil {
+			listing.URL = url
+		}
+	}
+	return listing
+}
+
+// lookupListedStack returns the stack that a backend listed under the given name.
+func lookupListedStack(b backend.Backend, name string) (backend.Stack, error) {
+	ref, err := b.ParseStackReference(name)
+	if err != nil {
+		return nil, err
+	}
+	return b.GetStack(commandContext(), ref)
+}
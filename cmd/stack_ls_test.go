@@ -0,0 +1,125 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestCollectStackListings(t *testing.T) {
+	pages := map[string]*apitype.ListStackListingsResponse{
+		"": {
+			Stacks:            []apitype.StackListing{{Name: "a"}, {Name: "b"}},
+			ContinuationToken: "b",
+		},
+		"b": {
+			Stacks: []apitype.StackListing{{Name: "c"}},
+		},
+	}
+	var tokens []string
+	fetch := func(token string, pageSize int) (*apitype.ListStackListingsResponse, error) {
+		tokens = append(tokens, token)
+		return pages[token], nil
+	}
+	listings, err := collectStackListings(fetch, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "b"}, tokens)
+	assert.Equal(t, []apitype.StackListing{{Name: "a"}, {Name: "b"}, {Name: "c"}}, listings)
+
+	// Listing stops as soon as enough stacks have been listed, and asks for no more than that.
+	tokens = nil
+	var pageSizes []int
+	listings, err = collectStackListings(func(token string, pageSize int) (*apitype.ListStackListingsResponse, error) {
+		pageSizes = append(pageSizes, pageSize)
+		return fetch(token, pageSize)
+	}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{""}, tokens)
+	assert.Equal(t, []int{1}, pageSizes)
+	assert.Equal(t, []apitype.StackListing{{Name: "a"}}, listings)
+
+	// An empty backend lists as an empty array rather than null.
+	listings, err = collectStackListings(func(string, int) (*apitype.ListStackListingsResponse, error) {
+		return &apitype.ListStackListingsResponse{}, nil
+	}, 0)
+	assert.NoError(t, err)
+	assert.NotNil(t, listings)
+	assert.Empty(t, listings)
+
+	// Errors, and backends that never stop paging, are reported.
+	_, err = collectStackListings(func(string, int) (*apitype.ListStackListingsResponse, error) {
+		return nil, errors.New("boom")
+	}, 0)
+	assert.Error(t, err)
+	_, err = collectStackListings(func(string, int) (*apitype.ListStackListingsResponse, error) {
+		return &apitype.ListStackListingsResponse{ContinuationToken: "again"}, nil
+	}, 0)
+	assert.Error(t, err)
+}
+
+func TestParseStackFilters(t *testing.T) {
+	now := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+	project := tokens.PackageName("current")
+
+	filter := backend.StackFilter{Project: &project}
+	err := parseStackFilters([]string{"project=other", "tag=pulumi:githubOwner=pulumi", "updated-before=720h"},
+		&filter, now)
+	assert.NoError(t, err)
+	assert.Equal(t, tokens.PackageName("other"), *filter.Project)
+	assert.Equal(t, "pulumi:githubOwner", filter.TagName)
+	assert.Equal(t, "pulumi", *filter.TagValue)
+	assert.Equal(t, time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC), *filter.LastUpdatedBefore)
+
+	// A tag may be filtered on without a value, and times may be given as dates or RFC3339 times.
+	filter = backend.StackFilter{}
+	assert.NoError(t, parseStackFilters([]string{"tag=env", "updated-before=2018-01-02"}, &filter, now))
+	assert.Equal(t, "env", filter.TagName)
+	assert.Nil(t, filter.TagValue)
+	assert.Equal(t, time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC), *filter.LastUpdatedBefore)
+	assert.NoError(t, parseStackFilters([]string{"updated-before=2018-01-02T03:04:05Z"}, &filter, now))
+	assert.Equal(t, time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC), *filter.LastUpdatedBefore)
+
+	for _, bad := range []string{"project", "project=", "owner=me", "updated-before=soon", "updated-before=-1h"} {
+		assert.Error(t, parseStackFilters([]string{bad}, &filter, now), bad)
+	}
+}
+
+func TestStackListing(t *testing.T) {
+	// A stack without a snapshot has neither a last update nor a resource count.
+	listing := stackListing(context.Background(), &dashboardTestStack{})
+	assert.Equal(t, apitype.StackListing{Name: "dev"}, listing)
+
+	updated := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	snap := deploy.NewSnapshot(deploy.Manifest{Time: updated}, []*resource.State{
+		{Type: "pulumi:pulumi:Stack", URN: "urn:pulumi:dev::proj::pulumi:pulumi:Stack::proj-dev"},
+	}, nil)
+	listing = stackListing(context.Background(), &dashboardTestStack{snap: snap})
+	assert.Equal(t, "dev", listing.Name)
+	assert.Equal(t, "2018-06-01T12:00:00Z", listing.LastUpdate)
+	if assert.NotNil(t, listing.ResourceCount) {
+		assert.Equal(t, 1, *listing.ResourceCount)
+	}
+}
@@ -15,18 +15,40 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/state"
 	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/glob"
+)
+
+// outputFormat is a machine-readable format in which stack outputs may be printed.
+type outputFormat string
+
+const (
+	outputFormatJSON   outputFormat = "json"   // a JSON object whose values keep their types.
+	outputFormatShell  outputFormat = "shell"  // `export NAME='value'` lines for a POSIX shell.
+	outputFormatDotenv outputFormat = "dotenv" // `NAME="value"` lines for a .env file.
 )
 
 func newStackOutputCmd() *cobra.Command {
 	var stackName string
+	var jsonOut bool
+	var shell bool
+	var dotenv bool
+	var filters []string
+	var showSecrets bool
 	cmd := &cobra.Command{
 		Use:   "output [property-name]",
 		Args:  cmdutil.MaximumNArgs(1),
@@ -34,24 +56,42 @@ func newStackOutputCmd() *cobra.Command {
 		Long: "Show a stack's output properties.\n" +
 			"\n" +
 			"By default, this command lists all output properties exported from a stack.\n" +
-			"If a specific property-name is supplied, just that property's value is shown.",
+			"If a specific property-name is supplied, just that property's value is shown.\n" +
+			"\n" +
+			"So that scripts can consume them, the outputs may instead be printed as a JSON object\n" +
+			"with --json, as shell `export` lines with --shell, or as the lines of a .env file with\n" +
+			"--dotenv.  The latter two name each variable after its output in upper snake case, e.g.\n" +
+			"bucketName becomes BUCKET_NAME.  Use --filter to print only the outputs whose names match\n" +
+			"a pattern.  Outputs that look like secrets, such as a dbPassword, are left out of these\n" +
+			"formats unless --show-secrets is passed or the output is asked for by name.\n" +
+			"\n" +
+			"The stack may live in a backend other than the current one if it is named by a URI of the\n" +
+			"form <backend-url>#<stack>, e.g. `local:///var/pulumi#dev` or `https://api.pulumi.com#acme/dev`.\n" +
+			"The credentials stored for that backend by `pulumi login` are used to read it, or else the\n" +
+			"token in PULUMI_ACCESS_TOKEN, which may be an output token created with\n" +
+			"`pulumi stack output-token create`.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := backend.DisplayOptions{
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
-			// Fetch the current stack and its output properties.
-			s, err := requireStack(stackName, false, opts, true /*setCurrent*/)
-			if err != nil {
-				return err
+			var format outputFormat
+			for f, set := range map[outputFormat]bool{
+				outputFormatJSON: jsonOut, outputFormatShell: shell, outputFormatDotenv: dotenv} {
+				if set {
+					if format != "" {
+						return errors.New("only one of --json, --shell, and --dotenv may be passed")
+					}
+					format = f
+				}
 			}
-			snap, err := s.Snapshot(commandContext())
+
+			// Fetch the stack's output properties.
+			outputs, err := readStackOutputs(stackName, opts)
 			if err != nil {
 				return err
 			}
-
-			res, outputs := stack.GetRootStackResource(snap)
-			if res == nil || outputs == nil {
+			if outputs == nil {
 				return errors.New("current stack has no output properties")
 			}
 
@@ -59,18 +99,191 @@ func newStackOutputCmd() *cobra.Command {
 			if len(args) > 0 {
 				name := args[0]
 				v, has := outputs[name]
-				if has {
-					fmt.Printf("%v\n", stringifyOutput(v))
-				} else {
+				if !has {
 					return errors.Errorf("current stack does not have output property '%v'", name)
 				}
-			} else {
+				if format == outputFormatJSON {
+					return writeJSONValue(os.Stdout, v)
+				} else if format != "" {
+					return writeOutputs(os.Stdout, map[string]interface{}{name: v}, format)
+				}
+				fmt.Printf("%v\n", stringifyOutput(v))
+				return nil
+			}
+
+			outputs = filterOutputs(outputs, filters)
+			if format == "" {
 				printStackOutputs(outputs)
+				return nil
+			}
+			if !showSecrets {
+				var hidden []string
+				outputs, hidden = hideSecretOutputs(outputs)
+				if len(hidden) > 0 {
+					fmt.Fprintf(os.Stderr, "warning: leaving out output(s) that look like secrets: %s; "+
+						"pass --show-secrets to include them\n", strings.Join(hidden, ", "))
+				}
 			}
-			return nil
+			return writeOutputs(os.Stdout, outputs, format)
 		}),
 	}
 	cmd.PersistentFlags().StringVarP(
-		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+		&stackName, "stack", "s", "",
+		"The name of the stack to operate on, or a <backend-url>#<stack> URI. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit the outputs as a JSON object whose values keep their types")
+	cmd.PersistentFlags().BoolVar(
+		&shell, "shell", false, "Emit the outputs as `export NAME='value'` lines for a POSIX shell")
+	cmd.PersistentFlags().BoolVar(
+		&dotenv, "dotenv", false, "Emit the outputs as the `NAME=\"value\"` lines of a .env file")
+	cmd.PersistentFlags().StringSliceVar(
+		&filters, "filter", nil,
+		"Only show the outputs whose names match one of the given patterns, in which `*` matches anything")
+	cmd.PersistentFlags().BoolVar(
+		&showSecrets, "show-secrets", false,
+		"Include outputs that look like secrets when emitting --json, --shell, or --dotenv")
 	return cmd
 }
+
+// readStackOutputs returns the outputs of the indicated stack.  A stack URI is resolved against the backend it names,
+// and is not selected as the current stack.  If that backend can read a stack's outputs without reading its state, it
+// is asked to, so that the outputs may be read with one of the stack's output tokens.
+func readStackOutputs(stackName string, opts backend.DisplayOptions) (map[string]interface{}, error) {
+	var s backend.Stack
+	if state.IsStackURI(stackName) {
+		b, ref, err := state.ResolveStackReference(cmdutil.Diag(), nil, stackName)
+		if err != nil {
+			return nil, err
+		}
+		defer closeBackend(b)
+		if reader, ok := b.(backend.StackOutputsReader); ok && b.Capabilities().OutputsReader {
+			return reader.GetStackOutputs(commandContext(), ref)
+		}
+		if s, err = b.GetStack(commandContext(), ref); err != nil {
+			return nil, err
+		}
+		if s == nil {
+			return nil, errors.Errorf("no stack named '%s' found", stackName)
+		}
+	} else {
+		var err error
+		if s, err = requireStack(stackName, false, opts, true /*setCurrent*/); err != nil {
+			return nil, err
+		}
+	}
+
+	snap, err := s.Snapshot(commandContext())
+	if err != nil {
+		return nil, err
+	}
+	res, outputs := stack.GetRootStackResource(snap)
+	if res == nil {
+		return nil, nil
+	}
+	return outputs, nil
+}
+
+// filterOutputs returns the outputs whose names match any of the given patterns, in which `*` matches any sequence
+// of characters.  If there are no patterns, every output is returned.
+func filterOutputs(outputs map[string]interface{}, patterns []string) map[string]interface{} {
+	if len(patterns) == 0 {
+		return outputs
+	}
+
+	re := glob.Compile(patterns...)
+
+	filtered := make(map[string]interface{})
+	for k, v := range outputs {
+		if re.MatchString(k) {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+// hideSecretOutputs returns the outputs that do not look like secrets, along with the sorted names of those that do.
+func hideSecretOutputs(outputs map[string]interface{}) (map[string]interface{}, []string) {
+	shown := make(map[string]interface{})
+	var hidden []string
+	for k, v := range outputs {
+		if looksLikeSecretValue(k, stringifyOutput(v)) {
+			hidden = append(hidden, k)
+		} else {
+			shown[k] = v
+		}
+	}
+	sort.Strings(hidden)
+	return shown, hidden
+}
+
+// writeOutputs writes the given outputs to the given writer in the given format.
+func writeOutputs(w io.Writer, outputs map[string]interface{}, format outputFormat) error {
+	if format == outputFormatJSON {
+		return writeJSONValue(w, outputs)
+	}
+
+	var names []string
+	for k := range outputs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		var line string
+		v := stringifyOutput(outputs[k])
+		switch format {
+		case outputFormatShell:
+			line = fmt.Sprintf("export %s=%s", envVarName(k), shellQuote(v))
+		case outputFormatDotenv:
+			line = fmt.Sprintf("%s=%s", envVarName(k), dotenvQuote(v))
+		default:
+			return errors.Errorf("unrecognized output format %q", format)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSONValue writes the given value to the given writer as indented JSON.
+func writeJSONValue(w io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+// envVarName returns the name of the environment variable for the output with the given name: the name in upper snake
+// case, with any character that may not appear in a variable's name replaced by an underscore.
+func envVarName(name string) string {
+	var b []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])):
+			b = append(b, '_', r)
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b = append(b, unicode.ToUpper(r))
+		default:
+			b = append(b, '_')
+		}
+	}
+	if len(b) == 0 || unicode.IsDigit(b[0]) {
+		b = append([]rune{'_'}, b...)
+	}
+	return string(b)
+}
+
+// shellQuote quotes the given string for a POSIX shell, within single quotes.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// dotenvQuote quotes the given string for a .env file, within double quotes.
+func dotenvQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
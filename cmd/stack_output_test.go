@@ -15,6 +15,7 @@
 package cmd
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,3 +37,50 @@ func TestStringifyOutput(t *testing.T) {
 	assert.Equal(t, "[\"hello\",\"goodbye\"]", stringifyOutput(arr))
 	assert.Equal(t, "{\"bar\":{\"baz\":true},\"foo\":42}", stringifyOutput(obj))
 }
+
+func TestEnvVarName(t *testing.T) {
+	assert.Equal(t, "BUCKET_NAME", envVarName("bucketName"))
+	assert.Equal(t, "URL", envVarName("url"))
+	assert.Equal(t, "SUBNET2_ID", envVarName("subnet2Id"))
+	assert.Equal(t, "MY_OUTPUT", envVarName("my-output"))
+	assert.Equal(t, "_1ST", envVarName("1st"))
+}
+
+func TestWriteOutputs(t *testing.T) {
+	outputs := map[string]interface{}{
+		"bucketName": "it's here",
+		"count":      float64(3),
+		"tags":       map[string]interface{}{"env": "dev"},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, writeOutputs(&out, outputs, outputFormatShell))
+	assert.Equal(t, "export BUCKET_NAME='it'\\''s here'\n"+
+		"export COUNT='3'\n"+
+		"export TAGS='{\"env\":\"dev\"}'\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, writeOutputs(&out, map[string]interface{}{"motd": "say \"hi\"\nbye"}, outputFormatDotenv))
+	assert.Equal(t, "MOTD=\"say \\\"hi\\\"\\nbye\"\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, writeOutputs(&out, outputs, outputFormatJSON))
+	assert.Equal(t, "{\n  \"bucketName\": \"it's here\",\n  \"count\": 3,\n"+
+		"  \"tags\": {\n    \"env\": \"dev\"\n  }\n}\n", out.String())
+}
+
+func TestFilterAndHideOutputs(t *testing.T) {
+	outputs := map[string]interface{}{
+		"dbHost":     "db.example.com",
+		"dbPassword": "q8#Vr!2mZp@4Lx9w",
+		"webUrl":     "https://example.com",
+	}
+
+	filtered := filterOutputs(outputs, []string{"db*"})
+	assert.Len(t, filtered, 2)
+	assert.Equal(t, outputs, filterOutputs(outputs, nil))
+
+	shown, hidden := hideSecretOutputs(filtered)
+	assert.Equal(t, map[string]interface{}{"dbHost": "db.example.com"}, shown)
+	assert.Equal(t, []string{"dbPassword"}, hidden)
+}
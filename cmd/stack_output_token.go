@@ -0,0 +1,212 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/cloud"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStackOutputTokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "output-token",
+		Short: "Manage tokens that may only read a stack's outputs",
+		Long: "Manage tokens that may only read a stack's outputs\n" +
+			"\n" +
+			"An output token lets the consumers of a stack, such as other teams' stacks, read its outputs\n" +
+			"without being granted access to its state or configuration.  A consumer reads the outputs by\n" +
+			"setting PULUMI_ACCESS_TOKEN to the token and running `pulumi stack output` with a stack URI\n" +
+			"of the form <backend-url>#<owner>/<stack>.  Output tokens are only available for backends\n" +
+			"that support them, such as the Pulumi Service.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newStackOutputTokenLsCmd())
+	cmd.AddCommand(newStackOutputTokenCreateCmd())
+	cmd.AddCommand(newStackOutputTokenRmCmd())
+
+	return cmd
+}
+
+func newStackOutputTokenLsCmd() *cobra.Command {
+	var jsonOut bool
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List the tokens that may read a stack's outputs",
+		Args:  cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			s, tokens, err := requireStackOutputTokenManager(stackName)
+			if err != nil {
+				return err
+			}
+
+			list, err := tokens.ListStackOutputTokens(commandContext(), s.Name())
+			if err != nil {
+				return errors.Wrap(err, "listing output tokens")
+			}
+			sortStackOutputTokens(list)
+
+			if jsonOut {
+				if list == nil {
+					list = []apitype.StackOutputToken{}
+				}
+				b, err := json.MarshalIndent(list, "", "    ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+
+			if len(list) == 0 {
+				fmt.Printf("Stack '%s' has no output tokens\n", s.Name())
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tCREATED\tLAST USED\tDESCRIPTION")
+			for _, token := range list {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", token.ID, humanize.Time(time.Unix(token.Created, 0)),
+					outputTokenLastUsed(token), token.Description)
+			}
+			return w.Flush()
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+func newStackOutputTokenCreateCmd() *cobra.Command {
+	var description string
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a token that may only read a stack's outputs",
+		Long: "Create a token that may only read a stack's outputs\n" +
+			"\n" +
+			"The token is printed on standard output, and cannot be retrieved again afterwards.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			s, tokens, err := requireStackOutputTokenManager(stackName)
+			if err != nil {
+				return err
+			}
+
+			id, token, err := tokens.CreateStackOutputToken(commandContext(), s.Name(), description)
+			if err != nil {
+				return errors.Wrap(err, "creating output token")
+			}
+			fmt.Fprintf(os.Stderr, "Created output token '%s' for stack '%s'\n", id, s.Name())
+			if cb, ok := s.Backend().(cloud.Backend); ok {
+				fmt.Fprintf(os.Stderr,
+					"Consumers may read the stack's outputs with `pulumi stack output --stack %s#%s`\n",
+					cb.CloudURL(), s.Name())
+			}
+			fmt.Println(token)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().StringVarP(
+		&description, "description", "d", "", "A note about who or what the token is for")
+
+	return cmd
+}
+
+func newStackOutputTokenRmCmd() *cobra.Command {
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "rm <id>",
+		Short: "Revoke a token that may read a stack's outputs",
+		Args:  cmdutil.SpecificArgs([]string{"id"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			s, tokens, err := requireStackOutputTokenManager(stackName)
+			if err != nil {
+				return err
+			}
+
+			if err = tokens.RevokeStackOutputToken(commandContext(), s.Name(), args[0]); err != nil {
+				return errors.Wrapf(err, "revoking output token '%s'", args[0])
+			}
+			fmt.Printf("Revoked output token '%s' of stack '%s'\n", args[0], s.Name())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+// requireStackOutputTokenManager returns the indicated stack along with its backend's output tokens, or an error if
+// the backend does not support output tokens.
+func requireStackOutputTokenManager(stackName string) (backend.Stack, backend.StackOutputTokenManager, error) {
+	opts := backend.DisplayOptions{
+		Color: cmdutil.GetGlobalColorization(),
+	}
+
+	s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tm, ok := s.Backend().(backend.StackOutputTokenManager)
+	if !ok || !s.Backend().Capabilities().OutputTokens {
+		return nil, nil, errors.Errorf("the backend for stack '%s' does not support output tokens", s.Name())
+	}
+	return s, tm, nil
+}
+
+// outputTokenLastUsed describes when an output token was last used.
+func outputTokenLastUsed(token apitype.StackOutputToken) string {
+	if token.LastUsed == 0 {
+		return "never"
+	}
+	return humanize.Time(time.Unix(token.LastUsed, 0))
+}
+
+// sortStackOutputTokens orders output tokens from the oldest to the newest, and then by ID.
+func sortStackOutputTokens(tokens []apitype.StackOutputToken) {
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].Created != tokens[j].Created {
+			return tokens[i].Created < tokens[j].Created
+		}
+		return tokens[i].ID < tokens[j].ID
+	})
+}
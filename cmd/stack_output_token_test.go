@@ -0,0 +1,44 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+func TestSortStackOutputTokens(t *testing.T) {
+	tokens := []apitype.StackOutputToken{
+		{ID: "c", Created: 20},
+		{ID: "b", Created: 10},
+		{ID: "a", Created: 20},
+	}
+	sortStackOutputTokens(tokens)
+	assert.Equal(t, []apitype.StackOutputToken{
+		{ID: "b", Created: 10},
+		{ID: "a", Created: 20},
+		{ID: "c", Created: 20},
+	}, tokens)
+}
+
+func TestOutputTokenLastUsed(t *testing.T) {
+	assert.Equal(t, "never", outputTokenLastUsed(apitype.StackOutputToken{ID: "a"}))
+	used := time.Now().Add(-2 * time.Hour).Unix()
+	assert.Equal(t, "2 hours ago", outputTokenLastUsed(apitype.StackOutputToken{ID: "a", LastUsed: used}))
+}
@@ -0,0 +1,191 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+func newStackRecoverCmd() *cobra.Command {
+	var clearUnresolved bool
+	var dryRun bool
+	var ids []string
+	var stackName string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "recover",
+		Args:  cmdutil.NoArgs,
+		Short: "Reconcile a stack's pending operations with the actual state of its resources",
+		Long: "Reconcile a stack's pending operations with the actual state of its resources.\n" +
+			"\n" +
+			"An update that is interrupted while creating, updating or deleting resources leaves those\n" +
+			"operations pending in the stack's checkpoint, and later updates refuse to run until they are\n" +
+			"dealt with.  Rather than discarding them, this command asks each resource's provider whether\n" +
+			"the operation actually completed, and patches the checkpoint to match:\n" +
+			"\n" +
+			"    - resources that were created are added to the checkpoint\n" +
+			"    - resources that were updated have their outputs refreshed\n" +
+			"    - resources that were deleted are removed from the checkpoint\n" +
+			"\n" +
+			"The ID of a resource that was being created is not usually known, so it must be supplied\n" +
+			"using `--id <urn>=<id>` if the resource exists.  Operations that cannot be resolved remain\n" +
+			"pending unless `--clear-unresolved` is given.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			hints, err := parseRecoverIDs(ids)
+			if err != nil {
+				return err
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			deployment, err := s.ExportDeployment(commandContext())
+			if err != nil {
+				return errors.Wrap(err, "could not export deployment")
+			}
+			snapshot, err := stack.DeserializeUntypedDeployment(deployment)
+			if err != nil {
+				return errors.Wrap(err, "could not deserialize deployment")
+			}
+			if snapshot == nil || len(snapshot.PendingOperations) == 0 {
+				fmt.Printf("Stack '%s' has no pending operations.\n", s.Name())
+				return nil
+			}
+
+			pwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			ctx, err := plugin.NewContext(cmdutil.Diag(), cmdutil.Diag(), nil, nil, nil, pwd, nil, nil)
+			if err != nil {
+				return err
+			}
+			defer contract.IgnoreClose(ctx)
+
+			recoveries, err := snapshot.RecoverPendingOperations(ctx.Host, hints)
+			if err != nil {
+				return err
+			}
+			changes := 0
+			for _, r := range recoveries {
+				color := colors.SpecInfo
+				if r.Resolved {
+					changes++
+				} else {
+					color = colors.SpecWarning
+				}
+				fmt.Println(opts.Color.Colorize(
+					fmt.Sprintf("%s%s%s (%s): %s", color, r.URN, colors.Reset, r.Type, r.Message)))
+			}
+			if clearUnresolved {
+				for _, op := range snapshot.PendingOperations {
+					msg := fmt.Sprintf("removing unresolved pending operation '%s' from snapshot", op.Type)
+					cmdutil.Diag().Warningf(diag.Message(op.Resource.URN, msg))
+					changes++
+				}
+				snapshot.PendingOperations = nil
+			}
+			if err = snapshot.VerifyIntegrity(); err != nil {
+				return errors.Wrap(err, "the recovered checkpoint is invalid")
+			}
+
+			if changes == 0 {
+				fmt.Printf("No pending operations on stack '%s' could be resolved.\n", s.Name())
+				return nil
+			}
+			if dryRun {
+				fmt.Printf("%d pending operation(s) on stack '%s' would be resolved.\n", changes, s.Name())
+				return nil
+			}
+
+			prompt := fmt.Sprintf("This will resolve %d pending operation(s) in the '%s' stack's checkpoint.",
+				changes, s.Name())
+			if !yes && !confirmPrompt(prompt, s.Name().String(), opts) {
+				return errors.New("confirmation declined")
+			}
+
+			bytes, err := json.Marshal(stack.SerializeDeployment(snapshot))
+			if err != nil {
+				return err
+			}
+			dep := apitype.UntypedDeployment{
+				Version:    apitype.DeploymentSchemaVersionCurrent,
+				Deployment: bytes,
+			}
+			if err = s.ImportDeployment(commandContext(), &dep); err != nil {
+				return errors.Wrap(err, "could not save recovered deployment")
+			}
+			fmt.Printf("Resolved %d pending operation(s) on stack '%s'.\n", changes, s.Name())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&clearUnresolved, "clear-unresolved", false,
+		"Remove any pending operations that cannot be resolved from the checkpoint")
+	cmd.PersistentFlags().BoolVar(
+		&dryRun, "dry-run", false,
+		"Report how the pending operations would be resolved without saving the changes")
+	cmd.PersistentFlags().StringArrayVar(
+		&ids, "id", nil,
+		"The ID of a resource whose creation is pending, as <urn>=<id>; may be given more than once")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and proceed with the recovery anyway")
+
+	return cmd
+}
+
+// parseRecoverIDs parses the `--id <urn>=<id>` flags given to `pulumi stack recover`.
+func parseRecoverIDs(ids []string) (map[resource.URN]resource.ID, error) {
+	hints := make(map[resource.URN]resource.ID)
+	for _, s := range ids {
+		eq := strings.Index(s, "=")
+		if eq <= 0 || eq == len(s)-1 {
+			return nil, errors.Errorf("invalid --id '%s': expected <urn>=<id>", s)
+		}
+		urn := resource.URN(s[:eq])
+		if !urn.IsValid() {
+			return nil, errors.Errorf("invalid --id '%s': '%s' is not a valid URN", s, urn)
+		}
+		hints[urn] = resource.ID(s[eq+1:])
+	}
+	return hints, nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+func TestParseRecoverIDs(t *testing.T) {
+	urn := "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs"
+	hints, err := parseRecoverIDs([]string{urn + "=logs-1a2b=c"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[resource.URN]resource.ID{resource.URN(urn): "logs-1a2b=c"}, hints)
+
+	for _, bad := range []string{"logs-1a2b", urn + "=", "=logs-1a2b", "not-a-urn=logs-1a2b"} {
+		_, err = parseRecoverIDs([]string{bad})
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// recordStackReferences records, in backends that support it, the outputs of other stacks that a stack's settings say
+// it reads, so that previews of those stacks can show the impact of their changes upon this one.  It is called once
+// the stack has been updated successfully, and so only warns if the references cannot be recorded.
+func recordStackReferences(s backend.Stack) {
+	if err := tryRecordStackReferences(s); err != nil {
+		cmdutil.Diag().Warningf(diag.Message("" /*urn*/, "could not record the stack's references: %v"), err)
+	}
+}
+
+func tryRecordStackReferences(s backend.Stack) error {
+	recorder, ok := s.Backend().(backend.StackReferenceRecorder)
+	if !ok || !s.Backend().Capabilities().StackReferences {
+		return nil
+	}
+	ps, err := workspace.DetectProjectStack(s.Name().StackName())
+	if err != nil {
+		return err
+	}
+	return recorder.RecordStackReferences(commandContext(), s.Name(), ps.StackReferences)
+}
+
+// getStackConsumers returns the outputs of a stack that each stack which references it reads, or nil if its backend
+// does not record stack references.
+func getStackConsumers(s backend.Stack) (map[string][]string, error) {
+	recorder, ok := s.Backend().(backend.StackReferenceRecorder)
+	if !ok || !s.Backend().Capabilities().StackReferences {
+		return nil, nil
+	}
+	return recorder.GetStackConsumers(commandContext(), s.Name())
+}
+
+// cascadePreview previews each of the given stacks, which consume the outputs of a stack that has just been previewed.
+// Their projects are found by searching the current repository for the projects that hold their settings.
+func cascadePreview(consumers map[string][]string) error {
+	if len(consumers) == 0 {
+		return nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, err := workspace.DetectRepositoryRoot(cwd)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for name := range consumers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	dirs, err := findStackProjects(root, names)
+	if err != nil {
+		return err
+	}
+
+	manifest := &workspace.DeployManifest{Stacks: make(map[string]*workspace.DeployManifestStack)}
+	var order []string
+	for _, name := range names {
+		dir, has := dirs[name]
+		if !has {
+			fmt.Printf("warning: could not find the project of stack '%s' under %s; it must be previewed by hand\n",
+				name, root)
+			continue
+		}
+		manifest.Stacks[name] = &workspace.DeployManifestStack{Project: dir, Stack: name}
+		order = append(order, name)
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\nPreviewing %d referencing stack(s):\n", len(order))
+	results := runDeploymentStacks(manifest, order, nil, defaultDeployParallel, "preview")
+	return printDeploymentResults(manifest, order, results)
+}
+
+// findStackProjects returns the directory of the project, under the given root, that holds the settings of each of the
+// named stacks.  Stacks whose settings cannot be found are omitted.
+func findStackProjects(root string, stacks []string) (map[string]string, error) {
+	paths, err := workspace.DetectProjectsUnder(root)
+	if err != nil {
+		return nil, errors.Wrapf(err, "searching %s for projects", root)
+	}
+
+	dirs := make(map[string]string)
+	for _, path := range paths {
+		proj, err := workspace.LoadProject(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading %s", path)
+		}
+		for _, stack := range stacks {
+			if _, has := dirs[stack]; has {
+				continue
+			}
+			if _, err := os.Stat(workspace.ProjectStackPath(proj, path, tokens.QName(stack))); err == nil {
+				dirs[stack] = filepath.Dir(path)
+			}
+		}
+	}
+	return dirs, nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindStackProjects(t *testing.T) {
+	root, err := ioutil.TempDir("", "pulumi-stackrefs")
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(root)) }()
+
+	write := func(path, contents string) {
+		path = filepath.Join(root, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	}
+	write(filepath.Join("network", "Pulumi.yaml"), "name: network\nruntime: nodejs\n")
+	write(filepath.Join("network", "Pulumi.network-dev.yaml"), "config: {}\n")
+	write(filepath.Join("api", "Pulumi.yaml"), "name: api\nruntime: nodejs\nconfig: stacks\n")
+	write(filepath.Join("api", "stacks", "Pulumi.api-dev.yaml"), "stackReferences:\n  network-dev: [vpcId]\n")
+
+	dirs, err := findStackProjects(root, []string{"api-dev", "web-dev"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"api-dev": filepath.Join(root, "api")}, dirs)
+}
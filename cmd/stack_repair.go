@@ -0,0 +1,122 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStackRepairCmd() *cobra.Command {
+	var dryRun bool
+	var stackName string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "repair",
+		Args:  cmdutil.NoArgs,
+		Short: "Automatically fix well-understood corruptions in a stack's checkpoint",
+		Long: "Automatically fix well-understood corruptions in a stack's checkpoint.\n" +
+			"\n" +
+			"Interrupted updates can leave a stack's checkpoint in a state that later updates refuse\n" +
+			"to work with.  This command repairs the following problems:\n" +
+			"\n" +
+			"    - duplicate resources left behind by an interrupted replacement\n" +
+			"    - resources marked for deletion that were never replaced\n" +
+			"    - dependencies on resources that no longer exist\n" +
+			"\n" +
+			"Every change made to the checkpoint is reported.  Problems that cannot be fixed\n" +
+			"automatically are reported as well, and must be fixed by hand using `pulumi stack export`\n" +
+			"and `pulumi stack import`.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			deployment, err := s.ExportDeployment(commandContext())
+			if err != nil {
+				return errors.Wrap(err, "could not export deployment")
+			}
+			snapshot, err := stack.DeserializeUntypedDeployment(deployment)
+			if err != nil {
+				return errors.Wrap(err, "could not deserialize deployment")
+			}
+
+			repairs := snapshot.Repair()
+			for _, repair := range repairs {
+				fmt.Println(opts.Color.Colorize(
+					fmt.Sprintf("%s%s%s: %s", colors.SpecInfo, repair.URN, colors.Reset, repair.Message)))
+			}
+			if err = snapshot.VerifyIntegrity(); err != nil {
+				msg := fmt.Sprintf("the checkpoint has problems that cannot be repaired automatically: %v", err)
+				cmdutil.Diag().Warningf(diag.Message("" /*urn*/, msg))
+			}
+
+			if len(repairs) == 0 {
+				fmt.Printf("No repairs are needed for stack '%s'.\n", s.Name())
+				return nil
+			}
+			if dryRun {
+				fmt.Printf("%d repair(s) would be made to stack '%s'.\n", len(repairs), s.Name())
+				return nil
+			}
+
+			prompt := fmt.Sprintf("This will save %d repair(s) to the '%s' stack's checkpoint.", len(repairs), s.Name())
+			if !yes && !confirmPrompt(prompt, s.Name().String(), opts) {
+				return errors.New("confirmation declined")
+			}
+
+			bytes, err := json.Marshal(stack.SerializeDeployment(snapshot))
+			if err != nil {
+				return err
+			}
+			dep := apitype.UntypedDeployment{
+				Version:    apitype.DeploymentSchemaVersionCurrent,
+				Deployment: bytes,
+			}
+			if err = s.ImportDeployment(commandContext(), &dep); err != nil {
+				return errors.Wrap(err, "could not save repaired deployment")
+			}
+			fmt.Printf("Made %d repair(s) to stack '%s'.\n", len(repairs), s.Name())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&dryRun, "dry-run", false,
+		"Report the repairs that would be made without saving them")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and proceed with the repair anyway")
+
+	return cmd
+}
@@ -0,0 +1,370 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/encoding"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/glob"
+)
+
+func newStackReportCmd() *cobra.Command {
+	var stackName string
+	var unreferenced bool
+	var format string
+	var projectionsFile string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Args:  cmdutil.NoArgs,
+		Short: "Summarize which of a stack's resources its program declared during the last update",
+		Long: "Summarize which of a stack's resources its program declared during the last update.\n" +
+			"\n" +
+			"An update deletes every resource that the program no longer declares. If some of those\n" +
+			"deletions fail or are prevented, e.g. because the resources are protected or locked, the\n" +
+			"resources remain in the stack until the next update tries again. Pass --unreferenced to\n" +
+			"list them, which helps to review a large cleanup before running it.\n" +
+			"\n" +
+			"Pass --format csv or --format json to instead list the stack's cloud resources as a flat\n" +
+			"inventory for compliance exports: each resource's URN, type, provider, region, creation and\n" +
+			"modification times, and key properties.  The key properties of each type of resource are\n" +
+			"chosen by projections, which may be given in a JSON or YAML file with --projections:\n" +
+			"\n" +
+			"    types:\n" +
+			"        aws:s3/bucket:Bucket:\n" +
+			"            properties: [bucket, tags.Owner]\n" +
+			"        gcp:*:\n" +
+			"            properties: [name, labels]\n" +
+			"            region: zone\n" +
+			"\n" +
+			"Types may be matched exactly or by a prefix ending in `*`, and the most specific match wins.\n" +
+			"A resource's region is read from the property that its projection names, or else from its\n" +
+			"`region` or `location` property, or else from the configuration of its provider.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "csv" && format != "json" {
+				return errors.Errorf("unknown format '%s'; expected 'text', 'csv', or 'json'", format)
+			}
+			if unreferenced && format != "text" {
+				return errors.New("--unreferenced may only be used with --format text")
+			}
+			projections := defaultReportProjections()
+			if projectionsFile != "" {
+				custom, err := loadReportProjections(projectionsFile)
+				if err != nil {
+					return err
+				}
+				projections.merge(custom)
+			}
+
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stackName, false, opts, true /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return err
+			}
+
+			switch format {
+			case "csv":
+				return writeInventoryCSV(os.Stdout, newInventoryReport(s.Name().StackName(), snap, projections))
+			case "json":
+				return writeInventoryJSON(os.Stdout, newInventoryReport(s.Name().StackName(), snap, projections))
+			}
+
+			report := newResourceReport(snap)
+			if unreferenced {
+				printUnreferencedResources(os.Stdout, report)
+			} else {
+				printResourceReport(os.Stdout, report)
+			}
+			return nil
+		}),
+	}
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&unreferenced, "unreferenced", false,
+		"List the resources that the program no longer declares, which the next update will delete")
+	cmd.PersistentFlags().StringVar(
+		&format, "format", "text", "The format of the report: 'text', or 'csv' or 'json' for an inventory")
+	cmd.PersistentFlags().StringVar(
+		&projectionsFile, "projections", "",
+		"A JSON or YAML file that chooses the key properties of each type of resource in an inventory")
+	return cmd
+}
+
+// resourceReport tallies a stack's resources by whether its program registered them during the last update.
+type resourceReport struct {
+	Total          int               // the number of live resources in the stack.
+	Registered     int               // the number of live resources that the program registered.
+	PendingReplace int               // the number of resources awaiting deletion after a replacement.
+	Unreferenced   []*resource.State // the live resources that the program no longer declares.
+}
+
+func newResourceReport(snap *deploy.Snapshot) resourceReport {
+	var report resourceReport
+	if snap == nil {
+		return report
+	}
+	for _, res := range snap.Resources {
+		switch {
+		case res.Delete:
+			report.PendingReplace++
+		case res.Unreferenced:
+			report.Total++
+			report.Unreferenced = append(report.Unreferenced, res)
+		default:
+			report.Total++
+			report.Registered++
+		}
+	}
+	return report
+}
+
+func printResourceReport(w io.Writer, report resourceReport) {
+	fmt.Fprintf(w, "Resources: %d\n", report.Total)
+	fmt.Fprintf(w, "    %d declared by the program during the last update\n", report.Registered)
+	if n := len(report.Unreferenced); n > 0 {
+		fmt.Fprintf(w, "    %d no longer declared by the program (use --unreferenced to list them)\n", n)
+	}
+	if report.PendingReplace > 0 {
+		fmt.Fprintf(w, "    %d pending deletion after a replacement\n", report.PendingReplace)
+	}
+}
+
+func printUnreferencedResources(w io.Writer, report resourceReport) {
+	if len(report.Unreferenced) == 0 {
+		fmt.Fprintln(w, "The program declared every resource in the stack during the last update.")
+		return
+	}
+
+	fmt.Fprintf(w, "The program no longer declares the following %d resource(s), which the next update will try "+
+		"to delete:\n", len(report.Unreferenced))
+	for _, res := range report.Unreferenced {
+		var notes string
+		switch {
+		case res.Locked:
+			notes = " (locked)"
+		case res.Protect:
+			notes = " (protected)"
+		}
+		fmt.Fprintf(w, "    %s%s\n", res.URN, notes)
+	}
+}
+
+// reportProjections choose the key properties of each type of resource to include in an inventory.
+type reportProjections struct {
+	// Types maps type tokens, or prefixes of them ending in `*` such as `aws:*`, to the projections of the types
+	// that they match.
+	Types map[string]reportProjection `json:"types" yaml:"types"`
+}
+
+// reportProjection chooses the key properties of a type of resource.
+type reportProjection struct {
+	// Properties are the paths of the key properties, e.g. `tags.Name`.
+	Properties []string `json:"properties,omitempty" yaml:"properties,omitempty"`
+	// Region is the path of the property that holds the resource's region, if it is not `region` or `location`.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+}
+
+// defaultReportProjections returns the projections used for the common cloud providers' resources.
+func defaultReportProjections() reportProjections {
+	return reportProjections{
+		Types: map[string]reportProjection{
+			"aws:*":   {Properties: []string{"arn", "tags"}},
+			"azure:*": {Properties: []string{"name", "resourceGroupName", "tags"}},
+			"gcp:*":   {Properties: []string{"name", "project", "labels"}},
+		},
+	}
+}
+
+// loadReportProjections reads projections from a JSON or YAML file, and checks that their property paths are valid.
+func loadReportProjections(path string) (reportProjections, error) {
+	var projections reportProjections
+	m, _ := encoding.Detect(path)
+	if m == nil {
+		return projections, errors.Errorf("could not read projections from %s: unknown file extension", path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return projections, errors.Wrap(err, "could not read projections")
+	}
+	if err = m.Unmarshal(data, &projections); err != nil {
+		return projections, errors.Wrapf(err, "could not read projections from %s", path)
+	}
+
+	for typ, projection := range projections.Types {
+		paths := projection.Properties
+		if projection.Region != "" {
+			paths = append(paths, projection.Region)
+		}
+		for _, path := range paths {
+			if _, err := resource.ParsePropertyPath(path); err != nil {
+				return projections, errors.Wrapf(err, "invalid property path '%s' in the projection of %s", path, typ)
+			}
+		}
+	}
+	return projections, nil
+}
+
+// merge adds the given projections to these, replacing any for the same types.
+func (p *reportProjections) merge(other reportProjections) {
+	for typ, projection := range other.Types {
+		p.Types[typ] = projection
+	}
+}
+
+// lookup returns the projection of the given type: the one for the type itself, or else the one with the longest
+// matching pattern, in which `*` matches any sequence of characters.
+func (p reportProjections) lookup(typ tokens.Type) reportProjection {
+	if projection, ok := p.Types[string(typ)]; ok {
+		return projection
+	}
+	var match string
+	var result reportProjection
+	for pattern, projection := range p.Types {
+		if len(pattern) >= len(match) && glob.Compile(pattern).MatchString(string(typ)) {
+			match, result = pattern, projection
+		}
+	}
+	return result
+}
+
+// newInventoryReport lists the custom resources in a snapshot of the given stack as an inventory, keeping only the key
+// properties that their projections choose.
+func newInventoryReport(stackName tokens.QName, snap *deploy.Snapshot,
+	projections reportProjections) *apitype.InventoryV1 {
+
+	var proj tokens.PackageName
+	if snap != nil {
+		proj = snapshotProject(snap)
+	}
+	inventory := stack.SerializeInventory(stackName, proj, snap)
+	for i := range inventory.Resources {
+		res := &inventory.Resources[i]
+		projection := projections.lookup(res.Type)
+		outputs := resource.NewPropertyMapFromMap(res.Properties)
+
+		res.Properties = nil
+		for _, path := range projection.Properties {
+			// Paths were validated when the projections were loaded.
+			p, err := resource.ParsePropertyPath(path)
+			if err != nil {
+				continue
+			}
+			if v, ok := p.Get(outputs); ok {
+				if res.Properties == nil {
+					res.Properties = make(map[string]interface{})
+				}
+				res.Properties[path] = v.Mappable()
+			}
+		}
+		if projection.Region != "" {
+			res.Region = ""
+			if p, err := resource.ParsePropertyPath(projection.Region); err == nil {
+				if v, ok := p.Get(outputs); ok && v.IsString() {
+					res.Region = v.StringValue()
+				}
+			}
+		}
+	}
+	return inventory
+}
+
+// writeInventoryJSON writes an inventory as JSON.
+func writeInventoryJSON(w io.Writer, inventory *apitype.InventoryV1) error {
+	bytes, err := json.MarshalIndent(inventory, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(bytes))
+	return err
+}
+
+// writeInventoryCSV writes an inventory's resources as CSV, with a column for each key property of any resource.
+// Values that are not strings are written as JSON.
+func writeInventoryCSV(w io.Writer, inventory *apitype.InventoryV1) error {
+	var columns []string
+	seen := make(map[string]bool)
+	for _, entry := range inventory.Resources {
+		for path := range entry.Properties {
+			if !seen[path] {
+				seen[path] = true
+				columns = append(columns, path)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	out := csv.NewWriter(w)
+	header := append([]string{"urn", "type", "provider", "region", "created", "modified"}, columns...)
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, entry := range inventory.Resources {
+		record := []string{string(entry.URN), string(entry.Type), string(entry.Package), entry.Region,
+			formatTime(entry.Created), formatTime(entry.Modified)}
+		for _, path := range columns {
+			var value string
+			switch v := entry.Properties[path].(type) {
+			case nil:
+			case string:
+				value = v
+			default:
+				bytes, err := json.Marshal(v)
+				if err != nil {
+					return err
+				}
+				value = string(bytes)
+			}
+			record = append(record, value)
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
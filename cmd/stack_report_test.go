@@ -0,0 +1,138 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestResourceReport(t *testing.T) {
+	newState := func(name string) *resource.State {
+		urn := resource.NewURN("test", "test", "", "pkgA:m:typA", tokens.QName(name))
+		return resource.NewState("pkgA:m:typA", urn, true, false, "", resource.PropertyMap{}, nil, "",
+			false, false, nil, nil, "")
+	}
+
+	resA, resB, resC, resD := newState("resA"), newState("resB"), newState("resC"), newState("resD")
+	resB.Unreferenced = true
+	resC.Unreferenced, resC.Protect = true, true
+	resD.Delete = true
+	snap := deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{resA, resB, resC, resD}, nil)
+
+	report := newResourceReport(snap)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 1, report.Registered)
+	assert.Equal(t, 1, report.PendingReplace)
+	assert.Equal(t, []*resource.State{resB, resC}, report.Unreferenced)
+
+	var out bytes.Buffer
+	printResourceReport(&out, report)
+	assert.Contains(t, out.String(), "2 no longer declared by the program")
+
+	out.Reset()
+	printUnreferencedResources(&out, report)
+	assert.Contains(t, out.String(), string(resB.URN)+"\n")
+	assert.Contains(t, out.String(), string(resC.URN)+" (protected)\n")
+
+	out.Reset()
+	printUnreferencedResources(&out, newResourceReport(nil))
+	assert.Equal(t, "The program declared every resource in the stack during the last update.\n", out.String())
+}
+
+func TestInventoryReport(t *testing.T) {
+	created := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	prov := resource.NewState("pulumi:providers:aws", "urn:pulumi:test::test::pulumi:providers:aws::default", true,
+		false, "provider-id", resource.NewPropertyMapFromMap(map[string]interface{}{"region": "us-west-2"}), nil, "",
+		false, false, nil, nil, "")
+	bucket := resource.NewState("aws:s3/bucket:Bucket", "urn:pulumi:test::test::aws:s3/bucket:Bucket::logs", true,
+		false, "logs-1234", resource.PropertyMap{}, resource.NewPropertyMapFromMap(map[string]interface{}{
+			"bucket": "logs-1234",
+			"arn":    "arn:aws:s3:::logs-1234",
+			"tags":   map[string]interface{}{"Owner": "ops"},
+		}), "", false, false, nil, nil, string(prov.URN)+"::provider-id")
+	bucket.Created, bucket.Modified = created, created.Add(time.Hour)
+	disk := resource.NewState("gcp:compute/disk:Disk", "urn:pulumi:test::test::gcp:compute/disk:Disk::data", true,
+		false, "data", resource.PropertyMap{}, resource.NewPropertyMapFromMap(map[string]interface{}{
+			"name": "data",
+			"zone": "us-central1-a",
+			"size": 10,
+		}), "", false, false, nil, nil, "")
+	component := resource.NewState("my:index:Component", "urn:pulumi:test::test::my:index:Component::comp", false,
+		false, "", resource.PropertyMap{}, nil, "", false, false, nil, nil, "")
+	snap := deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{prov, bucket, disk, component}, nil)
+
+	projections := defaultReportProjections()
+	projections.merge(reportProjections{Types: map[string]reportProjection{
+		"aws:s3/bucket:Bucket": {Properties: []string{"bucket", "tags.Owner"}},
+		"gcp:compute/*":        {Properties: []string{"name", "size"}, Region: "zone"},
+	}})
+
+	inventory := newInventoryReport("test", snap, projections)
+	assert.Equal(t, "test", string(inventory.Project))
+	entries := inventory.Resources
+	if !assert.Len(t, entries, 2) {
+		return
+	}
+	assert.Equal(t, bucket.URN, entries[0].URN)
+	assert.Equal(t, "aws", string(entries[0].Package))
+	assert.Equal(t, "us-west-2", entries[0].Region)
+	assert.Equal(t, map[string]interface{}{"bucket": "logs-1234", "tags.Owner": "ops"}, entries[0].Properties)
+	assert.Equal(t, "us-central1-a", entries[1].Region)
+	assert.Nil(t, entries[1].Created)
+
+	var out bytes.Buffer
+	assert.NoError(t, writeInventoryCSV(&out, inventory))
+	assert.Equal(t, "urn,type,provider,region,created,modified,bucket,name,size,tags.Owner\n"+
+		"urn:pulumi:test::test::aws:s3/bucket:Bucket::logs,aws:s3/bucket:Bucket,aws,us-west-2,"+
+		"2018-07-01T00:00:00Z,2018-07-01T01:00:00Z,logs-1234,,,ops\n"+
+		"urn:pulumi:test::test::gcp:compute/disk:Disk::data,gcp:compute/disk:Disk,gcp,us-central1-a,,,,data,10,\n",
+		out.String())
+
+	out.Reset()
+	assert.NoError(t, writeInventoryJSON(&out, newInventoryReport("test", nil, projections)))
+	var empty apitype.InventoryV1
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &empty))
+	assert.Empty(t, empty.Resources)
+}
+
+func TestLoadReportProjections(t *testing.T) {
+	dir, err := ioutil.TempDir("", "projections")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "projections.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("types:\n  aws:*:\n    properties: [arn]\n"), 0600))
+	projections, err := loadReportProjections(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"arn"}, projections.lookup("aws:s3/bucket:Bucket").Properties)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("types:\n  aws:*:\n    properties: [\"tags[\"]\n"), 0600))
+	_, err = loadReportProjections(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Edit the current state of a stack's resources",
+		Long: "Edit the current state of a stack's resources\n" +
+			"\n" +
+			"These commands change how Pulumi treats individual resources in a stack's checkpoint,\n" +
+			"without changing the resources themselves.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newStateConvertCmd())
+	cmd.AddCommand(newStateExportCmd())
+	cmd.AddCommand(newStateImportCmd())
+	cmd.AddCommand(newStateLockCmd())
+	cmd.AddCommand(newStateUnlockCmd())
+
+	return cmd
+}
+
+func newStateLockCmd() *cobra.Command {
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "lock <urn>",
+		Short: "Prevent a resource from being changed",
+		Long: "Prevent a resource from being changed\n" +
+			"\n" +
+			"A locked resource may not be updated, replaced, or deleted.  Any preview, update, or destroy\n" +
+			"that would change it fails, reporting the locked resources that blocked it.  This is stricter\n" +
+			"than marking a resource as protected, which only prevents it from being deleted.\n" +
+			"\n" +
+			"Use `pulumi state unlock` to allow the resource to change again.",
+		Args: cmdutil.SpecificArgs([]string{"urn"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			return setResourceLocked(stackName, resource.URN(args[0]), true)
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+func newStateUnlockCmd() *cobra.Command {
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "unlock <urn>",
+		Short: "Allow a locked resource to be changed",
+		Args:  cmdutil.SpecificArgs([]string{"urn"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			return setResourceLocked(stackName, resource.URN(args[0]), false)
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+// setResourceLocked locks or unlocks every resource with the given URN in the indicated stack's checkpoint.
+func setResourceLocked(stackName string, urn resource.URN, locked bool) error {
+	opts := backend.DisplayOptions{
+		Color: cmdutil.GetGlobalColorization(),
+	}
+
+	s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+	if err != nil {
+		return err
+	}
+
+	deployment, err := s.ExportDeployment(commandContext())
+	if err != nil {
+		return errors.Wrap(err, "could not export deployment")
+	}
+	snapshot, err := stack.DeserializeUntypedDeployment(deployment)
+	if err != nil {
+		return errors.Wrap(err, "could not deserialize deployment")
+	}
+
+	verb := "locked"
+	if !locked {
+		verb = "unlocked"
+	}
+
+	found, changed := false, false
+	if snapshot != nil {
+		for _, res := range snapshot.Resources {
+			if res.URN == urn {
+				found = true
+				changed = changed || res.Locked != locked
+				res.Locked = locked
+			}
+		}
+	}
+	if !found {
+		return errors.Errorf("no resource with URN '%s' exists in stack '%s'", urn, s.Name())
+	}
+	if !changed {
+		fmt.Printf("Resource '%s' is already %s.\n", urn, verb)
+		return nil
+	}
+
+	bytes, err := json.Marshal(stack.SerializeDeployment(snapshot))
+	if err != nil {
+		return err
+	}
+	dep := apitype.UntypedDeployment{
+		Version:    apitype.DeploymentSchemaVersionCurrent,
+		Deployment: bytes,
+	}
+	if err = s.ImportDeployment(commandContext(), &dep); err != nil {
+		return errors.Wrapf(err, "could not save %s resource", verb)
+	}
+	fmt.Printf("Resource '%s' is now %s.\n", urn, verb)
+	return nil
+}
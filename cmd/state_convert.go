@@ -0,0 +1,288 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/encoding"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStateConvertCmd() *cobra.Command {
+	var out string
+	var format string
+	var version int
+	var decryptSecrets bool
+	var encryptSecrets bool
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "convert [file]",
+		Short: "Convert exported state between versions and formats",
+		Long: "Convert exported state between versions and formats\n" +
+			"\n" +
+			"Reads a deployment produced by `pulumi stack export`, or a stack's checkpoint file, from the\n" +
+			"given file or else standard in, and writes it in the schema version given by --version to\n" +
+			"the file given by --out or else standard out.  The input may be JSON or YAML, and the output\n" +
+			"is written as JSON unless --format or the extension of the output file says otherwise.\n" +
+			"Converting to an older version fails if the state uses features that it cannot represent.\n" +
+			"\n" +
+			"Pass --decrypt-secrets to replace each secret in a checkpoint's configuration with an object\n" +
+			"of the form {\"plaintext\": \"<value>\"}, so that tools without the stack's key may read it,\n" +
+			"and --encrypt-secrets to encrypt such values again.  Secrets are encrypted and decrypted\n" +
+			"with the key of the stack given by --stack, or else the current stack.",
+		Args: cmdutil.MaximumNArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			var input []byte
+			var err error
+			if len(args) > 0 {
+				input, err = ioutil.ReadFile(args[0])
+			} else {
+				input, err = ioutil.ReadAll(os.Stdin)
+			}
+			if err != nil {
+				return errors.Wrap(err, "could not read state")
+			}
+
+			if format == "" {
+				format = "json"
+				if m, _ := encoding.Detect(out); m != nil && m.IsYAMLLike() {
+					format = "yaml"
+				}
+			}
+			if format != "json" && format != "yaml" {
+				return errors.Errorf("unknown format '%s'; expected 'json' or 'yaml'", format)
+			}
+			if decryptSecrets && encryptSecrets {
+				return errors.New("only one of --decrypt-secrets or --encrypt-secrets may be given")
+			}
+
+			var crypter config.Crypter
+			if decryptSecrets || encryptSecrets {
+				opts := backend.DisplayOptions{
+					Color: cmdutil.GetGlobalColorization(),
+				}
+				s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+				if err != nil {
+					return err
+				}
+				if crypter, err = backend.GetStackCrypter(s); err != nil {
+					return err
+				}
+			}
+
+			var encrypter config.Encrypter
+			var decrypter config.Decrypter
+			if encryptSecrets {
+				encrypter = crypter
+			} else if decryptSecrets {
+				decrypter = crypter
+			}
+			output, err := convertState(input, version, format == "yaml", encrypter, decrypter)
+			if err != nil {
+				return err
+			}
+
+			if out == "" {
+				_, err = os.Stdout.Write(output)
+				return err
+			}
+			return ioutil.WriteFile(out, output, 0600)
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&out, "out", "o", "", "The file to write the converted state to; defaults to standard out")
+	cmd.PersistentFlags().StringVar(
+		&format, "format", "", "The format to write the converted state in: 'json' or 'yaml'")
+	cmd.PersistentFlags().IntVar(
+		&version, "version", apitype.DeploymentSchemaVersionCurrent, "The schema version to convert the state to")
+	cmd.PersistentFlags().BoolVar(
+		&decryptSecrets, "decrypt-secrets", false, "Decrypt the secrets in a checkpoint's configuration")
+	cmd.PersistentFlags().BoolVar(
+		&encryptSecrets, "encrypt-secrets", false, "Encrypt the decrypted secrets in a checkpoint's configuration")
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "",
+		"The stack whose key encrypts and decrypts secrets. Defaults to the current stack")
+
+	return cmd
+}
+
+// convertState converts a deployment or checkpoint, in JSON or YAML, to the given schema version, writing it as JSON
+// or YAML.  Secrets in a checkpoint's configuration are first encrypted with the encrypter if it is non-nil, and are
+// last decrypted with the decrypter if it is non-nil.
+func convertState(input []byte, version int, toYAML bool, encrypter config.Encrypter,
+	decrypter config.Decrypter) ([]byte, error) {
+
+	input, err := stateToJSON(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Checkpoints hold the deployment within an inner checkpoint, or at their root if they predate versioning;
+	// deployments hold it within their deployment property.
+	var doc map[string]json.RawMessage
+	if err = json.Unmarshal(input, &doc); err != nil {
+		return nil, errors.Wrap(err, "could not read state")
+	}
+	_, isDeployment := doc["deployment"]
+	if isDeployment && (encrypter != nil || decrypter != nil) {
+		return nil, errors.New("only checkpoints have secret configuration to encrypt or decrypt")
+	}
+
+	var result interface{}
+	if isDeployment {
+		var deployment apitype.UntypedDeployment
+		if err = json.Unmarshal(input, &deployment); err != nil {
+			return nil, errors.Wrap(err, "could not read deployment")
+		}
+		if result, err = stack.ConvertUntypedDeployment(&deployment, version); err != nil {
+			return nil, errors.Wrap(err, "could not convert deployment")
+		}
+	} else {
+		if encrypter != nil {
+			if input, err = stack.EncryptCheckpointSecrets(input, encrypter); err != nil {
+				return nil, errors.Wrap(err, "could not encrypt secrets")
+			}
+		}
+		chk, err := stack.ConvertCheckpoint(input, version)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not convert checkpoint")
+		}
+		if decrypter == nil {
+			result = chk
+		} else {
+			b, err := json.Marshal(chk)
+			if err != nil {
+				return nil, err
+			}
+			if b, err = stack.DecryptCheckpointSecrets(b, decrypter); err != nil {
+				return nil, errors.Wrap(err, "could not decrypt secrets")
+			}
+			result = json.RawMessage(b)
+		}
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if toYAML {
+		return stateToYAML(b)
+	}
+	var buf bytes.Buffer
+	if err = json.Indent(&buf, b, "", "    "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// stateToJSON returns the given state as JSON, converting it from YAML if it is not JSON already.
+func stateToJSON(input []byte) ([]byte, error) {
+	if json.Valid(input) {
+		return input, nil
+	}
+
+	var v interface{}
+	if err := yaml.Unmarshal(input, &v); err != nil {
+		return nil, errors.Wrap(err, "could not read state as either JSON or YAML")
+	}
+	v, err := yamlValueToJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// yamlValueToJSON converts a value decoded from YAML, whose maps may have keys of any type, into one that may be
+// encoded as JSON.
+func yamlValueToJSON(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{})
+		for key, value := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, errors.Errorf("could not read state: expected a string key, not %v", key)
+			}
+			val, err := yamlValueToJSON(value)
+			if err != nil {
+				return nil, err
+			}
+			obj[k] = val
+		}
+		return obj, nil
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, value := range v {
+			val, err := yamlValueToJSON(value)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = val
+		}
+		return arr, nil
+	default:
+		return v, nil
+	}
+}
+
+// stateToYAML converts the given JSON state to YAML.  Numbers are kept as integers wherever they can be, so that
+// large ones are not rounded.
+func stateToYAML(input []byte) ([]byte, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(input))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(jsonNumbersToYAML(v))
+}
+
+// jsonNumbersToYAML replaces the JSON numbers within a value with integers or floats.
+func jsonNumbersToYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = jsonNumbersToYAML(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonNumbersToYAML(value)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return string(v)
+	}
+	return v
+}
@@ -0,0 +1,93 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+)
+
+const testV2Deployment = `{
+    "version": 2,
+    "deployment": {
+        "manifest": {"time": "2018-07-01T00:00:00Z", "magic": "", "version": ""},
+        "resources": [
+            {
+                "urn": "urn:pulumi:dev::proj::pulumi:pulumi:Stack::proj-dev",
+                "custom": false,
+                "type": "pulumi:pulumi:Stack",
+                "outputs": {"big": 9007199254740993}
+            }
+        ]
+    }
+}`
+
+func TestConvertStateVersionsAndFormats(t *testing.T) {
+	// Convert a deployment down to version 1 as YAML, and then back up to version 2 as JSON.
+	out, err := convertState([]byte(testV2Deployment), 1, true /*toYAML*/, nil, nil)
+	assert.NoError(t, err)
+	var doc map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal(out, &doc))
+	assert.Equal(t, 1, doc["version"])
+	assert.Contains(t, string(out), "big: 9007199254740993")
+
+	out, err = convertState(out, 2, false /*toYAML*/, nil, nil)
+	assert.NoError(t, err)
+	var deployment apitype.UntypedDeployment
+	assert.NoError(t, json.Unmarshal(out, &deployment))
+	assert.Equal(t, 2, deployment.Version)
+	snap, err := stack.DeserializeUntypedDeployment(&deployment)
+	assert.NoError(t, err)
+	assert.Len(t, snap.Resources, 1)
+	assert.Contains(t, string(out), "9007199254740993")
+
+	// Deployments have no secrets to decrypt.
+	crypter := config.NewSymmetricCrypter(make([]byte, config.SymmetricCrypterKeyBytes))
+	_, err = convertState([]byte(testV2Deployment), 2, false, nil, crypter)
+	assert.Error(t, err)
+
+	_, err = convertState([]byte("{not: [valid"), 2, false, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestConvertStateSecrets(t *testing.T) {
+	crypter := config.NewSymmetricCrypter(make([]byte, config.SymmetricCrypterKeyBytes))
+	ciphertext, err := crypter.EncryptValue("hunter2")
+	assert.NoError(t, err)
+	chk, err := json.Marshal(stack.SerializeCheckpoint("dev", config.Map{
+		config.MustMakeKey("proj", "password"): config.NewSecureValue(ciphertext),
+	}, nil))
+	assert.NoError(t, err)
+
+	plain, err := convertState(chk, 1, false, nil, crypter)
+	assert.NoError(t, err)
+	assert.Contains(t, string(plain), `"plaintext": "hunter2"`)
+	assert.NotContains(t, string(plain), ciphertext)
+
+	encrypted, err := convertState(plain, 2, false, crypter, nil)
+	assert.NoError(t, err)
+	loaded, err := stack.UnmarshalVersionedCheckpointToLatestCheckpoint(encrypted)
+	assert.NoError(t, err)
+	value, err := loaded.Config[config.MustMakeKey("proj", "password")].Value(crypter)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+}
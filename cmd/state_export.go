@@ -0,0 +1,158 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/resource/tfstate"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStateExportCmd() *cobra.Command {
+	var to string
+	var mappings []string
+	var providerNames []string
+	var out string
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the state of a stack's resources for use by other tools",
+		Long: "Export the state of a stack's resources for use by other tools\n" +
+			"\n" +
+			"Writes the resources of the stack given by --stack, or else the current stack, in the format\n" +
+			"given by --to, to the file given by --out or else standard out.  Two formats are supported:\n" +
+			"\n" +
+			"    inventory: a flat JSON list of the stack's cloud resources, with their IDs and properties,\n" +
+			"               for inventory and configuration management systems\n" +
+			"    terraform: a best-effort Terraform state file, for tools that understand only that format\n" +
+			"\n" +
+			"Terraform state is written using the mappings between Terraform and Pulumi resource types\n" +
+			"described by `pulumi state import`, taken from the --provider and --mapping flags.  The\n" +
+			"providers default to those of the packages of the stack's resources.  Resources whose types\n" +
+			"have no mapping are reported and left out.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if to != "inventory" && to != "terraform" {
+				return errors.Errorf("unknown format '%s'; expected 'inventory' or 'terraform'", to)
+			}
+
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			deployment, err := s.ExportDeployment(commandContext())
+			if err != nil {
+				return errors.Wrap(err, "could not export deployment")
+			}
+			snap, err := stack.DeserializeUntypedDeployment(deployment)
+			if err != nil {
+				return errors.Wrap(err, "could not deserialize deployment")
+			}
+			if snap == nil {
+				snap = deploy.NewSnapshot(deploy.Manifest{}, nil, nil)
+			}
+
+			var output []byte
+			var report *tfstate.ExportReport
+			if to == "inventory" {
+				inventory := stack.SerializeInventory(s.Name().StackName(), snapshotProject(snap), snap)
+				if output, err = json.MarshalIndent(inventory, "", "    "); err != nil {
+					return err
+				}
+			} else {
+				var packages []string
+				for _, res := range snap.Resources {
+					if res.Custom && !providers.IsProviderType(res.Type) {
+						packages = tfstate.AppendUnique(packages, string(res.Type.Package()))
+					}
+				}
+				mapping, err := getTerraformMapping(providerNames, packages, mappings)
+				if err != nil {
+					return err
+				}
+				if output, report, err = tfstate.Export(snap, mapping); err != nil {
+					return err
+				}
+			}
+
+			// Keep the report out of the exported state when the state is written to standard out.
+			reportTo := os.Stdout
+			if out == "" {
+				reportTo = os.Stderr
+				if _, err = os.Stdout.Write(append(output, '\n')); err != nil {
+					return err
+				}
+			} else if err = ioutil.WriteFile(out, output, 0600); err != nil {
+				return errors.Wrap(err, "could not write exported state")
+			}
+			if report != nil {
+				printTerraformExportReport(reportTo, report)
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&to, "to", "inventory", "The format to export to: 'inventory' or 'terraform'")
+	cmd.PersistentFlags().StringSliceVar(
+		&mappings, "mapping", []string{}, "A JSON file that maps Terraform resource types to Pulumi resource types")
+	cmd.PersistentFlags().StringSliceVar(
+		&providerNames, "provider", []string{}, "A Pulumi provider whose schema maps Terraform resource types")
+	cmd.PersistentFlags().StringVarP(
+		&out, "out", "o", "", "The file to write the exported state to; defaults to standard out")
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+// snapshotProject returns the name of the project that a snapshot's resources belong to, if it has any.
+func snapshotProject(snap *deploy.Snapshot) tokens.PackageName {
+	if len(snap.Resources) == 0 {
+		return ""
+	}
+	return snap.Resources[0].URN.Project()
+}
+
+// printTerraformExportReport describes which resources were exported as Terraform state, and which had no mapping.
+func printTerraformExportReport(w io.Writer, report *tfstate.ExportReport) {
+	fmt.Fprintf(w, "Exported %d resources as Terraform state.\n", len(report.Exported))
+	if len(report.Unmapped) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nThe following %d resources have no mapping to a Terraform resource type and were not exported:\n",
+		len(report.Unmapped))
+	for _, urn := range report.Unmapped {
+		fmt.Fprintf(w, "    %s\n", urn)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/codegen/schema"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/resource/tfstate"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStateImportCmd() *cobra.Command {
+	var from string
+	var mappings []string
+	var providers []string
+	var out string
+	var force bool
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import the state of resources managed by another tool",
+		Long: "Import the state of resources managed by another tool\n" +
+			"\n" +
+			"Reads the state file of another infrastructure tool, given by --from, and converts the\n" +
+			"resources it records into a checkpoint for the stack given by --stack, or else the current\n" +
+			"stack.  The checkpoint replaces the stack's state, or is written to the file given by --out.\n" +
+			"The resources themselves are not changed.  Only --from terraform is supported today.\n" +
+			"\n" +
+			"Each Terraform resource type is mapped to a Pulumi resource type by the mapping tables in\n" +
+			"the schemas of the Pulumi providers given by --provider.  These default to the providers\n" +
+			"that share the names of the Terraform providers in the state file, if they are installed.\n" +
+			"Mappings may also be written by hand in JSON files given by --mapping, which take\n" +
+			"precedence.  Resources whose types have no mapping are reported and left out.",
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if from != "terraform" {
+				return errors.Errorf("unknown source '%s'; only 'terraform' is supported", from)
+			}
+
+			data, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return errors.Wrap(err, "could not read Terraform state")
+			}
+			st, err := tfstate.Parse(data)
+			if err != nil {
+				return err
+			}
+			var defaultProviders []string
+			for _, r := range st.Resources {
+				defaultProviders = tfstate.AppendUnique(defaultProviders, r.Provider)
+			}
+			mapping, err := getTerraformMapping(providers, defaultProviders, mappings)
+			if err != nil {
+				return err
+			}
+
+			proj, _, err := readProject()
+			if err != nil {
+				return err
+			}
+
+			// A checkpoint written to a file may be for any stack, but one that replaces a stack's state must be for
+			// a stack that exists.
+			opts := backend.DisplayOptions{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+			var s backend.Stack
+			name := tokens.QName(stackName)
+			if out == "" || stackName == "" {
+				if s, err = requireStack(stackName, false, opts, false /*setCurrent*/); err != nil {
+					return err
+				}
+				name = s.Name().StackName()
+			}
+
+			snap, report, err := tfstate.Convert(st, mapping, name, proj.Name)
+			if err != nil {
+				return err
+			}
+
+			if out != "" {
+				bytes, err := json.MarshalIndent(stack.SerializeCheckpoint(name, nil, snap), "", "    ")
+				if err != nil {
+					return err
+				}
+				if err = ioutil.WriteFile(out, bytes, 0600); err != nil {
+					return errors.Wrap(err, "could not write checkpoint")
+				}
+			} else {
+				// Refuse to discard any resources that the stack already manages unless asked to.
+				if !force {
+					existing, err := s.ExportDeployment(commandContext())
+					if err != nil {
+						return errors.Wrap(err, "could not export deployment")
+					}
+					snapshot, err := stack.DeserializeUntypedDeployment(existing)
+					if err != nil {
+						return errors.Wrap(err, "could not deserialize deployment")
+					}
+					if snapshot != nil && len(snapshot.Resources) > 0 {
+						return errors.Errorf("stack '%s' already has resources; rerun with --force to replace them",
+							s.Name())
+					}
+				}
+
+				bytes, err := json.Marshal(stack.SerializeDeployment(snap))
+				if err != nil {
+					return err
+				}
+				dep := apitype.UntypedDeployment{
+					Version:    apitype.DeploymentSchemaVersionCurrent,
+					Deployment: bytes,
+				}
+				if err = s.ImportDeployment(commandContext(), &dep); err != nil {
+					return errors.Wrap(err, "could not import deployment")
+				}
+			}
+
+			printTerraformImportReport(os.Stdout, report)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&from, "from", "terraform", "The tool whose state to import; only 'terraform' is supported")
+	cmd.PersistentFlags().StringSliceVar(
+		&mappings, "mapping", []string{}, "A JSON file that maps Terraform resource types to Pulumi resource types")
+	cmd.PersistentFlags().StringSliceVar(
+		&providers, "provider", []string{}, "A Pulumi provider whose schema maps Terraform resource types")
+	cmd.PersistentFlags().StringVarP(
+		&out, "out", "o", "", "Write the checkpoint to this file instead of replacing the stack's state")
+	cmd.PersistentFlags().BoolVarP(
+		&force, "force", "f", false, "Replace the stack's state even if it already has resources")
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+// getTerraformMapping builds the mapping between Terraform resource types and Pulumi resource types out of the schemas
+// of the given providers, or else of the default providers, plus the given mapping files.  Default providers that
+// cannot be loaded are skipped with a warning.
+func getTerraformMapping(providers, defaultProviders, files []string) (*tfstate.Mapping, error) {
+	explicit := len(providers) > 0
+	if !explicit {
+		providers = defaultProviders
+	}
+
+	mapping := tfstate.NewMapping()
+	for _, name := range providers {
+		bytes, err := getProviderSchema(tokens.Package(name), nil, true /*useCache*/)
+		var pkg *schema.Package
+		if err == nil {
+			pkg, err = schema.Parse(bytes)
+		}
+		if err != nil {
+			// Providers that were not asked for by name need not exist; their resources are simply reported.
+			if explicit {
+				return nil, err
+			}
+			cmdutil.Diag().Warningf(diag.Message("" /*urn*/, fmt.Sprintf(
+				"could not load the mapping of the %s provider: %v", name, err)))
+			continue
+		}
+		mapping.Merge(tfstate.MappingFromSchema(pkg))
+	}
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read mapping")
+		}
+		m, err := tfstate.ParseMapping(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", file)
+		}
+		mapping.Merge(m)
+	}
+	return mapping, nil
+}
+
+// printTerraformImportReport describes which Terraform resources were imported, and which had no mapping.
+func printTerraformImportReport(w io.Writer, report *tfstate.Report) {
+	fmt.Fprintf(w, "Imported %d resources from Terraform state.\n", len(report.Imported))
+	if len(report.Unmapped) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nThe following %d resources have no mapping to a Pulumi resource type and were not imported:\n",
+		len(report.Unmapped))
+	for _, r := range report.Unmapped {
+		fmt.Fprintf(w, "    %s (%s)\n", r.Address, r.Type)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/tfstate"
+)
+
+func TestPrintTerraformImportReport(t *testing.T) {
+	var buf bytes.Buffer
+	printTerraformImportReport(&buf, &tfstate.Report{
+		Imported: map[string]resource.URN{
+			"aws_vpc.main": "urn:pulumi:dev::proj::aws:ec2/vpc:Vpc::main",
+		},
+		Unmapped: []*tfstate.Resource{
+			{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket"},
+		},
+	})
+	assert.Equal(t, "Imported 1 resources from Terraform state.\n"+
+		"\n"+
+		"The following 1 resources have no mapping to a Pulumi resource type and were not imported:\n"+
+		"    aws_s3_bucket.logs (aws_s3_bucket)\n", buf.String())
+
+	buf.Reset()
+	printTerraformImportReport(&buf, &tfstate.Report{})
+	assert.Equal(t, "Imported 0 resources from Terraform state.\n", buf.String())
+}
@@ -0,0 +1,146 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/cloud"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/telemetry"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+func newTelemetryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "telemetry",
+		Short: "Manage the reporting of anonymized operation metrics",
+		Long: "Manage the reporting of anonymized operation metrics.\n" +
+			"\n" +
+			"If you opt in, then after each preview, update, refresh, or destroy the CLI reports the number\n" +
+			"of resources it changed, how long it and each kind of resource operation took, and the codes\n" +
+			"of any errors it failed with, along with the CLI's version and platform.  Reports never include\n" +
+			"the names of stacks, projects, or resources, nor the values of their properties or configuration.\n" +
+			"They help prioritize performance work.  Telemetry is off unless you turn it on.\n" +
+			"\n" +
+			"The most recent report is kept whether or not telemetry is on; `pulumi telemetry status` shows\n" +
+			"it in full, so you can see exactly what is, or would be, sent.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newTelemetrySetCmd("on", true))
+	cmd.AddCommand(newTelemetrySetCmd("off", false))
+	cmd.AddCommand(newTelemetryStatusCmd())
+
+	return cmd
+}
+
+func newTelemetrySetCmd(use string, enabled bool) *cobra.Command {
+	short := "Opt in to reporting anonymized operation metrics"
+	if !enabled {
+		short = "Opt out of reporting anonymized operation metrics"
+	}
+
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			state, err := telemetry.LoadState()
+			if err != nil {
+				return err
+			}
+			state.Enabled = enabled
+			if err = state.Save(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Telemetry is now %s\n", use)
+			return nil
+		}),
+	}
+}
+
+func newTelemetryStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show whether telemetry is on, along with the most recent report",
+		Args:  cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			state, err := telemetry.LoadState()
+			if err != nil {
+				return err
+			}
+
+			if state.Enabled {
+				fmt.Println("Telemetry is on")
+			} else {
+				fmt.Println("Telemetry is off; run `pulumi telemetry on` to opt in")
+			}
+
+			if state.LastReport == nil {
+				fmt.Println("No operations have been reported yet")
+				return nil
+			}
+
+			switch {
+			case state.LastReportSent:
+				fmt.Println("The most recent report, which was sent:")
+			case state.Enabled:
+				fmt.Println("The most recent report, which could not be sent:")
+			default:
+				fmt.Println("The most recent report, which would have been sent had telemetry been on:")
+			}
+			b, err := json.MarshalIndent(state.LastReport, "", "    ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}),
+	}
+}
+
+// operationTelemetry gathers the metrics of an operation, such as an update, in order to report them once it's done.
+type operationTelemetry struct {
+	kind    apitype.UpdateKind
+	start   time.Time
+	timings []engine.StepTiming
+}
+
+// beginOperationTelemetry starts gathering the metrics of an operation of the given kind that will be run with the
+// given engine options.
+func beginOperationTelemetry(kind apitype.UpdateKind, opts *engine.UpdateOptions) *operationTelemetry {
+	t := &operationTelemetry{kind: kind, start: time.Now()}
+	opts.OnStepTimings = func(timings []engine.StepTiming) {
+		t.timings = timings
+	}
+	return t
+}
+
+// report records the metrics of the operation, given its resource changes and the error it failed with, if any, and
+// sends them if the user has opted in to telemetry.  Failing to do so doesn't fail the operation.
+func (t *operationTelemetry) report(changes engine.ResourceChanges, err error) {
+	report := telemetry.NewReport(t.kind, time.Since(t.start), changes, t.timings, err)
+	if recordErr := telemetry.Record(cloud.DefaultURL()+"/api/telemetry", report); recordErr != nil {
+		logging.V(7).Infof("failed to report telemetry: %v", recordErr)
+	}
+}
@@ -0,0 +1,725 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// The commands that a trigger may run.
+const (
+	triggerUp      = "up"
+	triggerRefresh = "refresh"
+)
+
+// The ways in which the changes of a trigger's command may be approved.
+const (
+	approvalPreview = "preview" // the changes are only previewed.
+	approvalSafe    = "safe"    // the changes are made unless they would delete or replace resources.
+	approvalAuto    = "auto"    // the changes are made, whatever they are.
+)
+
+// The events upon which a trigger may run.
+const (
+	triggerOnSchedule = "schedule"
+	triggerOnWebhook  = "webhook"
+	triggerOnDrift    = "drift"
+)
+
+// minDriftInterval is the shortest interval at which a trigger may check its stack for drift.
+const minDriftInterval = time.Minute
+
+// triggerNameRegexp matches valid trigger names, which appear in the paths of their webhooks.
+var triggerNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+func newTriggerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trigger",
+		Short: "Update or refresh stacks when schedules, webhooks, or drift trigger them",
+		Long: "Update or refresh stacks when schedules, webhooks, or drift trigger them.\n" +
+			"\n" +
+			"Triggers are kept in the settings of the current project's workspace, on this machine.  Each names a\n" +
+			"stack, the command to run on it (up or refresh), the events that run it, and how the changes that\n" +
+			"the command finds are approved: `preview` only previews and reports them, which is the default;\n" +
+			"`safe` makes them unless they would delete or replace resources; and `auto` makes them whatever\n" +
+			"they are.  A trigger may run on a cron schedule, when a webhook bearing its token is received, or\n" +
+			"when a refresh finds that its stack's resources have drifted from their recorded state.\n" +
+			"\n" +
+			"Use `pulumi trigger add` to add a trigger, and `pulumi trigger run` to run the workspace's triggers.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newTriggerAddCmd())
+	cmd.AddCommand(newTriggerLsCmd())
+	cmd.AddCommand(newTriggerRmCmd())
+	cmd.AddCommand(newTriggerRunCmd())
+
+	return cmd
+}
+
+func newTriggerAddCmd() *cobra.Command {
+	var stackName string
+	var command string
+	var schedule string
+	var webhook bool
+	var drift string
+	var approval string
+
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Add a trigger to the current workspace",
+		Long: "Add a trigger to the current workspace.\n" +
+			"\n" +
+			"At least one of --schedule, --webhook, and --drift must be given.  A schedule has five fields, as in\n" +
+			"cron: the minute, hour, day of the month, month, and day of the week, e.g. `0 3 * * 1-5` for 3am on\n" +
+			"weekdays, in local time.  A trigger with a webhook is given a secret token, which is printed once\n" +
+			"and only a hash of which is saved; the trigger runs when `pulumi trigger run` receives a POST to\n" +
+			"/hooks/<name> that bears the token in an `Authorization: token <token>` header.  A drift check\n" +
+			"previews a refresh of the stack at the given interval, running the trigger if any resources changed.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			w, err := workspace.New()
+			if err != nil {
+				return err
+			}
+			settings := w.Settings()
+			if findTrigger(settings.Triggers, args[0]) != nil {
+				return errors.Errorf("a trigger named '%s' already exists", args[0])
+			}
+
+			s, err := requireStack(stackName, false, backend.DisplayOptions{Color: cmdutil.GetGlobalColorization()},
+				false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			t := &workspace.Trigger{
+				Name:     args[0],
+				Stack:    s.Name().String(),
+				Command:  command,
+				Schedule: schedule,
+				Drift:    drift,
+				Approval: approval,
+			}
+			var token string
+			if webhook {
+				token = newTriggerToken()
+				t.WebhookHash = hashTriggerToken(token)
+			}
+			if err = validateTrigger(t); err != nil {
+				return err
+			}
+
+			settings.Triggers = append(settings.Triggers, t)
+			if err = w.Save(); err != nil {
+				return err
+			}
+			fmt.Printf("Added trigger '%s'.\n", t.Name)
+			if token != "" {
+				fmt.Printf("Its webhook is POST /hooks/%s, with the token %s.\n", t.Name, token)
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "",
+		"The name of the stack on which to run the command. Defaults to the current stack")
+	cmd.PersistentFlags().StringVar(
+		&command, "command", triggerUp,
+		"The command to run: up or refresh")
+	cmd.PersistentFlags().StringVar(
+		&schedule, "schedule", "",
+		"A cron schedule, e.g. '0 3 * * *', on which to run")
+	cmd.PersistentFlags().BoolVar(
+		&webhook, "webhook", false,
+		"Run when a webhook bearing the trigger's token is received")
+	cmd.PersistentFlags().StringVar(
+		&drift, "drift", "",
+		"An interval, e.g. 1h, at which to check the stack for drift, running when any is found")
+	cmd.PersistentFlags().StringVar(
+		&approval, "approval", approvalPreview,
+		"How the command's changes are approved: preview, safe, or auto")
+
+	return cmd
+}
+
+func newTriggerLsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Args:  cmdutil.NoArgs,
+		Short: "List the triggers of the current workspace",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			w, err := workspace.New()
+			if err != nil {
+				return err
+			}
+			triggers := w.Settings().Triggers
+			if len(triggers) == 0 {
+				fmt.Println("No triggers; use `pulumi trigger add` to add one.")
+				return nil
+			}
+
+			maxname, maxstack := len("NAME"), len("STACK")
+			for _, t := range triggers {
+				if len(t.Name) > maxname {
+					maxname = len(t.Name)
+				}
+				if len(t.Stack) > maxstack {
+					maxstack = len(t.Stack)
+				}
+			}
+			format := "%-" + strconv.Itoa(maxname) + "s %-" + strconv.Itoa(maxstack) + "s %-8s %-8s %s\n"
+			fmt.Printf(format, "NAME", "STACK", "COMMAND", "APPROVAL", "RUNS ON")
+			for _, t := range triggers {
+				fmt.Printf(format, t.Name, t.Stack, t.Command, triggerApproval(t), describeTriggerEvents(t))
+			}
+			return nil
+		}),
+	}
+}
+
+func newTriggerRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm <name>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Remove a trigger from the current workspace",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			w, err := workspace.New()
+			if err != nil {
+				return err
+			}
+			settings := w.Settings()
+			for i, t := range settings.Triggers {
+				if t.Name == args[0] {
+					settings.Triggers = append(settings.Triggers[:i], settings.Triggers[i+1:]...)
+					return w.Save()
+				}
+			}
+			return errors.Errorf("no trigger named '%s' exists", args[0])
+		}),
+	}
+}
+
+func newTriggerRunCmd() *cobra.Command {
+	var address string
+	var parallel int
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Args:  cmdutil.NoArgs,
+		Short: "Run the triggers of the current workspace until interrupted",
+		Long: "Run the triggers of the current workspace until interrupted.\n" +
+			"\n" +
+			"Each trigger's command is run on its stack as its events occur.  Commands run one at a time, in the\n" +
+			"order in which they were triggered, and a trigger that is triggered again while it is waiting to run\n" +
+			"runs just once.  Drift checks are made upon starting, and then at each trigger's interval.  If any\n" +
+			"trigger has a webhook, webhooks are received at the given address.  A command that fails is reported,\n" +
+			"and the triggers continue to run.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			w, err := workspace.New()
+			if err != nil {
+				return err
+			}
+			triggers := w.Settings().Triggers
+			if len(triggers) == 0 {
+				return errors.New("there are no triggers to run; use `pulumi trigger add` to add one")
+			}
+			for _, t := range triggers {
+				if err = validateTrigger(t); err != nil {
+					return err
+				}
+			}
+
+			timers, err := newTriggerTimers(triggers, time.Now())
+			if err != nil {
+				return err
+			}
+			p := &triggerPerformer{parallel: parallel, color: cmdutil.GetGlobalColorization()}
+			r := newTriggerRunner(triggers, p.perform, p.drifted)
+
+			if hasWebhooks(triggers) {
+				l, err := net.Listen("tcp", address)
+				if err != nil {
+					return errors.Wrap(err, "listening for webhooks")
+				}
+				fmt.Printf("Receiving webhooks at http://%s/hooks/<name>\n", l.Addr())
+				go func() {
+					err := http.Serve(l, r.webhookHandler())
+					cmdutil.Diag().Errorf(diag.RawMessage("" /*urn*/, fmt.Sprintf("receiving webhooks: %v", err)))
+				}()
+			}
+
+			go r.schedule(timers)
+			return r.run()
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&address, "address", "127.0.0.1:8086",
+		"The address at which to receive webhooks")
+	cmd.PersistentFlags().IntVarP(
+		&parallel, "parallel", "p", defaultParallel,
+		"Allow P resource operations to run in parallel at once (<=1 for no parallelism)")
+
+	return cmd
+}
+
+// newTriggerToken returns a new random token for a trigger's webhook.
+func newTriggerToken() string {
+	tokenBytes := make([]byte, 20)
+	_, err := cryptorand.Read(tokenBytes)
+	contract.AssertNoErrorf(err, "could not get random bytes")
+	return hex.EncodeToString(tokenBytes)
+}
+
+// hashTriggerToken returns the hex-encoded SHA-256 hash of a webhook's token, which is saved in the token's place.
+func hashTriggerToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// findTrigger returns the trigger with the given name, or nil if there isn't one.
+func findTrigger(triggers []*workspace.Trigger, name string) *workspace.Trigger {
+	for _, t := range triggers {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// hasWebhooks returns true if any of the given triggers has a webhook.
+func hasWebhooks(triggers []*workspace.Trigger) bool {
+	for _, t := range triggers {
+		if t.WebhookHash != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// triggerApproval returns how the changes of the given trigger's command are approved.
+func triggerApproval(t *workspace.Trigger) string {
+	if t.Approval == "" {
+		return approvalPreview
+	}
+	return t.Approval
+}
+
+// validateTrigger returns an error if the given trigger is not well-formed.
+func validateTrigger(t *workspace.Trigger) error {
+	if !triggerNameRegexp.MatchString(t.Name) {
+		return errors.Errorf("trigger name '%s' may only contain letters, digits, '_', '.', and '-'", t.Name)
+	}
+	if t.Stack == "" {
+		return errors.Errorf("trigger '%s' must name a stack", t.Name)
+	}
+	if t.Command != triggerUp && t.Command != triggerRefresh {
+		return errors.Errorf("trigger '%s' has unknown command '%s'; expected %s or %s",
+			t.Name, t.Command, triggerUp, triggerRefresh)
+	}
+	switch triggerApproval(t) {
+	case approvalPreview, approvalSafe, approvalAuto:
+	default:
+		return errors.Errorf("trigger '%s' has unknown approval '%s'; expected %s, %s, or %s",
+			t.Name, t.Approval, approvalPreview, approvalSafe, approvalAuto)
+	}
+	if t.Schedule == "" && t.WebhookHash == "" && t.Drift == "" {
+		return errors.Errorf("trigger '%s' must run on a schedule, a webhook, or drift", t.Name)
+	}
+	if t.Schedule != "" {
+		if _, err := parseCronSchedule(t.Schedule); err != nil {
+			return errors.Wrapf(err, "trigger '%s'", t.Name)
+		}
+	}
+	if t.Drift != "" {
+		if d, err := time.ParseDuration(t.Drift); err != nil || d < minDriftInterval {
+			return errors.Errorf("trigger '%s' has drift interval '%s'; expected a duration of at least %v",
+				t.Name, t.Drift, minDriftInterval)
+		}
+	}
+	return nil
+}
+
+// describeTriggerEvents describes the events upon which the given trigger runs.
+func describeTriggerEvents(t *workspace.Trigger) string {
+	var events []string
+	if t.Schedule != "" {
+		events = append(events, fmt.Sprintf("schedule '%s'", t.Schedule))
+	}
+	if t.WebhookHash != "" {
+		events = append(events, triggerOnWebhook)
+	}
+	if t.Drift != "" {
+		events = append(events, fmt.Sprintf("drift, checked every %s", t.Drift))
+	}
+	return strings.Join(events, ", ")
+}
+
+// triggerEvent is an occurrence of an event upon which a trigger runs.
+type triggerEvent struct {
+	trigger *workspace.Trigger
+	on      string // the kind of event: a schedule, a webhook, or drift.
+}
+
+// triggerTimer tracks when a trigger's schedule, or its next drift check, is next due.
+type triggerTimer struct {
+	trigger  *workspace.Trigger
+	on       string        // the kind of event: a schedule or drift.
+	cron     *cronSchedule // the trigger's schedule, for schedules.
+	interval time.Duration // the interval between checks, for drift.
+	next     time.Time     // when the timer is next due, or zero if it never will be.
+}
+
+// newTriggerTimers returns the timers of the given triggers' schedules and drift checks, as of the given time.  Drift
+// checks are due at once.
+func newTriggerTimers(triggers []*workspace.Trigger, now time.Time) ([]*triggerTimer, error) {
+	var timers []*triggerTimer
+	for _, t := range triggers {
+		if t.Schedule != "" {
+			cron, err := parseCronSchedule(t.Schedule)
+			if err != nil {
+				return nil, errors.Wrapf(err, "trigger '%s'", t.Name)
+			}
+			timers = append(timers, &triggerTimer{trigger: t, on: triggerOnSchedule, cron: cron, next: cron.next(now)})
+		}
+		if t.Drift != "" {
+			interval, err := time.ParseDuration(t.Drift)
+			if err != nil {
+				return nil, errors.Wrapf(err, "trigger '%s'", t.Name)
+			}
+			timers = append(timers, &triggerTimer{trigger: t, on: triggerOnDrift, interval: interval, next: now})
+		}
+	}
+	return timers, nil
+}
+
+// nextTriggerTime returns the time at which the first of the given timers is due, or false if none ever will be.
+func nextTriggerTime(timers []*triggerTimer) (time.Time, bool) {
+	var next time.Time
+	for _, timer := range timers {
+		if !timer.next.IsZero() && (next.IsZero() || timer.next.Before(next)) {
+			next = timer.next
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// dueTriggers returns the events of the given timers that are due as of the given time, and sets each such timer to
+// when it is next due.
+func dueTriggers(timers []*triggerTimer, now time.Time) []triggerEvent {
+	var events []triggerEvent
+	for _, timer := range timers {
+		if timer.next.IsZero() || timer.next.After(now) {
+			continue
+		}
+		events = append(events, triggerEvent{trigger: timer.trigger, on: timer.on})
+		if timer.cron != nil {
+			timer.next = timer.cron.next(now)
+		} else {
+			timer.next = now.Add(timer.interval)
+		}
+	}
+	return events
+}
+
+// triggerRunner runs triggers, one at a time, as their events occur.
+type triggerRunner struct {
+	triggers []*workspace.Trigger
+	perform  func(t *workspace.Trigger, on string) error // runs a trigger's command.
+	drifted  func(t *workspace.Trigger) (bool, error)    // checks whether a trigger's stack has drifted.
+	events   chan triggerEvent                           // the events of the triggers that are waiting to run.
+
+	lock    sync.Mutex
+	pending map[string]bool // the names of the triggers that are waiting to run.
+}
+
+func newTriggerRunner(triggers []*workspace.Trigger, perform func(t *workspace.Trigger, on string) error,
+	drifted func(t *workspace.Trigger) (bool, error)) *triggerRunner {
+
+	// At most one event per trigger is ever waiting, so the events never block.
+	return &triggerRunner{
+		triggers: triggers,
+		perform:  perform,
+		drifted:  drifted,
+		events:   make(chan triggerEvent, len(triggers)),
+		pending:  make(map[string]bool),
+	}
+}
+
+// fire queues the given event's trigger to run, unless it is already waiting to, and returns true if it was queued.
+func (r *triggerRunner) fire(e triggerEvent) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.pending[e.trigger.Name] {
+		return false
+	}
+	r.pending[e.trigger.Name] = true
+	r.events <- e
+	return true
+}
+
+// schedule fires the events of the given timers as they come due.
+func (r *triggerRunner) schedule(timers []*triggerTimer) {
+	for {
+		next, ok := nextTriggerTime(timers)
+		if !ok {
+			return
+		}
+		time.Sleep(time.Until(next))
+		for _, e := range dueTriggers(timers, time.Now()) {
+			r.fire(e)
+		}
+	}
+}
+
+// run runs each trigger as it is fired, until an update is canceled.
+func (r *triggerRunner) run() error {
+	for e := range r.events {
+		if err := r.handle(e); err == context.Canceled {
+			return errors.New("trigger run cancelled")
+		}
+	}
+	return nil
+}
+
+// handle runs the trigger of the given event, reporting its progress.  If the event is drift, the trigger only runs if
+// its stack has in fact drifted.  The trigger's failure to run is reported, and returned.
+func (r *triggerRunner) handle(e triggerEvent) error {
+	// Once the trigger has started, another event may queue it to run again.
+	r.lock.Lock()
+	delete(r.pending, e.trigger.Name)
+	r.lock.Unlock()
+
+	t := e.trigger
+	err := func() error {
+		if e.on == triggerOnDrift {
+			fmt.Printf("%s: checking stack %s for drift for trigger '%s'\n", time.Now().Format(time.RFC3339),
+				t.Stack, t.Name)
+			drifted, err := r.drifted(t)
+			if err != nil || !drifted {
+				return err
+			}
+		}
+		fmt.Printf("%s: running `pulumi %s` on stack %s for trigger '%s' upon %s\n", time.Now().Format(time.RFC3339),
+			t.Command, t.Stack, t.Name, e.on)
+		return r.perform(t, e.on)
+	}()
+	if err != nil && err != context.Canceled {
+		if perr := PrintEngineError(err); perr != nil {
+			cmdutil.Diag().Errorf(diag.RawMessage("" /*urn*/, fmt.Sprintf("trigger '%s': %v", t.Name, perr)))
+		}
+	}
+	return err
+}
+
+// webhookHandler returns the handler for the webhooks that run triggers.  A trigger with a webhook runs when a POST to
+// /hooks/<name> bears its token in an `Authorization: token` header.  Tokens are not accepted in query parameters,
+// which proxies and access logs record.
+func (r *triggerRunner) webhookHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !strings.HasPrefix(req.URL.Path, "/hooks/") {
+			http.NotFound(w, req)
+			return
+		}
+		t := findTrigger(r.triggers, strings.TrimPrefix(req.URL.Path, "/hooks/"))
+		if t == nil || t.WebhookHash == "" {
+			http.NotFound(w, req)
+			return
+		}
+
+		auth := req.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "token ") ||
+			subtle.ConstantTimeCompare([]byte(hashTriggerToken(strings.TrimPrefix(auth, "token "))),
+				[]byte(t.WebhookHash)) != 1 {
+			http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+			return
+		}
+		if req.Method != "POST" {
+			http.Error(w, "POST must be used", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		if r.fire(triggerEvent{trigger: t, on: triggerOnWebhook}) {
+			fmt.Fprintf(w, "trigger '%s' is queued to run\n", t.Name)
+		} else {
+			fmt.Fprintf(w, "trigger '%s' is already waiting to run\n", t.Name)
+		}
+	})
+}
+
+// triggerPerformer runs the commands of triggers, and checks their stacks for drift.
+type triggerPerformer struct {
+	parallel int
+	color    colors.Colorization
+}
+
+// triggerOperation is a command that is ready to run for a trigger.
+type triggerOperation struct {
+	stack backend.Stack
+	proj  *workspace.Project
+	root  string
+	m     backend.UpdateMetadata
+	opts  backend.UpdateOptions
+}
+
+// prepare returns the operation with which to run a command for the given trigger, writing its events to the given
+// writer, if any.
+func (p *triggerPerformer) prepare(t *workspace.Trigger, message string, events io.Writer) (*triggerOperation, error) {
+	op := &triggerOperation{
+		opts: backend.UpdateOptions{
+			Display: backend.DisplayOptions{
+				Color:         p.color,
+				SuppressSames: true,
+			},
+		},
+	}
+	if events != nil {
+		op.opts.Display.EventLog = backend.NewEventLogWriter(nopWriteCloser{events})
+	}
+
+	var err error
+	if op.stack, err = requireStack(t.Stack, false, op.opts.Display, false /*setCurrent*/); err != nil {
+		return nil, err
+	}
+	if op.proj, op.root, err = readProject(); err != nil {
+		return nil, err
+	}
+	if op.m, err = getUpdateMetadata(message, op.root); err != nil {
+		return nil, errors.Wrap(err, "gathering environment metadata")
+	}
+	op.opts.Engine = engine.UpdateOptions{Parallel: p.parallel, ProviderOptions: providerOptions}
+	setGuardOptions(op.m, "", &op.opts.Engine)
+	setRandomSeed(&op.opts.Engine)
+	return op, nil
+}
+
+// preview previews the operation.
+func (op *triggerOperation) preview() (engine.ResourceChanges, error) {
+	return op.stack.Preview(commandContext(), op.proj, op.root, op.m, op.opts, cancellationScopes)
+}
+
+// perform runs the given trigger's command, upon the given kind of event, approving its changes as the trigger says.
+func (p *triggerPerformer) perform(t *workspace.Trigger, on string) error {
+	op, err := p.prepare(t, fmt.Sprintf("Run by trigger '%s' upon %s", t.Name, on), nil)
+	if err != nil {
+		return err
+	}
+	approval := triggerApproval(t)
+
+	if t.Command == triggerRefresh {
+		// A refresh changes only the stack's state, never its resources, so only the preview approval holds it back.
+		// Its changes are previewed along with those that the program would then make.
+		if approval == approvalPreview {
+			op.opts.Engine.Refresh = true
+			_, err = op.preview()
+			return err
+		}
+		op.opts.AutoApprove, op.opts.SkipPreview = true, true
+		tel := beginOperationTelemetry(apitype.RefreshUpdate, &op.opts.Engine)
+		changes, err := op.stack.Refresh(commandContext(), op.proj, op.root, op.m, op.opts, cancellationScopes)
+		tel.report(changes, err)
+		return err
+	}
+
+	changes, err := op.preview()
+	if err != nil || approval == approvalPreview || !changes.HasChanges() {
+		return err
+	}
+	if approval == approvalSafe {
+		if counts := destructiveChanges(changes); len(counts) > 0 {
+			return errors.Errorf("the update would %s, which trigger '%s' may not approve; review it and run "+
+				"`pulumi up` to perform it", strings.Join(counts, " and "), t.Name)
+		}
+		// The program may change between the preview and the update, so the update itself may not delete either.
+		op.opts.Engine.StepDecider = noDeleteStepDecider{format: "trigger '" + t.Name + "' may not delete %s"}
+	}
+
+	op.opts.AutoApprove, op.opts.SkipPreview = true, true
+	tel := beginOperationTelemetry(apitype.UpdateUpdate, &op.opts.Engine)
+	changes, err = op.stack.Update(commandContext(), op.proj, op.root, op.m, op.opts, cancellationScopes)
+	tel.report(changes, err)
+	if err != nil {
+		return err
+	}
+	recordStackReferences(op.stack)
+	return nil
+}
+
+// drifted returns true if a refresh of the given trigger's stack would find that any of its resources have changed.
+func (p *triggerPerformer) drifted(t *workspace.Trigger) (bool, error) {
+	var events bytes.Buffer
+	op, err := p.prepare(t, "", &events)
+	if err != nil {
+		return false, err
+	}
+	op.opts.Engine.Refresh = true
+	if _, err = op.preview(); err != nil {
+		return false, err
+	}
+	return previewFoundDrift(&events)
+}
+
+// previewFoundDrift returns true if the summary in the given event log of a preview counts any resources that the
+// refresh which preceded the preview found had changed.
+func previewFoundDrift(log io.Reader) (bool, error) {
+	dec := json.NewDecoder(log)
+	for {
+		var e apitype.EngineEvent
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF {
+				return false, nil
+			}
+			return false, errors.Wrap(err, "reading the preview's events")
+		}
+		if e.Summary != nil {
+			for _, n := range e.Summary.RefreshChanges {
+				if n > 0 {
+					return true, nil
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+		"* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *", "1-a * * * *", "@sometimes"} {
+		_, err := parseCronSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+
+	sched, err := parseCronSchedule("0,30 9-17/4 * * *")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1|1<<30), sched.minutes)
+	assert.Equal(t, uint64(1<<9|1<<13|1<<17), sched.hours)
+
+	// Sunday may be written as 7, and a single value with a step runs to the end of the range.
+	sched, err = parseCronSchedule("5/20 * * * 7")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1<<5|1<<25|1<<45), sched.minutes)
+	assert.Equal(t, uint64(1), sched.weekdays&1)
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	// 2018-06-15 is a Friday.
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2018, month, day, hour, min, 0, 0, time.UTC)
+	}
+	now := at(time.June, 15, 10, 7).Add(30 * time.Second)
+
+	cases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", at(time.June, 15, 10, 8)},
+		{"*/15 * * * *", at(time.June, 15, 10, 15)},
+		{"0 3 * * *", at(time.June, 16, 3, 0)},
+		{"@hourly", at(time.June, 15, 11, 0)},
+		{"@daily", at(time.June, 16, 0, 0)},
+		{"@weekly", at(time.June, 17, 0, 0)},
+		{"@monthly", at(time.July, 1, 0, 0)},
+		{"0 0 * * 1-5", at(time.June, 18, 0, 0)},
+		{"30 12 29 2 *", time.Date(2020, time.February, 29, 12, 30, 0, 0, time.UTC)},
+		// When both the day of the month and the day of the week are restricted, either may match.
+		{"0 0 20 * 6", at(time.June, 16, 0, 0)},
+		{"0 0 16 * 1", at(time.June, 16, 0, 0)},
+		// The 31st of February never comes.
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, c := range cases {
+		sched, err := parseCronSchedule(c.spec)
+		if assert.NoError(t, err, c.spec) {
+			assert.Equal(t, c.next, sched.next(now), c.spec)
+		}
+	}
+
+	// A schedule that is due at the given time next runs after it.
+	sched, err := parseCronSchedule("0 * * * *")
+	assert.NoError(t, err)
+	assert.Equal(t, at(time.June, 15, 11, 0), sched.next(at(time.June, 15, 10, 0)))
+}
+
+func TestValidateTrigger(t *testing.T) {
+	valid := func() *workspace.Trigger {
+		return &workspace.Trigger{Name: "nightly", Stack: "dev", Command: triggerUp, Schedule: "0 3 * * *"}
+	}
+	assert.NoError(t, validateTrigger(valid()))
+
+	cases := map[string]func(t *workspace.Trigger){
+		"name":     func(t *workspace.Trigger) { t.Name = "a/b" },
+		"stack":    func(t *workspace.Trigger) { t.Stack = "" },
+		"command":  func(t *workspace.Trigger) { t.Command = "destroy" },
+		"approval": func(t *workspace.Trigger) { t.Approval = "always" },
+		"events":   func(t *workspace.Trigger) { t.Schedule = "" },
+		"schedule": func(t *workspace.Trigger) { t.Schedule = "0 25 * * *" },
+		"drift":    func(t *workspace.Trigger) { t.Drift = "30s" },
+		"interval": func(t *workspace.Trigger) { t.Drift = "hourly" },
+	}
+	for name, invalidate := range cases {
+		trigger := valid()
+		invalidate(trigger)
+		assert.Error(t, validateTrigger(trigger), name)
+	}
+
+	trigger := valid()
+	trigger.Command, trigger.Approval, trigger.Schedule, trigger.Drift = triggerRefresh, approvalAuto, "", "1h"
+	assert.NoError(t, validateTrigger(trigger))
+	token := newTriggerToken()
+	trigger.Drift, trigger.WebhookHash = "", hashTriggerToken(token)
+	assert.NoError(t, validateTrigger(trigger))
+	assert.Len(t, token, 40)
+	assert.NotEqual(t, token, newTriggerToken())
+	assert.NotContains(t, trigger.WebhookHash, token)
+}
+
+func TestDueTriggers(t *testing.T) {
+	scheduled := &workspace.Trigger{Name: "scheduled", Schedule: "*/10 * * * *"}
+	drifting := &workspace.Trigger{Name: "drifting", Drift: "15m"}
+	hooked := &workspace.Trigger{Name: "hooked", WebhookHash: hashTriggerToken("token")}
+
+	now := time.Date(2018, time.June, 15, 10, 5, 0, 0, time.UTC)
+	timers, err := newTriggerTimers([]*workspace.Trigger{scheduled, drifting, hooked}, now)
+	assert.NoError(t, err)
+	assert.Len(t, timers, 2)
+
+	// Drift is checked at once.
+	next, ok := nextTriggerTime(timers)
+	assert.True(t, ok)
+	assert.Equal(t, now, next)
+	assert.Equal(t, []triggerEvent{{trigger: drifting, on: triggerOnDrift}}, dueTriggers(timers, now))
+
+	next, _ = nextTriggerTime(timers)
+	assert.Equal(t, now.Add(5*time.Minute), next)
+	assert.Empty(t, dueTriggers(timers, now.Add(4*time.Minute)))
+	assert.Equal(t, []triggerEvent{{trigger: scheduled, on: triggerOnSchedule}},
+		dueTriggers(timers, now.Add(5*time.Minute)))
+
+	// Timers that have fallen behind are due just once.
+	assert.Equal(t, []triggerEvent{
+		{trigger: scheduled, on: triggerOnSchedule},
+		{trigger: drifting, on: triggerOnDrift},
+	}, dueTriggers(timers, now.Add(time.Hour)))
+	next, _ = nextTriggerTime(timers)
+	assert.Equal(t, now.Add(time.Hour+5*time.Minute), next)
+
+	_, ok = nextTriggerTime(nil)
+	assert.False(t, ok)
+}
+
+func TestTriggerWebhooks(t *testing.T) {
+	hooked := &workspace.Trigger{Name: "hooked", WebhookHash: hashTriggerToken("secret")}
+	scheduled := &workspace.Trigger{Name: "scheduled", Schedule: "@daily"}
+	r := newTriggerRunner([]*workspace.Trigger{hooked, scheduled}, nil, nil)
+
+	post := func(method, path, auth string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		r.webhookHandler().ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, post("POST", "/", ""))
+	assert.Equal(t, http.StatusNotFound, post("POST", "/hooks/unknown", "token secret"))
+	assert.Equal(t, http.StatusNotFound, post("POST", "/hooks/scheduled", "token "))
+	assert.Equal(t, http.StatusUnauthorized, post("POST", "/hooks/hooked", ""))
+	assert.Equal(t, http.StatusUnauthorized, post("POST", "/hooks/hooked", "token wrong"))
+	assert.Equal(t, http.StatusUnauthorized, post("POST", "/hooks/hooked", "secret"))
+	assert.Equal(t, http.StatusMethodNotAllowed, post("GET", "/hooks/hooked", "token secret"))
+	assert.Len(t, r.events, 0)
+
+	// Tokens are only accepted in the Authorization header, and never as the stored hash itself.
+	assert.Equal(t, http.StatusUnauthorized, post("POST", "/hooks/hooked?token=secret", ""))
+	assert.Equal(t, http.StatusUnauthorized, post("POST", "/hooks/hooked", "token "+hooked.WebhookHash))
+
+	// A trigger that is already waiting to run is not queued again.
+	assert.Equal(t, http.StatusAccepted, post("POST", "/hooks/hooked", "token secret"))
+	assert.Equal(t, http.StatusAccepted, post("POST", "/hooks/hooked", "token secret"))
+	assert.Len(t, r.events, 1)
+	assert.Equal(t, triggerEvent{trigger: hooked, on: triggerOnWebhook}, <-r.events)
+}
+
+func TestTriggerRunnerHandle(t *testing.T) {
+	trigger := &workspace.Trigger{Name: "drifting", Command: triggerUp, Drift: "1h"}
+
+	var performed []string
+	drifted := false
+	r := newTriggerRunner([]*workspace.Trigger{trigger},
+		func(t *workspace.Trigger, on string) error {
+			performed = append(performed, on)
+			return nil
+		},
+		func(t *workspace.Trigger) (bool, error) {
+			return drifted, nil
+		})
+
+	// A drift event runs the trigger only if its stack has drifted.
+	assert.True(t, r.fire(triggerEvent{trigger: trigger, on: triggerOnDrift}))
+	assert.False(t, r.fire(triggerEvent{trigger: trigger, on: triggerOnWebhook}))
+	assert.NoError(t, r.handle(<-r.events))
+	assert.Empty(t, performed)
+
+	// Once it has started, the trigger may be queued again.
+	drifted = true
+	assert.True(t, r.fire(triggerEvent{trigger: trigger, on: triggerOnDrift}))
+	assert.NoError(t, r.handle(<-r.events))
+	assert.True(t, r.fire(triggerEvent{trigger: trigger, on: triggerOnSchedule}))
+	assert.NoError(t, r.handle(<-r.events))
+	assert.Equal(t, []string{triggerOnDrift, triggerOnSchedule}, performed)
+}
+
+func TestPreviewFoundDrift(t *testing.T) {
+	drifted, err := previewFoundDrift(strings.NewReader(
+		`{"sequence":0,"timestamp":0,"preludeEvent":{"config":{}}}` + "\n" +
+			`{"sequence":1,"timestamp":0,"summaryEvent":{"isPreview":true,"maybeCorrupt":false,` +
+			`"durationSeconds":0,"resourceChanges":{"same":3},"refreshChanges":{"update":1}}}` + "\n"))
+	assert.NoError(t, err)
+	assert.True(t, drifted)
+
+	drifted, err = previewFoundDrift(strings.NewReader(
+		`{"sequence":0,"timestamp":0,"summaryEvent":{"isPreview":true,"maybeCorrupt":false,` +
+			`"durationSeconds":0,"resourceChanges":{"update":2}}}` + "\n"))
+	assert.NoError(t, err)
+	assert.False(t, drifted)
+
+	_, err = previewFoundDrift(strings.NewReader(`{"sequence":`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,337 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/glob"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newTTLCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ttl",
+		Short: "Find and destroy stacks and resources that have outlived their time-to-live",
+		Long: "Find and destroy stacks and resources that have outlived their time-to-live.\n" +
+			"\n" +
+			"A stack's time-to-live is set in the `ttl` section of its settings file, Pulumi.<stack>.yaml.\n" +
+			"Its `stack` value is how long after its last update the whole stack expires, and its\n" +
+			"`resources` map gives, for resource types (or `*` patterns), how long after their creation\n" +
+			"resources of those types expire. Durations are written like 90m, 72h, or 7d. For example:\n" +
+			"\n" +
+			"    ttl:\n" +
+			"      stack: 7d\n" +
+			"      resources:\n" +
+			"        aws:ec2/instance:Instance: 24h\n" +
+			"\n" +
+			"This is ideal for ephemeral environments, e.g. those created to review a change.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newTTLEnforceCmd())
+
+	return cmd
+}
+
+func newTTLEnforceCmd() *cobra.Command {
+	var stackName string
+	var destroy bool
+	var nonInteractive bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "enforce",
+		Short: "Report, and optionally destroy, stacks and resources past their time-to-live",
+		Long: "Report, and optionally destroy, stacks and resources past their time-to-live.\n" +
+			"\n" +
+			"By default, every stack of the current project is checked; use --stack to check just one.\n" +
+			"Pass --destroy to destroy the resources of each expired stack, and each expired resource\n" +
+			"of the other stacks along with the resources that depend upon it. Each destruction is\n" +
+			"previewed and must be confirmed unless --yes is passed. Destroyed stacks are not removed;\n" +
+			"use `pulumi stack rm` to do so.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			interactive := isInteractive(nonInteractive)
+
+			// Update options are only needed to destroy; merely reporting expired resources needs no approval.
+			var opts backend.UpdateOptions
+			if destroy {
+				if !interactive {
+					yes = true // auto-approve changes, since we cannot prompt.
+				}
+				var err error
+				if opts, err = updateFlagsToOptions(interactive, false /*skipPreview*/, yes); err != nil {
+					return err
+				}
+			}
+			opts.Display = backend.DisplayOptions{
+				Color:         cmdutil.GetGlobalColorization(),
+				IsInteractive: interactive,
+			}
+
+			proj, root, err := readProject()
+			if err != nil {
+				return err
+			}
+
+			stacks, err := projectStacks(stackName, proj, opts.Display)
+			if err != nil {
+				return err
+			}
+
+			now, found := time.Now(), false
+			for _, s := range stacks {
+				snap, err := s.Snapshot(commandContext())
+				if err != nil {
+					return err
+				}
+				ps, err := workspace.DetectProjectStack(s.Name().StackName())
+				if err != nil {
+					return err
+				}
+				expired, err := findExpired(ps.TTL, snap, now)
+				if err != nil {
+					return errors.Wrapf(err, "checking the time-to-live of stack '%s'", s.Name())
+				}
+				if !expired.any() {
+					continue
+				}
+				found = true
+				printExpired(s.Name().String(), expired, now)
+
+				if !destroy {
+					continue
+				}
+				m, err := getUpdateMetadata("Destroyed after its time-to-live expired", root)
+				if err != nil {
+					return errors.Wrap(err, "gathering environment metadata")
+				}
+				opts.Engine = engine.UpdateOptions{Parallel: defaultParallel, ProviderOptions: providerOptions}
+				if !expired.Stack {
+					for _, res := range expired.Resources {
+						opts.Engine.DeleteTargets = append(opts.Engine.DeleteTargets, res.URN)
+					}
+				}
+				setGuardOptions(m, "", &opts.Engine)
+				if _, err = s.Destroy(commandContext(), proj, root, m, opts, cancellationScopes); err != nil {
+					return PrintEngineError(err)
+				}
+			}
+
+			if !found {
+				fmt.Println("No stacks or resources have outlived their time-to-live.")
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "",
+		"The name of the stack to check. Defaults to every stack of the current project")
+	cmd.PersistentFlags().BoolVar(
+		&destroy, "destroy", false,
+		"Destroy the expired stacks and resources")
+	cmd.PersistentFlags().BoolVar(
+		&nonInteractive, "non-interactive", false, "Disable interactive mode")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Automatically approve and perform the destruction after previewing it")
+
+	return cmd
+}
+
+// projectStacks returns the stack with the given name or, if the name is empty, every stack of the given project.
+func projectStacks(
+	stackName string, proj *workspace.Project, opts backend.DisplayOptions) ([]backend.Stack, error) {
+
+	if stackName != "" {
+		s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+		if err != nil {
+			return nil, err
+		}
+		return []backend.Stack{s}, nil
+	}
+
+	b, err := currentBackend(opts)
+	if err != nil {
+		return nil, err
+	}
+	return b.ListStacks(commandContext(), &proj.Name)
+}
+
+// ttlExpiry describes the parts of a stack that have outlived their time-to-live.
+type ttlExpiry struct {
+	Stack     bool              // true if the whole stack has expired.
+	Since     time.Time         // the time at which the stack expired, if it has.
+	Resources []expiredResource // the resources that have expired, if the stack has not.
+}
+
+// expiredResource is a resource that has outlived its time-to-live.
+type expiredResource struct {
+	URN   resource.URN  // the resource's URN.
+	TTL   time.Duration // the resource's time-to-live.
+	Since time.Time     // the time at which the resource expired.
+}
+
+func (e ttlExpiry) any() bool {
+	return e.Stack || len(e.Resources) > 0
+}
+
+// findExpired returns the parts of the given stack snapshot that have outlived the given time-to-live at the given
+// time.  The stack's own time-to-live is counted from its last update, or from its initialization if it has never
+// been updated, and a resource's from its creation.  Resources whose creation time is unknown never expire.
+func findExpired(ttl *workspace.StackTTL, snap *deploy.Snapshot, now time.Time) (ttlExpiry, error) {
+	var expiry ttlExpiry
+	if ttl == nil {
+		return expiry, nil
+	}
+
+	if ttl.Stack != "" {
+		d, err := parseTTL(ttl.Stack)
+		if err != nil {
+			return expiry, err
+		}
+		last, err := lastUpdated(ttl, snap)
+		if err != nil {
+			return expiry, err
+		}
+		if !last.IsZero() && !last.Add(d).After(now) {
+			expiry.Stack, expiry.Since = true, last.Add(d)
+			return expiry, nil
+		}
+	}
+	if snap == nil {
+		return expiry, nil
+	}
+
+	// Every pattern that matches a resource's type applies, and the shortest time-to-live wins.
+	type pattern struct {
+		re  *regexp.Regexp
+		ttl time.Duration
+	}
+	var patterns []pattern
+	for p, v := range ttl.Resources {
+		d, err := parseTTL(v)
+		if err != nil {
+			return expiry, errors.Wrapf(err, "resources of type '%s'", p)
+		}
+		patterns = append(patterns, pattern{re: glob.Compile(p), ttl: d})
+	}
+	for _, res := range snap.Resources {
+		if res.Delete || res.Created.IsZero() || providers.IsProviderType(res.Type) {
+			continue
+		}
+		var d time.Duration
+		for _, p := range patterns {
+			if p.re.MatchString(string(res.Type)) && (d == 0 || p.ttl < d) {
+				d = p.ttl
+			}
+		}
+		if d != 0 && !res.Created.Add(d).After(now) {
+			expiry.Resources = append(expiry.Resources, expiredResource{
+				URN:   res.URN,
+				TTL:   d,
+				Since: res.Created.Add(d),
+			})
+		}
+	}
+	return expiry, nil
+}
+
+// lastUpdated returns the time from which a stack's time-to-live counts: the time of its last update, or, if it has
+// never been updated, the time at which it was initialized.  It returns the zero time if neither is known.
+func lastUpdated(ttl *workspace.StackTTL, snap *deploy.Snapshot) (time.Time, error) {
+	if snap != nil && !snap.Manifest.Time.IsZero() {
+		return snap.Manifest.Time, nil
+	}
+	if ttl.Initialized == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, ttl.Initialized)
+	if err != nil {
+		return time.Time{}, errors.Errorf("the stack's initialization time '%s' is not an RFC3339 time",
+			ttl.Initialized)
+	}
+	return t, nil
+}
+
+// parseTTL parses a time-to-live, which is either a Go duration like 72h, or a whole number of days like 7d.
+func parseTTL(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, errors.Errorf("time-to-live '%s' must be a positive duration, e.g. 72h or 7d", s)
+	}
+	return d, nil
+}
+
+// describeTTL returns a short description of when the given stack snapshot expires, and how many of its resources
+// have expired, according to the given time-to-live; or "" if the stack has no time-to-live.
+func describeTTL(ttl *workspace.StackTTL, snap *deploy.Snapshot, now time.Time) string {
+	if ttl == nil {
+		return ""
+	}
+	expiry, err := findExpired(ttl, snap, now)
+	if err != nil {
+		return "invalid ttl"
+	}
+
+	var parts []string
+	if expiry.Stack {
+		parts = append(parts, "expired "+humanize.RelTime(expiry.Since, now, "ago", "from now"))
+	} else if d, err := parseTTL(ttl.Stack); err == nil {
+		if last, err := lastUpdated(ttl, snap); err == nil && !last.IsZero() {
+			parts = append(parts, humanize.RelTime(last.Add(d), now, "ago", "from now"))
+		}
+	}
+	if n := len(expiry.Resources); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d resource(s) expired", n))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printExpired(stackName string, expiry ttlExpiry, now time.Time) {
+	if expiry.Stack {
+		fmt.Printf("Stack '%s' expired %s\n", stackName, humanize.RelTime(expiry.Since, now, "ago", "from now"))
+		return
+	}
+	fmt.Printf("Stack '%s' has %d expired resource(s):\n", stackName, len(expiry.Resources))
+	for _, res := range expiry.Resources {
+		fmt.Printf("    %s (expired %s, %v after its creation)\n",
+			res.URN, humanize.RelTime(res.Since, now, "ago", "from now"), res.TTL)
+	}
+}
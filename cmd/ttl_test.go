@@ -0,0 +1,114 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestParseTTL(t *testing.T) {
+	d, err := parseTTL("90m")
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, d)
+
+	d, err = parseTTL("7d")
+	assert.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, d)
+
+	for _, s := range []string{"", "-1h", "0d", "soon", "1.5d"} {
+		_, err = parseTTL(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestFindExpired(t *testing.T) {
+	now := time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC)
+	newState := func(typ tokens.Type, name string, age time.Duration) *resource.State {
+		urn := resource.NewURN("test", "test", "", typ, tokens.QName(name))
+		res := resource.NewState(typ, urn, true, false, "", resource.PropertyMap{}, nil, "", false, false, nil, nil, "")
+		if age != 0 {
+			res.Created = now.Add(-age)
+		}
+		return res
+	}
+
+	old := newState("pkgA:m:typA", "old", 48*time.Hour)
+	young := newState("pkgA:m:typA", "young", time.Hour)
+	unknown := newState("pkgA:m:typA", "unknown", 0)
+	other := newState("pkgB:m:typB", "other", 48*time.Hour)
+	snap := deploy.NewSnapshot(deploy.Manifest{Time: now.Add(-2 * time.Hour)},
+		[]*resource.State{old, young, unknown, other}, nil)
+
+	// Without a time-to-live, nothing expires.
+	expiry, err := findExpired(nil, snap, now)
+	assert.NoError(t, err)
+	assert.False(t, expiry.any())
+
+	// Resources expire some time after their creation, and the shortest matching time-to-live wins.
+	ttl := &workspace.StackTTL{
+		Stack:     "3h",
+		Resources: map[string]string{"pkgA:*": "7d", "*:typA": "1d"},
+	}
+	expiry, err = findExpired(ttl, snap, now)
+	assert.NoError(t, err)
+	assert.False(t, expiry.Stack)
+	assert.Equal(t, []expiredResource{{URN: old.URN, TTL: 24 * time.Hour, Since: now.Add(-24 * time.Hour)}},
+		expiry.Resources)
+	assert.Equal(t, "1 hour from now, 1 resource(s) expired", describeTTL(ttl, snap, now))
+
+	// The whole stack expires some time after its last update.
+	ttl.Stack = "2h"
+	expiry, err = findExpired(ttl, snap, now)
+	assert.NoError(t, err)
+	assert.True(t, expiry.Stack)
+	assert.Equal(t, now, expiry.Since)
+	assert.Empty(t, expiry.Resources)
+
+	ttl.Resources["*"] = "forever"
+	ttl.Stack = ""
+	_, err = findExpired(ttl, snap, now)
+	assert.Error(t, err)
+	assert.Equal(t, "invalid ttl", describeTTL(ttl, snap, now))
+}
+
+func TestFindExpiredBeforeFirstUpdate(t *testing.T) {
+	now := time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	// A stack that has never been updated expires some time after its initialization.
+	ttl := &workspace.StackTTL{Stack: "1d", Ephemeral: true, Initialized: "2018-08-31T06:00:00Z"}
+	expiry, err := findExpired(ttl, nil, now)
+	assert.NoError(t, err)
+	assert.True(t, expiry.Stack)
+	assert.Equal(t, now.Add(-6*time.Hour), expiry.Since)
+
+	// Once it has been updated, its time-to-live counts from then instead.
+	snap := deploy.NewSnapshot(deploy.Manifest{Time: now.Add(-time.Hour)}, nil, nil)
+	expiry, err = findExpired(ttl, snap, now)
+	assert.NoError(t, err)
+	assert.False(t, expiry.Stack)
+	assert.Equal(t, "23 hours from now", describeTTL(ttl, snap, now))
+
+	ttl.Initialized = "yesterday"
+	_, err = findExpired(ttl, nil, now)
+	assert.Error(t, err)
+}
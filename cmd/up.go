@@ -18,12 +18,14 @@ import (
 	"context"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/util/contract"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/resource/config"
@@ -40,8 +42,12 @@ const (
 // nolint: vetshadow, intentionally disabling here for cleaner err declaration/assignment.
 func newUpCmd() *cobra.Command {
 	var debug bool
+	var eventLog string
+	var debugSteps bool
 	var expectNop bool
 	var message string
+	var metadata []string
+	var overrideGuard string
 	var stack string
 	var configArray []string
 
@@ -50,10 +56,21 @@ func newUpCmd() *cobra.Command {
 	var diffDisplay bool
 	var nonInteractive bool
 	var parallel int
-	var refresh bool
+	var stepDecider string
+	var strictDiff bool
+	var showFullDiff bool
+	var refresh string
+	var refreshSince string
+	var refreshParallel int
+	var rateLimits []string
 	var showConfig bool
+	var noDedupe bool
+	var slowStepThreshold time.Duration
+	var showReads bool
 	var showReplacementSteps bool
 	var showSames bool
+	var showSecrets bool
+	var suppressSamesFlag bool
 	var skipPreview bool
 	var yes bool
 
@@ -85,15 +102,40 @@ func newUpCmd() *cobra.Command {
 		if err != nil {
 			return errors.Wrap(err, "gathering environment metadata")
 		}
+		if err = addUserMetadataToEnvironment(metadata, m.Environment); err != nil {
+			return err
+		}
 
 		opts.Engine = engine.UpdateOptions{
-			Analyzers: analyzers,
-			Parallel:  parallel,
-			Debug:     debug,
-			Refresh:   refresh,
+			Analyzers:       analyzers,
+			Parallel:        parallel,
+			Debug:           debug,
+			ShowSecrets:     showSecrets,
+			RefreshParallel: refreshParallel,
+			StrictDiff:      strictDiff,
+			ShowFullDiff:    showFullDiff,
+			InstallPlugins:  installMissingPlugins,
+			ProviderOptions: providerOptions,
+		}
+		setGuardOptions(m, overrideGuard, &opts.Engine)
+		setRandomSeed(&opts.Engine)
+		opts.Engine.StepDecider = newStepDecider(stepDecider)
+		if opts.Engine.RefreshRateLimits, err = parseRateLimits(rateLimits); err != nil {
+			return err
+		}
+		if err = setRefreshOptions(refresh, refreshSince, proj, &opts.Engine); err != nil {
+			return err
+		}
+		if debugSteps {
+			setStepDebugging(&opts)
 		}
 
+		tel := beginOperationTelemetry(apitype.UpdateUpdate, &opts.Engine)
 		changes, err := s.Update(commandContext(), proj, root, m, opts, cancellationScopes)
+		tel.report(changes, err)
+		if err == nil {
+			recordStackReferences(s)
+		}
 		switch {
 		case err == context.Canceled:
 			return errors.New("update cancelled")
@@ -225,12 +267,32 @@ func newUpCmd() *cobra.Command {
 		if err != nil {
 			return errors.Wrap(err, "gathering environment metadata")
 		}
+		if err = addUserMetadataToEnvironment(metadata, m.Environment); err != nil {
+			return err
+		}
 
 		opts.Engine = engine.UpdateOptions{
-			Analyzers: analyzers,
-			Parallel:  parallel,
-			Debug:     debug,
-			Refresh:   refresh,
+			Analyzers:       analyzers,
+			Parallel:        parallel,
+			Debug:           debug,
+			ShowSecrets:     showSecrets,
+			RefreshParallel: refreshParallel,
+			StrictDiff:      strictDiff,
+			ShowFullDiff:    showFullDiff,
+			InstallPlugins:  installMissingPlugins,
+			ProviderOptions: providerOptions,
+		}
+		setGuardOptions(m, overrideGuard, &opts.Engine)
+		setRandomSeed(&opts.Engine)
+		opts.Engine.StepDecider = newStepDecider(stepDecider)
+		if opts.Engine.RefreshRateLimits, err = parseRateLimits(rateLimits); err != nil {
+			return err
+		}
+		if err = setRefreshOptions(refresh, refreshSince, proj, &opts.Engine); err != nil {
+			return err
+		}
+		if debugSteps {
+			setStepDebugging(&opts)
 		}
 
 		// TODO for the URL case:
@@ -238,7 +300,9 @@ func newUpCmd() *cobra.Command {
 		// - attempt `destroy` on any update errors.
 		// - show template.Quickstart?
 
+		tel := beginOperationTelemetry(apitype.UpdateUpdate, &opts.Engine)
 		changes, err := s.Update(commandContext(), proj, root, m, opts, cancellationScopes)
+		tel.report(changes, err)
 		switch {
 		case err == context.Canceled:
 			return errors.New("update cancelled")
@@ -271,6 +335,9 @@ func newUpCmd() *cobra.Command {
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			interactive := isInteractive(nonInteractive)
 			if !interactive {
+				if debugSteps {
+					return errors.New("--debug-steps must be run in interactive mode")
+				}
 				yes = true // auto-approve changes, since we cannot prompt.
 			}
 
@@ -281,13 +348,23 @@ func newUpCmd() *cobra.Command {
 
 			opts.Display = backend.DisplayOptions{
 				Color:                cmdutil.GetGlobalColorization(),
-				ShowConfig:           showConfig,
-				ShowReplacementSteps: showReplacementSteps,
-				ShowSameResources:    showSames,
+				ShowConfig:           displayFlag(cmd, "show-config", showConfig),
+				ShowReads:            displayFlag(cmd, "show-reads", showReads),
+				ShowReplacementSteps: displayFlag(cmd, "show-replacement-steps", showReplacementSteps),
+				ShowSameResources:    displayFlag(cmd, "show-sames", showSames),
+				SuppressSames:        suppressSames(cmd, suppressSamesFlag),
+				NoDedupe:             noDedupe,
+				SlowStepThreshold:    slowStepThreshold,
 				IsInteractive:        interactive,
 				DiffDisplay:          diffDisplay,
 				Debug:                debug,
 			}
+			if eventLog != "" {
+				if opts.Display.EventLog, err = backend.NewEventLog(eventLog); err != nil {
+					return err
+				}
+				defer contract.IgnoreClose(opts.Display.EventLog)
+			}
 
 			if len(args) > 0 {
 				return upURL(args[0], opts)
@@ -300,6 +377,13 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVar(
+		&eventLog, "event-log", "",
+		"Log the engine's events as JSON to the given file, including each resource's properties before and after "+
+			"every step")
+	cmd.PersistentFlags().BoolVar(
+		&debugSteps, "debug-steps", false,
+		"Pause before each step of the update to show its changes, and choose whether to apply it, skip it, or abort")
 	cmd.PersistentFlags().BoolVar(
 		&expectNop, "expect-no-changes", false,
 		"Return an error if any changes occur during this update")
@@ -313,6 +397,12 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
 		"Optional message to associate with the update operation")
+	cmd.PersistentFlags().StringArrayVar(
+		&metadata, "metadata", []string{},
+		"Attach a key=value pair to the update, e.g. to link it to a ticket; may be given more than once")
+	cmd.PersistentFlags().StringVar(
+		&overrideGuard, "override-guard", "",
+		"Proceed even if the stack's guard forbids this operation, giving the reason for doing so")
 
 	// Flags for engine.UpdateOptions.
 	cmd.PersistentFlags().StringSliceVar(
@@ -323,21 +413,61 @@ func newUpCmd() *cobra.Command {
 		"Display operation as a rich diff showing the overall change")
 	cmd.PersistentFlags().BoolVar(
 		&nonInteractive, "non-interactive", false, "Disable interactive mode")
+	cmd.PersistentFlags().StringVar(
+		&stepDecider, "step-decider", "",
+		"Consult the given executable about the steps chosen, which may e.g. replace rather than update a resource "+
+			"or deny a deletion")
+	cmd.PersistentFlags().BoolVar(
+		&showFullDiff, "show-full-diff", false,
+		"Diff every input property, including those whose values providers fill in when programs do not give them")
+	cmd.PersistentFlags().BoolVar(
+		&strictDiff, "strict-diff", false,
+		"Have providers check and diff every resource, including those whose inputs have not changed since the "+
+			"last update")
 	cmd.PersistentFlags().IntVarP(
 		&parallel, "parallel", "p", defaultParallel,
 		"Allow P resource operations to run in parallel at once (<=1 for no parallelism)")
-	cmd.PersistentFlags().BoolVarP(
-		&refresh, "refresh", "r", false,
-		"Refresh the state of the stack's resources before this update")
+	cmd.PersistentFlags().StringVarP(
+		&refresh, "refresh", "r", refreshNone,
+		"Refresh the state of the stack's resources before this update; =changed-only reads only the resources "+
+			"whose types are listed in the project's driftProneTypes, or that their providers cannot report as "+
+			"unmodified since --refresh-since")
+	cmd.PersistentFlags().Lookup("refresh").NoOptDefVal = refreshAll
+	cmd.PersistentFlags().StringVar(
+		&refreshSince, "refresh-since", "",
+		"With --refresh=changed-only, an RFC3339 time or a duration ago (e.g. 24h) since which providers are asked "+
+			"whether resources were modified")
+	cmd.PersistentFlags().IntVar(
+		&refreshParallel, "refresh-parallel", 0,
+		"Allow P resources to be refreshed in parallel at once (defaults to --parallel)")
+	cmd.PersistentFlags().StringArrayVar(
+		&rateLimits, "rate-limit", nil,
+		"Limit how fast a provider's resources are refreshed, as <package>=<requests per second>"+
+			"[,retries=N][,backoff=D][,max-backoff=D]; may be given more than once")
+	cmd.PersistentFlags().BoolVar(
+		&noDedupe, "no-dedupe", false,
+		"Show every warning, rather than only the first of those that are repeated along with a count")
+	cmd.PersistentFlags().DurationVar(
+		&slowStepThreshold, "slow-step-threshold", backend.DefaultSlowStepThreshold,
+		"Warn about resource operations still running after this long (0 to disable)")
 	cmd.PersistentFlags().BoolVar(
 		&showConfig, "show-config", false,
 		"Show configuration keys and variables")
+	cmd.PersistentFlags().BoolVar(
+		&showReads, "show-reads", false,
+		"Show resources that are being read in, alongside those being managed directly in the stack")
 	cmd.PersistentFlags().BoolVar(
 		&showReplacementSteps, "show-replacement-steps", false,
 		"Show detailed resource replacement creates and deletes instead of a single step")
 	cmd.PersistentFlags().BoolVar(
 		&showSames, "show-sames", false,
 		"Show resources that don't need be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&showSecrets, "show-secrets", false,
+		"Show the values of secret configuration in resources' properties, rather than redacting them")
+	cmd.PersistentFlags().BoolVar(
+		&suppressSamesFlag, "suppress-sames", true,
+		"Show only a count of unchanged resources in progress output (the default unless -v is given)")
 	cmd.PersistentFlags().BoolVar(
 		&skipPreview, "skip-preview", false,
 		"Do not perform a preview before performing the update")
@@ -18,20 +18,24 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"runtime"
 	"sort"
+	"strings"
 
-	"github.com/golang/glog"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh/terminal"
 	survey "gopkg.in/AlecAivazis/survey.v1"
 	surveycore "gopkg.in/AlecAivazis/survey.v1/core"
 	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
 
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/cloud"
@@ -39,11 +43,16 @@ import (
 	"github.com/pulumi/pulumi/pkg/backend/state"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cancel"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/gitutil"
+	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/testutil"
+	"github.com/pulumi/pulumi/pkg/version"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
@@ -57,14 +66,40 @@ func currentBackend(opts backend.DisplayOptions) (backend.Backend, error) {
 		return nil, err
 	}
 	if local.IsLocalBackendURL(creds.Current) {
-		return local.New(cmdutil.Diag(), creds.Current), nil
+		b := local.New(cmdutil.Diag(), creds.Current)
+		openBackends = append(openBackends, b)
+		return b, nil
 	}
 	return cloud.Login(commandContext(), cmdutil.Diag(), creds.Current, opts)
 }
 
+// openBackends are the backends opened by currentBackend, which are closed once the command has run.
+var openBackends []backend.Backend
+
+// closeBackend closes the given backend if it holds resources, such as a state plugin, that must be released.
+func closeBackend(b backend.Backend) {
+	if closer, ok := b.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logging.Warningf("could not close the %s backend: %v", b.Name(), err)
+		}
+	}
+}
+
+// closeBackends closes all of the backends opened by currentBackend.
+func closeBackends() {
+	for _, b := range openBackends {
+		closeBackend(b)
+	}
+	openBackends = nil
+}
+
 // This is used to control the contents of the tracing header.
 var tracingHeader = os.Getenv("PULUMI_TRACING_HEADER")
 
+// providerOptions control how the resource providers of engine operations are run, as set by the --record, --replay,
+// and --chaos flags.
+var providerOptions plugin.ProviderOptions
+
 func commandContext() context.Context {
 	ctx := context.Background()
 	if cmdutil.IsTracingEnabled() {
@@ -278,6 +313,11 @@ func readProject() (*workspace.Project, string, error) {
 	return proj, filepath.Dir(path), nil
 }
 
+// stdoutIsTerminal returns true if standard out is a terminal.  Tests replace it in order to exercise `--color auto`.
+var stdoutIsTerminal = func() bool {
+	return terminal.IsTerminal(int(os.Stdout.Fd()))
+}
+
 type colorFlag struct {
 	value colors.Colorization
 }
@@ -294,11 +334,15 @@ func (cf *colorFlag) Set(value string) error {
 		cf.value = colors.Never
 	case "raw":
 		cf.value = colors.Raw
-	// Backwards compat for old flag values.
 	case "auto":
-		cf.value = colors.Always
+		// Colorize only when writing to a terminal.
+		if stdoutIsTerminal() {
+			cf.value = colors.Always
+		} else {
+			cf.value = colors.Never
+		}
 	default:
-		return errors.Errorf("unsupported color option: '%s'.  Supported values are: always, never, raw", value)
+		return errors.Errorf("unsupported color option: '%s'.  Supported values are: always, never, raw, auto", value)
 	}
 
 	return nil
@@ -308,16 +352,50 @@ func (cf *colorFlag) Type() string {
 	return "colors.Colorization"
 }
 
+// Colorization returns the colorization that was asked for explicitly, if any; otherwise, output is colorized unless
+// the NO_COLOR environment variable says not to.
 func (cf *colorFlag) Colorization() colors.Colorization {
-	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+	if cf.value != "" {
+		return cf.value
+	}
+	if cmdutil.NoColor() {
 		return colors.Never
 	}
+	return colors.Always
+}
+
+// initDisplay sets up the global colorization and display theme.  An explicit --color flag takes precedence over
+// the workspace's settings, which in turn take precedence over the NO_COLOR environment variable.  Outside of a
+// project there are no workspace settings, and so only the flag, NO_COLOR, and the --theme flag apply.
+func initDisplay(cmd *cobra.Command, color *colorFlag, theme string) error {
+	var settings *workspace.Settings
+	if w, err := workspace.New(); err == nil {
+		settings = w.Settings()
+	}
 
-	if cf.value == "" {
-		return colors.Always
+	if colorFlag := cmd.Flag("color"); (colorFlag == nil || !colorFlag.Changed) && settings != nil &&
+		settings.Color != "" {
+		if err := color.Set(settings.Color); err != nil {
+			return errors.Wrap(err, "invalid color in workspace settings")
+		}
 	}
+	cmdutil.SetGlobalColorization(color.Colorization())
 
-	return cf.value
+	var themes map[string]colors.Theme
+	if settings != nil {
+		themes = settings.Themes
+		if theme == "" {
+			theme = settings.Theme
+		}
+	}
+	if theme == "" {
+		theme = colors.DefaultTheme
+	}
+	resolved, err := colors.ResolveTheme(theme, themes)
+	if err != nil {
+		return err
+	}
+	return resolved.Apply()
 }
 
 // anyWriter is an io.Writer that will set itself to `true` iff any call to `anyWriter.Write` is made with a
@@ -363,13 +441,34 @@ func getUpdateMetadata(msg, root string) (backend.UpdateMetadata, error) {
 	}
 
 	if err := addGitMetadataToEnvironment(root, m.Environment); err != nil {
-		glog.V(3).Infof("errors detecting git metadata: %s", err)
+		logging.V(3).Infof("errors detecting git metadata: %s", err)
 	}
 	addCIMetadataToEnvironment(m.Environment)
+	addCLIMetadataToEnvironment(m.Environment)
 
 	return m, nil
 }
 
+// addCLIMetadataToEnvironment records the version of the CLI and the platform on which it runs in the environment
+// metadata bag, to help explain differences in the behavior of updates run by different versions or platforms.
+func addCLIMetadataToEnvironment(env map[string]string) {
+	env[backend.PulumiVersion] = version.Version
+	env[backend.PulumiOS] = runtime.GOOS
+	env[backend.PulumiArch] = runtime.GOARCH
+}
+
+// addUserMetadataToEnvironment adds the given `key=value` pairs to the environment metadata bag as user metadata.
+func addUserMetadataToEnvironment(pairs []string, env map[string]string) error {
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return errors.Errorf("invalid metadata '%s'; expected a key=value pair", pair)
+		}
+		env[backend.UserMetadataPrefix+strings.TrimSpace(kv[0])] = kv[1]
+	}
+	return nil
+}
+
 // addGitMetadataToEnvironment populate's the environment metadata bag with Git-related values.
 func addGitMetadataToEnvironment(repoRoot string, env map[string]string) error {
 	var allErrors *multierror.Error
@@ -424,6 +523,14 @@ func addGitCommitMetadataToEnvironment(repo *git.Repository, env map[string]stri
 	env[backend.GitAuthor] = commit.Author.Name
 	env[backend.GitAuthorEmail] = commit.Author.Email
 
+	tags, err := gitTagsAt(repo, hash)
+	if err != nil {
+		return errors.Wrap(err, "getting HEAD tags")
+	}
+	if len(tags) > 0 {
+		env[backend.GitTags] = strings.Join(tags, ",")
+	}
+
 	isDirty, err := isGitWorkTreeDirty()
 	if err != nil {
 		return errors.Wrapf(err, "checking git worktree dirty state")
@@ -433,6 +540,53 @@ func addGitCommitMetadataToEnvironment(repo *git.Repository, env map[string]stri
 	return nil
 }
 
+// gitTagsAt returns the sorted names of the tags that point at the given commit, either directly or by way of an
+// annotated tag.
+func gitTagsAt(repo *git.Repository, hash plumbing.Hash) ([]string, error) {
+	refs, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		target := ref.Hash()
+		if tag, tagErr := repo.TagObject(target); tagErr == nil {
+			target = tag.Target
+		}
+		if target == hash {
+			tags = append(tags, ref.Name().Short())
+		}
+		return nil
+	})
+	sort.Strings(tags)
+	return tags, err
+}
+
+// setGuardOptions prepares the engine to check the stack's guard against the update described by the given metadata.
+// If the guard is being overridden, the reason for doing so is recorded in the metadata.
+func setGuardOptions(m backend.UpdateMetadata, overrideReason string, opts *engine.UpdateOptions) {
+	if tags := m.Environment[backend.GitTags]; tags != "" {
+		opts.SourceTags = strings.Split(tags, ",")
+	}
+	if overrideReason != "" {
+		opts.GuardOverrideReason = overrideReason
+		m.Environment[backend.GuardOverrideReason] = overrideReason
+	}
+}
+
+// randomSeedEnvVar is the environment variable that, when set, holds the seed from which updates and previews derive
+// the random seeds of the resources that they create or replace, so that e.g. their auto-generated names are the same
+// each time.  This is primarily useful for tests whose expected results must not vary from run to run.
+const randomSeedEnvVar = "PULUMI_RANDOM_SEED"
+
+// setRandomSeed applies the seed in the randomSeedEnvVar environment variable, if any, to the given options.
+func setRandomSeed(opts *engine.UpdateOptions) {
+	if seed := os.Getenv(randomSeedEnvVar); seed != "" {
+		opts.RandomSeed = []byte(seed)
+	}
+}
+
 // addCIMetadataToEnvironment populate's the environment metadata bag with CI/CD-related values.
 func addCIMetadataToEnvironment(env map[string]string) {
 	// Check if running on Travis CI. See:
@@ -523,6 +677,31 @@ func isInteractive(nonInteractive bool) bool {
 	return !nonInteractive && terminal.IsTerminal(int(os.Stdout.Fd())) && !testutil.IsCI()
 }
 
+// suppressSames returns true if progress output should show only a count of unchanged resources.  Unless the
+// `--suppress-sames` flag is given explicitly, they are suppressed unless verbose logging is enabled.
+func suppressSames(cmd *cobra.Command, suppressSamesFlag bool) bool {
+	if cmd.Flags().Changed("suppress-sames") {
+		return suppressSamesFlag
+	}
+	return logging.Verbose == 0
+}
+
+// displayFlag returns the value of the given display flag, such as `show-sames`: the value that was passed, if the flag
+// was given explicitly, and otherwise the default in the workspace's settings, if there is one.
+func displayFlag(cmd *cobra.Command, name string, value bool) bool {
+	if cmd.Flags().Changed(name) {
+		return value
+	}
+	w, err := workspace.New()
+	if err != nil {
+		return value
+	}
+	if def, has := w.Settings().Display[name]; has {
+		return def
+	}
+	return value
+}
+
 // updateFlagsToOptions ensures that the given update flags represent a valid combination.  If so, an UpdateOptions
 // is returned with a nil-error; otherwise, the non-nil error contains information about why the combination is invalid.
 func updateFlagsToOptions(interactive, skipPreview, yes bool) (backend.UpdateOptions, error) {
@@ -536,3 +715,27 @@ func updateFlagsToOptions(interactive, skipPreview, yes bool) (backend.UpdateOpt
 		SkipPreview: skipPreview,
 	}, nil
 }
+
+// newStepDecider returns a step decider that consults the executable at the given path, or nil if the path is empty.
+func newStepDecider(path string) deploy.StepDecider {
+	if path == "" {
+		return nil
+	}
+	return deploy.NewScriptStepDecider(path)
+}
+
+// parseRateLimits parses the values of --rate-limit flags into the rate limits for each provider package.
+func parseRateLimits(specs []string) (map[tokens.Package]deploy.RateLimit, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	limits := make(map[tokens.Package]deploy.RateLimit)
+	for _, spec := range specs {
+		pkg, limit, err := deploy.ParseRateLimit(spec)
+		if err != nil {
+			return nil, err
+		}
+		limits[pkg] = limit
+	}
+	return limits, nil
+}
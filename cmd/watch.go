@@ -0,0 +1,402 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/operations"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+// watchLogInterval is how often `pulumi watch` fetches new log entries from the watched stack's resources.
+const watchLogInterval = time.Second
+
+func newWatchCmd() *cobra.Command {
+	var stackName string
+	var message string
+	var parallel int
+	var debounce time.Duration
+	var pollInterval time.Duration
+	var allowDeletes bool
+	var noLogs bool
+	var showSecrets bool
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Args:  cmdutil.NoArgs,
+		Short: "Continuously update the resources in a stack as its program changes",
+		Long: "Continuously update the resources in a stack as its program changes.\n" +
+			"\n" +
+			"This command updates the stack, and then watches the project's directory, updating the stack again\n" +
+			"each time that its files change.  Changes are debounced, so that saving several files at once leads\n" +
+			"to a single update.  Between updates, the logs of the stack's resources are streamed inline with the\n" +
+			"output of its program.  The command runs until it is interrupted.\n" +
+			"\n" +
+			"Updates are approved automatically, but only for the stack being watched, and only while they leave\n" +
+			"the stack's existing resources in place: each update is previewed first, and one that would delete\n" +
+			"or replace resources is not performed unless --allow-deletes is passed.  Such an update should\n" +
+			"instead be reviewed and performed with `pulumi up`.  Stack guards apply as they do to `pulumi up`.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if debounce < 0 || pollInterval <= 0 {
+				return errors.New("--debounce may not be negative and --poll-interval must be positive")
+			}
+
+			opts := backend.UpdateOptions{
+				Display: backend.DisplayOptions{
+					Color:         cmdutil.GetGlobalColorization(),
+					SuppressSames: true,
+				},
+			}
+
+			// The watched stack is fixed for the life of the command, even if another stack is selected meanwhile.
+			s, err := requireStack(stackName, true, opts.Display, true /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			proj, root, err := readProject()
+			if err != nil {
+				return err
+			}
+
+			deployed := false
+			deployStack := func() error {
+				m, err := getUpdateMetadata(message, root)
+				if err != nil {
+					return errors.Wrap(err, "gathering environment metadata")
+				}
+
+				opts.Engine = engine.UpdateOptions{
+					Parallel:        parallel,
+					ShowSecrets:     showSecrets,
+					InstallPlugins:  installMissingPlugins,
+					ProviderOptions: providerOptions,
+				}
+				setGuardOptions(m, "", &opts.Engine)
+				setRandomSeed(&opts.Engine)
+
+				changes, err := s.Preview(commandContext(), proj, root, m, opts, cancellationScopes)
+				if err != nil {
+					return err
+				}
+				if err = checkWatchApproval(changes, allowDeletes); err != nil {
+					return err
+				}
+				if !changes.HasChanges() && deployed {
+					return nil
+				}
+
+				// The program may change between the preview and the update, so the update itself is also kept from
+				// deleting resources unless that is allowed.
+				updateOpts := opts
+				updateOpts.AutoApprove, updateOpts.SkipPreview = true, true
+				if !allowDeletes {
+					updateOpts.Engine.StepDecider = noDeleteStepDecider{
+						format: "`pulumi watch` may not delete %s without --allow-deletes",
+					}
+				}
+				tel := beginOperationTelemetry(apitype.UpdateUpdate, &updateOpts.Engine)
+				changes, err = s.Update(commandContext(), proj, root, m, updateOpts, cancellationScopes)
+				tel.report(changes, err)
+				if err != nil {
+					return err
+				}
+				deployed = true
+				recordStackReferences(s)
+				return nil
+			}
+
+			w := &watcher{
+				root:     root,
+				debounce: debounce,
+				interval: pollInterval,
+				deploy:   deployStack,
+			}
+			if !noLogs {
+				w.logs = newWatchLogFollower(s, func() bool { return deployed })
+			}
+			return w.run(opts.Display.Color)
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "",
+		"The name of the stack to watch. Defaults to the current stack")
+	cmd.PersistentFlags().StringVarP(
+		&message, "message", "m", "",
+		"Optional message to associate with each update")
+	cmd.PersistentFlags().IntVarP(
+		&parallel, "parallel", "p", defaultParallel,
+		"Allow P resource operations to run in parallel at once (<=1 for no parallelism)")
+	cmd.PersistentFlags().DurationVar(
+		&debounce, "debounce", 500*time.Millisecond,
+		"How long the project's files must stay unchanged before an update begins")
+	cmd.PersistentFlags().DurationVar(
+		&pollInterval, "poll-interval", 250*time.Millisecond,
+		"How often to check the project's files for changes")
+	cmd.PersistentFlags().BoolVar(
+		&allowDeletes, "allow-deletes", false,
+		"Automatically perform updates that delete or replace resources")
+	cmd.PersistentFlags().BoolVar(
+		&noLogs, "no-logs", false,
+		"Do not stream the logs of the stack's resources between updates")
+	cmd.PersistentFlags().BoolVar(
+		&showSecrets, "show-secrets", false,
+		"Show the values of secret configuration in resources' properties, rather than redacting them")
+
+	return cmd
+}
+
+// watchDestructiveOps are the operations that `pulumi watch` only performs automatically when --allow-deletes is set.
+var watchDestructiveOps = []deploy.StepOp{
+	deploy.OpDelete, deploy.OpReplace, deploy.OpCreateReplacement, deploy.OpDeleteReplaced,
+}
+
+// watchApprovalError is returned by checkWatchApproval when an update may not be approved automatically.
+type watchApprovalError struct {
+	counts []string
+}
+
+func (e watchApprovalError) Error() string {
+	return fmt.Sprintf("the update would %s; review it and run `pulumi up` to perform it, "+
+		"or pass --allow-deletes to `pulumi watch`", strings.Join(e.counts, " and "))
+}
+
+// checkWatchApproval returns an error if the previewed changes may not be performed automatically, because they would
+// delete or replace resources and that is not allowed.
+func checkWatchApproval(changes engine.ResourceChanges, allowDeletes bool) error {
+	if allowDeletes {
+		return nil
+	}
+	if counts := destructiveChanges(changes); len(counts) > 0 {
+		return watchApprovalError{counts: counts}
+	}
+	return nil
+}
+
+// destructiveChanges describes the deletions and replacements among the given changes, e.g. "delete 2 resource(s)".
+func destructiveChanges(changes engine.ResourceChanges) []string {
+	var counts []string
+	if c := changes[deploy.OpDelete]; c > 0 {
+		counts = append(counts, fmt.Sprintf("delete %d resource(s)", c))
+	}
+	if c := changes[deploy.OpReplace] + changes[deploy.OpCreateReplacement]; c > 0 {
+		counts = append(counts, fmt.Sprintf("replace %d resource(s)", c))
+	}
+	return counts
+}
+
+// noDeleteStepDecider is the step decider of updates that are approved automatically only as long as they do not
+// delete resources, e.g. those that `pulumi watch` performs without --allow-deletes.  It denies every deletion, so that
+// a change to the program that was not previewed can not delete a resource.  Its format, given the URN of a resource,
+// explains why the resource may not be deleted.
+type noDeleteStepDecider struct {
+	format string
+}
+
+func (noDeleteStepDecider) DecideUpdate(old, new *resource.State, diff plugin.DiffResult) (bool, error) {
+	return false, nil
+}
+
+func (d noDeleteStepDecider) DecideDelete(old *resource.State) error {
+	return errors.Errorf(d.format, old.URN)
+}
+
+func (noDeleteStepDecider) OrderDeletes(deletes []deploy.Step) ([]deploy.Step, error) {
+	return deletes, nil
+}
+
+// newWatchLogFollower returns a function that prints the log entries of the stack's resources that it has not yet
+// printed, once the stack has been deployed.  If the logs can not be read, a warning is printed and they are no longer
+// followed.
+func newWatchLogFollower(s backend.Stack, deployed func() bool) func() {
+	start := time.Now()
+	shown := make(map[operations.LogEntry]bool)
+	var last time.Time
+	failed := false
+	return func() {
+		if failed || !deployed() || time.Since(last) < watchLogInterval {
+			return
+		}
+		last = time.Now()
+
+		logs, err := s.GetLogs(commandContext(), operations.LogQuery{StartTime: &start})
+		if err != nil {
+			cmdutil.Diag().Warningf(diag.RawMessage("" /*urn*/, fmt.Sprintf("no longer following logs: %v", err)))
+			failed = true
+			return
+		}
+		for _, logEntry := range logs {
+			if !shown[logEntry] {
+				printLogEntry(logEntry)
+				shown[logEntry] = true
+			}
+		}
+	}
+}
+
+// watcher deploys a program each time that the files of its project change.
+type watcher struct {
+	root     string        // the root directory of the project, beneath which files are watched.
+	debounce time.Duration // how long the files must stay unchanged before a change is deployed.
+	interval time.Duration // how often the files are checked for changes.
+	deploy   func() error  // deploys the program.
+	logs     func()        // if non-nil, prints new log entries; called each time that the files are checked.
+}
+
+// run deploys the program, and then deploys it again each time that the files of its project change, until the
+// deployment is canceled or the files can not be read.  Other failures to deploy are reported, and the next change is
+// awaited.
+func (w *watcher) run(color colors.Colorization) error {
+	files, err := scanWatchedFiles(w.root)
+	if err != nil {
+		return err
+	}
+
+	for {
+		err = w.deploy()
+		switch {
+		case err == context.Canceled:
+			return errors.New("update cancelled")
+		case err != nil:
+			if _, ok := err.(watchApprovalError); !ok {
+				err = PrintEngineError(err)
+			}
+			if err != nil {
+				cmdutil.Diag().Errorf(diag.RawMessage("" /*urn*/, err.Error()))
+			}
+		}
+		fmt.Println(color.Colorize(
+			colors.BrightMagenta + fmt.Sprintf("Watching %s for changes; press ^C to stop.", w.root) + colors.Reset))
+
+		var changed []string
+		if files, changed, err = w.wait(files); err != nil {
+			return err
+		}
+		fmt.Println(color.Colorize(colors.BrightMagenta + "Changed: " + strings.Join(changed, ", ") + colors.Reset))
+	}
+}
+
+// wait waits for the watched files to change and then stay unchanged for the debounce period, and returns their new
+// state along with the paths of those that changed.
+func (w *watcher) wait(files map[string]watchedFile) (map[string]watchedFile, []string, error) {
+	changed := make(map[string]bool)
+	var lastChange time.Time
+	for {
+		time.Sleep(w.interval)
+		if w.logs != nil {
+			w.logs()
+		}
+
+		next, err := scanWatchedFiles(w.root)
+		if err != nil {
+			return nil, nil, err
+		}
+		if paths := diffWatchedFiles(files, next); len(paths) > 0 {
+			for _, path := range paths {
+				changed[path] = true
+			}
+			files, lastChange = next, time.Now()
+			continue
+		}
+
+		if len(changed) > 0 && time.Since(lastChange) >= w.debounce {
+			var paths []string
+			for path := range changed {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			return files, paths, nil
+		}
+	}
+}
+
+// watchedFile is the state of a watched file that is compared to detect changes to it.
+type watchedFile struct {
+	size    int64
+	modTime time.Time
+}
+
+// isIgnoredWatchDir returns true if changes to the files beneath the directory with the given name are ignored: hidden
+// directories, such as .git, and those that hold installed dependencies or build outputs.
+func isIgnoredWatchDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "node_modules" || name == "__pycache__"
+}
+
+// scanWatchedFiles returns the state of each file beneath the given root directory, keyed by its path relative to the
+// root, ignoring directories for which isIgnoredWatchDir is true.
+func scanWatchedFiles(root string) (map[string]watchedFile, error) {
+	files := make(map[string]watchedFile)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// A file that was removed while the directory was being walked is simply missing from the result.
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			if path != root && isIgnoredWatchDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = watchedFile{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "watching %s", root)
+	}
+	return files, nil
+}
+
+// diffWatchedFiles returns the sorted paths of the files that were added, removed, or modified between two scans.
+func diffWatchedFiles(before, after map[string]watchedFile) []string {
+	var paths []string
+	for path, f := range after {
+		if old, has := before[path]; !has || old != f {
+			paths = append(paths, path)
+		}
+	}
+	for path := range before {
+		if _, has := after[path]; !has {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
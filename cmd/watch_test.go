@@ -0,0 +1,90 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestWatchedFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "pulumi-watch")
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(root)) }()
+
+	write := func(path, contents string) {
+		path = filepath.Join(root, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	}
+	write("index.js", "1")
+	write(filepath.Join("lib", "util.js"), "1")
+	write(filepath.Join("node_modules", "dep", "index.js"), "1")
+	write(filepath.Join(".git", "HEAD"), "1")
+
+	before, err := scanWatchedFiles(root)
+	assert.NoError(t, err)
+	assert.Len(t, before, 2)
+
+	// Changes beneath ignored directories are not noticed.
+	write(filepath.Join("node_modules", "dep", "index.js"), "22")
+	write(filepath.Join("lib", "util.js"), "22")
+	write("Pulumi.dev.yaml", "config: {}")
+	assert.NoError(t, os.Remove(filepath.Join(root, "index.js")))
+
+	after, err := scanWatchedFiles(root)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Pulumi.dev.yaml", "index.js", filepath.Join("lib", "util.js")},
+		diffWatchedFiles(before, after))
+	assert.Empty(t, diffWatchedFiles(after, after))
+
+	// A burst of changes is reported once they have settled.
+	w := &watcher{root: root, debounce: 50 * time.Millisecond, interval: 5 * time.Millisecond}
+	go func() {
+		write("a.js", "1")
+		time.Sleep(20 * time.Millisecond)
+		write("b.js", "1")
+	}()
+	_, changed, err := w.wait(after)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.js", "b.js"}, changed)
+}
+
+func TestWatchApproval(t *testing.T) {
+	assert.NoError(t, checkWatchApproval(engine.ResourceChanges{deploy.OpCreate: 1, deploy.OpUpdate: 2}, false))
+
+	destructive := engine.ResourceChanges{deploy.OpDelete: 1, deploy.OpReplace: 2, deploy.OpSame: 3}
+	err := checkWatchApproval(destructive, false)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "delete 1 resource(s) and replace 2 resource(s)")
+	}
+	assert.NoError(t, checkWatchApproval(destructive, true))
+
+	// Deletions that were not previewed are denied.
+	urn := resource.NewURN("dev", "proj", "", "pkgA:m:typA", "resA")
+	res := resource.NewState("pkgA:m:typA", urn, true, false, "id", resource.PropertyMap{}, resource.PropertyMap{}, "",
+		false, false, nil, nil, "")
+	err = noDeleteStepDecider{format: "may not delete %s"}.DecideDelete(res)
+	assert.EqualError(t, err, "may not delete "+string(res.URN))
+}
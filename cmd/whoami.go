@@ -15,21 +15,34 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/cloud"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 )
 
 func newWhoAmICmd() *cobra.Command {
+	var jsonOut bool
+	var verbose bool
+
 	cmd := &cobra.Command{
 		Use:   "whoami",
 		Short: "Display current logged in user",
 		Long: "Display current logged in user\n" +
 			"\n" +
-			"Displays the username of the currently logged in user.",
+			"Displays the username of the currently logged in user.  With --verbose, also displays the URL of\n" +
+			"the backend, the organizations the user belongs to, and the scopes and expiry of their access\n" +
+			"token, where the backend reports them.",
 		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := backend.DisplayOptions{
@@ -41,15 +54,111 @@ func newWhoAmICmd() *cobra.Command {
 				return err
 			}
 
-			name, err := b.CurrentUser()
-			if err != nil {
-				return err
-			}
-
-			fmt.Println(name)
-			return nil
+			return runWhoAmI(os.Stdout, b, verbose, jsonOut)
 		}),
 	}
 
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON; implies --verbose")
+	cmd.PersistentFlags().BoolVar(
+		&verbose, "verbose", false,
+		"Also display the backend URL, organizations, and access token details")
+
 	return cmd
 }
+
+// runWhoAmI writes the current user of the given backend to w, along with the details of the user and their access
+// token if verbose or jsonOut is set.  Only the cloud backend has these details to report.
+func runWhoAmI(w io.Writer, b backend.Backend, verbose, jsonOut bool) error {
+	if !verbose && !jsonOut {
+		name, err := b.CurrentUser()
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, name)
+		return err
+	}
+
+	cb, isCloud := b.(cloud.Backend)
+	if !isCloud {
+		// Report the backend's own reason for having no user, if it has one.
+		if _, err := b.CurrentUser(); err != nil {
+			return err
+		}
+		return errors.New("this backend does not report user details; run `pulumi whoami` without --verbose or --json")
+	}
+	user, token, err := cb.CurrentUserInfo(commandContext())
+	if err != nil {
+		return errors.Wrap(err, "getting user information")
+	}
+
+	info := newWhoAmIInfo(cb.CloudURL(), user, token)
+	if jsonOut {
+		return printWhoAmIJSON(w, info)
+	}
+	printWhoAmI(w, info)
+	return nil
+}
+
+// whoAmIInfo is the information displayed by `pulumi whoami --verbose`, in the form of its JSON output.
+type whoAmIInfo struct {
+	User           string                        `json:"user"`
+	URL            string                        `json:"url"`
+	Organizations  []apitype.OrganizationSummary `json:"organizations"`
+	TokenScopes    []string                      `json:"tokenScopes,omitempty"`
+	TokenExpiresAt *time.Time                    `json:"tokenExpiresAt,omitempty"`
+}
+
+func newWhoAmIInfo(url string, user *apitype.GetUserResponse, token *apitype.GetTokenInfoResponse) whoAmIInfo {
+	info := whoAmIInfo{
+		User:          user.GitHubLogin,
+		URL:           url,
+		Organizations: user.Organizations,
+	}
+	if info.Organizations == nil {
+		info.Organizations = []apitype.OrganizationSummary{}
+	}
+	if token != nil {
+		info.TokenScopes = token.Scopes
+		if token.ExpiresAt != 0 {
+			expiresAt := time.Unix(token.ExpiresAt, 0).UTC()
+			info.TokenExpiresAt = &expiresAt
+		}
+	}
+	return info
+}
+
+func printWhoAmIJSON(w io.Writer, info whoAmIInfo) error {
+	b, err := json.MarshalIndent(info, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func printWhoAmI(w io.Writer, info whoAmIInfo) {
+	fmt.Fprintf(w, "User: %s\n", info.User)
+	fmt.Fprintf(w, "Backend URL: %s\n", info.URL)
+
+	var orgs []string
+	for _, org := range info.Organizations {
+		if org.Role != "" {
+			orgs = append(orgs, fmt.Sprintf("%s (%s)", org.GitHubLogin, org.Role))
+		} else {
+			orgs = append(orgs, org.GitHubLogin)
+		}
+	}
+	if len(orgs) == 0 {
+		orgs = []string{"none"}
+	}
+	fmt.Fprintf(w, "Organizations: %s\n", strings.Join(orgs, ", "))
+
+	if len(info.TokenScopes) > 0 {
+		fmt.Fprintf(w, "Token scopes: %s\n", strings.Join(info.TokenScopes, ", "))
+	}
+	if info.TokenExpiresAt != nil {
+		fmt.Fprintf(w, "Token expires: %s\n", info.TokenExpiresAt.Format(time.RFC3339))
+	}
+}
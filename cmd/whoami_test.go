@@ -0,0 +1,101 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/cloud"
+)
+
+// whoAmITestBackend is a cloud backend that implements only what `pulumi whoami` needs.
+type whoAmITestBackend struct {
+	cloud.Backend
+}
+
+func (b *whoAmITestBackend) CurrentUser() (string, error) { return "alice", nil }
+func (b *whoAmITestBackend) CloudURL() string             { return "https://api.example.com" }
+
+func (b *whoAmITestBackend) CurrentUserInfo(ctx context.Context) (*apitype.GetUserResponse,
+	*apitype.GetTokenInfoResponse, error) {
+
+	return &apitype.GetUserResponse{
+		GitHubLogin:   "alice",
+		Organizations: []apitype.OrganizationSummary{{GitHubLogin: "acme", Role: "admin"}, {GitHubLogin: "oss"}},
+	}, &apitype.GetTokenInfoResponse{
+		Scopes:    []string{"stacks:read"},
+		ExpiresAt: 1500000000,
+	}, nil
+}
+
+// whoAmILocalBackend is a backend that, like the local backend, has no users.
+type whoAmILocalBackend struct {
+	backend.Backend
+}
+
+func (b *whoAmILocalBackend) CurrentUser() (string, error) {
+	return "", errors.New("the local backend does not support multiple users")
+}
+
+func TestWhoAmI(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, runWhoAmI(&out, &whoAmITestBackend{}, false, false))
+	assert.Equal(t, "alice\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runWhoAmI(&out, &whoAmITestBackend{}, true, false))
+	assert.Equal(t, "User: alice\n"+
+		"Backend URL: https://api.example.com\n"+
+		"Organizations: acme (admin), oss\n"+
+		"Token scopes: stacks:read\n"+
+		"Token expires: 2017-07-14T02:40:00Z\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runWhoAmI(&out, &whoAmITestBackend{}, false, true))
+	var info whoAmIInfo
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &info))
+	assert.Equal(t, "alice", info.User)
+	assert.Equal(t, "https://api.example.com", info.URL)
+	assert.Len(t, info.Organizations, 2)
+	assert.Equal(t, []string{"stacks:read"}, info.TokenScopes)
+}
+
+func TestWhoAmIWithoutUsers(t *testing.T) {
+	var out bytes.Buffer
+	err := runWhoAmI(&out, &whoAmILocalBackend{}, false, false)
+	assert.EqualError(t, err, "the local backend does not support multiple users")
+
+	// Asking for details reports the backend's error rather than failing an assertion.
+	err = runWhoAmI(&out, &whoAmILocalBackend{}, true, false)
+	assert.EqualError(t, err, "the local backend does not support multiple users")
+	assert.Empty(t, out.String())
+}
+
+func TestWhoAmIVerboseFlag(t *testing.T) {
+	// The command's --verbose flag must not take the -v shorthand of the global --verbose flag.
+	cmd := newWhoAmICmd()
+	flag := cmd.PersistentFlags().Lookup("verbose")
+	if assert.NotNil(t, flag) {
+		assert.Empty(t, flag.Shorthand)
+	}
+}
@@ -196,6 +196,32 @@ type ResourceV2 struct {
 	InitErrors []string `json:"initErrors" yaml:"initErrors,omitempty"`
 	// Provider is a reference to the provider that is associated with this resource.
 	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
+	// SourcePosition is an optional `file:line:column` position of the code that declared this resource.
+	SourcePosition string `json:"sourcePosition,omitempty" yaml:"sourcePosition,omitempty"`
+	// Locked is set to true when this resource may not be updated, replaced, or deleted until it is unlocked.
+	Locked bool `json:"locked,omitempty" yaml:"locked,omitempty"`
+	// DependsOn contains the subset of Dependencies that were explicitly requested with the `dependsOn` option.
+	DependsOn []resource.URN `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	// Unreferenced is set to true when the program did not register this resource during the last update.
+	Unreferenced bool `json:"unreferenced,omitempty" yaml:"unreferenced,omitempty"`
+	// Created is the time at which the resource was created, if known.
+	Created *time.Time `json:"created,omitempty" yaml:"created,omitempty"`
+	// Modified is the time at which the resource was last created or updated, if known.
+	Modified *time.Time `json:"modified,omitempty" yaml:"modified,omitempty"`
+	// UpdateVersion is the version of the stack produced by the update that last created or updated the resource.
+	UpdateVersion int `json:"updateVersion,omitempty" yaml:"updateVersion,omitempty"`
+	// AdditionalSecretOutputs names the outputs that are secret because the `additionalSecretOutputs` option says so.
+	AdditionalSecretOutputs []string `json:"additionalSecretOutputs,omitempty" yaml:"additionalSecretOutputs,omitempty"`
+	// ReplaceOnChanges lists the input property paths whose changes force the resource to be replaced.
+	ReplaceOnChanges []string `json:"replaceOnChanges,omitempty" yaml:"replaceOnChanges,omitempty"`
+	// DeleteBeforeReplace is set to true when replacements of this resource must delete the old one first.
+	DeleteBeforeReplace bool `json:"deleteBeforeReplace,omitempty" yaml:"deleteBeforeReplace,omitempty"`
+	// RandomSeed is the seed from which the resource's provider derives any randomness, such as auto-generated names.
+	RandomSeed []byte `json:"randomSeed,omitempty" yaml:"randomSeed,omitempty"`
+	// InputsHash is a hash of the inputs that the program last gave the resource, before they were checked.
+	InputsHash string `json:"inputsHash,omitempty" yaml:"inputsHash,omitempty"`
+	// ProviderDefaulted names the inputs that the provider filled in because the program last did not give them.
+	ProviderDefaulted []string `json:"providerDefaulted,omitempty" yaml:"providerDefaulted,omitempty"`
 }
 
 // ManifestV1 captures meta-information about this checkpoint file, such as versions of binaries, etc.
@@ -206,6 +232,10 @@ type ManifestV1 struct {
 	Magic string `json:"magic" yaml:"magic"`
 	// Version of the Pulumi engine used to render the checkpoint.
 	Version string `json:"version" yaml:"version"`
+	// OS is the operating system on which the Pulumi engine ran, e.g. `linux`.
+	OS string `json:"os,omitempty" yaml:"os,omitempty"`
+	// Arch is the architecture on which the Pulumi engine ran, e.g. `amd64`.
+	Arch string `json:"arch,omitempty" yaml:"arch,omitempty"`
 	// Plugins contains the binary version info of plug-ins used.
 	Plugins []PluginInfoV1 `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
@@ -0,0 +1,153 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitype
+
+import (
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// EngineEvent is a serialized engine event, as written to an event log.  Exactly one of the event-specific fields is
+// set, according to the event's Type.  Events are meant to be consumed by tools outside of the CLI, such as diff
+// visualizers and audit pipelines, so that they needn't re-run previews to learn what an update did.
+type EngineEvent struct {
+	// Sequence is the position of the event within its event log, starting at zero.
+	Sequence int `json:"sequence"`
+	// Timestamp is the Unix time, in seconds, at which the event was recorded.
+	Timestamp int64 `json:"timestamp"`
+	// Type is the kind of event, e.g. `resource-pre` or `diag`.
+	Type string `json:"type"`
+
+	Cancel                  *CancelEvent                  `json:"cancelEvent,omitempty"`
+	Stdout                  *StdoutEngineEvent            `json:"stdoutEvent,omitempty"`
+	Diagnostic              *DiagnosticEvent              `json:"diagnosticEvent,omitempty"`
+	Prelude                 *PreludeEvent                 `json:"preludeEvent,omitempty"`
+	Summary                 *SummaryEvent                 `json:"summaryEvent,omitempty"`
+	ResourcePre             *ResourcePreEvent             `json:"resourcePreEvent,omitempty"`
+	ResourceOutputs         *ResourceOutputsEvent         `json:"resOutputsEvent,omitempty"`
+	ResourceOperationFailed *ResourceOperationFailedEvent `json:"resOpFailedEvent,omitempty"`
+}
+
+// CancelEvent is emitted when an update has finished, and no further events will follow.
+type CancelEvent struct{}
+
+// StdoutEngineEvent is emitted whenever a generic message is written, e.g. warnings from the CLI itself.
+type StdoutEngineEvent struct {
+	Message string `json:"message"`
+	Color   string `json:"color"`
+}
+
+// DiagnosticEvent is emitted for each diagnostic message reported by the engine, a provider or the program.
+type DiagnosticEvent struct {
+	URN       resource.URN `json:"urn,omitempty"`
+	Code      string       `json:"code,omitempty"` // the code of the diagnostic's class, if any; see `pulumi explain`.
+	Prefix    string       `json:"prefix,omitempty"`
+	Message   string       `json:"message"`
+	Color     string       `json:"color"`
+	Severity  string       `json:"severity"`
+	StreamID  int32        `json:"streamID,omitempty"`
+	Ephemeral bool         `json:"ephemeral,omitempty"`
+}
+
+// PreludeEvent is emitted at the start of an update, and describes the configuration it uses.  The values of secret
+// configuration are always blinded.
+type PreludeEvent struct {
+	IsPreview bool              `json:"isPreview"`
+	Config    map[string]string `json:"config"`
+}
+
+// SummaryEvent is emitted at the end of an update, and summarizes the changes it made (or, for a preview, would make).
+type SummaryEvent struct {
+	IsPreview bool `json:"isPreview"`
+	// MaybeCorrupt is true if one or more resources may be corrupt.
+	MaybeCorrupt bool `json:"maybeCorrupt"`
+	// DurationSeconds is the number of seconds the update took (zero for previews).
+	DurationSeconds int `json:"durationSeconds"`
+	// ResourceChanges counts the resources for each operation, e.g. `create` or `same`.
+	ResourceChanges map[string]int `json:"resourceChanges"`
+	// RefreshChanges counts the resources that a refresh which preceded the update found had changed.
+	RefreshChanges map[string]int `json:"refreshChanges,omitempty"`
+	// OutputChanges are the names of the stack outputs that changed (or, for previews, may change).
+	OutputChanges []string `json:"outputChanges,omitempty"`
+}
+
+// ResourcePreEvent is emitted before a resource step is performed.
+type ResourcePreEvent struct {
+	Metadata StepEventMetadata `json:"metadata"`
+	Planning bool              `json:"planning,omitempty"`
+}
+
+// ResourceOutputsEvent is emitted once a resource step has been performed and its outputs are known.
+type ResourceOutputsEvent struct {
+	Metadata StepEventMetadata `json:"metadata"`
+	Planning bool              `json:"planning,omitempty"`
+}
+
+// ResourceOperationFailedEvent is emitted when a resource step fails.
+type ResourceOperationFailedEvent struct {
+	Metadata StepEventMetadata `json:"metadata"`
+	// Status describes what is known of the resource after the failure: 0 if it is unchanged, 1 if it may be
+	// partially updated, and 2 if its state is unknown.
+	Status int `json:"status"`
+	// Steps is the number of steps that had been performed when the failure occurred.
+	Steps int `json:"steps"`
+}
+
+// StepEventMetadata describes a step that the engine performs on a resource, along with the resource's state before
+// and after the step.
+type StepEventMetadata struct {
+	// Op is the operation performed by the step, e.g. `create` or `update`.
+	Op string `json:"op"`
+	// URN is the URN of the resource the step affects.
+	URN resource.URN `json:"urn"`
+	// Type is the type of the resource the step affects.
+	Type tokens.Type `json:"type"`
+	// Old is the state of the resource before the step, if any.
+	Old *StepEventStateMetadata `json:"old,omitempty"`
+	// New is the state of the resource after the step, if any.
+	New *StepEventStateMetadata `json:"new,omitempty"`
+	// Keys are the properties that cause the resource to be replaced, if the step is part of a replacement.
+	Keys []string `json:"keys,omitempty"`
+	// Reasons are the reasons, if any, that each of Keys causes a replacement.
+	Reasons map[string]string `json:"reasons,omitempty"`
+	// Logical is true if the step represents a logical operation in the program.
+	Logical bool `json:"logical,omitempty"`
+	// Provider is the reference to the provider that performs the step.
+	Provider string `json:"provider,omitempty"`
+	// DeleteBeforeReplace is true if the step replaces a resource by deleting it before creating its replacement.
+	DeleteBeforeReplace bool `json:"deleteBeforeReplace,omitempty"`
+	// Dependents are the resources that are replaced because they depend upon a resource that is deleted before it is
+	// replaced, in the order in which they are deleted.
+	Dependents []resource.URN `json:"dependents,omitempty"`
+}
+
+// StepEventStateMetadata is the state of a resource before or after a step.  The values of secrets in its properties
+// are redacted unless the update was asked to show them, and the contents of assets are stripped.
+type StepEventStateMetadata struct {
+	Type     tokens.Type  `json:"type"`
+	URN      resource.URN `json:"urn"`
+	Custom   bool         `json:"custom,omitempty"`
+	Delete   bool         `json:"delete,omitempty"`
+	ID       resource.ID  `json:"id,omitempty"`
+	Parent   resource.URN `json:"parent,omitempty"`
+	Protect  bool         `json:"protect,omitempty"`
+	Provider string       `json:"provider,omitempty"`
+	// Inputs are the resource's input properties, as specified by the program.
+	Inputs map[string]interface{} `json:"inputs"`
+	// Outputs are the resource's output properties, as returned by its provider.
+	Outputs map[string]interface{} `json:"outputs"`
+	// InitErrors are the errors, if any, that were encountered while initializing the resource.
+	InitErrors []string `json:"initErrors,omitempty"`
+}
@@ -93,10 +93,25 @@ type UpdateInfo struct {
 	Version         int             `json:"version"`
 	Deployment      json.RawMessage `json:"deployment,omitempty"`
 	ResourceChanges map[OpType]int  `json:"resourceChanges,omitempty"`
+	OutputChanges   []string        `json:"outputChanges,omitempty"`
+	StepTimings     []StepTiming    `json:"stepTimings,omitempty"`
+}
+
+// StepTiming records how long a single resource operation took to apply during an update.
+//
+// Should generally mirror engine.StepTiming, but we clone it in this package to add
+// flexibility in case there is a breaking change in the backend-type.
+type StepTiming struct {
+	URN      string `json:"urn"`              // the resource that the operation applied to.
+	Op       OpType `json:"op"`               // the kind of operation.
+	Duration int64  `json:"duration"`         // how long the operation took, in nanoseconds.
+	Failed   bool   `json:"failed,omitempty"` // true if the operation failed.
 }
 
 // GetHistoryResponse is the response from the Pulumi Service when requesting
 // a stack's history.
 type GetHistoryResponse struct {
 	Updates []UpdateInfo `json:"updates"`
+	// ContinuationToken, if set, may be passed back to fetch the next page of updates.
+	ContinuationToken string `json:"continuationToken,omitempty"`
 }
@@ -0,0 +1,70 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitype
+
+import (
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// InventoryVersionCurrent is the current version of the Inventory schema.
+const InventoryVersionCurrent = 1
+
+// InventoryV1 is a flat, tool-neutral list of the cloud resources that a stack manages.  It is meant to be consumed by
+// inventory and configuration management systems that do not understand Pulumi's deployments.
+type InventoryV1 struct {
+	// Version is the version of the inventory schema.
+	Version int `json:"version" yaml:"version"`
+	// Project is the name of the project that the stack belongs to.
+	Project tokens.PackageName `json:"project" yaml:"project"`
+	// Stack is the name of the stack.
+	Stack tokens.QName `json:"stack" yaml:"stack"`
+	// Time is the time at which the stack's resources were last updated.
+	Time time.Time `json:"time" yaml:"time"`
+	// Resources are the custom resources that the stack manages.
+	Resources []InventoryResourceV1 `json:"resources" yaml:"resources"`
+}
+
+// InventoryResourceV1 describes a single resource in an inventory.
+type InventoryResourceV1 struct {
+	// URN uniquely identifies the resource.
+	URN resource.URN `json:"urn" yaml:"urn"`
+	// Type is the resource's full type token.
+	Type tokens.Type `json:"type" yaml:"type"`
+	// Name is the resource's name within its stack.
+	Name tokens.QName `json:"name" yaml:"name"`
+	// Package is the package of the provider that manages the resource, e.g. `aws`.
+	Package tokens.Package `json:"package" yaml:"package"`
+	// Region is the region in which the resource lives, if it is known.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// Created is the time at which the resource was created, if it is known.
+	Created *time.Time `json:"created,omitempty" yaml:"created,omitempty"`
+	// Modified is the time at which the resource was last created or updated, if it is known.
+	Modified *time.Time `json:"modified,omitempty" yaml:"modified,omitempty"`
+	// ID is the provider-assigned ID of the resource.
+	ID resource.ID `json:"id" yaml:"id"`
+	// Parent is the URN of the resource's parent, if any.
+	Parent resource.URN `json:"parent,omitempty" yaml:"parent,omitempty"`
+	// Dependencies are the URNs of the resources that this resource depends on.
+	Dependencies []resource.URN `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	// Protect is true if the resource may not be deleted.
+	Protect bool `json:"protect,omitempty" yaml:"protect,omitempty"`
+	// External is true if the resource's lifecycle is not managed by the stack.
+	External bool `json:"external,omitempty" yaml:"external,omitempty"`
+	// Properties are the resource's current properties, as reported by its provider.
+	Properties map[string]interface{} `json:"properties,omitempty" yaml:"properties,omitempty"`
+}
@@ -14,7 +14,11 @@
 
 package migrate
 
-import "github.com/pulumi/pulumi/pkg/apitype"
+import (
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
 
 // UpToDeploymentV2 migrates a deployment from DeploymentV1 to DeploymentV2.
 func UpToDeploymentV2(v1 apitype.DeploymentV1) apitype.DeploymentV2 {
@@ -27,3 +31,24 @@ func UpToDeploymentV2(v1 apitype.DeploymentV1) apitype.DeploymentV2 {
 
 	return v2
 }
+
+// DownToDeploymentV1 migrates a deployment from DeploymentV2 to DeploymentV1.  A deployment with pending operations
+// fails to migrate, since DeploymentV1 has no way to record them.
+func DownToDeploymentV1(v2 apitype.DeploymentV2) (apitype.DeploymentV1, error) {
+	var v1 apitype.DeploymentV1
+	if len(v2.PendingOperations) > 0 {
+		return v1, errors.Errorf("the deployment has %d pending operations, which version 1 cannot represent",
+			len(v2.PendingOperations))
+	}
+
+	v1.Manifest = v2.Manifest
+	for _, res := range v2.Resources {
+		r, err := DownToResourceV1(res)
+		if err != nil {
+			return v1, err
+		}
+		v1.Resources = append(v1.Resources, r)
+	}
+
+	return v1, nil
+}
@@ -41,3 +41,29 @@ func TestDeploymentV1ToV2(t *testing.T) {
 	assert.Equal(t, resource.URN("a"), v1.Resources[0].URN)
 	assert.Equal(t, resource.URN("b"), v1.Resources[1].URN)
 }
+
+func TestDeploymentV2ToV1(t *testing.T) {
+	v2 := apitype.DeploymentV2{
+		Manifest: apitype.ManifestV1{Magic: "magic"},
+		Resources: []apitype.ResourceV2{
+			{
+				URN: resource.URN("a"),
+			},
+			{
+				URN: resource.URN("b"),
+			},
+		},
+	}
+
+	v1, err := DownToDeploymentV1(v2)
+	assert.NoError(t, err)
+	assert.Equal(t, v2.Manifest, v1.Manifest)
+	assert.Len(t, v1.Resources, 2)
+	assert.Equal(t, resource.URN("a"), v1.Resources[0].URN)
+	assert.Equal(t, resource.URN("b"), v1.Resources[1].URN)
+
+	// Pending operations cannot be recorded in version 1.
+	v2.PendingOperations = []apitype.OperationV1{{Resource: v2.Resources[0], Type: apitype.OperationTypeCreating}}
+	_, err = DownToDeploymentV1(v2)
+	assert.Error(t, err)
+}
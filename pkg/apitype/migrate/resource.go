@@ -15,6 +15,8 @@
 package migrate
 
 import (
+	"github.com/pkg/errors"
+
 	"github.com/pulumi/pulumi/pkg/apitype"
 )
 
@@ -47,3 +49,35 @@ func UpToResourceV2(v1 apitype.ResourceV1) apitype.ResourceV2 {
 	v2.InitErrors = append(v2.InitErrors, v1.InitErrors...)
 	return v2
 }
+
+// DownToResourceV1 migrates a resource from ResourceV2 to ResourceV1, for tools that only understand the older format.
+// Resources that Pulumi does not own, and resources bound to first-class providers, cannot be represented by a
+// ResourceV1, and so fail to migrate; the other fields that ResourceV2 added are simply dropped.
+func DownToResourceV1(v2 apitype.ResourceV2) (apitype.ResourceV1, error) {
+	var v1 apitype.ResourceV1
+	if v2.External {
+		return v1, errors.Errorf("resource '%s' is not managed by Pulumi, which version 1 cannot represent", v2.URN)
+	}
+	if v2.Provider != "" {
+		return v1, errors.Errorf("resource '%s' has a first-class provider, which version 1 cannot represent", v2.URN)
+	}
+
+	v1.URN = v2.URN
+	v1.Custom = v2.Custom
+	v1.Delete = v2.Delete
+	v1.ID = v2.ID
+	v1.Type = v2.Type
+	v1.Inputs = make(map[string]interface{})
+	for key, value := range v2.Inputs {
+		v1.Inputs[key] = value
+	}
+	v1.Outputs = make(map[string]interface{})
+	for key, value := range v2.Outputs {
+		v1.Outputs[key] = value
+	}
+	v1.Parent = v2.Parent
+	v1.Protect = v2.Protect
+	v1.Dependencies = append(v1.Dependencies, v2.Dependencies...)
+	v1.InitErrors = append(v1.InitErrors, v2.InitErrors...)
+	return v1, nil
+}
@@ -68,3 +68,40 @@ func TestV1ToV2(t *testing.T) {
 	}, v2.Dependencies)
 	assert.Empty(t, v2.Provider)
 }
+
+func TestV2ToV1(t *testing.T) {
+	v2 := apitype.ResourceV2{
+		URN:    resource.URN("foo"),
+		Custom: true,
+		ID:     resource.ID("bar"),
+		Type:   tokens.Type("special"),
+		Inputs: map[string]interface{}{
+			"foo_in": "baz",
+		},
+		Outputs: map[string]interface{}{
+			"foo_out": "out",
+		},
+		Parent:         resource.URN("parent"),
+		Protect:        true,
+		Dependencies:   []resource.URN{resource.URN("dep1")},
+		SourcePosition: "index.ts:1:1",
+	}
+
+	v1, err := DownToResourceV1(v2)
+	assert.NoError(t, err)
+	assert.Equal(t, resource.URN("foo"), v1.URN)
+	assert.True(t, v1.Custom)
+	assert.Equal(t, resource.ID("bar"), v1.ID)
+	assert.Equal(t, map[string]interface{}{"foo_in": "baz"}, v1.Inputs)
+	assert.Equal(t, map[string]interface{}{"foo_out": "out"}, v1.Outputs)
+	assert.Equal(t, resource.URN("parent"), v1.Parent)
+	assert.True(t, v1.Protect)
+	assert.Equal(t, []resource.URN{resource.URN("dep1")}, v1.Dependencies)
+	assert.Equal(t, v2.Inputs, UpToResourceV2(v1).Inputs)
+
+	// Resources that version 1 cannot represent fail to migrate.
+	_, err = DownToResourceV1(apitype.ResourceV2{URN: resource.URN("ext"), External: true})
+	assert.Error(t, err)
+	_, err = DownToResourceV1(apitype.ResourceV2{URN: resource.URN("prov"), Provider: "urn::default::id"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,139 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitype
+
+// StackResource is a stack as a Kubernetes operator sees it: a custom resource whose spec declares the program and
+// configuration that the stack should be updated to, and whose status reports how the last attempt to do so fared.  It
+// is read, and its status is written, by `pulumi reconcile`.
+type StackResource struct {
+	APIVersion string                `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string                `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Metadata   StackResourceMetadata `json:"metadata" yaml:"metadata"`
+	Spec       StackSpec             `json:"spec" yaml:"spec"`
+	Status     *StackStatus          `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// StackResourceMetadata is the subset of a custom resource's Kubernetes metadata that reconciling it uses.
+type StackResourceMetadata struct {
+	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Generation is incremented by Kubernetes each time that the spec changes.
+	Generation int64 `json:"generation,omitempty" yaml:"generation,omitempty"`
+}
+
+// StackSpec declares the program and configuration that a stack should be updated to.
+type StackSpec struct {
+	// Stack is the name of the stack, qualified by its owner if its backend requires it.
+	Stack string `json:"stack" yaml:"stack"`
+	// Backend is the URL of the backend that keeps the stack's state, e.g. `local:///state` or a Pulumi Cloud URL.  It
+	// defaults to the Pulumi Cloud.
+	Backend string `json:"backend,omitempty" yaml:"backend,omitempty"`
+	// AccessToken refers to the access token with which to log into the backend, if it needs one.
+	AccessToken *SecretReference `json:"accessToken,omitempty" yaml:"accessToken,omitempty"`
+	// CreateStack is true if the stack should be created if it does not exist.
+	CreateStack bool `json:"createStack,omitempty" yaml:"createStack,omitempty"`
+
+	// ProjectRepo is the URL of the Git repository that holds the stack's program.
+	ProjectRepo string `json:"projectRepo" yaml:"projectRepo"`
+	// Commit is the hash of the commit to check out.  Only one of Commit and Branch may be given.
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+	// Branch is the branch, or the fully-qualified reference, e.g. `refs/tags/v1.0`, to check out.  It defaults to
+	// `master`.
+	Branch string `json:"branch,omitempty" yaml:"branch,omitempty"`
+	// RepoDir is the directory within the repository that holds the program's project, if it is not the root.
+	RepoDir string `json:"repoDir,omitempty" yaml:"repoDir,omitempty"`
+
+	// Config holds configuration values to set on the stack, in addition to those that its project already sets.
+	Config map[string]string `json:"config,omitempty" yaml:"config,omitempty"`
+	// Secrets refers to configuration values to set on the stack as secrets.
+	Secrets map[string]SecretReference `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	// EnvRefs refers to the values of environment variables, such as a cloud provider's credentials, or the
+	// passphrase of a stack whose secrets a passphrase protects, with which to run the program.
+	EnvRefs map[string]SecretReference `json:"envRefs,omitempty" yaml:"envRefs,omitempty"`
+
+	// Refresh is true if the stack's resources should be refreshed before it is updated.
+	Refresh bool `json:"refresh,omitempty" yaml:"refresh,omitempty"`
+}
+
+// SecretReference refers to a secret value that is given to the reconciler, rather than written in the spec itself,
+// so that specs may be kept in source control.  In Kubernetes, the reconciler's pod is given such values from Secrets,
+// as environment variables or as mounted files.  Exactly one of Env and File must be set.
+type SecretReference struct {
+	// Env is the name of the environment variable that holds the value.
+	Env string `json:"env,omitempty" yaml:"env,omitempty"`
+	// File is the path of the file that holds the value.  A trailing newline is not part of the value.
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+// StackStatus reports how the last attempt to update a stack to its spec fared.
+type StackStatus struct {
+	// ObservedGeneration is the generation of the spec that was last reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" yaml:"observedGeneration,omitempty"`
+	// LastAttemptedCommit is the commit of the program that was last reconciled, whether or not it succeeded.
+	LastAttemptedCommit string `json:"lastAttemptedCommit,omitempty" yaml:"lastAttemptedCommit,omitempty"`
+	// LastSuccessfulCommit is the commit of the program that the stack was last successfully updated to.
+	LastSuccessfulCommit string `json:"lastSuccessfulCommit,omitempty" yaml:"lastSuccessfulCommit,omitempty"`
+	// Outputs are the stack's outputs, leaving out those that look like secrets, if the last update succeeded.
+	Outputs map[string]interface{} `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	// ResourceChanges counts the resources for each operation, e.g. `create` or `same`, of the last update.
+	ResourceChanges map[string]int `json:"resourceChanges,omitempty" yaml:"resourceChanges,omitempty"`
+	// Conditions are the stack's conditions, in the manner of Kubernetes.
+	Conditions []StackCondition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+}
+
+// StackConditionType is the type of a stack's condition.
+type StackConditionType string
+
+const (
+	// StackReady is true when the stack has been updated to its spec.
+	StackReady StackConditionType = "Ready"
+	// StackStalled is true when the stack cannot be updated to its spec until the spec changes, so that retrying is
+	// futile.
+	StackStalled StackConditionType = "Stalled"
+)
+
+// ConditionStatus is the status of a condition: "True", "False", or "Unknown".
+type ConditionStatus string
+
+// The statuses of conditions.
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// The reasons for a stack's conditions.
+const (
+	ReasonSucceeded         = "Succeeded"         // the stack was updated to its spec.
+	ReasonInvalidSpec       = "InvalidSpec"       // the spec is not well-formed.
+	ReasonSecretUnavailable = "SecretUnavailable" // a secret that the spec refers to could not be read.
+	ReasonSourceUnavailable = "SourceUnavailable" // the program could not be fetched.
+	ReasonLoginFailed       = "LoginFailed"       // the backend could not be logged into.
+	ReasonStackNotFound     = "StackNotFound"     // the stack does not exist, and the spec does not create it.
+	ReasonInvalidConfig     = "InvalidConfig"     // the stack's configuration could not be set.
+	ReasonUpdateFailed      = "UpdateFailed"      // the update itself failed.
+)
+
+// StackCondition is one of a stack's conditions, in the manner of Kubernetes.
+type StackCondition struct {
+	Type   StackConditionType `json:"type" yaml:"type"`
+	Status ConditionStatus    `json:"status" yaml:"status"`
+	// Reason is a short, CamelCase reason for the condition's status.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// Message explains the condition's status.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// LastTransitionTime is the time, in RFC3339 format, at which the condition last changed its status.
+	LastTransitionTime string `json:"lastTransitionTime" yaml:"lastTransitionTime"`
+}
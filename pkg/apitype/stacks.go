@@ -14,7 +14,11 @@
 
 package apitype
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
 
 // StackSummary presents an overview of a particular stack without enumerating its current resource set.
 type StackSummary struct {
@@ -35,6 +39,58 @@ type ListStacksResponse struct {
 	Stacks []StackSummary `json:"stacks"`
 }
 
+// StackListing describes a stack in the same way no matter which backend manages it.  It is the schema of the entries
+// that `pulumi stack ls --json` prints.
+type StackListing struct {
+	// Name is the name of the stack, qualified by its owner if the backend requires it.
+	Name string `json:"name"`
+	// Current is true if the stack is the one currently selected.
+	Current bool `json:"current"`
+	// LastUpdate is the time, in RFC3339 format, at which the stack was last updated, if it ever has been.
+	LastUpdate string `json:"lastUpdate,omitempty"`
+	// UpdateInProgress is true if an update of the stack is running right now.
+	UpdateInProgress bool `json:"updateInProgress"`
+	// ResourceCount is the number of resources in the stack, if it is known.
+	ResourceCount *int `json:"resourceCount,omitempty"`
+	// URL is the address at which the stack may be viewed, if its backend has one.
+	URL string `json:"url,omitempty"`
+	// Tags are the stack's tags, if its backend records them.
+	Tags map[StackTagName]string `json:"tags,omitempty"`
+}
+
+// ListStackListingsResponse is a page of stack listings, sorted by name.
+type ListStackListingsResponse struct {
+	// Stacks contains the listings on this page.
+	Stacks []StackListing `json:"stacks"`
+	// ContinuationToken, if set, may be passed back to fetch the next page of listings.
+	ContinuationToken string `json:"continuationToken,omitempty"`
+}
+
+// UserStackSummary describes a stack as returned by the `GET /api/user/stacks/summaries` endpoint of the PPC API.
+type UserStackSummary struct {
+	OrgName     string       `json:"orgName"`
+	ProjectName string       `json:"projName"`
+	StackName   tokens.QName `json:"stackName"`
+
+	// LastUpdate is the Unix time at which the stack was last updated, or 0 if it has never been updated.
+	LastUpdate int64 `json:"lastUpdate,omitempty"`
+	// UpdateInProgress is true if an update of the stack is running right now.
+	UpdateInProgress bool `json:"updateInProgress"`
+	// ResourceCount is the number of resources in the stack, if it is known.
+	ResourceCount *int `json:"resourceCount,omitempty"`
+	// Tags are the stack's tags.
+	Tags map[StackTagName]string `json:"tags,omitempty"`
+}
+
+// ListUserStackSummariesResponse describes the data returned by the `GET /api/user/stacks/summaries` endpoint of the
+// PPC API.  Stacks are returned a page at a time, sorted by name.
+type ListUserStackSummariesResponse struct {
+	// Stacks contains the summaries on this page.
+	Stacks []UserStackSummary `json:"stacks"`
+	// ContinuationToken, if set, may be passed back to fetch the next page of summaries.
+	ContinuationToken string `json:"continuationToken,omitempty"`
+}
+
 // CreateStackResponseByID describes the data returned by the `POST /stacks` endpoint of the PPC API.
 type CreateStackResponseByID struct {
 	// ID is the unique identifier for the newly-created stack.
@@ -120,3 +176,82 @@ type ImportStackRequest UntypedDeployment
 type ImportStackResponse struct {
 	UpdateID string `json:"updateId"`
 }
+
+// StackPermissionKind identifies the kind of principal to which a stack permission is granted.
+type StackPermissionKind string
+
+const (
+	// StackPermissionUser grants a permission to an individual user.
+	StackPermissionUser StackPermissionKind = "user"
+	// StackPermissionTeam grants a permission to every member of a team.
+	StackPermissionTeam StackPermissionKind = "team"
+)
+
+// StackRole is the level of access that a permission grants to a stack.
+type StackRole string
+
+const (
+	// StackRoleRead allows the stack and its history to be viewed.
+	StackRoleRead StackRole = "read"
+	// StackRoleWrite additionally allows the stack to be updated.
+	StackRoleWrite StackRole = "write"
+	// StackRoleAdmin additionally allows the stack to be deleted and its permissions to be changed.
+	StackRoleAdmin StackRole = "admin"
+)
+
+// StackPermission grants a user or team a role on a stack.
+type StackPermission struct {
+	// Kind is the kind of principal to which the permission is granted.
+	Kind StackPermissionKind `json:"kind"`
+	// Name is the login name of the user or the name of the team.
+	Name string `json:"name"`
+	// Role is the level of access the permission grants.
+	Role StackRole `json:"role"`
+}
+
+// ListStackPermissionsResponse describes the data returned by the `GET /stacks/{owner}/{stack}/permissions` endpoint
+// of the PPC API.
+type ListStackPermissionsResponse struct {
+	Permissions []StackPermission `json:"permissions"`
+}
+
+// UpdateStackPermissionRequest defines the request body for granting a user or team a role on a stack.
+type UpdateStackPermissionRequest struct {
+	Role StackRole `json:"role"`
+}
+
+// StackOutputToken describes a token that may only be used to read the outputs of the stack for which it was
+// created.  The token itself is only revealed when it is created.
+type StackOutputToken struct {
+	// ID identifies the token so that it may be revoked.
+	ID string `json:"id"`
+	// Description is an optional note about who or what the token was created for.
+	Description string `json:"description,omitempty"`
+	// Created is the time at which the token was created, in seconds since the epoch.
+	Created int64 `json:"created"`
+	// LastUsed is the time at which the token was last used, in seconds since the epoch, if it has been used.
+	LastUsed int64 `json:"lastUsed,omitempty"`
+}
+
+// ListStackOutputTokensResponse describes the data returned by the `GET /stacks/{owner}/{stack}/outputtokens`
+// endpoint of the PPC API.
+type ListStackOutputTokensResponse struct {
+	Tokens []StackOutputToken `json:"tokens"`
+}
+
+// CreateStackOutputTokenRequest defines the request body for creating a token that may only read a stack's outputs.
+type CreateStackOutputTokenRequest struct {
+	Description string `json:"description,omitempty"`
+}
+
+// CreateStackOutputTokenResponse is the response from creating a token that may only read a stack's outputs.
+type CreateStackOutputTokenResponse struct {
+	ID    string `json:"id"`
+	Token string `json:"token"`
+}
+
+// GetStackOutputsResponse describes the data returned by the `GET /stacks/{owner}/{stack}/outputs` endpoint of the
+// PPC API, which may be called with either a user's access token or one of the stack's output tokens.
+type GetStackOutputsResponse struct {
+	Outputs map[string]interface{} `json:"outputs"`
+}
@@ -282,6 +282,12 @@ const (
 // CompleteUpdateRequest defines the body of a reqeust to the update completion endpoint of the service API.
 type CompleteUpdateRequest struct {
 	Status UpdateStatus `json:"status"`
+
+	// OutputChanges lists the names of the stack outputs whose values the update changed.
+	OutputChanges []string `json:"outputChanges,omitempty"`
+
+	// StepTimings records how long each of the resource operations that the update applied took.
+	StepTimings []StepTiming `json:"stepTimings,omitempty"`
 }
 
 // PatchUpdateCheckpointRequest defines the body of a request to the patch update checkpoint endpoint of the service
@@ -0,0 +1,43 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitype
+
+// GetUserResponse describes the data returned by the `GET /api/user` endpoint of the PPC API.
+type GetUserResponse struct {
+	// GitHubLogin is the user's login name.
+	GitHubLogin string `json:"githubLogin"`
+	// Name is the user's display name, if they have one.
+	Name string `json:"name,omitempty"`
+	// Organizations lists the organizations to which the user belongs.
+	Organizations []OrganizationSummary `json:"organizations,omitempty"`
+}
+
+// OrganizationSummary describes an organization to which a user belongs.
+type OrganizationSummary struct {
+	// GitHubLogin is the organization's login name.
+	GitHubLogin string `json:"githubLogin"`
+	// Name is the organization's display name, if it has one.
+	Name string `json:"name,omitempty"`
+	// Role is the user's role within the organization, e.g. "admin" or "member", if the service reports it.
+	Role string `json:"role,omitempty"`
+}
+
+// GetTokenInfoResponse describes the data returned by the `GET /api/user/tokens/current` endpoint of the PPC API.
+type GetTokenInfoResponse struct {
+	// Scopes lists the operations the token may be used for.  An empty list means the token is unrestricted.
+	Scopes []string `json:"scopes,omitempty"`
+	// ExpiresAt is the time at which the token expires, in seconds since the Unix epoch, or zero if it never expires.
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+}
@@ -0,0 +1,80 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+)
+
+// AuditLogEntry records an operation that changed, or tried to change, a stack: who ran it, when, and how it ended.
+type AuditLogEntry struct {
+	Time         int64        `json:"time"`                   // the Unix time at which the operation started.
+	User         string       `json:"user"`                   // the user who ran the operation.
+	Kind         string       `json:"kind"`                   // the kind of operation, e.g. "update" or "import".
+	Command      string       `json:"command"`                // the command that ran the operation (see AuditCommand).
+	Result       UpdateResult `json:"result"`                 // whether the operation succeeded.
+	ConfigDigest string       `json:"configDigest,omitempty"` // a digest of the stack's configuration at the time.
+}
+
+// ConfigDigest returns a SHA-256 digest of a stack's configuration, so that audit logs can show when it changed without
+// recording it.  Secrets are digested in their encrypted form.
+func ConfigDigest(cfg config.Map) (string, error) {
+	if cfg == nil {
+		cfg = config.Map{}
+	}
+	byts, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(byts)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AuditCommand describes the command line with the given arguments for an audit log.  Since flag values, such as
+// secret configuration or credentials, must not be recorded, only the words that precede the first flag (that is, the
+// command and its leading arguments) and the names of the flags are kept.
+func AuditCommand(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	words := []string{filepath.Base(args[0])}
+	flags := false
+	for _, arg := range args[1:] {
+		switch {
+		case arg == "--":
+			return strings.Join(words, " ")
+		case strings.HasPrefix(arg, "--"):
+			flags = true
+			if eq := strings.Index(arg, "="); eq != -1 {
+				arg = arg[:eq]
+			}
+			words = append(words, arg)
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			// A shorthand flag may have its value attached, as in `-mvalue`, so only its first letter is kept.
+			flags = true
+			words = append(words, arg[:2])
+		case !flags:
+			words = append(words, arg)
+		}
+	}
+	return strings.Join(words, " ")
+}
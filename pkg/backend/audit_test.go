@@ -0,0 +1,62 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+)
+
+func TestConfigDigest(t *testing.T) {
+	empty, err := ConfigDigest(nil)
+	assert.NoError(t, err)
+	assert.Len(t, empty, 64)
+
+	cfg := config.Map{
+		config.MustMakeKey("aws", "region"):   config.NewValue("us-west-2"),
+		config.MustMakeKey("proj", "db-size"): config.NewValue("large"),
+		config.MustMakeKey("proj", "secret"):  config.NewSecureValue("c2VjcmV0"),
+	}
+	digest, err := ConfigDigest(cfg)
+	assert.NoError(t, err)
+	assert.NotEqual(t, empty, digest)
+
+	// The digest does not depend on the order in which the map is traversed.
+	for i := 0; i < 10; i++ {
+		again, err := ConfigDigest(cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, digest, again)
+	}
+
+	// Any change to the configuration, including to a secret, changes the digest.
+	cfg[config.MustMakeKey("proj", "secret")] = config.NewSecureValue("b3RoZXI=")
+	changed, err := ConfigDigest(cfg)
+	assert.NoError(t, err)
+	assert.NotEqual(t, digest, changed)
+}
+
+func TestAuditCommand(t *testing.T) {
+	assert.Equal(t, "", AuditCommand(nil))
+	assert.Equal(t, "pulumi up", AuditCommand([]string{"/usr/local/bin/pulumi", "up"}))
+	assert.Equal(t, "pulumi stack rm dev --yes", AuditCommand([]string{"pulumi", "stack", "rm", "dev", "--yes"}))
+
+	// Flag values, and anything else following the first flag, are left out.
+	assert.Equal(t, "pulumi up --config --message -s --yes", AuditCommand([]string{
+		"pulumi", "up", "--config", "db:password=hunter2", "--message=deploy", "-sdev", "--yes", "extra"}))
+	assert.Equal(t, "pulumi destroy -y", AuditCommand([]string{"pulumi", "destroy", "-y", "--", "--secret"}))
+}
@@ -22,9 +22,12 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/operations"
+	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cancel"
 	"github.com/pulumi/pulumi/pkg/workspace"
@@ -44,6 +47,11 @@ func (e StackAlreadyExistsError) Error() string {
 	return fmt.Sprintf("stack '%v' already exists", e.StackName)
 }
 
+// DiagnosticID returns the stable code for this class of errors.
+func (e StackAlreadyExistsError) DiagnosticID() diag.ID {
+	return diag.StackAlreadyExistsID
+}
+
 // StackReference is an opaque type that refers to a stack managed by a backend.  The CLI uses the ParseStackReference
 // method to turn a string like "my-great-stack" or "pulumi/my-great-stack" into a stack reference that can be used to
 // interact with the stack via the backend. Stack references are specific to a given backend and different back ends
@@ -62,6 +70,8 @@ type StackReference interface {
 type Backend interface {
 	// Name returns a friendly name for this backend.
 	Name() string
+	// Capabilities returns the optional features that this backend supports.
+	Capabilities() Capabilities
 
 	// ParseStackReference takes a string representation and parses it to a reference which may be used for other
 	// methods in this backend.
@@ -77,7 +87,6 @@ type Backend interface {
 	RemoveStack(ctx context.Context, stackRef StackReference, force bool) (bool, error)
 	// ListStacks returns a list of stack summaries for all known stacks in the target backend.
 	ListStacks(ctx context.Context, projectFilter *tokens.PackageName) ([]Stack, error)
-
 	// GetStackCrypter returns an encrypter/decrypter for the given stack's secret config values.
 	GetStackCrypter(stackRef StackReference) (config.Crypter, error)
 
@@ -97,6 +106,9 @@ type Backend interface {
 	// GetHistory returns all updates for the stack. The returned UpdateInfo slice will be in
 	// descending order (newest first).
 	GetHistory(ctx context.Context, stackRef StackReference) ([]UpdateInfo, error)
+	// GetHistoricalDeployment returns the deployment that was produced by the given version of the stack.
+	GetHistoricalDeployment(ctx context.Context, stackRef StackReference,
+		version int) (*apitype.UntypedDeployment, error)
 	// GetLogs fetches a list of log entries for the given stack, with optional filtering/querying.
 	GetLogs(ctx context.Context, stackRef StackReference, query operations.LogQuery) ([]operations.LogEntry, error)
 	// Get the configuration from the most recent deployment of the stack.
@@ -112,6 +124,101 @@ type Backend interface {
 	CurrentUser() (string, error)
 }
 
+// StackAccessManager is implemented by backends that control which users and teams may access each stack.  Backends
+// that do not support access control, such as the local backend, do not implement it.
+type StackAccessManager interface {
+	// ListStackAccess returns the users and teams that have been granted access to the given stack.
+	ListStackAccess(ctx context.Context, stackRef StackReference) ([]apitype.StackPermission, error)
+	// SetStackAccess grants a user or team the given role on the given stack, replacing any role previously granted.
+	SetStackAccess(ctx context.Context, stackRef StackReference, kind apitype.StackPermissionKind, name string,
+		role apitype.StackRole) error
+	// RemoveStackAccess revokes any role a user or team has been granted on the given stack.
+	RemoveStackAccess(ctx context.Context, stackRef StackReference, kind apitype.StackPermissionKind,
+		name string) error
+}
+
+// UpdateCanceler is implemented by backends that can cancel an update from a process other than the one running it.
+type UpdateCanceler interface {
+	// CancelCurrentUpdate cancels the update that is currently running against the given stack, if any.
+	CancelCurrentUpdate(ctx context.Context, stackRef StackReference) error
+}
+
+// ConsoleLinkedStack is implemented by stacks that have a page in a web console.
+type ConsoleLinkedStack interface {
+	// ConsoleURL returns the URL of the stack's page in the console.
+	ConsoleURL() (string, error)
+	// UpdateConsoleURL returns the URL of the console page of the update that produced the given version of the stack.
+	UpdateConsoleURL(version int) (string, error)
+	// ResourceConsoleURL returns the URL of the console page of the stack's resource with the given URN.
+	ResourceConsoleURL(urn resource.URN) (string, error)
+}
+
+// StackOutputTokenManager is implemented by backends that can create tokens which may only be used to read a stack's
+// outputs, so that the outputs may be shared with the consumers of a stack without granting them access to its state.
+type StackOutputTokenManager interface {
+	// ListStackOutputTokens returns the tokens that may read the outputs of the given stack.
+	ListStackOutputTokens(ctx context.Context, stackRef StackReference) ([]apitype.StackOutputToken, error)
+	// CreateStackOutputToken creates a token that may only read the outputs of the given stack, and returns its ID
+	// along with the token itself, which cannot be retrieved again.
+	CreateStackOutputToken(ctx context.Context, stackRef StackReference, description string) (string, string, error)
+	// RevokeStackOutputToken revokes the output token of the given stack that has the given ID.
+	RevokeStackOutputToken(ctx context.Context, stackRef StackReference, id string) error
+}
+
+// StackOutputsReader is implemented by backends that can read a stack's outputs without reading its state, as they
+// must when authenticated with one of the stack's output tokens.
+type StackOutputsReader interface {
+	// GetStackOutputs returns the outputs of the given stack.
+	GetStackOutputs(ctx context.Context, stackRef StackReference) (map[string]interface{}, error)
+}
+
+// StackLister is implemented by backends that can list their stacks a page at a time, filtering them as they go.
+type StackLister interface {
+	// ListStackListings returns a page of listings of the stacks in the target backend that pass the filter, sorted
+	// by name.  The first page is fetched with an empty continuation token, and each later one with the token of the
+	// page before.  A page size of zero uses the backend's default.
+	ListStackListings(ctx context.Context, filter StackFilter, continuationToken string,
+		pageSize int) (*apitype.ListStackListingsResponse, error)
+}
+
+// ProjectManager is implemented by backends that can list, rename, and remove the projects their stacks belong to.
+type ProjectManager interface {
+	// ListProjects returns the stacks of each of the backend's projects.  Stacks whose project cannot be determined,
+	// such as local stacks without any resources, are listed under the empty project name.
+	ListProjects(ctx context.Context) (map[tokens.PackageName][]StackReference, error)
+	// RenameProject moves every stack of a project into another, rewriting the URNs of their resources to match.
+	RenameProject(ctx context.Context, oldName, newName tokens.PackageName) error
+	// RemoveProject removes every stack of a project.  Unless all of them are empty, it fails and removes nothing.
+	RemoveProject(ctx context.Context, name tokens.PackageName) error
+}
+
+// StackReferenceRecorder is implemented by backends that record which stacks read the outputs of which others, so that
+// a preview of a stack can show the stacks whose inputs a change to its outputs would affect.  References are given as
+// a map from the name of each stack whose outputs are read to the names of those outputs, in which `*` matches
+// anything.
+type StackReferenceRecorder interface {
+	// RecordStackReferences replaces the references that the given stack is recorded as making to other stacks.
+	RecordStackReferences(ctx context.Context, stackRef StackReference, refs map[string][]string) error
+	// GetStackConsumers returns the outputs of the given stack that each of the stacks which reference it reads.
+	GetStackConsumers(ctx context.Context, stackRef StackReference) (map[string][]string, error)
+}
+
+// StackAuditLogReader is implemented by backends that keep an append-only log of the operations on each of their
+// stacks, such as the local backend.  The Pulumi Service keeps its own audit log, which its console displays.
+type StackAuditLogReader interface {
+	// GetAuditLog returns the stack's audit log, most recent entry first.
+	GetAuditLog(ctx context.Context, stackRef StackReference) ([]AuditLogEntry, error)
+}
+
+// UpdateEventsReader is implemented by backends that record the engine events of each stack's latest update as it
+// runs, so that its progress may be followed from another process.
+type UpdateEventsReader interface {
+	// GetLatestUpdateEvents returns the events that the stack's latest update has produced so far, and whether that
+	// update has finished.  A stack that has never been updated has no events, and its latest update is reported as
+	// finished.
+	GetLatestUpdateEvents(ctx context.Context, stackRef StackReference) ([]apitype.EngineEvent, bool, error)
+}
+
 // UpdateOptions is the full set of update options, including backend and engine options.
 type UpdateOptions struct {
 	// Engine contains all of the engine-specific options.
@@ -123,6 +230,9 @@ type UpdateOptions struct {
 	AutoApprove bool
 	// SkipPreview, when true, causes the preview step to be skipped.
 	SkipPreview bool
+	// PreviewAgainst, when non-nil, is used in place of the stack's current checkpoint as the base snapshot for
+	// previews. This allows computing a plan relative to an arbitrary, proposed snapshot.
+	PreviewAgainst *deploy.Snapshot
 }
 
 // CancellationScope provides a scoped source of cancellation and termination requests.
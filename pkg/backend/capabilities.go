@@ -0,0 +1,32 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+// Capabilities describes the optional features that a backend supports, so that commands can offer or withhold them
+// without knowing which kind of backend they are using.  A backend that reports a capability which some interface in
+// this package provides, such as StackAccessManager for Teams, must implement that interface.
+type Capabilities struct {
+	History         bool // the backend keeps the history of each stack's updates.
+	Tags            bool // stacks carry tags, which listings may be filtered by.
+	Teams           bool // stacks may be shared with users and teams; see StackAccessManager.
+	CancelUpdates   bool // an update may be canceled by a process other than the one running it; see UpdateCanceler.
+	Console         bool // stacks have pages in a web console; see ConsoleLinkedStack.
+	OutputTokens    bool // tokens may be created that only read a stack's outputs; see StackOutputTokenManager.
+	OutputsReader   bool // a stack's outputs may be read without reading its state; see StackOutputsReader.
+	Projects        bool // projects may be listed, renamed, and removed; see ProjectManager.
+	AuditLog        bool // the backend keeps an audit log of each stack; see StackAuditLogReader.
+	StackReferences bool // the backend records which stacks read others' outputs; see StackReferenceRecorder.
+	UpdateEvents    bool // the events of each stack's latest update may be followed as it runs; see UpdateEventsReader.
+}
@@ -20,6 +20,7 @@ import (
 	cryptorand "crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -52,6 +53,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/archive"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
@@ -141,6 +143,10 @@ type Backend interface {
 
 	CancelCurrentUpdate(ctx context.Context, stackRef backend.StackReference) error
 	StackConsoleURL(stackRef backend.StackReference) (string, error)
+
+	// CurrentUserInfo returns details about the current user and their access token.  The token information is nil if
+	// the service does not report it.
+	CurrentUserInfo(ctx context.Context) (*apitype.GetUserResponse, *apitype.GetTokenInfoResponse, error)
 }
 
 type cloudBackend struct {
@@ -149,6 +155,13 @@ type cloudBackend struct {
 	client *client.Client
 }
 
+var _ backend.StackAccessManager = (*cloudBackend)(nil)
+var _ backend.StackOutputTokenManager = (*cloudBackend)(nil)
+var _ backend.StackOutputsReader = (*cloudBackend)(nil)
+var _ backend.StackLister = (*cloudBackend)(nil)
+var _ backend.ProjectManager = (*cloudBackend)(nil)
+var _ backend.UpdateCanceler = (*cloudBackend)(nil)
+
 // New creates a new Pulumi backend for the given cloud API URL and token.
 func New(d diag.Sink, cloudURL string) (Backend, error) {
 	cloudURL = ValueOrDefaultURL(cloudURL)
@@ -157,11 +170,17 @@ func New(d diag.Sink, cloudURL string) (Backend, error) {
 		return nil, errors.Wrap(err, "getting stored credentials")
 	}
 
+	return NewWithAccessToken(d, cloudURL, apiToken), nil
+}
+
+// NewWithAccessToken creates a cloud backend for the given URL that authenticates with the given access token, rather
+// than with the one stored for the URL.
+func NewWithAccessToken(d diag.Sink, cloudURL, apiToken string) Backend {
 	return &cloudBackend{
 		d:      d,
 		url:    cloudURL,
 		client: client.NewClient(cloudURL, apiToken),
-	}, nil
+	}
 }
 
 // loginWithBrowser uses a web-browser to log into the cloud and returns the cloud backend for it.
@@ -325,10 +344,37 @@ func (b *cloudBackend) Name() string {
 	return b.url
 }
 
+func (b *cloudBackend) Capabilities() backend.Capabilities {
+	return backend.Capabilities{
+		History:       true,
+		Tags:          true,
+		Teams:         true,
+		CancelUpdates: true,
+		Console:       true,
+		OutputTokens:  true,
+		OutputsReader: true,
+		Projects:      true,
+	}
+}
+
 func (b *cloudBackend) CurrentUser() (string, error) {
 	return b.client.GetPulumiAccountName(context.Background())
 }
 
+func (b *cloudBackend) CurrentUserInfo(
+	ctx context.Context) (*apitype.GetUserResponse, *apitype.GetTokenInfoResponse, error) {
+
+	user, err := b.client.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	token, err := b.client.GetCurrentTokenInfo(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, token, nil
+}
+
 func (b *cloudBackend) CloudURL() string { return b.url }
 
 func (b *cloudBackend) ParseStackReference(s string) (backend.StackReference, error) {
@@ -515,6 +561,135 @@ func (b *cloudBackend) ListStacks(ctx context.Context, projectFilter *tokens.Pac
 	return results, nil
 }
 
+func (b *cloudBackend) ListStackListings(ctx context.Context, filter backend.StackFilter, continuationToken string,
+	pageSize int) (*apitype.ListStackListingsResponse, error) {
+
+	summaries, err := b.client.ListStackSummaries(ctx, filter, continuationToken, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stacks owned by the current user are named without their owner, just as cloudBackendReference names them.
+	curUser, err := b.client.GetPulumiAccountName(ctx)
+	if err != nil {
+		curUser = ""
+	}
+
+	resp := &apitype.ListStackListingsResponse{
+		Stacks:            []apitype.StackListing{},
+		ContinuationToken: summaries.ContinuationToken,
+	}
+	for _, summary := range summaries.Stacks {
+		listing := apitype.StackListing{
+			Name:             string(summary.StackName),
+			UpdateInProgress: summary.UpdateInProgress,
+			ResourceCount:    summary.ResourceCount,
+			Tags:             summary.Tags,
+		}
+		if summary.OrgName != curUser {
+			listing.Name = fmt.Sprintf("%s/%s", summary.OrgName, summary.StackName)
+		}
+		if summary.LastUpdate != 0 {
+			listing.LastUpdate = time.Unix(summary.LastUpdate, 0).UTC().Format(time.RFC3339)
+		}
+		stackID := client.StackIdentifier{Owner: summary.OrgName, Stack: string(summary.StackName)}
+		listing.URL = b.CloudConsoleURL(b.cloudConsoleStackPath(stackID))
+		resp.Stacks = append(resp.Stacks, listing)
+	}
+	return resp, nil
+}
+
+func (b *cloudBackend) ListProjects(ctx context.Context) (map[tokens.PackageName][]backend.StackReference, error) {
+	stacks, err := b.client.ListStacks(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make(map[tokens.PackageName][]backend.StackReference)
+	for _, stack := range stacks {
+		project := tokens.PackageName(stack.ProjectName)
+		projects[project] = append(projects[project], newStack(stack, b).Name())
+	}
+	return projects, nil
+}
+
+func (b *cloudBackend) RenameProject(ctx context.Context, oldName, newName tokens.PackageName) error {
+	stacks, err := b.client.ListStacks(ctx, &oldName)
+	if err != nil {
+		return err
+	}
+	if len(stacks) == 0 {
+		return errors.Errorf("project '%s' has no stacks", oldName)
+	}
+
+	// The service only knows a stack's project by its tag, so renaming the project means rewriting the stack's
+	// resources and then retagging it.
+	for _, apistack := range stacks {
+		ref := newStack(apistack, b).Name()
+		snap, err := b.getSnapshot(ctx, ref)
+		if err != nil {
+			return errors.Wrapf(err, "reading stack '%s'", ref)
+		}
+		if err = backend.RenameSnapshotProject(snap, newName); err != nil {
+			return errors.Wrapf(err, "renaming the resources of stack '%s'", ref)
+		}
+		if snap != nil {
+			var data bytes.Buffer
+			if err = stack.WriteDeployment(&data, snap, ""); err != nil {
+				return err
+			}
+			deployment := &apitype.UntypedDeployment{Version: 2, Deployment: json.RawMessage(data.Bytes())}
+			if err = b.ImportDeployment(ctx, ref, deployment); err != nil {
+				return errors.Wrapf(err, "writing stack '%s'", ref)
+			}
+		}
+
+		stackID, err := b.getCloudStackIdentifier(ref)
+		if err != nil {
+			return err
+		}
+		tags := make(map[apitype.StackTagName]string)
+		for k, v := range apistack.Tags {
+			tags[k] = v
+		}
+		tags[apitype.ProjectNameTag] = string(newName)
+		if err = b.client.UpdateStackTags(ctx, stackID, tags); err != nil {
+			return errors.Wrapf(err, "retagging stack '%s'", ref)
+		}
+	}
+	return nil
+}
+
+func (b *cloudBackend) RemoveProject(ctx context.Context, name tokens.PackageName) error {
+	stacks, err := b.client.ListStacks(ctx, &name)
+	if err != nil {
+		return err
+	}
+	if len(stacks) == 0 {
+		return errors.Errorf("project '%s' has no stacks", name)
+	}
+
+	// Check every stack before removing any, so that a project that is not empty is left untouched.
+	var refs []backend.StackReference
+	for _, apistack := range stacks {
+		ref := newStack(apistack, b).Name()
+		snap, err := b.getSnapshot(ctx, ref)
+		if err != nil {
+			return errors.Wrapf(err, "reading stack '%s'", ref)
+		}
+		if !backend.SnapshotIsEmpty(snap) {
+			return errors.Errorf("project '%s' is not empty: stack '%s' still has resources", name, ref)
+		}
+		refs = append(refs, ref)
+	}
+	for _, ref := range refs {
+		if _, err = b.RemoveStack(ctx, ref, true); err != nil {
+			return errors.Wrapf(err, "removing stack '%s'", ref)
+		}
+	}
+	return nil
+}
+
 func (b *cloudBackend) RemoveStack(ctx context.Context, stackRef backend.StackReference, force bool) (bool, error) {
 	stack, err := b.getCloudStackIdentifier(stackRef)
 	if err != nil {
@@ -814,8 +989,9 @@ func (b *cloudBackend) createAndStartUpdate(
 		const showProgress = true
 		return getUpdateContents(programContext, pkg.UseDefaultIgnores(), showProgress, opts.Display)
 	}
+	stackConfig := pkg.StackConfig(workspaceStack.Config)
 	update, err := b.client.CreateUpdate(
-		ctx, action, stack, pkg, workspaceStack.Config, main, metadata, opts.Engine, dryRun, getContents)
+		ctx, action, stack, pkg, stackConfig, main, metadata, opts.Engine, dryRun, getContents)
 	if err != nil {
 		return client.UpdateIdentifier{}, 0, "", err
 	}
@@ -832,7 +1008,7 @@ func (b *cloudBackend) createAndStartUpdate(
 	}
 	// Any non-preview update will be considered part of the stack's update history.
 	if action != apitype.PreviewUpdate {
-		logging.V(7).Infof("Stack %s being updated to version %d", stackRef, version)
+		logging.Backend.V(7).Infof("Stack %s being updated to version %d", stackRef, version)
 	}
 
 	return update, version, token, nil
@@ -862,6 +1038,9 @@ func (b *cloudBackend) updateStack(
 	if err != nil {
 		return nil, err
 	}
+	if persist && !dryRun {
+		opts.Engine.UpdateVersion = version
+	}
 
 	if persist {
 		// Print a URL at the end of the update pointing to the Pulumi Service.
@@ -925,6 +1104,11 @@ func (b *cloudBackend) runEngineAction(
 		return nil, err
 	}
 
+	// If we were handed a proposed snapshot to preview against, use it in place of the stack's checkpoint.
+	if dryRun && opts.PreviewAgainst != nil {
+		u.target.Snapshot = opts.PreviewAgainst
+	}
+
 	persister := b.newSnapshotPersister(ctx, u.update, u.tokenSource)
 	manager := backend.NewSnapshotManager(persister, u.GetTarget().Snapshot)
 	displayEvents := make(chan engine.Event)
@@ -952,6 +1136,26 @@ func (b *cloudBackend) runEngineAction(
 		close(eventsDone)
 	}()
 
+	// Remember which stack outputs the update changed, so that they may be recorded in the stack's history.
+	var outputChanges []string
+	onOutputChanges := opts.Engine.OnOutputChanges
+	opts.Engine.OnOutputChanges = func(outputs []string) {
+		outputChanges = outputs
+		if onOutputChanges != nil {
+			onOutputChanges(outputs)
+		}
+	}
+
+	// Likewise, remember how long each resource operation took.
+	var timings []engine.StepTiming
+	onStepTimings := opts.Engine.OnStepTimings
+	opts.Engine.OnStepTimings = func(t []engine.StepTiming) {
+		timings = t
+		if onStepTimings != nil {
+			onStepTimings(t)
+		}
+	}
+
 	// Depending on the action, kick off the relevant engine activity.  Note that we don't immediately check and
 	// return error conditions, because we will do so below after waiting for the display channels to close.
 	var changes engine.ResourceChanges
@@ -989,7 +1193,11 @@ func (b *cloudBackend) runEngineAction(
 			status = apitype.UpdateStatusFailed
 		}
 
-		completeErr := u.Complete(status)
+		completeErr := u.Complete(apitype.CompleteUpdateRequest{
+			Status:        status,
+			OutputChanges: outputChanges,
+			StepTimings:   convertStepTimings(timings),
+		})
 		if completeErr != nil {
 			err = multierror.Append(err, errors.Wrap(completeErr, "failed to complete update"))
 		}
@@ -1019,6 +1227,74 @@ func (b *cloudBackend) CancelCurrentUpdate(ctx context.Context, stackRef backend
 	return b.client.CancelUpdate(ctx, updateID)
 }
 
+func (b *cloudBackend) ListStackAccess(ctx context.Context,
+	stackRef backend.StackReference) ([]apitype.StackPermission, error) {
+
+	stackID, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return nil, err
+	}
+	return b.client.ListStackPermissions(ctx, stackID)
+}
+
+func (b *cloudBackend) SetStackAccess(ctx context.Context, stackRef backend.StackReference,
+	kind apitype.StackPermissionKind, name string, role apitype.StackRole) error {
+
+	stackID, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return err
+	}
+	return b.client.UpdateStackPermission(ctx, stackID, kind, name, role)
+}
+
+func (b *cloudBackend) RemoveStackAccess(ctx context.Context, stackRef backend.StackReference,
+	kind apitype.StackPermissionKind, name string) error {
+
+	stackID, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return err
+	}
+	return b.client.DeleteStackPermission(ctx, stackID, kind, name)
+}
+
+func (b *cloudBackend) ListStackOutputTokens(ctx context.Context,
+	stackRef backend.StackReference) ([]apitype.StackOutputToken, error) {
+
+	stackID, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return nil, err
+	}
+	return b.client.ListStackOutputTokens(ctx, stackID)
+}
+
+func (b *cloudBackend) CreateStackOutputToken(ctx context.Context, stackRef backend.StackReference,
+	description string) (string, string, error) {
+
+	stackID, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return "", "", err
+	}
+	return b.client.CreateStackOutputToken(ctx, stackID, description)
+}
+
+func (b *cloudBackend) RevokeStackOutputToken(ctx context.Context, stackRef backend.StackReference, id string) error {
+	stackID, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return err
+	}
+	return b.client.DeleteStackOutputToken(ctx, stackID, id)
+}
+
+func (b *cloudBackend) GetStackOutputs(ctx context.Context,
+	stackRef backend.StackReference) (map[string]interface{}, error) {
+
+	stackID, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return nil, err
+	}
+	return b.client.GetStackOutputs(ctx, stackID)
+}
+
 func (b *cloudBackend) GetHistory(ctx context.Context, stackRef backend.StackReference) ([]backend.UpdateInfo, error) {
 	stack, err := b.getCloudStackIdentifier(stackRef)
 	if err != nil {
@@ -1040,6 +1316,7 @@ func (b *cloudBackend) GetHistory(ctx context.Context, stackRef backend.StackRef
 		}
 
 		beUpdates = append(beUpdates, backend.UpdateInfo{
+			Version:         update.Version,
 			Kind:            update.Kind,
 			Message:         update.Message,
 			Environment:     update.Environment,
@@ -1048,12 +1325,36 @@ func (b *cloudBackend) GetHistory(ctx context.Context, stackRef backend.StackRef
 			StartTime:       update.StartTime,
 			EndTime:         update.EndTime,
 			ResourceChanges: convertResourceChanges(update.ResourceChanges),
+			OutputChanges:   update.OutputChanges,
+			StepTimings:     convertAPIStepTimings(update.StepTimings),
 		})
 	}
 
 	return beUpdates, nil
 }
 
+func (b *cloudBackend) GetHistoricalDeployment(ctx context.Context, stackRef backend.StackReference,
+	version int) (*apitype.UntypedDeployment, error) {
+
+	stack, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := b.client.GetStackUpdate(ctx, stack, version)
+	if err != nil {
+		return nil, err
+	}
+	if len(update.Deployment) == 0 {
+		return nil, errors.Errorf("version %d of stack '%s' has no recorded deployment", version, stackRef)
+	}
+
+	return &apitype.UntypedDeployment{
+		Version:    apitype.DeploymentSchemaVersionCurrent,
+		Deployment: update.Deployment,
+	}, nil
+}
+
 func (b *cloudBackend) GetLatestConfiguration(ctx context.Context,
 	stackRef backend.StackReference) (config.Map, error) {
 
@@ -1074,6 +1375,34 @@ func convertResourceChanges(changes map[apitype.OpType]int) engine.ResourceChang
 	return b
 }
 
+// convertStepTimings converts the engine's step timings into the apitype version.
+func convertStepTimings(timings []engine.StepTiming) []apitype.StepTiming {
+	var result []apitype.StepTiming
+	for _, t := range timings {
+		result = append(result, apitype.StepTiming{
+			URN:      string(t.URN),
+			Op:       apitype.OpType(t.Op),
+			Duration: int64(t.Duration),
+			Failed:   t.Failed,
+		})
+	}
+	return result
+}
+
+// convertAPIStepTimings converts the apitype version of step timings into the engine's.
+func convertAPIStepTimings(timings []apitype.StepTiming) []engine.StepTiming {
+	var result []engine.StepTiming
+	for _, t := range timings {
+		result = append(result, engine.StepTiming{
+			URN:      resource.URN(t.URN),
+			Op:       deploy.StepOp(t.Op),
+			Duration: time.Duration(t.Duration),
+			Failed:   t.Failed,
+		})
+	}
+	return result
+}
+
 // convertResourceChanges converts the apitype version of config.Map into the internal version.
 func convertConfig(apiConfig map[string]apitype.ConfigValue) (config.Map, error) {
 	c := make(config.Map)
@@ -1293,16 +1622,16 @@ func (b *cloudBackend) tryNextUpdate(ctx context.Context, update client.UpdateId
 			if try < 10 {
 				warn = false
 			}
-			logging.V(3).Infof("Expected %s HTTP %d error after %d retries (retrying): %v",
+			logging.Backend.V(3).Infof("Expected %s HTTP %d error after %d retries (retrying): %v",
 				b.CloudURL(), errResp.Code, try, err)
 		} else {
 			// Otherwise, we will issue an error.
-			logging.V(3).Infof("Unexpected %s HTTP %d error after %d retries (erroring): %v",
+			logging.Backend.V(3).Infof("Unexpected %s HTTP %d error after %d retries (erroring): %v",
 				b.CloudURL(), errResp.Code, try, err)
 			return false, nil, err
 		}
 	} else {
-		logging.V(3).Infof("Unexpected %s error after %d retries (retrying): %v", b.CloudURL(), try, err)
+		logging.Backend.V(3).Infof("Unexpected %s error after %d retries (retrying): %v", b.CloudURL(), try, err)
 	}
 
 	// Issue a warning if appropriate.
@@ -0,0 +1,73 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+// TestCapabilities ensures that the cloud backend implements the interfaces behind each capability it reports.
+func TestCapabilities(t *testing.T) {
+	var b backend.Backend = &cloudBackend{}
+	caps := b.Capabilities()
+
+	_, ok := b.(backend.StackAccessManager)
+	assert.Equal(t, caps.Teams, ok)
+	_, ok = b.(backend.UpdateCanceler)
+	assert.Equal(t, caps.CancelUpdates, ok)
+	_, ok = b.(backend.StackOutputTokenManager)
+	assert.Equal(t, caps.OutputTokens, ok)
+	_, ok = b.(backend.StackOutputsReader)
+	assert.Equal(t, caps.OutputsReader, ok)
+	_, ok = b.(backend.ProjectManager)
+	assert.Equal(t, caps.Projects, ok)
+	_, ok = b.(backend.StackAuditLogReader)
+	assert.Equal(t, caps.AuditLog, ok)
+	_, ok = b.(backend.StackReferenceRecorder)
+	assert.Equal(t, caps.StackReferences, ok)
+	_, ok = b.(backend.UpdateEventsReader)
+	assert.Equal(t, caps.UpdateEvents, ok)
+
+	var s backend.Stack = &cloudStack{b: &cloudBackend{}}
+	_, ok = s.(backend.ConsoleLinkedStack)
+	assert.Equal(t, caps.Console, ok)
+}
+
+// TestStepTimings ensures that step timings survive being recorded with an update and read back from its history, and
+// that they are serialized just as the local backend serializes them.
+func TestStepTimings(t *testing.T) {
+	timings := []engine.StepTiming{
+		{URN: "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs", Op: deploy.OpCreate, Duration: 3 * time.Second},
+		{URN: "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::old", Op: deploy.OpDelete, Duration: time.Second,
+			Failed: true},
+	}
+
+	converted := convertStepTimings(timings)
+	assert.Equal(t, timings, convertAPIStepTimings(converted))
+
+	engineJSON, err := json.Marshal(timings)
+	assert.NoError(t, err)
+	apiJSON, err := json.Marshal(converted)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(engineJSON), string(apiJSON))
+}
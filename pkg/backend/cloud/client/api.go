@@ -141,9 +141,9 @@ func pulumiAPICall(ctx context.Context, cloudAPI, method, path string, body []by
 		req.Header.Set("X-Pulumi-Tracing", tracingOptions.TracingHeader)
 	}
 
-	logging.V(7).Infof("Making Pulumi API call: %s", url)
-	if logging.V(9) {
-		logging.V(9).Infof("Pulumi API call details (%s): headers=%v; body=%v", url, req.Header, string(body))
+	logging.Backend.V(7).Infof("Making Pulumi API call: %s", url)
+	if logging.Backend.V(9).Enabled() {
+		logging.Backend.V(9).Infof("Pulumi API call details (%s): headers=%v; body=%v", url, req.Header, string(body))
 	}
 
 	var resp *http.Response
@@ -156,7 +156,7 @@ func pulumiAPICall(ctx context.Context, cloudAPI, method, path string, body []by
 	if err != nil {
 		return "", nil, errors.Wrapf(err, "performing HTTP request")
 	}
-	logging.V(7).Infof("Pulumi API call response code (%s): %v", url, resp.Status)
+	logging.Backend.V(7).Infof("Pulumi API call response code (%s): %v", url, resp.Status)
 
 	requestSpan.SetTag("responseCode", resp.Status)
 
@@ -230,8 +230,8 @@ func pulumiRESTCall(ctx context.Context, cloudAPI, method, path string, queryObj
 	if err != nil {
 		return errors.Wrapf(err, "reading response from API")
 	}
-	if logging.V(9) {
-		logging.V(7).Infof("Pulumi API call response body (%s): %v", url, string(respBody))
+	if logging.Backend.V(9).Enabled() {
+		logging.Backend.V(7).Infof("Pulumi API call response body (%s): %v", url, string(respBody))
 	}
 
 	if respObj != nil {
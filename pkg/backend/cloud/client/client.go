@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 	"time"
 
 	"github.com/blang/semver"
@@ -94,26 +95,54 @@ func getUpdatePath(update UpdateIdentifier, components ...string) string {
 	return getStackPath(update.StackIdentifier, components...)
 }
 
+// getStackPermissionPath returns the API path for the permission granted to the indicated user or team on a stack.
+func getStackPermissionPath(stack StackIdentifier, kind apitype.StackPermissionKind, name string) string {
+	return getStackPath(stack, "permissions", string(kind)+"s", url.PathEscape(name))
+}
+
 // GetPulumiAccountName returns the user implied by the API token associated with this client.
 func (pc *Client) GetPulumiAccountName(ctx context.Context) (string, error) {
 	if pc.apiUser == "" {
-		resp := struct {
-			GitHubLogin string `json:"githubLogin"`
-		}{}
-		if err := pc.restCall(ctx, "GET", "/api/user", nil, nil, &resp); err != nil {
+		user, err := pc.GetCurrentUser(ctx)
+		if err != nil {
 			return "", err
 		}
 
-		if resp.GitHubLogin == "" {
-			return "", errors.New("unexpected response from server")
-		}
-
-		pc.apiUser = resp.GitHubLogin
+		pc.apiUser = user.GitHubLogin
 	}
 
 	return pc.apiUser, nil
 }
 
+// GetCurrentUser returns information about the user implied by the API token associated with this client, including
+// the organizations to which they belong.
+func (pc *Client) GetCurrentUser(ctx context.Context) (*apitype.GetUserResponse, error) {
+	var resp apitype.GetUserResponse
+	if err := pc.restCall(ctx, "GET", "/api/user", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.GitHubLogin == "" {
+		return nil, errors.New("unexpected response from server")
+	}
+
+	return &resp, nil
+}
+
+// GetCurrentTokenInfo returns the scopes and expiry of the API token associated with this client.  If the service does
+// not report this information, nil is returned.
+func (pc *Client) GetCurrentTokenInfo(ctx context.Context) (*apitype.GetTokenInfoResponse, error) {
+	var resp apitype.GetTokenInfoResponse
+	if err := pc.restCall(ctx, "GET", "/api/user/tokens/current", nil, nil, &resp); err != nil {
+		if errResp, ok := err.(*apitype.ErrorResponse); ok && errResp.Code == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
 // DownloadPlugin downloads the indicated plugin from the Pulumi API.
 func (pc *Client) DownloadPlugin(ctx context.Context, info workspace.PluginInfo, os,
 	arch string) (io.ReadCloser, int64, error) {
@@ -168,6 +197,41 @@ func (pc *Client) ListStacks(ctx context.Context, projectFilter *tokens.PackageN
 	return stacks, nil
 }
 
+// ListStackSummaries lists a page of summaries of the stacks that pass the given filter.  The service applies the
+// filter, so that only matching stacks are downloaded.  An empty continuation token fetches the first page, and a page
+// size of zero uses the service's default.
+func (pc *Client) ListStackSummaries(ctx context.Context, filter backend.StackFilter, continuationToken string,
+	pageSize int) (apitype.ListUserStackSummariesResponse, error) {
+
+	query := struct {
+		ProjectFilter     string `url:"project,omitempty"`
+		TagName           string `url:"tagName,omitempty"`
+		TagValue          string `url:"tagValue,omitempty"`
+		LastUpdatedBefore int64  `url:"lastUpdatedBefore,omitempty"`
+		ContinuationToken string `url:"continuationToken,omitempty"`
+		PageSize          int    `url:"pageSize,omitempty"`
+	}{
+		TagName:           filter.TagName,
+		ContinuationToken: continuationToken,
+		PageSize:          pageSize,
+	}
+	if filter.Project != nil {
+		query.ProjectFilter = string(*filter.Project)
+	}
+	if filter.TagValue != nil {
+		query.TagValue = *filter.TagValue
+	}
+	if filter.LastUpdatedBefore != nil {
+		query.LastUpdatedBefore = filter.LastUpdatedBefore.Unix()
+	}
+
+	var resp apitype.ListUserStackSummariesResponse
+	if err := pc.restCall(ctx, "GET", "/api/user/stacks/summaries", query, nil, &resp); err != nil {
+		return apitype.ListUserStackSummariesResponse{}, err
+	}
+	return resp, nil
+}
+
 // GetLatestConfiguration returns the configuration for the latest deployment of a given stack.
 func (pc *Client) GetLatestConfiguration(ctx context.Context, stackID StackIdentifier) (config.Map, error) {
 	latest := struct {
@@ -252,6 +316,13 @@ func (pc *Client) DeleteStack(ctx context.Context, stack StackIdentifier, force
 	return false, pc.restCall(ctx, "DELETE", path, nil, nil, nil)
 }
 
+// UpdateStackTags replaces the tags of the indicated stack.
+func (pc *Client) UpdateStackTags(ctx context.Context, stack StackIdentifier,
+	tags map[apitype.StackTagName]string) error {
+
+	return pc.restCall(ctx, "PATCH", getStackPath(stack, "tags"), nil, tags, nil)
+}
+
 // EncryptValue encrypts a plaintext value in the context of the indicated stack.
 func (pc *Client) EncryptValue(ctx context.Context, stack StackIdentifier, plaintext []byte) ([]byte, error) {
 	req := apitype.EncryptValueRequest{Plaintext: plaintext}
@@ -272,6 +343,70 @@ func (pc *Client) DecryptValue(ctx context.Context, stack StackIdentifier, ciphe
 	return resp.Plaintext, nil
 }
 
+// ListStackPermissions returns the users and teams that have been granted access to the indicated stack.
+func (pc *Client) ListStackPermissions(ctx context.Context, stack StackIdentifier) ([]apitype.StackPermission, error) {
+	var resp apitype.ListStackPermissionsResponse
+	if err := pc.restCall(ctx, "GET", getStackPath(stack, "permissions"), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Permissions, nil
+}
+
+// UpdateStackPermission grants the indicated user or team the given role on the indicated stack, replacing any role
+// they were previously granted.
+func (pc *Client) UpdateStackPermission(ctx context.Context, stack StackIdentifier,
+	kind apitype.StackPermissionKind, name string, role apitype.StackRole) error {
+
+	req := apitype.UpdateStackPermissionRequest{Role: role}
+	return pc.restCall(ctx, "PUT", getStackPermissionPath(stack, kind, name), nil, &req, nil)
+}
+
+// DeleteStackPermission revokes any role the indicated user or team has been granted on the indicated stack.
+func (pc *Client) DeleteStackPermission(ctx context.Context, stack StackIdentifier,
+	kind apitype.StackPermissionKind, name string) error {
+
+	return pc.restCall(ctx, "DELETE", getStackPermissionPath(stack, kind, name), nil, nil, nil)
+}
+
+// ListStackOutputTokens returns the tokens that may read the outputs of the indicated stack.
+func (pc *Client) ListStackOutputTokens(ctx context.Context,
+	stack StackIdentifier) ([]apitype.StackOutputToken, error) {
+
+	var resp apitype.ListStackOutputTokensResponse
+	if err := pc.restCall(ctx, "GET", getStackPath(stack, "outputtokens"), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tokens, nil
+}
+
+// CreateStackOutputToken creates a token that may only read the outputs of the indicated stack, returning its ID and
+// the token itself.
+func (pc *Client) CreateStackOutputToken(ctx context.Context, stack StackIdentifier,
+	description string) (string, string, error) {
+
+	req := apitype.CreateStackOutputTokenRequest{Description: description}
+	var resp apitype.CreateStackOutputTokenResponse
+	if err := pc.restCall(ctx, "POST", getStackPath(stack, "outputtokens"), nil, &req, &resp); err != nil {
+		return "", "", err
+	}
+	return resp.ID, resp.Token, nil
+}
+
+// DeleteStackOutputToken revokes the indicated output token of the indicated stack.
+func (pc *Client) DeleteStackOutputToken(ctx context.Context, stack StackIdentifier, id string) error {
+	return pc.restCall(ctx, "DELETE", getStackPath(stack, "outputtokens", url.PathEscape(id)), nil, nil, nil)
+}
+
+// GetStackOutputs returns the outputs of the indicated stack.  Unlike the stack's deployment, these may be read with
+// one of the stack's output tokens.
+func (pc *Client) GetStackOutputs(ctx context.Context, stack StackIdentifier) (map[string]interface{}, error) {
+	var resp apitype.GetStackOutputsResponse
+	if err := pc.restCall(ctx, "GET", getStackPath(stack, "outputs"), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Outputs, nil
+}
+
 // GetStackLogs retrieves the log entries for the indicated stack that match the given query.
 func (pc *Client) GetStackLogs(ctx context.Context, stack StackIdentifier,
 	logQuery operations.LogQuery) ([]operations.LogEntry, error) {
@@ -289,14 +424,51 @@ func (pc *Client) GetStackLogs(ctx context.Context, stack StackIdentifier,
 	return logs, nil
 }
 
-// GetStackUpdates returns all updates to the indicated stack.
+// GetStackUpdates returns all updates to the indicated stack, fetching them a page at a time.
 func (pc *Client) GetStackUpdates(ctx context.Context, stack StackIdentifier) ([]apitype.UpdateInfo, error) {
+	var updates []apitype.UpdateInfo
+	var continuationToken string
+	for {
+		response, err := pc.ListStackUpdates(ctx, stack, continuationToken, 0)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, response.Updates...)
+		if response.ContinuationToken == "" || response.ContinuationToken == continuationToken {
+			return updates, nil
+		}
+		continuationToken = response.ContinuationToken
+	}
+}
+
+// ListStackUpdates returns a page of the updates to the indicated stack, newest first.  An empty continuation token
+// fetches the first page, and a page size of zero uses the service's default.
+func (pc *Client) ListStackUpdates(ctx context.Context, stack StackIdentifier, continuationToken string,
+	pageSize int) (apitype.GetHistoryResponse, error) {
+
+	query := struct {
+		ContinuationToken string `url:"continuationToken,omitempty"`
+		PageSize          int    `url:"pageSize,omitempty"`
+	}{ContinuationToken: continuationToken, PageSize: pageSize}
+
 	var response apitype.GetHistoryResponse
-	if err := pc.restCall(ctx, "GET", getStackPath(stack, "updates"), nil, nil, &response); err != nil {
-		return nil, err
+	if err := pc.restCall(ctx, "GET", getStackPath(stack, "updates"), query, nil, &response); err != nil {
+		return apitype.GetHistoryResponse{}, err
 	}
+	return response, nil
+}
+
+// GetStackUpdate returns the indicated version of the given stack's update history.
+func (pc *Client) GetStackUpdate(ctx context.Context, stack StackIdentifier,
+	version int) (apitype.UpdateInfo, error) {
 
-	return response.Updates, nil
+	var update apitype.UpdateInfo
+	if err := pc.restCall(ctx, "GET", getStackPath(stack, "updates", strconv.Itoa(version)), nil, nil,
+		&update); err != nil {
+		return apitype.UpdateInfo{}, err
+	}
+
+	return update, nil
 }
 
 // ExportStackDeployment exports the indicated stack's deployment as a raw JSON message.
@@ -494,18 +666,14 @@ func (pc *Client) InvalidateUpdateCheckpoint(ctx context.Context, update UpdateI
 		updateAccessToken(token), httpCallOptions{RetryAllMethods: true})
 }
 
-// PatchUpdateCheckpoint patches the checkpoint for the indicated update with the given contents.
-func (pc *Client) PatchUpdateCheckpoint(ctx context.Context, update UpdateIdentifier, deployment *apitype.DeploymentV2,
+// PatchUpdateCheckpoint patches the checkpoint for the indicated update with the given contents, which must be a
+// JSON-encoded apitype.DeploymentV2.
+func (pc *Client) PatchUpdateCheckpoint(ctx context.Context, update UpdateIdentifier, deployment json.RawMessage,
 	token string) error {
 
-	rawDeployment, err := json.Marshal(deployment)
-	if err != nil {
-		return err
-	}
-
 	req := apitype.PatchUpdateCheckpointRequest{
 		Version:    2,
-		Deployment: rawDeployment,
+		Deployment: deployment,
 	}
 
 	// It is safe to retry this PATCH operation, because it is logically idempotent, since we send the entire
@@ -522,14 +690,10 @@ func (pc *Client) CancelUpdate(ctx context.Context, update UpdateIdentifier) err
 		httpCallOptions{RetryAllMethods: true})
 }
 
-// CompleteUpdate completes the indicated update with the given status.
-func (pc *Client) CompleteUpdate(ctx context.Context, update UpdateIdentifier, status apitype.UpdateStatus,
+// CompleteUpdate completes the indicated update with the given status and results.
+func (pc *Client) CompleteUpdate(ctx context.Context, update UpdateIdentifier, req apitype.CompleteUpdateRequest,
 	token string) error {
 
-	req := apitype.CompleteUpdateRequest{
-		Status: status,
-	}
-
 	// It is safe to retry this PATCH operation, because it is logically idempotent.
 	return pc.updateRESTCall(ctx, "POST", getUpdatePath(update, "complete"), nil, req, nil,
 		updateAccessToken(token), httpCallOptions{RetryAllMethods: true})
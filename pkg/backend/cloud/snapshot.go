@@ -15,7 +15,9 @@
 package cloud
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/cloud/client"
@@ -45,8 +47,12 @@ func (persister *cloudSnapshotPersister) Save(snapshot *deploy.Snapshot) error {
 	if err != nil {
 		return err
 	}
-	deployment := stack.SerializeDeployment(snapshot)
-	return persister.backend.client.PatchUpdateCheckpoint(persister.context, persister.update, deployment, token)
+	var deployment bytes.Buffer
+	if err = stack.WriteDeployment(&deployment, snapshot, ""); err != nil {
+		return err
+	}
+	return persister.backend.client.PatchUpdateCheckpoint(persister.context, persister.update,
+		json.RawMessage(deployment.Bytes()), token)
 }
 
 var _ backend.SnapshotPersister = (*cloudSnapshotPersister)(nil)
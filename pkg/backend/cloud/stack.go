@@ -17,12 +17,15 @@ package cloud
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
 
 	"github.com/pkg/errors"
 	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/operations"
+	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/tokens"
@@ -47,6 +50,8 @@ type cloudStack struct {
 	b        *cloudBackend          // a pointer to the backend this stack belongs to.
 }
 
+var _ backend.ConsoleLinkedStack = (*cloudStack)(nil)
+
 type cloudBackendReference struct {
 	name  tokens.QName
 	owner string
@@ -143,13 +148,31 @@ func (s *cloudStack) ImportDeployment(ctx context.Context, deployment *apitype.U
 }
 
 func (s *cloudStack) ConsoleURL() (string, error) {
+	return s.consoleURL()
+}
+
+func (s *cloudStack) UpdateConsoleURL(version int) (string, error) {
+	return s.consoleURL("updates", strconv.Itoa(version))
+}
+
+func (s *cloudStack) ResourceConsoleURL(urn resource.URN) (string, error) {
+	// URNs may contain slashes, so the URN is escaped as a single path element rather than joined to the path.
+	base, err := s.consoleURL("resources")
+	if err != nil {
+		return "", err
+	}
+	return base + "/" + url.PathEscape(string(urn)), nil
+}
+
+// consoleURL returns the URL of the page at the given path beneath the stack's page in the cloud console.
+func (s *cloudStack) consoleURL(paths ...string) (string, error) {
 	path, err := s.b.StackConsoleURL(s.Name())
 	if err != nil {
-		return "", nil
+		return "", err
 	}
-	url := s.b.CloudConsoleURL(path)
-	if url == "" {
+	u := s.b.CloudConsoleURL(append([]string{path}, paths...)...)
+	if u == "" {
 		return "", errors.New("could not determine clould console URL")
 	}
-	return url, nil
+	return u, nil
 }
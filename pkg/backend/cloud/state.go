@@ -126,14 +126,14 @@ func (u *cloudUpdate) GetTarget() *deploy.Target {
 	return u.target
 }
 
-func (u *cloudUpdate) Complete(status apitype.UpdateStatus) error {
+func (u *cloudUpdate) Complete(req apitype.CompleteUpdateRequest) error {
 	defer u.tokenSource.Close()
 
 	token, err := u.tokenSource.GetToken()
 	if err != nil {
 		return err
 	}
-	return u.backend.client.CompleteUpdate(u.context, u.update, status, token)
+	return u.backend.client.CompleteUpdate(u.context, u.update, req, token)
 }
 
 func (u *cloudUpdate) recordEvent(
@@ -255,11 +255,19 @@ func (b *cloudBackend) getTarget(ctx context.Context, stackRef backend.StackRefe
 	if err != nil {
 		return nil, err
 	}
+	proj, err := workspace.DetectProject()
+	if err != nil {
+		return nil, err
+	}
 
-	decrypter, err := b.GetStackCrypter(stackRef)
+	crypter, err := b.GetStackCrypter(stackRef)
 	if err != nil {
 		return nil, err
 	}
+	envs, err := backend.ResolveEnvironments(stk.Environments, stk.Config, crypter)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving the stack's environments")
+	}
 	snapshot, err := b.getSnapshot(ctx, stackRef)
 	if err != nil {
 		switch err {
@@ -275,9 +283,15 @@ func (b *cloudBackend) getTarget(ctx context.Context, stackRef backend.StackRefe
 	}
 
 	return &deploy.Target{
-		Name:      stackRef.StackName(),
-		Config:    stk.Config,
-		Decrypter: decrypter,
-		Snapshot:  snapshot,
+		Name:               stackRef.StackName(),
+		Config:             proj.StackConfig(envs.Config),
+		Decrypter:          envs.Decrypter,
+		EnvVars:            envs.EnvVars,
+		Snapshot:           snapshot,
+		AutoNaming:         stk.AutoNaming,
+		Guard:              stk.Guard,
+		CredentialProfiles: stk.CredentialProfiles,
+		SuppressWarnings:   stk.SuppressWarnings,
+		SecretPaths:        stk.SecretPaths,
 	}, nil
 }
@@ -0,0 +1,158 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// fetchedCredentials are the short-lived credentials fetched for an environment, along with where they are given to
+// the operation.
+type fetchedCredentials struct {
+	EnvVars map[string]string
+	Config  map[config.Key]string
+}
+
+// runCredentialsCommand runs a cloud provider's CLI to fetch credentials and returns what it prints.  It is a variable
+// so that tests may fake the CLIs.
+var runCredentialsCommand = func(name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...) // nolint: gas
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "running %s: %s", name, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// fetchCredentials fetches the given short-lived credentials.
+func fetchCredentials(c workspace.EnvironmentCredentials) (*fetchedCredentials, error) {
+	switch {
+	case c.AWS != nil:
+		creds, err := fetchAWSCredentials(c.AWS)
+		return creds, errors.Wrapf(err, "assuming AWS role %s", c.AWS.RoleARN)
+	case c.GCP != nil:
+		creds, err := fetchGCPCredentials(c.GCP)
+		return creds, errors.Wrapf(err, "impersonating GCP service account %s", c.GCP.ServiceAccount)
+	case c.Vault != nil:
+		creds, err := fetchVaultCredentials(c.Vault)
+		return creds, errors.Wrapf(err, "reading Vault path %s", c.Vault.Path)
+	default:
+		return nil, errors.New("no credentials were given")
+	}
+}
+
+func fetchAWSCredentials(c *workspace.AWSCredentials) (*fetchedCredentials, error) {
+	sessionName := c.SessionName
+	if sessionName == "" {
+		sessionName = "pulumi"
+	}
+	args := []string{"sts", "assume-role", "--role-arn", c.RoleARN, "--role-session-name", sessionName,
+		"--output", "json"}
+	if c.ExternalID != "" {
+		args = append(args, "--external-id", c.ExternalID)
+	}
+	if c.Duration != "" {
+		d, err := time.ParseDuration(c.Duration)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid duration '%s'", c.Duration)
+		}
+		args = append(args, "--duration-seconds", strconv.Itoa(int(d.Seconds())))
+	}
+
+	out, err := runCredentialsCommand("aws", args...)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Credentials struct {
+			AccessKeyID     string `json:"AccessKeyId"`
+			SecretAccessKey string `json:"SecretAccessKey"`
+			SessionToken    string `json:"SessionToken"`
+		} `json:"Credentials"`
+	}
+	if err = json.Unmarshal(out, &resp); err != nil {
+		return nil, errors.Wrap(err, "parsing the credentials")
+	}
+	if resp.Credentials.AccessKeyID == "" {
+		return nil, errors.New("no credentials were returned")
+	}
+	return &fetchedCredentials{EnvVars: map[string]string{
+		"AWS_ACCESS_KEY_ID":     resp.Credentials.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY": resp.Credentials.SecretAccessKey,
+		"AWS_SESSION_TOKEN":     resp.Credentials.SessionToken,
+	}}, nil
+}
+
+func fetchGCPCredentials(c *workspace.GCPCredentials) (*fetchedCredentials, error) {
+	out, err := runCredentialsCommand("gcloud", "auth", "print-access-token",
+		"--impersonate-service-account="+c.ServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return nil, errors.New("no access token was returned")
+	}
+	return &fetchedCredentials{EnvVars: map[string]string{"GOOGLE_OAUTH_ACCESS_TOKEN": token}}, nil
+}
+
+func fetchVaultCredentials(c *workspace.VaultCredentials) (*fetchedCredentials, error) {
+	out, err := runCredentialsCommand("vault", "read", "-format=json", c.Path)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err = json.Unmarshal(out, &resp); err != nil {
+		return nil, errors.Wrap(err, "parsing the secret")
+	}
+
+	field := func(name string) (string, error) {
+		v, has := resp.Data[name]
+		if !has {
+			return "", errors.Errorf("the secret has no field '%s'", name)
+		}
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprintf("%v", v), nil
+	}
+
+	creds := &fetchedCredentials{EnvVars: make(map[string]string), Config: make(map[config.Key]string)}
+	for name, f := range c.EnvVars {
+		if creds.EnvVars[name], err = field(f); err != nil {
+			return nil, err
+		}
+	}
+	for key, f := range c.Config {
+		if creds.Config[key], err = field(f); err != nil {
+			return nil, err
+		}
+	}
+	return creds, nil
+}
@@ -14,16 +14,36 @@
 
 package backend
 
-import "github.com/pulumi/pulumi/pkg/diag/colors"
+import (
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+)
+
+// DefaultSlowStepThreshold is how long a resource operation may run before the display flags it as slow.
+const DefaultSlowStepThreshold = 10 * time.Minute
 
 // DisplayOptions controls how the output of events are rendered
 type DisplayOptions struct {
 	Color                colors.Colorization // colorization to apply to events.
 	ShowConfig           bool                // true if we should show configuration information.
+	ShowReads            bool                // true to show the resources that are read, alongside those managed.
 	ShowReplacementSteps bool                // true to show the replacement steps in the plan.
 	ShowSameResources    bool                // true to show the resources that aren't updated in addition to updates.
+	SuppressSames        bool                // true to show only a count of unchanged resources in progress output.
+	NoDedupe             bool                // true to show every warning, rather than aggregating repeated ones.
 	SummaryDiff          bool                // If the diff display should be summarized
 	IsInteractive        bool                // If we should display things interactively
 	DiffDisplay          bool                // true if we should display things as a rich diff
 	Debug                bool
+
+	// SlowStepThreshold is how long a resource operation may run before it is flagged with a warning; 0 disables this.
+	SlowStepThreshold time.Duration
+
+	// StackConsumers lists the outputs of the stack that each stack which references it reads, so that a summary can
+	// show which of them a change to the stack's outputs affects.
+	StackConsumers map[string][]string
+
+	// EventLog, if non-nil, is the log to which engine events are written as they are displayed.
+	EventLog *EventLog
 }
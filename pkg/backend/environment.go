@@ -0,0 +1,160 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// environmentSecretPrefix begins the placeholders that stand in for the secret values of environments in a stack's
+// configuration, which the decrypter returned by ResolveEnvironments replaces with the values themselves.
+const environmentSecretPrefix = "environment-secret:"
+
+// ResolvedEnvironments is the configuration of a stack once the environments attached to it have been resolved.
+type ResolvedEnvironments struct {
+	// Config is the stack's configuration, including the values of its environments that it does not override.
+	Config config.Map
+	// Decrypter decrypts the secret values of Config.  Because the secret values of environments are read when they
+	// are used rather than stored, they are represented in Config by placeholders that only Decrypter resolves.
+	Decrypter config.Decrypter
+	// EnvVars are the environment variables, such as those holding short-lived credentials, that the environments
+	// give to the stack's program and providers.  They are only passed to the processes of those plugins, and are
+	// never set in the current process or written anywhere else.
+	EnvVars map[string]string
+}
+
+// ResolveEnvironments reads the values of the named environments, and fetches their credentials, and adds them to the
+// given stack configuration, which overrides them.  Ciphertext other than the placeholders for the secrets of the
+// environments is decrypted by the given decrypter.
+func ResolveEnvironments(names []string, cfg config.Map, decrypter config.Decrypter) (*ResolvedEnvironments, error) {
+	if len(names) == 0 {
+		return &ResolvedEnvironments{Config: cfg, Decrypter: decrypter}, nil
+	}
+
+	env, err := workspace.FlattenEnvironments(names)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(config.Map, len(env.Values)+len(cfg))
+	secrets := make(map[string]string)
+	addSecret := func(key config.Key, s string) {
+		placeholder := environmentSecretPrefix + key.String()
+		secrets[placeholder] = s
+		result[key] = config.NewSecureValue(placeholder)
+	}
+	for key, v := range env.Values {
+		if _, has := cfg[key]; has {
+			continue
+		}
+		s, err := readEnvironmentValue(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading the value of %s", key)
+		}
+		if v.Secret {
+			addSecret(key, s)
+		} else {
+			result[key] = config.NewValue(s)
+		}
+	}
+
+	envVars := make(map[string]string)
+	for _, c := range env.Credentials {
+		creds, err := fetchCredentials(c)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range creds.EnvVars {
+			envVars[name] = value
+		}
+		for key, value := range creds.Config {
+			if _, has := cfg[key]; !has {
+				addSecret(key, value)
+			}
+		}
+	}
+
+	for key, v := range cfg {
+		result[key] = v
+	}
+
+	return &ResolvedEnvironments{
+		Config:    result,
+		Decrypter: &environmentDecrypter{secrets: secrets, decrypter: decrypter},
+		EnvVars:   envVars,
+	}, nil
+}
+
+// readEnvironmentValue returns an environment's value, reading it from its source if it has one.  A single trailing
+// newline, which files and the output of commands commonly end with, is removed.
+func readEnvironmentValue(v workspace.EnvironmentValue) (string, error) {
+	switch {
+	case v.FromEnv != "":
+		s, has := os.LookupEnv(v.FromEnv)
+		if !has {
+			return "", errors.Errorf("the environment variable %s is not set", v.FromEnv)
+		}
+		return s, nil
+	case v.FromFile != "":
+		b, err := ioutil.ReadFile(v.FromFile)
+		if err != nil {
+			return "", err
+		}
+		return trimNewline(string(b)), nil
+	case v.FromCommand != "":
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", v.FromCommand) // nolint: gas
+		} else {
+			cmd = exec.Command("sh", "-c", v.FromCommand) // nolint: gas
+		}
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		if err := cmd.Run(); err != nil {
+			return "", errors.Wrapf(err, "running `%s`: %s", v.FromCommand, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return trimNewline(stdout.String()), nil
+	default:
+		return v.Value, nil
+	}
+}
+
+func trimNewline(s string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(s, "\n"), "\r")
+}
+
+// environmentDecrypter resolves the placeholders for the secret values of environments, and decrypts any other
+// ciphertext with the stack's own decrypter.
+type environmentDecrypter struct {
+	secrets   map[string]string
+	decrypter config.Decrypter
+}
+
+func (d *environmentDecrypter) DecryptValue(ciphertext string) (string, error) {
+	if s, has := d.secrets[ciphertext]; has {
+		return s, nil
+	}
+	return d.decrypter.DecryptValue(ciphertext)
+}
@@ -0,0 +1,128 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestResolveEnvironments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-env-")
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+	defer os.Setenv(workspace.EnvironmentPathEnvVar, os.Getenv(workspace.EnvironmentPathEnvVar))
+	assert.NoError(t, os.Setenv(workspace.EnvironmentPathEnvVar, dir))
+	defer os.Unsetenv("TEST_ENV_PASSWORD")
+	assert.NoError(t, os.Setenv("TEST_ENV_PASSWORD", "hunter2"))
+
+	region, zone, password := config.MustMakeKey("aws", "region"), config.MustMakeKey("aws", "zone"),
+		config.MustMakeKey("app", "password")
+	assert.NoError(t, workspace.SaveEnvironment("dev", &workspace.Environment{
+		Values: map[config.Key]workspace.EnvironmentValue{
+			region:   {Value: "us-west-2"},
+			zone:     {Value: "a"},
+			password: {Secret: true, FromEnv: "TEST_ENV_PASSWORD"},
+		},
+	}))
+
+	// The stack's own configuration overrides the environment's, and its secrets are still decrypted by its own
+	// decrypter.
+	stackCfg := config.Map{
+		zone:                               config.NewValue("b"),
+		config.MustMakeKey("app", "token"): config.NewSecureValue("token"),
+	}
+	envs, err := ResolveEnvironments([]string{"dev"}, stackCfg, config.NopDecrypter)
+	assert.NoError(t, err)
+	assert.Len(t, envs.Config, 4)
+	assert.Empty(t, envs.EnvVars)
+
+	decrypted, err := envs.Config.Decrypt(envs.Decrypter)
+	assert.NoError(t, err)
+	assert.Equal(t, "us-west-2", decrypted[region])
+	assert.Equal(t, "b", decrypted[zone])
+	assert.Equal(t, "hunter2", decrypted[password])
+	assert.Equal(t, "token", decrypted[config.MustMakeKey("app", "token")])
+	assert.True(t, envs.Config[password].Secure())
+
+	// A secret that cannot be read fails the resolution.
+	assert.NoError(t, os.Unsetenv("TEST_ENV_PASSWORD"))
+	_, err = ResolveEnvironments([]string{"dev"}, config.Map{}, config.NopDecrypter)
+	assert.Error(t, err)
+}
+
+func TestResolveEnvironmentCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-env-")
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+	defer os.Setenv(workspace.EnvironmentPathEnvVar, os.Getenv(workspace.EnvironmentPathEnvVar))
+	assert.NoError(t, os.Setenv(workspace.EnvironmentPathEnvVar, dir))
+
+	// Fake the cloud providers' CLIs.
+	var commands []string
+	defer func(run func(string, ...string) ([]byte, error)) { runCredentialsCommand = run }(runCredentialsCommand)
+	runCredentialsCommand = func(name string, args ...string) ([]byte, error) {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		switch name {
+		case "aws":
+			return []byte(`{"Credentials": {"AccessKeyId": "AKID", "SecretAccessKey": "SECRET", ` +
+				`"SessionToken": "TOKEN"}}`), nil
+		case "vault":
+			return []byte(`{"lease_id": "db/creds/app/1", "data": {"username": "app-1", "password": "pw"}}`), nil
+		default:
+			return nil, errors.Errorf("unexpected command %s", name)
+		}
+	}
+
+	password := config.MustMakeKey("app", "dbPassword")
+	assert.NoError(t, workspace.SaveEnvironment("creds", &workspace.Environment{
+		Credentials: []workspace.EnvironmentCredentials{
+			{AWS: &workspace.AWSCredentials{RoleARN: "arn:aws:iam::1:role/deploy", Duration: "1h"}},
+			{Vault: &workspace.VaultCredentials{
+				Path:    "db/creds/app",
+				EnvVars: map[string]string{"DB_USER": "username"},
+				Config:  map[config.Key]string{password: "password"},
+			}},
+		},
+	}))
+
+	envs, err := ResolveEnvironments([]string{"creds"}, config.Map{}, config.NopDecrypter)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"aws sts assume-role --role-arn arn:aws:iam::1:role/deploy --role-session-name pulumi --output json " +
+			"--duration-seconds 3600",
+		"vault read -format=json db/creds/app",
+	}, commands)
+	assert.Equal(t, map[string]string{
+		"AWS_ACCESS_KEY_ID":     "AKID",
+		"AWS_SECRET_ACCESS_KEY": "SECRET",
+		"AWS_SESSION_TOKEN":     "TOKEN",
+		"DB_USER":               "app-1",
+	}, envs.EnvVars)
+
+	// Credentials given as configuration are secrets.
+	assert.True(t, envs.Config[password].Secure())
+	v, err := envs.Config[password].Value(envs.Decrypter)
+	assert.NoError(t, err)
+	assert.Equal(t, "pw", v)
+}
@@ -0,0 +1,242 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// ConvertEngineEvent converts an engine event into its serialized form.  The values of secrets in the event's
+// properties are always redacted, even if the update was asked to show them.
+func ConvertEngineEvent(e engine.Event) (apitype.EngineEvent, error) {
+	apiEvent := apitype.EngineEvent{Type: string(e.Type)}
+
+	switch e.Type {
+	case engine.CancelEvent:
+		apiEvent.Cancel = &apitype.CancelEvent{}
+	case engine.StdoutColorEvent:
+		p, ok := e.Payload.(engine.StdoutEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch(e)
+		}
+		apiEvent.Stdout = &apitype.StdoutEngineEvent{
+			Message: p.Message,
+			Color:   string(p.Color),
+		}
+	case engine.DiagEvent:
+		p, ok := e.Payload.(engine.DiagEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch(e)
+		}
+		apiEvent.Diagnostic = &apitype.DiagnosticEvent{
+			URN:       p.URN,
+			Code:      p.ID.Code(),
+			Prefix:    p.Prefix,
+			Message:   p.Message,
+			Color:     string(p.Color),
+			Severity:  string(p.Severity),
+			StreamID:  p.StreamID,
+			Ephemeral: p.Ephemeral,
+		}
+	case engine.PreludeEvent:
+		p, ok := e.Payload.(engine.PreludeEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch(e)
+		}
+		apiEvent.Prelude = &apitype.PreludeEvent{
+			IsPreview: p.IsPreview,
+			Config:    p.Config,
+		}
+	case engine.SummaryEvent:
+		p, ok := e.Payload.(engine.SummaryEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch(e)
+		}
+		apiEvent.Summary = &apitype.SummaryEvent{
+			IsPreview:       p.IsPreview,
+			MaybeCorrupt:    p.MaybeCorrupt,
+			DurationSeconds: int(p.Duration.Seconds()),
+			ResourceChanges: convertResourceChanges(p.ResourceChanges),
+			RefreshChanges:  convertResourceChanges(p.RefreshChanges),
+			OutputChanges:   p.OutputChanges,
+		}
+	case engine.ResourcePreEvent:
+		p, ok := e.Payload.(engine.ResourcePreEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch(e)
+		}
+		apiEvent.ResourcePre = &apitype.ResourcePreEvent{
+			Metadata: convertStepEventMetadata(p.Metadata),
+			Planning: p.Planning,
+		}
+	case engine.ResourceOutputsEvent:
+		p, ok := e.Payload.(engine.ResourceOutputsEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch(e)
+		}
+		apiEvent.ResourceOutputs = &apitype.ResourceOutputsEvent{
+			Metadata: convertStepEventMetadata(p.Metadata),
+			Planning: p.Planning,
+		}
+	case engine.ResourceOperationFailed:
+		p, ok := e.Payload.(engine.ResourceOperationFailedPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch(e)
+		}
+		apiEvent.ResourceOperationFailed = &apitype.ResourceOperationFailedEvent{
+			Metadata: convertStepEventMetadata(p.Metadata),
+			Status:   int(p.Status),
+			Steps:    p.Steps,
+		}
+	default:
+		return apiEvent, errors.Errorf("unknown event type %q", e.Type)
+	}
+
+	return apiEvent, nil
+}
+
+func eventTypePayloadMismatch(e engine.Event) error {
+	return errors.Errorf("unexpected payload of type %T for event type %q", e.Payload, e.Type)
+}
+
+func convertResourceChanges(changes engine.ResourceChanges) map[string]int {
+	if changes == nil {
+		return nil
+	}
+	result := make(map[string]int, len(changes))
+	for op, count := range changes {
+		result[string(op)] = count
+	}
+	return result
+}
+
+func convertStepEventMetadata(md engine.StepEventMetadata) apitype.StepEventMetadata {
+	var keys []string
+	for _, k := range md.Keys {
+		keys = append(keys, string(k))
+	}
+	var reasons map[string]string
+	if len(md.Reasons) > 0 {
+		reasons = make(map[string]string, len(md.Reasons))
+		for k, reason := range md.Reasons {
+			reasons[string(k)] = reason
+		}
+	}
+
+	return apitype.StepEventMetadata{
+		Op:       string(md.Op),
+		URN:      md.URN,
+		Type:     md.Type,
+		Old:      convertStepEventStateMetadata(md.Old),
+		New:      convertStepEventStateMetadata(md.New),
+		Keys:     keys,
+		Reasons:  reasons,
+		Logical:  md.Logical,
+		Provider: md.Provider,
+
+		DeleteBeforeReplace: md.DeleteBeforeReplace,
+		Dependents:          md.Dependents,
+	}
+}
+
+func convertStepEventStateMetadata(md *engine.StepEventStateMetadata) *apitype.StepEventStateMetadata {
+	if md == nil {
+		return nil
+	}
+
+	return &apitype.StepEventStateMetadata{
+		Type:       md.Type,
+		URN:        md.URN,
+		Custom:     md.Custom,
+		Delete:     md.Delete,
+		ID:         md.ID,
+		Parent:     md.Parent,
+		Protect:    md.Protect,
+		Provider:   md.Provider,
+		Inputs:     stack.SerializeProperties(md.Inputs),
+		Outputs:    stack.SerializeProperties(md.Outputs),
+		InitErrors: md.InitErrors,
+	}
+}
+
+// EventLog writes engine events, one JSON object per line, to a file.
+type EventLog struct {
+	w        io.WriteCloser
+	enc      *json.Encoder
+	sequence int
+}
+
+// NewEventLog creates (or truncates) the file at the given path and returns an event log that writes to it.
+func NewEventLog(path string) (*EventLog, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating event log")
+	}
+	return NewEventLogWriter(f), nil
+}
+
+// NewEventLogWriter returns an event log that writes to the given writer, which it closes when it is closed.
+func NewEventLogWriter(w io.WriteCloser) *EventLog {
+	contract.Require(w != nil, "w")
+	return &EventLog{w: w, enc: json.NewEncoder(w)}
+}
+
+// Write appends the given event to the log.
+func (l *EventLog) Write(e engine.Event) error {
+	contract.Require(l != nil, "l")
+
+	apiEvent, err := ConvertEngineEvent(e)
+	if err != nil {
+		return err
+	}
+	apiEvent.Sequence, apiEvent.Timestamp = l.sequence, time.Now().Unix()
+	l.sequence++
+
+	return errors.Wrap(l.enc.Encode(apiEvent), "writing event log")
+}
+
+// Close closes the event log's file.
+func (l *EventLog) Close() error {
+	return l.w.Close()
+}
+
+// Tee returns a channel that receives each event sent to the given one after it has been written to the log.  Failures
+// to write the log are logged rather than interrupting the operation that produces the events.
+func (l *EventLog) Tee(events <-chan engine.Event) <-chan engine.Event {
+	contract.Require(l != nil, "l")
+
+	out := make(chan engine.Event)
+	go func() {
+		defer close(out)
+		for e := range events {
+			if err := l.Write(e); err != nil {
+				logging.V(3).Infof("failed to write event log: %v", err)
+			}
+			out <- e
+		}
+	}()
+	return out
+}
@@ -0,0 +1,128 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func testResourcePreEvent() engine.Event {
+	old := newStackResource("prod", "a", resource.PropertyMap{"size": resource.NewNumberProperty(1)})
+	new := newStackResource("prod", "a", resource.PropertyMap{
+		"size": resource.NewNumberProperty(2),
+		"name": resource.NewStringProperty("a"),
+	})
+	return engine.Event{
+		Type: engine.ResourcePreEvent,
+		Payload: engine.ResourcePreEventPayload{
+			Metadata: engine.StepEventMetadata{
+				Op:      deploy.OpReplace,
+				URN:     new.URN,
+				Type:    new.Type,
+				Old:     engine.NewStepEventStateMetadata(old, false),
+				New:     engine.NewStepEventStateMetadata(new, false),
+				Keys:    []resource.PropertyKey{"size"},
+				Reasons: map[resource.PropertyKey]string{"size": "size cannot be changed"},
+			},
+			Planning: true,
+		},
+	}
+}
+
+func TestConvertEngineEvent(t *testing.T) {
+	e, err := ConvertEngineEvent(testResourcePreEvent())
+	assert.NoError(t, err)
+	assert.Equal(t, "resource-pre", e.Type)
+	if assert.NotNil(t, e.ResourcePre) {
+		md := e.ResourcePre.Metadata
+		assert.True(t, e.ResourcePre.Planning)
+		assert.Equal(t, "replace", md.Op)
+		assert.Equal(t, []string{"size"}, md.Keys)
+		assert.Equal(t, map[string]string{"size": "size cannot be changed"}, md.Reasons)
+		if assert.NotNil(t, md.Old) && assert.NotNil(t, md.New) {
+			assert.Equal(t, map[string]interface{}{"size": float64(1)}, md.Old.Inputs)
+			assert.Equal(t, map[string]interface{}{"size": float64(2), "name": "a"}, md.New.Inputs)
+		}
+	}
+
+	e, err = ConvertEngineEvent(engine.Event{
+		Type: engine.SummaryEvent,
+		Payload: engine.SummaryEventPayload{
+			ResourceChanges: engine.ResourceChanges{deploy.OpSame: 2, deploy.OpUpdate: 1},
+		},
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, e.Summary) {
+		assert.Equal(t, map[string]int{"same": 2, "update": 1}, e.Summary.ResourceChanges)
+		assert.Nil(t, e.Summary.RefreshChanges)
+	}
+
+	_, err = ConvertEngineEvent(engine.Event{Type: engine.DiagEvent, Payload: "oops"})
+	assert.Error(t, err)
+}
+
+func TestEventLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventlog")
+	assert.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "events.json")
+	log, err := NewEventLog(path)
+	assert.NoError(t, err)
+
+	events := make(chan engine.Event)
+	out := log.Tee(events)
+	go func() {
+		events <- testResourcePreEvent()
+		events <- engine.Event{Type: engine.CancelEvent}
+		close(events)
+	}()
+	var types []engine.EventType
+	for e := range out {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []engine.EventType{engine.ResourcePreEvent, engine.CancelEvent}, types)
+	assert.NoError(t, log.Close())
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	var logged []apitype.EngineEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e apitype.EngineEvent
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		logged = append(logged, e)
+	}
+	if assert.Len(t, logged, 2) {
+		assert.Equal(t, 0, logged[0].Sequence)
+		assert.NotNil(t, logged[0].ResourcePre)
+		assert.Equal(t, 1, logged[1].Sequence)
+		assert.NotNil(t, logged[1].Cancel)
+	}
+}
@@ -15,14 +15,16 @@
 package local
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
-	"os/user"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -33,6 +35,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/encoding"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/operations"
+	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/resource/stack"
@@ -55,8 +58,19 @@ type localBackend struct {
 	d         diag.Sink
 	url       string
 	stateRoot string
+	store     stateStore
+
+	blobLock     sync.Mutex      // protects blobsWritten.
+	blobsWritten map[string]bool // the hashes of the blobs that are known to have been stored already.
 }
 
+var _ io.Closer = (*localBackend)(nil)
+var _ backend.StackAuditLogReader = (*localBackend)(nil)
+var _ backend.ProjectManager = (*localBackend)(nil)
+var _ backend.StackLister = (*localBackend)(nil)
+var _ backend.StackReferenceRecorder = (*localBackend)(nil)
+var _ backend.UpdateEventsReader = (*localBackend)(nil)
+
 type localBackendReference struct {
 	name tokens.QName
 }
@@ -71,27 +85,65 @@ func (r localBackendReference) StackName() tokens.QName {
 
 func stateRootFromLocalURL(localURL string) string {
 	if localURL == localBackendURLPrefix {
-		user, err := user.Current()
-		contract.AssertNoErrorf(err, "could not determine current user")
-		return filepath.Join(user.HomeDir, workspace.BookkeepingDir)
+		home, err := workspace.GetPulumiHomeDir()
+		contract.AssertNoErrorf(err, "could not determine the Pulumi home directory")
+		return home
 	}
 
 	return localURL[len(localBackendURLPrefix):]
 }
 
+// backendPluginScheme returns the scheme of a URL whose state is kept by a backend plugin, or "" if it is not such a
+// URL.  Every scheme other than local, http, and https is served by the backend plugin of the same name.
+func backendPluginScheme(url string) string {
+	i := strings.Index(url, "://")
+	if i <= 0 {
+		return ""
+	}
+	switch scheme := url[:i]; scheme {
+	case "local", "http", "https":
+		return ""
+	default:
+		return scheme
+	}
+}
+
+// IsLocalBackendURL returns true if the URL is served by a local backend, whether it keeps its state on disk or with a
+// backend plugin.
 func IsLocalBackendURL(url string) bool {
-	return strings.HasPrefix(url, localBackendURLPrefix)
+	return strings.HasPrefix(url, localBackendURLPrefix) || backendPluginScheme(url) != ""
 }
 
 func New(d diag.Sink, localURL string) Backend {
-	return &localBackend{d: d, url: localURL, stateRoot: stateRootFromLocalURL(localURL)}
+	if scheme := backendPluginScheme(localURL); scheme != "" {
+		return &localBackend{d: d, url: localURL, store: &pluginStore{d: d, scheme: scheme, url: localURL}}
+	}
+	return &localBackend{d: d, url: localURL, stateRoot: stateRootFromLocalURL(localURL), store: fileStore{}}
 }
 
 func Login(d diag.Sink, localURL string) (Backend, error) {
-	return New(d, localURL), workspace.StoreAccessToken(localURL, "", true)
+	b := New(d, localURL)
+
+	// Make sure that a backend plugin can be launched for the URL before remembering it.
+	if store, ok := b.(*localBackend).store.(*pluginStore); ok {
+		if _, err := store.backend(); err != nil {
+			return nil, errors.Wrapf(err, "could not log into %s", localURL)
+		}
+	}
+
+	return b, workspace.StoreAccessToken(localURL, "", true)
+}
+
+// Close shuts down the backend's state plugin, if it has launched one.
+func (b *localBackend) Close() error {
+	return b.store.Close()
 }
 
 func (b *localBackend) Name() string {
+	if backendPluginScheme(b.url) != "" {
+		return b.url
+	}
+
 	name, err := os.Hostname()
 	contract.IgnoreError(err)
 	if name == "" {
@@ -100,6 +152,18 @@ func (b *localBackend) Name() string {
 	return name
 }
 
+// The local backend keeps each stack's history, audit log, references, and the events of its latest update alongside
+// its checkpoint, but has no server to search, share, or cancel updates to its stacks.
+func (b *localBackend) Capabilities() backend.Capabilities {
+	return backend.Capabilities{
+		History:         true,
+		Projects:        true,
+		AuditLog:        true,
+		StackReferences: true,
+		UpdateEvents:    true,
+	}
+}
+
 func (b *localBackend) ParseStackReference(stackRefName string) (backend.StackReference, error) {
 	return localBackendReference{name: tokens.QName(stackRefName)}, nil
 }
@@ -170,9 +234,135 @@ func (b *localBackend) ListStacks(ctx context.Context, projectFilter *tokens.Pac
 	return results, nil
 }
 
+// localStackListingPageSize is the default number of stacks on each page of listings, which bounds the number of
+// checkpoints that must be read at once.
+const localStackListingPageSize = 100
+
+func (b *localBackend) ListStackListings(ctx context.Context, filter backend.StackFilter, continuationToken string,
+	pageSize int) (*apitype.ListStackListingsResponse, error) {
+
+	stacks, err := b.getLocalStacks()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(stacks, func(i, j int) bool { return stacks[i] < stacks[j] })
+	if pageSize <= 0 {
+		pageSize = localStackListingPageSize
+	}
+
+	// The continuation token is simply the name of the last stack on the previous page.
+	resp := &apitype.ListStackListingsResponse{Stacks: []apitype.StackListing{}}
+	for _, stackName := range stacks {
+		if continuationToken != "" && string(stackName) <= continuationToken {
+			continue
+		}
+		if len(resp.Stacks) == pageSize {
+			resp.ContinuationToken = resp.Stacks[len(resp.Stacks)-1].Name
+			break
+		}
+
+		_, snapshot, _, err := b.getStack(stackName)
+		if err != nil {
+			return nil, err
+		}
+
+		// Local stacks have no tags, and only record their project in the URNs of their resources.
+		var project tokens.PackageName
+		var lastUpdate time.Time
+		listing := apitype.StackListing{Name: string(stackName)}
+		if snapshot != nil {
+			if len(snapshot.Resources) > 0 {
+				project = snapshot.Resources[0].URN.Project()
+			}
+			if lastUpdate = snapshot.Manifest.Time; !lastUpdate.IsZero() {
+				listing.LastUpdate = lastUpdate.UTC().Format(time.RFC3339)
+			}
+			count := len(snapshot.Resources)
+			listing.ResourceCount = &count
+		}
+		if !filter.Matches(project, nil, lastUpdate) {
+			continue
+		}
+		resp.Stacks = append(resp.Stacks, listing)
+	}
+	return resp, nil
+}
+
+// Local stacks do not record their project, which is instead taken from the URNs of their resources.
+func (b *localBackend) ListProjects(ctx context.Context) (map[tokens.PackageName][]backend.StackReference, error) {
+	stacks, err := b.getLocalStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make(map[tokens.PackageName][]backend.StackReference)
+	for _, stackName := range stacks {
+		_, snapshot, _, err := b.getStack(stackName)
+		if err != nil {
+			return nil, err
+		}
+		project := backend.SnapshotProject(snapshot)
+		projects[project] = append(projects[project], localBackendReference{name: stackName})
+	}
+	return projects, nil
+}
+
+func (b *localBackend) RenameProject(ctx context.Context, oldName, newName tokens.PackageName) error {
+	projects, err := b.ListProjects(ctx)
+	if err != nil {
+		return err
+	}
+	refs := projects[oldName]
+	if oldName == "" || len(refs) == 0 {
+		return errors.Errorf("project '%s' has no stacks", oldName)
+	}
+
+	for _, ref := range refs {
+		config, snapshot, _, err := b.getStack(ref.StackName())
+		if err != nil {
+			return err
+		}
+		if err = backend.RenameSnapshotProject(snapshot, newName); err != nil {
+			return errors.Wrapf(err, "renaming the resources of stack '%s'", ref)
+		}
+		if _, err = b.saveStack(ref.StackName(), config, snapshot); err != nil {
+			return errors.Wrapf(err, "writing stack '%s'", ref)
+		}
+	}
+	return nil
+}
+
+func (b *localBackend) RemoveProject(ctx context.Context, name tokens.PackageName) error {
+	projects, err := b.ListProjects(ctx)
+	if err != nil {
+		return err
+	}
+	refs := projects[name]
+	if name == "" || len(refs) == 0 {
+		return errors.Errorf("project '%s' has no stacks", name)
+	}
+
+	// Check every stack before removing any, so that a project that is not empty is left untouched.
+	for _, ref := range refs {
+		_, snapshot, _, err := b.getStack(ref.StackName())
+		if err != nil {
+			return err
+		}
+		if !backend.SnapshotIsEmpty(snapshot) {
+			return errors.Errorf("project '%s' is not empty: stack '%s' still has resources", name, ref)
+		}
+	}
+	for _, ref := range refs {
+		if _, err = b.RemoveStack(ctx, ref, true); err != nil {
+			return errors.Wrapf(err, "removing stack '%s'", ref)
+		}
+	}
+	return nil
+}
+
 func (b *localBackend) RemoveStack(ctx context.Context, stackRef backend.StackReference, force bool) (bool, error) {
 	stackName := stackRef.StackName()
-	_, snapshot, _, err := b.getStack(stackName)
+	cfg, snapshot, _, err := b.getStack(stackName)
 	if err != nil {
 		return false, err
 	}
@@ -182,7 +372,12 @@ func (b *localBackend) RemoveStack(ctx context.Context, stackRef backend.StackRe
 		return true, errors.New("refusing to remove stack because it still contains resources")
 	}
 
-	return false, b.removeStack(stackName)
+	start := time.Now().Unix()
+	if err = b.removeStack(stackName); err != nil {
+		return false, err
+	}
+	entry := newAuditLogEntry("remove", start, cfg, backend.SucceededResult)
+	return false, errors.Wrap(b.appendAuditLog(stackName, entry), "writing audit log")
 }
 
 func (b *localBackend) GetStackCrypter(stackRef backend.StackReference) (config.Crypter, error) {
@@ -257,17 +452,53 @@ func (b *localBackend) performEngineOp(op string, kind apitype.UpdateKind,
 	events := make(chan engine.Event)
 	dryRun := (kind == apitype.PreviewUpdate)
 
+	// The resources that an update changes record the version of the stack that it produces, which is its position in
+	// the stack's history.
+	if !dryRun {
+		files, err := b.getHistoryFiles(stackName)
+		if err != nil {
+			return nil, err
+		}
+		opts.Engine.UpdateVersion = len(files) + 1
+	}
+
+	// If we were handed a proposed snapshot to preview against, use it in place of the stack's checkpoint.
+	if dryRun && opts.PreviewAgainst != nil {
+		update.target.Snapshot = opts.PreviewAgainst
+	}
+
 	cancelScope := scopes.NewScope(events, dryRun)
 	defer cancelScope.Close()
 
+	// Record the events of updates as they happen, so that their progress may be followed from other processes.
+	displayEvents := (<-chan engine.Event)(events)
+	if !dryRun {
+		eventLog, err := b.newUpdateEventLog(stackName)
+		if err != nil {
+			return nil, err
+		}
+		defer contract.IgnoreClose(eventLog)
+		displayEvents = eventLog.Tee(events)
+	}
+
 	done := make(chan bool)
-	go DisplayEvents(op, kind, events, done, opts.Display)
+	go DisplayEvents(op, kind, displayEvents, done, opts.Display)
 
 	// Create the management machinery.
 	persister := b.newSnapshotPersister(stackName)
 	manager := backend.NewSnapshotManager(persister, update.GetTarget().Snapshot)
 	engineCtx := &engine.Context{Cancel: cancelScope.Context(), Events: events, SnapshotManager: manager}
 
+	// Remember how long each resource operation took, so that they may be recorded in the stack's history.
+	var timings []engine.StepTiming
+	onStepTimings := opts.Engine.OnStepTimings
+	opts.Engine.OnStepTimings = func(t []engine.StepTiming) {
+		timings = t
+		if onStepTimings != nil {
+			onStepTimings(t)
+		}
+	}
+
 	// Perform the update
 	start := time.Now().Unix()
 	changes, updateErr := performEngineOp(update, engineCtx, opts.Engine, dryRun)
@@ -278,6 +509,9 @@ func (b *localBackend) performEngineOp(op string, kind apitype.UpdateKind,
 	close(done)
 	contract.IgnoreClose(manager)
 
+	// Record the versions of the plugins that the update loaded alongside those of the CLI.
+	backend.AddPluginsToEnvironment(manager.Plugins(), m.Environment)
+
 	// Save update results.
 	result := backend.SucceededResult
 	if updateErr != nil {
@@ -295,25 +529,50 @@ func (b *localBackend) performEngineOp(op string, kind apitype.UpdateKind,
 		//     rudely assume it knows where the checkpoint file is on disk as it makes a copy of it.  This isn't
 		//     trivial to achieve today given the event driven nature of plan-walking, however.
 		ResourceChanges: changes,
+		StepTimings:     timings,
 	}
 	var saveErr error
+	var auditErr error
 	var backupErr error
 	if !dryRun {
+		info.OutputChanges = b.outputChanges(stackName, update.GetTarget().Snapshot)
 		saveErr = b.addToHistory(stackName, info)
+		auditErr = b.appendAuditLog(stackName, newAuditLogEntry(string(kind), start, info.Config, result))
 		backupErr = b.backupStack(stackName)
 	}
 
 	if updateErr != nil {
-		// We swallow saveErr and backupErr as they are less important than the updateErr.
+		// We swallow saveErr, auditErr, and backupErr as they are less important than the updateErr.
 		return changes, updateErr
 	}
 	if saveErr != nil {
-		// We swallow backupErr as it is less important than the saveErr.
+		// We swallow auditErr and backupErr as they are less important than the saveErr.
 		return changes, errors.Wrap(saveErr, "saving update info")
 	}
+	if auditErr != nil {
+		return changes, errors.Wrap(auditErr, "writing audit log")
+	}
 	return changes, errors.Wrap(backupErr, "saving backup")
 }
 
+// outputChanges returns the names of the stack outputs that differ between the given snapshot, taken before an update,
+// and the stack's current checkpoint.
+func (b *localBackend) outputChanges(stackName tokens.QName, before *deploy.Snapshot) []string {
+	_, after, _, err := b.getStack(stackName)
+	if err != nil {
+		return nil
+	}
+
+	var olds, news resource.PropertyMap
+	if res, _ := stack.GetRootStackResource(before); res != nil {
+		olds = res.Outputs()
+	}
+	if res, _ := stack.GetRootStackResource(after); res != nil {
+		news = res.Outputs()
+	}
+	return engine.StackOutputChanges(olds, news)
+}
+
 func (b *localBackend) GetHistory(ctx context.Context, stackRef backend.StackReference) ([]backend.UpdateInfo, error) {
 	stackName := stackRef.StackName()
 	updates, err := b.getHistory(stackName)
@@ -323,6 +582,30 @@ func (b *localBackend) GetHistory(ctx context.Context, stackRef backend.StackRef
 	return updates, nil
 }
 
+func (b *localBackend) GetHistoricalDeployment(ctx context.Context, stackRef backend.StackReference,
+	version int) (*apitype.UntypedDeployment, error) {
+
+	chk, err := b.getHistoricalCheckpoint(stackRef.StackName(), version)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment := chk.Latest
+	if deployment == nil {
+		deployment = stack.SerializeDeployment(deploy.NewSnapshot(deploy.Manifest{}, nil, nil))
+	}
+
+	data, err := json.Marshal(deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apitype.UntypedDeployment{
+		Version:    2,
+		Deployment: json.RawMessage(data),
+	}, nil
+}
+
 func (b *localBackend) GetLogs(ctx context.Context, stackRef backend.StackReference,
 	query operations.LogQuery) ([]operations.LogEntry, error) {
 
@@ -367,14 +650,14 @@ func (b *localBackend) ExportDeployment(ctx context.Context,
 		snap = deploy.NewSnapshot(deploy.Manifest{}, nil, nil)
 	}
 
-	data, err := json.Marshal(stack.SerializeDeployment(snap))
-	if err != nil {
+	var data bytes.Buffer
+	if err = stack.WriteDeployment(&data, snap, ""); err != nil {
 		return nil, err
 	}
 
 	return &apitype.UntypedDeployment{
 		Version:    2,
-		Deployment: json.RawMessage(data),
+		Deployment: json.RawMessage(data.Bytes()),
 	}, nil
 }
 
@@ -392,10 +675,54 @@ func (b *localBackend) ImportDeployment(ctx context.Context, stackRef backend.St
 		return err
 	}
 
+	start := time.Now().Unix()
+	result := backend.SucceededResult
 	_, err = b.saveStack(stackName, config, snap)
+	if err != nil {
+		result = backend.FailedResult
+	}
+	if auditErr := b.appendAuditLog(stackName, newAuditLogEntry("import", start, config, result)); err == nil {
+		err = errors.Wrap(auditErr, "writing audit log")
+	}
 	return err
 }
 
+func (b *localBackend) GetAuditLog(ctx context.Context,
+	stackRef backend.StackReference) ([]backend.AuditLogEntry, error) {
+
+	stackName := stackRef.StackName()
+	if _, _, _, err := b.getStack(stackName); err != nil {
+		return nil, err
+	}
+	return b.getAuditLog(stackName)
+}
+
+func (b *localBackend) GetLatestUpdateEvents(ctx context.Context,
+	stackRef backend.StackReference) ([]apitype.EngineEvent, bool, error) {
+
+	stackName := stackRef.StackName()
+	if _, _, _, err := b.getStack(stackName); err != nil {
+		return nil, false, err
+	}
+	return b.getUpdateEvents(stackName)
+}
+
+func (b *localBackend) RecordStackReferences(ctx context.Context, stackRef backend.StackReference,
+	refs map[string][]string) error {
+
+	stackName := stackRef.StackName()
+	if _, _, _, err := b.getStack(stackName); err != nil {
+		return err
+	}
+	return b.saveStackReferences(stackName, refs)
+}
+
+func (b *localBackend) GetStackConsumers(ctx context.Context,
+	stackRef backend.StackReference) (map[string][]string, error) {
+
+	return b.getStackConsumers(stackRef.StackName())
+}
+
 func (b *localBackend) Logout() error {
 	return workspace.DeleteAccessToken(b.url)
 }
@@ -410,19 +737,13 @@ func (b *localBackend) getLocalStacks() ([]tokens.QName, error) {
 	// Read the stack directory.
 	path := b.stackPath("")
 
-	files, err := ioutil.ReadDir(path)
+	files, err := b.store.ReadDir(path)
 	if err != nil && !os.IsNotExist(err) {
 		return nil, errors.Errorf("could not read stacks: %v", err)
 	}
 
-	for _, file := range files {
-		// Ignore directories.
-		if file.IsDir() {
-			continue
-		}
-
+	for _, stackfn := range files {
 		// Skip files without valid extensions (e.g., *.bak files).
-		stackfn := file.Name()
 		ext := filepath.Ext(stackfn)
 		if _, has := encoding.Marshalers[ext]; !has {
 			continue
@@ -432,7 +753,7 @@ func (b *localBackend) getLocalStacks() ([]tokens.QName, error) {
 		name := tokens.QName(stackfn[:len(stackfn)-len(ext)])
 		_, _, _, err := b.getStack(name)
 		if err != nil {
-			logging.V(5).Infof("error reading stack: %v (%v) skipping", name, err)
+			logging.Backend.V(5).Infof("error reading stack: %v (%v) skipping", name, err)
 			continue // failure reading the stack information.
 		}
 
@@ -0,0 +1,93 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// CheckpointBlobThresholdEnvVar is the environment variable that, when set to a number of bytes, stores each string
+// property value at least that long in a blob beside the stack's checkpoint, which refers to it by the hash of its
+// contents, instead of in the checkpoint itself.  This keeps checkpoints that hold large values, like kubeconfigs,
+// rendered templates, and certificates, small and their diffs readable.  Blobs are read back transparently, and are
+// kept for as long as the backend's state, since the checkpoints in stacks' histories may refer to them.
+const CheckpointBlobThresholdEnvVar = "PULUMI_CHECKPOINT_BLOB_THRESHOLD"
+
+// checkpointBlobThreshold returns the size at which property values are stored in blobs, or zero if they never are.
+func checkpointBlobThreshold() (int, error) {
+	v := os.Getenv(CheckpointBlobThresholdEnvVar)
+	if v == "" {
+		return 0, nil
+	}
+	threshold, err := strconv.Atoi(v)
+	if err != nil || threshold < 0 {
+		return 0, errors.Errorf("%s must be a number of bytes, not '%s'", CheckpointBlobThresholdEnvVar, v)
+	}
+	return threshold, nil
+}
+
+// localBlobStore keeps the blobs that a local backend's checkpoints refer to in the backend's state, beneath its
+// blobs directory.
+type localBlobStore struct {
+	b *localBackend
+}
+
+func (s localBlobStore) blobPath(hash string) (string, error) {
+	colon := strings.Index(hash, ":")
+	if colon <= 0 || strings.ContainsAny(hash[colon+1:], `/\.`) {
+		return "", errors.Errorf("invalid blob hash '%s'", hash)
+	}
+	return filepath.Join(s.b.stateRoot, workspace.BlobDir, hash[:colon], hash[colon+1:]), nil
+}
+
+func (s localBlobStore) ReadBlob(hash string) ([]byte, error) {
+	path, err := s.blobPath(hash)
+	if err != nil {
+		return nil, err
+	}
+	return s.b.store.ReadFile(path)
+}
+
+func (s localBlobStore) WriteBlob(hash string, data []byte) error {
+	// Blobs are immutable, so each need only be written once.
+	s.b.blobLock.Lock()
+	defer s.b.blobLock.Unlock()
+	if s.b.blobsWritten[hash] {
+		return nil
+	}
+
+	path, err := s.blobPath(hash)
+	if err != nil {
+		return err
+	}
+	if err = s.b.store.WriteFile(path, data); err != nil {
+		return err
+	}
+	if s.b.blobsWritten == nil {
+		s.b.blobsWritten = make(map[string]bool)
+	}
+	s.b.blobsWritten[hash] = true
+	return nil
+}
+
+var _ stack.BlobStore = localBlobStore{}
@@ -0,0 +1,94 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+)
+
+// dedupeSamples is the number of resources named when displaying a warning that was repeated.
+const dedupeSamples = 3
+
+// dedupeDiagEvents returns a channel carrying the given events, except that warnings that repeat one already seen are
+// held back.  Just before the update's summary, each warning that was repeated is displayed once more, along with how
+// many times it was repeated and some of the resources it was repeated for.
+func dedupeDiagEvents(events <-chan engine.Event) <-chan engine.Event {
+	out := make(chan engine.Event)
+	go func() {
+		deduper := diag.NewDeduper(dedupeSamples)
+		firsts := make(map[string]engine.DiagEventPayload)
+
+		flush := func() {
+			for _, agg := range deduper.Flush() {
+				first := firsts[agg.Message]
+				out <- engine.Event{
+					Type: engine.DiagEvent,
+					Payload: engine.DiagEventPayload{
+						ID:       first.ID,
+						Prefix:   first.Prefix,
+						Message:  repeatedMessage(agg),
+						Color:    first.Color,
+						Severity: agg.Severity,
+					},
+				}
+			}
+			firsts = make(map[string]engine.DiagEventPayload)
+		}
+
+		for e := range events {
+			switch e.Type {
+			case engine.DiagEvent:
+				payload := e.Payload.(engine.DiagEventPayload)
+				if payload.Severity == diag.Warning && !payload.Ephemeral {
+					if !deduper.Observe(payload.Severity, payload.URN, payload.Message) {
+						continue
+					}
+					firsts[payload.Message] = payload
+				}
+			case engine.SummaryEvent:
+				flush()
+			case engine.CancelEvent:
+				flush()
+				out <- e
+				return
+			}
+			out <- e
+		}
+		flush()
+		close(out)
+	}()
+	return out
+}
+
+// repeatedMessage returns the message for a warning that was repeated: the warning itself, followed by a note of how
+// many times it was repeated and for which resources.
+func repeatedMessage(agg diag.Aggregate) string {
+	note := fmt.Sprintf("(repeated %d more %s", agg.Repeats, plural("time", agg.Repeats))
+	if len(agg.URNs) > 0 {
+		var names []string
+		for _, urn := range agg.URNs {
+			names = append(names, fmt.Sprintf("'%s'", urn.Name()))
+		}
+		note += ", e.g. for " + strings.Join(names, ", ")
+	}
+	note += "; use --no-dedupe to see each)"
+
+	return strings.TrimSuffix(agg.Message, "\n") + " " + colors.SpecUnimportant + note + colors.Reset + "\n"
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pulumi/pulumi/pkg/apitype"
@@ -33,6 +34,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
 )
 
 // DisplayEvents reads events from the `events` channel until it is closed, displaying each event as
@@ -42,6 +44,14 @@ func DisplayEvents(
 	op string, action apitype.UpdateKind, events <-chan engine.Event,
 	done chan<- bool, opts backend.DisplayOptions) {
 
+	logging.Display.V(7).Infof("DisplayEvents(%s): diff=%v, interactive=%v", op, opts.DiffDisplay, opts.IsInteractive)
+	if opts.EventLog != nil {
+		events = opts.EventLog.Tee(events)
+	}
+	if !opts.NoDedupe {
+		events = dedupeDiagEvents(events)
+	}
+	events = showSecrets(events)
 	if opts.DiffDisplay {
 		DisplayDiffEvents(op, action, events, done, opts)
 	} else {
@@ -49,6 +59,19 @@ func DisplayEvents(
 	}
 }
 
+// showSecrets returns a channel that receives each event sent to the given one with the values of secrets shown in its
+// resource properties, if the update was asked to show them.  Only the display sees these values.
+func showSecrets(events <-chan engine.Event) <-chan engine.Event {
+	out := make(chan engine.Event)
+	go func() {
+		defer close(out)
+		for e := range events {
+			out <- engine.ShowSecrets(e)
+		}
+	}()
+	return out
+}
+
 type nopSpinner struct {
 }
 
@@ -148,16 +171,21 @@ func renderStdoutColorEvent(
 	return opts.Color.Colorize(payload.Message)
 }
 
-func renderSummaryEvent(
-	action apitype.UpdateKind, event engine.SummaryEventPayload, opts backend.DisplayOptions) string {
-	changes := event.ResourceChanges
-
-	changeCount := 0
+// countChanges returns the number of resources that the given changes actually change.
+func countChanges(changes engine.ResourceChanges) int {
+	count := 0
 	for op, c := range changes {
 		if op != deploy.OpSame {
-			changeCount += c
+			count += c
 		}
 	}
+	return count
+}
+
+func renderSummaryEvent(
+	action apitype.UpdateKind, event engine.SummaryEventPayload, opts backend.DisplayOptions) string {
+	changes := event.ResourceChanges
+	changeCount := countChanges(changes)
 
 	var actionVerbLabel string
 	if action == apitype.RefreshUpdate {
@@ -179,11 +207,26 @@ func renderSummaryEvent(
 		changesLabel = strconv.Itoa(changeCount)
 	}
 
+	out := &bytes.Buffer{}
+
+	// If the update was preceded by a refresh, first summarize the drift that it found, so that it may be told apart
+	// from the changes that the program itself asks for.
+	if refreshCount := countChanges(event.RefreshChanges); refreshCount > 0 {
+		fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("%vinfo%v: %v %v found during refresh:\n",
+			colors.SpecInfo, colors.Reset, refreshCount, plural("change", refreshCount))))
+		for _, op := range deploy.StepOps {
+			if c := event.RefreshChanges[op]; c > 0 && op != deploy.OpSame {
+				fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("    %v%v %v %v%v\n",
+					op.Prefix(), c, plural("resource", c), op.PastTense(), colors.Reset)))
+			}
+		}
+		actionVerbLabel += " by the program"
+	}
+
 	if changeCount > 0 || changes[deploy.OpSame] > 0 {
 		actionVerbLabel += ":"
 	}
 
-	out := &bytes.Buffer{}
 	fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("%vinfo%v: %v %v %v\n",
 		colors.SpecInfo, colors.Reset, changesLabel, plural("change", changeCount), actionVerbLabel)))
 
@@ -210,17 +253,74 @@ func renderSummaryEvent(
 		fprintfIgnoreError(out, "      %v %v unchanged\n", c, plural("resource", c))
 	}
 
+	// List the stack outputs that changed, since other systems may depend upon their values.
+	if c := len(event.OutputChanges); c > 0 {
+		verb := "changed"
+		if event.IsPreview {
+			verb = "may change"
+		}
+		fprintfIgnoreError(out, "    %v stack %v %v: %v\n",
+			c, plural("output", c), verb, strings.Join(event.OutputChanges, ", "))
+	}
+
+	// List the stacks that reference the changed outputs, since their inputs change along with them.
+	if affected := backend.AffectedStackConsumers(opts.StackConsumers, event.OutputChanges); len(affected) > 0 {
+		var consumers []string
+		for consumer, outputs := range affected {
+			consumers = append(consumers, fmt.Sprintf("%v (%v)", consumer, strings.Join(outputs, ", ")))
+		}
+		sort.Strings(consumers)
+
+		verb := "are"
+		if event.IsPreview {
+			verb = "may be"
+		}
+		c := len(consumers)
+		fprintfIgnoreError(out, "    %v referencing %v %v affected: %v\n",
+			c, plural("stack", c), verb, strings.Join(consumers, ", "))
+	}
+
 	// For actual deploys, we print some additional summary information
 	if !event.IsPreview {
 		if changeCount > 0 {
 			fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("%vUpdate duration: %v%v\n",
 				colors.SpecUnimportant, event.Duration, colors.Reset)))
 		}
+		renderSlowestSteps(out, event.StepTimings, opts)
 	}
 
 	return out.String()
 }
 
+// slowestStepsToShow is the number of the slowest resource operations that an update's summary lists.
+const slowestStepsToShow = 10
+
+// renderSlowestSteps lists the slowest of the given resource operations, so that the ones that dominate an update's
+// duration stand out.  Operations that took less than a second aren't worth mentioning.
+func renderSlowestSteps(out io.Writer, timings []engine.StepTiming, opts backend.DisplayOptions) {
+	var slowest []engine.StepTiming
+	for _, t := range engine.SlowestSteps(timings, slowestStepsToShow) {
+		if t.Duration >= time.Second {
+			slowest = append(slowest, t)
+		}
+	}
+	if len(slowest) == 0 {
+		return
+	}
+
+	fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("%vSlowest operations:%v\n",
+		colors.SpecUnimportant, colors.Reset)))
+	for _, t := range slowest {
+		var failed string
+		if t.Failed {
+			failed = colors.SpecError + " (failed)" + colors.Reset
+		}
+		fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("    %-10v %v%-20s%v %v %v%v\n",
+			t.Duration/time.Millisecond*time.Millisecond, t.Op.Color(), t.Op, colors.Reset,
+			simplifyTypeName(t.URN.Type()), t.URN.Name(), failed)))
+	}
+}
+
 func renderPreludeEvent(event engine.PreludeEventPayload, opts backend.DisplayOptions) string {
 	out := &bytes.Buffer{}
 
@@ -274,7 +374,7 @@ func renderResourcePreEvent(
 	out := &bytes.Buffer{}
 	if shouldShow(payload.Metadata, opts) || isRootStack(payload.Metadata) {
 		indent := engine.GetIndent(payload.Metadata, seen)
-		summary := engine.GetResourcePropertiesSummary(payload.Metadata, indent)
+		summary := engine.GetResourcePropertiesSummary(payload.Metadata, indent, payload.Debug)
 		details := engine.GetResourcePropertiesDetails(
 			payload.Metadata, indent, payload.Planning, opts.SummaryDiff, payload.Debug)
 
@@ -295,7 +395,7 @@ func renderResourceOutputsEvent(
 		indent := engine.GetIndent(payload.Metadata, seen)
 
 		if m, has := seen[payload.Metadata.URN]; has && m.Op == deploy.OpRefresh {
-			summary := engine.GetResourcePropertiesSummary(payload.Metadata, indent)
+			summary := engine.GetResourcePropertiesSummary(payload.Metadata, indent, payload.Debug)
 			fprintIgnoreError(out, opts.Color.Colorize(summary))
 		}
 
@@ -318,15 +418,21 @@ func isRootURN(urn resource.URN) bool {
 // shouldShow returns true if a step should show in the output.
 func shouldShow(step engine.StepEventMetadata, opts backend.DisplayOptions) bool {
 	// For certain operations, whether they are tracked is controlled by flags (to cut down on superfluous output).
-	if step.Op == deploy.OpSame {
+	switch step.Op {
+	case deploy.OpSame:
 		// If the op is the same, it is possible that the resource's metadata changed.  In that case, still show it.
 		if step.Old.Protect != step.New.Protect {
 			return true
 		}
 		return opts.ShowSameResources
+	case deploy.OpRead, deploy.OpReadReplacement:
+		return opts.ShowReads
+	case deploy.OpCreateReplacement, deploy.OpDeleteReplaced:
+		// A replacement is shown as a single replace step unless its individual creates and deletes are asked for.
+		return opts.ShowReplacementSteps
+	default:
+		return true
 	}
-
-	return true
 }
 
 func plural(s string, c int) string {
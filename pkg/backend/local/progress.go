@@ -38,6 +38,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
 )
 
 // Progress describes a message we want to show in the display.  There are two types of messages,
@@ -74,6 +75,11 @@ type DiagInfo struct {
 	// tree-view) next to a resource while it is in progress.
 	LastError, LastWarning, LastInfoError, LastInfo, LastDebug *engine.DiagEventPayload
 
+	// The last ephemeral status message the provider streamed for this resource.  In the
+	// non-interactive mode we'll print this out in the periodic messages saying that a
+	// long-running resource is still being worked on.
+	LastStatus *engine.DiagEventPayload
+
 	// All the diagnostic events we've heard about this resource.  We'll print the last diagnostic
 	// in the status region while a resource is in progress.  At the end we'll print out all
 	// diagnostics for a resource.
@@ -108,6 +114,10 @@ type ProgressDisplay struct {
 	// Any system events we've received.  They will be printed at the bottom of all the status rows
 	systemEventPayloads []engine.StdoutEventPayload
 
+	// The number of unchanged resources we've heard about.  If sames are suppressed, we show this count in their
+	// stead.
+	sameCount int
+
 	// Used to record the order that rows are created in.  That way, when we present in a tree, we
 	// can keep things ordered so they will not jump around.
 	displayOrderCounter int
@@ -153,8 +163,19 @@ type ProgressDisplay struct {
 	// Cache of lines we've already printed.  We don't print a progress message again if it hasn't
 	// changed between the last time we printed and now.
 	printedProgressCache map[string]Progress
+
+	// The last time we printed a heartbeat message for each in-flight row in the non-interactive
+	// mode.
+	lastHeartbeat map[ResourceRow]time.Time
+
+	// The rows whose current steps have run past the slow step threshold and been flagged as such.
+	slowRows map[ResourceRow]bool
 }
 
+// stepHeartbeatInterval is how often, in the non-interactive mode, we print a message saying that
+// a resource is still being worked on.
+const stepHeartbeatInterval = time.Minute
+
 var (
 	// simple regex to take our names like "aws:function:Function" and convert to
 	// "aws:Function"
@@ -251,6 +272,8 @@ func DisplayProgressEvents(
 		urnToID:                make(map[resource.URN]string),
 		colorizedToUncolorized: make(map[string]string),
 		printedProgressCache:   make(map[string]Progress),
+		lastHeartbeat:          make(map[ResourceRow]time.Time),
+		slowRows:               make(map[ResourceRow]bool),
 		displayOrderCounter:    1,
 		nonInteractiveSpinner:  spinner,
 	}
@@ -598,6 +621,12 @@ func (display *ProgressDisplay) refreshAllRowsIfInTerminal() {
 
 		systemID := len(rows)
 
+		if display.opts.SuppressSames && display.sameCount > 0 {
+			display.colorizeAndWriteProgress(makeActionProgress(fmt.Sprintf("%v", systemID),
+				fmt.Sprintf("    %v %v unchanged", display.sameCount, plural("resource", display.sameCount))))
+			systemID++
+		}
+
 		printedHeader := false
 		for _, payload := range display.systemEventPayloads {
 			msg := payload.Color.Colorize(payload.Message)
@@ -780,6 +809,7 @@ func (display *ProgressDisplay) processTick() {
 	// knows something is going on.  This is also helpful for hosts like jenkins that
 	// often timeout a process if output is not seen in a while.
 	display.currentTick++
+	display.processLongRunningSteps(time.Now())
 
 	if display.isTerminal {
 		display.refreshAllRowsIfInTerminal()
@@ -789,6 +819,107 @@ func (display *ProgressDisplay) processTick() {
 	}
 }
 
+// processLongRunningSteps flags any in-flight steps that have run past the slow step threshold.  If
+// we're not in a terminal, where rows are redrawn with their elapsed time on every tick, it also
+// prints a heartbeat message for each in-flight step every so often, so that a long create doesn't
+// look like a hang.
+func (display *ProgressDisplay) processLongRunningSteps(now time.Time) {
+	for _, row := range display.resourceRows {
+		if isRootURN(row.Step().URN) {
+			// The stack is in flight for the entire update; there's nothing to flag there.
+			continue
+		}
+
+		elapsed := display.getStepElapsed(row, now)
+		if elapsed == 0 {
+			continue
+		}
+
+		threshold := display.opts.SlowStepThreshold
+		if threshold > 0 && elapsed >= threshold && !display.slowRows[row] {
+			display.slowRows[row] = true
+			row.RecordDiagEvent(engine.Event{
+				Type: engine.DiagEvent,
+				Payload: engine.DiagEventPayload{
+					URN:      row.Step().URN,
+					Prefix:   colors.SpecWarning + "warning: " + colors.Reset,
+					Message:  fmt.Sprintf("still working after %v; this is longer than expected", threshold),
+					Color:    colors.Raw,
+					Severity: diag.Warning,
+				},
+			})
+
+			if !display.isTerminal {
+				display.lastHeartbeat[row] = now
+				display.refreshSingleRow("", row, nil)
+			}
+			continue
+		}
+
+		if display.isTerminal || row.HideRowIfUnnecessary() {
+			continue
+		}
+
+		last, has := display.lastHeartbeat[row]
+		if !has {
+			last = row.Started()
+		}
+		if now.Sub(last) >= stepHeartbeatInterval {
+			display.lastHeartbeat[row] = now
+			display.writeHeartbeat(row)
+		}
+	}
+}
+
+// writeHeartbeat prints a message saying that the given row's step is still being worked on, along
+// with the last status its provider reported, if any.
+func (display *ProgressDisplay) writeHeartbeat(row ResourceRow) {
+	info := "still working"
+	if status := row.DiagInfo().LastStatus; status != nil {
+		if msg := display.renderProgressDiagEvent(*status, false /*includePrefix:*/); msg != "" {
+			if newLineIndex := strings.Index(msg, "\n"); newLineIndex >= 0 {
+				msg = msg[0:newLineIndex]
+			}
+			info += "; last status: " + msg
+		}
+	}
+
+	columns := row.ColorizedColumns()
+	columns[infoColumn] = info
+	display.refreshColumns("", columns, nil)
+}
+
+// getStepElapsed returns how long the given row's step has been in flight, or zero if it isn't in
+// flight or isn't worth timing (for example, because the resource is unchanged).
+func (display *ProgressDisplay) getStepElapsed(row ResourceRow, now time.Time) time.Duration {
+	if row.IsDone() || row.Started().IsZero() {
+		return 0
+	}
+
+	step := row.Step()
+	if display.getStepOp(step) == deploy.OpSame && !isRootURN(step.URN) {
+		return 0
+	}
+
+	return now.Sub(row.Started())
+}
+
+// getElapsedDescription returns the elapsed time to show after the status of an in-flight row, or
+// the empty string if it hasn't been working for long enough to be worth showing.  Rows that have
+// run past the slow step threshold show their elapsed time as a warning.
+func (display *ProgressDisplay) getElapsedDescription(row ResourceRow) string {
+	elapsed := display.getStepElapsed(row, time.Now())
+	if elapsed < time.Second {
+		return ""
+	}
+
+	desc := fmt.Sprintf(" (%v)", elapsed/time.Second*time.Second)
+	if display.slowRows[row] {
+		return colors.SpecWarning + desc + colors.Reset
+	}
+	return desc
+}
+
 func (display *ProgressDisplay) getRowForURN(urn resource.URN, metadata *engine.StepEventMetadata) ResourceRow {
 	// If there's already a row for this URN, return it.
 	row, has := display.eventUrnToResourceRow[urn]
@@ -876,9 +1007,20 @@ func (display *ProgressDisplay) processNormalEvent(event engine.Event) {
 		row.SetHideRowIfUnnecessary(false)
 	}
 
+	// If we've been asked to suppress unchanged resources, don't print anything for them at all; just count them.
+	suppress := hideRowIfUnnecessary && display.opts.SuppressSames
+	if suppress && event.Type == engine.ResourcePreEvent && metadata.Op == deploy.OpSame {
+		display.sameCount++
+	}
+
 	if event.Type == engine.ResourcePreEvent {
 		step := event.Payload.(engine.ResourcePreEventPayload).Metadata
 		row.SetStep(step)
+
+		// Start timing the step, so that we can show how long it has been working.
+		row.SetStarted(time.Now())
+		delete(display.lastHeartbeat, row)
+		delete(display.slowRows, row)
 	} else if event.Type == engine.ResourceOutputsEvent {
 		isRefresh := display.getStepOp(row.Step()) == deploy.OpRefresh
 		step := event.Payload.(engine.ResourceOutputsEventPayload).Metadata
@@ -904,7 +1046,7 @@ func (display *ProgressDisplay) processNormalEvent(event engine.Event) {
 	if display.isTerminal {
 		// if we're in a terminal, then refresh everything so that all our columns line up
 		display.refreshAllRowsIfInTerminal()
-	} else {
+	} else if !suppress {
 		// otherwise, just print out this single row.
 		display.refreshSingleRow("", row, nil)
 	}
@@ -962,6 +1104,7 @@ func (display *ProgressDisplay) processEvents(ticker *time.Ticker, events <-chan
 			display.processTick()
 
 		case event := <-events:
+			logging.Display.V(9).Infof("ProgressDisplay.processEvents(...): %v event", event.Type)
 			if event.Type == "" || event.Type == engine.CancelEvent {
 				// Engine finished sending events.  Do all the final processing and return
 				// from this local func.  This will print out things like full diagnostic
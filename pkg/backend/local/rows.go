@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
@@ -48,6 +49,11 @@ type ResourceRow interface {
 	// ellipses to show progress for in-flight resources.
 	Tick() int
 
+	// The time at which the row's current step began, or the zero time if we haven't heard that it has.  Used to
+	// show how long in-flight resources have been working.
+	Started() time.Time
+	SetStarted(t time.Time)
+
 	IsDone() bool
 
 	SetFailed()
@@ -121,6 +127,9 @@ type resourceRowData struct {
 	// ellipses to show progress for in-flight resources.
 	tick int
 
+	// The time at which the current step began.
+	started time.Time
+
 	// If we failed this operation for any reason.
 	failed bool
 
@@ -170,6 +179,14 @@ func (data *resourceRowData) Tick() int {
 	return data.tick
 }
 
+func (data *resourceRowData) Started() time.Time {
+	return data.started
+}
+
+func (data *resourceRowData) SetStarted(t time.Time) {
+	data.started = t
+}
+
 func (data *resourceRowData) Failed() bool {
 	return data.failed
 }
@@ -187,6 +204,9 @@ func (data *resourceRowData) RecordDiagEvent(event engine.Event) {
 	payload := event.Payload.(engine.DiagEventPayload)
 
 	diagInfo.LastDiag = &payload
+	if payload.Ephemeral {
+		diagInfo.LastStatus = &payload
+	}
 
 	switch payload.Severity {
 	case diag.Error:
@@ -303,7 +323,8 @@ func (data *resourceRowData) ColorizedColumns() []string {
 		failed := data.failed || diagInfo.ErrorCount > 0
 		columns[statusColumn] = data.display.getStepDoneDescription(step, failed)
 	} else {
-		columns[statusColumn] = data.display.getStepInProgressDescription(step)
+		columns[statusColumn] =
+			data.display.getStepInProgressDescription(step) + data.display.getElapsedDescription(data)
 	}
 
 	columns[infoColumn] = data.getInfoColumn()
@@ -15,13 +15,16 @@
 package local
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
+	"os/user"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -90,19 +93,33 @@ func (b *localBackend) getTarget(stackName tokens.QName) (*deploy.Target, error)
 	if err != nil {
 		return nil, err
 	}
-	decrypter, err := defaultCrypter(stackName, stk.Config)
+	proj, err := workspace.DetectProject()
 	if err != nil {
 		return nil, err
 	}
+	crypter, err := defaultCrypter(stackName, stk.Config)
+	if err != nil {
+		return nil, err
+	}
+	envs, err := backend.ResolveEnvironments(stk.Environments, stk.Config, crypter)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving the stack's environments")
+	}
 	_, snapshot, _, err := b.getStack(stackName)
 	if err != nil {
 		return nil, err
 	}
 	return &deploy.Target{
-		Name:      stackName,
-		Config:    stk.Config,
-		Decrypter: decrypter,
-		Snapshot:  snapshot,
+		Name:               stackName,
+		Config:             proj.StackConfig(envs.Config),
+		Decrypter:          envs.Decrypter,
+		EnvVars:            envs.EnvVars,
+		Snapshot:           snapshot,
+		AutoNaming:         stk.AutoNaming,
+		Guard:              stk.Guard,
+		CredentialProfiles: stk.CredentialProfiles,
+		SuppressWarnings:   stk.SuppressWarnings,
+		SecretPaths:        stk.SecretPaths,
 	}, nil
 }
 
@@ -138,12 +155,26 @@ func (b *localBackend) getStack(name tokens.QName) (config.Map, *deploy.Snapshot
 // GetCheckpoint loads a checkpoint file for the given stack in this project, from the current project workspace.
 func (b *localBackend) getCheckpoint(stackName tokens.QName) (*apitype.CheckpointV2, error) {
 	chkpath := b.stackPath(stackName)
-	bytes, err := ioutil.ReadFile(chkpath)
+	bytes, err := b.store.ReadFile(chkpath)
 	if err != nil {
 		return nil, err
 	}
 
-	return stack.UnmarshalVersionedCheckpointToLatestCheckpoint(bytes)
+	return b.unmarshalCheckpoint(bytes)
+}
+
+// unmarshalCheckpoint unmarshals a checkpoint, reading the values of any of its properties that are stored in blobs.
+func (b *localBackend) unmarshalCheckpoint(bytes []byte) (*apitype.CheckpointV2, error) {
+	chk, err := stack.UnmarshalVersionedCheckpointToLatestCheckpoint(bytes)
+	if err != nil {
+		return nil, err
+	}
+	if chk.Latest != nil {
+		if err = stack.ResolveBlobs(chk.Latest, localBlobStore{b}); err != nil {
+			return nil, err
+		}
+	}
+	return chk, nil
 }
 
 func (b *localBackend) saveStack(name tokens.QName,
@@ -157,30 +188,30 @@ func (b *localBackend) saveStack(name tokens.QName,
 	if filepath.Ext(file) == "" {
 		file = file + ext
 	}
-	chk := stack.SerializeCheckpoint(name, config, snap)
-	byts, err := m.Marshal(chk)
+
+	// Check the blob threshold before touching the existing file, so that a bad setting cannot leave it moved aside.
+	threshold, err := checkpointBlobThreshold()
 	if err != nil {
-		return "", errors.Wrap(err, "An IO error occurred during the current operation")
+		return "", err
 	}
 
 	// Back up the existing file if it already exists.
-	bck := backupTarget(file)
-
-	// Ensure the directory exists.
-	if err = os.MkdirAll(filepath.Dir(file), 0700); err != nil {
-		return "", errors.Wrap(err, "An IO error occurred during the current operation")
-	}
+	bck := b.backupTarget(file)
 
 	// And now write out the new snapshot file, overwriting that location.
-	if err = ioutil.WriteFile(file, byts, 0600); err != nil {
+	if err = b.writeCheckpoint(file, m, name, config, snap, threshold); err != nil {
 		return "", errors.Wrap(err, "An IO error occurred during the current operation")
 	}
 
-	logging.V(7).Infof("Saved stack %s checkpoint to: %s (backup=%s)", name, file, bck)
+	logging.Backend.V(7).Infof("Saved stack %s checkpoint to: %s (backup=%s)", name, file, bck)
 
 	// And if we are retaining historical checkpoint information, write it out again
 	if cmdutil.IsTruthy(os.Getenv("PULUMI_RETAIN_CHECKPOINTS")) {
-		if err = ioutil.WriteFile(fmt.Sprintf("%v.%v", file, time.Now().UnixNano()), byts, 0600); err != nil {
+		byts, err := b.store.ReadFile(file)
+		if err == nil {
+			err = b.store.WriteFile(fmt.Sprintf("%v.%v", file, time.Now().UnixNano()), byts)
+		}
+		if err != nil {
 			return "", errors.Wrap(err, "An IO error occurred during the current operation")
 		}
 	}
@@ -199,24 +230,67 @@ func (b *localBackend) saveStack(name tokens.QName,
 	return file, nil
 }
 
+// writeCheckpoint writes a stack's checkpoint to the given file using the given marshaler.  JSON checkpoints are
+// streamed straight to the file, so that a very large checkpoint never needs to be held in memory all at once.
+func (b *localBackend) writeCheckpoint(file string, m encoding.Marshaler, name tokens.QName,
+	config map[config.Key]config.Value, snap *deploy.Snapshot, threshold int) error {
+
+	// Property values at least threshold bytes long are stored in blobs beside the checkpoint.
+	blobs := localBlobStore{b}
+
+	if !m.IsJSONLike() {
+		chk, err := stack.SerializeCheckpointWithBlobs(name, config, snap, threshold, blobs)
+		if err != nil {
+			return err
+		}
+		byts, err := m.Marshal(chk)
+		if err != nil {
+			return err
+		}
+		return b.store.WriteFile(file, byts)
+	}
+
+	f, err := b.store.Create(file)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err = stack.WriteCheckpointWithBlobs(w, name, config, snap, "    ", threshold, blobs); err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 // removeStack removes information about a stack from the current workspace.
 func (b *localBackend) removeStack(name tokens.QName) error {
 	contract.Require(name != "", "name")
 
 	// Just make a backup of the file and don't write out anything new.
 	file := b.stackPath(name)
-	backupTarget(file)
+	b.backupTarget(file)
+
+	// A stack that no longer exists no longer consumes the outputs of any others.
+	if err := b.store.Remove(b.referencesPath(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := b.store.Remove(b.updateEventsPath(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
 
 	historyDir := b.historyDirectory(name)
-	return os.RemoveAll(historyDir)
+	return b.store.RemoveAll(historyDir)
 }
 
 // backupTarget makes a backup of an existing file, in preparation for writing a new one.  Instead of a copy, it
 // simply renames the file, which is simpler, more efficient, etc.
-func backupTarget(file string) string {
+func (b *localBackend) backupTarget(file string) string {
 	contract.Require(file != "", "file")
 	bck := file + ".bak"
-	err := os.Rename(file, bck)
+	err := b.store.Rename(file, bck)
 	contract.IgnoreError(err) // ignore errors.
 	// IDEA: consider multiple backups (.bak.bak.bak...etc).
 	return bck
@@ -233,7 +307,7 @@ func (b *localBackend) backupStack(name tokens.QName) error {
 
 	// Read the current checkpoint file. (Assuming it aleady exists.)
 	stackPath := b.stackPath(name)
-	byts, err := ioutil.ReadFile(stackPath)
+	byts, err := b.store.ReadFile(stackPath)
 	if err != nil {
 		return err
 	}
@@ -241,17 +315,12 @@ func (b *localBackend) backupStack(name tokens.QName) error {
 	// Get the backup directory.
 	backupDir := b.backupDirectory(name)
 
-	// Ensure the backup directory exists.
-	if err = os.MkdirAll(backupDir, 0700); err != nil {
-		return err
-	}
-
 	// Write out the new backup checkpoint file.
 	stackFile := filepath.Base(stackPath)
 	ext := filepath.Ext(stackFile)
 	base := strings.TrimSuffix(stackFile, ext)
 	backupFile := fmt.Sprintf("%s.%v%s", base, time.Now().UnixNano(), ext)
-	return ioutil.WriteFile(filepath.Join(backupDir, backupFile), byts, 0600)
+	return b.store.WriteFile(filepath.Join(backupDir, backupFile), byts)
 }
 
 func (b *localBackend) stackPath(stack tokens.QName) string {
@@ -275,13 +344,140 @@ func (b *localBackend) backupDirectory(stack tokens.QName) string {
 	return filepath.Join(b.stateRoot, workspace.BackupDir, fsutil.QnamePath(stack))
 }
 
-// getHistory returns locally stored update history. The first element of the result will be
-// the most recent update record.
-func (b *localBackend) getHistory(name tokens.QName) ([]backend.UpdateInfo, error) {
+func (b *localBackend) auditLogPath(stack tokens.QName) string {
+	contract.Require(stack != "", "stack")
+
+	return filepath.Join(b.stateRoot, workspace.AuditDir, fsutil.QnamePath(stack)+".jsonl")
+}
+
+func (b *localBackend) updateEventsPath(stack tokens.QName) string {
+	contract.Require(stack != "", "stack")
+
+	return filepath.Join(b.stateRoot, workspace.EventsDir, fsutil.QnamePath(stack)+".jsonl")
+}
+
+func (b *localBackend) referencesPath(stack tokens.QName) string {
+	path := filepath.Join(b.stateRoot, workspace.ReferencesDir)
+	if stack != "" {
+		path = filepath.Join(path, fsutil.QnamePath(stack)+".json")
+	}
+
+	return path
+}
+
+// saveStackReferences records the outputs of other stacks that the given stack reads.
+func (b *localBackend) saveStackReferences(name tokens.QName, refs map[string][]string) error {
+	file := b.referencesPath(name)
+	if len(refs) == 0 {
+		if err := b.store.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	byts, err := json.MarshalIndent(refs, "", "    ")
+	if err != nil {
+		return err
+	}
+	return b.store.WriteFile(file, byts)
+}
+
+// getStackConsumers returns the outputs of the given stack that each stack which references it reads.
+func (b *localBackend) getStackConsumers(name tokens.QName) (map[string][]string, error) {
+	files, err := b.store.ReadDir(b.referencesPath(""))
+	if err != nil {
+		// No references exist until a stack that makes them has been updated.
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	consumers := make(map[string][]string)
+	for _, file := range files {
+		if filepath.Ext(file) != ".json" {
+			continue
+		}
+		path := filepath.Join(b.referencesPath(""), file)
+		byts, err := b.store.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var refs map[string][]string
+		if err = json.Unmarshal(byts, &refs); err != nil {
+			return nil, errors.Wrapf(err, "reading stack references %s", path)
+		}
+		if outputs, has := refs[string(name)]; has {
+			consumers[strings.TrimSuffix(file, ".json")] = outputs
+		}
+	}
+	return consumers, nil
+}
+
+// newAuditLogEntry describes an operation of the given kind, started at the given time by the current user, against a
+// stack with the given configuration.
+func newAuditLogEntry(kind string, start int64, cfg config.Map, result backend.UpdateResult) backend.AuditLogEntry {
+	entry := backend.AuditLogEntry{
+		Time:    start,
+		Kind:    kind,
+		Command: backend.AuditCommand(os.Args),
+		Result:  result,
+	}
+	if u, err := user.Current(); err == nil {
+		entry.User = u.Username
+	} else {
+		entry.User = os.Getenv("USER")
+	}
+	if digest, err := backend.ConfigDigest(cfg); err == nil {
+		entry.ConfigDigest = digest
+	}
+	return entry
+}
+
+// appendAuditLog adds an entry to the end of a stack's audit log.  The log is only ever appended to; in particular, it
+// outlives the stack itself, so that the stack's removal is recorded too.
+func (b *localBackend) appendAuditLog(name tokens.QName, entry backend.AuditLogEntry) error {
+	byts, err := json.Marshal(&entry)
+	if err != nil {
+		return err
+	}
+	return b.store.AppendFile(b.auditLogPath(name), append(byts, '\n'))
+}
+
+// getAuditLog returns the entries of a stack's audit log, the most recent first.
+func (b *localBackend) getAuditLog(name tokens.QName) ([]backend.AuditLogEntry, error) {
+	file := b.auditLogPath(name)
+	byts, err := b.store.ReadFile(file)
+	if err != nil {
+		// The audit log doesn't exist until an operation has been recorded.
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []backend.AuditLogEntry
+	lines := strings.Split(strings.TrimSpace(string(byts)), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i] == "" {
+			continue
+		}
+		var entry backend.AuditLogEntry
+		if err = json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			return nil, errors.Wrapf(err, "reading audit log %s, line %d", file, i+1)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// getHistoryFiles returns the paths of the locally stored update history files. The first element of the result
+// will be the most recent update record.
+func (b *localBackend) getHistoryFiles(name tokens.QName) ([]string, error) {
 	contract.Require(name != "", "name")
 
 	dir := b.historyDirectory(name)
-	allFiles, err := ioutil.ReadDir(dir)
+	allFiles, err := b.store.ReadDir(dir)
 	if err != nil {
 		// History doesn't exist until a stack has been updated.
 		if os.IsNotExist(err) {
@@ -290,43 +486,84 @@ func (b *localBackend) getHistory(name tokens.QName) ([]backend.UpdateInfo, erro
 		return nil, err
 	}
 
-	var updates []backend.UpdateInfo
+	var files []string
 
-	// os.ReadDir returns the array sorted by file name, but because of how we name files, older updates come before
+	// ReadDir returns the array sorted by file name, but because of how we name files, older updates come before
 	// newer ones. Loop backwards so we added the newest updates to the array we will return first.
 	for i := len(allFiles) - 1; i >= 0; i-- {
 		file := allFiles[i]
-		filepath := path.Join(dir, file.Name())
+		filepath := path.Join(dir, file)
 
-		// Open all of the history files, ignoring the checkpoints.
+		// Collect all of the history files, ignoring the checkpoints.
 		if !strings.HasSuffix(filepath, ".history.json") {
 			continue
 		}
 
+		files = append(files, filepath)
+	}
+
+	return files, nil
+}
+
+// getHistory returns locally stored update history. The first element of the result will be
+// the most recent update record.
+func (b *localBackend) getHistory(name tokens.QName) ([]backend.UpdateInfo, error) {
+	files, err := b.getHistoryFiles(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []backend.UpdateInfo
+	for i, filepath := range files {
 		var update backend.UpdateInfo
-		b, err := ioutil.ReadFile(filepath)
+		byts, err := b.store.ReadFile(filepath)
 		if err != nil {
 			return nil, errors.Wrapf(err, "reading history file %s", filepath)
 		}
-		err = json.Unmarshal(b, &update)
+		err = json.Unmarshal(byts, &update)
 		if err != nil {
 			return nil, errors.Wrapf(err, "reading history file %s", filepath)
 		}
 
+		// Versions are not recorded on disk; they are simply the update's position in the history, oldest first.
+		update.Version = len(files) - i
+
 		updates = append(updates, update)
 	}
 
 	return updates, nil
 }
 
+// getHistoricalCheckpoint returns the copy of the checkpoint file that was saved alongside the given version of the
+// stack's update history.
+func (b *localBackend) getHistoricalCheckpoint(name tokens.QName, version int) (*apitype.CheckpointV2, error) {
+	files, err := b.getHistoryFiles(name)
+	if err != nil {
+		return nil, err
+	}
+	if version < 1 || version > len(files) {
+		return nil, errors.Errorf("stack '%s' has no version %d", name, version)
+	}
+
+	historyFile := files[len(files)-version]
+	checkpointFile := strings.TrimSuffix(historyFile, ".history.json") + ".checkpoint.json"
+	byts, err := b.store.ReadFile(checkpointFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading checkpoint file %s", checkpointFile)
+	}
+
+	chk, err := b.unmarshalCheckpoint(byts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading checkpoint file %s", checkpointFile)
+	}
+	return chk, nil
+}
+
 // addToHistory saves the UpdateInfo and makes a copy of the current Checkpoint file.
 func (b *localBackend) addToHistory(name tokens.QName, update backend.UpdateInfo) error {
 	contract.Require(name != "", "name")
 
 	dir := b.historyDirectory(name)
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		return err
-	}
 
 	// Prefix for the update and checkpoint files.
 	pathPrefix := path.Join(dir, fmt.Sprintf("%s-%d", name, time.Now().UnixNano()))
@@ -338,16 +575,152 @@ func (b *localBackend) addToHistory(name tokens.QName, update backend.UpdateInfo
 	}
 
 	historyFile := fmt.Sprintf("%s.history.json", pathPrefix)
-	if err = ioutil.WriteFile(historyFile, byts, os.ModePerm); err != nil {
+	if err = b.store.WriteFile(historyFile, byts); err != nil {
 		return err
 	}
 
 	// Make a copy of the checkpoint file. (Assuming it aleady exists.)
-	byts, err = ioutil.ReadFile(b.stackPath(name))
+	byts, err = b.store.ReadFile(b.stackPath(name))
 	if err != nil {
 		return err
 	}
 
 	checkpointFile := fmt.Sprintf("%s.checkpoint.json", pathPrefix)
-	return ioutil.WriteFile(checkpointFile, byts, os.ModePerm)
+	return b.store.WriteFile(checkpointFile, byts)
+}
+
+// updateEventsFlushInterval is how often the events of an update are appended to the local backend's state store.
+// They are written in batches because some stores, such as those of state plugins, can only append to a file by
+// rewriting it.
+const updateEventsFlushInterval = time.Second
+
+// storeAppender is a writer that buffers writes and appends them to a file in a local backend's state store in
+// batches, so that every complete write may be read shortly after it has been made without each write waiting on the
+// store.
+type storeAppender struct {
+	store stateStore
+	path  string
+
+	flushLock sync.Mutex    // serializes the appends to the file.
+	lock      sync.Mutex    // guards buf and closed.
+	buf       []byte        // the writes that have yet to be appended to the file.
+	closed    bool          // true once the writer has been closed.
+	stop      chan struct{} // closed to stop the periodic flushes.
+	stopped   chan struct{} // closed once the periodic flushes have stopped.
+}
+
+// newStoreAppender returns a writer that appends to the given file, flushing its writes at the given interval.
+func newStoreAppender(store stateStore, path string, interval time.Duration) *storeAppender {
+	a := &storeAppender{store: store, path: path, stop: make(chan struct{}), stopped: make(chan struct{})}
+	go func() {
+		defer close(a.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := a.flush(); err != nil {
+					logging.V(3).Infof("failed to append to %s: %v", a.path, err)
+				}
+			case <-a.stop:
+				return
+			}
+		}
+	}()
+	return a
+}
+
+func (a *storeAppender) Write(p []byte) (int, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.closed {
+		return 0, errors.Errorf("%s is closed", a.path)
+	}
+	a.buf = append(a.buf, p...)
+	return len(p), nil
+}
+
+// flush appends the writes made so far to the file.  Writes that fail to be appended are kept for the next flush.
+func (a *storeAppender) flush() error {
+	a.flushLock.Lock()
+	defer a.flushLock.Unlock()
+
+	a.lock.Lock()
+	data := a.buf
+	a.buf = nil
+	a.lock.Unlock()
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := a.store.AppendFile(a.path, data); err != nil {
+		a.lock.Lock()
+		a.buf = append(data, a.buf...)
+		a.lock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Close stops the periodic flushes and appends any writes that remain to the file.
+func (a *storeAppender) Close() error {
+	a.lock.Lock()
+	if a.closed {
+		a.lock.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.lock.Unlock()
+
+	close(a.stop)
+	<-a.stopped
+	return a.flush()
+}
+
+// newUpdateEventLog returns an event log that records the events of a stack's update in place of those of its last.
+func (b *localBackend) newUpdateEventLog(name tokens.QName) (*backend.EventLog, error) {
+	file := b.updateEventsPath(name)
+	if err := b.store.WriteFile(file, nil); err != nil {
+		return nil, errors.Wrap(err, "creating update event log")
+	}
+	return backend.NewEventLogWriter(newStoreAppender(b.store, file, updateEventsFlushInterval)), nil
+}
+
+// getUpdateEvents returns the events that a stack's latest update has recorded so far, and whether that update has
+// finished, which it has once its cancellation event has been recorded.
+func (b *localBackend) getUpdateEvents(name tokens.QName) ([]apitype.EngineEvent, bool, error) {
+	file := b.updateEventsPath(name)
+	byts, err := b.store.ReadFile(file)
+	if err != nil {
+		// The events don't exist until the stack has been updated.
+		if os.IsNotExist(err) {
+			return nil, true, nil
+		}
+		return nil, false, err
+	}
+
+	// The update may still be writing its last event, so only complete lines are read.
+	if i := bytes.LastIndexByte(byts, '\n'); i >= 0 {
+		byts = byts[:i]
+	} else {
+		byts = nil
+	}
+
+	var events []apitype.EngineEvent
+	done := false
+	for i, line := range strings.Split(string(byts), "\n") {
+		if line == "" {
+			continue
+		}
+		var event apitype.EngineEvent
+		if err = json.Unmarshal([]byte(line), &event); err != nil {
+			return nil, false, errors.Wrapf(err, "reading update events %s, line %d", file, i+1)
+		}
+		if event.Cancel != nil {
+			done = true
+		}
+		events = append(events, event)
+	}
+	return events, done, nil
 }
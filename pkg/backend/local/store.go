@@ -0,0 +1,270 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// stateStore holds the files that make up a local backend's state: each stack's checkpoint, and its history, backups,
+// audit log, and references.  Files are named by paths beneath the backend's state root, and a file that does not
+// exist is reported with an error that satisfies os.IsNotExist, just as it is by the OS.
+type stateStore interface {
+	// Closer releases any resources, such as plugins, that the store holds.
+	io.Closer
+
+	// ReadFile returns the contents of the given file.
+	ReadFile(path string) ([]byte, error)
+	// WriteFile creates or replaces the given file, creating its directory if need be.
+	WriteFile(path string, data []byte) error
+	// AppendFile adds the given data to the end of the given file, creating it if need be.
+	AppendFile(path string, data []byte) error
+	// Create returns a writer that creates or replaces the given file.  The file is complete once the writer is
+	// closed.
+	Create(path string) (io.WriteCloser, error)
+	// Rename moves the given file to a new path, atomically replacing any file already there.
+	Rename(oldpath, newpath string) error
+	// Remove removes the given file.
+	Remove(path string) error
+	// RemoveAll removes the given directory and all of the files beneath it.
+	RemoveAll(path string) error
+	// ReadDir returns the names of the files directly within the given directory, sorted by name.
+	ReadDir(path string) ([]string, error)
+}
+
+// fileStore keeps a local backend's state on the local disk.
+type fileStore struct{}
+
+func (fileStore) Close() error {
+	return nil
+}
+
+func (fileStore) ReadFile(path string) ([]byte, error) {
+	return ioutil.ReadFile(path)
+}
+
+func (fileStore) WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+func (fileStore) AppendFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		contract.IgnoreClose(f)
+		return err
+	}
+	return f.Close()
+}
+
+func (fileStore) Create(path string) (io.WriteCloser, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+}
+
+func (fileStore) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (fileStore) Remove(path string) error {
+	return os.Remove(path)
+}
+
+func (fileStore) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func (fileStore) ReadDir(path string) ([]string, error) {
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir() {
+			names = append(names, info.Name())
+		}
+	}
+	return names, nil
+}
+
+// pluginStore keeps a local backend's state with the backend plugin that serves its URL's scheme, each file being a
+// blob whose key is the file's path.  The plugin is launched the first time that it is needed.
+type pluginStore struct {
+	d      diag.Sink
+	scheme string
+	url    string
+
+	once sync.Once
+	plug plugin.Backend
+	err  error
+}
+
+// backend returns the plugin that holds this store's blobs, launching it if it has not been already.
+func (s *pluginStore) backend() (plugin.Backend, error) {
+	s.once.Do(func() {
+		ctx := &plugin.Context{Diag: s.d, StatusDiag: s.d}
+		s.plug, s.err = plugin.NewBackend(ctx, s.scheme, s.url)
+	})
+	return s.plug, s.err
+}
+
+// Close shuts down the store's plugin, if it has been launched.  The plugin is not launched once the store is closed.
+func (s *pluginStore) Close() error {
+	s.once.Do(func() {})
+	plug := s.plug
+	s.plug, s.err = nil, errors.Errorf("the %s backend has been closed", s.url)
+	if plug == nil {
+		return nil
+	}
+	return plug.Close()
+}
+
+// notExist returns the error that reports that the given file does not exist.
+func notExist(op string, path string) error {
+	return &os.PathError{Op: op, Path: path, Err: os.ErrNotExist}
+}
+
+func (s *pluginStore) ReadFile(path string) ([]byte, error) {
+	plug, err := s.backend()
+	if err != nil {
+		return nil, err
+	}
+	data, found, err := plug.GetBlob(filepath.ToSlash(path))
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return nil, notExist("open", path)
+	}
+	return data, nil
+}
+
+func (s *pluginStore) WriteFile(path string, data []byte) error {
+	plug, err := s.backend()
+	if err != nil {
+		return err
+	}
+	return plug.PutBlob(filepath.ToSlash(path), data)
+}
+
+func (s *pluginStore) AppendFile(path string, data []byte) error {
+	existing, err := s.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return s.WriteFile(path, append(existing, data...))
+}
+
+func (s *pluginStore) Create(path string) (io.WriteCloser, error) {
+	return &pluginFileWriter{store: s, path: path}, nil
+}
+
+func (s *pluginStore) Rename(oldpath, newpath string) error {
+	plug, err := s.backend()
+	if err != nil {
+		return err
+	}
+	found, err := plug.RenameBlob(filepath.ToSlash(oldpath), filepath.ToSlash(newpath))
+	if err != nil {
+		return err
+	} else if !found {
+		return notExist("rename", oldpath)
+	}
+	return nil
+}
+
+func (s *pluginStore) Remove(path string) error {
+	plug, err := s.backend()
+	if err != nil {
+		return err
+	}
+	return plug.DeleteBlob(filepath.ToSlash(path))
+}
+
+func (s *pluginStore) RemoveAll(path string) error {
+	plug, err := s.backend()
+	if err != nil {
+		return err
+	}
+	keys, err := plug.ListBlobs(filepath.ToSlash(path) + "/")
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err = plug.DeleteBlob(key); err != nil {
+			return errors.Wrapf(err, "removing %s", key)
+		}
+	}
+	return nil
+}
+
+func (s *pluginStore) ReadDir(path string) ([]string, error) {
+	plug, err := s.backend()
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.ToSlash(path) + "/"
+	keys, err := plug.ListBlobs(prefix)
+	if err != nil {
+		return nil, err
+	} else if len(keys) == 0 {
+		return nil, notExist("open", path)
+	}
+	var names []string
+	for _, key := range keys {
+		// Blobs in directories beneath this one are not files directly within it.
+		if name := strings.TrimPrefix(key, prefix); !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// pluginFileWriter buffers a file that is being written to a plugin store, and stores it once it has been closed.
+type pluginFileWriter struct {
+	store *pluginStore
+	path  string
+	buf   bytes.Buffer
+}
+
+func (w *pluginFileWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *pluginFileWriter) Close() error {
+	return w.store.WriteFile(w.path, w.buf.Bytes())
+}
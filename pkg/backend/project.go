@@ -0,0 +1,102 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// SnapshotProject returns the project that the resources of a stack's snapshot belong to, or "" if it has none.
+func SnapshotProject(snap *deploy.Snapshot) tokens.PackageName {
+	if snap == nil || len(snap.Resources) == 0 {
+		return ""
+	}
+	return snap.Resources[0].URN.Project()
+}
+
+// SnapshotIsEmpty returns true if a stack's snapshot holds no resources other than the stack's root resource, which is
+// all that remains of a stack once its program declares nothing.
+func SnapshotIsEmpty(snap *deploy.Snapshot) bool {
+	if snap == nil {
+		return true
+	}
+	for _, res := range snap.Resources {
+		if res.Type != resource.RootStackType {
+			return false
+		}
+	}
+	return true
+}
+
+// RenameSnapshotProject moves every resource of a stack's snapshot into the given project, by rewriting their URNs and
+// every reference that one resource makes to another.  The snapshot is modified in place.
+func RenameSnapshotProject(snap *deploy.Snapshot, project tokens.PackageName) error {
+	if snap == nil {
+		return nil
+	}
+
+	rename := func(urn resource.URN) resource.URN {
+		if urn == "" {
+			return ""
+		}
+		// Names may themselves contain the delimiter, so only the elements before the name are split off.
+		parts := strings.SplitN(urn.URNName(), resource.URNNameDelimiter, 4)
+		parts[1] = string(project)
+		return resource.URN(resource.URNPrefix + strings.Join(parts, resource.URNNameDelimiter))
+	}
+	renameAll := func(urns []resource.URN) []resource.URN {
+		for i, urn := range urns {
+			urns[i] = rename(urn)
+		}
+		return urns
+	}
+
+	renameState := func(res *resource.State) error {
+		res.URN = rename(res.URN)
+		res.Parent = rename(res.Parent)
+		res.Dependencies = renameAll(res.Dependencies)
+		res.DependsOn = renameAll(res.DependsOn)
+		if res.Provider != "" {
+			ref, err := providers.ParseReference(res.Provider)
+			if err != nil {
+				return errors.Wrapf(err, "resource %s has an invalid provider reference", res.URN)
+			}
+			if ref, err = providers.NewReference(rename(ref.URN()), ref.ID()); err != nil {
+				return err
+			}
+			res.Provider = ref.String()
+		}
+		return nil
+	}
+
+	for _, res := range snap.Resources {
+		if err := renameState(res); err != nil {
+			return err
+		}
+	}
+	for _, op := range snap.PendingOperations {
+		if err := renameState(op.Resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func newProjectTestSnapshot(t *testing.T) *deploy.Snapshot {
+	urn := func(proj tokens.PackageName, typ tokens.Type, name tokens.QName) resource.URN {
+		return resource.NewURN("dev", proj, "", typ, name)
+	}
+	newState := func(typ tokens.Type, urn, parent resource.URN, deps []resource.URN, prov string) *resource.State {
+		return resource.NewState(typ, urn, true, false, "id", resource.PropertyMap{}, resource.PropertyMap{},
+			parent, false, false, deps, nil, prov)
+	}
+
+	root := newState(resource.RootStackType, urn("old", resource.RootStackType, "old-dev"), "", nil, "")
+	provURN := urn("old", "pulumi:providers:aws", "default")
+	prov := newState("pulumi:providers:aws", provURN, root.URN, nil, "")
+	provRef, err := providers.NewReference(provURN, "prov-id")
+	assert.NoError(t, err)
+	bucket := newState("aws:s3/bucket:Bucket", urn("old", "aws:s3/bucket:Bucket", "a::b"), root.URN,
+		[]resource.URN{provURN}, provRef.String())
+	pending := newState("aws:s3/bucket:Bucket", urn("old", "aws:s3/bucket:Bucket", "c"), root.URN, nil, "")
+
+	return deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{root, prov, bucket},
+		[]resource.Operation{resource.NewOperation(pending, resource.OperationTypeCreating)})
+}
+
+func TestRenameSnapshotProject(t *testing.T) {
+	snap := newProjectTestSnapshot(t)
+	assert.Equal(t, tokens.PackageName("old"), SnapshotProject(snap))
+
+	assert.NoError(t, RenameSnapshotProject(snap, "new"))
+	assert.Equal(t, tokens.PackageName("new"), SnapshotProject(snap))
+
+	root, prov, bucket := snap.Resources[0], snap.Resources[1], snap.Resources[2]
+	assert.Equal(t, resource.URN("urn:pulumi:dev::new::pulumi:pulumi:Stack::old-dev"), root.URN)
+	assert.Equal(t, resource.URN("urn:pulumi:dev::new::pulumi:providers:aws::default"), prov.URN)
+	assert.Equal(t, root.URN, prov.Parent)
+
+	// Names that contain the delimiter are left alone, and every reference to another resource is rewritten.
+	assert.Equal(t, resource.URN("urn:pulumi:dev::new::aws:s3/bucket:Bucket::a::b"), bucket.URN)
+	assert.Equal(t, root.URN, bucket.Parent)
+	assert.Equal(t, []resource.URN{prov.URN}, bucket.Dependencies)
+	ref, err := providers.ParseReference(bucket.Provider)
+	assert.NoError(t, err)
+	assert.Equal(t, prov.URN, ref.URN())
+	assert.Equal(t, resource.ID("prov-id"), ref.ID())
+
+	pending := snap.PendingOperations[0].Resource
+	assert.Equal(t, resource.URN("urn:pulumi:dev::new::aws:s3/bucket:Bucket::c"), pending.URN)
+
+	assert.NoError(t, RenameSnapshotProject(nil, "new"))
+}
+
+func TestSnapshotIsEmpty(t *testing.T) {
+	assert.True(t, SnapshotIsEmpty(nil))
+	assert.Equal(t, tokens.PackageName(""), SnapshotProject(nil))
+
+	snap := newProjectTestSnapshot(t)
+	assert.False(t, SnapshotIsEmpty(snap))
+
+	snap.Resources = snap.Resources[:1]
+	assert.True(t, SnapshotIsEmpty(snap))
+}
@@ -15,14 +15,21 @@
 package backend
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
 	"reflect"
+	"runtime"
 	"sort"
 	"time"
 
+	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/version"
@@ -60,10 +67,14 @@ type SnapshotManager struct {
 	dones            map[*resource.State]bool // The set of resources that have been operated upon already by this plan
 	completeOps      map[*resource.State]bool // The set of resources that have completed their operation
 	doVerify         bool                     // If true, verify the snapshot before persisting it
+	checkInvariants  bool                     // If true, check the snapshot's invariants after every mutation
 	plugins          []workspace.PluginInfo   // The list of plugins loaded by the plan, to be saved in the manifest
 	mutationRequests chan<- mutationRequest   // The queue of mutation requests, to be retired serially by the manager
 	cancel           chan bool                // A channel used to request cancellation of any new mutation requests.
 	done             <-chan error             // A channel that sends a single result when the manager has shut down.
+
+	// The states that replace those base resources that the plan no longer declares but that outlived it.
+	unreferenced map[*resource.State]*resource.State
 }
 
 var _ engine.SnapshotManager = (*SnapshotManager)(nil)
@@ -122,19 +133,25 @@ func (sm *SnapshotManager) RegisterResourceOutputs(step deploy.Step) error {
 
 // RecordPlugin records that the current plan loaded a plugin and saves it in the snapshot.
 func (sm *SnapshotManager) RecordPlugin(plugin workspace.PluginInfo) error {
-	logging.V(9).Infof("SnapshotManager: RecordPlugin(%v)", plugin)
+	logging.Backend.V(9).Infof("SnapshotManager: RecordPlugin(%v)", plugin)
 	return sm.mutate(func() bool {
 		sm.plugins = append(sm.plugins, plugin)
 		return true
 	})
 }
 
+// Plugins returns the plugins that the current plan loaded.  It must not be called until the manager has been closed.
+func (sm *SnapshotManager) Plugins() []workspace.PluginInfo {
+	return sm.plugins
+}
+
 // BeginMutation signals to the SnapshotManager that the engine intends to mutate the global snapshot
 // by performing the given Step. This function gives the SnapshotManager a chance to record the
 // intent to mutate before the mutation occurs.
 func (sm *SnapshotManager) BeginMutation(step deploy.Step) (engine.SnapshotMutation, error) {
 	contract.Require(step != nil, "step != nil")
-	logging.V(9).Infof("SnapshotManager: Beginning mutation for step `%s` on resource `%s`", step.Op(), step.URN())
+	logging.Backend.V(9).Infof(
+		"SnapshotManager: Beginning mutation for step `%s` on resource `%s`", step.Op(), step.URN())
 
 	switch step.Op() {
 	case deploy.OpSame:
@@ -178,7 +195,7 @@ func (ssm *sameSnapshotMutation) mustWrite(old, new *resource.State) bool {
 	contract.Assert(old.URN == new.URN)
 	contract.Assert(old.Delete == new.Delete)
 	contract.Assert(old.External == new.External)
-	contract.Assert(reflect.DeepEqual(old.Inputs, new.Inputs))
+	contract.Assert(reflect.DeepEqual(old.Inputs(), new.Inputs()))
 
 	// If the kind of this resource has changed, we must write the checkpoint.
 	if old.Custom != new.Custom {
@@ -199,7 +216,7 @@ func (ssm *sameSnapshotMutation) mustWrite(old, new *resource.State) bool {
 	}
 
 	// If the outputs of this resource have changed, we must write the checkpoint.
-	if !reflect.DeepEqual(old.Outputs, new.Outputs) {
+	if !reflect.DeepEqual(old.Outputs(), new.Outputs()) {
 		return true
 	}
 
@@ -219,7 +236,7 @@ func (ssm *sameSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step != nil, "step != nil")
 	contract.Require(step.Op() == deploy.OpSame, "step.Op() == deploy.OpSame")
 	contract.Assert(successful)
-	logging.V(9).Infof("SnapshotManager: sameSnapshotMutation.End(..., %v)", successful)
+	logging.Backend.V(9).Infof("SnapshotManager: sameSnapshotMutation.End(..., %v)", successful)
 	return ssm.manager.mutate(func() bool {
 		ssm.manager.markDone(step.Old())
 		ssm.manager.markNew(step.New())
@@ -230,7 +247,7 @@ func (ssm *sameSnapshotMutation) End(step deploy.Step, successful bool) error {
 		// As such, we diff all of the non-input properties of the resource here and write the snapshot if we find any
 		// changes.
 		if !ssm.mustWrite(step.Old(), step.New()) {
-			logging.V(9).Infof("SnapshotManager: sameSnapshotMutation.End() eliding write")
+			logging.Backend.V(9).Infof("SnapshotManager: sameSnapshotMutation.End() eliding write")
 			return false
 		}
 		return true
@@ -238,7 +255,7 @@ func (ssm *sameSnapshotMutation) End(step deploy.Step, successful bool) error {
 }
 
 func (sm *SnapshotManager) doCreate(step deploy.Step) (engine.SnapshotMutation, error) {
-	logging.V(9).Infof("SnapshotManager.doCreate(%s)", step.URN())
+	logging.Backend.V(9).Infof("SnapshotManager.doCreate(%s)", step.URN())
 	err := sm.mutate(func() bool {
 		sm.markOperationPending(step.New(), resource.OperationTypeCreating)
 		return true
@@ -256,7 +273,7 @@ type createSnapshotMutation struct {
 
 func (csm *createSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step != nil, "step != nil")
-	logging.V(9).Infof("SnapshotManager: createSnapshotMutation.End(..., %v)", successful)
+	logging.Backend.V(9).Infof("SnapshotManager: createSnapshotMutation.End(..., %v)", successful)
 	return csm.manager.mutate(func() bool {
 		csm.manager.markOperationComplete(step.New())
 		if successful {
@@ -276,7 +293,7 @@ func (csm *createSnapshotMutation) End(step deploy.Step, successful bool) error
 }
 
 func (sm *SnapshotManager) doUpdate(step deploy.Step) (engine.SnapshotMutation, error) {
-	logging.V(9).Info("SnapshotManager.doUpdate(%s)", step.URN())
+	logging.Backend.V(9).Info("SnapshotManager.doUpdate(%s)", step.URN())
 	err := sm.mutate(func() bool {
 		sm.markOperationPending(step.New(), resource.OperationTypeUpdating)
 		return true
@@ -294,7 +311,7 @@ type updateSnapshotMutation struct {
 
 func (usm *updateSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step != nil, "step != nil")
-	logging.V(9).Infof("SnapshotManager: updateSnapshotMutation.End(..., %v)", successful)
+	logging.Backend.V(9).Infof("SnapshotManager: updateSnapshotMutation.End(..., %v)", successful)
 	return usm.manager.mutate(func() bool {
 		usm.manager.markOperationComplete(step.New())
 		if successful {
@@ -306,7 +323,7 @@ func (usm *updateSnapshotMutation) End(step deploy.Step, successful bool) error
 }
 
 func (sm *SnapshotManager) doDelete(step deploy.Step) (engine.SnapshotMutation, error) {
-	logging.V(9).Infof("SnapshotManager.doDelete(%s)", step.URN())
+	logging.Backend.V(9).Infof("SnapshotManager.doDelete(%s)", step.URN())
 	err := sm.mutate(func() bool {
 		sm.markOperationPending(step.Old(), resource.OperationTypeDeleting)
 		return true
@@ -324,12 +341,15 @@ type deleteSnapshotMutation struct {
 
 func (dsm *deleteSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step != nil, "step != nil")
-	logging.V(9).Infof("SnapshotManager: deleteSnapshotMutation.End(..., %v)", successful)
+	logging.Backend.V(9).Infof("SnapshotManager: deleteSnapshotMutation.End(..., %v)", successful)
 	return dsm.manager.mutate(func() bool {
 		dsm.manager.markOperationComplete(step.Old())
 		if successful {
 			contract.Assert(!step.Old().Protect)
 			dsm.manager.markDone(step.Old())
+		} else if del, ok := step.(*deploy.DeleteStep); ok && del.Unreferenced() != nil {
+			// The resource outlives this plan, which no longer declares it; keep its state with that recorded.
+			dsm.manager.unreferenced[step.Old()] = del.Unreferenced()
 		}
 		return true
 	})
@@ -340,12 +360,12 @@ type replaceSnapshotMutation struct {
 }
 
 func (rsm *replaceSnapshotMutation) End(step deploy.Step, successful bool) error {
-	logging.V(9).Infof("SnapshotManager: replaceSnapshotMutation.End(..., %v)", successful)
+	logging.Backend.V(9).Infof("SnapshotManager: replaceSnapshotMutation.End(..., %v)", successful)
 	return nil
 }
 
 func (sm *SnapshotManager) doRead(step deploy.Step) (engine.SnapshotMutation, error) {
-	logging.V(9).Infof("SnapshotManager.doRead(%s)", step.URN())
+	logging.Backend.V(9).Infof("SnapshotManager.doRead(%s)", step.URN())
 	err := sm.mutate(func() bool {
 		sm.markOperationPending(step.New(), resource.OperationTypeReading)
 		return true
@@ -363,7 +383,7 @@ type readSnapshotMutation struct {
 
 func (rsm *readSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step != nil, "step != nil")
-	logging.V(9).Infof("SnapshotManager: readSnapshotMutation.End(..., %v)", successful)
+	logging.Backend.V(9).Infof("SnapshotManager: readSnapshotMutation.End(..., %v)", successful)
 	return rsm.manager.mutate(func() bool {
 		rsm.manager.markOperationComplete(step.New())
 		if successful {
@@ -384,7 +404,7 @@ type refreshSnapshotMutation struct {
 func (rsm *refreshSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step != nil, "step != nil")
 	contract.Require(step.Op() == deploy.OpRefresh, "step.Op() == deploy.OpRefresh")
-	logging.V(9).Infof("SnapshotManager: refreshSnapshotMutation.End(..., %v)", successful)
+	logging.Backend.V(9).Infof("SnapshotManager: refreshSnapshotMutation.End(..., %v)", successful)
 	return rsm.manager.mutate(func() bool {
 		// We always elide refreshes. The expectation is that all of these run before any actual mutations and that
 		// some other component will rewrite the base snapshot in-memory, so there's no action the snapshot
@@ -399,7 +419,7 @@ func (rsm *refreshSnapshotMutation) End(step deploy.Step, successful bool) error
 func (sm *SnapshotManager) markDone(state *resource.State) {
 	contract.Assert(state != nil)
 	sm.dones[state] = true
-	logging.V(9).Infof("Marked old state snapshot as done: %v", state.URN)
+	logging.Backend.V(9).Infof("Marked old state snapshot as done: %v", state.URN)
 }
 
 // markNew marks a resource as existing in the new snapshot. This occurs on
@@ -408,21 +428,21 @@ func (sm *SnapshotManager) markDone(state *resource.State) {
 func (sm *SnapshotManager) markNew(state *resource.State) {
 	contract.Assert(state != nil)
 	sm.resources = append(sm.resources, state)
-	logging.V(9).Infof("Appended new state snapshot to be written: %v", state.URN)
+	logging.Backend.V(9).Infof("Appended new state snapshot to be written: %v", state.URN)
 }
 
 // markOperationPending marks a resource as undergoing an operation that will now be considered pending.
 func (sm *SnapshotManager) markOperationPending(state *resource.State, op resource.OperationType) {
 	contract.Assert(state != nil)
 	sm.operations = append(sm.operations, resource.NewOperation(state, op))
-	logging.V(9).Infof("SnapshotManager.markPendingOperation(%s, %s)", state.URN, string(op))
+	logging.Backend.V(9).Infof("SnapshotManager.markPendingOperation(%s, %s)", state.URN, string(op))
 }
 
 // markOperationComplete marks a resource as having completed the operation that it previously was performing.
 func (sm *SnapshotManager) markOperationComplete(state *resource.State) {
 	contract.Assert(state != nil)
 	sm.completeOps[state] = true
-	logging.V(9).Infof("SnapshotManager.markOperationComplete(%s)", state.URN)
+	logging.Backend.V(9).Infof("SnapshotManager.markOperationComplete(%s)", state.URN)
 }
 
 // snap produces a new Snapshot given the base snapshot and a list of resources that the current
@@ -465,6 +485,9 @@ func (sm *SnapshotManager) snap() *deploy.Snapshot {
 	if base := sm.baseSnapshot; base != nil {
 		for _, res := range base.Resources {
 			if !sm.dones[res] {
+				if unreferenced, has := sm.unreferenced[res]; has {
+					res = unreferenced
+				}
 				resources = append(resources, res)
 			}
 		}
@@ -481,6 +504,8 @@ func (sm *SnapshotManager) snap() *deploy.Snapshot {
 	manifest := deploy.Manifest{
 		Time:    time.Now(),
 		Version: version.Version,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
 		Plugins: sm.plugins,
 	}
 
@@ -490,10 +515,16 @@ func (sm *SnapshotManager) snap() *deploy.Snapshot {
 
 // saveSnapshot persists the current snapshot and optionally verifies it afterwards.
 func (sm *SnapshotManager) saveSnapshot() error {
+	span := opentracing.StartSpan("pulumi-save-snapshot")
+	defer span.Finish()
+
 	snap := sm.snap()
 	if err := sm.persister.Save(snap); err != nil {
 		return errors.Wrap(err, "failed to save snapshot")
 	}
+	if sm.checkInvariants {
+		return checkSnapshotInvariants(snap)
+	}
 	if sm.doVerify {
 		if err := snap.VerifyIntegrity(); err != nil {
 			return errors.Wrapf(err, "failed to verify snapshot")
@@ -502,6 +533,42 @@ func (sm *SnapshotManager) saveSnapshot() error {
 	return nil
 }
 
+// CheckInvariantsEnvVar is the environment variable that, when set to a truthy value, causes the snapshot manager to
+// check the snapshot's invariants after every mutation, including those whose writes are elided.  This is expensive,
+// and intended for tests and for tracking down state corruption.
+const CheckInvariantsEnvVar = "PULUMI_CHECK_SNAPSHOT_INVARIANTS"
+
+// checkSnapshotInvariants verifies the snapshot's invariants, and that it survives a round trip through its
+// serialized form unchanged.
+func checkSnapshotInvariants(snap *deploy.Snapshot) error {
+	if err := snap.VerifyInvariants(); err != nil {
+		return errors.Wrap(err, "snapshot invariant violated")
+	}
+	if snap == nil {
+		return nil
+	}
+
+	// Compare the serialized forms as JSON, which is what is actually persisted; this ignores distinctions (such as
+	// nil versus empty maps) that do not survive persistence anyway.
+	deployment := stack.SerializeDeployment(snap)
+	roundTripped, err := stack.DeserializeDeploymentV2(*deployment)
+	if err != nil {
+		return errors.Wrap(err, "snapshot invariant violated: failed to deserialize snapshot")
+	}
+	before, err := json.Marshal(deployment)
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(stack.SerializeDeployment(roundTripped))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(before, after) {
+		return errors.New("snapshot invariant violated: snapshot changed during a serialization round trip")
+	}
+	return nil
+}
+
 // NewSnapshotManager creates a new SnapshotManager for the given stack name, using the given persister
 // and base snapshot.
 //
@@ -516,7 +583,9 @@ func NewSnapshotManager(persister SnapshotPersister, baseSnap *deploy.Snapshot)
 		baseSnapshot:     baseSnap,
 		dones:            make(map[*resource.State]bool),
 		completeOps:      make(map[*resource.State]bool),
+		unreferenced:     make(map[*resource.State]*resource.State),
 		doVerify:         true,
+		checkInvariants:  cmdutil.IsTruthy(os.Getenv(CheckInvariantsEnvVar)),
 		mutationRequests: mutationRequests,
 		cancel:           cancel,
 		done:             done,
@@ -537,6 +606,9 @@ func NewSnapshotManager(persister SnapshotPersister, baseSnap *deploy.Snapshot)
 					hasElidedWrites = false
 				} else {
 					hasElidedWrites = true
+					if manager.checkInvariants {
+						err = checkSnapshotInvariants(manager.snap())
+					}
 				}
 				request.result <- err
 			case <-cancel:
@@ -547,7 +619,7 @@ func NewSnapshotManager(persister SnapshotPersister, baseSnap *deploy.Snapshot)
 		// If we still have elided writes once the channel has closed, flush the snapshot.
 		var err error
 		if hasElidedWrites {
-			logging.V(9).Infof("SnapshotManager: flushing elided writes...")
+			logging.Backend.V(9).Infof("SnapshotManager: flushing elided writes...")
 			err = manager.saveSnapshot()
 		}
 		done <- err
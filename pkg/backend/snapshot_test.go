@@ -58,13 +58,8 @@ func MockSetup(t *testing.T, baseSnap *deploy.Snapshot) (*SnapshotManager, *Mock
 }
 
 func NewResource(name string, deps ...resource.URN) *resource.State {
-	return &resource.State{
-		Type:         tokens.Type("test"),
-		URN:          resource.URN(name),
-		Inputs:       make(resource.PropertyMap),
-		Outputs:      make(resource.PropertyMap),
-		Dependencies: deps,
-	}
+	return resource.NewState(tokens.Type("test"), resource.URN(name), false, false, "", make(resource.PropertyMap),
+		make(resource.PropertyMap), "", false, false, deps, nil, "")
 }
 
 func NewSnapshot(resources []*resource.State) *deploy.Snapshot {
@@ -200,7 +195,7 @@ func TestSamesWithOtherMeaningfulChanges(t *testing.T) {
 
 	// Change the resource outputs.
 	changes = append(changes, NewResource(string(resourceA.URN)))
-	changes[3].Outputs = resource.PropertyMap{"foo": resource.NewStringProperty("bar")}
+	changes[3].SetOutputs(resource.PropertyMap{"foo": resource.NewStringProperty("bar")})
 
 	snap := NewSnapshot([]*resource.State{
 		resourceP,
@@ -328,8 +323,8 @@ func TestVexingDeployment(t *testing.T) {
 	cPrime := NewResource(string(c.URN), bPrime.URN)
 
 	// mocking out the behavior of a provider indicating that this resource needs to be deleted
-	createReplacement := deploy.NewCreateReplacementStep(nil, MockRegisterResourceEvent{}, c, cPrime, nil, true)
-	replace := deploy.NewReplaceStep(nil, c, cPrime, nil, true)
+	createReplacement := deploy.NewCreateReplacementStep(nil, MockRegisterResourceEvent{}, c, cPrime, nil, nil, true)
+	replace := deploy.NewReplaceStep(nil, c, cPrime, nil, nil, true)
 	c.Delete = true
 
 	applyStep(createReplacement)
@@ -490,9 +485,9 @@ func TestRecordingCreateFailure(t *testing.T) {
 
 func TestRecordingUpdateSuccess(t *testing.T) {
 	resourceA := NewResource("a")
-	resourceA.Inputs["key"] = resource.NewStringProperty("old")
+	resourceA.Inputs()["key"] = resource.NewStringProperty("old")
 	resourceANew := NewResource("a")
-	resourceANew.Inputs["key"] = resource.NewStringProperty("new")
+	resourceANew.Inputs()["key"] = resource.NewStringProperty("new")
 	snap := NewSnapshot([]*resource.State{
 		resourceA,
 	})
@@ -511,7 +506,7 @@ func TestRecordingUpdateSuccess(t *testing.T) {
 	assert.Len(t, snap.PendingOperations, 1)
 	assert.Equal(t, resourceA.URN, snap.PendingOperations[0].Resource.URN)
 	assert.Equal(t, resource.OperationTypeUpdating, snap.PendingOperations[0].Type)
-	assert.Equal(t, resource.NewStringProperty("new"), snap.PendingOperations[0].Resource.Inputs["key"])
+	assert.Equal(t, resource.NewStringProperty("new"), snap.PendingOperations[0].Resource.Inputs()["key"])
 
 	err = mutation.End(step, true /* successful */)
 	if !assert.NoError(t, err) {
@@ -524,14 +519,14 @@ func TestRecordingUpdateSuccess(t *testing.T) {
 	assert.Len(t, snap.Resources, 1)
 	assert.Len(t, snap.PendingOperations, 0)
 	assert.Equal(t, resourceA.URN, snap.Resources[0].URN)
-	assert.Equal(t, resource.NewStringProperty("new"), snap.Resources[0].Inputs["key"])
+	assert.Equal(t, resource.NewStringProperty("new"), snap.Resources[0].Inputs()["key"])
 }
 
 func TestRecordingUpdateFailure(t *testing.T) {
 	resourceA := NewResource("a")
-	resourceA.Inputs["key"] = resource.NewStringProperty("old")
+	resourceA.Inputs()["key"] = resource.NewStringProperty("old")
 	resourceANew := NewResource("a")
-	resourceANew.Inputs["key"] = resource.NewStringProperty("new")
+	resourceANew.Inputs()["key"] = resource.NewStringProperty("new")
 	snap := NewSnapshot([]*resource.State{
 		resourceA,
 	})
@@ -550,7 +545,7 @@ func TestRecordingUpdateFailure(t *testing.T) {
 	assert.Len(t, snap.PendingOperations, 1)
 	assert.Equal(t, resourceA.URN, snap.PendingOperations[0].Resource.URN)
 	assert.Equal(t, resource.OperationTypeUpdating, snap.PendingOperations[0].Type)
-	assert.Equal(t, resource.NewStringProperty("new"), snap.PendingOperations[0].Resource.Inputs["key"])
+	assert.Equal(t, resource.NewStringProperty("new"), snap.PendingOperations[0].Resource.Inputs()["key"])
 
 	err = mutation.End(step, false /* successful */)
 	if !assert.NoError(t, err) {
@@ -563,7 +558,7 @@ func TestRecordingUpdateFailure(t *testing.T) {
 	assert.Len(t, snap.Resources, 1)
 	assert.Len(t, snap.PendingOperations, 0)
 	assert.Equal(t, resourceA.URN, snap.Resources[0].URN)
-	assert.Equal(t, resource.NewStringProperty("old"), snap.Resources[0].Inputs["key"])
+	assert.Equal(t, resource.NewStringProperty("old"), snap.Resources[0].Inputs()["key"])
 }
 
 func TestRecordingDeleteSuccess(t *testing.T) {
@@ -661,11 +656,11 @@ func TestRecordingReadSuccessPreviousResource(t *testing.T) {
 	resourceA := NewResource("a")
 	resourceA.External = true
 	resourceA.Custom = true
-	resourceA.Inputs["key"] = resource.NewStringProperty("old")
+	resourceA.Inputs()["key"] = resource.NewStringProperty("old")
 	resourceANew := NewResource("a")
 	resourceANew.External = true
 	resourceANew.Custom = true
-	resourceANew.Inputs["key"] = resource.NewStringProperty("new")
+	resourceANew.Inputs()["key"] = resource.NewStringProperty("new")
 
 	snap := NewSnapshot([]*resource.State{
 		resourceA,
@@ -684,9 +679,9 @@ func TestRecordingReadSuccessPreviousResource(t *testing.T) {
 	assert.Len(t, snap.PendingOperations, 1)
 	assert.Equal(t, resourceA.URN, snap.PendingOperations[0].Resource.URN)
 	assert.Equal(t, resource.OperationTypeReading, snap.PendingOperations[0].Type)
-	assert.Equal(t, resource.NewStringProperty("new"), snap.PendingOperations[0].Resource.Inputs["key"])
+	assert.Equal(t, resource.NewStringProperty("new"), snap.PendingOperations[0].Resource.Inputs()["key"])
 	assert.Equal(t, resourceA.URN, snap.Resources[0].URN)
-	assert.Equal(t, resource.NewStringProperty("old"), snap.Resources[0].Inputs["key"])
+	assert.Equal(t, resource.NewStringProperty("old"), snap.Resources[0].Inputs()["key"])
 	err = mutation.End(step, true /* successful */)
 	if !assert.NoError(t, err) {
 		t.FailNow()
@@ -697,7 +692,7 @@ func TestRecordingReadSuccessPreviousResource(t *testing.T) {
 	assert.Len(t, snap.Resources, 1)
 	assert.Len(t, snap.PendingOperations, 0)
 	assert.Equal(t, resourceA.URN, snap.Resources[0].URN)
-	assert.Equal(t, resource.NewStringProperty("new"), snap.Resources[0].Inputs["key"])
+	assert.Equal(t, resource.NewStringProperty("new"), snap.Resources[0].Inputs()["key"])
 }
 
 func TestRecordingReadFailureNoPreviousResource(t *testing.T) {
@@ -733,11 +728,11 @@ func TestRecordingReadFailurePreviousResource(t *testing.T) {
 	resourceA := NewResource("a")
 	resourceA.External = true
 	resourceA.Custom = true
-	resourceA.Inputs["key"] = resource.NewStringProperty("old")
+	resourceA.Inputs()["key"] = resource.NewStringProperty("old")
 	resourceANew := NewResource("a")
 	resourceANew.External = true
 	resourceANew.Custom = true
-	resourceANew.Inputs["key"] = resource.NewStringProperty("new")
+	resourceANew.Inputs()["key"] = resource.NewStringProperty("new")
 
 	snap := NewSnapshot([]*resource.State{
 		resourceA,
@@ -756,9 +751,9 @@ func TestRecordingReadFailurePreviousResource(t *testing.T) {
 	assert.Len(t, snap.PendingOperations, 1)
 	assert.Equal(t, resourceA.URN, snap.PendingOperations[0].Resource.URN)
 	assert.Equal(t, resource.OperationTypeReading, snap.PendingOperations[0].Type)
-	assert.Equal(t, resource.NewStringProperty("new"), snap.PendingOperations[0].Resource.Inputs["key"])
+	assert.Equal(t, resource.NewStringProperty("new"), snap.PendingOperations[0].Resource.Inputs()["key"])
 	assert.Equal(t, resourceA.URN, snap.Resources[0].URN)
-	assert.Equal(t, resource.NewStringProperty("old"), snap.Resources[0].Inputs["key"])
+	assert.Equal(t, resource.NewStringProperty("old"), snap.Resources[0].Inputs()["key"])
 	err = mutation.End(step, false /* successful */)
 	if !assert.NoError(t, err) {
 		t.FailNow()
@@ -770,7 +765,7 @@ func TestRecordingReadFailurePreviousResource(t *testing.T) {
 	assert.Len(t, snap.Resources, 1)
 	assert.Len(t, snap.PendingOperations, 0)
 	assert.Equal(t, resourceA.URN, snap.Resources[0].URN)
-	assert.Equal(t, resource.NewStringProperty("old"), snap.Resources[0].Inputs["key"])
+	assert.Equal(t, resource.NewStringProperty("old"), snap.Resources[0].Inputs()["key"])
 }
 
 func TestRegisterOutputs(t *testing.T) {
@@ -815,3 +810,37 @@ func TestSavePlugins(t *testing.T) {
 	assert.Len(t, lastSnap.Manifest.Plugins, 1)
 	assert.Equal(t, "myplugin", lastSnap.Manifest.Plugins[0].Name)
 }
+
+func TestCheckInvariants(t *testing.T) {
+	newResource := func(name string, custom bool) *resource.State {
+		typ := tokens.Type("test")
+		if custom {
+			// Provider resources are the only custom resources that may be created without a provider of their own.
+			typ = "pulumi:providers:test"
+		}
+		res := NewResource(string(resource.NewURN("stack", "proj", "", typ, tokens.QName(name))))
+		res.Type = typ
+		res.Custom = custom
+		return res
+	}
+
+	manager, _ := MockSetup(t, NewSnapshot(nil))
+	manager.checkInvariants = true
+
+	// A well-formed creation passes.
+	resourceA := newResource("a", false)
+	step := deploy.NewCreateStep(nil, &MockRegisterResourceEvent{}, resourceA)
+	mutation, err := manager.BeginMutation(step)
+	assert.NoError(t, err)
+	assert.NoError(t, mutation.End(step, true /* successful */))
+
+	// A custom resource recorded without an ID violates the snapshot's invariants.
+	resourceB := newResource("b", true)
+	step = deploy.NewCreateStep(nil, &MockRegisterResourceEvent{}, resourceB)
+	mutation, err = manager.BeginMutation(step)
+	assert.NoError(t, err)
+	err = mutation.End(step, true /* successful */)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "has no ID")
+	}
+}
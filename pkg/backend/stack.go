@@ -18,6 +18,7 @@ import (
 	"context"
 	"path/filepath"
 	"regexp"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -26,10 +27,40 @@ import (
 	"github.com/pulumi/pulumi/pkg/operations"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/gitutil"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
+// StackFilter narrows a listing of stacks.  Every field is optional, and the zero filter matches every stack.
+type StackFilter struct {
+	Project           *tokens.PackageName // only stacks of this project.
+	TagName           string              // only stacks with this tag...
+	TagValue          *string             // ...and, if set, with this value for it.
+	LastUpdatedBefore *time.Time          // only stacks that were last updated before this time.
+}
+
+// Matches returns true if a stack of the given project, with the given tags, that was last updated at the given time
+// passes the filter.  The project is empty if it is not known, in which case it is not filtered on; the time is zero if
+// the stack has never been updated.
+func (f StackFilter) Matches(project tokens.PackageName, tags map[apitype.StackTagName]string,
+	lastUpdate time.Time) bool {
+
+	if f.Project != nil && project != "" && project != *f.Project {
+		return false
+	}
+	if f.TagName != "" {
+		value, has := tags[f.TagName]
+		if !has || (f.TagValue != nil && value != *f.TagValue) {
+			return false
+		}
+	}
+	if f.LastUpdatedBefore != nil && (lastUpdate.IsZero() || !lastUpdate.Before(*f.LastUpdatedBefore)) {
+		return false
+	}
+	return true
+}
+
 // Stack is a stack associated with a particular backend implementation.
 type Stack interface {
 	Name() StackReference                                   // this stack's identity.
@@ -0,0 +1,53 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestStackFilterMatches(t *testing.T) {
+	now := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+	tags := map[apitype.StackTagName]string{"env": "prod"}
+
+	// The zero filter matches everything.
+	assert.True(t, StackFilter{}.Matches("", nil, time.Time{}))
+
+	// Stacks of unknown projects are not filtered on their project.
+	project := tokens.PackageName("web")
+	byProject := StackFilter{Project: &project}
+	assert.True(t, byProject.Matches("web", nil, now))
+	assert.True(t, byProject.Matches("", nil, now))
+	assert.False(t, byProject.Matches("api", nil, now))
+
+	prod, dev := "prod", "dev"
+	assert.True(t, StackFilter{TagName: "env"}.Matches("", tags, now))
+	assert.True(t, StackFilter{TagName: "env", TagValue: &prod}.Matches("", tags, now))
+	assert.False(t, StackFilter{TagName: "env", TagValue: &dev}.Matches("", tags, now))
+	assert.False(t, StackFilter{TagName: "owner"}.Matches("", tags, now))
+
+	// Stacks that have never been updated were not updated before any time.
+	before := now.Add(time.Hour)
+	byTime := StackFilter{LastUpdatedBefore: &before}
+	assert.True(t, byTime.Matches("", nil, now))
+	assert.False(t, byTime.Matches("", nil, before))
+	assert.False(t, byTime.Matches("", nil, time.Time{}))
+}
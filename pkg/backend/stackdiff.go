@@ -0,0 +1,113 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// ResourceDiff pairs up the states of a single logical resource as it exists in two different snapshots.
+type ResourceDiff struct {
+	Base  *resource.State      // the resource's state in the base snapshot.
+	Other *resource.State      // the resource's state in the other snapshot.
+	Diff  *resource.ObjectDiff // the difference between the two states' inputs.
+}
+
+// SnapshotDiff describes the resource-level differences between two snapshots.
+type SnapshotDiff struct {
+	OnlyInBase  []*resource.State // resources present only in the base snapshot.
+	OnlyInOther []*resource.State // resources present only in the other snapshot.
+	Changed     []ResourceDiff    // resources present in both snapshots, but whose inputs differ.
+	Same        []ResourceDiff    // resources present in both snapshots with identical inputs.
+}
+
+// HasChanges returns true if the two snapshots differ in any way.
+func (d *SnapshotDiff) HasChanges() bool {
+	return len(d.OnlyInBase) > 0 || len(d.OnlyInOther) > 0 || len(d.Changed) > 0
+}
+
+// stacklessURN returns the portion of a URN that does not depend on the stack it belongs to, so that resources from
+// two stacks of the same project may be matched up with one another.
+func stacklessURN(urn resource.URN) string {
+	return string(urn.Project()) + resource.URNNameDelimiter + string(urn.QualifiedType()) +
+		resource.URNNameDelimiter + string(urn.Name())
+}
+
+// liveResources returns the resources in the given snapshot that are not pending deletion, keyed by stackless URN.
+func liveResources(snap *deploy.Snapshot) ([]*resource.State, map[string]*resource.State) {
+	var order []*resource.State
+	live := make(map[string]*resource.State)
+	if snap == nil {
+		return order, live
+	}
+	for _, res := range snap.Resources {
+		if res.Delete {
+			continue
+		}
+		order = append(order, res)
+		live[stacklessURN(res.URN)] = res
+	}
+	return order, live
+}
+
+// snapshotProject returns the name of the project to which the resources in the given snapshot belong, or "" if it has
+// none.
+func snapshotProject(snap *deploy.Snapshot) tokens.PackageName {
+	if snap == nil || len(snap.Resources) == 0 {
+		return ""
+	}
+	return snap.Resources[0].URN.Project()
+}
+
+// DiffSnapshots computes the resource-level differences between two snapshots, which may belong to different stacks
+// of the same project. Resources are matched up using their URNs, ignoring the stack portion.  It is an error for the
+// snapshots to belong to different projects, whose resources would never match.
+func DiffSnapshots(base, other *deploy.Snapshot) (*SnapshotDiff, error) {
+	baseProject, otherProject := snapshotProject(base), snapshotProject(other)
+	if baseProject != "" && otherProject != "" && baseProject != otherProject {
+		return nil, errors.Errorf("cannot compare stacks of different projects ('%s' and '%s')",
+			baseProject, otherProject)
+	}
+
+	baseOrder, baseLive := liveResources(base)
+	otherOrder, otherLive := liveResources(other)
+
+	result := &SnapshotDiff{}
+	for _, res := range baseOrder {
+		o, has := otherLive[stacklessURN(res.URN)]
+		if !has {
+			result.OnlyInBase = append(result.OnlyInBase, res)
+			continue
+		}
+
+		d := ResourceDiff{Base: res, Other: o, Diff: res.Inputs().Diff(o.Inputs())}
+		if d.Diff == nil {
+			result.Same = append(result.Same, d)
+		} else {
+			result.Changed = append(result.Changed, d)
+		}
+	}
+	for _, res := range otherOrder {
+		if _, has := baseLive[stacklessURN(res.URN)]; !has {
+			result.OnlyInOther = append(result.OnlyInOther, res)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func newStackResource(stack, name string, props resource.PropertyMap) *resource.State {
+	t := tokens.Type("test:index:resource")
+	return resource.NewState(t, resource.NewURN(tokens.QName(stack), "proj", "", t, tokens.QName(name)), true, false,
+		"", props, nil, "", false, false, nil, nil, "")
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	base := NewSnapshot([]*resource.State{
+		newStackResource("staging", "a", resource.PropertyMap{"x": resource.NewNumberProperty(1)}),
+		newStackResource("staging", "b", resource.PropertyMap{"x": resource.NewNumberProperty(1)}),
+		newStackResource("staging", "c", resource.PropertyMap{}),
+	})
+	other := NewSnapshot([]*resource.State{
+		newStackResource("prod", "a", resource.PropertyMap{"x": resource.NewNumberProperty(1)}),
+		newStackResource("prod", "b", resource.PropertyMap{"x": resource.NewNumberProperty(2)}),
+		newStackResource("prod", "d", resource.PropertyMap{}),
+	})
+
+	diff, err := DiffSnapshots(base, other)
+	assert.NoError(t, err)
+	assert.True(t, diff.HasChanges())
+	if assert.Len(t, diff.Same, 1) {
+		assert.Equal(t, tokens.QName("a"), diff.Same[0].Base.URN.Name())
+	}
+	if assert.Len(t, diff.Changed, 1) {
+		assert.Equal(t, tokens.QName("b"), diff.Changed[0].Base.URN.Name())
+		assert.True(t, diff.Changed[0].Diff.Updated("x"))
+	}
+	if assert.Len(t, diff.OnlyInBase, 1) {
+		assert.Equal(t, tokens.QName("c"), diff.OnlyInBase[0].URN.Name())
+	}
+	if assert.Len(t, diff.OnlyInOther, 1) {
+		assert.Equal(t, tokens.QName("d"), diff.OnlyInOther[0].URN.Name())
+	}
+}
+
+func TestDiffSnapshotsIgnoresPendingDeletes(t *testing.T) {
+	deleted := newStackResource("staging", "a", resource.PropertyMap{})
+	deleted.Delete = true
+	base := NewSnapshot([]*resource.State{deleted})
+
+	diff, err := DiffSnapshots(base, nil)
+	assert.NoError(t, err)
+	assert.False(t, diff.HasChanges())
+}
+
+func TestDiffSnapshotsOfDifferentProjects(t *testing.T) {
+	typ := tokens.Type("test:index:resource")
+	base := NewSnapshot([]*resource.State{newStackResource("staging", "a", resource.PropertyMap{})})
+	other := NewSnapshot([]*resource.State{
+		resource.NewState(typ, resource.NewURN("prod", "other", "", typ, "a"), true, false, "",
+			resource.PropertyMap{}, nil, "", false, false, nil, nil, ""),
+	})
+
+	_, err := DiffSnapshots(base, other)
+	assert.Error(t, err)
+}
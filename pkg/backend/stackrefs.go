@@ -0,0 +1,35 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import "path"
+
+// AffectedStackConsumers returns the stacks, of those that consume a stack's outputs, which read any of the given
+// changed outputs, along with the changed outputs each of them reads.  Consumers read outputs by name or by a pattern
+// in which `*` matches anything; patterns that are malformed match nothing.
+func AffectedStackConsumers(consumers map[string][]string, changed []string) map[string][]string {
+	affected := make(map[string][]string)
+	for consumer, patterns := range consumers {
+		for _, output := range changed {
+			for _, pattern := range patterns {
+				if matched, err := path.Match(pattern, output); err == nil && matched {
+					affected[consumer] = append(affected[consumer], output)
+					break
+				}
+			}
+		}
+	}
+	return affected
+}
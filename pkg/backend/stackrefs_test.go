@@ -0,0 +1,40 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffectedStackConsumers(t *testing.T) {
+	consumers := map[string][]string{
+		"api":     {"dbEndpoint", "vpcId"},
+		"web":     {"cdn*"},
+		"reports": {"dbEndpoint"},
+		"broken":  {"["},
+	}
+
+	affected := AffectedStackConsumers(consumers, []string{"cdnDomain", "dbEndpoint", "subnetIds"})
+	assert.Equal(t, map[string][]string{
+		"api":     {"dbEndpoint"},
+		"web":     {"cdnDomain"},
+		"reports": {"dbEndpoint"},
+	}, affected)
+
+	assert.Empty(t, AffectedStackConsumers(consumers, []string{"subnetIds"}))
+	assert.Empty(t, AffectedStackConsumers(nil, []string{"vpcId"}))
+}
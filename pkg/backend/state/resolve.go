@@ -0,0 +1,109 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/cloud"
+	"github.com/pulumi/pulumi/pkg/backend/local"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// stackURISeparator separates the backend URL of a stack URI from the name of the stack within that backend.
+const stackURISeparator = "#"
+
+// IsStackURI returns true if the given stack reference names the backend that the stack lives in, rather than
+// referring to a stack in the current backend.
+func IsStackURI(s string) bool {
+	return strings.Contains(s, stackURISeparator)
+}
+
+// ParseStackURI splits a stack URI of the form `<backend-url>#<stack>` into the URL of the backend and the name of
+// the stack, e.g. `local:///var/pulumi#dev` or `https://api.pulumi.com#acme/dev`.
+func ParseStackURI(s string) (string, string, error) {
+	idx := strings.LastIndex(s, stackURISeparator)
+	if idx == -1 {
+		return "", "", errors.Errorf("stack URI '%s' does not have the form <backend-url>#<stack>", s)
+	}
+	url, name := s[:idx], s[idx+1:]
+	if url == "" || name == "" {
+		return "", "", errors.Errorf("stack URI '%s' does not have the form <backend-url>#<stack>", s)
+	}
+	return url, name, nil
+}
+
+// BackendForURL returns the backend at the given URL without selecting it as the current one.  Local backends need no
+// credentials.  Cloud backends use the access token stored for their URL by `pulumi login`, or else the one in the
+// PULUMI_ACCESS_TOKEN environment variable, so that a stack may refer to stacks in backends other than its own.
+func BackendForURL(d diag.Sink, url string) (backend.Backend, error) {
+	if local.IsLocalBackendURL(url) {
+		return local.New(d, url), nil
+	}
+
+	token, err := workspace.GetAccessToken(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting stored credentials")
+	}
+	if token == "" {
+		token = os.Getenv(cloud.AccessTokenEnvVar)
+	}
+	if token == "" {
+		return nil, errors.Errorf("no credentials are available for the backend at %s; run `pulumi login %s` or set %s",
+			url, url, cloud.AccessTokenEnvVar)
+	}
+	return cloud.NewWithAccessToken(d, url, token), nil
+}
+
+// ResolveStackReference returns the backend in which the stack named by the given reference lives, along with the
+// backend's reference to it.  A stack URI is resolved against the backend it names; any other reference is resolved
+// against the given current backend, which may be nil only if the reference is a stack URI.
+func ResolveStackReference(d diag.Sink, current backend.Backend,
+	s string) (backend.Backend, backend.StackReference, error) {
+
+	b, name := current, s
+	if IsStackURI(s) {
+		url, stackName, err := ParseStackURI(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		if b, err = BackendForURL(d, url); err != nil {
+			return nil, nil, err
+		}
+		name = stackName
+	}
+
+	ref, err := b.ParseStackReference(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, ref, nil
+}
+
+// ResolveStack returns the stack named by the given reference, which is resolved as by ResolveStackReference.  If the
+// stack does not exist, nil is returned.
+func ResolveStack(ctx context.Context, d diag.Sink, current backend.Backend, s string) (backend.Stack, error) {
+	b, ref, err := ResolveStackReference(d, current, s)
+	if err != nil {
+		return nil, err
+	}
+	return b.GetStack(ctx, ref)
+}
@@ -0,0 +1,62 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+)
+
+func TestParseStackURI(t *testing.T) {
+	assert.False(t, IsStackURI("dev"))
+	assert.True(t, IsStackURI("local:///var/pulumi#dev"))
+
+	url, name, err := ParseStackURI("local:///var/pulumi#dev")
+	assert.NoError(t, err)
+	assert.Equal(t, "local:///var/pulumi", url)
+	assert.Equal(t, "dev", name)
+
+	url, name, err = ParseStackURI("https://api.pulumi.com#acme/dev")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.pulumi.com", url)
+	assert.Equal(t, "acme/dev", name)
+
+	for _, bad := range []string{"dev", "#dev", "local://", "local:///var/pulumi#"} {
+		_, _, err = ParseStackURI(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestResolveStackInOtherBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-state-")
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	d := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{})
+	b, err := BackendForURL(d, "local://"+dir)
+	assert.NoError(t, err)
+	assert.NotNil(t, b)
+
+	// The stack does not exist in the other backend, which needs no current backend to resolve it.
+	s, err := ResolveStack(context.Background(), d, nil, "local://"+dir+"#dev")
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 // UpdateMetadata describes optional metadata about an update.
@@ -57,6 +58,9 @@ const (
 	// GitAuthorEmail is the email address associated with the commit's author.
 	GitAuthorEmail = "git.author.email"
 
+	// GitTags is a comma-separated list of the names of the tags that point at the commit at HEAD.
+	GitTags = "git.tags"
+
 	// GitHubLogin is the user/organization who owns the local repo, if the origin remote is hosted on GitHub.com.
 	GitHubLogin = "github.login"
 	// GitHubRepo is the name of the GitHub repo, if the local git repo's remote origin is hosted on GitHub.com.
@@ -74,10 +78,41 @@ const (
 	// CIPRHeadSHA is the SHA of the HEAD commit of a pull request running on CI. This is needed since the CI
 	// server will run at a different, merge commit. (headSHA merged into the target branch.)
 	CIPRHeadSHA = "ci.pr.headSHA"
+
+	// GuardOverrideReason is the reason given for overriding the stack's guard, if the update did so.
+	GuardOverrideReason = "guard.override.reason"
+
+	// UserMetadataPrefix prefixes the keys of arbitrary metadata attached to an update by its user, e.g. to link the
+	// update to a ticket.
+	UserMetadataPrefix = "metadata."
+
+	// PulumiVersion is the version of the Pulumi CLI that performed the update.
+	PulumiVersion = "pulumi.version"
+	// PulumiOS is the operating system on which the Pulumi CLI ran, e.g. "linux".
+	PulumiOS = "pulumi.os"
+	// PulumiArch is the architecture on which the Pulumi CLI ran, e.g. "amd64".
+	PulumiArch = "pulumi.arch"
+	// PluginVersionPrefix prefixes the keys that record the versions of the plugins that an update loaded, which are
+	// followed by the plugin's kind and name, e.g. "plugin.resource.aws".  Unknown versions are recorded as empty.
+	PluginVersionPrefix = "plugin."
 )
 
+// AddPluginsToEnvironment records the versions of the given plugins in an update's environment.
+func AddPluginsToEnvironment(plugins []workspace.PluginInfo, env map[string]string) {
+	for _, plugin := range plugins {
+		var version string
+		if plugin.Version != nil {
+			version = plugin.Version.String()
+		}
+		env[PluginVersionPrefix+string(plugin.Kind)+"."+plugin.Name] = version
+	}
+}
+
 // UpdateInfo describes a previous update.
 type UpdateInfo struct {
+	// Version is the stack's version after this update, starting at 1 for the stack's first update.
+	Version int `json:"version"`
+
 	// Information known before an update is started.
 	Kind      apitype.UpdateKind `json:"kind"`
 	StartTime int64              `json:"startTime"`
@@ -96,4 +131,10 @@ type UpdateInfo struct {
 	Result          UpdateResult           `json:"result"`
 	EndTime         int64                  `json:"endTime"`
 	ResourceChanges engine.ResourceChanges `json:"resourceChanges,omitempty"`
+
+	// OutputChanges lists the names of the stack outputs whose values the update changed.
+	OutputChanges []string `json:"outputChanges,omitempty"`
+
+	// StepTimings records how long each of the resource operations that the update applied took.
+	StepTimings []engine.StepTiming `json:"stepTimings,omitempty"`
 }
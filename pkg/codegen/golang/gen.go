@@ -0,0 +1,300 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package golang generates Go SDKs from provider schemas.  Each module in the schema becomes a Go package containing
+// one file per resource and function, written against the `github.com/pulumi/pulumi/sdk/go/pulumi` programming model.
+package golang
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/codegen"
+	"github.com/pulumi/pulumi/pkg/codegen/schema"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// GeneratePackage generates a Go SDK for the given package.  The result maps paths, relative to the root of the SDK,
+// to the contents of the files at those paths.  tool names the program that generated the code; it is recorded in
+// each file's header.
+func GeneratePackage(tool string, pkg *schema.Package) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	emit := func(tok string, body func(w *bytes.Buffer, name string) error) error {
+		member := tokens.ModuleMember(tok)
+		dir, pkgName := modulePackage(pkg.Name, string(member.Module().Name()))
+		name := fieldName(string(member.Name()))
+
+		var w bytes.Buffer
+		fmt.Fprintf(&w, "// *** WARNING: this file was generated by %s. ***\n", tool)
+		fmt.Fprintf(&w, "// *** Do not edit by hand unless you're certain you know what you are doing! ***\n\n")
+		fmt.Fprintf(&w, "package %s\n\n", pkgName)
+		if err := body(&w, name); err != nil {
+			return errors.Wrapf(err, "generating %s", tok)
+		}
+
+		code, err := format.Source(w.Bytes())
+		if err != nil {
+			return errors.Wrapf(err, "formatting generated code for %s", tok)
+		}
+		file := path.Join(dir, codegen.CamelToSnake(name)+".go")
+		if _, has := files[file]; has {
+			return errors.Errorf("%s: more than one member would be generated into %s", tok, file)
+		}
+		files[file] = code
+		return nil
+	}
+
+	for _, tok := range pkg.ResourceTokens() {
+		res := pkg.Resources[tok]
+		err := emit(tok, func(w *bytes.Buffer, name string) error {
+			return genResource(w, tok, name, res)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, tok := range pkg.FunctionTokens() {
+		fn := pkg.Functions[tok]
+		err := emit(tok, func(w *bytes.Buffer, name string) error {
+			return genFunction(w, tok, name, fn)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// modulePackage returns the directory and Go package name for a schema module.  Members of the `index` module (or
+// of no module at all) live at the root of the SDK; other modules use the first component of their name, so that
+// `aws:s3/bucket:Bucket` is generated into package `s3`.
+func modulePackage(pkgName, module string) (string, string) {
+	module = strings.SplitN(module, "/", 2)[0]
+	if module == "" || module == "index" {
+		return "", goPackageName(pkgName)
+	}
+	name := goPackageName(module)
+	return name, name
+}
+
+// goPackageName turns an arbitrary name into a legal Go package name.
+func goPackageName(name string) string {
+	var b bytes.Buffer
+	for _, c := range strings.ToLower(name) {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			b.WriteRune(c)
+		}
+	}
+	if b.Len() == 0 || !unicode.IsLetter(rune(b.String()[0])) {
+		return "pkg" + b.String()
+	}
+	return b.String()
+}
+
+func genResource(w *bytes.Buffer, tok, name string, res schema.Resource) error {
+	genImports(w, len(res.RequiredInputs) > 0)
+
+	// First, the resource type itself.
+	genComment(w, res.Description, fmt.Sprintf("%s is a %s resource.", name, tok))
+	fmt.Fprintf(w, "type %s struct {\n\ts *pulumi.ResourceState\n}\n\n", name)
+
+	// Next, the constructor, which validates required arguments before registering the resource.
+	inputs := sortedProperties(res.InputProperties)
+	required := make(map[string]bool)
+	for _, p := range res.RequiredInputs {
+		required[p] = true
+	}
+	fmt.Fprintf(w, "// New%s registers a new resource with the given unique name, arguments, and options.\n", name)
+	fmt.Fprintf(w, "func New%s(ctx *pulumi.Context,\n", name)
+	fmt.Fprintf(w, "\tname string, args *%sArgs, opts ...pulumi.ResourceOpt) (*%s, error) {\n", name, name)
+	for _, p := range inputs {
+		if required[p] {
+			fmt.Fprintf(w, "\tif args == nil || args.%s == nil {\n", fieldName(p))
+			fmt.Fprintf(w, "\t\treturn nil, errors.New(\"missing required argument '%s'\")\n\t}\n", fieldName(p))
+		}
+	}
+	fmt.Fprintf(w, "\tinputs := make(map[string]interface{})\n")
+	if len(inputs) > 0 {
+		fmt.Fprintf(w, "\tif args == nil {\n")
+		for _, p := range inputs {
+			fmt.Fprintf(w, "\t\tinputs[%q] = nil\n", p)
+		}
+		fmt.Fprintf(w, "\t} else {\n")
+		for _, p := range inputs {
+			fmt.Fprintf(w, "\t\tinputs[%q] = args.%s\n", p, fieldName(p))
+		}
+		fmt.Fprintf(w, "\t}\n")
+	}
+	fmt.Fprintf(w, "\ts, err := ctx.RegisterResource(%q, name, true, inputs, opts...)\n", tok)
+	fmt.Fprintf(w, "\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(w, "\treturn &%s{s: s}, nil\n}\n\n", name)
+
+	// Then, the standard URN and ID accessors, followed by typed accessors for each output property.
+	fmt.Fprintf(w, "// URN is this resource's unique name assigned by Pulumi.\n")
+	fmt.Fprintf(w, "func (r *%s) URN() *pulumi.URNOutput {\n\treturn r.s.URN\n}\n\n", name)
+	fmt.Fprintf(w, "// ID is this resource's unique identifier assigned by its provider.\n")
+	fmt.Fprintf(w, "func (r *%s) ID() *pulumi.IDOutput {\n\treturn r.s.ID\n}\n\n", name)
+	for _, p := range sortedProperties(res.Properties) {
+		if p == "id" || p == "urn" {
+			continue
+		}
+		prop := res.Properties[p]
+		field, typ := fieldName(p), outputType(prop)
+		genComment(w, prop.Description, "")
+		if typ == "*pulumi.Output" {
+			fmt.Fprintf(w, "func (r *%s) %s() %s {\n\treturn r.s.State[%q]\n}\n\n", name, field, typ, p)
+		} else {
+			fmt.Fprintf(w, "func (r *%s) %s() %s {\n\treturn (%s)(r.s.State[%q])\n}\n\n", name, field, typ, typ, p)
+		}
+	}
+
+	// Finally, the arguments type.
+	fmt.Fprintf(w, "// %sArgs is the set of arguments for constructing a %s resource.\n", name, name)
+	genStruct(w, name+"Args", res.InputProperties)
+	return nil
+}
+
+func genFunction(w *bytes.Buffer, tok, name string, fn schema.Function) error {
+	inputs := sortedProperties(fn.Inputs)
+	required := make(map[string]bool)
+	for _, p := range fn.RequiredInputs {
+		required[p] = true
+	}
+	genImports(w, len(fn.RequiredInputs) > 0)
+
+	genComment(w, fn.Description, fmt.Sprintf("%s invokes the %s function.", name, tok))
+	fmt.Fprintf(w, "func %s(ctx *pulumi.Context, args *%sArgs) (*%sResult, error) {\n", name, name, name)
+	for _, p := range inputs {
+		if required[p] {
+			fmt.Fprintf(w, "\tif args == nil || args.%s == nil {\n", fieldName(p))
+			fmt.Fprintf(w, "\t\treturn nil, errors.New(\"missing required argument '%s'\")\n\t}\n", fieldName(p))
+		}
+	}
+	fmt.Fprintf(w, "\tinputs := make(map[string]interface{})\n")
+	if len(inputs) > 0 {
+		fmt.Fprintf(w, "\tif args != nil {\n")
+		for _, p := range inputs {
+			fmt.Fprintf(w, "\t\tinputs[%q] = args.%s\n", p, fieldName(p))
+		}
+		fmt.Fprintf(w, "\t}\n")
+	}
+	fmt.Fprintf(w, "\touts, err := ctx.Invoke(%q, inputs)\n", tok)
+	fmt.Fprintf(w, "\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(w, "\treturn &%sResult{\n", name)
+	for _, p := range sortedProperties(fn.Outputs) {
+		fmt.Fprintf(w, "\t\t%s: outs[%q],\n", fieldName(p), p)
+	}
+	fmt.Fprintf(w, "\t}, nil\n}\n\n")
+
+	fmt.Fprintf(w, "// %sArgs is the set of arguments for invoking %s.\n", name, name)
+	genStruct(w, name+"Args", fn.Inputs)
+	fmt.Fprintf(w, "// %sResult is the result of invoking %s.\n", name, name)
+	genStruct(w, name+"Result", fn.Outputs)
+	return nil
+}
+
+// genImports emits the imports for a generated file.  The errors package is only needed to report missing required
+// arguments.
+func genImports(w *bytes.Buffer, needErrors bool) {
+	fmt.Fprintf(w, "import (\n")
+	if needErrors {
+		fmt.Fprintf(w, "\t\"github.com/pkg/errors\"\n")
+	}
+	fmt.Fprintf(w, "\t\"github.com/pulumi/pulumi/sdk/go/pulumi\"\n)\n\n")
+}
+
+// genStruct emits a struct whose fields hold the given properties.  Fields are untyped so that they may hold either
+// prompt values or outputs from other resources.
+func genStruct(w *bytes.Buffer, name string, props map[string]schema.Property) {
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for _, p := range sortedProperties(props) {
+		genComment(w, props[p].Description, "")
+		fmt.Fprintf(w, "\t%s interface{}\n", fieldName(p))
+	}
+	fmt.Fprintf(w, "}\n\n")
+}
+
+// genComment emits a doc comment containing the given description, or the fallback if there is no description.
+func genComment(w *bytes.Buffer, description, fallback string) {
+	if description == "" {
+		description = fallback
+	}
+	if description == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(description), "\n") {
+		fmt.Fprintf(w, "// %s\n", strings.TrimRightFunc(line, unicode.IsSpace))
+	}
+}
+
+// outputType returns the type of the output accessor for a property of the given type.
+func outputType(prop schema.Property) string {
+	switch prop.Type {
+	case schema.StringType:
+		return "*pulumi.StringOutput"
+	case schema.NumberType:
+		return "*pulumi.Float64Output"
+	case schema.IntegerType:
+		return "*pulumi.IntOutput"
+	case schema.BooleanType:
+		return "*pulumi.BoolOutput"
+	case schema.ArrayType:
+		return "*pulumi.ArrayOutput"
+	case schema.ObjectType:
+		return "*pulumi.MapOutput"
+	default:
+		return "*pulumi.Output"
+	}
+}
+
+// fieldName turns a property name such as `bucketPrefix` or `bucket_prefix` into an exported Go identifier such as
+// `BucketPrefix`.
+func fieldName(prop string) string {
+	var b bytes.Buffer
+	upper := true
+	for _, c := range prop {
+		switch {
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			if upper {
+				b.WriteRune(unicode.ToUpper(c))
+			} else {
+				b.WriteRune(c)
+			}
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		name = "P" + name
+	}
+	return name
+}
+
+func sortedProperties(props map[string]schema.Property) []string {
+	var names []string
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,141 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/codegen/schema"
+)
+
+const testSchema = `{
+    "name": "test",
+    "version": "0.1.0",
+    "resources": {
+        "test:storage/bucket:Bucket": {
+            "description": "Bucket is an object storage bucket.",
+            "inputProperties": {
+                "bucketName": {"type": "string"},
+                "versioning": {"type": "boolean"},
+                "tags": {"type": "object", "additionalProperties": {"type": "string"}}
+            },
+            "requiredInputs": ["bucketName"],
+            "properties": {
+                "id": {"type": "string"},
+                "arn": {"type": "string", "description": "The bucket's ARN."},
+                "size": {"type": "integer"},
+                "metadata": {}
+            }
+        },
+        "test:index:Widget": {}
+    },
+    "functions": {
+        "test:storage/getBucket:getBucket": {
+            "inputs": {"name": {"type": "string"}},
+            "outputs": {"arn": {"type": "string"}}
+        }
+    }
+}`
+
+func TestGeneratePackage(t *testing.T) {
+	pkg, err := schema.Parse([]byte(testSchema))
+	assert.NoError(t, err)
+
+	files, err := GeneratePackage("test", pkg)
+	assert.NoError(t, err)
+	assert.Len(t, files, 3)
+
+	bucket := string(files["storage/bucket.go"])
+	assert.Contains(t, bucket, "package storage")
+	assert.Contains(t, bucket, "// Bucket is an object storage bucket.\ntype Bucket struct")
+	assert.Contains(t, bucket, "func NewBucket(ctx *pulumi.Context,")
+	assert.Contains(t, bucket, `return nil, errors.New("missing required argument 'BucketName'")`)
+	assert.Contains(t, bucket, `inputs["bucketName"] = args.BucketName`)
+	assert.Contains(t, bucket, `ctx.RegisterResource("test:storage/bucket:Bucket", name, true, inputs, opts...)`)
+	assert.Contains(t, bucket, "// The bucket's ARN.\nfunc (r *Bucket) Arn() *pulumi.StringOutput {")
+	assert.Contains(t, bucket, "func (r *Bucket) Size() *pulumi.IntOutput {")
+	assert.Contains(t, bucket, "func (r *Bucket) Metadata() *pulumi.Output {")
+	assert.NotContains(t, bucket, "func (r *Bucket) Id()")
+	assert.Contains(t, bucket, "Versioning interface{}")
+
+	widget := string(files["widget.go"])
+	assert.Contains(t, widget, "package test")
+	assert.NotContains(t, widget, "github.com/pkg/errors")
+
+	getBucket := string(files["storage/get_bucket.go"])
+	assert.Contains(t, getBucket, "func GetBucket(ctx *pulumi.Context, args *GetBucketArgs) (*GetBucketResult, error) {")
+	assert.Contains(t, getBucket, `ctx.Invoke("test:storage/getBucket:getBucket", inputs)`)
+	assert.Contains(t, getBucket, `Arn: outs["arn"],`)
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, "BucketPrefix", fieldName("bucketPrefix"))
+	assert.Equal(t, "BucketPrefix", fieldName("bucket_prefix"))
+	assert.Equal(t, "P3name", fieldName("3name"))
+
+	dir, name := modulePackage("my-cloud", "index")
+	assert.Equal(t, "", dir)
+	assert.Equal(t, "mycloud", name)
+	dir, name = modulePackage("aws", "s3/bucket")
+	assert.Equal(t, "s3", dir)
+	assert.Equal(t, "s3", name)
+}
+
+// TestGeneratedPackageTypeChecks ensures that the generated SDK is formatted and type-checks against the Go SDK, rather
+// than only looking for snippets of it.
+func TestGeneratedPackageTypeChecks(t *testing.T) {
+	pkg, err := schema.Parse([]byte(testSchema))
+	assert.NoError(t, err)
+	files, err := GeneratePackage("test", pkg)
+	assert.NoError(t, err)
+
+	// Group the files into their Go packages, one per directory.
+	fset := token.NewFileSet()
+	packages := make(map[string][]*ast.File)
+	var paths []string
+	for name := range files {
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+	for _, name := range paths {
+		code := files[name]
+		formatted, err := format.Source(code)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, string(formatted), string(code), "%s is not formatted", name)
+		}
+		f, err := parser.ParseFile(fset, name, code, parser.ParseComments)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		dir := path.Dir(name)
+		packages[dir] = append(packages[dir], f)
+	}
+
+	conf := types.Config{Importer: importer.For("source", nil)}
+	for dir, pkgFiles := range packages {
+		_, err := conf.Check(path.Join("github.com/pulumi/pulumi-test", dir), fset, pkgFiles, nil)
+		assert.NoError(t, err, "type-checking %s", dir)
+	}
+}
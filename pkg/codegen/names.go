@@ -0,0 +1,43 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codegen contains helpers shared by the code generators, and by other tools that map between the names that
+// Pulumi schemas use and those of other languages and systems.
+package codegen
+
+import (
+	"bytes"
+	"unicode"
+)
+
+// CamelToSnake turns a camel- or Pascal-cased name such as `instanceType` or `BucketPolicy` into a snake-cased name
+// such as `instance_type` or `bucket_policy`.  Acronyms are kept together, so `getHTTPEndpoint` becomes
+// `get_http_endpoint`.
+func CamelToSnake(name string) string {
+	var b bytes.Buffer
+	runes := []rune(name)
+	for i, c := range runes {
+		if unicode.IsUpper(c) {
+			// Start a new word at a lower-to-upper transition, or at the last capital of an acronym.
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1]))) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(c))
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,28 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCamelToSnake(t *testing.T) {
+	assert.Equal(t, "instance_type", CamelToSnake("instanceType"))
+	assert.Equal(t, "bucket_policy", CamelToSnake("BucketPolicy"))
+	assert.Equal(t, "get_http_endpoint", CamelToSnake("getHTTPEndpoint"))
+	assert.Equal(t, "name", CamelToSnake("name"))
+}
@@ -0,0 +1,272 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema defines the format in which resource providers describe the resources and functions they implement.
+// Schemas are returned by a provider's GetSchema RPC and are the input to SDK code generation.
+package schema
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// The primitive and structural types that a property may have.
+const (
+	StringType  = "string"
+	NumberType  = "number"
+	IntegerType = "integer"
+	BooleanType = "boolean"
+	ArrayType   = "array"
+	ObjectType  = "object"
+	AnyType     = "any"
+)
+
+// Package describes a resource provider's package: its configuration, resources, and functions.
+type Package struct {
+	// Name is the name of the package, e.g. `aws`.
+	Name string `json:"name"`
+	// Version is the version of the package, if known.
+	Version string `json:"version,omitempty"`
+	// Description is an optional description of the package.
+	Description string `json:"description,omitempty"`
+	// Config describes the configuration variables accepted by the package's provider.
+	Config map[string]Property `json:"config,omitempty"`
+	// Resources maps resource type tokens to their descriptions.
+	Resources map[string]Resource `json:"resources,omitempty"`
+	// Functions maps function tokens to their descriptions.
+	Functions map[string]Function `json:"functions,omitempty"`
+}
+
+// Resource describes a single resource type.
+type Resource struct {
+	// Description is an optional description of the resource.
+	Description string `json:"description,omitempty"`
+	// InputProperties are the properties that may be supplied when creating the resource.
+	InputProperties map[string]Property `json:"inputProperties,omitempty"`
+	// RequiredInputs lists the input properties that must be supplied.
+	RequiredInputs []string `json:"requiredInputs,omitempty"`
+	// Properties are the output properties of the resource.
+	Properties map[string]Property `json:"properties,omitempty"`
+	// TerraformType is the type of the Terraform resource that this resource corresponds to, if any, e.g.
+	// `aws_instance`.  It allows Terraform state to be imported as instances of this resource.
+	TerraformType string `json:"terraformType,omitempty"`
+}
+
+// Function describes a single provider function that may be invoked by a program.
+type Function struct {
+	// Description is an optional description of the function.
+	Description string `json:"description,omitempty"`
+	// Inputs are the arguments accepted by the function.
+	Inputs map[string]Property `json:"inputs,omitempty"`
+	// RequiredInputs lists the arguments that must be supplied.
+	RequiredInputs []string `json:"requiredInputs,omitempty"`
+	// Outputs are the properties of the function's result.
+	Outputs map[string]Property `json:"outputs,omitempty"`
+}
+
+// Property describes a single property of a resource, function, or object.
+type Property struct {
+	// Description is an optional description of the property.
+	Description string `json:"description,omitempty"`
+	// Type is the type of the property: one of string, number, integer, boolean, array, object, or any.  An empty
+	// type is treated as any.
+	Type string `json:"type,omitempty"`
+	// Items describes the elements of an array property.
+	Items *Property `json:"items,omitempty"`
+	// AdditionalProperties describes the values of an object property.
+	AdditionalProperties *Property `json:"additionalProperties,omitempty"`
+	// TerraformName is the name of the Terraform attribute that this property corresponds to, if it is not the
+	// snake_case form of the property's name.
+	TerraformName string `json:"terraformName,omitempty"`
+	// Secret is true if the property's value is always secret.  The engine keeps such values out of the displays of
+	// updates, even if the program did not mark them as secret.
+	Secret bool `json:"secret,omitempty"`
+	// ProviderDefault is true if, when a program does not give the input property a value, the provider (or the
+	// service behind it) fills one in.  The engine ignores such properties when diffing resources whose programs do
+	// not give them, so that the values filled in are not mistaken for changes.
+	ProviderDefault bool `json:"providerDefault,omitempty"`
+}
+
+// Parse unmarshals and validates a package schema.
+func Parse(data []byte) (*Package, error) {
+	var pkg Package
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling schema")
+	}
+	if err := pkg.Validate(); err != nil {
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+// Validate checks that the package is well-formed: that it has a name, that all of its tokens belong to it, that all
+// required inputs are declared, and that all property types are known.
+func (pkg *Package) Validate() error {
+	if pkg.Name == "" {
+		return errors.New("schema is missing a package name")
+	}
+	for _, tok := range pkg.ResourceTokens() {
+		res := pkg.Resources[tok]
+		if err := pkg.validateToken(tok); err != nil {
+			return err
+		}
+		if err := validateProperties(tok, res.InputProperties, res.RequiredInputs); err != nil {
+			return err
+		}
+		if err := validateProperties(tok, res.Properties, nil); err != nil {
+			return err
+		}
+	}
+	for _, tok := range pkg.FunctionTokens() {
+		fn := pkg.Functions[tok]
+		if err := pkg.validateToken(tok); err != nil {
+			return err
+		}
+		if err := validateProperties(tok, fn.Inputs, fn.RequiredInputs); err != nil {
+			return err
+		}
+		if err := validateProperties(tok, fn.Outputs, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResourceTokens returns the package's resource type tokens in sorted order.
+func (pkg *Package) ResourceTokens() []string {
+	var toks []string
+	for tok := range pkg.Resources {
+		toks = append(toks, tok)
+	}
+	sort.Strings(toks)
+	return toks
+}
+
+// FunctionTokens returns the package's function tokens in sorted order.
+func (pkg *Package) FunctionTokens() []string {
+	var toks []string
+	for tok := range pkg.Functions {
+		toks = append(toks, tok)
+	}
+	sort.Strings(toks)
+	return toks
+}
+
+// SecretPaths returns the `<type>:<path>` rules that mark the package's secret properties as such: the secret inputs
+// and outputs of its resources, and the secret configuration of its provider.  A secret property whose value is an
+// object of secrets marks each of the object's properties as secret.
+func (pkg *Package) SecretPaths() []string {
+	var paths []string
+	add := func(typ string, props map[string]Property) {
+		var names []string
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop := props[name]
+			switch {
+			case prop.Secret:
+				paths = append(paths, typ+":"+name)
+			case prop.AdditionalProperties != nil && prop.AdditionalProperties.Secret:
+				paths = append(paths, typ+":"+name+".*")
+			}
+		}
+	}
+
+	add("pulumi:providers:"+pkg.Name, pkg.Config)
+	for _, tok := range pkg.ResourceTokens() {
+		res := pkg.Resources[tok]
+		add(tok, res.InputProperties)
+		add(tok, res.Properties)
+	}
+
+	// A property may be both an input and an output, so remove any duplicate rules.
+	var result []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// ProviderDefaults returns, for each of the package's resource types that has any, the sorted names of the input
+// properties whose values the provider fills in when a program does not give them.
+func (pkg *Package) ProviderDefaults() map[string][]string {
+	defaults := make(map[string][]string)
+	for _, tok := range pkg.ResourceTokens() {
+		var names []string
+		for name, prop := range pkg.Resources[tok].InputProperties {
+			if prop.ProviderDefault {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			defaults[tok] = names
+		}
+	}
+	return defaults
+}
+
+func (pkg *Package) validateToken(tok string) error {
+	member, err := tokens.ParseModuleMember(tok)
+	if err != nil || tokens.Token(tok).Delimiters() != 2 || !tokens.IsName(string(member.Name())) {
+		return errors.Errorf("%s is not a valid token; expected <package>:<module>:<name>", tok)
+	}
+	if p := string(member.Package()); p != pkg.Name {
+		return errors.Errorf("%s belongs to package %s, not %s", tok, p, pkg.Name)
+	}
+	return nil
+}
+
+func validateProperties(tok string, props map[string]Property, required []string) error {
+	for _, name := range required {
+		if _, has := props[name]; !has {
+			return errors.Errorf("%s: required property %s is not declared", tok, name)
+		}
+	}
+	for name, prop := range props {
+		if err := prop.validate(); err != nil {
+			return errors.Wrapf(err, "%s: property %s", tok, name)
+		}
+	}
+	return nil
+}
+
+func (prop *Property) validate() error {
+	switch prop.Type {
+	case "", StringType, NumberType, IntegerType, BooleanType, AnyType:
+		return nil
+	case ArrayType:
+		if prop.Items == nil {
+			return errors.New("array properties must describe their items")
+		}
+		return prop.Items.validate()
+	case ObjectType:
+		if prop.AdditionalProperties != nil {
+			return prop.AdditionalProperties.validate()
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown type %q", prop.Type)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	pkg, err := Parse([]byte(`{
+        "name": "test",
+        "resources": {
+            "test:index:Widget": {
+                "inputProperties": {"size": {"type": "integer"}},
+                "requiredInputs": ["size"]
+            }
+        }
+    }`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test:index:Widget"}, pkg.ResourceTokens())
+	assert.Equal(t, IntegerType, pkg.Resources["test:index:Widget"].InputProperties["size"].Type)
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := map[string]string{
+		"missing name":    `{"resources": {}}`,
+		"bad token":       `{"name": "test", "resources": {"test:Widget": {}}}`,
+		"foreign token":   `{"name": "test", "resources": {"other:index:Widget": {}}}`,
+		"unknown type":    `{"name": "test", "resources": {"test:index:Widget": {"properties": {"a": {"type": "date"}}}}}`,
+		"untyped items":   `{"name": "test", "functions": {"test:index:f": {"inputs": {"a": {"type": "array"}}}}}`,
+		"missing require": `{"name": "test", "functions": {"test:index:f": {"requiredInputs": ["a"]}}}`,
+	}
+	for name, data := range tests {
+		_, err := Parse([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestSecretPaths(t *testing.T) {
+	pkg, err := Parse([]byte(`{
+        "name": "test",
+        "config": {"apiKey": {"type": "string", "secret": true}},
+        "resources": {
+            "test:index:Key": {
+                "inputProperties": {"name": {"type": "string"}, "seed": {"type": "string", "secret": true}},
+                "properties": {
+                    "seed": {"type": "string", "secret": true},
+                    "value": {"type": "string", "secret": true},
+                    "shares": {"type": "object", "additionalProperties": {"type": "string", "secret": true}}
+                }
+            }
+        }
+    }`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"pulumi:providers:test:apiKey",
+		"test:index:Key:seed",
+		"test:index:Key:shares.*",
+		"test:index:Key:value",
+	}, pkg.SecretPaths())
+}
+
+func TestProviderDefaults(t *testing.T) {
+	pkg, err := Parse([]byte(`{
+        "name": "test",
+        "resources": {
+            "test:index:Bucket": {
+                "inputProperties": {
+                    "name": {"type": "string"},
+                    "region": {"type": "string", "providerDefault": true},
+                    "acl": {"type": "string", "providerDefault": true}
+                },
+                "properties": {"arn": {"type": "string", "providerDefault": true}}
+            },
+            "test:index:Object": {
+                "inputProperties": {"key": {"type": "string"}}
+            }
+        }
+    }`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"test:index:Bucket": {"acl", "region"},
+	}, pkg.ProviderDefaults())
+}
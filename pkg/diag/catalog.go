@@ -0,0 +1,149 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// LocaleEnvVar is the name of the environment variable that selects the locale for user-facing messages, overriding
+// the one detected from the usual LC_ALL, LC_MESSAGES, and LANG variables.
+const LocaleEnvVar = "PULUMI_LOCALE"
+
+// Catalog holds the translations of user-facing messages for a locale, keyed by their original English text.  For
+// messages that are format strings, such as those of diagnostics, the key is the format string itself.
+type Catalog map[string]string
+
+var (
+	catalogLock sync.RWMutex
+	catalogs    = make(map[string]Catalog) // the registered catalogs, by locale.
+	locale      string                     // the current locale; empty means untranslated English.
+)
+
+// DetectLocale returns the locale for user-facing messages from the environment, normalized to a form like `pt-BR`,
+// or an empty string if messages should be left untranslated.
+func DetectLocale() string {
+	for _, env := range []string{LocaleEnvVar, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			return NormalizeLocale(v)
+		}
+	}
+	return ""
+}
+
+// NormalizeLocale turns a POSIX locale name, such as `pt_BR.UTF-8` or `de_DE@euro`, into a form like `pt-BR`.  The
+// `C` and `POSIX` locales normalize to an empty string, since they stand for untranslated messages.
+func NormalizeLocale(name string) string {
+	if i := strings.IndexAny(name, ".@"); i != -1 {
+		name = name[:i]
+	}
+	if name == "C" || name == "POSIX" {
+		return ""
+	}
+	parts := strings.Split(strings.Replace(name, "_", "-", -1), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i])
+	}
+	return strings.Join(parts, "-")
+}
+
+// SetLocale selects the locale whose catalog is used to translate messages.  Translations for a locale with a region,
+// such as `pt-BR`, fall back to those of its language, `pt`.
+func SetLocale(l string) {
+	catalogLock.Lock()
+	defer catalogLock.Unlock()
+	locale = l
+}
+
+// Locale returns the locale whose catalog is used to translate messages.
+func Locale() string {
+	catalogLock.RLock()
+	defer catalogLock.RUnlock()
+	return locale
+}
+
+// RegisterCatalog adds the given translations to the catalog for a locale, replacing any it already has.
+func RegisterCatalog(l string, catalog Catalog) {
+	catalogLock.Lock()
+	defer catalogLock.Unlock()
+	l = NormalizeLocale(l)
+	if catalogs[l] == nil {
+		catalogs[l] = make(Catalog)
+	}
+	for k, v := range catalog {
+		catalogs[l][k] = v
+	}
+}
+
+// LoadCatalog reads a catalog from a JSON file holding an object that maps messages to their translations.
+func LoadCatalog(path string) (Catalog, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var catalog Catalog
+	if err = json.Unmarshal(b, &catalog); err != nil {
+		return nil, errors.Wrapf(err, "could not parse message catalog %s", path)
+	}
+	return catalog, nil
+}
+
+// Localize returns the translation of a message for the current locale, or the message itself if there isn't one.
+// A translation of a format string that doesn't use the same formatting verbs, in the same order, is ignored, since
+// formatting it with the message's arguments would garble it.
+func Localize(msg string) string {
+	catalogLock.RLock()
+	defer catalogLock.RUnlock()
+	if locale == "" || msg == "" {
+		return msg
+	}
+
+	l := locale
+	for {
+		if translated, has := catalogs[l][msg]; has && sameVerbs(msg, translated) {
+			return translated
+		}
+		i := strings.LastIndex(l, "-")
+		if i == -1 {
+			return msg
+		}
+		l = l[:i]
+	}
+}
+
+// verbRegexp matches the formatting verbs in a format string.
+var verbRegexp = regexp.MustCompile(`%[-+# 0]*(\[\d+\])?(\d+|\*)?(\.(\d+|\*)?)?[a-zA-Z%]`)
+
+// sameVerbs returns true if two format strings use the same formatting verbs in the same order.
+func sameVerbs(a, b string) bool {
+	va, vb := verbRegexp.FindAllString(a, -1), verbRegexp.FindAllString(b, -1)
+	if len(va) != len(vb) {
+		return false
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			return false
+		}
+	}
+	return true
+}
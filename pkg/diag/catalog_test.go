@@ -0,0 +1,64 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "pt-BR", NormalizeLocale("pt_BR.UTF-8"))
+	assert.Equal(t, "de-DE", NormalizeLocale("de_DE@euro"))
+	assert.Equal(t, "fr", NormalizeLocale("FR"))
+	assert.Equal(t, "", NormalizeLocale("C"))
+	assert.Equal(t, "", NormalizeLocale("POSIX.UTF-8"))
+}
+
+func TestLocalize(t *testing.T) {
+	// Not parallel, since the locale is global; restore it for the tests that are.
+	defer SetLocale(Locale())
+
+	dir, err := ioutil.TempDir("", "pulumi-catalog")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "xx.json")
+	catalog := `{"error": "fehler", "Preview failed: %v": "Vorschau fehlgeschlagen: %v", "Bad: %v": "Schlecht: %d"}`
+	assert.NoError(t, ioutil.WriteFile(path, []byte(catalog), 0600))
+	loaded, err := LoadCatalog(path)
+	assert.NoError(t, err)
+	RegisterCatalog("xx", loaded)
+	RegisterCatalog("xx_YY", Catalog{"error": "Fehler"})
+
+	// Without a locale, nothing is translated.
+	SetLocale("")
+	assert.Equal(t, "error", Localize("error"))
+
+	// With one, translations fall back from the region to the language, and are ignored if their verbs differ.
+	SetLocale("xx-YY")
+	assert.Equal(t, "Fehler", Localize("error"))
+	assert.Equal(t, "Vorschau fehlgeschlagen: %v", Localize("Preview failed: %v"))
+	assert.Equal(t, "Bad: %v", Localize("Bad: %v"))
+	assert.Equal(t, "warning", Localize("warning"))
+
+	p, s := discardSink().Stringify(Error, GetPreviewFailedError(""), "boom")
+	assert.Equal(t, "Fehler PU2005: Vorschau fehlgeschlagen: boom\n", p+s)
+}
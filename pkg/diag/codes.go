@@ -0,0 +1,264 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The stable codes of the classes of errors and warnings that the CLI reports.  Once assigned, a code never changes
+// meaning, so that automation may match on codes rather than on the text of messages, which may change or be
+// translated.
+const (
+	// Plan and apply errors are in the [2000,3000) range.
+	PlanApplyFailedID              ID = 2000
+	DuplicateResourceURNID         ID = 2001
+	ResourceInvalidID              ID = 2002
+	ResourcePropertyInvalidValueID ID = 2003
+	AnalyzeResourceFailureID       ID = 2004
+	PreviewFailedID                ID = 2005
+	ResourceLockedID               ID = 2006
+	PendingOperationsID            ID = 2007
+	ConfigDecryptFailedID          ID = 2008
+
+	// Provider and plugin errors are in the [3000,4000) range.
+	PluginMissingID      ID = 3000
+	ResourceInitFailedID ID = 3001
+
+	// Backend errors are in the [4000,5000) range.
+	StackAlreadyExistsID ID = 4000
+
+	// Warnings are in the [5000,6000) range.
+	PluginVersionMismatchID     ID = 5000
+	ConflictingPluginVersionsID ID = 5001
+	ProviderVersionOverriddenID ID = 5002
+	GuardOverriddenID           ID = 5003
+)
+
+// Code returns the code for the ID as it is displayed, such as `PU2001`, or an empty string for the zero ID.
+func (id ID) Code() string {
+	if id == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s%04d", DefaultSinkIDPrefix, int(id))
+}
+
+// ParseCode parses a code, such as `PU2001`, into its ID.  The prefix is optional and case-insensitive.
+func ParseCode(code string) (ID, error) {
+	digits := code
+	if strings.HasPrefix(strings.ToUpper(code), DefaultSinkIDPrefix) {
+		digits = code[len(DefaultSinkIDPrefix):]
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || n <= 0 {
+		return 0, errors.Errorf("invalid error code '%s'; expected a code like %s", code, PlanApplyFailedID.Code())
+	}
+	return ID(n), nil
+}
+
+// IdentifiedError is implemented by errors of a class that has a stable code.
+type IdentifiedError interface {
+	error
+	// DiagnosticID returns the ID of the error's class.
+	DiagnosticID() ID
+}
+
+// ErrorID returns the ID of the class of the given error, or of the first error it wraps that has one, if any.
+func ErrorID(err error) (ID, bool) {
+	for err != nil {
+		if ierr, ok := err.(IdentifiedError); ok {
+			return ierr.DiagnosticID(), true
+		}
+		causer, ok := err.(interface {
+			Cause() error
+		})
+		if !ok {
+			break
+		}
+		err = causer.Cause()
+	}
+	return 0, false
+}
+
+// Explanation describes a class of errors: what it means, what commonly causes it, and how to remedy it.
+type Explanation struct {
+	ID          ID
+	Title       string
+	Causes      []string
+	Remediation []string
+}
+
+// Explain returns the explanation for the class of errors with the given ID.
+func Explain(id ID) (Explanation, bool) {
+	e, has := explanations[id]
+	return e, has
+}
+
+// Explanations returns the explanations for all classes of errors, ordered by ID.
+func Explanations() []Explanation {
+	var result []Explanation
+	for _, e := range explanations {
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+var explanations = map[ID]Explanation{}
+
+func explain(id ID, title string, causes []string, remediation []string) {
+	explanations[id] = Explanation{ID: id, Title: title, Causes: causes, Remediation: remediation}
+}
+
+func init() {
+	explain(PlanApplyFailedID, "A resource operation failed during an update",
+		[]string{
+			"The resource provider rejected or failed to perform a create, update, delete, or read.",
+			"The cloud credentials in use lack the permissions the operation needs.",
+			"The resource's inputs are invalid in a way the provider only discovers when it contacts the cloud.",
+		},
+		[]string{
+			"Read the provider's message that follows the code for the specific failure.",
+			"Fix the program or the cloud environment, and run `pulumi up` again; resources that were changed " +
+				"successfully are recorded, so the update resumes where it failed.",
+		})
+	explain(DuplicateResourceURNID, "Two resources have the same URN",
+		[]string{
+			"The program registered two resources of the same type with the same name.",
+		},
+		[]string{
+			"Give each resource a unique name, for example by including an index or a parent's name.",
+		})
+	explain(ResourceInvalidID, "A resource failed its provider's checks",
+		[]string{
+			"The provider's Check reported a problem with the resource's inputs as a whole.",
+		},
+		[]string{
+			"Correct the resource's inputs as described by the message, and run the command again.",
+		})
+	explain(ResourcePropertyInvalidValueID, "A resource property has an invalid value",
+		[]string{
+			"The provider's Check reported that a property's value is invalid, such as being of the wrong type or " +
+				"out of range.",
+		},
+		[]string{
+			"Correct the named property's value as described by the message, and run the command again.",
+		})
+	explain(AnalyzeResourceFailureID, "An analyzer rejected a resource",
+		[]string{
+			"An analyzer configured for the project reported a violation of one of its policies.",
+		},
+		[]string{
+			"Change the resource to comply with the analyzer's policy, as described by its reason.",
+		})
+	explain(PreviewFailedID, "A resource operation failed during a preview",
+		[]string{
+			"The resource provider failed to compute the effect of a change, such as while diffing or checking " +
+				"inputs.",
+			"A provider plugin could not be loaded or configured.",
+		},
+		[]string{
+			"Read the provider's message that follows the code for the specific failure.",
+			"Check the provider's configuration, such as credentials and region.",
+		})
+	explain(ResourceLockedID, "A locked resource would have been changed",
+		[]string{
+			"The resource was locked with `pulumi state lock`, and the update would replace, update, or delete it.",
+		},
+		[]string{
+			"Change the program so that it no longer changes the resource.",
+			"Run `pulumi state unlock <urn>` to allow the change, if it is intended.",
+		})
+	explain(PendingOperationsID, "The stack has operations that were interrupted",
+		[]string{
+			"A previous update was interrupted, such as by a crash or a killed process, while resource operations " +
+				"were in flight, so the state of those resources is unknown.",
+		},
+		[]string{
+			"Check in the cloud provider whether each listed operation completed.",
+			"Export the stack with `pulumi stack export`, remove the completed operations from its " +
+				"`pending_operations`, and import it with `pulumi stack import`.",
+		})
+	explain(ConfigDecryptFailedID, "A secret configuration value could not be decrypted",
+		[]string{
+			"The secret was copied from another stack; secrets are encrypted per stack.",
+			"The passphrase for a stack on the local backend is not the one it was encrypted with.",
+		},
+		[]string{
+			"Set the value again with `pulumi config set <key> <value> --secret` with this stack selected.",
+			"For the local backend, set PULUMI_CONFIG_PASSPHRASE to the stack's passphrase.",
+		})
+	explain(PluginMissingID, "A required plugin is not installed",
+		[]string{
+			"The program uses a resource provider or language plugin that isn't installed in the plugin cache or " +
+				"on the PATH.",
+			"The project pins a provider version that isn't installed.",
+		},
+		[]string{
+			"Install the plugin with `pulumi plugin install <kind> <name> <version>`.",
+			"Run `pulumi plugin ls --project` to see the plugins the project needs.",
+		})
+	explain(ResourceInitFailedID, "A resource was created but failed to initialize",
+		[]string{
+			"The provider created the resource, but it did not become healthy, such as a service whose " +
+				"containers fail to start.",
+		},
+		[]string{
+			"Inspect the resource in the cloud provider, using the reasons given, to find why it is unhealthy.",
+			"The resource is recorded as created, so fix the cause and run `pulumi up` again to update it.",
+		})
+	explain(StackAlreadyExistsID, "The stack already exists",
+		[]string{
+			"A stack with the same name was already created in this backend, possibly by someone else.",
+		},
+		[]string{
+			"Choose a different stack name, or select the existing stack with `pulumi stack select <name>`.",
+		})
+	explain(PluginVersionMismatchID, "A resource plugin reported an older version than the one requested",
+		[]string{
+			"A different version of the plugin is on the PATH, which takes precedence over the plugin cache.",
+			"The plugin reports its version incorrectly.",
+		},
+		[]string{
+			"Remove the plugin from the PATH, or install the requested version with `pulumi plugin install`.",
+		})
+	explain(ConflictingPluginVersionsID, "The program requires several versions of a plugin",
+		[]string{
+			"The program's dependencies require different versions of the same resource provider.",
+		},
+		[]string{
+			"The newest of the versions is used; update the dependencies so that they agree on a version.",
+		})
+	explain(ProviderVersionOverriddenID, "The project overrides the plugin version that the program requires",
+		[]string{
+			"The project's `providers` settings pin a version, or give a range, that excludes the version of a " +
+				"provider that the program requires.",
+		},
+		[]string{
+			"Update the project's pinned version or range, or the program's dependency on the provider.",
+		})
+	explain(GuardOverriddenID, "An operation overrode the stack's guard",
+		[]string{
+			"The operation was run with --override-guard, although the stack's guard forbids it.",
+		},
+		[]string{
+			"No action is needed if the override was intended; the reason given is recorded with the warning.",
+		})
+}
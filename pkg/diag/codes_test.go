@@ -0,0 +1,61 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type testIdentifiedError struct{}
+
+func (testIdentifiedError) Error() string    { return "identified" }
+func (testIdentifiedError) DiagnosticID() ID { return ResourceLockedID }
+
+func TestCodes(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "PU2001", DuplicateResourceURNID.Code())
+	assert.Equal(t, "", ID(0).Code())
+	for _, code := range []string{"PU2001", "pu2001", "2001"} {
+		id, err := ParseCode(code)
+		assert.NoError(t, err)
+		assert.Equal(t, DuplicateResourceURNID, id)
+	}
+	_, err := ParseCode("PUxyz")
+	assert.Error(t, err)
+
+	// Every code has an explanation, with causes and remediation.
+	for _, e := range Explanations() {
+		assert.NotEmpty(t, e.Title, e.ID.Code())
+		assert.NotEmpty(t, e.Causes, e.ID.Code())
+		assert.NotEmpty(t, e.Remediation, e.ID.Code())
+	}
+	_, has := Explain(StackAlreadyExistsID)
+	assert.True(t, has)
+
+	// Codes are found through wrapped errors.
+	id, ok := ErrorID(errors.Wrap(testIdentifiedError{}, "wrapped"))
+	assert.True(t, ok)
+	assert.Equal(t, ResourceLockedID, id)
+	_, ok = ErrorID(errors.New("plain"))
+	assert.False(t, ok)
+
+	// Diagnostics with codes lead with them.
+	p, s := discardSink().Stringify(Error, GetDuplicateResourceURNError(""), "urn")
+	assert.Equal(t, "error PU2001: Duplicate resource URN 'urn'; try giving it a unique name\n", p+s)
+}
@@ -0,0 +1,275 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colors
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Theme is a palette for the colors used to display logical conditions, such as the kinds of steps in a diff, along
+// with optional replacements for the glyphs that mark those steps.  A theme may be based on another, in which case
+// it only overrides what it names explicitly.
+type Theme struct {
+	Base   string            `json:"base,omitempty" yaml:"base,omitempty"`     // an optional theme this one extends.
+	Colors map[string]string `json:"colors,omitempty" yaml:"colors,omitempty"` // colors by condition, e.g. `create`.
+	Glyphs map[string]string `json:"glyphs,omitempty" yaml:"glyphs,omitempty"` // glyphs by step kind, e.g. `create`.
+}
+
+// Names of the built-in themes.
+const (
+	DefaultTheme      = "default"
+	HighContrastTheme = "high-contrast"
+	ColorblindTheme   = "colorblind"
+)
+
+// BuiltinThemes are the themes that are always available by name.
+var BuiltinThemes = map[string]Theme{
+	DefaultTheme: {},
+	HighContrastTheme: {
+		Colors: map[string]string{
+			"unimportant":        "white",
+			"info":               "bold bright-magenta",
+			"error":              "bold bright-red",
+			"warning":            "bold bright-yellow",
+			"location":           "bright-cyan",
+			"attention":          "bold bright-red",
+			"note":               "bright-white",
+			"create":             "bold bright-green",
+			"update":             "bold bright-yellow",
+			"read":               "bold bright-white",
+			"replace":            "bold bright-magenta",
+			"delete":             "bold bright-red",
+			"create-replacement": "bold bright-green",
+			"delete-replaced":    "bold bright-red",
+		},
+	},
+	ColorblindTheme: {
+		// Avoids distinguishing anything by red versus green alone, using blue and orange instead.
+		Colors: map[string]string{
+			"error":              "208",
+			"attention":          "bold 208",
+			"create":             "bright-blue",
+			"update":             "bright-yellow",
+			"replace":            "bright-magenta",
+			"delete":             "208",
+			"create-replacement": "bright-cyan",
+			"delete-replaced":    "202",
+		},
+	},
+}
+
+// specColors are the logical colors a theme may set, by name.
+var specColors = map[string]*string{
+	"important":          &SpecImportant,
+	"unimportant":        &SpecUnimportant,
+	"debug":              &SpecDebug,
+	"info":               &SpecInfo,
+	"error":              &SpecError,
+	"warning":            &SpecWarning,
+	"location":           &SpecLocation,
+	"attention":          &SpecAttention,
+	"note":               &SpecNote,
+	"create":             &SpecCreate,
+	"update":             &SpecUpdate,
+	"read":               &SpecRead,
+	"replace":            &SpecReplace,
+	"delete":             &SpecDelete,
+	"create-replacement": &SpecCreateReplacement,
+	"delete-replaced":    &SpecDeleteReplaced,
+}
+
+// glyphNames are the kinds of steps whose glyphs a theme may replace.
+var glyphNames = []string{
+	"same", "create", "update", "delete", "replace", "create-replacement", "delete-replaced",
+	"read", "read-replacement", "refresh",
+}
+
+// colorNames maps the names usable in a theme to the colors they stand for.
+var colorNames = map[string]string{
+	"black":          Black,
+	"red":            Red,
+	"green":          Green,
+	"yellow":         Yellow,
+	"blue":           Blue,
+	"magenta":        Magenta,
+	"cyan":           Cyan,
+	"white":          White,
+	"bright-black":   BrightBlack,
+	"bright-red":     BrightRed,
+	"bright-green":   BrightGreen,
+	"bright-yellow":  BrightYellow,
+	"bright-blue":    BrightBlue,
+	"bright-magenta": BrightMagenta,
+	"bright-cyan":    BrightCyan,
+	"bright-white":   BrightWhite,
+	"bold":           Bold,
+	"underline":      Underline,
+}
+
+// defaultSpecColors records the logical colors before any theme is applied, so that themes always start from them.
+var defaultSpecColors = func() map[string]string {
+	defaults := make(map[string]string)
+	for name, spec := range specColors {
+		defaults[name] = *spec
+	}
+	return defaults
+}()
+
+// glyphs holds the glyphs replaced by the current theme, by step kind.
+var glyphs map[string]string
+
+// Glyph returns the glyph the current theme uses for the given kind of step, if it replaces the default one.
+func Glyph(kind string) (string, bool) {
+	glyph, has := glyphs[kind]
+	return glyph, has
+}
+
+// ParseColor turns a space-separated list of color names (such as `bold bright-green`) into the commands for them.
+// Besides the names of the basic colors, `bold`, and `underline`, a number from 0 to 255 selects that color from
+// the terminal's 256-color palette.
+func ParseColor(s string) (string, error) {
+	var result string
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return "", errors.New("empty color")
+	}
+	for _, word := range words {
+		if c, has := colorNames[word]; has {
+			result += c
+		} else if n, err := strconv.Atoi(word); err == nil && n >= 0 && n <= 255 {
+			result += Command("fg " + word)
+		} else {
+			return "", errors.Errorf("unknown color '%s'", word)
+		}
+	}
+	return result, nil
+}
+
+// ResolveTheme looks up the theme with the given name, first amongst the given user-defined themes and then amongst
+// the built-in ones, and flattens it and any themes it's based on into a single theme.
+func ResolveTheme(name string, themes map[string]Theme) (Theme, error) {
+	resolved := Theme{Colors: make(map[string]string), Glyphs: make(map[string]string)}
+	seen := make(map[string]bool)
+	var chain []Theme
+	for name != "" {
+		if seen[name] {
+			return Theme{}, errors.Errorf("theme '%s' is based on itself", name)
+		}
+		seen[name] = true
+
+		theme, has := themes[name]
+		if !has {
+			if theme, has = BuiltinThemes[name]; !has {
+				return Theme{}, errors.Errorf("unknown theme '%s'; expected one of %s",
+					name, strings.Join(ThemeNames(themes), ", "))
+			}
+		}
+		chain = append(chain, theme)
+		name = theme.Base
+	}
+
+	// Apply the most basic theme first, so that each theme overrides those it's based on.
+	for i := len(chain) - 1; i >= 0; i-- {
+		for k, v := range chain[i].Colors {
+			resolved.Colors[k] = v
+		}
+		for k, v := range chain[i].Glyphs {
+			resolved.Glyphs[k] = v
+		}
+	}
+	return resolved, resolved.Validate()
+}
+
+// ThemeNames returns the sorted names of the built-in themes and the given user-defined ones.
+func ThemeNames(themes map[string]Theme) []string {
+	var names []string
+	for name := range BuiltinThemes {
+		names = append(names, name)
+	}
+	for name := range themes {
+		if _, has := BuiltinThemes[name]; !has {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate returns an error if the theme names an unknown color or step kind, or has a glyph that isn't one or two
+// characters wide.
+func (t Theme) Validate() error {
+	for name, color := range t.Colors {
+		if _, has := specColors[name]; !has {
+			return errors.Errorf("unknown theme color '%s'", name)
+		}
+		if _, err := ParseColor(color); err != nil {
+			return errors.Wrapf(err, "invalid theme color '%s'", name)
+		}
+	}
+	for kind, glyph := range t.Glyphs {
+		if !isGlyphName(kind) {
+			return errors.Errorf("unknown theme glyph '%s'", kind)
+		}
+		if n := utf8.RuneCountInString(glyph); n < 1 || n > 2 {
+			return errors.Errorf("invalid theme glyph '%s': %q must be one or two characters", kind, glyph)
+		}
+	}
+	return nil
+}
+
+// Apply makes this the current theme, replacing the logical colors and step glyphs set by any previous one.
+func (t Theme) Apply() error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	for name, spec := range specColors {
+		*spec = defaultSpecColors[name]
+		if color, has := t.Colors[name]; has {
+			c, err := ParseColor(color)
+			if err != nil {
+				return err
+			}
+			*spec = c
+		}
+	}
+	if _, has := t.Colors["debug"]; !has {
+		SpecDebug = SpecUnimportant // debugging output follows unimportant output, unless it's themed itself.
+	}
+
+	glyphs = make(map[string]string)
+	for kind, glyph := range t.Glyphs {
+		// Glyphs occupy two columns, so pad those that are a single character wide.
+		if utf8.RuneCountInString(glyph) == 1 {
+			glyph += " "
+		}
+		glyphs[kind] = glyph
+	}
+	return nil
+}
+
+func isGlyphName(kind string) bool {
+	for _, name := range glyphNames {
+		if kind == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColor(t *testing.T) {
+	c, err := ParseColor("bold bright-green")
+	assert.NoError(t, err)
+	assert.Equal(t, Bold+BrightGreen, c)
+
+	c, err = ParseColor("208")
+	assert.NoError(t, err)
+	assert.Equal(t, Command("fg 208"), c)
+
+	_, err = ParseColor("256")
+	assert.Error(t, err)
+	_, err = ParseColor("chartreuse")
+	assert.Error(t, err)
+	_, err = ParseColor(" ")
+	assert.Error(t, err)
+}
+
+func TestResolveTheme(t *testing.T) {
+	themes := map[string]Theme{
+		"mine": {
+			Base:   HighContrastTheme,
+			Colors: map[string]string{"create": "cyan"},
+			Glyphs: map[string]string{"create": "A"},
+		},
+		"loop":  {Base: "loop"},
+		"bad":   {Colors: map[string]string{"creates": "red"}},
+		"wide":  {Glyphs: map[string]string{"delete": "---"}},
+		"steps": {Glyphs: map[string]string{"deletes": "-"}},
+	}
+
+	theme, err := ResolveTheme("mine", themes)
+	assert.NoError(t, err)
+	assert.Equal(t, "cyan", theme.Colors["create"])
+	assert.Equal(t, "bold bright-red", theme.Colors["delete"])
+	assert.Equal(t, "A", theme.Glyphs["create"])
+
+	for _, name := range []string{"loop", "bad", "wide", "steps", "missing"} {
+		_, err = ResolveTheme(name, themes)
+		assert.Error(t, err, name)
+	}
+
+	for name := range BuiltinThemes {
+		_, err = ResolveTheme(name, nil)
+		assert.NoError(t, err, name)
+	}
+	assert.Equal(t, []string{"bad", "colorblind", "default", "high-contrast", "loop", "mine", "steps", "wide"},
+		ThemeNames(themes))
+}
+
+func TestApplyTheme(t *testing.T) {
+	defer func() { assert.NoError(t, Theme{}.Apply()) }()
+
+	theme := Theme{
+		Colors: map[string]string{"create": "blue", "unimportant": "white"},
+		Glyphs: map[string]string{"create": "A", "delete": "B-"},
+	}
+	assert.NoError(t, theme.Apply())
+	assert.Equal(t, Blue, SpecCreate)
+	assert.Equal(t, White, SpecDebug)
+	assert.Equal(t, Red, SpecDelete)
+	glyph, has := Glyph("create")
+	assert.True(t, has)
+	assert.Equal(t, "A ", glyph)
+	glyph, has = Glyph("delete")
+	assert.True(t, has)
+	assert.Equal(t, "B-", glyph)
+
+	// Applying another theme starts over from the defaults.
+	assert.NoError(t, Theme{}.Apply())
+	assert.Equal(t, Green, SpecCreate)
+	assert.Equal(t, BrightBlack, SpecDebug)
+	_, has = Glyph("create")
+	assert.False(t, has)
+}
@@ -0,0 +1,82 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+// Aggregate describes a diagnostic that was reported more than once.
+type Aggregate struct {
+	Severity Severity       // the severity of the diagnostic.
+	Message  string         // the diagnostic's message.
+	Repeats  int            // the number of times the diagnostic was reported after the first.
+	URNs     []resource.URN // a sample of the resources the repeats were reported for, in the order they were seen.
+}
+
+// Deduper recognizes diagnostics that repeat ones already reported, such as a provider's deprecation notice for each
+// of hundreds of resources, so that they can be aggregated rather than each being displayed.  Diagnostics are the same
+// if they have the same severity and message, no matter which resource they are reported for.
+type Deduper struct {
+	samples    int                      // the maximum number of resources to record for each aggregate.
+	aggregates map[dedupeKey]*Aggregate // the aggregates for the diagnostics seen so far.
+	order      []dedupeKey              // the keys of the diagnostics in the order they were first seen.
+}
+
+type dedupeKey struct {
+	sev Severity
+	msg string
+}
+
+// NewDeduper creates a new deduper, which records up to the given number of sample resources for each aggregate.
+func NewDeduper(samples int) *Deduper {
+	return &Deduper{samples: samples, aggregates: make(map[dedupeKey]*Aggregate)}
+}
+
+// Observe records a diagnostic, returning true if it's the first with its severity and message, and so should be
+// displayed, or false if it repeats one that was.
+func (d *Deduper) Observe(sev Severity, urn resource.URN, msg string) bool {
+	key := dedupeKey{sev: sev, msg: msg}
+	agg, has := d.aggregates[key]
+	if !has {
+		d.aggregates[key] = &Aggregate{Severity: sev, Message: msg}
+		d.order = append(d.order, key)
+		return true
+	}
+
+	agg.Repeats++
+	if urn != "" && len(agg.URNs) < d.samples {
+		for _, seen := range agg.URNs {
+			if seen == urn {
+				return false
+			}
+		}
+		agg.URNs = append(agg.URNs, urn)
+	}
+	return false
+}
+
+// Flush returns the aggregates for the diagnostics that were repeated, in the order they were first seen, and forgets
+// everything seen so far.
+func (d *Deduper) Flush() []Aggregate {
+	var result []Aggregate
+	for _, key := range d.order {
+		if agg := d.aggregates[key]; agg.Repeats > 0 {
+			result = append(result, *agg)
+		}
+	}
+	d.aggregates, d.order = make(map[dedupeKey]*Aggregate), nil
+	return result
+}
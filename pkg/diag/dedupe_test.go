@@ -0,0 +1,53 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestDeduper(t *testing.T) {
+	t.Parallel()
+
+	urn := func(name string) resource.URN {
+		return resource.NewURN("stack", "proj", "", "pkg:m:t", tokens.QName(name))
+	}
+
+	d := NewDeduper(2)
+	assert.True(t, d.Observe(Warning, urn("a"), "deprecated"))
+	assert.True(t, d.Observe(Warning, urn("a"), "other"))
+	assert.True(t, d.Observe(Error, urn("a"), "deprecated"))
+	assert.False(t, d.Observe(Warning, urn("b"), "deprecated"))
+	assert.False(t, d.Observe(Warning, urn("b"), "deprecated"))
+	assert.False(t, d.Observe(Warning, urn("c"), "deprecated"))
+	assert.False(t, d.Observe(Warning, urn("d"), "deprecated"))
+
+	aggs := d.Flush()
+	assert.Equal(t, []Aggregate{{
+		Severity: Warning,
+		Message:  "deprecated",
+		Repeats:  4,
+		URNs:     []resource.URN{urn("b"), urn("c")},
+	}}, aggs)
+
+	// Flushing forgets what was seen.
+	assert.Empty(t, d.Flush())
+	assert.True(t, d.Observe(Warning, urn("a"), "deprecated"))
+}
@@ -15,6 +15,11 @@
 package diag
 
 import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
 	"github.com/pulumi/pulumi/pkg/resource"
 )
 
@@ -27,6 +32,7 @@ type Diag struct {
 	ID      ID           // a unique identifier for this diagnostic.
 	Message string       // a human-friendly message for this diagnostic.
 	Raw     bool         // true if this diagnostic should not be formatted when displayed.
+	Pos     *Pos         // the source position this diagnostic is associated with, if any.
 
 	// An ID used to collate a stream of conceptually sequential messages.  0 means that the message
 	// is not part of any sequential message stream.
@@ -50,3 +56,76 @@ func RawMessage(urn resource.URN, msg string) *Diag {
 func StreamMessage(urn resource.URN, msg string, streamID int32) *Diag {
 	return &Diag{URN: urn, Message: msg, Raw: true, StreamID: streamID}
 }
+
+// At returns a copy of this diagnostic that is associated with the given source position.
+func (diag *Diag) At(pos *Pos) *Diag {
+	d := *diag
+	d.Pos = pos
+	return &d
+}
+
+// Pos is a position within a program's source code.
+type Pos struct {
+	File   string // the path of the source file.
+	Line   int    // the 1-based line number, or 0 if unknown.
+	Column int    // the 1-based column number, or 0 if unknown.
+}
+
+// ParsePos parses a position of the form `file:line:column`, in which the line and column are optional.  It returns
+// nil if the string is empty.
+func ParsePos(s string) *Pos {
+	if s == "" {
+		return nil
+	}
+
+	// Peel numeric components off of the end, so that file paths containing colons (e.g. on Windows) survive intact.
+	var nums []int
+	for len(nums) < 2 {
+		i := strings.LastIndex(s, ":")
+		if i == -1 {
+			break
+		}
+		n, err := strconv.Atoi(s[i+1:])
+		if err != nil || n < 0 {
+			break
+		}
+		nums = append([]int{n}, nums...)
+		s = s[:i]
+	}
+
+	pos := &Pos{File: s}
+	if len(nums) > 0 {
+		pos.Line = nums[0]
+	}
+	if len(nums) > 1 {
+		pos.Column = nums[1]
+	}
+	return pos
+}
+
+// String returns the position in the usual `file:line:column` form.
+func (pos *Pos) String() string {
+	return pos.format(pos.File)
+}
+
+// Rel returns the position as a string whose file is relative to the given directory, if possible.
+func (pos *Pos) Rel(dir string) string {
+	file := pos.File
+	if dir != "" && filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(dir, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	return pos.format(file)
+}
+
+func (pos *Pos) format(file string) string {
+	switch {
+	case pos.Line == 0:
+		return file
+	case pos.Column == 0:
+		return fmt.Sprintf("%s:%d", file, pos.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", file, pos.Line, pos.Column)
+	}
+}
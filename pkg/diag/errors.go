@@ -18,31 +18,31 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource"
 )
 
-// newError registers a new error message underneath the given id.
+// newError registers a new error or warning message underneath the given id.
 func newError(urn resource.URN, id ID, message string) *Diag {
 	return &Diag{URN: urn, ID: id, Message: message}
 }
 
-// Plan and apply errors are in the [2000,3000) range.
+// Plan and apply errors are in the [2000,3000) range; see codes.go for their explanations.
 
 func GetPlanApplyFailedError(urn resource.URN) *Diag {
-	return newError(urn, 2000, "Plan apply failed: %v")
+	return newError(urn, PlanApplyFailedID, "Plan apply failed: %v")
 }
 
 func GetDuplicateResourceURNError(urn resource.URN) *Diag {
-	return newError(urn, 2001, "Duplicate resource URN '%v'; try giving it a unique name")
+	return newError(urn, DuplicateResourceURNID, "Duplicate resource URN '%v'; try giving it a unique name")
 }
 
 func GetResourceInvalidError(urn resource.URN) *Diag {
-	return newError(urn, 2002, "%v resource '%v' has a problem: %v")
+	return newError(urn, ResourceInvalidID, "%v resource '%v' has a problem: %v")
 }
 
 func GetResourcePropertyInvalidValueError(urn resource.URN) *Diag {
-	return newError(urn, 2003, "%v resource '%v's property '%v' value %v has a problem: %v")
+	return newError(urn, ResourcePropertyInvalidValueID, "%v resource '%v's property '%v' value %v has a problem: %v")
 }
 
 func GetAnalyzeResourceFailureError(urn resource.URN) *Diag {
-	return newError(urn, 2004,
+	return newError(urn, AnalyzeResourceFailureID,
 		"Analyzer '%v' reported a resource error:\n"+
 			"\tResource: %v\n"+
 			"\tProperty: %v\n"+
@@ -50,5 +50,36 @@ func GetAnalyzeResourceFailureError(urn resource.URN) *Diag {
 }
 
 func GetPreviewFailedError(urn resource.URN) *Diag {
-	return newError(urn, 2005, "Preview failed: %v")
+	return newError(urn, PreviewFailedID, "Preview failed: %v")
+}
+
+func GetResourceLockedError(urn resource.URN) *Diag {
+	return newError(urn, ResourceLockedID,
+		"%v resource '%v' is locked and cannot be %v; run `pulumi state unlock '%v'` to allow it to change")
+}
+
+// Warnings are in the [5000,6000) range; see codes.go for their explanations.
+
+func GetPluginVersionMismatchWarning(urn resource.URN) *Diag {
+	return newError(urn, PluginVersionMismatchID, "resource plugin %s is expected to have version >=%s, but has %s; "+
+		"the wrong version may be on your path, or this may be a bug in the plugin")
+}
+
+func GetConflictingPluginVersionsWarning(urn resource.URN) *Diag {
+	return newError(urn, ConflictingPluginVersionsID, "the program requires several versions of the %s plugin (%s); "+
+		"using %s")
+}
+
+func GetProviderVersionPinnedWarning(urn resource.URN) *Diag {
+	return newError(urn, ProviderVersionOverriddenID, "the project pins version %s of the %s plugin, which is older "+
+		"than version %s that the program requires")
+}
+
+func GetProviderVersionExcludedWarning(urn resource.URN) *Diag {
+	return newError(urn, ProviderVersionOverriddenID, "the project's range '%s' for the %s plugin excludes "+
+		"version %s that the program requires")
+}
+
+func GetGuardOverriddenWarning(urn resource.URN) *Diag {
+	return newError(urn, GuardOverriddenID, "overriding the stack's guard (%s): %s")
 }
@@ -126,7 +126,7 @@ func (d *defaultSink) Debugf(diag *Diag, args ...interface{}) {
 	// For debug messages, write both to the glogger and a stream, if there is one.
 	logging.V(3).Infof(diag.Message, args...)
 	msg := d.createMessage(Debug, diag, args...)
-	if logging.V(9) {
+	if logging.V(9).Enabled() {
 		logging.V(9).Infof("defaultSink::Debug(%v)", msg[:len(msg)-1])
 	}
 	fmt.Fprint(d.writers[Debug], msg)
@@ -134,7 +134,7 @@ func (d *defaultSink) Debugf(diag *Diag, args ...interface{}) {
 
 func (d *defaultSink) Infof(diag *Diag, args ...interface{}) {
 	msg := d.createMessage(Info, diag, args...)
-	if logging.V(5) {
+	if logging.V(5).Enabled() {
 		logging.V(5).Infof("defaultSink::Info(%v)", msg[:len(msg)-1])
 	}
 	fmt.Fprint(d.writers[Info], msg)
@@ -142,7 +142,7 @@ func (d *defaultSink) Infof(diag *Diag, args ...interface{}) {
 
 func (d *defaultSink) Infoerrf(diag *Diag, args ...interface{}) {
 	msg := d.createMessage(Info /* not Infoerr, just "info: "*/, diag, args...)
-	if logging.V(5) {
+	if logging.V(5).Enabled() {
 		logging.V(5).Infof("defaultSink::Infoerr(%v)", msg[:len(msg)-1])
 	}
 	fmt.Fprint(d.writers[Infoerr], msg)
@@ -150,7 +150,7 @@ func (d *defaultSink) Infoerrf(diag *Diag, args ...interface{}) {
 
 func (d *defaultSink) Errorf(diag *Diag, args ...interface{}) {
 	msg := d.createMessage(Error, diag, args...)
-	if logging.V(5) {
+	if logging.V(5).Enabled() {
 		logging.V(5).Infof("defaultSink::Error(%v)", msg[:len(msg)-1])
 	}
 	fmt.Fprint(d.writers[Error], msg)
@@ -158,7 +158,7 @@ func (d *defaultSink) Errorf(diag *Diag, args ...interface{}) {
 
 func (d *defaultSink) Warningf(diag *Diag, args ...interface{}) {
 	msg := d.createMessage(Warning, diag, args...)
-	if logging.V(5) {
+	if logging.V(5).Enabled() {
 		logging.V(5).Infof("defaultSink::Warning(%v)", msg[:len(msg)-1])
 	}
 	fmt.Fprint(d.writers[Warning], msg)
@@ -181,7 +181,12 @@ func (d *defaultSink) Stringify(sev Severity, diag *Diag, args ...interface{}) (
 		contract.Failf("Unrecognized diagnostic severity: %v", sev)
 	}
 
-	prefix.WriteString(string(sev))
+	prefix.WriteString(Localize(string(sev)))
+	if code := diag.ID.Code(); code != "" {
+		// Lead with the diagnostic's code, if it has one, so that it can be looked up with `pulumi explain`.
+		prefix.WriteString(" ")
+		prefix.WriteString(code)
+	}
 	prefix.WriteString(": ")
 	prefix.WriteString(colors.Reset)
 
@@ -189,17 +194,23 @@ func (d *defaultSink) Stringify(sev Severity, diag *Diag, args ...interface{}) (
 	var buffer bytes.Buffer
 	buffer.WriteString(colors.SpecNote)
 
+	// If the diagnostic is associated with a position in the program's source, lead with it.
+	if diag.Pos != nil {
+		buffer.WriteString(diag.Pos.Rel(d.opts.Pwd))
+		buffer.WriteString(": ")
+	}
+
 	if diag.Raw {
 		buffer.WriteString(diag.Message)
 	} else {
-		buffer.WriteString(fmt.Sprintf(diag.Message, args...))
+		buffer.WriteString(fmt.Sprintf(Localize(diag.Message), args...))
 	}
 
 	buffer.WriteString(colors.Reset)
 	buffer.WriteRune('\n')
 
-	// TODO[pulumi/pulumi#15]: support Clang-style expressive diagnostics.  This would entail, for example, using
-	//     the buffer within the target document, to demonstrate the offending line/column range of code.
+	// Show the offending line of source code, if we can find it, with the position's column marked.
+	buffer.WriteString(FormatSource(diag.Pos))
 
 	// Ensure that any sensitive data we know about is filtered out preemptively.
 	filtered := logging.FilterString(buffer.String())
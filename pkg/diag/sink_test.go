@@ -17,6 +17,8 @@ package diag
 import (
 	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,8 +27,12 @@ import (
 )
 
 func discardSink() Sink {
+	return discardSinkWithOptions(FormatOptions{Color: colors.Never})
+}
+
+func discardSinkWithOptions(opts FormatOptions) Sink {
 	// Create a new default sink with /dev/null writers to avoid spamming the test log.
-	return newDefaultSink(FormatOptions{Color: colors.Never}, map[Severity]io.Writer{
+	return newDefaultSink(opts, map[Severity]io.Writer{
 		Debug:   ioutil.Discard,
 		Info:    ioutil.Discard,
 		Infoerr: ioutil.Discard,
@@ -65,3 +71,40 @@ func TestEscape(t *testing.T) {
 	pmiss, smiss := sink.Stringify(Error, Message("", "lots of %v %s %d chars"))
 	assert.Equal(t, "error: lots of %!v(MISSING) %!s(MISSING) %!d(MISSING) chars\n", pmiss+smiss)
 }
+
+func TestParsePos(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, ParsePos(""))
+	assert.Equal(t, &Pos{File: "index.ts"}, ParsePos("index.ts"))
+	assert.Equal(t, &Pos{File: "index.ts", Line: 12}, ParsePos("index.ts:12"))
+	assert.Equal(t, &Pos{File: "index.ts", Line: 12, Column: 5}, ParsePos("index.ts:12:5"))
+	assert.Equal(t, &Pos{File: `C:\src\index.ts`, Line: 12, Column: 5}, ParsePos(`C:\src\index.ts:12:5`))
+	assert.Equal(t, "index.ts:12:5", ParsePos("index.ts:12:5").String())
+}
+
+// TestSourcePosition ensures that diagnostics with positions name them and show the offending source line.
+func TestSourcePosition(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "diag")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "index.ts")
+	source := "import * as aws from \"@pulumi/aws\";\nconst b = new aws.s3.Bucket(\"b\");\n"
+	err = ioutil.WriteFile(file, []byte(source), 0600)
+	assert.NoError(t, err)
+
+	sink := discardSinkWithOptions(FormatOptions{Pwd: dir, Color: colors.Never})
+	d := Message("", "bad bucket").At(&Pos{File: file, Line: 2, Column: 11})
+	p, s := sink.Stringify(Error, d)
+	assert.Equal(t, "error: index.ts:2:11: bad bucket\n"+
+		"    const b = new aws.s3.Bucket(\"b\");\n"+
+		"              ^\n", p+s)
+
+	// A position whose file cannot be read is still named.
+	d = Message("", "bad bucket").At(&Pos{File: filepath.Join(dir, "missing.ts"), Line: 2})
+	p, s = sink.Stringify(Error, d)
+	assert.Equal(t, "error: missing.ts:2: bad bucket\n", p+s)
+}
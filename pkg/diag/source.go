@@ -0,0 +1,66 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// FormatSource renders the line of source code that a position refers to, followed by a caret that marks its column,
+// in the style of a compiler's diagnostics.  If the position has no line number, or its line cannot be read, the
+// result is empty.
+func FormatSource(pos *Pos) string {
+	if pos == nil || pos.Line <= 0 {
+		return ""
+	}
+
+	f, err := os.Open(pos.File)
+	if err != nil {
+		return ""
+	}
+	defer contract.IgnoreClose(f)
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if line < pos.Line {
+			continue
+		}
+
+		text := strings.TrimRight(scanner.Text(), "\r")
+		var b bytes.Buffer
+		b.WriteString("    ")
+		b.WriteString(text)
+		b.WriteRune('\n')
+		if pos.Column > 0 && pos.Column <= len(text)+1 {
+			// Preserve any tabs in the line's prefix so that the caret lines up regardless of tab width.
+			b.WriteString("    ")
+			for _, c := range text[:pos.Column-1] {
+				if c == '\t' {
+					b.WriteRune('\t')
+				} else {
+					b.WriteRune(' ')
+				}
+			}
+			b.WriteString("^\n")
+		}
+		return b.String()
+	}
+	return ""
+}
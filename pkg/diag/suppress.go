@@ -0,0 +1,138 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/glob"
+)
+
+// Suppression silences the warnings that match it.  Each of its criteria that is set must match.
+type Suppression struct {
+	ID       ID             // the code of the warnings, if any.
+	URN      string         // a pattern of the URNs of the warnings' resources, in which `*` matches anything, if any.
+	Provider tokens.Package // the package of the provider of the warnings' resources, if any.
+	Reason   string         // why the warnings are suppressed.
+}
+
+func (s Suppression) String() string {
+	var criteria []string
+	if s.ID != 0 {
+		criteria = append(criteria, "code "+s.ID.Code())
+	}
+	if s.URN != "" {
+		criteria = append(criteria, fmt.Sprintf("URN '%s'", s.URN))
+	}
+	if s.Provider != "" {
+		criteria = append(criteria, fmt.Sprintf("provider '%s'", s.Provider))
+	}
+	return strings.Join(criteria, ", ")
+}
+
+// SuppressingSink is a sink that drops the warnings matching any of a set of suppressions, passing everything else
+// on to another sink.  It counts the warnings that each suppression drops, so that suppressions can be audited.
+type SuppressingSink struct {
+	Sink
+
+	suppressions []Suppression
+	patterns     []*regexp.Regexp // the compiled URN patterns of the suppressions, or nil for those without one.
+	counts       []int            // the number of warnings each suppression has dropped.
+	lock         sync.Mutex
+}
+
+// NewSuppressingSink creates a sink that drops the warnings that match any of the given suppressions, and passes
+// everything else on to the given sink.
+func NewSuppressingSink(sink Sink, suppressions []Suppression) *SuppressingSink {
+	patterns := make([]*regexp.Regexp, len(suppressions))
+	for i, s := range suppressions {
+		if s.URN != "" {
+			patterns[i] = glob.Compile(s.URN)
+		}
+	}
+	return &SuppressingSink{
+		Sink:         sink,
+		suppressions: suppressions,
+		patterns:     patterns,
+		counts:       make([]int, len(suppressions)),
+	}
+}
+
+// Logf issues a log message, unless it's a suppressed warning.
+func (s *SuppressingSink) Logf(sev Severity, diag *Diag, args ...interface{}) {
+	if sev == Warning {
+		s.Warningf(diag, args...)
+		return
+	}
+	s.Sink.Logf(sev, diag, args...)
+}
+
+// Warningf issues a new warning diagnostic, unless it's suppressed.
+func (s *SuppressingSink) Warningf(diag *Diag, args ...interface{}) {
+	if s.suppress(diag) {
+		return
+	}
+	s.Sink.Warningf(diag, args...)
+}
+
+// Suppressed returns the suppressions that have dropped warnings, along with the number each has dropped.
+func (s *SuppressingSink) Suppressed() map[Suppression]int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := make(map[Suppression]int)
+	for i, count := range s.counts {
+		if count > 0 {
+			result[s.suppressions[i]] += count
+		}
+	}
+	return result
+}
+
+// suppress returns true, and counts the warning, if any of the suppressions matches the given warning.
+func (s *SuppressingSink) suppress(diag *Diag) bool {
+	for i, sup := range s.suppressions {
+		if sup.ID != 0 && sup.ID != diag.ID {
+			continue
+		}
+		if s.patterns[i] != nil && !s.patterns[i].MatchString(string(diag.URN)) {
+			continue
+		}
+		if sup.Provider != "" && (diag.URN == "" || providerPackage(diag.URN) != sup.Provider) {
+			continue
+		}
+
+		s.lock.Lock()
+		s.counts[i]++
+		s.lock.Unlock()
+		return true
+	}
+	return false
+}
+
+// providerPackage returns the package of the provider of the resource with the given URN: that of its type or, for a
+// provider resource, such as `pulumi:providers:aws`, the package it provides.
+func providerPackage(urn resource.URN) tokens.Package {
+	typ := urn.Type()
+	if pkg := typ.Package(); pkg != "pulumi" || typ.Module().Name() != "providers" {
+		return pkg
+	}
+	return tokens.Package(typ.Name())
+}
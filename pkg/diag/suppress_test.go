@@ -0,0 +1,62 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// warningRecorder is a sink that records the messages of the warnings issued to it.
+type warningRecorder struct {
+	Sink
+	warnings []string
+}
+
+func (r *warningRecorder) Warningf(diag *Diag, args ...interface{}) {
+	r.warnings = append(r.warnings, diag.Message)
+}
+
+func TestSuppressingSink(t *testing.T) {
+	t.Parallel()
+
+	urn := func(typ tokens.Type, name string) resource.URN {
+		return resource.NewURN("stack", "proj", "", typ, tokens.QName(name))
+	}
+	bucket, provider := urn("aws:s3/bucket:Bucket", "logs"), urn("pulumi:providers:aws", "default")
+	cluster := urn("gcp:container/cluster:Cluster", "main")
+
+	deprecated := Suppression{ID: AnalyzeResourceFailureID, Reason: "tracked in #123"}
+	aws := Suppression{Provider: "aws", Reason: "noisy"}
+	logs := Suppression{URN: "*::logs", Reason: "legacy bucket"}
+
+	recorder := &warningRecorder{Sink: discardSink()}
+	sink := NewSuppressingSink(recorder, []Suppression{deprecated, aws, logs})
+	sink.Warningf(&Diag{URN: cluster, ID: AnalyzeResourceFailureID, Message: "coded"})
+	sink.Warningf(&Diag{URN: bucket, Message: "bucket"})
+	sink.Logf(Warning, &Diag{URN: provider, Message: "provider"})
+	sink.Warningf(&Diag{URN: cluster, Message: "cluster"})
+	sink.Warningf(&Diag{Message: "global"})
+	sink.Errorf(&Diag{URN: bucket, Message: "error"})
+
+	assert.Equal(t, []string{"cluster", "global"}, recorder.warnings)
+	assert.Equal(t, map[Suppression]int{deprecated: 1, aws: 2}, sink.Suppressed())
+	assert.Equal(t, "code PU2004", deprecated.String())
+	assert.Equal(t, "URN '*::logs'", logs.String())
+}
@@ -33,7 +33,7 @@ func Destroy(u UpdateInfo, ctx *Context, opts UpdateOptions, dryRun bool) (Resou
 	}
 	defer info.Close()
 
-	emitter, err := makeEventEmitter(ctx.Events, u)
+	emitter, err := makeEventEmitter(ctx.Events, u, opts.ShowSecrets)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +47,7 @@ func Destroy(u UpdateInfo, ctx *Context, opts UpdateOptions, dryRun bool) (Resou
 }
 
 func newDestroySource(
-	opts planOptions, proj *workspace.Project, pwd, main string,
+	opts planOptions, proj *workspace.Project, root, pwd, main string,
 	target *deploy.Target, plugctx *plugin.Context, dryRun bool) (deploy.Source, error) {
 
 	// For destroy, we consult the manifest for the plugin versions/ required to destroy it.
@@ -57,6 +57,9 @@ func newDestroySource(
 		if err := plugctx.Host.EnsurePlugins(target.Snapshot.Manifest.Plugins, kinds); err != nil {
 			return nil, err
 		}
+
+		// Start the plugins for the snapshot's providers concurrently, since each of them will be needed.
+		warmProviders(plugctx, target, nil)
 	}
 
 	// Create a nil source.  This simply returns "nothing" as the new state, which will cause the
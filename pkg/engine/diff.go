@@ -22,6 +22,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/sergi/go-diff/diffmatchpatch"
 
 	"github.com/pulumi/pulumi/pkg/diag/colors"
@@ -108,7 +109,7 @@ func writeVerbatim(b *bytes.Buffer, op deploy.StepOp, value string) {
 	writeWithIndentNoPrefix(b, 0, op, "%s", value)
 }
 
-func GetResourcePropertiesSummary(step StepEventMetadata, indent int) string {
+func GetResourcePropertiesSummary(step StepEventMetadata, indent int, debug bool) string {
 	var b bytes.Buffer
 
 	op := step.Op
@@ -140,6 +141,17 @@ func GetResourcePropertiesSummary(step StepEventMetadata, indent int) string {
 	if urn != "" {
 		writeWithIndentNoPrefix(&b, indent+1, simplePropOp, "[urn=%s]\n", urn)
 	}
+	if debug && step.Res != nil && step.Res.SourcePosition != "" {
+		writeWithIndentNoPrefix(&b, indent+1, simplePropOp, "[source=%s]\n", step.Res.SourcePosition)
+	}
+	if op != deploy.OpCreate && old != nil {
+		printResourceAge(&b, old, indent+1, simplePropOp)
+	}
+
+	// If this is a replacement, explain which properties caused it and why.
+	if op == deploy.OpReplace || op == deploy.OpCreateReplacement {
+		printReplaceReasons(&b, step, indent+1)
+	}
 
 	if step.Provider != "" {
 		new := step.New
@@ -170,6 +182,47 @@ func GetResourcePropertiesSummary(step StepEventMetadata, indent int) string {
 	return b.String()
 }
 
+// printResourceAge prints when an existing resource was created and last modified, and by which update, if known,
+// e.g. "[created=3 days ago]" and "[modified=2 hours ago by update 12]".
+func printResourceAge(b *bytes.Buffer, old *StepEventStateMetadata, indent int, op deploy.StepOp) {
+	var by string
+	if old.UpdateVersion > 0 {
+		by = fmt.Sprintf(" by update %d", old.UpdateVersion)
+	}
+
+	modified := !old.Modified.IsZero() && !old.Modified.Equal(old.Created)
+	if !old.Created.IsZero() {
+		if modified {
+			writeWithIndentNoPrefix(b, indent, op, "[created=%s]\n", humanize.Time(old.Created))
+		} else {
+			writeWithIndentNoPrefix(b, indent, op, "[created=%s%s]\n", humanize.Time(old.Created), by)
+		}
+	}
+	if modified {
+		writeWithIndentNoPrefix(b, indent, op, "[modified=%s%s]\n", humanize.Time(old.Modified), by)
+	}
+}
+
+// printReplaceReasons prints a line for each property that caused a replacement, along with the provider's explanation
+// if it offered one, e.g. "[replace because `engineVersion` changed and is immutable]".  If the old resource is deleted
+// before it is replaced, it says so, and prints a line for each dependent that is replaced as a result, in the order in
+// which they are deleted, e.g. "[also replaces dependent `web` (aws:ec2/instance:Instance)]".
+func printReplaceReasons(b *bytes.Buffer, step StepEventMetadata, indent int) {
+	for _, k := range step.Keys {
+		reason, has := step.Reasons[k]
+		if !has || reason == "" {
+			reason = "changed"
+		}
+		writeWithIndentNoPrefix(b, indent, step.Op, "[replace because `%s` %s]\n", k, reason)
+	}
+	if step.DeleteBeforeReplace {
+		writeWithIndentNoPrefix(b, indent, step.Op, "[delete before replace]\n")
+	}
+	for _, urn := range step.Dependents {
+		writeWithIndentNoPrefix(b, indent, step.Op, "[also replaces dependent `%s` (%s)]\n", urn.Name(), urn.Type())
+	}
+}
+
 func GetResourcePropertiesDetails(
 	step StepEventMetadata, indent int, planning bool, summary bool, debug bool) string {
 	var b bytes.Buffer
@@ -0,0 +1,62 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestResourcePropertiesSummaryAge(t *testing.T) {
+	old := &StepEventStateMetadata{
+		URN:           "urn:pulumi:test::test::pkgA:m:typA::resA",
+		Type:          "pkgA:m:typA",
+		Created:       time.Now().Add(-72 * time.Hour),
+		Modified:      time.Now().Add(-2 * time.Hour),
+		UpdateVersion: 12,
+	}
+	step := StepEventMetadata{Op: deploy.OpUpdate, URN: old.URN, Type: old.Type, Old: old, New: old, Res: old}
+
+	summary := GetResourcePropertiesSummary(step, 0, false)
+	assert.Contains(t, summary, "[created=3 days ago]")
+	assert.Contains(t, summary, "[modified=2 hours ago by update 12]")
+
+	// A resource that has not been modified since it was created credits its creation to the update.
+	old.Modified = old.Created
+	summary = GetResourcePropertiesSummary(step, 0, false)
+	assert.Contains(t, summary, "[created=3 days ago by update 12]")
+	assert.NotContains(t, summary, "[modified=")
+
+	// Nothing is known about the age of new resources.
+	step.Op, step.Old = deploy.OpCreate, nil
+	assert.NotContains(t, GetResourcePropertiesSummary(step, 0, false), "[created=")
+}
+
+func TestResourcePropertiesSummarySource(t *testing.T) {
+	res := &StepEventStateMetadata{
+		URN:            "urn:pulumi:test::test::pkgA:m:typA::resA",
+		Type:           "pkgA:m:typA",
+		SourcePosition: "index.ts:12:5",
+	}
+	step := StepEventMetadata{Op: deploy.OpCreate, URN: res.URN, Type: res.Type, New: res, Res: res}
+
+	// The position of the code that declared a resource is only shown when debugging.
+	assert.NotContains(t, GetResourcePropertiesSummary(step, 0, false), "[source=")
+	assert.Contains(t, GetResourcePropertiesSummary(step, 0, true), "[source=index.ts:12:5]")
+}
@@ -0,0 +1,98 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+// AcceptEnvVar is the environment variable that, when set to a truthy value, causes AssertGoldenSnapshot to rewrite
+// golden files with the snapshots it is given rather than comparing against them.
+const AcceptEnvVar = "PULUMI_ACCEPT"
+
+// SerializeGoldenSnapshot serializes a snapshot as indented JSON, in the same form a backend would persist it.  The
+// parts of the manifest that vary from run to run (the time, magic, version, and plugins) are omitted, as are the
+// times at which resources were created and modified, and the randomly-generated IDs of provider resources are
+// replaced with sequential ones, so that the same update always serializes to the same bytes.  The update must have
+// been given a random seed (see engine.UpdateOptions.RandomSeed) for the resources' own random seeds to be the same.
+func SerializeGoldenSnapshot(snap *deploy.Snapshot) ([]byte, error) {
+	deployment := stack.SerializeDeployment(snap)
+	deployment.Manifest = apitype.ManifestV1{}
+
+	ids := make(map[resource.ID]resource.ID)
+	for i, res := range deployment.Resources {
+		deployment.Resources[i].Created, deployment.Resources[i].Modified = nil, nil
+		if providers.IsProviderType(res.Type) && res.ID != "" {
+			ids[res.ID] = resource.ID(fmt.Sprintf("provider-%d", len(ids)))
+			deployment.Resources[i].ID = ids[res.ID]
+		}
+	}
+	for i, res := range deployment.Resources {
+		if res.Provider == "" {
+			continue
+		}
+		ref, err := providers.ParseReference(res.Provider)
+		if err != nil {
+			return nil, err
+		}
+		if id, ok := ids[ref.ID()]; ok {
+			ref, err = providers.NewReference(ref.URN(), id)
+			if err != nil {
+				return nil, err
+			}
+			deployment.Resources[i].Provider = ref.String()
+		}
+	}
+
+	bytes, err := json.MarshalIndent(deployment, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(bytes, '\n'), nil
+}
+
+// AssertGoldenSnapshot asserts that the given snapshot serializes to the contents of the golden file at path.  If
+// the AcceptEnvVar environment variable is set, the golden file is (re)written instead.
+func AssertGoldenSnapshot(t *testing.T, snap *deploy.Snapshot, path string) {
+	actual, err := SerializeGoldenSnapshot(snap)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if cmdutil.IsTruthy(os.Getenv(AcceptEnvVar)) {
+		assert.NoError(t, ioutil.WriteFile(path, actual, 0644))
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if !assert.NoError(t, err, "reading golden file; set %s=true to create it", AcceptEnvVar) {
+		return
+	}
+	assert.Equal(t, string(expected), string(actual), "snapshot differs from %s; set %s=true to update it",
+		path, AcceptEnvVar)
+}
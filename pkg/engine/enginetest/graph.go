@@ -0,0 +1,199 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+const (
+	// GraphComponentType is the type of the component resources in generated graphs.
+	GraphComponentType = tokens.Type("pkgA:index:Component")
+	// GraphCustomType is the type of the custom resources in generated graphs.  Their provider is package pkgA.
+	GraphCustomType = tokens.Type("pkgA:index:Custom")
+
+	// GraphValueKey is the input of generated custom resources whose changes can be updated in place.
+	GraphValueKey = resource.PropertyKey("value")
+	// GraphReplaceKey is the input of generated custom resources whose changes require replacement.
+	GraphReplaceKey = resource.PropertyKey("replaceKey")
+)
+
+// GraphResource describes a single resource in a generated graph.
+type GraphResource struct {
+	Name         string               // the resource's name, which is unique within its graph.
+	Custom       bool                 // true if the resource is custom, false if it is a component.
+	Parent       int                  // the index of the resource's parent, or -1 if it has none.
+	Dependencies []int                // the indices of the resources that this resource depends upon.
+	Inputs       resource.PropertyMap // the resource's inputs.
+}
+
+// Graph is a randomly-generated resource graph, in the order in which its resources are registered.  Parents and
+// dependencies always refer to earlier resources, and parents are always components.
+type Graph struct {
+	Resources []GraphResource
+	nextName  int
+}
+
+// GenerateGraph generates a random graph of up to n resources.
+func GenerateGraph(r *rand.Rand, n int) *Graph {
+	g := &Graph{}
+	for i := 0; i < n; i++ {
+		g.add(r)
+	}
+	return g
+}
+
+func (g *Graph) add(r *rand.Rand) {
+	res := GraphResource{
+		Name:   fmt.Sprintf("res%d", g.nextName),
+		Custom: r.Intn(3) != 0,
+		Parent: -1,
+	}
+	g.nextName++
+
+	count := len(g.Resources)
+	if count > 0 {
+		if parent := r.Intn(count); r.Intn(2) == 0 && !g.Resources[parent].Custom {
+			res.Parent = parent
+		}
+		for i, deps := 0, r.Intn(3); i < deps; i++ {
+			res.Dependencies = appendUnique(res.Dependencies, r.Intn(count))
+		}
+	}
+
+	res.Inputs = resource.PropertyMap{}
+	if res.Custom {
+		res.Inputs[GraphValueKey] = resource.NewNumberProperty(float64(r.Intn(3)))
+		res.Inputs[GraphReplaceKey] = resource.NewStringProperty(fmt.Sprintf("k%d", r.Intn(2)))
+	}
+	g.Resources = append(g.Resources, res)
+}
+
+// Mutate returns a random variation of the graph: some of its resources may be removed, have their inputs changed,
+// or gain new dependencies, and new resources may be added.  The receiver is left unchanged.
+func (g *Graph) Mutate(r *rand.Rand) *Graph {
+	result := &Graph{nextName: g.nextName}
+
+	// Decide which resources survive, and remap the indices of those that do.
+	remap := make(map[int]int)
+	for i, res := range g.Resources {
+		if r.Intn(5) == 0 {
+			continue
+		}
+		remap[i] = len(result.Resources)
+
+		copied := GraphResource{Name: res.Name, Custom: res.Custom, Parent: -1, Inputs: copyInputs(res.Inputs)}
+		if p, has := remap[res.Parent]; has && res.Parent != -1 {
+			copied.Parent = p
+		} else if res.Parent != -1 {
+			// The parent was removed; reparenting changes the resource's URN, so it is effectively a new resource.
+			copied.Name = fmt.Sprintf("res%d", result.nextName)
+			result.nextName++
+		}
+		for _, dep := range res.Dependencies {
+			if d, has := remap[dep]; has {
+				copied.Dependencies = append(copied.Dependencies, d)
+			}
+		}
+		if len(result.Resources) > 0 && r.Intn(4) == 0 {
+			copied.Dependencies = appendUnique(copied.Dependencies, r.Intn(len(result.Resources)))
+		}
+		if res.Custom {
+			switch r.Intn(4) {
+			case 0:
+				value := copied.Inputs[GraphValueKey].NumberValue()
+				copied.Inputs[GraphValueKey] = resource.NewNumberProperty(value + 1)
+			case 1:
+				copied.Inputs[GraphReplaceKey] = resource.NewStringProperty(fmt.Sprintf("k%d", r.Intn(2)))
+			}
+		}
+		result.Resources = append(result.Resources, copied)
+	}
+
+	for i, adds := 0, r.Intn(3); i < adds; i++ {
+		result.add(r)
+	}
+	return result
+}
+
+// Program returns a program that registers each of the graph's resources in order.
+func (g *Graph) Program() deploytest.ProgramFunc {
+	return func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		urns := make([]resource.URN, len(g.Resources))
+		for i, res := range g.Resources {
+			t := GraphComponentType
+			if res.Custom {
+				t = GraphCustomType
+			}
+			var parent resource.URN
+			if res.Parent != -1 {
+				parent = urns[res.Parent]
+			}
+			var deps []resource.URN
+			for _, dep := range res.Dependencies {
+				deps = append(deps, urns[dep])
+			}
+
+			urn, _, _, err := monitor.RegisterResource(t, res.Name, res.Custom, parent, false, deps, "", res.Inputs)
+			if err != nil {
+				return err
+			}
+			urns[i] = urn
+		}
+		return nil
+	}
+}
+
+// GraphProvider returns a mock provider for the custom resources in generated graphs.  Changes to GraphReplaceKey
+// require replacement; any other changes are updated in place.
+func GraphProvider() *deploytest.Provider {
+	return &deploytest.Provider{
+		DiffF: func(urn resource.URN, id resource.ID, olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+			if !olds[GraphReplaceKey].DeepEquals(news[GraphReplaceKey]) {
+				return plugin.DiffResult{
+					Changes:     plugin.DiffSome,
+					ReplaceKeys: []resource.PropertyKey{GraphReplaceKey},
+				}, nil
+			}
+			if !olds.DeepEquals(news) {
+				return plugin.DiffResult{Changes: plugin.DiffSome}, nil
+			}
+			return plugin.DiffResult{Changes: plugin.DiffNone}, nil
+		},
+	}
+}
+
+func appendUnique(indices []int, index int) []int {
+	for _, i := range indices {
+		if i == index {
+			return indices
+		}
+	}
+	return append(indices, index)
+}
+
+func copyInputs(inputs resource.PropertyMap) resource.PropertyMap {
+	result := make(resource.PropertyMap, len(inputs))
+	for k, v := range inputs {
+		result[k] = v
+	}
+	return result
+}
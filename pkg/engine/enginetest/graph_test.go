@@ -0,0 +1,108 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// validateInvariants checks that every intermediate snapshot the engine would have persisted during an operation, as
+// well as the final one, satisfies the snapshot invariants.
+func validateInvariants(t *testing.T, base *deploy.Snapshot) ValidateFunc {
+	return func(_ workspace.Project, _ deploy.Target, j *Journal, _ []engine.Event, err error) error {
+		for i := 0; i <= len(j.Entries); i++ {
+			prefix := &Journal{Entries: j.Entries[:i]}
+			assert.NoError(t, prefix.Snap(base).VerifyInvariants(), "after journal entry %d", i)
+		}
+		return err
+	}
+}
+
+func TestRandomGraphInvariants(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		r := rand.New(rand.NewSource(seed))
+
+		var graph *Graph
+		program := func(info plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+			return graph.Program()(info, monitor)
+		}
+		loaders := []*deploytest.ProviderLoader{
+			deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+				return GraphProvider(), nil
+			}),
+		}
+		p := NewPlan(program, loaders...)
+
+		// Create a random graph, then put it through a few rounds of random changes.
+		var snap *deploy.Snapshot
+		for round := 0; round < 4; round++ {
+			if graph == nil {
+				graph = GenerateGraph(r, 10)
+			} else {
+				graph = graph.Mutate(r)
+			}
+
+			p.Steps = []TestStep{{Op: engine.Update, SkipPreview: true, Validate: validateInvariants(t, snap)}}
+			snap = p.Run(t, snap)
+			if !assert.NoError(t, snap.VerifyInvariants(), "seed %d, round %d", seed, round) {
+				return
+			}
+		}
+
+		// Finally, destroy everything.
+		p.Steps = []TestStep{{Op: engine.Destroy, SkipPreview: true, Validate: validateInvariants(t, snap)}}
+		snap = p.Run(t, snap)
+		assert.NoError(t, snap.VerifyInvariants())
+		assert.Len(t, snap.Resources, 0, "seed %d", seed)
+	}
+}
+
+func TestMutateLeavesGraphUnchanged(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	graph := GenerateGraph(r, 10)
+
+	var names []string
+	var inputs []resource.PropertyMap
+	for _, res := range graph.Resources {
+		names, inputs = append(names, res.Name), append(inputs, copyInputs(res.Inputs))
+	}
+	mutated := graph.Mutate(r)
+	for i, res := range graph.Resources {
+		assert.Equal(t, names[i], res.Name)
+		assert.Equal(t, inputs[i], res.Inputs)
+	}
+
+	// Parents and dependencies must always refer to earlier resources, and parents must be components.
+	for i, res := range mutated.Resources {
+		if res.Parent != -1 {
+			assert.True(t, res.Parent < i)
+			assert.False(t, mutated.Resources[res.Parent].Custom)
+		}
+		for _, dep := range res.Dependencies {
+			assert.True(t, dep < i)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package enginetest runs the engine entirely in memory, against mock providers and programs built with the
+// deploytest package, so that provider and program authors can test the steps an update would take, and the
+// snapshots it would produce, without loading any plugins or talking to a backend.
+package enginetest
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// JournalEntryKind is the kind of a journal entry.
+type JournalEntryKind int
+
+const (
+	JournalEntryBegin   JournalEntryKind = 0 // a step has begun.
+	JournalEntrySuccess JournalEntryKind = 1 // a step has completed successfully.
+	JournalEntryFailure JournalEntryKind = 2 // a step has failed.
+	JournalEntryOutputs JournalEntryKind = 4 // a step's resource has registered its outputs.
+)
+
+// JournalEntry records a single event in the lifetime of a step.
+type JournalEntry struct {
+	Kind JournalEntryKind
+	Step deploy.Step
+}
+
+// Journal is an engine.SnapshotManager that records every step the engine performs, in order, rather than persisting
+// a snapshot.  The snapshot that a real snapshot manager would have produced can be recovered with Snap.
+type Journal struct {
+	Entries []JournalEntry
+	events  chan JournalEntry
+	cancel  chan bool
+	done    chan bool
+}
+
+var _ engine.SnapshotManager = (*Journal)(nil)
+
+// NewJournal creates a new, empty journal.  It must be closed before its entries are inspected.
+func NewJournal() *Journal {
+	j := &Journal{
+		events: make(chan JournalEntry),
+		cancel: make(chan bool),
+		done:   make(chan bool),
+	}
+	go func() {
+		for {
+			select {
+			case <-j.cancel:
+				close(j.done)
+				return
+			case e := <-j.events:
+				j.Entries = append(j.Entries, e)
+			}
+		}
+	}()
+	return j
+}
+
+func (j *Journal) Close() error {
+	close(j.cancel)
+	<-j.done
+
+	return nil
+}
+
+func (j *Journal) BeginMutation(step deploy.Step) (engine.SnapshotMutation, error) {
+	select {
+	case j.events <- JournalEntry{Kind: JournalEntryBegin, Step: step}:
+		return j, nil
+	case <-j.cancel:
+		return nil, errors.New("journal closed")
+	}
+}
+
+func (j *Journal) End(step deploy.Step, success bool) error {
+	kind := JournalEntryFailure
+	if success {
+		kind = JournalEntrySuccess
+	}
+	select {
+	case j.events <- JournalEntry{Kind: kind, Step: step}:
+		return nil
+	case <-j.cancel:
+		return errors.New("journal closed")
+	}
+}
+
+func (j *Journal) RegisterResourceOutputs(step deploy.Step) error {
+	select {
+	case j.events <- JournalEntry{Kind: JournalEntryOutputs, Step: step}:
+		return nil
+	case <-j.cancel:
+		return errors.New("journal closed")
+	}
+}
+
+func (j *Journal) RecordPlugin(plugin workspace.PluginInfo) error {
+	return nil
+}
+
+// Steps returns the steps that completed successfully, in the order in which they completed.
+func (j *Journal) Steps() []deploy.Step {
+	var steps []deploy.Step
+	for _, e := range j.Entries {
+		if e.Kind == JournalEntrySuccess {
+			steps = append(steps, e.Step)
+		}
+	}
+	return steps
+}
+
+// Snap replays the journal on top of the given base snapshot, returning the snapshot that the update produced.
+func (j *Journal) Snap(base *deploy.Snapshot) *deploy.Snapshot {
+	// Build up a list of current resources by replaying the journal.
+	resources, dones := []*resource.State{}, make(map[*resource.State]bool)
+	unreferenced := make(map[*resource.State]*resource.State)
+	ops, doneOps := []resource.Operation{}, make(map[*resource.State]bool)
+	for _, e := range j.Entries {
+		logging.Engine.V(7).Infof("%v %v (%v)", e.Step.Op(), e.Step.URN(), e.Kind)
+
+		// Begin journal entries add pending operations to the snapshot. As we see success or failure
+		// entries, we'll record them in doneOps.
+		if e.Kind == JournalEntryBegin {
+			switch e.Step.Op() {
+			case deploy.OpCreate, deploy.OpCreateReplacement:
+				ops = append(ops, resource.NewOperation(e.Step.New(), resource.OperationTypeCreating))
+			case deploy.OpDelete, deploy.OpDeleteReplaced:
+				ops = append(ops, resource.NewOperation(e.Step.Old(), resource.OperationTypeDeleting))
+			case deploy.OpRead, deploy.OpReadReplacement:
+				ops = append(ops, resource.NewOperation(e.Step.New(), resource.OperationTypeReading))
+			case deploy.OpUpdate:
+				ops = append(ops, resource.NewOperation(e.Step.New(), resource.OperationTypeUpdating))
+			}
+
+			continue
+		}
+
+		if e.Kind != JournalEntryOutputs {
+			switch e.Step.Op() {
+			case deploy.OpCreate, deploy.OpCreateReplacement, deploy.OpRead, deploy.OpReadReplacement, deploy.OpUpdate:
+				doneOps[e.Step.New()] = true
+			case deploy.OpDelete, deploy.OpDeleteReplaced:
+				doneOps[e.Step.Old()] = true
+			}
+		}
+
+		if e.Kind == JournalEntryFailure {
+			if del, ok := e.Step.(*deploy.DeleteStep); ok && del.Unreferenced() != nil {
+				unreferenced[e.Step.Old()] = del.Unreferenced()
+			}
+		}
+		if e.Kind != JournalEntrySuccess {
+			continue
+		}
+
+		switch e.Step.Op() {
+		case deploy.OpSame, deploy.OpUpdate:
+			resources = append(resources, e.Step.New())
+			dones[e.Step.Old()] = true
+		case deploy.OpCreate, deploy.OpCreateReplacement:
+			resources = append(resources, e.Step.New())
+		case deploy.OpDelete, deploy.OpDeleteReplaced:
+			dones[e.Step.Old()] = true
+		case deploy.OpReplace:
+			// do nothing.
+		case deploy.OpRead, deploy.OpReadReplacement:
+			resources = append(resources, e.Step.New())
+			if e.Step.Old() != nil {
+				dones[e.Step.Old()] = true
+			}
+		}
+	}
+
+	// Append any resources from the base snapshot that were not produced by the current snapshot.
+	// See backend.SnapshotManager.snap for why this works.
+	if base != nil {
+		for _, res := range base.Resources {
+			if !dones[res] {
+				if u, has := unreferenced[res]; has {
+					res = u
+				}
+				resources = append(resources, res)
+			}
+		}
+	}
+
+	// Append any pending operations.
+	var operations []resource.Operation
+	for _, op := range ops {
+		if !doneOps[op.Resource] {
+			operations = append(operations, op)
+		}
+	}
+
+	manifest := deploy.Manifest{}
+	manifest.Magic = manifest.NewMagic()
+	return deploy.NewSnapshot(manifest, resources, operations)
+}
@@ -0,0 +1,268 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cancel"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+type updateInfo struct {
+	project workspace.Project
+	target  deploy.Target
+}
+
+func (u *updateInfo) GetRoot() string {
+	return ""
+}
+
+func (u *updateInfo) GetProject() *workspace.Project {
+	return &u.project
+}
+
+func (u *updateInfo) GetTarget() *deploy.Target {
+	return &u.target
+}
+
+// TestOp is an engine operation, such as engine.Update, engine.Refresh, or engine.Destroy.
+type TestOp func(engine.UpdateInfo, *engine.Context, engine.UpdateOptions, bool) (engine.ResourceChanges, error)
+
+// ValidateFunc inspects the results of running an operation.  It receives the journal of the steps that were
+// performed, the events that were emitted, and the error, if any, that the operation returned.  Its own result
+// replaces that error.
+type ValidateFunc func(project workspace.Project, target deploy.Target, j *Journal,
+	events []engine.Event, err error) error
+
+// Run runs the operation against the given project and target, then calls the validator, if any.  Previews return a
+// nil snapshot.
+func (op TestOp) Run(project workspace.Project, target deploy.Target, opts engine.UpdateOptions,
+	dryRun bool, validate ValidateFunc) (*deploy.Snapshot, error) {
+
+	return op.RunWithContext(context.Background(), project, target, opts, dryRun, validate)
+}
+
+// RunWithContext runs the operation as Run does, canceling it if the given context is canceled.
+func (op TestOp) RunWithContext(callerCtx context.Context, project workspace.Project, target deploy.Target,
+	opts engine.UpdateOptions, dryRun bool, validate ValidateFunc) (*deploy.Snapshot, error) {
+
+	// Create an appropriate update info and context.
+	info := &updateInfo{project: project, target: target}
+
+	cancelCtx, _ := cancel.NewContext(callerCtx)
+	events := make(chan engine.Event)
+	journal := NewJournal()
+
+	ctx := &engine.Context{
+		Cancel:          cancelCtx,
+		Events:          events,
+		SnapshotManager: journal,
+	}
+
+	// Begin draining events.
+	var firedEvents []engine.Event
+	drained := make(chan bool)
+	go func() {
+		for e := range events {
+			firedEvents = append(firedEvents, e)
+		}
+		close(drained)
+	}()
+
+	// Run the step and its validator.
+	_, err := op(info, ctx, opts, dryRun)
+	contract.IgnoreClose(journal)
+	close(events)
+	<-drained
+
+	if validate != nil {
+		err = validate(project, target, journal, firedEvents, err)
+	}
+	if dryRun {
+		return nil, err
+	}
+
+	snap := journal.Snap(target.Snapshot)
+	if err == nil && snap != nil {
+		err = snap.VerifyIntegrity()
+	}
+	return snap, err
+}
+
+// TestStep is a single operation in a test plan.
+type TestStep struct {
+	Op              TestOp       // the operation to run.
+	ExpectFailure   bool         // true if the operation is expected to fail.
+	SkipPreview     bool         // true to skip previewing the operation before running it.
+	Validate        ValidateFunc // an optional function that checks the results of the operation.
+	ValidatePreview ValidateFunc // an optional function that checks the results of the operation's preview.
+}
+
+// TestPlan is a sequence of operations to run against a single stack, each starting from the snapshot that its
+// predecessor produced.
+type TestPlan struct {
+	Project            string
+	Stack              string
+	Runtime            string
+	Config             config.Map
+	Decrypter          config.Decrypter
+	Options            engine.UpdateOptions
+	Steps              []TestStep
+	CredentialProfiles map[string]*workspace.CredentialProfile
+
+	// true to preview each step against its own copy of the snapshot, as a backend would, rather than against the
+	// snapshot that the step then updates.  Previews of replacements otherwise mark the old resources for deletion.
+	IsolatePreviews bool
+}
+
+// DefaultRandomSeed is the seed from which the test plans that NewPlan creates derive the random seeds of their
+// resources, so that the same program always produces the same snapshots.
+const DefaultRandomSeed = "enginetest"
+
+// NewPlan creates a test plan that runs the given program against the given mock providers.
+func NewPlan(program deploytest.ProgramFunc, loaders ...*deploytest.ProviderLoader) *TestPlan {
+	host := deploytest.NewPluginHost(nil, nil, deploytest.NewLanguageRuntime(program), loaders...)
+	return &TestPlan{
+		Options:         engine.UpdateOptions{Host: host, RandomSeed: []byte(DefaultRandomSeed)},
+		IsolatePreviews: true,
+	}
+}
+
+func (p *TestPlan) getNames() (stack tokens.QName, project tokens.PackageName, runtime string) {
+	project = tokens.PackageName(p.Project)
+	if project == "" {
+		project = "test"
+	}
+	runtime = p.Runtime
+	if runtime == "" {
+		runtime = "test"
+	}
+	stack = tokens.QName(p.Stack)
+	if stack == "" {
+		stack = "test"
+	}
+	return stack, project, runtime
+}
+
+// NewURN returns the URN that a resource with the given type, name, and parent will have in this plan's stack.
+func (p *TestPlan) NewURN(typ tokens.Type, name string, parent resource.URN) resource.URN {
+	stack, project, _ := p.getNames()
+	var pt tokens.Type
+	if parent != "" {
+		pt = parent.Type()
+	}
+	return resource.NewURN(stack, project, pt, typ, tokens.QName(name))
+}
+
+// NewProviderURN returns the URN that a provider for the given package will have in this plan's stack.
+func (p *TestPlan) NewProviderURN(pkg tokens.Package, name string, parent resource.URN) resource.URN {
+	return p.NewURN(providers.MakeProviderType(pkg), name, parent)
+}
+
+// GetProject returns the project that this plan's operations run against.
+func (p *TestPlan) GetProject() workspace.Project {
+	_, projectName, runtime := p.getNames()
+
+	return workspace.Project{
+		Name:        projectName,
+		RuntimeInfo: workspace.NewProjectRuntimeInfo(runtime, nil),
+	}
+}
+
+// GetTarget returns the target that this plan's operations run against, starting from the given snapshot.
+func (p *TestPlan) GetTarget(snapshot *deploy.Snapshot) deploy.Target {
+	stack, _, _ := p.getNames()
+
+	cfg := p.Config
+	if cfg == nil {
+		cfg = config.Map{}
+	}
+
+	return deploy.Target{
+		Name:               stack,
+		Config:             cfg,
+		Decrypter:          p.Decrypter,
+		Snapshot:           snapshot,
+		CredentialProfiles: p.CredentialProfiles,
+	}
+}
+
+// Run runs each of the plan's steps in turn, previewing each first unless it asks otherwise, and returns the final
+// snapshot.
+func (p *TestPlan) Run(t *testing.T, snapshot *deploy.Snapshot) *deploy.Snapshot {
+	project, target := p.GetProject(), p.GetTarget(snapshot)
+
+	for _, step := range p.Steps {
+		if !step.SkipPreview {
+			preview := target
+			if p.IsolatePreviews {
+				preview.Snapshot = copySnapshot(t, target.Snapshot)
+			}
+			_, err := step.Op.Run(project, preview, p.Options, true, step.ValidatePreview)
+			if step.ExpectFailure {
+				assert.Error(t, err)
+				continue
+			}
+
+			assert.NoError(t, err)
+		}
+
+		var err error
+		target.Snapshot, err = step.Op.Run(project, target, p.Options, false, step.Validate)
+		if step.ExpectFailure {
+			assert.Error(t, err)
+			continue
+		}
+
+		assert.NoError(t, err)
+	}
+
+	return target.Snapshot
+}
+
+// copySnapshot returns a deep copy of the given snapshot, made by serializing and deserializing it as a backend would.
+func copySnapshot(t *testing.T, snap *deploy.Snapshot) *deploy.Snapshot {
+	if snap == nil {
+		return nil
+	}
+	copied, err := stack.DeserializeDeploymentV2(*stack.SerializeDeployment(snap))
+	assert.NoError(t, err)
+	return copied
+}
+
+// StepEvents returns the metadata of each step that the engine announced, in order, from the given events.  Unlike
+// the journal, this includes the steps of previews, along with the keys and reasons behind any replacements.
+func StepEvents(events []engine.Event) []engine.StepEventMetadata {
+	var steps []engine.StepEventMetadata
+	for _, e := range events {
+		if e.Type == engine.ResourcePreEvent {
+			steps = append(steps, e.Payload.(engine.ResourcePreEventPayload).Metadata)
+		}
+	}
+	return steps
+}
@@ -0,0 +1,107 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newTestPlan(t *testing.T) *TestPlan {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN,
+					news resource.PropertyMap) (resource.ID, resource.PropertyMap, resource.Status, error) {
+					return resource.ID(urn.Name() + "-id"), news, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.NewPropertyMapFromMap(map[string]interface{}{"foo": "bar"}))
+		assert.NoError(t, err)
+		return nil
+	}
+
+	return NewPlan(program, loaders...)
+}
+
+func TestPlanSteps(t *testing.T) {
+	p := newTestPlan(t)
+	resA := p.NewURN("pkgA:m:typA", "resA", "")
+
+	p.Steps = []TestStep{{
+		Op: engine.Update,
+		ValidatePreview: func(_ workspace.Project, _ deploy.Target, _ *Journal,
+			events []engine.Event, err error) error {
+			// The preview should announce the creation of the resource.
+			var found bool
+			for _, step := range StepEvents(events) {
+				if step.URN == resA {
+					assert.Equal(t, deploy.OpCreate, step.Op)
+					assert.Equal(t, "bar", step.New.Inputs["foo"].StringValue())
+					found = true
+				}
+			}
+			assert.True(t, found)
+			return err
+		},
+		Validate: func(_ workspace.Project, target deploy.Target, j *Journal, _ []engine.Event, err error) error {
+			// The update should create the default provider and the resource, in that order.
+			steps := j.Steps()
+			if assert.Len(t, steps, 2) {
+				assert.Equal(t, deploy.OpCreate, steps[0].Op())
+				assert.Equal(t, p.NewProviderURN("pkgA", "default", ""), steps[0].URN())
+				assert.Equal(t, deploy.OpCreate, steps[1].Op())
+				assert.Equal(t, resA, steps[1].URN())
+			}
+			return err
+		},
+	}}
+	snap := p.Run(t, nil)
+	assert.Len(t, snap.Resources, 2)
+}
+
+func TestGoldenSnapshotIsDeterministic(t *testing.T) {
+	run := func() []byte {
+		p := newTestPlan(t)
+		p.Steps = []TestStep{{Op: engine.Update}}
+		bytes, err := SerializeGoldenSnapshot(p.Run(t, nil))
+		assert.NoError(t, err)
+		return bytes
+	}
+
+	// Two independent runs of the same program should serialize identically, despite the random IDs that the
+	// engine assigns to providers, and the random seeds that it records for resources, which are derived from the
+	// plan's seed.
+	first, second := run(), run()
+	assert.Equal(t, string(first), string(second))
+	assert.Contains(t, string(first), `"id": "provider-0"`)
+	assert.Contains(t, string(first), `"provider": "urn:pulumi:test::test::pulumi:providers:pkgA::default::provider-0"`)
+	assert.Contains(t, string(first), `"id": "resA-id"`)
+	assert.Contains(t, string(first), `"randomSeed": `)
+}
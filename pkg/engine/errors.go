@@ -17,6 +17,7 @@ package engine
 import (
 	"fmt"
 
+	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 )
 
@@ -38,3 +39,8 @@ type DecryptError struct {
 func (d DecryptError) Error() string {
 	return fmt.Sprintf("failed to decrypt configuration key '%s': %s", d.Key, d.Err.Error())
 }
+
+// DiagnosticID returns the stable code for this class of errors.
+func (d DecryptError) DiagnosticID() diag.ID {
+	return diag.ConfigDecryptFailedID
+}
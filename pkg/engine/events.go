@@ -56,6 +56,7 @@ func cancelEvent() Event {
 // DiagEventPayload is the payload for an event with type `diag`
 type DiagEventPayload struct {
 	URN       resource.URN
+	ID        diag.ID
 	Prefix    string
 	Message   string
 	Color     colors.Colorization
@@ -79,6 +80,9 @@ type SummaryEventPayload struct {
 	MaybeCorrupt    bool            // true if one or more resources may be corrupt
 	Duration        time.Duration   // the duration of the entire update operation (zero values for previews)
 	ResourceChanges ResourceChanges // count of changed resources, useful for reporting
+	RefreshChanges  ResourceChanges // count of resources found changed by a refresh that preceded the update
+	OutputChanges   []string        // the names of the stack outputs that changed (or, for previews, may change)
+	StepTimings     []StepTiming    // how long each of the resource operations applied took (empty for previews)
 }
 
 type ResourceOperationFailedPayload struct {
@@ -100,15 +104,22 @@ type ResourcePreEventPayload struct {
 }
 
 type StepEventMetadata struct {
-	Op       deploy.StepOp           // the operation performed by this step.
-	URN      resource.URN            // the resource URN (for before and after).
-	Type     tokens.Type             // the type affected by this step.
-	Old      *StepEventStateMetadata // the state of the resource before performing this step.
-	New      *StepEventStateMetadata // the state of the resource after performing this step.
-	Res      *StepEventStateMetadata // the latest state for the resource that is known (worst case, old).
-	Keys     []resource.PropertyKey  // the keys causing replacement (only for CreateStep and ReplaceStep).
-	Logical  bool                    // true if this step represents a logical operation in the program.
-	Provider string                  // the provider that performed this step.
+	Op       deploy.StepOp                   // the operation performed by this step.
+	URN      resource.URN                    // the resource URN (for before and after).
+	Type     tokens.Type                     // the type affected by this step.
+	Old      *StepEventStateMetadata         // the state of the resource before performing this step.
+	New      *StepEventStateMetadata         // the state of the resource after performing this step.
+	Res      *StepEventStateMetadata         // the latest state for the resource that is known (worst case, old).
+	Keys     []resource.PropertyKey          // the keys causing replacement (only for CreateStep and ReplaceStep).
+	Reasons  map[resource.PropertyKey]string // the reasons, if any, that the keys cause replacement.
+	Logical  bool                            // true if this step represents a logical operation in the program.
+	Provider string                          // the provider that performed this step.
+
+	// DeleteBeforeReplace is true if the step replaces a resource by deleting it before creating its replacement.
+	DeleteBeforeReplace bool
+	// Dependents are the resources that are replaced because they depend upon a resource that is deleted before it is
+	// replaced, in the order in which they are deleted (only for ReplaceStep).
+	Dependents []resource.URN
 }
 
 type StepEventStateMetadata struct {
@@ -136,14 +147,24 @@ type StepEventStateMetadata struct {
 	// the resource's complete output state (as returned by the resource provider).  See "Inputs"
 	// for additional details about how data will be transformed before going into this map.
 	Outputs resource.PropertyMap
+	// the resource's input and output properties including the values of secrets, set only if the update was asked
+	// to show them.  These are meant only for the local display of events (see ShowSecrets): they are never recorded
+	// by backends or written to event logs.
+	PlaintextInputs, PlaintextOutputs resource.PropertyMap
 	// the resource's provider reference
 	Provider string
 	// InitErrors is the set of errors encountered in the process of initializing resource (i.e.,
 	// during create or update).
 	InitErrors []string
+	// the optional `file:line:column` position of the code that declared the resource.
+	SourcePosition string
+	// the times at which the resource was created and last modified, if known.
+	Created, Modified time.Time
+	// the version of the stack produced by the update that last created or updated the resource, if known.
+	UpdateVersion int
 }
 
-func makeEventEmitter(events chan<- Event, update UpdateInfo) (eventEmitter, error) {
+func makeEventEmitter(events chan<- Event, update UpdateInfo, showSecrets bool) (eventEmitter, error) {
 	target := update.GetTarget()
 	var secrets []string
 	if target.Config.HasSecureValue() {
@@ -165,23 +186,44 @@ func makeEventEmitter(events chan<- Event, update UpdateInfo) (eventEmitter, err
 
 	logging.AddGlobalFilter(logging.CreateFilter(secrets, "[secret]"))
 
+	paths, err := GetSecretPaths(update)
+	if err != nil {
+		return eventEmitter{}, err
+	}
+
 	return eventEmitter{
 		Chan: events,
+		Secrets: secretDisplay{
+			Show:  showSecrets,
+			Paths: paths,
+		},
 	}, nil
 }
 
 type eventEmitter struct {
-	Chan chan<- Event
+	Chan    chan<- Event
+	Secrets secretDisplay // how the values of secrets in resource properties are displayed.
+}
+
+// secretDisplay controls whether the values of secrets in the resource properties of events are redacted.
+type secretDisplay struct {
+	Show  bool                     // true if resource properties should include the values of secrets.
+	Paths resource.SecretPathRules // the rules that mark the values of properties at certain paths as secret.
 }
 
-func makeStepEventMetadata(op deploy.StepOp, step deploy.Step, debug bool) StepEventMetadata {
+func makeStepEventMetadata(op deploy.StepOp, step deploy.Step, debug bool, secrets secretDisplay) StepEventMetadata {
 	contract.Assert(op == step.Op() || step.Op() == deploy.OpRefresh)
 
 	var keys []resource.PropertyKey
+	var reasons map[resource.PropertyKey]string
+	var deleteBeforeReplace bool
+	var dependents []resource.URN
 	if step.Op() == deploy.OpCreateReplacement {
-		keys = step.(*deploy.CreateStep).Keys()
+		keys, reasons = step.(*deploy.CreateStep).Keys(), step.(*deploy.CreateStep).Reasons()
 	} else if step.Op() == deploy.OpReplace {
-		keys = step.(*deploy.ReplaceStep).Keys()
+		replace := step.(*deploy.ReplaceStep)
+		keys, reasons = replace.Keys(), replace.Reasons()
+		deleteBeforeReplace, dependents = replace.DeleteBeforeReplace(), replace.Dependents()
 	}
 
 	return StepEventMetadata{
@@ -189,37 +231,98 @@ func makeStepEventMetadata(op deploy.StepOp, step deploy.Step, debug bool) StepE
 		URN:      step.URN(),
 		Type:     step.Type(),
 		Keys:     keys,
-		Old:      makeStepEventStateMetadata(step.Old(), debug),
-		New:      makeStepEventStateMetadata(step.New(), debug),
-		Res:      makeStepEventStateMetadata(step.Res(), debug),
+		Reasons:  reasons,
+		Old:      makeStepEventStateMetadata(step.Old(), debug, secrets),
+		New:      makeStepEventStateMetadata(step.New(), debug, secrets),
+		Res:      makeStepEventStateMetadata(step.Res(), debug, secrets),
 		Logical:  step.Logical(),
 		Provider: step.Provider(),
+
+		DeleteBeforeReplace: deleteBeforeReplace,
+		Dependents:          dependents,
 	}
 }
 
-func makeStepEventStateMetadata(state *resource.State, debug bool) *StepEventStateMetadata {
+// NewStepEventStateMetadata creates the display metadata for the given resource state, filtering out any secrets.
+func NewStepEventStateMetadata(state *resource.State, debug bool) *StepEventStateMetadata {
+	return makeStepEventStateMetadata(state, debug, secretDisplay{})
+}
+
+func makeStepEventStateMetadata(state *resource.State, debug bool, secrets secretDisplay) *StepEventStateMetadata {
 	if state == nil {
 		return nil
 	}
 
-	return &StepEventStateMetadata{
-		Type:       state.Type,
-		URN:        state.URN,
-		Custom:     state.Custom,
-		Delete:     state.Delete,
-		ID:         state.ID,
-		Parent:     state.Parent,
-		Protect:    state.Protect,
-		Inputs:     filterPropertyMap(state.Inputs, debug),
-		Outputs:    filterPropertyMap(state.Outputs, debug),
-		Provider:   state.Provider,
-		InitErrors: state.InitErrors,
+	// Redact the values that are secret by virtue of their paths.  If we've been asked to show secrets, keep their
+	// values alongside for display.
+	inputs, outputs := state.Inputs(), state.Outputs()
+	redact := func(resource.PropertyValue) resource.PropertyValue { return redactedSecretValue }
+	paths := secrets.Paths.ForResource(state)
+
+	md := &StepEventStateMetadata{
+		Type:           state.Type,
+		URN:            state.URN,
+		Custom:         state.Custom,
+		Delete:         state.Delete,
+		ID:             state.ID,
+		Parent:         state.Parent,
+		Protect:        state.Protect,
+		Inputs:         filterPropertyMap(paths.Redact(state.Type, inputs, redact), debug, false),
+		Outputs:        filterPropertyMap(paths.Redact(state.Type, outputs, redact), debug, false),
+		Provider:       state.Provider,
+		InitErrors:     state.InitErrors,
+		SourcePosition: state.SourcePosition,
+		Created:        state.Created,
+		Modified:       state.Modified,
+		UpdateVersion:  state.UpdateVersion,
+	}
+	if secrets.Show {
+		md.PlaintextInputs = filterPropertyMap(inputs, debug, true)
+		md.PlaintextOutputs = filterPropertyMap(outputs, debug, true)
+	}
+	return md
+}
+
+// ShowSecrets returns a copy of the given event whose resource properties include the values of secrets, if the update
+// was asked to show them.  The event's own resource properties are left redacted, so that the plaintext reaches only
+// the local display that asks for it, and never the events that backends record.
+func ShowSecrets(e Event) Event {
+	showState := func(md *StepEventStateMetadata) *StepEventStateMetadata {
+		if md == nil || (md.PlaintextInputs == nil && md.PlaintextOutputs == nil) {
+			return md
+		}
+		shown := *md
+		shown.Inputs, shown.Outputs = md.PlaintextInputs, md.PlaintextOutputs
+		return &shown
+	}
+	show := func(md StepEventMetadata) StepEventMetadata {
+		md.Old, md.New, md.Res = showState(md.Old), showState(md.New), showState(md.Res)
+		return md
 	}
+
+	switch p := e.Payload.(type) {
+	case ResourcePreEventPayload:
+		p.Metadata = show(p.Metadata)
+		e.Payload = p
+	case ResourceOutputsEventPayload:
+		p.Metadata = show(p.Metadata)
+		e.Payload = p
+	case ResourceOperationFailedPayload:
+		p.Metadata = show(p.Metadata)
+		e.Payload = p
+	}
+	return e
 }
 
-func filterPropertyMap(propertyMap resource.PropertyMap, debug bool) resource.PropertyMap {
+func filterPropertyMap(propertyMap resource.PropertyMap, debug, showSecrets bool) resource.PropertyMap {
 	mappable := propertyMap.Mappable()
 
+	// Unless we've been asked to show them, replace the values of any secrets in strings with a placeholder.
+	filterString := logging.FilterString
+	if showSecrets {
+		filterString = func(s string) string { return s }
+	}
+
 	var filterValue func(v interface{}) interface{}
 
 	filterPropertyValue := func(pv resource.PropertyValue) resource.PropertyValue {
@@ -243,7 +346,7 @@ func filterPropertyMap(propertyMap resource.PropertyMap, debug bool) resource.Pr
 			return v
 		case string:
 			// have to ensure we filter out secrets.
-			return logging.FilterString(t)
+			return filterString(t)
 		case *resource.Asset:
 			text := t.Text
 			if text != "" {
@@ -254,7 +357,7 @@ func filterPropertyMap(propertyMap resource.PropertyMap, debug bool) resource.Pr
 				// progress/diffs/etc.
 				if t.IsUserProgramCode() {
 					// also make sure we filter this in case there are any secrets in the code.
-					text = logging.FilterString(resource.MassageIfUserProgramCodeAsset(t, debug).Text)
+					text = filterString(resource.MassageIfUserProgramCodeAsset(t, debug).Text)
 				} else {
 					// We need to have some string here so that we preserve that this is a
 					// text-asset
@@ -334,7 +437,7 @@ func (e *eventEmitter) resourceOperationFailedEvent(
 	e.Chan <- Event{
 		Type: ResourceOperationFailed,
 		Payload: ResourceOperationFailedPayload{
-			Metadata: makeStepEventMetadata(step.Op(), step, debug),
+			Metadata: makeStepEventMetadata(step.Op(), step, debug, e.Secrets),
 			Status:   status,
 			Steps:    steps,
 		},
@@ -347,7 +450,7 @@ func (e *eventEmitter) resourceOutputsEvent(op deploy.StepOp, step deploy.Step,
 	e.Chan <- Event{
 		Type: ResourceOutputsEvent,
 		Payload: ResourceOutputsEventPayload{
-			Metadata: makeStepEventMetadata(op, step, debug),
+			Metadata: makeStepEventMetadata(op, step, debug, e.Secrets),
 			Planning: planning,
 			Debug:    debug,
 		},
@@ -362,7 +465,7 @@ func (e *eventEmitter) resourcePreEvent(
 	e.Chan <- Event{
 		Type: ResourcePreEvent,
 		Payload: ResourcePreEventPayload{
-			Metadata: makeStepEventMetadata(step.Op(), step, debug),
+			Metadata: makeStepEventMetadata(step.Op(), step, debug, e.Secrets),
 			Planning: planning,
 			Debug:    debug,
 		},
@@ -389,7 +492,7 @@ func (e *eventEmitter) preludeEvent(isPreview bool, cfg config.Map) {
 	}
 }
 
-func (e *eventEmitter) previewSummaryEvent(resourceChanges ResourceChanges) {
+func (e *eventEmitter) previewSummaryEvent(resourceChanges, refreshChanges ResourceChanges, outputChanges []string) {
 	contract.Requiref(e != nil, "e", "!= nil")
 
 	e.Chan <- Event{
@@ -399,12 +502,15 @@ func (e *eventEmitter) previewSummaryEvent(resourceChanges ResourceChanges) {
 			MaybeCorrupt:    false,
 			Duration:        0,
 			ResourceChanges: resourceChanges,
+			RefreshChanges:  refreshChanges,
+			OutputChanges:   outputChanges,
 		},
 	}
 }
 
 func (e *eventEmitter) updateSummaryEvent(maybeCorrupt bool,
-	duration time.Duration, resourceChanges ResourceChanges) {
+	duration time.Duration, resourceChanges, refreshChanges ResourceChanges, outputChanges []string,
+	stepTimings []StepTiming) {
 	contract.Requiref(e != nil, "e", "!= nil")
 
 	e.Chan <- Event{
@@ -414,6 +520,9 @@ func (e *eventEmitter) updateSummaryEvent(maybeCorrupt bool,
 			MaybeCorrupt:    maybeCorrupt,
 			Duration:        duration,
 			ResourceChanges: resourceChanges,
+			RefreshChanges:  refreshChanges,
+			OutputChanges:   outputChanges,
+			StepTimings:     stepTimings,
 		},
 	}
 }
@@ -426,6 +535,7 @@ func diagEvent(e *eventEmitter, d *diag.Diag, prefix, msg string, sev diag.Sever
 		Type: DiagEvent,
 		Payload: DiagEventPayload{
 			URN:       d.URN,
+			ID:        d.ID,
 			Prefix:    logging.FilterString(prefix),
 			Message:   logging.FilterString(msg),
 			Color:     colors.Raw,
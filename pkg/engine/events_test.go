@@ -0,0 +1,91 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+func TestFilterPropertyMapSecrets(t *testing.T) {
+	logging.AddGlobalFilter(logging.CreateFilter([]string{"hunter2-password"}, "[secret]"))
+
+	props := resource.PropertyMap{
+		"password": resource.NewStringProperty("hunter2-password"),
+		"tags": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.NewStringProperty("user=admin;pass=hunter2-password"),
+		}),
+		"size": resource.NewNumberProperty(3),
+	}
+
+	redacted := filterPropertyMap(props, false, false)
+	assert.Equal(t, "[secret]", redacted["password"].StringValue())
+	assert.Equal(t, "user=admin;pass=[secret]", redacted["tags"].ArrayValue()[0].StringValue())
+	assert.Equal(t, float64(3), redacted["size"].NumberValue())
+
+	shown := filterPropertyMap(props, false, true)
+	assert.Equal(t, "hunter2-password", shown["password"].StringValue())
+	assert.Equal(t, "user=admin;pass=hunter2-password", shown["tags"].ArrayValue()[0].StringValue())
+}
+
+func TestStepEventStateMetadataSecretPaths(t *testing.T) {
+	paths, err := resource.ParseSecretPathRules([]string{"aws:iam/accessKey:AccessKey:secret"})
+	assert.NoError(t, err)
+
+	outputs := resource.PropertyMap{
+		"id":     resource.NewStringProperty("AKIA123"),
+		"secret": resource.NewStringProperty("wJalrXUtnFEMI"),
+	}
+	state := resource.NewState("aws:iam/accessKey:AccessKey", "urn:pulumi:dev::proj::aws:iam/accessKey:AccessKey::key",
+		true, false, "AKIA123", resource.PropertyMap{}, outputs, "", false, false, nil, nil, "")
+
+	redacted := makeStepEventStateMetadata(state, false, secretDisplay{Paths: paths})
+	assert.Equal(t, "[secret]", redacted.Outputs["secret"].StringValue())
+	assert.Equal(t, "AKIA123", redacted.Outputs["id"].StringValue())
+	assert.Equal(t, "wJalrXUtnFEMI", state.Outputs()["secret"].StringValue())
+
+	// Showing secrets leaves the event's properties redacted, and keeps their values only for display.
+	shown := makeStepEventStateMetadata(state, false, secretDisplay{Show: true, Paths: paths})
+	assert.Equal(t, "[secret]", shown.Outputs["secret"].StringValue())
+	assert.Equal(t, "wJalrXUtnFEMI", shown.PlaintextOutputs["secret"].StringValue())
+
+	e := Event{Type: ResourcePreEvent, Payload: ResourcePreEventPayload{Metadata: StepEventMetadata{New: shown}}}
+	displayed := ShowSecrets(e).Payload.(ResourcePreEventPayload).Metadata.New
+	assert.Equal(t, "wJalrXUtnFEMI", displayed.Outputs["secret"].StringValue())
+	assert.Equal(t, "[secret]", shown.Outputs["secret"].StringValue())
+}
+
+func TestStepEventStateMetadataAdditionalSecretOutputs(t *testing.T) {
+	outputs := resource.PropertyMap{
+		"endpoint": resource.NewStringProperty("db.example.com"),
+		"token":    resource.NewStringProperty("tok-123"),
+	}
+	state := resource.NewState("my:index:Db", "urn:pulumi:dev::proj::my:index:Db::db",
+		true, false, "db", resource.PropertyMap{}, outputs, "", false, false, nil, nil, "")
+	state.AdditionalSecretOutputs = []resource.PropertyKey{"token"}
+
+	// The resource's own secret outputs are redacted even if no rules apply to its type.
+	redacted := makeStepEventStateMetadata(state, false, secretDisplay{})
+	assert.Equal(t, "[secret]", redacted.Outputs["token"].StringValue())
+	assert.Equal(t, "db.example.com", redacted.Outputs["endpoint"].StringValue())
+
+	shown := makeStepEventStateMetadata(state, false, secretDisplay{Show: true})
+	assert.Equal(t, "[secret]", shown.Outputs["token"].StringValue())
+	assert.Equal(t, "tok-123", shown.PlaintextOutputs["token"].StringValue())
+}
@@ -17,6 +17,7 @@ package engine
 import (
 	"bytes"
 	"fmt"
+	"os"
 
 	"github.com/pulumi/pulumi/pkg/diag"
 
@@ -57,42 +58,42 @@ func (s *eventSink) Logf(sev diag.Severity, d *diag.Diag, args ...interface{}) {
 
 func (s *eventSink) Debugf(d *diag.Diag, args ...interface{}) {
 	// For debug messages, write both to the glogger and a stream, if there is one.
-	logging.V(3).Infof(d.Message, args...)
+	logging.Engine.V(3).Infof(d.Message, args...)
 	prefix, msg := s.Stringify(diag.Debug, d, args...)
-	if logging.V(9) {
-		logging.V(9).Infof("eventSink::Debug(%v)", msg[:len(msg)-1])
+	if logging.Engine.V(9).Enabled() {
+		logging.Engine.V(9).Infof("eventSink::Debug(%v)", msg[:len(msg)-1])
 	}
 	s.events.diagDebugEvent(d, prefix, msg, s.statusSink)
 }
 
 func (s *eventSink) Infof(d *diag.Diag, args ...interface{}) {
 	prefix, msg := s.Stringify(diag.Info, d, args...)
-	if logging.V(5) {
-		logging.V(5).Infof("eventSink::Info(%v)", msg[:len(msg)-1])
+	if logging.Engine.V(5).Enabled() {
+		logging.Engine.V(5).Infof("eventSink::Info(%v)", msg[:len(msg)-1])
 	}
 	s.events.diagInfoEvent(d, prefix, msg, s.statusSink)
 }
 
 func (s *eventSink) Infoerrf(d *diag.Diag, args ...interface{}) {
 	prefix, msg := s.Stringify(diag.Info /* not Infoerr, just "info: "*/, d, args...)
-	if logging.V(5) {
-		logging.V(5).Infof("eventSink::Infoerr(%v)", msg[:len(msg)-1])
+	if logging.Engine.V(5).Enabled() {
+		logging.Engine.V(5).Infof("eventSink::Infoerr(%v)", msg[:len(msg)-1])
 	}
 	s.events.diagInfoerrEvent(d, prefix, msg, s.statusSink)
 }
 
 func (s *eventSink) Errorf(d *diag.Diag, args ...interface{}) {
 	prefix, msg := s.Stringify(diag.Error, d, args...)
-	if logging.V(5) {
-		logging.V(5).Infof("eventSink::Error(%v)", msg[:len(msg)-1])
+	if logging.Engine.V(5).Enabled() {
+		logging.Engine.V(5).Infof("eventSink::Error(%v)", msg[:len(msg)-1])
 	}
 	s.events.diagErrorEvent(d, prefix, msg, s.statusSink)
 }
 
 func (s *eventSink) Warningf(d *diag.Diag, args ...interface{}) {
 	prefix, msg := s.Stringify(diag.Warning, d, args...)
-	if logging.V(5) {
-		logging.V(5).Infof("eventSink::Warning(%v)", msg[:len(msg)-1])
+	if logging.Engine.V(5).Enabled() {
+		logging.Engine.V(5).Infof("eventSink::Warning(%v)", msg[:len(msg)-1])
 	}
 	s.events.diagWarningEvent(d, prefix, msg, s.statusSink)
 }
@@ -122,6 +123,13 @@ func (s *eventSink) Stringify(sev diag.Severity, d *diag.Diag, args ...interface
 	var buffer bytes.Buffer
 	buffer.WriteString(colors.SpecNote)
 
+	// If the diagnostic is associated with a position in the program's source, lead with it.
+	if d.Pos != nil {
+		pwd, _ := os.Getwd()
+		buffer.WriteString(d.Pos.Rel(pwd))
+		buffer.WriteString(": ")
+	}
+
 	if d.Raw {
 		buffer.WriteString(d.Message)
 	} else {
@@ -131,8 +139,8 @@ func (s *eventSink) Stringify(sev diag.Severity, d *diag.Diag, args ...interface
 	buffer.WriteString(colors.Reset)
 	buffer.WriteRune('\n')
 
-	// TODO[pulumi/pulumi#15]: support Clang-style expressive diagnostics.  This would entail, for example, using
-	//     the buffer within the target document, to demonstrate the offending line/column range of code.
+	// Show the offending line of source code, if we can find it, with the position's column marked.
+	buffer.WriteString(diag.FormatSource(d.Pos))
 
 	return prefix.String(), buffer.String()
 }
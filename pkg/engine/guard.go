@@ -0,0 +1,105 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// checkGuard returns an error if the given stack guard forbids changing the stack at the given time.  Every violated
+// restriction is reported.  If the guard is being overridden it is not enforced, but a warning records the reason.
+func checkGuard(guard *workspace.StackGuard, opts planOptions, now time.Time) error {
+	if guard == nil {
+		return nil
+	}
+
+	var problems []string
+	if guard.AllowedHours != "" {
+		start, end, err := parseAllowedHours(guard.AllowedHours)
+		if err != nil {
+			return err
+		}
+		if !hourAllowed(now.UTC().Hour(), start, end) {
+			problems = append(problems,
+				fmt.Sprintf("changes are only allowed between %02d:00 and %02d:00 UTC", start, end))
+		}
+	}
+	if guard.RequireApprovalTag != "" {
+		approved, err := hasApprovalTag(guard.RequireApprovalTag, opts.SourceTags)
+		if err != nil {
+			return err
+		}
+		if !approved {
+			problems = append(problems,
+				fmt.Sprintf("the commit being deployed has no tag matching '%s'", guard.RequireApprovalTag))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	if opts.GuardOverrideReason != "" {
+		opts.Diag.Warningf(diag.GetGuardOverriddenWarning(""), strings.Join(problems, "; "), opts.GuardOverrideReason)
+		return nil
+	}
+	return errors.Errorf("the stack's guard forbids this operation: %s; "+
+		"pass --override-guard with a reason to proceed anyway", strings.Join(problems, "; "))
+}
+
+// parseAllowedHours parses a range of hours of the form "start-end", where start is the first hour during which
+// changes are allowed and end is the hour at which they are no longer allowed.  The range wraps around midnight if
+// end is less than start.
+func parseAllowedHours(hours string) (int, int, error) {
+	parts := strings.Split(hours, "-")
+	if len(parts) == 2 {
+		start, startErr := strconv.Atoi(strings.TrimSpace(parts[0]))
+		end, endErr := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if startErr == nil && endErr == nil && start >= 0 && start < 24 && end >= 0 && end <= 24 && start != end {
+			return start, end, nil
+		}
+	}
+	return 0, 0, errors.Errorf("invalid allowedHours '%s' in the stack's guard; expected a range like '9-17'", hours)
+}
+
+// hourAllowed returns true if the given hour falls within the range [start, end).
+func hourAllowed(hour, start, end int) bool {
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// hasApprovalTag returns true if any of the given tags matches the given pattern.
+func hasApprovalTag(pattern string, tags []string) (bool, error) {
+	for _, tag := range tags {
+		matched, err := path.Match(pattern, tag)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid requireApprovalTag '%s' in the stack's guard", pattern)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
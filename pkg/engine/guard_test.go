@@ -0,0 +1,93 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestParseAllowedHours(t *testing.T) {
+	start, end, err := parseAllowedHours("9-17")
+	assert.NoError(t, err)
+	assert.Equal(t, 9, start)
+	assert.Equal(t, 17, end)
+
+	start, end, err = parseAllowedHours(" 22 - 6 ")
+	assert.NoError(t, err)
+	assert.Equal(t, 22, start)
+	assert.Equal(t, 6, end)
+
+	for _, hours := range []string{"", "9", "9-", "9-9", "24-2", "3-25", "a-b", "1-2-3"} {
+		_, _, err = parseAllowedHours(hours)
+		assert.Error(t, err, hours)
+	}
+}
+
+func TestHourAllowed(t *testing.T) {
+	assert.True(t, hourAllowed(9, 9, 17))
+	assert.True(t, hourAllowed(16, 9, 17))
+	assert.False(t, hourAllowed(17, 9, 17))
+	assert.False(t, hourAllowed(8, 9, 17))
+
+	// Ranges may wrap around midnight.
+	assert.True(t, hourAllowed(23, 22, 6))
+	assert.True(t, hourAllowed(0, 22, 6))
+	assert.False(t, hourAllowed(6, 22, 6))
+	assert.False(t, hourAllowed(12, 22, 6))
+}
+
+func TestCheckGuard(t *testing.T) {
+	noon := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2018, 7, 1, 23, 0, 0, 0, time.UTC)
+	guard := &workspace.StackGuard{AllowedHours: "9-17", RequireApprovalTag: "approved/*"}
+
+	var stderr bytes.Buffer
+	opts := planOptions{
+		Diag: diag.DefaultSink(&bytes.Buffer{}, &stderr, diag.FormatOptions{Color: colors.Never}),
+	}
+
+	// Without a guard, anything goes.
+	assert.NoError(t, checkGuard(nil, opts, night))
+
+	// Both restrictions must be satisfied, and each violation is reported.
+	opts.SourceTags = []string{"v1.0", "approved/alice"}
+	assert.NoError(t, checkGuard(guard, opts, noon))
+
+	err := checkGuard(guard, opts, night)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "between 09:00 and 17:00 UTC")
+
+	opts.SourceTags = []string{"v1.0"}
+	err = checkGuard(guard, opts, night)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "between 09:00 and 17:00 UTC")
+	assert.Contains(t, err.Error(), "no tag matching 'approved/*'")
+
+	// Overriding the guard allows the operation, but warns with the reason.
+	opts.GuardOverrideReason = "emergency fix"
+	assert.NoError(t, checkGuard(guard, opts, night))
+	assert.Contains(t, stderr.String(), "emergency fix")
+
+	// A malformed guard is always an error.
+	assert.Error(t, checkGuard(&workspace.StackGuard{AllowedHours: "noon"}, opts, noon))
+}
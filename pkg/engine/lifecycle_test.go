@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package engine
+package engine_test
 
 import (
 	"context"
@@ -20,361 +20,38 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
 
 	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/engine/enginetest"
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
 	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/discovery"
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/tokens"
-	"github.com/pulumi/pulumi/pkg/util/cancel"
-	"github.com/pulumi/pulumi/pkg/util/contract"
-	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil/rpcerror"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
-type JournalEntryKind int
-
-const (
-	JournalEntryBegin   JournalEntryKind = 0
-	JournalEntrySuccess JournalEntryKind = 1
-	JournalEntryFailure JournalEntryKind = 2
-	JournalEntryOutputs JournalEntryKind = 4
-)
-
-type JournalEntry struct {
-	Kind JournalEntryKind
-	Step deploy.Step
-}
-
-type Journal struct {
-	Entries []JournalEntry
-	events  chan JournalEntry
-	cancel  chan bool
-	done    chan bool
-}
-
-func (j *Journal) Close() error {
-	close(j.cancel)
-	<-j.done
-
-	return nil
-}
-
-func (j *Journal) BeginMutation(step deploy.Step) (SnapshotMutation, error) {
-	select {
-	case j.events <- JournalEntry{Kind: JournalEntryBegin, Step: step}:
-		return j, nil
-	case <-j.cancel:
-		return nil, errors.New("journal closed")
-	}
-}
-
-func (j *Journal) End(step deploy.Step, success bool) error {
-	kind := JournalEntryFailure
-	if success {
-		kind = JournalEntrySuccess
-	}
-	select {
-	case j.events <- JournalEntry{Kind: kind, Step: step}:
-		return nil
-	case <-j.cancel:
-		return errors.New("journal closed")
-	}
-}
-
-func (j *Journal) RegisterResourceOutputs(step deploy.Step) error {
-	select {
-	case j.events <- JournalEntry{Kind: JournalEntryOutputs, Step: step}:
-		return nil
-	case <-j.cancel:
-		return errors.New("journal closed")
-	}
-}
-
-func (j *Journal) RecordPlugin(plugin workspace.PluginInfo) error {
-	return nil
-}
-
-func (j *Journal) Snap(base *deploy.Snapshot) *deploy.Snapshot {
-	// Build up a list of current resources by replaying the journal.
-	resources, dones := []*resource.State{}, make(map[*resource.State]bool)
-	ops, doneOps := []resource.Operation{}, make(map[*resource.State]bool)
-	for _, e := range j.Entries {
-		logging.V(7).Infof("%v %v (%v)", e.Step.Op(), e.Step.URN(), e.Kind)
-
-		// Begin journal entries add pending operations to the snapshot. As we see success or failure
-		// entries, we'll record them in doneOps.
-		if e.Kind == JournalEntryBegin {
-			switch e.Step.Op() {
-			case deploy.OpCreate, deploy.OpCreateReplacement:
-				ops = append(ops, resource.NewOperation(e.Step.New(), resource.OperationTypeCreating))
-			case deploy.OpDelete, deploy.OpDeleteReplaced:
-				ops = append(ops, resource.NewOperation(e.Step.Old(), resource.OperationTypeDeleting))
-			case deploy.OpRead, deploy.OpReadReplacement:
-				ops = append(ops, resource.NewOperation(e.Step.New(), resource.OperationTypeReading))
-			case deploy.OpUpdate:
-				ops = append(ops, resource.NewOperation(e.Step.New(), resource.OperationTypeUpdating))
-			}
-
-			continue
-		}
-
-		if e.Kind != JournalEntryOutputs {
-			switch e.Step.Op() {
-			case deploy.OpCreate, deploy.OpCreateReplacement, deploy.OpRead, deploy.OpReadReplacement, deploy.OpUpdate:
-				doneOps[e.Step.New()] = true
-			case deploy.OpDelete, deploy.OpDeleteReplaced:
-				doneOps[e.Step.Old()] = true
-			}
-		}
-
-		if e.Kind != JournalEntrySuccess {
-			continue
-		}
-
-		switch e.Step.Op() {
-		case deploy.OpSame, deploy.OpUpdate:
-			resources = append(resources, e.Step.New())
-			dones[e.Step.Old()] = true
-		case deploy.OpCreate, deploy.OpCreateReplacement:
-			resources = append(resources, e.Step.New())
-		case deploy.OpDelete, deploy.OpDeleteReplaced:
-			dones[e.Step.Old()] = true
-		case deploy.OpReplace:
-			// do nothing.
-		case deploy.OpRead, deploy.OpReadReplacement:
-			resources = append(resources, e.Step.New())
-			if e.Step.Old() != nil {
-				dones[e.Step.Old()] = true
-			}
-		}
-	}
-
-	// Append any resources from the base snapshot that were not produced by the current snapshot.
-	// See backend.SnapshotManager.snap for why this works.
-	if base != nil {
-		for _, res := range base.Resources {
-			if !dones[res] {
-				resources = append(resources, res)
-			}
-		}
-	}
-
-	// Append any pending operations.
-	var operations []resource.Operation
-	for _, op := range ops {
-		if !doneOps[op.Resource] {
-			operations = append(operations, op)
-		}
-	}
-
-	manifest := deploy.Manifest{}
-	manifest.Magic = manifest.NewMagic()
-	return deploy.NewSnapshot(manifest, resources, operations)
-}
-
-func newJournal() *Journal {
-	j := &Journal{
-		events: make(chan JournalEntry),
-		cancel: make(chan bool),
-		done:   make(chan bool),
-	}
-	go func() {
-		for {
-			select {
-			case <-j.cancel:
-				close(j.done)
-				return
-			case e := <-j.events:
-				j.Entries = append(j.Entries, e)
-			}
-		}
-	}()
-	return j
-}
-
-type updateInfo struct {
-	project workspace.Project
-	target  deploy.Target
-}
-
-func (u *updateInfo) GetRoot() string {
-	return ""
-}
-
-func (u *updateInfo) GetProject() *workspace.Project {
-	return &u.project
-}
-
-func (u *updateInfo) GetTarget() *deploy.Target {
-	return &u.target
-}
-
-type TestOp func(UpdateInfo, *Context, UpdateOptions, bool) (ResourceChanges, error)
-type ValidateFunc func(project workspace.Project, target deploy.Target, j *Journal, events []Event, err error) error
-
-func (op TestOp) Run(project workspace.Project, target deploy.Target, opts UpdateOptions,
-	dryRun bool, validate ValidateFunc) (*deploy.Snapshot, error) {
-
-	return op.RunWithContext(context.Background(), project, target, opts, dryRun, validate)
-}
-
-func (op TestOp) RunWithContext(callerCtx context.Context, project workspace.Project, target deploy.Target,
-	opts UpdateOptions, dryRun bool, validate ValidateFunc) (*deploy.Snapshot, error) {
-
-	// Create an appropriate update info and context.
-	info := &updateInfo{project: project, target: target}
-
-	cancelCtx, _ := cancel.NewContext(callerCtx)
-	events := make(chan Event)
-	journal := newJournal()
-
-	ctx := &Context{
-		Cancel:          cancelCtx,
-		Events:          events,
-		SnapshotManager: journal,
-	}
-
-	// Begin draining events.
-	var firedEvents []Event
-	go func() {
-		for e := range events {
-			firedEvents = append(firedEvents, e)
-		}
-	}()
-
-	// Run the step and its validator.
-	_, err := op(info, ctx, opts, dryRun)
-	contract.IgnoreClose(journal)
-
-	if dryRun {
-		return nil, err
-	}
-	if validate != nil {
-		err = validate(project, target, journal, firedEvents, err)
-	}
-
-	snap := journal.Snap(target.Snapshot)
-	if err == nil && snap != nil {
-		err = snap.VerifyIntegrity()
-	}
-	return snap, err
-
-}
-
-type TestStep struct {
-	Op            TestOp
-	ExpectFailure bool
-	SkipPreview   bool
-	Validate      ValidateFunc
-}
-
-type TestPlan struct {
-	Project   string
-	Stack     string
-	Runtime   string
-	Config    config.Map
-	Decrypter config.Decrypter
-	Options   UpdateOptions
-	Steps     []TestStep
-}
-
-func (p *TestPlan) getNames() (stack tokens.QName, project tokens.PackageName, runtime string) {
-	project = tokens.PackageName(p.Project)
-	if project == "" {
-		project = "test"
-	}
-	runtime = p.Runtime
-	if runtime == "" {
-		runtime = "test"
-	}
-	stack = tokens.QName(p.Stack)
-	if stack == "" {
-		stack = "test"
-	}
-	return stack, project, runtime
-}
-
-func (p *TestPlan) NewURN(typ tokens.Type, name string, parent resource.URN) resource.URN {
-	stack, project, _ := p.getNames()
-	var pt tokens.Type
-	if parent != "" {
-		pt = parent.Type()
-	}
-	return resource.NewURN(stack, project, pt, typ, tokens.QName(name))
-}
-
-func (p *TestPlan) NewProviderURN(pkg tokens.Package, name string, parent resource.URN) resource.URN {
-	return p.NewURN(providers.MakeProviderType(pkg), name, parent)
-}
-
-func (p *TestPlan) GetProject() workspace.Project {
-	_, projectName, runtime := p.getNames()
-
-	return workspace.Project{
-		Name:        projectName,
-		RuntimeInfo: workspace.NewProjectRuntimeInfo(runtime, nil),
-	}
-}
-
-func (p *TestPlan) GetTarget(snapshot *deploy.Snapshot) deploy.Target {
-	stack, _, _ := p.getNames()
-
-	cfg := p.Config
-	if cfg == nil {
-		cfg = config.Map{}
-	}
-
-	return deploy.Target{
-		Name:      stack,
-		Config:    cfg,
-		Decrypter: p.Decrypter,
-		Snapshot:  snapshot,
-	}
-}
-
-func (p *TestPlan) Run(t *testing.T, snapshot *deploy.Snapshot) *deploy.Snapshot {
-	project, target := p.GetProject(), p.GetTarget(snapshot)
-
-	for _, step := range p.Steps {
-		if !step.SkipPreview {
-			_, err := step.Op.Run(project, target, p.Options, true, step.Validate)
-			if step.ExpectFailure {
-				assert.Error(t, err)
-				continue
-			}
-
-			assert.NoError(t, err)
-		}
-
-		var err error
-		target.Snapshot, err = step.Op.Run(project, target, p.Options, false, step.Validate)
-		if step.ExpectFailure {
-			assert.Error(t, err)
-			continue
-		}
-
-		assert.NoError(t, err)
-	}
-
-	return target.Snapshot
-}
-
-func MakeBasicLifecycleSteps(t *testing.T, resCount int) []TestStep {
-	return []TestStep{
+func MakeBasicLifecycleSteps(t *testing.T, resCount int) []enginetest.TestStep {
+	return []enginetest.TestStep{
 		// Initial update
 		{
-			Op: Update,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+			Op: engine.Update,
+			Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+				err error) error {
 				// Should see only creates.
 				for _, entry := range j.Entries {
 					assert.Equal(t, deploy.OpCreate, entry.Step.Op())
@@ -385,8 +62,9 @@ func MakeBasicLifecycleSteps(t *testing.T, resCount int) []TestStep {
 		},
 		// No-op refresh
 		{
-			Op: Refresh,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+			Op: engine.Refresh,
+			Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+				err error) error {
 				// Should see only refresh-sames.
 				for _, entry := range j.Entries {
 					assert.Equal(t, deploy.OpRefresh, entry.Step.Op())
@@ -398,8 +76,9 @@ func MakeBasicLifecycleSteps(t *testing.T, resCount int) []TestStep {
 		},
 		// No-op update
 		{
-			Op: Update,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+			Op: engine.Update,
+			Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+				err error) error {
 				// Should see only sames.
 				for _, entry := range j.Entries {
 					assert.Equal(t, deploy.OpSame, entry.Step.Op())
@@ -410,8 +89,9 @@ func MakeBasicLifecycleSteps(t *testing.T, resCount int) []TestStep {
 		},
 		// No-op refresh
 		{
-			Op: Refresh,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+			Op: engine.Refresh,
+			Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+				err error) error {
 				// Should see only referesh-sames.
 				for _, entry := range j.Entries {
 					assert.Equal(t, deploy.OpRefresh, entry.Step.Op())
@@ -423,8 +103,9 @@ func MakeBasicLifecycleSteps(t *testing.T, resCount int) []TestStep {
 		},
 		// Destroy
 		{
-			Op: Destroy,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+			Op: engine.Destroy,
+			Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+				err error) error {
 				// Should see only deletes.
 				for _, entry := range j.Entries {
 					assert.Equal(t, deploy.OpDelete, entry.Step.Op())
@@ -435,8 +116,9 @@ func MakeBasicLifecycleSteps(t *testing.T, resCount int) []TestStep {
 		},
 		// No-op refresh
 		{
-			Op: Refresh,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+			Op: engine.Refresh,
+			Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+				err error) error {
 				assert.Len(t, j.Entries, 0)
 				assert.Len(t, j.Snap(target.Snapshot).Resources, 0)
 				return err
@@ -451,8 +133,8 @@ func TestEmptyProgramLifecycle(t *testing.T) {
 	})
 	host := deploytest.NewPluginHost(nil, nil, program)
 
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
 		Steps:   MakeBasicLifecycleSteps(t, 0),
 	}
 	p.Run(t, nil)
@@ -473,8 +155,8 @@ func TestSingleResourceDefaultProviderLifecycle(t *testing.T) {
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
 		Steps:   MakeBasicLifecycleSteps(t, 2),
 	}
 	p.Run(t, nil)
@@ -507,8 +189,8 @@ func TestSingleResourceExplicitProviderLifecycle(t *testing.T) {
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
 		Steps:   MakeBasicLifecycleSteps(t, 2),
 	}
 	p.Run(t, nil)
@@ -529,8 +211,8 @@ func TestSingleResourceDefaultProviderUpgrade(t *testing.T) {
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
 	}
 
 	provURN := p.NewProviderURN("pkgA", "default", "")
@@ -538,18 +220,15 @@ func TestSingleResourceDefaultProviderUpgrade(t *testing.T) {
 
 	// Create an old snapshot with an existing copy of the single resource and no providers.
 	old := &deploy.Snapshot{
-		Resources: []*resource.State{{
-			Type:    resURN.Type(),
-			URN:     resURN,
-			Custom:  true,
-			ID:      "0",
-			Inputs:  resource.PropertyMap{},
-			Outputs: resource.PropertyMap{},
-		}},
+		Resources: []*resource.State{
+			resource.NewState(resURN.Type(), resURN, true, false, "0", resource.PropertyMap{}, resource.PropertyMap{},
+				"", false, false, nil, nil, ""),
+		},
 	}
 
 	isRefresh := false
-	validate := func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+	validate := func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+		err error) error {
 		// Should see only sames: the default provider should be injected into the old state before the update
 		// runs.
 		for _, entry := range j.Entries {
@@ -569,19 +248,20 @@ func TestSingleResourceDefaultProviderUpgrade(t *testing.T) {
 	}
 
 	// Run a single update step using the base snapshot.
-	p.Steps = []TestStep{{Op: Update, Validate: validate}}
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, Validate: validate}}
 	p.Run(t, old)
 
 	// Run a single refresh step using the base snapshot.
 	isRefresh = true
-	p.Steps = []TestStep{{Op: Refresh, Validate: validate}}
+	p.Steps = []enginetest.TestStep{{Op: engine.Refresh, Validate: validate}}
 	p.Run(t, old)
 
 	// Run a single destroy step using the base snapshot.
 	isRefresh = false
-	p.Steps = []TestStep{{
-		Op: Destroy,
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+	p.Steps = []enginetest.TestStep{{
+		Op: engine.Destroy,
+		Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+			err error) error {
 			// Should see two deletes:  the default provider should be injected into the old state before the update
 			// runs.
 			deleted := make(map[resource.URN]bool)
@@ -630,8 +310,8 @@ func TestSingleResourceDefaultProviderReplace(t *testing.T) {
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
 		Config: config.Map{
 			config.MustMakeKey("pkgA", "foo"): config.NewValue("bar"),
 		},
@@ -646,16 +326,17 @@ func TestSingleResourceDefaultProviderReplace(t *testing.T) {
 
 	// Change the config and run an update. We expect everything to require replacement.
 	p.Config[config.MustMakeKey("pkgA", "foo")] = config.NewValue("baz")
-	p.Steps = []TestStep{{
-		Op: Update,
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+	p.Steps = []enginetest.TestStep{{
+		Op: engine.Update,
+		Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+			err error) error {
 			provURN := p.NewProviderURN("pkgA", "default", "")
 			resURN := p.NewURN("pkgA:m:typA", "resA", "")
 
 			// Look for replace steps on the provider and the resource.
 			replacedProvider, replacedResource := false, false
 			for _, entry := range j.Entries {
-				if entry.Kind != JournalEntrySuccess || entry.Step.Op() != deploy.OpDeleteReplaced {
+				if entry.Kind != enginetest.JournalEntrySuccess || entry.Step.Op() != deploy.OpDeleteReplaced {
 					continue
 				}
 
@@ -720,8 +401,8 @@ func TestSingleResourceExplicitProviderReplace(t *testing.T) {
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
 	}
 
 	// Build a basic lifecycle.
@@ -733,16 +414,17 @@ func TestSingleResourceExplicitProviderReplace(t *testing.T) {
 
 	// Change the config and run an update. We expect everything to require replacement.
 	providerInputs[resource.PropertyKey("foo")] = resource.NewStringProperty("baz")
-	p.Steps = []TestStep{{
-		Op: Update,
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+	p.Steps = []enginetest.TestStep{{
+		Op: engine.Update,
+		Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+			err error) error {
 			provURN := p.NewProviderURN("pkgA", "provA", "")
 			resURN := p.NewURN("pkgA:m:typA", "resA", "")
 
 			// Look for replace steps on the provider and the resource.
 			replacedProvider, replacedResource := false, false
 			for _, entry := range j.Entries {
-				if entry.Kind != JournalEntrySuccess || entry.Step.Op() != deploy.OpDeleteReplaced {
+				if entry.Kind != enginetest.JournalEntrySuccess || entry.Step.Op() != deploy.OpDeleteReplaced {
 					continue
 				}
 
@@ -807,8 +489,8 @@ func TestSingleResourceExplicitProviderDeleteBeforeReplace(t *testing.T) {
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
 	}
 
 	// Build a basic lifecycle.
@@ -820,9 +502,10 @@ func TestSingleResourceExplicitProviderDeleteBeforeReplace(t *testing.T) {
 
 	// Change the config and run an update. We expect everything to require replacement.
 	providerInputs[resource.PropertyKey("foo")] = resource.NewStringProperty("baz")
-	p.Steps = []TestStep{{
-		Op: Update,
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+	p.Steps = []enginetest.TestStep{{
+		Op: engine.Update,
+		Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+			err error) error {
 			provURN := p.NewProviderURN("pkgA", "provA", "")
 			resURN := p.NewURN("pkgA:m:typA", "resA", "")
 
@@ -830,7 +513,7 @@ func TestSingleResourceExplicitProviderDeleteBeforeReplace(t *testing.T) {
 			createdProvider, createdResource := false, false
 			deletedProvider, deletedResource := false, false
 			for _, entry := range j.Entries {
-				if entry.Kind != JournalEntrySuccess {
+				if entry.Kind != enginetest.JournalEntrySuccess {
 					continue
 				}
 
@@ -886,8 +569,8 @@ func TestDestroyWithPendingDelete(t *testing.T) {
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
 	}
 
 	resURN := p.NewURN("pkgA:m:typA", "resA", "")
@@ -896,35 +579,22 @@ func TestDestroyWithPendingDelete(t *testing.T) {
 	// that is not.
 	old := &deploy.Snapshot{
 		Resources: []*resource.State{
-			{
-				Type:    resURN.Type(),
-				URN:     resURN,
-				Custom:  true,
-				ID:      "1",
-				Inputs:  resource.PropertyMap{},
-				Outputs: resource.PropertyMap{},
-			},
-			{
-				Type:    resURN.Type(),
-				URN:     resURN,
-				Custom:  true,
-				ID:      "0",
-				Inputs:  resource.PropertyMap{},
-				Outputs: resource.PropertyMap{},
-				Delete:  true,
-			},
+			resource.NewState(resURN.Type(), resURN, true, false, "1", resource.PropertyMap{}, resource.PropertyMap{},
+				"", false, false, nil, nil, ""),
+			resource.NewState(resURN.Type(), resURN, true, true, "0", resource.PropertyMap{}, resource.PropertyMap{},
+				"", false, false, nil, nil, ""),
 		},
 	}
 
-	p.Steps = []TestStep{{
-		Op: Update,
-		Validate: func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
+	p.Steps = []enginetest.TestStep{{
+		Op: engine.Update,
+		Validate: func(_ workspace.Project, _ deploy.Target, j *enginetest.Journal, _ []engine.Event, err error) error {
 			// Verify that we see a DeleteReplacement for the resource with ID 0 and a Delete for the resouce with
 			// ID 1.
 			deletedID0, deletedID1 := false, false
 			for _, entry := range j.Entries {
 				// Ignore non-terminal steps and steps that affect the injected default provider.
-				if entry.Kind != JournalEntrySuccess || entry.Step.URN() != resURN ||
+				if entry.Kind != enginetest.JournalEntrySuccess || entry.Step.URN() != resURN ||
 					(entry.Step.Op() != deploy.OpDelete && entry.Step.Op() != deploy.OpDeleteReplaced) {
 					continue
 				}
@@ -958,8 +628,8 @@ func TestUpdateWithPendingDelete(t *testing.T) {
 
 	host := deploytest.NewPluginHost(nil, nil, nil, loaders...)
 
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
 	}
 
 	resURN := p.NewURN("pkgA:m:typA", "resA", "")
@@ -968,35 +638,22 @@ func TestUpdateWithPendingDelete(t *testing.T) {
 	// that is not.
 	old := &deploy.Snapshot{
 		Resources: []*resource.State{
-			{
-				Type:    resURN.Type(),
-				URN:     resURN,
-				Custom:  true,
-				ID:      "1",
-				Inputs:  resource.PropertyMap{},
-				Outputs: resource.PropertyMap{},
-			},
-			{
-				Type:    resURN.Type(),
-				URN:     resURN,
-				Custom:  true,
-				ID:      "0",
-				Inputs:  resource.PropertyMap{},
-				Outputs: resource.PropertyMap{},
-				Delete:  true,
-			},
+			resource.NewState(resURN.Type(), resURN, true, false, "1", resource.PropertyMap{}, resource.PropertyMap{},
+				"", false, false, nil, nil, ""),
+			resource.NewState(resURN.Type(), resURN, true, true, "0", resource.PropertyMap{}, resource.PropertyMap{},
+				"", false, false, nil, nil, ""),
 		},
 	}
 
-	p.Steps = []TestStep{{
-		Op: Destroy,
-		Validate: func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
+	p.Steps = []enginetest.TestStep{{
+		Op: engine.Destroy,
+		Validate: func(_ workspace.Project, _ deploy.Target, j *enginetest.Journal, _ []engine.Event, err error) error {
 			// Verify that we see a DeleteReplacement for the resource with ID 0 and a Delete for the resouce with
 			// ID 1.
 			deletedID0, deletedID1 := false, false
 			for _, entry := range j.Entries {
 				// Ignore non-terminal steps and steps that affect the injected default provider.
-				if entry.Kind != JournalEntrySuccess || entry.Step.URN() != resURN ||
+				if entry.Kind != enginetest.JournalEntrySuccess || entry.Step.URN() != resURN ||
 					(entry.Step.Op() != deploy.OpDelete && entry.Step.Op() != deploy.OpDeleteReplaced) {
 					continue
 				}
@@ -1051,11 +708,11 @@ func TestParallelRefresh(t *testing.T) {
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p := &TestPlan{
-		Options: UpdateOptions{Parallel: 4, host: host},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Parallel: 4, Host: host},
 	}
 
-	p.Steps = []TestStep{{Op: Update}}
+	p.Steps = []enginetest.TestStep{{Op: engine.Update}}
 	snap := p.Run(t, nil)
 
 	assert.Len(t, snap.Resources, 5)
@@ -1065,7 +722,7 @@ func TestParallelRefresh(t *testing.T) {
 	assert.Equal(t, string(snap.Resources[3].URN.Name()), "resC")
 	assert.Equal(t, string(snap.Resources[4].URN.Name()), "resD")
 
-	p.Steps = []TestStep{{Op: Refresh}}
+	p.Steps = []enginetest.TestStep{{Op: engine.Refresh}}
 	snap = p.Run(t, snap)
 
 	assert.Len(t, snap.Resources, 5)
@@ -1076,71 +733,90 @@ func TestParallelRefresh(t *testing.T) {
 	assert.Equal(t, string(snap.Resources[4].URN.Name()), "resD")
 }
 
-func TestExternalRefresh(t *testing.T) {
+func TestThrottledRefresh(t *testing.T) {
+	// Each read is throttled twice before it succeeds.
+	var lock sync.Mutex
+	reads := make(map[resource.URN]int)
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
-			return &deploytest.Provider{}, nil
+			return &deploytest.Provider{
+				ReadF: func(urn resource.URN, id resource.ID,
+					props resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
+
+					lock.Lock()
+					defer lock.Unlock()
+					if reads[urn]++; reads[urn] <= 2 {
+						return nil, resource.StatusOK, rpcerror.New(codes.ResourceExhausted, "slow down")
+					}
+					return props, resource.StatusOK, nil
+				},
+			}, nil
 		}),
 	}
 
-	// Our program reads a resource and exits.
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-		_, _, err := monitor.ReadResource("pkgA:m:typA", "resA", "resA-some-id", "", resource.PropertyMap{}, "")
-		if !assert.NoError(t, err) {
-			t.FailNow()
+		for _, name := range []string{"resA", "resB", "resC"} {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", name, true, "", false, nil, "",
+				resource.PropertyMap{})
+			assert.NoError(t, err)
 		}
-
 		return nil
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
-		Steps:   []TestStep{{Op: Update}},
-	}
 
-	// The read should place "resA" in the snapshot with the "External" bit set.
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{
+			Host:            host,
+			RefreshParallel: 3,
+			RefreshRateLimits: map[tokens.Package]deploy.RateLimit{
+				"pkgA": {RequestsPerSecond: 1000, MaxRetries: 2, Backoff: time.Millisecond},
+			},
+		},
+		Steps: []enginetest.TestStep{{Op: engine.Update}},
+	}
 	snap := p.Run(t, nil)
-	assert.Len(t, snap.Resources, 2)
-	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
-	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
-	assert.True(t, snap.Resources[1].External)
 
-	p = &TestPlan{
-		Options: UpdateOptions{host: host},
-		Steps:   []TestStep{{Op: Refresh}},
+	// With two retries, every read eventually succeeds.
+	p.Steps = []enginetest.TestStep{{Op: engine.Refresh, SkipPreview: true}}
+	snap = p.Run(t, snap)
+	assert.Len(t, snap.Resources, 4)
+	for _, name := range []string{"resA", "resB", "resC"} {
+		assert.Equal(t, 3, reads[p.NewURN("pkgA:m:typA", name, "")])
 	}
 
-	snap = p.Run(t, snap)
-	// A refresh should leave "resA" as it is in the snapshot. The External bit should still be set.
-	assert.Len(t, snap.Resources, 2)
-	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
-	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
-	assert.True(t, snap.Resources[1].External)
+	// With only one, the refresh fails.
+	reads = make(map[resource.URN]int)
+	p.Options.RefreshRateLimits["pkgA"] = deploy.RateLimit{MaxRetries: 1, Backoff: time.Millisecond}
+	p.Steps = []enginetest.TestStep{{Op: engine.Refresh, SkipPreview: true, ExpectFailure: true}}
+	p.Run(t, snap)
 }
 
-func TestRefreshInitFailure(t *testing.T) {
-	//
-	// Refresh will persist any initialization errors that are returned by `Read`. This provider
-	// will error out or not based on the value of `refreshShouldFail`.
-	//
-	refreshShouldFail := false
-
-	//
-	// Set up test environment to use `readFailProvider` as the underlying resource provider.
-	//
+func TestChangedOnlyRefresh(t *testing.T) {
+	var lock sync.Mutex
+	reads := make(map[string]int)
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				ReadF: func(
-					urn resource.URN, id resource.ID, props resource.PropertyMap,
-				) (resource.PropertyMap, resource.Status, error) {
-					if refreshShouldFail {
-						err := &plugin.InitError{
-							Reasons: []string{"Refresh reports continued to fail to initialize"},
-						}
-						return resource.PropertyMap{}, resource.StatusPartialFailure, err
+				ReadF: func(urn resource.URN, id resource.ID,
+					props resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
+
+					lock.Lock()
+					defer lock.Unlock()
+					reads[string(urn.Name())]++
+					return props, resource.StatusOK, nil
+				},
+				// resB is unmodified, but resC has drifted.
+				ReadIfModifiedF: func(urn resource.URN, id resource.ID, props resource.PropertyMap,
+					since time.Time) (resource.PropertyMap, bool, resource.Status, error) {
+
+					lock.Lock()
+					defer lock.Unlock()
+					reads[string(urn.Name())+"?"]++
+					if urn.Name() == "resB" {
+						return nil, true, resource.StatusOK, nil
 					}
-					return resource.PropertyMap{}, resource.StatusOK, nil
+					return resource.PropertyMap{"drifted": resource.NewBoolProperty(true)}, false,
+						resource.StatusOK, nil
 				},
 			}, nil
 		}),
@@ -1150,107 +826,432 @@ func TestRefreshInitFailure(t *testing.T) {
 		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
 			resource.PropertyMap{})
 		assert.NoError(t, err)
+		for _, name := range []string{"resB", "resC"} {
+			_, _, _, err = monitor.RegisterResource("pkgA:m:typB", name, true, "", false, nil, "",
+				resource.PropertyMap{})
+			assert.NoError(t, err)
+		}
 		return nil
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
-	}
-
-	provURN := p.NewProviderURN("pkgA", "default", "")
-	resURN := p.NewURN("pkgA:m:typA", "resA", "")
-
-	//
-	// Create an old snapshot with a single initialization failure.
-	//
-	old := &deploy.Snapshot{
-		Resources: []*resource.State{{
-			Type:       resURN.Type(),
-			URN:        resURN,
-			Custom:     true,
-			ID:         "0",
-			Inputs:     resource.PropertyMap{},
-			Outputs:    resource.PropertyMap{},
-			InitErrors: []string{"Resource failed to initialize"},
-		}},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host, RefreshChangedOnly: true, RefreshDriftProneTypes: []string{"*:typA"}},
+		Steps:   []enginetest.TestStep{{Op: engine.Update}},
 	}
+	snap := p.Run(t, nil)
 
-	//
-	// Refresh DOES NOT fail, causing the initialization error to disappear.
-	//
-	p.Steps = []TestStep{{Op: Refresh}}
-	snap := p.Run(t, old)
-
-	for _, resource := range snap.Resources {
-		switch urn := resource.URN; urn {
-		case provURN:
-			// break
-		case resURN:
-			assert.Empty(t, resource.InitErrors)
-		default:
-			t.Fatalf("unexpected resource %v", urn)
-		}
-	}
+	// Without a time, only drift-prone resources are read.
+	p.Steps = []enginetest.TestStep{{Op: engine.Refresh, SkipPreview: true}}
+	snap = p.Run(t, snap)
+	assert.Equal(t, map[string]int{"resA": 1}, reads)
 
-	//
-	// Refresh DOES fail, causing the new initialization error to appear.
-	//
-	refreshShouldFail = true
-	p.Steps = []TestStep{{Op: Refresh}}
-	snap = p.Run(t, old)
-	for _, resource := range snap.Resources {
-		switch urn := resource.URN; urn {
-		case provURN:
-			// break
-		case resURN:
-			assert.Equal(t, []string{"Refresh reports continued to fail to initialize"}, resource.InitErrors)
-		default:
-			t.Fatalf("unexpected resource %v", urn)
-		}
+	// With one, the providers of other resources are asked whether they have been modified since.
+	reads = make(map[string]int)
+	p.Options.RefreshSince = time.Now().Add(-time.Hour)
+	snap = p.Run(t, snap)
+	assert.Equal(t, map[string]int{"resA": 1, "resB?": 1, "resC?": 1}, reads)
+	assert.Len(t, snap.Resources, 4)
+	for _, res := range snap.Resources[1:] {
+		assert.Equal(t, res.URN.Name() == "resC", res.Outputs()["drifted"].IsBool(), string(res.URN.Name()))
 	}
 }
 
-// Test that ensures that we log diagnostics for resources that receive an error from Check. (Note that this
-// is distinct from receiving non-error failures from Check.)
-func TestCheckFailureRecord(t *testing.T) {
+func TestIntegratedRefreshSummary(t *testing.T) {
+	drifted := false
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				CheckF: func(urn resource.URN,
-					olds, news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
-					return nil, nil, errors.New("oh no, check had an error")
+				ReadF: func(urn resource.URN, id resource.ID,
+					props resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
+
+					if drifted && urn.Name() == "resA" {
+						return resource.PropertyMap{"drifted": resource.NewBoolProperty(true)}, resource.StatusOK, nil
+					}
+					return props, resource.StatusOK, nil
 				},
 			}, nil
 		}),
 	}
 
+	names := []string{"resA", "resB"}
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", nil)
-		assert.Error(t, err)
-		return err
+		for _, name := range names {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", name, true, "", false, nil, "",
+				resource.PropertyMap{})
+			assert.NoError(t, err)
+		}
+		return nil
 	})
-
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
-		Steps: []TestStep{{
-			Op:            Update,
-			ExpectFailure: true,
-			SkipPreview:   true,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
-				sawFailure := false
-				for _, evt := range evts {
-					if evt.Type == DiagEvent {
-						e := evt.Payload.(DiagEventPayload)
-						msg := colors.Never.Colorize(e.Message)
-						sawFailure = msg == "oh no, check had an error\n" && e.Severity == diag.Error
-					}
-				}
 
-				assert.True(t, sawFailure)
-				return err
-			},
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps:   []enginetest.TestStep{{Op: engine.Update}},
+	}
+	snap := p.Run(t, nil)
+
+	// Now let resA drift and have the program add resC. The refresh that precedes the update should report the drift
+	// apart from the changes that the program asks for.
+	drifted, names = true, append(names, "resC")
+	p.Options.Refresh = true
+	p.Steps = []enginetest.TestStep{{
+		Op:          engine.Update,
+		SkipPreview: true,
+		Validate: func(_ workspace.Project, _ deploy.Target, _ *enginetest.Journal, evts []engine.Event,
+			err error) error {
+			assert.NoError(t, err)
+
+			var summary *engine.SummaryEventPayload
+			for _, evt := range evts {
+				if evt.Type == engine.SummaryEvent {
+					payload := evt.Payload.(engine.SummaryEventPayload)
+					summary = &payload
+				}
+			}
+			if assert.NotNil(t, summary) {
+				assert.Equal(t, engine.ResourceChanges{deploy.OpUpdate: 1}, summary.RefreshChanges)
+				assert.Equal(t, 1, summary.ResourceChanges[deploy.OpCreate])
+				assert.Equal(t, 0, summary.ResourceChanges[deploy.OpUpdate])
+			}
+			return err
+		},
+	}}
+	p.Run(t, snap)
+}
+
+func TestUnreferencedResources(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	names := []string{"resA", "resB"}
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		for _, name := range names {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", name, true, "", name == "resA", nil, "",
+				resource.PropertyMap{})
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps:   []enginetest.TestStep{{Op: engine.Update}},
+	}
+	snap := p.Run(t, nil)
+
+	unreferenced := func(snap *deploy.Snapshot) []string {
+		var names []string
+		for _, res := range snap.Resources {
+			if res.Unreferenced {
+				names = append(names, string(res.URN.Name()))
+			}
+		}
+		return names
+	}
+	assert.Empty(t, unreferenced(snap))
+
+	// Drop both resources from the program. The protected resA cannot be deleted, and so outlives the update. Its
+	// default provider, which the failure leaves in place for resA to use, is not marked. The base snapshot is left
+	// untouched.
+	names = nil
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, SkipPreview: true, ExpectFailure: true}}
+	base := snap
+	snap = p.Run(t, base)
+	assert.Equal(t, []string{"resA"}, unreferenced(snap))
+	assert.Empty(t, unreferenced(base))
+
+	// Declaring it once more clears the mark.
+	names = []string{"resA"}
+	p.Steps = []enginetest.TestStep{{Op: engine.Update}}
+	snap = p.Run(t, snap)
+	assert.Empty(t, unreferenced(snap))
+}
+
+func TestUpdateVersion(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	inputs := resource.PropertyMap{"foo": resource.NewStringProperty("bar")}
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", inputs)
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, nil, "",
+			resource.PropertyMap{})
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	versions := func(snap *deploy.Snapshot) map[string]int {
+		result := make(map[string]int)
+		for _, res := range snap.Resources {
+			result[string(res.URN.Name())] = res.UpdateVersion
+		}
+		return result
+	}
+
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host, UpdateVersion: 1},
+		Steps:   []enginetest.TestStep{{Op: engine.Update}},
+	}
+	snap := p.Run(t, nil)
+	assert.Equal(t, map[string]int{"default": 1, "resA": 1, "resB": 1}, versions(snap))
+
+	// Only the resources that a later update changes record its version.
+	inputs = resource.PropertyMap{"foo": resource.NewStringProperty("baz")}
+	p.Options.UpdateVersion = 2
+	snap = p.Run(t, snap)
+	assert.Equal(t, map[string]int{"default": 1, "resA": 2, "resB": 1}, versions(snap))
+	for _, res := range snap.Resources {
+		if res.URN.Name() == "resA" {
+			assert.True(t, res.Modified.After(res.Created))
+		} else {
+			assert.Equal(t, res.Created, res.Modified)
+		}
+	}
+}
+
+func TestDeleteTargets(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	// resB depends upon resA and resC is its child, while resD is unrelated.
+	var urnA resource.URN
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		var err error
+		urnA, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{})
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, []resource.URN{urnA}, "",
+			resource.PropertyMap{})
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resC", true, urnA, false, nil, "",
+			resource.PropertyMap{})
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resD", true, "", false, nil, "",
+			resource.PropertyMap{})
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps:   []enginetest.TestStep{{Op: engine.Update}},
+	}
+	snap := p.Run(t, nil)
+	assert.Len(t, snap.Resources, 5)
+	created := make(map[resource.URN]time.Time)
+	for _, res := range snap.Resources {
+		assert.False(t, res.Created.IsZero())
+		assert.Equal(t, res.Created, res.Modified)
+		created[res.URN] = res.Created
+	}
+
+	// A later update that changes nothing keeps each resource's creation and modification times.
+	snap = p.Run(t, snap)
+	for _, res := range snap.Resources {
+		assert.Equal(t, created[res.URN], res.Created)
+		assert.Equal(t, created[res.URN], res.Modified)
+	}
+
+	// Destroying resA also destroys the resources that depend upon or descend from it.
+	p.Options.DeleteTargets = []resource.URN{urnA}
+	p.Steps = []enginetest.TestStep{{Op: engine.Destroy}}
+	snap = p.Run(t, snap)
+	var names []string
+	for _, res := range snap.Resources {
+		names = append(names, string(res.URN.Name()))
+	}
+	assert.Equal(t, []string{"default", "resD"}, names)
+
+	// Targeting a resource that does not exist is an error.
+	p.Steps = []enginetest.TestStep{{Op: engine.Destroy, ExpectFailure: true}}
+	p.Run(t, snap)
+}
+
+func TestExternalRefresh(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	// Our program reads a resource and exits.
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, err := monitor.ReadResource("pkgA:m:typA", "resA", "resA-some-id", "", resource.PropertyMap{}, "")
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps:   []enginetest.TestStep{{Op: engine.Update}},
+	}
+
+	// The read should place "resA" in the snapshot with the "External" bit set.
+	snap := p.Run(t, nil)
+	assert.Len(t, snap.Resources, 2)
+	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
+	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
+	assert.True(t, snap.Resources[1].External)
+
+	p = &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps:   []enginetest.TestStep{{Op: engine.Refresh}},
+	}
+
+	snap = p.Run(t, snap)
+	// A refresh should leave "resA" as it is in the snapshot. The External bit should still be set.
+	assert.Len(t, snap.Resources, 2)
+	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
+	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
+	assert.True(t, snap.Resources[1].External)
+}
+
+func TestRefreshInitFailure(t *testing.T) {
+	//
+	// Refresh will persist any initialization errors that are returned by `Read`. This provider
+	// will error out or not based on the value of `refreshShouldFail`.
+	//
+	refreshShouldFail := false
+
+	//
+	// Set up test environment to use `readFailProvider` as the underlying resource provider.
+	//
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				ReadF: func(
+					urn resource.URN, id resource.ID, props resource.PropertyMap,
+				) (resource.PropertyMap, resource.Status, error) {
+					if refreshShouldFail {
+						err := &plugin.InitError{
+							Reasons: []string{"Refresh reports continued to fail to initialize"},
+						}
+						return resource.PropertyMap{}, resource.StatusPartialFailure, err
+					}
+					return resource.PropertyMap{}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{})
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+	}
+
+	provURN := p.NewProviderURN("pkgA", "default", "")
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+
+	//
+	// Create an old snapshot with a single initialization failure.
+	//
+	old := &deploy.Snapshot{
+		Resources: []*resource.State{
+			resource.NewState(resURN.Type(), resURN, true, false, "0", resource.PropertyMap{}, resource.PropertyMap{},
+				"", false, false, nil, []string{"Resource failed to initialize"}, ""),
+		},
+	}
+
+	//
+	// Refresh DOES NOT fail, causing the initialization error to disappear.
+	//
+	p.Steps = []enginetest.TestStep{{Op: engine.Refresh}}
+	snap := p.Run(t, old)
+
+	for _, resource := range snap.Resources {
+		switch urn := resource.URN; urn {
+		case provURN:
+			// break
+		case resURN:
+			assert.Empty(t, resource.InitErrors)
+		default:
+			t.Fatalf("unexpected resource %v", urn)
+		}
+	}
+
+	//
+	// Refresh DOES fail, causing the new initialization error to appear.
+	//
+	refreshShouldFail = true
+	p.Steps = []enginetest.TestStep{{Op: engine.Refresh}}
+	snap = p.Run(t, old)
+	for _, resource := range snap.Resources {
+		switch urn := resource.URN; urn {
+		case provURN:
+			// break
+		case resURN:
+			assert.Equal(t, []string{"Refresh reports continued to fail to initialize"}, resource.InitErrors)
+		default:
+			t.Fatalf("unexpected resource %v", urn)
+		}
+	}
+}
+
+// Test that ensures that we log diagnostics for resources that receive an error from Check. (Note that this
+// is distinct from receiving non-error failures from Check.)
+func TestCheckFailureRecord(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CheckF: func(urn resource.URN, olds, news resource.PropertyMap,
+					_ []byte) (resource.PropertyMap, []plugin.CheckFailure, error) {
+					return nil, nil, errors.New("oh no, check had an error")
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", nil)
+		assert.Error(t, err)
+		return err
+	})
+
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps: []enginetest.TestStep{{
+			Op:            engine.Update,
+			ExpectFailure: true,
+			SkipPreview:   true,
+			Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, evts []engine.Event,
+				err error) error {
+				sawFailure := false
+				for _, evt := range evts {
+					if evt.Type == engine.DiagEvent {
+						e := evt.Payload.(engine.DiagEventPayload)
+						msg := colors.Never.Colorize(e.Message)
+						sawFailure = msg == "oh no, check had an error\n" && e.Severity == diag.Error
+					}
+				}
+
+				assert.True(t, sawFailure)
+				return err
+			},
 		}},
 	}
 
@@ -1262,8 +1263,8 @@ func TestCheckFailureInvalidPropertyRecord(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				CheckF: func(urn resource.URN,
-					olds, news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+				CheckF: func(urn resource.URN, olds, news resource.PropertyMap,
+					_ []byte) (resource.PropertyMap, []plugin.CheckFailure, error) {
 					return nil, []plugin.CheckFailure{{
 						Property: "someprop",
 						Reason:   "field is not valid",
@@ -1280,17 +1281,18 @@ func TestCheckFailureInvalidPropertyRecord(t *testing.T) {
 	})
 
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
-		Steps: []TestStep{{
-			Op:            Update,
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps: []enginetest.TestStep{{
+			Op:            engine.Update,
 			ExpectFailure: true,
 			SkipPreview:   true,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+			Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, evts []engine.Event,
+				err error) error {
 				sawFailure := false
 				for _, evt := range evts {
-					if evt.Type == DiagEvent {
-						e := evt.Payload.(DiagEventPayload)
+					if evt.Type == engine.DiagEvent {
+						e := evt.Payload.(engine.DiagEventPayload)
 						msg := colors.Never.Colorize(e.Message)
 						sawFailure = strings.Contains(msg, "field is not valid") && e.Severity == diag.Error
 						if sawFailure {
@@ -1335,12 +1337,12 @@ func TestRefreshWithDelete(t *testing.T) {
 			})
 
 			host := deploytest.NewPluginHost(nil, nil, program, loaders...)
-			p := &TestPlan{Options: UpdateOptions{host: host, Parallel: parallelFactor}}
+			p := &enginetest.TestPlan{Options: engine.UpdateOptions{Host: host, Parallel: parallelFactor}}
 
-			p.Steps = []TestStep{{Op: Update}}
+			p.Steps = []enginetest.TestStep{{Op: engine.Update}}
 			snap := p.Run(t, nil)
 
-			p.Steps = []TestStep{{Op: Refresh}}
+			p.Steps = []enginetest.TestStep{{Op: engine.Refresh}}
 			snap = p.Run(t, snap)
 
 			// Refresh succeeds and records that the resource in the snapshot doesn't exist anymore
@@ -1353,7 +1355,7 @@ func TestRefreshWithDelete(t *testing.T) {
 
 // Tests that dependencies are correctly rewritten when refresh removes deleted resources.
 func TestRefreshDeleteDependencies(t *testing.T) {
-	p := &TestPlan{}
+	p := &enginetest.TestPlan{}
 
 	const resType = "pkgA:m:typA"
 
@@ -1362,16 +1364,8 @@ func TestRefreshDeleteDependencies(t *testing.T) {
 	urnC := p.NewURN(resType, "resC", "")
 
 	newResource := func(urn resource.URN, id resource.ID, delete bool, dependencies ...resource.URN) *resource.State {
-		return &resource.State{
-			Type:         urn.Type(),
-			URN:          urn,
-			Custom:       true,
-			Delete:       delete,
-			ID:           id,
-			Inputs:       resource.PropertyMap{},
-			Outputs:      resource.PropertyMap{},
-			Dependencies: dependencies,
-		}
+		return resource.NewState(urn.Type(), urn, true, delete, id, resource.PropertyMap{}, resource.PropertyMap{}, "",
+			false, false, dependencies, nil, "")
 	}
 
 	oldResources := []*resource.State{
@@ -1405,9 +1399,9 @@ func TestRefreshDeleteDependencies(t *testing.T) {
 		}),
 	}
 
-	p.Options.host = deploytest.NewPluginHost(nil, nil, nil, loaders...)
+	p.Options.Host = deploytest.NewPluginHost(nil, nil, nil, loaders...)
 
-	p.Steps = []TestStep{{Op: Refresh}}
+	p.Steps = []enginetest.TestStep{{Op: engine.Refresh}}
 	snap := p.Run(t, old)
 
 	provURN := p.NewProviderURN("pkgA", "default", "")
@@ -1445,7 +1439,7 @@ func TestRefreshDeleteDependencies(t *testing.T) {
 
 // Tests basic refresh functionality.
 func TestRefreshBasics(t *testing.T) {
-	p := &TestPlan{}
+	p := &enginetest.TestPlan{}
 
 	const resType = "pkgA:m:typA"
 
@@ -1454,16 +1448,8 @@ func TestRefreshBasics(t *testing.T) {
 	urnC := p.NewURN(resType, "resC", "")
 
 	newResource := func(urn resource.URN, id resource.ID, delete bool, dependencies ...resource.URN) *resource.State {
-		return &resource.State{
-			Type:         urn.Type(),
-			URN:          urn,
-			Custom:       true,
-			Delete:       delete,
-			ID:           id,
-			Inputs:       resource.PropertyMap{},
-			Outputs:      resource.PropertyMap{},
-			Dependencies: dependencies,
-		}
+		return resource.NewState(urn.Type(), urn, true, delete, id, resource.PropertyMap{}, resource.PropertyMap{}, "",
+			false, false, dependencies, nil, "")
 	}
 
 	oldResources := []*resource.State{
@@ -1507,11 +1493,12 @@ func TestRefreshBasics(t *testing.T) {
 		}),
 	}
 
-	p.Options.host = deploytest.NewPluginHost(nil, nil, nil, loaders...)
+	p.Options.Host = deploytest.NewPluginHost(nil, nil, nil, loaders...)
 
-	p.Steps = []TestStep{{
-		Op: Refresh,
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+	p.Steps = []enginetest.TestStep{{
+		Op: engine.Refresh,
+		Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+			err error) error {
 			// Should see only refreshes.
 			for _, entry := range j.Entries {
 				assert.Equal(t, deploy.OpRefresh, entry.Step.Op())
@@ -1532,14 +1519,14 @@ func TestRefreshBasics(t *testing.T) {
 				} else {
 					// If there were changes to the outputs, we want the result op to be an OpUpdate. Otherwise we want
 					// an OpSame.
-					if reflect.DeepEqual(old.Outputs, expected) {
+					if reflect.DeepEqual(old.Outputs(), expected) {
 						assert.Equal(t, deploy.OpSame, resultOp)
 					} else {
 						assert.Equal(t, deploy.OpUpdate, resultOp)
 					}
 
 					// Only the outputs should have changed (if anything changed).
-					old.Outputs = expected
+					old.SetOutputs(expected)
 					assert.Equal(t, old, new)
 				}
 			}
@@ -1569,14 +1556,14 @@ func TestRefreshBasics(t *testing.T) {
 
 		// The new resources should be equal to the old resources + the new outputs.
 		old := oldResources[int(idx)]
-		old.Outputs = expected
+		old.SetOutputs(expected)
 		assert.Equal(t, old, r)
 	}
 }
 
 // Tests that an interrupted refresh leaves behind an expected state.
 func TestCanceledRefresh(t *testing.T) {
-	p := &TestPlan{}
+	p := &enginetest.TestPlan{}
 
 	const resType = "pkgA:m:typA"
 
@@ -1585,16 +1572,8 @@ func TestCanceledRefresh(t *testing.T) {
 	urnC := p.NewURN(resType, "resC", "")
 
 	newResource := func(urn resource.URN, id resource.ID, delete bool, dependencies ...resource.URN) *resource.State {
-		return &resource.State{
-			Type:         urn.Type(),
-			URN:          urn,
-			Custom:       true,
-			Delete:       delete,
-			ID:           id,
-			Inputs:       resource.PropertyMap{},
-			Outputs:      resource.PropertyMap{},
-			Dependencies: dependencies,
-		}
+		return resource.NewState(urn.Type(), urn, true, delete, id, resource.PropertyMap{}, resource.PropertyMap{}, "",
+			false, false, dependencies, nil, "")
 	}
 
 	oldResources := []*resource.State{
@@ -1644,13 +1623,14 @@ func TestCanceledRefresh(t *testing.T) {
 	}
 
 	refreshed := make(map[resource.ID]bool)
-	op := TestOp(Refresh)
-	options := UpdateOptions{
+	op := enginetest.TestOp(engine.Refresh)
+	options := engine.UpdateOptions{
 		Parallel: 1,
-		host:     deploytest.NewPluginHost(nil, nil, nil, loaders...),
+		Host:     deploytest.NewPluginHost(nil, nil, nil, loaders...),
 	}
 	project, target := p.GetProject(), p.GetTarget(old)
-	validate := func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+	validate := func(project workspace.Project, target deploy.Target, j *enginetest.Journal, _ []engine.Event,
+		err error) error {
 		for _, entry := range j.Entries {
 			assert.Equal(t, deploy.OpRefresh, entry.Step.Op())
 			resultOp := entry.Step.(*deploy.RefreshStep).ResultOp()
@@ -1672,14 +1652,14 @@ func TestCanceledRefresh(t *testing.T) {
 			} else {
 				// If there were changes to the outputs, we want the result op to be an OpUpdate. Otherwise we want
 				// an OpSame.
-				if reflect.DeepEqual(old.Outputs, expected) {
+				if reflect.DeepEqual(old.Outputs(), expected) {
 					assert.Equal(t, deploy.OpSame, resultOp)
 				} else {
 					assert.Equal(t, deploy.OpUpdate, resultOp)
 				}
 
 				// Only the outputs should have changed (if anything changed).
-				old.Outputs = expected
+				old.SetOutputs(expected)
 				assert.Equal(t, old, new)
 			}
 		}
@@ -1713,7 +1693,7 @@ func TestCanceledRefresh(t *testing.T) {
 				assert.Fail(t, "refreshed resource was not deleted")
 			} else {
 				old := oldResources[int(idx)]
-				old.Outputs = expected
+				old.SetOutputs(expected)
 				assert.Equal(t, old, r)
 			}
 		} else {
@@ -1739,18 +1719,19 @@ func TestLanguageHostDiagnostics(t *testing.T) {
 	})
 
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
-		Steps: []TestStep{{
-			Op:            Update,
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps: []enginetest.TestStep{{
+			Op:            engine.Update,
 			ExpectFailure: true,
 			SkipPreview:   true,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+			Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, evts []engine.Event,
+				err error) error {
 				assert.Error(t, err)
 				sawExitCode := false
 				for _, evt := range evts {
-					if evt.Type == DiagEvent {
-						e := evt.Payload.(DiagEventPayload)
+					if evt.Type == engine.DiagEvent {
+						e := evt.Payload.(engine.DiagEventPayload)
 						msg := colors.Never.Colorize(e.Message)
 						sawExitCode = strings.Contains(msg, errorText) && e.Severity == diag.Error
 						if sawExitCode {
@@ -1792,17 +1773,18 @@ func TestBrokenDecrypter(t *testing.T) {
 	msg := "decryption failed"
 	configMap := make(config.Map)
 	configMap[key] = config.NewSecureValue("hunter2")
-	p := &TestPlan{
-		Options:   UpdateOptions{host: host},
+	p := &enginetest.TestPlan{
+		Options:   engine.UpdateOptions{Host: host},
 		Decrypter: brokenDecrypter{ErrorMessage: msg},
 		Config:    configMap,
-		Steps: []TestStep{{
-			Op:            Update,
+		Steps: []enginetest.TestStep{{
+			Op:            engine.Update,
 			ExpectFailure: true,
 			SkipPreview:   true,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+			Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, evts []engine.Event,
+				err error) error {
 				assert.Error(t, err)
-				decryptErr := err.(DecryptError)
+				decryptErr := err.(engine.DecryptError)
 				assert.Equal(t, key, decryptErr.Key)
 				assert.Contains(t, decryptErr.Err.Error(), msg)
 				return err
@@ -1812,3 +1794,1037 @@ func TestBrokenDecrypter(t *testing.T) {
 
 	p.Run(t, nil)
 }
+
+func TestReplaceReasons(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+
+					// Any change to the resource's inputs is a change to its immutable engine version.
+					return plugin.DiffResult{
+						Changes:        plugin.DiffSome,
+						ReplaceKeys:    []resource.PropertyKey{"engineVersion"},
+						ReplaceReasons: map[resource.PropertyKey]string{"engineVersion": "changed and is immutable"},
+					}, nil
+				},
+			}, nil
+		}),
+	}
+
+	engineVersion := "5.6"
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{"engineVersion": resource.NewStringProperty(engineVersion)})
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options:         engine.UpdateOptions{Host: host},
+		Steps:           []enginetest.TestStep{{Op: engine.Update}},
+		IsolatePreviews: true,
+	}
+	snap := p.Run(t, nil)
+
+	// Change the engine version. The replacement should carry the provider's explanation.
+	engineVersion = "5.7"
+	p.Steps = []enginetest.TestStep{{
+		Op: engine.Update,
+		Validate: func(project workspace.Project, target deploy.Target, j *enginetest.Journal, events []engine.Event,
+			err error) error {
+			resURN := p.NewURN("pkgA:m:typA", "resA", "")
+
+			sawReplace := false
+			for _, e := range events {
+				if e.Type != engine.ResourcePreEvent {
+					continue
+				}
+				md := e.Payload.(engine.ResourcePreEventPayload).Metadata
+				if md.URN != resURN || md.Op != deploy.OpReplace {
+					continue
+				}
+
+				sawReplace = true
+				assert.Equal(t, []resource.PropertyKey{"engineVersion"}, md.Keys)
+				assert.Equal(t, "changed and is immutable", md.Reasons["engineVersion"])
+				assert.Contains(t, engine.GetResourcePropertiesSummary(md, 0, false),
+					"[replace because `engineVersion` changed and is immutable]")
+			}
+			assert.True(t, sawReplace)
+
+			return err
+		},
+	}}
+	p.Run(t, snap)
+}
+
+func TestLockedResources(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	register, value := true, "foo"
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		if register {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+				resource.PropertyMap{"value": resource.NewStringProperty(value)})
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps:   []enginetest.TestStep{{Op: engine.Update}},
+	}
+	snap := p.Run(t, nil)
+
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+	for _, res := range snap.Resources {
+		if res.URN == resURN {
+			res.Locked = true
+		}
+	}
+
+	// validateUnchanged checks that the plan was refused without touching the locked resource.
+	validateUnchanged := func(_ workspace.Project, _ deploy.Target, j *enginetest.Journal, _ []engine.Event,
+		err error) error {
+		for _, entry := range j.Entries {
+			if entry.Step.URN() == resURN {
+				assert.Equal(t, deploy.OpSame, entry.Step.Op())
+			}
+		}
+		assert.Error(t, err)
+		return err
+	}
+
+	// Neither updating nor deleting the locked resource is allowed.
+	value = "bar"
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, ExpectFailure: true, Validate: validateUnchanged}}
+	snap = p.Run(t, snap)
+
+	value, register = "foo", false
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, ExpectFailure: true, Validate: validateUnchanged}}
+	snap = p.Run(t, snap)
+
+	// Leaving the resource as it is succeeds, and the resource remains locked.
+	register = true
+	p.Steps = []enginetest.TestStep{{Op: engine.Update}}
+	snap = p.Run(t, snap)
+	for _, res := range snap.Resources {
+		if res.URN == resURN {
+			assert.True(t, res.Locked)
+		}
+	}
+}
+
+func TestStepHook(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	register, value := true, "foo"
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		if register {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+				resource.PropertyMap{"value": resource.NewStringProperty(value)})
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps:   []enginetest.TestStep{{Op: engine.Update}},
+	}
+	snap := p.Run(t, nil)
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+
+	var seen []deploy.StepOp
+	action := deploy.StepSkip
+	p.Options.StepHook = func(step engine.StepEventMetadata) deploy.StepAction {
+		if step.URN != resURN {
+			return deploy.StepContinue
+		}
+		seen = append(seen, step.Op)
+		return action
+	}
+	findResA := func(snap *deploy.Snapshot) *resource.State {
+		for _, res := range snap.Resources {
+			if res.URN == resURN {
+				return res
+			}
+		}
+		return nil
+	}
+
+	// A skipped update leaves the resource as it was.
+	value = "bar"
+	snap = p.Run(t, snap)
+	assert.Equal(t, []deploy.StepOp{deploy.OpUpdate}, seen)
+	assert.Equal(t, "foo", findResA(snap).Inputs()["value"].StringValue())
+
+	// A skipped deletion leaves the resource in place, along with its provider.
+	seen, register = nil, false
+	snap = p.Run(t, snap)
+	assert.Equal(t, []deploy.StepOp{deploy.OpDelete}, seen)
+	assert.NotNil(t, findResA(snap))
+
+	// Aborting fails the update.
+	action = deploy.StepAbort
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, ExpectFailure: true, SkipPreview: true}}
+	snap = p.Run(t, snap)
+	assert.NotNil(t, findResA(snap))
+
+	// Continuing applies the step.
+	action = deploy.StepContinue
+	p.Steps = []enginetest.TestStep{{Op: engine.Update}}
+	snap = p.Run(t, snap)
+	assert.Nil(t, findResA(snap))
+}
+
+// testStepDecider is a deploy.StepDecider whose decisions are fixed by its fields.
+type testStepDecider struct {
+	replace bool
+	deny    bool
+	reverse bool
+}
+
+func (d *testStepDecider) DecideUpdate(old, new *resource.State, diff plugin.DiffResult) (bool, error) {
+	return d.replace, nil
+}
+
+func (d *testStepDecider) DecideDelete(old *resource.State) error {
+	if d.deny {
+		return errors.Errorf("%s may not be deleted", old.URN)
+	}
+	return nil
+}
+
+func (d *testStepDecider) OrderDeletes(deletes []deploy.Step) ([]deploy.Step, error) {
+	if !d.reverse {
+		return deletes, nil
+	}
+	reversed := make([]deploy.Step, len(deletes))
+	for i, step := range deletes {
+		reversed[len(deletes)-1-i] = step
+	}
+	return reversed, nil
+}
+
+func TestStepDecider(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	register, value := true, "foo"
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		if register {
+			urnA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+				resource.PropertyMap{"value": resource.NewStringProperty(value)})
+			assert.NoError(t, err)
+			_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false,
+				[]resource.URN{urnA}, "", resource.PropertyMap{})
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	decider := &testStepDecider{}
+	p := &enginetest.TestPlan{
+		Options:         engine.UpdateOptions{Host: host, StepDecider: decider},
+		Steps:           []enginetest.TestStep{{Op: engine.Update}},
+		IsolatePreviews: true,
+	}
+	snap := p.Run(t, nil)
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+
+	// The decider may require that an update be a replacement.
+	decider.replace, value = true, "bar"
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, Validate: func(_ workspace.Project, _ deploy.Target,
+		j *enginetest.Journal, _ []engine.Event, err error) error {
+
+		replaced := false
+		for _, entry := range j.Entries {
+			if entry.Step.URN() == resURN {
+				assert.NotEqual(t, deploy.OpUpdate, entry.Step.Op())
+				replaced = replaced || entry.Step.Op() == deploy.OpDeleteReplaced
+			}
+		}
+		assert.True(t, replaced)
+		return err
+	}}}
+	snap = p.Run(t, snap)
+
+	// The decider may deny deletions.
+	decider.deny, register = true, false
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, ExpectFailure: true}}
+	snap = p.Run(t, snap)
+	assert.Len(t, snap.Resources, 3)
+
+	// The decider may not reorder deletes such that a resource is deleted before its dependents.
+	decider.deny, decider.reverse = false, true
+	snap = p.Run(t, snap)
+	assert.Len(t, snap.Resources, 3)
+
+	decider.reverse = false
+	p.Steps = []enginetest.TestStep{{Op: engine.Update}}
+	snap = p.Run(t, snap)
+	assert.Len(t, snap.Resources, 0)
+}
+
+func TestExplicitDependencies(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	register := true
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		if register {
+			urnA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+				resource.PropertyMap{})
+			assert.NoError(t, err)
+			_, _, _, err = monitor.RegisterResourceWithDependsOn("pkgA:m:typA", "resB", true, "", false, nil,
+				[]resource.URN{urnA}, "", resource.PropertyMap{})
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps:   []enginetest.TestStep{{Op: engine.Update}},
+	}
+	snap := p.Run(t, nil)
+	urnA, urnB := p.NewURN("pkgA:m:typA", "resA", ""), p.NewURN("pkgA:m:typA", "resB", "")
+
+	// The explicit dependency must be recorded both as such and as an ordinary dependency.
+	assert.Len(t, snap.Resources, 3)
+	resB := snap.Resources[2]
+	assert.Equal(t, urnB, resB.URN)
+	assert.Equal(t, []resource.URN{urnA}, resB.Dependencies)
+	assert.Equal(t, []resource.URN{urnA}, resB.DependsOn)
+	assert.NoError(t, snap.VerifyInvariants())
+
+	// When both resources are deleted, the dependent must be deleted first.
+	register = false
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, Validate: func(_ workspace.Project, _ deploy.Target,
+		j *enginetest.Journal, _ []engine.Event, err error) error {
+
+		var deleted []resource.URN
+		for _, entry := range j.Entries {
+			if entry.Kind == enginetest.JournalEntrySuccess && entry.Step.Op() == deploy.OpDelete &&
+				!providers.IsProviderType(entry.Step.URN().Type()) {
+				deleted = append(deleted, entry.Step.URN())
+			}
+		}
+		assert.Equal(t, []resource.URN{urnB, urnA}, deleted)
+		return err
+	}}}
+	snap = p.Run(t, snap)
+	assert.Len(t, snap.Resources, 0)
+}
+
+func TestReplaceOnChanges(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	inputs := resource.NewPropertyMapFromMap(map[string]interface{}{
+		"size": "small",
+		"spec": map[string]interface{}{"image": "nginx:1.14", "replicas": 1},
+	})
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResourceWithOptions("pkgA:m:typA", "resA", true, "", false, nil, "", inputs,
+			deploytest.ResourceOptions{ReplaceOnChanges: []string{"spec.image"}})
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	// expect validates that the update performed the given operation on the resource.
+	expect := func(op deploy.StepOp) enginetest.ValidateFunc {
+		return func(_ workspace.Project, _ deploy.Target, _ *enginetest.Journal, events []engine.Event,
+			err error) error {
+			var ops []deploy.StepOp
+			for _, e := range events {
+				if e.Type != engine.ResourcePreEvent {
+					continue
+				}
+				md := e.Payload.(engine.ResourcePreEventPayload).Metadata
+				if md.URN.Type() != "pkgA:m:typA" {
+					continue
+				}
+				ops = append(ops, md.Op)
+				if md.Op == deploy.OpReplace {
+					assert.Equal(t, []resource.PropertyKey{"spec"}, md.Keys)
+					assert.Equal(t, "changed and is listed in replaceOnChanges", md.Reasons["spec"])
+				}
+			}
+			assert.Contains(t, ops, op)
+			if op == deploy.OpUpdate {
+				assert.NotContains(t, ops, deploy.OpReplace)
+			}
+			return err
+		}
+	}
+
+	p := &enginetest.TestPlan{
+		Options:         engine.UpdateOptions{Host: host},
+		Steps:           []enginetest.TestStep{{Op: engine.Update}},
+		IsolatePreviews: true,
+	}
+	snap := p.Run(t, nil)
+	assert.Equal(t, []string{"spec.image"}, snap.Resources[1].ReplaceOnChanges)
+
+	// Changes to other inputs, even within the same object, update the resource in place.
+	inputs = resource.NewPropertyMapFromMap(map[string]interface{}{
+		"size": "large",
+		"spec": map[string]interface{}{"image": "nginx:1.14", "replicas": 2},
+	})
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, SkipPreview: true, Validate: expect(deploy.OpUpdate)}}
+	snap = p.Run(t, snap)
+
+	// But changes to the listed paths replace it, although the provider would have updated it.
+	inputs = resource.NewPropertyMapFromMap(map[string]interface{}{
+		"size": "large",
+		"spec": map[string]interface{}{"image": "nginx:1.15", "replicas": 2},
+	})
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, SkipPreview: true, Validate: expect(deploy.OpReplace)}}
+	p.Run(t, snap)
+}
+
+func TestDeleteBeforeReplaceOption(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+					// Changes to "size" require replacement, although the provider does not ask to delete first.
+					if !olds["size"].DeepEquals(news["size"]) {
+						return plugin.DiffResult{Changes: plugin.DiffSome,
+							ReplaceKeys: []resource.PropertyKey{"size"}}, nil
+					}
+					return plugin.DiffResult{}, nil
+				},
+			}, nil
+		}),
+	}
+
+	size := "small"
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		urnA, _, _, err := monitor.RegisterResourceWithOptions("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.NewPropertyMapFromMap(map[string]interface{}{"size": size}),
+			deploytest.ResourceOptions{DeleteBeforeReplace: true})
+		assert.NoError(t, err)
+		urnB, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false,
+			[]resource.URN{urnA}, "", resource.PropertyMap{})
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resC", true, "", false,
+			[]resource.URN{urnB}, "", resource.PropertyMap{})
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options:         engine.UpdateOptions{Host: host},
+		Steps:           []enginetest.TestStep{{Op: engine.Update}},
+		IsolatePreviews: true,
+	}
+	snap := p.Run(t, nil)
+	assert.True(t, snap.Resources[1].DeleteBeforeReplace)
+	urnA := p.NewURN("pkgA:m:typA", "resA", "")
+	urnB, urnC := p.NewURN("pkgA:m:typA", "resB", ""), p.NewURN("pkgA:m:typA", "resC", "")
+
+	// Replacing A deletes it first, and so first deletes the chain of resources that depend upon it, which are then
+	// replaced in turn.
+	size = "large"
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, SkipPreview: true, Validate: func(_ workspace.Project,
+		_ deploy.Target, j *enginetest.Journal, events []engine.Event, err error) error {
+
+		sawReplace := false
+		for _, e := range events {
+			if e.Type != engine.ResourcePreEvent {
+				continue
+			}
+			md := e.Payload.(engine.ResourcePreEventPayload).Metadata
+			if md.URN != urnA || md.Op != deploy.OpReplace {
+				continue
+			}
+			sawReplace = true
+			assert.True(t, md.DeleteBeforeReplace)
+			assert.Equal(t, []resource.URN{urnC, urnB}, md.Dependents)
+
+			summary := engine.GetResourcePropertiesSummary(md, 0, false)
+			assert.Contains(t, summary, "[delete before replace]")
+			assert.Contains(t, summary, "[also replaces dependent `resC` (pkgA:m:typA)]")
+			assert.Contains(t, summary, "[also replaces dependent `resB` (pkgA:m:typA)]")
+		}
+		assert.True(t, sawReplace)
+
+		var deleted, created []resource.URN
+		for _, entry := range j.Entries {
+			if entry.Kind != enginetest.JournalEntrySuccess || providers.IsProviderType(entry.Step.URN().Type()) {
+				continue
+			}
+			switch entry.Step.Op() {
+			case deploy.OpDeleteReplaced:
+				assert.Empty(t, created)
+				deleted = append(deleted, entry.Step.URN())
+			case deploy.OpCreateReplacement:
+				created = append(created, entry.Step.URN())
+			}
+		}
+		assert.Equal(t, []resource.URN{urnC, urnB, urnA}, deleted)
+		assert.Equal(t, []resource.URN{urnA, urnB, urnC}, created)
+		return err
+	}}}
+	snap = p.Run(t, snap)
+	assert.Len(t, snap.Resources, 4)
+
+	// A step decider that denies deletions also denies those that deleting before replacing requires.
+	size = "medium"
+	p.Options.StepDecider = &testStepDecider{deny: true}
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, ExpectFailure: true}}
+	snap = p.Run(t, snap)
+	assert.Len(t, snap.Resources, 4)
+	for _, res := range snap.Resources[1:] {
+		assert.False(t, res.Delete)
+	}
+}
+
+func TestDeleteBeforeReplaceSharedDependent(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+					if !olds["size"].DeepEquals(news["size"]) {
+						return plugin.DiffResult{Changes: plugin.DiffSome,
+							ReplaceKeys: []resource.PropertyKey{"size"}, DeleteBeforeReplace: true}, nil
+					}
+					return plugin.DiffResult{}, nil
+				},
+			}, nil
+		}),
+	}
+
+	size := "small"
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		inputs := resource.NewPropertyMapFromMap(map[string]interface{}{"size": size})
+		urnA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", inputs)
+		assert.NoError(t, err)
+		urnB, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, nil, "", inputs)
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resC", true, "", false,
+			[]resource.URN{urnA, urnB}, "", resource.PropertyMap{})
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options:         engine.UpdateOptions{Host: host},
+		Steps:           []enginetest.TestStep{{Op: engine.Update}},
+		IsolatePreviews: true,
+	}
+	snap := p.Run(t, nil)
+	urnA, urnB := p.NewURN("pkgA:m:typA", "resA", ""), p.NewURN("pkgA:m:typA", "resB", "")
+	urnC := p.NewURN("pkgA:m:typA", "resC", "")
+
+	// Replacing both A and B deletes C, which depends on both, only once: with the first of them.
+	size = "large"
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, SkipPreview: true, Validate: func(_ workspace.Project,
+		_ deploy.Target, j *enginetest.Journal, events []engine.Event, err error) error {
+
+		dependents := make(map[resource.URN][]resource.URN)
+		for _, e := range events {
+			if e.Type == engine.ResourcePreEvent {
+				if md := e.Payload.(engine.ResourcePreEventPayload).Metadata; md.Op == deploy.OpReplace {
+					dependents[md.URN] = md.Dependents
+				}
+			}
+		}
+		assert.Equal(t, []resource.URN{urnC}, dependents[urnA])
+		assert.Empty(t, dependents[urnB])
+
+		var deleted []resource.URN
+		for _, entry := range j.Entries {
+			if entry.Kind == enginetest.JournalEntrySuccess && entry.Step.Op() == deploy.OpDeleteReplaced {
+				deleted = append(deleted, entry.Step.URN())
+			}
+		}
+		assert.Equal(t, []resource.URN{urnC, urnA, urnB}, deleted)
+		return err
+	}}}
+	snap = p.Run(t, snap)
+	assert.Len(t, snap.Resources, 4)
+}
+
+func TestAdditionalSecretOutputs(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN,
+					news resource.PropertyMap) (resource.ID, resource.PropertyMap, resource.Status, error) {
+					outs := news.Copy()
+					outs["token"] = resource.NewStringProperty("tok-123")
+					return "created-id", outs, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(info plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		opts := deploytest.ResourceOptions{AdditionalSecretOutputs: []resource.PropertyKey{"token"}}
+		_, _, outs, err := monitor.RegisterResourceWithOptions("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, opts)
+		assert.NoError(t, err)
+		// The program itself still sees the value.
+		if !info.DryRun {
+			assert.Equal(t, "tok-123", outs["token"].StringValue())
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps: []enginetest.TestStep{{Op: engine.Update, Validate: func(_ workspace.Project, _ deploy.Target,
+			_ *enginetest.Journal, evts []engine.Event, err error) error {
+
+			// Events never carry the value of the output.
+			sawOutputs := false
+			for _, evt := range evts {
+				if evt.Type != engine.ResourceOutputsEvent {
+					continue
+				}
+				md := evt.Payload.(engine.ResourceOutputsEventPayload).Metadata
+				if md.URN.Type() == "pkgA:m:typA" {
+					sawOutputs = true
+					assert.Equal(t, "[secret]", md.New.Outputs["token"].StringValue())
+				}
+			}
+			assert.True(t, sawOutputs)
+			return err
+		}}},
+	}
+	snap := p.Run(t, nil)
+	assert.Equal(t, []resource.PropertyKey{"token"}, snap.Resources[1].AdditionalSecretOutputs)
+
+	// The checkpoint keeps the value itself, so that later updates can use it.
+	assert.Equal(t, "tok-123", snap.Resources[1].Outputs()["token"].StringValue())
+}
+
+func TestStackOutputChanges(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	outputs := resource.PropertyMap{
+		"endpoint": resource.NewStringProperty("https://a.example.com"),
+		"arn":      resource.NewStringProperty("arn:a"),
+	}
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		urn, _, _, err := monitor.RegisterResource(resource.RootStackType, "test", false, "", false, nil, "",
+			resource.PropertyMap{})
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resA", true, urn, false, nil, "",
+			resource.PropertyMap{})
+		assert.NoError(t, err)
+		return monitor.RegisterResourceOutputs(urn, outputs)
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	summaryOutputs := func(evts []engine.Event) []string {
+		for _, evt := range evts {
+			if evt.Type == engine.SummaryEvent {
+				return evt.Payload.(engine.SummaryEventPayload).OutputChanges
+			}
+		}
+		return nil
+	}
+
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps: []enginetest.TestStep{{
+			Op: engine.Update,
+			Validate: func(_ workspace.Project, _ deploy.Target, _ *enginetest.Journal, evts []engine.Event,
+				err error) error {
+				assert.Equal(t, []string{"arn", "endpoint"}, summaryOutputs(evts))
+				return err
+			},
+		}},
+	}
+	snap := p.Run(t, nil)
+
+	// An update that leaves the outputs alone reports no changes to them, even when they are guarded.
+	p.Options.ExpectNoOutputChanges = []string{"*"}
+	p.Steps = []enginetest.TestStep{{
+		Op: engine.Update,
+		Validate: func(_ workspace.Project, _ deploy.Target, _ *enginetest.Journal, evts []engine.Event,
+			err error) error {
+			assert.Empty(t, summaryOutputs(evts))
+			return err
+		},
+	}}
+	snap = p.Run(t, snap)
+
+	// Changing an output that is not guarded is allowed, but changing a guarded one fails the preview.
+	outputs = resource.PropertyMap{
+		"endpoint": resource.NewStringProperty("https://b.example.com"),
+		"arn":      resource.NewStringProperty("arn:a"),
+	}
+	// Both the preview and the update report the change.
+	var reported [][]string
+	p.Options.OnOutputChanges = func(outputs []string) {
+		reported = append(reported, outputs)
+	}
+	p.Options.ExpectNoOutputChanges = []string{"arn"}
+	p.Steps = []enginetest.TestStep{{Op: engine.Update}}
+	p.Run(t, snap)
+	assert.Equal(t, [][]string{{"endpoint"}, {"endpoint"}}, reported)
+	p.Options.OnOutputChanges = nil
+
+	p.Options.ExpectNoOutputChanges = []string{"end*"}
+	p.Steps = []enginetest.TestStep{{
+		Op:            engine.Update,
+		ExpectFailure: true,
+		Validate: func(_ workspace.Project, _ deploy.Target, _ *enginetest.Journal, evts []engine.Event,
+			err error) error {
+			assert.Equal(t, []string{"endpoint"}, summaryOutputs(evts))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), "endpoint")
+			}
+			return err
+		},
+	}}
+	p.Run(t, snap)
+}
+
+func TestStackOutputChangesUnknowns(t *testing.T) {
+	olds := resource.PropertyMap{
+		"same":    resource.NewStringProperty("a"),
+		"deleted": resource.NewStringProperty("b"),
+		"unknown": resource.NewStringProperty("c"),
+	}
+	news := resource.PropertyMap{
+		"same":    resource.NewStringProperty("a"),
+		"unknown": resource.MakeComputed(resource.NewStringProperty("")),
+		"added":   resource.NewNumberProperty(1),
+	}
+	assert.Equal(t, []string{"added", "deleted", "unknown"}, engine.StackOutputChanges(olds, news))
+	assert.Empty(t, engine.StackOutputChanges(olds, olds))
+}
+
+func TestCredentialProfiles(t *testing.T) {
+	accounts := map[string]string{"dev": "111111111111", "prod": "222222222222"}
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("aws", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			var profile string
+			return &deploytest.Provider{
+				ConfigureF: func(news resource.PropertyMap) error {
+					if v, has := news["profile"]; has {
+						profile = v.StringValue()
+					}
+					return nil
+				},
+				InvokeF: func(tok tokens.ModuleMember,
+					inputs resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+					assert.Equal(t, tokens.ModuleMember("aws:index/getCallerIdentity:getCallerIdentity"), tok)
+					return resource.PropertyMap{"accountId": resource.NewStringProperty(accounts[profile])}, nil, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("aws:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{})
+		return err
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	// The profile's settings are passed to the default provider, whose credentials must match the expected account.
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps:   []enginetest.TestStep{{Op: engine.Update}},
+		CredentialProfiles: map[string]*workspace.CredentialProfile{
+			"aws": {Config: map[string]string{"profile": "dev"}, ExpectedIdentity: "111111111111"},
+		},
+	}
+	snap := p.Run(t, nil)
+	for _, res := range snap.Resources {
+		if providers.IsProviderType(res.Type) {
+			assert.Equal(t, "dev", res.Inputs()["profile"].StringValue())
+		}
+	}
+
+	// Selecting credentials for another account refuses to run, even during a preview.
+	p.CredentialProfiles["aws"].Config["profile"] = "prod"
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, ExpectFailure: true}}
+	p.Run(t, snap)
+
+	// A profile that names its own identity function must also name the field of its result to check.
+	p.CredentialProfiles["aws"] = &workspace.CredentialProfile{
+		ExpectedIdentity: "111111111111",
+		IdentityFunction: "aws:index/getCallerIdentity:getCallerIdentity",
+	}
+	p.Run(t, snap)
+}
+
+func TestRandomSeed(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CheckF: func(urn resource.URN, olds, news resource.PropertyMap,
+					randomSeed []byte) (resource.PropertyMap, []plugin.CheckFailure, error) {
+					// Auto-name the resource from its random seed.
+					name, err := resource.DefaultNamingStrategy.NewNameFromSeed(string(urn.Name()), -1, randomSeed)
+					if err != nil {
+						return nil, nil, err
+					}
+					inputs := news.Copy()
+					inputs["name"] = resource.NewStringProperty(name)
+					return inputs, nil, nil
+				},
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+					if !olds["size"].DeepEquals(news["size"]) {
+						return plugin.DiffResult{Changes: plugin.DiffSome,
+							ReplaceKeys: []resource.PropertyKey{"size"}}, nil
+					}
+					return plugin.DiffResult{}, nil
+				},
+			}, nil
+		}),
+	}
+
+	size := "small"
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.NewPropertyMapFromMap(map[string]interface{}{"size": size}))
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	name := func(snap *deploy.Snapshot) string {
+		return snap.Resources[1].Inputs()["name"].StringValue()
+	}
+
+	// Updates with the same seed produce the same resource seeds, and so the same names.
+	p := &enginetest.TestPlan{
+		Options:         engine.UpdateOptions{Host: host, RandomSeed: []byte("seed")},
+		Steps:           []enginetest.TestStep{{Op: engine.Update}},
+		IsolatePreviews: true,
+	}
+	snap := p.Run(t, nil)
+	assert.Len(t, snap.Resources[1].RandomSeed, 32)
+	assert.True(t, strings.HasPrefix(name(snap), "resA-"))
+
+	again := p.Run(t, nil)
+	assert.Equal(t, snap.Resources[1].RandomSeed, again.Resources[1].RandomSeed)
+	assert.Equal(t, name(snap), name(again))
+
+	// Whereas those with a different seed, or with none at all, do not.
+	p.Options.RandomSeed = []byte("other")
+	other := p.Run(t, nil)
+	assert.NotEqual(t, name(snap), name(other))
+	p.Options.RandomSeed = nil
+	other = p.Run(t, nil)
+	assert.NotEqual(t, name(snap), name(other))
+
+	// A resource keeps its seed, and so its name, from update to update, whether or not the plan has a seed.
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, SkipPreview: true}}
+	same := p.Run(t, snap)
+	assert.Equal(t, snap.Resources[1].RandomSeed, same.Resources[1].RandomSeed)
+	assert.Equal(t, name(snap), name(same))
+
+	// A replacement is given a new seed, and so a new name, which is again derived from the plan's seed, if any.
+	seed, oldName := snap.Resources[1].RandomSeed, name(snap)
+	size = "large"
+	p.Options.RandomSeed = []byte("seed")
+	replaced := p.Run(t, same)
+	assert.NotEqual(t, seed, replaced.Resources[1].RandomSeed)
+	assert.NotEqual(t, oldName, name(replaced))
+
+	again = p.Run(t, again)
+	assert.Equal(t, replaced.Resources[1].RandomSeed, again.Resources[1].RandomSeed)
+	assert.Equal(t, name(replaced), name(again))
+}
+
+func TestUnchangedInputsSkipDiff(t *testing.T) {
+	checks, diffs, drifted := 0, 0, false
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CheckF: func(urn resource.URN, olds, news resource.PropertyMap,
+					randomSeed []byte) (resource.PropertyMap, []plugin.CheckFailure, error) {
+					checks++
+					inputs := news.Copy()
+					inputs["region"] = resource.NewStringProperty("us-west-2")
+					return inputs, nil, nil
+				},
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+					diffs++
+					if !olds["size"].DeepEquals(news["size"]) {
+						return plugin.DiffResult{Changes: plugin.DiffSome}, nil
+					}
+					return plugin.DiffResult{Changes: plugin.DiffNone}, nil
+				},
+				ReadF: func(urn resource.URN, id resource.ID,
+					props resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
+					outs := props.Copy()
+					if drifted {
+						outs["drift"] = resource.NewBoolProperty(true)
+					}
+					return outs, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	size := "small"
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.NewPropertyMapFromMap(map[string]interface{}{"size": size}))
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps:   []enginetest.TestStep{{Op: engine.Update, SkipPreview: true}},
+	}
+	snap := p.Run(t, nil)
+	assert.Equal(t, 1, checks)
+	assert.Equal(t, 0, diffs)
+	hash := snap.Resources[1].InputsHash
+	assert.NotEqual(t, "", hash)
+
+	// An update whose inputs hash to the same value as the last one's skips the provider entirely, and keeps the
+	// inputs the provider checked last time.
+	checks, diffs = 0, 0
+	snap = p.Run(t, snap)
+	assert.Equal(t, 0, checks)
+	assert.Equal(t, 0, diffs)
+	assert.Equal(t, hash, snap.Resources[1].InputsHash)
+	assert.Equal(t, "us-west-2", snap.Resources[1].Inputs()["region"].StringValue())
+
+	// Strict diffing and refreshes always consult the provider.
+	p.Options.StrictDiff = true
+	snap = p.Run(t, snap)
+	assert.Equal(t, 1, checks)
+	p.Options.StrictDiff = false
+
+	checks = 0
+	p.Options.Refresh = true
+	snap = p.Run(t, snap)
+	assert.Equal(t, 1, checks)
+	p.Options.Refresh = false
+
+	// A refresh that finds the resource as it was keeps the hash of its inputs, but one that finds it changed drops
+	// it, so that the next update checks the program's inputs against the resource's new state.
+	p.Steps = []enginetest.TestStep{{Op: engine.Refresh}}
+	snap = p.Run(t, snap)
+	assert.Equal(t, hash, snap.Resources[1].InputsHash)
+	drifted = true
+	snap = p.Run(t, snap)
+	assert.Equal(t, "", snap.Resources[1].InputsHash)
+	p.Steps = []enginetest.TestStep{{Op: engine.Update, SkipPreview: true}}
+
+	checks = 0
+	snap = p.Run(t, snap)
+	assert.Equal(t, 1, checks)
+	assert.Equal(t, hash, snap.Resources[1].InputsHash)
+
+	// Updates whose provider's configuration has changed consult the provider too.
+	checks = 0
+	p.Config = config.Map{config.MustMakeKey("pkgA", "foo"): config.NewValue("bar")}
+	snap = p.Run(t, snap)
+	assert.Equal(t, 1, checks)
+	hash = snap.Resources[1].InputsHash
+
+	// As do updates whose inputs have changed.
+	checks, diffs = 0, 0
+	size = "large"
+	snap = p.Run(t, snap)
+	assert.Equal(t, 1, checks)
+	assert.Equal(t, 1, diffs)
+	assert.NotEqual(t, hash, snap.Resources[1].InputsHash)
+}
+
+func TestImportIntoExistingStack(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	names := []string{"resA"}
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		root, _, _, err := monitor.RegisterResource(resource.RootStackType, "test-test", false, "", false, nil, "",
+			resource.PropertyMap{})
+		assert.NoError(t, err)
+		for _, name := range names {
+			_, _, _, err = monitor.RegisterResource("pkgA:m:typA", name, true, root, false, nil, "",
+				resource.PropertyMap{})
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &enginetest.TestPlan{
+		Options: engine.UpdateOptions{Host: host},
+		Steps:   []enginetest.TestStep{{Op: engine.Update}},
+	}
+	snap := p.Run(t, nil)
+
+	// Import a resource of the same package as resA.  It shares resA's default provider rather than getting one of
+	// its own.
+	m := &discovery.Manifest{Resources: []discovery.Resource{{Type: "pkgA:m:typA", Name: "imported", ID: "id-1"}}}
+	snap, err := m.Snapshot(snap, "test", "test")
+	assert.NoError(t, err)
+
+	names = []string{"resA", "imported"}
+	snap = p.Run(t, snap)
+
+	provURN := p.NewProviderURN("pkgA", "default", "")
+	var defaultProviders int
+	byName := make(map[tokens.QName]*resource.State)
+	for _, res := range snap.Resources {
+		if res.URN == provURN {
+			defaultProviders++
+		}
+		byName[res.URN.Name()] = res
+	}
+	assert.Equal(t, 1, defaultProviders)
+	if assert.NotNil(t, byName["resA"]) && assert.NotNil(t, byName["imported"]) {
+		assert.Equal(t, byName["resA"].Provider, byName["imported"].Provider)
+		assert.Equal(t, resource.ID("id-1"), byName["imported"].ID)
+	}
+}
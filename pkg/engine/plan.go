@@ -48,6 +48,9 @@ func ProjectInfoContext(projinfo *Projinfo, host plugin.Host, config plugin.Conf
 	if err != nil {
 		return "", "", nil, err
 	}
+	if projinfo.Proj.Sandbox != nil {
+		ctx.Limits = projinfo.Proj.Sandbox.Limits
+	}
 
 	return pwd, main, ctx, nil
 }
@@ -101,7 +104,7 @@ type planOptions struct {
 
 // planSourceFunc is a callback that will be used to prepare for, and evaluate, the "new" state for a stack.
 type planSourceFunc func(
-	opts planOptions, proj *workspace.Project, pwd, main string,
+	opts planOptions, proj *workspace.Project, root, pwd, main string,
 	target *deploy.Target, plugctx *plugin.Context, dryRun bool) (deploy.Source, error)
 
 // plan just uses the standard logic to parse arguments, options, and to create a snapshot and plan.
@@ -123,15 +126,55 @@ func plan(ctx *Context, info *planContext, opts planOptions, dryRun bool) (*plan
 	contract.Assert(proj != nil)
 	contract.Assert(target != nil)
 	projinfo := &Projinfo{Proj: proj, Root: info.Update.GetRoot()}
-	pwd, main, plugctx, err := ProjectInfoContext(projinfo, opts.host, target, pluginEvents,
+
+	// The program's sandbox is torn down when the plan is closed, or straight away if no plan is made.
+	var programCopy string
+	var refuser *networkRefuser
+	planned := false
+	defer func() {
+		if !planned {
+			contract.IgnoreError(closeSandbox(programCopy, refuser))
+		}
+	}()
+
+	// If the program must run from a read-only copy of its project, plan against the copy.
+	if proj.Sandbox != nil && proj.Sandbox.ReadOnlyProgram {
+		var err error
+		if programCopy, err = copyReadOnly(projinfo.Root); err != nil {
+			return nil, errors.Wrap(err, "copying the program into its sandbox")
+		}
+		projinfo.Root = programCopy
+	}
+
+	// If asked, direct the HTTP requests that the program makes while it is previewed to a proxy that refuses them.  This
+	// is only a hint: the program remains free to reach the network without the proxy.
+	if dryRun && proj.Sandbox != nil && proj.Sandbox.ProxyDenyHTTP {
+		var err error
+		if refuser, err = startNetworkRefuser(); err != nil {
+			return nil, err
+		}
+	}
+
+	pwd, main, plugctx, err := ProjectInfoContext(projinfo, opts.Host, target, pluginEvents,
 		opts.Diag, opts.StatusDiag, info.TracingSpan)
 	if err != nil {
 		return nil, err
 	}
+	plugctx.AutoNaming = resource.NewNamingStrategy(proj.AutoNaming, target.AutoNaming)
+	plugctx.EnvVars = target.EnvVars
+	plugctx.ProviderOptions = opts.ProviderOptions
+	if refuser != nil {
+		plugctx.ProgramEnvVars = refuser.EnvVars()
+	}
+	if plugctx.ExpectedAccount, err = target.GetExpectedAccount(); err != nil {
+		return nil, err
+	}
 
 	// Now create the state source.  This may issue an error if it can't create the source.  This entails,
 	// for example, loading any plugins which will be required to execute a program, among other things.
-	source, err := opts.SourceFunc(opts, proj, pwd, main, target, plugctx, dryRun)
+	sourceSpan := opentracing.StartSpan("pulumi-plan-source", opentracing.ChildOf(info.TracingSpan.Context()))
+	source, err := opts.SourceFunc(opts, proj, projinfo.Root, pwd, main, target, plugctx, dryRun)
+	sourceSpan.Finish()
 	if err != nil {
 		return nil, err
 	}
@@ -154,11 +197,14 @@ func plan(ctx *Context, info *planContext, opts planOptions, dryRun bool) (*plan
 	if err != nil {
 		return nil, err
 	}
+	planned = true
 	return &planResult{
-		Ctx:     info,
-		Plugctx: plugctx,
-		Plan:    plan,
-		Options: opts,
+		Ctx:         info,
+		Plugctx:     plugctx,
+		Plan:        plan,
+		Options:     opts,
+		programCopy: programCopy,
+		refuser:     refuser,
 	}, nil
 }
 
@@ -167,6 +213,9 @@ type planResult struct {
 	Plugctx *plugin.Context // the context containing plugins and their state.
 	Plan    *deploy.Plan    // the plan created by this command.
 	Options planOptions     // the options used during planning.
+
+	programCopy string          // the read-only copy of the project that the program runs from, if any.
+	refuser     *networkRefuser // the proxy that refuses the program's HTTP requests, if any.
 }
 
 // Chdir changes the directory so that all operations from now on are relative to the project we are working with.
@@ -194,16 +243,41 @@ func (res *planResult) Chdir() (func(), error) {
 // resulting Snapshot, no matter whether an error occurs or not; an error, if something went wrong; the step that
 // failed, if the error is non-nil; and finally the state of the resource modified in the failing step.
 func (res *planResult) Walk(cancelCtx *Context, events deploy.Events, preview bool) error {
+	span := opentracing.StartSpan("pulumi-plan-walk", opentracing.ChildOf(res.Ctx.TracingSpan.Context()))
+	defer span.Finish()
+
 	ctx, cancelFunc := context.WithCancel(context.Background())
 
 	done := make(chan bool)
 	var err error
 	go func() {
 		opts := deploy.Options{
-			Events:      events,
-			Parallel:    res.Options.Parallel,
-			Refresh:     res.Options.Refresh,
-			RefreshOnly: res.Options.isRefresh,
+			Events:            events,
+			Parallel:          res.Options.Parallel,
+			Refresh:           res.Options.Refresh,
+			RefreshOnly:       res.Options.isRefresh,
+			StepDecider:       res.Options.StepDecider,
+			RefreshParallel:   res.Options.RefreshParallel,
+			RefreshRateLimits: res.Options.RefreshRateLimits,
+
+			RefreshChangedOnly:     res.Options.RefreshChangedOnly,
+			RefreshDriftProneTypes: res.Options.RefreshDriftProneTypes,
+			RefreshSince:           res.Options.RefreshSince,
+			DeleteTargets:          res.Options.DeleteTargets,
+			UpdateVersion:          res.Options.UpdateVersion,
+			RandomSeed:             res.Options.RandomSeed,
+			StrictDiff:             res.Options.StrictDiff,
+			ProviderDefaults:       getProviderDefaults(res.Ctx.Update),
+			ShowFullDiff:           res.Options.ShowFullDiff,
+		}
+		if hook := res.Options.StepHook; hook != nil {
+			opts.StepHook = func(step deploy.Step) deploy.StepAction {
+				// Steps that are not reported are not presented to the hook either.
+				if !res.Options.reportDefaultProviderSteps && isDefaultProviderStep(step) {
+					return deploy.StepContinue
+				}
+				return hook(makeStepEventMetadata(step.Op(), step, res.Options.Debug, res.Options.Events.Secrets))
+			}
 		}
 		err = res.Plan.Execute(ctx, opts, preview)
 		close(done)
@@ -230,7 +304,12 @@ func (res *planResult) Walk(cancelCtx *Context, events deploy.Events, preview bo
 }
 
 func (res *planResult) Close() error {
-	return res.Plugctx.Close()
+	// The plugins are closed first, since they may be running from the program's copy or using its proxy.
+	err := res.Plugctx.Close()
+	if sberr := closeSandbox(res.programCopy, res.refuser); err == nil {
+		err = sberr
+	}
+	return err
 }
 
 // printPlan prints the plan's result to the plan's Options.Events stream.
@@ -245,22 +324,33 @@ func printPlan(ctx *Context, result *planResult, dryRun bool) (ResourceChanges,
 
 	// Emit an event with a summary of operation counts.
 	changes := ResourceChanges(actions.Ops)
-	result.Options.Events.previewSummaryEvent(changes)
+	result.Options.Events.previewSummaryEvent(changes, ResourceChanges(actions.RefreshOps), actions.Outputs)
+	if result.Options.OnOutputChanges != nil {
+		result.Options.OnOutputChanges(actions.Outputs)
+	}
+
+	// Fail the preview if it would change any outputs that were expected to remain the same.
+	if err := checkOutputChanges(result.Options.ExpectNoOutputChanges, actions.Outputs); err != nil {
+		return changes, err
+	}
 	return changes, nil
 }
 
 type planActions struct {
-	Ops     map[deploy.StepOp]int
-	Opts    planOptions
-	Seen    map[resource.URN]deploy.Step
-	MapLock sync.Mutex
+	Ops        map[deploy.StepOp]int
+	RefreshOps map[deploy.StepOp]int
+	Outputs    []string
+	Opts       planOptions
+	Seen       map[resource.URN]deploy.Step
+	MapLock    sync.Mutex
 }
 
 func newPlanActions(opts planOptions) *planActions {
 	return &planActions{
-		Ops:  make(map[deploy.StepOp]int),
-		Opts: opts,
-		Seen: make(map[resource.URN]deploy.Step),
+		Ops:        make(map[deploy.StepOp]int),
+		RefreshOps: make(map[deploy.StepOp]int),
+		Opts:       opts,
+		Seen:       make(map[resource.URN]deploy.Step),
 	}
 }
 
@@ -295,7 +385,11 @@ func (acts *planActions) OnResourceStepPost(ctx interface{},
 			reportedURN = step.URN()
 		}
 
-		acts.Opts.Diag.Errorf(diag.GetPreviewFailedError(reportedURN), err)
+		d := diag.GetPreviewFailedError(reportedURN)
+		if id, ok := diag.ErrorID(err); ok {
+			d.ID = id
+		}
+		acts.Opts.Diag.Errorf(d, err)
 	} else if reportStep {
 		op, record := step.Op(), step.Logical()
 		if acts.Opts.isRefresh && op == deploy.OpRefresh {
@@ -310,15 +404,35 @@ func (acts *planActions) OnResourceStepPost(ctx interface{},
 			acts.MapLock.Unlock()
 		}
 
+		// Track any drift found by a refresh that precedes the preview separately from the program's changes.
+		if refreshOp, changed := refreshResult(acts.Opts, step); changed {
+			acts.MapLock.Lock()
+			acts.RefreshOps[refreshOp]++
+			acts.MapLock.Unlock()
+		}
+
 		acts.Opts.Events.resourceOutputsEvent(op, step, true /*planning*/, acts.Opts.Debug)
 	}
 
 	return nil
 }
 
+// refreshResult returns the result of a refresh step that precedes an update or preview, and whether that result is
+// a change to the resource's state that ought to be reported apart from the changes made by the program.
+func refreshResult(opts planOptions, step deploy.Step) (deploy.StepOp, bool) {
+	if !opts.Refresh || opts.isRefresh || step.Op() != deploy.OpRefresh {
+		return "", false
+	}
+	op := step.(*deploy.RefreshStep).ResultOp()
+	return op, op != deploy.OpSame
+}
+
 func (acts *planActions) OnResourceOutputs(step deploy.Step) error {
 	acts.MapLock.Lock()
 	assertSeen(acts.Seen, step)
+	if step.URN().Type() == resource.RootStackType {
+		acts.Outputs = stackOutputChanges(step)
+	}
 	acts.MapLock.Unlock()
 
 	// Check for a default provider step and skip reporting if necessary.
@@ -337,6 +451,15 @@ func assertSeen(seen map[resource.URN]deploy.Step, step deploy.Step) {
 	contract.Assertf(has, "URN '%v' had not been marked as seen", step.URN())
 }
 
+// stackOutputChanges returns the names of the outputs of a stack's root resource that the given step changes.
+func stackOutputChanges(step deploy.Step) []string {
+	var olds resource.PropertyMap
+	if old := step.Old(); old != nil {
+		olds = old.Outputs()
+	}
+	return StackOutputChanges(olds, step.New().Outputs())
+}
+
 func isDefaultProviderStep(step deploy.Step) bool {
 	urn := step.URN()
 	return providers.IsProviderType(urn.Type()) && urn.Name() == "default"
@@ -0,0 +1,159 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// resolveProviderVersions computes the versions of the default providers' plugins from the plugins that the language
+// host reports the program requires.  A version pinned by the project takes precedence over the one that the program
+// asks for, and a range of versions resolves to the newest installed version within it or, if there is none, to the
+// lowest version it admits, which must then be installed.  Conflicting requirements are reported as warnings.
+func resolveProviderVersions(plugins []workspace.PluginInfo, proj *workspace.Project,
+	d diag.Sink) (map[tokens.Package]*semver.Version, error) {
+
+	// First gather the versions that the program asks for.  If its dependencies ask for several versions of the same
+	// plugin, the newest satisfies them all, since each is a minimum.
+	required := make(map[string][]*semver.Version)
+	var names []string
+	for _, p := range plugins {
+		if p.Kind != workspace.ResourcePlugin {
+			continue
+		}
+		if _, has := required[p.Name]; !has {
+			names = append(names, p.Name)
+		}
+		required[p.Name] = append(required[p.Name], p.Version)
+	}
+	sort.Strings(names)
+
+	versions := make(map[tokens.Package]*semver.Version)
+	for _, name := range names {
+		version := newestVersion(required[name])
+		if distinct := distinctVersions(required[name]); len(distinct) > 1 {
+			d.Warningf(diag.GetConflictingPluginVersionsWarning(""), name, strings.Join(distinct, ", "), version)
+		}
+
+		if pinned := proj.ProviderVersion(name); pinned != nil {
+			if version != nil && pinned.LT(*version) {
+				d.Warningf(diag.GetProviderVersionPinnedWarning(""), pinned, name, version)
+			}
+			version = pinned
+		} else if rng := proj.ProviderVersionRange(name); rng != nil {
+			if version != nil && !rng.Contains(*version) {
+				d.Warningf(diag.GetProviderVersionExcludedWarning(""), rng, name, version)
+			}
+			// Use the newest installed version within the range.  If none is installed, install the lowest version
+			// within it, since that is the only one known to exist; the exact version is required either way, so
+			// that a newer version outside of the range is neither loaded nor mistaken for an installed one.
+			resolved, err := workspace.ResolvePluginVersion(workspace.ResourcePlugin, name, rng)
+			if err != nil {
+				return nil, err
+			}
+			if resolved == nil {
+				if resolved = rng.Min(); resolved == nil {
+					return nil, errors.Errorf("no installed version of the %s plugin satisfies '%s'", name, rng)
+				}
+				workspace.SetExactPluginVersion(workspace.ResourcePlugin, name, *resolved)
+			}
+			version = resolved
+		}
+		versions[tokens.Package(name)] = version
+	}
+	return versions, nil
+}
+
+// ensureProviderPlugins checks that the plugins for the given default providers are installed before the program
+// runs, rather than failing when the program registers its first resource of each provider's package.  Missing
+// plugins are passed to install, if it is non-nil; otherwise, they are all reported at once.
+func ensureProviderPlugins(versions map[tokens.Package]*semver.Version,
+	install func(plugins []workspace.PluginInfo) error, d diag.Sink) error {
+
+	var missing []workspace.PluginInfo
+	for pkg, version := range versions {
+		_, path, err := workspace.GetPluginPath(workspace.ResourcePlugin, string(pkg), version)
+		if err != nil {
+			return err
+		} else if path == "" {
+			missing = append(missing, workspace.PluginInfo{
+				Kind:    workspace.ResourcePlugin,
+				Name:    string(pkg),
+				Version: version,
+			})
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Name < missing[j].Name })
+
+	if install != nil {
+		for _, p := range missing {
+			d.Infof(diag.Message("", fmt.Sprintf("installing the missing %s plugin %s", p.Kind, p)))
+		}
+		return install(missing)
+	}
+
+	var commands []string
+	for _, p := range missing {
+		command := fmt.Sprintf("pulumi plugin install %s %s", p.Kind, p.Name)
+		if p.Version != nil {
+			command += " " + p.Version.String()
+		}
+		commands = append(commands, "\n    "+command)
+	}
+	return errors.Errorf("the program requires plugins that are not installed; install them with:%s",
+		strings.Join(commands, ""))
+}
+
+// newestVersion returns the newest of the given versions, or nil if none of them is set.
+func newestVersion(versions []*semver.Version) *semver.Version {
+	var newest *semver.Version
+	for _, v := range versions {
+		if v != nil && (newest == nil || v.GT(*newest)) {
+			newest = v
+		}
+	}
+	return newest
+}
+
+// distinctVersions returns the distinct versions among the given ones that are set, in ascending order.
+func distinctVersions(versions []*semver.Version) []string {
+	var sorted []semver.Version
+	for _, v := range versions {
+		if v != nil {
+			sorted = append(sorted, *v)
+		}
+	}
+	semver.Sort(sorted)
+
+	var distinct []string
+	for i, v := range sorted {
+		if i == 0 || !v.Equals(sorted[i-1]) {
+			distinct = append(distinct, v.String())
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,82 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestResolveProviderVersions(t *testing.T) {
+	version := func(s string) *semver.Version {
+		v := semver.MustParse(s)
+		return &v
+	}
+	plugins := []workspace.PluginInfo{
+		{Kind: workspace.LanguagePlugin, Name: "nodejs"},
+		{Kind: workspace.ResourcePlugin, Name: "pkgA", Version: version("1.2.0")},
+		{Kind: workspace.ResourcePlugin, Name: "pkgA", Version: version("1.4.0")},
+		{Kind: workspace.ResourcePlugin, Name: "pkgB", Version: version("2.0.0")},
+		{Kind: workspace.ResourcePlugin, Name: "pkgC"},
+	}
+	proj := &workspace.Project{
+		Name: "test",
+		Providers: map[string]*workspace.ProviderDefaults{
+			"pkgB": {Version: "1.9.0"},
+		},
+	}
+
+	var stderr bytes.Buffer
+	d := diag.DefaultSink(&bytes.Buffer{}, &stderr, diag.FormatOptions{Color: colors.Never})
+	versions, err := resolveProviderVersions(plugins, proj, d)
+	assert.NoError(t, err)
+	assert.Equal(t, map[tokens.Package]*semver.Version{
+		"pkgA": version("1.4.0"),
+		"pkgB": version("1.9.0"),
+		"pkgC": nil,
+	}, versions)
+	assert.Contains(t, stderr.String(), "several versions of the pkgA plugin (1.2.0, 1.4.0); using 1.4.0")
+	assert.Contains(t, stderr.String(), "pins version 1.9.0 of the pkgB plugin, which is older than version 2.0.0")
+}
+
+func TestEnsureProviderPlugins(t *testing.T) {
+	version := semver.MustParse("1.0.0")
+	versions := map[tokens.Package]*semver.Version{"not-a-real-package": &version}
+	d := diag.DefaultSink(&bytes.Buffer{}, &bytes.Buffer{}, diag.FormatOptions{Color: colors.Never})
+
+	// Without an installer, the missing plugins are reported along with how to install them.
+	err := ensureProviderPlugins(versions, nil, d)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "pulumi plugin install resource not-a-real-package 1.0.0")
+
+	// Otherwise, the installer is asked to install them.
+	var installed []workspace.PluginInfo
+	err = ensureProviderPlugins(versions, func(plugins []workspace.PluginInfo) error {
+		installed = plugins
+		return nil
+	}, d)
+	assert.NoError(t, err)
+	assert.Equal(t, []workspace.PluginInfo{
+		{Kind: workspace.ResourcePlugin, Name: "not-a-real-package", Version: &version},
+	}, installed)
+}
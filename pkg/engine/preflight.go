@@ -0,0 +1,155 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// prepareProviders checks the credentials of each of the given default providers before planning begins, so that bad
+// credentials are reported up front instead of after some resources have already been changed, and then starts the
+// provider plugins that the operation will need.  The checks run first so that they start copies of their providers
+// of their own, rather than configuring and closing the warm copies that the provider registry would otherwise use.
+func prepareProviders(plugctx *plugin.Context, proj *workspace.Project, target *deploy.Target,
+	versions map[tokens.Package]*semver.Version) error {
+
+	if err := checkProviderAuth(plugctx, proj, target, versions); err != nil {
+		return err
+	}
+	warmProviders(plugctx, target, versions)
+	return nil
+}
+
+// checkProviderAuth loads the default provider for each of the given packages, configures it using the project's
+// provider defaults and the target's configuration, and asks it to verify its credentials.  This runs before planning
+// so that credential and permission problems are reported before any resources are touched, rather than partway
+// through an update.  All failures are collected and reported together.
+//
+// The provider registry is not created until planning begins, so each check starts, configures, and closes a copy of
+// its provider of its own; every update therefore pays for one extra start and configuration of each default provider.
+// Only default providers are checked, since the configuration of explicit providers is not known until the program
+// runs.
+func checkProviderAuth(plugctx *plugin.Context, proj *workspace.Project, target *deploy.Target,
+	versions map[tokens.Package]*semver.Version) error {
+
+	// Check packages in a stable order so that the resulting errors are deterministic.
+	var pkgs []string
+	for pkg := range versions {
+		pkgs = append(pkgs, string(pkg))
+	}
+	sort.Strings(pkgs)
+
+	// Each check must start its provider's plugin, which can be slow, so run the checks concurrently.
+	errs := make([]error, len(pkgs))
+	var wg sync.WaitGroup
+	for i, pkg := range pkgs {
+		wg.Add(1)
+		go func(i int, pkg tokens.Package) {
+			defer wg.Done()
+			errs[i] = checkPackageAuth(plugctx, proj, target, pkg, versions[pkg])
+		}(i, tokens.Package(pkg))
+	}
+	wg.Wait()
+
+	var result error
+	for _, err := range errs {
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result
+}
+
+// checkPackageAuth verifies the credentials of the default provider for a single package.
+func checkPackageAuth(plugctx *plugin.Context, proj *workspace.Project, target *deploy.Target, pkg tokens.Package,
+	version *semver.Version) error {
+
+	logging.Engine.V(7).Infof("checkPackageAuth(%s): verifying provider credentials", pkg)
+
+	inputs, err := deploy.DefaultProviderConfig(proj, target, pkg)
+	if err != nil {
+		return err
+	}
+
+	prov, err := plugctx.Host.Provider(pkg, version)
+	if _, missing := errors.Cause(err).(*plugin.MissingError); missing || (err == nil && prov == nil) {
+		// If the plugin isn't available, the plan itself will report that in due course.
+		logging.Engine.V(7).Infof("checkPackageAuth(%s): skipping missing provider plugin", pkg)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer func() { contract.IgnoreError(plugctx.Host.CloseProvider(prov)) }()
+
+	if err = prov.Configure(inputs); err == nil {
+		err = prov.CheckAuth()
+	}
+	if err != nil {
+		return errors.Errorf("the %s provider could not verify its credentials: %v\n"+
+			"    check this stack's '%s:' configuration settings and any credentials in your environment, "+
+			"then try again", pkg, err, pkg)
+	}
+	return nil
+}
+
+// warmProviders starts, concurrently, the provider plugins that an operation is about to need: one for each of the
+// given default provider packages and one for each provider in the target's snapshot, all of which the provider
+// registry loads up front.  Without this, each plugin would be started in turn as it was first used.
+func warmProviders(plugctx *plugin.Context, target *deploy.Target, versions map[tokens.Package]*semver.Version) {
+	var plugins []workspace.PluginInfo
+	for pkg, version := range versions {
+		plugins = append(plugins, workspace.PluginInfo{
+			Name:    string(pkg),
+			Kind:    workspace.ResourcePlugin,
+			Version: version,
+		})
+	}
+	if target != nil && target.Snapshot != nil {
+		for _, res := range target.Snapshot.Resources {
+			if !providers.IsProviderType(res.Type) {
+				continue
+			}
+
+			// Use the version that the provider was created with, if any; the registry will ask for the same one.
+			var version *semver.Version
+			if v, ok := res.Inputs()["version"]; ok && v.IsString() {
+				if sv, err := semver.ParseTolerant(v.StringValue()); err == nil {
+					version = &sv
+				}
+			}
+			plugins = append(plugins, workspace.PluginInfo{
+				Name:    string(res.Type.Name()),
+				Kind:    workspace.ResourcePlugin,
+				Version: version,
+			})
+		}
+	}
+
+	logging.Engine.V(7).Infof("warmProviders(): starting %d provider plugins", len(plugins))
+	plugctx.Host.WarmProviders(plugins)
+}
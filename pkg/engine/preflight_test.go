@@ -0,0 +1,133 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestCheckProviderAuth(t *testing.T) {
+	var configured resource.PropertyMap
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				ConfigureF: func(news resource.PropertyMap) error {
+					configured = news
+					return nil
+				},
+			}, nil
+		}),
+		deploytest.NewProviderLoader("pkgB", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CheckAuthF: func() error {
+					return errors.New("access denied")
+				},
+			}, nil
+		}),
+	}
+
+	host := deploytest.NewPluginHost(nil, nil, nil, loaders...)
+	plugctx, err := plugin.NewContext(nil, nil, host, nil, nil, "", nil, nil)
+	assert.NoError(t, err)
+
+	target := &deploy.Target{
+		Name: "test",
+		Config: config.Map{
+			config.MustMakeKey("pkgA", "region"): config.NewValue("us-west-2"),
+		},
+	}
+
+	// pkgA has valid credentials and should see its configuration.
+	err = checkProviderAuth(plugctx, nil, target, map[tokens.Package]*semver.Version{"pkgA": nil})
+	assert.NoError(t, err)
+	assert.Equal(t, resource.NewStringProperty("us-west-2"), configured["region"])
+
+	// pkgB should report a failure that names the provider.
+	err = checkProviderAuth(plugctx, nil, target, map[tokens.Package]*semver.Version{"pkgA": nil, "pkgB": nil})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "the pkgB provider could not verify its credentials: access denied")
+	assert.NotContains(t, err.Error(), "pkgA provider")
+}
+
+// missingPluginHost is a plugin host that, like the default host, reports that no provider plugins are installed.
+type missingPluginHost struct {
+	plugin.Host
+}
+
+func (host *missingPluginHost) Provider(pkg tokens.Package, version *semver.Version) (plugin.Provider, error) {
+	return nil, plugin.NewMissingError(workspace.PluginInfo{Kind: workspace.ResourcePlugin, Name: string(pkg)})
+}
+
+func TestCheckProviderAuthMissingPlugin(t *testing.T) {
+	host := &missingPluginHost{Host: deploytest.NewPluginHost(nil, nil, nil)}
+	plugctx, err := plugin.NewContext(nil, nil, host, nil, nil, "", nil, nil)
+	assert.NoError(t, err)
+
+	// The plan reports missing plugins itself, so the check leaves them be.
+	err = checkProviderAuth(plugctx, nil, &deploy.Target{Name: "test"}, map[tokens.Package]*semver.Version{"pkgA": nil})
+	assert.NoError(t, err)
+}
+
+// warmRecordingHost is a plugin host that records the order in which providers are loaded, closed, and warmed.
+type warmRecordingHost struct {
+	plugin.Host
+	calls []string
+}
+
+func (host *warmRecordingHost) Provider(pkg tokens.Package, version *semver.Version) (plugin.Provider, error) {
+	host.calls = append(host.calls, "provider "+string(pkg))
+	return host.Host.Provider(pkg, version)
+}
+
+func (host *warmRecordingHost) CloseProvider(provider plugin.Provider) error {
+	host.calls = append(host.calls, "close "+string(provider.Pkg()))
+	return host.Host.CloseProvider(provider)
+}
+
+func (host *warmRecordingHost) WarmProviders(plugins []workspace.PluginInfo) {
+	for _, plug := range plugins {
+		host.calls = append(host.calls, "warm "+plug.Name)
+	}
+	host.Host.WarmProviders(plugins)
+}
+
+func TestPrepareProvidersChecksBeforeWarming(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{Package: "pkgA"}, nil
+		}),
+	}
+	host := &warmRecordingHost{Host: deploytest.NewPluginHost(nil, nil, nil, loaders...)}
+	plugctx, err := plugin.NewContext(nil, nil, host, nil, nil, "", nil, nil)
+	assert.NoError(t, err)
+
+	// The credential check must close its own copy of the provider before any are warmed, so that it never closes
+	// the copies that the provider registry is about to use.
+	err = prepareProviders(plugctx, nil, &deploy.Target{Name: "test"}, map[tokens.Package]*semver.Version{"pkgA": nil})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"provider pkgA", "close pkgA", "warm pkgA"}, host.calls)
+}
@@ -0,0 +1,36 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// getProviderDefaults returns, by resource type, the input properties whose values providers fill in when a program
+// does not give them, as described by the cached schemas of the providers that the update's stack last used.
+func getProviderDefaults(u UpdateInfo) map[tokens.Type][]resource.PropertyKey {
+	defaults := make(map[tokens.Type][]resource.PropertyKey)
+	for _, pkg := range getCachedSchemas(u.GetTarget()) {
+		for tok, names := range pkg.ProviderDefaults() {
+			keys := make([]resource.PropertyKey, len(names))
+			for i, name := range names {
+				keys[i] = resource.PropertyKey(name)
+			}
+			defaults[tokens.Type(tok)] = keys
+		}
+	}
+	return defaults
+}
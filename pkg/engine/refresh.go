@@ -33,7 +33,7 @@ func Refresh(u UpdateInfo, ctx *Context, opts UpdateOptions, dryRun bool) (Resou
 	}
 	defer info.Close()
 
-	emitter, err := makeEventEmitter(ctx.Events, u)
+	emitter, err := makeEventEmitter(ctx.Events, u, opts.ShowSecrets)
 	if err != nil {
 		return nil, err
 	}
@@ -51,9 +51,12 @@ func Refresh(u UpdateInfo, ctx *Context, opts UpdateOptions, dryRun bool) (Resou
 	}, dryRun)
 }
 
-func newRefreshSource(opts planOptions, proj *workspace.Project, pwd, main string,
+func newRefreshSource(opts planOptions, proj *workspace.Project, root, pwd, main string,
 	target *deploy.Target, plugctx *plugin.Context, dryRun bool) (deploy.Source, error) {
 
+	// Start the plugins for the snapshot's providers concurrently, since each of them will be needed.
+	warmProviders(plugctx, target, nil)
+
 	// Just return an error source. Refresh doesn't use its source.
 	return deploy.NewErrorSource(proj.Name), nil
 }
@@ -0,0 +1,203 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// copyReadOnly copies the directory at root into a new temporary directory, whose path it returns, and makes the
+// copy's files and directories read-only, so that a program that runs from the copy cannot change its own files, nor
+// those of the original.  Symbolic links are copied so as to point within the copy; it is an error for a link to
+// point outside of the directory, since the copy would leave its target writable.  Links that point nowhere, and other
+// special files, are left out.
+func copyReadOnly(root string) (string, error) {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", errors.Wrapf(err, "copying %s", root)
+	}
+	dir, err := ioutil.TempDir("", "pulumi-program")
+	if err != nil {
+		return "", err
+	}
+
+	// Directories are made read-only only once they have been filled, deepest first.
+	var dirs []string
+	var modes []os.FileMode
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dir, rel)
+
+		switch {
+		case info.IsDir():
+			if rel != "." {
+				if err = os.Mkdir(dst, 0700); err != nil {
+					return err
+				}
+			}
+			dirs, modes = append(dirs, dst), append(modes, info.Mode().Perm())
+			return nil
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := copiedLinkTarget(realRoot, rel, path)
+			if err != nil || target == "" {
+				return err
+			}
+			return os.Symlink(target, dst)
+		case info.Mode().IsRegular():
+			return copyFileReadOnly(dst, path, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+	for i := len(dirs) - 1; err == nil && i >= 0; i-- {
+		err = os.Chmod(dirs[i], modes[i]&^0222)
+	}
+	if err != nil {
+		contract.IgnoreError(removeReadOnlyCopy(dir))
+		return "", errors.Wrapf(err, "copying %s", root)
+	}
+	return dir, nil
+}
+
+// copiedLinkTarget returns the target that the copy of the symbolic link at path, which is at rel within the directory
+// being copied, should have: the relative path from the link to the file or directory within the copy that the
+// original link resolves to.  Links that resolve to nothing are given no target, and those that resolve to anything
+// outside of the directory, whose real path is root, are rejected.
+func copiedLinkTarget(root, rel, path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	within, err := filepath.Rel(root, resolved)
+	if err != nil || within == ".." || strings.HasPrefix(within, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("symbolic link %s points outside of the program's directory", path)
+	}
+	return filepath.Rel(filepath.Dir(rel), within)
+}
+
+// copyFileReadOnly copies the file at src to dst, giving the copy the given permissions without write access.
+func copyFileReadOnly(dst, src string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer contract.IgnoreClose(in)
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm&^0222)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		contract.IgnoreClose(out)
+		return err
+	}
+	return out.Close()
+}
+
+// removeReadOnlyCopy removes a copy made by copyReadOnly, restoring write access to its directories so that their
+// contents may be removed.
+func removeReadOnlyCopy(dir string) error {
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		return os.Chmod(path, 0700)
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// networkRefuser is a proxy on this machine's loopback interface that refuses every request made through it.  It is a
+// best-effort hint rather than a sandbox: only clients that honor the proxy environment variables use it, and nothing
+// stops a program from ignoring them, or from using DNS or raw sockets, to reach the network.
+type networkRefuser struct {
+	listener net.Listener
+	done     chan error
+}
+
+// startNetworkRefuser starts a proxy that refuses every request made through it.
+func startNetworkRefuser() (*networkRefuser, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, errors.Wrap(err, "starting the proxy that refuses the program's HTTP requests")
+	}
+	r := &networkRefuser{listener: listener, done: make(chan error, 1)}
+	go func() {
+		r.done <- http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			http.Error(w, "this program may not use the network while it is previewed", http.StatusForbidden)
+		}))
+	}()
+	return r, nil
+}
+
+// EnvVars returns the environment variables that direct a program's HTTP and HTTPS requests to the proxy.  Requests to
+// this machine are left alone, since the program reaches the engine over them.
+func (r *networkRefuser) EnvVars() map[string]string {
+	proxy := "http://" + r.listener.Addr().String()
+	noProxy := "localhost,127.0.0.1,::1"
+	return map[string]string{
+		"HTTP_PROXY":  proxy,
+		"HTTPS_PROXY": proxy,
+		"ALL_PROXY":   proxy,
+		"NO_PROXY":    noProxy,
+		"http_proxy":  proxy,
+		"https_proxy": proxy,
+		"all_proxy":   proxy,
+		"no_proxy":    noProxy,
+	}
+}
+
+// Close stops the proxy.
+func (r *networkRefuser) Close() error {
+	err := r.listener.Close()
+	<-r.done
+	return err
+}
+
+// closeSandbox removes the program's read-only copy and stops the proxy that refuses its HTTP requests, either of which
+// may be absent.
+func closeSandbox(programCopy string, refuser *networkRefuser) error {
+	var err error
+	if refuser != nil {
+		err = refuser.Close()
+	}
+	if programCopy != "" {
+		if rmerr := removeReadOnlyCopy(programCopy); err == nil {
+			err = rmerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyReadOnly(t *testing.T) {
+	root, err := ioutil.TempDir("", "pulumi-sandbox-test")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() { assert.NoError(t, os.RemoveAll(root)) }()
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "lib", "nested"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "Pulumi.yaml"), []byte("name: test\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "lib", "nested", "index.js"), []byte("42"), 0755))
+	assert.NoError(t, os.Symlink(filepath.Join("lib", "nested", "index.js"), filepath.Join(root, "index.js")))
+	assert.NoError(t, os.Symlink(filepath.Join(root, "Pulumi.yaml"), filepath.Join(root, "lib", "project.yaml")))
+	assert.NoError(t, os.Symlink("missing.js", filepath.Join(root, "dangling.js")))
+
+	dir, err := copyReadOnly(root)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The copy's contents match the original's.
+	b, err := ioutil.ReadFile(filepath.Join(dir, "Pulumi.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "name: test\n", string(b))
+	b, err = ioutil.ReadFile(filepath.Join(dir, "index.js"))
+	assert.NoError(t, err)
+	assert.Equal(t, "42", string(b))
+	target, err := os.Readlink(filepath.Join(dir, "index.js"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("lib", "nested", "index.js"), target)
+
+	// Links are made to point within the copy, rather than at the original's files, and links to nothing are left out.
+	target, err = os.Readlink(filepath.Join(dir, "lib", "project.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("..", "Pulumi.yaml"), target)
+	_, err = os.Lstat(filepath.Join(dir, "dangling.js"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Nothing in the copy is writable, though the rest of each file's permissions are kept.
+	for _, path := range []string{".", "lib", filepath.Join("lib", "nested"), "Pulumi.yaml"} {
+		info, err := os.Stat(filepath.Join(dir, path))
+		if assert.NoError(t, err) {
+			assert.Zero(t, info.Mode().Perm()&0222, path)
+		}
+	}
+	info, err := os.Stat(filepath.Join(dir, "lib", "nested", "index.js"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0555), info.Mode().Perm())
+	}
+	if os.Geteuid() != 0 {
+		assert.Error(t, ioutil.WriteFile(filepath.Join(dir, "Pulumi.yaml"), []byte("name: other\n"), 0644))
+		assert.Error(t, ioutil.WriteFile(filepath.Join(dir, "lib", "new.js"), []byte("0"), 0644))
+	}
+
+	// The original is left alone.
+	info, err = os.Stat(filepath.Join(root, "Pulumi.yaml"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+	}
+
+	assert.NoError(t, removeReadOnlyCopy(dir))
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	// Links that point outside of the directory cannot be copied without leaving their targets writable.
+	outside, err := ioutil.TempDir("", "pulumi-sandbox-test")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() { assert.NoError(t, os.RemoveAll(outside)) }()
+	assert.NoError(t, os.Symlink(outside, filepath.Join(root, "outside")))
+	_, err = copyReadOnly(root)
+	assert.Error(t, err)
+}
+
+func TestNetworkRefuser(t *testing.T) {
+	refuser, err := startNetworkRefuser()
+	if !assert.NoError(t, err) {
+		return
+	}
+	env := refuser.EnvVars()
+	assert.Equal(t, env["HTTP_PROXY"], env["https_proxy"])
+	assert.Equal(t, "localhost,127.0.0.1,::1", env["NO_PROXY"])
+
+	// Requests made through the proxy are refused, without ever leaving this machine.
+	proxy, err := url.Parse(env["HTTPS_PROXY"])
+	if !assert.NoError(t, err) {
+		return
+	}
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxy), DisableKeepAlives: true}}
+	resp, err := client.Get("http://example.invalid/")
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.NoError(t, resp.Body.Close())
+	}
+	_, err = client.Get("https://example.invalid/")
+	assert.Error(t, err)
+
+	assert.NoError(t, refuser.Close())
+	_, err = client.Get("http://example.invalid/")
+	assert.Error(t, err)
+}
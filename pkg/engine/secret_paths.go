@@ -0,0 +1,83 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/codegen/schema"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// redactedSecretValue replaces the values of secrets in the resource properties of events.
+var redactedSecretValue = resource.NewStringProperty("[secret]")
+
+// GetSecretPaths returns the rules that mark the values of resource properties as secret for an update of the given
+// stack: those that the project and the stack give, and those that the schemas of the providers the stack last used
+// give.  Only schemas that have been cached, e.g. by `pulumi package get-schema`, are consulted, so that providers
+// need not be loaded to find them.
+func GetSecretPaths(u UpdateInfo) (resource.SecretPathRules, error) {
+	var paths []string
+	if proj := u.GetProject(); proj != nil {
+		paths = append(paths, proj.SecretPaths...)
+	}
+	target := u.GetTarget()
+	if target != nil {
+		paths = append(paths, target.SecretPaths...)
+	}
+	rules, err := resource.ParseSecretPathRules(paths)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid secret paths")
+	}
+
+	for _, pkg := range getCachedSchemas(target) {
+		providerRules, err := resource.ParseSecretPathRules(pkg.SecretPaths())
+		if err != nil {
+			logging.Engine.V(7).Infof("GetSecretPaths(): ignoring the secret paths of %s: %v", pkg.Name, err)
+			continue
+		}
+		rules = append(rules, providerRules...)
+	}
+	return rules, nil
+}
+
+// getCachedSchemas returns the cached schemas of the resource providers that the target's stack last used.  Providers
+// whose schemas have not been cached, or whose cached schemas are invalid, are skipped.
+func getCachedSchemas(target *deploy.Target) []*schema.Package {
+	if target == nil || target.Snapshot == nil {
+		return nil
+	}
+
+	var pkgs []*schema.Package
+	for _, info := range target.Snapshot.Manifest.Plugins {
+		if info.Kind != workspace.ResourcePlugin || info.Version == nil {
+			continue
+		}
+		bytes, err := workspace.GetCachedSchema(info)
+		if err != nil || bytes == nil {
+			continue
+		}
+		pkg, err := schema.Parse(bytes)
+		if err != nil {
+			logging.Engine.V(7).Infof("getCachedSchemas(): ignoring the invalid schema of %s: %v", info, err)
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs
+}
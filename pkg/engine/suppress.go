@@ -0,0 +1,69 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// warningSuppressions returns the suppressions of warnings that the project and the stack being updated give.
+func warningSuppressions(proj *workspace.Project, target *deploy.Target) ([]diag.Suppression, error) {
+	var settings []workspace.WarningSuppression
+	if proj != nil {
+		settings = append(settings, proj.SuppressWarnings...)
+	}
+	if target != nil {
+		settings = append(settings, target.SuppressWarnings...)
+	}
+
+	var result []diag.Suppression
+	for _, s := range settings {
+		if err := s.Validate(); err != nil {
+			return nil, err
+		}
+
+		var id diag.ID
+		if s.Code != "" {
+			var err error
+			if id, err = diag.ParseCode(s.Code); err != nil {
+				return nil, errors.Wrap(err, "invalid warning suppression")
+			}
+		}
+		result = append(result, diag.Suppression{
+			ID:       id,
+			URN:      s.URN,
+			Provider: tokens.Package(s.Provider),
+			Reason:   s.Reason,
+		})
+	}
+	return result, nil
+}
+
+// reportSuppressedWarnings reports how many warnings each suppression silenced, along with its reason, so that what
+// was silenced can be audited.
+func reportSuppressedWarnings(sink *diag.SuppressingSink) {
+	for s, count := range sink.Suppressed() {
+		noun := "warnings"
+		if count == 1 {
+			noun = "warning"
+		}
+		sink.Infof(diag.Message("", "suppressed %d %s matching %s: %s"), count, noun, s, s.Reason)
+	}
+}
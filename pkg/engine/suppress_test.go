@@ -0,0 +1,82 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestWarningSuppressions(t *testing.T) {
+	t.Parallel()
+
+	proj := &workspace.Project{SuppressWarnings: []workspace.WarningSuppression{
+		{Code: "PU2004", Reason: "tracked elsewhere"},
+	}}
+	target := &deploy.Target{SuppressWarnings: []workspace.WarningSuppression{
+		{Provider: "aws", URN: "*Bucket*", Reason: "noisy"},
+	}}
+	suppressions, err := warningSuppressions(proj, target)
+	assert.NoError(t, err)
+	assert.Equal(t, []diag.Suppression{
+		{ID: diag.AnalyzeResourceFailureID, Reason: "tracked elsewhere"},
+		{Provider: "aws", URN: "*Bucket*", Reason: "noisy"},
+	}, suppressions)
+
+	// Suppressions must say what they match and why.
+	for _, s := range []workspace.WarningSuppression{
+		{Reason: "everything"},
+		{Code: "PU2004"},
+		{Code: "bogus", Reason: "bad code"},
+	} {
+		_, err = warningSuppressions(&workspace.Project{SuppressWarnings: []workspace.WarningSuppression{s}}, nil)
+		assert.Error(t, err)
+	}
+}
+
+func TestSuppressEngineWarning(t *testing.T) {
+	t.Parallel()
+
+	proj := &workspace.Project{
+		Name: "test",
+		Providers: map[string]*workspace.ProviderDefaults{
+			"pkgA": {Version: "1.0.0"},
+		},
+		SuppressWarnings: []workspace.WarningSuppression{
+			{Code: diag.ProviderVersionOverriddenID.Code(), Reason: "pinned until the upgrade is tested"},
+		},
+	}
+	suppressions, err := warningSuppressions(proj, nil)
+	assert.NoError(t, err)
+
+	// The project's pin of an older version than the program requires would otherwise be warned about.
+	v := semver.MustParse("1.2.0")
+	plugins := []workspace.PluginInfo{{Kind: workspace.ResourcePlugin, Name: "pkgA", Version: &v}}
+	var stderr bytes.Buffer
+	sink := diag.NewSuppressingSink(
+		diag.DefaultSink(&bytes.Buffer{}, &stderr, diag.FormatOptions{Color: colors.Never}), suppressions)
+	_, err = resolveProviderVersions(plugins, proj, sink)
+	assert.NoError(t, err)
+	assert.Empty(t, stderr.String())
+	assert.Equal(t, map[diag.Suppression]int{suppressions[0]: 1}, sink.Suppressed())
+}
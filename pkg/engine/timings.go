@@ -0,0 +1,45 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+// StepTiming records how long a single resource operation took to apply during an update.
+type StepTiming struct {
+	URN      resource.URN  `json:"urn"`              // the resource that the operation applied to.
+	Op       deploy.StepOp `json:"op"`               // the kind of operation.
+	Duration time.Duration `json:"duration"`         // how long the operation took, in nanoseconds.
+	Failed   bool          `json:"failed,omitempty"` // true if the operation failed.
+}
+
+// SlowestSteps returns up to n of the given timings, slowest first.  The timings themselves are left untouched.
+func SlowestSteps(timings []StepTiming, n int) []StepTiming {
+	slowest := make([]StepTiming, len(timings))
+	copy(slowest, timings)
+	sort.SliceStable(slowest, func(i, j int) bool {
+		return slowest[i].Duration > slowest[j].Duration
+	})
+
+	if n >= 0 && len(slowest) > n {
+		slowest = slowest[:n]
+	}
+	return slowest
+}
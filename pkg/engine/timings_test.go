@@ -0,0 +1,47 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestSlowestSteps(t *testing.T) {
+	t.Parallel()
+
+	timings := []StepTiming{
+		{URN: "urn:pulumi:s::p::t::a", Op: deploy.OpCreate, Duration: time.Second},
+		{URN: "urn:pulumi:s::p::t::b", Op: deploy.OpUpdate, Duration: 3 * time.Second},
+		{URN: "urn:pulumi:s::p::t::c", Op: deploy.OpDelete, Duration: 2 * time.Second, Failed: true},
+		{URN: "urn:pulumi:s::p::t::d", Op: deploy.OpCreate, Duration: 2 * time.Second},
+	}
+
+	slowest := SlowestSteps(timings, 3)
+	assert.Len(t, slowest, 3)
+	assert.Equal(t, "urn:pulumi:s::p::t::b", string(slowest[0].URN))
+	assert.Equal(t, "urn:pulumi:s::p::t::c", string(slowest[1].URN))
+	assert.Equal(t, "urn:pulumi:s::p::t::d", string(slowest[2].URN))
+
+	// The input should be left in its original order.
+	assert.Equal(t, "urn:pulumi:s::p::t::a", string(timings[0].URN))
+
+	assert.Len(t, SlowestSteps(timings, 10), 4)
+	assert.Len(t, SlowestSteps(nil, 10), 0)
+}
@@ -15,10 +15,13 @@
 package engine
 
 import (
+	"path"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/blang/semver"
+	"github.com/pkg/errors"
 
 	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/resource"
@@ -41,14 +44,84 @@ type UpdateOptions struct {
 	// true if debugging output it enabled
 	Debug bool
 
+	// true if the local display of resource properties should include the values of secrets rather than redacting them.
+	ShowSecrets bool
+
 	// true if the plan should refresh before executing.
 	Refresh bool
 
+	// the degree of parallelism for refreshes (<=0 to use Parallel).
+	RefreshParallel int
+
+	// the rate limits for the reads performed by refreshes, keyed by provider package.
+	RefreshRateLimits map[tokens.Package]deploy.RateLimit
+
+	// true if refreshes should only read the resources that may have drifted: those whose types match one of
+	// RefreshDriftProneTypes and, if RefreshSince is set, those that their providers cannot report as unmodified.
+	RefreshChangedOnly bool
+
+	// patterns of the resource types that a changed-only refresh always reads, in which `*` matches anything.
+	RefreshDriftProneTypes []string
+
+	// the time since which a changed-only refresh asks providers whether resources were modified, if any.
+	RefreshSince time.Time
+
+	// if non-empty, the only resources that a destroy deletes, along with those that depend upon or descend from them.
+	DeleteTargets []resource.URN
+
+	// the version of the stack that the update will produce, if known, which is recorded on the resources it changes.
+	UpdateVersion int
+
+	// if non-empty, the seed from which the random seeds of the resources that the update creates or replaces are
+	// derived, so that e.g. auto-generated names are the same each time the update runs.  Otherwise they are random.
+	RandomSeed []byte
+
+	// true to have providers check and diff every resource's inputs, rather than skipping those resources whose inputs
+	// have not changed since they were last checked.
+	StrictDiff bool
+
+	// true to diff every input property of resources, rather than ignoring those whose values the resources' providers
+	// fill in when programs do not give them (as described by the providers' cached schemas).
+	ShowFullDiff bool
+
+	// patterns of the names of stack outputs that a preview fails if it finds would change, in which `*` matches
+	// anything.  Outputs whose new values are unknown during the preview are counted as changing.
+	ExpectNoOutputChanges []string
+
+	// if non-nil, called with the names of the stack outputs that a preview finds may change, or that an update
+	// changed, once it completes.
+	OnOutputChanges func(outputs []string)
+
+	// if non-nil, called with how long each of the resource operations that an update applied took, once it completes.
+	OnStepTimings func(timings []StepTiming)
+
+	// if non-nil, called with the resource plugins that the program requires but that are not installed, before the
+	// program runs, to install them.  If nil, the update fails up front instead.
+	InstallPlugins func(plugins []workspace.PluginInfo) error
+
 	// true if we should report events for steps that involve default providers.
 	reportDefaultProviderSteps bool
 
-	// the plugin host to use for this update
-	host plugin.Host
+	// the git tags on the commit being deployed, which a stack's guard may require to include an approval tag.
+	SourceTags []string
+
+	// if non-empty, the reason for overriding the stack's guard, which is then not enforced.
+	GuardOverrideReason string
+
+	// an optional callback that is given each step other than a same step before it is applied, and decides whether
+	// the step is applied, skipped, or the update aborted.  It is not called during previews.
+	StepHook func(step StepEventMetadata) deploy.StepAction
+
+	// an optional decider that may override the engine's choice of steps, e.g. to replace rather than update a
+	// resource, or to deny a deletion.
+	StepDecider deploy.StepDecider
+
+	// options that control how resource providers are run, e.g. to record or replay the requests made of them.
+	ProviderOptions plugin.ProviderOptions
+
+	// the plugin host to use for this update, or nil to load plugins from the workspace.  This is primarily useful
+	// for running the engine against in-memory providers and programs in tests; see the enginetest package.
+	Host plugin.Host
 }
 
 // ResourceChanges contains the aggregate resource changes by operation type.
@@ -65,6 +138,44 @@ func (changes ResourceChanges) HasChanges() bool {
 	return c > 0
 }
 
+// StackOutputChanges returns the sorted names of the stack outputs that differ between the old and new outputs of a
+// stack's root resource: those that were added, deleted, or updated, along with those whose new values are unknown.
+func StackOutputChanges(olds, news resource.PropertyMap) []string {
+	var changed []string
+	for k, old := range olds {
+		if new, has := news[k]; !has || new.IsComputed() || new.IsOutput() || !old.DeepEquals(new) {
+			changed = append(changed, string(k))
+		}
+	}
+	for k := range news {
+		if _, has := olds[k]; !has {
+			changed = append(changed, string(k))
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// checkOutputChanges returns an error if any of the changed stack outputs match one of the given patterns.
+func checkOutputChanges(patterns []string, changed []string) error {
+	var unexpected []string
+	for _, name := range changed {
+		for _, pattern := range patterns {
+			if matched, err := path.Match(pattern, name); err != nil {
+				return errors.Wrapf(err, "invalid output pattern '%s'", pattern)
+			} else if matched {
+				unexpected = append(unexpected, name)
+				break
+			}
+		}
+	}
+	if len(unexpected) == 0 {
+		return nil
+	}
+	return errors.Errorf("no changes were expected to these stack outputs but they would change: %s",
+		strings.Join(unexpected, ", "))
+}
+
 func Update(u UpdateInfo, ctx *Context, opts UpdateOptions, dryRun bool) (ResourceChanges, error) {
 	contract.Require(u != nil, "update")
 	contract.Require(ctx != nil, "ctx")
@@ -77,7 +188,7 @@ func Update(u UpdateInfo, ctx *Context, opts UpdateOptions, dryRun bool) (Resour
 	}
 	defer info.Close()
 
-	emitter, err := makeEventEmitter(ctx.Events, u)
+	emitter, err := makeEventEmitter(ctx.Events, u, opts.ShowSecrets)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +202,7 @@ func Update(u UpdateInfo, ctx *Context, opts UpdateOptions, dryRun bool) (Resour
 }
 
 func newUpdateSource(
-	opts planOptions, proj *workspace.Project, pwd, main string,
+	opts planOptions, proj *workspace.Project, root, pwd, main string,
 	target *deploy.Target, plugctx *plugin.Context, dryRun bool) (deploy.Source, error) {
 
 	// Figure out which plugins to load by inspecting the program contents.
@@ -110,19 +221,29 @@ func newUpdateSource(
 		return nil, err
 	}
 
-	// Collect the version information for default providers.
-	defaultProviderVersions := make(map[tokens.Package]*semver.Version)
-	for _, p := range plugins {
-		if p.Kind != workspace.ResourcePlugin {
-			continue
+	// Resolve the versions of the default providers' plugins, and make sure that each of them is installed before the
+	// program runs, rather than failing partway through the deployment when its first resource is registered.
+	defaultProviderVersions, err := resolveProviderVersions(plugins, proj, opts.Diag)
+	if err != nil {
+		return nil, err
+	}
+	// Replayed providers don't need their plugins.
+	if plugctx.ProviderOptions.ReplayDir == "" {
+		if err = ensureProviderPlugins(defaultProviderVersions, opts.InstallPlugins, opts.Diag); err != nil {
+			return nil, err
 		}
-		defaultProviderVersions[tokens.Package(p.Name)] = p.Version
+	}
+
+	// Make sure each default provider can authenticate, then start the provider plugins that we know we will need.
+	if err = prepareProviders(plugctx, proj, target, defaultProviderVersions); err != nil {
+		return nil, err
 	}
 
 	// If that succeeded, create a new source that will perform interpretation of the compiled program.
 	// TODO[pulumi/pulumi#88]: we are passing `nil` as the arguments map; we need to allow a way to pass these.
 	return deploy.NewEvalSource(plugctx, &deploy.EvalRunInfo{
 		Proj:    proj,
+		Root:    root,
 		Pwd:     pwd,
 		Program: main,
 		Target:  target,
@@ -130,6 +251,24 @@ func newUpdateSource(
 }
 
 func update(ctx *Context, info *planContext, opts planOptions, dryRun bool) (ResourceChanges, error) {
+	// Previews are always allowed, but any operation that changes the stack must satisfy its guard.
+	if !dryRun {
+		if err := checkGuard(info.Update.GetTarget().Guard, opts, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Warnings that the project or stack suppresses are only counted, and the counts reported once we're done.
+	suppressions, err := warningSuppressions(info.Update.GetProject(), info.Update.GetTarget())
+	if err != nil {
+		return nil, err
+	}
+	if len(suppressions) > 0 {
+		sink := diag.NewSuppressingSink(opts.Diag, suppressions)
+		defer reportSuppressedWarnings(sink)
+		opts.Diag = sink
+	}
+
 	result, err := plan(ctx, info, opts, dryRun)
 	if err != nil {
 		return nil, err
@@ -159,10 +298,18 @@ func update(ctx *Context, info *planContext, opts planOptions, dryRun bool) (Res
 
 			err = result.Walk(ctx, actions, false)
 			resourceChanges = ResourceChanges(actions.Ops)
+			refreshChanges := ResourceChanges(actions.RefreshOps)
 
-			if len(resourceChanges) != 0 {
+			if len(resourceChanges) != 0 || len(refreshChanges) != 0 {
 				// Print out the total number of steps performed (and their kinds), the duration, and any summary info.
-				opts.Events.updateSummaryEvent(actions.MaybeCorrupt, time.Since(start), resourceChanges)
+				opts.Events.updateSummaryEvent(actions.MaybeCorrupt, time.Since(start),
+					resourceChanges, refreshChanges, actions.Outputs, actions.Timings)
+			}
+			if opts.OnOutputChanges != nil {
+				opts.OnOutputChanges(actions.Outputs)
+			}
+			if opts.OnStepTimings != nil {
+				opts.OnStepTimings(actions.Timings)
 			}
 		}
 	}
@@ -184,7 +331,11 @@ type updateActions struct {
 	Context      *Context
 	Steps        int
 	Ops          map[deploy.StepOp]int
+	RefreshOps   map[deploy.StepOp]int
+	Outputs      []string
 	Seen         map[resource.URN]deploy.Step
+	Starts       map[deploy.Step]time.Time
+	Timings      []StepTiming
 	MapLock      sync.Mutex
 	MaybeCorrupt bool
 	Update       UpdateInfo
@@ -193,11 +344,13 @@ type updateActions struct {
 
 func newUpdateActions(context *Context, u UpdateInfo, opts planOptions) *updateActions {
 	return &updateActions{
-		Context: context,
-		Ops:     make(map[deploy.StepOp]int),
-		Seen:    make(map[resource.URN]deploy.Step),
-		Update:  u,
-		Opts:    opts,
+		Context:    context,
+		Ops:        make(map[deploy.StepOp]int),
+		RefreshOps: make(map[deploy.StepOp]int),
+		Seen:       make(map[resource.URN]deploy.Step),
+		Starts:     make(map[deploy.Step]time.Time),
+		Update:     u,
+		Opts:       opts,
 	}
 }
 
@@ -205,6 +358,7 @@ func (acts *updateActions) OnResourceStepPre(step deploy.Step) (interface{}, err
 	// Ensure we've marked this step as observed.
 	acts.MapLock.Lock()
 	acts.Seen[step.URN()] = step
+	acts.Starts[step] = time.Now()
 	acts.MapLock.Unlock()
 
 	// Check for a default provider step and skip reporting if necessary.
@@ -220,6 +374,8 @@ func (acts *updateActions) OnResourceStepPost(ctx interface{},
 	step deploy.Step, status resource.Status, err error) error {
 	acts.MapLock.Lock()
 	assertSeen(acts.Seen, step)
+	start := acts.Starts[step]
+	delete(acts.Starts, step)
 	acts.MapLock.Unlock()
 
 	// If we've already been terminated, exit without writing the checkpoint. We explicitly want to leave the
@@ -230,6 +386,18 @@ func (acts *updateActions) OnResourceStepPost(ctx interface{},
 
 	reportStep := acts.Opts.reportDefaultProviderSteps || !isDefaultProviderStep(step)
 
+	// Record how long the step took, so that the operations that dominate the update can be identified.
+	if reportStep && step.Op() != deploy.OpSame {
+		acts.MapLock.Lock()
+		acts.Timings = append(acts.Timings, StepTiming{
+			URN:      step.URN(),
+			Op:       step.Op(),
+			Duration: time.Since(start),
+			Failed:   err != nil,
+		})
+		acts.MapLock.Unlock()
+	}
+
 	// Report the result of the step.
 	if err != nil {
 		if status == resource.StatusUnknown {
@@ -241,8 +409,12 @@ func (acts *updateActions) OnResourceStepPost(ctx interface{},
 			errorURN = step.URN()
 		}
 
-		// Issue a true, bonafide error.
-		acts.Opts.Diag.Errorf(diag.GetPlanApplyFailedError(errorURN), err)
+		// Issue a true, bonafide error, with the code of the error's own class if it has one.
+		d := diag.GetPlanApplyFailedError(errorURN)
+		if id, ok := diag.ErrorID(err); ok {
+			d.ID = id
+		}
+		acts.Opts.Diag.Errorf(d, err)
 		if reportStep {
 			acts.Opts.Events.resourceOperationFailedEvent(step, status, acts.Steps, acts.Opts.Debug)
 		}
@@ -260,6 +432,11 @@ func (acts *updateActions) OnResourceStepPost(ctx interface{},
 			acts.Ops[op]++
 			acts.MapLock.Unlock()
 		}
+		if refreshOp, changed := refreshResult(acts.Opts, step); changed {
+			acts.MapLock.Lock()
+			acts.RefreshOps[refreshOp]++
+			acts.MapLock.Unlock()
+		}
 
 		// Also show outputs here for custom resources, since there might be some from the initial registration. We do
 		// not show outputs for component resources at this point: any that exist must be from a previous execution of
@@ -278,6 +455,9 @@ func (acts *updateActions) OnResourceStepPost(ctx interface{},
 func (acts *updateActions) OnResourceOutputs(step deploy.Step) error {
 	acts.MapLock.Lock()
 	assertSeen(acts.Seen, step)
+	if step.URN().Type() == resource.RootStackType {
+		acts.Outputs = stackOutputChanges(step)
+	}
 	acts.MapLock.Unlock()
 
 	// Check for a default provider step and skip reporting if necessary.
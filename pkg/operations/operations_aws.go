@@ -96,7 +96,7 @@ func (ops *awsOpsProvider) GetLogs(query LogQuery) (*[]LogEntry, error) {
 	logging.V(6).Infof("GetLogs[%v]", state.URN)
 	switch state.Type {
 	case awsFunctionType:
-		functionName := state.Outputs["name"].StringValue()
+		functionName := state.Outputs()["name"].StringValue()
 		logResult := ops.awsConnection.getLogsForLogGroupsConcurrently(
 			[]string{functionName},
 			[]string{"/aws/lambda/" + functionName},
@@ -107,7 +107,7 @@ func (ops *awsOpsProvider) GetLogs(query LogQuery) (*[]LogEntry, error) {
 		logging.V(5).Infof("GetLogs[%v] return %d logs", state.URN, len(logResult))
 		return &logResult, nil
 	case awsLogGroupType:
-		name := state.Outputs["name"].StringValue()
+		name := state.Outputs()["name"].StringValue()
 		logResult := ops.awsConnection.getLogsForLogGroupsConcurrently(
 			[]string{name},
 			[]string{name},
@@ -49,8 +49,8 @@ func TestTodo(t *testing.T) {
 	if !assert.NotNil(t, table) {
 		return
 	}
-	assert.Equal(t, 2, len(table.State.Inputs))
-	assert.Equal(t, "id", table.State.Inputs["primaryKey"].StringValue())
+	assert.Equal(t, 2, len(table.State.Inputs()))
+	assert.Equal(t, "id", table.State.Inputs()["primaryKey"].StringValue())
 	assert.Equal(t, 1, len(table.Children))
 	table, ok = table.GetChild("aws:dynamodb/table:Table", "todo")
 	assert.True(t, ok)
@@ -62,9 +62,9 @@ func TestTodo(t *testing.T) {
 	if !assert.NotNil(t, endpoint) {
 		return
 	}
-	assert.Equal(t, 5, len(endpoint.State.Inputs))
+	assert.Equal(t, 5, len(endpoint.State.Inputs()))
 	assert.Equal(t,
-		"https://eupwl7wu4i.execute-api.us-east-2.amazonaws.com/", endpoint.State.Inputs["url"].StringValue())
+		"https://eupwl7wu4i.execute-api.us-east-2.amazonaws.com/", endpoint.State.Inputs()["url"].StringValue())
 	assert.Equal(t, 14, len(endpoint.Children))
 	endpoint, ok = endpoint.GetChild("aws:apigateway/restApi:RestApi", "todo")
 	assert.True(t, ok)
@@ -86,7 +86,7 @@ func TestCrawler(t *testing.T) {
 	if !assert.NotNil(t, topic) {
 		return
 	}
-	assert.Equal(t, 0, len(topic.State.Inputs))
+	assert.Equal(t, 0, len(topic.State.Inputs()))
 	assert.Equal(t, 1, len(topic.Children))
 	topic, ok = topic.GetChild("aws:sns/topic:Topic", "countDown")
 	assert.True(t, ok)
@@ -98,8 +98,8 @@ func TestCrawler(t *testing.T) {
 	if !assert.NotNil(t, heartbeat) {
 		return
 	}
-	assert.Equal(t, 1, len(heartbeat.State.Inputs))
-	assert.Equal(t, "rate(5 minutes)", heartbeat.State.Inputs["scheduleExpression"].StringValue())
+	assert.Equal(t, 1, len(heartbeat.State.Inputs()))
+	assert.Equal(t, "rate(5 minutes)", heartbeat.State.Inputs()["scheduleExpression"].StringValue())
 	assert.Equal(t, 4, len(heartbeat.Children))
 
 	// Function child of timer
@@ -108,6 +108,6 @@ func TestCrawler(t *testing.T) {
 	if !assert.NotNil(t, function) {
 		return
 	}
-	assert.Equal(t, 1, len(function.State.Inputs))
+	assert.Equal(t, 1, len(function.State.Inputs()))
 	assert.Equal(t, 3, len(function.Children))
 }
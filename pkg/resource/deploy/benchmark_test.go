@@ -0,0 +1,197 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+// The benchmarks in this file measure the engine's time and memory over a synthetic stack of benchmarkResources
+// custom resources, each of which has a handful of properties and depends upon the resource before it.  Run them with
+//
+//	go test -run=NONE -bench=. -benchmem ./pkg/resource/deploy
+//
+// adding -memprofile=mem.out or -cpuprofile=cpu.out to gather profiles to inspect with `go tool pprof`.  Because
+// timings vary from machine to machine, regressions are guarded against by TestLargeStackAllocations, which checks
+// the allocations made per resource for a smaller stack against fixed thresholds.  Those thresholds should only be
+// raised deliberately, with a note in the commit that does so of what the new allocations pay for.
+const benchmarkResources = 50000
+
+// newBenchmarkSnapshot returns a snapshot of a stack with a default provider and n custom resources, along with the
+// events with which a program would register those resources again, unchanged.
+func newBenchmarkSnapshot(n int) (*Snapshot, []RegisterResourceEvent) {
+	target, project := tokens.QName("bench"), tokens.PackageName("bench")
+
+	provURN := resource.NewURN(target, project, "", providers.MakeProviderType("pkgA"), "default")
+	prov := resource.NewState(provURN.Type(), provURN, true, false, "prov-id", resource.PropertyMap{}, nil, "", false,
+		false, nil, nil, "")
+	ref, err := providers.NewReference(provURN, prov.ID)
+	if err != nil {
+		panic(err)
+	}
+
+	provHash := hashProvider(prov.Inputs(), "1.0.0")
+
+	resources, events := []*resource.State{prov}, make([]RegisterResourceEvent, 0, n)
+	var last resource.URN
+	for i := 0; i < n; i++ {
+		name := tokens.QName(fmt.Sprintf("res%d", i))
+		urn := resource.NewURN(target, project, "", "pkgA:m:typA", name)
+		inputs := resource.NewPropertyMapFromMap(map[string]interface{}{
+			"name":    string(name),
+			"size":    i % 16,
+			"enabled": i%2 == 0,
+			"tags":    map[string]interface{}{"team": "platform", "index": fmt.Sprintf("%d", i)},
+		})
+		var deps []resource.URN
+		if last != "" {
+			deps = []resource.URN{last}
+		}
+
+		state := resource.NewState(urn.Type(), urn, true, false, resource.ID(fmt.Sprintf("id%d", i)), inputs,
+			inputs.Copy(), "", false, false, deps, nil, ref.String())
+		state.RandomSeed = newRandomSeed(nil, urn, nil)
+		state.InputsHash = hashInputs(inputs, state.RandomSeed, provHash)
+		resources = append(resources, state)
+
+		events = append(events, &testRegEvent{goal: resource.NewGoal(urn.Type(), name, true, inputs.Copy(), "",
+			false, deps, ref.String(), nil)})
+		last = urn
+	}
+
+	return newSnapshot(resources, nil), events
+}
+
+// newBenchmarkPlan returns a plan over the given snapshot whose provider does nothing.
+func newBenchmarkPlan(tb testing.TB, snap *Snapshot) *Plan {
+	loader := deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+		return &deploytest.Provider{}, nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, nil, loader)
+	ctx, err := plugin.NewContext(cmdutil.Diag(), cmdutil.Diag(), host, nil, nil, "", nil, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	plan, err := NewPlan(ctx, &Target{Name: "bench"}, snap, NewFixedSource("bench", nil), nil, false)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return plan
+}
+
+// generateSteps generates the steps for each of the given events, as the plan executor would.
+func generateSteps(tb testing.TB, plan *Plan, opts Options, events []RegisterResourceEvent) {
+	sg := newStepGenerator(plan, opts)
+	for _, e := range events {
+		if _, err := sg.GenerateSteps(e); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSnapshotIterate(b *testing.B) {
+	snap, _ := newBenchmarkSnapshot(benchmarkResources)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		iter := snap.IterateLive()
+		for _, ok := iter.Next(); ok; _, ok = iter.Next() {
+		}
+	}
+}
+
+func BenchmarkVerifyIntegrity(b *testing.B) {
+	snap, _ := newBenchmarkSnapshot(benchmarkResources)
+	snap.Manifest.Magic = snap.Manifest.NewMagic()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := snap.VerifyIntegrity(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewPlan(b *testing.B) {
+	snap, _ := newBenchmarkSnapshot(benchmarkResources)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newBenchmarkPlan(b, snap)
+	}
+}
+
+func BenchmarkGenerateSteps(b *testing.B) {
+	snap, events := newBenchmarkSnapshot(benchmarkResources)
+	plan := newBenchmarkPlan(b, snap)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		generateSteps(b, plan, Options{}, events)
+	}
+}
+
+func BenchmarkGenerateStepsStrictDiff(b *testing.B) {
+	snap, events := newBenchmarkSnapshot(benchmarkResources)
+	plan := newBenchmarkPlan(b, snap)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		generateSteps(b, plan, Options{StrictDiff: true}, events)
+	}
+}
+
+func TestLargeStackAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector's allocations would be counted")
+	}
+
+	const n = 2000
+	snap, events := newBenchmarkSnapshot(n)
+	snap.Manifest.Magic = snap.Manifest.NewMagic()
+	plan := newBenchmarkPlan(t, snap)
+
+	perResource := func(f func()) float64 {
+		return testing.AllocsPerRun(5, f) / n
+	}
+
+	// Walking a snapshot allocates nothing at all.
+	assert.Equal(t, float64(0), testing.AllocsPerRun(5, func() {
+		iter := snap.IterateLive()
+		for _, ok := iter.Next(); ok; _, ok = iter.Next() {
+		}
+	}))
+
+	verify := perResource(func() { assert.NoError(t, snap.VerifyIntegrity()) })
+	assert.True(t, verify <= 8, "VerifyIntegrity allocates %v per resource", verify)
+	newPlan := perResource(func() { newBenchmarkPlan(t, snap) })
+	assert.True(t, newPlan <= 14, "NewPlan allocates %v per resource", newPlan)
+	steps := perResource(func() { generateSteps(t, plan, Options{}, events) })
+	assert.True(t, steps <= 64, "generating steps allocates %v per resource", steps)
+	strictSteps := perResource(func() { generateSteps(t, plan, Options{StrictDiff: true}, events) })
+	assert.True(t, strictSteps <= 80, "generating steps with strict diffs allocates %v per resource", strictSteps)
+}
@@ -104,6 +104,8 @@ func (host *pluginHost) Analyzer(nm tokens.QName) (plugin.Analyzer, error) {
 func (host *pluginHost) CloseProvider(provider plugin.Provider) error {
 	return nil
 }
+func (host *pluginHost) WarmProviders(plugins []workspace.PluginInfo) {
+}
 func (host *pluginHost) ListPlugins() []workspace.PluginInfo {
 	return nil
 }
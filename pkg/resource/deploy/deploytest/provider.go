@@ -15,6 +15,8 @@
 package deploytest
 
 import (
+	"time"
+
 	"github.com/blang/semver"
 	uuid "github.com/satori/go.uuid"
 
@@ -32,12 +34,14 @@ type Provider struct {
 
 	configured bool
 
-	CheckConfigF func(olds, news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error)
-	DiffConfigF  func(olds, news resource.PropertyMap) (plugin.DiffResult, error)
-	ConfigureF   func(news resource.PropertyMap) error
+	ParameterizeF func(params resource.PropertyMap) error
+	CheckConfigF  func(olds, news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error)
+	DiffConfigF   func(olds, news resource.PropertyMap) (plugin.DiffResult, error)
+	ConfigureF    func(news resource.PropertyMap) error
+	CheckAuthF    func() error
 
 	CheckF func(urn resource.URN,
-		olds, news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error)
+		olds, news resource.PropertyMap, randomSeed []byte) (resource.PropertyMap, []plugin.CheckFailure, error)
 	DiffF   func(urn resource.URN, id resource.ID, olds, news resource.PropertyMap) (plugin.DiffResult, error)
 	CreateF func(urn resource.URN,
 		inputs resource.PropertyMap) (resource.ID, resource.PropertyMap, resource.Status, error)
@@ -47,8 +51,15 @@ type Provider struct {
 
 	ReadF func(urn resource.URN, id resource.ID,
 		props resource.PropertyMap) (resource.PropertyMap, resource.Status, error)
+	ReadIfModifiedF func(urn resource.URN, id resource.ID, props resource.PropertyMap,
+		since time.Time) (resource.PropertyMap, bool, resource.Status, error)
 	InvokeF func(tok tokens.ModuleMember,
 		inputs resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error)
+	CallF func(tok tokens.ModuleMember,
+		inputs resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error)
+
+	GetSchemaF func() ([]byte, error)
+	DiscoverF  func(filter plugin.DiscoverFilter) ([]plugin.DiscoveredResource, error)
 }
 
 func (prov *Provider) SignalCancellation() error {
@@ -70,6 +81,26 @@ func (prov *Provider) GetPluginInfo() (workspace.PluginInfo, error) {
 	}, nil
 }
 
+func (prov *Provider) GetSchema() ([]byte, error) {
+	if prov.GetSchemaF == nil {
+		return []byte("{}"), nil
+	}
+	return prov.GetSchemaF()
+}
+
+func (prov *Provider) Discover(filter plugin.DiscoverFilter) ([]plugin.DiscoveredResource, error) {
+	if prov.DiscoverF == nil {
+		return nil, nil
+	}
+	return prov.DiscoverF(filter)
+}
+
+func (prov *Provider) Parameterize(params resource.PropertyMap) error {
+	if prov.ParameterizeF == nil {
+		return nil
+	}
+	return prov.ParameterizeF(params)
+}
 func (prov *Provider) CheckConfig(olds,
 	news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
 	if prov.CheckConfigF == nil {
@@ -92,13 +123,23 @@ func (prov *Provider) Configure(inputs resource.PropertyMap) error {
 	}
 	return prov.ConfigureF(inputs)
 }
+func (prov *Provider) CheckAuth() error {
+	if prov.CheckAuthF == nil {
+		return nil
+	}
+	return prov.CheckAuthF()
+}
 
 func (prov *Provider) Check(urn resource.URN,
-	olds, news resource.PropertyMap, _ bool) (resource.PropertyMap, []plugin.CheckFailure, error) {
+	olds, news resource.PropertyMap, allowUnknowns bool) (resource.PropertyMap, []plugin.CheckFailure, error) {
+	return prov.CheckWithSeed(urn, olds, news, nil, allowUnknowns)
+}
+func (prov *Provider) CheckWithSeed(urn resource.URN, olds, news resource.PropertyMap, randomSeed []byte,
+	_ bool) (resource.PropertyMap, []plugin.CheckFailure, error) {
 	if prov.CheckF == nil {
 		return news, nil, nil
 	}
-	return prov.CheckF(urn, olds, news)
+	return prov.CheckF(urn, olds, news, randomSeed)
 }
 func (prov *Provider) Create(urn resource.URN, props resource.PropertyMap) (resource.ID,
 	resource.PropertyMap, resource.Status, error) {
@@ -136,6 +177,14 @@ func (prov *Provider) Read(urn resource.URN, id resource.ID,
 	}
 	return prov.ReadF(urn, id, props)
 }
+func (prov *Provider) ReadIfModified(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	since time.Time) (resource.PropertyMap, bool, resource.Status, error) {
+	if prov.ReadIfModifiedF == nil {
+		outs, status, err := prov.Read(urn, id, props)
+		return outs, false, status, err
+	}
+	return prov.ReadIfModifiedF(urn, id, props, since)
+}
 func (prov *Provider) Invoke(tok tokens.ModuleMember,
 	args resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
 	if prov.InvokeF == nil {
@@ -143,3 +192,10 @@ func (prov *Provider) Invoke(tok tokens.ModuleMember,
 	}
 	return prov.InvokeF(tok, args)
 }
+func (prov *Provider) Call(tok tokens.ModuleMember,
+	args resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+	if prov.CallF == nil {
+		return resource.PropertyMap{}, nil, nil
+	}
+	return prov.CallF(tok, args)
+}
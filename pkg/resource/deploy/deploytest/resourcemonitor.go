@@ -17,6 +17,8 @@ package deploytest
 import (
 	"context"
 
+	"google.golang.org/grpc"
+
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/tokens"
@@ -30,6 +32,31 @@ type ResourceMonitor struct {
 func (rm *ResourceMonitor) RegisterResource(t tokens.Type, name string, custom bool, parent resource.URN, protect bool,
 	dependencies []resource.URN, provider string,
 	inputs resource.PropertyMap) (resource.URN, resource.ID, resource.PropertyMap, error) {
+	return rm.RegisterResourceWithDependsOn(t, name, custom, parent, protect, dependencies, nil, provider, inputs)
+}
+
+// RegisterResourceWithDependsOn registers a resource whose explicit dependencies are reported separately from the
+// rest, just as a language host does for the `dependsOn` resource option.  Unlike a language host, it does not add the
+// explicit dependencies to the full list itself.
+func (rm *ResourceMonitor) RegisterResourceWithDependsOn(t tokens.Type, name string, custom bool, parent resource.URN,
+	protect bool, dependencies []resource.URN, dependsOn []resource.URN, provider string,
+	inputs resource.PropertyMap) (resource.URN, resource.ID, resource.PropertyMap, error) {
+	return rm.RegisterResourceWithOptions(t, name, custom, parent, protect, dependencies, provider, inputs,
+		ResourceOptions{DependsOn: dependsOn})
+}
+
+// ResourceOptions holds the less common resource options that a language host may send with a registration.
+type ResourceOptions struct {
+	DependsOn               []resource.URN         // the dependencies requested with the `dependsOn` option.
+	AdditionalSecretOutputs []resource.PropertyKey // the outputs named by the `additionalSecretOutputs` option.
+	ReplaceOnChanges        []string               // the input paths listed by the `replaceOnChanges` option.
+	DeleteBeforeReplace     bool                   // true if the `deleteBeforeReplace` option is set.
+}
+
+// RegisterResourceWithOptions registers a resource along with the given options.
+func (rm *ResourceMonitor) RegisterResourceWithOptions(t tokens.Type, name string, custom bool, parent resource.URN,
+	protect bool, dependencies []resource.URN, provider string, inputs resource.PropertyMap,
+	opts ResourceOptions) (resource.URN, resource.ID, resource.PropertyMap, error) {
 
 	// marshal inputs
 	ins, err := plugin.MarshalProperties(inputs, plugin.MarshalOptions{KeepUnknowns: true})
@@ -42,6 +69,14 @@ func (rm *ResourceMonitor) RegisterResource(t tokens.Type, name string, custom b
 	for _, d := range dependencies {
 		deps = append(deps, string(d))
 	}
+	var explicitDeps []string
+	for _, d := range opts.DependsOn {
+		explicitDeps = append(explicitDeps, string(d))
+	}
+	var secretOutputs []string
+	for _, k := range opts.AdditionalSecretOutputs {
+		secretOutputs = append(secretOutputs, string(k))
+	}
 
 	// submit request
 	resp, err := rm.resmon.RegisterResource(context.Background(), &pulumirpc.RegisterResourceRequest{
@@ -53,6 +88,11 @@ func (rm *ResourceMonitor) RegisterResource(t tokens.Type, name string, custom b
 		Dependencies: deps,
 		Provider:     provider,
 		Object:       ins,
+		DependsOn:    explicitDeps,
+
+		AdditionalSecretOutputs: secretOutputs,
+		ReplaceOnChanges:        opts.ReplaceOnChanges,
+		DeleteBeforeReplace:     opts.DeleteBeforeReplace,
 	})
 	if err != nil {
 		return "", "", nil, err
@@ -67,6 +107,23 @@ func (rm *ResourceMonitor) RegisterResource(t tokens.Type, name string, custom b
 	return resource.URN(resp.Urn), resource.ID(resp.Id), outs, nil
 }
 
+// RegisterResourceOutputs records the outputs of a resource that has already been registered, just as a language host
+// does for a component resource once its children have been created.
+func (rm *ResourceMonitor) RegisterResourceOutputs(urn resource.URN, outputs resource.PropertyMap) error {
+	// marshal outputs
+	outs, err := plugin.MarshalProperties(outputs, plugin.MarshalOptions{KeepUnknowns: true})
+	if err != nil {
+		return err
+	}
+
+	// submit request
+	_, err = rm.resmon.RegisterResourceOutputs(context.Background(), &pulumirpc.RegisterResourceOutputsRequest{
+		Urn:     string(urn),
+		Outputs: outs,
+	})
+	return err
+}
+
 func (rm *ResourceMonitor) ReadResource(t tokens.Type, name string, id resource.ID, parent resource.URN,
 	inputs resource.PropertyMap, provider string) (resource.URN, resource.PropertyMap, error) {
 
@@ -99,6 +156,18 @@ func (rm *ResourceMonitor) ReadResource(t tokens.Type, name string, id resource.
 
 func (rm *ResourceMonitor) Invoke(tok tokens.ModuleMember,
 	inputs resource.PropertyMap, provider string) (resource.PropertyMap, []*pulumirpc.CheckFailure, error) {
+	return rm.invoke(rm.resmon.Invoke, tok, inputs, provider)
+}
+
+func (rm *ResourceMonitor) Call(tok tokens.ModuleMember,
+	inputs resource.PropertyMap, provider string) (resource.PropertyMap, []*pulumirpc.CheckFailure, error) {
+	return rm.invoke(rm.resmon.Call, tok, inputs, provider)
+}
+
+func (rm *ResourceMonitor) invoke(
+	rpc func(context.Context, *pulumirpc.InvokeRequest, ...grpc.CallOption) (*pulumirpc.InvokeResponse, error),
+	tok tokens.ModuleMember, inputs resource.PropertyMap,
+	provider string) (resource.PropertyMap, []*pulumirpc.CheckFailure, error) {
 
 	// marshal inputs
 	ins, err := plugin.MarshalProperties(inputs, plugin.MarshalOptions{KeepUnknowns: true})
@@ -107,7 +176,7 @@ func (rm *ResourceMonitor) Invoke(tok tokens.ModuleMember,
 	}
 
 	// submit request
-	resp, err := rm.resmon.Invoke(context.Background(), &pulumirpc.InvokeRequest{
+	resp, err := rpc(context.Background(), &pulumirpc.InvokeRequest{
 		Tok:      string(tok),
 		Provider: provider,
 		Args:     ins,
@@ -0,0 +1,101 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// hashInputs returns a hash of the inputs that a program gave a resource, keyed by the resource's random seed, upon
+// which the results of checking the inputs also depend, as they do upon the resource's provider, whose hash (see
+// hashProvider) is folded in.  Inputs that contain unknowns, assets, or archives are not hashed, as the values that
+// they stand for may change without the inputs changing; for them, and for resources whose provider's hash is empty,
+// the result is empty.
+func hashInputs(inputs resource.PropertyMap, randomSeed []byte, providerHash string) string {
+	if providerHash == "" {
+		return ""
+	}
+	return hashProperties(inputs, randomSeed, providerHash)
+}
+
+// hashProvider returns a hash of a provider's checked inputs, i.e. its configuration, and the version of its plugin.
+// Configuration that contains unknowns, assets, or archives is not hashed, and nor is that of a plugin without a
+// version, as its behavior may change without its version changing; for them, the result is empty.
+func hashProvider(inputs resource.PropertyMap, version string) string {
+	if version == "" {
+		return ""
+	}
+	return hashProperties(inputs, nil, version)
+}
+
+// hashProperties returns an HMAC, keyed by key, of the given properties followed by suffix, or "" if the properties
+// contain unknowns, assets, or archives.
+func hashProperties(props resource.PropertyMap, key []byte, suffix string) string {
+	if !isHashable(resource.NewObjectProperty(props)) {
+		return ""
+	}
+	buf := inputsBuffers.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		inputsBuffers.Put(buf)
+	}()
+	if err := json.NewEncoder(buf).Encode(props.Mappable()); err != nil {
+		return ""
+	}
+
+	// The encoder terminates what it writes with a newline, which json.Marshal would not; it is dropped so that the
+	// hash is of exactly the marshaled properties, which are separated from the suffix by a NUL.
+	mac := hmac.New(sha256.New, key)
+	_, err := mac.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	contract.AssertNoError(err)
+	_, err = mac.Write(append([]byte{0}, suffix...))
+	contract.AssertNoError(err)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// inputsBuffers pools the buffers into which inputs are encoded to be hashed, which would otherwise be allocated, and
+// grown, anew for each of the resources that a plan registers.
+var inputsBuffers = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// isHashable returns true if the given property value contains no unknowns, assets, or archives (deeply).
+func isHashable(v resource.PropertyValue) bool {
+	switch {
+	case v.IsComputed() || v.IsOutput() || v.IsAsset() || v.IsArchive():
+		return false
+	case v.IsArray():
+		for _, e := range v.ArrayValue() {
+			if !isHashable(e) {
+				return false
+			}
+		}
+	case v.IsObject():
+		for _, e := range v.ObjectValue() {
+			if !isHashable(e) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+func TestHashInputs(t *testing.T) {
+	inputs := func(v interface{}) resource.PropertyMap {
+		return resource.PropertyMap{
+			"name": resource.NewStringProperty("a<b>&c"),
+			"tags": resource.NewArrayProperty([]resource.PropertyValue{resource.NewPropertyValue(v)}),
+		}
+	}
+
+	hash := hashInputs(inputs("x"), []byte("seed"), "prov")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, hashInputs(inputs("x"), []byte("seed"), "prov"))
+	assert.NotEqual(t, hash, hashInputs(inputs("y"), []byte("seed"), "prov"))
+	assert.NotEqual(t, hash, hashInputs(inputs("x"), []byte("other"), "prov"))
+	assert.NotEqual(t, hash, hashInputs(inputs("x"), []byte("seed"), "other"))
+
+	// The hash is of the inputs exactly as json.Marshal encodes them, followed by the provider's hash.
+	byts, err := json.Marshal(inputs("x").Mappable())
+	assert.NoError(t, err)
+	mac := hmac.New(sha256.New, []byte("seed"))
+	_, err = mac.Write(append(append(byts, 0), "prov"...))
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), hash)
+
+	// Resources whose provider's hash is not known are not hashed.
+	assert.Equal(t, "", hashInputs(inputs("x"), []byte("seed"), ""))
+
+	// Inputs that contain unknowns or assets, however deeply, are not hashed.
+	unknown := inputs(nil)
+	unknown["tags"].ArrayValue()[0] = resource.MakeComputed(resource.NewStringProperty(""))
+	assert.Equal(t, "", hashInputs(unknown, []byte("seed"), "prov"))
+	asset, err := resource.NewTextAsset("hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "", hashInputs(inputs(asset), []byte("seed"), "prov"))
+}
+
+func TestHashProvider(t *testing.T) {
+	config := resource.PropertyMap{"region": resource.NewStringProperty("us-west-2")}
+	hash := hashProvider(config, "1.0.0")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, hashProvider(config.Copy(), "1.0.0"))
+	assert.NotEqual(t, hash, hashProvider(config, "1.1.0"))
+	assert.NotEqual(t, hash, hashProvider(resource.PropertyMap{"region": resource.NewStringProperty("us-east-1")},
+		"1.0.0"))
+
+	// Plugins without versions are not hashed.
+	assert.Equal(t, "", hashProvider(config, ""))
+}
@@ -16,6 +16,7 @@ package deploy
 
 import (
 	"context"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
@@ -32,10 +33,78 @@ import (
 
 // Options controls the planning and deployment process.
 type Options struct {
-	Events      Events // an optional events callback interface.
-	Parallel    int    // the degree of parallelism for resource operations (<=1 for serial).
-	Refresh     bool   // whether or not to refresh before executing the plan.
-	RefreshOnly bool   // whether or not to exit after refreshing.
+	Events      Events   // an optional events callback interface.
+	Parallel    int      // the degree of parallelism for resource operations (<=1 for serial).
+	Refresh     bool     // whether or not to refresh before executing the plan.
+	RefreshOnly bool     // whether or not to exit after refreshing.
+	StepHook    StepHook // an optional callback that decides whether each step is applied.
+	// StepDecider optionally overrides the decisions made by the step generator.
+	StepDecider StepDecider
+	// RefreshParallel is the degree of parallelism for refreshes (<=0 to use Parallel).
+	RefreshParallel int
+	// RefreshRateLimits holds the rate limits for the reads performed by refreshes, keyed by provider package.
+	// Packages without a limit use DefaultRateLimit.
+	RefreshRateLimits map[tokens.Package]RateLimit
+	// RefreshChangedOnly limits refreshes to the resources that may have drifted: those whose types match one of
+	// RefreshDriftProneTypes and, if RefreshSince is set, those that their providers cannot report as unmodified since
+	// then.  Other resources keep their current state.
+	RefreshChangedOnly bool
+	// RefreshDriftProneTypes holds patterns of resource types, in which `*` matches any sequence of characters.
+	RefreshDriftProneTypes []string
+	// RefreshSince is the time since which a changed-only refresh asks providers whether resources were modified.
+	RefreshSince time.Time
+	// DeleteTargets, if non-empty, limits the resources that a plan deletes to those with the given URNs, along with
+	// the resources that depend upon or descend from them.  Every other resource is left as it is.
+	DeleteTargets []resource.URN
+	// UpdateVersion is the version of the stack that the plan will produce, if known.  It is recorded on each resource
+	// that the plan creates or updates.
+	UpdateVersion int
+	// RandomSeed, if non-empty, is the seed from which the plan derives the random seeds of the resources that it
+	// creates or replaces, such that repeated plans with the same seed produce the same results.  If empty, resources
+	// are given random seeds.
+	RandomSeed []byte
+	// StrictDiff, when true, has providers check and diff the inputs of every resource, even those whose inputs are
+	// unchanged since they were last checked.
+	StrictDiff bool
+	// ProviderDefaults lists, by resource type, the input properties whose values providers fill in when programs do
+	// not give them.  Resources are not considered changed if the only differences between their old and new inputs
+	// are in such properties that their programs gave neither time, unless ShowFullDiff is set.
+	ProviderDefaults map[tokens.Type][]resource.PropertyKey
+	// ShowFullDiff, when true, considers every difference between resources' old and new inputs, including those in
+	// properties that their providers filled in.
+	ShowFullDiff bool
+}
+
+// StepAction is the action to take for a step, as decided by a StepHook.
+type StepAction int
+
+const (
+	// StepContinue applies the step.
+	StepContinue StepAction = iota
+	// StepSkip skips the step, leaving the resource as it was.  Only steps for which CanSkip returns true may be
+	// skipped.
+	StepSkip
+	// StepAbort aborts the deployment.
+	StepAbort
+)
+
+// StepHook is called before each step other than a same step is applied during a deployment, and decides whether the
+// step is applied, skipped, or the deployment is aborted.  It is not called during previews.
+type StepHook func(step Step) StepAction
+
+// CanSkip returns true if a step with the given operation may be skipped by a StepHook.  Skipping an update retains
+// the resource's old state, and skipping a deletion leaves the resource in place; other steps produce state that
+// later steps depend upon, and so may not be skipped.
+func CanSkip(op StepOp) bool {
+	return op == OpUpdate || op == OpDelete
+}
+
+// refreshOptions returns the options to use for the step executor that performs refreshes.
+func (o Options) refreshOptions() Options {
+	if o.RefreshParallel > 0 {
+		o.Parallel = o.RefreshParallel
+	}
+	return o
 }
 
 // DegreeOfParallelism returns the degree of parallelism that should be used during the
@@ -65,6 +134,11 @@ func (p PlanPendingOperationsError) Error() string {
 	return "one or more operations are currently pending"
 }
 
+// DiagnosticID returns the stable code for this class of errors.
+func (p PlanPendingOperationsError) DiagnosticID() diag.ID {
+	return diag.PendingOperationsID
+}
+
 // Plan is the output of analyzing resource graphs and contains the steps necessary to perform an infrastructure
 // deployment.  A plan can be generated out of whole cloth from a resource graph -- in the case of new deployments --
 // however, it can alternatively be generated by diffing two resource graphs -- in the case of updates to existing
@@ -129,13 +203,7 @@ func addDefaultProviders(target *Target, source Source, prev *Snapshot) error {
 			ref, err = providers.NewReference(urn, id)
 			contract.Assert(err == nil)
 
-			provider := &resource.State{
-				Type:   urn.Type(),
-				URN:    urn,
-				Custom: true,
-				ID:     id,
-				Inputs: inputs,
-			}
+			provider := resource.NewState(urn.Type(), urn, true, false, id, inputs, nil, "", false, false, nil, nil, "")
 			defaultProviders = append(defaultProviders, provider)
 			defaultProviderRefs[pkg] = ref
 		}
@@ -182,25 +250,25 @@ func NewPlan(ctx *plugin.Context, target *Target, prev *Snapshot, source Source,
 	//
 	// NOTE: we can and do mutate prev.Resources, olds, and depGraph during execution after performing a refresh. See
 	// planExecutor.refresh for details.
-	olds := make(map[resource.URN]*resource.State)
+	var olds map[resource.URN]*resource.State
 	if prev != nil {
 		if prev.PendingOperations != nil {
 			return nil, PlanPendingOperationsError{prev.PendingOperations}
 		}
 		oldResources = prev.Resources
 
-		for _, oldres := range oldResources {
-			// Ignore resources that are pending deletion; these should not be recorded in the LUT.
-			if oldres.Delete {
-				continue
-			}
-
+		// Ignore resources that are pending deletion; these should not be recorded in the LUT.
+		olds = make(map[resource.URN]*resource.State, len(oldResources))
+		iter := prev.IterateLive()
+		for oldres, ok := iter.Next(); ok; oldres, ok = iter.Next() {
 			urn := oldres.URN
 			contract.Assert(olds[urn] == nil)
 			olds[urn] = oldres
 		}
 
 		depGraph = graph.NewDependencyGraph(oldResources)
+	} else {
+		olds = make(map[resource.URN]*resource.State)
 	}
 
 	// Create a new provider registry. Although we really only need to pass in any providers that were present in the
@@ -23,6 +23,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
 	"github.com/pulumi/pulumi/pkg/resource/graph"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/glob"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 )
 
@@ -37,7 +38,7 @@ type planExecutor struct {
 }
 
 // Utility for convenient logging.
-var log = logging.V(4)
+var log = logging.Engine.V(4)
 
 // execError creates an error appropriate for returning from planExecutor.Execute.
 func execError(message string, preview bool) error {
@@ -142,7 +143,13 @@ func (pe *planExecutor) Execute(callerCtx context.Context, opts Options, preview
 					// TODO[pulumi/pulumi#1625] Today we lack the ability to parallelize deletions. We have all the
 					// information we need to do so (namely, a dependency graph). `GenerateDeletes` returns a single
 					// chain of every delete that needs to be executed.
-					deletes := pe.stepGen.GenerateDeletes()
+					deletes, err := pe.stepGen.GenerateDeletes()
+					if err != nil {
+						log.Infof("planExecutor.Execute(...): error generating deletes: %v", err)
+						pe.reportError("", err)
+						cancel()
+						return false, err
+					}
 					pe.stepExec.Execute(deletes)
 
 					// Signal completion to the step executor. It'll exit once it's done retiring all of the steps in
@@ -216,15 +223,24 @@ func (pe *planExecutor) refresh(callerCtx context.Context, opts Options, preview
 		return nil
 	}
 
-	// Create a refresh step for each resource in the old snapshot.
+	// Create a refresh step for each resource in the old snapshot.  The reads of all resources managed by the same
+	// provider package share a rate limiter, which stops waiting as soon as the refresh is canceled.
+	ctx, cancel := context.WithCancel(callerCtx)
+	limiters := newRateLimiters(ctx, opts.RefreshRateLimits)
+	driftProne := glob.Compile(opts.RefreshDriftProneTypes...)
 	steps := make([]Step, len(prev.Resources))
-	for i := range prev.Resources {
-		steps[i] = NewRefreshStep(pe.plan, prev.Resources[i], nil)
+	for i, res := range prev.Resources {
+		step := NewRefreshStep(pe.plan, res, nil).(*RefreshStep)
+		step.limiter = limiters.forPackage(res.Type.Package())
+		if opts.RefreshChangedOnly && !driftProne.MatchString(string(res.Type)) {
+			// Resources that aren't drift-prone are only read if their providers can't vouch for them.
+			step.since, step.skip = opts.RefreshSince, opts.RefreshSince.IsZero()
+		}
+		steps[i] = step
 	}
 
 	// Fire up a worker pool and issue each refresh in turn.
-	ctx, cancel := context.WithCancel(callerCtx)
-	stepExec := newStepExecutor(ctx, cancel, pe.plan, opts, preview)
+	stepExec := newStepExecutor(ctx, cancel, pe.plan, opts.refreshOptions(), preview)
 	for i := range steps {
 		if ctx.Err() != nil {
 			break
@@ -282,6 +298,15 @@ func (pe *planExecutor) refresh(callerCtx context.Context, opts Options, preview
 			}
 			new.Dependencies = deps
 		}
+		if len(new.DependsOn) != 0 {
+			deps := make([]resource.URN, 0, len(new.DependsOn))
+			for _, d := range new.DependsOn {
+				if referenceable[d] {
+					deps = append(deps, d)
+				}
+			}
+			new.DependsOn = deps
+		}
 
 		// Add this resource to the resource list and mark it as referenceable.
 		resources = append(resources, new)
@@ -13,12 +13,9 @@ import (
 
 func newResource(name string) *resource.State {
 	ty := tokens.Type("test")
-	return &resource.State{
-		Type:    ty,
-		URN:     resource.NewURN(tokens.QName("teststack"), tokens.PackageName("pkg"), ty, ty, tokens.QName(name)),
-		Inputs:  make(resource.PropertyMap),
-		Outputs: make(resource.PropertyMap),
-	}
+	urn := resource.NewURN(tokens.QName("teststack"), tokens.PackageName("pkg"), ty, ty, tokens.QName(name))
+	return resource.NewState(ty, urn, false, false, "", make(resource.PropertyMap), make(resource.PropertyMap), "",
+		false, false, nil, nil, "")
 }
 
 func newSnapshot(resources []*resource.State, ops []resource.Operation) *Snapshot {
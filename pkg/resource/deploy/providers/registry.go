@@ -16,7 +16,9 @@ package providers
 
 import (
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
@@ -31,8 +33,9 @@ import (
 )
 
 // getProviderVersion fetches and parses a provider version from the given property map. If the version property is not
-// present, this function returns nil.
-func getProviderVersion(inputs resource.PropertyMap) (*semver.Version, error) {
+// present, this function returns nil. If the version property is a range of versions, this function returns the newest
+// installed version of the package's plugin within that range.
+func getProviderVersion(pkg tokens.Package, inputs resource.PropertyMap) (*semver.Version, error) {
 	versionProp, ok := inputs["version"]
 	if !ok {
 		return nil, nil
@@ -42,6 +45,21 @@ func getProviderVersion(inputs resource.PropertyMap) (*semver.Version, error) {
 		return nil, errors.New("'version' must be a string")
 	}
 
+	if workspace.IsPluginVersionRange(versionProp.StringValue()) {
+		rng, err := workspace.ParsePluginVersionRange(versionProp.StringValue())
+		if err != nil {
+			return nil, err
+		}
+		name := strings.Replace(string(pkg), tokens.QNameDelimiter, "_", -1)
+		sv, err := workspace.ResolvePluginVersion(workspace.ResourcePlugin, name, rng)
+		if err != nil {
+			return nil, err
+		} else if sv == nil {
+			return nil, errors.Errorf("no installed version of the %s plugin satisfies '%s'", name, rng)
+		}
+		return sv, nil
+	}
+
 	sv, err := semver.ParseTolerant(versionProp.StringValue())
 	if err != nil {
 		return nil, errors.Errorf("could not parse provider version: %v", err)
@@ -49,6 +67,28 @@ func getProviderVersion(inputs resource.PropertyMap) (*semver.Version, error) {
 	return &sv, nil
 }
 
+// getProviderParameters fetches the provider parameters from the given property map. If the parameters property is not
+// present or is not yet known, this function returns nil.
+func getProviderParameters(inputs resource.PropertyMap) (resource.PropertyMap, error) {
+	paramsProp, ok := inputs[plugin.ParametersKey]
+	if !ok || paramsProp.IsComputed() {
+		return nil, nil
+	}
+
+	if !paramsProp.IsObject() {
+		return nil, errors.New("'parameters' must be an object")
+	}
+	return paramsProp.ObjectValue(), nil
+}
+
+// parameterizeProvider passes the given parameters, if any, to the provider.
+func parameterizeProvider(provider plugin.Provider, params resource.PropertyMap) error {
+	if params == nil {
+		return nil
+	}
+	return provider.Parameterize(params)
+}
+
 // Registry manages the lifecylce of provider resources and their plugins and handles the resolution of provider
 // references to loaded plugins.
 //
@@ -72,6 +112,8 @@ var _ plugin.Provider = (*Registry)(nil)
 // NewRegistry creates a new provider registry using the given host and old resources. Each provider present in the old
 // resources will be loaded, configured, and added to the returned registry under its reference. If any provider is not
 // loadable/configurable or has an invalid ID, this function returns an error.
+//
+// Starting a provider plugin can take a second or more, so the old providers are loaded and configured concurrently.
 func NewRegistry(host plugin.Host, prev []*resource.State, isPreview bool) (*Registry, error) {
 	r := &Registry{
 		host:      host,
@@ -79,10 +121,14 @@ func NewRegistry(host plugin.Host, prev []*resource.State, isPreview bool) (*Reg
 		providers: make(map[Reference]plugin.Provider),
 	}
 
+	// First, validate the old providers and decide which of them must be loaded.
+	var refs []Reference
+	var olds []*resource.State
+	seen := make(map[Reference]bool)
 	for _, res := range prev {
 		urn := res.URN
 		if !IsProviderType(urn.Type()) {
-			logging.V(7).Infof("provider(%v): %v", urn, res.Provider)
+			logging.Engine.V(7).Infof("provider(%v): %v", urn, res.Provider)
 			continue
 		}
 
@@ -93,41 +139,84 @@ func NewRegistry(host plugin.Host, prev []*resource.State, isPreview bool) (*Reg
 
 		// Ensure that we have no duplicates.
 		ref := mustNewReference(urn, res.ID)
-		if _, ok := r.providers[ref]; ok {
+		if seen[ref] {
 			return nil, errors.Errorf("duplicate provider found in old state: '%v'", ref)
 		}
+		seen[ref] = true
 
-		// Parse the provider version, then load, configure, and register the provider.
-		version, err := getProviderVersion(res.Inputs)
-		if err != nil {
-			return nil, errors.Errorf("could not parse version for provider '%v': %v", urn, err)
-		}
-		provider, err := host.Provider(getProviderPackage(urn.Type()), version)
-		if provider == nil {
-			return nil, errors.Errorf("could not find plugin for provider '%v'", urn)
-		}
+		refs, olds = append(refs, ref), append(olds, res)
+	}
+
+	// Next, load each provider concurrently.
+	providers, errs := make([]plugin.Provider, len(olds)), make([]error, len(olds))
+	var wg sync.WaitGroup
+	for i := range olds {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			providers[i], errs[i] = loadProvider(host, olds[i])
+		}(i)
+	}
+	wg.Wait()
+
+	// Finally, register the providers. If any of them failed to load, report the first failure and close the rest.
+	for _, err := range errs {
 		if err != nil {
-			return nil, errors.Errorf("could not load plugin for provider '%v': %v", urn, err)
-		}
-		if err := provider.Configure(res.Inputs); err != nil {
-			closeErr := host.CloseProvider(provider)
-			contract.IgnoreError(closeErr)
-			return nil, errors.Errorf("could not configure provider '%v': %v", urn, err)
+			for _, provider := range providers {
+				if provider != nil {
+					contract.IgnoreError(host.CloseProvider(provider))
+				}
+			}
+			return nil, err
 		}
-
-		logging.V(7).Infof("loaded provider %v", ref)
-		r.providers[ref] = provider
+	}
+	for i, ref := range refs {
+		logging.Engine.V(7).Infof("loaded provider %v", ref)
+		r.providers[ref] = providers[i]
 	}
 
 	return r, nil
 }
 
+// loadProvider loads, parameterizes, and configures the plugin for an old provider resource.
+func loadProvider(host plugin.Host, res *resource.State) (plugin.Provider, error) {
+	urn := res.URN
+
+	// Parse the provider version and parameters, then load, parameterize, and configure the provider.
+	version, err := getProviderVersion(getProviderPackage(urn.Type()), res.Inputs())
+	if err != nil {
+		return nil, errors.Errorf("could not parse version for provider '%v': %v", urn, err)
+	}
+	params, err := getProviderParameters(res.Inputs())
+	if err != nil {
+		return nil, errors.Errorf("could not parse parameters for provider '%v': %v", urn, err)
+	}
+	provider, err := host.Provider(getProviderPackage(urn.Type()), version)
+	if provider == nil {
+		return nil, errors.Errorf("could not find plugin for provider '%v'", urn)
+	}
+	if err != nil {
+		return nil, errors.Errorf("could not load plugin for provider '%v': %v", urn, err)
+	}
+	if err := parameterizeProvider(provider, params); err != nil {
+		closeErr := host.CloseProvider(provider)
+		contract.IgnoreError(closeErr)
+		return nil, errors.Errorf("could not parameterize provider '%v': %v", urn, err)
+	}
+	if err := provider.Configure(res.Inputs()); err != nil {
+		closeErr := host.CloseProvider(provider)
+		contract.IgnoreError(closeErr)
+		return nil, errors.Errorf("could not configure provider '%v': %v", urn, err)
+	}
+	return provider, nil
+}
+
 // GetProvider returns the provider plugin that is currently registered under the given reference, if any.
 func (r *Registry) GetProvider(ref Reference) (plugin.Provider, bool) {
 	r.m.RLock()
 	defer r.m.RUnlock()
 
-	logging.V(7).Infof("GetProvider(%v)", ref)
+	logging.Engine.V(7).Infof("GetProvider(%v)", ref)
 
 	provider, ok := r.providers[ref]
 	return provider, ok
@@ -137,7 +226,7 @@ func (r *Registry) setProvider(ref Reference, provider plugin.Provider) {
 	r.m.Lock()
 	defer r.m.Unlock()
 
-	logging.V(7).Infof("setProvider(%v)", ref)
+	logging.Engine.V(7).Infof("setProvider(%v)", ref)
 
 	r.providers[ref] = provider
 }
@@ -180,16 +269,26 @@ func (r *Registry) DiffConfig(olds, news resource.PropertyMap) (plugin.DiffResul
 	return plugin.DiffResult{}, errors.New("the provider registry is not configurable")
 }
 
+func (r *Registry) Parameterize(params resource.PropertyMap) error {
+	contract.Fail()
+	return errors.New("the provider registry is not parameterizable")
+}
+
 func (r *Registry) Configure(props resource.PropertyMap) error {
 	contract.Fail()
 	return errors.New("the provider registry is not configurable")
 }
 
+// CheckAuth always succeeds: the registry has no credentials of its own.
+func (r *Registry) CheckAuth() error {
+	return nil
+}
+
 // Check validates the configuration for a particular provider resource.
 //
 // The particulars of Check are a bit subtle for a few reasons:
 // - we need to load the provider for the package indicated by the type name portion provider resource's URN in order
-//   to check its config
+//   to check its config, and parameterize it with the parameters, if any, recorded in the config
 // - we need to keep the newly-loaded provider around in case we need to diff its config
 // - if we are running a preview, we need to configure the provider, as its corresponding CRUD operations will not run
 //   (we would normally configure the provider in Create or Update).
@@ -199,13 +298,17 @@ func (r *Registry) Check(urn resource.URN, olds, news resource.PropertyMap,
 	contract.Require(IsProviderType(urn.Type()), "urn")
 
 	label := fmt.Sprintf("%s.Check(%s)", r.label(), urn)
-	logging.V(7).Infof("%s executing (#olds=%d,#news=%d", label, len(olds), len(news))
+	logging.Engine.V(7).Infof("%s executing (#olds=%d,#news=%d", label, len(olds), len(news))
 
-	// Parse the version from the provider properties and load the provider.
-	version, err := getProviderVersion(news)
+	// Parse the version and parameters from the provider properties, then load and parameterize the provider.
+	version, err := getProviderVersion(getProviderPackage(urn.Type()), news)
 	if err != nil {
 		return nil, []plugin.CheckFailure{{Property: "version", Reason: err.Error()}}, nil
 	}
+	params, err := getProviderParameters(news)
+	if err != nil {
+		return nil, []plugin.CheckFailure{{Property: plugin.ParametersKey, Reason: err.Error()}}, nil
+	}
 	provider, err := r.host.Provider(getProviderPackage(urn.Type()), version)
 	if err != nil {
 		return nil, nil, err
@@ -213,6 +316,11 @@ func (r *Registry) Check(urn resource.URN, olds, news resource.PropertyMap,
 	if provider == nil {
 		return nil, nil, errors.New("could not find plugin")
 	}
+	if err := parameterizeProvider(provider, params); err != nil {
+		closeErr := r.host.CloseProvider(provider)
+		contract.IgnoreError(closeErr)
+		return nil, nil, err
+	}
 
 	// Check the provider's config. If the check fails, unload the provider.
 	inputs, failures, err := provider.CheckConfig(olds, news)
@@ -246,7 +354,7 @@ func (r *Registry) Diff(urn resource.URN, id resource.ID, olds, news resource.Pr
 	contract.Require(id != "", "id")
 
 	label := fmt.Sprintf("%s.Diff(%s,%s)", r.label(), urn, id)
-	logging.V(7).Infof("%s: executing (#olds=%d,#news=%d)", label, len(olds), len(news))
+	logging.Engine.V(7).Infof("%s: executing (#olds=%d,#news=%d)", label, len(olds), len(news))
 
 	// Create a reference using the URN and the unknown ID and fetch the provider.
 	provider, ok := r.GetProvider(mustNewReference(urn, UnknownID))
@@ -282,7 +390,7 @@ func (r *Registry) Create(urn resource.URN,
 	contract.Assert(!r.isPreview)
 
 	label := fmt.Sprintf("%s.Create(%s)", r.label(), urn)
-	logging.V(7).Infof("%s executing (#news=%v)", label, len(news))
+	logging.Engine.V(7).Infof("%s executing (#news=%v)", label, len(news))
 
 	// Fetch the unconfigured provider, configure it, and register it under a new ID.
 	provider, ok := r.GetProvider(mustNewReference(urn, UnknownID))
@@ -309,7 +417,7 @@ func (r *Registry) Update(urn resource.URN, id resource.ID, olds,
 	contract.Assert(!r.isPreview)
 
 	label := fmt.Sprintf("%s.Update(%s,%s)", r.label(), id, urn)
-	logging.V(7).Infof("%s executing (#olds=%v,#news=%v)", label, len(olds), len(news))
+	logging.Engine.V(7).Infof("%s executing (#olds=%v,#news=%v)", label, len(olds), len(news))
 
 	// Fetch the unconfigured provider and configure it.
 	provider, ok := r.GetProvider(mustNewReference(urn, UnknownID))
@@ -343,6 +451,11 @@ func (r *Registry) Read(urn resource.URN, id resource.ID,
 	return nil, resource.StatusUnknown, errors.New("provider resources may not be read")
 }
 
+func (r *Registry) ReadIfModified(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	since time.Time) (resource.PropertyMap, bool, resource.Status, error) {
+	return nil, false, resource.StatusUnknown, errors.New("provider resources may not be read")
+}
+
 func (r *Registry) Invoke(tok tokens.ModuleMember,
 	args resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
 
@@ -352,11 +465,29 @@ func (r *Registry) Invoke(tok tokens.ModuleMember,
 	return nil, nil, errors.New("the provider registry is not invokable")
 }
 
+func (r *Registry) Call(tok tokens.ModuleMember,
+	args resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+
+	// As with Invoke, the eval source must never attempt a call using the provider registry.
+	contract.Fail()
+	return nil, nil, errors.New("the provider registry is not callable")
+}
+
 func (r *Registry) GetPluginInfo() (workspace.PluginInfo, error) {
 	// return an error: this should not be called for the provider registry
 	return workspace.PluginInfo{}, errors.New("the provider registry does not report plugin info")
 }
 
+func (r *Registry) GetSchema() ([]byte, error) {
+	// return an error: this should not be called for the provider registry
+	return nil, errors.New("the provider registry does not report a schema")
+}
+
+func (r *Registry) Discover(filter plugin.DiscoverFilter) ([]plugin.DiscoveredResource, error) {
+	// return an error: this should not be called for the provider registry
+	return nil, errors.New("the provider registry does not discover resources")
+}
+
 func (r *Registry) SignalCancellation() error {
 	// At the moment there isn't anything reasonable we can do here. In the future, it might be nice to plumb
 	// cancellation through the plugin loader and cancel any outstanding load requests here.
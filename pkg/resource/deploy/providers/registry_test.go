@@ -15,7 +15,10 @@
 package providers
 
 import (
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
@@ -62,6 +65,8 @@ func (host *testPluginHost) CloseProvider(provider plugin.Provider) error {
 func (host *testPluginHost) LanguageRuntime(runtime string) (plugin.LanguageRuntime, error) {
 	return nil, errors.New("unsupported")
 }
+func (host *testPluginHost) WarmProviders(plugins []workspace.PluginInfo) {
+}
 func (host *testPluginHost) ListPlugins() []workspace.PluginInfo {
 	return nil
 }
@@ -77,6 +82,7 @@ type testProvider struct {
 	pkg         tokens.Package
 	version     semver.Version
 	configured  bool
+	params      resource.PropertyMap
 	checkConfig func(resource.PropertyMap, resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error)
 	diffConfig  func(resource.PropertyMap, resource.PropertyMap) (plugin.DiffResult, error)
 	config      func(resource.PropertyMap) error
@@ -91,6 +97,10 @@ func (prov *testProvider) Close() error {
 func (prov *testProvider) Pkg() tokens.Package {
 	return prov.pkg
 }
+func (prov *testProvider) Parameterize(params resource.PropertyMap) error {
+	prov.params = params
+	return nil
+}
 func (prov *testProvider) CheckConfig(olds,
 	news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
 	return prov.checkConfig(olds, news)
@@ -105,6 +115,9 @@ func (prov *testProvider) Configure(inputs resource.PropertyMap) error {
 	prov.configured = true
 	return nil
 }
+func (prov *testProvider) CheckAuth() error {
+	return nil
+}
 func (prov *testProvider) Check(urn resource.URN,
 	olds, news resource.PropertyMap, _ bool) (resource.PropertyMap, []plugin.CheckFailure, error) {
 	return nil, nil, errors.New("unsupported")
@@ -117,6 +130,10 @@ func (prov *testProvider) Read(urn resource.URN, id resource.ID,
 	props resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
 	return nil, resource.StatusUnknown, errors.New("unsupported")
 }
+func (prov *testProvider) ReadIfModified(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	since time.Time) (resource.PropertyMap, bool, resource.Status, error) {
+	return nil, false, resource.StatusUnknown, errors.New("unsupported")
+}
 func (prov *testProvider) Diff(urn resource.URN, id resource.ID,
 	olds resource.PropertyMap, news resource.PropertyMap, _ bool) (plugin.DiffResult, error) {
 	return plugin.DiffResult{}, errors.New("unsupported")
@@ -133,12 +150,22 @@ func (prov *testProvider) Invoke(tok tokens.ModuleMember,
 	args resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
 	return nil, nil, errors.New("unsupported")
 }
+func (prov *testProvider) Call(tok tokens.ModuleMember,
+	args resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+	return nil, nil, errors.New("unsupported")
+}
 func (prov *testProvider) GetPluginInfo() (workspace.PluginInfo, error) {
 	return workspace.PluginInfo{
 		Name:    "testProvider",
 		Version: &prov.version,
 	}, nil
 }
+func (prov *testProvider) GetSchema() ([]byte, error) {
+	return nil, errors.New("unsupported")
+}
+func (prov *testProvider) Discover(filter plugin.DiscoverFilter) ([]plugin.DiscoveredResource, error) {
+	return nil, errors.New("unsupported")
+}
 
 type providerLoader struct {
 	pkg     tokens.Package
@@ -219,14 +246,7 @@ func newProviderState(pkg, name, id string, delete bool, inputs resource.Propert
 	if inputs == nil {
 		inputs = resource.PropertyMap{}
 	}
-	return &resource.State{
-		Type:   typ,
-		URN:    urn,
-		Custom: true,
-		Delete: delete,
-		ID:     resource.ID(id),
-		Inputs: inputs,
-	}
+	return resource.NewState(typ, urn, true, delete, resource.ID(id), inputs, nil, "", false, false, nil, nil, "")
 }
 
 func TestNewRegistryNoOldState(t *testing.T) {
@@ -281,7 +301,7 @@ func TestNewRegistryOldState(t *testing.T) {
 
 		assert.Equal(t, getProviderPackage(old.Type), p.Pkg())
 
-		ver, err := getProviderVersion(old.Inputs)
+		ver, err := getProviderVersion(getProviderPackage(old.Type), old.Inputs())
 		assert.NoError(t, err)
 		if ver != nil {
 			info, err := p.GetPluginInfo()
@@ -291,6 +311,83 @@ func TestNewRegistryOldState(t *testing.T) {
 	}
 }
 
+func TestNewRegistryOldStateParameters(t *testing.T) {
+	params := resource.PropertyMap{
+		"apiVersion": resource.NewStringProperty("1.10"),
+	}
+	olds := []*resource.State{
+		newProviderState("pkgA", "a", "id1", false, nil),
+		newProviderState("pkgA", "b", "id2", false, resource.PropertyMap{
+			"parameters": resource.NewObjectProperty(params),
+		}),
+	}
+	loaders := []*providerLoader{
+		newSimpleLoader(t, "pkgA", "", nil),
+	}
+	host := newPluginHost(t, loaders)
+
+	r, err := NewRegistry(host, olds, false)
+	assert.NoError(t, err)
+	assert.NotNil(t, r)
+
+	// Only the provider with recorded parameters should have been parameterized.
+	p, ok := r.GetProvider(mustNewReference(olds[0].URN, olds[0].ID))
+	assert.True(t, ok)
+	assert.Nil(t, p.(*testProvider).params)
+
+	p, ok = r.GetProvider(mustNewReference(olds[1].URN, olds[1].ID))
+	assert.True(t, ok)
+	assert.Equal(t, params, p.(*testProvider).params)
+	assert.True(t, p.(*testProvider).configured)
+}
+
+func TestNewRegistryOldStateBadParameters(t *testing.T) {
+	olds := []*resource.State{
+		newProviderState("pkgA", "a", "id1", false, resource.PropertyMap{
+			"parameters": resource.NewStringProperty("1.10"),
+		}),
+	}
+	loaders := []*providerLoader{
+		newSimpleLoader(t, "pkgA", "", nil),
+	}
+	host := newPluginHost(t, loaders)
+
+	r, err := NewRegistry(host, olds, false)
+	assert.Error(t, err)
+	assert.Nil(t, r)
+}
+
+func TestNewRegistryOldStateConfigureFailure(t *testing.T) {
+	olds := []*resource.State{
+		newProviderState("pkgA", "a", "id1", false, nil),
+		newProviderState("pkgB", "a", "id1", false, nil),
+	}
+	loaders := []*providerLoader{
+		newSimpleLoader(t, "pkgA", "", nil),
+		newSimpleLoader(t, "pkgB", "", func(resource.PropertyMap) error {
+			return errors.New("bad config")
+		}),
+	}
+
+	// The providers are loaded concurrently; if one fails, the registry must close all of the others that loaded.
+	var lock sync.Mutex
+	var closed []tokens.Package
+	host := newPluginHost(t, loaders).(*testPluginHost)
+	host.closeProvider = func(provider plugin.Provider) error {
+		lock.Lock()
+		defer lock.Unlock()
+		closed = append(closed, provider.Pkg())
+		return nil
+	}
+
+	r, err := NewRegistry(host, olds, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "bad config")
+	assert.Nil(t, r)
+	sort.Slice(closed, func(i, j int) bool { return closed[i] < closed[j] })
+	assert.Equal(t, []tokens.Package{"pkgA", "pkgB"}, closed)
+}
+
 func TestNewRegistryOldStateNoProviders(t *testing.T) {
 	olds := []*resource.State{
 		newProviderState("pkgA", "a", "id1", false, nil),
@@ -439,7 +536,7 @@ func TestCRUD(t *testing.T) {
 	// Update the existing provider for the first entry in olds.
 	{
 		urn, id := olds[0].URN, olds[0].ID
-		olds, news := olds[0].Inputs, olds[0].Inputs
+		olds, news := olds[0].Inputs(), olds[0].Inputs()
 
 		// Fetch the old provider instance.
 		old, ok := r.GetProvider(Reference{urn: urn, id: id})
@@ -565,7 +662,7 @@ func TestCRUDPreview(t *testing.T) {
 	// Update the existing provider for the first entry in olds.
 	{
 		urn, id := olds[0].URN, olds[0].ID
-		olds, news := olds[0].Inputs, olds[0].Inputs
+		olds, news := olds[0].Inputs(), olds[0].Inputs()
 
 		// Fetch the old provider instance.
 		old, ok := r.GetProvider(Reference{urn: urn, id: id})
@@ -598,7 +695,7 @@ func TestCRUDPreview(t *testing.T) {
 	// Replace the existing provider for the last entry in olds.
 	{
 		urn, id := olds[len(olds)-1].URN, olds[len(olds)-1].ID
-		olds, news := olds[len(olds)-1].Inputs, olds[len(olds)-1].Inputs
+		olds, news := olds[len(olds)-1].Inputs(), olds[len(olds)-1].Inputs()
 
 		// Fetch the old provider instance.
 		old, ok := r.GetProvider(Reference{urn: urn, id: id})
@@ -0,0 +1,48 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"crypto/hmac"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// randomSeedLength is the length, in bytes, of the random seeds generated for resources.
+const randomSeedLength = sha256.Size
+
+// newRandomSeed returns a new random seed for the resource with the given URN.  If the plan has a seed of its own, the
+// result is derived from it, the URN, and the resource's prior seed, if any, so that the same plan seed always
+// produces the same resource seeds; otherwise the result is random.
+func newRandomSeed(planSeed []byte, urn resource.URN, prior []byte) []byte {
+	if len(planSeed) == 0 {
+		seed := make([]byte, randomSeedLength)
+		_, err := cryptorand.Read(seed)
+		contract.AssertNoError(err)
+		return seed
+	}
+
+	mac := hmac.New(sha256.New, planSeed)
+	_, err := mac.Write([]byte(urn))
+	contract.AssertNoError(err)
+	_, err = mac.Write([]byte{0})
+	contract.AssertNoError(err)
+	_, err = mac.Write(prior)
+	contract.AssertNoError(err)
+	return mac.Sum(nil)
+}
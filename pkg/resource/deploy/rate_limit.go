@@ -0,0 +1,205 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil/rpcerror"
+)
+
+// RateLimit limits the rate at which a refresh reads the resources managed by a single provider package, and controls
+// how those reads back off when the provider reports that it is being throttled.
+type RateLimit struct {
+	RequestsPerSecond float64       // the maximum rate at which reads are started (<=0 for no limit).
+	MaxRetries        int           // the number of times that a throttled read is retried.
+	Backoff           time.Duration // the delay before the first retry, doubled for each retry thereafter.
+	MaxBackoff        time.Duration // the longest delay between retries.
+}
+
+// DefaultRateLimit is the rate limit for provider packages that have not been given one of their own: reads are not
+// limited, but throttled reads are retried.
+var DefaultRateLimit = RateLimit{
+	MaxRetries: 5,
+	Backoff:    time.Second,
+	MaxBackoff: 30 * time.Second,
+}
+
+// ParseRateLimit parses a rate limit of the form `<package>=<requests per second>`, optionally followed by any of
+// `,retries=<count>`, `,backoff=<duration>`, and `,max-backoff=<duration>`.  Anything left unspecified is taken from
+// DefaultRateLimit.
+func ParseRateLimit(spec string) (tokens.Package, RateLimit, error) {
+	limit := DefaultRateLimit
+
+	eq := strings.Index(spec, "=")
+	if eq <= 0 {
+		return "", limit, errors.Errorf("rate limit %q must be of the form <package>=<requests per second>", spec)
+	}
+	pkg, settings := tokens.Package(spec[:eq]), strings.Split(spec[eq+1:], ",")
+
+	rps, err := strconv.ParseFloat(settings[0], 64)
+	if err != nil || rps <= 0 {
+		return "", limit, errors.Errorf("rate limit for %s must be a positive number of requests per second", pkg)
+	}
+	limit.RequestsPerSecond = rps
+
+	for _, setting := range settings[1:] {
+		kv := strings.SplitN(setting, "=", 2)
+		if len(kv) != 2 {
+			return "", limit, errors.Errorf("rate limit setting %q for %s must be of the form <key>=<value>",
+				setting, pkg)
+		}
+		switch kv[0] {
+		case "retries":
+			if limit.MaxRetries, err = strconv.Atoi(kv[1]); err == nil && limit.MaxRetries < 0 {
+				err = errors.New("must not be negative")
+			}
+		case "backoff":
+			limit.Backoff, err = time.ParseDuration(kv[1])
+		case "max-backoff":
+			limit.MaxBackoff, err = time.ParseDuration(kv[1])
+		default:
+			err = errors.New("unknown setting")
+		}
+		if err != nil {
+			return "", limit, errors.Wrapf(err, "rate limit setting %q for %s", setting, pkg)
+		}
+	}
+	return pkg, limit, nil
+}
+
+// rateLimiters holds the rate limiter for each provider package, creating them as they are first needed.
+type rateLimiters struct {
+	ctx      context.Context
+	limits   map[tokens.Package]RateLimit
+	lock     sync.Mutex
+	limiters map[tokens.Package]*rateLimiter
+}
+
+func newRateLimiters(ctx context.Context, limits map[tokens.Package]RateLimit) *rateLimiters {
+	return &rateLimiters{ctx: ctx, limits: limits, limiters: make(map[tokens.Package]*rateLimiter)}
+}
+
+// forPackage returns the rate limiter shared by all reads of resources managed by the given package.
+func (ls *rateLimiters) forPackage(pkg tokens.Package) *rateLimiter {
+	ls.lock.Lock()
+	defer ls.lock.Unlock()
+
+	l, has := ls.limiters[pkg]
+	if !has {
+		limit, has := ls.limits[pkg]
+		if !has {
+			limit = DefaultRateLimit
+		}
+		l = &rateLimiter{ctx: ls.ctx, pkg: pkg, limit: limit, sleep: sleep}
+		ls.limiters[pkg] = l
+	}
+	return l
+}
+
+// rateLimiter spaces out the reads of a single provider package so that they start no faster than its rate limit
+// allows.  When a read is throttled, all reads of the package are held back until its backoff has elapsed.
+type rateLimiter struct {
+	ctx   context.Context // the context whose cancellation abandons any wait.
+	pkg   tokens.Package
+	limit RateLimit
+	sleep func(context.Context, time.Duration) error // the function used to wait; replaceable for testing.
+
+	lock sync.Mutex
+	next time.Time // the earliest time at which the next read may start.
+}
+
+// sleep blocks until the given delay has elapsed or the given context is canceled, whichever comes first.  It returns
+// the context's error if the context was canceled.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// wait blocks until the next read may start.  It returns an error if the limiter's context is canceled first.
+func (l *rateLimiter) wait() error {
+	var interval time.Duration
+	if l.limit.RequestsPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / l.limit.RequestsPerSecond)
+	}
+
+	l.lock.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(interval)
+	l.lock.Unlock()
+
+	if delay := start.Sub(now); delay > 0 {
+		return l.sleep(l.ctx, delay)
+	}
+	return nil
+}
+
+// pause holds back every read of the package until the given delay has elapsed.
+func (l *rateLimiter) pause(delay time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if until := time.Now().Add(delay); until.After(l.next) {
+		l.next = until
+	}
+}
+
+// do performs the given read once the rate limit allows it, retrying it with exponential backoff for as long as the
+// provider reports that it is being throttled.
+func (l *rateLimiter) do(urn resource.URN,
+	read func() (resource.PropertyMap, resource.Status, error)) (resource.PropertyMap, resource.Status, error) {
+
+	backoff := l.limit.Backoff
+	for attempt := 0; ; attempt++ {
+		if err := l.wait(); err != nil {
+			return nil, resource.StatusOK, err
+		}
+		props, status, err := read()
+		if err == nil || !isThrottled(err) || attempt >= l.limit.MaxRetries {
+			return props, status, err
+		}
+
+		logging.Engine.V(5).Infof("Read of %s was throttled by %s; retrying in %v", urn, l.pkg, backoff)
+		l.pause(backoff)
+		if backoff *= 2; l.limit.MaxBackoff > 0 && backoff > l.limit.MaxBackoff {
+			backoff = l.limit.MaxBackoff
+		}
+	}
+}
+
+// isThrottled returns true if the given error from a provider indicates that the provider is being throttled.
+func isThrottled(err error) bool {
+	rpcErr, ok := rpcerror.FromError(err)
+	return ok && rpcErr.Code() == codes.ResourceExhausted
+}
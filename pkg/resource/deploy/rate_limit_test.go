@@ -0,0 +1,118 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil/rpcerror"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	pkg, limit, err := ParseRateLimit("aws=20")
+	assert.NoError(t, err)
+	assert.Equal(t, "aws", string(pkg))
+	assert.Equal(t, 20.0, limit.RequestsPerSecond)
+	assert.Equal(t, DefaultRateLimit.MaxRetries, limit.MaxRetries)
+
+	pkg, limit, err = ParseRateLimit("gcp=0.5,retries=8,backoff=2s,max-backoff=1m")
+	assert.NoError(t, err)
+	assert.Equal(t, "gcp", string(pkg))
+	assert.Equal(t, RateLimit{
+		RequestsPerSecond: 0.5,
+		MaxRetries:        8,
+		Backoff:           2 * time.Second,
+		MaxBackoff:        time.Minute,
+	}, limit)
+
+	for _, spec := range []string{"aws", "=20", "aws=fast", "aws=0", "aws=20,retries", "aws=20,retries=-1",
+		"aws=20,backoff=soon", "aws=20,jitter=1s"} {
+		_, _, err = ParseRateLimit(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	var slept []time.Duration
+	l := &rateLimiter{
+		ctx:   context.Background(),
+		pkg:   "pkgA",
+		limit: RateLimit{RequestsPerSecond: 10, MaxRetries: 3, Backoff: time.Second, MaxBackoff: 3 * time.Second},
+		sleep: func(_ context.Context, d time.Duration) error {
+			slept = append(slept, d)
+			return nil
+		},
+	}
+
+	// Reads that start together are spaced out according to the rate limit.
+	assert.NoError(t, l.wait())
+	assert.NoError(t, l.wait())
+	assert.NoError(t, l.wait())
+	if assert.Len(t, slept, 2) {
+		assert.InDelta(t, float64(100*time.Millisecond), float64(slept[0]), float64(10*time.Millisecond))
+		assert.InDelta(t, float64(200*time.Millisecond), float64(slept[1]), float64(10*time.Millisecond))
+	}
+
+	// Throttled reads are retried, up to the limit, after backing off.
+	attempts := 0
+	read := func() (resource.PropertyMap, resource.Status, error) {
+		attempts++
+		return nil, resource.StatusOK, rpcerror.New(codes.ResourceExhausted, "slow down")
+	}
+	slept = nil
+	_, _, err := l.do("urn", read)
+	assert.Error(t, err)
+	assert.Equal(t, 4, attempts)
+	if assert.Len(t, slept, 4) {
+		// The last of the three backoffs is capped at the maximum.
+		assert.True(t, slept[3] > 2*time.Second && slept[3] <= 3*time.Second, "%v", slept[3])
+	}
+
+	// Other errors are not retried.
+	attempts = 0
+	_, _, err = l.do("urn", func() (resource.PropertyMap, resource.Status, error) {
+		attempts++
+		return nil, resource.StatusOK, rpcerror.New(codes.Internal, "failed")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRateLimiterCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := newRateLimiters(ctx, map[tokens.Package]RateLimit{
+		"pkgA": {RequestsPerSecond: 0.001},
+	}).forPackage("pkgA")
+
+	// The first read starts immediately; the second would wait for a very long time, but gives up as soon as the
+	// context is canceled.
+	assert.NoError(t, l.wait())
+	cancel()
+
+	reads := 0
+	_, _, err := l.do("urn", func() (resource.PropertyMap, resource.Status, error) {
+		reads++
+		return nil, resource.StatusOK, nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, reads)
+}
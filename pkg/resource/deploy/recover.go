@@ -0,0 +1,249 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// PendingOperationRecovery describes how RecoverPendingOperations dealt with a single pending operation.
+type PendingOperationRecovery struct {
+	URN      resource.URN           // the resource that the operation affected.
+	Type     resource.OperationType // the kind of operation that was pending.
+	Resolved bool                   // true if the operation was removed from the snapshot.
+	Message  string                 // a human-readable description of what was found and done.
+}
+
+// RecoverPendingOperations reconciles each of the snapshot's pending operations with the actual state of its resource,
+// as read from the resource's provider, and patches the snapshot in place to match:
+//  1. Creating: if the resource exists, it is added to the snapshot (replacing any live copy, which is then marked for
+//     deletion).  As the ID of a resource that was being created is not usually known, the ID may be supplied by ids.
+//  2. Updating: the resource's outputs are refreshed, or it is removed from the snapshot if it no longer exists.
+//  3. Deleting: the resource is removed from the snapshot if it no longer exists.
+//  4. Reading: the resource is added to the snapshot if it exists.
+//
+// Operations whose outcome cannot be determined (for instance, because the ID of a resource that was being created is
+// not known) remain pending; the returned records explain why.  Providers are loaded using the given host.
+func (snap *Snapshot) RecoverPendingOperations(host plugin.Host,
+	ids map[resource.URN]resource.ID) ([]PendingOperationRecovery, error) {
+
+	contract.Require(host != nil, "host")
+	if snap == nil || len(snap.PendingOperations) == 0 {
+		return nil, nil
+	}
+
+	// Load just the providers that the pending operations need.
+	var provs []*resource.State
+	seen := make(map[*resource.State]bool)
+	for _, op := range snap.PendingOperations {
+		if prov := snap.findProvider(op.Resource); prov != nil && !seen[prov] {
+			provs, seen[prov] = append(provs, prov), true
+		}
+	}
+	reg, err := providers.NewRegistry(host, provs, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading providers")
+	}
+
+	var recoveries []PendingOperationRecovery
+	var remaining []resource.Operation
+	for _, op := range snap.PendingOperations {
+		recovery := PendingOperationRecovery{URN: op.Resource.URN, Type: op.Type}
+
+		var prov plugin.Provider
+		if ref, refErr := providers.ParseReference(op.Resource.Provider); refErr == nil {
+			prov, _ = reg.GetProvider(ref)
+		}
+		if prov == nil {
+			recovery.Message = fmt.Sprintf("could not find the provider '%s'", op.Resource.Provider)
+		} else {
+			recovery.Resolved, recovery.Message, err = snap.recoverOperation(prov, op, ids[op.Resource.URN])
+			if err != nil {
+				return nil, errors.Wrapf(err, "recovering pending %s operation on %s", op.Type, op.Resource.URN)
+			}
+		}
+
+		if !recovery.Resolved {
+			remaining = append(remaining, op)
+		}
+		recoveries = append(recoveries, recovery)
+	}
+	snap.PendingOperations = remaining
+
+	return recoveries, nil
+}
+
+// recoverOperation reconciles a single pending operation using its resource's provider.  It returns true if the
+// operation was resolved, along with a description of what was found and done.
+func (snap *Snapshot) recoverOperation(prov plugin.Provider, op resource.Operation,
+	hint resource.ID) (bool, string, error) {
+
+	res := op.Resource
+	live := snap.findLive(res.URN)
+
+	switch op.Type {
+	case resource.OperationTypeCreating, resource.OperationTypeReading:
+		id := res.ID
+		if hint != "" {
+			id = hint
+		}
+		if id == "" || id == providers.UnknownID {
+			return false, "the resource's ID is not known; supply it if the resource exists", nil
+		}
+
+		outs, err := readResource(prov, res.URN, id, res.Inputs())
+		if err != nil {
+			return false, "", err
+		}
+		if outs == nil {
+			return true, fmt.Sprintf("no resource with ID '%s' exists; discarded the operation", id), nil
+		}
+
+		if live != nil && live.ID == id {
+			// The live copy is the resource itself, so simply bring its state up to date.
+			live.SetOutputs(outs)
+			return true, fmt.Sprintf("resource with ID '%s' is already in the snapshot; refreshed its outputs", id), nil
+		}
+
+		// The operation's state is the resource as the engine meant it to be, so it can be added as it is.
+		res.ID, res.Delete = id, false
+		res.SetOutputs(outs)
+		snap.insert(res, live)
+		msg := fmt.Sprintf("resource with ID '%s' exists; added it to the snapshot", id)
+		if live != nil {
+			live.Delete = true
+			msg += fmt.Sprintf(" and marked the copy with ID '%s' for deletion", live.ID)
+		}
+		return true, msg, nil
+	case resource.OperationTypeUpdating:
+		if live == nil {
+			return false, "the resource is not in the snapshot", nil
+		}
+		outs, err := readResource(prov, live.URN, live.ID, live.Outputs())
+		if err != nil {
+			return false, "", err
+		}
+		if outs == nil {
+			snap.remove(live)
+			msg := fmt.Sprintf("resource with ID '%s' no longer exists; removed it from the snapshot", live.ID)
+			return true, msg, nil
+		}
+		live.SetOutputs(outs)
+		return true, fmt.Sprintf("refreshed the outputs of the resource with ID '%s'", live.ID), nil
+	case resource.OperationTypeDeleting:
+		target := snap.findByID(res.URN, res.ID)
+		if target == nil {
+			msg := fmt.Sprintf("resource with ID '%s' is not in the snapshot; discarded the operation", res.ID)
+			return true, msg, nil
+		}
+		outs, err := readResource(prov, target.URN, target.ID, target.Outputs())
+		if err != nil {
+			return false, "", err
+		}
+		if outs == nil {
+			snap.remove(target)
+			return true, fmt.Sprintf("resource with ID '%s' was deleted; removed it from the snapshot", target.ID), nil
+		}
+		return true, fmt.Sprintf("resource with ID '%s' still exists; kept it in the snapshot", target.ID), nil
+	default:
+		return false, fmt.Sprintf("unrecognized operation type '%s'", op.Type), nil
+	}
+}
+
+// readResource reads the live state of a resource, returning nil outputs if the resource does not exist.
+func readResource(prov plugin.Provider, urn resource.URN, id resource.ID,
+	props resource.PropertyMap) (resource.PropertyMap, error) {
+
+	outs, _, err := prov.Read(urn, id, props)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading resource with ID '%s'", id)
+	}
+	return outs, nil
+}
+
+// findProvider returns the provider resource referenced by the given resource, if it is in the snapshot.
+func (snap *Snapshot) findProvider(res *resource.State) *resource.State {
+	ref, err := providers.ParseReference(res.Provider)
+	if err != nil {
+		return nil
+	}
+	return snap.findByID(ref.URN(), ref.ID())
+}
+
+// findLive returns the copy of the given resource that isn't pending deletion, if any.
+func (snap *Snapshot) findLive(urn resource.URN) *resource.State {
+	for _, state := range snap.Resources {
+		if state.URN == urn && !state.Delete {
+			return state
+		}
+	}
+	return nil
+}
+
+// findByID returns the copy of the given resource with the given ID, if any.
+func (snap *Snapshot) findByID(urn resource.URN, id resource.ID) *resource.State {
+	for _, state := range snap.Resources {
+		if state.URN == urn && state.ID == id {
+			return state
+		}
+	}
+	return nil
+}
+
+// insert adds a resource to the snapshot after its parent, provider and dependencies, and ahead of the given older copy
+// of it if possible, as the engine writes the newest copy of a resource first.
+func (snap *Snapshot) insert(state, older *resource.State) {
+	deps := map[resource.URN]bool{state.Parent: true}
+	if ref, err := providers.ParseReference(state.Provider); err == nil {
+		deps[ref.URN()] = true
+	}
+	for _, dep := range state.Dependencies {
+		deps[dep] = true
+	}
+
+	index := 0
+	for i, other := range snap.Resources {
+		if deps[other.URN] {
+			index = i + 1
+		}
+	}
+	for i, other := range snap.Resources {
+		if other == older && i >= index {
+			index = i
+			break
+		}
+	}
+
+	snap.Resources = append(snap.Resources, nil)
+	copy(snap.Resources[index+1:], snap.Resources[index:])
+	snap.Resources[index] = state
+}
+
+// remove removes the given copy of a resource from the snapshot.
+func (snap *Snapshot) remove(state *resource.State) {
+	for i, other := range snap.Resources {
+		if other == state {
+			snap.Resources = append(snap.Resources[:i], snap.Resources[i+1:]...)
+			return
+		}
+	}
+}
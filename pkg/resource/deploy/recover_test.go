@@ -0,0 +1,99 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestRecoverPendingOperations(t *testing.T) {
+	// The provider reports that only the resources with these IDs exist.
+	existing := map[resource.ID]resource.PropertyMap{
+		"created-id": {"state": resource.NewStringProperty("created")},
+		"updated-id": {"state": resource.NewStringProperty("updated")},
+		"kept-id":    {"state": resource.NewStringProperty("kept")},
+	}
+	loader := deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+		return &deploytest.Provider{
+			ReadF: func(urn resource.URN, id resource.ID,
+				props resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
+				return existing[id], resource.StatusOK, nil
+			},
+		}, nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, nil, loader)
+
+	provType := providers.MakeProviderType("pkgA")
+	provURN := resource.NewURN("teststack", "pkg", "", provType, "default")
+	prov := resource.NewState(provType, provURN, true, false, "prov-id", resource.PropertyMap{}, nil, "", false,
+		false, nil, nil, "")
+	provRef, err := providers.NewReference(provURN, "prov-id")
+	assert.NoError(t, err)
+
+	newCustom := func(name string, id resource.ID) *resource.State {
+		ty := tokens.Type("pkgA:index:typ")
+		urn := resource.NewURN("teststack", "pkg", "", ty, tokens.QName(name))
+		return resource.NewState(ty, urn, true, false, id, resource.PropertyMap{}, resource.PropertyMap{}, "",
+			false, false, nil, nil, provRef.String())
+	}
+
+	// "created" was being created, and does exist; "lost" was being created, but its ID is unknown.
+	created, lost := newCustom("created", ""), newCustom("lost", "")
+	// "updated" was being updated; "gone" was being updated, but has since been deleted.
+	updated, gone := newCustom("updated", "updated-id"), newCustom("gone", "gone-id")
+	// "deleted" was being deleted, and was; "kept" was being deleted, but wasn't.
+	deleted, kept := newCustom("deleted", "deleted-id"), newCustom("kept", "kept-id")
+
+	snap := newSnapshot([]*resource.State{prov, updated, gone, deleted, kept}, []resource.Operation{
+		resource.NewOperation(created, resource.OperationTypeCreating),
+		resource.NewOperation(lost, resource.OperationTypeCreating),
+		resource.NewOperation(newCustom("updated", ""), resource.OperationTypeUpdating),
+		resource.NewOperation(newCustom("gone", ""), resource.OperationTypeUpdating),
+		resource.NewOperation(newCustom("deleted", "deleted-id"), resource.OperationTypeDeleting),
+		resource.NewOperation(newCustom("kept", "kept-id"), resource.OperationTypeDeleting),
+	})
+
+	recoveries, err := snap.RecoverPendingOperations(host, map[resource.URN]resource.ID{created.URN: "created-id"})
+	assert.NoError(t, err)
+	if assert.Len(t, recoveries, 6) {
+		for _, r := range recoveries {
+			assert.Equal(t, r.URN != lost.URN, r.Resolved, "%s: %s", r.URN, r.Message)
+		}
+	}
+
+	// Only the creation whose ID is unknown remains pending.
+	if assert.Len(t, snap.PendingOperations, 1) {
+		assert.Equal(t, lost.URN, snap.PendingOperations[0].Resource.URN)
+	}
+
+	var urns []resource.URN
+	for _, res := range snap.Resources {
+		urns = append(urns, res.URN)
+	}
+	assert.Equal(t, []resource.URN{provURN, created.URN, updated.URN, kept.URN}, urns)
+	assert.Equal(t, resource.ID("created-id"), snap.Resources[1].ID)
+	assert.Equal(t, "created", snap.Resources[1].Outputs()["state"].StringValue())
+	assert.Equal(t, "updated", updated.Outputs()["state"].StringValue())
+	assert.NoError(t, snap.VerifyIntegrity())
+}
@@ -0,0 +1,101 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+)
+
+// replaceOnChangesAll is the path in a resource's `replaceOnChanges` option that matches every input property.
+const replaceOnChangesAll = "*"
+
+// replaceOnChangesReason explains a replacement required by a resource's `replaceOnChanges` option.
+const replaceOnChangesReason = "changed and is listed in replaceOnChanges"
+
+// checkReplaceOnChanges returns an error if any of the given `replaceOnChanges` paths is malformed.
+func checkReplaceOnChanges(paths []string) error {
+	for _, path := range paths {
+		if path == replaceOnChangesAll {
+			continue
+		}
+		if _, err := resource.ParsePropertyPath(path); err != nil {
+			return errors.Wrapf(err, "invalid replaceOnChanges path '%s'", path)
+		}
+	}
+	return nil
+}
+
+// replaceOnChangesKeys returns the top-level keys of the input properties whose values at any of the given paths
+// differ between the old and new inputs.  A value that is unknown in the new inputs may differ, and so counts.
+func replaceOnChangesKeys(paths []string, oldInputs, newInputs resource.PropertyMap) []resource.PropertyKey {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	changed := make(map[resource.PropertyKey]bool)
+	for _, p := range paths {
+		if p == replaceOnChangesAll {
+			if d := oldInputs.Diff(newInputs); d != nil {
+				for _, k := range d.Keys() {
+					if d.Changed(k) {
+						changed[k] = true
+					}
+				}
+			}
+			continue
+		}
+
+		path, err := resource.ParsePropertyPath(p)
+		if err != nil {
+			// Paths are checked when resources are registered, so only old states can hold bad ones.
+			continue
+		}
+		oldValue, hadOld := path.Get(oldInputs)
+		newValue, hasNew := path.Get(newInputs)
+		if hadOld != hasNew || (hasNew && (newValue.ContainsUnknowns() || !oldValue.DeepEquals(newValue))) {
+			changed[resource.PropertyKey(path[0].(string))] = true
+		}
+	}
+
+	var keys []resource.PropertyKey
+	for k := range changed {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// replaceOnChangesReplacement turns a diff into one that describes a replacement because of changes to the given
+// keys, which a resource's `replaceOnChanges` option lists.
+func replaceOnChangesReplacement(diff plugin.DiffResult, keys []resource.PropertyKey) plugin.DiffResult {
+	diff.Changes = plugin.DiffSome
+	diff.ReplaceKeys = append(diff.ReplaceKeys, keys...)
+	reasons := make(map[resource.PropertyKey]string)
+	for k, reason := range diff.ReplaceReasons {
+		reasons[k] = reason
+	}
+	for _, k := range keys {
+		if _, has := reasons[k]; !has {
+			reasons[k] = replaceOnChangesReason
+		}
+	}
+	diff.ReplaceReasons = reasons
+	return diff
+}
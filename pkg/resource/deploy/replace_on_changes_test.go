@@ -0,0 +1,65 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+)
+
+func TestCheckReplaceOnChanges(t *testing.T) {
+	assert.NoError(t, checkReplaceOnChanges(nil))
+	assert.NoError(t, checkReplaceOnChanges([]string{"*", "a", "a.b[0].c"}))
+	assert.Error(t, checkReplaceOnChanges([]string{"a..b"}))
+	assert.Error(t, checkReplaceOnChanges([]string{""}))
+}
+
+func TestReplaceOnChangesKeys(t *testing.T) {
+	olds := resource.NewPropertyMapFromMap(map[string]interface{}{
+		"name": "a",
+		"spec": map[string]interface{}{"image": "nginx:1.14", "replicas": 1},
+		"tags": []interface{}{"x", "y"},
+	})
+	news := resource.NewPropertyMapFromMap(map[string]interface{}{
+		"name": "b",
+		"spec": map[string]interface{}{"image": "nginx:1.14", "replicas": 2},
+		"tags": []interface{}{"x", "z"},
+	})
+
+	assert.Empty(t, replaceOnChangesKeys(nil, olds, news))
+	assert.Empty(t, replaceOnChangesKeys([]string{"spec.image", "tags[0]"}, olds, news))
+	assert.Equal(t, []resource.PropertyKey{"spec", "tags"},
+		replaceOnChangesKeys([]string{"spec.replicas", "tags[1]"}, olds, news))
+	assert.Equal(t, []resource.PropertyKey{"name", "spec", "tags"}, replaceOnChangesKeys([]string{"*"}, olds, news))
+
+	// Properties that appear or disappear have changed, and so may those whose new values are unknown.
+	assert.Equal(t, []resource.PropertyKey{"spec"}, replaceOnChangesKeys([]string{"spec.volumes"}, olds,
+		resource.NewPropertyMapFromMap(map[string]interface{}{"spec": map[string]interface{}{"volumes": 1}})))
+	unknown := news.Copy()
+	unknown["name"] = resource.MakeComputed(resource.NewStringProperty(""))
+	assert.Equal(t, []resource.PropertyKey{"name"}, replaceOnChangesKeys([]string{"name"}, olds, unknown))
+}
+
+func TestReplaceOnChangesReplacement(t *testing.T) {
+	diff := replaceOnChangesReplacement(plugin.DiffResult{Changes: plugin.DiffNone}, []resource.PropertyKey{"spec"})
+	assert.Equal(t, plugin.DiffSome, diff.Changes)
+	assert.True(t, diff.Replace())
+	assert.Equal(t, []resource.PropertyKey{"spec"}, diff.ReplaceKeys)
+	assert.Equal(t, replaceOnChangesReason, diff.ReplaceReasons["spec"])
+}
@@ -40,6 +40,8 @@ type Manifest struct {
 	Time    time.Time              // the time this snapshot was taken.
 	Magic   string                 // a magic cookie.
 	Version string                 // the pulumi command version.
+	OS      string                 // the operating system on which the pulumi command ran, e.g. `linux`.
+	Arch    string                 // the architecture on which the pulumi command ran, e.g. `amd64`.
 	Plugins []workspace.PluginInfo // the plugin versions also loaded.
 }
 
@@ -62,6 +64,42 @@ func NewSnapshot(manifest Manifest, resources []*resource.State, ops []resource.
 	}
 }
 
+// ResourceIterator enumerates the resources of a snapshot in order.  It yields the snapshot's own states rather than
+// copies of them, so that even very large snapshots may be walked without allocating; callers must therefore not
+// retain or modify the states unless they own the snapshot.
+type ResourceIterator struct {
+	resources []*resource.State
+	live      bool
+	next      int
+}
+
+// Iterate returns an iterator over all of the snapshot's resources, including those that are pending deletion.
+func (snap *Snapshot) Iterate() *ResourceIterator {
+	if snap == nil {
+		return &ResourceIterator{}
+	}
+	return &ResourceIterator{resources: snap.Resources}
+}
+
+// IterateLive returns an iterator over the snapshot's resources that are not pending deletion.
+func (snap *Snapshot) IterateLive() *ResourceIterator {
+	iter := snap.Iterate()
+	iter.live = true
+	return iter
+}
+
+// Next returns the next resource, or false if there are no more.
+func (iter *ResourceIterator) Next() (*resource.State, bool) {
+	for iter.next < len(iter.resources) {
+		res := iter.resources[iter.next]
+		iter.next++
+		if !iter.live || !res.Delete {
+			return res, true
+		}
+	}
+	return nil, false
+}
+
 // VerifyIntegrity checks a snapshot to ensure it is well-formed.  Because of the cost of this operation,
 // integrity verification is only performed on demand, and not automatically during snapshot construction.
 //
@@ -81,7 +119,7 @@ func (snap *Snapshot) VerifyIntegrity() error {
 
 		// Now check the resources.  For now, we just verify that parents come before children, and that there aren't
 		// any duplicate URNs.
-		urns := make(map[resource.URN]*resource.State)
+		urns := make(map[resource.URN]*resource.State, len(snap.Resources))
 		provs := make(map[providers.Reference]struct{})
 		for i, state := range snap.Resources {
 			urn := state.URN
@@ -140,3 +178,179 @@ func (snap *Snapshot) VerifyIntegrity() error {
 
 	return nil
 }
+
+// VerifyInvariants performs the checks of VerifyIntegrity along with a number of stricter, more expensive checks of
+// the invariants that the engine maintains as it builds snapshots.  It is intended for use in tests and when debugging
+// state corruption, rather than on every update.
+func (snap *Snapshot) VerifyInvariants() error {
+	if err := snap.VerifyIntegrity(); err != nil {
+		return err
+	}
+	if snap == nil {
+		return nil
+	}
+
+	live := make(map[resource.URN]bool, len(snap.Resources))
+	for _, state := range snap.Resources {
+		urn := state.URN
+		if !urn.IsValid() {
+			return errors.Errorf("resource %s has a malformed URN", urn)
+		}
+		if state.Type != urn.Type() {
+			return errors.Errorf("resource %s has type %s, which does not match its URN", urn, state.Type)
+		}
+		if state.Inputs() == nil {
+			return errors.Errorf("resource %s has no inputs", urn)
+		}
+		if state.Custom {
+			// Custom resources are only recorded once their providers have assigned them IDs.
+			if state.ID == "" {
+				return errors.Errorf("custom resource %s has no ID", urn)
+			}
+		} else if state.ID != "" || state.Provider != "" {
+			return errors.Errorf("component resource %s has an ID or a provider", urn)
+		}
+
+		// Every explicit dependency must also be a dependency, or it would not be honored when ordering operations.
+		for _, dep := range state.DependsOn {
+			found := false
+			for _, d := range state.Dependencies {
+				found = found || d == dep
+			}
+			if !found {
+				return errors.Errorf("resource %s's explicit dependency %s is not among its dependencies", urn, dep)
+			}
+		}
+
+		// At most one copy of a resource may be live; any others must be pending deletion.
+		if !state.Delete {
+			if live[urn] {
+				return errors.Errorf("resource %s is live more than once", urn)
+			}
+			live[urn] = true
+		}
+	}
+
+	pending := make(map[*resource.State]bool)
+	for _, op := range snap.PendingOperations {
+		if op.Resource == nil {
+			return errors.Errorf("pending %s operation has no resource", op.Type)
+		}
+		if pending[op.Resource] {
+			return errors.Errorf("resource %s has more than one pending operation", op.Resource.URN)
+		}
+		pending[op.Resource] = true
+	}
+
+	return nil
+}
+
+// SnapshotRepair describes a single mutation made to a snapshot by Repair.
+type SnapshotRepair struct {
+	URN     resource.URN // the resource that was changed.
+	Message string       // a human-readable description of the change.
+}
+
+// Repair fixes well-understood corruptions in a snapshot in place, returning a record of every mutation made.  It
+// handles the following cases:
+//  1. Duplicate URNs left behind by interrupted replacements: all but the newest copy are marked for deletion
+//  2. Resources marked for deletion with no live copy: the newest copy is unmarked, since nothing replaced it
+//  3. Dependencies on resources that are no longer in the snapshot: the dependency is removed
+//  4. Dependencies of live resources that only copies pending deletion satisfy: the dependency is removed, since
+//     those copies are about to be deleted and the live copy, if any, does not come first
+//
+// Other integrity problems (such as ordering errors) are left alone; callers should use VerifyIntegrity afterwards to
+// see if any remain.
+func (snap *Snapshot) Repair() []SnapshotRepair {
+	if snap == nil {
+		return nil
+	}
+
+	var repairs []SnapshotRepair
+
+	// Group all copies of each URN, preserving snapshot order.  The engine writes the states it produces ahead of any
+	// states that it carries over from the previous snapshot, so the first copy of a URN is the newest.
+	var urns []resource.URN
+	copies := make(map[resource.URN][]*resource.State)
+	for _, state := range snap.Resources {
+		if _, has := copies[state.URN]; !has {
+			urns = append(urns, state.URN)
+		}
+		copies[state.URN] = append(copies[state.URN], state)
+	}
+
+	for _, urn := range urns {
+		var live []*resource.State
+		for _, state := range copies[urn] {
+			if !state.Delete {
+				live = append(live, state)
+			}
+		}
+
+		switch {
+		case len(live) > 1:
+			for _, state := range live[1:] {
+				state.Delete = true
+				repairs = append(repairs, SnapshotRepair{
+					URN:     urn,
+					Message: fmt.Sprintf("marked duplicate copy with ID '%s' for deletion", state.ID),
+				})
+			}
+		case len(live) == 0:
+			newest := copies[urn][0]
+			newest.Delete = false
+			repairs = append(repairs, SnapshotRepair{
+				URN:     urn,
+				Message: fmt.Sprintf("cleared pending deletion of copy with ID '%s'; nothing replaced it", newest.ID),
+			})
+		}
+	}
+
+	// Walk the resources in order, recording which URNs have had live copies and copies pending deletion so far, so
+	// that each live resource's dependencies can be checked against the copies that come ahead of it.
+	seenLive, seenDeleted := make(map[resource.URN]bool, len(urns)), make(map[resource.URN]bool)
+	for _, state := range snap.Resources {
+		var deps []resource.URN
+		for _, dep := range state.Dependencies {
+			if _, has := copies[dep]; !has {
+				repairs = append(repairs, SnapshotRepair{
+					URN:     state.URN,
+					Message: fmt.Sprintf("removed dependency on missing resource %s", dep),
+				})
+				continue
+			}
+			if !state.Delete && !seenLive[dep] && seenDeleted[dep] {
+				repairs = append(repairs, SnapshotRepair{
+					URN:     state.URN,
+					Message: fmt.Sprintf("removed dependency on %s, whose earlier copies are all pending deletion", dep),
+				})
+				continue
+			}
+			deps = append(deps, dep)
+		}
+		if len(deps) != len(state.Dependencies) {
+			state.Dependencies = deps
+		}
+		if state.Delete {
+			seenDeleted[state.URN] = true
+		} else {
+			seenLive[state.URN] = true
+		}
+
+		// Explicit dependencies are a subset of the dependencies, so any removed above were already reported.
+		var dependsOn []resource.URN
+		for _, dep := range state.DependsOn {
+			for _, d := range deps {
+				if d == dep {
+					dependsOn = append(dependsOn, dep)
+					break
+				}
+			}
+		}
+		if len(dependsOn) != len(state.DependsOn) {
+			state.DependsOn = dependsOn
+		}
+	}
+
+	return repairs
+}
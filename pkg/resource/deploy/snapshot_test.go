@@ -0,0 +1,147 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairDuplicateURNs(t *testing.T) {
+	old := newResource("a")
+	old.ID = "old"
+	replacement := newResource("a")
+	replacement.ID = "new"
+	snap := newSnapshot([]*resource.State{replacement, old}, nil)
+
+	repairs := snap.Repair()
+	assert.Len(t, repairs, 1)
+	assert.Equal(t, old.URN, repairs[0].URN)
+	assert.True(t, old.Delete)
+	assert.False(t, replacement.Delete)
+	assert.NoError(t, snap.VerifyIntegrity())
+}
+
+func TestRepairOrphanedDelete(t *testing.T) {
+	a := newResource("a")
+	a.Delete = true
+	b := newResource("b")
+	b.ID = "old"
+	b.Delete = true
+	newB := newResource("b")
+	newB.ID = "new"
+	snap := newSnapshot([]*resource.State{a, newB, b}, nil)
+
+	repairs := snap.Repair()
+	assert.Len(t, repairs, 1)
+	assert.Equal(t, a.URN, repairs[0].URN)
+	assert.False(t, a.Delete)
+	assert.True(t, b.Delete)
+	assert.False(t, newB.Delete)
+	assert.NoError(t, snap.VerifyIntegrity())
+}
+
+func TestRepairMissingDependencies(t *testing.T) {
+	a := newResource("a")
+	b := newResource("b")
+	b.Dependencies = []resource.URN{a.URN, newResource("gone").URN}
+	snap := newSnapshot([]*resource.State{a, b}, nil)
+	assert.Error(t, snap.VerifyIntegrity())
+
+	repairs := snap.Repair()
+	assert.Len(t, repairs, 1)
+	assert.Equal(t, b.URN, repairs[0].URN)
+	assert.Equal(t, []resource.URN{a.URN}, b.Dependencies)
+	assert.NoError(t, snap.VerifyIntegrity())
+}
+
+func TestRepairPendingDeleteDependencies(t *testing.T) {
+	oldA := newResource("a")
+	oldA.ID = "old"
+	oldA.Delete = true
+	newA := newResource("a")
+	newA.ID = "new"
+	b := newResource("b")
+	b.Dependencies = []resource.URN{oldA.URN}
+	b.DependsOn = []resource.URN{oldA.URN}
+	c := newResource("c")
+	c.Dependencies = []resource.URN{oldA.URN}
+	snap := newSnapshot([]*resource.State{oldA, b, newA, c}, nil)
+
+	// b comes before the live copy of a, so only the copy pending deletion satisfies its dependency; c is fine.
+	repairs := snap.Repair()
+	assert.Len(t, repairs, 1)
+	assert.Equal(t, b.URN, repairs[0].URN)
+	assert.Empty(t, b.Dependencies)
+	assert.Empty(t, b.DependsOn)
+	assert.Equal(t, []resource.URN{oldA.URN}, c.Dependencies)
+}
+
+func TestRepairHealthySnapshot(t *testing.T) {
+	a := newResource("a")
+	b := newResource("b")
+	b.Dependencies = []resource.URN{a.URN}
+	snap := newSnapshot([]*resource.State{a, b}, nil)
+
+	assert.Empty(t, snap.Repair())
+	assert.NoError(t, snap.VerifyIntegrity())
+}
+
+func TestVerifyInvariants(t *testing.T) {
+	a := newResource("a")
+	b := newResource("b")
+	b.Dependencies = []resource.URN{a.URN}
+	assert.NoError(t, newSnapshot([]*resource.State{a, b}, nil).VerifyInvariants())
+
+	// Custom resources must have IDs.
+	a.Custom = true
+	assert.Error(t, newSnapshot([]*resource.State{a, b}, nil).VerifyInvariants())
+	a.ID = "a-id"
+	assert.NoError(t, newSnapshot([]*resource.State{a, b}, nil).VerifyInvariants())
+
+	// Component resources must not.
+	b.ID = "b-id"
+	assert.Error(t, newSnapshot([]*resource.State{a, b}, nil).VerifyInvariants())
+	b.ID = ""
+
+	// A resource may not have more than one pending operation.
+	ops := []resource.Operation{
+		resource.NewOperation(b, resource.OperationTypeUpdating),
+		resource.NewOperation(b, resource.OperationTypeDeleting),
+	}
+	assert.Error(t, newSnapshot([]*resource.State{a, b}, ops).VerifyInvariants())
+	assert.NoError(t, newSnapshot([]*resource.State{a, b}, ops[:1]).VerifyInvariants())
+}
+
+func TestIterate(t *testing.T) {
+	old, replacement, b := newResource("a"), newResource("a"), newResource("b")
+	old.Delete = true
+	snap := newSnapshot([]*resource.State{replacement, old, b}, nil)
+
+	collect := func(iter *ResourceIterator) []*resource.State {
+		var resources []*resource.State
+		for res, ok := iter.Next(); ok; res, ok = iter.Next() {
+			resources = append(resources, res)
+		}
+		return resources
+	}
+	assert.Equal(t, []*resource.State{replacement, old, b}, collect(snap.Iterate()))
+	assert.Equal(t, []*resource.State{replacement, b}, collect(snap.IterateLive()))
+
+	var none *Snapshot
+	assert.Empty(t, collect(none.Iterate()))
+}
@@ -24,6 +24,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 
+	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
@@ -39,6 +40,7 @@ import (
 // EvalRunInfo provides information required to execute and deploy resources within a package.
 type EvalRunInfo struct {
 	Proj    *workspace.Project `json:"proj" yaml:"proj"`                         // the package metadata.
+	Root    string             `json:"root,omitempty" yaml:"root,omitempty"`     // the package's root directory.
 	Pwd     string             `json:"pwd" yaml:"pwd"`                           // the package's working directory.
 	Program string             `json:"program" yaml:"program"`                   // the path to the program.
 	Args    []string           `json:"args,omitempty" yaml:"args,omitempty"`     // any arguments to pass to the package.
@@ -139,24 +141,24 @@ func (iter *evalSourceIterator) Next() (SourceEvent, error) {
 	case reg := <-iter.regChan:
 		contract.Assert(reg != nil)
 		goal := reg.Goal()
-		logging.V(5).Infof("EvalSourceIterator produced a registration: t=%v,name=%v,#props=%v",
+		logging.Engine.V(5).Infof("EvalSourceIterator produced a registration: t=%v,name=%v,#props=%v",
 			goal.Type, goal.Name, len(goal.Properties))
 		return reg, nil
 	case regOut := <-iter.regOutChan:
 		contract.Assert(regOut != nil)
-		logging.V(5).Infof("EvalSourceIterator produced a completion: urn=%v,#outs=%v",
+		logging.Engine.V(5).Infof("EvalSourceIterator produced a completion: urn=%v,#outs=%v",
 			regOut.URN(), len(regOut.Outputs()))
 		return regOut, nil
 	case read := <-iter.regReadChan:
 		contract.Assert(read != nil)
-		logging.V(5).Infoln("EvalSourceIterator produced a read")
+		logging.Engine.V(5).Infoln("EvalSourceIterator produced a read")
 		return read, nil
 	case err := <-iter.finChan:
 		// If we are finished, we can safely exit.  The contract with the language provider is that this implies
 		// that the language runtime has exited and so calling Close on the plugin is fine.
 		iter.done = true
 		if err != nil {
-			logging.V(5).Infof("EvalSourceIterator ended with an error: %v", err)
+			logging.Engine.V(5).Infof("EvalSourceIterator ended with an error: %v", err)
 		}
 		return nil, err
 	}
@@ -210,13 +212,37 @@ func (iter *evalSourceIterator) forkRun(opts Options) {
 	}()
 }
 
+// DefaultProviderConfig returns the configuration of the default provider for the given package: the project's
+// defaults for the package's provider, overridden by the stack's own configuration for the package.
+func DefaultProviderConfig(proj *workspace.Project, config plugin.ConfigSource,
+	pkg tokens.Package) (resource.PropertyMap, error) {
+
+	cfg, err := config.GetPackageConfig(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make(resource.PropertyMap)
+	for k, v := range proj.ProviderConfig(string(pkg)) {
+		inputs[resource.PropertyKey(k)] = resource.NewStringProperty(v)
+	}
+	for k, v := range cfg {
+		inputs[resource.PropertyKey(k.Name())] = resource.NewStringProperty(v)
+	}
+	return inputs, nil
+}
+
 // defaultProviders manages the registration of default providers. The default provider for a package is the provider
 // resource that will be used to manage resources that do not explicitly reference a provider. Default providers will
 // only be registered for packages that are used by resources registered by the user's Pulumi program.
 type defaultProviders struct {
 	versions  map[tokens.Package]*semver.Version
 	providers map[tokens.Package]providers.Reference
+	failures  map[tokens.Package]error
+	project   *workspace.Project
 	config    plugin.ConfigSource
+	profiles  map[string]*workspace.CredentialProfile
+	provs     ProviderSource
 
 	requests chan defaultProviderRequest
 	regChan  chan<- *registerResourceEvent
@@ -238,16 +264,16 @@ type defaultProviderRequest struct {
 func (d *defaultProviders) newRegisterDefaultProviderEvent(
 	pkg tokens.Package) (*registerResourceEvent, <-chan *RegisterResult, error) {
 
-	// Attempt to get the config for the package.
-	cfg, err := d.config.GetPackageConfig(pkg)
+	// Create the inputs for the provider resource from the package's configuration.
+	inputs, err := DefaultProviderConfig(d.project, d.config, pkg)
 	if err != nil {
 		return nil, nil, err
 	}
-
-	// Create the inputs for the provider resource.
-	inputs := make(resource.PropertyMap)
-	for k, v := range cfg {
-		inputs[resource.PropertyKey(k.Name())] = resource.NewStringProperty(v)
+	// If the stack selects credentials for this package, they take precedence over its ordinary configuration.
+	if profile := d.profiles[string(pkg)]; profile != nil {
+		for k, v := range profile.Config {
+			inputs[resource.PropertyKey(k)] = resource.NewStringProperty(v)
+		}
 	}
 	if version := d.versions[pkg]; version != nil {
 		inputs["version"] = resource.NewStringProperty(version.String())
@@ -270,11 +296,11 @@ func (d *defaultProviders) newRegisterDefaultProviderEvent(
 // Note that this function must not be called from two goroutines concurrently; it is the responsibility of d.serve()
 // to ensure this.
 func (d *defaultProviders) handleRequest(pkg tokens.Package) (providers.Reference, error) {
-	logging.V(5).Infof("handling default provider request for package %s", pkg)
+	logging.Engine.V(5).Infof("handling default provider request for package %s", pkg)
 
 	ref, ok := d.providers[pkg]
 	if ok {
-		return ref, nil
+		return ref, d.failures[pkg]
 	}
 
 	event, done, err := d.newRegisterDefaultProviderEvent(pkg)
@@ -288,7 +314,7 @@ func (d *defaultProviders) handleRequest(pkg tokens.Package) (providers.Referenc
 		return providers.Reference{}, context.Canceled
 	}
 
-	logging.V(5).Infof("waiting for default provider for package %s", pkg)
+	logging.Engine.V(5).Infof("waiting for default provider for package %s", pkg)
 
 	var result *RegisterResult
 	select {
@@ -297,7 +323,7 @@ func (d *defaultProviders) handleRequest(pkg tokens.Package) (providers.Referenc
 		return providers.Reference{}, context.Canceled
 	}
 
-	logging.V(5).Infof("registered default provider for package %s: %s", pkg, result.State.URN)
+	logging.Engine.V(5).Infof("registered default provider for package %s: %s", pkg, result.State.URN)
 
 	id := result.State.ID
 	if id == "" {
@@ -308,9 +334,76 @@ func (d *defaultProviders) handleRequest(pkg tokens.Package) (providers.Referenc
 	contract.Assert(err == nil)
 	d.providers[pkg] = ref
 
+	// Refuse to hand out a provider whose credentials do not belong to the identity the stack expects.  The failure is
+	// remembered so that every later resource that would use this provider fails the same way.
+	if err = d.checkIdentity(pkg, ref); err != nil {
+		d.failures[pkg] = err
+		return providers.Reference{}, err
+	}
+
 	return ref, nil
 }
 
+// identityCheck names a function, and the field of its result, that reports the identity of a provider's credentials.
+type identityCheck struct {
+	function tokens.ModuleMember
+	field    resource.PropertyKey
+}
+
+// defaultIdentityChecks are the identity checks for packages whose credential profiles need not name one themselves.
+var defaultIdentityChecks = map[tokens.Package]identityCheck{
+	"aws":   {function: "aws:index/getCallerIdentity:getCallerIdentity", field: "accountId"},
+	"azure": {function: "azure:core/getClientConfig:getClientConfig", field: "subscriptionId"},
+	"gcp":   {function: "gcp:organizations/getClientConfig:getClientConfig", field: "project"},
+}
+
+// checkIdentity ensures that the indicated default provider is using credentials that belong to the identity that the
+// stack's credential profile for its package expects, if there is one.
+func (d *defaultProviders) checkIdentity(pkg tokens.Package, ref providers.Reference) error {
+	profile := d.profiles[string(pkg)]
+	if profile == nil || profile.ExpectedIdentity == "" {
+		return nil
+	}
+
+	check, has := defaultIdentityChecks[pkg]
+	if profile.IdentityFunction != "" {
+		check = identityCheck{
+			function: tokens.ModuleMember(profile.IdentityFunction),
+			field:    resource.PropertyKey(profile.IdentityField),
+		}
+	} else if !has {
+		return errors.Errorf("the credential profile for package %s must set identityFunction and identityField "+
+			"to check that its credentials belong to %s", pkg, profile.ExpectedIdentity)
+	}
+	if check.field == "" {
+		return errors.Errorf("the credential profile for package %s sets identityFunction but not identityField", pkg)
+	}
+
+	prov, ok := d.provs.GetProvider(ref)
+	contract.Assertf(ok, "default provider %v was not loaded", ref)
+
+	logging.Engine.V(5).Infof("checking the identity of default provider %v using %s", ref, check.function)
+	ret, failures, err := prov.Invoke(check.function, resource.PropertyMap{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to determine the identity of the default %s provider's credentials", pkg)
+	}
+	if len(failures) > 0 {
+		return errors.Errorf("failed to determine the identity of the default %s provider's credentials: %s",
+			pkg, failures[0].Reason)
+	}
+
+	actual := ret[check.field]
+	if !actual.IsString() {
+		return errors.Errorf("%s did not return a '%s' identifying the default %s provider's credentials",
+			check.function, check.field, pkg)
+	}
+	if actual.StringValue() != profile.ExpectedIdentity {
+		return errors.Errorf("refusing to use the default %s provider: its credentials belong to %s, "+
+			"but the stack expects %s", pkg, actual.StringValue(), profile.ExpectedIdentity)
+	}
+	return nil
+}
+
 // serve is the primary loop responsible for handling default provider requests.
 func (d *defaultProviders) serve() {
 	for {
@@ -365,7 +458,11 @@ func newResourceMonitor(src *evalSource, provs ProviderSource, regChan chan *reg
 	d := &defaultProviders{
 		versions:  src.defaultProviderVersions,
 		providers: make(map[tokens.Package]providers.Reference),
+		failures:  make(map[tokens.Package]error),
+		project:   src.runinfo.Proj,
 		config:    src.runinfo.Target,
+		profiles:  src.runinfo.Target.CredentialProfiles,
+		provs:     provs,
 		requests:  make(chan defaultProviderRequest),
 		regChan:   regChan,
 		cancel:    cancel,
@@ -450,8 +547,23 @@ func (rm *resmon) getProvider(pkg tokens.Package, rawProviderRef string) (plugin
 	return provider, nil
 }
 
+// selfKey is the name of the argument that carries the resource whose method is being called.
+const selfKey = "__self__"
+
 // Invoke performs an invocation of a member located in a resource provider.
 func (rm *resmon) Invoke(ctx context.Context, req *pulumirpc.InvokeRequest) (*pulumirpc.InvokeResponse, error) {
+	return rm.invoke(req, false)
+}
+
+// Call performs a call of a method of an existing resource located in a resource provider.  The resource is passed in
+// the `__self__` argument.  If the resource or any other argument is not yet known, as may happen during a preview,
+// the provider is not consulted and the response carries no return value, which indicates that the result is unknown.
+func (rm *resmon) Call(ctx context.Context, req *pulumirpc.InvokeRequest) (*pulumirpc.InvokeResponse, error) {
+	return rm.invoke(req, true)
+}
+
+// invoke implements both Invoke and Call, which differ only in the provider method they use.
+func (rm *resmon) invoke(req *pulumirpc.InvokeRequest, call bool) (*pulumirpc.InvokeResponse, error) {
 	// Fetch the token and load up the resource provider if necessary.
 	tok := tokens.ModuleMember(req.GetTok())
 
@@ -460,7 +572,11 @@ func (rm *resmon) Invoke(ctx context.Context, req *pulumirpc.InvokeRequest) (*pu
 		return nil, err
 	}
 
-	label := fmt.Sprintf("ResourceMonitor.Invoke(%s)", tok)
+	op, method := "Invoke", prov.Invoke
+	if call {
+		op, method = "Call", prov.Call
+	}
+	label := fmt.Sprintf("ResourceMonitor.%s(%s)", op, tok)
 
 	args, err := plugin.UnmarshalProperties(
 		req.GetArgs(), plugin.MarshalOptions{Label: label, KeepUnknowns: true})
@@ -468,9 +584,19 @@ func (rm *resmon) Invoke(ctx context.Context, req *pulumirpc.InvokeRequest) (*pu
 		return nil, errors.Wrapf(err, "failed to unmarshal %v args", tok)
 	}
 
+	if call {
+		if _, has := args[selfKey]; !has {
+			return nil, errors.Errorf("call of %v is missing its %s argument", tok, selfKey)
+		}
+		if args.ContainsUnknowns() {
+			logging.Engine.V(5).Infof("%s: arguments contain unknowns; result is unknown", label)
+			return &pulumirpc.InvokeResponse{}, nil
+		}
+	}
+
 	// Do the invoke and then return the arguments.
-	logging.V(5).Infof("ResourceMonitor.Invoke received: tok=%v #args=%v", tok, len(args))
-	ret, failures, err := prov.Invoke(tok, args)
+	logging.Engine.V(5).Infof("ResourceMonitor.%s received: tok=%v #args=%v", op, tok, len(args))
+	ret, failures, err := method(tok, args)
 	if err != nil {
 		return nil, errors.Wrapf(err, "invocation of %v returned an error", tok)
 	}
@@ -533,7 +659,7 @@ func (rm *resmon) ReadResource(ctx context.Context,
 	select {
 	case rm.regReadChan <- event:
 	case <-rm.cancel:
-		logging.V(5).Infof("ResourceMonitor.ReadResource operation canceled, name=%s", name)
+		logging.Engine.V(5).Infof("ResourceMonitor.ReadResource operation canceled, name=%s", name)
 		return nil, rpcerror.New(codes.Unavailable, "resource monitor shut down while sending resource registration")
 	}
 
@@ -542,12 +668,12 @@ func (rm *resmon) ReadResource(ctx context.Context,
 	select {
 	case result = <-event.done:
 	case <-rm.cancel:
-		logging.V(5).Infof("ResourceMonitor.ReadResource operation canceled, name=%s", name)
+		logging.Engine.V(5).Infof("ResourceMonitor.ReadResource operation canceled, name=%s", name)
 		return nil, rpcerror.New(codes.Unavailable, "resource monitor shut down while waiting on step's done channel")
 	}
 
 	contract.Assert(result != nil)
-	marshaled, err := plugin.MarshalProperties(result.State.Outputs, plugin.MarshalOptions{
+	marshaled, err := plugin.MarshalProperties(result.State.Outputs(), plugin.MarshalOptions{
 		Label:        label,
 		KeepUnknowns: true,
 	})
@@ -583,8 +709,31 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 	}
 
 	dependencies := []resource.URN{}
+	seen := make(map[resource.URN]bool)
 	for _, dependingURN := range req.GetDependencies() {
 		dependencies = append(dependencies, resource.URN(dependingURN))
+		seen[resource.URN(dependingURN)] = true
+	}
+
+	// Explicit dependencies are always dependencies as well, so that they are honored when ordering both creates and
+	// deletes, even if the language host did not include them in the full list.
+	var dependsOn []resource.URN
+	for _, dependingURN := range req.GetDependsOn() {
+		urn := resource.URN(dependingURN)
+		dependsOn = append(dependsOn, urn)
+		if !seen[urn] {
+			dependencies = append(dependencies, urn)
+			seen[urn] = true
+		}
+	}
+
+	var additionalSecretOutputs []resource.PropertyKey
+	for _, k := range req.GetAdditionalSecretOutputs() {
+		additionalSecretOutputs = append(additionalSecretOutputs, resource.PropertyKey(k))
+	}
+	replaceOnChanges := req.GetReplaceOnChanges()
+	if err := checkReplaceOnChanges(replaceOnChanges); err != nil {
+		return nil, errors.Wrapf(err, "%s", label)
 	}
 
 	props, err := plugin.UnmarshalProperties(
@@ -593,21 +742,28 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 		return nil, err
 	}
 
-	logging.V(5).Infof(
+	logging.Engine.V(5).Infof(
 		"ResourceMonitor.RegisterResource received: t=%v, name=%v, custom=%v, #props=%v, parent=%v, protect=%v, "+
-			"provider=%v, deps=%v",
-		t, name, custom, len(props), parent, protect, provider, dependencies)
-
-	// Send the goal state to the engine.
+			"provider=%v, deps=%v, dependsOn=%v, additionalSecretOutputs=%v, replaceOnChanges=%v, "+
+			"deleteBeforeReplace=%v",
+		t, name, custom, len(props), parent, protect, provider, dependencies, dependsOn, additionalSecretOutputs,
+		replaceOnChanges, req.GetDeleteBeforeReplace())
+
+	// Send the goal state to the engine, remembering where the program allocated the resource.
+	goal := resource.NewGoal(t, name, custom, props, parent, protect, dependencies, provider, nil)
+	goal.SourcePosition = relativeSourcePosition(req.GetSourcePosition(), rm.src.runinfo.Root)
+	goal.DependsOn = dependsOn
+	goal.AdditionalSecretOutputs, goal.ReplaceOnChanges = additionalSecretOutputs, replaceOnChanges
+	goal.DeleteBeforeReplace = req.GetDeleteBeforeReplace()
 	step := &registerResourceEvent{
-		goal: resource.NewGoal(t, name, custom, props, parent, protect, dependencies, provider, nil),
+		goal: goal,
 		done: make(chan *RegisterResult),
 	}
 
 	select {
 	case rm.regChan <- step:
 	case <-rm.cancel:
-		logging.V(5).Infof("ResourceMonitor.RegisterResource operation canceled, name=%s", name)
+		logging.Engine.V(5).Infof("ResourceMonitor.RegisterResource operation canceled, name=%s", name)
 		return nil, rpcerror.New(codes.Unavailable, "resource monitor shut down while sending resource registration")
 	}
 
@@ -616,7 +772,7 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 	select {
 	case result = <-step.done:
 	case <-rm.cancel:
-		logging.V(5).Infof("ResourceMonitor.RegisterResource operation canceled, name=%s", name)
+		logging.Engine.V(5).Infof("ResourceMonitor.RegisterResource operation canceled, name=%s", name)
 		return nil, rpcerror.New(codes.Unavailable, "resource monitor shut down while waiting on step's done channel")
 	}
 
@@ -627,7 +783,7 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 	for _, sta := range result.Stables {
 		stables = append(stables, string(sta))
 	}
-	logging.V(5).Infof(
+	logging.Engine.V(5).Infof(
 		"ResourceMonitor.RegisterResource operation finished: t=%v, urn=%v, stable=%v, #stables=%v #outs=%v",
 		state.Type, state.URN, stable, len(stables), len(props))
 
@@ -646,6 +802,16 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 	}, nil
 }
 
+// relativeSourcePosition returns the given `file:line:column` position with its file relative to the project's root
+// directory, if it is within it, so that the positions recorded in a stack's state do not depend upon where the
+// project happened to be checked out.
+func relativeSourcePosition(pos string, root string) string {
+	if pos == "" || root == "" {
+		return pos
+	}
+	return diag.ParsePos(pos).Rel(root)
+}
+
 // RegisterResourceOutputs records some new output properties for a resource that have arrived after its initial
 // provisioning.  These will make their way into the eventual checkpoint state file for that resource.
 func (rm *resmon) RegisterResourceOutputs(ctx context.Context,
@@ -662,7 +828,7 @@ func (rm *resmon) RegisterResourceOutputs(ctx context.Context,
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot unmarshal output properties")
 	}
-	logging.V(5).Infof("ResourceMonitor.RegisterResourceOutputs received: urn=%v, #outs=%v", urn, len(outs))
+	logging.Engine.V(5).Infof("ResourceMonitor.RegisterResourceOutputs received: urn=%v, #outs=%v", urn, len(outs))
 
 	// Now send the step over to the engine to perform.
 	step := &registerResourceOutputsEvent{
@@ -674,7 +840,7 @@ func (rm *resmon) RegisterResourceOutputs(ctx context.Context,
 	select {
 	case rm.regOutChan <- step:
 	case <-rm.cancel:
-		logging.V(5).Infof("ResourceMonitor.RegisterResourceOutputs operation canceled, urn=%s", urn)
+		logging.Engine.V(5).Infof("ResourceMonitor.RegisterResourceOutputs operation canceled, urn=%s", urn)
 		return nil, rpcerror.New(codes.Unavailable, "resource monitor shut down while sending resource outputs")
 	}
 
@@ -682,11 +848,11 @@ func (rm *resmon) RegisterResourceOutputs(ctx context.Context,
 	select {
 	case <-step.done:
 	case <-rm.cancel:
-		logging.V(5).Infof("ResourceMonitor.RegisterResourceOutputs operation canceled, urn=%s", urn)
+		logging.Engine.V(5).Infof("ResourceMonitor.RegisterResourceOutputs operation canceled, urn=%s", urn)
 		return nil, rpcerror.New(codes.Unavailable, "resource monitor shut down while waiting on output step's done channel")
 	}
 
-	logging.V(5).Infof(
+	logging.Engine.V(5).Infof(
 		"ResourceMonitor.RegisterResourceOutputs operation finished: urn=%v, #outs=%v", urn, len(outs))
 	return &pbempty.Empty{}, nil
 }
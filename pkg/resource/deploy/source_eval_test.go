@@ -16,13 +16,16 @@ package deploy
 
 import (
 	"context"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
 	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
@@ -479,3 +482,123 @@ func TestReadInvokeDefaultProviders(t *testing.T) {
 	assert.Equal(t, expectedReads, int(reads))
 	assert.Equal(t, expectedInvokes, int(invokes))
 }
+
+func TestCallDefaultProviders(t *testing.T) {
+	runInfo := &EvalRunInfo{
+		Proj:   &workspace.Project{Name: "test"},
+		Target: &Target{Name: "test"},
+	}
+
+	calls := int32(0)
+	provider := &deploytest.Provider{
+		CallF: func(tok tokens.ModuleMember,
+			args resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+
+			atomic.AddInt32(&calls, 1)
+			assert.Equal(t, tokens.ModuleMember("pkgA:m:getKubeconfig"), tok)
+			assert.Equal(t, resource.NewStringProperty("id1"), args[selfKey].ObjectValue()["id"])
+			return resource.PropertyMap{"kubeconfig": resource.NewStringProperty("config")}, nil, nil
+		},
+	}
+
+	program := func(_ plugin.RunInfo, resmon *deploytest.ResourceMonitor) error {
+		self := resource.NewObjectProperty(resource.PropertyMap{
+			"urn": resource.NewStringProperty("urn:pulumi:test::test::pkgA:m:typA::resA"),
+			"id":  resource.NewStringProperty("id1"),
+		})
+
+		// A call on a known resource is passed to the provider.
+		ret, _, err := resmon.Call("pkgA:m:getKubeconfig", resource.PropertyMap{selfKey: self}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, resource.NewStringProperty("config"), ret["kubeconfig"])
+
+		// A call with unknown arguments has an unknown result, and does not reach the provider.
+		ret, _, err = resmon.Call("pkgA:m:getKubeconfig", resource.PropertyMap{
+			selfKey: resource.MakeComputed(resource.NewStringProperty("")),
+		}, "")
+		assert.NoError(t, err)
+		assert.Len(t, ret, 0)
+
+		// A call must say which resource it is for.
+		_, _, err = resmon.Call("pkgA:m:getKubeconfig", resource.PropertyMap{}, "")
+		assert.Error(t, err)
+
+		return nil
+	}
+
+	ctx, err := newTestPluginContext(program)
+	assert.NoError(t, err)
+
+	providerSource := &testProviderSource{providers: make(map[providers.Reference]plugin.Provider)}
+
+	iter, err := NewEvalSource(ctx, runInfo, nil, false).Iterate(context.Background(), Options{}, providerSource)
+	assert.NoError(t, err)
+
+	for {
+		event, err := iter.Next()
+		assert.NoError(t, err)
+		if event == nil {
+			break
+		}
+
+		// The only registrations are for default providers.
+		e := event.(RegisterResourceEvent)
+		goal := e.Goal()
+		urn := resource.NewURN(runInfo.Target.Name, runInfo.Proj.Name, "", goal.Type, goal.Name)
+		ref, err := providers.NewReference(urn, "id")
+		assert.NoError(t, err)
+		providerSource.registerProvider(ref, provider)
+
+		e.Done(&RegisterResult{
+			State: resource.NewState(goal.Type, urn, goal.Custom, false, "id", goal.Properties, resource.PropertyMap{},
+				goal.Parent, goal.Protect, false, goal.Dependencies, nil, goal.Provider),
+		})
+	}
+
+	assert.Equal(t, 1, int(calls))
+}
+
+func TestDefaultProviderProjectDefaults(t *testing.T) {
+	version := semver.MustParse("1.2.3")
+	d := &defaultProviders{
+		versions: map[tokens.Package]*semver.Version{"pkgA": &version},
+		project: &workspace.Project{
+			Name: "test",
+			Providers: map[string]*workspace.ProviderDefaults{
+				"pkgA": {Config: map[string]string{"region": "us-west-2", "profile": "team"}},
+			},
+		},
+		config: &Target{
+			Name:   "test",
+			Config: config.Map{config.MustMakeKey("pkgA", "region"): config.NewValue("us-east-1")},
+		},
+	}
+
+	// The stack's own configuration overrides the project's defaults.
+	event, _, err := d.newRegisterDefaultProviderEvent("pkgA")
+	assert.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{
+		"region":  resource.NewStringProperty("us-east-1"),
+		"profile": resource.NewStringProperty("team"),
+		"version": resource.NewStringProperty("1.2.3"),
+	}, event.Goal().Properties)
+
+	// Packages without defaults are configured by the stack alone.
+	event, _, err = d.newRegisterDefaultProviderEvent("pkgB")
+	assert.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{}, event.Goal().Properties)
+}
+
+func TestRelativeSourcePosition(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "src", "proj")
+
+	// Positions within the project are recorded relative to its root.
+	assert.Equal(t, filepath.Join("lib", "index.ts")+":12:5",
+		relativeSourcePosition(filepath.Join(root, "lib", "index.ts")+":12:5", root))
+
+	// Positions outside of it, and positions that are already relative, are left alone.
+	outside := filepath.Join(string(filepath.Separator), "other", "index.ts") + ":3"
+	assert.Equal(t, outside, relativeSourcePosition(outside, root))
+	assert.Equal(t, "index.ts:3:1", relativeSourcePosition("index.ts:3:1", root))
+	assert.Equal(t, "", relativeSourcePosition("", root))
+}
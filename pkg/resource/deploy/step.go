@@ -15,6 +15,8 @@
 package deploy
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 
 	"github.com/pulumi/pulumi/pkg/diag/colors"
@@ -92,20 +94,35 @@ func (s *SameStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error
 	// Retain the URN, ID, and outputs:
 	s.new.URN = s.old.URN
 	s.new.ID = s.old.ID
-	s.new.Outputs = s.old.Outputs
+	s.new.SetOutputs(s.old.Outputs())
 	complete := func() { s.reg.Done(&RegisterResult{State: s.new, Stable: true}) }
 	return resource.StatusOK, complete, nil
 }
 
+// newSkippedUpdateStep returns a step that retains the old state of the resource that the given update would have
+// changed, just as if the program had not changed it.
+func newSkippedUpdateStep(update *UpdateStep) Step {
+	old := update.old
+	kept := resource.NewState(old.Type, old.URN, old.Custom, false, "", old.Inputs(), nil, old.Parent, old.Protect,
+		old.External, old.Dependencies, old.InitErrors, old.Provider)
+	kept.SourcePosition, kept.Locked, kept.DependsOn = old.SourcePosition, old.Locked, old.DependsOn
+	kept.Created, kept.Modified, kept.UpdateVersion = old.Created, old.Modified, old.UpdateVersion
+	kept.AdditionalSecretOutputs, kept.ReplaceOnChanges = old.AdditionalSecretOutputs, old.ReplaceOnChanges
+	kept.DeleteBeforeReplace, kept.RandomSeed, kept.InputsHash = old.DeleteBeforeReplace, old.RandomSeed, old.InputsHash
+	kept.ProviderDefaulted = old.ProviderDefaulted
+	return NewSameStep(update.plan, update.reg, old, kept)
+}
+
 // CreateStep is a mutating step that creates an entirely new resource.
 type CreateStep struct {
-	plan          *Plan                  // the current plan.
-	reg           RegisterResourceEvent  // the registration intent to convey a URN back to.
-	old           *resource.State        // the state of the existing resource (only for replacements).
-	new           *resource.State        // the state of the resource after this step.
-	keys          []resource.PropertyKey // the keys causing replacement (only for replacements).
-	replacing     bool                   // true if this is a create due to a replacement.
-	pendingDelete bool                   // true if this replacement should create a pending delete.
+	plan          *Plan                           // the current plan.
+	reg           RegisterResourceEvent           // the registration intent to convey a URN back to.
+	old           *resource.State                 // the state of the existing resource (only for replacements).
+	new           *resource.State                 // the state of the resource after this step.
+	keys          []resource.PropertyKey          // the keys causing replacement (only for replacements).
+	reasons       map[resource.PropertyKey]string // the reasons, if any, that the keys cause replacement.
+	replacing     bool                            // true if this is a create due to a replacement.
+	pendingDelete bool                            // true if this replacement should create a pending delete.
 }
 
 var _ Step = (*CreateStep)(nil)
@@ -126,7 +143,8 @@ func NewCreateStep(plan *Plan, reg RegisterResourceEvent, new *resource.State) S
 }
 
 func NewCreateReplacementStep(plan *Plan, reg RegisterResourceEvent,
-	old *resource.State, new *resource.State, keys []resource.PropertyKey, pendingDelete bool) Step {
+	old *resource.State, new *resource.State, keys []resource.PropertyKey,
+	reasons map[resource.PropertyKey]string, pendingDelete bool) Step {
 	contract.Assert(reg != nil)
 	contract.Assert(old != nil)
 	contract.Assert(old.URN != "")
@@ -145,6 +163,7 @@ func NewCreateReplacementStep(plan *Plan, reg RegisterResourceEvent,
 		old:           old,
 		new:           new,
 		keys:          keys,
+		reasons:       reasons,
 		replacing:     true,
 		pendingDelete: pendingDelete,
 	}
@@ -156,27 +175,30 @@ func (s *CreateStep) Op() StepOp {
 	}
 	return OpCreate
 }
-func (s *CreateStep) Plan() *Plan                  { return s.plan }
-func (s *CreateStep) Type() tokens.Type            { return s.new.Type }
-func (s *CreateStep) Provider() string             { return s.new.Provider }
-func (s *CreateStep) URN() resource.URN            { return s.new.URN }
-func (s *CreateStep) Old() *resource.State         { return s.old }
-func (s *CreateStep) New() *resource.State         { return s.new }
-func (s *CreateStep) Res() *resource.State         { return s.new }
-func (s *CreateStep) Keys() []resource.PropertyKey { return s.keys }
-func (s *CreateStep) Logical() bool                { return !s.replacing }
+func (s *CreateStep) Plan() *Plan                              { return s.plan }
+func (s *CreateStep) Type() tokens.Type                        { return s.new.Type }
+func (s *CreateStep) Provider() string                         { return s.new.Provider }
+func (s *CreateStep) URN() resource.URN                        { return s.new.URN }
+func (s *CreateStep) Old() *resource.State                     { return s.old }
+func (s *CreateStep) New() *resource.State                     { return s.new }
+func (s *CreateStep) Res() *resource.State                     { return s.new }
+func (s *CreateStep) Keys() []resource.PropertyKey             { return s.keys }
+func (s *CreateStep) Reasons() map[resource.PropertyKey]string { return s.reasons }
+func (s *CreateStep) Logical() bool                            { return !s.replacing }
 
 func (s *CreateStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error) {
 	var resourceError error
 	resourceStatus := resource.StatusOK
 	if !preview {
+		s.new.Created = time.Now()
+		s.new.Modified = s.new.Created
 		if s.new.Custom {
 			// Invoke the Create RPC function for this provider:
 			prov, err := getProvider(s)
 			if err != nil {
 				return resource.StatusOK, nil, err
 			}
-			id, outs, rst, err := prov.Create(s.URN(), s.new.Inputs)
+			id, outs, rst, err := prov.Create(s.URN(), s.new.Inputs())
 			if err != nil {
 				if rst != resource.StatusPartialFailure {
 					return rst, nil, err
@@ -194,7 +216,7 @@ func (s *CreateStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 
 			// Copy any of the default and output properties on the live object state.
 			s.new.ID = id
-			s.new.Outputs = outs
+			s.new.SetOutputs(outs)
 		}
 	}
 
@@ -213,9 +235,10 @@ func (s *CreateStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 // DeleteStep is a mutating step that deletes an existing resource. If `old` is marked "External",
 // DeleteStep is a no-op.
 type DeleteStep struct {
-	plan      *Plan           // the current plan.
-	old       *resource.State // the state of the existing resource.
-	replacing bool            // true if part of a replacement.
+	plan         *Plan           // the current plan.
+	old          *resource.State // the state of the existing resource.
+	replacing    bool            // true if part of a replacement.
+	unreferenced *resource.State // the state to keep should the resource outlive this step, or nil.
 }
 
 var _ Step = (*DeleteStep)(nil)
@@ -232,6 +255,16 @@ func NewDeleteStep(plan *Plan, old *resource.State) Step {
 	}
 }
 
+// NewUnreferencedDeleteStep returns a step that deletes a resource that the program no longer declares.  Should the
+// resource outlive the step, the snapshot keeps a copy of its state that is marked as unreferenced.
+func NewUnreferencedDeleteStep(plan *Plan, old *resource.State) Step {
+	step := NewDeleteStep(plan, old).(*DeleteStep)
+	unreferenced := *old
+	unreferenced.Unreferenced = true
+	step.unreferenced = &unreferenced
+	return step
+}
+
 func NewDeleteReplacementStep(plan *Plan, old *resource.State, pendingDelete bool) Step {
 	contract.Assert(old != nil)
 	contract.Assert(old.URN != "")
@@ -260,6 +293,10 @@ func (s *DeleteStep) New() *resource.State { return nil }
 func (s *DeleteStep) Res() *resource.State { return s.old }
 func (s *DeleteStep) Logical() bool        { return !s.replacing }
 
+// Unreferenced returns the state that replaces the old state in the snapshot should the resource outlive this step,
+// or nil if the old state is to be kept as it is.
+func (s *DeleteStep) Unreferenced() *resource.State { return s.unreferenced }
+
 func (s *DeleteStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error) {
 	// Refuse to delete protected resources.
 	if s.old.Protect {
@@ -336,6 +373,7 @@ func (s *UpdateStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 	var resourceError error
 	resourceStatus := resource.StatusOK
 	if !preview {
+		s.new.Modified = time.Now()
 		if s.new.Custom {
 			// Invoke the Update RPC function for this provider:
 			prov, err := getProvider(s)
@@ -344,7 +382,7 @@ func (s *UpdateStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 			}
 
 			// Update to the combination of the old "all" state (including outputs), but overwritten with new inputs.
-			outs, rst, upderr := prov.Update(s.URN(), s.old.ID, s.old.All(), s.new.Inputs)
+			outs, rst, upderr := prov.Update(s.URN(), s.old.ID, s.old.All(), s.new.Inputs())
 			if upderr != nil {
 				if rst != resource.StatusPartialFailure {
 					return rst, nil, upderr
@@ -359,7 +397,7 @@ func (s *UpdateStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 			}
 
 			// Now copy any output state back in case the update triggered cascading updates to other properties.
-			s.new.Outputs = outs
+			s.new.SetOutputs(outs)
 		}
 	}
 
@@ -375,17 +413,20 @@ func (s *UpdateStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 // a creation of the new resource, any number of intervening updates of dependents to the new resource, and then
 // a deletion of the now-replaced old resource.  This logical step is primarily here for tools and visualization.
 type ReplaceStep struct {
-	plan          *Plan                  // the current plan.
-	old           *resource.State        // the state of the existing resource.
-	new           *resource.State        // the new state snapshot.
-	keys          []resource.PropertyKey // the keys causing replacement.
-	pendingDelete bool                   // true if a pending deletion should happen.
+	plan          *Plan                           // the current plan.
+	old           *resource.State                 // the state of the existing resource.
+	new           *resource.State                 // the new state snapshot.
+	keys          []resource.PropertyKey          // the keys causing replacement.
+	reasons       map[resource.PropertyKey]string // the reasons, if any, that the keys cause replacement.
+	pendingDelete bool                            // true if a pending deletion should happen.
+	deleteFirst   bool                            // true if the old resource is deleted before its replacement.
+	dependents    []resource.URN                  // the dependents replaced along with a resource deleted first.
 }
 
 var _ Step = (*ReplaceStep)(nil)
 
 func NewReplaceStep(plan *Plan, old *resource.State, new *resource.State,
-	keys []resource.PropertyKey, pendingDelete bool) Step {
+	keys []resource.PropertyKey, reasons map[resource.PropertyKey]string, pendingDelete bool) Step {
 	contract.Assert(old != nil)
 	contract.Assert(old.URN != "")
 	contract.Assert(old.ID != "" || !old.Custom)
@@ -399,20 +440,39 @@ func NewReplaceStep(plan *Plan, old *resource.State, new *resource.State,
 		old:           old,
 		new:           new,
 		keys:          keys,
+		reasons:       reasons,
 		pendingDelete: pendingDelete,
 	}
 }
 
-func (s *ReplaceStep) Op() StepOp                   { return OpReplace }
-func (s *ReplaceStep) Plan() *Plan                  { return s.plan }
-func (s *ReplaceStep) Type() tokens.Type            { return s.old.Type }
-func (s *ReplaceStep) Provider() string             { return s.old.Provider }
-func (s *ReplaceStep) URN() resource.URN            { return s.old.URN }
-func (s *ReplaceStep) Old() *resource.State         { return s.old }
-func (s *ReplaceStep) New() *resource.State         { return s.new }
-func (s *ReplaceStep) Res() *resource.State         { return s.new }
-func (s *ReplaceStep) Keys() []resource.PropertyKey { return s.keys }
-func (s *ReplaceStep) Logical() bool                { return true }
+// NewDeleteBeforeReplaceStep returns a replace step for a resource that is deleted before its replacement is created.
+// The given dependents, which depend directly or indirectly upon the resource, are deleted along with it, in the given
+// order, and will be replaced in turn.
+func NewDeleteBeforeReplaceStep(plan *Plan, old *resource.State, new *resource.State, keys []resource.PropertyKey,
+	reasons map[resource.PropertyKey]string, dependents []resource.URN) Step {
+	step := NewReplaceStep(plan, old, new, keys, reasons, false).(*ReplaceStep)
+	step.deleteFirst, step.dependents = true, dependents
+	return step
+}
+
+func (s *ReplaceStep) Op() StepOp                               { return OpReplace }
+func (s *ReplaceStep) Plan() *Plan                              { return s.plan }
+func (s *ReplaceStep) Type() tokens.Type                        { return s.old.Type }
+func (s *ReplaceStep) Provider() string                         { return s.old.Provider }
+func (s *ReplaceStep) URN() resource.URN                        { return s.old.URN }
+func (s *ReplaceStep) Old() *resource.State                     { return s.old }
+func (s *ReplaceStep) New() *resource.State                     { return s.new }
+func (s *ReplaceStep) Res() *resource.State                     { return s.new }
+func (s *ReplaceStep) Keys() []resource.PropertyKey             { return s.keys }
+func (s *ReplaceStep) Reasons() map[resource.PropertyKey]string { return s.reasons }
+func (s *ReplaceStep) Logical() bool                            { return true }
+
+// DeleteBeforeReplace returns true if the old resource is deleted before its replacement is created.
+func (s *ReplaceStep) DeleteBeforeReplace() bool { return s.deleteFirst }
+
+// Dependents returns the resources that are deleted and replaced because they depend upon a resource that is deleted
+// before it is replaced, in the order in which they are deleted.
+func (s *ReplaceStep) Dependents() []resource.URN { return s.dependents }
 
 func (s *ReplaceStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error) {
 	// If this is a pending delete, we should have marked the old resource for deletion in the CreateReplacement step.
@@ -501,14 +561,14 @@ func (s *ReadStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error
 	// Unlike most steps, Read steps run during previews. The only time
 	// we can't run is if the ID we are given is unknown.
 	if id == "" || id == plugin.UnknownStringValue {
-		s.new.Outputs = resource.PropertyMap{}
+		s.new.SetOutputs(resource.PropertyMap{})
 	} else {
 		prov, err := getProvider(s)
 		if err != nil {
 			return resource.StatusOK, nil, err
 		}
 
-		result, rst, err := prov.Read(urn, id, s.new.Inputs)
+		result, rst, err := prov.Read(urn, id, s.new.Inputs())
 		if err != nil {
 			if rst != resource.StatusPartialFailure {
 				return rst, nil, err
@@ -522,7 +582,7 @@ func (s *ReadStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error
 			}
 		}
 
-		s.new.Outputs = result
+		s.new.SetOutputs(result)
 	}
 
 	// If we were asked to replace an existing, non-External resource, pend the
@@ -546,6 +606,10 @@ type RefreshStep struct {
 	old  *resource.State // the old resource state, if one exists for this urn
 	new  *resource.State // the new resource state, to be used to query the provider
 	done chan<- bool     // the channel to use to signal completion, if any
+
+	limiter *rateLimiter // the rate limiter to read the resource with, if any
+	since   time.Time    // if non-zero, the resource is not read if its provider reports it unmodified since then
+	skip    bool         // true if the resource is not to be read at all
 }
 
 // NewRefreshStep creates a new Refresh step.
@@ -577,7 +641,7 @@ func (s *RefreshStep) ResultOp() StepOp {
 	if s.new == nil {
 		return OpDelete
 	}
-	if s.new == s.old || s.old.Outputs.Diff(s.new.Outputs) == nil {
+	if s.new == s.old || s.old.Outputs().Diff(s.new.Outputs()) == nil {
 		return OpSame
 	}
 	return OpUpdate
@@ -589,8 +653,9 @@ func (s *RefreshStep) Apply(preview bool) (resource.Status, StepCompleteFunc, er
 		complete = func() { close(s.done) }
 	}
 
-	// Component and provider resources never change with a refresh; just return the current state.
-	if !s.old.Custom || providers.IsProviderType(s.old.Type) {
+	// Component and provider resources never change with a refresh; just return the current state.  The same goes for
+	// resources that a changed-only refresh needn't read.
+	if !s.old.Custom || providers.IsProviderType(s.old.Type) || s.skip {
 		return resource.StatusOK, complete, nil
 	}
 
@@ -601,7 +666,25 @@ func (s *RefreshStep) Apply(preview bool) (resource.Status, StepCompleteFunc, er
 	}
 
 	var initErrors []string
-	refreshed, rst, err := prov.Read(s.old.URN, s.old.ID, s.old.Outputs)
+	var unmodified bool
+	read := func() (resource.PropertyMap, resource.Status, error) {
+		if s.since.IsZero() {
+			return prov.Read(s.old.URN, s.old.ID, s.old.Outputs())
+		}
+		outs, unmod, status, err := prov.ReadIfModified(s.old.URN, s.old.ID, s.old.Outputs(), s.since)
+		unmodified = unmod
+		return outs, status, err
+	}
+	var refreshed resource.PropertyMap
+	var rst resource.Status
+	if s.limiter != nil {
+		refreshed, rst, err = s.limiter.do(s.old.URN, read)
+	} else {
+		refreshed, rst, err = read()
+	}
+	if err == nil && unmodified {
+		return resource.StatusOK, complete, nil
+	}
 	if err != nil {
 		if rst != resource.StatusPartialFailure {
 			return rst, nil, err
@@ -614,8 +697,26 @@ func (s *RefreshStep) Apply(preview bool) (resource.Status, StepCompleteFunc, er
 	}
 
 	if refreshed != nil {
-		s.new = resource.NewState(s.old.Type, s.old.URN, s.old.Custom, s.old.Delete, s.old.ID, s.old.Inputs, refreshed,
-			s.old.Parent, s.old.Protect, s.old.External, s.old.Dependencies, initErrors, s.old.Provider)
+		s.new = resource.NewState(s.old.Type, s.old.URN, s.old.Custom, s.old.Delete, s.old.ID, s.old.Inputs(),
+			refreshed, s.old.Parent, s.old.Protect, s.old.External, s.old.Dependencies, initErrors, s.old.Provider)
+		s.new.SourcePosition = s.old.SourcePosition
+		s.new.Locked = s.old.Locked
+		s.new.DependsOn = s.old.DependsOn
+		s.new.Unreferenced = s.old.Unreferenced
+		s.new.Created = s.old.Created
+		s.new.Modified = s.old.Modified
+		s.new.UpdateVersion = s.old.UpdateVersion
+		s.new.AdditionalSecretOutputs = s.old.AdditionalSecretOutputs
+		s.new.ReplaceOnChanges = s.old.ReplaceOnChanges
+		s.new.DeleteBeforeReplace = s.old.DeleteBeforeReplace
+		s.new.RandomSeed = s.old.RandomSeed
+		s.new.ProviderDefaulted = s.old.ProviderDefaulted
+
+		// The provider may not diff the program's inputs as it did before if the resource's state has changed, so the
+		// hash of the inputs that it last checked is only kept if the state has not.
+		if refreshed.DeepEquals(s.old.Outputs()) {
+			s.new.InputsHash = s.old.InputsHash
+		}
 	} else {
 		s.new = nil
 	}
@@ -687,8 +788,12 @@ func (op StepOp) Prefix() string {
 	return op.Color() + op.RawPrefix()
 }
 
-// RawPrefix returns the uncolorized prefix text.
+// RawPrefix returns the uncolorized prefix text, which the current display theme may replace.
 func (op StepOp) RawPrefix() string {
+	if glyph, has := colors.Glyph(string(op)); has {
+		return glyph
+	}
+
 	switch op {
 	case OpSame:
 		return "* "
@@ -0,0 +1,221 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+)
+
+// StepDecider may override the decisions made by the step generator, so that an organization can impose deployment
+// semantics of its own.  It is consulted during previews as well as updates.
+type StepDecider interface {
+	// DecideUpdate is consulted when a resource is to be updated in place, and returns true if it must be replaced
+	// instead.
+	DecideUpdate(old, new *resource.State, diff plugin.DiffResult) (bool, error)
+	// DecideDelete is consulted when a resource that is no longer part of the program, or that is pending deletion
+	// after a replacement, is to be deleted.  Returning an error denies the deletion, which fails the plan.
+	DecideDelete(old *resource.State) error
+	// OrderDeletes may reorder the steps that delete resources.  The result must contain exactly the given steps, and
+	// must still delete each resource before any resource that it depends upon.
+	OrderDeletes(deletes []Step) ([]Step, error)
+}
+
+// deciderReplaceReason explains a replacement required by a StepDecider.
+const deciderReplaceReason = "requires replacement according to the step decider"
+
+// deciderReplacement turns a diff that describes an in-place update into one that describes a replacement.
+func deciderReplacement(diff plugin.DiffResult, oldInputs, newInputs resource.PropertyMap) plugin.DiffResult {
+	var keys []resource.PropertyKey
+	if d := oldInputs.Diff(newInputs); d != nil {
+		for _, k := range d.Keys() {
+			if d.Changed(k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		keys = []resource.PropertyKey{"id"}
+	}
+
+	diff.ReplaceKeys = keys
+	diff.ReplaceReasons = make(map[resource.PropertyKey]string)
+	for _, k := range keys {
+		diff.ReplaceReasons[k] = deciderReplaceReason
+	}
+	return diff
+}
+
+// checkDeleteOrder ensures that a StepDecider's reordering of deletes contains exactly the original steps, and deletes
+// each resource before the resources it depends upon.
+func checkDeleteOrder(original, ordered []Step) error {
+	if len(original) != len(ordered) {
+		return errors.Errorf("the step decider returned %d deletes; expected %d", len(ordered), len(original))
+	}
+	remaining := make(map[Step]bool)
+	for _, step := range original {
+		remaining[step] = true
+	}
+
+	position := make(map[resource.URN]int)
+	for i, step := range ordered {
+		if !remaining[step] {
+			return errors.Errorf("the step decider returned an unexpected or repeated delete of %s", step.URN())
+		}
+		delete(remaining, step)
+		position[step.URN()] = i
+	}
+
+	for i, step := range ordered {
+		for _, dep := range stepDependencies(step.Old()) {
+			if j, has := position[dep]; has && j < i {
+				return errors.Errorf("the step decider ordered the delete of %s before that of %s, which depends on it",
+					dep, step.URN())
+			}
+		}
+	}
+	return nil
+}
+
+// stepDependencies returns the URNs of the resources that the given resource depends upon: its parent, its explicit
+// dependencies, and its provider.
+func stepDependencies(res *resource.State) []resource.URN {
+	var deps []resource.URN
+	if res.Parent != "" {
+		deps = append(deps, res.Parent)
+	}
+	deps = append(deps, res.Dependencies...)
+	if res.Provider != "" {
+		if ref, err := providers.ParseReference(res.Provider); err == nil {
+			deps = append(deps, ref.URN())
+		}
+	}
+	return deps
+}
+
+// scriptStepDecider is a StepDecider that runs an executable for each decision.  The decision is described by a JSON
+// object written to the executable's stdin, and the executable writes its answer as a JSON object to stdout; an empty
+// answer leaves the step generator's decision as it was.
+type scriptStepDecider struct {
+	path string
+}
+
+// NewScriptStepDecider returns a StepDecider that consults the executable at the given path.  Each request has a
+// "kind".  An "update" request gives the "urn" and "type" of the resource, along with its "old" and "new" inputs, and
+// may be answered with {"replace": true}.  A "delete" request gives the "urn", "type", and "old" inputs, and may be
+// answered with {"deny": "<reason>"}.  An "orderDeletes" request lists the "urns" to be deleted, and may be answered
+// with the same "urns" in the order in which they should be deleted.
+func NewScriptStepDecider(path string) StepDecider {
+	return &scriptStepDecider{path: path}
+}
+
+type stepDecisionRequest struct {
+	Kind string                 `json:"kind"`
+	URN  resource.URN           `json:"urn,omitempty"`
+	Type string                 `json:"type,omitempty"`
+	Old  map[string]interface{} `json:"old,omitempty"`
+	New  map[string]interface{} `json:"new,omitempty"`
+	URNs []resource.URN         `json:"urns,omitempty"`
+}
+
+type stepDecisionResponse struct {
+	Replace bool           `json:"replace,omitempty"`
+	Deny    string         `json:"deny,omitempty"`
+	URNs    []resource.URN `json:"urns,omitempty"`
+}
+
+func (d *scriptStepDecider) run(req stepDecisionRequest) (stepDecisionResponse, error) {
+	var resp stepDecisionResponse
+	in, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(d.path) // nolint: gas
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = bytes.NewReader(in), &stdout, &stderr
+	if err = cmd.Run(); err != nil {
+		return resp, errors.Wrapf(err, "running step decider %s: %s", d.path, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) > 0 {
+		if err = json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+			return resp, errors.Wrapf(err, "parsing the answer of step decider %s", d.path)
+		}
+	}
+	return resp, nil
+}
+
+func (d *scriptStepDecider) DecideUpdate(old, new *resource.State, diff plugin.DiffResult) (bool, error) {
+	resp, err := d.run(stepDecisionRequest{
+		Kind: "update",
+		URN:  old.URN,
+		Type: string(old.Type),
+		Old:  old.Inputs().Mappable(),
+		New:  new.Inputs().Mappable(),
+	})
+	return resp.Replace, err
+}
+
+func (d *scriptStepDecider) DecideDelete(old *resource.State) error {
+	resp, err := d.run(stepDecisionRequest{
+		Kind: "delete",
+		URN:  old.URN,
+		Type: string(old.Type),
+		Old:  old.Inputs().Mappable(),
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Deny != "" {
+		return errors.Errorf("the step decider denied the deletion of %s: %s", old.URN, resp.Deny)
+	}
+	return nil
+}
+
+func (d *scriptStepDecider) OrderDeletes(deletes []Step) ([]Step, error) {
+	if len(deletes) < 2 {
+		return deletes, nil
+	}
+
+	byURN := make(map[resource.URN][]Step)
+	req := stepDecisionRequest{Kind: "orderDeletes"}
+	for _, step := range deletes {
+		req.URNs = append(req.URNs, step.URN())
+		byURN[step.URN()] = append(byURN[step.URN()], step)
+	}
+	resp, err := d.run(req)
+	if err != nil || len(resp.URNs) == 0 {
+		return deletes, err
+	}
+
+	// A URN may be deleted more than once if there are pending deletes; such steps keep their relative order.
+	var ordered []Step
+	for _, urn := range resp.URNs {
+		steps := byURN[urn]
+		if len(steps) == 0 {
+			return nil, errors.Errorf("the step decider ordered an unexpected or repeated delete of %s", urn)
+		}
+		ordered, byURN[urn] = append(ordered, steps[0]), steps[1:]
+	}
+	return ordered, nil
+}
@@ -22,6 +22,8 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 )
@@ -56,6 +58,7 @@ type stepExecutor struct {
 	opts        Options  // The options for this current plan.
 	preview     bool     // Whether or not we are doing a preview.
 	pendingNews sync.Map // Resources that have been created but are pending a RegisterResourceOutputs.
+	retained    sync.Map // Resources whose deletion was skipped, and the resources that they depend upon.
 
 	workers        sync.WaitGroup // WaitGroup tracking the worker goroutines that are owned by this step executor.
 	incomingChains chan Chain     // Incoming chains that we are to execute
@@ -95,8 +98,8 @@ func (se *stepExecutor) ExecuteRegisterResourceOutputs(e RegisterResourceOutputs
 	// might already be there, since otherwise "deleting" outputs would have no affect.
 	outs := e.Outputs()
 	se.log(synchronousWorkerID,
-		"registered resource outputs %s: old=#%d, new=#%d", urn, len(reg.New().Outputs), len(outs))
-	reg.New().Outputs = e.Outputs()
+		"registered resource outputs %s: old=#%d, new=#%d", urn, len(reg.New().Outputs()), len(outs))
+	reg.New().SetOutputs(e.Outputs())
 	// If there is an event subscription for finishing the resource, execute them.
 	if e := se.opts.Events; e != nil {
 		if eventerr := e.OnResourceOutputs(reg); eventerr != nil {
@@ -190,6 +193,33 @@ func (se *stepExecutor) cancelDueToError() {
 // executeStep executes a single step, returning true if the step execution was successful and
 // false if it was not.
 func (se *stepExecutor) executeStep(workerID int, step Step) error {
+	if se.opts.StepHook != nil && !se.preview && step.Op() != OpSame {
+		// Resources that a resource whose deletion was skipped depends upon must remain as well.  Deletions are
+		// ordered such that dependents are deleted first, so these are always known by the time they are needed.
+		if _, has := se.retained.Load(step.URN()); has && step.Op() == OpDelete {
+			se.log(workerID, "skipping step %v on %v, which a retained resource depends upon", step.Op(), step.URN())
+			se.retain(step.Old())
+			return nil
+		}
+
+		switch action := se.opts.StepHook(step); action {
+		case StepAbort:
+			se.log(workerID, "step %v on %v aborted the deployment", step.Op(), step.URN())
+			return errors.Errorf("deployment aborted before %v of %v", step.Op(), step.URN())
+		case StepSkip:
+			contract.Assertf(CanSkip(step.Op()), "step %v on %v may not be skipped", step.Op(), step.URN())
+			se.log(workerID, "skipping step %v on %v", step.Op(), step.URN())
+			if step.Op() == OpDelete {
+				// A skipped deletion leaves the resource in the snapshot, where it is found by the next deployment.
+				se.retain(step.Old())
+				return nil
+			}
+			step = newSkippedUpdateStep(step.(*UpdateStep))
+		default:
+			contract.Assertf(action == StepContinue, "unexpected step action %v", action)
+		}
+	}
+
 	var payload interface{}
 	events := se.opts.Events
 	if events != nil {
@@ -204,6 +234,14 @@ func (se *stepExecutor) executeStep(workerID int, step Step) error {
 	se.log(workerID, "applying step %v on %v (preview %v)", step.Op(), step.URN(), se.preview)
 	status, stepComplete, err := step.Apply(se.preview)
 
+	// Record which version of the stack last created or updated the resource.
+	if !se.preview && se.opts.UpdateVersion > 0 && step.New() != nil {
+		switch step.Op() {
+		case OpCreate, OpCreateReplacement, OpUpdate:
+			step.New().UpdateVersion = se.opts.UpdateVersion
+		}
+	}
+
 	if err == nil {
 		// If we have a state object, and this is a create or update, remember it, as we may need to update it later.
 		if step.Logical() && step.New() != nil {
@@ -238,11 +276,28 @@ func (se *stepExecutor) executeStep(workerID int, step Step) error {
 	return nil
 }
 
+// retain records that the given resource, whose deletion was skipped, and the resources that it depends upon must not
+// be deleted.
+func (se *stepExecutor) retain(old *resource.State) {
+	se.retained.Store(old.URN, true)
+	if old.Parent != "" {
+		se.retained.Store(old.Parent, true)
+	}
+	for _, dep := range old.Dependencies {
+		se.retained.Store(dep, true)
+	}
+	if old.Provider != "" {
+		if ref, err := providers.ParseReference(old.Provider); err == nil {
+			se.retained.Store(ref.URN(), true)
+		}
+	}
+}
+
 // log is a simple logging helper for the step executor.
 func (se *stepExecutor) log(workerID int, msg string, args ...interface{}) {
-	if logging.V(stepExecutorLogLevel) {
+	if logging.Engine.V(stepExecutorLogLevel).Enabled() {
 		message := fmt.Sprintf(msg, args...)
-		logging.V(stepExecutorLogLevel).Infof("StepExecutor worker(%d): %s", workerID, message)
+		logging.Engine.V(stepExecutorLogLevel).Infof("StepExecutor worker(%d): %s", workerID, message)
 	}
 }
 
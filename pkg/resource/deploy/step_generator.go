@@ -15,6 +15,8 @@
 package deploy
 
 import (
+	"fmt"
+
 	"github.com/pkg/errors"
 
 	"github.com/pulumi/pulumi/pkg/diag"
@@ -40,6 +42,48 @@ type stepGenerator struct {
 	updates  map[resource.URN]bool // set of URNs updated in this plan
 	creates  map[resource.URN]bool // set of URNs created in this plan
 	sames    map[resource.URN]bool // set of URNs that were not changed in this plan
+
+	providerInputs map[resource.URN]resource.PropertyMap // checked inputs of the providers registered in this plan
+	providerHashes map[string]string                     // hashes of providers (see hashProvider), by reference
+}
+
+// deleteTargets returns the set of URNs that a plan with the given delete targets may delete: the targets themselves,
+// along with every resource that depends upon or descends from them, none of which could outlive them.  It returns
+// nil if the plan's deletes are not targeted.
+func deleteTargets(resources []*resource.State, urns []resource.URN) (map[resource.URN]bool, error) {
+	if len(urns) == 0 {
+		return nil, nil
+	}
+
+	targets, found := make(map[resource.URN]bool), make(map[resource.URN]bool)
+	for _, urn := range urns {
+		targets[urn] = true
+	}
+	for _, res := range resources {
+		found[res.URN] = true
+
+		targeted := targets[res.URN] || targets[res.Parent]
+		if !targeted && res.Provider != "" {
+			ref, err := providers.ParseReference(res.Provider)
+			if err != nil {
+				return nil, err
+			}
+			targeted = targets[ref.URN()]
+		}
+		for _, dep := range res.Dependencies {
+			targeted = targeted || targets[dep]
+		}
+		if targeted {
+			targets[res.URN] = true
+		}
+	}
+
+	for _, urn := range urns {
+		if !found[urn] {
+			return nil, errors.Errorf("no resource named '%s' exists in the stack", urn)
+		}
+	}
+	return targets, nil
 }
 
 // GenerateReadSteps is responsible for producing one or more steps required to service
@@ -75,17 +119,20 @@ func (sg *stepGenerator) GenerateReadSteps(event ReadResourceEvent) ([]Step, err
 	// This operation is tenatively called "relinquish" - it semantically represents the
 	// release of a resource from the management of Pulumi.
 	if hasOld && !old.External && old.ID != event.ID() {
-		logging.V(7).Infof(
+		logging.Engine.V(7).Infof(
 			"stepGenerator.GenerateReadSteps(...): replacing existing resource %s, ids don't match", urn)
+		if sg.isLocked(old, "replaced") {
+			return nil, errLockedResources
+		}
 		sg.replaces[urn] = true
 		return []Step{
 			NewReadReplacementStep(sg.plan, event, old, newState),
-			NewReplaceStep(sg.plan, old, newState, nil, true),
+			NewReplaceStep(sg.plan, old, newState, nil, nil, true),
 		}, nil
 	}
 
-	if bool(logging.V(7)) && hasOld && old.ID == event.ID() {
-		logging.V(7).Infof("stepGenerator.GenerateReadSteps(...): recognized relinquish of resource %s", urn)
+	if logging.Engine.V(7).Enabled() && hasOld && old.ID == event.ID() {
+		logging.Engine.V(7).Infof("stepGenerator.GenerateReadSteps(...): recognized relinquish of resource %s", urn)
 	}
 
 	sg.reads[urn] = true
@@ -118,8 +165,8 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 	var oldInputs resource.PropertyMap
 	var oldOutputs resource.PropertyMap
 	if hasOld {
-		oldInputs = old.Inputs
-		oldOutputs = old.Outputs
+		oldInputs = old.Inputs()
+		oldOutputs = old.Outputs()
 	}
 
 	// Produce a new state object that we'll build up as operations are performed.  Ultimately, this is what will
@@ -127,9 +174,18 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 	inputs := goal.Properties
 	new := resource.NewState(goal.Type, urn, goal.Custom, false, "", inputs, nil, goal.Parent, goal.Protect, false,
 		goal.Dependencies, goal.InitErrors, goal.Provider)
+	new.SourcePosition, new.DependsOn = goal.SourcePosition, goal.DependsOn
+	new.AdditionalSecretOutputs, new.ReplaceOnChanges = goal.AdditionalSecretOutputs, goal.ReplaceOnChanges
+	new.DeleteBeforeReplace = goal.DeleteBeforeReplace
+	new.ProviderDefaulted = sg.providerDefaulted(goal)
+	if hasOld {
+		new.Locked, new.Created, new.Modified, new.UpdateVersion = old.Locked, old.Created, old.Modified,
+			old.UpdateVersion
+	}
 
 	// Fetch the provider for this resource type, assuming it isn't just a logical one.
 	var prov plugin.Provider
+	var provHash string
 	var err error
 	if goal.Custom {
 		// If this resource is a provider resource, use the plan's provider registry for its CRUD operations.
@@ -147,7 +203,7 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 			if !ok {
 				return nil, errors.Errorf("unknown provider '%v' for resource '%v'", ref, urn)
 			}
-			prov = p
+			prov, provHash = p, sg.providerHash(ref, p)
 		}
 	}
 
@@ -160,25 +216,50 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 	// We may be creating this resource if it previously existed in the snapshot as an External resource
 	wasExternal := hasOld && old.External
 
+	// The resource keeps the random seed that it was created with, so that the provider derives the same results
+	// from it, unless it is being created anew.
+	if hasOld && len(old.RandomSeed) > 0 && !recreating && !wasExternal {
+		new.RandomSeed = old.RandomSeed
+	} else {
+		new.RandomSeed = newRandomSeed(sg.opts.RandomSeed, urn, nil)
+	}
+
+	// If the program gave the resource exactly the inputs that its provider last checked, the provider would check and
+	// diff them just as it did then, so it is asked to do neither; the resource's checked inputs are kept as they are.
+	// This is not done if the resource's provider changed, if a refresh may have changed its state, or if full diffs
+	// are required.  The hash of the inputs covers the provider's configuration and version, either of which may
+	// change the results of checking and diffing them.
+	new.InputsHash = hashInputs(goal.Properties, new.RandomSeed, provHash)
+	unchanged := hasOld && prov != nil && !providers.IsProviderType(goal.Type) && !recreating && !wasExternal &&
+		new.InputsHash != "" && new.InputsHash == old.InputsHash && old.Provider == new.Provider &&
+		!sg.opts.Refresh && !sg.opts.StrictDiff
+
 	// Ensure the provider is okay with this resource and fetch the inputs to pass to subsequent methods.
-	if prov != nil {
+	if unchanged {
+		logging.Engine.V(7).Infof("Planner skipped checking '%v', whose inputs have not changed", urn)
+		inputs = oldInputs
+		new.SetInputs(inputs)
+	} else if prov != nil {
 		var failures []plugin.CheckFailure
 
 		// If we are re-creating this resource because it was deleted earlier, the old inputs are now
 		// invalid (they got deleted) so don't consider them. Similarly, if the old resource was External,
 		// don't consider those inputs since Pulumi does not own them.
 		if recreating || wasExternal {
-			inputs, failures, err = prov.Check(urn, nil, goal.Properties, allowUnknowns)
+			inputs, failures, err = plugin.CheckWithSeed(prov, urn, nil, goal.Properties, new.RandomSeed, allowUnknowns)
 		} else {
-			inputs, failures, err = prov.Check(urn, oldInputs, inputs, allowUnknowns)
+			inputs, failures, err = plugin.CheckWithSeed(prov, urn, oldInputs, inputs, new.RandomSeed, allowUnknowns)
 		}
 
 		if err != nil {
 			return nil, err
-		} else if sg.issueCheckErrors(new, urn, failures) {
+		} else if sg.issueCheckErrors(new, urn, goal.SourcePosition, failures) {
 			invalid = true
 		}
-		new.Inputs = inputs
+		new.SetInputs(inputs)
+	}
+	if providers.IsProviderType(goal.Type) {
+		sg.providerInputs[urn] = inputs
 	}
 
 	// Next, give each analyzer -- if any -- a chance to inspect the resource too.
@@ -223,14 +304,15 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 	//  already existed.
 	contract.Assert(!recreating || hasOld)
 	if recreating {
-		logging.V(7).Infof("Planner decided to re-create replaced resource '%v' deleted due to dependent DBR", urn)
+		logging.Engine.V(7).Infof(
+			"Planner decided to re-create replaced resource '%v' deleted due to dependent DBR", urn)
 
 		// Unmark this resource as deleted, we now know it's being replaced instead.
 		delete(sg.deletes, urn)
 		sg.replaces[urn] = true
 		return []Step{
-			NewReplaceStep(sg.plan, old, new, nil, false),
-			NewCreateReplacementStep(sg.plan, event, old, new, nil, false),
+			NewReplaceStep(sg.plan, old, new, nil, nil, false),
+			NewCreateReplacementStep(sg.plan, event, old, new, nil, nil, false),
 		}, nil
 	}
 
@@ -243,15 +325,18 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 	//  to take its place. Since this is technically a replacement operation, we pend deletion of
 	//  read until the end of the plan.
 	if wasExternal {
-		logging.V(7).Infof("Planner recognized '%s' as old external resource, creating instead", urn)
+		logging.Engine.V(7).Infof("Planner recognized '%s' as old external resource, creating instead", urn)
+		if sg.isLocked(old, "replaced") {
+			return nil, errLockedResources
+		}
 		sg.creates[urn] = true
 		if err != nil {
 			return nil, err
 		}
 
 		return []Step{
-			NewCreateReplacementStep(sg.plan, event, old, new, nil, true),
-			NewReplaceStep(sg.plan, old, new, nil, true),
+			NewCreateReplacementStep(sg.plan, event, old, new, nil, nil, true),
+			NewReplaceStep(sg.plan, old, new, nil, nil, true),
 		}, nil
 	}
 
@@ -270,9 +355,12 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 		var diff plugin.DiffResult
 		if old.Provider != new.Provider {
 			diff = plugin.DiffResult{Changes: plugin.DiffSome, ReplaceKeys: []resource.PropertyKey{"provider"}}
+		} else if unchanged {
+			diff = plugin.DiffResult{Changes: plugin.DiffNone}
 		} else {
 			// Determine whether the change resulted in a diff.
-			d, diffErr := sg.diff(urn, old.ID, oldInputs, oldOutputs, inputs, prov, allowUnknowns)
+			d, diffErr := sg.diff(urn, old.ID, oldInputs, oldOutputs, inputs, sg.ignoredDefaults(old, new), prov,
+				allowUnknowns)
 			if diffErr != nil {
 				return nil, diffErr
 			}
@@ -285,27 +373,54 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 				"unrecognized diff state for %s: %d", urn, diff.Changes)
 		}
 
+		// The program may require that changes to some inputs replace the resource, whatever the provider says.
+		if !diff.Replace() {
+			if keys := replaceOnChangesKeys(goal.ReplaceOnChanges, oldInputs, inputs); len(keys) > 0 {
+				logging.Engine.V(7).Infof("replaceOnChanges requires that '%v' be replaced (keys=%v)", urn, keys)
+				diff = replaceOnChangesReplacement(diff, keys)
+			}
+		}
+
 		// If there were changes, check for a replacement vs. an in-place update.
 		if diff.Changes == plugin.DiffSome {
+			// The step decider, if any, may require that an in-place update be a replacement instead.
+			if decider := sg.opts.StepDecider; decider != nil && !diff.Replace() {
+				replace, decideErr := decider.DecideUpdate(old, new, diff)
+				if decideErr != nil {
+					return nil, decideErr
+				}
+				if replace {
+					logging.Engine.V(7).Infof("Step decider requires that '%v' be replaced", urn)
+					diff = deciderReplacement(diff, oldInputs, inputs)
+				}
+			}
+
 			if diff.Replace() {
+				if sg.isLocked(old, "replaced") {
+					return nil, errLockedResources
+				}
 				sg.replaces[urn] = true
 
 				// If we are going to perform a replacement, we need to recompute the default values.  The above logic
 				// had assumed that we were going to carry them over from the old resource, which is no longer true.
+				// The replacement is given a new random seed as well, so that e.g. its auto-generated name differs
+				// from that of the resource it replaces.
+				new.RandomSeed = newRandomSeed(sg.opts.RandomSeed, urn, new.RandomSeed)
+				new.InputsHash = hashInputs(goal.Properties, new.RandomSeed, provHash)
 				if prov != nil {
 					var failures []plugin.CheckFailure
-					inputs, failures, err = prov.Check(urn, nil, goal.Properties, allowUnknowns)
+					inputs, failures, err = plugin.CheckWithSeed(prov, urn, nil, goal.Properties, new.RandomSeed, allowUnknowns)
 					if err != nil {
 						return nil, err
-					} else if sg.issueCheckErrors(new, urn, failures) {
+					} else if sg.issueCheckErrors(new, urn, goal.SourcePosition, failures) {
 						return nil, errors.New("One or more resource validation errors occurred; refusing to proceed")
 					}
-					new.Inputs = inputs
+					new.SetInputs(inputs)
 				}
 
-				if logging.V(7) {
-					logging.V(7).Infof("Planner decided to replace '%v' (oldprops=%v inputs=%v)",
-						urn, oldInputs, new.Inputs)
+				if logging.Engine.V(7).Enabled() {
+					logging.Engine.V(7).Infof("Planner decided to replace '%v' (oldprops=%v inputs=%v)",
+						urn, oldInputs, new.Inputs())
 				}
 
 				// We have two approaches to performing replacements:
@@ -320,10 +435,15 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 				//       until pulumi/pulumi#624 is resolved, we cannot safely perform this operation on resources
 				//       that have dependent resources (we try to delete the resource while they refer to it).
 				//
-				// The provider is responsible for requesting which of these two modes to use.
+				// The provider is responsible for requesting which of these two modes to use, although the program
+				// may require the latter using the `deleteBeforeReplace` resource option.
+				if goal.DeleteBeforeReplace && !diff.DeleteBeforeReplace {
+					logging.Engine.V(7).Infof("deleteBeforeReplace requires that '%v' be deleted first", urn)
+					diff.DeleteBeforeReplace = true
+				}
 
 				if diff.DeleteBeforeReplace {
-					logging.V(7).Infof("Planner decided to delete-before-replacement for resource '%v'", urn)
+					logging.Engine.V(7).Infof("Planner decided to delete-before-replacement for resource '%v'", urn)
 					contract.Assert(sg.plan.depGraph != nil)
 
 					// DeleteBeforeCreate implies that we must immediately delete the resource. For correctness,
@@ -335,17 +455,35 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 					var steps []Step
 					dependents := sg.plan.depGraph.DependingOn(old)
 
+					// Each of the dependents will be replaced as well, so none of them may be locked.
+					locked := false
+					for _, dependentResource := range dependents {
+						if sg.isLocked(dependentResource, "replaced") {
+							locked = true
+						}
+					}
+					if locked {
+						return nil, errLockedResources
+					}
+
 					// Deletions must occur in reverse dependency order, and `deps` is returned in dependency
-					// order, so we iterate in reverse.
+					// order, so we iterate in reverse.  The resulting chain of dependents is recorded on the
+					// replacement, so that the cascade is visible before it happens.
+					var chain []resource.URN
 					for i := len(dependents) - 1; i >= 0; i-- {
 						dependentResource := dependents[i]
 
 						// If we already deleted this resource due to some other DBR, don't do it again.
-						if sg.deletes[urn] {
+						if sg.deletes[dependentResource.URN] {
 							continue
 						}
+						if err := sg.decideDelete(dependentResource); err != nil {
+							return nil, err
+						}
+						chain = append(chain, dependentResource.URN)
 
-						logging.V(7).Infof("Planner decided to delete '%v' due to dependence on condemned resource '%v'",
+						logging.Engine.V(7).Infof(
+							"Planner decided to delete '%v' due to dependence on condemned resource '%v'",
 							dependentResource.URN, urn)
 
 						steps = append(steps, NewDeleteReplacementStep(sg.plan, dependentResource, false))
@@ -354,24 +492,31 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 						sg.deletes[dependentResource.URN] = true
 					}
 
+					if err := sg.decideDelete(old); err != nil {
+						return nil, err
+					}
 					return append(steps,
 						NewDeleteReplacementStep(sg.plan, old, false),
-						NewReplaceStep(sg.plan, old, new, diff.ReplaceKeys, false),
-						NewCreateReplacementStep(sg.plan, event, old, new, diff.ReplaceKeys, false),
+						NewDeleteBeforeReplaceStep(sg.plan, old, new, diff.ReplaceKeys, diff.ReplaceReasons, chain),
+						NewCreateReplacementStep(sg.plan, event, old, new, diff.ReplaceKeys, diff.ReplaceReasons, false),
 					), nil
 				}
 
 				return []Step{
-					NewCreateReplacementStep(sg.plan, event, old, new, diff.ReplaceKeys, true),
-					NewReplaceStep(sg.plan, old, new, diff.ReplaceKeys, true),
+					NewCreateReplacementStep(sg.plan, event, old, new, diff.ReplaceKeys, diff.ReplaceReasons, true),
+					NewReplaceStep(sg.plan, old, new, diff.ReplaceKeys, diff.ReplaceReasons, true),
 					// note that the delete step is generated "later" on, after all creates/updates finish.
 				}, nil
 			}
 
 			// If we fell through, it's an update.
+			if sg.isLocked(old, "updated") {
+				return nil, errLockedResources
+			}
 			sg.updates[urn] = true
-			if logging.V(7) {
-				logging.V(7).Infof("Planner decided to update '%v' (oldprops=%v inputs=%v", urn, oldInputs, new.Inputs)
+			if logging.Engine.V(7).Enabled() {
+				logging.Engine.V(7).Infof("Planner decided to update '%v' (oldprops=%v inputs=%v",
+					urn, oldInputs, new.Inputs())
 			}
 			return []Step{NewUpdateStep(sg.plan, event, old, new, diff.StableKeys)}, nil
 		}
@@ -379,14 +524,17 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 		// If resource was unchanged, but there were initialization errors, generate an empty update
 		// step to attempt to "continue" awaiting initialization.
 		if len(old.InitErrors) > 0 {
+			if sg.isLocked(old, "updated") {
+				return nil, errLockedResources
+			}
 			sg.updates[urn] = true
 			return []Step{NewUpdateStep(sg.plan, event, old, new, diff.StableKeys)}, nil
 		}
 
 		// No need to update anything, the properties didn't change.
 		sg.sames[urn] = true
-		if logging.V(7) {
-			logging.V(7).Infof("Planner decided not to update '%v' (same) (inputs=%v)", urn, new.Inputs)
+		if logging.Engine.V(7).Enabled() {
+			logging.Engine.V(7).Infof("Planner decided not to update '%v' (same) (inputs=%v)", urn, new.Inputs())
 		}
 		return []Step{NewSameStep(sg.plan, event, old, new)}, nil
 	}
@@ -395,21 +543,39 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, err
 	//  If a resource isn't being recreated and it's not being updated or replaced,
 	//  it's just being created.
 	sg.creates[urn] = true
-	logging.V(7).Infof("Planner decided to create '%v' (inputs=%v)", urn, new.Inputs)
+	logging.Engine.V(7).Infof("Planner decided to create '%v' (inputs=%v)", urn, new.Inputs())
 	return []Step{NewCreateStep(sg.plan, event, new)}, nil
 }
 
-func (sg *stepGenerator) GenerateDeletes() []Step {
+// GenerateDeletes produces the steps required to delete the old resources that the program no longer registered, as
+// well as those pending deletion due to replacement.  If any of these resources are locked, they are all reported and
+// an error is returned.
+func (sg *stepGenerator) GenerateDeletes() ([]Step, error) {
 	// To compute the deletion list, we must walk the list of old resources *backwards*.  This is because the list is
 	// stored in dependency order, and earlier elements are possibly leaf nodes for later elements.  We must not delete
 	// dependencies prior to their dependent nodes.
 	var dels []Step
+	locked := false
 	if prev := sg.plan.prev; prev != nil {
+		targets, err := deleteTargets(prev.Resources, sg.opts.DeleteTargets)
+		if err != nil {
+			return nil, err
+		}
 		for i := len(prev.Resources) - 1; i >= 0; i-- {
 			// If this resource is explicitly marked for deletion or wasn't seen at all, delete it.
 			res := prev.Resources[i]
+			if targets != nil && !targets[res.URN] {
+				continue
+			}
 			if res.Delete {
-				logging.V(7).Infof("Planner decided to delete '%v' due to replacement", res.URN)
+				if sg.isLocked(res, "deleted") {
+					locked = true
+					continue
+				}
+				if err := sg.decideDelete(res); err != nil {
+					return nil, err
+				}
+				logging.Engine.V(7).Infof("Planner decided to delete '%v' due to replacement", res.URN)
 				// The below assert is commented-out because it's believed to be wrong.
 				//
 				// The original justification for this assert is that the author (swgillespie) believed that
@@ -427,26 +593,99 @@ func (sg *stepGenerator) GenerateDeletes() []Step {
 				// whenever we see multiple deletes for the same URN.
 				// contract.Assert(!sg.deletes[res.URN])
 				if sg.deletes[res.URN] {
-					logging.V(7).Infof(
+					logging.Engine.V(7).Infof(
 						"Planner is deleting pending-delete urn '%v' that has already been deleted", res.URN)
 				}
 				sg.deletes[res.URN] = true
 				dels = append(dels, NewDeleteReplacementStep(sg.plan, res, true))
 			} else if !sg.sames[res.URN] && !sg.updates[res.URN] && !sg.replaces[res.URN] && !sg.reads[res.URN] {
+				if sg.isLocked(res, "deleted") {
+					locked = true
+					continue
+				}
+				if err := sg.decideDelete(res); err != nil {
+					return nil, err
+				}
 				// NOTE: we deliberately do not check sg.deletes here, as it is possible for us to issue multiple
 				// delete steps for the same URN if the old checkpoint contained pending deletes.
-				logging.V(7).Infof("Planner decided to delete '%v'", res.URN)
+				logging.Engine.V(7).Infof("Planner decided to delete '%v'", res.URN)
 				sg.deletes[res.URN] = true
-				dels = append(dels, NewDeleteStep(sg.plan, res))
+				if sg.plan.preview {
+					dels = append(dels, NewDeleteStep(sg.plan, res))
+				} else {
+					// Remember that the program no longer declares this resource, so that it can be reported should
+					// it outlive this update.
+					dels = append(dels, NewUnreferencedDeleteStep(sg.plan, res))
+				}
 			}
 		}
 	}
-	return dels
+	if locked {
+		return nil, errLockedResources
+	}
+
+	// The step decider, if any, may reorder the deletes, provided that dependents are still deleted first.
+	if decider := sg.opts.StepDecider; decider != nil {
+		ordered, err := decider.OrderDeletes(dels)
+		if err != nil {
+			return nil, err
+		}
+		if err = checkDeleteOrder(dels, ordered); err != nil {
+			return nil, err
+		}
+		dels = ordered
+	}
+	return dels, nil
+}
+
+// decideDelete consults the step decider, if any, about deleting the given resource.
+func (sg *stepGenerator) decideDelete(res *resource.State) error {
+	if sg.opts.StepDecider == nil {
+		return nil
+	}
+	return sg.opts.StepDecider.DecideDelete(res)
+}
+
+// providerHash returns the hash (see hashProvider) of the configuration and plugin version of the given provider, or ""
+// if either is unknown.  Providers are configured with the inputs that this plan checked for them or, if this plan has
+// not registered them, with those of their last snapshot.
+func (sg *stepGenerator) providerHash(ref providers.Reference, prov plugin.Provider) string {
+	if hash, has := sg.providerHashes[ref.String()]; has {
+		return hash
+	}
+
+	var hash string
+	inputs, has := sg.providerInputs[ref.URN()]
+	if !has {
+		if old, hasOld := sg.plan.Olds()[ref.URN()]; hasOld && old.ID == ref.ID() {
+			inputs, has = old.Inputs(), true
+		}
+	}
+	if has {
+		if info, err := prov.GetPluginInfo(); err == nil && info.Version != nil {
+			hash = hashProvider(inputs, info.Version.String())
+		}
+	}
+	sg.providerHashes[ref.String()] = hash
+	return hash
+}
+
+// errLockedResources is returned when a plan would change resources that are locked.
+var errLockedResources = errors.New("One or more locked resources would have changed; refusing to proceed")
+
+// isLocked returns true, after reporting an error that names the resource, if the given old resource is locked and so
+// may not be updated, replaced, or deleted as indicated by the given verb.
+func (sg *stepGenerator) isLocked(old *resource.State, verb string) bool {
+	if !old.Locked {
+		return false
+	}
+	sg.plan.Diag().Errorf(diag.GetResourceLockedError(old.URN), old.Type, old.URN.Name(), verb, old.URN)
+	return true
 }
 
 // diff returns a DiffResult for the given resource.
 func (sg *stepGenerator) diff(urn resource.URN, id resource.ID, oldInputs, oldOutputs, newInputs resource.PropertyMap,
-	prov plugin.Provider, allowUnknowns bool) (plugin.DiffResult, error) {
+	defaulted []resource.PropertyKey, prov plugin.Provider, allowUnknowns bool) (plugin.DiffResult, error) {
 
 	// Workaround #1251: unexpected replaces.
 	//
@@ -460,6 +699,16 @@ func (sg *stepGenerator) diff(urn resource.URN, id resource.ID, oldInputs, oldOu
 		return plugin.DiffResult{Changes: plugin.DiffNone}, nil
 	}
 
+	// Likewise, differences in the values that the provider filled in for properties that the program did not give,
+	// either now or before, are not changes that the program made, so they alone do not make for a diff.  Any other
+	// differences are for the provider to diff.
+	if len(defaulted) > 0 &&
+		withoutProperties(oldInputs, defaulted).DeepEquals(withoutProperties(newInputs, defaulted)) {
+		logging.Engine.V(7).Infof("Planner ignored changes to '%v' properties filled in by its provider: %v",
+			urn, defaulted)
+		return plugin.DiffResult{Changes: plugin.DiffNone}, nil
+	}
+
 	// If there is no provider for this resource, simply return a "diffs exist" result.
 	if prov == nil {
 		return plugin.DiffResult{Changes: plugin.DiffSome}, nil
@@ -477,20 +726,81 @@ func (sg *stepGenerator) diff(urn resource.URN, id resource.ID, oldInputs, oldOu
 	return diff, nil
 }
 
-// issueCheckErrors prints any check errors to the diagnostics sink.
-func (sg *stepGenerator) issueCheckErrors(new *resource.State, urn resource.URN,
+// providerDefaulted returns the input properties of the given goal's resource whose values its provider fills in when
+// they are not given, and which the goal does not give.
+func (sg *stepGenerator) providerDefaulted(goal *resource.Goal) []resource.PropertyKey {
+	var keys []resource.PropertyKey
+	for _, k := range sg.opts.ProviderDefaults[goal.Type] {
+		if _, has := goal.Properties[k]; !has {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// ignoredDefaults returns the input properties whose differences diffs ignore: those that the provider filled in for
+// both the old and new states of a resource, because the program gave them neither time.  If the program gave such a
+// property before, but no longer does, the provider decides whether that is a change.  Nothing is ignored if full
+// diffs are requested.
+func (sg *stepGenerator) ignoredDefaults(old, new *resource.State) []resource.PropertyKey {
+	if sg.opts.ShowFullDiff {
+		return nil
+	}
+	var keys []resource.PropertyKey
+	for _, k := range new.ProviderDefaulted {
+		for _, o := range old.ProviderDefaulted {
+			if k == o {
+				keys = append(keys, k)
+				break
+			}
+		}
+	}
+	return keys
+}
+
+// withoutProperties returns the given properties less those with the given keys, copying them only if necessary.
+func withoutProperties(props resource.PropertyMap, keys []resource.PropertyKey) resource.PropertyMap {
+	result, copied := props, false
+	for _, k := range keys {
+		if _, has := props[k]; !has {
+			continue
+		}
+		if !copied {
+			result, copied = props.Copy(), true
+		}
+		delete(result, k)
+	}
+	return result
+}
+
+// issueCheckErrors prints any check errors to the diagnostics sink.  If the program reported where it allocated the
+// resource, the errors are attributed to that position.
+func (sg *stepGenerator) issueCheckErrors(new *resource.State, urn resource.URN, pos string,
 	failures []plugin.CheckFailure) bool {
 	if len(failures) == 0 {
 		return false
 	}
-	inputs := new.Inputs
+	at := diag.ParsePos(pos)
+	inputs := new.Inputs()
 	for _, failure := range failures {
+		reason := failure.Reason
+		if failure.Suggestion != "" {
+			reason = fmt.Sprintf("%s (suggestion: %s)", reason, failure.Suggestion)
+		}
+
 		if failure.Property != "" {
-			sg.plan.Diag().Errorf(diag.GetResourcePropertyInvalidValueError(urn),
-				new.Type, urn.Name(), failure.Property, inputs[failure.Property], failure.Reason)
+			// Failures may name nested properties by path (e.g. `tags[0].key`); show the offending value if we can.
+			value := inputs[failure.Property]
+			if path, err := resource.ParsePropertyPath(string(failure.Property)); err == nil {
+				if v, ok := path.Get(inputs); ok {
+					value = v
+				}
+			}
+			sg.plan.Diag().Errorf(diag.GetResourcePropertyInvalidValueError(urn).At(at),
+				new.Type, urn.Name(), failure.Property, value, reason)
 		} else {
 			sg.plan.Diag().Errorf(
-				diag.GetResourceInvalidError(urn), new.Type, urn.Name(), failure.Reason)
+				diag.GetResourceInvalidError(urn).At(at), new.Type, urn.Name(), reason)
 		}
 	}
 	return true
@@ -498,15 +808,21 @@ func (sg *stepGenerator) issueCheckErrors(new *resource.State, urn resource.URN,
 
 // newStepGenerator creates a new step generator that operates on the given plan.
 func newStepGenerator(plan *Plan, opts Options) *stepGenerator {
+	// Most resources in a large stack are registered again, unchanged, so the sets that they are recorded in are sized
+	// up front rather than grown one resource at a time.
+	olds := len(plan.Olds())
 	return &stepGenerator{
 		plan:     plan,
 		opts:     opts,
-		urns:     make(map[resource.URN]bool),
+		urns:     make(map[resource.URN]bool, olds),
 		reads:    make(map[resource.URN]bool),
 		creates:  make(map[resource.URN]bool),
-		sames:    make(map[resource.URN]bool),
+		sames:    make(map[resource.URN]bool, olds),
 		replaces: make(map[resource.URN]bool),
 		updates:  make(map[resource.URN]bool),
 		deletes:  make(map[resource.URN]bool),
+
+		providerInputs: make(map[resource.URN]resource.PropertyMap),
+		providerHashes: make(map[string]string),
 	}
 }
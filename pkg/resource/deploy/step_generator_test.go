@@ -0,0 +1,71 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestProviderDefaultedDiff(t *testing.T) {
+	snap, events := newBenchmarkSnapshot(1)
+	res, goal := snap.Resources[1], events[0].Goal()
+
+	// The provider filled in a region when the resource was created, which the program did not give.
+	inputs := res.Inputs().Copy()
+	inputs["region"] = resource.NewStringProperty("us-west-2")
+	res.SetInputs(inputs)
+	res.InputsHash, res.ProviderDefaulted = "", []resource.PropertyKey{"acl", "region"}
+
+	defaults := map[tokens.Type][]resource.PropertyKey{res.Type: {"acl", "region"}}
+	generate := func(opts Options) StepOp {
+		steps, err := newStepGenerator(newBenchmarkPlan(t, snap), opts).GenerateSteps(events[0])
+		assert.NoError(t, err)
+		assert.Len(t, steps, 1)
+		return steps[0].Op()
+	}
+
+	// The difference is ignored if the provider's schema says that it fills in the property...
+	assert.Equal(t, OpSame, generate(Options{ProviderDefaults: defaults}))
+
+	// ...but not if the program gives the property, nor if full diffs are requested.
+	goal.Properties["region"] = resource.NewStringProperty("us-east-1")
+	assert.Equal(t, OpUpdate, generate(Options{ProviderDefaults: defaults}))
+	delete(goal.Properties, "region")
+	assert.Equal(t, OpUpdate, generate(Options{ProviderDefaults: defaults, ShowFullDiff: true}))
+
+	// If the program gave the property before, the provider decides whether no longer giving it is a change.
+	res.ProviderDefaulted = []resource.PropertyKey{"acl"}
+	assert.Equal(t, OpUpdate, generate(Options{ProviderDefaults: defaults}))
+}
+
+func TestWithoutProperties(t *testing.T) {
+	props := resource.PropertyMap{
+		"a": resource.NewStringProperty("a"),
+		"b": resource.NewStringProperty("b"),
+	}
+
+	without := withoutProperties(props, []resource.PropertyKey{"a", "c"})
+	assert.Equal(t, resource.PropertyMap{"b": resource.NewStringProperty("b")}, without)
+	assert.Len(t, props, 2)
+
+	// If none of the keys are present, the properties are returned as they are.
+	without = withoutProperties(props, []resource.PropertyKey{"d"})
+	assert.Equal(t, props, without)
+}
@@ -17,14 +17,28 @@ package deploy
 import (
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 // Target represents information about a deployment target.
 type Target struct {
-	Name      tokens.QName     // the target stack name.
-	Config    config.Map       // optional configuration key/value pairs.
-	Decrypter config.Decrypter // decrypter for secret configuration values.
-	Snapshot  *Snapshot        // the last snapshot deployed to the target.
+	Name       tokens.QName          // the target stack name.
+	Config     config.Map            // optional configuration key/value pairs.
+	Decrypter  config.Decrypter      // decrypter for secret configuration values.
+	EnvVars    map[string]string     // optional environment variables to give the program and its plugins.
+	Snapshot   *Snapshot             // the last snapshot deployed to the target.
+	AutoNaming *workspace.AutoNaming // optional stack-specific overrides for physical resource naming.
+	Guard      *workspace.StackGuard // optional restrictions on when the target may be changed.
+
+	// CredentialProfiles optionally selects, by package, the credentials that default providers use.
+	CredentialProfiles map[string]*workspace.CredentialProfile
+
+	// SuppressWarnings optionally silences warnings for this stack, besides those that the project silences.
+	SuppressWarnings []workspace.WarningSuppression
+
+	// SecretPaths optionally gives `<type>:<path>` rules for the resource properties whose values are secret, besides
+	// those that the project gives.
+	SecretPaths []string
 }
 
 // GetPackageConfig returns the set of configuration parameters for the indicated package, if any.
@@ -45,3 +59,13 @@ func (t *Target) GetPackageConfig(pkg tokens.Package) (map[config.Key]string, er
 	}
 	return result, nil
 }
+
+// GetExpectedAccount returns the cloud account, subscription, or project that the target's `pulumi:expectedAccount`
+// configuration pins it to, or "" if it is not pinned.
+func (t *Target) GetExpectedAccount() (string, error) {
+	c, has := t.Config[config.MustMakeKey("pulumi", "expectedAccount")]
+	if !has {
+		return "", nil
+	}
+	return c.Value(t.Decrypter)
+}
@@ -0,0 +1,42 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+)
+
+func TestGetExpectedAccount(t *testing.T) {
+	target := &Target{Config: config.Map{
+		config.MustMakeKey("aws", "region"): config.NewValue("us-west-2"),
+	}}
+	account, err := target.GetExpectedAccount()
+	assert.NoError(t, err)
+	assert.Equal(t, "", account)
+
+	target.Config[config.MustMakeKey("pulumi", "expectedAccount")] = config.NewValue("123456789012")
+	account, err = target.GetExpectedAccount()
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789012", account)
+
+	// The pin is not package configuration, so it is never passed to providers as such.
+	cfg, err := target.GetPackageConfig("aws")
+	assert.NoError(t, err)
+	assert.Len(t, cfg, 1)
+}
@@ -0,0 +1,195 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package discovery turns the existing resources that a provider discovers into an import manifest, which lists them
+// by type and ID, and from there into a stack's state and a skeleton of a program that manages them.  It also generates
+// programs that reproduce the resources already in a stack's state.
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/version"
+)
+
+// Manifest lists existing resources to import into a stack.
+type Manifest struct {
+	Resources []Resource `json:"resources"`
+}
+
+// Resource is a single existing resource to import.
+type Resource struct {
+	Type       tokens.Type            `json:"type"`                 // the resource's type.
+	Name       string                 `json:"name"`                 // the name the resource will have in the stack.
+	ID         resource.ID            `json:"id"`                   // the resource's ID.
+	Properties map[string]interface{} `json:"properties,omitempty"` // the resource's inputs, as discovered.
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// NewManifest creates a manifest that lists the given discovered resources, ordered by type and then by ID.  Each
+// resource is named after the name its provider suggested, or else its ID, made unique amongst those of its type.
+func NewManifest(discovered []plugin.DiscoveredResource) *Manifest {
+	sorted := make([]plugin.DiscoveredResource, len(discovered))
+	copy(sorted, discovered)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Type != sorted[j].Type {
+			return sorted[i].Type < sorted[j].Type
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	m := &Manifest{}
+	used := make(map[tokens.Type]map[string]bool)
+	for _, d := range sorted {
+		if used[d.Type] == nil {
+			used[d.Type] = make(map[string]bool)
+		}
+
+		base := resourceName(d)
+		name := base
+		for i := 2; used[d.Type][name]; i++ {
+			name = fmt.Sprintf("%s-%d", base, i)
+		}
+		used[d.Type][name] = true
+
+		var props map[string]interface{}
+		if len(d.Properties) > 0 {
+			props = d.Properties.Mappable()
+		}
+		m.Resources = append(m.Resources, Resource{Type: d.Type, Name: name, ID: d.ID, Properties: props})
+	}
+	return m
+}
+
+// resourceName returns the base name of a discovered resource: its suggested name, or else the last segment of its
+// ID, with any characters that would be awkward in a URN replaced.
+func resourceName(d plugin.DiscoveredResource) string {
+	name := d.Name
+	if name == "" {
+		name = string(d.ID)
+		if i := strings.LastIndexAny(name, "/:"); i >= 0 && i < len(name)-1 {
+			name = name[i+1:]
+		}
+	}
+	name = strings.Trim(unsafeNameChars.ReplaceAllString(name, "-"), "-")
+	if name == "" {
+		name = strings.ToLower(string(d.Type.Name()))
+	}
+	return name
+}
+
+// ParseManifest parses and validates a JSON import manifest.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.Wrap(err, "could not parse import manifest")
+	}
+
+	seen := make(map[string]bool)
+	for i, r := range m.Resources {
+		if r.Type == "" || r.Name == "" || r.ID == "" {
+			return nil, errors.Errorf("resource %d of the import manifest must have a type, a name, and an ID", i)
+		}
+		key := string(r.Type) + "::" + r.Name
+		if seen[key] {
+			return nil, errors.Errorf("the import manifest lists more than one %s named '%s'", r.Type, r.Name)
+		}
+		seen[key] = true
+	}
+	return &m, nil
+}
+
+// Snapshot adds the manifest's resources to the given snapshot of a stack, which may be nil, and returns the result.
+// Each resource is a child of the stack's root resource, and has its discovered properties as both its inputs and
+// outputs; run a refresh afterwards to read their complete state.  It is an error for the stack to already have a
+// resource of the same type and name as one in the manifest.
+//
+// Each resource is managed by the default provider for its package.  If the stack already has that provider, the
+// resource references it; otherwise the resource references no provider, and the next update adds one.
+func (m *Manifest) Snapshot(base *deploy.Snapshot, stackName tokens.QName,
+	proj tokens.PackageName) (*deploy.Snapshot, error) {
+
+	var resources []*resource.State
+	var rootURN resource.URN
+	existing := make(map[resource.URN]bool)
+	defaultProviders := make(map[resource.URN]*resource.State)
+	if base != nil {
+		for _, res := range base.Resources {
+			if res.Type == resource.RootStackType && res.Parent == "" {
+				rootURN = res.URN
+			}
+			if providers.IsProviderType(res.Type) && !res.Delete {
+				defaultProviders[res.URN] = res
+			}
+			existing[res.URN] = true
+			resources = append(resources, res)
+		}
+	}
+	if rootURN == "" {
+		rootName := tokens.QName(string(proj) + "-" + string(stackName))
+		rootURN = resource.NewURN(stackName, proj, "", resource.RootStackType, rootName)
+		resources = append([]*resource.State{
+			resource.NewState(resource.RootStackType, rootURN, false, false, "", resource.PropertyMap{}, nil, "",
+				false, false, nil, nil, ""),
+		}, resources...)
+	}
+
+	for _, r := range m.Resources {
+		urn := resource.NewURN(stackName, proj, "", r.Type, tokens.QName(r.Name))
+		if existing[urn] {
+			return nil, errors.Errorf("the stack already has a resource named %s", urn)
+		}
+
+		// Adding a second default provider alongside the stack's own would leave the stack with two resources of the
+		// same URN, so reference the existing one if there is one.
+		var provider string
+		provURN := resource.NewURN(stackName, proj, "", providers.MakeProviderType(r.Type.Package()), "default")
+		if prov, ok := defaultProviders[provURN]; ok {
+			ref, err := providers.NewReference(prov.URN, prov.ID)
+			if err != nil {
+				return nil, err
+			}
+			provider = ref.String()
+		}
+
+		props := resource.NewPropertyMapFromMap(r.Properties)
+		resources = append(resources, resource.NewState(r.Type, urn, true, false, r.ID, props, props.Copy(),
+			rootURN, false, false, nil, nil, provider))
+	}
+
+	// Keep the base snapshot's manifest, and with it the plugins that its resources were created with.
+	manifest := deploy.Manifest{}
+	var pending []resource.Operation
+	if base != nil {
+		manifest = base.Manifest
+		pending = base.PendingOperations
+	}
+	manifest.Time = time.Now()
+	manifest.Version = version.Version
+	manifest.Magic = manifest.NewMagic()
+	return deploy.NewSnapshot(manifest, resources, pending), nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestNewManifest(t *testing.T) {
+	t.Parallel()
+
+	m := NewManifest([]plugin.DiscoveredResource{
+		{Type: "aws:s3/bucket:Bucket", ID: "logs", Name: "logs"},
+		{Type: "aws:ec2/instance:Instance", ID: "i-0123", Name: "web server",
+			Properties: resource.NewPropertyMapFromMap(map[string]interface{}{"instanceType": "t2.micro"})},
+		{Type: "aws:s3/bucket:Bucket", ID: "arn:aws:s3:::assets"},
+		{Type: "aws:s3/bucket:Bucket", ID: "other-logs", Name: "logs"},
+	})
+
+	assert.Equal(t, []Resource{
+		{Type: "aws:ec2/instance:Instance", Name: "web-server", ID: "i-0123",
+			Properties: map[string]interface{}{"instanceType": "t2.micro"}},
+		{Type: "aws:s3/bucket:Bucket", Name: "assets", ID: "arn:aws:s3:::assets"},
+		{Type: "aws:s3/bucket:Bucket", Name: "logs", ID: "logs"},
+		{Type: "aws:s3/bucket:Bucket", Name: "logs-2", ID: "other-logs"},
+	}, m.Resources)
+}
+
+func TestParseManifest(t *testing.T) {
+	t.Parallel()
+
+	m, err := ParseManifest([]byte(`{"resources": [{"type": "aws:s3/bucket:Bucket", "name": "logs", "id": "logs"}]}`))
+	assert.NoError(t, err)
+	assert.Len(t, m.Resources, 1)
+
+	_, err = ParseManifest([]byte(`{"resources": [{"type": "aws:s3/bucket:Bucket", "name": "logs"}]}`))
+	assert.Error(t, err)
+
+	_, err = ParseManifest([]byte(`{"resources": [
+		{"type": "aws:s3/bucket:Bucket", "name": "logs", "id": "a"},
+		{"type": "aws:s3/bucket:Bucket", "name": "logs", "id": "b"}]}`))
+	assert.Error(t, err)
+}
+
+func TestManifestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	m := &Manifest{Resources: []Resource{
+		{
+			Type: "aws:s3/bucket:Bucket", Name: "logs", ID: "logs-1234",
+			Properties: map[string]interface{}{"acl": "private"},
+		},
+	}}
+	snap, err := m.Snapshot(nil, "dev", "proj")
+	assert.NoError(t, err)
+	assert.NoError(t, snap.VerifyIntegrity())
+	assert.Len(t, snap.Resources, 2)
+
+	res := snap.Resources[1]
+	assert.Equal(t, resource.URN("urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs"), res.URN)
+	assert.Equal(t, resource.ID("logs-1234"), res.ID)
+	assert.Equal(t, snap.Resources[0].URN, res.Parent)
+	assert.Equal(t, "private", res.Inputs()["acl"].StringValue())
+	assert.Equal(t, "private", res.Outputs()["acl"].StringValue())
+
+	// Resources may be added to a stack that already has some, but not if their URNs clash.  The stack's manifest,
+	// along with its plugins, is kept.
+	snap.Manifest.Plugins = []workspace.PluginInfo{{Name: "aws", Kind: workspace.ResourcePlugin}}
+	other := &Manifest{Resources: []Resource{{Type: "aws:s3/bucket:Bucket", Name: "assets", ID: "assets-1234"}}}
+	merged, err := other.Snapshot(snap, "dev", "proj")
+	assert.NoError(t, err)
+	assert.NoError(t, merged.VerifyIntegrity())
+	assert.Len(t, merged.Resources, 3)
+	assert.Equal(t, snap.Resources[0].URN, merged.Resources[2].Parent)
+	assert.Equal(t, snap.Manifest.Plugins, merged.Manifest.Plugins)
+	_, err = m.Snapshot(snap, "dev", "proj")
+	assert.Error(t, err)
+
+	// The manifest round-trips through JSON.
+	b, err := json.Marshal(m)
+	assert.NoError(t, err)
+	parsed, err := ParseManifest(b)
+	assert.NoError(t, err)
+	assert.Equal(t, m, parsed)
+}
@@ -0,0 +1,484 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// ProgramFile returns the name of the file that holds a program skeleton for the given language runtime.  It is not the
+// name of the runtime's main program file, so that a skeleton may be generated alongside an existing program and then
+// imported by it.
+func ProgramFile(runtime string) (string, error) {
+	switch runtime {
+	case "nodejs":
+		return "imported.ts", nil
+	case "python":
+		return "imported.py", nil
+	default:
+		return "", errors.Errorf("program skeletons may only be generated for nodejs and python projects, not %s",
+			runtime)
+	}
+}
+
+// GenerateProgram generates the skeleton of a program, in the language of the given runtime, that declares each of
+// the manifest's resources with the properties that were discovered for it.  The skeleton is a starting point: some
+// inputs may not have been discovered, and others may be better written in terms of other resources' outputs.
+func GenerateProgram(m *Manifest, runtime string) ([]byte, error) {
+	var g generator
+	switch runtime {
+	case "nodejs":
+		g = &nodejsGenerator{}
+	case "python":
+		g = &pythonGenerator{}
+	default:
+		_, err := ProgramFile(runtime)
+		return nil, err
+	}
+
+	resources := make([]programResource, len(m.Resources))
+	for i, r := range m.Resources {
+		resources[i] = programResource{Resource: r}
+	}
+	return generate(g, []string{
+		"Resources discovered in existing infrastructure by `pulumi import --discover`.  Fill in any",
+		"inputs that were not discovered, then run `pulumi preview` to check the program against them.",
+	}, resources), nil
+}
+
+// programResource is a resource to declare in a generated program, along with the options to declare it with.  The
+// options refer to other resources by URN, and only to those that the program also declares.
+type programResource struct {
+	Resource
+	urn       resource.URN
+	parent    resource.URN
+	dependsOn []resource.URN
+	provider  resource.URN
+	protect   bool
+}
+
+// resourceOptions are the options of a resource in a generated program, which refer to other resources by the names
+// of the variables that hold them.
+type resourceOptions struct {
+	parent    string
+	dependsOn []string
+	provider  string
+	protect   bool
+}
+
+func (opts resourceOptions) empty() bool {
+	return opts.parent == "" && len(opts.dependsOn) == 0 && opts.provider == "" && !opts.protect
+}
+
+// generate writes a program that starts with the given comment and then declares each of the given resources.
+func generate(g generator, header []string, resources []programResource) []byte {
+	var pkgs []string
+	seen := make(map[string]bool)
+	for _, r := range resources {
+		if pkg := string(sdkType(r.Type).Package()); !seen[pkg] {
+			seen[pkg] = true
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	sort.Strings(pkgs)
+
+	// Name each resource's variable first, so that options may refer to resources declared later in the program.
+	vars := make(map[string]bool)
+	names := make([]string, len(resources))
+	urns := make(map[resource.URN]string)
+	for i, r := range resources {
+		base := g.variable(r.Name)
+		name := base
+		for j := 2; vars[name]; j++ {
+			name = base + strconv.Itoa(j)
+		}
+		vars[name] = true
+		names[i] = name
+		if r.urn != "" {
+			urns[r.urn] = name
+		}
+	}
+
+	options := make([]resourceOptions, len(resources))
+	hasOptions := false
+	for i, r := range resources {
+		opts := resourceOptions{
+			parent:   urns[r.parent],
+			provider: urns[r.provider],
+			protect:  r.protect,
+		}
+		for _, dep := range r.dependsOn {
+			if name, has := urns[dep]; has {
+				opts.dependsOn = append(opts.dependsOn, name)
+			}
+		}
+		options[i] = opts
+		hasOptions = hasOptions || !opts.empty()
+	}
+
+	var out bytes.Buffer
+	g.imports(&out, pkgs, hasOptions)
+	fmt.Fprintln(&out)
+	for _, line := range header {
+		g.comment(&out, line)
+	}
+	g.resources(&out)
+	for i, r := range resources {
+		fmt.Fprintln(&out)
+		g.resource(&out, names[i], r.Resource, options[i])
+	}
+	return out.Bytes()
+}
+
+// generator writes the parts of a program in a particular language.
+type generator interface {
+	// imports writes the imports of the given packages, and of the core SDK if resources have options.
+	imports(out *bytes.Buffer, pkgs []string, options bool)
+	comment(out *bytes.Buffer, text string)
+	// resources writes whatever introduces the program's resources, after its imports and header comment.
+	resources(out *bytes.Buffer)
+	variable(name string) string
+	resource(out *bytes.Buffer, variable string, r Resource, opts resourceOptions)
+}
+
+// sdkType returns the type of the SDK class that declares a resource of the given type: provider resources, whose
+// types are like `pulumi:providers:aws`, are declared by a `Provider` class in their package's index module.
+func sdkType(typ tokens.Type) tokens.Type {
+	if providers.IsProviderType(typ) {
+		return tokens.Type(string(typ.Name()) + ":index:Provider")
+	}
+	return typ
+}
+
+// typeReference returns the reference to a resource type's class within its package's SDK: `s3.Bucket` for
+// `aws:s3/bucket:Bucket`, or just `Provider` for a type in the package's index module.
+func typeReference(typ tokens.Type) string {
+	mod := string(typ.Module().Name())
+	if i := strings.Index(mod, "/"); i >= 0 {
+		mod = mod[:i]
+	}
+	if mod == "" || mod == "index" {
+		return string(typ.Name())
+	}
+	return mod + "." + string(typ.Name())
+}
+
+// words splits a name into its words, at punctuation and at changes from lower to upper case.
+func words(name string) []string {
+	var result []string
+	var word []rune
+	var prev rune
+	for _, c := range name {
+		switch {
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			if len(word) > 0 {
+				result, word = append(result, string(word)), nil
+			}
+		case unicode.IsUpper(c) && unicode.IsLower(prev) && len(word) > 0:
+			result, word = append(result, string(word)), []rune{c}
+		default:
+			word = append(word, c)
+		}
+		prev = c
+	}
+	if len(word) > 0 {
+		result = append(result, string(word))
+	}
+	return result
+}
+
+// identifier joins words into an identifier, prefixing it if it would otherwise start with a digit.
+func identifier(ws []string, join func(i int, w string) string) string {
+	var b bytes.Buffer
+	for i, w := range ws {
+		b.WriteString(join(i, w))
+	}
+	id := b.String()
+	if id == "" || unicode.IsDigit(rune(id[0])) {
+		id = "r" + strings.Title(id)
+	}
+	return id
+}
+
+// writeValue writes a property value as a literal, using the given spellings of true, false, and null, and quoting
+// object keys with the given function.
+func writeValue(out *bytes.Buffer, v interface{}, indent string, t, f, null string, key func(string) string) {
+	switch v := v.(type) {
+	case nil:
+		out.WriteString(null)
+	case bool:
+		if v {
+			out.WriteString(t)
+		} else {
+			out.WriteString(f)
+		}
+	case float64:
+		out.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		b, err := json.Marshal(v)
+		if err != nil {
+			b = []byte(strconv.Quote(v))
+		}
+		out.Write(b)
+	case []interface{}:
+		if len(v) == 0 {
+			out.WriteString("[]")
+			return
+		}
+		out.WriteString("[\n")
+		for _, e := range v {
+			out.WriteString(indent + "    ")
+			writeValue(out, e, indent+"    ", t, f, null, key)
+			out.WriteString(",\n")
+		}
+		out.WriteString(indent + "]")
+	case map[string]interface{}:
+		if len(v) == 0 {
+			out.WriteString("{}")
+			return
+		}
+		out.WriteString("{\n")
+		for _, k := range sortedKeys(v) {
+			out.WriteString(indent + "    " + key(k) + ": ")
+			writeValue(out, v[k], indent+"    ", t, f, null, key)
+			out.WriteString(",\n")
+		}
+		out.WriteString(indent + "}")
+	default:
+		out.WriteString(null)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func quote(s string) string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return strconv.Quote(s)
+	}
+	return string(b)
+}
+
+// nodejsGenerator writes TypeScript.
+type nodejsGenerator struct{}
+
+func (g *nodejsGenerator) imports(out *bytes.Buffer, pkgs []string, options bool) {
+	for _, pkg := range pkgs {
+		fmt.Fprintf(out, "import * as %s from %s;\n", g.variable(pkg), quote("@pulumi/"+pkg))
+	}
+}
+
+func (g *nodejsGenerator) comment(out *bytes.Buffer, text string) {
+	fmt.Fprintf(out, "// %s\n", text)
+}
+
+func (g *nodejsGenerator) resources(out *bytes.Buffer) {}
+
+func (g *nodejsGenerator) variable(name string) string {
+	return identifier(words(name), func(i int, w string) string {
+		if i == 0 {
+			return strings.ToLower(w[:1]) + w[1:]
+		}
+		return strings.ToUpper(w[:1]) + w[1:]
+	})
+}
+
+func (g *nodejsGenerator) resource(out *bytes.Buffer, variable string, r Resource, opts resourceOptions) {
+	typ := sdkType(r.Type)
+	pkg := g.variable(string(typ.Package()))
+	fmt.Fprintf(out, "const %s = new %s.%s(%s, ", variable, pkg, typeReference(typ), quote(r.Name))
+	writeValue(out, r.Properties, "", "true", "false", "undefined", func(k string) string {
+		if isIdentifier(k) {
+			return k
+		}
+		return quote(k)
+	})
+	if !opts.empty() {
+		var fields []string
+		if opts.parent != "" {
+			fields = append(fields, "parent: "+opts.parent)
+		}
+		if len(opts.dependsOn) > 0 {
+			fields = append(fields, "dependsOn: ["+strings.Join(opts.dependsOn, ", ")+"]")
+		}
+		if opts.provider != "" {
+			fields = append(fields, "provider: "+opts.provider)
+		}
+		if opts.protect {
+			fields = append(fields, "protect: true")
+		}
+		fmt.Fprintf(out, ", { %s }", strings.Join(fields, ", "))
+	}
+	out.WriteString(");\n")
+}
+
+// pythonGenerator writes Python.
+type pythonGenerator struct{}
+
+func (g *pythonGenerator) imports(out *bytes.Buffer, pkgs []string, options bool) {
+	if options {
+		fmt.Fprintln(out, "import pulumi")
+	}
+	for _, pkg := range pkgs {
+		mod := "pulumi_" + strings.Replace(pkg, "-", "_", -1)
+		fmt.Fprintf(out, "import %s as %s\n", mod, g.variable(pkg))
+	}
+}
+
+func (g *pythonGenerator) comment(out *bytes.Buffer, text string) {
+	fmt.Fprintf(out, "# %s\n", text)
+}
+
+func (g *pythonGenerator) resources(out *bytes.Buffer) {}
+
+func (g *pythonGenerator) variable(name string) string {
+	return identifier(words(name), func(i int, w string) string {
+		if i == 0 {
+			return strings.ToLower(w)
+		}
+		return "_" + strings.ToLower(w)
+	})
+}
+
+func (g *pythonGenerator) resource(out *bytes.Buffer, variable string, r Resource, opts resourceOptions) {
+	typ := sdkType(r.Type)
+	pkg := g.variable(string(typ.Package()))
+	fmt.Fprintf(out, "%s = %s.%s(%s", variable, pkg, typeReference(typ), quote(r.Name))
+	for _, k := range sortedKeys(r.Properties) {
+		fmt.Fprintf(out, ",\n    %s=", g.variable(k))
+		writeValue(out, r.Properties[k], "    ", "True", "False", "None", quote)
+	}
+	if !opts.empty() {
+		var args []string
+		if opts.parent != "" {
+			args = append(args, "parent="+opts.parent)
+		}
+		if len(opts.dependsOn) > 0 {
+			args = append(args, "depends_on=["+strings.Join(opts.dependsOn, ", ")+"]")
+		}
+		if opts.provider != "" {
+			args = append(args, "provider="+opts.provider)
+		}
+		if opts.protect {
+			args = append(args, "protect=True")
+		}
+		fmt.Fprintf(out, ",\n    opts=pulumi.ResourceOptions(%s)", strings.Join(args, ", "))
+	}
+	out.WriteString(")\n")
+}
+
+// isIdentifier returns true if the given object key may be written without quotes in TypeScript.
+func isIdentifier(s string) bool {
+	for i, c := range s {
+		if !(unicode.IsLetter(c) || c == '_' || c == '$' || i > 0 && unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// yamlGenerator writes a program for the YAML runtime, which is a complete Pulumi.yaml.
+type yamlGenerator struct {
+	project tokens.PackageName
+}
+
+func (g *yamlGenerator) imports(out *bytes.Buffer, pkgs []string, options bool) {
+	fmt.Fprintf(out, "name: %s\n", g.project)
+	fmt.Fprintln(out, "runtime: yaml")
+}
+
+func (g *yamlGenerator) comment(out *bytes.Buffer, text string) {
+	fmt.Fprintf(out, "# %s\n", text)
+}
+
+func (g *yamlGenerator) resources(out *bytes.Buffer) {
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "resources:")
+}
+
+func (g *yamlGenerator) variable(name string) string {
+	return name
+}
+
+func (g *yamlGenerator) resource(out *bytes.Buffer, variable string, r Resource, opts resourceOptions) {
+	fmt.Fprintf(out, "  %s:\n", yamlString(variable))
+	fmt.Fprintf(out, "    type: %s\n", yamlString(string(r.Type)))
+	if variable != r.Name {
+		fmt.Fprintf(out, "    name: %s\n", yamlString(r.Name))
+	}
+	if len(r.Properties) > 0 {
+		fmt.Fprintln(out, "    properties:")
+		writeYAML(out, r.Properties, "      ")
+	}
+	if !opts.empty() {
+		fmt.Fprintln(out, "    options:")
+		if opts.parent != "" {
+			fmt.Fprintf(out, "      parent: ${%s}\n", opts.parent)
+		}
+		if len(opts.dependsOn) > 0 {
+			fmt.Fprintln(out, "      dependsOn:")
+			for _, dep := range opts.dependsOn {
+				fmt.Fprintf(out, "        - ${%s}\n", dep)
+			}
+		}
+		if opts.provider != "" {
+			fmt.Fprintf(out, "      provider: ${%s}\n", opts.provider)
+		}
+		if opts.protect {
+			fmt.Fprintln(out, "      protect: true")
+		}
+	}
+}
+
+// yamlString returns a string as a YAML scalar, quoting it only if it must be.
+func yamlString(s string) string {
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return quote(s)
+	}
+	return strings.TrimSuffix(string(b), "\n")
+}
+
+// writeYAML writes a value as a YAML block, indenting each of its lines.
+func writeYAML(out *bytes.Buffer, v interface{}, indent string) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		b = []byte(quote(fmt.Sprintf("%v", v)) + "\n")
+	}
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(string(b), "\n"), "\n") {
+		out.WriteString(indent + line)
+	}
+	out.WriteString("\n")
+}
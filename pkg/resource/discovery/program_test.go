@@ -0,0 +1,78 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testManifest = &Manifest{Resources: []Resource{
+	{Type: "aws:s3/bucket:Bucket", Name: "site-logs", ID: "site-logs", Properties: map[string]interface{}{
+		"acl":          "private",
+		"forceDestroy": false,
+		"tags":         map[string]interface{}{"env": "prod", "cost-center": "42"},
+	}},
+	{Type: "aws:index/provider:Provider", Name: "2nd", ID: "p"},
+}}
+
+func TestGenerateNodeJSProgram(t *testing.T) {
+	t.Parallel()
+
+	program, err := GenerateProgram(testManifest, "nodejs")
+	assert.NoError(t, err)
+	assert.Equal(t, `import * as aws from "@pulumi/aws";
+
+// Resources discovered in existing infrastructure by `+"`pulumi import --discover`"+`.  Fill in any
+// inputs that were not discovered, then run `+"`pulumi preview`"+` to check the program against them.
+
+const siteLogs = new aws.s3.Bucket("site-logs", {
+    acl: "private",
+    forceDestroy: false,
+    tags: {
+        "cost-center": "42",
+        env: "prod",
+    },
+});
+
+const r2nd = new aws.Provider("2nd", {});
+`, string(program))
+}
+
+func TestGeneratePythonProgram(t *testing.T) {
+	t.Parallel()
+
+	program, err := GenerateProgram(testManifest, "python")
+	assert.NoError(t, err)
+	assert.Equal(t, `import pulumi_aws as aws
+
+# Resources discovered in existing infrastructure by `+"`pulumi import --discover`"+`.  Fill in any
+# inputs that were not discovered, then run `+"`pulumi preview`"+` to check the program against them.
+
+site_logs = aws.s3.Bucket("site-logs",
+    acl="private",
+    force_destroy=False,
+    tags={
+        "cost-center": "42",
+        "env": "prod",
+    })
+
+r2nd = aws.Provider("2nd")
+`, string(program))
+
+	_, err = GenerateProgram(testManifest, "go")
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// GenerateStackProgram generates a program, in the language of the given runtime, that declares the resources in a
+// snapshot of a stack with their current inputs and options.  Unlike the skeleton that GenerateProgram generates for
+// discovered resources, the result is meant to reproduce the stack as it is, e.g. after resources have been imported
+// or to recover a program's lost source.  The yaml, nodejs and python runtimes are supported.
+//
+// Component resources are left out, since the resources they contain are declared by code that cannot be recovered
+// from the snapshot, as are the default providers and resources that are read rather than managed.  Inputs that were
+// computed from other resources' outputs are written as the values they had, rather than as references.
+func GenerateStackProgram(snap *deploy.Snapshot, proj tokens.PackageName, runtime string) ([]byte, error) {
+	var g generator
+	switch runtime {
+	case "yaml":
+		g = &yamlGenerator{project: proj}
+	case "nodejs":
+		g = &nodejsGenerator{}
+	case "python":
+		g = &pythonGenerator{}
+	default:
+		return nil, errors.Errorf("programs may only be generated in yaml, nodejs, or python, not %s", runtime)
+	}
+
+	var resources []programResource
+	components := 0
+	if snap != nil {
+		for _, res := range snap.Resources {
+			switch {
+			case res.Delete || res.External || res.Unreferenced:
+				continue
+			case !res.Custom:
+				if res.Type != resource.RootStackType {
+					components++
+				}
+				continue
+			case providers.IsProviderType(res.Type) && res.URN.Name() == "default":
+				continue
+			}
+
+			r := programResource{
+				Resource: Resource{
+					Type:       res.Type,
+					Name:       string(res.URN.Name()),
+					ID:         res.ID,
+					Properties: res.Inputs().Mappable(),
+				},
+				urn:       res.URN,
+				parent:    res.Parent,
+				dependsOn: res.DependsOn,
+				protect:   res.Protect,
+			}
+			if res.Provider != "" {
+				ref, err := providers.ParseReference(res.Provider)
+				if err != nil {
+					return nil, errors.Wrapf(err, "resource %s has an invalid provider reference", res.URN)
+				}
+				r.provider = ref.URN()
+			}
+			resources = append(resources, r)
+		}
+	}
+
+	header := []string{
+		"Resources in the stack's state, as generated by `pulumi stack export --generate-code`.  Run",
+		"`pulumi preview` to check that the program matches them; it should report no changes.",
+	}
+	if components > 0 {
+		noun := "resources were"
+		if components == 1 {
+			noun = "resource was"
+		}
+		header = append(header, fmt.Sprintf(
+			"%d component %s left out, so the resources that belonged to them have new URNs.", components, noun))
+	}
+	return generate(g, header, resources), nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func newTestStackSnapshot() *deploy.Snapshot {
+	urn := func(t tokens.Type, name string) resource.URN {
+		return resource.NewURN("dev", "proj", "", t, tokens.QName(name))
+	}
+	newState := func(t tokens.Type, name string, custom bool, parent resource.URN, provider string,
+		inputs resource.PropertyMap) *resource.State {
+		if inputs == nil {
+			inputs = resource.PropertyMap{}
+		}
+		var id resource.ID
+		if custom {
+			id = resource.ID(name + "-id")
+		}
+		return resource.NewState(t, urn(t, name), custom, false, id, inputs, inputs, parent,
+			false, false, nil, nil, provider)
+	}
+
+	root := newState(resource.RootStackType, "proj-dev", false, "", "", nil)
+	defaultProv := newState(providers.MakeProviderType("aws"), "default", true, "", "", nil)
+	westProv := newState(providers.MakeProviderType("aws"), "us-west", true, root.URN, "",
+		resource.PropertyMap{"region": resource.NewStringProperty("us-west-2")})
+	component := newState("my:index:Site", "site", false, root.URN, "", nil)
+	bucket := newState("aws:s3/bucket:Bucket", "site-logs", true, component.URN,
+		string(defaultProv.URN)+"::"+string(defaultProv.ID),
+		resource.PropertyMap{"acl": resource.NewStringProperty("private")})
+	bucket.Protect = true
+	object := newState("aws:s3/bucketObject:BucketObject", "index", true, root.URN,
+		string(westProv.URN)+"::"+string(westProv.ID), nil)
+	object.DependsOn = []resource.URN{bucket.URN}
+
+	return deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{
+		root, defaultProv, westProv, component, bucket, object,
+	}, nil)
+}
+
+func TestGenerateYAMLStackProgram(t *testing.T) {
+	t.Parallel()
+
+	program, err := GenerateStackProgram(newTestStackSnapshot(), "proj", "yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, `name: proj
+runtime: yaml
+
+# Resources in the stack's state, as generated by `+"`pulumi stack export --generate-code`"+`.  Run
+# `+"`pulumi preview`"+` to check that the program matches them; it should report no changes.
+# 1 component resource was left out, so the resources that belonged to them have new URNs.
+
+resources:
+
+  us-west:
+    type: pulumi:providers:aws
+    properties:
+      region: us-west-2
+
+  site-logs:
+    type: aws:s3/bucket:Bucket
+    properties:
+      acl: private
+    options:
+      protect: true
+
+  index:
+    type: aws:s3/bucketObject:BucketObject
+    options:
+      dependsOn:
+        - ${site-logs}
+      provider: ${us-west}
+`, string(program))
+}
+
+func TestGenerateNodeJSStackProgram(t *testing.T) {
+	t.Parallel()
+
+	program, err := GenerateStackProgram(newTestStackSnapshot(), "proj", "nodejs")
+	assert.NoError(t, err)
+	assert.Equal(t, `import * as aws from "@pulumi/aws";
+
+// Resources in the stack's state, as generated by `+"`pulumi stack export --generate-code`"+`.  Run
+// `+"`pulumi preview`"+` to check that the program matches them; it should report no changes.
+// 1 component resource was left out, so the resources that belonged to them have new URNs.
+
+const usWest = new aws.Provider("us-west", {
+    region: "us-west-2",
+});
+
+const siteLogs = new aws.s3.Bucket("site-logs", {
+    acl: "private",
+}, { protect: true });
+
+const index = new aws.s3.BucketObject("index", {}, { dependsOn: [siteLogs], provider: usWest });
+`, string(program))
+
+	_, err = GenerateStackProgram(newTestStackSnapshot(), "proj", "go")
+	assert.Error(t, err)
+}
+
+func TestGeneratePythonStackProgram(t *testing.T) {
+	t.Parallel()
+
+	program, err := GenerateStackProgram(newTestStackSnapshot(), "proj", "python")
+	assert.NoError(t, err)
+	assert.Contains(t, string(program), "import pulumi\nimport pulumi_aws as aws\n")
+	assert.Contains(t, string(program), `index = aws.s3.BucketObject("index",
+    opts=pulumi.ResourceOptions(depends_on=[site_logs], provider=us_west))`)
+}
@@ -13,14 +13,8 @@ import (
 
 func NewProviderResource(pkg, name, id string, deps ...resource.URN) *resource.State {
 	t := providers.MakeProviderType(tokens.Package(pkg))
-	return &resource.State{
-		Type:         t,
-		URN:          resource.NewURN("test", "test", "", t, tokens.QName(name)),
-		ID:           resource.ID(id),
-		Inputs:       resource.PropertyMap{},
-		Outputs:      resource.PropertyMap{},
-		Dependencies: deps,
-	}
+	return resource.NewState(t, resource.NewURN("test", "test", "", t, tokens.QName(name)), true, false,
+		resource.ID(id), resource.PropertyMap{}, resource.PropertyMap{}, "", false, false, deps, nil, "")
 }
 
 func NewResource(name string, provider *resource.State, deps ...resource.URN) *resource.State {
@@ -34,14 +28,8 @@ func NewResource(name string, provider *resource.State, deps ...resource.URN) *r
 	}
 
 	t := tokens.Type("test:test:test")
-	return &resource.State{
-		Type:         t,
-		URN:          resource.NewURN("test", "test", "", t, tokens.QName(name)),
-		Inputs:       resource.PropertyMap{},
-		Outputs:      resource.PropertyMap{},
-		Dependencies: deps,
-		Provider:     prov,
-	}
+	return resource.NewState(t, resource.NewURN("test", "test", "", t, tokens.QName(name)), false, false, "",
+		resource.PropertyMap{}, resource.PropertyMap{}, "", false, false, deps, nil, prov)
 }
 
 func TestBasicGraph(t *testing.T) {
@@ -0,0 +1,251 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// PropertyInterner deduplicates identical property values so that a single copy of each is shared in memory.  Large
+// snapshots tend to repeat the same strings (tags, ARN prefixes, regions) and small objects thousands of times, and
+// interning them as they are decoded considerably reduces the memory needed to plan and apply very large stacks.
+//
+// Interned arrays and objects are shared between every value that contains them, and so must never be mutated in
+// place; as elsewhere, callers that need to change a property value must copy it first.  A nil interner is valid and
+// simply returns values as they are given.  It is safe to use an interner from multiple goroutines.
+type PropertyInterner struct {
+	lock       sync.Mutex
+	keys       map[PropertyKey]PropertyKey // canonical copies of property keys.
+	strings    map[string]PropertyValue    // canonical boxed string values.
+	composites map[uint64][]PropertyValue  // canonical arrays and objects, bucketed by their structural hash.
+}
+
+// NewPropertyInterner creates a new, empty interner.
+func NewPropertyInterner() *PropertyInterner {
+	return &PropertyInterner{
+		keys:       make(map[PropertyKey]PropertyKey),
+		strings:    make(map[string]PropertyValue),
+		composites: make(map[uint64][]PropertyValue),
+	}
+}
+
+// Map returns a copy of the given property map whose keys and values have been interned.  The map itself is always
+// freshly allocated, so callers may modify it freely; only the values nested within it are shared.
+func (in *PropertyInterner) Map(props PropertyMap) PropertyMap {
+	if in == nil || props == nil {
+		return props
+	}
+
+	in.lock.Lock()
+	defer in.lock.Unlock()
+
+	result := make(PropertyMap, len(props))
+	for k, v := range props {
+		result[in.key(k)], _, _ = in.intern(v)
+	}
+	return result
+}
+
+// Value returns the canonical copy of the given property value.
+func (in *PropertyInterner) Value(v PropertyValue) PropertyValue {
+	if in == nil {
+		return v
+	}
+
+	in.lock.Lock()
+	defer in.lock.Unlock()
+
+	result, _, _ := in.intern(v)
+	return result
+}
+
+// key returns the canonical copy of the given property key.
+func (in *PropertyInterner) key(k PropertyKey) PropertyKey {
+	if c, has := in.keys[k]; has {
+		return c
+	}
+	in.keys[k] = k
+	return k
+}
+
+// intern returns the canonical copy of the given property value along with its structural hash.  Values are interned
+// bottom-up, so the elements of an array or object are themselves canonical by the time the composite is looked up;
+// this lets composites be compared shallowly.  If the value cannot be interned -- it is, or contains, an asset,
+// archive, computed, or output value -- the final result is false and the hash is meaningless.
+func (in *PropertyInterner) intern(v PropertyValue) (PropertyValue, uint64, bool) {
+	h := fnv.New64a()
+	switch {
+	case v.IsNull():
+		writeHash(h, 'z', 0)
+		return v, h.Sum64(), true
+	case v.IsBool():
+		var b uint64
+		if v.BoolValue() {
+			b = 1
+		}
+		writeHash(h, 'b', b)
+		return v, h.Sum64(), true
+	case v.IsNumber():
+		writeHash(h, 'n', math.Float64bits(v.NumberValue()))
+		return v, h.Sum64(), true
+	case v.IsString():
+		s := v.StringValue()
+		c, has := in.strings[s]
+		if !has {
+			c = v
+			in.strings[s] = c
+		}
+		writeHash(h, 's', uint64(len(s)))
+		_, err := h.Write([]byte(s))
+		contract.IgnoreError(err)
+		return c, h.Sum64(), true
+	case v.IsArray():
+		arr := v.ArrayValue()
+		var elems []PropertyValue
+		if arr != nil {
+			elems = make([]PropertyValue, len(arr))
+		}
+		internable := true
+		writeHash(h, 'a', nilness(arr == nil))
+		for i, e := range arr {
+			var eh uint64
+			var ok bool
+			elems[i], eh, ok = in.intern(e)
+			internable = internable && ok
+			writeHash(h, 'e', eh)
+		}
+		return in.composite(NewArrayProperty(elems), h.Sum64(), internable)
+	case v.IsObject():
+		obj := v.ObjectValue()
+		var m PropertyMap
+		if obj != nil {
+			m = make(PropertyMap, len(obj))
+		}
+		internable := true
+		writeHash(h, 'o', nilness(obj == nil))
+		for _, k := range obj.StableKeys() {
+			ev, eh, ok := in.intern(obj[k])
+			m[in.key(k)] = ev
+			internable = internable && ok
+			writeHash(h, 'k', uint64(len(k)))
+			_, err := h.Write([]byte(k))
+			contract.IgnoreError(err)
+			writeHash(h, 'e', eh)
+		}
+		return in.composite(NewObjectProperty(m), h.Sum64(), internable)
+	default:
+		return v, 0, false
+	}
+}
+
+// composite returns the canonical copy of the given array or object, whose elements have already been interned.
+func (in *PropertyInterner) composite(v PropertyValue, h uint64, internable bool) (PropertyValue, uint64, bool) {
+	if !internable {
+		return v, h, false
+	}
+	for _, c := range in.composites[h] {
+		if sameInterned(v, c) {
+			return c, h, true
+		}
+	}
+	in.composites[h] = append(in.composites[h], v)
+	return v, h, true
+}
+
+// sameInterned returns true if the two values, whose elements have been interned, are identical.  Unlike DeepEquals,
+// this distinguishes between nil and empty composites and between missing and null object properties, as interning
+// must never change how a value is represented.
+func sameInterned(a, b PropertyValue) bool {
+	switch {
+	case a.IsArray():
+		if !b.IsArray() {
+			return false
+		}
+		aa, ba := a.ArrayValue(), b.ArrayValue()
+		if len(aa) != len(ba) || (aa == nil) != (ba == nil) {
+			return false
+		}
+		for i := range aa {
+			if !sameElement(aa[i], ba[i]) {
+				return false
+			}
+		}
+		return true
+	case a.IsObject():
+		if !b.IsObject() {
+			return false
+		}
+		ao, bo := a.ObjectValue(), b.ObjectValue()
+		if len(ao) != len(bo) || (ao == nil) != (bo == nil) {
+			return false
+		}
+		for k, av := range ao {
+			bv, has := bo[k]
+			if !has || !sameElement(av, bv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.V == b.V
+	}
+}
+
+// sameElement returns true if two canonical elements of a composite are identical.  Because they are canonical, nested
+// arrays and objects are identical only if they are the very same array or object.
+func sameElement(a, b PropertyValue) bool {
+	switch {
+	case a.IsArray():
+		if !b.IsArray() {
+			return false
+		}
+		aa, ba := a.ArrayValue(), b.ArrayValue()
+		if len(aa) == 0 || len(ba) == 0 {
+			return len(aa) == len(ba) && (aa == nil) == (ba == nil)
+		}
+		return len(aa) == len(ba) && &aa[0] == &ba[0]
+	case a.IsObject():
+		if !b.IsObject() {
+			return false
+		}
+		return reflect.ValueOf(a.ObjectValue()).Pointer() == reflect.ValueOf(b.ObjectValue()).Pointer()
+	default:
+		return a.V == b.V
+	}
+}
+
+// writeHash adds a tagged integer to the given structural hash.
+func writeHash(h hash.Hash64, tag byte, n uint64) {
+	var buf [9]byte
+	buf[0] = tag
+	binary.LittleEndian.PutUint64(buf[1:], n)
+	_, err := h.Write(buf[:])
+	contract.IgnoreError(err)
+}
+
+// nilness encodes whether a composite is nil, so that nil and empty composites hash differently.
+func nilness(isNil bool) uint64 {
+	if isNil {
+		return 1
+	}
+	return 0
+}
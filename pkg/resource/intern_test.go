@@ -0,0 +1,91 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInternSharesIdenticalValues(t *testing.T) {
+	in := NewPropertyInterner()
+
+	newTags := func() PropertyValue {
+		return NewObjectProperty(PropertyMap{
+			"env":   NewStringProperty("production"),
+			"owner": NewStringProperty("infra"),
+			"ports": NewArrayProperty([]PropertyValue{NewNumberProperty(80), NewNumberProperty(443)}),
+		})
+	}
+	a := in.Map(PropertyMap{"name": NewStringProperty("a"), "tags": newTags()})
+	b := in.Map(PropertyMap{"name": NewStringProperty("b"), "tags": newTags()})
+
+	// The values are unchanged...
+	assert.True(t, a.DeepEquals(PropertyMap{"name": NewStringProperty("a"), "tags": newTags()}))
+	assert.True(t, b.DeepEquals(PropertyMap{"name": NewStringProperty("b"), "tags": newTags()}))
+
+	// ...but the tags, and everything within them, are now shared.
+	assert.Equal(t,
+		reflect.ValueOf(a["tags"].ObjectValue()).Pointer(), reflect.ValueOf(b["tags"].ObjectValue()).Pointer())
+	assert.Equal(t,
+		&a["tags"].ObjectValue()["ports"].ArrayValue()[0], &b["tags"].ObjectValue()["ports"].ArrayValue()[0])
+
+	// The top-level maps themselves are never shared.
+	assert.NotEqual(t, reflect.ValueOf(a).Pointer(), reflect.ValueOf(b).Pointer())
+}
+
+func TestInternPreservesRepresentation(t *testing.T) {
+	in := NewPropertyInterner()
+
+	// Values that DeepEquals considers equal, but that are represented differently, must remain distinct.
+	withNull := in.Value(NewObjectProperty(PropertyMap{"a": NewNullProperty()}))
+	empty := in.Value(NewObjectProperty(PropertyMap{}))
+	assert.Len(t, withNull.ObjectValue(), 1)
+	assert.Len(t, empty.ObjectValue(), 0)
+
+	nilArr := in.Value(NewArrayProperty(nil))
+	emptyArr := in.Value(NewArrayProperty([]PropertyValue{}))
+	assert.Nil(t, nilArr.ArrayValue())
+	assert.NotNil(t, emptyArr.ArrayValue())
+
+	// Values of different kinds are never confused.
+	str := in.Value(NewObjectProperty(PropertyMap{"a": NewStringProperty("1")}))
+	num := in.Value(NewObjectProperty(PropertyMap{"a": NewNumberProperty(1)}))
+	assert.True(t, str.ObjectValue()["a"].IsString())
+	assert.True(t, num.ObjectValue()["a"].IsNumber())
+}
+
+func TestInternSkipsUninternableValues(t *testing.T) {
+	in := NewPropertyInterner()
+
+	newComputed := func() PropertyValue {
+		return NewObjectProperty(PropertyMap{
+			"id":   MakeComputed(NewStringProperty("")),
+			"name": NewStringProperty("x"),
+		})
+	}
+	a, b := in.Value(newComputed()), in.Value(newComputed())
+	assert.True(t, a.DeepEquals(newComputed()))
+	assert.NotEqual(t, reflect.ValueOf(a.ObjectValue()).Pointer(), reflect.ValueOf(b.ObjectValue()).Pointer())
+}
+
+func TestNilInterner(t *testing.T) {
+	var in *PropertyInterner
+	props := PropertyMap{"a": NewStringProperty("b")}
+	assert.Equal(t, reflect.ValueOf(props).Pointer(), reflect.ValueOf(in.Map(props)).Pointer())
+	assert.Equal(t, NewStringProperty("b"), in.Value(NewStringProperty("b")))
+}
@@ -0,0 +1,71 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// NamingStrategy controls how physical names are generated from a resource's logical name.  The engine passes the
+// stack's strategy to providers when it asks them to check a resource's inputs.
+type NamingStrategy struct {
+	SuffixLength int    // the number of random hex characters appended to the logical name.
+	Delimiter    string // the text placed between the logical name and the random suffix.
+	Verbatim     bool   // true to use the logical name as-is, with no random suffix.
+}
+
+// DefaultNamingStrategy is the strategy used when neither the project nor the stack specifies one: a dash followed
+// by seven random hex characters.
+var DefaultNamingStrategy = NamingStrategy{
+	SuffixLength: 7,
+	Delimiter:    "-",
+}
+
+// NewNamingStrategy returns the default naming strategy with the given settings applied in order, such that later
+// settings override earlier ones.  Nil settings are ignored.
+func NewNamingStrategy(settings ...*workspace.AutoNaming) NamingStrategy {
+	s := DefaultNamingStrategy
+	for _, setting := range settings {
+		if setting == nil {
+			continue
+		}
+		if setting.RandomSuffixLength != nil {
+			s.SuffixLength = *setting.RandomSuffixLength
+		}
+		if setting.Delimiter != nil {
+			s.Delimiter = *setting.Delimiter
+		}
+		if setting.Verbatim != nil {
+			s.Verbatim = *setting.Verbatim
+		}
+	}
+	return s
+}
+
+// NewNameFromSeed generates a physical name from the given logical name.  The result must not exceed maxlen total
+// characters (if > 0).  The random suffix, if any, is derived from the given seed, such that the same seed always
+// produces the same name; a nil seed produces a random suffix.  Providers should use the random seed that the engine
+// passes to Check, so that a resource keeps its name until it is replaced.
+func (s NamingStrategy) NewNameFromSeed(logical string, maxlen int, seed []byte) (string, error) {
+	if s.Verbatim || s.SuffixLength <= 0 {
+		if maxlen > 0 && len(logical) > maxlen {
+			return "", errors.Errorf("name '%s' is longer than maximum length %d", logical, maxlen)
+		}
+		return logical, nil
+	}
+	return NewUniqueHexFromSeed(logical+s.Delimiter, s.SuffixLength, maxlen, seed)
+}
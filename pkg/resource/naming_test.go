@@ -0,0 +1,69 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestDefaultNamingStrategy(t *testing.T) {
+	assert.Equal(t, DefaultNamingStrategy, NewNamingStrategy())
+	name, err := DefaultNamingStrategy.NewNameFromSeed("bucket", -1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, len("bucket-")+7, len(name))
+	assert.True(t, strings.HasPrefix(name, "bucket-"))
+}
+
+func TestNamingStrategyFromSeed(t *testing.T) {
+	seed := []byte("seed")
+	name, err := DefaultNamingStrategy.NewNameFromSeed("bucket", -1, seed)
+	assert.Nil(t, err)
+	assert.Equal(t, len("bucket-")+7, len(name))
+	assert.True(t, strings.HasPrefix(name, "bucket-"))
+
+	again, err := DefaultNamingStrategy.NewNameFromSeed("bucket", -1, seed)
+	assert.Nil(t, err)
+	assert.Equal(t, name, again)
+
+	verbatim := NamingStrategy{Verbatim: true}
+	name, err = verbatim.NewNameFromSeed("bucket", -1, seed)
+	assert.Nil(t, err)
+	assert.Equal(t, "bucket", name)
+}
+
+func TestNamingStrategyOverrides(t *testing.T) {
+	length, delim, verbatim := 4, "_", true
+	project := &workspace.AutoNaming{RandomSuffixLength: &length, Delimiter: &delim}
+	stack := &workspace.AutoNaming{Verbatim: &verbatim}
+
+	s := NewNamingStrategy(project, nil)
+	assert.Equal(t, NamingStrategy{SuffixLength: 4, Delimiter: "_"}, s)
+	name, err := s.NewNameFromSeed("bucket", -1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, len("bucket_")+4, len(name))
+	assert.True(t, strings.HasPrefix(name, "bucket_"))
+
+	s = NewNamingStrategy(project, stack)
+	name, err = s.NewNameFromSeed("bucket", -1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "bucket", name)
+	_, err = s.NewNameFromSeed("bucket", 3, nil)
+	assert.NotNil(t, err)
+}
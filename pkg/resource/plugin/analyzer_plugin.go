@@ -53,7 +53,7 @@ func NewAnalyzer(host Host, ctx *Context, name tokens.QName) (Analyzer, error) {
 		})
 	}
 
-	plug, err := newPlugin(ctx, path, fmt.Sprintf("%v (analyzer)", name), []string{host.ServerAddr()})
+	plug, err := newPlugin(ctx, path, fmt.Sprintf("%v (analyzer)", name), []string{host.ServerAddr()}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +77,7 @@ func (a *analyzer) label() string {
 // Analyze analyzes a single resource object, and returns any errors that it finds.
 func (a *analyzer) Analyze(t tokens.Type, props resource.PropertyMap) ([]AnalyzeFailure, error) {
 	label := fmt.Sprintf("%s.Analyze(%s)", a.label(), t)
-	logging.V(7).Infof("%s executing (#props=%d)", label, len(props))
+	logging.Provider.V(7).Infof("%s executing (#props=%d)", label, len(props))
 	mprops, err := MarshalProperties(props, MarshalOptions{})
 	if err != nil {
 		return nil, err
@@ -89,7 +89,7 @@ func (a *analyzer) Analyze(t tokens.Type, props resource.PropertyMap) ([]Analyze
 	})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
-		logging.V(7).Infof("%s failed: err=%v", label, rpcError)
+		logging.Provider.V(7).Infof("%s failed: err=%v", label, rpcError)
 		return nil, rpcError
 	}
 
@@ -100,18 +100,18 @@ func (a *analyzer) Analyze(t tokens.Type, props resource.PropertyMap) ([]Analyze
 			Reason:   failure.Reason,
 		})
 	}
-	logging.V(7).Infof("%s success: failures=#%d", label, len(failures))
+	logging.Provider.V(7).Infof("%s success: failures=#%d", label, len(failures))
 	return failures, nil
 }
 
 // GetPluginInfo returns this plugin's information.
 func (a *analyzer) GetPluginInfo() (workspace.PluginInfo, error) {
 	label := fmt.Sprintf("%s.GetPluginInfo()", a.label())
-	logging.V(7).Infof("%s executing", label)
+	logging.Provider.V(7).Infof("%s executing", label)
 	resp, err := a.client.GetPluginInfo(a.ctx.Request(), &pbempty.Empty{})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
-		logging.V(7).Infof("%s failed: err=%v", a.label(), rpcError)
+		logging.Provider.V(7).Infof("%s failed: err=%v", a.label(), rpcError)
 		return workspace.PluginInfo{}, rpcError
 	}
 
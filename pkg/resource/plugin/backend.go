@@ -0,0 +1,45 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"io"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// Backend provides a pluggable interface for storing the state of stacks, so that they may be kept in stores that the
+// CLI does not itself understand.  A backend plugin serves the URLs with a given scheme, and keeps the state as opaque
+// blobs, each named by a slash-separated key.  This interface hides the messiness of the underlying machinery, since
+// backends are behind an RPC boundary.
+type Backend interface {
+	// Closer closes any underlying OS resources associated with this backend (like processes, RPC channels, etc).
+	io.Closer
+	// Scheme returns the URL scheme that this backend serves.
+	Scheme() string
+	// GetBlob returns the contents of the blob with the given key, and false if there is no such blob.
+	GetBlob(key string) ([]byte, bool, error)
+	// PutBlob creates or replaces the blob with the given key.
+	PutBlob(key string, contents []byte) error
+	// DeleteBlob deletes the blob with the given key, if there is one.
+	DeleteBlob(key string) error
+	// RenameBlob atomically moves the blob with the given key to a new key, replacing any blob that already has it.
+	// It returns false if there is no blob to move.
+	RenameBlob(key, newKey string) (bool, error)
+	// ListBlobs returns the keys of all blobs that begin with the given prefix, in lexical order.
+	ListBlobs(prefix string) ([]string, error)
+	// GetPluginInfo returns this plugin's information.
+	GetPluginInfo() (workspace.PluginInfo, error)
+}
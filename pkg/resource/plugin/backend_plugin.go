@@ -0,0 +1,185 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/blang/semver"
+	pbempty "github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil/rpcerror"
+	"github.com/pulumi/pulumi/pkg/workspace"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+// backend reflects a backend plugin, loaded dynamically to store the state of the stacks at a single URL.
+type backend struct {
+	ctx    *Context
+	scheme string
+	plug   *plugin
+	client pulumirpc.BackendClient
+}
+
+// NewBackend binds to the backend plugin for the given URL scheme and creates a gRPC connection to it, passing it the
+// URL whose stacks it is to store.  If the associated plugin could not be found by name on the PATH, or an error
+// occurs while creating the child process, an error is returned.
+func NewBackend(ctx *Context, scheme string, url string) (Backend, error) {
+	// Load the plugin's path by using the standard workspace logic.
+	_, path, err := workspace.GetPluginPath(workspace.BackendPlugin, scheme, nil)
+	if err != nil {
+		return nil, rpcerror.Convert(err)
+	} else if path == "" {
+		return nil, NewMissingError(workspace.PluginInfo{
+			Kind: workspace.BackendPlugin,
+			Name: scheme,
+		})
+	}
+
+	plug, err := newPlugin(ctx, path, fmt.Sprintf("%v (backend)", scheme), []string{url}, nil)
+	if err != nil {
+		return nil, err
+	}
+	contract.Assertf(plug != nil, "unexpected nil backend plugin for %s", scheme)
+
+	return &backend{
+		ctx:    ctx,
+		scheme: scheme,
+		plug:   plug,
+		client: pulumirpc.NewBackendClient(plug.Conn),
+	}, nil
+}
+
+func (b *backend) Scheme() string { return b.scheme }
+
+// label returns a base label for tracing functions.
+func (b *backend) label() string {
+	return fmt.Sprintf("Backend[%s]", b.scheme)
+}
+
+// GetBlob returns the contents of the blob with the given key, and false if there is no such blob.
+func (b *backend) GetBlob(key string) ([]byte, bool, error) {
+	label := fmt.Sprintf("%s.GetBlob(%s)", b.label(), key)
+	logging.Backend.V(7).Infof("%s executing", label)
+	resp, err := b.client.GetBlob(b.ctx.Request(), &pulumirpc.GetBlobRequest{Key: key})
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		if rpcError.Code() == codes.NotFound {
+			logging.Backend.V(7).Infof("%s success: not found", label)
+			return nil, false, nil
+		}
+		logging.Backend.V(7).Infof("%s failed: err=%v", label, rpcError)
+		return nil, false, rpcError
+	}
+	logging.Backend.V(7).Infof("%s success: #bytes=%d", label, len(resp.GetContents()))
+	return resp.GetContents(), true, nil
+}
+
+// PutBlob creates or replaces the blob with the given key.
+func (b *backend) PutBlob(key string, contents []byte) error {
+	label := fmt.Sprintf("%s.PutBlob(%s)", b.label(), key)
+	logging.Backend.V(7).Infof("%s executing (#bytes=%d)", label, len(contents))
+	_, err := b.client.PutBlob(b.ctx.Request(), &pulumirpc.PutBlobRequest{Key: key, Contents: contents})
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		logging.Backend.V(7).Infof("%s failed: err=%v", label, rpcError)
+		return rpcError
+	}
+	logging.Backend.V(7).Infof("%s success", label)
+	return nil
+}
+
+// DeleteBlob deletes the blob with the given key, if there is one.
+func (b *backend) DeleteBlob(key string) error {
+	label := fmt.Sprintf("%s.DeleteBlob(%s)", b.label(), key)
+	logging.Backend.V(7).Infof("%s executing", label)
+	_, err := b.client.DeleteBlob(b.ctx.Request(), &pulumirpc.DeleteBlobRequest{Key: key})
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		logging.Backend.V(7).Infof("%s failed: err=%v", label, rpcError)
+		return rpcError
+	}
+	logging.Backend.V(7).Infof("%s success", label)
+	return nil
+}
+
+// RenameBlob atomically moves the blob with the given key to a new key, replacing any blob that already has it.  It
+// returns false if there is no blob to move.
+func (b *backend) RenameBlob(key, newKey string) (bool, error) {
+	label := fmt.Sprintf("%s.RenameBlob(%s, %s)", b.label(), key, newKey)
+	logging.Backend.V(7).Infof("%s executing", label)
+	_, err := b.client.RenameBlob(b.ctx.Request(), &pulumirpc.RenameBlobRequest{Key: key, NewKey: newKey})
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		if rpcError.Code() == codes.NotFound {
+			logging.Backend.V(7).Infof("%s success: not found", label)
+			return false, nil
+		}
+		logging.Backend.V(7).Infof("%s failed: err=%v", label, rpcError)
+		return false, rpcError
+	}
+	logging.Backend.V(7).Infof("%s success", label)
+	return true, nil
+}
+
+// ListBlobs returns the keys of all blobs that begin with the given prefix, in lexical order.
+func (b *backend) ListBlobs(prefix string) ([]string, error) {
+	label := fmt.Sprintf("%s.ListBlobs(%s)", b.label(), prefix)
+	logging.Backend.V(7).Infof("%s executing", label)
+	resp, err := b.client.ListBlobs(b.ctx.Request(), &pulumirpc.ListBlobsRequest{Prefix: prefix})
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		logging.Backend.V(7).Infof("%s failed: err=%v", label, rpcError)
+		return nil, rpcError
+	}
+	logging.Backend.V(7).Infof("%s success: #keys=%d", label, len(resp.GetKeys()))
+	return resp.GetKeys(), nil
+}
+
+// GetPluginInfo returns this plugin's information.
+func (b *backend) GetPluginInfo() (workspace.PluginInfo, error) {
+	label := fmt.Sprintf("%s.GetPluginInfo()", b.label())
+	logging.Backend.V(7).Infof("%s executing", label)
+	resp, err := b.client.GetPluginInfo(b.ctx.Request(), &pbempty.Empty{})
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		logging.Backend.V(7).Infof("%s failed: err=%v", label, rpcError)
+		return workspace.PluginInfo{}, rpcError
+	}
+
+	var version *semver.Version
+	if v := resp.Version; v != "" {
+		sv, err := semver.ParseTolerant(v)
+		if err != nil {
+			return workspace.PluginInfo{}, err
+		}
+		version = &sv
+	}
+
+	return workspace.PluginInfo{
+		Name:    b.scheme,
+		Path:    b.plug.Bin,
+		Kind:    workspace.BackendPlugin,
+		Version: version,
+	}, nil
+}
+
+// Close tears down the underlying plugin RPC connection and process.
+func (b *backend) Close() error {
+	return b.plug.Close()
+}
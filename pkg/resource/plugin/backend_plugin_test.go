@@ -0,0 +1,161 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	pbempty "github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/pulumi/pulumi/pkg/util/rpcutil"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+// memoryBackend is a backend plugin server that keeps its blobs in memory.
+type memoryBackend struct {
+	m     sync.Mutex
+	blobs map[string][]byte
+}
+
+func (m *memoryBackend) GetBlob(ctx context.Context,
+	req *pulumirpc.GetBlobRequest) (*pulumirpc.GetBlobResponse, error) {
+
+	m.m.Lock()
+	defer m.m.Unlock()
+	contents, has := m.blobs[req.GetKey()]
+	if !has {
+		return nil, status.Errorf(codes.NotFound, "no blob %s", req.GetKey())
+	}
+	return &pulumirpc.GetBlobResponse{Contents: contents}, nil
+}
+
+func (m *memoryBackend) PutBlob(ctx context.Context, req *pulumirpc.PutBlobRequest) (*pbempty.Empty, error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	m.blobs[req.GetKey()] = req.GetContents()
+	return &pbempty.Empty{}, nil
+}
+
+func (m *memoryBackend) DeleteBlob(ctx context.Context, req *pulumirpc.DeleteBlobRequest) (*pbempty.Empty, error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	delete(m.blobs, req.GetKey())
+	return &pbempty.Empty{}, nil
+}
+
+func (m *memoryBackend) RenameBlob(ctx context.Context, req *pulumirpc.RenameBlobRequest) (*pbempty.Empty, error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	contents, has := m.blobs[req.GetKey()]
+	if !has {
+		return nil, status.Errorf(codes.NotFound, "no blob %s", req.GetKey())
+	}
+	delete(m.blobs, req.GetKey())
+	m.blobs[req.GetNewKey()] = contents
+	return &pbempty.Empty{}, nil
+}
+
+func (m *memoryBackend) ListBlobs(ctx context.Context,
+	req *pulumirpc.ListBlobsRequest) (*pulumirpc.ListBlobsResponse, error) {
+
+	m.m.Lock()
+	defer m.m.Unlock()
+	var keys []string
+	for key := range m.blobs {
+		if strings.HasPrefix(key, req.GetPrefix()) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return &pulumirpc.ListBlobsResponse{Keys: keys}, nil
+}
+
+func (m *memoryBackend) GetPluginInfo(ctx context.Context, req *pbempty.Empty) (*pulumirpc.PluginInfo, error) {
+	return &pulumirpc.PluginInfo{Version: "1.2.3"}, nil
+}
+
+func TestBackendPlugin(t *testing.T) {
+	cancel := make(chan bool)
+	port, done, err := rpcutil.Serve(0, cancel, []func(*grpc.Server) error{
+		func(srv *grpc.Server) error {
+			pulumirpc.RegisterBackendServer(srv, &memoryBackend{blobs: make(map[string][]byte)})
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	defer func() {
+		close(cancel)
+		assert.NoError(t, <-done)
+	}()
+
+	conn, err := dialPlugin(port, "pulumi-backend-memory", "memory (backend)")
+	assert.NoError(t, err)
+	b := &backend{
+		ctx:    &Context{},
+		scheme: "memory",
+		plug:   &plugin{Bin: "pulumi-backend-memory", Conn: conn},
+		client: pulumirpc.NewBackendClient(conn),
+	}
+	defer func() { assert.NoError(t, conn.Close()) }()
+
+	// A missing blob is reported as such, rather than as an error.
+	_, found, err := b.GetBlob("stacks/dev.json")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, b.PutBlob("stacks/dev.json", []byte("{}")))
+	assert.NoError(t, b.PutBlob("stacks/prod.json", []byte("[]")))
+	assert.NoError(t, b.PutBlob("history/dev/dev-1.history.json", []byte("{}")))
+
+	contents, found, err := b.GetBlob("stacks/dev.json")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "{}", string(contents))
+
+	keys, err := b.ListBlobs("stacks/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"stacks/dev.json", "stacks/prod.json"}, keys)
+
+	// Renaming a blob replaces any blob that already has the new key.
+	found, err = b.RenameBlob("stacks/dev.json", "stacks/prod.json")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	contents, _, err = b.GetBlob("stacks/prod.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(contents))
+	found, err = b.RenameBlob("stacks/dev.json", "stacks/prod.json")
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.NoError(t, b.PutBlob("stacks/dev.json", []byte("{}")))
+
+	assert.NoError(t, b.DeleteBlob("stacks/dev.json"))
+	assert.NoError(t, b.DeleteBlob("stacks/dev.json"))
+	keys, err = b.ListBlobs("stacks/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"stacks/prod.json"}, keys)
+
+	info, err := b.GetPluginInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "memory", info.Name)
+	assert.Equal(t, "1.2.3", info.Version.String())
+}
@@ -0,0 +1,281 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/util/glob"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// ChaosConfig is a set of rules describing the failures, latencies, and partial results to inject into calls made of
+// resource providers, which allows recovery from such failures to be exercised on purpose.  It is usually read from a
+// JSON file with LoadChaosConfig.
+type ChaosConfig struct {
+	Rules []ChaosRule `json:"rules"`
+}
+
+// ChaosRule describes the fault to inject into the provider operations it matches.  The first rule that matches an
+// operation applies to it.
+type ChaosRule struct {
+	// URN is a pattern that the URN of the resource must match, in which `*` matches any sequence of characters.
+	URN string `json:"urn"`
+	// Operations is the list of operations the rule applies to: check, diff, create, read, update, and delete.  If
+	// empty, the rule applies to all of them.
+	Operations []string `json:"operations,omitempty"`
+	// Probability is the chance, between 0 and 1, that a matching operation is affected.  Zero means always.
+	Probability float64 `json:"probability,omitempty"`
+	// Latency is a duration, e.g. "5s", to wait before performing the operation.
+	Latency string `json:"latency,omitempty"`
+	// Error, if non-empty, causes the operation to fail with this message.
+	Error string `json:"error,omitempty"`
+	// After causes the operation to be performed before it fails with Error, so that its outcome is unknown to the
+	// engine, just as if the response from the provider had been lost.
+	After bool `json:"after,omitempty"`
+	// Partial causes a create or update to be performed and then reported as having failed to initialize, with Error
+	// as the reason.
+	Partial bool `json:"partial,omitempty"`
+
+	urn     *regexp.Regexp
+	latency time.Duration
+}
+
+// matches returns true if the rule applies to the given operation on the resource with the given URN.
+func (r *ChaosRule) matches(op string, urn resource.URN) bool {
+	if !r.urn.MatchString(string(urn)) {
+		return false
+	}
+	if len(r.Operations) > 0 {
+		found := false
+		for _, o := range r.Operations {
+			if strings.EqualFold(o, op) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return r.Probability <= 0 || rand.Float64() < r.Probability // nolint: gas
+}
+
+func (r *ChaosRule) err() error {
+	msg := r.Error
+	if msg == "" {
+		msg = "injected failure"
+	}
+	return errors.New(msg)
+}
+
+var chaosOperations = map[string]bool{
+	"check": true, "diff": true, "create": true, "read": true, "update": true, "delete": true,
+}
+
+// ParseChaosConfig parses and validates the JSON form of a ChaosConfig.
+func ParseChaosConfig(data []byte) (*ChaosConfig, error) {
+	var config ChaosConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if rule.URN == "" {
+			return nil, errors.Errorf("rule %d: missing urn", i)
+		}
+		rule.urn = glob.Compile(rule.URN)
+
+		for _, op := range rule.Operations {
+			if !chaosOperations[strings.ToLower(op)] {
+				return nil, errors.Errorf("rule %d: unknown operation %q", i, op)
+			}
+		}
+		if rule.Probability < 0 || rule.Probability > 1 {
+			return nil, errors.Errorf("rule %d: probability must be between 0 and 1", i)
+		}
+		if rule.Latency != "" {
+			d, err := time.ParseDuration(rule.Latency)
+			if err != nil {
+				return nil, errors.Wrapf(err, "rule %d: invalid latency", i)
+			}
+			rule.latency = d
+		}
+		if rule.After && rule.Partial {
+			return nil, errors.Errorf("rule %d: only one of after and partial may be set", i)
+		}
+	}
+	return &config, nil
+}
+
+// LoadChaosConfig reads and validates the ChaosConfig in the JSON file at the given path.
+func LoadChaosConfig(path string) (*ChaosConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading chaos rules")
+	}
+	config, err := ParseChaosConfig(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid chaos rules in %s", path)
+	}
+	return config, nil
+}
+
+// chaosProvider wraps a real provider, injecting faults into the operations matched by its rules.
+type chaosProvider struct {
+	Provider
+	config *ChaosConfig
+}
+
+// NewChaosProvider returns a provider that performs operations using the given provider, but injects faults into
+// those that match the given configuration's rules.
+func NewChaosProvider(p Provider, config *ChaosConfig) Provider {
+	return &chaosProvider{Provider: p, config: config}
+}
+
+// rule returns the rule to apply to an operation, if any, after waiting for its latency.
+func (p *chaosProvider) rule(op string, urn resource.URN) *ChaosRule {
+	for i := range p.config.Rules {
+		rule := &p.config.Rules[i]
+		if rule.matches(op, urn) {
+			logging.Provider.V(5).Infof("chaos: applying rule %d to %s of %s", i, op, urn)
+			time.Sleep(rule.latency)
+			if rule.Error == "" && !rule.Partial {
+				return nil
+			}
+			if rule.Partial && op != "create" && op != "update" {
+				return nil
+			}
+			return rule
+		}
+	}
+	return nil
+}
+
+func (p *chaosProvider) Check(urn resource.URN, olds, news resource.PropertyMap,
+	allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error) {
+	return p.CheckWithSeed(urn, olds, news, nil, allowUnknowns)
+}
+
+func (p *chaosProvider) CheckWithSeed(urn resource.URN, olds, news resource.PropertyMap, randomSeed []byte,
+	allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error) {
+
+	rule := p.rule("check", urn)
+	if rule != nil && !rule.After {
+		return nil, nil, rule.err()
+	}
+	inputs, failures, err := CheckWithSeed(p.Provider, urn, olds, news, randomSeed, allowUnknowns)
+	if err == nil && rule != nil {
+		return nil, nil, rule.err()
+	}
+	return inputs, failures, err
+}
+
+func (p *chaosProvider) Diff(urn resource.URN, id resource.ID, olds resource.PropertyMap, news resource.PropertyMap,
+	allowUnknowns bool) (DiffResult, error) {
+
+	rule := p.rule("diff", urn)
+	if rule != nil && !rule.After {
+		return DiffResult{}, rule.err()
+	}
+	diff, err := p.Provider.Diff(urn, id, olds, news, allowUnknowns)
+	if err == nil && rule != nil {
+		return DiffResult{}, rule.err()
+	}
+	return diff, err
+}
+
+func (p *chaosProvider) Create(urn resource.URN,
+	news resource.PropertyMap) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+	rule := p.rule("create", urn)
+	if rule != nil && !rule.After && !rule.Partial {
+		return "", nil, resource.StatusOK, rule.err()
+	}
+	id, outs, status, err := p.Provider.Create(urn, news)
+	if err == nil && rule != nil {
+		if rule.Partial {
+			return id, outs, resource.StatusPartialFailure, &InitError{Reasons: []string{rule.err().Error()}}
+		}
+		return "", nil, resource.StatusUnknown, rule.err()
+	}
+	return id, outs, status, err
+}
+
+func (p *chaosProvider) Read(urn resource.URN, id resource.ID,
+	props resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
+
+	rule := p.rule("read", urn)
+	if rule != nil && !rule.After {
+		return nil, resource.StatusUnknown, rule.err()
+	}
+	outs, status, err := p.Provider.Read(urn, id, props)
+	if err == nil && rule != nil {
+		return nil, resource.StatusUnknown, rule.err()
+	}
+	return outs, status, err
+}
+
+func (p *chaosProvider) ReadIfModified(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	since time.Time) (resource.PropertyMap, bool, resource.Status, error) {
+
+	rule := p.rule("read", urn)
+	if rule != nil && !rule.After {
+		return nil, false, resource.StatusUnknown, rule.err()
+	}
+	outs, unmodified, status, err := p.Provider.ReadIfModified(urn, id, props, since)
+	if err == nil && rule != nil {
+		return nil, false, resource.StatusUnknown, rule.err()
+	}
+	return outs, unmodified, status, err
+}
+
+func (p *chaosProvider) Update(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+	news resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
+
+	rule := p.rule("update", urn)
+	if rule != nil && !rule.After && !rule.Partial {
+		return nil, resource.StatusOK, rule.err()
+	}
+	outs, status, err := p.Provider.Update(urn, id, olds, news)
+	if err == nil && rule != nil {
+		if rule.Partial {
+			return outs, resource.StatusPartialFailure, &InitError{Reasons: []string{rule.err().Error()}}
+		}
+		return nil, resource.StatusUnknown, rule.err()
+	}
+	return outs, status, err
+}
+
+func (p *chaosProvider) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap) (resource.Status, error) {
+	rule := p.rule("delete", urn)
+	if rule != nil && !rule.After {
+		return resource.StatusOK, rule.err()
+	}
+	status, err := p.Provider.Delete(urn, id, props)
+	if err == nil && rule != nil {
+		return resource.StatusUnknown, rule.err()
+	}
+	return status, err
+}
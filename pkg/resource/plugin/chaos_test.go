@@ -0,0 +1,126 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+// countingProvider is a Provider whose creates and deletes succeed, counting how many were performed.
+type countingProvider struct {
+	Provider
+	creates int
+	deletes int
+}
+
+func (p *countingProvider) Create(urn resource.URN,
+	news resource.PropertyMap) (resource.ID, resource.PropertyMap, resource.Status, error) {
+	p.creates++
+	return "id", news, resource.StatusOK, nil
+}
+
+func (p *countingProvider) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap) (resource.Status, error) {
+	p.deletes++
+	return resource.StatusOK, nil
+}
+
+func TestParseChaosConfig(t *testing.T) {
+	config, err := ParseChaosConfig(
+		[]byte(`{"rules": [{"urn": "*::a", "operations": ["Create"], "latency": "1ms"}]}`))
+	assert.NoError(t, err)
+	assert.Len(t, config.Rules, 1)
+
+	for _, bad := range []string{
+		`{"rules": [{"operations": ["create"]}]}`,
+		`{"rules": [{"urn": "*", "operations": ["explode"]}]}`,
+		`{"rules": [{"urn": "*", "probability": 2}]}`,
+		`{"rules": [{"urn": "*", "latency": "soon"}]}`,
+		`{"rules": [{"urn": "*", "after": true, "partial": true}]}`,
+	} {
+		_, err = ParseChaosConfig([]byte(bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestChaosProvider(t *testing.T) {
+	config, err := ParseChaosConfig([]byte(`{"rules": [
+		{"urn": "*::before", "error": "boom"},
+		{"urn": "*::after", "error": "lost", "after": true},
+		{"urn": "*::partial", "operations": ["create"], "error": "half", "partial": true}
+	]}`))
+	assert.NoError(t, err)
+
+	real := &countingProvider{}
+	p := NewChaosProvider(real, config)
+
+	// Failures injected before an operation prevent it from being performed.
+	_, _, status, err := p.Create("urn:pulumi:s::p::t::before", nil)
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, resource.StatusOK, status)
+	assert.Equal(t, 0, real.creates)
+
+	// Failures injected after an operation leave its outcome unknown.
+	_, _, status, err = p.Create("urn:pulumi:s::p::t::after", nil)
+	assert.EqualError(t, err, "lost")
+	assert.Equal(t, resource.StatusUnknown, status)
+	assert.Equal(t, 1, real.creates)
+
+	// Partial failures return the resource along with an initialization error.
+	id, _, status, err := p.Create("urn:pulumi:s::p::t::partial", nil)
+	assert.IsType(t, &InitError{}, err)
+	assert.Equal(t, resource.StatusPartialFailure, status)
+	assert.Equal(t, resource.ID("id"), id)
+	assert.Equal(t, 2, real.creates)
+
+	// Operations that no rule matches are unaffected.
+	_, err = p.Delete("urn:pulumi:s::p::t::partial", "id", nil)
+	assert.NoError(t, err)
+	_, _, _, err = p.Create("urn:pulumi:s::p::t::other", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, real.creates)
+	assert.Equal(t, 1, real.deletes)
+}
+
+// seededProvider is a SeededProvider that records the seed with which its inputs were last checked.
+type seededProvider struct {
+	Provider
+	seed []byte
+}
+
+func (p *seededProvider) Check(urn resource.URN, olds, news resource.PropertyMap,
+	allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error) {
+	return p.CheckWithSeed(urn, olds, news, nil, allowUnknowns)
+}
+
+func (p *seededProvider) CheckWithSeed(urn resource.URN, olds, news resource.PropertyMap, randomSeed []byte,
+	allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error) {
+	p.seed = randomSeed
+	return news, nil, nil
+}
+
+func TestChaosProviderCheckWithSeed(t *testing.T) {
+	config, err := ParseChaosConfig([]byte(`{"rules": []}`))
+	assert.NoError(t, err)
+
+	// Seeds pass through the chaos provider to providers that accept them.
+	real := &seededProvider{}
+	_, _, err = CheckWithSeed(NewChaosProvider(real, config), "urn:pulumi:s::p::t::a", nil, nil, []byte("seed"), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("seed"), real.seed)
+}
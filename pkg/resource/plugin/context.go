@@ -16,11 +16,15 @@ package plugin
 
 import (
 	"context"
+	"os"
+	"sort"
 
 	"github.com/opentracing/opentracing-go"
 
 	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/util/rpcutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 // Context is used to group related operations together so that associated OS resources can be cached, shared, and
@@ -31,9 +35,33 @@ type Context struct {
 	Host       Host      // the host that can be used to fetch providers.
 	Pwd        string    // the working directory to spawn all plugins in.
 
+	AutoNaming      resource.NamingStrategy    // the strategy providers should use to generate physical names.
+	ExpectedAccount string                     // the cloud account providers must be configured for, if any.
+	Interner        *resource.PropertyInterner // deduplicates the properties returned by providers.
+
+	Limits         *workspace.ProcessLimits // the resource limits to place on each plugin process, if any.
+	EnvVars        map[string]string        // environment variables to add to those each plugin process inherits.
+	ProgramEnvVars map[string]string        // environment variables to add to those of the language host only.
+
+	ProviderOptions ProviderOptions // options that control how resource providers are run.
+
 	tracingSpan opentracing.Span // the OpenTracing span to parent requests within.
 }
 
+// ProviderOptions control how the resource providers loaded by a context's host are run.  They are primarily useful
+// for reproducing and testing the behavior of updates.
+type ProviderOptions struct {
+	// RecordDir, if non-empty, is a directory into which every request made of a resource provider, along with the
+	// provider's response, is recorded.
+	RecordDir string
+	// ReplayDir, if non-empty, is a directory holding a recording made with RecordDir.  Rather than loading resource
+	// provider plugins, the host answers each request with the matching recorded response.  This allows an update to
+	// be reproduced without access to the resources that it manages.
+	ReplayDir string
+	// Chaos, if non-nil, describes the faults to inject into calls made of each resource provider.
+	Chaos *ChaosConfig
+}
+
 // NewContext allocates a new context with a given sink and host.  Note that the host is "owned" by this context from
 // here forwards, such that when the context's resources are reclaimed, so too are the host's.
 func NewContext(d, statusD diag.Sink, host Host, cfg ConfigSource, events Events,
@@ -43,6 +71,8 @@ func NewContext(d, statusD diag.Sink, host Host, cfg ConfigSource, events Events
 		StatusDiag:  statusD,
 		Host:        host,
 		Pwd:         pwd,
+		AutoNaming:  resource.DefaultNamingStrategy,
+		Interner:    resource.NewPropertyInterner(),
 		tracingSpan: parentSpan,
 	}
 	if host == nil {
@@ -55,6 +85,24 @@ func NewContext(d, statusD diag.Sink, host Host, cfg ConfigSource, events Events
 	return ctx, nil
 }
 
+// environ returns the environment of a plugin process: that of this process, overlaid with the context's environment
+// variables and then with extra, which may be nil.  Because the variables are never set in this process, they are only
+// visible to the plugins of contexts that carry them.
+func (ctx *Context) environ(extra map[string]string) []string {
+	env := os.Environ()
+	for _, vars := range []map[string]string{ctx.EnvVars, extra} {
+		names := make([]string, 0, len(vars))
+		for name := range vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			env = append(env, name+"="+vars[name])
+		}
+	}
+	return env
+}
+
 // Request allocates a request sub-context.
 func (ctx *Context) Request() context.Context {
 	// TODO[pulumi/pulumi#143]: support cancellation.
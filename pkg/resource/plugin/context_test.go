@@ -0,0 +1,41 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextEnviron(t *testing.T) {
+	ctx := &Context{EnvVars: map[string]string{"PULUMI_TEST_CREDENTIAL": "secret"}}
+	env := ctx.environ(nil)
+	assert.Equal(t, "PULUMI_TEST_CREDENTIAL=secret", env[len(env)-1])
+	assert.Equal(t, len(os.Environ())+1, len(env))
+
+	// The variables are given to plugins, but never set in this process.
+	_, has := os.LookupEnv("PULUMI_TEST_CREDENTIAL")
+	assert.False(t, has)
+
+	// Extra variables follow the context's, so that they take precedence.
+	env = ctx.environ(map[string]string{"PULUMI_TEST_PROXY": "b", "PULUMI_TEST_CREDENTIAL": "other"})
+	assert.Equal(t, []string{
+		"PULUMI_TEST_CREDENTIAL=secret",
+		"PULUMI_TEST_CREDENTIAL=other",
+		"PULUMI_TEST_PROXY=b",
+	}, env[len(env)-3:])
+}
@@ -0,0 +1,206 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// DisableHostDaemonEnvVar is the name of an environment variable that, when set to a truthy value, prevents the CLI
+// from attaching to a running `pulumi host` daemon.  Plugins are then always launched by the CLI itself.
+const DisableHostDaemonEnvVar = "PULUMI_DISABLE_HOST_DAEMON"
+
+// DaemonInfo records where a running host daemon may be reached.  The daemon writes it to the file named by
+// workspace.GetHostDaemonFilePath for as long as it is serving, and removes it when it exits.
+type DaemonInfo struct {
+	PID   int    `json:"pid"`   // the daemon's process ID.
+	Addr  string `json:"addr"`  // the address of the daemon's HTTP endpoint.
+	Token string `json:"token"` // the secret that authorizes requests to the daemon.
+}
+
+// daemonAcquireRequest asks a host daemon for a running instance of a plugin.
+type daemonAcquireRequest struct {
+	Path   string   `json:"path"`           // the path to the plugin's binary.
+	Args   []string `json:"args,omitempty"` // any arguments to pass ahead of the engine's address.
+	Pwd    string   `json:"pwd"`            // the working directory in which to run the plugin.
+	Env    []string `json:"env"`            // the environment in which to run the plugin.
+	Engine string   `json:"engine"`         // the address of the engine to which the plugin reports.
+}
+
+// daemonAcquireResponse describes a plugin instance that a host daemon has leased to the CLI.
+type daemonAcquireResponse struct {
+	ID   string `json:"id"`   // the lease's ID, which is used to release the plugin.
+	Port int    `json:"port"` // the port on which the plugin is listening.
+}
+
+// daemonReleaseRequest returns a leased plugin instance to a host daemon.
+type daemonReleaseRequest struct {
+	ID string `json:"id"`
+}
+
+// daemonRequestTimeout bounds each request to a host daemon.  Acquiring a plugin may require the daemon to start it.
+var daemonRequestTimeout = pluginRPCConnectionTimeout * 3
+
+// daemonClient issues requests to a running host daemon.
+type daemonClient struct {
+	info   DaemonInfo
+	client *http.Client
+}
+
+var (
+	attachOnce     sync.Once
+	attachedClient *daemonClient
+)
+
+// attachedDaemon returns a client for the host daemon that this process should use to launch plugins, or nil if there
+// is no such daemon.  The daemon's file is only read once per process.
+func attachedDaemon() *daemonClient {
+	attachOnce.Do(func() {
+		if cmdutil.IsTruthy(os.Getenv(DisableHostDaemonEnvVar)) {
+			return
+		}
+		path, err := workspace.GetHostDaemonFilePath()
+		if err != nil {
+			return
+		}
+		info, err := readDaemonInfo(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logging.Provider.V(7).Infof("ignoring unreadable host daemon file %s: %v", path, err)
+			}
+			return
+		}
+		logging.Provider.V(7).Infof("attaching to host daemon %d at %s", info.PID, info.Addr)
+		attachedClient = newDaemonClient(info)
+	})
+	return attachedClient
+}
+
+// readDaemonInfo reads the host daemon file at the given path.
+func readDaemonInfo(path string) (DaemonInfo, error) {
+	var info DaemonInfo
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	if err = json.Unmarshal(b, &info); err != nil {
+		return info, errors.Wrapf(err, "parsing %s", path)
+	}
+	return info, nil
+}
+
+func newDaemonClient(info DaemonInfo) *daemonClient {
+	return &daemonClient{
+		info:   info,
+		client: &http.Client{Timeout: daemonRequestTimeout},
+	}
+}
+
+// acquire leases a running instance of a plugin from the daemon and connects to it.
+func (d *daemonClient) acquire(ctx *Context, bin, prefix string, args []string, env []string,
+	engine string) (*plugin, error) {
+	req := daemonAcquireRequest{
+		Path:   bin,
+		Args:   args,
+		Pwd:    ctx.Pwd,
+		Env:    env,
+		Engine: engine,
+	}
+	var resp daemonAcquireResponse
+	if err := d.post("/acquire", req, &resp); err != nil {
+		return nil, err
+	}
+	release := func() error {
+		return d.post("/release", daemonReleaseRequest{ID: resp.ID}, nil)
+	}
+
+	conn, err := dialPlugin(resp.Port, bin, prefix)
+	if err != nil {
+		contract.IgnoreError(release())
+		return nil, err
+	}
+
+	logging.Provider.V(9).Infof("Leased plugin '%v' from host daemon on port %d (lease %s)", prefix, resp.Port, resp.ID)
+	return &plugin{
+		Bin:     bin,
+		Args:    args,
+		Port:    resp.Port,
+		Conn:    conn,
+		release: release,
+	}, nil
+}
+
+// post sends a JSON request to the daemon and decodes its JSON response, if any, into resp.
+func (d *daemonClient) post(path string, req interface{}, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequest("POST", fmt.Sprintf("http://%s%s", d.info.Addr, path), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "token "+d.info.Token)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := d.client.Do(httpReq)
+	if err != nil {
+		return errors.Wrap(err, "contacting host daemon")
+	}
+	defer contract.IgnoreClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(httpResp.Body)
+		return errors.Errorf("host daemon returned %s: %s", httpResp.Status, bytes.TrimSpace(msg))
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
+
+// startPlugin launches the plugin at bin, passing it args followed by the address of the host's RPC server.  If this
+// process is attached to a host daemon, the daemon supplies an instance that is already running instead; should that
+// fail for any reason, the plugin is launched directly.  Plugins whose resources are limited are always launched
+// directly, since the daemon's plugins are not limited.  The plugin's environment is overlaid with the context's
+// environment variables and then with env, which may be nil.
+func startPlugin(host Host, ctx *Context, bin, prefix string, args []string, env map[string]string) (*plugin, error) {
+	environ := ctx.environ(env)
+	if d := attachedDaemon(); d != nil && ctx.Limits == nil {
+		start := time.Now()
+		plug, err := d.acquire(ctx, bin, prefix, args, environ, host.ServerAddr())
+		if err == nil {
+			logging.Provider.V(7).Infof("acquired %s from host daemon in %v", prefix, time.Since(start))
+			return plug, nil
+		}
+		logging.Provider.V(7).Infof("host daemon could not supply %s; launching it directly: %v", prefix, err)
+	}
+
+	return newPlugin(ctx, bin, prefix, append(append([]string{}, args...), host.ServerAddr()), environ)
+}
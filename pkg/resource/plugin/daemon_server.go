@@ -0,0 +1,546 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pbempty "github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil"
+	lumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+// DefaultDaemonIdleTimeout is how long a host daemon waits without any plugins leased or requested before it exits.
+const DefaultDaemonIdleTimeout = 30 * time.Minute
+
+// Daemon is a long-running process that keeps language and provider plugins resident between CLI invocations.
+//
+// Plugins are stateful: once a CLI has configured a provider, it cannot safely be handed to another CLI.  The daemon
+// therefore keeps a spare, already-started instance of each plugin it has been asked for.  When a CLI acquires a
+// plugin, it leases the spare (starting one only if none is ready) and the daemon starts a replacement in the
+// background.  Released plugins are shut down.  The CLI thus never waits for a plugin process to boot once the daemon
+// has seen that plugin.
+//
+// Each plugin reports to an engine RPC server of its own inside the daemon, which forwards its log messages and its
+// output to the engine of the CLI that holds its lease.
+type Daemon struct {
+	path        string        // the file that records the daemon's address.
+	info        DaemonInfo    // the address and token of the daemon.
+	idleTimeout time.Duration // how long to wait without any activity before exiting.
+
+	listener net.Listener   // the listener for the daemon's HTTP endpoint.
+	server   *http.Server   // the daemon's HTTP server.
+	done     chan struct{}  // closed when the daemon shuts down.
+	starting sync.WaitGroup // tracks spares being started in the background.
+
+	lock       sync.Mutex               // guards the fields below.
+	spares     map[string]*daemonPlugin // a started, unleased plugin for each key.
+	warming    map[string]bool          // the keys for which a spare is being started.
+	leases     map[string]*daemonPlugin // the plugins currently leased by CLIs, by lease ID.
+	lastActive time.Time                // the last time that a CLI acquired or released a plugin.
+	closed     bool                     // true once the daemon has begun shutting down.
+	nextID     int                      // the ID of the next plugin to start.
+}
+
+// daemonPlugin is a single plugin process owned by a host daemon.
+type daemonPlugin struct {
+	id     string        // the plugin's ID, which doubles as its lease ID.
+	plug   *plugin       // the plugin itself.
+	engine *daemonEngine // the engine server to which the plugin reports.
+	lessee string        // the address of the engine of the CLI that leased the plugin, if any.
+}
+
+// NewDaemon creates a host daemon listening on a loopback port and records its address in the file at path.  It is an
+// error if the file already names a daemon that is still running.
+func NewDaemon(path string, idleTimeout time.Duration) (*Daemon, error) {
+	if idleTimeout <= 0 {
+		return nil, errors.New("the host daemon's idle timeout must be positive")
+	}
+	if info, err := readDaemonInfo(path); err == nil && daemonReachable(info.Addr) {
+		return nil, errors.Errorf("a host daemon is already running (pid %d)", info.PID)
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, errors.Wrap(err, "generating host daemon token")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Daemon{
+		path: path,
+		info: DaemonInfo{
+			PID:   os.Getpid(),
+			Addr:  listener.Addr().String(),
+			Token: hex.EncodeToString(token),
+		},
+		idleTimeout: idleTimeout,
+		listener:    listener,
+		spares:      make(map[string]*daemonPlugin),
+		warming:     make(map[string]bool),
+		leases:      make(map[string]*daemonPlugin),
+		lastActive:  time.Now(),
+		done:        make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/acquire", d.handle(func(body []byte) (interface{}, error) {
+		var req daemonAcquireRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		return d.acquire(req)
+	}))
+	mux.HandleFunc("/release", d.handle(func(body []byte) (interface{}, error) {
+		var req daemonReleaseRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		return nil, d.release(req.ID)
+	}))
+	d.server = &http.Server{Handler: mux}
+
+	b, err := json.Marshal(d.info)
+	if err != nil {
+		contract.IgnoreClose(listener)
+		return nil, err
+	}
+	if err = ioutil.WriteFile(path, b, 0600); err != nil {
+		contract.IgnoreClose(listener)
+		return nil, errors.Wrapf(err, "writing %s", path)
+	}
+
+	return d, nil
+}
+
+// Info returns the address and PID of the daemon.
+func (d *Daemon) Info() DaemonInfo {
+	return d.info
+}
+
+// Serve handles requests until the daemon is shut down, either by a call to Shutdown or because it has been idle for
+// longer than its idle timeout.
+func (d *Daemon) Serve() error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- d.server.Serve(d.listener)
+	}()
+
+	// Check on the daemon's leases and idleness a few times per idle timeout.
+	interval := d.idleTimeout / 4
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return nil
+		case err := <-serveErr:
+			d.Shutdown()
+			if err == http.ErrServerClosed {
+				return nil
+			}
+			return err
+		case <-ticker.C:
+			if d.reap() {
+				logging.Provider.V(5).Infof("host daemon idle for %v; shutting down", d.idleTimeout)
+				d.Shutdown()
+				return nil
+			}
+		}
+	}
+}
+
+// Shutdown stops the daemon, shuts down all of its plugins, and removes its file.
+func (d *Daemon) Shutdown() {
+	d.lock.Lock()
+	if d.closed {
+		d.lock.Unlock()
+		return
+	}
+	d.closed = true
+	d.lock.Unlock()
+
+	contract.IgnoreError(d.server.Close())
+	d.starting.Wait()
+
+	d.lock.Lock()
+	plugins := make([]*daemonPlugin, 0, len(d.spares)+len(d.leases))
+	for _, p := range d.spares {
+		plugins = append(plugins, p)
+	}
+	for _, p := range d.leases {
+		plugins = append(plugins, p)
+	}
+	d.spares, d.leases = map[string]*daemonPlugin{}, map[string]*daemonPlugin{}
+	d.lock.Unlock()
+
+	for _, p := range plugins {
+		p.close()
+	}
+
+	// Only remove the file if it still belongs to this daemon.
+	if info, err := readDaemonInfo(d.path); err == nil && info.Token == d.info.Token {
+		contract.IgnoreError(os.Remove(d.path))
+	}
+	close(d.done)
+}
+
+// handle wraps a daemon request handler with authorization and JSON encoding.
+func (d *Daemon) handle(handler func(body []byte) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(auth, []byte("token "+d.info.Token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != "POST" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp, err := handler(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if resp == nil {
+			resp = struct{}{}
+		}
+		contract.IgnoreError(json.NewEncoder(w).Encode(resp))
+	}
+}
+
+// acquire leases a running plugin to a CLI, starting one if no spare is ready, and starts a spare to replace it.
+func (d *Daemon) acquire(req daemonAcquireRequest) (*daemonAcquireResponse, error) {
+	key := daemonKey(req)
+
+	d.lock.Lock()
+	if d.closed {
+		d.lock.Unlock()
+		return nil, errors.New("the host daemon is shutting down")
+	}
+	d.lastActive = time.Now()
+	p := d.spares[key]
+	delete(d.spares, key)
+	d.lock.Unlock()
+
+	if p == nil {
+		var err error
+		if p, err = d.start(req); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.engine.attach(req.Engine); err != nil {
+		p.close()
+		return nil, err
+	}
+	p.lessee = req.Engine
+
+	d.lock.Lock()
+	d.leases[p.id] = p
+	d.lock.Unlock()
+
+	d.warm(key, req)
+	logging.Provider.V(7).Infof("host daemon leased %s (%s) to %s", p.id, req.Path, req.Engine)
+	return &daemonAcquireResponse{ID: p.id, Port: p.plug.Port}, nil
+}
+
+// release shuts down a plugin that was leased to a CLI.
+func (d *Daemon) release(id string) error {
+	d.lock.Lock()
+	p, has := d.leases[id]
+	delete(d.leases, id)
+	d.lastActive = time.Now()
+	d.lock.Unlock()
+
+	if !has {
+		return errors.Errorf("unknown lease %q", id)
+	}
+	logging.Provider.V(7).Infof("host daemon released %s", id)
+	p.close()
+	return nil
+}
+
+// warm starts a spare instance of a plugin in the background, unless one is already ready or being started.
+func (d *Daemon) warm(key string, req daemonAcquireRequest) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.closed || d.spares[key] != nil || d.warming[key] {
+		return
+	}
+	d.warming[key] = true
+	d.starting.Add(1)
+
+	go func() {
+		defer d.starting.Done()
+		p, err := d.start(req)
+
+		d.lock.Lock()
+		defer d.lock.Unlock()
+		delete(d.warming, key)
+		if err != nil {
+			logging.Provider.V(5).Infof("host daemon failed to start a spare %s: %v", req.Path, err)
+			return
+		}
+		if d.closed || d.spares[key] != nil {
+			go p.close()
+			return
+		}
+		d.spares[key] = p
+	}()
+}
+
+// start launches a new instance of a plugin along with the engine server to which it reports.
+func (d *Daemon) start(req daemonAcquireRequest) (*daemonPlugin, error) {
+	engine, err := newDaemonEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &Context{Diag: engine, StatusDiag: engine, Pwd: req.Pwd}
+	args := append(append([]string{}, req.Args...), engine.addr)
+	plug, err := newPlugin(ctx, req.Path, req.Path, args, req.Env)
+	if err != nil {
+		contract.IgnoreError(engine.Close())
+		return nil, err
+	}
+
+	d.lock.Lock()
+	d.nextID++
+	id := fmt.Sprintf("%d", d.nextID)
+	d.lock.Unlock()
+
+	return &daemonPlugin{id: id, plug: plug, engine: engine}, nil
+}
+
+// reap shuts down any leased plugins whose CLI has gone away without releasing them, and reports whether the daemon
+// has been idle for longer than its idle timeout.
+func (d *Daemon) reap() bool {
+	d.lock.Lock()
+	leases := make([]*daemonPlugin, 0, len(d.leases))
+	for _, p := range d.leases {
+		leases = append(leases, p)
+	}
+	d.lock.Unlock()
+
+	for _, p := range leases {
+		if !daemonReachable(p.lessee) {
+			logging.Provider.V(5).Infof("host daemon reclaiming %s: its CLI at %s has exited", p.id, p.lessee)
+			contract.IgnoreError(d.release(p.id))
+		}
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return len(d.leases) == 0 && time.Since(d.lastActive) > d.idleTimeout
+}
+
+// close shuts down the plugin and its engine server.
+func (p *daemonPlugin) close() {
+	contract.IgnoreError(p.plug.Close())
+	contract.IgnoreError(p.engine.Close())
+}
+
+// daemonKey returns the key under which plugins started for the given request are pooled.  Plugins may only be
+// handed out for requests that would have launched them identically.
+func daemonKey(req daemonAcquireRequest) string {
+	// Language hosts' arguments come from a map, so their order is not significant.
+	args := append([]string{}, req.Args...)
+	sort.Strings(args)
+	env := append([]string{}, req.Env...)
+	sort.Strings(env)
+
+	h := sha256.New()
+	for _, part := range [][]string{{req.Path, req.Pwd}, args, env} {
+		_, err := fmt.Fprintf(h, "%s\x00", strings.Join(part, "\x00"))
+		contract.IgnoreError(err)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// daemonReachable returns true if something is listening at the given TCP address.
+func daemonReachable(addr string) bool {
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		return false
+	}
+	contract.IgnoreClose(conn)
+	return true
+}
+
+// daemonEngine is the engine RPC server to which a plugin started by a host daemon reports.  It forwards the plugin's
+// log messages, along with anything the plugin writes to stdout or stderr, to the engine of the CLI that has leased
+// the plugin.  Messages that arrive while the plugin is not leased are only logged by the daemon.
+type daemonEngine struct {
+	addr   string     // the address the engine server is listening on.
+	cancel chan bool  // a channel that can cancel the server.
+	done   chan error // a channel that resolves when the server completes.
+
+	lock   sync.Mutex           // guards the fields below.
+	conn   *grpc.ClientConn     // the connection to the lessee's engine, if any.
+	target lumirpc.EngineClient // the lessee's engine, if any.
+}
+
+var _ diag.Sink = (*daemonEngine)(nil)
+
+func newDaemonEngine() (*daemonEngine, error) {
+	engine := &daemonEngine{cancel: make(chan bool)}
+	port, done, err := rpcutil.Serve(0, engine.cancel, []func(*grpc.Server) error{
+		func(srv *grpc.Server) error {
+			lumirpc.RegisterEngineServer(srv, engine)
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	engine.addr = fmt.Sprintf("127.0.0.1:%d", port)
+	engine.done = done
+	return engine, nil
+}
+
+// attach directs subsequent messages to the engine at the given address.
+func (eng *daemonEngine) attach(addr string) error {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return errors.Wrapf(err, "could not dial engine at %s", addr)
+	}
+
+	eng.lock.Lock()
+	defer eng.lock.Unlock()
+	if eng.conn != nil {
+		contract.IgnoreError(eng.conn.Close())
+	}
+	eng.conn, eng.target = conn, lumirpc.NewEngineClient(conn)
+	return nil
+}
+
+// Close stops the engine server and disconnects from the lessee's engine.
+func (eng *daemonEngine) Close() error {
+	eng.lock.Lock()
+	if eng.conn != nil {
+		contract.IgnoreError(eng.conn.Close())
+		eng.conn, eng.target = nil, nil
+	}
+	eng.lock.Unlock()
+
+	eng.cancel <- true
+	return <-eng.done
+}
+
+// Log forwards a log message from the plugin to the lessee's engine.
+func (eng *daemonEngine) Log(ctx context.Context, req *lumirpc.LogRequest) (*pbempty.Empty, error) {
+	eng.lock.Lock()
+	target := eng.target
+	eng.lock.Unlock()
+
+	if target == nil {
+		logging.Provider.V(5).Infof("unleased plugin logged: %s", req.Message)
+		return &pbempty.Empty{}, nil
+	}
+	return target.Log(ctx, req)
+}
+
+// Logf forwards output from the plugin process to the lessee's engine.
+func (eng *daemonEngine) Logf(sev diag.Severity, d *diag.Diag, args ...interface{}) {
+	msg := d.Message
+	if !d.Raw {
+		msg = fmt.Sprintf(msg, args...)
+	}
+
+	var severity lumirpc.LogSeverity
+	switch sev {
+	case diag.Debug:
+		severity = lumirpc.LogSeverity_DEBUG
+	case diag.Warning:
+		severity = lumirpc.LogSeverity_WARNING
+	case diag.Error:
+		severity = lumirpc.LogSeverity_ERROR
+	default:
+		// The engine protocol has no way to distinguish stderr output, so it is reported as ordinary output.
+		severity = lumirpc.LogSeverity_INFO
+	}
+
+	_, err := eng.Log(context.Background(), &lumirpc.LogRequest{
+		Severity: severity,
+		Message:  msg,
+		Urn:      string(d.URN),
+		StreamId: d.StreamID,
+	})
+	if err != nil {
+		logging.Provider.V(5).Infof("failed to forward plugin output: %v", err)
+	}
+}
+
+func (eng *daemonEngine) Debugf(d *diag.Diag, args ...interface{}) {
+	eng.Logf(diag.Debug, d, args...)
+}
+
+func (eng *daemonEngine) Infof(d *diag.Diag, args ...interface{}) {
+	eng.Logf(diag.Info, d, args...)
+}
+
+func (eng *daemonEngine) Infoerrf(d *diag.Diag, args ...interface{}) {
+	eng.Logf(diag.Infoerr, d, args...)
+}
+
+func (eng *daemonEngine) Errorf(d *diag.Diag, args ...interface{}) {
+	eng.Logf(diag.Error, d, args...)
+}
+
+func (eng *daemonEngine) Warningf(d *diag.Diag, args ...interface{}) {
+	eng.Logf(diag.Warning, d, args...)
+}
+
+// Stringify renders a message without any decoration; the lessee's engine formats the messages it receives.
+func (eng *daemonEngine) Stringify(sev diag.Severity, d *diag.Diag, args ...interface{}) (string, string) {
+	if d.Raw {
+		return "", d.Message
+	}
+	return "", fmt.Sprintf(d.Message, args...)
+}
@@ -0,0 +1,134 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDaemon(t *testing.T, idleTimeout time.Duration) (*Daemon, string) {
+	dir, err := ioutil.TempDir("", "pulumi-host-daemon")
+	assert.NoError(t, err)
+	path := filepath.Join(dir, "host.json")
+
+	d, err := NewDaemon(path, idleTimeout)
+	assert.NoError(t, err)
+	return d, path
+}
+
+func TestDaemonRecordsItself(t *testing.T) {
+	d, path := newTestDaemon(t, time.Minute)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	served := make(chan error)
+	go func() { served <- d.Serve() }()
+
+	info, err := readDaemonInfo(path)
+	assert.NoError(t, err)
+	assert.Equal(t, d.Info(), info)
+	assert.Equal(t, os.Getpid(), info.PID)
+
+	// A second daemon may not start while the first is running.
+	_, err = NewDaemon(path, time.Minute)
+	assert.Error(t, err)
+
+	d.Shutdown()
+	assert.NoError(t, <-served)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDaemonIdleShutdown(t *testing.T) {
+	d, path := newTestDaemon(t, 100*time.Millisecond)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	served := make(chan error)
+	go func() { served <- d.Serve() }()
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("daemon did not shut down while idle")
+	}
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDaemonRequests(t *testing.T) {
+	d, path := newTestDaemon(t, time.Minute)
+	defer os.RemoveAll(filepath.Dir(path))
+	served := make(chan error)
+	go func() { served <- d.Serve() }()
+	defer func() {
+		d.Shutdown()
+		assert.NoError(t, <-served)
+	}()
+
+	// Requests without the daemon's token are rejected.
+	bad := d.Info()
+	bad.Token = "wrong"
+	err := newDaemonClient(bad).post("/release", daemonReleaseRequest{ID: "1"}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+
+	// Releasing a plugin that was never leased is an error.
+	err = newDaemonClient(d.Info()).post("/release", daemonReleaseRequest{ID: "1"}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown lease")
+
+	// Acquiring a plugin that cannot be launched reports the failure.
+	var resp daemonAcquireResponse
+	err = newDaemonClient(d.Info()).post("/acquire", daemonAcquireRequest{
+		Path:   filepath.Join(filepath.Dir(path), "missing-plugin"),
+		Engine: "127.0.0.1:0",
+	}, &resp)
+	assert.Error(t, err)
+}
+
+func TestDaemonKey(t *testing.T) {
+	base := daemonAcquireRequest{
+		Path: "/bin/plugin",
+		Args: []string{"-a=true", "-b=false"},
+		Pwd:  "/src",
+		Env:  []string{"A=1", "B=2"},
+	}
+	key := daemonKey(base)
+
+	// The order of arguments and environment variables is not significant, and nor is the engine.
+	reordered := base
+	reordered.Args = []string{"-b=false", "-a=true"}
+	reordered.Env = []string{"B=2", "A=1"}
+	reordered.Engine = "127.0.0.1:1234"
+	assert.Equal(t, key, daemonKey(reordered))
+
+	// Everything else distinguishes plugins.
+	for _, mutate := range []func(r *daemonAcquireRequest){
+		func(r *daemonAcquireRequest) { r.Path = "/bin/other" },
+		func(r *daemonAcquireRequest) { r.Args = []string{"-a=true"} },
+		func(r *daemonAcquireRequest) { r.Pwd = "/other" },
+		func(r *daemonAcquireRequest) { r.Env = []string{"A=1", "B=3"} },
+	} {
+		changed := base
+		mutate(&changed)
+		assert.NotEqual(t, key, daemonKey(changed))
+	}
+}
@@ -16,6 +16,7 @@ package plugin
 
 import (
 	"os"
+	"sync"
 
 	"github.com/blang/semver"
 	"github.com/hashicorp/go-multierror"
@@ -52,6 +53,10 @@ type Host interface {
 	Provider(pkg tokens.Package, version *semver.Version) (Provider, error)
 	// CloseProvider closes the given provider plugin and deregisters it from this host.
 	CloseProvider(provider Provider) error
+	// WarmProviders concurrently starts a copy of the provider plugin for each of the given resource plugins, so that
+	// the plugins are ready by the time they are first requested.  Plugins that cannot be started are skipped; the
+	// error is reported when the plugin is actually requested.
+	WarmProviders(plugins []workspace.PluginInfo)
 	// LanguageRuntime fetches the language runtime plugin for a given language, lazily allocating if necessary.  If
 	// an implementation of this language runtime wasn't found, on an error occurs, a non-nil error is returned.
 	LanguageRuntime(runtime string) (LanguageRuntime, error)
@@ -95,6 +100,8 @@ func NewDefaultHost(ctx *Context, config ConfigSource, events Events,
 		languagePlugins:         make(map[string]*languagePlugin),
 		resourcePlugins:         make(map[Provider]*resourcePlugin),
 		reportedResourcePlugins: make(map[string]struct{}),
+		warmProviders:           make(map[string][]Provider),
+		keepWarm:                cmdutil.IsTruthy(os.Getenv(KeepProvidersWarmEnvVar)),
 		loadRequests:            make(chan pluginLoadRequest),
 	}
 
@@ -116,6 +123,11 @@ func NewDefaultHost(ctx *Context, config ConfigSource, events Events,
 	return host, nil
 }
 
+// KeepProvidersWarmEnvVar is the name of an environment variable that, if truthy, causes the plugin host to keep a
+// spare, unconfigured copy of each provider plugin running once it has been used.  This trades an extra process per
+// provider for not having to wait for a plugin to start each time a new provider is needed.
+const KeepProvidersWarmEnvVar = "PULUMI_KEEP_PROVIDERS_WARM"
+
 type pluginLoadRequest struct {
 	load   func() error
 	result chan<- error
@@ -131,6 +143,9 @@ type defaultHost struct {
 	resourcePlugins         map[Provider]*resourcePlugin     // the set of loaded resource plugins.
 	reportedResourcePlugins map[string]struct{}              // the set of unique resource plugins we'll report.
 	plugins                 []workspace.PluginInfo           // a list of plugins allocated by this host.
+	warmProviders           map[string][]Provider            // started but unused providers, by package and version.
+	keepWarm                bool                             // true to replace warm providers as they are used.
+	warming                 sync.WaitGroup                   // tracks providers being started in the background.
 	loadRequests            chan pluginLoadRequest           // a channel used to satisfy plugin load requests.
 	server                  *hostServer                      // the server's RPC machinery.
 }
@@ -215,55 +230,139 @@ func (host *defaultHost) Analyzer(name tokens.QName) (Analyzer, error) {
 }
 
 func (host *defaultHost) Provider(pkg tokens.Package, version *semver.Version) (Provider, error) {
+	// If a copy of this plugin has already been started, hand it out.
+	if plug := host.takeWarmProvider(pkg, version); plug != nil {
+		return plug, nil
+	}
+	return host.startProvider(pkg, version)
+}
+
+// warmProviderKey returns the key under which warm copies of the provider for the given package and version are kept.
+func warmProviderKey(pkg tokens.Package, version *semver.Version) string {
+	if version == nil {
+		return string(pkg)
+	}
+	return string(pkg) + "@" + version.String()
+}
+
+// takeWarmProvider removes and returns a warm copy of the provider for the given package and version, if there is one.
+func (host *defaultHost) takeWarmProvider(pkg tokens.Package, version *semver.Version) Provider {
+	key := warmProviderKey(pkg, version)
 	plugin, err := host.loadPlugin(func() (interface{}, error) {
-		// Try to load and bind to a plugin.
-		plug, err := NewProvider(host, host.ctx, pkg, version)
-		if err == nil && plug != nil {
-			info, infoerr := plug.GetPluginInfo()
-			if infoerr != nil {
-				return nil, infoerr
-			}
+		warm := host.warmProviders[key]
+		if len(warm) == 0 {
+			return nil, nil
+		}
+		host.warmProviders[key] = warm[1:]
+		return warm[0], nil
+	})
+	contract.AssertNoError(err)
+	if plugin == nil {
+		return nil
+	}
 
-			// Warn if the plugin version was not what we expected
-			if version != nil && !cmdutil.IsTruthy(os.Getenv("PULUMI_DEV")) {
-				if info.Version == nil || !info.Version.GTE(*version) {
-					var v string
-					if info.Version != nil {
-						v = info.Version.String()
-					}
-					host.ctx.Diag.Warningf(
-						diag.Message("", /*urn*/
-							"resource plugin %s is expected to have version >=%s, but has %s; "+
-								"the wrong version may be on your path, or this may be a bug in the plugin"),
-						info.Name, version.String(), v)
-				}
+	logging.Provider.V(7).Infof("using a warm copy of the %s provider", key)
+	if host.keepWarm {
+		host.warmProvider(pkg, version)
+	}
+	return plugin.(Provider)
+}
+
+// warmProvider starts a copy of the provider for the given package and version in the background, and adds it to the
+// host's warm providers once it is ready.
+func (host *defaultHost) warmProvider(pkg tokens.Package, version *semver.Version) {
+	host.warming.Add(1)
+	go func() {
+		defer host.warming.Done()
+		if err := host.addWarmProvider(pkg, version); err != nil {
+			logging.Provider.V(7).Infof("failed to warm the %s provider: %v", pkg, err)
+		}
+	}()
+}
+
+// addWarmProvider starts a copy of the provider for the given package and version and adds it to the host's warm
+// providers.
+func (host *defaultHost) addWarmProvider(pkg tokens.Package, version *semver.Version) error {
+	plug, err := host.startProvider(pkg, version)
+	if plug == nil || err != nil {
+		return err
+	}
+	key := warmProviderKey(pkg, version)
+	_, err = host.loadPlugin(func() (interface{}, error) {
+		host.warmProviders[key] = append(host.warmProviders[key], plug)
+		return nil, nil
+	})
+	return err
+}
+
+// WarmProviders concurrently starts a copy of the provider plugin for each of the given resource plugins.
+func (host *defaultHost) WarmProviders(plugins []workspace.PluginInfo) {
+	var wg sync.WaitGroup
+	for _, plugin := range plugins {
+		if plugin.Kind != workspace.ResourcePlugin {
+			continue
+		}
+		wg.Add(1)
+		go func(pkg tokens.Package, version *semver.Version) {
+			defer wg.Done()
+			if err := host.addWarmProvider(pkg, version); err != nil {
+				logging.Provider.V(7).Infof("failed to warm the %s provider: %v", pkg, err)
 			}
+		}(tokens.Package(plugin.Name), plugin.Version)
+	}
+	wg.Wait()
+}
+
+// startProvider starts a new copy of the provider for the given package and version.  Starting a plugin is slow, so
+// it happens outside of the plugin loader, which allows several plugins to start at once; only the bookkeeping that
+// follows is serialized.
+func (host *defaultHost) startProvider(pkg tokens.Package, version *semver.Version) (Provider, error) {
+	// Try to load and bind to a plugin.
+	plug, err := NewProvider(host, host.ctx, pkg, version)
+	if plug == nil || err != nil {
+		return nil, err
+	}
+	info, err := plug.GetPluginInfo()
+	if err != nil {
+		contract.IgnoreError(plug.Close())
+		return nil, err
+	}
 
-			// Record the result and add the plugin's info to our list of loaded plugins if it's the first copy of its
-			// kind.
-			key := info.Name
+	// Warn if the plugin version was not what we expected
+	if version != nil && !cmdutil.IsTruthy(os.Getenv("PULUMI_DEV")) {
+		if info.Version == nil || !info.Version.GTE(*version) {
+			var v string
 			if info.Version != nil {
-				key += info.Version.String()
-			}
-			_, alreadyReported := host.reportedResourcePlugins[key]
-			if !alreadyReported {
-				host.reportedResourcePlugins[key] = struct{}{}
-				host.plugins = append(host.plugins, info)
-			}
-			host.resourcePlugins[plug] = &resourcePlugin{Plugin: plug, Info: info}
-			if host.events != nil && !alreadyReported {
-				if eventerr := host.events.OnPluginLoad(info); eventerr != nil {
-					return nil, errors.Wrapf(eventerr, "failed to perform plugin load callback")
-				}
+				v = info.Version.String()
 			}
+			host.ctx.Diag.Warningf(diag.GetPluginVersionMismatchWarning("" /*urn*/), info.Name, version.String(), v)
 		}
+	}
 
-		return plug, err
+	_, err = host.loadPlugin(func() (interface{}, error) {
+		// Record the result and add the plugin's info to our list of loaded plugins if it's the first copy of its
+		// kind.
+		key := info.Name
+		if info.Version != nil {
+			key += info.Version.String()
+		}
+		_, alreadyReported := host.reportedResourcePlugins[key]
+		if !alreadyReported {
+			host.reportedResourcePlugins[key] = struct{}{}
+			host.plugins = append(host.plugins, info)
+		}
+		host.resourcePlugins[plug] = &resourcePlugin{Plugin: plug, Info: info}
+		if host.events != nil && !alreadyReported {
+			if eventerr := host.events.OnPluginLoad(info); eventerr != nil {
+				return nil, errors.Wrapf(eventerr, "failed to perform plugin load callback")
+			}
+		}
+		return nil, nil
 	})
-	if plugin == nil || err != nil {
+	if err != nil {
 		return nil, err
 	}
-	return plugin.(Provider), nil
+	return plug, nil
 }
 
 func (host *defaultHost) LanguageRuntime(runtime string) (LanguageRuntime, error) {
@@ -412,6 +511,9 @@ func (host *defaultHost) CloseProvider(provider Provider) error {
 }
 
 func (host *defaultHost) Close() error {
+	// Wait for any providers that are starting in the background, so that they are closed below.
+	host.warming.Wait()
+
 	// Close all plugins.
 	for _, plug := range host.analyzerPlugins {
 		if err := plug.Plugin.Close(); err != nil {
@@ -433,6 +535,7 @@ func (host *defaultHost) Close() error {
 	host.analyzerPlugins = make(map[tokens.QName]*analyzerPlugin)
 	host.languagePlugins = make(map[string]*languagePlugin)
 	host.resourcePlugins = make(map[Provider]*resourcePlugin)
+	host.warmProviders = make(map[string][]Provider)
 
 	// Shut down the plugin loader.
 	close(host.loadRequests)
@@ -59,9 +59,9 @@ func NewLanguageRuntime(host Host, ctx *Context, runtime string,
 	for k, v := range options {
 		args = append(args, fmt.Sprintf("-%s=%t", k, v))
 	}
-	args = append(args, host.ServerAddr())
 
-	plug, err := newPlugin(ctx, path, runtime, args)
+	// The language host runs the program, so the variables that are meant for the program are given to it alone.
+	plug, err := startPlugin(host, ctx, path, runtime, args, ctx.ProgramEnvVars)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +80,7 @@ func (h *langhost) Runtime() string { return h.runtime }
 // GetRequiredPlugins computes the complete set of anticipated plugins required by a program.
 func (h *langhost) GetRequiredPlugins(info ProgInfo) ([]workspace.PluginInfo, error) {
 	proj := string(info.Proj.Name)
-	logging.V(7).Infof("langhost[%v].GetRequiredPlugins(proj=%s,pwd=%s,program=%s) executing",
+	logging.Provider.V(7).Infof("langhost[%v].GetRequiredPlugins(proj=%s,pwd=%s,program=%s) executing",
 		h.runtime, proj, info.Pwd, info.Program)
 	resp, err := h.client.GetRequiredPlugins(h.ctx.Request(), &pulumirpc.GetRequiredPluginsRequest{
 		Project: proj,
@@ -89,7 +89,7 @@ func (h *langhost) GetRequiredPlugins(info ProgInfo) ([]workspace.PluginInfo, er
 	})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
-		logging.V(7).Infof("langhost[%v].GetRequiredPlugins(proj=%s,pwd=%s,program=%s) failed: err=%v",
+		logging.Provider.V(7).Infof("langhost[%v].GetRequiredPlugins(proj=%s,pwd=%s,program=%s) failed: err=%v",
 			h.runtime, proj, info.Pwd, info.Program, rpcError)
 
 		// It's possible this is just an older language host, prior to the emergence of the GetRequiredPlugins
@@ -121,7 +121,7 @@ func (h *langhost) GetRequiredPlugins(info ProgInfo) ([]workspace.PluginInfo, er
 		})
 	}
 
-	logging.V(7).Infof("langhost[%v].GetRequiredPlugins(proj=%s,pwd=%s,program=%s) success: #versions=%d",
+	logging.Provider.V(7).Infof("langhost[%v].GetRequiredPlugins(proj=%s,pwd=%s,program=%s) success: #versions=%d",
 		h.runtime, proj, info.Pwd, info.Program, len(results))
 	return results, nil
 
@@ -131,7 +131,8 @@ func (h *langhost) GetRequiredPlugins(info ProgInfo) ([]workspace.PluginInfo, er
 // the code must not assume that side-effects or final values resulting from resource deployments are actually
 // available.  If it is false, on the other hand, a real deployment is occurring and it may safely depend on these.
 func (h *langhost) Run(info RunInfo) (string, error) {
-	logging.V(7).Infof("langhost[%v].Run(pwd=%v,program=%v,#args=%v,proj=%s,stack=%v,#config=%v,dryrun=%v) executing",
+	logging.Provider.V(7).Infof(
+		"langhost[%v].Run(pwd=%v,program=%v,#args=%v,proj=%s,stack=%v,#config=%v,dryrun=%v) executing",
 		h.runtime, info.Pwd, info.Program, len(info.Args), info.Project, info.Stack, len(info.Config), info.DryRun)
 	config := make(map[string]string)
 	for k, v := range info.Config {
@@ -150,24 +151,24 @@ func (h *langhost) Run(info RunInfo) (string, error) {
 	})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
-		logging.V(7).Infof("langhost[%v].Run(pwd=%v,program=%v,...,dryrun=%v) failed: err=%v",
+		logging.Provider.V(7).Infof("langhost[%v].Run(pwd=%v,program=%v,...,dryrun=%v) failed: err=%v",
 			h.runtime, info.Pwd, info.Program, info.DryRun, rpcError)
 		return "", rpcError
 	}
 
 	progerr := resp.GetError()
-	logging.V(7).Infof("langhost[%v].RunPlan(pwd=%v,program=%v,...,dryrun=%v) success: progerr=%v",
+	logging.Provider.V(7).Infof("langhost[%v].RunPlan(pwd=%v,program=%v,...,dryrun=%v) success: progerr=%v",
 		h.runtime, info.Pwd, info.Program, info.DryRun, progerr)
 	return progerr, nil
 }
 
 // GetPluginInfo returns this plugin's information.
 func (h *langhost) GetPluginInfo() (workspace.PluginInfo, error) {
-	logging.V(7).Infof("langhost[%v].GetPluginInfo() executing", h.runtime)
+	logging.Provider.V(7).Infof("langhost[%v].GetPluginInfo() executing", h.runtime)
 	resp, err := h.client.GetPluginInfo(h.ctx.Request(), &pbempty.Empty{})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
-		logging.V(7).Infof("langhost[%v].GetPluginInfo() failed: err=%v", h.runtime, rpcError)
+		logging.Provider.V(7).Infof("langhost[%v].GetPluginInfo() failed: err=%v", h.runtime, rpcError)
 		return workspace.PluginInfo{}, rpcError
 	}
 
@@ -0,0 +1,47 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// limitedCommand returns a command that runs bin with the given arguments under the given resource limits.  The
+// command is a shell that places the limits on itself and then replaces itself with bin, so that the limits are in
+// place before bin starts.  Each limit is both the soft and the hard limit, so that bin cannot raise it again.
+func limitedCommand(bin string, args []string, limits *workspace.ProcessLimits) (*exec.Cmd, error) {
+	var script []string
+	for _, limit := range []struct {
+		flag  string
+		value uint64
+	}{
+		{"-t", limits.CPUSeconds},        // seconds
+		{"-v", limits.MemoryMB * 1024},   // kilobytes
+		{"-n", limits.OpenFiles},         // files
+		{"-f", limits.FileSizeMB * 2048}, // 512-byte blocks
+	} {
+		if limit.value != 0 {
+			script = append(script, fmt.Sprintf("ulimit %s %d", limit.flag, limit.value))
+		}
+	}
+	script = append(script, `exec "$0" "$@"`)
+
+	// nolint: gas
+	return exec.Command("/bin/sh", append([]string{"-c", strings.Join(script, " && "), bin}, args...)...), nil
+}
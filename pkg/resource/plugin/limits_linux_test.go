@@ -0,0 +1,65 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestPluginProcessLimits(t *testing.T) {
+	// The limits are in place before the plugin starts, so it sees them as soon as it does.
+	limits := &workspace.ProcessLimits{CPUSeconds: 60, MemoryMB: 4096, OpenFiles: 64, FileSizeMB: 1}
+	plug, err := execPlugin("/bin/cat", []string{"/proc/self/limits"}, "", nil, limits)
+	if !assert.NoError(t, err) {
+		return
+	}
+	out, err := ioutil.ReadAll(plug.Stdout)
+	assert.NoError(t, err)
+	_, err = plug.Proc.Wait()
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		`Max cpu time\s+60\s+60\s+seconds`,
+		`Max address space\s+4294967296\s+4294967296\s+bytes`,
+		`Max open files\s+64\s+64\s+files`,
+		`Max file size\s+1048576\s+1048576\s+bytes`,
+	} {
+		assert.Regexp(t, regexp.MustCompile(expected), string(out))
+	}
+}
+
+func TestPluginProcessLimitsUnraisable(t *testing.T) {
+	// A process may not raise a limit that has been placed on it, unless it is privileged.
+	if os.Geteuid() == 0 {
+		t.Skip("privileged processes may raise their limits")
+	}
+	limits := &workspace.ProcessLimits{OpenFiles: 64}
+	plug, err := execPlugin("/bin/sh", []string{"-c", "ulimit -n 128"}, "", nil, limits)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = ioutil.ReadAll(plug.Stderr)
+	assert.NoError(t, err)
+	state, err := plug.Proc.Wait()
+	assert.NoError(t, err)
+	assert.False(t, state.Success())
+}
@@ -0,0 +1,31 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// +build !linux
+
+package plugin
+
+import (
+	"os/exec"
+	"runtime"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// limitedCommand returns a command that runs bin with the given arguments under the given resource limits, which
+// this platform does not support.
+func limitedCommand(bin string, args []string, limits *workspace.ProcessLimits) (*exec.Cmd, error) {
+	return nil, errors.Errorf("limiting the resources of plugins is not supported on %s", runtime.GOOS)
+}
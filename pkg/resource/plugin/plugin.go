@@ -59,17 +59,26 @@ func (err *MissingError) Error() string {
 		err.Info.Kind, err.Info.String())
 }
 
+// DiagnosticID returns the stable code for this class of errors.
+func (err *MissingError) DiagnosticID() diag.ID {
+	return diag.PluginMissingID
+}
+
 type plugin struct {
 	stdoutDone <-chan bool
 	stderrDone <-chan bool
 
 	Bin    string
 	Args   []string
+	Port   int
 	Conn   *grpc.ClientConn
 	Proc   *os.Process
 	Stdin  io.WriteCloser
 	Stdout io.ReadCloser
 	Stderr io.ReadCloser
+
+	// release, if non-nil, hands the plugin back to the host daemon that supplied it instead of killing a process.
+	release func() error
 }
 
 // pluginRPCConnectionTimeout dictates how long we wait for the plugin's RPC to become available.
@@ -80,8 +89,10 @@ var pluginRPCConnectionTimeout = time.Second * 10
 // time.
 var nextStreamID int32
 
-func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin, error) {
-	if logging.V(9) {
+// newPlugin launches the plugin at bin and connects to it.  If env is non-nil, it replaces the environment that the
+// plugin would otherwise inherit from this process and the context.
+func newPlugin(ctx *Context, bin string, prefix string, args []string, env []string) (*plugin, error) {
+	if logging.Provider.V(9).Enabled() {
 		var argstr string
 		for i, arg := range args {
 			if i > 0 {
@@ -89,11 +100,14 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 			}
 			argstr += arg
 		}
-		logging.V(9).Infof("Launching plugin '%v' from '%v' with args: %v", prefix, bin, argstr)
+		logging.Provider.V(9).Infof("Launching plugin '%v' from '%v' with args: %v", prefix, bin, argstr)
 	}
 
 	// Try to execute the binary.
-	plug, err := execPlugin(bin, args, ctx.Pwd)
+	if env == nil && len(ctx.EnvVars) > 0 {
+		env = ctx.environ(nil)
+	}
+	plug, err := execPlugin(bin, args, ctx.Pwd, env, ctx.Limits)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to load plugin %s", bin)
 	}
@@ -157,7 +171,7 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 	}
 
 	// Parse the output line (minus the '\n') to ensure it's a numeric port.
-	if _, err = strconv.Atoi(port); err != nil {
+	if plug.Port, err = strconv.Atoi(port); err != nil {
 		killerr := plug.Proc.Kill()
 		contract.IgnoreError(killerr) // ignoring the error because the existing one trumps it.
 		return nil, errors.Wrapf(
@@ -170,7 +184,20 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 	go runtrace(plug.Stdout, false, stdoutDone)
 
 	// Now that we have the port, go ahead and create a gRPC client connection to it.
-	conn, err := grpc.Dial(":"+port, grpc.WithInsecure(), grpc.WithUnaryInterceptor(
+	conn, err := dialPlugin(plug.Port, bin, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Done; store the connection and return the plugin info.
+	plug.Conn = conn
+	return plug, nil
+}
+
+// dialPlugin creates a gRPC client connection to the plugin listening on the given port and waits for it to become
+// ready.
+func dialPlugin(port int, bin string, prefix string) (*grpc.ClientConn, error) {
+	conn, err := grpc.Dial(fmt.Sprintf(":%d", port), grpc.WithInsecure(), grpc.WithUnaryInterceptor(
 		rpcutil.OpenTracingClientInterceptor(),
 	))
 	if err != nil {
@@ -208,6 +235,7 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 					}
 
 					// Unexpected error; get outta dodge.
+					contract.IgnoreError(conn.Close())
 					return nil, errors.Wrapf(err, "%v plugin [%v] did not come alive", prefix, bin)
 				}
 			}
@@ -215,24 +243,27 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 		}
 		// Not ready yet; ask the gRPC client APIs to block until the state transitions again so we can retry.
 		if !conn.WaitForStateChange(timeout, s) {
+			contract.IgnoreError(conn.Close())
 			return nil, errors.Errorf("%v plugin [%v] did not begin responding to RPC connections", prefix, bin)
 		}
 	}
 
-	// Done; store the connection and return the plugin info.
-	plug.Conn = conn
-	return plug, nil
+	return conn, nil
 }
 
-func execPlugin(bin string, pluginArgs []string, pwd string) (*plugin, error) {
+// execPlugin starts the plugin at bin.  If limits is non-nil, they are placed on the plugin's process before it starts,
+// so that the processes that it starts to do its work, such as a language host's program, inherit them too.
+func execPlugin(bin string, pluginArgs []string, pwd string, env []string,
+	limits *workspace.ProcessLimits) (*plugin, error) {
 	var args []string
 	// Flow the logging information if set.
 	if logging.LogFlow {
 		if logging.LogToStderr {
 			args = append(args, "-logtostderr")
 		}
-		if logging.Verbose > 0 {
-			args = append(args, "-v="+strconv.Itoa(logging.Verbose))
+		// Plugins only understand glog's flags, so they are given the provider module's verbosity.
+		if verbose := logging.Provider.Level(); verbose > 0 {
+			args = append(args, "-v="+strconv.Itoa(verbose))
 		}
 	}
 	// Always flow tracing settings.
@@ -243,8 +274,15 @@ func execPlugin(bin string, pluginArgs []string, pwd string) (*plugin, error) {
 
 	// nolint: gas
 	cmd := exec.Command(bin, args...)
+	if limits != nil {
+		var err error
+		if cmd, err = limitedCommand(bin, args, limits); err != nil {
+			return nil, err
+		}
+	}
 	cmdutil.RegisterProcessGroup(cmd)
 	cmd.Dir = pwd
+	cmd.Env = env
 	in, _ := cmd.StdinPipe()
 	out, _ := cmd.StdoutPipe()
 	err, _ := cmd.StderrPipe()
@@ -268,6 +306,11 @@ func (p *plugin) Close() error {
 		contract.IgnoreError(closerr)
 	}
 
+	// If the plugin belongs to a host daemon, there is no process of our own to kill.
+	if p.release != nil {
+		return p.release()
+	}
+
 	var result error
 
 	// On each platform, plugins are not loaded directly, instead a shell launches each plugin as a child process, so
@@ -16,6 +16,7 @@ package plugin
 
 import (
 	"io"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/tokens"
@@ -38,12 +39,17 @@ type Provider interface {
 	// Pkg fetches this provider's package.
 	Pkg() tokens.Package
 
+	// Parameterize parameterizes the provider before it is configured, e.g. with the version of the API it should
+	// target.  It must be called, if at all, before CheckConfig.
+	Parameterize(params resource.PropertyMap) error
 	// CheckConfig validates the configuration for this resource provider.
 	CheckConfig(olds, news resource.PropertyMap) (resource.PropertyMap, []CheckFailure, error)
 	// DiffConfig checks what impacts a hypothetical change to this provider's configuration will have on the provider.
 	DiffConfig(olds, news resource.PropertyMap) (DiffResult, error)
 	// Configure configures the resource provider with "globals" that control its behavior.
 	Configure(inputs resource.PropertyMap) error
+	// CheckAuth verifies that the provider's configured credentials are valid and grant the access it needs.
+	CheckAuth() error
 
 	// Check validates that the given property bag is valid for a resource of the given type and returns the inputs
 	// that should be passed to successive calls to Diff, Create, or Update for this resource.
@@ -59,6 +65,11 @@ type Provider interface {
 	// resource is missing (for instance, because it has been deleted), the resulting property map will be nil.
 	Read(urn resource.URN, id resource.ID,
 		props resource.PropertyMap) (resource.PropertyMap, resource.Status, error)
+	// ReadIfModified reads a resource just as Read does, unless the provider can tell that the resource has not been
+	// modified since the given time, in which case it returns true and no state.  Providers that cannot tell always
+	// read the resource.
+	ReadIfModified(urn resource.URN, id resource.ID, props resource.PropertyMap,
+		since time.Time) (resource.PropertyMap, bool, resource.Status, error)
 	// Update updates an existing resource with new values.
 	Update(urn resource.URN, id resource.ID,
 		olds resource.PropertyMap, news resource.PropertyMap) (resource.PropertyMap, resource.Status, error)
@@ -66,8 +77,16 @@ type Provider interface {
 	Delete(urn resource.URN, id resource.ID, props resource.PropertyMap) (resource.Status, error)
 	// Invoke dynamically executes a built-in function in the provider.
 	Invoke(tok tokens.ModuleMember, args resource.PropertyMap) (resource.PropertyMap, []CheckFailure, error)
+	// Call dynamically executes a method of an existing resource in the provider.  The resource is passed in the
+	// `__self__` argument.
+	Call(tok tokens.ModuleMember, args resource.PropertyMap) (resource.PropertyMap, []CheckFailure, error)
 	// GetPluginInfo returns this plugin's information.
 	GetPluginInfo() (workspace.PluginInfo, error)
+	// GetSchema returns a JSON description of the resource and function types this provider implements.
+	GetSchema() ([]byte, error)
+	// Discover enumerates the existing resources that the provider's configured account can see and that match the
+	// given filter, so that they may be imported.
+	Discover(filter DiscoverFilter) ([]DiscoveredResource, error)
 
 	// SignalCancellation asks all resource providers to gracefully shut down and abort any ongoing
 	// operations. Operation aborted in this way will return an error (e.g., `Update` and `Create`
@@ -77,10 +96,49 @@ type Provider interface {
 	SignalCancellation() error
 }
 
+// SeededProvider is a provider that can derive any randomness in the inputs that Check returns, such as the suffix of
+// an auto-generated name, from a seed.
+type SeededProvider interface {
+	Provider
+
+	// CheckWithSeed is like Check, but derives any randomness in the inputs from the given seed, so that the same seed
+	// always produces the same inputs.
+	CheckWithSeed(urn resource.URN, olds, news resource.PropertyMap, randomSeed []byte,
+		allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error)
+}
+
+// CheckWithSeed checks a resource's inputs with the given provider, passing it the given random seed if it is a
+// SeededProvider.  Other providers are simply asked to Check the inputs.
+func CheckWithSeed(prov Provider, urn resource.URN, olds, news resource.PropertyMap, randomSeed []byte,
+	allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error) {
+
+	if seeded, ok := prov.(SeededProvider); ok {
+		return seeded.CheckWithSeed(urn, olds, news, randomSeed, allowUnknowns)
+	}
+	return prov.Check(urn, olds, news, allowUnknowns)
+}
+
 // CheckFailure indicates that a call to check failed; it contains the property and reason for the failure.
 type CheckFailure struct {
-	Property resource.PropertyKey // the property that failed checking.
-	Reason   string               // the reason the property failed to check.
+	Property   resource.PropertyKey // the path of the property that failed checking, e.g. `tags[0].key`.
+	Reason     string               // the reason the property failed to check.
+	Suggestion string               // an optional suggested fix for the failure.
+}
+
+// DiscoverFilter restricts the resources that a call to Discover enumerates.  Empty fields match every resource.
+type DiscoverFilter struct {
+	Types         []tokens.Type     // the types of resources to enumerate.
+	Tags          map[string]string // tags that resources must carry, along with their values.
+	ResourceGroup string            // the resource group that resources must belong to.
+	VPC           string            // the VPC (or virtual network) that resources must belong to.
+}
+
+// DiscoveredResource is an existing resource that a call to Discover found.
+type DiscoveredResource struct {
+	Type       tokens.Type          // the resource's type.
+	ID         resource.ID          // the resource's ID, by which it may be read or imported.
+	Name       string               // a name suggested for the resource, e.g. from its tags, if any.
+	Properties resource.PropertyMap // the resource's inputs, as they would be written in a program.
 }
 
 // DiffChanges represents the kind of changes detected by a diff operation.
@@ -97,10 +155,11 @@ const (
 
 // DiffResult indicates whether an operation should replace or update an existing resource.
 type DiffResult struct {
-	Changes             DiffChanges            // true if this diff represents a changed resource.
-	ReplaceKeys         []resource.PropertyKey // an optional list of replacement keys.
-	StableKeys          []resource.PropertyKey // an optional list of property keys that are stable.
-	DeleteBeforeReplace bool                   // if true, this resource must be deleted before recreating it.
+	Changes             DiffChanges                     // true if this diff represents a changed resource.
+	ReplaceKeys         []resource.PropertyKey          // an optional list of replacement keys.
+	ReplaceReasons      map[resource.PropertyKey]string // an optional explanation for each replacement key.
+	StableKeys          []resource.PropertyKey          // an optional list of property keys that are stable.
+	DeleteBeforeReplace bool                            // if true, this resource must be deleted before recreating it.
 }
 
 // Replace returns true if this diff represents a replacement.
@@ -15,8 +15,11 @@
 package plugin
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/blang/semver"
 	pbempty "github.com/golang/protobuf/ptypes/empty"
@@ -25,6 +28,7 @@ import (
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 
+	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
@@ -43,11 +47,40 @@ type provider struct {
 	cfgerr    error                            // non-nil if a configure call fails.
 	cfgknown  bool                             // true if all configuration values are known.
 	cfgdone   chan bool                        // closed when configuration has completed.
+	recording io.Closer                        // the recording or replay of this provider's requests, if any.
 }
 
 // NewProvider attempts to bind to a given package's resource plugin and then creates a gRPC connection to it.  If the
 // plugin could not be found, or an error occurs while creating the child process, an error is returned.
 func NewProvider(host Host, ctx *Context, pkg tokens.Package, version *semver.Version) (Provider, error) {
+	p, err := newProvider(host, ctx, pkg, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// If faults are to be injected, wrap the provider in one that does so.
+	if chaos := ctx.ProviderOptions.Chaos; chaos != nil {
+		return NewChaosProvider(p, chaos), nil
+	}
+	return p, nil
+}
+
+func newProvider(host Host, ctx *Context, pkg tokens.Package, version *semver.Version) (Provider, error) {
+	// If a recording is being replayed, there is no plugin to load.
+	if dir := ctx.ProviderOptions.ReplayDir; dir != "" {
+		conn, replayer, err := newReplayConn(dir, pkg)
+		if err != nil {
+			return nil, err
+		}
+		return &provider{
+			ctx:       ctx,
+			pkg:       pkg,
+			clientRaw: pulumirpc.NewResourceProviderClient(conn),
+			cfgdone:   make(chan bool),
+			recording: replayer,
+		}, nil
+	}
+
 	// Load the plugin's path by using the standard workspace logic.
 	_, path, err := workspace.GetPluginPath(
 		workspace.ResourcePlugin, strings.Replace(string(pkg), tokens.QNameDelimiter, "_", -1), version)
@@ -60,19 +93,28 @@ func NewProvider(host Host, ctx *Context, pkg tokens.Package, version *semver.Ve
 		})
 	}
 
-	plug, err := newPlugin(ctx, path, fmt.Sprintf("%v (resource)", pkg), []string{host.ServerAddr()})
+	plug, err := startPlugin(host, ctx, path, fmt.Sprintf("%v (resource)", pkg), nil, nil)
 	if err != nil {
 		return nil, err
 	}
 	contract.Assertf(plug != nil, "unexpected nil resource plugin for %s", pkg)
 
-	return &provider{
+	p := &provider{
 		ctx:       ctx,
 		pkg:       pkg,
 		plug:      plug,
 		clientRaw: pulumirpc.NewResourceProviderClient(plug.Conn),
 		cfgdone:   make(chan bool),
-	}, nil
+	}
+	if dir := ctx.ProviderOptions.RecordDir; dir != "" {
+		conn, recorder, err := newRecordingConn(plug.Conn, dir, pkg)
+		if err != nil {
+			contract.IgnoreClose(plug)
+			return nil, err
+		}
+		p.clientRaw, p.recording = pulumirpc.NewResourceProviderClient(conn), recorder
+	}
+	return p, nil
 }
 
 func (p *provider) Pkg() tokens.Package { return p.pkg }
@@ -82,11 +124,54 @@ func (p *provider) label() string {
 	return fmt.Sprintf("Provider[%s, %p]", p.pkg, p)
 }
 
+// ParametersKey is the name of the provider resource property that holds the provider's parameters, if any.  Unlike
+// the provider's other properties, the parameters are an object rather than a string, and they are passed to the
+// provider via Parameterize rather than Configure.
+const ParametersKey resource.PropertyKey = "parameters"
+
+// ExpectedAccountKey is the provider configuration variable carrying the cloud account, subscription, or project that
+// the stack expects to be deployed into.  Providers that understand it refuse to be configured with credentials for any
+// other account, which keeps an update from targeting the wrong one.
+const ExpectedAccountKey = "pulumi:expectedAccount"
+
+// Parameterize parameterizes the provider before it is configured.
+func (p *provider) Parameterize(params resource.PropertyMap) error {
+	label := fmt.Sprintf("%s.Parameterize()", p.label())
+	logging.Provider.V(7).Infof("%s executing (#params=%d)", label, len(params))
+
+	mparams, err := MarshalProperties(params, MarshalOptions{Label: fmt.Sprintf("%s.params", label)})
+	if err != nil {
+		return err
+	}
+
+	// Note that we use the raw client here: parameterization necessarily precedes configuration.
+	if _, err = p.clientRaw.Parameterize(p.ctx.Request(), mparams); err != nil {
+		rpcError := rpcerror.Convert(err)
+		logging.Provider.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
+		if rpcError.Code() == codes.Unimplemented {
+			return errors.Errorf("the %s provider does not accept parameters", p.pkg)
+		}
+		return rpcError
+	}
+
+	logging.Provider.V(7).Infof("%s success", label)
+	return nil
+}
+
 // CheckConfig validates the configuration for this resource provider.
 func (p *provider) CheckConfig(olds, news resource.PropertyMap) (resource.PropertyMap, []CheckFailure, error) {
 	// Ensure that all config values are strings or unknowns.
 	var failures []CheckFailure
 	for k, v := range news {
+		if k == ParametersKey {
+			if !v.IsObject() && !v.IsComputed() {
+				failures = append(failures, CheckFailure{
+					Property: k,
+					Reason:   "provider parameters must be an object",
+				})
+			}
+			continue
+		}
 		if !v.IsString() && !v.IsComputed() {
 			failures = append(failures, CheckFailure{
 				Property: k,
@@ -131,6 +216,15 @@ func (p *provider) DiffConfig(olds, news resource.PropertyMap) (DiffResult, erro
 
 	var replaceKeys []resource.PropertyKey
 	for k, v := range news {
+		// A provider's parameters may change the meaning of its resources' properties entirely, so any change to them
+		// requires replacement.
+		if k == ParametersKey {
+			if v.IsComputed() || !v.DeepEquals(olds[k]) {
+				replaceKeys = append(replaceKeys, k)
+			}
+			continue
+		}
+
 		// These are ensured during Check().
 		contract.Assert(v.IsString() || v.IsComputed())
 
@@ -139,6 +233,11 @@ func (p *provider) DiffConfig(olds, news resource.PropertyMap) (DiffResult, erro
 			replaceKeys = append(replaceKeys, k)
 		}
 	}
+	if _, has := news[ParametersKey]; !has {
+		if _, had := olds[ParametersKey]; had {
+			replaceKeys = append(replaceKeys, ParametersKey)
+		}
+	}
 
 	return DiffResult{Changes: DiffUnknown, ReplaceKeys: replaceKeys}, nil
 }
@@ -163,13 +262,13 @@ func (p *provider) ensureConfigured() error {
 // Configure configures the resource provider with "globals" that control its behavior.
 func (p *provider) Configure(inputs resource.PropertyMap) error {
 	label := fmt.Sprintf("%s.Configure()", p.label())
-	logging.V(7).Infof("%s executing (#vars=%d)", label, len(inputs))
+	logging.Provider.V(7).Infof("%s executing (#vars=%d)", label, len(inputs))
 
 	// Convert the inputs to a config map. If any are unknown, do not configure the underlying plugin: instead, leavce
 	// the cfgknown bit unset and carry on.
 	config := make(map[string]string)
 	for k, v := range inputs {
-		if k == "version" {
+		if k == "version" || (k == ParametersKey && !v.IsComputed()) {
 			continue
 		}
 		switch {
@@ -188,13 +287,19 @@ func (p *provider) Configure(inputs resource.PropertyMap) error {
 		}
 	}
 
+	// Let providers that can check their credentials know which account the stack expects.  This variable lives in
+	// the reserved "pulumi" namespace so that it cannot collide with the provider's own configuration.
+	if p.ctx.ExpectedAccount != "" {
+		config[ExpectedAccountKey] = p.ctx.ExpectedAccount
+	}
+
 	// Spawn the configure to happen in parallel.  This ensures that we remain responsive elsewhere that might
 	// want to make forward progress, even as the configure call is happening.
 	go func() {
 		_, err := p.clientRaw.Configure(p.ctx.Request(), &pulumirpc.ConfigureRequest{Variables: config})
 		if err != nil {
 			rpcError := rpcerror.Convert(err)
-			logging.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
+			logging.Provider.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
 			err = createConfigureError(rpcError)
 		}
 		// Acquire the lock, publish the results, and notify any waiters.
@@ -205,11 +310,48 @@ func (p *provider) Configure(inputs resource.PropertyMap) error {
 	return nil
 }
 
+// CheckAuth verifies that the provider's configured credentials are valid and grant the access it needs.
+func (p *provider) CheckAuth() error {
+	label := fmt.Sprintf("%s.CheckAuth()", p.label())
+	logging.Provider.V(7).Infof("%s executing", label)
+
+	// Get the RPC client and ensure it's configured.
+	client, err := p.getClient()
+	if err != nil {
+		return err
+	}
+
+	// If the configuration for this provider was not fully known, there are no credentials to check yet.
+	if !p.cfgknown {
+		return nil
+	}
+
+	if _, err = client.CheckAuth(p.ctx.Request(), &pbempty.Empty{}); err != nil {
+		rpcError := rpcerror.Convert(err)
+		if rpcError.Code() == codes.Unimplemented {
+			// For backwards compatibility, assume the credentials are fine if the provider can't check them.
+			return nil
+		}
+		logging.Provider.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
+		return rpcError
+	}
+
+	logging.Provider.V(7).Infof("%s success", label)
+	return nil
+}
+
 // Check validates that the given property bag is valid for a resource of the given type.
 func (p *provider) Check(urn resource.URN,
 	olds, news resource.PropertyMap, allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error) {
+	return p.CheckWithSeed(urn, olds, news, nil, allowUnknowns)
+}
+
+// CheckWithSeed validates that the given property bag is valid for a resource of the given type, deriving any
+// randomness in the resulting inputs from the given seed.
+func (p *provider) CheckWithSeed(urn resource.URN, olds, news resource.PropertyMap, randomSeed []byte,
+	allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error) {
 	label := fmt.Sprintf("%s.Check(%s)", p.label(), urn)
-	logging.V(7).Infof("%s executing (#olds=%d,#news=%d", label, len(olds), len(news))
+	logging.Provider.V(7).Infof("%s executing (#olds=%d,#news=%d", label, len(olds), len(news))
 
 	// Get the RPC client and ensure it's configured.
 	client, err := p.getClient()
@@ -234,14 +376,21 @@ func (p *provider) Check(urn resource.URN,
 		return nil, nil, err
 	}
 
+	// Providers that generate physical names are told the stack's naming strategy.  A strategy without a suffix is
+	// always sent as verbatim, so that providers can tell it apart from the unset fields of an older engine.
+	naming := p.ctx.AutoNaming
 	resp, err := client.Check(p.ctx.Request(), &pulumirpc.CheckRequest{
-		Urn:  string(urn),
-		Olds: molds,
-		News: mnews,
+		Urn:                    string(urn),
+		Olds:                   molds,
+		News:                   mnews,
+		RandomSeed:             randomSeed,
+		AutonamingSuffixLength: int32(naming.SuffixLength),
+		AutonamingDelimiter:    naming.Delimiter,
+		AutonamingVerbatim:     naming.Verbatim || naming.SuffixLength <= 0,
 	})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
-		logging.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
+		logging.Provider.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
 		return nil, nil, rpcError
 	}
 
@@ -258,10 +407,14 @@ func (p *provider) Check(urn resource.URN,
 	// And now any properties that failed verification.
 	var failures []CheckFailure
 	for _, failure := range resp.GetFailures() {
-		failures = append(failures, CheckFailure{resource.PropertyKey(failure.Property), failure.Reason})
+		failures = append(failures, CheckFailure{
+			Property:   resource.PropertyKey(failure.GetProperty()),
+			Reason:     failure.GetReason(),
+			Suggestion: failure.GetSuggestion(),
+		})
 	}
 
-	logging.V(7).Infof("%s success: inputs=#%d failures=#%d", label, len(inputs), len(failures))
+	logging.Provider.V(7).Infof("%s success: inputs=#%d failures=#%d", label, len(inputs), len(failures))
 	return inputs, failures, nil
 }
 
@@ -274,7 +427,7 @@ func (p *provider) Diff(urn resource.URN, id resource.ID,
 	contract.Assert(olds != nil)
 
 	label := fmt.Sprintf("%s.Diff(%s,%s)", p.label(), urn, id)
-	logging.V(7).Infof("%s: executing (#olds=%d,#news=%d)", label, len(olds), len(news))
+	logging.Provider.V(7).Infof("%s: executing (#olds=%d,#news=%d)", label, len(olds), len(news))
 
 	// Get the RPC client and ensure it's configured.
 	client, err := p.getClient()
@@ -306,7 +459,7 @@ func (p *provider) Diff(urn resource.URN, id resource.ID,
 	})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
-		logging.V(7).Infof("%s failed: %v", label, rpcError.Message())
+		logging.Provider.V(7).Infof("%s failed: %v", label, rpcError.Message())
 		return DiffResult{}, rpcError
 	}
 
@@ -314,17 +467,25 @@ func (p *provider) Diff(urn resource.URN, id resource.ID,
 	for _, replace := range resp.GetReplaces() {
 		replaces = append(replaces, resource.PropertyKey(replace))
 	}
+	var reasons map[resource.PropertyKey]string
+	for k, reason := range resp.GetReplaceReasons() {
+		if reasons == nil {
+			reasons = make(map[resource.PropertyKey]string)
+		}
+		reasons[resource.PropertyKey(k)] = reason
+	}
 	var stables []resource.PropertyKey
 	for _, stable := range resp.GetStables() {
 		stables = append(stables, resource.PropertyKey(stable))
 	}
 	changes := resp.GetChanges()
 	deleteBeforeReplace := resp.GetDeleteBeforeReplace()
-	logging.V(7).Infof("%s success: changes=%d #replaces=%d #stables=%d delbefrepl=%v",
+	logging.Provider.V(7).Infof("%s success: changes=%d #replaces=%d #stables=%d delbefrepl=%v",
 		label, changes, len(replaces), len(stables), deleteBeforeReplace)
 	return DiffResult{
 		Changes:             DiffChanges(changes),
 		ReplaceKeys:         replaces,
+		ReplaceReasons:      reasons,
 		StableKeys:          stables,
 		DeleteBeforeReplace: deleteBeforeReplace,
 	}, nil
@@ -337,7 +498,7 @@ func (p *provider) Create(urn resource.URN, props resource.PropertyMap) (resourc
 	contract.Assert(props != nil)
 
 	label := fmt.Sprintf("%s.Create(%s)", p.label(), urn)
-	logging.V(7).Infof("%s executing (#props=%v)", label, len(props))
+	logging.Provider.V(7).Infof("%s executing (#props=%v)", label, len(props))
 
 	mprops, err := MarshalProperties(props, MarshalOptions{Label: fmt.Sprintf("%s.inputs", label)})
 	if err != nil {
@@ -363,7 +524,7 @@ func (p *provider) Create(urn resource.URN, props resource.PropertyMap) (resourc
 	})
 	if err != nil {
 		resourceStatus, id, liveObject, resourceError = parseError(err)
-		logging.V(7).Infof("%s failed: %v", label, resourceError)
+		logging.Provider.V(7).Infof("%s failed: %v", label, resourceError)
 
 		if resourceStatus != resource.StatusPartialFailure {
 			return "", nil, resourceStatus, resourceError
@@ -384,8 +545,9 @@ func (p *provider) Create(urn resource.URN, props resource.PropertyMap) (resourc
 	if err != nil {
 		return "", nil, resourceStatus, err
 	}
+	outs = p.ctx.Interner.Map(outs)
 
-	logging.V(7).Infof("%s success: id=%s; #outs=%d", label, id, len(outs))
+	logging.Provider.V(7).Infof("%s success: id=%s; #outs=%d", label, id, len(outs))
 	if resourceError == nil {
 		return id, outs, resourceStatus, nil
 	}
@@ -397,57 +559,80 @@ func (p *provider) Create(urn resource.URN, props resource.PropertyMap) (resourc
 func (p *provider) Read(
 	urn resource.URN, id resource.ID, props resource.PropertyMap,
 ) (resource.PropertyMap, resource.Status, error) {
+	results, _, status, err := p.read(urn, id, props, time.Time{})
+	return results, status, err
+}
+
+// ReadIfModified reads a resource just as Read does, unless the provider can tell that the resource has not been
+// modified since the given time.
+func (p *provider) ReadIfModified(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	since time.Time) (resource.PropertyMap, bool, resource.Status, error) {
+	contract.Assert(!since.IsZero())
+	return p.read(urn, id, props, since)
+}
+
+func (p *provider) read(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	since time.Time) (resource.PropertyMap, bool, resource.Status, error) {
 	contract.Assert(urn != "")
 	contract.Assert(id != "")
 
 	label := fmt.Sprintf("%s.Read(%s,%s)", p.label(), id, urn)
-	logging.V(7).Infof("%s executing (#props=%v)", label, len(props))
+	logging.Provider.V(7).Infof("%s executing (#props=%v)", label, len(props))
 
 	// Get the RPC client and ensure it's configured.
 	client, err := p.getClient()
 	if err != nil {
-		return nil, resource.StatusUnknown, err
+		return nil, false, resource.StatusUnknown, err
 	}
 
 	// If the provider is not fully configured, return an empty bag.
 	if !p.cfgknown {
-		return resource.PropertyMap{}, resource.StatusUnknown, nil
+		return resource.PropertyMap{}, false, resource.StatusUnknown, nil
 	}
 
 	// Marshal the input state so we can perform the RPC.
 	marshaled, err := MarshalProperties(props, MarshalOptions{Label: label, ElideAssetContents: true})
 	if err != nil {
-		return nil, resource.StatusUnknown, err
+		return nil, false, resource.StatusUnknown, err
 	}
 
 	// Now issue the read request over RPC, blocking until it finished.
+	req := &pulumirpc.ReadRequest{
+		Id:         string(id),
+		Urn:        string(urn),
+		Properties: marshaled,
+	}
+	if !since.IsZero() {
+		req.ModifiedSince = since.Unix()
+	}
 	var readID resource.ID
 	var liveObject *_struct.Struct
 	var resourceError error
 	var resourceStatus = resource.StatusOK
-	resp, err := client.Read(p.ctx.Request(), &pulumirpc.ReadRequest{
-		Id:         string(id),
-		Urn:        string(urn),
-		Properties: marshaled,
-	})
+	resp, err := client.Read(p.ctx.Request(), req)
 	if err != nil {
 		resourceStatus, readID, liveObject, resourceError = parseError(err)
-		logging.V(7).Infof("%s failed: %v", label, err)
+		logging.Provider.V(7).Infof("%s failed: %v", label, err)
 
 		if resourceStatus != resource.StatusPartialFailure {
-			return nil, resourceStatus, resourceError
+			return nil, false, resourceStatus, resourceError
 		}
 		// Else it's a `StatusPartialFailure`.
 	} else {
+		// If we asked, the provider may report that the resource is unmodified rather than reading it.
+		if !since.IsZero() && resp.GetUnmodified() {
+			logging.Provider.V(7).Infof("%s success; unmodified since %v", label, since)
+			return nil, true, resourceStatus, nil
+		}
 		readID = resource.ID(resp.GetId())
 		liveObject = resp.GetProperties()
 	}
 
 	// If the resource was missing, simply return a nil property map.
 	if string(readID) == "" {
-		return nil, resourceStatus, nil
+		return nil, false, resourceStatus, nil
 	} else if readID != id {
-		return nil, resourceStatus, errors.Errorf(
+		return nil, false, resourceStatus, errors.Errorf(
 			"reading resource %s yielded an unexpected ID; expected %s, got %s", urn, id, readID)
 	}
 
@@ -455,11 +640,12 @@ func (p *provider) Read(
 	results, err := UnmarshalProperties(liveObject, MarshalOptions{
 		Label: fmt.Sprintf("%s.outputs", label), RejectUnknowns: true})
 	if err != nil {
-		return nil, resourceStatus, err
+		return nil, false, resourceStatus, err
 	}
+	results = p.ctx.Interner.Map(results)
 
-	logging.V(7).Infof("%s success; #outs=%d", label, len(results))
-	return results, resourceStatus, resourceError
+	logging.Provider.V(7).Infof("%s success; #outs=%d", label, len(results))
+	return results, false, resourceStatus, resourceError
 }
 
 // Update updates an existing resource with new values.
@@ -471,7 +657,7 @@ func (p *provider) Update(urn resource.URN, id resource.ID,
 	contract.Assert(olds != nil)
 
 	label := fmt.Sprintf("%s.Update(%s,%s)", p.label(), id, urn)
-	logging.V(7).Infof("%s executing (#olds=%v,#news=%v)", label, len(olds), len(news))
+	logging.Provider.V(7).Infof("%s executing (#olds=%v,#news=%v)", label, len(olds), len(news))
 
 	molds, err := MarshalProperties(olds, MarshalOptions{
 		Label: fmt.Sprintf("%s.olds", label), ElideAssetContents: true})
@@ -503,7 +689,7 @@ func (p *provider) Update(urn resource.URN, id resource.ID,
 	})
 	if err != nil {
 		resourceStatus, _, liveObject, resourceError = parseError(err)
-		logging.V(7).Infof("%s failed: %v", label, resourceError)
+		logging.Provider.V(7).Infof("%s failed: %v", label, resourceError)
 
 		if resourceStatus != resource.StatusPartialFailure {
 			return nil, resourceStatus, resourceError
@@ -518,8 +704,9 @@ func (p *provider) Update(urn resource.URN, id resource.ID,
 	if err != nil {
 		return nil, resourceStatus, err
 	}
+	outs = p.ctx.Interner.Map(outs)
 
-	logging.V(7).Infof("%s success; #outs=%d", label, len(outs))
+	logging.Provider.V(7).Infof("%s success; #outs=%d", label, len(outs))
 	if resourceError == nil {
 		return outs, resourceStatus, nil
 	}
@@ -532,7 +719,7 @@ func (p *provider) Delete(urn resource.URN, id resource.ID, props resource.Prope
 	contract.Assert(id != "")
 
 	label := fmt.Sprintf("%s.Delete(%s,%s)", p.label(), urn, id)
-	logging.V(7).Infof("%s executing (#props=%d)", label, len(props))
+	logging.Provider.V(7).Infof("%s executing (#props=%d)", label, len(props))
 
 	mprops, err := MarshalProperties(props, MarshalOptions{Label: label, ElideAssetContents: true})
 	if err != nil {
@@ -554,11 +741,11 @@ func (p *provider) Delete(urn resource.URN, id resource.ID, props resource.Prope
 		Properties: mprops,
 	}); err != nil {
 		resourceStatus, rpcErr := resourceStateAndError(err)
-		logging.V(7).Infof("%s failed: %v", label, rpcErr)
+		logging.Provider.V(7).Infof("%s failed: %v", label, rpcErr)
 		return resourceStatus, rpcErr
 	}
 
-	logging.V(7).Infof("%s success", label)
+	logging.Provider.V(7).Infof("%s success", label)
 	return resource.StatusOK, nil
 }
 
@@ -568,7 +755,7 @@ func (p *provider) Invoke(tok tokens.ModuleMember, args resource.PropertyMap) (r
 	contract.Assert(tok != "")
 
 	label := fmt.Sprintf("%s.Invoke(%s)", p.label(), tok)
-	logging.V(7).Infof("%s executing (#args=%d)", label, len(args))
+	logging.Provider.V(7).Infof("%s executing (#args=%d)", label, len(args))
 
 	// Get the RPC client and ensure it's configured.
 	client, err := p.getClient()
@@ -589,7 +776,7 @@ func (p *provider) Invoke(tok tokens.ModuleMember, args resource.PropertyMap) (r
 	resp, err := client.Invoke(p.ctx.Request(), &pulumirpc.InvokeRequest{Tok: string(tok), Args: margs})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
-		logging.V(7).Infof("%s failed: %v", label, rpcError.Message())
+		logging.Provider.V(7).Infof("%s failed: %v", label, rpcError.Message())
 		return nil, nil, rpcError
 	}
 
@@ -603,24 +790,83 @@ func (p *provider) Invoke(tok tokens.ModuleMember, args resource.PropertyMap) (r
 	// And now any properties that failed verification.
 	var failures []CheckFailure
 	for _, failure := range resp.GetFailures() {
-		failures = append(failures, CheckFailure{resource.PropertyKey(failure.Property), failure.Reason})
+		failures = append(failures, CheckFailure{
+			Property:   resource.PropertyKey(failure.GetProperty()),
+			Reason:     failure.GetReason(),
+			Suggestion: failure.GetSuggestion(),
+		})
 	}
 
-	logging.V(7).Infof("%s success (#ret=%d,#failures=%d) success", label, len(ret), len(failures))
+	logging.Provider.V(7).Infof("%s success (#ret=%d,#failures=%d) success", label, len(ret), len(failures))
+	return ret, failures, nil
+}
+
+// Call dynamically executes a method of an existing resource in the provider.
+func (p *provider) Call(tok tokens.ModuleMember, args resource.PropertyMap) (resource.PropertyMap,
+	[]CheckFailure, error) {
+	contract.Assert(tok != "")
+
+	label := fmt.Sprintf("%s.Call(%s)", p.label(), tok)
+	logging.Provider.V(7).Infof("%s executing (#args=%d)", label, len(args))
+
+	// Get the RPC client and ensure it's configured.
+	client, err := p.getClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// If the provider is not fully configured, return an empty property map.
+	if !p.cfgknown {
+		return resource.PropertyMap{}, nil, nil
+	}
+
+	margs, err := MarshalProperties(args, MarshalOptions{Label: fmt.Sprintf("%s.args", label)})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := client.Call(p.ctx.Request(), &pulumirpc.InvokeRequest{Tok: string(tok), Args: margs})
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		logging.Provider.V(7).Infof("%s failed: %v", label, rpcError.Message())
+		if rpcError.Code() == codes.Unimplemented {
+			return nil, nil, errors.Errorf("the %s provider does not support calling resource methods", p.pkg)
+		}
+		return nil, nil, rpcError
+	}
+
+	// Unmarshal any return values.
+	ret, err := UnmarshalProperties(resp.GetReturn(), MarshalOptions{
+		Label: fmt.Sprintf("%s.returns", label), RejectUnknowns: true})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// And now any properties that failed verification.
+	var failures []CheckFailure
+	for _, failure := range resp.GetFailures() {
+		failures = append(failures, CheckFailure{
+			Property:   resource.PropertyKey(failure.GetProperty()),
+			Reason:     failure.GetReason(),
+			Suggestion: failure.GetSuggestion(),
+		})
+	}
+
+	logging.Provider.V(7).Infof("%s success (#ret=%d,#failures=%d) success", label, len(ret), len(failures))
 	return ret, failures, nil
 }
 
 // GetPluginInfo returns this plugin's information.
 func (p *provider) GetPluginInfo() (workspace.PluginInfo, error) {
 	label := fmt.Sprintf("%s.GetPluginInfo()", p.label())
-	logging.V(7).Infof("%s executing", label)
+	logging.Provider.V(7).Infof("%s executing", label)
 
 	// Calling GetPluginInfo happens immediately after loading, and does not require configuration to proceed.
 	// Thus, we access the clientRaw property, rather than calling getClient.
 	resp, err := p.clientRaw.GetPluginInfo(p.ctx.Request(), &pbempty.Empty{})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
-		logging.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
+		logging.Provider.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
 		return workspace.PluginInfo{}, rpcError
 	}
 
@@ -633,19 +879,123 @@ func (p *provider) GetPluginInfo() (workspace.PluginInfo, error) {
 		version = &sv
 	}
 
+	var path string
+	if p.plug != nil {
+		path = p.plug.Bin
+	}
 	return workspace.PluginInfo{
 		Name:    string(p.pkg),
-		Path:    p.plug.Bin,
+		Path:    path,
 		Kind:    workspace.ResourcePlugin,
 		Version: version,
 	}, nil
 }
 
+// GetSchema returns a JSON description of the resource and function types this provider implements.
+func (p *provider) GetSchema() ([]byte, error) {
+	label := fmt.Sprintf("%s.GetSchema()", p.label())
+	logging.Provider.V(7).Infof("%s executing", label)
+
+	// Like GetPluginInfo, describing the provider does not require configuration, so we use the clientRaw property.
+	resp, err := p.clientRaw.GetSchema(p.ctx.Request(), &pbempty.Empty{})
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		logging.Provider.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
+		if rpcError.Code() == codes.Unimplemented {
+			return nil, errors.Errorf("the %s provider does not support schema introspection", p.pkg)
+		}
+		return nil, rpcError
+	}
+
+	schema, err := UnmarshalProperties(resp, MarshalOptions{Label: fmt.Sprintf("%s.schema", label)})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(schema.Mappable())
+}
+
+// Discover enumerates the existing resources that the provider's configured account can see and that match the given
+// filter.  The request and response are structs; see the Discover RPC for their shapes.
+func (p *provider) Discover(filter DiscoverFilter) ([]DiscoveredResource, error) {
+	label := fmt.Sprintf("%s.Discover()", p.label())
+	logging.Provider.V(7).Infof("%s executing (#types=%d, #tags=%d)", label, len(filter.Types), len(filter.Tags))
+
+	// Get the RPC client and ensure it's configured.
+	client, err := p.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]interface{}, len(filter.Types))
+	for i, t := range filter.Types {
+		types[i] = string(t)
+	}
+	tags := make(map[string]interface{})
+	for k, v := range filter.Tags {
+		tags[k] = v
+	}
+	req, err := MarshalProperties(resource.NewPropertyMapFromMap(map[string]interface{}{
+		"types":         types,
+		"tags":          tags,
+		"resourceGroup": filter.ResourceGroup,
+		"vpc":           filter.VPC,
+	}), MarshalOptions{Label: fmt.Sprintf("%s.filter", label)})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Discover(p.ctx.Request(), req)
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		logging.Provider.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
+		if rpcError.Code() == codes.Unimplemented {
+			return nil, errors.Errorf("the %s provider does not support discovering resources", p.pkg)
+		}
+		return nil, rpcError
+	}
+
+	result, err := UnmarshalProperties(resp, MarshalOptions{
+		Label: fmt.Sprintf("%s.resources", label), RejectUnknowns: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var discovered []DiscoveredResource
+	if resources, has := result["resources"]; has && resources.IsArray() {
+		for i, r := range resources.ArrayValue() {
+			if !r.IsObject() {
+				return nil, errors.Errorf("%s: resource %d is not an object", label, i)
+			}
+			obj := r.ObjectValue()
+			res := DiscoveredResource{Properties: resource.PropertyMap{}}
+			if v, has := obj["type"]; has && v.IsString() {
+				res.Type = tokens.Type(v.StringValue())
+			}
+			if v, has := obj["id"]; has && v.IsString() {
+				res.ID = resource.ID(v.StringValue())
+			}
+			if v, has := obj["name"]; has && v.IsString() {
+				res.Name = v.StringValue()
+			}
+			if v, has := obj["properties"]; has && v.IsObject() {
+				res.Properties = v.ObjectValue()
+			}
+			if res.Type == "" || res.ID == "" {
+				return nil, errors.Errorf("%s: resource %d is missing its type or ID", label, i)
+			}
+			discovered = append(discovered, res)
+		}
+	}
+
+	logging.Provider.V(7).Infof("%s success (#resources=%d)", label, len(discovered))
+	return discovered, nil
+}
+
 func (p *provider) SignalCancellation() error {
 	_, err := p.clientRaw.Cancel(p.ctx.Request(), &pbempty.Empty{})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
-		logging.V(8).Infof("provider received rpc error `%s`: `%s`", rpcError.Code(),
+		logging.Provider.V(8).Infof("provider received rpc error `%s`: `%s`", rpcError.Code(),
 			rpcError.Message())
 		switch rpcError.Code() {
 		case codes.Unimplemented:
@@ -659,6 +1009,12 @@ func (p *provider) SignalCancellation() error {
 
 // Close tears down the underlying plugin RPC connection and process.
 func (p *provider) Close() error {
+	if p.recording != nil {
+		contract.IgnoreClose(p.recording)
+	}
+	if p.plug == nil {
+		return nil
+	}
 	return p.plug.Close()
 }
 
@@ -700,14 +1056,14 @@ func createConfigureError(rpcerr *rpcerror.Error) error {
 // `codes.DataLoss`, or `codes.Unknown` to us.
 func resourceStateAndError(err error) (resource.Status, *rpcerror.Error) {
 	rpcError := rpcerror.Convert(err)
-	logging.V(8).Infof("provider received rpc error `%s`: `%s`", rpcError.Code(), rpcError.Message())
+	logging.Provider.V(8).Infof("provider received rpc error `%s`: `%s`", rpcError.Code(), rpcError.Message())
 	switch rpcError.Code() {
 	case codes.Internal, codes.DataLoss, codes.Unknown:
-		logging.V(8).Infof("rpc error kind `%s` may not be recoverable", rpcError.Code())
+		logging.Provider.V(8).Infof("rpc error kind `%s` may not be recoverable", rpcError.Code())
 		return resource.StatusUnknown, rpcError
 	}
 
-	logging.V(8).Infof("rpc error kind `%s` is well-understood and recoverable", rpcError.Code())
+	logging.Provider.V(8).Infof("rpc error kind `%s` is well-understood and recoverable", rpcError.Code())
 	return resource.StatusOK, rpcError
 }
 
@@ -756,3 +1112,8 @@ func (ie *InitError) Error() string {
 	}
 	return err.Error()
 }
+
+// DiagnosticID returns the stable code for this class of errors.
+func (ie *InitError) DiagnosticID() diag.ID {
+	return diag.ResourceInitFailedID
+}
@@ -0,0 +1,320 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+// rpcRecord is a single recorded request and its response.  Records are written to a recording one per line.
+type rpcRecord struct {
+	Method   string          `json:"method"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Code     uint32          `json:"code,omitempty"` // the gRPC status code, if the request failed.
+	Message  string          `json:"message,omitempty"`
+}
+
+var rpcMarshaler = jsonpb.Marshaler{OrigName: true}
+
+// marshalRPCMessage returns the compact JSON form of an RPC request or response.
+func marshalRPCMessage(msg interface{}) (json.RawMessage, error) {
+	pb, ok := msg.(proto.Message)
+	if !ok {
+		return nil, errors.Errorf("unexpected RPC message of type %T", msg)
+	}
+	s, err := rpcMarshaler.MarshalToString(pb)
+	if err != nil {
+		return nil, err
+	}
+	return compactJSON([]byte(s))
+}
+
+func compactJSON(data []byte) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// redactedConfigValue replaces the value of each of a provider's configuration variables in recordings.
+const redactedConfigValue = "[secret]"
+
+// redactRequest returns the given request with the values that must never be written to a recording removed.  These
+// are the values of the configuration variables with which a provider is configured, which routinely hold
+// credentials.  Replays redact requests in the same way before looking them up, so that they still match.
+func redactRequest(req interface{}) interface{} {
+	if configure, ok := req.(*pulumirpc.ConfigureRequest); ok {
+		redacted := &pulumirpc.ConfigureRequest{Variables: make(map[string]string, len(configure.Variables))}
+		for k := range configure.Variables {
+			redacted.Variables[k] = redactedConfigValue
+		}
+		return redacted
+	}
+	return req
+}
+
+// newVirtualConn returns a client connection that never connects to anything; every call made with it is handled
+// entirely by the given interceptor.
+func newVirtualConn(interceptor grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
+	return grpc.Dial("virtual", grpc.WithInsecure(), grpc.WithUnaryInterceptor(interceptor),
+		grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+			return nil, errors.New("virtual connections cannot be dialed")
+		}))
+}
+
+// nextRecording distinguishes the recordings of several instances of the same provider.
+var nextRecording int32
+
+// rpcRecorder appends the requests made of a single provider instance, and their responses, to a recording.
+type rpcRecorder struct {
+	lock sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+	conn *grpc.ClientConn // the virtual connection through which requests are recorded.
+}
+
+// newRecordingConn returns a connection that forwards every request to conn and records it in a new recording of the
+// given package's provider in dir.
+func newRecordingConn(conn *grpc.ClientConn, dir string, pkg tokens.Package) (*grpc.ClientConn, *rpcRecorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, nil, errors.Wrap(err, "creating recording directory")
+	}
+
+	name := fmt.Sprintf("%s.%d.%d.jsonl", pkg, os.Getpid(), atomic.AddInt32(&nextRecording, 1))
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "creating recording")
+	}
+	r := &rpcRecorder{file: f, enc: json.NewEncoder(f)}
+
+	r.conn, err = newVirtualConn(func(ctx context.Context, method string, req, reply interface{},
+		_ *grpc.ClientConn, _ grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		err := grpc.Invoke(ctx, method, req, reply, conn, opts...)
+		r.record(method, req, reply, err)
+		return err
+	})
+	if err != nil {
+		contract.IgnoreClose(f)
+		return nil, nil, err
+	}
+
+	logging.Provider.V(5).Infof("recording requests made of %s to %s", pkg, f.Name())
+	return r.conn, r, nil
+}
+
+func (r *rpcRecorder) record(method string, req, reply interface{}, err error) {
+	rec := rpcRecord{Method: method}
+
+	var merr error
+	if rec.Request, merr = marshalRPCMessage(redactRequest(req)); merr != nil {
+		logging.Provider.V(5).Infof("could not record request to %s: %v", method, merr)
+		return
+	}
+	if err != nil {
+		s, _ := status.FromError(err)
+		rec.Code, rec.Message = uint32(s.Code()), s.Message()
+	} else if rec.Response, merr = marshalRPCMessage(reply); merr != nil {
+		logging.Provider.V(5).Infof("could not record response from %s: %v", method, merr)
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if werr := r.enc.Encode(rec); werr != nil {
+		logging.Provider.V(5).Infof("could not record %s: %v", method, werr)
+	}
+}
+
+// Close finishes the recording.
+func (r *rpcRecorder) Close() error {
+	contract.IgnoreClose(r.conn)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.file.Close()
+}
+
+// replayRecord is a recorded request that may be replayed.
+type replayRecord struct {
+	rpcRecord
+	urn  string // the URN of the resource that the request concerns, if any.
+	used bool   // true once the record has been replayed.
+}
+
+// rpcReplayer answers the requests made of a provider with the responses from its recordings.
+type rpcReplayer struct {
+	lock    sync.Mutex
+	pkg     tokens.Package
+	records []*replayRecord
+	conn    *grpc.ClientConn // the virtual connection through which requests are answered.
+}
+
+// newReplayConn returns a connection that answers requests with the responses recorded for the given package's
+// provider in dir.
+func newReplayConn(dir string, pkg tokens.Package) (*grpc.ClientConn, *rpcReplayer, error) {
+	r, err := loadRPCReplayer(dir, pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.conn, err = newVirtualConn(r.intercept); err != nil {
+		return nil, nil, err
+	}
+	return r.conn, r, nil
+}
+
+// loadRPCReplayer reads every recording of the given package's provider in dir.
+func loadRPCReplayer(dir string, pkg tokens.Package) (*rpcReplayer, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, string(pkg)+".*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, errors.Errorf("no recording of the %s provider was found in %s", pkg, dir)
+	}
+	sort.Strings(paths)
+
+	r := &rpcReplayer{pkg: pkg}
+	for _, path := range paths {
+		if err = r.load(path); err != nil {
+			return nil, errors.Wrapf(err, "reading recording %s", path)
+		}
+	}
+	return r, nil
+}
+
+func (r *rpcReplayer) load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer contract.IgnoreClose(f)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var rec replayRecord
+		if err = json.Unmarshal(scanner.Bytes(), &rec.rpcRecord); err != nil {
+			return err
+		}
+		if rec.Request, err = compactJSON(rec.Request); err != nil {
+			return err
+		}
+		rec.urn = requestURN(rec.Request)
+		r.records = append(r.records, &rec)
+	}
+	return scanner.Err()
+}
+
+// requestURN returns the URN of the resource that a request concerns, if any.
+func requestURN(request json.RawMessage) string {
+	var fields struct {
+		URN string `json:"urn"`
+	}
+	if err := json.Unmarshal(request, &fields); err != nil {
+		return ""
+	}
+	return fields.URN
+}
+
+func (r *rpcReplayer) intercept(ctx context.Context, method string, req, reply interface{},
+	_ *grpc.ClientConn, _ grpc.UnaryInvoker, _ ...grpc.CallOption) error {
+
+	request, err := marshalRPCMessage(redactRequest(req))
+	if err != nil {
+		return err
+	}
+
+	rec := r.find(method, request)
+	if rec == nil {
+		logging.Provider.V(5).Infof("no recorded response from %s to %s: %s", r.pkg, method, request)
+		return status.Errorf(codes.Unavailable, "the recording of the %s provider has no response to %s", r.pkg, method)
+	}
+	if codes.Code(rec.Code) != codes.OK {
+		return status.Error(codes.Code(rec.Code), rec.Message)
+	}
+
+	pb, ok := reply.(proto.Message)
+	if !ok {
+		return errors.Errorf("unexpected RPC message of type %T", reply)
+	}
+	return jsonpb.UnmarshalString(string(rec.Response), pb)
+}
+
+// find returns the record with which to answer a request.  A record of an identical request is preferred, replaying
+// each at most once so that repeated requests receive their responses in order; once they are exhausted, the last is
+// replayed again.  Failing that, a record of the same method concerning the same resource is used, which allows
+// minor differences in the program being replayed.
+func (r *rpcReplayer) find(method string, request json.RawMessage) *replayRecord {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var reuse *replayRecord
+	for _, rec := range r.records {
+		if rec.Method == method && bytes.Equal(rec.Request, request) {
+			if !rec.used {
+				rec.used = true
+				return rec
+			}
+			reuse = rec
+		}
+	}
+	if reuse != nil {
+		return reuse
+	}
+
+	if urn := requestURN(request); urn != "" {
+		for _, rec := range r.records {
+			if rec.Method == method && rec.urn == urn && !rec.used {
+				rec.used = true
+				return rec
+			}
+		}
+	}
+	return nil
+}
+
+// Close releases the replay's virtual connection.
+func (r *rpcReplayer) Close() error {
+	return r.conn.Close()
+}
@@ -0,0 +1,152 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pbempty "github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recording")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, recorder, err := newRecordingConn(nil, dir, "test")
+	assert.NoError(t, err)
+	recorder.record("/pulumirpc.ResourceProvider/Read",
+		&pulumirpc.ReadRequest{Id: "id1", Urn: "urn:a"}, &pulumirpc.ReadResponse{Id: "id1"}, nil)
+	recorder.record("/pulumirpc.ResourceProvider/Read",
+		&pulumirpc.ReadRequest{Id: "id1", Urn: "urn:a"}, &pulumirpc.ReadResponse{Id: "id2"}, nil)
+	recorder.record("/pulumirpc.ResourceProvider/Delete",
+		&pulumirpc.DeleteRequest{Id: "id1", Urn: "urn:a"}, nil, status.Error(codes.Internal, "oops"))
+	assert.NoError(t, recorder.Close())
+
+	_, replayer, err := newReplayConn(dir, "test")
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, replayer.Close()) }()
+
+	read := func(req *pulumirpc.ReadRequest) (*pulumirpc.ReadResponse, error) {
+		var resp pulumirpc.ReadResponse
+		err := replayer.intercept(context.Background(), "/pulumirpc.ResourceProvider/Read", req, &resp, nil, nil)
+		return &resp, err
+	}
+
+	// Identical requests are answered in the order in which they were recorded, and the last answer is repeated.
+	for _, expected := range []string{"id1", "id2", "id2"} {
+		resp, err := read(&pulumirpc.ReadRequest{Id: "id1", Urn: "urn:a"})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, resp.Id)
+	}
+
+	// Recorded errors are replayed.
+	err = replayer.intercept(context.Background(), "/pulumirpc.ResourceProvider/Delete",
+		&pulumirpc.DeleteRequest{Id: "id1", Urn: "urn:a"}, &pulumirpc.DeleteRequest{}, nil, nil)
+	s, _ := status.FromError(err)
+	assert.Equal(t, codes.Internal, s.Code())
+	assert.Equal(t, "oops", s.Message())
+
+	// Requests for which there is no recording fail.
+	_, err = read(&pulumirpc.ReadRequest{Id: "id3", Urn: "urn:b"})
+	s, _ = status.FromError(err)
+	assert.Equal(t, codes.Unavailable, s.Code())
+
+	_, _, err = newReplayConn(dir, "other")
+	assert.Error(t, err)
+}
+
+func TestReplayMatchesByURN(t *testing.T) {
+	request, err := marshalRPCMessage(&pulumirpc.ReadRequest{Id: "id1", Urn: "urn:a"})
+	assert.NoError(t, err)
+	response, err := marshalRPCMessage(&pulumirpc.ReadResponse{Id: "id1"})
+	assert.NoError(t, err)
+
+	r := &rpcReplayer{records: []*replayRecord{{
+		rpcRecord: rpcRecord{Method: "/pulumirpc.ResourceProvider/Read", Request: request, Response: response},
+		urn:       "urn:a",
+	}}}
+
+	// A request that differs from the recording but concerns the same resource uses the recording, once.
+	other, err := marshalRPCMessage(&pulumirpc.ReadRequest{Id: "id2", Urn: "urn:a"})
+	assert.NoError(t, err)
+	assert.NotNil(t, r.find("/pulumirpc.ResourceProvider/Read", other))
+	assert.Nil(t, r.find("/pulumirpc.ResourceProvider/Read", other))
+}
+
+func TestProviderOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recording")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, recorder, err := newRecordingConn(nil, dir, "test")
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.Close())
+
+	// A context whose options replay a recording loads no plugin, and one with chaos rules injects faults.
+	ctx := &Context{ProviderOptions: ProviderOptions{ReplayDir: dir, Chaos: &ChaosConfig{}}}
+	prov, err := NewProvider(nil, ctx, "test", nil)
+	assert.NoError(t, err)
+	_, isChaos := prov.(*chaosProvider)
+	assert.True(t, isChaos)
+	assert.Equal(t, tokens.Package("test"), prov.Pkg())
+	assert.NoError(t, prov.Close())
+
+	// Without them, the plugin must be installed.
+	_, err = NewProvider(nil, &Context{}, "test-not-installed", nil)
+	assert.Error(t, err)
+}
+
+func TestRecordingRedactsConfiguration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recording")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, recorder, err := newRecordingConn(nil, dir, "test")
+	assert.NoError(t, err)
+	recorder.record("/pulumirpc.ResourceProvider/Configure",
+		&pulumirpc.ConfigureRequest{Variables: map[string]string{"test:config:token": "hunter2"}},
+		&pbempty.Empty{}, nil)
+	assert.NoError(t, recorder.Close())
+
+	// The recording must not contain the configuration's values.
+	paths, err := filepath.Glob(filepath.Join(dir, "test.*.jsonl"))
+	assert.NoError(t, err)
+	if assert.Len(t, paths, 1) {
+		data, err := ioutil.ReadFile(paths[0])
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "hunter2")
+		assert.Contains(t, string(data), "test:config:token")
+	}
+
+	// The redacted request is still replayed, whatever the configuration's values.
+	_, replayer, err := newReplayConn(dir, "test")
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, replayer.Close()) }()
+	err = replayer.intercept(context.Background(), "/pulumirpc.ResourceProvider/Configure",
+		&pulumirpc.ConfigureRequest{Variables: map[string]string{"test:config:token": "hunter3"}},
+		&pbempty.Empty{}, nil, nil)
+	assert.NoError(t, err)
+}
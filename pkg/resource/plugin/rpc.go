@@ -65,11 +65,11 @@ func MarshalProperties(props resource.PropertyMap, opts MarshalOptions) (*struct
 	fields := make(map[string]*structpb.Value)
 	for _, key := range props.StableKeys() {
 		v := props[key]
-		logging.V(9).Infof("Marshaling property for RPC[%s]: %s=%v", opts.Label, key, v)
+		logging.Provider.V(9).Infof("Marshaling property for RPC[%s]: %s=%v", opts.Label, key, v)
 		if v.IsOutput() {
-			logging.V(9).Infof("Skipping output property for RPC[%s]: %v", opts.Label, key)
+			logging.Provider.V(9).Infof("Skipping output property for RPC[%s]: %v", opts.Label, key)
 		} else if opts.SkipNulls && v.IsNull() {
-			logging.V(9).Infof("Skipping null property for RPC[%s]: %s (as requested)", opts.Label, key)
+			logging.Provider.V(9).Infof("Skipping null property for RPC[%s]: %s (as requested)", opts.Label, key)
 		} else {
 			m, err := MarshalPropertyValue(v, opts)
 			if err != nil {
@@ -201,9 +201,9 @@ func UnmarshalProperties(props *structpb.Struct, opts MarshalOptions) (resource.
 		if err != nil {
 			return nil, err
 		} else if v != nil {
-			logging.V(9).Infof("Unmarshaling property for RPC[%s]: %s=%v", opts.Label, key, v)
+			logging.Provider.V(9).Infof("Unmarshaling property for RPC[%s]: %s=%v", opts.Label, key, v)
 			if opts.SkipNulls && v.IsNull() {
-				logging.V(9).Infof("Skipping unmarshaling for RPC[%s]: %s is null", opts.Label, key)
+				logging.Provider.V(9).Infof("Skipping unmarshaling for RPC[%s]: %s is null", opts.Label, key)
 			} else {
 				result[pk] = *v
 			}
@@ -0,0 +1,89 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PropertyPath is a path to a value nested within a property map, such as `tags[0].key`.  Each element is either a
+// string, which names an object property, or an int, which indexes an array.
+type PropertyPath []interface{}
+
+// ParsePropertyPath parses a property path of the form `a.b[0].c`.  A plain property name parses to a path with a
+// single element.
+func ParsePropertyPath(path string) (PropertyPath, error) {
+	var result PropertyPath
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			if len(result) == 0 {
+				return nil, errors.New("property path may not begin with '.'")
+			}
+			path = path[1:]
+		case '[':
+			end := strings.IndexRune(path, ']')
+			if end == -1 {
+				return nil, errors.New("missing closing bracket in property path")
+			}
+			index, err := strconv.Atoi(path[1:end])
+			if err != nil || index < 0 {
+				return nil, errors.Errorf("invalid array index %q in property path", path[1:end])
+			}
+			result, path = append(result, index), path[end+1:]
+			continue
+		}
+
+		end := strings.IndexAny(path, ".[")
+		if end == -1 {
+			end = len(path)
+		}
+		if end == 0 {
+			return nil, errors.New("missing property name in property path")
+		}
+		result, path = append(result, path[:end]), path[end:]
+	}
+	if len(result) == 0 {
+		return nil, errors.New("property path may not be empty")
+	}
+	return result, nil
+}
+
+// Get returns the value at this path within the given property map, if there is one.
+func (p PropertyPath) Get(props PropertyMap) (PropertyValue, bool) {
+	v := NewObjectProperty(props)
+	for _, elem := range p {
+		switch elem := elem.(type) {
+		case string:
+			if !v.IsObject() {
+				return PropertyValue{}, false
+			}
+			child, ok := v.ObjectValue()[PropertyKey(elem)]
+			if !ok {
+				return PropertyValue{}, false
+			}
+			v = child
+		case int:
+			if !v.IsArray() || elem >= len(v.ArrayValue()) {
+				return PropertyValue{}, false
+			}
+			v = v.ArrayValue()[elem]
+		}
+	}
+	return v, true
+}
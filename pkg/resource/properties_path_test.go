@@ -0,0 +1,75 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePropertyPath(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]PropertyPath{
+		"root":            {"root"},
+		"root.nested":     {"root", "nested"},
+		"root[0]":         {"root", 0},
+		"tags[0].key":     {"tags", 0, "key"},
+		"a[1][2].b.c[3]":  {"a", 1, 2, "b", "c", 3},
+		"[0].elem":        {0, "elem"},
+		"with-dash.under": {"with-dash", "under"},
+	}
+	for s, expected := range cases {
+		actual, err := ParsePropertyPath(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, actual, s)
+	}
+
+	for _, s := range []string{"", ".root", "root.", "root..nested", "root[", "root[x]", "root[-1]"} {
+		_, err := ParsePropertyPath(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestPropertyPathGet(t *testing.T) {
+	t.Parallel()
+
+	props := NewPropertyMapFromMap(map[string]interface{}{
+		"name": "b",
+		"tags": []interface{}{
+			map[string]interface{}{"key": "owner", "value": "me"},
+		},
+	})
+
+	get := func(s string) (PropertyValue, bool) {
+		path, err := ParsePropertyPath(s)
+		assert.NoError(t, err)
+		return path.Get(props)
+	}
+
+	v, ok := get("name")
+	assert.True(t, ok)
+	assert.Equal(t, NewStringProperty("b"), v)
+
+	v, ok = get("tags[0].key")
+	assert.True(t, ok)
+	assert.Equal(t, NewStringProperty("owner"), v)
+
+	for _, s := range []string{"missing", "tags[1]", "tags[0].missing", "name.nested", "name[0]"} {
+		_, ok = get(s)
+		assert.False(t, ok, s)
+	}
+}
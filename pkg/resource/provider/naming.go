@@ -0,0 +1,38 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"github.com/pulumi/pulumi/pkg/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+// NamingStrategy returns the stack's strategy for auto-generating physical names, which the engine passes along with
+// each request to check a resource's inputs.  Requests from engines that do not pass one get the default strategy.
+func NamingStrategy(req *pulumirpc.CheckRequest) resource.NamingStrategy {
+	length, delimiter, verbatim := req.GetAutonamingSuffixLength(), req.GetAutonamingDelimiter(),
+		req.GetAutonamingVerbatim()
+	if length == 0 && delimiter == "" && !verbatim {
+		return resource.DefaultNamingStrategy
+	}
+	return resource.NamingStrategy{SuffixLength: int(length), Delimiter: delimiter, Verbatim: verbatim}
+}
+
+// NewName generates a physical name from the given logical name for the resource whose inputs are being checked,
+// following the stack's naming strategy.  Any random suffix is derived from the request's random seed, so that the
+// resource keeps its name until it is replaced.  The result must not exceed maxlen total characters (if > 0).
+func NewName(req *pulumirpc.CheckRequest, logical string, maxlen int) (string, error) {
+	return NamingStrategy(req).NewNameFromSeed(logical, maxlen, req.GetRandomSeed())
+}
@@ -0,0 +1,45 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+func TestNamingStrategy(t *testing.T) {
+	// An engine that predates naming strategies leaves them unset.
+	assert.Equal(t, resource.DefaultNamingStrategy, NamingStrategy(&pulumirpc.CheckRequest{}))
+
+	req := &pulumirpc.CheckRequest{AutonamingSuffixLength: 4, AutonamingDelimiter: "_", RandomSeed: []byte("seed")}
+	assert.Equal(t, resource.NamingStrategy{SuffixLength: 4, Delimiter: "_"}, NamingStrategy(req))
+
+	name, err := NewName(req, "bucket", -1)
+	assert.NoError(t, err)
+	assert.Equal(t, len("bucket_")+4, len(name))
+	assert.True(t, strings.HasPrefix(name, "bucket_"))
+	again, err := NewName(req, "bucket", -1)
+	assert.NoError(t, err)
+	assert.Equal(t, name, again)
+
+	name, err = NewName(&pulumirpc.CheckRequest{AutonamingVerbatim: true}, "bucket", -1)
+	assert.NoError(t, err)
+	assert.Equal(t, "bucket", name)
+}
@@ -16,6 +16,8 @@ package resource
 
 import (
 	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 
 	"github.com/pkg/errors"
@@ -79,6 +81,40 @@ func NewUniqueHex(prefix string, randlen, maxlen int) (string, error) {
 	return prefix + hex.EncodeToString(bs)[:randlen], nil
 }
 
+// NewUniqueHexFromSeed generates a hex string just as NewUniqueHex does, except that its "random" characters are
+// derived from the given seed and prefix, such that the same seed and prefix always produce the same string.  If the
+// seed is empty, the characters are random.
+func NewUniqueHexFromSeed(prefix string, randlen, maxlen int, seed []byte) (string, error) {
+	if len(seed) == 0 {
+		return NewUniqueHex(prefix, randlen, maxlen)
+	}
+	if randlen <= 0 {
+		randlen = 8
+	}
+	if maxlen > 0 && len(prefix)+randlen > maxlen {
+		return "", errors.Errorf(
+			"name '%s' plus %d random chars is longer than maximum length %d", prefix, randlen, maxlen)
+	}
+
+	// Hash the seed, the prefix, and a counter as many times as it takes to produce enough characters.
+	var chars []byte
+	for i := uint32(0); len(chars) < randlen; i++ {
+		var counter [4]byte
+		binary.BigEndian.PutUint32(counter[:], i)
+
+		hash := sha256.New()
+		_, err := hash.Write(seed)
+		contract.AssertNoError(err)
+		_, err = hash.Write([]byte(prefix))
+		contract.AssertNoError(err)
+		_, err = hash.Write(counter[:])
+		contract.AssertNoError(err)
+		chars = append(chars, hex.EncodeToString(hash.Sum(nil))...)
+	}
+
+	return prefix + string(chars[:randlen]), nil
+}
+
 // NewUniqueHexID generates a new "random" hex string for use by resource providers. It will take the optional prefix
 // and append randlen random characters (defaulting to 8 if not > 0).  The result must not exceed maxlen total
 // characterss (if > 0).  Note that capping to maxlen necessarily increases the risk of collisions.
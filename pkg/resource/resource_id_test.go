@@ -85,3 +85,31 @@ func TestNewUniqueDefaultsID(t *testing.T) {
 	assert.Equal(t, len(prefix)+8, len(id))
 	assert.Equal(t, true, strings.HasPrefix(string(id), prefix))
 }
+
+func TestNewUniqueHexFromSeed(t *testing.T) {
+	seed := []byte("seed")
+	id, err := NewUniqueHexFromSeed("prefix", 8, 100, seed)
+	assert.Nil(t, err)
+	assert.Equal(t, len("prefix")+8, len(id))
+	assert.True(t, strings.HasPrefix(id, "prefix"))
+
+	// The same seed and prefix always produce the same string, while a different seed or prefix does not.
+	again, err := NewUniqueHexFromSeed("prefix", 8, 100, seed)
+	assert.Nil(t, err)
+	assert.Equal(t, id, again)
+	other, err := NewUniqueHexFromSeed("prefix", 8, 100, []byte("other"))
+	assert.Nil(t, err)
+	assert.NotEqual(t, id, other)
+	other, err = NewUniqueHexFromSeed("other", 8, 100, seed)
+	assert.Nil(t, err)
+	assert.NotEqual(t, id[len("prefix"):], other[len("other"):])
+
+	// Suffixes longer than a single hash are supported, and extend shorter ones.
+	long, err := NewUniqueHexFromSeed("prefix", 100, -1, seed)
+	assert.Nil(t, err)
+	assert.Equal(t, len("prefix")+100, len(long))
+	assert.True(t, strings.HasPrefix(long, id))
+
+	_, err = NewUniqueHexFromSeed("prefix", 8, 13, seed)
+	assert.NotNil(t, err)
+}
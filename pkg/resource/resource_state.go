@@ -15,6 +15,8 @@
 package resource
 
 import (
+	"time"
+
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 )
@@ -28,14 +30,56 @@ type State struct {
 	Custom       bool        // true if the resource is custom, managed by a plugin.
 	Delete       bool        // true if this resource is pending deletion due to a replacement.
 	ID           ID          // the resource's unique ID, assigned by the resource provider (or blank if none/uncreated).
-	Inputs       PropertyMap // the resource's input properties (as specified by the program).
-	Outputs      PropertyMap // the resource's complete output state (as returned by the resource provider).
 	Parent       URN         // an optional parent URN that this resource belongs to.
 	Protect      bool        // true to "protect" this resource (protected resources cannot be deleted).
 	External     bool        // true if this resource is "external" to Pulumi and we don't control the lifecycle
 	Dependencies []URN       // the resource's dependencies
 	InitErrors   []string    // the set of errors encountered in the process of initializing resource.
 	Provider     string      // the provider to use for this resource.
+	// SourcePosition is an optional `file:line:column` position of the code that allocated this resource.
+	SourcePosition string
+	// Locked is true if this resource may not be updated, replaced, or deleted.  Unlike Protect, which is set by the
+	// program, a resource is locked and unlocked by editing the stack's state.
+	Locked bool
+	// DependsOn is the subset of Dependencies that the program requested explicitly, using the `dependsOn` resource
+	// option, rather than those implied by the resource's input properties.
+	DependsOn []URN
+	// Unreferenced is true if the program did not register this resource during the last update, which therefore
+	// meant to delete it.  Such a resource remains only if its deletion failed or was prevented, and the next update
+	// will try again unless the program declares it once more.
+	Unreferenced bool
+	// Created is the time at which the resource was created, or the zero time if it is not known, e.g. because the
+	// resource was created before creation times were recorded.
+	Created time.Time
+	// Modified is the time at which the resource was last created or updated, or the zero time if it is not known.
+	Modified time.Time
+	// UpdateVersion is the version of the stack that was produced by the update that last created or updated the
+	// resource, or zero if it is not known.
+	UpdateVersion int
+	// AdditionalSecretOutputs names the output properties that the program requested be treated as secret, using the
+	// `additionalSecretOutputs` resource option, whether or not the provider's schema marks them as such.
+	AdditionalSecretOutputs []PropertyKey
+	// ReplaceOnChanges lists the paths of input properties, e.g. `spec.template` or `*` for all of them, whose changes
+	// force the resource to be replaced rather than updated, using the `replaceOnChanges` resource option.
+	ReplaceOnChanges []string
+	// DeleteBeforeReplace is true if the program requested, using the `deleteBeforeReplace` resource option, that
+	// replacements of this resource delete the old resource before creating its replacement, whatever the provider
+	// says.
+	DeleteBeforeReplace bool
+	// RandomSeed is the seed from which the resource's provider derives any randomness, such as the suffix of an
+	// auto-generated name, so that checking the same inputs again produces the same results.  It is chosen when the
+	// resource is created and kept until it is replaced.
+	RandomSeed []byte
+	// InputsHash is a hash of the inputs that the program last gave the resource, before its provider checked them, or
+	// empty if they could not be hashed.  When the program gives it the same inputs again, its provider is not asked
+	// to check or diff them.
+	InputsHash string
+	// ProviderDefaulted lists the input properties whose values the resource's provider filled in, according to its
+	// schema, because the program did not give them when it last gave the resource its inputs.
+	ProviderDefaulted []PropertyKey
+
+	inputs  PropertyMap // the resource's input properties (as specified by the program).
+	outputs PropertyMap // the resource's complete output state (as returned by the resource provider).
 }
 
 // NewState creates a new resource value from existing resource state information.
@@ -51,18 +95,38 @@ func NewState(t tokens.Type, urn URN, custom bool, del bool, id ID,
 		Custom:       custom,
 		Delete:       del,
 		ID:           id,
-		Inputs:       inputs,
-		Outputs:      outputs,
 		Parent:       parent,
 		Protect:      protect,
 		External:     external,
 		Dependencies: dependencies,
 		InitErrors:   initErrors,
 		Provider:     provider,
+		inputs:       inputs,
+		outputs:      outputs,
 	}
 }
 
+// Inputs returns the resource's input properties (as specified by the program).
+func (s *State) Inputs() PropertyMap {
+	return s.inputs
+}
+
+// SetInputs replaces the resource's input properties.
+func (s *State) SetInputs(inputs PropertyMap) {
+	s.inputs = inputs
+}
+
+// Outputs returns the resource's complete output state (as returned by the resource provider).
+func (s *State) Outputs() PropertyMap {
+	return s.outputs
+}
+
+// SetOutputs replaces the resource's output properties.
+func (s *State) SetOutputs(outputs PropertyMap) {
+	s.outputs = outputs
+}
+
 // All returns all resource state, including the inputs and outputs, overlaid in that order.
 func (s *State) All() PropertyMap {
-	return s.Inputs.Merge(s.Outputs)
+	return s.Inputs().Merge(s.Outputs())
 }
@@ -0,0 +1,205 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/glob"
+)
+
+// SecretPathRule marks the values at a property path of the resources of certain types as secret, whether or not the
+// program that declared them knew them to be.  A rule is written `<type>:<path>`, e.g. `*:password` or
+// `aws:iam/accessKey:AccessKey:secret`.  The type is a resource type token, the module of one (e.g. `aws:iam`), or a
+// pattern of either in which `*` matches anything.  The path is a property path, e.g. `connection.password`, in which
+// a property named `*` matches every property of an object, and arrays are looked through, so that `users.password`
+// applies to the password of every element of a `users` array.
+type SecretPathRule struct {
+	Type string       // the pattern of the types of the resources to which the rule applies.
+	Path PropertyPath // the path of the secret values within the resources' properties.
+
+	typ *regexp.Regexp
+}
+
+// ParseSecretPathRule parses a rule of the form `<type>:<path>`.
+func ParseSecretPathRule(s string) (SecretPathRule, error) {
+	colon := strings.LastIndex(s, ":")
+	if colon <= 0 {
+		return SecretPathRule{}, errors.Errorf("secret path '%s' must be of the form <type>:<path>", s)
+	}
+	path, err := ParsePropertyPath(s[colon+1:])
+	if err != nil {
+		return SecretPathRule{}, errors.Wrapf(err, "invalid secret path '%s'", s)
+	}
+	typ := s[:colon]
+	return SecretPathRule{
+		Type: typ,
+		Path: path,
+		typ:  glob.Compile(typ),
+	}, nil
+}
+
+// String returns the rule as it is written.
+func (r SecretPathRule) String() string {
+	var path string
+	for _, elem := range r.Path {
+		switch elem := elem.(type) {
+		case string:
+			if path != "" {
+				path += "."
+			}
+			path += elem
+		case int:
+			path += "[" + strconv.Itoa(elem) + "]"
+		}
+	}
+	return r.Type + ":" + path
+}
+
+// AppliesTo returns true if the rule applies to resources of the given type, because its pattern matches either the
+// type or the type's module.
+func (r SecretPathRule) AppliesTo(typ tokens.Type) bool {
+	if r.typ == nil {
+		return false
+	}
+	return r.typ.MatchString(string(typ)) || r.typ.MatchString(string(typ.Module()))
+}
+
+// SecretPathRules is a set of rules that mark property values as secret.
+type SecretPathRules []SecretPathRule
+
+// ParseSecretPathRules parses each of the given rules.
+func ParseSecretPathRules(rules []string) (SecretPathRules, error) {
+	var result SecretPathRules
+	for _, s := range rules {
+		rule, err := ParseSecretPathRule(s)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// AppliesTo returns true if any of the rules applies to resources of the given type.
+func (rules SecretPathRules) AppliesTo(typ tokens.Type) bool {
+	for _, rule := range rules {
+		if rule.AppliesTo(typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForResource returns the rules that apply to the given resource: these, and one for each of the properties that its
+// `additionalSecretOutputs` option names, which are secret wherever they appear in its inputs or outputs.
+func (rules SecretPathRules) ForResource(res *State) SecretPathRules {
+	if len(res.AdditionalSecretOutputs) == 0 {
+		return rules
+	}
+	result := append(SecretPathRules{}, rules...)
+	typ := regexp.MustCompile("^" + regexp.QuoteMeta(string(res.Type)) + "$")
+	for _, k := range res.AdditionalSecretOutputs {
+		result = append(result, SecretPathRule{Type: string(res.Type), Path: PropertyPath{string(k)}, typ: typ})
+	}
+	return result
+}
+
+// Redact returns the given properties of a resource of the given type with each value that the rules mark as secret
+// replaced by the result of calling replace with it.  Null and computed values are left as they are.  The properties
+// are copied only as much as is needed to replace the secrets in them, and are returned as they are if there are none.
+func (rules SecretPathRules) Redact(typ tokens.Type, props PropertyMap,
+	replace func(v PropertyValue) PropertyValue) PropertyMap {
+
+	for _, rule := range rules {
+		if !rule.AppliesTo(typ) {
+			continue
+		}
+		if v, changed := redactPath(NewObjectProperty(props), rule.Path, replace); changed {
+			props = v.ObjectValue()
+		}
+	}
+	return props
+}
+
+// redactPath replaces the values at the given path within a value, returning a copy of the value and true if any were
+// replaced.
+func redactPath(v PropertyValue, path PropertyPath, replace func(v PropertyValue) PropertyValue) (PropertyValue, bool) {
+	if len(path) == 0 {
+		if v.IsNull() || v.IsComputed() || v.IsOutput() {
+			return v, false
+		}
+		return replace(v), true
+	}
+
+	switch {
+	case v.IsArray():
+		var result []PropertyValue
+		arr := v.ArrayValue()
+		if index, ok := path[0].(int); ok {
+			if index >= len(arr) {
+				return v, false
+			}
+			e, changed := redactPath(arr[index], path[1:], replace)
+			if !changed {
+				return v, false
+			}
+			result = append(result, arr...)
+			result[index] = e
+			return NewArrayProperty(result), true
+		}
+		for i, e := range arr {
+			if e, changed := redactPath(e, path, replace); changed {
+				if result == nil {
+					result = append(result, arr...)
+				}
+				result[i] = e
+			}
+		}
+		if result == nil {
+			return v, false
+		}
+		return NewArrayProperty(result), true
+	case v.IsObject():
+		name, ok := path[0].(string)
+		if !ok {
+			return v, false
+		}
+		var result PropertyMap
+		obj := v.ObjectValue()
+		for k, e := range obj {
+			if name != "*" && string(k) != name {
+				continue
+			}
+			if e, changed := redactPath(e, path[1:], replace); changed {
+				if result == nil {
+					result = obj.Copy()
+				}
+				result[k] = e
+			}
+		}
+		if result == nil {
+			return v, false
+		}
+		return NewObjectProperty(result), true
+	default:
+		return v, false
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestParseSecretPathRule(t *testing.T) {
+	t.Parallel()
+
+	rule, err := ParseSecretPathRule("aws:iam/accessKey:AccessKey:secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "aws:iam/accessKey:AccessKey", rule.Type)
+	assert.Equal(t, PropertyPath{"secret"}, rule.Path)
+	assert.Equal(t, "aws:iam/accessKey:AccessKey:secret", rule.String())
+	assert.True(t, rule.AppliesTo("aws:iam/accessKey:AccessKey"))
+	assert.False(t, rule.AppliesTo("aws:iam/user:User"))
+
+	rule, err = ParseSecretPathRule("aws:iam/accessKey:secret")
+	assert.NoError(t, err)
+	assert.True(t, rule.AppliesTo("aws:iam/accessKey:AccessKey"))
+
+	rule, err = ParseSecretPathRule("*:connection.password")
+	assert.NoError(t, err)
+	assert.Equal(t, PropertyPath{"connection", "password"}, rule.Path)
+	assert.True(t, rule.AppliesTo("aws:rds/instance:Instance"))
+
+	for _, bad := range []string{"password", ":password", "*:", "*:a..b"} {
+		_, err = ParseSecretPathRule(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRedactSecretPaths(t *testing.T) {
+	t.Parallel()
+
+	rules, err := ParseSecretPathRules([]string{"*:password", "my:index:Db:users.secret", "my:index:Db:keys.*"})
+	assert.NoError(t, err)
+
+	props := NewPropertyMapFromMap(map[string]interface{}{
+		"password": "hunter2",
+		"name":     "db",
+		"users": []interface{}{
+			map[string]interface{}{"name": "a", "secret": "s1"},
+			map[string]interface{}{"name": "b"},
+		},
+		"keys": map[string]interface{}{"k1": "v1", "k2": "v2"},
+	})
+	redacted := rules.Redact("my:index:Db", props, func(PropertyValue) PropertyValue {
+		return NewStringProperty("[secret]")
+	})
+	assert.Equal(t, map[string]interface{}{
+		"password": "[secret]",
+		"name":     "db",
+		"users": []interface{}{
+			map[string]interface{}{"name": "a", "secret": "[secret]"},
+			map[string]interface{}{"name": "b"},
+		},
+		"keys": map[string]interface{}{"k1": "[secret]", "k2": "[secret]"},
+	}, redacted.Mappable())
+
+	// The original properties are left alone, and properties without secrets are not copied.
+	assert.Equal(t, "hunter2", props["password"].StringValue())
+	other := NewPropertyMapFromMap(map[string]interface{}{"name": "x"})
+	assert.Equal(t, other, rules.Redact(tokens.Type("my:index:Other"), other, func(PropertyValue) PropertyValue {
+		return NewStringProperty("[secret]")
+	}))
+}
+
+func TestSecretPathRulesForResource(t *testing.T) {
+	t.Parallel()
+
+	rules, err := ParseSecretPathRules([]string{"*:password"})
+	assert.NoError(t, err)
+
+	res := NewState("my:index:Db", "urn:pulumi:dev::proj::my:index:Db::db", true, false, "db",
+		PropertyMap{}, PropertyMap{}, "", false, false, nil, nil, "")
+	assert.Equal(t, rules, rules.ForResource(res))
+
+	res.AdditionalSecretOutputs = []PropertyKey{"token"}
+	forRes := rules.ForResource(res)
+	assert.Len(t, forRes, 2)
+	assert.Len(t, rules, 1)
+	assert.True(t, forRes[1].AppliesTo("my:index:Db"))
+	assert.False(t, forRes[1].AppliesTo("my:index:Other"))
+
+	props := NewPropertyMapFromMap(map[string]interface{}{"token": "t", "password": "p", "name": "db"})
+	redacted := forRes.Redact(res.Type, props, func(PropertyValue) PropertyValue {
+		return NewStringProperty("[secret]")
+	})
+	assert.Equal(t, map[string]interface{}{"token": "[secret]", "password": "[secret]", "name": "db"},
+		redacted.Mappable())
+}
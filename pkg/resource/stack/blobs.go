@@ -0,0 +1,177 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+// BlobSig is the signature of an object that, in place of a large string property value in a checkpoint, refers to
+// the blob that holds the value.
+const BlobSig = "a1d5b3c8e2f74e0b9c6d8f3a7b2e5c91"
+
+// BlobStore holds the blobs that checkpoints refer to.  Blobs are addressed by the hashes of their contents, so a blob
+// that many checkpoints refer to is stored only once.
+type BlobStore interface {
+	// ReadBlob returns the contents of the blob with the given hash.
+	ReadBlob(hash string) ([]byte, error)
+	// WriteBlob stores a blob with the given hash and contents.
+	WriteBlob(hash string, data []byte) error
+}
+
+// blobHashPrefix begins the hash of every blob, naming the algorithm by which it was computed.
+const blobHashPrefix = "sha256:"
+
+// BlobHash returns the hash by which a blob with the given contents is addressed.
+func BlobHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return blobHashPrefix + hex.EncodeToString(sum[:])
+}
+
+// ExternalizeBlobs replaces each string in the given resource's inputs and outputs that is at least threshold bytes
+// long with a reference to a blob that holds it, storing each such blob in the given store.  It does nothing if
+// threshold is not positive.
+func ExternalizeBlobs(res *apitype.ResourceV2, threshold int, store BlobStore) error {
+	if threshold <= 0 {
+		return nil
+	}
+	written := make(map[string]bool)
+	externalize := func(s string) (interface{}, error) {
+		data := []byte(s)
+		hash := BlobHash(data)
+		if !written[hash] {
+			if err := store.WriteBlob(hash, data); err != nil {
+				return nil, errors.Wrapf(err, "storing the value of a property of %s", res.URN)
+			}
+			written[hash] = true
+		}
+		return map[string]interface{}{
+			string(resource.SigKey): BlobSig,
+			"hash":                  hash,
+			"size":                  len(data),
+		}, nil
+	}
+
+	var walk func(v interface{}) (interface{}, error)
+	walk = func(v interface{}) (interface{}, error) {
+		switch v := v.(type) {
+		case string:
+			if len(v) >= threshold {
+				return externalize(v)
+			}
+		case []interface{}:
+			result := make([]interface{}, len(v))
+			for i, e := range v {
+				w, err := walk(e)
+				if err != nil {
+					return nil, err
+				}
+				result[i] = w
+			}
+			return result, nil
+		case map[string]interface{}:
+			result := make(map[string]interface{}, len(v))
+			for k, e := range v {
+				w, err := walk(e)
+				if err != nil {
+					return nil, err
+				}
+				result[k] = w
+			}
+			return result, nil
+		}
+		return v, nil
+	}
+
+	for _, props := range []*map[string]interface{}{&res.Inputs, &res.Outputs} {
+		if *props == nil {
+			continue
+		}
+		w, err := walk(*props)
+		if err != nil {
+			return err
+		}
+		*props = w.(map[string]interface{})
+	}
+	return nil
+}
+
+// ResolveBlobs replaces each reference to a blob in the given deployment's resources with the value that the blob
+// holds, reading the blobs from the given store.
+func ResolveBlobs(d *apitype.DeploymentV2, store BlobStore) error {
+	var walk func(v interface{}) (interface{}, error)
+	walk = func(v interface{}) (interface{}, error) {
+		switch v := v.(type) {
+		case []interface{}:
+			for i, e := range v {
+				w, err := walk(e)
+				if err != nil {
+					return nil, err
+				}
+				v[i] = w
+			}
+		case map[string]interface{}:
+			if v[string(resource.SigKey)] == BlobSig {
+				hash, ok := v["hash"].(string)
+				if !ok || !strings.HasPrefix(hash, blobHashPrefix) {
+					return nil, errors.New("malformed blob reference")
+				}
+				data, err := store.ReadBlob(hash)
+				if err != nil {
+					return nil, errors.Wrapf(err, "reading blob %s", hash)
+				}
+				if BlobHash(data) != hash {
+					return nil, errors.Errorf("blob %s is corrupt", hash)
+				}
+				return string(data), nil
+			}
+			for k, e := range v {
+				w, err := walk(e)
+				if err != nil {
+					return nil, err
+				}
+				v[k] = w
+			}
+		}
+		return v, nil
+	}
+
+	resolve := func(res *apitype.ResourceV2) error {
+		for _, props := range []map[string]interface{}{res.Inputs, res.Outputs} {
+			if _, err := walk(props); err != nil {
+				return errors.Wrapf(err, "resolving the properties of %s", res.URN)
+			}
+		}
+		return nil
+	}
+	for i := range d.Resources {
+		if err := resolve(&d.Resources[i]); err != nil {
+			return err
+		}
+	}
+	for i := range d.PendingOperations {
+		if err := resolve(&d.PendingOperations[i].Resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+type memBlobStore map[string][]byte
+
+func (s memBlobStore) ReadBlob(hash string) ([]byte, error) {
+	data, ok := s[hash]
+	if !ok {
+		return nil, errors.Errorf("no blob %s", hash)
+	}
+	return data, nil
+}
+
+func (s memBlobStore) WriteBlob(hash string, data []byte) error {
+	s[hash] = data
+	return nil
+}
+
+func TestExternalizeBlobs(t *testing.T) {
+	long := "a value that is long enough to be stored in a blob"
+	res := apitype.ResourceV2{
+		URN:     "urn:pulumi:test::test::test:index:resource::a",
+		Inputs:  map[string]interface{}{"short": "abc", "long": long},
+		Outputs: map[string]interface{}{"nested": []interface{}{map[string]interface{}{"long": long}}},
+	}
+
+	// A threshold of zero stores nothing.
+	store := memBlobStore{}
+	assert.NoError(t, ExternalizeBlobs(&res, 0, store))
+	assert.Equal(t, long, res.Inputs["long"])
+	assert.Len(t, store, 0)
+
+	assert.NoError(t, ExternalizeBlobs(&res, 10, store))
+	assert.Equal(t, "abc", res.Inputs["short"])
+	ref := map[string]interface{}{
+		string(resource.SigKey): BlobSig,
+		"hash":                  BlobHash([]byte(long)),
+		"size":                  len(long),
+	}
+	assert.Equal(t, ref, res.Inputs["long"])
+	assert.Equal(t, ref, res.Outputs["nested"].([]interface{})[0].(map[string]interface{})["long"])
+	assert.Len(t, store, 1)
+	assert.Equal(t, long, string(store[BlobHash([]byte(long))]))
+}
+
+func TestCheckpointBlobsRoundTrip(t *testing.T) {
+	snap := newStreamTestSnapshot(t)
+	store := memBlobStore{}
+
+	var actual bytes.Buffer
+	assert.NoError(t, WriteCheckpointWithBlobs(&actual, "test", nil, snap, "    ", 10, store))
+	assert.NotEmpty(t, store)
+	serialized, err := SerializeCheckpointWithBlobs("test", nil, snap, 10, store)
+	assert.NoError(t, err)
+	expected, err := json.MarshalIndent(serialized, "", "    ")
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), actual.String())
+
+	// Once its blobs are resolved, the checkpoint must match one written without them.
+	chk, err := UnmarshalVersionedCheckpointToLatestCheckpoint(actual.Bytes())
+	assert.NoError(t, err)
+	assert.NotEqual(t, SerializeDeployment(snap).Resources, chk.Latest.Resources)
+	assert.NoError(t, ResolveBlobs(chk.Latest, store))
+	b, err := json.Marshal(SerializeCheckpoint("test", nil, snap))
+	assert.NoError(t, err)
+	plain, err := UnmarshalVersionedCheckpointToLatestCheckpoint(b)
+	assert.NoError(t, err)
+	assert.Equal(t, plain.Latest.Resources, chk.Latest.Resources)
+	assert.Equal(t, plain.Latest.PendingOperations, chk.Latest.PendingOperations)
+}
+
+func TestResolveBlobsErrors(t *testing.T) {
+	newDeployment := func(hash string) *apitype.DeploymentV2 {
+		return &apitype.DeploymentV2{Resources: []apitype.ResourceV2{{
+			URN: "urn:pulumi:test::test::test:index:resource::a",
+			Outputs: map[string]interface{}{
+				"value": map[string]interface{}{string(resource.SigKey): BlobSig, "hash": hash},
+			},
+		}}}
+	}
+
+	data := []byte("some data")
+	hash := BlobHash(data)
+
+	// Missing blobs are reported.
+	assert.Error(t, ResolveBlobs(newDeployment(hash), memBlobStore{}))
+
+	// So are blobs whose contents do not match their hashes.
+	err := ResolveBlobs(newDeployment(hash), memBlobStore{hash: []byte("other data")})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "corrupt")
+	}
+
+	// And malformed references.
+	assert.Error(t, ResolveBlobs(newDeployment("md5:abc"), memBlobStore{}))
+
+	d := newDeployment(hash)
+	assert.NoError(t, ResolveBlobs(d, memBlobStore{hash: data}))
+	assert.Equal(t, "some data", d.Resources[0].Outputs["value"])
+}
@@ -71,10 +71,29 @@ func UnmarshalVersionedCheckpointToLatestCheckpoint(bytes []byte) (*apitype.Chec
 
 // SerializeCheckpoint turns a snapshot into a data structure suitable for serialization.
 func SerializeCheckpoint(stack tokens.QName, config config.Map, snap *deploy.Snapshot) *apitype.VersionedCheckpoint {
+	chk, err := SerializeCheckpointWithBlobs(stack, config, snap, 0, nil)
+	contract.AssertNoError(err)
+	return chk
+}
+
+// SerializeCheckpointWithBlobs is like SerializeCheckpoint, except that each string property value that is at least
+// threshold bytes long is stored in the given blob store, and the checkpoint refers to it as ExternalizeBlobs does.
+func SerializeCheckpointWithBlobs(stack tokens.QName, config config.Map, snap *deploy.Snapshot,
+	threshold int, blobs BlobStore) (*apitype.VersionedCheckpoint, error) {
 	// If snap is nil, that's okay, we will just create an empty deployment; otherwise, serialize the whole snapshot.
 	var latest *apitype.DeploymentV2
 	if snap != nil {
 		latest = SerializeDeployment(snap)
+		for i := range latest.Resources {
+			if err := ExternalizeBlobs(&latest.Resources[i], threshold, blobs); err != nil {
+				return nil, err
+			}
+		}
+		for i := range latest.PendingOperations {
+			if err := ExternalizeBlobs(&latest.PendingOperations[i].Resource, threshold, blobs); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	b, err := json.Marshal(apitype.CheckpointV2{
@@ -87,7 +106,7 @@ func SerializeCheckpoint(stack tokens.QName, config config.Map, snap *deploy.Sna
 	return &apitype.VersionedCheckpoint{
 		Version:    apitype.DeploymentSchemaVersionCurrent,
 		Checkpoint: json.RawMessage(b),
-	}
+	}, nil
 }
 
 // DeserializeCheckpoint takes a serialized deployment record and returns its associated snapshot. Returns nil
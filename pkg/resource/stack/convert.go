@@ -0,0 +1,203 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/apitype/migrate"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+)
+
+// checkSchemaVersion returns an error if deployments of the given schema version can be neither read nor written.
+func checkSchemaVersion(version int) error {
+	switch {
+	case version > apitype.DeploymentSchemaVersionCurrent:
+		return ErrDeploymentSchemaVersionTooNew
+	case version < DeploymentSchemaVersionOldestSupported:
+		return ErrDeploymentSchemaVersionTooOld
+	}
+	return nil
+}
+
+// ConvertUntypedDeployment converts a deployment, such as one produced by `pulumi stack export`, to the given schema
+// version.  Converting to an older version fails if the deployment uses features that the older version cannot
+// represent.  A deployment that is already of the given version is returned unchanged.
+func ConvertUntypedDeployment(deployment *apitype.UntypedDeployment,
+	version int) (*apitype.UntypedDeployment, error) {
+
+	if err := checkSchemaVersion(version); err != nil {
+		return nil, err
+	}
+	if err := checkSchemaVersion(deployment.Version); err != nil {
+		return nil, err
+	}
+	if deployment.Version == version {
+		return deployment, nil
+	}
+
+	// Bring the deployment up to the current version, and then take it down to the one that was asked for.
+	var v2deployment apitype.DeploymentV2
+	switch deployment.Version {
+	case 1:
+		var v1deployment apitype.DeploymentV1
+		if err := unmarshalExact(deployment.Deployment, &v1deployment); err != nil {
+			return nil, err
+		}
+		v2deployment = migrate.UpToDeploymentV2(v1deployment)
+	case 2:
+		if err := unmarshalExact(deployment.Deployment, &v2deployment); err != nil {
+			return nil, err
+		}
+	}
+
+	var result interface{} = v2deployment
+	if version == 1 {
+		v1deployment, err := migrate.DownToDeploymentV1(v2deployment)
+		if err != nil {
+			return nil, err
+		}
+		result = v1deployment
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return &apitype.UntypedDeployment{Version: version, Deployment: json.RawMessage(b)}, nil
+}
+
+// ConvertCheckpoint converts a serialized checkpoint, versioned or not, to a checkpoint of the given schema version.
+// Converting to an older version fails if the checkpoint uses features that the older version cannot represent.
+func ConvertCheckpoint(bytes []byte, version int) (*apitype.VersionedCheckpoint, error) {
+	if err := checkSchemaVersion(version); err != nil {
+		return nil, err
+	}
+
+	// A checkpoint that is already of the given version is left as it is.
+	var versionedCheckpoint apitype.VersionedCheckpoint
+	if err := json.Unmarshal(bytes, &versionedCheckpoint); err != nil {
+		return nil, err
+	}
+	if versionedCheckpoint.Version == version {
+		return &versionedCheckpoint, nil
+	}
+
+	// Bring the checkpoint up to the current version, and then take it down to the one that was asked for.  This
+	// mirrors UnmarshalVersionedCheckpointToLatestCheckpoint, but keeps the numbers in resources' properties exact.
+	var v2checkpoint apitype.CheckpointV2
+	switch versionedCheckpoint.Version {
+	case 0:
+		var v1checkpoint apitype.CheckpointV1
+		if err := unmarshalExact(bytes, &v1checkpoint); err != nil {
+			return nil, err
+		}
+		v2checkpoint = migrate.UpToCheckpointV2(v1checkpoint)
+	case 1:
+		var v1checkpoint apitype.CheckpointV1
+		if err := unmarshalExact(versionedCheckpoint.Checkpoint, &v1checkpoint); err != nil {
+			return nil, err
+		}
+		v2checkpoint = migrate.UpToCheckpointV2(v1checkpoint)
+	case 2:
+		if err := unmarshalExact(versionedCheckpoint.Checkpoint, &v2checkpoint); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unsupported checkpoint version %d", versionedCheckpoint.Version)
+	}
+
+	var result interface{} = v2checkpoint
+	if version == 1 {
+		v1checkpoint, err := migrate.DownToCheckpointV1(v2checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		result = v1checkpoint
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return &apitype.VersionedCheckpoint{Version: version, Checkpoint: json.RawMessage(b)}, nil
+}
+
+// unmarshalExact unmarshals JSON into v, decoding the numbers within untyped values as json.Numbers rather than as
+// floats, so that they are written back out exactly as they were read.
+func unmarshalExact(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// plaintextKey is the key of the object that holds a decrypted secret in a checkpoint's configuration, in place of the
+// object of the form {"secure": "<ciphertext>"} that holds it encrypted.
+const plaintextKey = "plaintext"
+
+// DecryptCheckpointSecrets decrypts each secure value in a serialized checkpoint's configuration, replacing it with an
+// object of the form {"plaintext": "<value>"}, so that the checkpoint may be read by tools that do not hold the stack's
+// key.  Such a checkpoint may not be used by Pulumi until it has been encrypted again by EncryptCheckpointSecrets.
+func DecryptCheckpointSecrets(bytes []byte, decrypter config.Decrypter) ([]byte, error) {
+	return transformCheckpointConfig(bytes, "secure", plaintextKey, decrypter.DecryptValue)
+}
+
+// EncryptCheckpointSecrets encrypts each value in a serialized checkpoint's configuration that was decrypted by
+// DecryptCheckpointSecrets, replacing it with an object of the form {"secure": "<ciphertext>"}.
+func EncryptCheckpointSecrets(bytes []byte, encrypter config.Encrypter) ([]byte, error) {
+	return transformCheckpointConfig(bytes, plaintextKey, "secure", encrypter.EncryptValue)
+}
+
+// transformCheckpointConfig replaces each value in a serialized checkpoint's configuration that is an object with the
+// single string property from by an object with the single property to, whose value is the result of transforming the
+// original.  Versioned checkpoints hold their configuration within their inner checkpoint, and unversioned ones at
+// their root.
+func transformCheckpointConfig(b []byte, from, to string, transform func(string) (string, error)) ([]byte, error) {
+	var root map[string]interface{}
+	if err := unmarshalExact(b, &root); err != nil {
+		return nil, err
+	}
+
+	checkpoint := root
+	if inner, has := root["checkpoint"]; has {
+		var ok bool
+		if checkpoint, ok = inner.(map[string]interface{}); !ok {
+			return nil, errors.New("malformed checkpoint: expected an object")
+		}
+	}
+
+	cfg, _ := checkpoint["config"].(map[string]interface{})
+	for key, value := range cfg {
+		obj, ok := value.(map[string]interface{})
+		if !ok || len(obj) != 1 {
+			continue
+		}
+		s, ok := obj[from].(string)
+		if !ok {
+			continue
+		}
+		result, err := transform(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "transforming the value of %s", key)
+		}
+		cfg[key] = map[string]interface{}{to: result}
+	}
+
+	return json.Marshal(root)
+}
@@ -0,0 +1,123 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+)
+
+func TestConvertCheckpoint(t *testing.T) {
+	bytes, err := ioutil.ReadFile("testdata/checkpoint-v1.json")
+	assert.NoError(t, err)
+
+	v2, err := ConvertCheckpoint(bytes, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v2.Version)
+	v2bytes, err := json.Marshal(v2)
+	assert.NoError(t, err)
+	chk, err := UnmarshalVersionedCheckpointToLatestCheckpoint(v2bytes)
+	assert.NoError(t, err)
+	assert.Len(t, chk.Latest.Resources, 30)
+
+	// Converting back down yields a version 1 checkpoint with the same resources.
+	v1, err := ConvertCheckpoint(v2bytes, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v1.Version)
+	var v1checkpoint apitype.CheckpointV1
+	assert.NoError(t, json.Unmarshal(v1.Checkpoint, &v1checkpoint))
+	assert.Len(t, v1checkpoint.Latest.Resources, 30)
+
+	// Unversioned checkpoints may be converted too.
+	bytes, err = ioutil.ReadFile("testdata/checkpoint-v0.json")
+	assert.NoError(t, err)
+	v2, err = ConvertCheckpoint(bytes, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v2.Version)
+
+	_, err = ConvertCheckpoint(bytes, apitype.DeploymentSchemaVersionCurrent+1)
+	assert.Equal(t, ErrDeploymentSchemaVersionTooNew, err)
+}
+
+func TestConvertUntypedDeployment(t *testing.T) {
+	v2deployment := apitype.DeploymentV2{
+		Resources: []apitype.ResourceV2{
+			{URN: resource.URN("urn:pulumi:test::test::pulumi:pulumi:Stack::test-test"), Type: "pulumi:pulumi:Stack"},
+		},
+	}
+	b, err := json.Marshal(v2deployment)
+	assert.NoError(t, err)
+	deployment := &apitype.UntypedDeployment{Version: 2, Deployment: json.RawMessage(b)}
+
+	same, err := ConvertUntypedDeployment(deployment, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, deployment, same)
+
+	v1, err := ConvertUntypedDeployment(deployment, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v1.Version)
+	snap, err := DeserializeUntypedDeployment(v1)
+	assert.NoError(t, err)
+	assert.Len(t, snap.Resources, 1)
+
+	// Resources that version 1 cannot represent prevent the conversion.
+	v2deployment.Resources[0].External = true
+	b, err = json.Marshal(v2deployment)
+	assert.NoError(t, err)
+	_, err = ConvertUntypedDeployment(&apitype.UntypedDeployment{Version: 2, Deployment: json.RawMessage(b)}, 1)
+	assert.Error(t, err)
+}
+
+func TestCheckpointSecrets(t *testing.T) {
+	crypter := config.NewSymmetricCrypter(make([]byte, config.SymmetricCrypterKeyBytes))
+	ciphertext, err := crypter.EncryptValue("hunter2")
+	assert.NoError(t, err)
+
+	cfg := config.Map{
+		config.MustMakeKey("test", "plain"):  config.NewValue("value"),
+		config.MustMakeKey("test", "secret"): config.NewSecureValue(ciphertext),
+	}
+	chk := SerializeCheckpoint("test", cfg, nil)
+	bytes, err := json.Marshal(chk)
+	assert.NoError(t, err)
+
+	decrypted, err := DecryptCheckpointSecrets(bytes, crypter)
+	assert.NoError(t, err)
+	var plain struct {
+		Checkpoint struct {
+			Config map[string]interface{} `json:"config"`
+		} `json:"checkpoint"`
+	}
+	assert.NoError(t, json.Unmarshal(decrypted, &plain))
+	assert.Equal(t, "value", plain.Checkpoint.Config["test:plain"])
+	assert.Equal(t, map[string]interface{}{"plaintext": "hunter2"}, plain.Checkpoint.Config["test:secret"])
+
+	encrypted, err := EncryptCheckpointSecrets(decrypted, crypter)
+	assert.NoError(t, err)
+	loaded, err := UnmarshalVersionedCheckpointToLatestCheckpoint(encrypted)
+	assert.NoError(t, err)
+	secret := loaded.Config[config.MustMakeKey("test", "secret")]
+	assert.True(t, secret.Secure())
+	value, err := secret.Value(crypter)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+}
@@ -18,8 +18,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/blang/semver"
+	"github.com/pkg/errors"
 	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/apitype/migrate"
 	"github.com/pulumi/pulumi/pkg/resource"
@@ -51,23 +53,7 @@ func SerializeDeployment(snap *deploy.Snapshot) *apitype.DeploymentV2 {
 	contract.Require(snap != nil, "snap")
 
 	// Capture the version information into a manifest.
-	manifest := apitype.ManifestV1{
-		Time:    snap.Manifest.Time,
-		Magic:   snap.Manifest.Magic,
-		Version: snap.Manifest.Version,
-	}
-	for _, plug := range snap.Manifest.Plugins {
-		var version string
-		if plug.Version != nil {
-			version = plug.Version.String()
-		}
-		manifest.Plugins = append(manifest.Plugins, apitype.PluginInfoV1{
-			Name:    plug.Name,
-			Path:    plug.Path,
-			Type:    plug.Kind,
-			Version: version,
-		})
-	}
+	manifest := serializeManifest(snap.Manifest)
 
 	// Serialize all vertices and only include a vertex section if non-empty.
 	var resources []apitype.ResourceV2
@@ -87,6 +73,30 @@ func SerializeDeployment(snap *deploy.Snapshot) *apitype.DeploymentV2 {
 	}
 }
 
+// serializeManifest captures a snapshot's version information into a serializable manifest.
+func serializeManifest(m deploy.Manifest) apitype.ManifestV1 {
+	manifest := apitype.ManifestV1{
+		Time:    m.Time,
+		Magic:   m.Magic,
+		Version: m.Version,
+		OS:      m.OS,
+		Arch:    m.Arch,
+	}
+	for _, plug := range m.Plugins {
+		var version string
+		if plug.Version != nil {
+			version = plug.Version.String()
+		}
+		manifest.Plugins = append(manifest.Plugins, apitype.PluginInfoV1{
+			Name:    plug.Name,
+			Path:    plug.Path,
+			Type:    plug.Kind,
+			Version: version,
+		})
+	}
+	return manifest
+}
+
 // DeserializeUntypedDeployment deserializes an untyped deployment and produces a `deploy.Snapshot`
 // from it. DeserializeDeployment will return an error if the untyped deployment's version is
 // not within the range `DeploymentSchemaVersionCurrent` and `DeploymentSchemaVersionOldestSupported`.
@@ -121,32 +131,18 @@ func DeserializeUntypedDeployment(deployment *apitype.UntypedDeployment) (*deplo
 
 // DeserializeDeploymentV2 deserializes a typed DeploymentV2 into a `deploy.Snapshot`.
 func DeserializeDeploymentV2(deployment apitype.DeploymentV2) (*deploy.Snapshot, error) {
-	// Unpack the versions.
-	manifest := deploy.Manifest{
-		Time:    deployment.Manifest.Time,
-		Magic:   deployment.Manifest.Magic,
-		Version: deployment.Manifest.Version,
-	}
-	for _, plug := range deployment.Manifest.Plugins {
-		var version *semver.Version
-		if v := plug.Version; v != "" {
-			sv, err := semver.ParseTolerant(v)
-			if err != nil {
-				return nil, err
-			}
-			version = &sv
-		}
-		manifest.Plugins = append(manifest.Plugins, workspace.PluginInfo{
-			Name:    plug.Name,
-			Kind:    plug.Type,
-			Version: version,
-		})
+	manifest, err := deserializeManifest(deployment.Manifest)
+	if err != nil {
+		return nil, err
 	}
 
+	// Share a single interner among all of the deployment's resources, so that identical values are only kept once.
+	interner := resource.NewPropertyInterner()
+
 	// For every serialized resource vertex, create a ResourceDeployment out of it.
 	var resources []*resource.State
 	for _, res := range deployment.Resources {
-		desres, err := DeserializeResource(res)
+		desres, err := deserializeResource(res, interner)
 		if err != nil {
 			return nil, err
 		}
@@ -155,16 +151,43 @@ func DeserializeDeploymentV2(deployment apitype.DeploymentV2) (*deploy.Snapshot,
 
 	var ops []resource.Operation
 	for _, op := range deployment.PendingOperations {
-		desop, err := DeserializeOperation(op)
+		desres, err := deserializeResource(op.Resource, interner)
 		if err != nil {
 			return nil, err
 		}
-		ops = append(ops, desop)
+		ops = append(ops, resource.NewOperation(desres, resource.OperationType(op.Type)))
 	}
 
 	return deploy.NewSnapshot(manifest, resources, ops), nil
 }
 
+// deserializeManifest unpacks the version information in a deployment's manifest.
+func deserializeManifest(m apitype.ManifestV1) (deploy.Manifest, error) {
+	manifest := deploy.Manifest{
+		Time:    m.Time,
+		Magic:   m.Magic,
+		Version: m.Version,
+		OS:      m.OS,
+		Arch:    m.Arch,
+	}
+	for _, plug := range m.Plugins {
+		var version *semver.Version
+		if v := plug.Version; v != "" {
+			sv, err := semver.ParseTolerant(v)
+			if err != nil {
+				return deploy.Manifest{}, err
+			}
+			version = &sv
+		}
+		manifest.Plugins = append(manifest.Plugins, workspace.PluginInfo{
+			Name:    plug.Name,
+			Kind:    plug.Type,
+			Version: version,
+		})
+	}
+	return manifest, nil
+}
+
 // SerializeResource turns a resource into a structure suitable for serialization.
 func SerializeResource(res *resource.State) apitype.ResourceV2 {
 	contract.Assert(res != nil)
@@ -172,31 +195,63 @@ func SerializeResource(res *resource.State) apitype.ResourceV2 {
 
 	// Serialize all input and output properties recursively, and add them if non-empty.
 	var inputs map[string]interface{}
-	if inp := res.Inputs; inp != nil {
+	if inp := res.Inputs(); inp != nil {
 		inputs = SerializeProperties(inp)
 	}
 	var outputs map[string]interface{}
-	if outp := res.Outputs; outp != nil {
+	if outp := res.Outputs(); outp != nil {
 		outputs = SerializeProperties(outp)
 	}
+	var created, modified *time.Time
+	if !res.Created.IsZero() {
+		created = &res.Created
+	}
+	if !res.Modified.IsZero() {
+		modified = &res.Modified
+	}
 
 	return apitype.ResourceV2{
-		URN:          res.URN,
-		Custom:       res.Custom,
-		Delete:       res.Delete,
-		ID:           res.ID,
-		Type:         res.Type,
-		Parent:       res.Parent,
-		Inputs:       inputs,
-		Outputs:      outputs,
-		Protect:      res.Protect,
-		External:     res.External,
-		Dependencies: res.Dependencies,
-		InitErrors:   res.InitErrors,
-		Provider:     res.Provider,
+		URN:            res.URN,
+		Custom:         res.Custom,
+		Delete:         res.Delete,
+		ID:             res.ID,
+		Type:           res.Type,
+		Parent:         res.Parent,
+		Inputs:         inputs,
+		Outputs:        outputs,
+		Protect:        res.Protect,
+		External:       res.External,
+		Dependencies:   res.Dependencies,
+		InitErrors:     res.InitErrors,
+		Provider:       res.Provider,
+		SourcePosition: res.SourcePosition,
+		Locked:         res.Locked,
+		DependsOn:      res.DependsOn,
+		Unreferenced:   res.Unreferenced,
+		Created:        created,
+		Modified:       modified,
+		UpdateVersion:  res.UpdateVersion,
+
+		AdditionalSecretOutputs: serializeKeys(res.AdditionalSecretOutputs),
+		ReplaceOnChanges:        res.ReplaceOnChanges,
+		DeleteBeforeReplace:     res.DeleteBeforeReplace,
+		RandomSeed:              res.RandomSeed,
+		InputsHash:              res.InputsHash,
+		ProviderDefaulted:       serializeKeys(res.ProviderDefaulted),
 	}
 }
 
+func serializeKeys(keys []resource.PropertyKey) []string {
+	if len(keys) == 0 {
+		return nil
+	}
+	result := make([]string, len(keys))
+	for i, k := range keys {
+		result[i] = string(k)
+	}
+	return result
+}
+
 func SerializeOperation(op resource.Operation) apitype.OperationV1 {
 	res := SerializeResource(op.Resource)
 	return apitype.OperationV1{
@@ -252,19 +307,47 @@ func SerializePropertyValue(prop resource.PropertyValue) interface{} {
 
 // DeserializeResource turns a serialized resource back into its usual form.
 func DeserializeResource(res apitype.ResourceV2) (*resource.State, error) {
+	return deserializeResource(res, nil)
+}
+
+// deserializeResource turns a serialized resource back into its usual form, interning its properties with the given
+// interner (if any).
+func deserializeResource(res apitype.ResourceV2, interner *resource.PropertyInterner) (*resource.State, error) {
 	// Deserialize the resource properties, if they exist.
 	inputs, err := DeserializeProperties(res.Inputs)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrapf(err, "decoding the inputs of %s", res.URN)
 	}
 	outputs, err := DeserializeProperties(res.Outputs)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrapf(err, "decoding the outputs of %s", res.URN)
 	}
 
-	return resource.NewState(
+	state := resource.NewState(
 		res.Type, res.URN, res.Custom, res.Delete, res.ID,
-		inputs, outputs, res.Parent, res.Protect, res.External, res.Dependencies, res.InitErrors, res.Provider), nil
+		interner.Map(inputs), interner.Map(outputs), res.Parent, res.Protect, res.External, res.Dependencies,
+		res.InitErrors, res.Provider)
+	state.SourcePosition = res.SourcePosition
+	state.Locked = res.Locked
+	state.DependsOn = res.DependsOn
+	state.Unreferenced = res.Unreferenced
+	if res.Created != nil {
+		state.Created = *res.Created
+	}
+	if res.Modified != nil {
+		state.Modified = *res.Modified
+	}
+	state.UpdateVersion = res.UpdateVersion
+	for _, k := range res.AdditionalSecretOutputs {
+		state.AdditionalSecretOutputs = append(state.AdditionalSecretOutputs, resource.PropertyKey(k))
+	}
+	state.ReplaceOnChanges = res.ReplaceOnChanges
+	state.DeleteBeforeReplace = res.DeleteBeforeReplace
+	state.RandomSeed, state.InputsHash = res.RandomSeed, res.InputsHash
+	for _, k := range res.ProviderDefaulted {
+		state.ProviderDefaulted = append(state.ProviderDefaulted, resource.PropertyKey(k))
+	}
+	return state, nil
 }
 
 func DeserializeOperation(op apitype.OperationV1) (resource.Operation, error) {
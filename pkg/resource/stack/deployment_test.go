@@ -15,13 +15,19 @@
 package stack
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/blang/semver"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 // TestDeploymentSerialization creates a basic snapshot of a given resource state.
@@ -77,6 +83,17 @@ func TestDeploymentSerialization(t *testing.T) {
 		[]string{},
 		"",
 	)
+	res.SourcePosition = "/src/index.ts:12:5"
+	res.DependsOn = []resource.URN{resource.URN("foo:bar:boo")}
+	res.Unreferenced = true
+	res.Created = time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC)
+	res.Modified = time.Date(2018, 9, 2, 12, 0, 0, 0, time.UTC)
+	res.UpdateVersion = 7
+	res.AdditionalSecretOutputs = []resource.PropertyKey{"out-string"}
+	res.ReplaceOnChanges = []string{"in-map.a"}
+	res.DeleteBeforeReplace = true
+	res.RandomSeed = []byte{1, 2, 3}
+	res.ProviderDefaulted = []resource.PropertyKey{"region"}
 
 	dep := SerializeResource(res)
 
@@ -88,6 +105,17 @@ func TestDeploymentSerialization(t *testing.T) {
 	assert.Equal(t, 2, len(dep.Dependencies))
 	assert.Equal(t, resource.URN("foo:bar:baz"), dep.Dependencies[0])
 	assert.Equal(t, resource.URN("foo:bar:boo"), dep.Dependencies[1])
+	assert.Equal(t, "/src/index.ts:12:5", dep.SourcePosition)
+	assert.Equal(t, []resource.URN{resource.URN("foo:bar:boo")}, dep.DependsOn)
+	assert.True(t, dep.Unreferenced)
+	assert.Equal(t, res.Created, *dep.Created)
+	assert.Equal(t, res.Modified, *dep.Modified)
+	assert.Equal(t, 7, dep.UpdateVersion)
+	assert.Equal(t, []string{"out-string"}, dep.AdditionalSecretOutputs)
+	assert.Equal(t, []string{"in-map.a"}, dep.ReplaceOnChanges)
+	assert.True(t, dep.DeleteBeforeReplace)
+	assert.Equal(t, []byte{1, 2, 3}, dep.RandomSeed)
+	assert.Equal(t, []string{"region"}, dep.ProviderDefaulted)
 
 	// assert some things about the inputs:
 	assert.NotNil(t, dep.Inputs)
@@ -145,6 +173,42 @@ func TestDeploymentSerialization(t *testing.T) {
 	assert.Equal(t, float64(999.9), outmap["z"].(float64))
 	assert.NotNil(t, dep.Outputs["out-empty-map"])
 	assert.Equal(t, 0, len(dep.Outputs["out-empty-map"].(map[string]interface{})))
+
+	// the source position, explicit dependencies, unreferenced mark, timestamps, and options should survive a round
+	// trip:
+	back, err := DeserializeResource(dep)
+	assert.NoError(t, err)
+	assert.Equal(t, "/src/index.ts:12:5", back.SourcePosition)
+	assert.Equal(t, []resource.URN{resource.URN("foo:bar:boo")}, back.DependsOn)
+	assert.True(t, back.Unreferenced)
+	assert.Equal(t, res.Created, back.Created)
+	assert.Equal(t, res.Modified, back.Modified)
+	assert.Equal(t, 7, back.UpdateVersion)
+	assert.Equal(t, []resource.PropertyKey{"out-string"}, back.AdditionalSecretOutputs)
+	assert.Equal(t, []string{"in-map.a"}, back.ReplaceOnChanges)
+	assert.True(t, back.DeleteBeforeReplace)
+	assert.Equal(t, []byte{1, 2, 3}, back.RandomSeed)
+	assert.Equal(t, []resource.PropertyKey{"region"}, back.ProviderDefaulted)
+}
+
+func TestManifestSerialization(t *testing.T) {
+	version := semver.MustParse("0.15.2")
+	manifest := deploy.Manifest{
+		Time:    time.Now().UTC(),
+		Version: "v0.15.0",
+		OS:      "linux",
+		Arch:    "amd64",
+		Plugins: []workspace.PluginInfo{{Name: "aws", Kind: workspace.ResourcePlugin, Version: &version}},
+	}
+
+	m := serializeManifest(manifest)
+	assert.Equal(t, "linux", m.OS)
+	assert.Equal(t, "amd64", m.Arch)
+	assert.Equal(t, "0.15.2", m.Plugins[0].Version)
+
+	back, err := deserializeManifest(m)
+	assert.NoError(t, err)
+	assert.Equal(t, manifest, back)
 }
 
 func TestLoadTooNewDeployment(t *testing.T) {
@@ -168,3 +232,78 @@ func TestLoadTooOldDeployment(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, ErrDeploymentSchemaVersionTooOld, err)
 }
+
+// newTestDeployment creates an untyped deployment of n resources, each with a handful of properties.
+func newTestDeployment(t testing.TB, n int) *apitype.UntypedDeployment {
+	var resources []apitype.ResourceV2
+	for i := 0; i < n; i++ {
+		typ := tokens.Type("test:index:resource")
+		props := map[string]interface{}{
+			"name":  fmt.Sprintf("resource-%d", i),
+			"count": float64(i),
+			"tags":  map[string]interface{}{"owner": "test", "index": fmt.Sprintf("%d", i)},
+			"list":  []interface{}{"a", "b", true, float64(42)},
+		}
+		resources = append(resources, apitype.ResourceV2{
+			URN:     resource.NewURN("test", "test", "", typ, tokens.QName(fmt.Sprintf("resource-%d", i))),
+			Type:    typ,
+			Custom:  true,
+			ID:      resource.ID(fmt.Sprintf("id-%d", i)),
+			Inputs:  props,
+			Outputs: props,
+		})
+	}
+
+	bytes, err := json.Marshal(apitype.DeploymentV2{Resources: resources})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &apitype.UntypedDeployment{Version: 2, Deployment: bytes}
+}
+
+func TestDeserializeDeploymentRoundTrip(t *testing.T) {
+	deployment := newTestDeployment(t, 3)
+	snap, err := DeserializeUntypedDeployment(deployment)
+	assert.NoError(t, err)
+	assert.Len(t, snap.Resources, 3)
+
+	// The properties of a deployment's resources should match those of its resources deserialized on their own.
+	var typed apitype.DeploymentV2
+	assert.NoError(t, json.Unmarshal(deployment.Deployment, &typed))
+	for i, res := range snap.Resources {
+		single, err := DeserializeResource(typed.Resources[i])
+		assert.NoError(t, err)
+		assert.Equal(t, single.Inputs(), res.Inputs())
+		assert.Equal(t, single.Outputs(), res.Outputs())
+		assert.Equal(t, "test", res.Inputs()["tags"].ObjectValue()["owner"].StringValue())
+	}
+
+	// Re-serializing the snapshot should produce the original deployment.
+	assert.Equal(t, typed, *SerializeDeployment(snap))
+}
+
+func TestDeserializeMalformedProperties(t *testing.T) {
+	// A malformed archive is reported when the deployment is loaded, rather than when the resource is first used.
+	deployment := &apitype.UntypedDeployment{
+		Version: 2,
+		Deployment: []byte(`{"manifest": {}, "resources": [{"urn": "urn:pulumi:test::test::test:index:resource::a",` +
+			`"type": "test:index:resource", "inputs": {"archive": {"` + string(resource.SigKey) + `": "` +
+			resource.ArchiveSig + `", "assets": {"bad": {}}}}}]}`),
+	}
+	snap, err := DeserializeUntypedDeployment(deployment)
+	assert.Nil(t, snap)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "urn:pulumi:test::test::test:index:resource::a")
+	}
+}
+
+// BenchmarkDeserialize measures the cost of loading a large deployment.
+func BenchmarkDeserialize(b *testing.B) {
+	deployment := newTestDeployment(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := DeserializeUntypedDeployment(deployment); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// SerializeInventory lists the custom resources in a snapshot of the given stack as an inventory.  Component
+// resources, providers, and resources pending deletion do not correspond to cloud resources, and are left out.
+func SerializeInventory(stackName tokens.QName, proj tokens.PackageName, snap *deploy.Snapshot) *apitype.InventoryV1 {
+	inventory := &apitype.InventoryV1{
+		Version:   apitype.InventoryVersionCurrent,
+		Project:   proj,
+		Stack:     stackName,
+		Resources: []apitype.InventoryResourceV1{},
+	}
+	if snap == nil {
+		return inventory
+	}
+
+	inventory.Time = snap.Manifest.Time
+	provs := make(map[resource.URN]*resource.State)
+	for _, res := range snap.Resources {
+		if providers.IsProviderType(res.Type) {
+			provs[res.URN] = res
+		}
+	}
+
+	for _, res := range snap.Resources {
+		if !res.Custom || res.Delete || providers.IsProviderType(res.Type) {
+			continue
+		}
+		entry := apitype.InventoryResourceV1{
+			URN:          res.URN,
+			Type:         res.Type,
+			Name:         res.URN.Name(),
+			Package:      res.Type.Package(),
+			Region:       inventoryRegion(res, provs),
+			ID:           res.ID,
+			Parent:       res.Parent,
+			Dependencies: res.Dependencies,
+			Protect:      res.Protect,
+			External:     res.External,
+			Properties:   res.Outputs().Mappable(),
+		}
+		if !res.Created.IsZero() {
+			created := res.Created
+			entry.Created = &created
+		}
+		if !res.Modified.IsZero() {
+			modified := res.Modified
+			entry.Modified = &modified
+		}
+		inventory.Resources = append(inventory.Resources, entry)
+	}
+	return inventory
+}
+
+// inventoryRegion returns the region of a resource: the value of its `region` or `location` property, or else of its
+// provider's `region` or `location` configuration.
+func inventoryRegion(res *resource.State, provs map[resource.URN]*resource.State) string {
+	stringOf := func(props resource.PropertyMap) string {
+		for _, key := range []resource.PropertyKey{"region", "location"} {
+			if v, ok := props[key]; ok && v.IsString() {
+				return v.StringValue()
+			}
+		}
+		return ""
+	}
+
+	if region := stringOf(res.Outputs()); region != "" {
+		return region
+	}
+	if ref, err := providers.ParseReference(res.Provider); err == nil {
+		if prov, ok := provs[ref.URN()]; ok {
+			return stringOf(prov.Inputs())
+		}
+	}
+	return ""
+}
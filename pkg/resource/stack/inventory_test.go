@@ -0,0 +1,63 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestSerializeInventory(t *testing.T) {
+	root := resource.NewState(resource.RootStackType, "urn:pulumi:dev::proj::pulumi:pulumi:Stack::proj-dev",
+		false, false, "", resource.PropertyMap{}, nil, "", false, false, nil, nil, "")
+	prov := resource.NewState("pulumi:providers:aws", "urn:pulumi:dev::proj::pulumi:providers:aws::default",
+		true, false, "id", resource.PropertyMap{}, nil, "", false, false, nil, nil, "")
+	bucket := resource.NewState("aws:s3/bucket:Bucket", "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs",
+		true, false, "logs-1234", resource.PropertyMap{},
+		resource.NewPropertyMapFromMap(map[string]interface{}{"arn": "arn:aws:s3:::logs-1234"}),
+		root.URN, true, false, nil, nil, "")
+	bucket.Created = time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+	disk := resource.NewState("gcp:compute/disk:Disk", "urn:pulumi:dev::proj::gcp:compute/disk:Disk::data",
+		true, false, "data", resource.PropertyMap{},
+		resource.NewPropertyMapFromMap(map[string]interface{}{"location": "us-central1"}),
+		root.URN, false, false, nil, nil, "")
+	deleted := resource.NewState("aws:s3/bucket:Bucket", "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::old",
+		true, true, "old-1234", resource.PropertyMap{}, nil, root.URN, false, false, nil, nil, "")
+	snap := deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{root, prov, bucket, disk, deleted}, nil)
+
+	inventory := SerializeInventory("dev", "proj", snap)
+	assert.Equal(t, 1, inventory.Version)
+	assert.Equal(t, "proj", string(inventory.Project))
+	if assert.Len(t, inventory.Resources, 2) {
+		res := inventory.Resources[0]
+		assert.Equal(t, bucket.URN, res.URN)
+		assert.Equal(t, "logs", string(res.Name))
+		assert.Equal(t, "aws", string(res.Package))
+		assert.Equal(t, resource.ID("logs-1234"), res.ID)
+		assert.Equal(t, root.URN, res.Parent)
+		assert.True(t, res.Protect)
+		assert.Equal(t, map[string]interface{}{"arn": "arn:aws:s3:::logs-1234"}, res.Properties)
+		assert.Equal(t, bucket.Created, *res.Created)
+		assert.Nil(t, res.Modified)
+		assert.Equal(t, "us-central1", inventory.Resources[1].Region)
+	}
+
+	assert.Empty(t, SerializeInventory("dev", "proj", nil).Resources)
+}
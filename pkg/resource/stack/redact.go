@@ -0,0 +1,195 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/apitype/migrate"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// RedactedValue replaces each sensitive value in a redacted deployment, unless the values are hashed.
+const RedactedValue = "[redacted]"
+
+// DefaultRedactedNames lists the fragments of property names whose values are always treated as sensitive.
+var DefaultRedactedNames = []string{"password", "secret", "token", "privatekey", "apikey", "accesskey", "credential"}
+
+// RedactOptions controls which property values are treated as sensitive when redacting a deployment, and how they
+// are hidden.
+type RedactOptions struct {
+	// Names lists fragments of property names, in addition to DefaultRedactedNames, whose values are sensitive.  A
+	// property is sensitive if its name contains any of the fragments, ignoring case.
+	Names []string
+	// Secrets lists the stack's secret configuration keys.  The corresponding properties of each package's providers
+	// are sensitive.
+	Secrets []config.Key
+	// HashSalt, if non-nil, replaces each sensitive value with a salted hash instead of RedactedValue, so that equal
+	// values may still be recognized as such.
+	HashSalt []byte
+}
+
+// RedactUntypedDeployment returns a copy of the given deployment in which every sensitive property value has been
+// replaced.  The structure of the deployment, including that of any object or array that holds sensitive values, is
+// otherwise preserved.
+func RedactUntypedDeployment(deployment *apitype.UntypedDeployment,
+	opts RedactOptions) (*apitype.UntypedDeployment, error) {
+
+	var d apitype.DeploymentV2
+	switch {
+	case deployment.Version > apitype.DeploymentSchemaVersionCurrent:
+		return nil, ErrDeploymentSchemaVersionTooNew
+	case deployment.Version < DeploymentSchemaVersionOldestSupported:
+		return nil, ErrDeploymentSchemaVersionTooOld
+	case deployment.Version == 1:
+		var v1deployment apitype.DeploymentV1
+		if err := json.Unmarshal([]byte(deployment.Deployment), &v1deployment); err != nil {
+			return nil, err
+		}
+		d = migrate.UpToDeploymentV2(v1deployment)
+	default:
+		if err := json.Unmarshal([]byte(deployment.Deployment), &d); err != nil {
+			return nil, err
+		}
+	}
+
+	r := newRedactor(opts)
+	for i := range d.Resources {
+		r.redactResource(&d.Resources[i])
+	}
+	for i := range d.PendingOperations {
+		r.redactResource(&d.PendingOperations[i].Resource)
+	}
+
+	bytes, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return &apitype.UntypedDeployment{
+		Version:    apitype.DeploymentSchemaVersionCurrent,
+		Deployment: bytes,
+	}, nil
+}
+
+// redactor replaces sensitive values within a deployment's resources.
+type redactor struct {
+	names    []string                        // the lowercase fragments of sensitive property names.
+	secrets  map[tokens.Type]map[string]bool // the sensitive properties of each provider type.
+	hashSalt []byte                          // if non-nil, the salt with which to hash sensitive values.
+}
+
+func newRedactor(opts RedactOptions) *redactor {
+	r := &redactor{
+		secrets:  make(map[tokens.Type]map[string]bool),
+		hashSalt: opts.HashSalt,
+	}
+	for _, name := range append(append([]string{}, DefaultRedactedNames...), opts.Names...) {
+		if name != "" {
+			r.names = append(r.names, strings.ToLower(name))
+		}
+	}
+	for _, key := range opts.Secrets {
+		typ := providers.MakeProviderType(tokens.Package(key.Namespace()))
+		if r.secrets[typ] == nil {
+			r.secrets[typ] = make(map[string]bool)
+		}
+		r.secrets[typ][key.Name()] = true
+	}
+	return r
+}
+
+func (r *redactor) redactResource(res *apitype.ResourceV2) {
+	secrets := r.secrets[res.Type]
+	r.redactObject(res.Inputs, secrets)
+	r.redactObject(res.Outputs, secrets)
+}
+
+// redactObject redacts the sensitive properties of the given object in place.  The properties named by secrets are
+// sensitive in addition to those whose names match one of the redactor's fragments.
+func (r *redactor) redactObject(obj map[string]interface{}, secrets map[string]bool) {
+	for k, v := range obj {
+		// Leave the signatures that identify assets, archives, and the like intact so that the shape of these values
+		// is preserved.
+		if k == string(resource.SigKey) {
+			continue
+		}
+		if secrets[k] || r.isSensitive(k) {
+			obj[k] = r.redactAll(v)
+		} else {
+			obj[k] = r.redactValue(v)
+		}
+	}
+}
+
+// redactValue redacts the sensitive properties of any objects within the given value.
+func (r *redactor) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		r.redactObject(v, nil)
+	case []interface{}:
+		for i, e := range v {
+			v[i] = r.redactValue(e)
+		}
+	}
+	return v
+}
+
+// redactAll replaces every scalar within the given value, leaving the structure of any objects and arrays intact.
+func (r *redactor) redactAll(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		for k, e := range v {
+			if k != string(resource.SigKey) {
+				v[k] = r.redactAll(e)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = r.redactAll(e)
+		}
+		return v
+	default:
+		if r.hashSalt == nil {
+			return RedactedValue
+		}
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return RedactedValue
+		}
+		sum := sha256.Sum256(append(append([]byte{}, r.hashSalt...), bytes...))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+}
+
+// isSensitive returns true if the given property name contains any of the redactor's fragments, ignoring case.
+func (r *redactor) isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, fragment := range r.names {
+		if strings.Contains(name, fragment) {
+			return true
+		}
+	}
+	return false
+}
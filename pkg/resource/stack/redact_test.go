@@ -0,0 +1,109 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+)
+
+func newRedactTestDeployment(t *testing.T) *apitype.UntypedDeployment {
+	d := apitype.DeploymentV2{
+		Resources: []apitype.ResourceV2{
+			{
+				URN:    resource.URN("urn:pulumi:test::test::pulumi:providers:aws::default"),
+				Custom: true,
+				Type:   "pulumi:providers:aws",
+				Inputs: map[string]interface{}{"region": "us-west-2", "secretKey": "hunter2", "skipChecks": "true"},
+			},
+			{
+				URN:    resource.URN("urn:pulumi:test::test::aws:rds:Instance::db"),
+				Custom: true,
+				Type:   "aws:rds:Instance",
+				Inputs: map[string]interface{}{
+					"masterPassword": "hunter2",
+					"engine":         "postgres",
+					"tags":           map[string]interface{}{"ticket": "OPS-1", "owner": "alice"},
+				},
+				Outputs: map[string]interface{}{
+					"masterPassword": "hunter2",
+					"users": []interface{}{
+						map[string]interface{}{"name": "admin", "apiToken": "abc"},
+					},
+					"credentials": map[string]interface{}{"user": "admin", "ports": []interface{}{5432.0}},
+				},
+			},
+		},
+	}
+	bytes, err := json.Marshal(d)
+	assert.NoError(t, err)
+	return &apitype.UntypedDeployment{Version: 2, Deployment: bytes}
+}
+
+func redactTestDeployment(t *testing.T, opts RedactOptions) apitype.DeploymentV2 {
+	redacted, err := RedactUntypedDeployment(newRedactTestDeployment(t), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, apitype.DeploymentSchemaVersionCurrent, redacted.Version)
+
+	var d apitype.DeploymentV2
+	assert.NoError(t, json.Unmarshal(redacted.Deployment, &d))
+	return d
+}
+
+func TestRedactDeployment(t *testing.T) {
+	d := redactTestDeployment(t, RedactOptions{
+		Names:   []string{"owner"},
+		Secrets: []config.Key{config.MustMakeKey("aws", "skipChecks")},
+	})
+
+	// Provider properties that hold secret configuration are redacted, as are those with sensitive names.
+	provider := d.Resources[0]
+	assert.Equal(t, "us-west-2", provider.Inputs["region"])
+	assert.Equal(t, RedactedValue, provider.Inputs["secretKey"])
+	assert.Equal(t, RedactedValue, provider.Inputs["skipChecks"])
+
+	// Nested properties are redacted, and the structure of sensitive objects and arrays is preserved.
+	db := d.Resources[1]
+	assert.Equal(t, RedactedValue, db.Inputs["masterPassword"])
+	assert.Equal(t, "postgres", db.Inputs["engine"])
+	assert.Equal(t, map[string]interface{}{"ticket": "OPS-1", "owner": RedactedValue}, db.Inputs["tags"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "admin", "apiToken": RedactedValue},
+	}, db.Outputs["users"])
+	assert.Equal(t, map[string]interface{}{
+		"user": RedactedValue, "ports": []interface{}{RedactedValue},
+	}, db.Outputs["credentials"])
+}
+
+func TestRedactDeploymentHashes(t *testing.T) {
+	d := redactTestDeployment(t, RedactOptions{HashSalt: []byte("salt")})
+
+	// Equal values hash equally, without revealing the value.
+	password := d.Resources[1].Inputs["masterPassword"].(string)
+	assert.True(t, strings.HasPrefix(password, "sha256:"))
+	assert.Equal(t, password, d.Resources[1].Outputs["masterPassword"])
+	assert.Equal(t, password, d.Resources[0].Inputs["secretKey"])
+
+	// A different salt produces different hashes.
+	other := redactTestDeployment(t, RedactOptions{HashSalt: []byte("pepper")})
+	assert.NotEqual(t, password, other.Resources[1].Inputs["masterPassword"])
+}
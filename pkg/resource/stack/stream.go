@@ -0,0 +1,194 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// WriteDeployment writes the JSON form of a snapshot to w, exactly as marshaling the result of SerializeDeployment
+// would, but without first building the entire serialized deployment in memory: each resource is serialized and
+// written in turn.  If indent is non-empty, the output is indented as json.MarshalIndent would indent it.
+func WriteDeployment(w io.Writer, snap *deploy.Snapshot, indent string) error {
+	contract.Require(snap != nil, "snap")
+
+	jw := &jsonWriter{w: w, indent: indent}
+	writeDeployment(jw, snap, 0, nil)
+	return jw.err
+}
+
+// WriteCheckpoint writes the JSON form of a versioned checkpoint for the given stack, configuration, and snapshot to w,
+// exactly as marshaling the result of SerializeCheckpoint would, streaming the snapshot's resources as WriteDeployment
+// does.  The snapshot may be nil if the stack has not yet been deployed.
+func WriteCheckpoint(w io.Writer, stack tokens.QName, config config.Map, snap *deploy.Snapshot, indent string) error {
+	return WriteCheckpointWithBlobs(w, stack, config, snap, indent, 0, nil)
+}
+
+// WriteCheckpointWithBlobs writes a checkpoint as WriteCheckpoint does, except that each string property value that is
+// at least threshold bytes long is stored in the given blob store, and the checkpoint refers to it as ExternalizeBlobs
+// describes.
+func WriteCheckpointWithBlobs(w io.Writer, stack tokens.QName, config config.Map, snap *deploy.Snapshot, indent string,
+	threshold int, blobs BlobStore) error {
+
+	jw := &jsonWriter{w: w, indent: indent}
+	jw.open("{")
+	jw.key("version")
+	jw.value(apitype.DeploymentSchemaVersionCurrent)
+	jw.key("checkpoint")
+	jw.open("{")
+	jw.key("stack")
+	jw.value(stack)
+	if len(config) > 0 {
+		jw.key("config")
+		jw.value(config)
+	}
+	if snap != nil {
+		jw.key("latest")
+		writeDeployment(jw, snap, threshold, blobs)
+	}
+	jw.close("}")
+	jw.close("}")
+	return jw.err
+}
+
+// writeDeployment writes a snapshot in the form of an apitype.DeploymentV2, whose field order and omissions it mirrors.
+// Large property values are stored in the given blob store, if there is one.
+func writeDeployment(jw *jsonWriter, snap *deploy.Snapshot, threshold int, blobs BlobStore) {
+	serialize := func(res *resource.State) apitype.ResourceV2 {
+		r := SerializeResource(res)
+		if blobs != nil && jw.err == nil {
+			jw.err = ExternalizeBlobs(&r, threshold, blobs)
+		}
+		return r
+	}
+
+	jw.open("{")
+	jw.key("manifest")
+	jw.value(serializeManifest(snap.Manifest))
+	if len(snap.Resources) > 0 {
+		jw.key("resources")
+		jw.open("[")
+		for _, res := range snap.Resources {
+			jw.next()
+			jw.value(serialize(res))
+		}
+		jw.close("]")
+	}
+	if len(snap.PendingOperations) > 0 {
+		jw.key("pending_operations")
+		jw.open("[")
+		for _, op := range snap.PendingOperations {
+			jw.next()
+			serialized := SerializeOperation(op)
+			serialized.Resource = serialize(op.Resource)
+			jw.value(serialized)
+		}
+		jw.close("]")
+	}
+	jw.close("}")
+}
+
+// jsonWriter writes a JSON document piece by piece.  Individual values are marshaled by the encoding/json package, so
+// map keys are written in sorted order and the output matches what that package would produce for the whole document.
+// The first error encountered is recorded, after which all writes are ignored.
+type jsonWriter struct {
+	w      io.Writer // the writer to which the document is written.
+	indent string    // the indentation for each level of nesting, or empty for compact output.
+	levels []bool    // for each open object or array, whether it has any members yet.
+	err    error     // the first error encountered, if any.
+}
+
+// write writes a literal string.
+func (jw *jsonWriter) write(s string) {
+	if jw.err == nil {
+		_, jw.err = io.WriteString(jw.w, s)
+	}
+}
+
+// newline begins a new line at the current level of nesting, if the output is indented.
+func (jw *jsonWriter) newline() {
+	if jw.indent != "" {
+		jw.write("\n" + strings.Repeat(jw.indent, len(jw.levels)))
+	}
+}
+
+// open begins an object or an array with the given delimiter.
+func (jw *jsonWriter) open(delim string) {
+	jw.write(delim)
+	jw.levels = append(jw.levels, false)
+}
+
+// close ends the innermost object or array with the given delimiter.
+func (jw *jsonWriter) close(delim string) {
+	contract.Assert(len(jw.levels) > 0)
+	members := jw.levels[len(jw.levels)-1]
+	jw.levels = jw.levels[:len(jw.levels)-1]
+	if members {
+		jw.newline()
+	}
+	jw.write(delim)
+}
+
+// next begins the next member of the innermost object or array.
+func (jw *jsonWriter) next() {
+	contract.Assert(len(jw.levels) > 0)
+	if jw.levels[len(jw.levels)-1] {
+		jw.write(",")
+	}
+	jw.levels[len(jw.levels)-1] = true
+	jw.newline()
+}
+
+// key begins the next member of the innermost object, whose value must be written next.
+func (jw *jsonWriter) key(k string) {
+	jw.next()
+	jw.value(k)
+	if jw.indent != "" {
+		jw.write(": ")
+	} else {
+		jw.write(":")
+	}
+}
+
+// value marshals and writes a single value at the current level of nesting.
+func (jw *jsonWriter) value(v interface{}) {
+	if jw.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		jw.err = err
+		return
+	}
+	if jw.indent != "" {
+		var buf bytes.Buffer
+		if err = json.Indent(&buf, b, strings.Repeat(jw.indent, len(jw.levels)), jw.indent); err != nil {
+			jw.err = err
+			return
+		}
+		b = buf.Bytes()
+	}
+	_, jw.err = jw.w.Write(b)
+}
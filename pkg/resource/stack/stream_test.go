@@ -0,0 +1,91 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// newStreamTestSnapshot creates a snapshot with resources, a pending operation, and a manifest that lists a plugin.
+func newStreamTestSnapshot(t *testing.T) *deploy.Snapshot {
+	snap, err := DeserializeUntypedDeployment(newTestDeployment(t, 3))
+	assert.NoError(t, err)
+
+	version := semver.MustParse("1.2.3")
+	manifest := deploy.Manifest{
+		Time:    time.Unix(1500000000, 0).UTC(),
+		Magic:   "magic",
+		Version: "v0.1.0",
+		Plugins: []workspace.PluginInfo{{Name: "test", Kind: workspace.ResourcePlugin, Version: &version}},
+	}
+	ops := []resource.Operation{resource.NewOperation(snap.Resources[2], resource.OperationTypeCreating)}
+	return deploy.NewSnapshot(manifest, snap.Resources, ops)
+}
+
+func TestWriteDeployment(t *testing.T) {
+	for _, snap := range []*deploy.Snapshot{
+		deploy.NewSnapshot(deploy.Manifest{}, nil, nil),
+		newStreamTestSnapshot(t),
+	} {
+		expected, err := json.Marshal(SerializeDeployment(snap))
+		assert.NoError(t, err)
+		var actual bytes.Buffer
+		assert.NoError(t, WriteDeployment(&actual, snap, ""))
+		assert.Equal(t, string(expected), actual.String())
+
+		expected, err = json.MarshalIndent(SerializeDeployment(snap), "", "    ")
+		assert.NoError(t, err)
+		actual.Reset()
+		assert.NoError(t, WriteDeployment(&actual, snap, "    "))
+		assert.Equal(t, string(expected), actual.String())
+	}
+}
+
+func TestWriteCheckpoint(t *testing.T) {
+	cfg := config.Map{
+		config.MustMakeKey("test", "a"): config.NewValue("b"),
+		config.MustMakeKey("test", "c"): config.NewSecureValue("ZA=="),
+	}
+	for _, c := range []struct {
+		config config.Map
+		snap   *deploy.Snapshot
+	}{
+		{nil, nil},
+		{cfg, nil},
+		{cfg, newStreamTestSnapshot(t)},
+	} {
+		expected, err := json.MarshalIndent(SerializeCheckpoint("test", c.config, c.snap), "", "    ")
+		assert.NoError(t, err)
+		var actual bytes.Buffer
+		assert.NoError(t, WriteCheckpoint(&actual, "test", c.config, c.snap, "    "))
+		assert.Equal(t, string(expected), actual.String())
+
+		// The result must round-trip through the usual checkpoint loading path.
+		chk, err := UnmarshalVersionedCheckpointToLatestCheckpoint(actual.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, len(c.config), len(chk.Config))
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstate
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/codegen/schema"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/version"
+)
+
+// Report describes the outcome of converting Terraform state.
+type Report struct {
+	// Imported maps the addresses of the Terraform resources that were converted to the URNs they were given.
+	Imported map[string]resource.URN
+	// Unmapped lists the Terraform resources whose types have no mapping, and which were therefore left out.
+	Unmapped []*Resource
+}
+
+// Convert turns the resources in Terraform state into a snapshot of the given stack, using the mapping to choose
+// each resource's Pulumi type and property names.  Every converted resource is a child of the stack's root resource,
+// and is named after its address, e.g. `module.network.aws_subnet.private[0]` is named `network-private-0`.
+// Resources whose types have no mapping are left out of the snapshot and listed in the returned report.
+//
+// The converted resources do not reference a provider, so the default provider for each resource's package manages
+// it; configure that provider as the Terraform provider was configured before running an update.
+func Convert(st *State, m *Mapping, stackName tokens.QName, proj tokens.PackageName) (*deploy.Snapshot, *Report,
+	error) {
+
+	report := &Report{Imported: make(map[string]resource.URN)}
+
+	rootName := tokens.QName(string(proj) + "-" + string(stackName))
+	rootURN := resource.NewURN(stackName, proj, "", resource.RootStackType, rootName)
+	root := resource.NewState(resource.RootStackType, rootURN, false, false, "", resource.PropertyMap{}, nil, "",
+		false, false, nil, nil, "")
+
+	// First decide the URN of each resource that has a mapping, so that dependencies can refer to them.
+	var mapped []*Resource
+	urns := make(map[string][]resource.URN)
+	owners := make(map[resource.URN]string)
+	for _, r := range st.Resources {
+		res, ok := m.Resources[r.Type]
+		if !ok {
+			report.Unmapped = append(report.Unmapped, r)
+			continue
+		}
+
+		urn := resource.NewURN(stackName, proj, "", res.Type, tokens.QName(resourceName(r)))
+		if other, has := owners[urn]; has {
+			return nil, nil, errors.Errorf("the Terraform resources %s and %s would both have the URN %s",
+				other, r.Address, urn)
+		}
+		owners[urn] = r.Address
+		urns[r.ResourceAddress()] = append(urns[r.ResourceAddress()], urn)
+		report.Imported[r.Address] = urn
+		mapped = append(mapped, r)
+	}
+
+	// Then convert each resource, ordering them such that every resource follows those that it depends on.
+	resources := []*resource.State{root}
+	done := make(map[*Resource]bool)
+	byURN := make(map[resource.URN]*Resource)
+	for _, r := range mapped {
+		byURN[report.Imported[r.Address]] = r
+	}
+	var visit func(r *Resource)
+	visit = func(r *Resource) {
+		if done[r] {
+			return
+		}
+		done[r] = true
+
+		var deps []resource.URN
+		for _, dep := range r.Dependencies {
+			for _, urn := range urns[dep] {
+				visit(byURN[urn])
+				deps = append(deps, urn)
+			}
+		}
+
+		res := m.Resources[r.Type]
+		outputs := convertAttributes(r.Attributes, res)
+		inputs := outputs
+		if len(res.Inputs) > 0 {
+			inputs = make(resource.PropertyMap)
+			for _, name := range res.Inputs {
+				if v, has := outputs[resource.PropertyKey(name)]; has {
+					inputs[resource.PropertyKey(name)] = v
+				}
+			}
+		}
+
+		urn := report.Imported[r.Address]
+		resources = append(resources, resource.NewState(res.Type, urn, true, false, resource.ID(r.ID), inputs,
+			outputs, rootURN, false, false, deps, nil, ""))
+	}
+	for _, r := range mapped {
+		visit(r)
+	}
+
+	manifest := deploy.Manifest{
+		Time:    time.Now(),
+		Version: version.Version,
+	}
+	manifest.Magic = manifest.NewMagic()
+	return deploy.NewSnapshot(manifest, resources, nil), report, nil
+}
+
+// resourceName returns the name of the Pulumi resource that corresponds to the given Terraform resource instance.
+func resourceName(r *Resource) string {
+	parts := make([]string, 0, len(r.Modules)+2)
+	for _, mod := range r.Modules {
+		parts = append(parts, strings.Replace(strings.Replace(mod, "[", "-", -1), "]", "", -1))
+	}
+	parts = append(parts, r.Name)
+	if r.Index != "" {
+		parts = append(parts, r.Index)
+	}
+	return strings.Replace(strings.Join(parts, "-"), `"`, "", -1)
+}
+
+// convertAttributes turns a Terraform resource's attributes into Pulumi properties.  Its ID is left out, since
+// Pulumi records it separately.
+func convertAttributes(attrs map[string]interface{}, res ResourceMapping) resource.PropertyMap {
+	props := make(map[string]interface{})
+	for name, v := range attrs {
+		if name == "id" {
+			continue
+		}
+
+		prop, ok := res.Properties[name]
+		if !ok || prop.Name == "" {
+			prop.Name = snakeToCamel(name)
+		}
+		props[prop.Name] = convertValue(coerceValue(v, prop.Type))
+	}
+	return resource.NewPropertyMapFromMap(props)
+}
+
+// convertValue converts the names of the attributes of nested blocks, which Terraform records as lists of objects,
+// to camelCase.  Objects that are not within lists are maps, whose keys are preserved.
+func convertValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, elem := range v {
+			if block, ok := elem.(map[string]interface{}); ok {
+				obj := make(map[string]interface{})
+				for name, attr := range block {
+					obj[snakeToCamel(name)] = convertValue(attr)
+				}
+				list[i] = obj
+			} else {
+				list[i] = convertValue(elem)
+			}
+		}
+		return list
+	case map[string]interface{}:
+		m := make(map[string]interface{})
+		for k, elem := range v {
+			m[k] = convertValue(elem)
+		}
+		return m
+	default:
+		return v
+	}
+}
+
+// coerceValue converts a string to the given type, if it is a number or boolean and the string represents one.
+func coerceValue(v interface{}, typ string) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	switch typ {
+	case schema.NumberType, schema.IntegerType:
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	case schema.BooleanType:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return v
+}
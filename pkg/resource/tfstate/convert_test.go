@@ -0,0 +1,146 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/codegen/schema"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func testMapping() *Mapping {
+	return MappingFromSchema(&schema.Package{
+		Name: "aws",
+		Resources: map[string]schema.Resource{
+			"aws:ec2/vpc:Vpc": {
+				TerraformType: "aws_vpc",
+				InputProperties: map[string]schema.Property{
+					"cidrBlock":        {Type: schema.StringType},
+					"enableDnsSupport": {Type: schema.BooleanType},
+				},
+				Properties: map[string]schema.Property{
+					"cidrBlock":        {Type: schema.StringType},
+					"enableDnsSupport": {Type: schema.BooleanType},
+					"arn":              {Type: schema.StringType},
+				},
+			},
+			"aws:ec2/subnet:Subnet": {
+				TerraformType: "aws_subnet",
+				InputProperties: map[string]schema.Property{
+					"vpc":                 {Type: schema.StringType, TerraformName: "vpc_id"},
+					"mapPublicIpOnLaunch": {Type: schema.BooleanType},
+				},
+			},
+			"aws:s3/bucket:Bucket": {},
+		},
+	})
+}
+
+func TestMappingFromSchema(t *testing.T) {
+	m := testMapping()
+	assert.Len(t, m.Resources, 2)
+	vpc := m.Resources["aws_vpc"]
+	assert.Equal(t, tokens.Type("aws:ec2/vpc:Vpc"), vpc.Type)
+	assert.Equal(t, PropertyMapping{Name: "cidrBlock", Type: schema.StringType}, vpc.Properties["cidr_block"])
+	assert.Equal(t, []string{"cidrBlock", "enableDnsSupport"}, vpc.Inputs)
+	assert.Equal(t, "vpc", m.Resources["aws_subnet"].Properties["vpc_id"].Name)
+
+	// Hand-written mappings replace those of the same Terraform types.
+	override, err := ParseMapping([]byte(`{"resources": {"aws_vpc": {"type": "custom:index:Vpc"}}}`))
+	assert.NoError(t, err)
+	m.Merge(override)
+	assert.Equal(t, tokens.Type("custom:index:Vpc"), m.Resources["aws_vpc"].Type)
+
+	_, err = ParseMapping([]byte(`{"resources": {"aws_vpc": {"type": "Vpc"}}}`))
+	assert.Error(t, err)
+}
+
+func TestConvert(t *testing.T) {
+	st, err := Parse([]byte(stateV4JSON))
+	assert.NoError(t, err)
+	st.Resources = append(st.Resources, &Resource{
+		Address:    "aws_s3_bucket.logs",
+		Type:       "aws_s3_bucket",
+		Name:       "logs",
+		ID:         "logs",
+		Attributes: map[string]interface{}{},
+	})
+	// List the subnet first, so that it must be reordered to follow the VPC it depends on.
+	st.Resources[0], st.Resources[1] = st.Resources[1], st.Resources[0]
+
+	snap, report, err := Convert(st, testMapping(), "dev", "network")
+	assert.NoError(t, err)
+	if !assert.Len(t, snap.Resources, 3) {
+		return
+	}
+	assert.NoError(t, snap.VerifyIntegrity())
+
+	root, vpc, subnet := snap.Resources[0], snap.Resources[1], snap.Resources[2]
+	assert.Equal(t, resource.RootStackType, root.Type)
+	assert.Equal(t, resource.URN("urn:pulumi:dev::network::pulumi:pulumi:Stack::network-dev"), root.URN)
+
+	assert.Equal(t, resource.URN("urn:pulumi:dev::network::aws:ec2/vpc:Vpc::main"), vpc.URN)
+	assert.Equal(t, root.URN, vpc.Parent)
+	assert.True(t, vpc.Custom)
+	assert.Equal(t, resource.ID("vpc-1"), vpc.ID)
+	assert.Equal(t, resource.NewPropertyMapFromMap(map[string]interface{}{
+		"cidrBlock":        "10.0.0.0/16",
+		"enableDnsSupport": true,
+	}), vpc.Outputs())
+
+	assert.Equal(t, resource.URN("urn:pulumi:dev::network::aws:ec2/subnet:Subnet::network-private-a"), subnet.URN)
+	assert.Equal(t, []resource.URN{vpc.URN}, subnet.Dependencies)
+	assert.Equal(t, resource.NewStringProperty("vpc-1"), subnet.Inputs()["vpc"])
+
+	assert.Equal(t, map[string]resource.URN{
+		"aws_vpc.main":                           vpc.URN,
+		`module.network.aws_subnet.private["a"]`: subnet.URN,
+	}, report.Imported)
+	if assert.Len(t, report.Unmapped, 1) {
+		assert.Equal(t, "aws_s3_bucket.logs", report.Unmapped[0].Address)
+	}
+}
+
+func TestConvertAttributes(t *testing.T) {
+	res := ResourceMapping{
+		Properties: map[string]PropertyMapping{
+			"port":    {Type: schema.IntegerType},
+			"enabled": {Name: "isEnabled", Type: schema.BooleanType},
+		},
+	}
+	props := convertAttributes(map[string]interface{}{
+		"id":      "i-1",
+		"port":    "8080",
+		"enabled": "true",
+		"tags":    map[string]interface{}{"cost_center": "a"},
+		"ebs_block_device": []interface{}{
+			map[string]interface{}{"device_name": "/dev/sdb", "volume_size": "8"},
+		},
+	}, res)
+	assert.Equal(t, resource.NewPropertyMapFromMap(map[string]interface{}{
+		"port":      8080,
+		"isEnabled": true,
+		"tags":      map[string]interface{}{"cost_center": "a"},
+		"ebsBlockDevice": []interface{}{
+			map[string]interface{}{"deviceName": "/dev/sdb", "volumeSize": "8"},
+		},
+	}), props)
+
+	assert.Equal(t, "instanceType", snakeToCamel("instance_type"))
+}
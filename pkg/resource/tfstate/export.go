@@ -0,0 +1,188 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstate
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"unicode"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/pulumi/pulumi/pkg/codegen"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// ExportReport describes the outcome of exporting a snapshot as Terraform state.
+type ExportReport struct {
+	// Exported maps the URNs of the resources that were exported to the Terraform addresses they were given.
+	Exported map[resource.URN]string
+	// Unmapped lists the resources whose types have no mapping, and which were therefore left out.
+	Unmapped []resource.URN
+}
+
+// Export writes a best-effort Terraform state file, in version 4 of the state format, that records the custom
+// resources in a snapshot.  The mapping is used in reverse to choose each resource's Terraform type and attribute
+// names; resources whose types have no mapping are left out of the state and listed in the returned report.
+// Component resources, providers, and resources pending deletion are always left out.
+//
+// The state is meant to be read by inventory and auditing tools rather than Terraform itself: it records no
+// Terraform schema versions, and attributes that the resources' Pulumi providers did not report are missing.
+func Export(snap *deploy.Snapshot, m *Mapping) ([]byte, *ExportReport, error) {
+	report := &ExportReport{Exported: make(map[resource.URN]string)}
+	types := m.terraformTypes()
+
+	st := stateV4{
+		Version:          4,
+		TerraformVersion: "0.12.0",
+		Serial:           1,
+		Lineage:          uuid.NewV4().String(),
+		Outputs:          map[string]interface{}{},
+		Resources:        []resourceV4{},
+	}
+	names := make(map[string]bool)
+	for _, res := range snap.Resources {
+		if !res.Custom || res.Delete || providers.IsProviderType(res.Type) {
+			continue
+		}
+		tfType, ok := types[res.Type]
+		if !ok {
+			report.Unmapped = append(report.Unmapped, res.URN)
+			continue
+		}
+
+		// Terraform names must be identifiers that are unique among the resources of each type.
+		name := terraformName(string(res.URN.Name()))
+		for i := 2; names[tfType+"."+name]; i++ {
+			name = terraformName(string(res.URN.Name())) + "_" + strconv.Itoa(i)
+		}
+		address := tfType + "." + name
+		names[address] = true
+		report.Exported[res.URN] = address
+
+		// Dependencies precede their dependents in a snapshot, so their addresses are already known.
+		var deps []string
+		for _, dep := range res.Dependencies {
+			if addr, has := report.Exported[dep]; has {
+				deps = append(deps, addr)
+			}
+		}
+
+		attrs := exportProperties(res.Outputs(), m.Resources[tfType])
+		attrs["id"] = string(res.ID)
+		st.Resources = append(st.Resources, resourceV4{
+			Mode:     "managed",
+			Type:     tfType,
+			Name:     name,
+			Provider: "provider." + providerName("", tfType),
+			Instances: []instanceV4{{
+				Attributes:   attrs,
+				Dependencies: deps,
+			}},
+		})
+	}
+
+	bytes, err := json.MarshalIndent(st, "", "    ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return bytes, report, nil
+}
+
+// terraformTypes inverts the mapping, returning the Terraform type that corresponds to each Pulumi type.  If several
+// Terraform types map to the same Pulumi type, the first of them in sorted order is chosen.
+func (m *Mapping) terraformTypes() map[tokens.Type]string {
+	var tfTypes []string
+	for tfType := range m.Resources {
+		tfTypes = append(tfTypes, tfType)
+	}
+	sort.Strings(tfTypes)
+
+	types := make(map[tokens.Type]string)
+	for _, tfType := range tfTypes {
+		if _, has := types[m.Resources[tfType].Type]; !has {
+			types[m.Resources[tfType].Type] = tfType
+		}
+	}
+	return types
+}
+
+// terraformName turns a Pulumi resource name into a Terraform identifier by replacing any characters that may not
+// appear in one.
+func terraformName(name string) string {
+	runes := []rune(name)
+	for i, c := range runes {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '-' {
+			runes[i] = '_'
+		}
+	}
+	if len(runes) == 0 || !unicode.IsLetter(runes[0]) && runes[0] != '_' {
+		runes = append([]rune{'_'}, runes...)
+	}
+	return string(runes)
+}
+
+// exportProperties turns a Pulumi resource's properties into Terraform attributes.  This is the inverse of
+// convertAttributes: the names of properties and of the attributes of nested blocks are converted to snake_case,
+// while the keys of maps are preserved.
+func exportProperties(props resource.PropertyMap, res ResourceMapping) map[string]interface{} {
+	names := make(map[string]string)
+	for tfName, prop := range res.Properties {
+		if prop.Name != "" {
+			names[prop.Name] = tfName
+		}
+	}
+
+	attrs := make(map[string]interface{})
+	for k, v := range props.Mappable() {
+		tfName, ok := names[k]
+		if !ok {
+			tfName = codegen.CamelToSnake(k)
+		}
+		attrs[tfName] = exportValue(v)
+	}
+	return attrs
+}
+
+func exportValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, elem := range v {
+			if obj, ok := elem.(map[string]interface{}); ok {
+				block := make(map[string]interface{})
+				for name, attr := range obj {
+					block[codegen.CamelToSnake(name)] = exportValue(attr)
+				}
+				list[i] = block
+			} else {
+				list[i] = exportValue(elem)
+			}
+		}
+		return list
+	case map[string]interface{}:
+		m := make(map[string]interface{})
+		for k, elem := range v {
+			m[k] = exportValue(elem)
+		}
+		return m
+	default:
+		return v
+	}
+}
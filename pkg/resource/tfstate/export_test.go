@@ -0,0 +1,81 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	st, err := Parse([]byte(stateV4JSON))
+	assert.NoError(t, err)
+	snap, _, err := Convert(st, testMapping(), "dev", "network")
+	assert.NoError(t, err)
+
+	// Add a resource with no mapping and a name that is not a Terraform identifier.
+	bucketURN := resource.NewURN("dev", "network", "", "aws:s3/bucket:Bucket", "logs")
+	snap.Resources = append(snap.Resources, resource.NewState("aws:s3/bucket:Bucket", bucketURN, true, false,
+		"logs", resource.PropertyMap{}, nil, "", false, false, nil, nil, ""))
+	odd := resource.NewURN("dev", "network", "", "aws:ec2/vpc:Vpc", "1.odd name")
+	snap.Resources = append(snap.Resources, resource.NewState("aws:ec2/vpc:Vpc", odd, true, false,
+		"vpc-2", resource.PropertyMap{}, nil, "", false, false, nil, nil, ""))
+
+	bytes, report, err := Export(snap, testMapping())
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.URN{bucketURN}, report.Unmapped)
+	assert.Equal(t, "aws_vpc._1_odd_name", report.Exported[odd])
+
+	exported, err := Parse(bytes)
+	assert.NoError(t, err)
+	if !assert.Len(t, exported.Resources, 3) {
+		return
+	}
+
+	vpc := exported.Resources[0]
+	assert.Equal(t, "aws_vpc.main", vpc.Address)
+	assert.Equal(t, "aws", vpc.Provider)
+	assert.Equal(t, "vpc-1", vpc.ID)
+	assert.Equal(t, map[string]interface{}{
+		"id":                 "vpc-1",
+		"cidr_block":         "10.0.0.0/16",
+		"enable_dns_support": true,
+	}, vpc.Attributes)
+
+	subnet := exported.Resources[1]
+	assert.Equal(t, "aws_subnet.network-private-a", subnet.Address)
+	assert.Equal(t, "vpc-1", subnet.Attributes["vpc_id"])
+	assert.Equal(t, []string{"aws_vpc.main"}, subnet.Dependencies)
+}
+
+func TestExportSkipsNonCustomResources(t *testing.T) {
+	root := resource.NewState(resource.RootStackType, "urn:pulumi:dev::network::pulumi:pulumi:Stack::network-dev",
+		false, false, "", resource.PropertyMap{}, nil, "", false, false, nil, nil, "")
+	prov := resource.NewState("pulumi:providers:aws", "urn:pulumi:dev::network::pulumi:providers:aws::default",
+		true, false, "id", resource.PropertyMap{}, nil, "", false, false, nil, nil, "")
+	snap := deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{root, prov}, nil)
+
+	bytes, report, err := Export(snap, testMapping())
+	assert.NoError(t, err)
+	assert.Empty(t, report.Exported)
+	assert.Empty(t, report.Unmapped)
+	exported, err := Parse(bytes)
+	assert.NoError(t, err)
+	assert.Empty(t, exported.Resources)
+}
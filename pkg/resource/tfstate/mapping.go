@@ -0,0 +1,137 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstate
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/codegen"
+	"github.com/pulumi/pulumi/pkg/codegen/schema"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// Mapping is a table that maps Terraform resource types to the Pulumi resource types that manage the same resources.
+// Mappings are supplied by resource providers as part of their schemas, and may also be written by hand as JSON.
+type Mapping struct {
+	// Resources maps Terraform resource types, e.g. `aws_instance`, to their Pulumi equivalents.
+	Resources map[string]ResourceMapping `json:"resources"`
+}
+
+// ResourceMapping describes how a Terraform resource type maps to a Pulumi resource type.
+type ResourceMapping struct {
+	// Type is the Pulumi resource type, e.g. `aws:ec2/instance:Instance`.
+	Type tokens.Type `json:"type"`
+	// Properties maps Terraform attribute names to their Pulumi properties.  Attributes that are not listed map to
+	// properties named with the camelCase form of their names.
+	Properties map[string]PropertyMapping `json:"properties,omitempty"`
+	// Inputs lists the names of the Pulumi properties that are inputs of the resource.  If empty, every property is
+	// taken to be an input.
+	Inputs []string `json:"inputs,omitempty"`
+}
+
+// PropertyMapping describes how a Terraform attribute maps to a Pulumi property.
+type PropertyMapping struct {
+	// Name is the name of the Pulumi property.
+	Name string `json:"name,omitempty"`
+	// Type is the type of the Pulumi property, if known.  Attributes read from older Terraform state files are
+	// always strings, and are converted to numbers or booleans according to this type.
+	Type string `json:"type,omitempty"`
+}
+
+// NewMapping returns an empty mapping.
+func NewMapping() *Mapping {
+	return &Mapping{Resources: make(map[string]ResourceMapping)}
+}
+
+// ParseMapping reads a mapping from JSON.
+func ParseMapping(data []byte) (*Mapping, error) {
+	m := NewMapping()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrap(err, "could not read mapping")
+	}
+	if m.Resources == nil {
+		m.Resources = make(map[string]ResourceMapping)
+	}
+	for tfType, res := range m.Resources {
+		if tokens.Token(res.Type).Delimiters() != 2 {
+			return nil, errors.Errorf("the mapping for %s has an invalid type '%s'; expected <package>:<module>:<name>",
+				tfType, res.Type)
+		}
+	}
+	return m, nil
+}
+
+// MappingFromSchema returns the mapping described by a provider's schema, which includes every resource that names
+// the Terraform resource type that it corresponds to.
+func MappingFromSchema(pkg *schema.Package) *Mapping {
+	m := NewMapping()
+	for _, tok := range pkg.ResourceTokens() {
+		res := pkg.Resources[tok]
+		if res.TerraformType == "" {
+			continue
+		}
+
+		mapping := ResourceMapping{
+			Type:       tokens.Type(tok),
+			Properties: make(map[string]PropertyMapping),
+		}
+		for _, props := range []map[string]schema.Property{res.Properties, res.InputProperties} {
+			for name, prop := range props {
+				tfName := prop.TerraformName
+				if tfName == "" {
+					tfName = codegen.CamelToSnake(name)
+				}
+				mapping.Properties[tfName] = PropertyMapping{Name: name, Type: prop.Type}
+			}
+		}
+		for name := range res.InputProperties {
+			mapping.Inputs = append(mapping.Inputs, name)
+		}
+		sort.Strings(mapping.Inputs)
+
+		m.Resources[res.TerraformType] = mapping
+	}
+	return m
+}
+
+// Merge adds the entries of another mapping to this one, replacing any entries for the same Terraform types.
+func (m *Mapping) Merge(other *Mapping) {
+	for tfType, res := range other.Resources {
+		m.Resources[tfType] = res
+	}
+}
+
+// snakeToCamel turns an attribute name such as `instance_type` into a property name such as `instanceType`.
+func snakeToCamel(name string) string {
+	var b bytes.Buffer
+	upper := false
+	for i, c := range name {
+		switch {
+		case c == '_' && i > 0:
+			upper = true
+		case upper:
+			b.WriteRune(unicode.ToUpper(c))
+			upper = false
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
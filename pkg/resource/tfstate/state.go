@@ -0,0 +1,380 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tfstate reads Terraform state files and converts the resources they record into a Pulumi checkpoint, so that
+// infrastructure managed by Terraform can be brought under Pulumi's management without being recreated.  It can also
+// write a Pulumi checkpoint's resources as Terraform state, for tools that understand only that format.
+package tfstate
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// State is the set of managed resources recorded in a Terraform state file.
+type State struct {
+	// Version is the version of the state file's format.
+	Version int
+	// Resources are the resource instances recorded in the state, in the order in which they appear.
+	Resources []*Resource
+}
+
+// Resource is a single instance of a managed Terraform resource.
+type Resource struct {
+	// Address is the resource instance's full address, e.g. `module.network.aws_subnet.private[0]`.
+	Address string
+	// Modules are the names of the modules that enclose the resource, outermost first.
+	Modules []string
+	// Type is the resource's Terraform type, e.g. `aws_subnet`.
+	Type string
+	// Name is the resource's name within its module, e.g. `private`.
+	Name string
+	// Index is the instance's count or for_each key, if any.
+	Index string
+	// Provider is the name of the provider that manages the resource, e.g. `aws`.
+	Provider string
+	// ID is the provider-assigned ID of the resource.
+	ID string
+	// Attributes are the resource's attributes, keyed by their Terraform names.  Attributes read from version 3
+	// state files are always strings, lists, or maps, since that format does not record their types.
+	Attributes map[string]interface{}
+	// Dependencies are the addresses of the resources that this resource depends on.  These addresses never include
+	// an index, and so refer to every instance of the resource they name.
+	Dependencies []string
+}
+
+// ResourceAddress returns the address of the resource that this is an instance of: its address without any index.
+func (r *Resource) ResourceAddress() string {
+	return moduleAddress(r.Modules) + r.Type + "." + r.Name
+}
+
+// Parse reads a Terraform state file.  Versions 3 and 4 of the state format, which are written by Terraform 0.11
+// and later, are supported.  Data sources are skipped, as they are not managed by Terraform.
+func Parse(data []byte) (*State, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, errors.Wrap(err, "could not read Terraform state")
+	}
+
+	switch header.Version {
+	case 3:
+		return parseV3(data)
+	case 4:
+		return parseV4(data)
+	default:
+		return nil, errors.Errorf("unsupported Terraform state version %d; "+
+			"run `terraform refresh` with Terraform 0.11 or later to upgrade it", header.Version)
+	}
+}
+
+type stateV3 struct {
+	Modules []struct {
+		Path      []string `json:"path"`
+		Resources map[string]struct {
+			Type      string   `json:"type"`
+			DependsOn []string `json:"depends_on"`
+			Primary   *struct {
+				ID         string            `json:"id"`
+				Attributes map[string]string `json:"attributes"`
+			} `json:"primary"`
+			Provider string `json:"provider"`
+		} `json:"resources"`
+	} `json:"modules"`
+}
+
+func parseV3(data []byte) (*State, error) {
+	var st stateV3
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, errors.Wrap(err, "could not read Terraform state")
+	}
+
+	result := &State{Version: 3}
+	for _, mod := range st.Modules {
+		// Module paths begin with the root module, which does not appear in addresses.
+		var modules []string
+		if len(mod.Path) > 0 {
+			modules = mod.Path[1:]
+		}
+
+		// Resources are keyed by `[data.]<type>.<name>[.<index>]`; sort them so that the result is deterministic.
+		var keys []string
+		for key := range mod.Resources {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			res := mod.Resources[key]
+			if strings.HasPrefix(key, "data.") || res.Primary == nil {
+				continue
+			}
+
+			parts := strings.Split(key, ".")
+			if len(parts) < 2 || len(parts) > 3 {
+				return nil, errors.Errorf("invalid resource key '%s' in Terraform state", key)
+			}
+			r := &Resource{
+				Modules:    modules,
+				Type:       parts[0],
+				Name:       parts[1],
+				Provider:   providerName(res.Provider, parts[0]),
+				ID:         res.Primary.ID,
+				Attributes: expandFlatmap(res.Primary.Attributes),
+			}
+			if res.Type != "" {
+				r.Type = res.Type
+			}
+			if len(parts) == 3 {
+				r.Index = parts[2]
+			}
+			r.Address = r.ResourceAddress()
+			if r.Index != "" {
+				r.Address += "[" + r.Index + "]"
+			}
+
+			// Dependencies are relative to the module, and may name a single instance or all of them with `.*`.
+			for _, dep := range res.DependsOn {
+				if strings.HasPrefix(dep, "data.") || strings.HasPrefix(dep, "module.") {
+					continue
+				}
+				depParts := strings.Split(dep, ".")
+				if len(depParts) < 2 {
+					continue
+				}
+				r.Dependencies = AppendUnique(r.Dependencies, moduleAddress(modules)+depParts[0]+"."+depParts[1])
+			}
+
+			result.Resources = append(result.Resources, r)
+		}
+	}
+	return result, nil
+}
+
+// stateV4 is the layout of a version 4 state file, which is both read by Parse and written by Export.
+type stateV4 struct {
+	Version          int                    `json:"version"`
+	TerraformVersion string                 `json:"terraform_version,omitempty"`
+	Serial           int                    `json:"serial,omitempty"`
+	Lineage          string                 `json:"lineage,omitempty"`
+	Outputs          map[string]interface{} `json:"outputs"`
+	Resources        []resourceV4           `json:"resources"`
+}
+
+type resourceV4 struct {
+	Module    string       `json:"module,omitempty"`
+	Mode      string       `json:"mode"`
+	Type      string       `json:"type"`
+	Name      string       `json:"name"`
+	Provider  string       `json:"provider"`
+	DependsOn []string     `json:"depends_on,omitempty"`
+	Instances []instanceV4 `json:"instances"`
+}
+
+type instanceV4 struct {
+	IndexKey      interface{}            `json:"index_key,omitempty"`
+	SchemaVersion int                    `json:"schema_version"`
+	Attributes    map[string]interface{} `json:"attributes"`
+	Dependencies  []string               `json:"dependencies,omitempty"`
+	DependsOn     []string               `json:"depends_on,omitempty"`
+}
+
+func parseV4(data []byte) (*State, error) {
+	var st stateV4
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, errors.Wrap(err, "could not read Terraform state")
+	}
+
+	result := &State{Version: 4}
+	for _, res := range st.Resources {
+		if res.Mode != "" && res.Mode != "managed" {
+			continue
+		}
+
+		// Module addresses look like `module.a.module.b`, and may index module instances as in `module.a[0]`.
+		var modules []string
+		if res.Module != "" {
+			for _, part := range strings.Split(res.Module, ".") {
+				if part != "module" {
+					modules = append(modules, part)
+				}
+			}
+		}
+
+		for _, inst := range res.Instances {
+			r := &Resource{
+				Modules:    modules,
+				Type:       res.Type,
+				Name:       res.Name,
+				Provider:   providerName(res.Provider, res.Type),
+				Attributes: inst.Attributes,
+			}
+			if id, ok := inst.Attributes["id"].(string); ok {
+				r.ID = id
+			}
+			r.Address = r.ResourceAddress()
+			switch key := inst.IndexKey.(type) {
+			case float64:
+				r.Index = strconv.FormatFloat(key, 'f', -1, 64)
+				r.Address += "[" + r.Index + "]"
+			case string:
+				r.Index = key
+				r.Address += "[" + strconv.Quote(key) + "]"
+			}
+
+			// Dependencies are absolute addresses; older versions of Terraform 0.12 recorded them per resource.
+			deps := append(append([]string{}, res.DependsOn...), inst.DependsOn...)
+			deps = append(deps, inst.Dependencies...)
+			for _, dep := range deps {
+				if i := strings.Index(dep, "["); i >= 0 {
+					dep = dep[:i]
+				}
+				r.Dependencies = AppendUnique(r.Dependencies, dep)
+			}
+
+			result.Resources = append(result.Resources, r)
+		}
+	}
+	return result, nil
+}
+
+// moduleAddress returns the address prefix of resources within the given modules, e.g. `module.a.module.b.`.
+func moduleAddress(modules []string) string {
+	var prefix string
+	for _, mod := range modules {
+		prefix += "module." + mod + "."
+	}
+	return prefix
+}
+
+// providerName extracts the name of a provider from a provider reference such as `provider.aws`,
+// `provider.aws.west`, or `provider["registry.terraform.io/hashicorp/aws"]`.  If the reference is empty, the
+// provider is inferred from the resource's type, whose first segment names the provider by convention.
+func providerName(ref, typ string) string {
+	if i := strings.Index(ref, "provider["); i >= 0 {
+		source := strings.TrimPrefix(ref[i+len("provider["):], `"`)
+		if j := strings.Index(source, `"`); j >= 0 {
+			source = source[:j]
+		}
+		if j := strings.LastIndex(source, "/"); j >= 0 {
+			source = source[j+1:]
+		}
+		return source
+	}
+	if i := strings.Index(ref, "provider."); i >= 0 {
+		name := ref[i+len("provider."):]
+		if j := strings.Index(name, "."); j >= 0 {
+			name = name[:j]
+		}
+		return name
+	}
+	if i := strings.Index(typ, "_"); i >= 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+// AppendUnique appends the given string to the list unless the list already contains it.
+func AppendUnique(list []string, s string) []string {
+	for _, e := range list {
+		if e == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// expandFlatmap turns the flattened attributes of a version 3 state file, in which `tags.%` is the number of
+// entries in the map `tags`, `tags.Name` is one of its entries, `ports.#` is the number of elements in the list
+// `ports`, and `ports.0` is its first element, back into a tree of maps and lists.
+func expandFlatmap(attrs map[string]string) map[string]interface{} {
+	result := make(map[string]interface{})
+	for key := range attrs {
+		if i := strings.Index(key, "."); i >= 0 {
+			key = key[:i]
+		}
+		if _, has := result[key]; !has {
+			result[key] = expandFlatmapKey(attrs, key)
+		}
+	}
+	return result
+}
+
+func expandFlatmapKey(attrs map[string]string, key string) interface{} {
+	if _, isList := attrs[key+".#"]; isList {
+		// The elements of sets are keyed by their hashes rather than their positions, so sort the keys numerically.
+		elems := flatmapChildren(attrs, key, "#")
+		sort.SliceStable(elems, func(i, j int) bool {
+			a, aerr := strconv.Atoi(elems[i])
+			b, berr := strconv.Atoi(elems[j])
+			if aerr != nil || berr != nil {
+				return elems[i] < elems[j]
+			}
+			return a < b
+		})
+		list := []interface{}{}
+		for _, elem := range elems {
+			list = append(list, expandFlatmapKey(attrs, key+"."+elem))
+		}
+		return list
+	}
+	if _, isMap := attrs[key+".%"]; isMap {
+		// Map keys may themselves contain dots, so everything after the map's key names an entry.
+		m := make(map[string]interface{})
+		prefix := key + "."
+		for k, v := range attrs {
+			if strings.HasPrefix(k, prefix) && k != key+".%" {
+				m[k[len(prefix):]] = v
+			}
+		}
+		return m
+	}
+	if v, has := attrs[key]; has {
+		return v
+	}
+
+	// Otherwise, this is an element of a list of blocks, each of whose attributes is keyed beneath it.
+	obj := make(map[string]interface{})
+	for _, child := range flatmapChildren(attrs, key, "") {
+		obj[child] = expandFlatmapKey(attrs, key+"."+child)
+	}
+	return obj
+}
+
+// flatmapChildren returns the distinct names of the children of the given key, excluding the given count key.
+func flatmapChildren(attrs map[string]string, key, count string) []string {
+	var children []string
+	seen := make(map[string]bool)
+	prefix := key + "."
+	for k := range attrs {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		child := k[len(prefix):]
+		if i := strings.Index(child, "."); i >= 0 {
+			child = child[:i]
+		}
+		if child != count && !seen[child] {
+			seen[child] = true
+			children = append(children, child)
+		}
+	}
+	sort.Strings(children)
+	return children
+}
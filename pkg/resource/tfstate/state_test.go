@@ -0,0 +1,171 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const stateV3JSON = `{
+    "version": 3,
+    "terraform_version": "0.11.7",
+    "modules": [
+        {
+            "path": ["root"],
+            "resources": {
+                "aws_vpc.main": {
+                    "type": "aws_vpc",
+                    "primary": {
+                        "id": "vpc-1",
+                        "attributes": {
+                            "id": "vpc-1",
+                            "cidr_block": "10.0.0.0/16",
+                            "tags.%": "2",
+                            "tags.Name": "main",
+                            "tags.kubernetes.io/cluster": "shared"
+                        }
+                    },
+                    "provider": "provider.aws"
+                },
+                "data.aws_ami.ubuntu": {
+                    "type": "aws_ami",
+                    "primary": {"id": "ami-1", "attributes": {"id": "ami-1"}}
+                }
+            }
+        },
+        {
+            "path": ["root", "network"],
+            "resources": {
+                "aws_subnet.private.0": {
+                    "type": "aws_subnet",
+                    "depends_on": ["aws_vpc.main"],
+                    "primary": {
+                        "id": "subnet-1",
+                        "attributes": {
+                            "id": "subnet-1",
+                            "map_public_ip_on_launch": "false",
+                            "ingress.#": "2",
+                            "ingress.2411.from_port": "443",
+                            "ingress.2411.cidr_blocks.#": "1",
+                            "ingress.2411.cidr_blocks.0": "0.0.0.0/0",
+                            "ingress.123.from_port": "80",
+                            "ingress.123.cidr_blocks.#": "0"
+                        }
+                    },
+                    "provider": "module.network.provider.aws"
+                }
+            }
+        }
+    ]
+}`
+
+const stateV4JSON = `{
+    "version": 4,
+    "terraform_version": "0.12.0",
+    "resources": [
+        {
+            "mode": "data",
+            "type": "aws_ami",
+            "name": "ubuntu",
+            "provider": "provider.aws",
+            "instances": [{"attributes": {"id": "ami-1"}}]
+        },
+        {
+            "mode": "managed",
+            "type": "aws_vpc",
+            "name": "main",
+            "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+            "instances": [{"attributes": {"id": "vpc-1", "cidr_block": "10.0.0.0/16", "enable_dns_support": true}}]
+        },
+        {
+            "module": "module.network",
+            "mode": "managed",
+            "type": "aws_subnet",
+            "name": "private",
+            "each": "map",
+            "provider": "provider.aws",
+            "instances": [
+                {
+                    "index_key": "a",
+                    "attributes": {"id": "subnet-1", "vpc_id": "vpc-1"},
+                    "dependencies": ["aws_vpc.main"]
+                }
+            ]
+        }
+    ]
+}`
+
+func TestParseV3(t *testing.T) {
+	st, err := Parse([]byte(stateV3JSON))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, st.Version)
+	if !assert.Len(t, st.Resources, 2) {
+		return
+	}
+
+	vpc := st.Resources[0]
+	assert.Equal(t, "aws_vpc.main", vpc.Address)
+	assert.Equal(t, "aws", vpc.Provider)
+	assert.Equal(t, "vpc-1", vpc.ID)
+	assert.Equal(t, map[string]interface{}{
+		"id":         "vpc-1",
+		"cidr_block": "10.0.0.0/16",
+		"tags": map[string]interface{}{
+			"Name":                  "main",
+			"kubernetes.io/cluster": "shared",
+		},
+	}, vpc.Attributes)
+
+	subnet := st.Resources[1]
+	assert.Equal(t, "module.network.aws_subnet.private[0]", subnet.Address)
+	assert.Equal(t, "module.network.aws_subnet.private", subnet.ResourceAddress())
+	assert.Equal(t, []string{"network"}, subnet.Modules)
+	assert.Equal(t, "0", subnet.Index)
+	assert.Equal(t, "aws", subnet.Provider)
+	assert.Equal(t, []string{"module.network.aws_vpc.main"}, subnet.Dependencies)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"from_port": "80", "cidr_blocks": []interface{}{}},
+		map[string]interface{}{"from_port": "443", "cidr_blocks": []interface{}{"0.0.0.0/0"}},
+	}, subnet.Attributes["ingress"])
+}
+
+func TestParseV4(t *testing.T) {
+	st, err := Parse([]byte(stateV4JSON))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, st.Version)
+	if !assert.Len(t, st.Resources, 2) {
+		return
+	}
+
+	vpc := st.Resources[0]
+	assert.Equal(t, "aws_vpc.main", vpc.Address)
+	assert.Equal(t, "aws", vpc.Provider)
+	assert.Equal(t, "vpc-1", vpc.ID)
+	assert.Equal(t, true, vpc.Attributes["enable_dns_support"])
+
+	subnet := st.Resources[1]
+	assert.Equal(t, `module.network.aws_subnet.private["a"]`, subnet.Address)
+	assert.Equal(t, "a", subnet.Index)
+	assert.Equal(t, []string{"aws_vpc.main"}, subnet.Dependencies)
+}
+
+func TestParseUnsupportedVersion(t *testing.T) {
+	_, err := Parse([]byte(`{"version": 2}`))
+	assert.Error(t, err)
+	_, err = Parse([]byte(`not json`))
+	assert.Error(t, err)
+}
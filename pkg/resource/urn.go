@@ -68,6 +68,15 @@ func NewURN(stack tokens.QName, proj tokens.PackageName, parentType, baseType to
 	)
 }
 
+// IsValid returns true if the URN is well-formed, i.e. if it has the standard prefix and each of its elements.
+func (urn URN) IsValid() bool {
+	s := string(urn)
+	if !strings.HasPrefix(s, URNPrefix) {
+		return false
+	}
+	return len(strings.Split(s[len(URNPrefix):], URNNameDelimiter)) >= 4
+}
+
 // URNName returns the URN name part of a URN (i.e., strips off the prefix).
 func (urn URN) URNName() string {
 	s := string(urn)
@@ -49,3 +49,10 @@ func TestURNRoundTripping2(t *testing.T) {
 	assert.Equal(t, typ, urn.Type())
 	assert.Equal(t, name, urn.Name())
 }
+
+func TestURNIsValid(t *testing.T) {
+	urn := NewURN("stck", "proj", "", "bang:boom/fizzle:MajorResource", "a-swell-resource")
+	assert.True(t, urn.IsValid())
+	assert.False(t, URN("a-unique-urn").IsValid())
+	assert.False(t, URN("urn:pulumi:stck::proj").IsValid())
+}
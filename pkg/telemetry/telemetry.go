@@ -0,0 +1,211 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package telemetry reports anonymized metrics about the operations that the CLI performs, for users who have opted in
+// to doing so, to help prioritize performance work.  Reports only ever contain counts, durations, and error codes:
+// never the names of stacks, projects, or resources, nor the values of their properties or configuration.
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/version"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// EndpointEnvVar is the name of the environment variable that overrides the URL to which reports are sent.
+const EndpointEnvVar = "PULUMI_TELEMETRY_ENDPOINT"
+
+// sendTimeout bounds how long sending a report may hold up the CLI.
+const sendTimeout = 5 * time.Second
+
+// OperationStats summarizes the resource operations of a single kind that an update applied.
+type OperationStats struct {
+	Count       int   `json:"count"`            // the number of operations.
+	Failed      int   `json:"failed,omitempty"` // the number of those operations that failed.
+	TotalMillis int64 `json:"totalMs"`          // the total time that the operations took, in milliseconds.
+	MaxMillis   int64 `json:"maxMs"`            // the time that the slowest operation took, in milliseconds.
+}
+
+// Report holds the anonymized metrics of a single operation, such as an update or a preview.
+type Report struct {
+	CLIVersion      string                            `json:"cliVersion,omitempty"`
+	OS              string                            `json:"os"`
+	Arch            string                            `json:"arch"`
+	Kind            apitype.UpdateKind                `json:"kind"`
+	Succeeded       bool                              `json:"succeeded"`
+	DurationMillis  int64                             `json:"durationMs"`
+	ResourceChanges map[deploy.StepOp]int             `json:"resourceChanges,omitempty"`
+	Operations      map[deploy.StepOp]*OperationStats `json:"operations,omitempty"`
+	ErrorCodes      []string                          `json:"errorCodes,omitempty"`
+}
+
+// NewReport creates the report of an operation of the given kind that took the given time, from the resource changes
+// it made, the timings of its resource operations, and the error it failed with, if any.
+func NewReport(kind apitype.UpdateKind, duration time.Duration, changes engine.ResourceChanges,
+	timings []engine.StepTiming, err error) Report {
+
+	report := Report{
+		CLIVersion:     version.Version,
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		Kind:           kind,
+		Succeeded:      err == nil,
+		DurationMillis: millis(duration),
+	}
+
+	for op, c := range changes {
+		if c > 0 {
+			if report.ResourceChanges == nil {
+				report.ResourceChanges = make(map[deploy.StepOp]int)
+			}
+			report.ResourceChanges[op] = c
+		}
+	}
+
+	for _, t := range timings {
+		if report.Operations == nil {
+			report.Operations = make(map[deploy.StepOp]*OperationStats)
+		}
+		stats, has := report.Operations[t.Op]
+		if !has {
+			stats = &OperationStats{}
+			report.Operations[t.Op] = stats
+		}
+
+		stats.Count++
+		if t.Failed {
+			stats.Failed++
+		}
+		stats.TotalMillis += millis(t.Duration)
+		if ms := millis(t.Duration); ms > stats.MaxMillis {
+			stats.MaxMillis = ms
+		}
+	}
+
+	if id, ok := diag.ErrorID(err); ok {
+		report.ErrorCodes = []string{id.Code()}
+	}
+
+	return report
+}
+
+func millis(d time.Duration) int64 {
+	return int64(d / time.Millisecond)
+}
+
+// State records whether the user has opted in to telemetry, along with the most recent report, so that users may see
+// exactly what is sent.
+type State struct {
+	Enabled        bool    `json:"enabled"`
+	LastReport     *Report `json:"lastReport,omitempty"`
+	LastReportSent bool    `json:"lastReportSent,omitempty"`
+}
+
+// LoadState loads the user's telemetry state.  Telemetry is off unless the user has turned it on.
+func LoadState() (*State, error) {
+	path, err := workspace.GetTelemetryFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "reading '%s'", path)
+	}
+
+	var state State
+	if err = json.Unmarshal(b, &state); err != nil {
+		return nil, errors.Wrapf(err, "parsing '%s'", path)
+	}
+	return &state, nil
+}
+
+// Save saves the user's telemetry state.
+func (s *State) Save() error {
+	path, err := workspace.GetTelemetryFilePath()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0600)
+}
+
+// Send sends a report to the given endpoint.
+func Send(endpoint string, report Report) error {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: sendTimeout}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrap(err, "sending telemetry")
+	}
+	defer contract.IgnoreClose(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("sending telemetry: %s", resp.Status)
+	}
+	return nil
+}
+
+// Record records a report as the most recent one, so that it may be previewed with `pulumi telemetry status`, and, if
+// the user has opted in, sends it to the given endpoint.  The endpoint may be overridden by $PULUMI_TELEMETRY_ENDPOINT.
+func Record(endpoint string, report Report) error {
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+
+	if override := os.Getenv(EndpointEnvVar); override != "" {
+		endpoint = override
+	}
+
+	var sendErr error
+	state.LastReport, state.LastReportSent = &report, false
+	if state.Enabled {
+		sendErr = Send(endpoint, report)
+		state.LastReportSent = sendErr == nil
+	}
+
+	if err = state.Save(); err != nil {
+		return err
+	}
+	return sendErr
+}
@@ -0,0 +1,102 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestNewReport(t *testing.T) {
+	t.Parallel()
+
+	changes := engine.ResourceChanges{deploy.OpCreate: 2, deploy.OpSame: 5, deploy.OpDelete: 0}
+	timings := []engine.StepTiming{
+		{URN: "urn:pulumi:secret-stack::secret-project::aws:s3/bucket:Bucket::secret-bucket",
+			Op: deploy.OpCreate, Duration: 2 * time.Second},
+		{URN: "urn:pulumi:secret-stack::secret-project::aws:s3/bucket:Bucket::other-bucket",
+			Op: deploy.OpCreate, Duration: 500 * time.Millisecond, Failed: true},
+	}
+	err := errors.Wrap(plugin.NewMissingError(workspace.PluginInfo{Name: "secret-plugin"}), "loading")
+
+	report := NewReport(apitype.UpdateUpdate, 3*time.Second, changes, timings, err)
+	assert.False(t, report.Succeeded)
+	assert.Equal(t, int64(3000), report.DurationMillis)
+	assert.Equal(t, map[deploy.StepOp]int{deploy.OpCreate: 2, deploy.OpSame: 5}, report.ResourceChanges)
+	assert.Equal(t, &OperationStats{Count: 2, Failed: 1, TotalMillis: 2500, MaxMillis: 2000},
+		report.Operations[deploy.OpCreate])
+	assert.Equal(t, []string{"PU3000"}, report.ErrorCodes)
+
+	// Nothing that names the stack, project, or its resources may appear in the report.
+	b, err := json.Marshal(report)
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(b), "secret"), string(b))
+}
+
+func TestRecord(t *testing.T) {
+	home, err := ioutil.TempDir("", "telemetry")
+	assert.NoError(t, err)
+	defer func() {
+		contract.IgnoreError(os.RemoveAll(home))
+	}()
+	oldHome := os.Getenv(workspace.PulumiHomeEnvVar)
+	defer func() {
+		contract.IgnoreError(os.Setenv(workspace.PulumiHomeEnvVar, oldHome))
+	}()
+	assert.NoError(t, os.Setenv(workspace.PulumiHomeEnvVar, home))
+
+	var received []Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report Report
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&report))
+		received = append(received, report)
+	}))
+	defer server.Close()
+
+	// Telemetry is off by default: reports are recorded, so that they may be previewed, but not sent.
+	report := NewReport(apitype.PreviewUpdate, time.Second, nil, nil, nil)
+	assert.NoError(t, Record(server.URL, report))
+	assert.Len(t, received, 0)
+	state, err := LoadState()
+	assert.NoError(t, err)
+	assert.False(t, state.Enabled)
+	assert.Equal(t, &report, state.LastReport)
+	assert.False(t, state.LastReportSent)
+
+	// Once turned on, they are sent.
+	state.Enabled = true
+	assert.NoError(t, state.Save())
+	assert.NoError(t, Record(server.URL, report))
+	assert.Equal(t, []Report{report}, received)
+	state, err = LoadState()
+	assert.NoError(t, err)
+	assert.True(t, state.LastReportSent)
+}
@@ -24,16 +24,27 @@ import (
 
 var snk diag.Sink
 
-var globalColorization = colors.Always
+var globalColorization colors.Colorization
+
+// NoColorEnvVar is the environment variable that, when set to a non-empty value, disables colorized output unless
+// it's asked for explicitly.  See https://no-color.org.
+const NoColorEnvVar = "NO_COLOR"
+
+// NoColor returns true if the NO_COLOR environment variable asks that output not be colorized.
+func NoColor() bool {
+	return os.Getenv(NoColorEnvVar) != ""
+}
 
 // GetGlobalColorization gets the global setting for how things should be colored.
 // This is helpful for the parts of our stack that do not take a DisplayOptions struct.
 func GetGlobalColorization() colors.Colorization {
-	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+	if globalColorization != "" {
+		return globalColorization
+	}
+	if NoColor() {
 		return colors.Never
 	}
-
-	return globalColorization
+	return colors.Always
 }
 
 // SetGlobalColorization sets the global setting for how things should be colored.
@@ -105,7 +105,9 @@ func RunFunc(run func(cmd *cobra.Command, args []string) error) func(*cobra.Comm
 				logging.V(3).Infof(DetailedError(err))
 			}
 
-			ExitError(msg)
+			// Errors of a class with a stable code are reported with it, so that they can be looked up.
+			id, _ := diag.ErrorID(err)
+			exitDiag(-1, &diag.Diag{ID: id, Message: msg})
 		}
 	}
 }
@@ -122,7 +124,12 @@ func ExitError(msg string, args ...interface{}) {
 
 // exitErrorCode issues an error and exists with the given error exit code.
 func exitErrorCode(code int, msg string, args ...interface{}) {
-	Diag().Errorf(diag.Message("", msg), args...)
+	exitDiag(code, diag.Message("", msg), args...)
+}
+
+// exitDiag issues an error diagnostic and exits with the given error exit code.
+func exitDiag(code int, d *diag.Diag, args ...interface{}) {
+	Diag().Errorf(d, args...)
 	os.Exit(code)
 }
 
@@ -133,7 +140,7 @@ func errorMessage(err error) string {
 		if len(wr) == 1 {
 			return errorMessage(wr[0])
 		}
-		msg := fmt.Sprintf("%d errors occurred:", len(wr))
+		msg := fmt.Sprintf(diag.Localize("%d errors occurred:"), len(wr))
 		for i, werr := range wr {
 			msg += fmt.Sprintf("\n    %d) %s", i+1, errorMessage(werr))
 		}
@@ -0,0 +1,69 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+)
+
+// InitLocale selects the locale for user-facing messages, as detected from the environment, and registers the
+// message catalogs for it that are found in the given directory.  Catalogs are named for their locales, such as
+// `pt-BR.json`; the catalog for a locale's language, such as `pt.json`, is loaded too, to fall back on.
+func InitLocale(dir string) error {
+	locale := diag.DetectLocale()
+	diag.SetLocale(locale)
+
+	for l := locale; l != ""; {
+		catalog, err := diag.LoadCatalog(filepath.Join(dir, l+".json"))
+		if err == nil {
+			diag.RegisterCatalog(l, catalog)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
+		i := strings.LastIndex(l, "-")
+		if i == -1 {
+			break
+		}
+		l = l[:i]
+	}
+	return nil
+}
+
+// LocalizeCommand translates the help of a command, of its flags, and of all of its subcommands.
+func LocalizeCommand(cmd *cobra.Command) {
+	cmd.Short = diag.Localize(cmd.Short)
+	cmd.Long = diag.Localize(cmd.Long)
+	// A command's persistent flags may be amongst its flags too, so take care to only translate each once.
+	seen := make(map[*pflag.Flag]bool)
+	localizeFlag := func(f *pflag.Flag) {
+		if !seen[f] {
+			seen[f] = true
+			f.Usage = diag.Localize(f.Usage)
+		}
+	}
+	cmd.Flags().VisitAll(localizeFlag)
+	cmd.PersistentFlags().VisitAll(localizeFlag)
+	for _, sub := range cmd.Commands() {
+		LocalizeCommand(sub)
+	}
+}
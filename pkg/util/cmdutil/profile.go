@@ -16,39 +16,74 @@ package cmdutil
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"runtime/pprof"
 	"runtime/trace"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
 
+	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 
 	"github.com/pulumi/pulumi/pkg/util/contract"
 )
 
+var (
+	profileStart  time.Time    // the time at which profiling began.
+	profileCPU    *os.File     // the file to which the CPU profile is written.
+	profileExec   *os.File     // the file to which the execution trace is written.
+	profileTimers *phaseTracer // the tracer that times engine phases, if tracing is not otherwise enabled.
+)
+
+// InitProfiling begins capturing a CPU profile and an execution trace into files named after the given prefix.  Unless
+// spans are already being sent to a tracing endpoint, it also installs a tracer that times each of the engine's phases
+// and RPCs, so that CloseProfiling can write a summary of where the operation spent its time.
 func InitProfiling(prefix string) error {
+	profileStart = time.Now()
+
+	if _, isNoop := opentracing.GlobalTracer().(opentracing.NoopTracer); isNoop {
+		profileTimers = newPhaseTracer()
+		opentracing.SetGlobalTracer(profileTimers)
+	}
+
 	cpu, err := os.Create(fmt.Sprintf("%s.%v.cpu", prefix, os.Getpid()))
 	if err != nil {
 		return errors.Wrap(err, "could not start CPU profile")
 	}
 	if err = pprof.StartCPUProfile(cpu); err != nil {
+		contract.IgnoreClose(cpu)
 		return errors.Wrap(err, "could not start CPU profile")
 	}
+	profileCPU = cpu
 
 	exec, err := os.Create(fmt.Sprintf("%s.%v.trace", prefix, os.Getpid()))
 	if err != nil {
 		return errors.Wrap(err, "could not start execution trace")
 	}
 	if err = trace.Start(exec); err != nil {
+		contract.IgnoreClose(exec)
 		return errors.Wrap(err, "could not start execution trace")
 	}
+	profileExec = exec
 
 	return nil
 }
 
+// CloseProfiling stops the profiles begun by InitProfiling and writes them, along with a heap profile and a summary of
+// the operation's timing, into files named after the given prefix.
 func CloseProfiling(prefix string) error {
 	pprof.StopCPUProfile()
 	trace.Stop()
+	if profileCPU != nil {
+		contract.IgnoreClose(profileCPU)
+	}
+	if profileExec != nil {
+		contract.IgnoreClose(profileExec)
+	}
 
 	mem, err := os.Create(fmt.Sprintf("%s.%v.mem", prefix, os.Getpid()))
 	if err != nil {
@@ -61,5 +96,145 @@ func CloseProfiling(prefix string) error {
 		return errors.Wrap(err, "could not write memory profile")
 	}
 
+	timing, err := os.Create(fmt.Sprintf("%s.%v.timing", prefix, os.Getpid()))
+	if err != nil {
+		return errors.Wrap(err, "could not create timing summary")
+	}
+	defer contract.IgnoreClose(timing)
+
+	if err = writeTimingSummary(timing, time.Since(profileStart), profileTimers); err != nil {
+		return errors.Wrap(err, "could not write timing summary")
+	}
+
 	return nil
 }
+
+// writeTimingSummary writes the total duration of an operation and, if available, the time spent in each of the
+// phases recorded by the given tracer, longest first.
+func writeTimingSummary(w io.Writer, total time.Duration, timers *phaseTracer) error {
+	if _, err := fmt.Fprintf(w, "Total time: %v\n", total); err != nil {
+		return err
+	}
+	if timers == nil {
+		_, err := fmt.Fprintf(w, "Phase timings are unavailable while tracing is enabled.\n")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	if _, err := fmt.Fprintf(tw, "\nPHASE\tCOUNT\tTOTAL\tMAX\n"); err != nil {
+		return err
+	}
+	for _, p := range timers.Phases() {
+		if _, err := fmt.Fprintf(tw, "%s\t%d\t%v\t%v\n", p.Name, p.Count, p.Total, p.Max); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// phaseTiming records the time spent in each occurrence of a single phase.
+type phaseTiming struct {
+	Name  string        // the name of the phase.
+	Count int           // the number of times the phase occurred.
+	Total time.Duration // the total time spent in the phase.
+	Max   time.Duration // the time spent in the longest occurrence of the phase.
+}
+
+// phaseTracer is an OpenTracing tracer that, rather than reporting spans, accumulates their durations by operation
+// name.  Span contexts are not propagated, so it never affects the RPCs across which spans are carried.
+type phaseTracer struct {
+	opentracing.NoopTracer // the tracer that handles everything but timing.
+
+	lock   sync.Mutex
+	phases map[string]*phaseTiming
+}
+
+func newPhaseTracer() *phaseTracer {
+	return &phaseTracer{phases: make(map[string]*phaseTiming)}
+}
+
+// StartSpan starts a span that records its duration with this tracer when it is finished.
+func (t *phaseTracer) StartSpan(name string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	var options opentracing.StartSpanOptions
+	for _, opt := range opts {
+		opt.Apply(&options)
+	}
+	start := options.StartTime
+	if start.IsZero() {
+		start = time.Now()
+	}
+	return &timedSpan{Span: t.NoopTracer.StartSpan(name, opts...), tracer: t, name: name, start: start}
+}
+
+// record adds a single occurrence of the named phase.
+func (t *phaseTracer) record(name string, d time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p, has := t.phases[name]
+	if !has {
+		p = &phaseTiming{Name: name}
+		t.phases[name] = p
+	}
+	p.Count++
+	p.Total += d
+	if d > p.Max {
+		p.Max = d
+	}
+}
+
+// Phases returns the timings recorded so far, ordered from the longest total time to the shortest.
+func (t *phaseTracer) Phases() []phaseTiming {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var result []phaseTiming
+	for _, p := range t.phases {
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// timedSpan is a span that reports its duration to a phaseTracer when it is finished.
+type timedSpan struct {
+	opentracing.Span // the span that handles everything but timing.
+
+	tracer *phaseTracer
+	name   string
+	start  time.Time
+}
+
+func (s *timedSpan) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+func (s *timedSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	end := opts.FinishTime
+	if end.IsZero() {
+		end = time.Now()
+	}
+	s.tracer.record(s.name, end.Sub(s.start))
+}
+
+func (s *timedSpan) SetOperationName(name string) opentracing.Span {
+	s.name = name
+	return s
+}
+
+func (s *timedSpan) SetTag(key string, value interface{}) opentracing.Span {
+	return s
+}
+
+func (s *timedSpan) SetBaggageItem(key, value string) opentracing.Span {
+	return s
+}
+
+func (s *timedSpan) Tracer() opentracing.Tracer {
+	return s.tracer
+}
@@ -0,0 +1,35 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package glob matches strings against simple glob patterns, in which `*` matches any sequence of characters and
+// every other character matches itself.
+package glob
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Compile returns a regular expression that matches the whole of any string matched by at least one of the given
+// patterns.  If there are no patterns, the expression matches nothing.
+func Compile(patterns ...string) *regexp.Regexp {
+	if len(patterns) == 0 {
+		return regexp.MustCompile("$.")
+	}
+	quoted := make([]string, len(patterns))
+	for i, p := range patterns {
+		quoted[i] = strings.Replace(regexp.QuoteMeta(p), `\*`, ".*", -1)
+	}
+	return regexp.MustCompile("^(" + strings.Join(quoted, "|") + ")$")
+}
@@ -0,0 +1,37 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	re := Compile("aws:s3/*", "aws:ec2/instance:Instance")
+	assert.True(t, re.MatchString("aws:s3/bucket:Bucket"))
+	assert.True(t, re.MatchString("aws:ec2/instance:Instance"))
+	assert.False(t, re.MatchString("aws:ec2/instance:InstanceProfile"))
+	assert.False(t, re.MatchString("xaws:s3/bucket:Bucket"))
+
+	// Characters other than `*` match only themselves.
+	assert.True(t, Compile("a.b").MatchString("a.b"))
+	assert.False(t, Compile("a.b").MatchString("axb"))
+
+	// With no patterns, nothing matches.
+	assert.False(t, Compile().MatchString(""))
+	assert.False(t, Compile().MatchString("anything"))
+}
@@ -26,6 +26,7 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"os"
 	"regexp"
 	"strconv"
 	"sync"
@@ -44,24 +45,26 @@ var LogFlow = false     // true to flow logging settings to child processes.
 var rwLock sync.RWMutex
 var filters []Filter
 
-func V(level glog.Level) glog.Verbose {
-	return glog.V(level)
+// V returns a VerboseLogger that logs only if verbose logging is enabled at the given level.
+func V(level glog.Level) VerboseLogger {
+	return Default.V(level)
 }
 
 func Errorf(format string, args ...interface{}) {
-	glog.Errorf("%s", FilterString(fmt.Sprintf(format, args...)))
+	output(errorSeverity, Default, fmt.Sprintf(format, args...))
 }
 
 func Infof(format string, args ...interface{}) {
-	glog.Infof("%s", FilterString(fmt.Sprintf(format, args...)))
+	output(infoSeverity, Default, fmt.Sprintf(format, args...))
 }
 
 func Warningf(format string, args ...interface{}) {
-	glog.Warningf("%s", FilterString(fmt.Sprintf(format, args...)))
+	output(warningSeverity, Default, fmt.Sprintf(format, args...))
 }
 
 func Flush() {
 	glog.Flush()
+	flushOutput()
 }
 
 // InitLogging ensures the logging library has been initialized with the given settings.
@@ -83,6 +86,14 @@ func InitLogging(logToStderr bool, verbose int, logFlow bool) {
 	}
 }
 
+// LogDir returns the directory into which log files are written.
+func LogDir() string {
+	if f := flag.Lookup("log_dir"); f != nil && f.Value.String() != "" {
+		return f.Value.String()
+	}
+	return os.TempDir()
+}
+
 func assertNoError(err error) {
 	if err != nil {
 		failfast(err.Error())
@@ -15,6 +15,11 @@
 package logging
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -42,3 +47,62 @@ func TestFilter(t *testing.T) {
 	msg2 := filter2.Filter("These are my secrets: secret1, secret2, secret3, secret.*, secre[t]3")
 	assert.Equal(t, msg2, "These are my secrets: secret1, secret2, secret3, [creds], [creds]")
 }
+
+func TestParseModuleLevels(t *testing.T) {
+	levels, err := ParseModuleLevels("engine=9, backend=3")
+	assert.NoError(t, err)
+	assert.Equal(t, map[Module]int{Engine: 9, Backend: 3}, levels)
+
+	levels, err = ParseModuleLevels("")
+	assert.NoError(t, err)
+	assert.Len(t, levels, 0)
+
+	_, err = ParseModuleLevels("engine")
+	assert.Error(t, err)
+	_, err = ParseModuleLevels("engin=3")
+	assert.Error(t, err)
+	_, err = ParseModuleLevels("engine=loud")
+	assert.Error(t, err)
+}
+
+func TestModuleLevels(t *testing.T) {
+	SetModuleLevels(map[Module]int{Engine: 7})
+	defer SetModuleLevels(nil)
+
+	assert.True(t, Engine.V(7).Enabled())
+	assert.False(t, Engine.V(8).Enabled())
+	assert.Equal(t, 7, Engine.Level())
+
+	// Modules without a level of their own use the global verbosity.
+	assert.Equal(t, Verbose, Backend.Level())
+}
+
+func TestJSONOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logging")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	AddGlobalFilter(CreateFilter([]string{"hunter2"}, "[secret]"))
+	SetModuleLevels(map[Module]int{Provider: 1})
+	defer SetModuleLevels(nil)
+
+	path := filepath.Join(dir, "pulumi.log")
+	assert.NoError(t, InitOutput(path, JSONFormat))
+	Provider.V(1).Infof("the password is %s", "hunter2")
+	Provider.V(2).Infof("too verbose")
+	Flush()
+	assert.NoError(t, InitOutput("", TextFormat))
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 1)
+
+	var msg jsonMessage
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &msg))
+	assert.Equal(t, "info", msg.Severity)
+	assert.Equal(t, "provider", msg.Module)
+	assert.Equal(t, "the password is [secret]", msg.Message)
+
+	assert.Error(t, InitOutput(path, Format("xml")))
+}
@@ -0,0 +1,156 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// Module identifies a subsystem whose logging verbosity may be set independently of the global verbosity.
+type Module string
+
+const (
+	// Default is the module used by code that does not belong to a particular subsystem.  Its verbosity is always
+	// the global verbosity.
+	Default Module = ""
+	// Engine covers planning and deployment.
+	Engine Module = "engine"
+	// Provider covers the RPCs made to plugins, as well as the logging of the plugins themselves.
+	Provider Module = "provider"
+	// Backend covers the backends, including the client for the Pulumi Service.
+	Backend Module = "backend"
+	// Display covers the rendering of engine events.
+	Display Module = "display"
+)
+
+// Modules is the list of modules whose verbosity may be set.
+var Modules = []Module{Engine, Provider, Backend, Display}
+
+var moduleLock sync.RWMutex
+var moduleLevels map[Module]int
+
+// VerboseLogger logs messages only if verbose logging is enabled at the level with which it was created.  Unlike glog's
+// Verbose, every message it logs is filtered before being written.
+type VerboseLogger struct {
+	module  Module
+	enabled bool
+}
+
+// V returns a VerboseLogger that logs on behalf of this module only if its verbosity is at least the given level.
+func (m Module) V(level glog.Level) VerboseLogger {
+	if l, has := m.explicitLevel(); has {
+		return VerboseLogger{module: m, enabled: glog.Level(l) >= level}
+	}
+	return VerboseLogger{module: m, enabled: bool(glog.V(level))}
+}
+
+// Level returns this module's verbosity: its own level if one has been set, or the global verbosity otherwise.
+func (m Module) Level() int {
+	if l, has := m.explicitLevel(); has {
+		return l
+	}
+	return Verbose
+}
+
+func (m Module) explicitLevel() (int, bool) {
+	if m == Default {
+		return 0, false
+	}
+	moduleLock.RLock()
+	defer moduleLock.RUnlock()
+	l, has := moduleLevels[m]
+	return l, has
+}
+
+// Enabled returns true if this VerboseLogger will log messages.
+func (v VerboseLogger) Enabled() bool {
+	return v.enabled
+}
+
+func (v VerboseLogger) Info(args ...interface{}) {
+	if v.enabled {
+		output(infoSeverity, v.module, fmt.Sprint(args...))
+	}
+}
+
+func (v VerboseLogger) Infoln(args ...interface{}) {
+	if v.enabled {
+		output(infoSeverity, v.module, fmt.Sprintln(args...))
+	}
+}
+
+func (v VerboseLogger) Infof(format string, args ...interface{}) {
+	if v.enabled {
+		output(infoSeverity, v.module, fmt.Sprintf(format, args...))
+	}
+}
+
+// SetModuleLevels sets the verbosity of individual modules; modules that are not present use the global verbosity.
+func SetModuleLevels(levels map[Module]int) {
+	moduleLock.Lock()
+	defer moduleLock.Unlock()
+	moduleLevels = levels
+}
+
+// ParseModuleLevels parses a comma-separated list of module=level pairs, e.g. "engine=9,backend=3".
+func ParseModuleLevels(spec string) (map[Module]int, error) {
+	levels := make(map[Module]int)
+	if strings.TrimSpace(spec) == "" {
+		return levels, nil
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("expected module=level, got %q", pair)
+		}
+
+		m := Module(strings.TrimSpace(parts[0]))
+		if !isModule(m) {
+			return nil, errors.Errorf("unknown logging module %q; known modules are %s", m, moduleNames())
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || level < 0 {
+			return nil, errors.Errorf("invalid level %q for logging module %q", parts[1], m)
+		}
+		levels[m] = level
+	}
+	return levels, nil
+}
+
+func isModule(m Module) bool {
+	for _, known := range Modules {
+		if m == known {
+			return true
+		}
+	}
+	return false
+}
+
+func moduleNames() string {
+	var names []string
+	for _, m := range Modules {
+		names = append(names, string(m))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
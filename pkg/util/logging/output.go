@@ -0,0 +1,158 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// Format is the format in which log messages are written.
+type Format string
+
+const (
+	// TextFormat writes each message on a line of its own, prefixed with glog's header.
+	TextFormat Format = "text"
+	// JSONFormat writes each message as a JSON object on a line of its own.
+	JSONFormat Format = "json"
+)
+
+type severity string
+
+const (
+	infoSeverity    severity = "info"
+	warningSeverity severity = "warning"
+	errorSeverity   severity = "error"
+)
+
+// jsonMessage is the JSON representation of a single log message.
+type jsonMessage struct {
+	Time     string `json:"time"`
+	Severity string `json:"severity"`
+	Module   string `json:"module,omitempty"`
+	Message  string `json:"msg"`
+}
+
+// logOutput is the destination of log messages when they are not being written through glog.
+var logOutput struct {
+	sync.Mutex
+	w      io.Writer
+	file   *os.File
+	format Format
+}
+
+// InitOutput redirects log messages to the file at the given path, in the given format.  If the path is empty,
+// messages in the text format continue to be written through glog; messages in the JSON format are written to stderr
+// if logging to stderr was requested, and to a file in the log directory otherwise.
+func InitOutput(path string, format Format) error {
+	switch format {
+	case "", TextFormat:
+		format = TextFormat
+	case JSONFormat:
+	default:
+		return errors.Errorf("unknown log format %q; expected %q or %q", format, TextFormat, JSONFormat)
+	}
+
+	if path == "" && format == JSONFormat && !LogToStderr {
+		path = filepath.Join(LogDir(), fmt.Sprintf("pulumi.%d.log.json", os.Getpid()))
+	}
+
+	var w io.Writer
+	var file *os.File
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return errors.Wrap(err, "opening log file")
+		}
+		w, file = f, f
+	} else if format == JSONFormat {
+		w = os.Stderr
+	}
+
+	logOutput.Lock()
+	defer logOutput.Unlock()
+	if logOutput.file != nil {
+		_ = logOutput.file.Close()
+	}
+	logOutput.w, logOutput.file, logOutput.format = w, file, format
+	return nil
+}
+
+// output filters a message and writes it to the current destination.
+func output(sev severity, m Module, msg string) {
+	msg = strings.TrimSuffix(FilterString(msg), "\n")
+
+	logOutput.Lock()
+	defer logOutput.Unlock()
+
+	if logOutput.w == nil {
+		// Skip output and its caller, so that glog attributes the message to the code that logged it.
+		if m != Default {
+			msg = string(m) + ": " + msg
+		}
+		switch sev {
+		case errorSeverity:
+			glog.ErrorDepth(2, msg)
+		case warningSeverity:
+			glog.WarningDepth(2, msg)
+		default:
+			glog.InfoDepth(2, msg)
+		}
+		return
+	}
+
+	now := time.Now()
+	var line []byte
+	if logOutput.format == JSONFormat {
+		b, err := json.Marshal(jsonMessage{
+			Time:     now.Format(time.RFC3339Nano),
+			Severity: string(sev),
+			Module:   string(m),
+			Message:  msg,
+		})
+		if err != nil {
+			return
+		}
+		line = append(b, '\n')
+	} else {
+		module := string(m)
+		if module == "" {
+			module = "-"
+		}
+		line = []byte(fmt.Sprintf("%c%s %d %s] %s\n",
+			strings.ToUpper(string(sev))[0], now.Format("0102 15:04:05.000000"), os.Getpid(), module, msg))
+	}
+
+	// There is nowhere to report a failure to write a log message.
+	_, _ = logOutput.w.Write(line)
+}
+
+// flushOutput ensures that all messages written to a log file have reached the disk.
+func flushOutput() {
+	logOutput.Lock()
+	defer logOutput.Unlock()
+	if logOutput.file != nil {
+		_ = logOutput.file.Sync()
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// DeployManifestFile is the name of the file that describes the stacks that `pulumi deploy` operates on together.
+const DeployManifestFile = "PulumiDeploy.yaml"
+
+// DeployManifest describes a set of stacks, possibly of many projects, that are deployed together, along with the
+// order in which they must be deployed: a stack that reads another's outputs through a stack reference must not be
+// updated until the other has been.
+type DeployManifest struct {
+	Stacks map[string]*DeployManifestStack `json:"stacks" yaml:"stacks"` // the stacks to deploy, by name.
+}
+
+// DeployManifestStack is one of the stacks of a deployment manifest.
+// nolint: lll
+type DeployManifestStack struct {
+	Project   string   `json:"project" yaml:"project"`                         // the directory of the stack's project, relative to the manifest.
+	Stack     string   `json:"stack" yaml:"stack"`                             // the name of the stack.
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"` // the stacks whose outputs this stack references.
+}
+
+// Validate returns an error if a stack of the manifest is incomplete, depends on a stack that the manifest does not
+// describe, or depends on itself, directly or otherwise.
+func (m *DeployManifest) Validate() error {
+	if len(m.Stacks) == 0 {
+		return errors.New("the manifest does not describe any stacks")
+	}
+	for name, s := range m.Stacks {
+		if s == nil || s.Project == "" || s.Stack == "" {
+			return errors.Errorf("stack '%s' must give both a project and a stack", name)
+		}
+		for _, dep := range s.DependsOn {
+			if _, has := m.Stacks[dep]; !has {
+				return errors.Errorf("stack '%s' depends on '%s', which the manifest does not describe", name, dep)
+			}
+		}
+	}
+	_, err := m.Order()
+	return err
+}
+
+// Order returns the names of the manifest's stacks in an order in which each stack comes after all of those that it
+// depends on.  Stacks that could come in either order are given in lexical order, so that the result is stable.
+func (m *DeployManifest) Order() ([]string, error) {
+	var names []string
+	for name := range m.Stacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var order []string
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.Errorf("stacks depend on each other in a cycle: %s", strings.Join(append(path, name), " -> "))
+		}
+		state[name] = visiting
+		deps := append([]string{}, m.Stacks[name].DependsOn...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// LoadDeployManifest reads and validates a deployment manifest.  The projects of its stacks are resolved relative to
+// the directory that holds it.
+func LoadDeployManifest(path string) (*DeployManifest, error) {
+	contract.Require(path != "", "path")
+
+	m, err := marshallerForPath(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest DeployManifest
+	if err = m.Unmarshal(b, &manifest); err != nil {
+		return nil, errors.Wrapf(err, "reading deployment manifest %s", path)
+	}
+	if err = manifest.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid deployment manifest %s", path)
+	}
+
+	dir := filepath.Dir(path)
+	for _, s := range manifest.Stacks {
+		if !filepath.IsAbs(s.Project) {
+			s.Project = filepath.Join(dir, s.Project)
+		}
+	}
+	return &manifest, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployManifestOrder(t *testing.T) {
+	manifest := &DeployManifest{
+		Stacks: map[string]*DeployManifestStack{
+			"api":      {Project: "services/api", Stack: "dev", DependsOn: []string{"network", "database"}},
+			"database": {Project: "infra/database", Stack: "dev", DependsOn: []string{"network"}},
+			"network":  {Project: "infra/network", Stack: "dev"},
+			"web":      {Project: "services/web", Stack: "dev", DependsOn: []string{"api"}},
+			"dns":      {Project: "infra/dns", Stack: "dev"},
+		},
+	}
+	assert.NoError(t, manifest.Validate())
+
+	order, err := manifest.Order()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"network", "database", "api", "dns", "web"}, order)
+}
+
+func TestDeployManifestValidate(t *testing.T) {
+	assert.Error(t, (&DeployManifest{}).Validate())
+
+	incomplete := &DeployManifest{Stacks: map[string]*DeployManifestStack{"a": {Project: "a"}}}
+	assert.Error(t, incomplete.Validate())
+
+	unknown := &DeployManifest{
+		Stacks: map[string]*DeployManifestStack{"a": {Project: "a", Stack: "dev", DependsOn: []string{"b"}}},
+	}
+	assert.Error(t, unknown.Validate())
+
+	cycle := &DeployManifest{
+		Stacks: map[string]*DeployManifestStack{
+			"a": {Project: "a", Stack: "dev", DependsOn: []string{"c"}},
+			"b": {Project: "b", Stack: "dev", DependsOn: []string{"a"}},
+			"c": {Project: "c", Stack: "dev", DependsOn: []string{"b"}},
+		},
+	}
+	err := cycle.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "a -> c -> b -> a")
+	}
+}
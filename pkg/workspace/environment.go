@@ -0,0 +1,330 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/encoding"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+const (
+	// EnvironmentDir is the name of the directory, within the bookkeeping directory, in which environments are stored.
+	EnvironmentDir = "environments"
+	// EnvironmentPathEnvVar may name a directory in which to store environments instead, e.g. a checkout of a
+	// repository of environments that a team shares.
+	EnvironmentPathEnvVar = "PULUMI_ENVIRONMENT_PATH"
+)
+
+// Environment is a named set of configuration values that may be attached to any number of stacks, so that values
+// which many stacks share need not be copied into each of their configurations.  An environment may import other
+// environments, whose values its own override.  It may also fetch short-lived credentials whenever an operation on a
+// stack starts, which are never stored.
+// nolint: lll
+type Environment struct {
+	Imports     []string                        `json:"imports,omitempty" yaml:"imports,omitempty"`         // the environments whose values this one imports, in order of increasing precedence.
+	Values      map[config.Key]EnvironmentValue `json:"values,omitempty" yaml:"values,omitempty"`           // the configuration values that the environment defines.
+	Credentials []EnvironmentCredentials        `json:"credentials,omitempty" yaml:"credentials,omitempty"` // the short-lived credentials that the environment fetches.
+}
+
+// EnvironmentValue is a configuration value defined by an environment.  It is either given literally or, so that
+// secrets need not be written down, read when the environment is used: from an environment variable, a file, or the
+// output of a command, which may e.g. fetch it from a cloud provider's secret store.  Only one source may be given.
+// nolint: lll
+type EnvironmentValue struct {
+	Value       string `json:"value,omitempty" yaml:"value,omitempty"`             // the literal value.
+	Secret      bool   `json:"secret,omitempty" yaml:"secret,omitempty"`           // true if the value must be treated as a secret.
+	FromEnv     string `json:"fromEnv,omitempty" yaml:"fromEnv,omitempty"`         // the environment variable to read the value from.
+	FromFile    string `json:"fromFile,omitempty" yaml:"fromFile,omitempty"`       // the file to read the value from.
+	FromCommand string `json:"fromCommand,omitempty" yaml:"fromCommand,omitempty"` // the shell command whose output is the value.
+}
+
+// Validate returns an error if the value has more than one source, or is a secret given literally.
+func (v EnvironmentValue) Validate() error {
+	var sources []string
+	for _, source := range []string{v.FromEnv, v.FromFile, v.FromCommand} {
+		if source != "" {
+			sources = append(sources, source)
+		}
+	}
+	if len(sources) > 1 || len(sources) == 1 && v.Value != "" {
+		return errors.New("only one of value, fromEnv, fromFile, and fromCommand may be given")
+	}
+	if v.Secret && len(sources) == 0 {
+		return errors.New("secrets must be read with fromEnv, fromFile, or fromCommand rather than given literally")
+	}
+	return nil
+}
+
+// MarshalYAML writes a literal value that is not a secret as a plain string.
+func (v EnvironmentValue) MarshalYAML() (interface{}, error) {
+	if v == (EnvironmentValue{Value: v.Value}) {
+		return v.Value, nil
+	}
+	type value EnvironmentValue
+	return value(v), nil
+}
+
+// UnmarshalYAML reads a value written either as a plain string, which is a literal value, or as an object.
+func (v *EnvironmentValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*v = EnvironmentValue{Value: s}
+		return nil
+	}
+	type value EnvironmentValue
+	return unmarshal((*value)(v))
+}
+
+// EnvironmentCredentials fetches short-lived credentials for a cloud provider when an operation starts, which are given
+// to the stack's program and providers in environment variables or configuration.  Exactly one kind of credentials may
+// be given.
+// nolint: lll
+type EnvironmentCredentials struct {
+	AWS   *AWSCredentials   `json:"aws,omitempty" yaml:"aws,omitempty"`     // credentials for a role assumed with AWS STS.
+	GCP   *GCPCredentials   `json:"gcp,omitempty" yaml:"gcp,omitempty"`     // an access token for an impersonated GCP service account.
+	Vault *VaultCredentials `json:"vault,omitempty" yaml:"vault,omitempty"` // a lease on secrets read from HashiCorp Vault.
+}
+
+// Validate returns an error if the credentials are not of exactly one kind, or are missing a required setting.
+func (c EnvironmentCredentials) Validate() error {
+	kinds := 0
+	if c.AWS != nil {
+		kinds++
+		if c.AWS.RoleARN == "" {
+			return errors.New("aws credentials require a roleArn")
+		}
+	}
+	if c.GCP != nil {
+		kinds++
+		if c.GCP.ServiceAccount == "" {
+			return errors.New("gcp credentials require a serviceAccount")
+		}
+	}
+	if c.Vault != nil {
+		kinds++
+		if c.Vault.Path == "" {
+			return errors.New("vault credentials require a path")
+		}
+		if len(c.Vault.EnvVars) == 0 && len(c.Vault.Config) == 0 {
+			return errors.New("vault credentials require envVars or config to which to give the secrets read")
+		}
+	}
+	if kinds != 1 {
+		return errors.New("exactly one of aws, gcp, and vault credentials must be given")
+	}
+	return nil
+}
+
+// AWSCredentials assumes an IAM role with AWS STS, using the AWS CLI and whatever credentials it is configured with,
+// and gives the role's temporary credentials to the operation in the standard AWS environment variables.
+// nolint: lll
+type AWSCredentials struct {
+	RoleARN     string `json:"roleArn" yaml:"roleArn"`                             // the ARN of the role to assume.
+	SessionName string `json:"sessionName,omitempty" yaml:"sessionName,omitempty"` // the name of the role session, which defaults to "pulumi".
+	ExternalID  string `json:"externalId,omitempty" yaml:"externalId,omitempty"`   // the external ID that the role's trust policy requires, if any.
+	Duration    string `json:"duration,omitempty" yaml:"duration,omitempty"`       // how long the credentials last, e.g. "1h"; the role's default if empty.
+}
+
+// GCPCredentials impersonates a GCP service account, using the gcloud CLI and whatever account it is logged into, and
+// gives the access token to the operation in the GOOGLE_OAUTH_ACCESS_TOKEN environment variable.
+// nolint: lll
+type GCPCredentials struct {
+	ServiceAccount string `json:"serviceAccount" yaml:"serviceAccount"` // the email address of the service account to impersonate.
+}
+
+// VaultCredentials reads a path of HashiCorp Vault, such as that of a secrets engine that leases dynamic credentials,
+// using the vault CLI and whatever token it is configured with.  Fields of the data read are given to the operation in
+// environment variables or as secret configuration values.
+// nolint: lll
+type VaultCredentials struct {
+	Path    string                `json:"path" yaml:"path"`                           // the path to read, e.g. "aws/creds/deploy".
+	EnvVars map[string]string     `json:"envVars,omitempty" yaml:"envVars,omitempty"` // the fields of the data to give in each environment variable.
+	Config  map[config.Key]string `json:"config,omitempty" yaml:"config,omitempty"`   // the fields of the data to give as each configuration value.
+}
+
+var environmentNameRE = regexp.MustCompile("^[a-zA-Z0-9-_.]{1,100}$")
+
+// ValidateEnvironmentName returns an error if the given name may not be used for an environment.
+func ValidateEnvironmentName(name string) error {
+	if !environmentNameRE.MatchString(name) {
+		return errors.Errorf("environment names may only contain alphanumeric, hyphens, underscores, or periods, "+
+			"and may be at most 100 characters long: '%s'", name)
+	}
+	return nil
+}
+
+// GetEnvironmentDir returns the directory in which environments are stored.
+func GetEnvironmentDir() (string, error) {
+	if dir := os.Getenv(EnvironmentPathEnvVar); dir != "" {
+		return dir, nil
+	}
+
+	home, err := GetPulumiHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, EnvironmentDir), nil
+}
+
+// environmentPath returns the path of the file in which the named environment is stored.
+func environmentPath(name string) (string, error) {
+	if err := ValidateEnvironmentName(name); err != nil {
+		return "", err
+	}
+	dir, err := GetEnvironmentDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+encoding.YAMLExt), nil
+}
+
+// LoadEnvironment reads the named environment.
+func LoadEnvironment(name string) (*Environment, error) {
+	path, err := environmentPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, errors.Errorf("no environment named '%s' exists", name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var env Environment
+	if err = encoding.YAML.Unmarshal(b, &env); err != nil {
+		return nil, errors.Wrapf(err, "could not read environment '%s'", name)
+	}
+	for key, v := range env.Values {
+		if err = v.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "environment '%s' has an invalid value for %s", name, key)
+		}
+	}
+	for _, c := range env.Credentials {
+		if err = c.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "environment '%s' has invalid credentials", name)
+		}
+	}
+	return &env, nil
+}
+
+// SaveEnvironment writes the named environment.
+func SaveEnvironment(name string, env *Environment) error {
+	contract.Require(env != nil, "env")
+
+	path, err := environmentPath(name)
+	if err != nil {
+		return err
+	}
+
+	b, err := encoding.YAML.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	// nolint: gas, gas prefers 0700 for a directory, but 0755 (so group and world can read it) is what we prefer
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// RemoveEnvironment deletes the named environment.
+func RemoveEnvironment(name string) error {
+	path, err := environmentPath(name)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); os.IsNotExist(err) {
+		return errors.Errorf("no environment named '%s' exists", name)
+	}
+	return err
+}
+
+// ListEnvironments returns the sorted names of the environments that exist.
+func ListEnvironments() ([]string, error) {
+	dir, err := GetEnvironmentDir()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() && filepath.Ext(file.Name()) == encoding.YAMLExt {
+			names = append(names, strings.TrimSuffix(file.Name(), encoding.YAMLExt))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// FlattenEnvironments returns a single environment with the values and credentials of the named environments,
+// including those that they import.  Each environment's values take precedence over those that it imports, and later
+// environments take precedence over earlier ones.  Credentials are fetched in the same order.
+func FlattenEnvironments(names []string) (*Environment, error) {
+	return flattenEnvironments(names, LoadEnvironment)
+}
+
+func flattenEnvironments(names []string, load func(name string) (*Environment, error)) (*Environment, error) {
+	result := &Environment{Values: make(map[config.Key]EnvironmentValue)}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		for _, seen := range path {
+			if seen == name {
+				return errors.Errorf("environment import cycle: %s", strings.Join(append(path, name), " -> "))
+			}
+		}
+
+		env, err := load(name)
+		if err != nil {
+			return err
+		}
+		for _, imported := range env.Imports {
+			if err = visit(imported, append(path, name)); err != nil {
+				return err
+			}
+		}
+		for key, v := range env.Values {
+			result.Values[key] = v
+		}
+		result.Credentials = append(result.Credentials, env.Credentials...)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/encoding"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+)
+
+func TestEnvironmentValueYAML(t *testing.T) {
+	env := Environment{
+		Imports: []string{"base"},
+		Values: map[config.Key]EnvironmentValue{
+			config.MustMakeKey("aws", "region"):   {Value: "us-west-2"},
+			config.MustMakeKey("app", "password"): {Secret: true, FromEnv: "APP_PASSWORD"},
+		},
+	}
+	b, err := encoding.YAML.Marshal(env)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "aws:region: us-west-2\n")
+
+	var roundTripped Environment
+	assert.NoError(t, encoding.YAML.Unmarshal(b, &roundTripped))
+	assert.Equal(t, env, roundTripped)
+}
+
+func TestValidateEnvironmentValue(t *testing.T) {
+	assert.NoError(t, EnvironmentValue{Value: "a"}.Validate())
+	assert.NoError(t, EnvironmentValue{Secret: true, FromCommand: "cat secret"}.Validate())
+	assert.Error(t, EnvironmentValue{Secret: true, Value: "hunter2"}.Validate())
+	assert.Error(t, EnvironmentValue{Value: "a", FromEnv: "A"}.Validate())
+	assert.Error(t, EnvironmentValue{FromFile: "a", FromEnv: "A"}.Validate())
+}
+
+func TestValidateEnvironmentCredentials(t *testing.T) {
+	assert.NoError(t, EnvironmentCredentials{AWS: &AWSCredentials{RoleARN: "arn:aws:iam::1:role/r"}}.Validate())
+	assert.NoError(t, EnvironmentCredentials{Vault: &VaultCredentials{
+		Path: "aws/creds/deploy", EnvVars: map[string]string{"AWS_ACCESS_KEY_ID": "access_key"}}}.Validate())
+	assert.Error(t, EnvironmentCredentials{}.Validate())
+	assert.Error(t, EnvironmentCredentials{AWS: &AWSCredentials{}}.Validate())
+	assert.Error(t, EnvironmentCredentials{Vault: &VaultCredentials{Path: "aws/creds/deploy"}}.Validate())
+	assert.Error(t, EnvironmentCredentials{
+		AWS: &AWSCredentials{RoleARN: "r"}, GCP: &GCPCredentials{ServiceAccount: "sa"}}.Validate())
+}
+
+func TestFlattenEnvironments(t *testing.T) {
+	region, zone := config.MustMakeKey("aws", "region"), config.MustMakeKey("aws", "zone")
+	envs := map[string]*Environment{
+		"base": {
+			Values: map[config.Key]EnvironmentValue{
+				region: {Value: "us-east-1"},
+				zone:   {Value: "a"},
+			},
+			Credentials: []EnvironmentCredentials{{AWS: &AWSCredentials{RoleARN: "base"}}},
+		},
+		"west": {
+			Imports: []string{"base"},
+			Values: map[config.Key]EnvironmentValue{
+				region: {Value: "us-west-2"},
+			},
+			Credentials: []EnvironmentCredentials{{AWS: &AWSCredentials{RoleARN: "west"}}},
+		},
+		"zoneb": {Values: map[config.Key]EnvironmentValue{
+			zone: {Value: "b"},
+		}},
+		"loop1": {Imports: []string{"loop2"}},
+		"loop2": {Imports: []string{"loop1"}},
+	}
+	load := func(name string) (*Environment, error) {
+		if env, has := envs[name]; has {
+			return env, nil
+		}
+		return nil, errors.Errorf("no environment named '%s' exists", name)
+	}
+
+	// An environment's values override those it imports, and later environments override earlier ones.
+	flat, err := flattenEnvironments([]string{"west"}, load)
+	assert.NoError(t, err)
+	assert.Equal(t, "us-west-2", flat.Values[region].Value)
+	assert.Equal(t, "a", flat.Values[zone].Value)
+	assert.Equal(t, []EnvironmentCredentials{{AWS: &AWSCredentials{RoleARN: "base"}},
+		{AWS: &AWSCredentials{RoleARN: "west"}}}, flat.Credentials)
+
+	flat, err = flattenEnvironments([]string{"west", "zoneb"}, load)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", flat.Values[zone].Value)
+
+	_, err = flattenEnvironments([]string{"loop1"}, load)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "loop1 -> loop2 -> loop1")
+	}
+
+	_, err = flattenEnvironments([]string{"missing"}, load)
+	assert.Error(t, err)
+}
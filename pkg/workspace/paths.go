@@ -24,18 +24,26 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/pulumi/pulumi/pkg/encoding"
+	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/fsutil"
 )
 
 //nolint: lll
 const (
+	AuditDir       = "audit"      // the name of the directory that holds the audit logs of stacks.
 	BackupDir      = "backups"    // the name of the folder where backup stack information is stored.
+	BlobDir        = "blobs"      // the name of the directory that holds large property values stored outside of checkpoints.
 	BookkeepingDir = ".pulumi"    // the name of our bookeeping folder, we store state here (like .git for git).
+	CacheDir       = "cache"      // the name of the directory that holds the per-project caches of plugins.
 	ConfigDir      = "config"     // the name of the folder that holds local configuration information.
+	EventsDir      = "events"     // the name of the directory that holds the events of stacks' latest updates.
 	GitDir         = ".git"       // the name of the folder git uses to store information.
 	HistoryDir     = "history"    // the name of the directory that holds historical information for projects.
+	LocaleDir      = "locales"    // the name of the directory containing message catalogs for localization.
 	PluginDir      = "plugins"    // the name of the directory containing plugins.
+	ReferencesDir  = "references" // the name of the directory that holds the stack references of stacks.
+	SchemaDir      = "schemas"    // the name of the directory containing cached provider schemas.
 	StackDir       = "stacks"     // the name of the directory that holds stack information for projects.
 	TemplateDir    = "templates"  // the name of the directory containing templates.
 	WorkspaceDir   = "workspaces" // the name of the directory that holds workspace information for projects.
@@ -45,8 +53,31 @@ const (
 	RepoFile          = "settings.json"      // the name of the file that holds information specific to the entire repository.
 	WorkspaceFile     = "workspace.json"     // the name of the file that holds workspace information.
 	CachedVersionFile = ".cachedVersionInfo" // the name of the file we use to store when we last checked if the CLI was out of date
+	HostDaemonFile    = "host.json"          // the name of the file that records the running plugin host daemon.
+	TelemetryFile     = "telemetry.json"     // the name of the file that records the telemetry opt-in.
 )
 
+// PulumiHomeEnvVar is the name of the environment variable that overrides the directory in which Pulumi keeps its
+// bookkeeping, such as plugins, templates, and local state, which is `~/.pulumi` by default.
+const PulumiHomeEnvVar = "PULUMI_HOME"
+
+// GetPulumiHomeDir returns the directory in which Pulumi keeps its bookkeeping: $PULUMI_HOME if it is set, and
+// otherwise `~/.pulumi`.
+func GetPulumiHomeDir() (string, error) {
+	if dir := os.Getenv(PulumiHomeEnvVar); dir != "" {
+		return dir, nil
+	}
+	u, err := user.Current()
+	if u == nil || err != nil {
+		return "", errors.Wrapf(err, "getting user home directory")
+	}
+	return filepath.Join(u.HomeDir, BookkeepingDir), nil
+}
+
+// ProjectCacheDirEnvVar is the name of the environment variable through which plugins learn the directory that they
+// may cache data for the current project in.
+const ProjectCacheDirEnvVar = "PULUMI_PROJECT_CACHE_DIR"
+
 // DetectProjectPath locates the closest project from the current working directory, or an error if not found.
 func DetectProjectPath() (string, error) {
 	dir, err := os.Getwd()
@@ -70,8 +101,14 @@ func DetectProjectStackPath(stackName tokens.QName) (string, error) {
 		return "", err
 	}
 
-	return filepath.Join(filepath.Dir(projPath), proj.Config, fmt.Sprintf("%s.%s%s", ProjectFile, qnameFileName(stackName),
-		filepath.Ext(projPath))), nil
+	return ProjectStackPath(proj, projPath, stackName), nil
+}
+
+// ProjectStackPath returns the name of the file in which the project at projPath stores the settings of the given
+// stack.
+func ProjectStackPath(proj *Project, projPath string, stackName tokens.QName) string {
+	name := fmt.Sprintf("%s.%s%s", ProjectFile, qnameFileName(stackName), filepath.Ext(projPath))
+	return filepath.Join(filepath.Dir(projPath), proj.Config, name)
 }
 
 // DetectProjectPathFrom locates the closest project from the given path, searching "upwards" in the directory
@@ -82,6 +119,60 @@ func DetectProjectPathFrom(path string) (string, error) {
 	})
 }
 
+// DetectRepositoryRoot returns the root of the git repository that holds the given directory, or the directory itself
+// if it is not within one.
+func DetectRepositoryRoot(dir string) (string, error) {
+	gitDir, err := fsutil.WalkUp(dir, func(s string) bool {
+		return filepath.Base(s) == GitDir
+	}, nil)
+	if err != nil {
+		return "", err
+	} else if gitDir == "" {
+		return dir, nil
+	}
+	return filepath.Dir(gitDir), nil
+}
+
+// DetectProjectsUnder returns the paths of all of the projects beneath the given directory, such as those of a
+// repository that holds many projects, in lexical order.  Hidden directories and node_modules are not searched.
+func DetectProjectsUnder(root string) ([]string, error) {
+	var paths []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isProject(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// GetProjectCacheDir returns the directory in which plugins may cache data for the project at the given path, by
+// default `~/.pulumi/cache/<project-dir>-<hash>`.  Each project gets a directory of its own, keyed by its location,
+// so that projects that share a name, as those of a monorepo often do, do not trample on each other's caches.
+func GetProjectCacheDir(projPath string) (string, error) {
+	abs, err := filepath.Abs(projPath)
+	if err != nil {
+		return "", err
+	}
+	home, err := GetPulumiHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	name := filepath.Base(filepath.Dir(abs)) + "-" + sha1HexString(abs)
+	return filepath.Join(home, CacheDir, name), nil
+}
+
 // DetectProject loads the closest project from the current working directory, or an error if not found.
 func DetectProject() (*Project, error) {
 	proj, _, err := DetectProjectAndPath()
@@ -97,6 +188,21 @@ func DetectProjectStack(stackName tokens.QName) (*ProjectStack, error) {
 	return LoadProjectStack(path)
 }
 
+// DetectStackConfig loads the effective configuration of the given stack of the closest project, which includes any
+// configuration defaults set by the project itself.
+func DetectStackConfig(stackName tokens.QName) (config.Map, error) {
+	proj, err := DetectProject()
+	if err != nil {
+		return nil, err
+	}
+	ps, err := DetectProjectStack(stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	return proj.StackConfig(ps.Config), nil
+}
+
 // DetectProjectAndPath loads the closest package from the current working directory, or an error if not found.  It
 // also returns the path where the package was found.
 func DetectProjectAndPath() (*Project, string, error) {
@@ -164,10 +270,32 @@ func isMarkupFile(path string, expect string) bool {
 // GetCachedVersionFilePath returns the location where the CLI caches information from pulumi.com on the newest
 // available version of the CLI
 func GetCachedVersionFilePath() (string, error) {
-	user, err := user.Current()
+	home, err := GetPulumiHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, CachedVersionFile), nil
+}
+
+// GetHostDaemonFilePath returns the location of the file in which a running `pulumi host` daemon records the address
+// at which the CLI can reach it.
+func GetHostDaemonFilePath() (string, error) {
+	home, err := GetPulumiHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, HostDaemonFile), nil
+}
+
+// GetTelemetryFilePath returns the location of the file that records whether the user has opted in to telemetry, along
+// with the most recent report.
+func GetTelemetryFilePath() (string, error) {
+	home, err := GetPulumiHomeDir()
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.Join(user.HomeDir, BookkeepingDir, CachedVersionFile), nil
+	return filepath.Join(home, TelemetryFile), nil
 }
@@ -0,0 +1,66 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectProjectsUnder(t *testing.T) {
+	root, err := ioutil.TempDir("", "pulumi-monorepo")
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(root)) }()
+
+	write := func(path string) {
+		path = filepath.Join(root, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NoError(t, ioutil.WriteFile(path, []byte("name: test\nruntime: nodejs\n"), 0600))
+	}
+	write(filepath.Join("infra", "network", "Pulumi.yaml"))
+	write(filepath.Join("infra", "network", "Pulumi.dev.yaml"))
+	write(filepath.Join("services", "api", "Pulumi.json"))
+	write(filepath.Join("services", "api", "node_modules", "dep", "Pulumi.yaml"))
+	write(filepath.Join(".hidden", "Pulumi.yaml"))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, GitDir), 0700))
+
+	paths, err := DetectProjectsUnder(root)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "infra", "network", "Pulumi.yaml"),
+		filepath.Join(root, "services", "api", "Pulumi.json"),
+	}, paths)
+
+	// Any directory within the repository leads back to its root.
+	repo, err := DetectRepositoryRoot(filepath.Join(root, "services", "api"))
+	assert.NoError(t, err)
+	assert.Equal(t, root, repo)
+}
+
+func TestGetProjectCacheDir(t *testing.T) {
+	a, err := GetProjectCacheDir(filepath.Join("a", "app", "Pulumi.yaml"))
+	assert.NoError(t, err)
+	b, err := GetProjectCacheDir(filepath.Join("b", "app", "Pulumi.yaml"))
+	assert.NoError(t, err)
+
+	// Projects in directories of the same name still get caches of their own.
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, filepath.Dir(a), filepath.Dir(b))
+	assert.Contains(t, filepath.Base(a), "app-")
+}
@@ -0,0 +1,162 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+)
+
+// PluginVersionRange is a semver range that constrains the versions of a plugin that may be used, such as
+// `>=4.0,<5.0`.  Comparators separated by commas or spaces must all hold, and `||` separates alternatives.  Versions
+// within a range may omit their minor and patch numbers, which default to zero.
+type PluginVersionRange struct {
+	spec string          // the range as it was written.
+	rng  semver.Range    // the parsed range.
+	min  *semver.Version // the lowest version that the range admits, if it has a lower bound.
+}
+
+// IsPluginVersionRange returns true if the given version specification is a range rather than a single version.  A
+// single version is treated as a minimum, as plugin versions always have been.
+func IsPluginVersionRange(spec string) bool {
+	if _, err := semver.ParseTolerant(spec); err == nil {
+		return false
+	}
+	return strings.ContainsAny(spec, "<>=!|, ")
+}
+
+// ParsePluginVersionRange parses a semver range, such as `>=4.0,<5.0` or `<2.0 || >=2.5.1`.
+func ParsePluginVersionRange(spec string) (*PluginVersionRange, error) {
+	var alternatives []string
+	var min *semver.Version
+	bounded := true
+	for _, alt := range strings.Split(spec, "||") {
+		var comparators []string
+		var altMin *semver.Version
+		for _, comp := range strings.FieldsFunc(alt, func(c rune) bool { return c == ',' || c == ' ' || c == '\t' }) {
+			op := comp[:len(comp)-len(strings.TrimLeft(comp, "<>=!"))]
+			version, err := semver.ParseTolerant(comp[len(op):])
+			if err != nil {
+				return nil, errors.Errorf("invalid version range '%s': %v", spec, err)
+			}
+			comparators = append(comparators, op+version.String())
+
+			// Track the lowest version that this alternative admits, if it has a lower bound.
+			lower := version
+			switch op {
+			case ">":
+				lower.Patch++
+				lower.Pre = nil
+			case "", "=", "==", ">=":
+			default:
+				continue
+			}
+			if altMin == nil || lower.GT(*altMin) {
+				altMin = &lower
+			}
+		}
+		if len(comparators) == 0 {
+			return nil, errors.Errorf("invalid version range '%s': empty alternative", spec)
+		}
+		alternatives = append(alternatives, strings.Join(comparators, " "))
+
+		if altMin == nil {
+			bounded = false
+		} else if min == nil || altMin.LT(*min) {
+			min = altMin
+		}
+	}
+
+	rng, err := semver.ParseRange(strings.Join(alternatives, " || "))
+	if err != nil {
+		return nil, errors.Errorf("invalid version range '%s': %v", spec, err)
+	}
+	if !bounded || (min != nil && !rng(*min)) {
+		min = nil
+	}
+	return &PluginVersionRange{spec: spec, rng: rng, min: min}, nil
+}
+
+// Contains returns true if the given version lies within the range.
+func (r *PluginVersionRange) Contains(version semver.Version) bool {
+	return r.rng(version)
+}
+
+// Min returns the lowest version that the range admits, or nil if the range has no lower bound.
+func (r *PluginVersionRange) Min() *semver.Version {
+	return r.min
+}
+
+func (r *PluginVersionRange) String() string {
+	return r.spec
+}
+
+// ResolvePluginVersion returns the newest installed version of the given plugin that lies within the range, or nil if
+// no installed version does.  GetPluginPath loads exactly the version that it returns, rather than a newer version that
+// may lie outside of the range.
+func ResolvePluginVersion(kind PluginKind, name string, rng *PluginVersionRange) (*semver.Version, error) {
+	plugins, err := GetPlugins()
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading plugin list")
+	}
+	version := selectPluginVersion(plugins, kind, name, rng)
+	if version != nil {
+		SetExactPluginVersion(kind, name, *version)
+	}
+	return version, nil
+}
+
+// exactPluginVersions records the versions of plugins that were chosen from ranges, keyed by exactPluginVersionKey.
+var exactPluginVersions = struct {
+	sync.Mutex
+	versions map[string]bool
+}{versions: make(map[string]bool)}
+
+func exactPluginVersionKey(kind PluginKind, name string, version semver.Version) string {
+	return fmt.Sprintf("%s-%s-v%s", kind, name, version)
+}
+
+// SetExactPluginVersion records that the given version of a plugin was chosen from a range, so that GetPluginPath loads
+// exactly that version rather than the newest installed version that is at least as new.
+func SetExactPluginVersion(kind PluginKind, name string, version semver.Version) {
+	exactPluginVersions.Lock()
+	defer exactPluginVersions.Unlock()
+	exactPluginVersions.versions[exactPluginVersionKey(kind, name, version)] = true
+}
+
+// isExactPluginVersion returns true if the given version of a plugin was chosen from a range.
+func isExactPluginVersion(kind PluginKind, name string, version semver.Version) bool {
+	exactPluginVersions.Lock()
+	defer exactPluginVersions.Unlock()
+	return exactPluginVersions.versions[exactPluginVersionKey(kind, name, version)]
+}
+
+// selectPluginVersion returns the newest version of the given plugin among plugins that lies within the range.
+func selectPluginVersion(plugins []PluginInfo, kind PluginKind, name string, rng *PluginVersionRange) *semver.Version {
+	var match *semver.Version
+	for _, plugin := range plugins {
+		if plugin.Kind != kind || plugin.Name != name || plugin.Version == nil || !rng.Contains(*plugin.Version) {
+			continue
+		}
+		if match == nil || plugin.Version.GT(*match) {
+			match = plugin.Version
+		}
+	}
+	return match
+}
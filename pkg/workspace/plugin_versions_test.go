@@ -0,0 +1,103 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPluginVersionRange(t *testing.T) {
+	assert.False(t, IsPluginVersionRange(""))
+	assert.False(t, IsPluginVersionRange("1.2.3"))
+	assert.False(t, IsPluginVersionRange("v1.2"))
+	assert.False(t, IsPluginVersionRange("latest"))
+	assert.True(t, IsPluginVersionRange(">=4.0,<5.0"))
+	assert.True(t, IsPluginVersionRange("<2.0 || >=2.5.1"))
+}
+
+func TestParsePluginVersionRange(t *testing.T) {
+	rng, err := ParsePluginVersionRange(">=4.0,<5.0")
+	assert.NoError(t, err)
+	assert.Equal(t, ">=4.0,<5.0", rng.String())
+	assert.True(t, rng.Contains(semver.MustParse("4.0.0")))
+	assert.True(t, rng.Contains(semver.MustParse("4.9.1")))
+	assert.False(t, rng.Contains(semver.MustParse("3.9.0")))
+	assert.False(t, rng.Contains(semver.MustParse("5.0.0")))
+	assert.Equal(t, "4.0.0", rng.Min().String())
+
+	rng, err = ParsePluginVersionRange(">1.2.3 <2.0.0 || >=3.0.0")
+	assert.NoError(t, err)
+	assert.True(t, rng.Contains(semver.MustParse("1.5.0")))
+	assert.False(t, rng.Contains(semver.MustParse("2.5.0")))
+	assert.True(t, rng.Contains(semver.MustParse("3.1.0")))
+	assert.Equal(t, "1.2.4", rng.Min().String())
+
+	// A range without a lower bound has no minimum.
+	rng, err = ParsePluginVersionRange("<2.0 || >=2.5.1")
+	assert.NoError(t, err)
+	assert.Nil(t, rng.Min())
+
+	_, err = ParsePluginVersionRange(">=four")
+	assert.Error(t, err)
+	_, err = ParsePluginVersionRange(">=1.0 ||")
+	assert.Error(t, err)
+}
+
+func TestSelectPluginVersion(t *testing.T) {
+	version := func(s string) *semver.Version {
+		v := semver.MustParse(s)
+		return &v
+	}
+	plugins := []PluginInfo{
+		{Kind: ResourcePlugin, Name: "aws", Version: version("3.9.0")},
+		{Kind: ResourcePlugin, Name: "aws", Version: version("4.1.0")},
+		{Kind: ResourcePlugin, Name: "aws", Version: version("4.3.2")},
+		{Kind: ResourcePlugin, Name: "aws", Version: version("5.0.0")},
+		{Kind: ResourcePlugin, Name: "gcp", Version: version("4.8.0")},
+		{Kind: AnalyzerPlugin, Name: "aws", Version: version("4.9.0")},
+	}
+
+	rng, err := ParsePluginVersionRange(">=4.0,<5.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "4.3.2", selectPluginVersion(plugins, ResourcePlugin, "aws", rng).String())
+	assert.Nil(t, selectPluginVersion(plugins, ResourcePlugin, "azure", rng))
+
+	rng, err = ParsePluginVersionRange(">=6.0")
+	assert.NoError(t, err)
+	assert.Nil(t, selectPluginVersion(plugins, ResourcePlugin, "aws", rng))
+}
+
+func TestMatchPlugin(t *testing.T) {
+	version := func(s string) *semver.Version {
+		v := semver.MustParse(s)
+		return &v
+	}
+	plugins := []PluginInfo{
+		{Kind: ResourcePlugin, Name: "aws", Version: version("4.1.0")},
+		{Kind: ResourcePlugin, Name: "aws", Version: version("4.3.2")},
+		{Kind: ResourcePlugin, Name: "aws", Version: version("5.0.0")},
+	}
+
+	// An ordinary request loads the newest version that is at least as new as the one requested.
+	assert.Equal(t, "5.0.0", matchPlugin(plugins, ResourcePlugin, "aws", version("4.3.2"), false).Version.String())
+	assert.Equal(t, "5.0.0", matchPlugin(plugins, ResourcePlugin, "aws", nil, false).Version.String())
+
+	// A version chosen from a range is loaded exactly, and only if it is installed.
+	assert.Equal(t, "4.3.2", matchPlugin(plugins, ResourcePlugin, "aws", version("4.3.2"), true).Version.String())
+	assert.Nil(t, matchPlugin(plugins, ResourcePlugin, "aws", version("4.0.0"), true))
+}
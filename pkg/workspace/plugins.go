@@ -17,12 +17,14 @@ package workspace
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
-	"os/user"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -47,6 +49,17 @@ type PluginInfo struct {
 	Size         int64           // the size of the plugin, in bytes.
 	InstallTime  time.Time       // the time the plugin was installed.
 	LastUsedTime time.Time       // the last time the plugin was used.
+	Source       string          // where the plugin was downloaded from, if known.
+	Checksum     string          // the checksum of the tarball that the plugin was installed from, if known.
+	CacheDir     string          // the plugin cache that holds the plugin, if it is installed; "" for the user's own.
+	Shared       bool            // true if the plugin is installed in a shared, read-only plugin cache.
+}
+
+// PluginManifest is the metadata that is recorded in a plugin's directory when it is installed.
+type PluginManifest struct {
+	Checksum    string    `json:"checksum"`         // the checksum of the tarball that the plugin was installed from.
+	InstallTime time.Time `json:"installTime"`      // the time the plugin was installed.
+	Source      string    `json:"source,omitempty"` // where the plugin was downloaded from, if known.
 }
 
 // Dir gets the expected plugin directory for this plugin.
@@ -76,8 +89,11 @@ func (info PluginInfo) FileSuffix() string {
 	return ""
 }
 
-// DirPath returns the directory where this plugin should be installed.
+// DirPath returns the directory where this plugin is, or should be, installed.
 func (info PluginInfo) DirPath() (string, error) {
+	if info.CacheDir != "" {
+		return filepath.Join(info.CacheDir, info.Dir()), nil
+	}
 	dir, err := GetPluginDir()
 	if err != nil {
 		return "", err
@@ -97,6 +113,9 @@ func (info PluginInfo) FilePath() (string, error) {
 // Delete removes the plugin from the cache.  It also deletes any supporting files in the cache, which includes
 // any files that contain the same prefix as the plugin itself.
 func (info PluginInfo) Delete() error {
+	if info.Shared {
+		return errors.Errorf("the plugin is in the read-only plugin cache %s", info.CacheDir)
+	}
 	dir, err := info.DirPath()
 	if err != nil {
 		return err
@@ -127,22 +146,100 @@ func (info *PluginInfo) SetFileMetadata(path string) error {
 	}
 
 	info.LastUsedTime = tinfo.AccessTime()
+
+	// Finally, prefer the metadata that was recorded when the plugin was installed, if there is any.
+	dir := path
+	if !file.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	manifest, err := readPluginManifest(dir)
+	if err != nil {
+		return err
+	} else if manifest != nil {
+		info.InstallTime = manifest.InstallTime
+		info.Source = manifest.Source
+		info.Checksum = manifest.Checksum
+	}
 	return nil
 }
 
+// readPluginManifest reads the manifest in the given plugin directory, returning nil if there is none.
+func readPluginManifest(dir string) (*PluginManifest, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, PluginManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var manifest PluginManifest
+	if err = json.Unmarshal(b, &manifest); err != nil {
+		return nil, errors.Wrapf(err, "reading plugin manifest in %s", dir)
+	}
+	return &manifest, nil
+}
+
 // Install installs a plugin's tarball into the cache.  It validates that plugin names are in the expected format.
 func (info PluginInfo) Install(tarball io.ReadCloser) error {
-	// Fetch the directory into which we will expand this tarball, and create it.
-	pluginDir, err := info.DirPath()
+	return info.InstallFrom(tarball, "")
+}
+
+// InstallFrom installs a plugin's tarball, which was downloaded from the given source, into the user's own plugin
+// cache, and records a manifest of its checksum, install time, and source alongside it.  The tarball is expanded into
+// a temporary directory that then replaces the plugin's directory, so that a partially installed plugin is never
+// loaded, and installs of the same plugin by concurrent processes are serialized by a lock file.
+func (info PluginInfo) InstallFrom(tarball io.ReadCloser, source string) error {
+	defer contract.IgnoreClose(tarball)
+
+	// Fetch the cache into which we will install the plugin, and lock this plugin's place in it.
+	cacheDir, err := GetPluginDir()
 	if err != nil {
 		return err
 	}
-	if err = os.MkdirAll(pluginDir, 0700); err != nil {
-		return errors.Wrapf(err, "creating plugin directory %s", pluginDir)
+	if err = os.MkdirAll(cacheDir, 0700); err != nil {
+		return errors.Wrapf(err, "creating plugin cache %s", cacheDir)
+	}
+	pluginDir := filepath.Join(cacheDir, info.Dir())
+	unlock, err := lockPluginDir(pluginDir)
+	if err != nil {
+		return err
 	}
+	defer unlock()
 
-	// Unzip and untar the file as we go.
-	defer contract.IgnoreClose(tarball)
+	// Expand the tarball into a temporary directory, whose name doesn't look like a plugin's, hashing it as we go.
+	tempDir, err := ioutil.TempDir(cacheDir, ".partial-"+info.Dir()+"-")
+	if err != nil {
+		return errors.Wrapf(err, "creating temporary plugin directory")
+	}
+	defer func() { contract.IgnoreError(os.RemoveAll(tempDir)) }()
+	hash := sha256.New()
+	if err = expandPluginTarball(io.TeeReader(tarball, hash), tempDir); err != nil {
+		return err
+	}
+
+	// Record the manifest, and then move the plugin into place.
+	manifest, err := json.MarshalIndent(PluginManifest{
+		Checksum:    "sha256:" + hex.EncodeToString(hash.Sum(nil)),
+		InstallTime: time.Now().UTC(),
+		Source:      source,
+	}, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(filepath.Join(tempDir, PluginManifestFile), manifest, 0600); err != nil {
+		return errors.Wrapf(err, "writing plugin manifest")
+	}
+	if err = os.RemoveAll(pluginDir); err != nil {
+		return errors.Wrapf(err, "removing existing plugin directory %s", pluginDir)
+	}
+	if err = os.Rename(tempDir, pluginDir); err != nil {
+		return errors.Wrapf(err, "moving plugin into %s", pluginDir)
+	}
+	return nil
+}
+
+// expandPluginTarball unzips and untars a plugin's tarball into the given directory, consuming all of its contents.
+func expandPluginTarball(tarball io.Reader, pluginDir string) error {
 	gzr, err := gzip.NewReader(tarball)
 	if err != nil {
 		return errors.Wrapf(err, "unzipping")
@@ -172,15 +269,51 @@ func (info PluginInfo) Install(tarball io.ReadCloser) error {
 			if err != nil {
 				return errors.Wrapf(err, "opening file %s for untar", path)
 			}
-			defer contract.IgnoreClose(dst)
-			if _, err = io.Copy(dst, r); err != nil {
+			_, err = io.Copy(dst, r)
+			contract.IgnoreClose(dst)
+			if err != nil {
 				return errors.Wrapf(err, "untarring file %s", path)
 			}
 		default:
 			return errors.Errorf("unexpected plugin file type %s (%v)", header.Name, header.Typeflag)
 		}
 	}
-	return nil
+
+	// Drain anything that follows the archive so that the whole tarball is checksummed.
+	_, err = io.Copy(ioutil.Discard, tarball)
+	return err
+}
+
+// pluginLockTimeout is how long a plugin's lock file may be held before it is assumed to have been abandoned by a
+// process that died mid-install.
+const pluginLockTimeout = 10 * time.Minute
+
+// lockPluginDir acquires the lock on installing into the given plugin directory, waiting for any other process that
+// holds it.  It returns a function that releases the lock.
+func lockPluginDir(pluginDir string) (func(), error) {
+	lockFile := pluginDir + ".lock"
+	for {
+		f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			contract.IgnoreClose(f)
+			if err != nil {
+				contract.IgnoreError(os.Remove(lockFile))
+				return nil, errors.Wrapf(err, "writing plugin lock %s", lockFile)
+			}
+			return func() { contract.IgnoreError(os.Remove(lockFile)) }, nil
+		} else if !os.IsExist(err) {
+			return nil, errors.Wrapf(err, "creating plugin lock %s", lockFile)
+		}
+
+		// Someone else holds the lock.  Break it if it is stale, and otherwise wait for it to be released.
+		if stat, statErr := os.Stat(lockFile); statErr == nil && time.Since(stat.ModTime()) > pluginLockTimeout {
+			logging.V(5).Infof("breaking stale plugin lock %s", lockFile)
+			contract.IgnoreError(os.Remove(lockFile))
+			continue
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
 }
 
 func (info PluginInfo) String() string {
@@ -197,6 +330,8 @@ type PluginKind string
 const (
 	// AnalyzerPlugin is a plugin that can be used as a resource analyzer.
 	AnalyzerPlugin PluginKind = "analyzer"
+	// BackendPlugin is a plugin that can be used to store the state of stacks.
+	BackendPlugin PluginKind = "backend"
 	// LanguagePlugin is a plugin that can be used as a language host.
 	LanguagePlugin PluginKind = "language"
 	// ResourcePlugin is a plugin that can be used as a resource provider for custom CRUD operations.
@@ -206,7 +341,7 @@ const (
 // IsPluginKind returns true if k is a valid plugin kind, and false otherwise.
 func IsPluginKind(k string) bool {
 	switch PluginKind(k) {
-	case AnalyzerPlugin, LanguagePlugin, ResourcePlugin:
+	case AnalyzerPlugin, BackendPlugin, LanguagePlugin, ResourcePlugin:
 		return true
 	default:
 		return false
@@ -215,10 +350,12 @@ func IsPluginKind(k string) bool {
 
 // HasPlugin returns true if the given plugin exists.
 func HasPlugin(plug PluginInfo) bool {
-	dir, err := plug.DirPath()
-	if err == nil {
-		_, err := os.Stat(dir)
-		if err == nil {
+	caches, err := GetPluginCacheDirs()
+	if err != nil {
+		return false
+	}
+	for _, cache := range caches {
+		if _, err := os.Stat(filepath.Join(cache, plug.Dir())); err == nil {
 			return true
 		}
 	}
@@ -247,41 +384,77 @@ func HasPluginGTE(plug PluginInfo) (bool, error) {
 	return false, nil
 }
 
-// GetPluginDir returns the directory in which plugins on the current machine are managed.
+// PluginCachePathEnvVar is the name of the environment variable that lists, separated as $PATH is, any shared,
+// read-only plugin caches, such as those baked into CI images.  These are searched after the user's own plugin cache.
+const PluginCachePathEnvVar = "PULUMI_PLUGIN_CACHE_PATH"
+
+// PluginManifestFile is the name of the file in a plugin's directory that records its PluginManifest.
+const PluginManifestFile = "pulumi-plugin-manifest.json"
+
+// GetPluginDir returns the directory in which plugins on the current machine are managed, by default
+// `~/.pulumi/plugins`.  This is the user's own plugin cache, into which plugins are installed.
 func GetPluginDir() (string, error) {
-	u, err := user.Current()
-	if u == nil || err != nil {
-		return "", errors.Wrapf(err, "getting user home directory")
+	home, err := GetPulumiHomeDir()
+	if err != nil {
+		return "", err
 	}
-	return filepath.Join(u.HomeDir, BookkeepingDir, PluginDir), nil
+	return filepath.Join(home, PluginDir), nil
 }
 
-// GetPlugins returns a list of installed plugins.
-func GetPlugins() ([]PluginInfo, error) {
-	// To get the list of plugins, simply scan the directory in the usual place.
+// GetPluginCacheDirs returns the plugin caches that are searched for installed plugins, in order: the user's own,
+// followed by any shared caches.
+func GetPluginCacheDirs() ([]string, error) {
 	dir, err := GetPluginDir()
 	if err != nil {
 		return nil, err
 	}
-	files, err := ioutil.ReadDir(dir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
+	dirs := []string{dir}
+	for _, shared := range filepath.SplitList(os.Getenv(PluginCachePathEnvVar)) {
+		if shared != "" {
+			dirs = append(dirs, shared)
 		}
+	}
+	return dirs, nil
+}
+
+// GetPlugins returns a list of installed plugins.  If the same version of a plugin is installed in several caches, the
+// copy in the cache that is searched first is returned.
+func GetPlugins() ([]PluginInfo, error) {
+	caches, err := GetPluginCacheDirs()
+	if err != nil {
 		return nil, err
 	}
 
-	// Now read the file infos and create the plugin infos.
 	var plugins []PluginInfo
-	for _, file := range files {
-		// Skip anything that doesn't look like a plugin.
-		if kind, name, version, ok := tryPlugin(file); ok {
+	seen := make(map[string]bool)
+	for i, cache := range caches {
+		// To get the list of plugins, simply scan the cache's directory.
+		files, err := ioutil.ReadDir(cache)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		// Now read the file infos and create the plugin infos.
+		for _, file := range files {
+			// Skip anything that doesn't look like a plugin, or that an earlier cache already holds.
+			kind, name, version, ok := tryPlugin(file)
+			if !ok || seen[file.Name()] {
+				continue
+			}
+			seen[file.Name()] = true
+
 			plugin := PluginInfo{
 				Name:    name,
 				Kind:    kind,
 				Version: &version,
 			}
-			if err = plugin.SetFileMetadata(filepath.Join(dir, file.Name())); err != nil {
+			if i > 0 {
+				plugin.CacheDir, plugin.Shared = cache, true
+			}
+			if err = plugin.SetFileMetadata(filepath.Join(cache, file.Name())); err != nil {
 				return nil, err
 			}
 			plugins = append(plugins, plugin)
@@ -291,7 +464,8 @@ func GetPlugins() ([]PluginInfo, error) {
 }
 
 // GetPluginPath finds a plugin's path by its kind, name, and optional version.  It will match the latest version that
-// is >= the version specified.  If no version is supplied, the latest plugin for that given kind/name pair is loaded,
+// is >= the version specified, unless the version was resolved from a range (see ResolvePluginVersion), in which case
+// only that version matches.  If no version is supplied, the latest plugin for that given kind/name pair is loaded,
 // using standard semver sorting rules.  A plugin may be overridden entirely by placing it on your $PATH.
 func GetPluginPath(kind PluginKind, name string, version *semver.Version) (string, string, error) {
 	// If we have a version of the plugin on its $PATH, use it.  This supports development scenarios.
@@ -306,12 +480,42 @@ func GetPluginPath(kind PluginKind, name string, version *semver.Version) (strin
 	if err != nil {
 		return "", "", errors.Wrapf(err, "loading plugin list")
 	}
+	match := matchPlugin(plugins, kind, name, version, version != nil && isExactPluginVersion(kind, name, *version))
+	if match != nil {
+		matchDir, err := match.DirPath()
+		if err != nil {
+			return "", "", err
+		}
+		matchPath, err := match.FilePath()
+		if err != nil {
+			return "", "", err
+		}
+
+		logging.V(6).Infof("GetPluginPath(%s, %s, %v): found in cache at %s", kind, name, version, matchPath)
+		return matchDir, matchPath, nil
+	}
+
+	return "", "", nil
+}
+
+// matchPlugin returns the plugin among plugins that GetPluginPath loads for the given kind, name, and optional
+// version, or nil if none matches.  If exact is true, only the given version matches.
+func matchPlugin(plugins []PluginInfo, kind PluginKind, name string, version *semver.Version,
+	exact bool) *PluginInfo {
+
 	var match *PluginInfo
 	for _, cur := range plugins {
 		// Since the value of cur changes as we iterate, we can't save a pointer to it. So let's have a local that
 		// we can take a pointer to if this plugin is the best match yet.
 		plugin := cur
 		if plugin.Kind == kind && plugin.Name == name {
+			if exact {
+				if plugin.Version != nil && plugin.Version.Equals(*version) {
+					return &plugin
+				}
+				continue
+			}
+
 			// Always pick the most recent version of the plugin available.  Even if this is an exact match, we
 			// keep on searching just in case there's a newer version available.
 			var m *PluginInfo
@@ -331,22 +535,7 @@ func GetPluginPath(kind PluginKind, name string, version *semver.Version) (strin
 			}
 		}
 	}
-
-	if match != nil {
-		matchDir, err := match.DirPath()
-		if err != nil {
-			return "", "", err
-		}
-		matchPath, err := match.FilePath()
-		if err != nil {
-			return "", "", err
-		}
-
-		logging.V(6).Infof("GetPluginPath(%s, %s, %v): found in cache at %s", kind, name, version, matchPath)
-		return matchDir, matchPath, nil
-	}
-
-	return "", "", nil
+	return match
 }
 
 // pluginRegexp matches plugin filenames: pulumi-KIND-NAME-VERSION[.exe].
@@ -0,0 +1,136 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+// pluginTarball returns a plugin tarball that holds a single executable with the given contents.
+func pluginTarball(t *testing.T, info PluginInfo, contents string) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     info.File(),
+		Mode:     0700,
+		Size:     int64(len(contents)),
+		Typeflag: tar.TypeReg,
+	}))
+	_, err := tw.Write([]byte(contents))
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gzw.Close())
+	return buf.Bytes()
+}
+
+// setEnv sets an environment variable for the duration of a test, returning a function that restores it.
+func setEnv(t *testing.T, key, value string) func() {
+	old, had := os.LookupEnv(key)
+	assert.NoError(t, os.Setenv(key, value))
+	return func() {
+		if had {
+			assert.NoError(t, os.Setenv(key, old))
+		} else {
+			assert.NoError(t, os.Unsetenv(key))
+		}
+	}
+}
+
+func TestPluginInstallManifestAndHome(t *testing.T) {
+	home, err := ioutil.TempDir("", "pulumi-home")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	defer setEnv(t, PulumiHomeEnvVar, home)()
+	defer setEnv(t, PluginCachePathEnvVar, "")()
+
+	dir, err := GetPluginDir()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, PluginDir), dir)
+
+	// Install the same plugin from several goroutines at once; the lock serializes them.
+	version := semver.MustParse("1.2.3")
+	info := PluginInfo{Kind: ResourcePlugin, Name: "test", Version: &version}
+	tarball := pluginTarball(t, info, "#!/bin/sh\n")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, info.InstallFrom(ioutil.NopCloser(bytes.NewReader(tarball)), "https://example.com"))
+		}()
+	}
+	wg.Wait()
+
+	// The plugin is installed with its manifest, and neither the lock nor any partial install is left behind.
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, "resource-test-v1.2.3", files[0].Name())
+	}
+	plugins, err := GetPlugins()
+	assert.NoError(t, err)
+	if assert.Len(t, plugins, 1) {
+		assert.Equal(t, "https://example.com", plugins[0].Source)
+		assert.True(t, strings.HasPrefix(plugins[0].Checksum, "sha256:"))
+		assert.False(t, plugins[0].InstallTime.IsZero())
+		assert.False(t, plugins[0].Shared)
+	}
+
+	_, path, err := GetPluginPath(ResourcePlugin, "test", &version)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "resource-test-v1.2.3", info.File()), path)
+}
+
+func TestSharedPluginCache(t *testing.T) {
+	home, err := ioutil.TempDir("", "pulumi-home")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	shared, err := ioutil.TempDir("", "pulumi-shared-plugins")
+	assert.NoError(t, err)
+	defer os.RemoveAll(shared)
+	defer setEnv(t, PulumiHomeEnvVar, home)()
+	defer setEnv(t, PluginCachePathEnvVar, shared)()
+
+	// Place a plugin in the shared cache, as a CI image might.
+	version := semver.MustParse("2.0.0")
+	info := PluginInfo{Kind: ResourcePlugin, Name: "test", Version: &version}
+	assert.NoError(t, os.MkdirAll(filepath.Join(shared, info.Dir()), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(shared, info.Dir(), info.File()), []byte("#!/bin/sh\n"), 0700))
+
+	assert.True(t, HasPlugin(info))
+	plugins, err := GetPlugins()
+	assert.NoError(t, err)
+	if assert.Len(t, plugins, 1) {
+		assert.True(t, plugins[0].Shared)
+		assert.Equal(t, shared, plugins[0].CacheDir)
+		assert.Error(t, plugins[0].Delete())
+	}
+
+	_, path, err := GetPluginPath(ResourcePlugin, "test", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(shared, info.Dir(), info.File()), path)
+}
@@ -17,12 +17,14 @@ package workspace
 import (
 	"encoding/json"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
 
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 
+	"github.com/blang/semver"
 	"github.com/pkg/errors"
 
 	"github.com/pulumi/pulumi/pkg/encoding"
@@ -48,6 +50,94 @@ type ProjectTemplateConfigValue struct {
 	Secret      bool   `json:"secret,omitempty" yaml:"secret,omitempty"`           // an optional value indicating whether the config value should be encrypted.
 }
 
+// AutoNaming controls how physical resource names are generated from logical names.  Every field is optional; unset
+// fields fall back to the defaults, or to the project's settings when used to override them for a single stack.
+// nolint: lll
+type AutoNaming struct {
+	RandomSuffixLength *int    `json:"randomSuffixLength,omitempty" yaml:"randomSuffixLength,omitempty"` // the number of random characters to append.
+	Delimiter          *string `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`                   // the text placed between the logical name and suffix.
+	Verbatim           *bool   `json:"verbatim,omitempty" yaml:"verbatim,omitempty"`                     // true to use logical names exactly as given.
+}
+
+// StackGuard restricts when a stack may be changed.  Every field is optional; previews are never restricted.
+// nolint: lll
+type StackGuard struct {
+	AllowedHours       string `json:"allowedHours,omitempty" yaml:"allowedHours,omitempty"`             // the range of UTC hours, e.g. "9-17", during which changes are allowed.
+	RequireApprovalTag string `json:"requireApprovalTag,omitempty" yaml:"requireApprovalTag,omitempty"` // a pattern, e.g. "approved/*", that a git tag on the deployed commit must match.
+}
+
+// StackTTL limits how long a stack, or some of its resources, may live.  Expired stacks and resources are reported by
+// `pulumi stack ls` and `pulumi ttl enforce`, the latter of which can also destroy them.  Every field is optional.
+// nolint: lll
+type StackTTL struct {
+	Stack       string            `json:"stack,omitempty" yaml:"stack,omitempty"`             // how long after its last update, e.g. "72h", the whole stack expires.
+	Resources   map[string]string `json:"resources,omitempty" yaml:"resources,omitempty"`     // how long after their creation resources of a type (or `*` pattern) expire.
+	Ephemeral   bool              `json:"ephemeral,omitempty" yaml:"ephemeral,omitempty"`     // true if the stack is short-lived, so `pulumi stack gc` may remove it once it expires.
+	Initialized string            `json:"initialized,omitempty" yaml:"initialized,omitempty"` // the RFC3339 time at which the stack was initialized, from which its TTL counts until its first update.
+}
+
+// CredentialProfile selects the credentials used by a stack's default provider for a package and, optionally, the
+// identity (such as an AWS account or Azure subscription) that those credentials must belong to.  Packages other than
+// aws, azure, and gcp must name the function, and the field of its result, that reports the identity.
+// nolint: lll
+type CredentialProfile struct {
+	Config           map[string]string `json:"config,omitempty" yaml:"config,omitempty"`                     // provider settings, e.g. `profile`, that select the credentials.
+	ExpectedIdentity string            `json:"expectedIdentity,omitempty" yaml:"expectedIdentity,omitempty"` // the account, subscription, or project the credentials must belong to.
+	IdentityFunction string            `json:"identityFunction,omitempty" yaml:"identityFunction,omitempty"` // the function that reports the identity of the credentials.
+	IdentityField    string            `json:"identityField,omitempty" yaml:"identityField,omitempty"`       // the field of the function's result that holds the identity.
+}
+
+// ProviderDefaults are the settings that the engine applies to the default provider for a package, so that programs
+// needn't repeat them.  Every field is optional.
+// nolint: lll
+type ProviderDefaults struct {
+	Version           string            `json:"version,omitempty" yaml:"version,omitempty"`                     // the version of the provider's plugin to use, or a range of versions.
+	PluginDownloadURL string            `json:"pluginDownloadURL,omitempty" yaml:"pluginDownloadURL,omitempty"` // the URL from which `pulumi plugin install` downloads the plugin.
+	Config            map[string]string `json:"config,omitempty" yaml:"config,omitempty"`                       // provider settings, which a stack's own configuration for the package overrides.
+}
+
+// WarningSuppression silences the warnings that match it, which are then only counted.  At least one of the code, URN,
+// and provider must be given, and every one that is given must match.  A reason is required, so that suppressions can
+// be audited.
+// nolint: lll
+type WarningSuppression struct {
+	Code     string `json:"code,omitempty" yaml:"code,omitempty"`         // the code of the warnings, e.g. "PU2004"; see `pulumi explain`.
+	URN      string `json:"urn,omitempty" yaml:"urn,omitempty"`           // a pattern, in which `*` matches anything, of the URNs of the resources the warnings are for.
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"` // the package of the provider, e.g. "aws", of the resources the warnings are for.
+	Reason   string `json:"reason" yaml:"reason"`                         // why the warnings are suppressed.
+}
+
+// Validate returns an error if the suppression matches every warning or gives no reason.
+func (s WarningSuppression) Validate() error {
+	if s.Code == "" && s.URN == "" && s.Provider == "" {
+		return errors.New("a warning suppression must give a code, a URN pattern, or a provider")
+	}
+	if s.Reason == "" {
+		return errors.New("a warning suppression must give the reason for it")
+	}
+	return nil
+}
+
+// ProjectSandbox constrains the language host and plugins that run a project's program, and the processes that they
+// start, so that untrusted programs can be previewed with less at stake.  Each plugin already runs in a process group
+// of its own, which is killed as a whole once the plugin is done.  The program is not isolated from the network.
+// nolint: lll
+type ProjectSandbox struct {
+	ReadOnlyProgram bool           `json:"readOnlyProgram,omitempty" yaml:"readOnlyProgram,omitempty"` // true to run the program from a read-only copy of its project, so that it cannot change the project's files.
+	ProxyDenyHTTP   bool           `json:"proxyDenyHTTP,omitempty" yaml:"proxyDenyHTTP,omitempty"`     // true to point the program's HTTP proxy variables during previews at a proxy that refuses every request; a best-effort hint that programs may ignore, not a sandbox.
+	Limits          *ProcessLimits `json:"limits,omitempty" yaml:"limits,omitempty"`                   // optional resource limits on each plugin process, which are only supported on Linux.
+}
+
+// ProcessLimits are resource limits on each of the plugin processes that run a project's program, which the processes
+// that they start inherit.  Every field is optional; a zero limit is not applied.
+// nolint: lll
+type ProcessLimits struct {
+	CPUSeconds uint64 `json:"cpuSeconds,omitempty" yaml:"cpuSeconds,omitempty"` // the CPU time, in seconds, that each process may use.
+	MemoryMB   uint64 `json:"memoryMB,omitempty" yaml:"memoryMB,omitempty"`     // the virtual memory, in megabytes, that each process may map.
+	OpenFiles  uint64 `json:"openFiles,omitempty" yaml:"openFiles,omitempty"`   // the number of files that each process may hold open.
+	FileSizeMB uint64 `json:"fileSizeMB,omitempty" yaml:"fileSizeMB,omitempty"` // the size, in megabytes, of the largest file that each process may write.
+}
+
 // Project is a Pulumi project manifest..
 //
 // We explicitly add yaml tags (instead of using the default behavior from https://github.com/ghodss/yaml which works
@@ -73,7 +163,21 @@ type Project struct {
 
 	Config string `json:"config,omitempty" yaml:"config,omitempty"` // where to store Pulumi.<stack-name>.yaml files, this is combined with the folder Pulumi.yaml is in.
 
+	ConfigDefaults config.Map `json:"configDefaults,omitempty" yaml:"configDefaults,omitempty"` // optional config shared by every stack, which each stack's own config overrides.
+
 	Template *ProjectTemplate `json:"template,omitempty" yaml:"template,omitempty"` // optional template manifest.
+
+	AutoNaming *AutoNaming `json:"autoNaming,omitempty" yaml:"autoNaming,omitempty"` // optional physical naming settings.
+
+	DriftProneTypes []string `json:"driftProneTypes,omitempty" yaml:"driftProneTypes,omitempty"` // resource types (or `*` patterns) that changed-only refreshes always read.
+
+	Providers map[string]*ProviderDefaults `json:"providers,omitempty" yaml:"providers,omitempty"` // optional defaults for the default provider of each package.
+
+	SuppressWarnings []WarningSuppression `json:"suppressWarnings,omitempty" yaml:"suppressWarnings,omitempty"` // optional warnings to silence for every stack.
+
+	SecretPaths []string `json:"secretPaths,omitempty" yaml:"secretPaths,omitempty"` // optional `<type>:<path>` rules for resource properties whose values are always secret.
+
+	Sandbox *ProjectSandbox `json:"sandbox,omitempty" yaml:"sandbox,omitempty"` // optional constraints on the processes that run the program.
 }
 
 func (proj *Project) Validate() error {
@@ -83,10 +187,93 @@ func (proj *Project) Validate() error {
 	if proj.RuntimeInfo.Name() == "" {
 		return errors.New("project is missing a 'runtime' attribute")
 	}
+	for k, v := range proj.ConfigDefaults {
+		// Each stack encrypts its secrets with its own key, so there is no way to share one among them.
+		if v.Secure() {
+			return errors.Errorf("project config '%s' is a secret; secrets may only be set on a stack", k)
+		}
+	}
+	for i, s := range proj.SuppressWarnings {
+		if err := s.Validate(); err != nil {
+			return errors.Wrapf(err, "suppressWarnings[%d]", i)
+		}
+	}
+	for pkg, defaults := range proj.Providers {
+		if defaults == nil {
+			continue
+		}
+		if IsPluginVersionRange(defaults.Version) {
+			if _, err := ParsePluginVersionRange(defaults.Version); err != nil {
+				return errors.Errorf("provider '%s' has an invalid version: %v", pkg, err)
+			}
+		} else if defaults.Version != "" {
+			if _, err := semver.ParseTolerant(defaults.Version); err != nil {
+				return errors.Errorf("provider '%s' has an invalid version '%s': %v", pkg, defaults.Version, err)
+			}
+		}
+		if defaults.PluginDownloadURL != "" {
+			u, err := url.Parse(defaults.PluginDownloadURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return errors.Errorf("provider '%s' has an invalid pluginDownloadURL '%s'; expected an http(s) URL",
+					pkg, defaults.PluginDownloadURL)
+			}
+		}
+	}
 
 	return nil
 }
 
+// ProviderVersion returns the version of the given package's provider plugin that the project pins, if any.  If the
+// project constrains the version to a range instead, ProviderVersionRange returns it.
+func (proj *Project) ProviderVersion(pkg string) *semver.Version {
+	if proj == nil || proj.Providers[pkg] == nil || proj.Providers[pkg].Version == "" {
+		return nil
+	}
+	version, err := semver.ParseTolerant(proj.Providers[pkg].Version)
+	if err != nil {
+		return nil
+	}
+	return &version
+}
+
+// ProviderVersionRange returns the range of versions of the given package's provider plugin that the project allows,
+// if its version setting is a range rather than a single version.
+func (proj *Project) ProviderVersionRange(pkg string) *PluginVersionRange {
+	if proj == nil || proj.Providers[pkg] == nil || !IsPluginVersionRange(proj.Providers[pkg].Version) {
+		return nil
+	}
+	rng, err := ParsePluginVersionRange(proj.Providers[pkg].Version)
+	if err != nil {
+		return nil
+	}
+	return rng
+}
+
+// ProviderConfig returns the project's default settings for the given package's default provider, if any.
+func (proj *Project) ProviderConfig(pkg string) map[string]string {
+	if proj == nil || proj.Providers[pkg] == nil {
+		return nil
+	}
+	return proj.Providers[pkg].Config
+}
+
+// StackConfig returns the effective configuration of a stack of this project: the project's configuration defaults,
+// overridden by any values that the stack sets itself.
+func (proj *Project) StackConfig(stackConfig config.Map) config.Map {
+	if len(proj.ConfigDefaults) == 0 {
+		return stackConfig
+	}
+
+	result := make(config.Map, len(proj.ConfigDefaults)+len(stackConfig))
+	for k, v := range proj.ConfigDefaults {
+		result[k] = v
+	}
+	for k, v := range stackConfig {
+		result[k] = v
+	}
+	return result
+}
+
 func (proj *Project) UseDefaultIgnores() bool {
 	if proj.NoDefaultIgnores == nil {
 		return true
@@ -117,8 +304,17 @@ func (proj *Project) Save(path string) error {
 // ProjectStack holds stack specific information about a project.
 // nolint: lll
 type ProjectStack struct {
-	EncryptionSalt string     `json:"encryptionsalt,omitempty" yaml:"encryptionsalt,omitempty"` // base64 encoded encryption salt.
-	Config         config.Map `json:"config,omitempty" yaml:"config,omitempty"`                 // optional config.
+	EncryptionSalt string      `json:"encryptionsalt,omitempty" yaml:"encryptionsalt,omitempty"` // base64 encoded encryption salt.
+	Config         config.Map  `json:"config,omitempty" yaml:"config,omitempty"`                 // optional config.
+	AutoNaming     *AutoNaming `json:"autoNaming,omitempty" yaml:"autoNaming,omitempty"`         // optional physical naming overrides.
+	Guard          *StackGuard `json:"guard,omitempty" yaml:"guard,omitempty"`                   // optional restrictions on changes.
+	TTL            *StackTTL   `json:"ttl,omitempty" yaml:"ttl,omitempty"`                       // optional limits on the stack's lifetime.
+	Environments   []string    `json:"environments,omitempty" yaml:"environments,omitempty"`     // optional environments whose values the stack's configuration overrides.
+
+	CredentialProfiles map[string]*CredentialProfile `json:"credentialProfiles,omitempty" yaml:"credentialProfiles,omitempty"` // optional credentials for each package's default provider.
+	StackReferences    map[string][]string           `json:"stackReferences,omitempty" yaml:"stackReferences,omitempty"`       // optional outputs (or `*` patterns) this stack reads, by the name of the stack that produces them.
+	SuppressWarnings   []WarningSuppression          `json:"suppressWarnings,omitempty" yaml:"suppressWarnings,omitempty"`     // optional warnings to silence for this stack, besides those the project silences.
+	SecretPaths        []string                      `json:"secretPaths,omitempty" yaml:"secretPaths,omitempty"`               // optional `<type>:<path>` rules for resource properties whose values are secret, besides the project's.
 }
 
 // Save writes a project definition to a file.
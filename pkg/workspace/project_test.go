@@ -6,6 +6,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/tokens"
 )
 
 func TestProjectRuntimeInfoRoundtripYAML(t *testing.T) {
@@ -36,3 +39,68 @@ func TestProjectRuntimeInfoRoundtripYAML(t *testing.T) {
 	doTest(yaml.Marshal, yaml.Unmarshal)
 	doTest(json.Marshal, json.Unmarshal)
 }
+
+func TestProjectStackConfig(t *testing.T) {
+	region, zone := config.MustMakeKey("aws", "region"), config.MustMakeKey("aws", "zone")
+	proj := &Project{
+		Name:        tokens.PackageName("test"),
+		RuntimeInfo: NewProjectRuntimeInfo("nodejs", nil),
+		ConfigDefaults: config.Map{
+			region: config.NewValue("us-west-2"),
+			zone:   config.NewValue("us-west-2a"),
+		},
+	}
+	assert.NoError(t, proj.Validate())
+
+	// A stack's own values override the project's.
+	stackConfig := config.Map{zone: config.NewValue("us-west-2b")}
+	assert.Equal(t, config.Map{
+		region: config.NewValue("us-west-2"),
+		zone:   config.NewValue("us-west-2b"),
+	}, proj.StackConfig(stackConfig))
+	assert.Equal(t, config.Map{zone: config.NewValue("us-west-2b")}, stackConfig)
+
+	// Without defaults, the stack's configuration is used as it is.
+	proj.ConfigDefaults = nil
+	assert.Equal(t, stackConfig, proj.StackConfig(stackConfig))
+
+	// Secrets cannot be shared by the project's stacks.
+	proj.ConfigDefaults = config.Map{region: config.NewSecureValue("c2VjcmV0")}
+	assert.Error(t, proj.Validate())
+}
+
+func TestProjectProviderDefaults(t *testing.T) {
+	var proj Project
+	err := yaml.Unmarshal([]byte(
+		"name: test\n"+
+			"runtime: nodejs\n"+
+			"providers:\n"+
+			"  aws:\n"+
+			"    version: 0.15.0\n"+
+			"    pluginDownloadURL: https://plugins.example.com/aws\n"+
+			"    config:\n"+
+			"      region: us-west-2\n"), &proj)
+	assert.NoError(t, err)
+	assert.NoError(t, proj.Validate())
+
+	assert.Equal(t, "0.15.0", proj.ProviderVersion("aws").String())
+	assert.Equal(t, map[string]string{"region": "us-west-2"}, proj.ProviderConfig("aws"))
+	assert.Nil(t, proj.ProviderVersion("gcp"))
+	assert.Nil(t, proj.ProviderConfig("gcp"))
+
+	proj.Providers["aws"].Version = ">=0.15,<1.0"
+	assert.NoError(t, proj.Validate())
+	assert.Nil(t, proj.ProviderVersion("aws"))
+	assert.Equal(t, ">=0.15,<1.0", proj.ProviderVersionRange("aws").String())
+	assert.Nil(t, proj.ProviderVersionRange("gcp"))
+
+	proj.Providers["aws"].Version = ">=0.15,<one"
+	assert.Error(t, proj.Validate())
+
+	proj.Providers["aws"].Version = "latest"
+	assert.Error(t, proj.Validate())
+
+	proj.Providers["aws"].Version = ""
+	proj.Providers["aws"].PluginDownloadURL = "plugins.example.com/aws"
+	assert.Error(t, proj.Validate())
+}
@@ -0,0 +1,76 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// GetSchemaDir returns the directory in which provider schemas are cached, by default `~/.pulumi/schemas`.
+func GetSchemaDir() (string, error) {
+	home, err := GetPulumiHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, SchemaDir), nil
+}
+
+// schemaPath returns the path of the cached schema for the given resource plugin.  Schemas are keyed by the plugin's
+// name and exact version, so the plugin must have a version.
+func schemaPath(info PluginInfo) (string, error) {
+	contract.Require(info.Kind == ResourcePlugin, "info.Kind")
+	contract.Require(info.Version != nil, "info.Version")
+
+	dir, err := GetSchemaDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.json", info.Name, info.Version)), nil
+}
+
+// GetCachedSchema returns the cached schema for the given resource plugin, or nil if there isn't one.
+func GetCachedSchema(info PluginInfo) ([]byte, error) {
+	path, err := schemaPath(info)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "reading cached schema %s", path)
+	}
+	return schema, nil
+}
+
+// CacheSchema saves the schema for the given resource plugin so that later requests need not load the plugin.
+func CacheSchema(info PluginInfo, schema []byte) error {
+	path, err := schemaPath(info)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrapf(err, "creating schema cache directory")
+	}
+	return ioutil.WriteFile(path, schema, 0600)
+}
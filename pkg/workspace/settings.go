@@ -14,14 +14,39 @@
 
 package workspace
 
+import (
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+)
+
 // Settings defines workspace settings shared amongst many related projects.
 // nolint: lll
 type Settings struct {
-	Stack string `json:"stack,omitempty" yaml:"env,omitempty"` // an optional default stack to use.
+	Stack   string                  `json:"stack,omitempty" yaml:"env,omitempty"`       // an optional default stack to use.
+	Display map[string]bool         `json:"display,omitempty" yaml:"display,omitempty"` // optional defaults for display flags, such as `show-sames`.
+	Color   string                  `json:"color,omitempty" yaml:"color,omitempty"`     // an optional default for `--color`.
+	Theme   string                  `json:"theme,omitempty" yaml:"theme,omitempty"`     // an optional display theme, by name.
+	Themes  map[string]colors.Theme `json:"themes,omitempty" yaml:"themes,omitempty"`   // optional user-defined display themes, by name.
+
+	Triggers []*Trigger `json:"triggers,omitempty" yaml:"triggers,omitempty"` // optional triggers that `pulumi trigger run` runs.
+}
+
+// Trigger runs `pulumi up` or `pulumi refresh` on a stack when an event occurs: when a cron schedule comes due, when
+// a webhook bearing a secret token is received, or when the stack's resources are found to have drifted from its
+// state.  The approval of the command's changes is configured per trigger.
+// nolint: lll
+type Trigger struct {
+	Name        string `json:"name" yaml:"name"`                                   // the trigger's name, unique among the workspace's triggers.
+	Stack       string `json:"stack" yaml:"stack"`                                 // the stack on which to run the command.
+	Command     string `json:"command" yaml:"command"`                             // the command to run: `up` or `refresh`.
+	Schedule    string `json:"schedule,omitempty" yaml:"schedule,omitempty"`       // an optional five-field cron schedule, e.g. `0 3 * * *`, on which to run.
+	WebhookHash string `json:"webhookHash,omitempty" yaml:"webhookHash,omitempty"` // an optional SHA-256 hash, in hex, of a token; webhooks bearing the token run the trigger.
+	Drift       string `json:"drift,omitempty" yaml:"drift,omitempty"`             // an optional interval, e.g. `1h`, at which to check for drift, running when any is found.
+	Approval    string `json:"approval,omitempty" yaml:"approval,omitempty"`       // how changes are approved: `preview` (the default), `safe`, or `auto`.
 }
 
-// IsEmpty returns true when the settings object is logically empty (no selected stack and nothing in the deprecated
-// configuration bag).
+// IsEmpty returns true when the settings object is logically empty (no selected stack, no display defaults, themes, or
+// triggers, and nothing in the deprecated configuration bag).
 func (s *Settings) IsEmpty() bool {
-	return s.Stack == ""
+	return s.Stack == "" && len(s.Display) == 0 && s.Color == "" && s.Theme == "" && len(s.Themes) == 0 &&
+		len(s.Triggers) == 0
 }
@@ -18,7 +18,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/user"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -365,11 +364,11 @@ func GetTemplateDir() (string, error) {
 
 	// Use the classic template directory if there is no override.
 	if dir == "" {
-		u, err := user.Current()
-		if u == nil || err != nil {
-			return "", errors.Wrap(err, "getting user home directory")
+		home, err := GetPulumiHomeDir()
+		if err != nil {
+			return "", err
 		}
-		dir = filepath.Join(u.HomeDir, BookkeepingDir, TemplateDir)
+		dir = filepath.Join(home, TemplateDir)
 	}
 
 	return dir, nil
@@ -20,7 +20,6 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"os"
-	"os/user"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -110,6 +109,32 @@ func NewFrom(dir string) (W, error) {
 	return w, nil
 }
 
+// RenameProject saves the project in the current working directory under a new name.  Workspace settings are kept per
+// project name, so its settings, such as the currently selected stack, are moved to the new name along with it.
+func RenameProject(proj *Project, name tokens.PackageName) error {
+	w, err := New()
+	if err != nil {
+		return err
+	}
+	pw, ok := w.(*projectWorkspace)
+	contract.Assertf(ok, "unexpected workspace type %T", w)
+	oldSettings := pw.settingsPath()
+
+	proj.Name = name
+	if err = SaveProject(proj); err != nil {
+		return err
+	}
+
+	pw.name = name
+	if err = pw.Save(); err != nil {
+		return err
+	}
+	if err = os.Remove(oldSettings); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 func (pw *projectWorkspace) Settings() *Settings {
 	return pw.settings
 }
@@ -165,11 +190,11 @@ func (pw *projectWorkspace) readSettings() error {
 }
 
 func (pw *projectWorkspace) settingsPath() string {
-	user, err := user.Current()
-	contract.AssertNoErrorf(err, "could not get current user")
+	home, err := GetPulumiHomeDir()
+	contract.AssertNoErrorf(err, "could not get the Pulumi home directory")
 
 	uniqueFileName := string(pw.name) + "-" + sha1HexString(pw.project) + "-" + WorkspaceFile
-	return filepath.Join(user.HomeDir, BookkeepingDir, WorkspaceDir, uniqueFileName)
+	return filepath.Join(home, WorkspaceDir, uniqueFileName)
 }
 
 // sha1HexString returns a hex string of the sha1 hash of value.
@@ -15,7 +15,10 @@
 package pulumi
 
 import (
+	"fmt"
+	"runtime"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/golang/glog"
@@ -163,6 +166,88 @@ func (ctx *Context) Invoke(tok string, args map[string]interface{}) (map[string]
 	return outs, err
 }
 
+// Call dynamically calls the method, tok, of an existing resource, which is offered by a provider plugin.  self is the
+// resource's state, as returned by RegisterResource or ReadResource.  The result is an output that resolves to the map
+// of values the method returns, and that depends on the resource itself and on any resources that args depend on.
+// During previews, the result is unknown if the resource or any of the arguments are unknown.
+func (ctx *Context) Call(tok string, args map[string]interface{}, self *ResourceState) (*Output, error) {
+	if tok == "" {
+		return nil, errors.New("call token must not be empty")
+	} else if self == nil || self.URN == nil {
+		return nil, errors.Errorf("call of %s requires the resource whose method is being called", tok)
+	}
+
+	// The result depends on the resource itself, and on anything the arguments depend on.
+	deps := []Resource{resourceStateRef{self}}
+	for _, arg := range args {
+		if out, isOut := arg.(*Output); isOut {
+			deps = append(deps, out.Deps()...)
+		}
+	}
+	out, resolve, reject := NewOutput(deps)
+
+	// The resource itself is passed in the __self__ argument.
+	callArgs := map[string]interface{}{
+		"__self__": map[string]interface{}{"urn": self.URN, "id": self.ID},
+	}
+	for k, v := range args {
+		callArgs[k] = v
+	}
+
+	// Note that we're about to make an outstanding RPC request, so that we can rendezvous during shutdown.
+	if err := ctx.beginRPC(); err != nil {
+		return nil, err
+	}
+
+	// Kick off the call.  Awaiting the arguments may block on other resources, so this happens asynchronously.
+	go func() {
+		defer ctx.endRPC()
+
+		_, rpcArgs, _, err := marshalInputs(callArgs)
+		if err != nil {
+			reject(errors.Wrap(err, "marshaling arguments"))
+			return
+		}
+
+		glog.V(9).Infof("Call(%s, #args=%d): RPC call being made", tok, len(args))
+		resp, err := ctx.monitor.Call(ctx.ctx, &pulumirpc.InvokeRequest{
+			Tok:  tok,
+			Args: rpcArgs,
+		})
+		if err != nil {
+			glog.V(9).Infof("Call(%s, ...): error: %v", tok, err)
+			reject(err)
+			return
+		}
+
+		// If there were any failures from the provider, return them.
+		if len(resp.Failures) > 0 {
+			var ferr error
+			for _, failure := range resp.Failures {
+				ferr = multierror.Append(ferr,
+					errors.Errorf("%s call failed: %s (%s)", tok, failure.Reason, failure.Property))
+			}
+			reject(ferr)
+			return
+		}
+
+		// A missing return value means that the call could not be performed because some arguments are not yet known.
+		if resp.Return == nil {
+			resolve(nil, !ctx.DryRun())
+			return
+		}
+		outs, err := unmarshalOutputs(resp.Return)
+		if err != nil {
+			reject(err)
+			return
+		}
+		glog.V(9).Infof("Call(%s, ...): success: w/ %d outs", tok, len(outs))
+		resolve(outs, true)
+	}()
+
+	return out, nil
+}
+
 // ReadResource reads an existing custom resource's state from the resource monitor.  Note that resources read in this
 // way will not be part of the resulting stack's state, as they are presumed to belong to another.
 func (ctx *Context) ReadResource(
@@ -242,6 +327,9 @@ func (ctx *Context) RegisterResource(
 		return nil, err
 	}
 
+	// Remember where the program allocated this resource, so that diagnostics about it can point there.
+	pos := sourcePosition()
+
 	// Note that we're about to make an outstanding RPC request, so that we can rendezvous during shutdown.
 	if err = ctx.beginRPC(); err != nil {
 		return nil, err
@@ -252,13 +340,19 @@ func (ctx *Context) RegisterResource(
 	go func() {
 		glog.V(9).Infof("RegisterResource(%s, %s): Goroutine spawned, RPC call being made", t, name)
 		resp, err := ctx.monitor.RegisterResource(ctx.ctx, &pulumirpc.RegisterResourceRequest{
-			Type:         t,
-			Name:         name,
-			Parent:       op.parent,
-			Object:       op.rpcProps,
-			Custom:       custom,
-			Protect:      op.protect,
-			Dependencies: op.deps,
+			Type:           t,
+			Name:           name,
+			Parent:         op.parent,
+			Object:         op.rpcProps,
+			Custom:         custom,
+			Protect:        op.protect,
+			Dependencies:   op.deps,
+			SourcePosition: pos,
+			DependsOn:      op.dependsOn,
+
+			AdditionalSecretOutputs: ctx.getOptsAdditionalSecretOutputs(opts...),
+			ReplaceOnChanges:        ctx.getOptsReplaceOnChanges(opts...),
+			DeleteBeforeReplace:     ctx.getOptsDeleteBeforeReplace(opts...),
 		})
 		if err != nil {
 			glog.V(9).Infof("RegisterResource(%s, %s): error: %v", t, name, err)
@@ -296,15 +390,16 @@ func (ctx *Context) RegisterResource(
 
 // resourceOperation reflects all of the inputs necessary to perform core resource RPC operations.
 type resourceOperation struct {
-	ctx      *Context
-	parent   string
-	deps     []string
-	protect  bool
-	props    map[string]interface{}
-	rpcProps *structpb.Struct
-	outURN   *resourceOutput
-	outID    *resourceOutput
-	outState map[string]*resourceOutput
+	ctx       *Context
+	parent    string
+	deps      []string
+	dependsOn []string
+	protect   bool
+	props     map[string]interface{}
+	rpcProps  *structpb.Struct
+	outURN    *resourceOutput
+	outID     *resourceOutput
+	outState  map[string]*resourceOutput
 }
 
 // newResourceOperation prepares the inputs for a resource operation, shared between read and register.
@@ -331,6 +426,13 @@ func (ctx *Context) newResourceOperation(custom bool, props map[string]interface
 	}
 	sort.Strings(deps)
 
+	// Remember which of those dependencies were requested explicitly, so that the engine can record them as such.
+	var dependsOn []string
+	for _, dep := range optDeps {
+		dependsOn = append(dependsOn, string(dep))
+	}
+	sort.Strings(dependsOn)
+
 	// Create a set of resolvers that we'll use to finalize state, for URNs, IDs, and output properties.
 	outURN, resolveURN, rejectURN := NewOutput(nil)
 	urn := &resourceOutput{out: outURN, resolve: resolveURN, reject: rejectURN}
@@ -352,15 +454,16 @@ func (ctx *Context) newResourceOperation(custom bool, props map[string]interface
 	}
 
 	return &resourceOperation{
-		ctx:      ctx,
-		parent:   string(parent),
-		deps:     deps,
-		protect:  protect,
-		props:    props,
-		rpcProps: rpcProps,
-		outURN:   urn,
-		outID:    id,
-		outState: state,
+		ctx:       ctx,
+		parent:    string(parent),
+		deps:      deps,
+		dependsOn: dependsOn,
+		protect:   protect,
+		props:     props,
+		rpcProps:  rpcProps,
+		outURN:    urn,
+		outID:     id,
+		outState:  state,
 	}, nil
 }
 
@@ -460,6 +563,35 @@ func (ctx *Context) getOptsProtect(opts ...ResourceOpt) bool {
 	return false
 }
 
+// getOptsAdditionalSecretOutputs returns the names of the outputs that a resource's options mark as secret.
+func (ctx *Context) getOptsAdditionalSecretOutputs(opts ...ResourceOpt) []string {
+	var names []string
+	for _, opt := range opts {
+		names = append(names, opt.AdditionalSecretOutputs...)
+	}
+	return names
+}
+
+// getOptsReplaceOnChanges returns the input paths whose changes a resource's options say must replace it.
+func (ctx *Context) getOptsReplaceOnChanges(opts ...ResourceOpt) []string {
+	var paths []string
+	for _, opt := range opts {
+		paths = append(paths, opt.ReplaceOnChanges...)
+	}
+	return paths
+}
+
+// getOptsDeleteBeforeReplace returns true if a resource's options indicate that it is to be deleted before it is
+// replaced.
+func (ctx *Context) getOptsDeleteBeforeReplace(opts ...ResourceOpt) bool {
+	for _, opt := range opts {
+		if opt.DeleteBeforeReplace {
+			return true
+		}
+	}
+	return false
+}
+
 // noMoreRPCs is a sentinel value used to stop subsequent RPCs from occurring.
 const noMoreRPCs = -1
 
@@ -515,6 +647,20 @@ type ResourceState struct {
 	State Outputs
 }
 
+// resourceStateRef adapts a registered resource's state to the Resource interface, so that it may be tracked as a
+// dependency.  Its URN blocks until the registration has completed.
+type resourceStateRef struct {
+	state *ResourceState
+}
+
+func (ref resourceStateRef) URN() URN {
+	urn, err := ref.state.URN.Value()
+	if err != nil {
+		return ""
+	}
+	return urn
+}
+
 // RegisterResourceOutputs completes the resource registration, attaching an optional set of computed outputs.
 func (ctx *Context) RegisterResourceOutputs(urn URN, outs map[string]interface{}) error {
 	return nil
@@ -524,3 +670,20 @@ func (ctx *Context) RegisterResourceOutputs(urn URN, outs map[string]interface{}
 func (ctx *Context) Export(name string, value interface{}) {
 	ctx.exports[name] = value
 }
+
+// sourcePosition returns the `file:line` position of the innermost caller that belongs to the user's program, rather
+// than to this package or to a dependency (such as a provider's SDK).  It returns "" if there is no such caller.
+func sourcePosition() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if frame.File != "" && !strings.HasPrefix(frame.Function, "github.com/pulumi/pulumi/sdk/go/pulumi.") &&
+			!strings.Contains(frame.File, "/vendor/") && !strings.Contains(frame.File, "/pkg/mod/") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
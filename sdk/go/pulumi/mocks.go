@@ -0,0 +1,168 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pulumi
+
+import (
+	"github.com/golang/protobuf/ptypes/empty"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+// Mocks supplies the results of resource operations to a program that is run with RunWithMocks, in place of the
+// providers that would normally perform them.
+type Mocks interface {
+	// NewResource returns the ID and output state of a new custom resource, given its type, name, inputs, and
+	// provider reference.  If the resource is being read rather than created, id is the ID that was requested.  If
+	// the returned ID is empty, one is generated from the resource's name; if the returned state is nil, the
+	// resource's outputs are its inputs.
+	NewResource(typeToken, name string, inputs resource.PropertyMap,
+		provider, id string) (string, resource.PropertyMap, error)
+	// Call returns the result of invoking the function with the given token, whether it is a provider function or
+	// a method of an existing resource.
+	Call(token string, args resource.PropertyMap, provider string) (resource.PropertyMap, error)
+}
+
+// RunWithMocks executes the body of a Pulumi program against an in-process resource monitor that consults the given
+// mocks rather than the engine.  No plugins or cloud credentials are required, and URNs and IDs are generated
+// deterministically, so this is well-suited to unit testing programs.
+func RunWithMocks(project, stack string, mocks Mocks, body RunFunc) error {
+	return RunWithMocksInfo(RunInfo{Project: project, Stack: stack}, mocks, body)
+}
+
+// RunWithMocksInfo executes the body of a Pulumi program as RunWithMocks does, using the given run information (for
+// instance, to supply configuration or to run as a preview).  The info's monitor and engine addresses are ignored.
+func RunWithMocksInfo(info RunInfo, mocks Mocks, body RunFunc) error {
+	info.MonitorAddr, info.EngineAddr = "", ""
+	ctx, err := NewContext(context.TODO(), info)
+	if err != nil {
+		return err
+	}
+	defer contract.IgnoreClose(ctx)
+
+	ctx.monitor = &mockMonitor{project: info.Project, stack: info.Stack, mocks: mocks}
+	return runWithContext(ctx, body)
+}
+
+// mockMonitor is an in-process implementation of the resource monitor that defers to a set of mocks.
+type mockMonitor struct {
+	project string
+	stack   string
+	mocks   Mocks
+}
+
+var _ pulumirpc.ResourceMonitorClient = (*mockMonitor)(nil)
+
+func (m *mockMonitor) newURN(parent, t, name string) string {
+	var parentType tokens.Type
+	if parent != "" {
+		parentType = resource.URN(parent).Type()
+	}
+	return string(resource.NewURN(
+		tokens.QName(m.stack), tokens.PackageName(m.project), parentType, tokens.Type(t), tokens.QName(name)))
+}
+
+func (m *mockMonitor) call(tok string, args *structpb.Struct, provider string) (*pulumirpc.InvokeResponse, error) {
+	label := "mocks.Call(" + tok + ")"
+	argsMap, err := plugin.UnmarshalProperties(args, plugin.MarshalOptions{Label: label, KeepUnknowns: true})
+	if err != nil {
+		return nil, err
+	}
+	ret, err := m.mocks.Call(tok, argsMap, provider)
+	if err != nil {
+		return nil, err
+	}
+	mret, err := plugin.MarshalProperties(ret, plugin.MarshalOptions{Label: label, KeepUnknowns: true})
+	if err != nil {
+		return nil, err
+	}
+	return &pulumirpc.InvokeResponse{Return: mret}, nil
+}
+
+func (m *mockMonitor) Invoke(ctx context.Context, in *pulumirpc.InvokeRequest,
+	opts ...grpc.CallOption) (*pulumirpc.InvokeResponse, error) {
+	return m.call(in.GetTok(), in.GetArgs(), in.GetProvider())
+}
+
+func (m *mockMonitor) Call(ctx context.Context, in *pulumirpc.InvokeRequest,
+	opts ...grpc.CallOption) (*pulumirpc.InvokeResponse, error) {
+	return m.call(in.GetTok(), in.GetArgs(), in.GetProvider())
+}
+
+// newResource consults the mocks for a custom resource's ID and state, filling in any defaults.
+func (m *mockMonitor) newResource(t, name string, inputs *structpb.Struct, provider,
+	id string) (string, *structpb.Struct, error) {
+
+	label := "mocks.NewResource(" + t + "," + name + ")"
+	opts := plugin.MarshalOptions{Label: label, KeepUnknowns: true}
+	props, err := plugin.UnmarshalProperties(inputs, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	id, state, err := m.mocks.NewResource(t, name, props, provider, id)
+	if err != nil {
+		return "", nil, err
+	}
+	if id == "" {
+		id = name + "_id"
+	}
+	if state == nil {
+		state = props
+	}
+	outs, err := plugin.MarshalProperties(state, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, outs, nil
+}
+
+func (m *mockMonitor) ReadResource(ctx context.Context, in *pulumirpc.ReadResourceRequest,
+	opts ...grpc.CallOption) (*pulumirpc.ReadResourceResponse, error) {
+
+	urn := m.newURN(in.GetParent(), in.GetType(), in.GetName())
+	_, outs, err := m.newResource(in.GetType(), in.GetName(), in.GetProperties(), in.GetProvider(), in.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return &pulumirpc.ReadResourceResponse{Urn: urn, Properties: outs}, nil
+}
+
+func (m *mockMonitor) RegisterResource(ctx context.Context, in *pulumirpc.RegisterResourceRequest,
+	opts ...grpc.CallOption) (*pulumirpc.RegisterResourceResponse, error) {
+
+	urn := m.newURN(in.GetParent(), in.GetType(), in.GetName())
+
+	// Component resources have no provider to consult; their outputs are simply their inputs.
+	if !in.GetCustom() {
+		return &pulumirpc.RegisterResourceResponse{Urn: urn, Object: in.GetObject()}, nil
+	}
+
+	id, outs, err := m.newResource(in.GetType(), in.GetName(), in.GetObject(), in.GetProvider(), "")
+	if err != nil {
+		return nil, err
+	}
+	return &pulumirpc.RegisterResourceResponse{Urn: urn, Id: id, Object: outs}, nil
+}
+
+func (m *mockMonitor) RegisterResourceOutputs(ctx context.Context, in *pulumirpc.RegisterResourceOutputsRequest,
+	opts ...grpc.CallOption) (*empty.Empty, error) {
+	return &empty.Empty{}, nil
+}
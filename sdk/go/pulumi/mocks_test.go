@@ -0,0 +1,95 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pulumi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+type testMocks struct {
+	calls []string
+}
+
+func (m *testMocks) NewResource(typeToken, name string, inputs resource.PropertyMap,
+	provider, id string) (string, resource.PropertyMap, error) {
+	if name == "echo" {
+		return "", nil, nil
+	}
+	state := resource.PropertyMap{"size": resource.NewNumberProperty(inputs["size"].NumberValue() * 2)}
+	return "bucket-id", state, nil
+}
+
+func (m *testMocks) Call(token string, args resource.PropertyMap, provider string) (resource.PropertyMap, error) {
+	m.calls = append(m.calls, token)
+	return resource.PropertyMap{"region": resource.NewStringProperty("us-west-2")}, nil
+}
+
+func TestRunWithMocks(t *testing.T) {
+	mocks := &testMocks{}
+	err := RunWithMocks("project", "stack", mocks, func(ctx *Context) error {
+		// Custom resources take their IDs and states from the mocks.
+		bucket, err := ctx.RegisterResource("aws:s3/bucket:Bucket", "bucket", true, map[string]interface{}{
+			"size": 21,
+		})
+		assert.NoError(t, err)
+
+		urn, err := bucket.URN.Value()
+		assert.NoError(t, err)
+		assert.Equal(t, URN("urn:pulumi:stack::project::pulumi:pulumi:Stack$aws:s3/bucket:Bucket::bucket"), urn)
+		id, known, err := bucket.ID.Value()
+		assert.NoError(t, err)
+		assert.True(t, known)
+		assert.Equal(t, ID("bucket-id"), id)
+		size, known, err := bucket.State["size"].Value()
+		assert.NoError(t, err)
+		assert.True(t, known)
+		assert.Equal(t, float64(42), size)
+
+		// If the mocks return nothing, IDs are derived from names and states from inputs.
+		echo, err := ctx.RegisterResource("aws:s3/bucket:Bucket", "echo", true, map[string]interface{}{
+			"size": 21,
+		})
+		assert.NoError(t, err)
+		id, _, err = echo.ID.Value()
+		assert.NoError(t, err)
+		assert.Equal(t, ID("echo_id"), id)
+		size, _, err = echo.State["size"].Value()
+		assert.NoError(t, err)
+		assert.Equal(t, float64(21), size)
+
+		// Invokes are answered by the mocks.
+		ret, err := ctx.Invoke("aws:index/getRegion:getRegion", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "us-west-2", ret["region"])
+
+		// Calls depend on the resource whose method is called.
+		out, err := ctx.Call("aws:s3/bucket:Bucket/getRegion", nil, bucket)
+		assert.NoError(t, err)
+		if assert.Len(t, out.Deps(), 1) {
+			assert.Equal(t, urn, out.Deps()[0].URN())
+		}
+		v, known, err := out.Value()
+		assert.NoError(t, err)
+		assert.True(t, known)
+		assert.Equal(t, "us-west-2", v.(map[string]interface{})["region"])
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aws:index/getRegion:getRegion", "aws:s3/bucket:Bucket/getRegion"}, mocks.calls)
+}
@@ -51,4 +51,12 @@ type ResourceOpt struct {
 	DependsOn []Resource
 	// Protect, when set to true, ensures that this resource cannot be deleted (without first setting it to false).
 	Protect bool
+	// AdditionalSecretOutputs names outputs of a custom resource that are secret, whatever its provider says.
+	AdditionalSecretOutputs []string
+	// ReplaceOnChanges lists paths of a custom resource's inputs, e.g. "spec.template" or "*" for all of them, whose
+	// changes replace the resource rather than update it in place.
+	ReplaceOnChanges []string
+	// DeleteBeforeReplace, when set to true, deletes a custom resource before creating its replacement, rather than
+	// after.  Any resources that depend upon it are replaced as well.
+	DeleteBeforeReplace bool
 }
@@ -63,6 +63,13 @@ func RunErr(body RunFunc) error {
 	}
 	defer contract.IgnoreClose(ctx)
 
+	return runWithContext(ctx, body)
+}
+
+// runWithContext executes the body of a Pulumi program using the given deployment context.
+func runWithContext(ctx *Context, body RunFunc) error {
+	info := ctx.info
+
 	// Create a root stack resource that we'll parent everything to.
 	reg, err := ctx.RegisterResource(
 		"pulumi:pulumi:Stack", fmt.Sprintf("%s-%s", info.Project, info.Stack), false, nil)
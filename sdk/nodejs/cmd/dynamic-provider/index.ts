@@ -55,6 +55,11 @@ function configureRPC(call: any, callback: any): void {
     callback(undefined, new emptyproto.Empty());
 }
 
+function checkAuthRPC(call: any, callback: any): void {
+    // Dynamic providers have no credentials of their own to verify.
+    callback(undefined, new emptyproto.Empty());
+}
+
 async function invokeRPC(call: any, callback: any): Promise<void> {
     const req: any = call.request;
 
@@ -62,6 +67,13 @@ async function invokeRPC(call: any, callback: any): Promise<void> {
     callback(new Error(`unknown function ${req.getTok()}`), undefined);
 }
 
+async function callRPC(call: any, callback: any): Promise<void> {
+    const req: any = call.request;
+
+    // Dynamic resources do not offer any methods.
+    callback(new Error(`unknown method ${req.getTok()}`), undefined);
+}
+
 async function checkRPC(call: any, callback: any): Promise<void> {
     try {
         const req: any = call.request;
@@ -69,12 +81,13 @@ async function checkRPC(call: any, callback: any): Promise<void> {
 
         const olds = req.getOlds().toJavaScript();
         const news = req.getNews().toJavaScript();
+        const randomSeed = Buffer.from(req.getRandomseed_asU8());
         const provider = getProvider(news);
 
         let inputs: any = {};
         let failures: any[] = [];
         if (provider.check) {
-            const result = await provider.check(olds, news);
+            const result = await provider.check(olds, news, randomSeed);
             if (result.inputs) {
                 inputs = result.inputs;
             }
@@ -92,6 +105,9 @@ async function checkRPC(call: any, callback: any): Promise<void> {
                 const failure = new provproto.CheckFailure();
                 failure.setProperty(f.property);
                 failure.setReason(f.reason);
+                if (f.suggestion) {
+                    failure.setSuggestion(f.suggestion);
+                }
                 failureList.push(failure);
             }
             resp.setFailuresList(failureList);
@@ -132,6 +148,12 @@ async function diffRPC(call: any, callback: any): Promise<void> {
                 if (result.replaces && result.replaces.length !== 0) {
                     resp.setReplacesList(result.replaces);
                 }
+                if (result.replaceReasons) {
+                    const reasons = resp.getReplacereasonsMap();
+                    for (const k of Object.keys(result.replaceReasons)) {
+                        reasons.set(k, result.replaceReasons[k]);
+                    }
+                }
                 if (result.deleteBeforeReplace) {
                     resp.setDeletebeforereplace(result.deleteBeforeReplace);
                 }
@@ -300,7 +322,9 @@ export function main(args: string[]): void {
     server.addService(provrpc.ResourceProviderService, {
         cancel: cancelRPC,
         configure: configureRPC,
+        checkAuth: checkAuthRPC,
         invoke: invokeRPC,
+        call: callRPC,
         check: checkRPC,
         diff: diffRPC,
         create: createRPC,
@@ -283,7 +283,7 @@ func (host *nodeLanguageHost) Run(ctx context.Context, req *pulumirpc.RunRequest
 		env = append(env, "PULUMI_NODEJS_TYPESCRIPT=true")
 	}
 
-	if logging.V(5) {
+	if logging.V(5).Enabled() {
 		commandStr := strings.Join(args, " ")
 		logging.V(5).Infoln("Language host launching process: ", host.nodeBin, commandStr)
 	}
@@ -35,7 +35,7 @@ export interface CheckResult {
  */
 export interface CheckFailure {
     /**
-     * The property that failed validation.
+     * The property that failed validation.  Nested properties may be named by path, e.g. `tags[0].key`.
      */
     readonly property: string;
 
@@ -43,6 +43,11 @@ export interface CheckFailure {
      * The reason that the property failed validation.
      */
     readonly reason: string;
+
+    /**
+     * An optional suggested fix for the failure.
+     */
+    readonly suggestion?: string;
 }
 
 /**
@@ -59,6 +64,12 @@ export interface DiffResult {
      */
     readonly replaces?: string[];
 
+    /**
+     * An optional explanation for each of the properties in `replaces`, phrased to follow the property's name in
+     * the preview, e.g. `{ engineVersion: "changed and is immutable" }`.
+     */
+    readonly replaceReasons?: Record<string, string>;
+
     /**
      * An optional list of properties that will not ever change.
      */
@@ -116,8 +127,10 @@ export interface ResourceProvider {
      *
      * @param olds The old input properties to use for validation.
      * @param news The new input properties to use for validation.
+     * @param randomSeed A seed from which to derive any randomness in the checked inputs, such as the suffix of an
+     *     auto-generated name, so that checking the same inputs again produces the same results.
      */
-    check?: (olds: any, news: any) => Promise<CheckResult>;
+    check?: (olds: any, news: any, randomSeed: Buffer) => Promise<CheckResult>;
 
     /**
      * Diff checks what impacts a hypothetical update will have on the resource's properties.
@@ -0,0 +1,206 @@
+// GENERATED CODE -- DO NOT EDIT!
+
+// Original file comments:
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+'use strict';
+var grpc = require('grpc');
+var backend_pb = require('./backend_pb.js');
+var plugin_pb = require('./plugin_pb.js');
+var google_protobuf_empty_pb = require('google-protobuf/google/protobuf/empty_pb.js');
+
+function serialize_google_protobuf_Empty(arg) {
+  if (!(arg instanceof google_protobuf_empty_pb.Empty)) {
+    throw new Error('Expected argument of type google.protobuf.Empty');
+  }
+  return new Buffer(arg.serializeBinary());
+}
+
+function deserialize_google_protobuf_Empty(buffer_arg) {
+  return google_protobuf_empty_pb.Empty.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_DeleteBlobRequest(arg) {
+  if (!(arg instanceof backend_pb.DeleteBlobRequest)) {
+    throw new Error('Expected argument of type pulumirpc.DeleteBlobRequest');
+  }
+  return new Buffer(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_DeleteBlobRequest(buffer_arg) {
+  return backend_pb.DeleteBlobRequest.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_GetBlobRequest(arg) {
+  if (!(arg instanceof backend_pb.GetBlobRequest)) {
+    throw new Error('Expected argument of type pulumirpc.GetBlobRequest');
+  }
+  return new Buffer(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_GetBlobRequest(buffer_arg) {
+  return backend_pb.GetBlobRequest.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_GetBlobResponse(arg) {
+  if (!(arg instanceof backend_pb.GetBlobResponse)) {
+    throw new Error('Expected argument of type pulumirpc.GetBlobResponse');
+  }
+  return new Buffer(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_GetBlobResponse(buffer_arg) {
+  return backend_pb.GetBlobResponse.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_ListBlobsRequest(arg) {
+  if (!(arg instanceof backend_pb.ListBlobsRequest)) {
+    throw new Error('Expected argument of type pulumirpc.ListBlobsRequest');
+  }
+  return new Buffer(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_ListBlobsRequest(buffer_arg) {
+  return backend_pb.ListBlobsRequest.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_ListBlobsResponse(arg) {
+  if (!(arg instanceof backend_pb.ListBlobsResponse)) {
+    throw new Error('Expected argument of type pulumirpc.ListBlobsResponse');
+  }
+  return new Buffer(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_ListBlobsResponse(buffer_arg) {
+  return backend_pb.ListBlobsResponse.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_PluginInfo(arg) {
+  if (!(arg instanceof plugin_pb.PluginInfo)) {
+    throw new Error('Expected argument of type pulumirpc.PluginInfo');
+  }
+  return new Buffer(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_PluginInfo(buffer_arg) {
+  return plugin_pb.PluginInfo.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_PutBlobRequest(arg) {
+  if (!(arg instanceof backend_pb.PutBlobRequest)) {
+    throw new Error('Expected argument of type pulumirpc.PutBlobRequest');
+  }
+  return new Buffer(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_PutBlobRequest(buffer_arg) {
+  return backend_pb.PutBlobRequest.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_RenameBlobRequest(arg) {
+  if (!(arg instanceof backend_pb.RenameBlobRequest)) {
+    throw new Error('Expected argument of type pulumirpc.RenameBlobRequest');
+  }
+  return new Buffer(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_RenameBlobRequest(buffer_arg) {
+  return backend_pb.RenameBlobRequest.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+
+// Backend is a pluggable service that stores the state of stacks on behalf of the CLI, so that stacks may be kept in
+// stores the CLI does not itself understand.  A backend plugin named `pulumi-backend-<scheme>` serves the URLs with
+// that scheme given to `pulumi login`; it is passed the URL as its only argument.  The CLI runs updates itself, and
+// keeps each stack's checkpoint, history, backups, audit log, and references as opaque blobs in the store, each named
+// by a slash-separated key.  Since the CLI holds a plugin for the life of a single command, a plugin should exit when
+// its standard input is closed.
+var BackendService = exports.BackendService = {
+  // GetBlob returns the contents of the blob with the given key, failing with NOT_FOUND if there is none.
+  getBlob: {
+    path: '/pulumirpc.Backend/GetBlob',
+    requestStream: false,
+    responseStream: false,
+    requestType: backend_pb.GetBlobRequest,
+    responseType: backend_pb.GetBlobResponse,
+    requestSerialize: serialize_pulumirpc_GetBlobRequest,
+    requestDeserialize: deserialize_pulumirpc_GetBlobRequest,
+    responseSerialize: serialize_pulumirpc_GetBlobResponse,
+    responseDeserialize: deserialize_pulumirpc_GetBlobResponse,
+  },
+  // PutBlob creates or replaces the blob with the given key.
+  putBlob: {
+    path: '/pulumirpc.Backend/PutBlob',
+    requestStream: false,
+    responseStream: false,
+    requestType: backend_pb.PutBlobRequest,
+    responseType: google_protobuf_empty_pb.Empty,
+    requestSerialize: serialize_pulumirpc_PutBlobRequest,
+    requestDeserialize: deserialize_pulumirpc_PutBlobRequest,
+    responseSerialize: serialize_google_protobuf_Empty,
+    responseDeserialize: deserialize_google_protobuf_Empty,
+  },
+  // DeleteBlob deletes the blob with the given key, if there is one.
+  deleteBlob: {
+    path: '/pulumirpc.Backend/DeleteBlob',
+    requestStream: false,
+    responseStream: false,
+    requestType: backend_pb.DeleteBlobRequest,
+    responseType: google_protobuf_empty_pb.Empty,
+    requestSerialize: serialize_pulumirpc_DeleteBlobRequest,
+    requestDeserialize: deserialize_pulumirpc_DeleteBlobRequest,
+    responseSerialize: serialize_google_protobuf_Empty,
+    responseDeserialize: deserialize_google_protobuf_Empty,
+  },
+  // RenameBlob atomically moves the blob with the given key to a new key, replacing any blob that already has it,
+  // failing with NOT_FOUND if there is no blob to move.
+  renameBlob: {
+    path: '/pulumirpc.Backend/RenameBlob',
+    requestStream: false,
+    responseStream: false,
+    requestType: backend_pb.RenameBlobRequest,
+    responseType: google_protobuf_empty_pb.Empty,
+    requestSerialize: serialize_pulumirpc_RenameBlobRequest,
+    requestDeserialize: deserialize_pulumirpc_RenameBlobRequest,
+    responseSerialize: serialize_google_protobuf_Empty,
+    responseDeserialize: deserialize_google_protobuf_Empty,
+  },
+  // ListBlobs returns the keys of all blobs that begin with the given prefix, in lexical order.
+  listBlobs: {
+    path: '/pulumirpc.Backend/ListBlobs',
+    requestStream: false,
+    responseStream: false,
+    requestType: backend_pb.ListBlobsRequest,
+    responseType: backend_pb.ListBlobsResponse,
+    requestSerialize: serialize_pulumirpc_ListBlobsRequest,
+    requestDeserialize: deserialize_pulumirpc_ListBlobsRequest,
+    responseSerialize: serialize_pulumirpc_ListBlobsResponse,
+    responseDeserialize: deserialize_pulumirpc_ListBlobsResponse,
+  },
+  // GetPluginInfo returns generic information about this plugin, like its version.
+  getPluginInfo: {
+    path: '/pulumirpc.Backend/GetPluginInfo',
+    requestStream: false,
+    responseStream: false,
+    requestType: google_protobuf_empty_pb.Empty,
+    responseType: plugin_pb.PluginInfo,
+    requestSerialize: serialize_google_protobuf_Empty,
+    requestDeserialize: deserialize_google_protobuf_Empty,
+    responseSerialize: serialize_pulumirpc_PluginInfo,
+    responseDeserialize: deserialize_pulumirpc_PluginInfo,
+  },
+};
+
+exports.BackendClient = grpc.makeGenericClientConstructor(BackendService);
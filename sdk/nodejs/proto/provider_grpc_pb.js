@@ -33,6 +33,17 @@ function deserialize_google_protobuf_Empty(buffer_arg) {
   return google_protobuf_empty_pb.Empty.deserializeBinary(new Uint8Array(buffer_arg));
 }
 
+function serialize_google_protobuf_Struct(arg) {
+  if (!(arg instanceof google_protobuf_struct_pb.Struct)) {
+    throw new Error('Expected argument of type google.protobuf.Struct');
+  }
+  return new Buffer(arg.serializeBinary());
+}
+
+function deserialize_google_protobuf_Struct(buffer_arg) {
+  return google_protobuf_struct_pb.Struct.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
 function serialize_pulumirpc_CheckRequest(arg) {
   if (!(arg instanceof provider_pb.CheckRequest)) {
     throw new Error('Expected argument of type pulumirpc.CheckRequest');
@@ -202,6 +213,21 @@ function deserialize_pulumirpc_UpdateResponse(buffer_arg) {
 // ResourceProvider is a service that understands how to create, read, update, or delete resources for types defined
 // within a single package.  It is driven by the overall planning engine in response to resource diffs.
 var ResourceProviderService = exports.ResourceProviderService = {
+  // Parameterize parameterizes the provider before it is configured, e.g. with the version of the API it should
+  // target or with endpoint information it should use to discover that version.  The parameters are recorded in the
+  // state of the provider resource so that later operations reproduce the same behavior.  Providers that do not
+  // accept parameters should return UNIMPLEMENTED.
+  parameterize: {
+    path: '/pulumirpc.ResourceProvider/Parameterize',
+    requestStream: false,
+    responseStream: false,
+    requestType: google_protobuf_struct_pb.Struct,
+    responseType: google_protobuf_empty_pb.Empty,
+    requestSerialize: serialize_google_protobuf_Struct,
+    requestDeserialize: deserialize_google_protobuf_Struct,
+    responseSerialize: serialize_google_protobuf_Empty,
+    responseDeserialize: deserialize_google_protobuf_Empty,
+  },
   // Configure configures the resource provider with "globals" that control its behavior.
   configure: {
     path: '/pulumirpc.ResourceProvider/Configure',
@@ -214,6 +240,20 @@ var ResourceProviderService = exports.ResourceProviderService = {
     responseSerialize: serialize_google_protobuf_Empty,
     responseDeserialize: deserialize_google_protobuf_Empty,
   },
+  // CheckAuth verifies that the provider's configured credentials are valid and grant the access it needs.  It is
+  // called after Configure and before any resource operations, so that problems can be reported up front.  Providers
+  // that cannot perform such a check should return UNIMPLEMENTED.
+  checkAuth: {
+    path: '/pulumirpc.ResourceProvider/CheckAuth',
+    requestStream: false,
+    responseStream: false,
+    requestType: google_protobuf_empty_pb.Empty,
+    responseType: google_protobuf_empty_pb.Empty,
+    requestSerialize: serialize_google_protobuf_Empty,
+    requestDeserialize: deserialize_google_protobuf_Empty,
+    responseSerialize: serialize_google_protobuf_Empty,
+    responseDeserialize: deserialize_google_protobuf_Empty,
+  },
   // Invoke dynamically executes a built-in function in the provider.
   invoke: {
     path: '/pulumirpc.ResourceProvider/Invoke',
@@ -226,6 +266,19 @@ var ResourceProviderService = exports.ResourceProviderService = {
     responseSerialize: serialize_pulumirpc_InvokeResponse,
     responseDeserialize: deserialize_pulumirpc_InvokeResponse,
   },
+  // Call dynamically executes a method of an existing resource in the provider.  The resource is passed in
+  // the `__self__` argument; its result participates in dependency tracking like any other output.
+  call: {
+    path: '/pulumirpc.ResourceProvider/Call',
+    requestStream: false,
+    responseStream: false,
+    requestType: provider_pb.InvokeRequest,
+    responseType: provider_pb.InvokeResponse,
+    requestSerialize: serialize_pulumirpc_InvokeRequest,
+    requestDeserialize: deserialize_pulumirpc_InvokeRequest,
+    responseSerialize: serialize_pulumirpc_InvokeResponse,
+    responseDeserialize: deserialize_pulumirpc_InvokeResponse,
+  },
   // Check validates that the given property bag is valid for a resource of the given type and returns the inputs
   // that should be passed to successive calls to Diff, Create, or Update for this resource. As a rule, the provider
   // inputs returned by a call to Check should preserve the original representation of the properties as present in
@@ -268,7 +321,9 @@ var ResourceProviderService = exports.ResourceProviderService = {
     responseDeserialize: deserialize_pulumirpc_CreateResponse,
   },
   // Read the current live state associated with a resource.  Enough state must be include in the inputs to uniquely
-  // identify the resource; this is typically just the resource ID, but may also include some properties.
+  // identify the resource; this is typically just the resource ID, but may also include some properties.  If the
+  // request has a modifiedSince time, and the provider can tell that the resource has not been modified since then,
+  // it may report that the resource is unmodified instead of reading it.
   read: {
     path: '/pulumirpc.ResourceProvider/Read',
     requestStream: false,
@@ -328,6 +383,35 @@ var ResourceProviderService = exports.ResourceProviderService = {
     responseSerialize: serialize_pulumirpc_PluginInfo,
     responseDeserialize: deserialize_pulumirpc_PluginInfo,
   },
+  // GetSchema returns a JSON description of the resource and function types this provider implements, along with
+  // the configuration it accepts.  Providers that cannot describe themselves should return UNIMPLEMENTED.
+  getSchema: {
+    path: '/pulumirpc.ResourceProvider/GetSchema',
+    requestStream: false,
+    responseStream: false,
+    requestType: google_protobuf_empty_pb.Empty,
+    responseType: google_protobuf_struct_pb.Struct,
+    requestSerialize: serialize_google_protobuf_Empty,
+    requestDeserialize: deserialize_google_protobuf_Empty,
+    responseSerialize: serialize_google_protobuf_Struct,
+    responseDeserialize: deserialize_google_protobuf_Struct,
+  },
+  // Discover enumerates the existing resources that the configured provider can see, so that they may be imported.
+  // The request may restrict them with `types`, a list of resource types; `tags`, a map of tags that resources must
+  // carry; `resourceGroup`; and `vpc`.  The response's `resources` list holds an object for each resource with its
+  // `type`, `id`, a suggested `name`, and its `properties`, as they would be written in a program.  Providers that
+  // cannot enumerate resources should return UNIMPLEMENTED.
+  discover: {
+    path: '/pulumirpc.ResourceProvider/Discover',
+    requestStream: false,
+    responseStream: false,
+    requestType: google_protobuf_struct_pb.Struct,
+    responseType: google_protobuf_struct_pb.Struct,
+    requestSerialize: serialize_google_protobuf_Struct,
+    requestDeserialize: deserialize_google_protobuf_Struct,
+    responseSerialize: serialize_google_protobuf_Struct,
+    responseDeserialize: deserialize_google_protobuf_Struct,
+  },
 };
 
 exports.ResourceProviderClient = grpc.makeGenericClientConstructor(ResourceProviderService);
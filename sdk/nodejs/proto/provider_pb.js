@@ -988,7 +988,11 @@ proto.pulumirpc.CheckRequest.toObject = function(includeInstance, msg) {
   var f, obj = {
     urn: jspb.Message.getFieldWithDefault(msg, 1, ""),
     olds: (f = msg.getOlds()) && google_protobuf_struct_pb.Struct.toObject(includeInstance, f),
-    news: (f = msg.getNews()) && google_protobuf_struct_pb.Struct.toObject(includeInstance, f)
+    news: (f = msg.getNews()) && google_protobuf_struct_pb.Struct.toObject(includeInstance, f),
+    randomseed: msg.getRandomseed_asB64(),
+    autonamingsuffixlength: jspb.Message.getFieldWithDefault(msg, 5, 0),
+    autonamingdelimiter: jspb.Message.getFieldWithDefault(msg, 6, ""),
+    autonamingverbatim: jspb.Message.getFieldWithDefault(msg, 7, false)
   };
 
   if (includeInstance) {
@@ -1039,6 +1043,22 @@ proto.pulumirpc.CheckRequest.deserializeBinaryFromReader = function(msg, reader)
       reader.readMessage(value,google_protobuf_struct_pb.Struct.deserializeBinaryFromReader);
       msg.setNews(value);
       break;
+    case 4:
+      var value = /** @type {!Uint8Array} */ (reader.readBytes());
+      msg.setRandomseed(value);
+      break;
+    case 5:
+      var value = /** @type {number} */ (reader.readInt32());
+      msg.setAutonamingsuffixlength(value);
+      break;
+    case 6:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setAutonamingdelimiter(value);
+      break;
+    case 7:
+      var value = /** @type {boolean} */ (reader.readBool());
+      msg.setAutonamingverbatim(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -1091,6 +1111,34 @@ proto.pulumirpc.CheckRequest.serializeBinaryToWriter = function(message, writer)
       google_protobuf_struct_pb.Struct.serializeBinaryToWriter
     );
   }
+  f = message.getRandomseed_asU8();
+  if (f.length > 0) {
+    writer.writeBytes(
+      4,
+      f
+    );
+  }
+  f = message.getAutonamingsuffixlength();
+  if (f !== 0) {
+    writer.writeInt32(
+      5,
+      f
+    );
+  }
+  f = message.getAutonamingdelimiter();
+  if (f.length > 0) {
+    writer.writeString(
+      6,
+      f
+    );
+  }
+  f = message.getAutonamingverbatim();
+  if (f) {
+    writer.writeBool(
+      7,
+      f
+    );
+  }
 };
 
 
@@ -1169,6 +1217,92 @@ proto.pulumirpc.CheckRequest.prototype.hasNews = function() {
 };
 
 
+/**
+ * optional bytes randomSeed = 4;
+ * @return {!(string|Uint8Array)}
+ */
+proto.pulumirpc.CheckRequest.prototype.getRandomseed = function() {
+  return /** @type {!(string|Uint8Array)} */ (jspb.Message.getFieldWithDefault(this, 4, ""));
+};
+
+
+/**
+ * optional bytes randomSeed = 4;
+ * This is a type-conversion wrapper around `getRandomseed()`
+ * @return {string}
+ */
+proto.pulumirpc.CheckRequest.prototype.getRandomseed_asB64 = function() {
+  return /** @type {string} */ (jspb.Message.bytesAsB64(
+      this.getRandomseed()));
+};
+
+
+/**
+ * optional bytes randomSeed = 4;
+ * Note that Uint8Array is not supported on all browsers.
+ * @see http://caniuse.com/Uint8Array
+ * This is a type-conversion wrapper around `getRandomseed()`
+ * @return {!Uint8Array}
+ */
+proto.pulumirpc.CheckRequest.prototype.getRandomseed_asU8 = function() {
+  return /** @type {!Uint8Array} */ (jspb.Message.bytesAsU8(
+      this.getRandomseed()));
+};
+
+
+/** @param {!(string|Uint8Array)} value */
+proto.pulumirpc.CheckRequest.prototype.setRandomseed = function(value) {
+  jspb.Message.setProto3BytesField(this, 4, value);
+};
+
+
+/**
+ * optional int32 autonamingSuffixLength = 5;
+ * @return {number}
+ */
+proto.pulumirpc.CheckRequest.prototype.getAutonamingsuffixlength = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 5, 0));
+};
+
+
+/** @param {number} value */
+proto.pulumirpc.CheckRequest.prototype.setAutonamingsuffixlength = function(value) {
+  jspb.Message.setProto3IntField(this, 5, value);
+};
+
+
+/**
+ * optional string autonamingDelimiter = 6;
+ * @return {string}
+ */
+proto.pulumirpc.CheckRequest.prototype.getAutonamingdelimiter = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 6, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.CheckRequest.prototype.setAutonamingdelimiter = function(value) {
+  jspb.Message.setProto3StringField(this, 6, value);
+};
+
+
+/**
+ * optional bool autonamingVerbatim = 7;
+ * Note that Boolean fields may be set to 0/1 when serialized from a Java server.
+ * You should avoid comparisons like {@code val === true/false} in those cases.
+ * @return {boolean}
+ */
+proto.pulumirpc.CheckRequest.prototype.getAutonamingverbatim = function() {
+  return /** @type {boolean} */ (jspb.Message.getFieldWithDefault(this, 7, false));
+};
+
+
+/** @param {boolean} value */
+proto.pulumirpc.CheckRequest.prototype.setAutonamingverbatim = function(value) {
+  jspb.Message.setProto3BooleanField(this, 7, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -1429,7 +1563,8 @@ proto.pulumirpc.CheckFailure.prototype.toObject = function(opt_includeInstance)
 proto.pulumirpc.CheckFailure.toObject = function(includeInstance, msg) {
   var f, obj = {
     property: jspb.Message.getFieldWithDefault(msg, 1, ""),
-    reason: jspb.Message.getFieldWithDefault(msg, 2, "")
+    reason: jspb.Message.getFieldWithDefault(msg, 2, ""),
+    suggestion: jspb.Message.getFieldWithDefault(msg, 3, "")
   };
 
   if (includeInstance) {
@@ -1474,6 +1609,10 @@ proto.pulumirpc.CheckFailure.deserializeBinaryFromReader = function(msg, reader)
       var value = /** @type {string} */ (reader.readString());
       msg.setReason(value);
       break;
+    case 3:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setSuggestion(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -1517,6 +1656,13 @@ proto.pulumirpc.CheckFailure.serializeBinaryToWriter = function(message, writer)
       f
     );
   }
+  f = message.getSuggestion();
+  if (f.length > 0) {
+    writer.writeString(
+      3,
+      f
+    );
+  }
 };
 
 
@@ -1550,6 +1696,21 @@ proto.pulumirpc.CheckFailure.prototype.setReason = function(value) {
 };
 
 
+/**
+ * optional string suggestion = 3;
+ * @return {string}
+ */
+proto.pulumirpc.CheckFailure.prototype.getSuggestion = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 3, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.CheckFailure.prototype.setSuggestion = function(value) {
+  jspb.Message.setProto3StringField(this, 3, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -1864,7 +2025,8 @@ proto.pulumirpc.DiffResponse.toObject = function(includeInstance, msg) {
     replacesList: jspb.Message.getRepeatedField(msg, 1),
     stablesList: jspb.Message.getRepeatedField(msg, 2),
     deletebeforereplace: jspb.Message.getFieldWithDefault(msg, 3, false),
-    changes: jspb.Message.getFieldWithDefault(msg, 4, 0)
+    changes: jspb.Message.getFieldWithDefault(msg, 4, 0),
+    replacereasonsMap: (f = msg.getReplacereasonsMap()) ? f.toObject(includeInstance, undefined) : []
   };
 
   if (includeInstance) {
@@ -1917,6 +2079,12 @@ proto.pulumirpc.DiffResponse.deserializeBinaryFromReader = function(msg, reader)
       var value = /** @type {!proto.pulumirpc.DiffResponse.DiffChanges} */ (reader.readEnum());
       msg.setChanges(value);
       break;
+    case 5:
+      var value = msg.getReplacereasonsMap();
+      reader.readMessage(value, function(message, reader) {
+        jspb.Map.deserializeBinary(message, reader, jspb.BinaryReader.prototype.readString, jspb.BinaryReader.prototype.readString);
+         });
+      break;
     default:
       reader.skipField();
       break;
@@ -1974,6 +2142,10 @@ proto.pulumirpc.DiffResponse.serializeBinaryToWriter = function(message, writer)
       f
     );
   }
+  f = message.getReplacereasonsMap(true);
+  if (f && f.getLength() > 0) {
+    f.serializeBinary(5, writer, jspb.BinaryWriter.prototype.writeString, jspb.BinaryWriter.prototype.writeString);
+  }
 };
 
 
@@ -2076,6 +2248,24 @@ proto.pulumirpc.DiffResponse.prototype.setChanges = function(value) {
 };
 
 
+/**
+ * map<string, string> replaceReasons = 5;
+ * @param {boolean=} opt_noLazyCreate Do not create the map if
+ * empty, instead returning `undefined`
+ * @return {!jspb.Map<string,string>}
+ */
+proto.pulumirpc.DiffResponse.prototype.getReplacereasonsMap = function(opt_noLazyCreate) {
+  return /** @type {!jspb.Map<string,string>} */ (
+      jspb.Message.getMapField(this, 5, opt_noLazyCreate,
+      null));
+};
+
+
+proto.pulumirpc.DiffResponse.prototype.clearReplacereasonsMap = function() {
+  this.getReplacereasonsMap().clear();
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -2497,7 +2687,8 @@ proto.pulumirpc.ReadRequest.toObject = function(includeInstance, msg) {
   var f, obj = {
     id: jspb.Message.getFieldWithDefault(msg, 1, ""),
     urn: jspb.Message.getFieldWithDefault(msg, 2, ""),
-    properties: (f = msg.getProperties()) && google_protobuf_struct_pb.Struct.toObject(includeInstance, f)
+    properties: (f = msg.getProperties()) && google_protobuf_struct_pb.Struct.toObject(includeInstance, f),
+    modifiedsince: jspb.Message.getFieldWithDefault(msg, 4, 0)
   };
 
   if (includeInstance) {
@@ -2547,6 +2738,10 @@ proto.pulumirpc.ReadRequest.deserializeBinaryFromReader = function(msg, reader)
       reader.readMessage(value,google_protobuf_struct_pb.Struct.deserializeBinaryFromReader);
       msg.setProperties(value);
       break;
+    case 4:
+      var value = /** @type {number} */ (reader.readInt64());
+      msg.setModifiedsince(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -2598,6 +2793,13 @@ proto.pulumirpc.ReadRequest.serializeBinaryToWriter = function(message, writer)
       google_protobuf_struct_pb.Struct.serializeBinaryToWriter
     );
   }
+  f = message.getModifiedsince();
+  if (f !== 0) {
+    writer.writeInt64(
+      4,
+      f
+    );
+  }
 };
 
 
@@ -2661,6 +2863,21 @@ proto.pulumirpc.ReadRequest.prototype.hasProperties = function() {
 };
 
 
+/**
+ * optional int64 modifiedSince = 4;
+ * @return {number}
+ */
+proto.pulumirpc.ReadRequest.prototype.getModifiedsince = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 4, 0));
+};
+
+
+/** @param {number} value */
+proto.pulumirpc.ReadRequest.prototype.setModifiedsince = function(value) {
+  jspb.Message.setProto3IntField(this, 4, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -2709,7 +2926,8 @@ proto.pulumirpc.ReadResponse.prototype.toObject = function(opt_includeInstance)
 proto.pulumirpc.ReadResponse.toObject = function(includeInstance, msg) {
   var f, obj = {
     id: jspb.Message.getFieldWithDefault(msg, 1, ""),
-    properties: (f = msg.getProperties()) && google_protobuf_struct_pb.Struct.toObject(includeInstance, f)
+    properties: (f = msg.getProperties()) && google_protobuf_struct_pb.Struct.toObject(includeInstance, f),
+    unmodified: jspb.Message.getFieldWithDefault(msg, 3, false)
   };
 
   if (includeInstance) {
@@ -2755,6 +2973,10 @@ proto.pulumirpc.ReadResponse.deserializeBinaryFromReader = function(msg, reader)
       reader.readMessage(value,google_protobuf_struct_pb.Struct.deserializeBinaryFromReader);
       msg.setProperties(value);
       break;
+    case 3:
+      var value = /** @type {boolean} */ (reader.readBool());
+      msg.setUnmodified(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -2799,6 +3021,13 @@ proto.pulumirpc.ReadResponse.serializeBinaryToWriter = function(message, writer)
       google_protobuf_struct_pb.Struct.serializeBinaryToWriter
     );
   }
+  f = message.getUnmodified();
+  if (f) {
+    writer.writeBool(
+      3,
+      f
+    );
+  }
 };
 
 
@@ -2847,6 +3076,23 @@ proto.pulumirpc.ReadResponse.prototype.hasProperties = function() {
 };
 
 
+/**
+ * optional bool unmodified = 3;
+ * Note that Boolean fields may be set to 0/1 when serialized from a Java server.
+ * You should avoid comparisons like {@code val === true/false} in those cases.
+ * @return {boolean}
+ */
+proto.pulumirpc.ReadResponse.prototype.getUnmodified = function() {
+  return /** @type {boolean} */ (jspb.Message.getFieldWithDefault(this, 3, false));
+};
+
+
+/** @param {boolean} value */
+proto.pulumirpc.ReadResponse.prototype.setUnmodified = function(value) {
+  jspb.Message.setProto3BooleanField(this, 3, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -124,6 +124,17 @@ var ResourceMonitorService = exports.ResourceMonitorService = {
     responseSerialize: serialize_pulumirpc_InvokeResponse,
     responseDeserialize: deserialize_pulumirpc_InvokeResponse,
   },
+  call: {
+    path: '/pulumirpc.ResourceMonitor/Call',
+    requestStream: false,
+    responseStream: false,
+    requestType: provider_pb.InvokeRequest,
+    responseType: provider_pb.InvokeResponse,
+    requestSerialize: serialize_pulumirpc_InvokeRequest,
+    requestDeserialize: deserialize_pulumirpc_InvokeRequest,
+    responseSerialize: serialize_pulumirpc_InvokeResponse,
+    responseDeserialize: deserialize_pulumirpc_InvokeResponse,
+  },
   readResource: {
     path: '/pulumirpc.ResourceMonitor/ReadResource',
     requestStream: false,
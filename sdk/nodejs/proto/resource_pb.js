@@ -570,7 +570,7 @@ if (goog.DEBUG && !COMPILED) {
  * @private {!Array<number>}
  * @const
  */
-proto.pulumirpc.RegisterResourceRequest.repeatedFields_ = [7];
+proto.pulumirpc.RegisterResourceRequest.repeatedFields_ = [7,10,11,12];
 
 
 
@@ -608,7 +608,12 @@ proto.pulumirpc.RegisterResourceRequest.toObject = function(includeInstance, msg
     object: (f = msg.getObject()) && google_protobuf_struct_pb.Struct.toObject(includeInstance, f),
     protect: jspb.Message.getFieldWithDefault(msg, 6, false),
     dependenciesList: jspb.Message.getRepeatedField(msg, 7),
-    provider: jspb.Message.getFieldWithDefault(msg, 8, "")
+    provider: jspb.Message.getFieldWithDefault(msg, 8, ""),
+    sourceposition: jspb.Message.getFieldWithDefault(msg, 9, ""),
+    dependsonList: jspb.Message.getRepeatedField(msg, 10),
+    additionalsecretoutputsList: jspb.Message.getRepeatedField(msg, 11),
+    replaceonchangesList: jspb.Message.getRepeatedField(msg, 12),
+    deletebeforereplace: jspb.Message.getFieldWithDefault(msg, 13, false)
   };
 
   if (includeInstance) {
@@ -678,6 +683,26 @@ proto.pulumirpc.RegisterResourceRequest.deserializeBinaryFromReader = function(m
       var value = /** @type {string} */ (reader.readString());
       msg.setProvider(value);
       break;
+    case 9:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setSourceposition(value);
+      break;
+    case 10:
+      var value = /** @type {string} */ (reader.readString());
+      msg.addDependson(value);
+      break;
+    case 11:
+      var value = /** @type {string} */ (reader.readString());
+      msg.addAdditionalsecretoutputs(value);
+      break;
+    case 12:
+      var value = /** @type {string} */ (reader.readString());
+      msg.addReplaceonchanges(value);
+      break;
+    case 13:
+      var value = /** @type {boolean} */ (reader.readBool());
+      msg.setDeletebeforereplace(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -764,6 +789,41 @@ proto.pulumirpc.RegisterResourceRequest.serializeBinaryToWriter = function(messa
       f
     );
   }
+  f = message.getSourceposition();
+  if (f.length > 0) {
+    writer.writeString(
+      9,
+      f
+    );
+  }
+  f = message.getDependsonList();
+  if (f.length > 0) {
+    writer.writeRepeatedString(
+      10,
+      f
+    );
+  }
+  f = message.getAdditionalsecretoutputsList();
+  if (f.length > 0) {
+    writer.writeRepeatedString(
+      11,
+      f
+    );
+  }
+  f = message.getReplaceonchangesList();
+  if (f.length > 0) {
+    writer.writeRepeatedString(
+      12,
+      f
+    );
+  }
+  f = message.getDeletebeforereplace();
+  if (f) {
+    writer.writeBool(
+      13,
+      f
+    );
+  }
 };
 
 
@@ -920,6 +980,125 @@ proto.pulumirpc.RegisterResourceRequest.prototype.setProvider = function(value)
 };
 
 
+/**
+ * optional string sourcePosition = 9;
+ * @return {string}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.getSourceposition = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 9, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.RegisterResourceRequest.prototype.setSourceposition = function(value) {
+  jspb.Message.setProto3StringField(this, 9, value);
+};
+
+
+/**
+ * repeated string dependsOn = 10;
+ * @return {!Array.<string>}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.getDependsonList = function() {
+  return /** @type {!Array.<string>} */ (jspb.Message.getRepeatedField(this, 10));
+};
+
+
+/** @param {!Array.<string>} value */
+proto.pulumirpc.RegisterResourceRequest.prototype.setDependsonList = function(value) {
+  jspb.Message.setField(this, 10, value || []);
+};
+
+
+/**
+ * @param {!string} value
+ * @param {number=} opt_index
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.addDependson = function(value, opt_index) {
+  jspb.Message.addToRepeatedField(this, 10, value, opt_index);
+};
+
+
+proto.pulumirpc.RegisterResourceRequest.prototype.clearDependsonList = function() {
+  this.setDependsonList([]);
+};
+
+
+/**
+ * repeated string additionalSecretOutputs = 11;
+ * @return {!Array.<string>}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.getAdditionalsecretoutputsList = function() {
+  return /** @type {!Array.<string>} */ (jspb.Message.getRepeatedField(this, 11));
+};
+
+
+/** @param {!Array.<string>} value */
+proto.pulumirpc.RegisterResourceRequest.prototype.setAdditionalsecretoutputsList = function(value) {
+  jspb.Message.setField(this, 11, value || []);
+};
+
+
+/**
+ * @param {!string} value
+ * @param {number=} opt_index
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.addAdditionalsecretoutputs = function(value, opt_index) {
+  jspb.Message.addToRepeatedField(this, 11, value, opt_index);
+};
+
+
+proto.pulumirpc.RegisterResourceRequest.prototype.clearAdditionalsecretoutputsList = function() {
+  this.setAdditionalsecretoutputsList([]);
+};
+
+
+/**
+ * repeated string replaceOnChanges = 12;
+ * @return {!Array.<string>}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.getReplaceonchangesList = function() {
+  return /** @type {!Array.<string>} */ (jspb.Message.getRepeatedField(this, 12));
+};
+
+
+/** @param {!Array.<string>} value */
+proto.pulumirpc.RegisterResourceRequest.prototype.setReplaceonchangesList = function(value) {
+  jspb.Message.setField(this, 12, value || []);
+};
+
+
+/**
+ * @param {!string} value
+ * @param {number=} opt_index
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.addReplaceonchanges = function(value, opt_index) {
+  jspb.Message.addToRepeatedField(this, 12, value, opt_index);
+};
+
+
+proto.pulumirpc.RegisterResourceRequest.prototype.clearReplaceonchangesList = function() {
+  this.setReplaceonchangesList([]);
+};
+
+
+/**
+ * optional bool deleteBeforeReplace = 13;
+ * Note that Boolean fields may be set to 0/1 when serialized from a Java server.
+ * You should avoid comparisons like {@code val === true/false} in those cases.
+ * @return {boolean}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.getDeletebeforereplace = function() {
+  return /** @type {boolean} */ (jspb.Message.getFieldWithDefault(this, 13, false));
+};
+
+
+/** @param {boolean} value */
+proto.pulumirpc.RegisterResourceRequest.prototype.setDeletebeforereplace = function(value) {
+  jspb.Message.setProto3BooleanField(this, 13, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -167,6 +167,21 @@ export interface CustomResourceOptions extends ResourceOptions {
      * provider bag (see also ComponentResourceOptions.providers).
      */
     provider?: ProviderResource;
+    /**
+     * The names of outputs for this resource that should be treated as secrets, whether or not the provider marks
+     * them as such.  Their values are recorded in the stack's state but are never displayed.
+     */
+    additionalSecretOutputs?: string[];
+    /**
+     * Paths of input properties, such as "spec.template" or "*" for all of them, whose changes should replace this
+     * resource rather than update it in place, whether or not the provider would.
+     */
+    replaceOnChanges?: string[];
+    /**
+     * When set to true, replacing this resource deletes the existing resource before creating its replacement, rather
+     * than after.  Any resources that depend upon it are replaced as well.
+     */
+    deleteBeforeReplace?: boolean;
 }
 
 /**
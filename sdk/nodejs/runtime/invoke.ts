@@ -15,10 +15,10 @@
 import * as grpc from "grpc";
 import { InvokeOptions } from "../invoke";
 import * as log from "../log";
-import { Inputs } from "../resource";
+import { CustomResource, Inputs, Output, Resource } from "../resource";
 import { debuggablePromise } from "./debuggable";
 import { deserializeProperties, serializeProperties, unknownValue } from "./rpc";
-import { excessiveDebugOutput, getMonitor, getRootResource, rpcKeepAlive, serialize } from "./settings";
+import { excessiveDebugOutput, getMonitor, getRootResource, isDryRun, rpcKeepAlive, serialize } from "./settings";
 
 const gstruct = require("google-protobuf/google/protobuf/struct_pb.js");
 const resproto = require("../proto/resource_pb.js");
@@ -95,6 +95,99 @@ export async function invoke(tok: string, props: Inputs, opts?: InvokeOptions):
     }
 }
 
+/**
+ * call dynamically calls the method, tok, of the resource res, which is offered by a provider plugin.  The inputs
+ * can be a bag of computed values (Ts or Promise<T>s).  The result is an Output that depends on res and on any
+ * resources the inputs depend on.  During previews, the result is unknown if res or any of the inputs are unknown.
+ */
+export function call<T>(tok: string, props: Inputs, res: Resource, opts?: InvokeOptions): Output<T> {
+    log.debug(`Calling method: tok=${tok}` + (excessiveDebugOutput ? `, props=${JSON.stringify(props)}` : ``));
+
+    opts = opts || {};
+    if (opts.provider === undefined) {
+        opts.provider = res.getProvider(tok);
+    }
+    const provider = opts.provider;
+
+    // The result depends on the resource itself and on anything its arguments depend on.
+    const resources = new Set<Resource>([res]);
+    for (const k of Object.keys(props)) {
+        const prop = props[k];
+        if (Output.isInstance(prop)) {
+            prop.resources().forEach(r => resources.add(r));
+        }
+    }
+
+    let resolveIsKnown: (isKnown: boolean) => void;
+    const isKnown = new Promise<boolean>(resolve => resolveIsKnown = resolve);
+    const result = debuggablePromise((async () => {
+        // Wait for all values to be available, and then perform the RPC.
+        const done = rpcKeepAlive();
+        try {
+            const args = await serializeProperties(`call:${tok}`, props);
+            const id = CustomResource.isInstance(res) ? await res.id.promise() : undefined;
+            args["__self__"] = {
+                urn: await res.urn.promise(),
+                id: id || unknownValue,
+            };
+            const obj = gstruct.Struct.fromJavaScript(args);
+
+            let providerRef: string | undefined;
+            if (provider !== undefined) {
+                const providerURN = await provider.urn.promise();
+                const providerID = await provider.id.promise() || unknownValue;
+                providerRef = `${providerURN}::${providerID}`;
+            }
+
+            const req = new resproto.InvokeRequest();
+            req.setTok(tok);
+            req.setArgs(obj);
+            req.setProvider(providerRef);
+            const monitor: any = getMonitor();
+            const resp: any = await new Promise((innerResolve, innerReject) =>
+                monitor.call(req, (err: grpc.StatusObject, innerResponse: any) => {
+                    log.debug(`Call RPC finished: tok=${tok}; err: ${err}, resp: ${innerResponse}`);
+                    if (err) {
+                        // If the monitor is unavailable, it is in the process of shutting down or has already
+                        // shut down. Don't emit an error and don't do any more RPCs.
+                        if (err.code === grpc.status.UNAVAILABLE) {
+                            log.debug("Resource monitor is terminating");
+                            waitForDeath();
+                        }
+                        innerReject(new Error(err.details));
+                    }
+                    else {
+                        innerResolve(innerResponse);
+                    }
+                }));
+
+            // If there were failures, propagate them.
+            const failures: any = resp.getFailuresList();
+            if (failures && failures.length) {
+                throw new Error(`Call of '${tok}' failed: ${failures[0].reason} (${failures[0].property})`);
+            }
+
+            // A missing return value means that the engine could not perform the call because some of its
+            // arguments are not yet known.
+            if (!resp.hasReturn()) {
+                resolveIsKnown(!isDryRun());
+                return <T><any>undefined;
+            }
+            resolveIsKnown(true);
+            return <T>deserializeProperties(resp.getReturn());
+        }
+        catch (err) {
+            resolveIsKnown(false);
+            throw err;
+        }
+        finally {
+            done();
+        }
+    })());
+
+    return new Output<T>(resources, result, isKnown);
+}
+
 /**
  * waitForDeath loops forever. See the comments in resource.ts on the function with
  * the same name for an explanation as to why this exists.
@@ -13,6 +13,7 @@
 // limitations under the License.
 
 import * as grpc from "grpc";
+import * as path from "path";
 import * as log from "../log";
 import { CustomResourceOptions, ID, Input, Inputs, Output, Resource, ResourceOptions, URN } from "../resource";
 import { debuggablePromise, errorString } from "./debuggable";
@@ -47,6 +48,8 @@ interface ResourceResolverOperation {
     serializedProps: Record<string, any>;
     // A set of dependency URNs that this resource is dependent upon (both implicitly and explicitly).
     dependencies: Set<URN>;
+    // The subset of those dependency URNs that were requested explicitly with the dependsOn option.
+    explicitDependencies: Set<URN>;
 }
 
 /**
@@ -102,6 +105,36 @@ export function readResource(res: Resource, t: string, name: string, props: Inpu
     }));
 }
 
+// sdkRoot is the root directory of this package, whose frames are skipped when finding a resource's source position.
+const sdkRoot = path.resolve(__dirname, "..");
+
+/**
+ * getSourcePosition returns the `file:line:column` position of the innermost stack frame that belongs to the user's
+ * program, rather than to this package or to another package (such as a resource provider's SDK).  This lets the
+ * engine attribute diagnostics about a resource to the line of code that allocated it.  It returns the empty string
+ * if no such frame can be found.
+ */
+function getSourcePosition(): string {
+    const stack = new Error().stack;
+    if (!stack) {
+        return "";
+    }
+    for (const line of stack.split("\n").slice(1)) {
+        // Frames look like either `    at func (file:line:column)` or `    at file:line:column`.
+        const match = /^\s*at (?:.*\()?(.+):(\d+):(\d+)\)?$/.exec(line);
+        if (!match) {
+            continue;
+        }
+        const file = match[1];
+        if (!path.isAbsolute(file) || file.startsWith(sdkRoot + path.sep) ||
+                file.split(path.sep).indexOf("node_modules") !== -1) {
+            continue;
+        }
+        return `${file}:${match[2]}:${match[3]}`;
+    }
+    return "";
+}
+
 /**
  * registerResource registers a new resource object with a given type t and name.  It returns the auto-generated
  * URN and the ID that will resolve after the deployment has completed.  All properties will be initialized to property
@@ -112,6 +145,9 @@ export function registerResource(res: Resource, t: string, name: string, custom:
     const label = `resource:${name}[${t}]`;
     log.debug(`Registering resource: t=${t}, name=${name}, custom=${custom}`);
 
+    // Capture the position of the allocation now, while the program's frames are still on the stack.
+    const sourcePosition = getSourcePosition();
+
     const monitor: any = getMonitor();
     const resopAsync = prepareResource(label, res, custom, props, opts);
     debuggablePromise(resopAsync.then(async (resop) => {
@@ -127,6 +163,13 @@ export function registerResource(res: Resource, t: string, name: string, custom:
         req.setProtect(opts.protect);
         req.setProvider(resop.providerRef);
         req.setDependenciesList(Array.from(resop.dependencies));
+        req.setSourceposition(sourcePosition);
+        req.setDependsonList(Array.from(resop.explicitDependencies));
+        if (custom) {
+            req.setAdditionalsecretoutputsList((<CustomResourceOptions>opts).additionalSecretOutputs || []);
+            req.setReplaceonchangesList((<CustomResourceOptions>opts).replaceOnChanges || []);
+            req.setDeletebeforereplace(!!(<CustomResourceOptions>opts).deleteBeforeReplace);
+        }
 
         // Now run the operation, serializing the invocation if necessary.
         const opLabel = `monitor.registerResource(${label})`;
@@ -247,6 +290,7 @@ async function prepareResource(label: string, res: Resource, custom: boolean,
         parentURN: parentURN,
         providerRef: providerRef,
         dependencies: dependencies,
+        explicitDependencies: new Set<URN>(explicitURNDeps),
     };
 }
 
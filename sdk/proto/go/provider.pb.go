@@ -48,7 +48,7 @@ func (x DiffResponse_DiffChanges) String() string {
 	return proto.EnumName(DiffResponse_DiffChanges_name, int32(x))
 }
 func (DiffResponse_DiffChanges) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{8, 0}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{8, 0}
 }
 
 type ConfigureRequest struct {
@@ -62,7 +62,7 @@ func (m *ConfigureRequest) Reset()         { *m = ConfigureRequest{} }
 func (m *ConfigureRequest) String() string { return proto.CompactTextString(m) }
 func (*ConfigureRequest) ProtoMessage()    {}
 func (*ConfigureRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{0}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{0}
 }
 func (m *ConfigureRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfigureRequest.Unmarshal(m, b)
@@ -101,7 +101,7 @@ func (m *ConfigureErrorMissingKeys) Reset()         { *m = ConfigureErrorMissing
 func (m *ConfigureErrorMissingKeys) String() string { return proto.CompactTextString(m) }
 func (*ConfigureErrorMissingKeys) ProtoMessage()    {}
 func (*ConfigureErrorMissingKeys) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{1}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{1}
 }
 func (m *ConfigureErrorMissingKeys) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfigureErrorMissingKeys.Unmarshal(m, b)
@@ -140,7 +140,7 @@ func (m *ConfigureErrorMissingKeys_MissingKey) Reset()         { *m = ConfigureE
 func (m *ConfigureErrorMissingKeys_MissingKey) String() string { return proto.CompactTextString(m) }
 func (*ConfigureErrorMissingKeys_MissingKey) ProtoMessage()    {}
 func (*ConfigureErrorMissingKeys_MissingKey) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{1, 0}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{1, 0}
 }
 func (m *ConfigureErrorMissingKeys_MissingKey) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfigureErrorMissingKeys_MissingKey.Unmarshal(m, b)
@@ -187,7 +187,7 @@ func (m *InvokeRequest) Reset()         { *m = InvokeRequest{} }
 func (m *InvokeRequest) String() string { return proto.CompactTextString(m) }
 func (*InvokeRequest) ProtoMessage()    {}
 func (*InvokeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{2}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{2}
 }
 func (m *InvokeRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_InvokeRequest.Unmarshal(m, b)
@@ -240,7 +240,7 @@ func (m *InvokeResponse) Reset()         { *m = InvokeResponse{} }
 func (m *InvokeResponse) String() string { return proto.CompactTextString(m) }
 func (*InvokeResponse) ProtoMessage()    {}
 func (*InvokeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{3}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{3}
 }
 func (m *InvokeResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_InvokeResponse.Unmarshal(m, b)
@@ -275,19 +275,25 @@ func (m *InvokeResponse) GetFailures() []*CheckFailure {
 }
 
 type CheckRequest struct {
-	Urn                  string          `protobuf:"bytes,1,opt,name=urn" json:"urn,omitempty"`
-	Olds                 *_struct.Struct `protobuf:"bytes,2,opt,name=olds" json:"olds,omitempty"`
-	News                 *_struct.Struct `protobuf:"bytes,3,opt,name=news" json:"news,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+	Urn        string          `protobuf:"bytes,1,opt,name=urn" json:"urn,omitempty"`
+	Olds       *_struct.Struct `protobuf:"bytes,2,opt,name=olds" json:"olds,omitempty"`
+	News       *_struct.Struct `protobuf:"bytes,3,opt,name=news" json:"news,omitempty"`
+	RandomSeed []byte          `protobuf:"bytes,4,opt,name=randomSeed,proto3" json:"randomSeed,omitempty"`
+	// The stack's strategy for auto-generating physical names from logical names.  An engine that predates these
+	// fields leaves them all unset, in which case providers should use their default strategy.
+	AutonamingSuffixLength int32    `protobuf:"varint,5,opt,name=autonamingSuffixLength" json:"autonamingSuffixLength,omitempty"`
+	AutonamingDelimiter    string   `protobuf:"bytes,6,opt,name=autonamingDelimiter" json:"autonamingDelimiter,omitempty"`
+	AutonamingVerbatim     bool     `protobuf:"varint,7,opt,name=autonamingVerbatim" json:"autonamingVerbatim,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{} `json:"-"`
+	XXX_unrecognized       []byte   `json:"-"`
+	XXX_sizecache          int32    `json:"-"`
 }
 
 func (m *CheckRequest) Reset()         { *m = CheckRequest{} }
 func (m *CheckRequest) String() string { return proto.CompactTextString(m) }
 func (*CheckRequest) ProtoMessage()    {}
 func (*CheckRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{4}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{4}
 }
 func (m *CheckRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckRequest.Unmarshal(m, b)
@@ -328,6 +334,34 @@ func (m *CheckRequest) GetNews() *_struct.Struct {
 	return nil
 }
 
+func (m *CheckRequest) GetRandomSeed() []byte {
+	if m != nil {
+		return m.RandomSeed
+	}
+	return nil
+}
+
+func (m *CheckRequest) GetAutonamingSuffixLength() int32 {
+	if m != nil {
+		return m.AutonamingSuffixLength
+	}
+	return 0
+}
+
+func (m *CheckRequest) GetAutonamingDelimiter() string {
+	if m != nil {
+		return m.AutonamingDelimiter
+	}
+	return ""
+}
+
+func (m *CheckRequest) GetAutonamingVerbatim() bool {
+	if m != nil {
+		return m.AutonamingVerbatim
+	}
+	return false
+}
+
 type CheckResponse struct {
 	Inputs               *_struct.Struct `protobuf:"bytes,1,opt,name=inputs" json:"inputs,omitempty"`
 	Failures             []*CheckFailure `protobuf:"bytes,2,rep,name=failures" json:"failures,omitempty"`
@@ -340,7 +374,7 @@ func (m *CheckResponse) Reset()         { *m = CheckResponse{} }
 func (m *CheckResponse) String() string { return proto.CompactTextString(m) }
 func (*CheckResponse) ProtoMessage()    {}
 func (*CheckResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{5}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{5}
 }
 func (m *CheckResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckResponse.Unmarshal(m, b)
@@ -377,6 +411,7 @@ func (m *CheckResponse) GetFailures() []*CheckFailure {
 type CheckFailure struct {
 	Property             string   `protobuf:"bytes,1,opt,name=property" json:"property,omitempty"`
 	Reason               string   `protobuf:"bytes,2,opt,name=reason" json:"reason,omitempty"`
+	Suggestion           string   `protobuf:"bytes,3,opt,name=suggestion" json:"suggestion,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -386,7 +421,7 @@ func (m *CheckFailure) Reset()         { *m = CheckFailure{} }
 func (m *CheckFailure) String() string { return proto.CompactTextString(m) }
 func (*CheckFailure) ProtoMessage()    {}
 func (*CheckFailure) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{6}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{6}
 }
 func (m *CheckFailure) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckFailure.Unmarshal(m, b)
@@ -420,6 +455,13 @@ func (m *CheckFailure) GetReason() string {
 	return ""
 }
 
+func (m *CheckFailure) GetSuggestion() string {
+	if m != nil {
+		return m.Suggestion
+	}
+	return ""
+}
+
 type DiffRequest struct {
 	Id                   string          `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	Urn                  string          `protobuf:"bytes,2,opt,name=urn" json:"urn,omitempty"`
@@ -434,7 +476,7 @@ func (m *DiffRequest) Reset()         { *m = DiffRequest{} }
 func (m *DiffRequest) String() string { return proto.CompactTextString(m) }
 func (*DiffRequest) ProtoMessage()    {}
 func (*DiffRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{7}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{7}
 }
 func (m *DiffRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DiffRequest.Unmarshal(m, b)
@@ -487,6 +529,7 @@ type DiffResponse struct {
 	Stables              []string                 `protobuf:"bytes,2,rep,name=stables" json:"stables,omitempty"`
 	DeleteBeforeReplace  bool                     `protobuf:"varint,3,opt,name=deleteBeforeReplace" json:"deleteBeforeReplace,omitempty"`
 	Changes              DiffResponse_DiffChanges `protobuf:"varint,4,opt,name=changes,enum=pulumirpc.DiffResponse_DiffChanges" json:"changes,omitempty"`
+	ReplaceReasons       map[string]string        `protobuf:"bytes,5,rep,name=replaceReasons" json:"replaceReasons,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
 	XXX_unrecognized     []byte                   `json:"-"`
 	XXX_sizecache        int32                    `json:"-"`
@@ -496,7 +539,7 @@ func (m *DiffResponse) Reset()         { *m = DiffResponse{} }
 func (m *DiffResponse) String() string { return proto.CompactTextString(m) }
 func (*DiffResponse) ProtoMessage()    {}
 func (*DiffResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{8}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{8}
 }
 func (m *DiffResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DiffResponse.Unmarshal(m, b)
@@ -544,6 +587,13 @@ func (m *DiffResponse) GetChanges() DiffResponse_DiffChanges {
 	return DiffResponse_DIFF_UNKNOWN
 }
 
+func (m *DiffResponse) GetReplaceReasons() map[string]string {
+	if m != nil {
+		return m.ReplaceReasons
+	}
+	return nil
+}
+
 type CreateRequest struct {
 	Urn                  string          `protobuf:"bytes,1,opt,name=urn" json:"urn,omitempty"`
 	Properties           *_struct.Struct `protobuf:"bytes,2,opt,name=properties" json:"properties,omitempty"`
@@ -556,7 +606,7 @@ func (m *CreateRequest) Reset()         { *m = CreateRequest{} }
 func (m *CreateRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateRequest) ProtoMessage()    {}
 func (*CreateRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{9}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{9}
 }
 func (m *CreateRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateRequest.Unmarshal(m, b)
@@ -602,7 +652,7 @@ func (m *CreateResponse) Reset()         { *m = CreateResponse{} }
 func (m *CreateResponse) String() string { return proto.CompactTextString(m) }
 func (*CreateResponse) ProtoMessage()    {}
 func (*CreateResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{10}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{10}
 }
 func (m *CreateResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateResponse.Unmarshal(m, b)
@@ -640,6 +690,7 @@ type ReadRequest struct {
 	Id                   string          `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	Urn                  string          `protobuf:"bytes,2,opt,name=urn" json:"urn,omitempty"`
 	Properties           *_struct.Struct `protobuf:"bytes,3,opt,name=properties" json:"properties,omitempty"`
+	ModifiedSince        int64           `protobuf:"varint,4,opt,name=modifiedSince" json:"modifiedSince,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -649,7 +700,7 @@ func (m *ReadRequest) Reset()         { *m = ReadRequest{} }
 func (m *ReadRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadRequest) ProtoMessage()    {}
 func (*ReadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{11}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{11}
 }
 func (m *ReadRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadRequest.Unmarshal(m, b)
@@ -690,9 +741,17 @@ func (m *ReadRequest) GetProperties() *_struct.Struct {
 	return nil
 }
 
+func (m *ReadRequest) GetModifiedSince() int64 {
+	if m != nil {
+		return m.ModifiedSince
+	}
+	return 0
+}
+
 type ReadResponse struct {
 	Id                   string          `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	Properties           *_struct.Struct `protobuf:"bytes,2,opt,name=properties" json:"properties,omitempty"`
+	Unmodified           bool            `protobuf:"varint,3,opt,name=unmodified" json:"unmodified,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -702,7 +761,7 @@ func (m *ReadResponse) Reset()         { *m = ReadResponse{} }
 func (m *ReadResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse) ProtoMessage()    {}
 func (*ReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{12}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{12}
 }
 func (m *ReadResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadResponse.Unmarshal(m, b)
@@ -736,6 +795,13 @@ func (m *ReadResponse) GetProperties() *_struct.Struct {
 	return nil
 }
 
+func (m *ReadResponse) GetUnmodified() bool {
+	if m != nil {
+		return m.Unmodified
+	}
+	return false
+}
+
 type UpdateRequest struct {
 	Id                   string          `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	Urn                  string          `protobuf:"bytes,2,opt,name=urn" json:"urn,omitempty"`
@@ -750,7 +816,7 @@ func (m *UpdateRequest) Reset()         { *m = UpdateRequest{} }
 func (m *UpdateRequest) String() string { return proto.CompactTextString(m) }
 func (*UpdateRequest) ProtoMessage()    {}
 func (*UpdateRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{13}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{13}
 }
 func (m *UpdateRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateRequest.Unmarshal(m, b)
@@ -809,7 +875,7 @@ func (m *UpdateResponse) Reset()         { *m = UpdateResponse{} }
 func (m *UpdateResponse) String() string { return proto.CompactTextString(m) }
 func (*UpdateResponse) ProtoMessage()    {}
 func (*UpdateResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{14}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{14}
 }
 func (m *UpdateResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateResponse.Unmarshal(m, b)
@@ -849,7 +915,7 @@ func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
 func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
 func (*DeleteRequest) ProtoMessage()    {}
 func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{15}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{15}
 }
 func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
@@ -905,7 +971,7 @@ func (m *ErrorResourceInitFailed) Reset()         { *m = ErrorResourceInitFailed
 func (m *ErrorResourceInitFailed) String() string { return proto.CompactTextString(m) }
 func (*ErrorResourceInitFailed) ProtoMessage()    {}
 func (*ErrorResourceInitFailed) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_5951afc12b1894bc, []int{16}
+	return fileDescriptor_provider_59fc5cb0a5e9002f, []int{16}
 }
 func (m *ErrorResourceInitFailed) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ErrorResourceInitFailed.Unmarshal(m, b)
@@ -958,6 +1024,7 @@ func init() {
 	proto.RegisterType((*CheckFailure)(nil), "pulumirpc.CheckFailure")
 	proto.RegisterType((*DiffRequest)(nil), "pulumirpc.DiffRequest")
 	proto.RegisterType((*DiffResponse)(nil), "pulumirpc.DiffResponse")
+	proto.RegisterMapType((map[string]string)(nil), "pulumirpc.DiffResponse.ReplaceReasonsEntry")
 	proto.RegisterType((*CreateRequest)(nil), "pulumirpc.CreateRequest")
 	proto.RegisterType((*CreateResponse)(nil), "pulumirpc.CreateResponse")
 	proto.RegisterType((*ReadRequest)(nil), "pulumirpc.ReadRequest")
@@ -980,10 +1047,22 @@ const _ = grpc.SupportPackageIsVersion4
 // Client API for ResourceProvider service
 
 type ResourceProviderClient interface {
+	// Parameterize parameterizes the provider before it is configured, e.g. with the version of the API it should
+	// target or with endpoint information it should use to discover that version.  The parameters are recorded in the
+	// state of the provider resource so that later operations reproduce the same behavior.  Providers that do not
+	// accept parameters should return UNIMPLEMENTED.
+	Parameterize(ctx context.Context, in *_struct.Struct, opts ...grpc.CallOption) (*empty.Empty, error)
 	// Configure configures the resource provider with "globals" that control its behavior.
 	Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	// CheckAuth verifies that the provider's configured credentials are valid and grant the access it needs.  It is
+	// called after Configure and before any resource operations, so that problems can be reported up front.  Providers
+	// that cannot perform such a check should return UNIMPLEMENTED.
+	CheckAuth(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
 	// Invoke dynamically executes a built-in function in the provider.
 	Invoke(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error)
+	// Call dynamically executes a method of an existing resource in the provider.  The resource is passed in
+	// the `__self__` argument; its result participates in dependency tracking like any other output.
+	Call(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error)
 	// Check validates that the given property bag is valid for a resource of the given type and returns the inputs
 	// that should be passed to successive calls to Diff, Create, or Update for this resource. As a rule, the provider
 	// inputs returned by a call to Check should preserve the original representation of the properties as present in
@@ -996,7 +1075,9 @@ type ResourceProviderClient interface {
 	// must be blank.)  If this call fails, the resource must not have been created (i.e., it is "transacational").
 	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
 	// Read the current live state associated with a resource.  Enough state must be include in the inputs to uniquely
-	// identify the resource; this is typically just the resource ID, but may also include some properties.
+	// identify the resource; this is typically just the resource ID, but may also include some properties.  If the
+	// request has a modifiedSince time, and the provider can tell that the resource has not been modified since then,
+	// it may report that the resource is unmodified instead of reading it.
 	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (*ReadResponse, error)
 	// Update updates an existing resource with new values.
 	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
@@ -1006,6 +1087,15 @@ type ResourceProviderClient interface {
 	Cancel(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
 	// GetPluginInfo returns generic information about this plugin, like its version.
 	GetPluginInfo(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*PluginInfo, error)
+	// GetSchema returns a JSON description of the resource and function types this provider implements, along with
+	// the configuration it accepts.  Providers that cannot describe themselves should return UNIMPLEMENTED.
+	GetSchema(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*_struct.Struct, error)
+	// Discover enumerates the existing resources that the configured provider can see, so that they may be imported.
+	// The request may restrict them with `types`, a list of resource types; `tags`, a map of tags that resources must
+	// carry; `resourceGroup`; and `vpc`.  The response's `resources` list holds an object for each resource with its
+	// `type`, `id`, a suggested `name`, and its `properties`, as they would be written in a program.  Providers that
+	// cannot enumerate resources should return UNIMPLEMENTED.
+	Discover(ctx context.Context, in *_struct.Struct, opts ...grpc.CallOption) (*_struct.Struct, error)
 }
 
 type resourceProviderClient struct {
@@ -1016,6 +1106,15 @@ func NewResourceProviderClient(cc *grpc.ClientConn) ResourceProviderClient {
 	return &resourceProviderClient{cc}
 }
 
+func (c *resourceProviderClient) Parameterize(ctx context.Context, in *_struct.Struct, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/Parameterize", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *resourceProviderClient) Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
 	out := new(empty.Empty)
 	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/Configure", in, out, c.cc, opts...)
@@ -1025,6 +1124,15 @@ func (c *resourceProviderClient) Configure(ctx context.Context, in *ConfigureReq
 	return out, nil
 }
 
+func (c *resourceProviderClient) CheckAuth(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/CheckAuth", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *resourceProviderClient) Invoke(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error) {
 	out := new(InvokeResponse)
 	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/Invoke", in, out, c.cc, opts...)
@@ -1034,6 +1142,15 @@ func (c *resourceProviderClient) Invoke(ctx context.Context, in *InvokeRequest,
 	return out, nil
 }
 
+func (c *resourceProviderClient) Call(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error) {
+	out := new(InvokeResponse)
+	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/Call", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *resourceProviderClient) Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error) {
 	out := new(CheckResponse)
 	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/Check", in, out, c.cc, opts...)
@@ -1106,13 +1223,43 @@ func (c *resourceProviderClient) GetPluginInfo(ctx context.Context, in *empty.Em
 	return out, nil
 }
 
+func (c *resourceProviderClient) GetSchema(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*_struct.Struct, error) {
+	out := new(_struct.Struct)
+	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/GetSchema", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resourceProviderClient) Discover(ctx context.Context, in *_struct.Struct, opts ...grpc.CallOption) (*_struct.Struct, error) {
+	out := new(_struct.Struct)
+	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/Discover", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for ResourceProvider service
 
 type ResourceProviderServer interface {
+	// Parameterize parameterizes the provider before it is configured, e.g. with the version of the API it should
+	// target or with endpoint information it should use to discover that version.  The parameters are recorded in the
+	// state of the provider resource so that later operations reproduce the same behavior.  Providers that do not
+	// accept parameters should return UNIMPLEMENTED.
+	Parameterize(context.Context, *_struct.Struct) (*empty.Empty, error)
 	// Configure configures the resource provider with "globals" that control its behavior.
 	Configure(context.Context, *ConfigureRequest) (*empty.Empty, error)
+	// CheckAuth verifies that the provider's configured credentials are valid and grant the access it needs.  It is
+	// called after Configure and before any resource operations, so that problems can be reported up front.  Providers
+	// that cannot perform such a check should return UNIMPLEMENTED.
+	CheckAuth(context.Context, *empty.Empty) (*empty.Empty, error)
 	// Invoke dynamically executes a built-in function in the provider.
 	Invoke(context.Context, *InvokeRequest) (*InvokeResponse, error)
+	// Call dynamically executes a method of an existing resource in the provider.  The resource is passed in
+	// the `__self__` argument; its result participates in dependency tracking like any other output.
+	Call(context.Context, *InvokeRequest) (*InvokeResponse, error)
 	// Check validates that the given property bag is valid for a resource of the given type and returns the inputs
 	// that should be passed to successive calls to Diff, Create, or Update for this resource. As a rule, the provider
 	// inputs returned by a call to Check should preserve the original representation of the properties as present in
@@ -1125,7 +1272,9 @@ type ResourceProviderServer interface {
 	// must be blank.)  If this call fails, the resource must not have been created (i.e., it is "transacational").
 	Create(context.Context, *CreateRequest) (*CreateResponse, error)
 	// Read the current live state associated with a resource.  Enough state must be include in the inputs to uniquely
-	// identify the resource; this is typically just the resource ID, but may also include some properties.
+	// identify the resource; this is typically just the resource ID, but may also include some properties.  If the
+	// request has a modifiedSince time, and the provider can tell that the resource has not been modified since then,
+	// it may report that the resource is unmodified instead of reading it.
 	Read(context.Context, *ReadRequest) (*ReadResponse, error)
 	// Update updates an existing resource with new values.
 	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
@@ -1135,12 +1284,39 @@ type ResourceProviderServer interface {
 	Cancel(context.Context, *empty.Empty) (*empty.Empty, error)
 	// GetPluginInfo returns generic information about this plugin, like its version.
 	GetPluginInfo(context.Context, *empty.Empty) (*PluginInfo, error)
+	// GetSchema returns a JSON description of the resource and function types this provider implements, along with
+	// the configuration it accepts.  Providers that cannot describe themselves should return UNIMPLEMENTED.
+	GetSchema(context.Context, *empty.Empty) (*_struct.Struct, error)
+	// Discover enumerates the existing resources that the configured provider can see, so that they may be imported.
+	// The request may restrict them with `types`, a list of resource types; `tags`, a map of tags that resources must
+	// carry; `resourceGroup`; and `vpc`.  The response's `resources` list holds an object for each resource with its
+	// `type`, `id`, a suggested `name`, and its `properties`, as they would be written in a program.  Providers that
+	// cannot enumerate resources should return UNIMPLEMENTED.
+	Discover(context.Context, *_struct.Struct) (*_struct.Struct, error)
 }
 
 func RegisterResourceProviderServer(s *grpc.Server, srv ResourceProviderServer) {
 	s.RegisterService(&_ResourceProvider_serviceDesc, srv)
 }
 
+func _ResourceProvider_Parameterize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(_struct.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceProviderServer).Parameterize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pulumirpc.ResourceProvider/Parameterize",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceProviderServer).Parameterize(ctx, req.(*_struct.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ResourceProvider_Configure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ConfigureRequest)
 	if err := dec(in); err != nil {
@@ -1159,6 +1335,24 @@ func _ResourceProvider_Configure_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ResourceProvider_CheckAuth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceProviderServer).CheckAuth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pulumirpc.ResourceProvider/CheckAuth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceProviderServer).CheckAuth(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ResourceProvider_Invoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(InvokeRequest)
 	if err := dec(in); err != nil {
@@ -1177,6 +1371,24 @@ func _ResourceProvider_Invoke_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ResourceProvider_Call_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvokeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceProviderServer).Call(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pulumirpc.ResourceProvider/Call",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceProviderServer).Call(ctx, req.(*InvokeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ResourceProvider_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CheckRequest)
 	if err := dec(in); err != nil {
@@ -1321,18 +1533,66 @@ func _ResourceProvider_GetPluginInfo_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ResourceProvider_GetSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceProviderServer).GetSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pulumirpc.ResourceProvider/GetSchema",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceProviderServer).GetSchema(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResourceProvider_Discover_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(_struct.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceProviderServer).Discover(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pulumirpc.ResourceProvider/Discover",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceProviderServer).Discover(ctx, req.(*_struct.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ResourceProvider_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pulumirpc.ResourceProvider",
 	HandlerType: (*ResourceProviderServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Parameterize",
+			Handler:    _ResourceProvider_Parameterize_Handler,
+		},
 		{
 			MethodName: "Configure",
 			Handler:    _ResourceProvider_Configure_Handler,
 		},
+		{
+			MethodName: "CheckAuth",
+			Handler:    _ResourceProvider_CheckAuth_Handler,
+		},
 		{
 			MethodName: "Invoke",
 			Handler:    _ResourceProvider_Invoke_Handler,
 		},
+		{
+			MethodName: "Call",
+			Handler:    _ResourceProvider_Call_Handler,
+		},
 		{
 			MethodName: "Check",
 			Handler:    _ResourceProvider_Check_Handler,
@@ -1365,69 +1625,91 @@ var _ResourceProvider_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetPluginInfo",
 			Handler:    _ResourceProvider_GetPluginInfo_Handler,
 		},
+		{
+			MethodName: "GetSchema",
+			Handler:    _ResourceProvider_GetSchema_Handler,
+		},
+		{
+			MethodName: "Discover",
+			Handler:    _ResourceProvider_Discover_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "provider.proto",
 }
 
-func init() { proto.RegisterFile("provider.proto", fileDescriptor_provider_5951afc12b1894bc) }
-
-var fileDescriptor_provider_5951afc12b1894bc = []byte{
-	// 889 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xc4, 0x56, 0xdd, 0x8e, 0xdb, 0x44,
-	0x14, 0x5e, 0x27, 0xd9, 0x74, 0x73, 0xf2, 0xa3, 0x68, 0x80, 0xdd, 0x34, 0xe5, 0xa2, 0x32, 0x37,
-	0x15, 0x48, 0x0e, 0x4a, 0x2f, 0x80, 0xaa, 0x15, 0x28, 0xdd, 0x2c, 0x44, 0x55, 0xb3, 0xc5, 0x55,
-	0x59, 0xc1, 0x0d, 0xf2, 0xda, 0x93, 0xac, 0x89, 0x63, 0x9b, 0xf1, 0x38, 0x28, 0x88, 0x17, 0x58,
-	0xf1, 0x06, 0x3c, 0x06, 0xcf, 0xc6, 0x03, 0x30, 0x9e, 0x1f, 0xc7, 0x93, 0x6c, 0xb2, 0x61, 0xb5,
-	0xa2, 0x77, 0x73, 0x7c, 0x7e, 0xbe, 0xf3, 0x9d, 0x73, 0xe6, 0x8c, 0xa1, 0x15, 0x93, 0x68, 0xe1,
-	0x7b, 0x98, 0x58, 0xec, 0x40, 0x23, 0x54, 0x8b, 0xd3, 0x20, 0x9d, 0xfb, 0x24, 0x76, 0xbb, 0x8d,
-	0x38, 0x48, 0xa7, 0x7e, 0x28, 0x14, 0xdd, 0x47, 0xd3, 0x28, 0x9a, 0x06, 0xb8, 0xc7, 0xa5, 0xcb,
-	0x74, 0xd2, 0xc3, 0xf3, 0x98, 0x2e, 0xa5, 0xf2, 0xe3, 0x75, 0x65, 0x42, 0x49, 0xea, 0x52, 0xa1,
-	0x35, 0xff, 0x32, 0xa0, 0xfd, 0x32, 0x0a, 0x27, 0xfe, 0x34, 0x25, 0xd8, 0xc6, 0xbf, 0xa6, 0x38,
-	0xa1, 0xe8, 0x3b, 0xa8, 0x2d, 0x1c, 0xe2, 0x3b, 0x97, 0x01, 0x4e, 0x3a, 0xc6, 0xe3, 0xf2, 0x93,
-	0x7a, 0xff, 0x53, 0x2b, 0x07, 0xb7, 0xd6, 0xed, 0xad, 0x1f, 0x94, 0xf1, 0x30, 0xa4, 0x64, 0x69,
-	0xaf, 0x9c, 0xbb, 0xcf, 0xa1, 0xa5, 0x2b, 0x51, 0x1b, 0xca, 0x33, 0xbc, 0x64, 0x51, 0x8d, 0x27,
-	0x35, 0x3b, 0x3b, 0xa2, 0x0f, 0xe1, 0x70, 0xe1, 0x04, 0x29, 0xee, 0x94, 0xf8, 0x37, 0x21, 0x3c,
-	0x2b, 0x7d, 0x69, 0x98, 0x7f, 0x1b, 0xf0, 0x30, 0x07, 0x1b, 0x12, 0x12, 0x91, 0xd7, 0x7e, 0x92,
-	0xf8, 0xe1, 0xf4, 0x15, 0x5e, 0x26, 0xe8, 0x7b, 0xa8, 0xcf, 0x57, 0xa2, 0xcc, 0xb3, 0x77, 0x53,
-	0x9e, 0xeb, 0xae, 0xd6, 0xea, 0x6c, 0x17, 0x63, 0x74, 0x07, 0x00, 0x2b, 0x15, 0x42, 0x50, 0x09,
-	0x9d, 0x39, 0x96, 0xb9, 0xf2, 0x33, 0x7a, 0x0c, 0x75, 0x0f, 0x27, 0x2e, 0xf1, 0x63, 0xea, 0x47,
-	0xa1, 0x4c, 0xb9, 0xf8, 0xc9, 0xfc, 0x05, 0x9a, 0xa3, 0x70, 0x11, 0xcd, 0xf2, 0x6a, 0x32, 0xc6,
-	0x34, 0x9a, 0x29, 0xc6, 0xec, 0x88, 0x3e, 0x83, 0x8a, 0x43, 0xa6, 0x09, 0xf7, 0xae, 0xf7, 0x4f,
-	0x2c, 0xd1, 0x21, 0x4b, 0x75, 0xc8, 0x7a, 0xcb, 0x3b, 0x64, 0x73, 0x23, 0xd4, 0x85, 0x23, 0x35,
-	0x07, 0x9d, 0x32, 0x8f, 0x91, 0xcb, 0xe6, 0x02, 0x5a, 0x0a, 0x2b, 0x89, 0xa3, 0x30, 0xc1, 0xa8,
-	0x07, 0x55, 0x82, 0x69, 0x4a, 0x42, 0x8e, 0xb7, 0x23, 0xb8, 0x34, 0x43, 0x4f, 0xe1, 0x68, 0xe2,
-	0xf8, 0x01, 0xab, 0x52, 0x96, 0x4f, 0x99, 0xbb, 0x14, 0x4a, 0x78, 0x85, 0xdd, 0xd9, 0x99, 0xd0,
-	0xdb, 0xb9, 0xa1, 0xf9, 0x3b, 0x34, 0xb8, 0xa6, 0x40, 0x51, 0x41, 0x32, 0x8a, 0x59, 0x58, 0x46,
-	0x31, 0x0a, 0xbc, 0xdb, 0x29, 0x66, 0x46, 0x99, 0x71, 0x88, 0x7f, 0x4b, 0x38, 0xbd, 0x5d, 0xc6,
-	0x99, 0x91, 0x99, 0x42, 0x53, 0x62, 0xaf, 0x28, 0xfb, 0x61, 0x9c, 0xd2, 0xe4, 0x56, 0xca, 0xc2,
-	0xec, 0x6e, 0x94, 0x07, 0x92, 0xb2, 0xd4, 0xc8, 0xb6, 0xc4, 0x98, 0x50, 0x35, 0xcc, 0xb9, 0x8c,
-	0x8e, 0xb3, 0x26, 0x38, 0x49, 0x3e, 0x1f, 0x52, 0x32, 0xaf, 0x0d, 0xa8, 0x9f, 0xfa, 0x93, 0x89,
-	0x2a, 0x5b, 0x0b, 0x4a, 0xbe, 0x27, 0xbd, 0xd9, 0x49, 0x95, 0xb1, 0xb4, 0x59, 0xc6, 0xf2, 0x7f,
-	0x29, 0x63, 0x65, 0x9f, 0x32, 0xfe, 0x63, 0x40, 0x43, 0xe4, 0x22, 0xcb, 0xc8, 0x08, 0x11, 0x1c,
-	0x07, 0x8e, 0x2b, 0xef, 0x3c, 0x23, 0xa4, 0x64, 0xd4, 0x81, 0x07, 0x09, 0x15, 0xeb, 0xa0, 0xc4,
-	0x55, 0x4a, 0x44, 0x9f, 0xc3, 0x07, 0x1e, 0x0e, 0x30, 0xc5, 0x03, 0x3c, 0x89, 0xb2, 0x8d, 0xc0,
-	0x3d, 0x78, 0xbe, 0x47, 0xf6, 0x4d, 0x2a, 0xf4, 0x02, 0x1e, 0xb8, 0x57, 0x4e, 0x38, 0xc5, 0x22,
-	0xd1, 0x56, 0xff, 0x93, 0x42, 0xf1, 0x8b, 0x19, 0x71, 0xe1, 0xa5, 0x30, 0xb5, 0x95, 0x8f, 0xf9,
-	0x42, 0x94, 0x50, 0x7e, 0x67, 0x25, 0x6b, 0x9c, 0x8e, 0xce, 0xce, 0x7e, 0x7e, 0x37, 0x7e, 0x35,
-	0x3e, 0xbf, 0x18, 0xb7, 0x0f, 0x50, 0x13, 0x6a, 0xfc, 0xcb, 0xf8, 0x7c, 0x3c, 0x6c, 0x1b, 0xb9,
-	0xf8, 0xf6, 0xfc, 0xf5, 0xb0, 0x5d, 0x32, 0x7f, 0x62, 0xd3, 0xc3, 0xba, 0x41, 0xf1, 0xf6, 0xd1,
-	0xfd, 0x02, 0x40, 0x76, 0xd2, 0xc7, 0xb7, 0x0e, 0x70, 0xc1, 0xd4, 0xfc, 0x11, 0x5a, 0x2a, 0xb6,
-	0xac, 0xe9, 0x7a, 0x83, 0xef, 0x1c, 0xfa, 0x0a, 0xea, 0x36, 0x76, 0xbc, 0xfd, 0x07, 0x47, 0x47,
-	0x2a, 0xef, 0x8f, 0x74, 0x01, 0x0d, 0x81, 0x74, 0xdf, 0x14, 0xfe, 0x34, 0xa0, 0xf9, 0x2e, 0xf6,
-	0x0a, 0xa5, 0x7f, 0x9f, 0xe3, 0x3f, 0x82, 0x96, 0x4a, 0x46, 0x12, 0xd5, 0x89, 0x19, 0xfb, 0x13,
-	0x63, 0x0b, 0xff, 0x94, 0xcf, 0xf9, 0xff, 0xd0, 0x9d, 0x3f, 0xe0, 0x84, 0x3f, 0x66, 0x2c, 0xeb,
-	0x28, 0x25, 0x2e, 0x1e, 0x85, 0x3e, 0xcd, 0x36, 0x12, 0xf6, 0xee, 0xad, 0x51, 0xd9, 0x65, 0x17,
-	0xfb, 0x2a, 0xcb, 0x8c, 0x5f, 0x76, 0x29, 0xf6, 0xaf, 0x0f, 0xa1, 0xad, 0x90, 0xdf, 0xc8, 0x37,
-	0x08, 0x0d, 0xa0, 0x96, 0x3f, 0xb4, 0xe8, 0xd1, 0x8e, 0xdf, 0x84, 0xee, 0xf1, 0x06, 0xfa, 0x30,
-	0xfb, 0x4f, 0x31, 0x0f, 0xd0, 0xd7, 0x50, 0x15, 0xef, 0x18, 0xea, 0x14, 0x02, 0x68, 0xcf, 0x68,
-	0xf7, 0xe1, 0x0d, 0x1a, 0xd1, 0x3a, 0x16, 0xe0, 0x39, 0x1c, 0xf2, 0xed, 0x8c, 0x36, 0x36, 0xb9,
-	0x72, 0xef, 0x6c, 0x2a, 0x72, 0xef, 0xaf, 0xa0, 0x92, 0xed, 0x14, 0x74, 0xbc, 0xb1, 0x89, 0x84,
-	0xef, 0xc9, 0x96, 0x0d, 0x25, 0x32, 0x17, 0x77, 0x5e, 0xcb, 0x5c, 0x5b, 0x31, 0x5a, 0xe6, 0xfa,
-	0x82, 0x10, 0xd8, 0xd9, 0x7d, 0xd3, 0xb0, 0x0b, 0x57, 0x5d, 0xc3, 0x2e, 0x5e, 0x4c, 0x81, 0x2d,
-	0x66, 0x58, 0xc3, 0xd6, 0xee, 0x98, 0x86, 0xad, 0x0f, 0x3c, 0xaf, 0x5a, 0x55, 0x4c, 0xae, 0x16,
-	0x40, 0x1b, 0xe6, 0x1d, 0x4d, 0x7b, 0xc6, 0xa8, 0x3b, 0xa1, 0x8b, 0x03, 0xb4, 0xc5, 0x66, 0x87,
-	0xef, 0x37, 0xd0, 0xfc, 0x16, 0xd3, 0x37, 0xfc, 0x27, 0x76, 0x14, 0x4e, 0xa2, 0xad, 0x21, 0x3e,
-	0x2a, 0x24, 0xb6, 0x32, 0x37, 0x0f, 0x2e, 0xab, 0xdc, 0xf0, 0xe9, 0xbf, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0x2f, 0x82, 0x27, 0xcd, 0x25, 0x0b, 0x00, 0x00,
+func init() { proto.RegisterFile("provider.proto", fileDescriptor_provider_59fc5cb0a5e9002f) }
+
+var fileDescriptor_provider_59fc5cb0a5e9002f = []byte{
+	// 1108 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x57, 0xeb, 0x6e, 0xe3, 0x44,
+	0x14, 0xae, 0x93, 0x34, 0x4d, 0x4e, 0x2e, 0x8a, 0x66, 0xa1, 0x75, 0xbd, 0x08, 0x45, 0x86, 0x1f,
+	0x15, 0x2b, 0xa5, 0xa8, 0x2b, 0x71, 0x59, 0x6d, 0x81, 0x5e, 0x97, 0x6a, 0xd9, 0xb6, 0x38, 0xda,
+	0x45, 0xf0, 0x07, 0xb9, 0xf6, 0x89, 0x3b, 0x5b, 0xdf, 0x18, 0x8f, 0xb3, 0x14, 0xf1, 0x02, 0x08,
+	0xc4, 0x03, 0xf0, 0x18, 0x88, 0xa7, 0xe0, 0xa9, 0x90, 0x67, 0x6c, 0xc7, 0x6e, 0xe2, 0xb6, 0x5b,
+	0x2d, 0xda, 0x7f, 0x3e, 0xe7, 0x3b, 0xb7, 0x99, 0xf3, 0x9d, 0x93, 0x09, 0xf4, 0x43, 0x16, 0x4c,
+	0xa9, 0x8d, 0x6c, 0x14, 0xb2, 0x80, 0x07, 0xa4, 0x1d, 0xc6, 0x6e, 0xec, 0x51, 0x16, 0x5a, 0x5a,
+	0x37, 0x74, 0x63, 0x87, 0xfa, 0x12, 0xd0, 0xee, 0x3b, 0x41, 0xe0, 0xb8, 0xb8, 0x29, 0xa4, 0xb3,
+	0x78, 0xb2, 0x89, 0x5e, 0xc8, 0x2f, 0x53, 0xf0, 0xbd, 0xab, 0x60, 0xc4, 0x59, 0x6c, 0x71, 0x89,
+	0xea, 0x7f, 0x29, 0x30, 0xd8, 0x0b, 0xfc, 0x09, 0x75, 0x62, 0x86, 0x06, 0xfe, 0x14, 0x63, 0xc4,
+	0xc9, 0xd7, 0xd0, 0x9e, 0x9a, 0x8c, 0x9a, 0x67, 0x2e, 0x46, 0xaa, 0x32, 0xac, 0x6f, 0x74, 0xb6,
+	0x3e, 0x1a, 0xe5, 0xc9, 0x47, 0x57, 0xed, 0x47, 0x2f, 0x32, 0xe3, 0x03, 0x9f, 0xb3, 0x4b, 0x63,
+	0xe6, 0xac, 0x3d, 0x86, 0x7e, 0x19, 0x24, 0x03, 0xa8, 0x5f, 0xe0, 0xa5, 0xaa, 0x0c, 0x95, 0x8d,
+	0xb6, 0x91, 0x7c, 0x92, 0x77, 0x60, 0x79, 0x6a, 0xba, 0x31, 0xaa, 0x35, 0xa1, 0x93, 0xc2, 0xa3,
+	0xda, 0x67, 0x8a, 0xfe, 0xb7, 0x02, 0xeb, 0x79, 0xb2, 0x03, 0xc6, 0x02, 0xf6, 0x8c, 0x46, 0x11,
+	0xf5, 0x9d, 0xa7, 0x78, 0x19, 0x91, 0x6f, 0xa1, 0xe3, 0xcd, 0xc4, 0xb4, 0xce, 0xcd, 0x45, 0x75,
+	0x5e, 0x75, 0x1d, 0xcd, 0xbe, 0x8d, 0x62, 0x0c, 0x6d, 0x17, 0x60, 0x06, 0x11, 0x02, 0x0d, 0xdf,
+	0xf4, 0x30, 0xad, 0x55, 0x7c, 0x93, 0x21, 0x74, 0x6c, 0x8c, 0x2c, 0x46, 0x43, 0x4e, 0x03, 0x3f,
+	0x2d, 0xb9, 0xa8, 0xd2, 0x5f, 0x42, 0xef, 0xc8, 0x9f, 0x06, 0x17, 0xf9, 0x6d, 0x0e, 0xa0, 0xce,
+	0x83, 0x8b, 0xec, 0xc4, 0x3c, 0xb8, 0x20, 0x0f, 0xa0, 0x61, 0x32, 0x27, 0x12, 0xde, 0x9d, 0xad,
+	0xb5, 0x91, 0xec, 0xd0, 0x28, 0xeb, 0xd0, 0x68, 0x2c, 0x3a, 0x64, 0x08, 0x23, 0xa2, 0x41, 0x2b,
+	0xe3, 0x81, 0x5a, 0x17, 0x31, 0x72, 0x59, 0x9f, 0x42, 0x3f, 0xcb, 0x15, 0x85, 0x81, 0x1f, 0x21,
+	0xd9, 0x84, 0x26, 0x43, 0x1e, 0x33, 0x5f, 0x55, 0xae, 0x0f, 0x9e, 0x9a, 0x91, 0x87, 0xd0, 0x9a,
+	0x98, 0xd4, 0x8d, 0x19, 0x26, 0xf5, 0xd4, 0x85, 0x4b, 0xe1, 0x0a, 0xcf, 0xd1, 0xba, 0x38, 0x94,
+	0xb8, 0x91, 0x1b, 0xea, 0xff, 0xd4, 0xa0, 0x2b, 0xa0, 0xc2, 0x19, 0xb3, 0x9c, 0x6d, 0x23, 0xf9,
+	0x4c, 0xce, 0x18, 0xb8, 0xf6, 0xcd, 0x67, 0x4c, 0x8c, 0x12, 0x63, 0x1f, 0x5f, 0x45, 0x6a, 0xfd,
+	0x06, 0xe3, 0xc4, 0x88, 0xbc, 0x0f, 0xc0, 0x4c, 0xdf, 0x0e, 0xbc, 0x31, 0xa2, 0xad, 0x36, 0x86,
+	0xca, 0x46, 0xd7, 0x28, 0x68, 0xc8, 0x27, 0xb0, 0x6a, 0xc6, 0x3c, 0xf0, 0x4d, 0x8f, 0xfa, 0xce,
+	0x38, 0x9e, 0x4c, 0xe8, 0xcf, 0xdf, 0xa0, 0xef, 0xf0, 0x73, 0x75, 0x79, 0xa8, 0x6c, 0x2c, 0x1b,
+	0x15, 0x28, 0xf9, 0x18, 0xee, 0xcd, 0x90, 0x7d, 0x74, 0xa9, 0x47, 0x39, 0x32, 0xb5, 0x29, 0xce,
+	0xb4, 0x08, 0x22, 0x23, 0x20, 0x33, 0xf5, 0x0b, 0x64, 0x67, 0x26, 0xa7, 0x9e, 0xba, 0x32, 0x54,
+	0x36, 0x5a, 0xc6, 0x02, 0x44, 0x8f, 0xa1, 0x97, 0xde, 0xda, 0xac, 0x5b, 0xd4, 0x0f, 0x63, 0x1e,
+	0xdd, 0xd8, 0x2d, 0x69, 0x76, 0xb7, 0x6e, 0x9d, 0x41, 0xb7, 0x88, 0xa4, 0x8c, 0x0a, 0x91, 0xf1,
+	0x6c, 0x0e, 0x73, 0x99, 0xac, 0x26, 0xfc, 0x31, 0xa3, 0x9c, 0xda, 0xa9, 0x94, 0x5c, 0x7a, 0x14,
+	0x3b, 0x0e, 0x46, 0x82, 0xf6, 0x92, 0x87, 0x05, 0x8d, 0xfe, 0x9b, 0x02, 0x9d, 0x7d, 0x3a, 0x99,
+	0x64, 0x84, 0xe8, 0x43, 0x8d, 0xda, 0x69, 0xf4, 0x1a, 0xb5, 0x33, 0x82, 0xd4, 0xe6, 0x09, 0x52,
+	0x7f, 0x1d, 0x82, 0x34, 0x6e, 0x41, 0x10, 0xfd, 0x8f, 0x3a, 0x74, 0x65, 0x2d, 0xe9, 0x35, 0x6b,
+	0xd0, 0x62, 0x18, 0xba, 0xa6, 0x95, 0xae, 0xb3, 0xb6, 0x91, 0xcb, 0x44, 0x85, 0x95, 0x88, 0xcb,
+	0x4d, 0x57, 0x13, 0x50, 0x26, 0x26, 0x7c, 0xb0, 0xd1, 0x45, 0x8e, 0xbb, 0x38, 0x09, 0x92, 0x65,
+	0x27, 0x3c, 0x44, 0xbd, 0x2d, 0x63, 0x11, 0x44, 0xb6, 0x61, 0xc5, 0x3a, 0x37, 0x7d, 0x07, 0x65,
+	0xa1, 0xfd, 0xad, 0x0f, 0x0a, 0xcd, 0x29, 0x56, 0x24, 0x84, 0x3d, 0x69, 0x6a, 0x64, 0x3e, 0x64,
+	0x0c, 0xfd, 0xb4, 0x2c, 0x43, 0x5c, 0x7a, 0xa4, 0x2e, 0x8b, 0x16, 0x3f, 0xa8, 0x8a, 0x62, 0x94,
+	0xac, 0xe5, 0xf2, 0xbd, 0x12, 0x42, 0xdb, 0x81, 0x7b, 0x0b, 0xcc, 0x5e, 0x6b, 0x0d, 0x6f, 0x43,
+	0xa7, 0x50, 0x2f, 0x19, 0x40, 0x77, 0xff, 0xe8, 0xf0, 0xf0, 0xc7, 0xe7, 0xc7, 0x4f, 0x8f, 0x4f,
+	0xbe, 0x3b, 0x1e, 0x2c, 0x91, 0x1e, 0xb4, 0x85, 0xe6, 0xf8, 0xe4, 0xf8, 0x60, 0xa0, 0xe4, 0xe2,
+	0xf8, 0xe4, 0xd9, 0xc1, 0xa0, 0xa6, 0xff, 0x00, 0xbd, 0x3d, 0x86, 0x26, 0xc7, 0xea, 0x65, 0xf1,
+	0x29, 0x40, 0xca, 0x40, 0x8a, 0x37, 0xae, 0x8c, 0x82, 0xa9, 0xfe, 0x3d, 0xf4, 0xb3, 0xd8, 0x69,
+	0xaf, 0xaf, 0x12, 0xef, 0xce, 0xa1, 0xff, 0x54, 0xa0, 0x63, 0xa0, 0x69, 0xdf, 0x9e, 0xd1, 0xe5,
+	0x54, 0xf5, 0x5b, 0xa7, 0x22, 0x1f, 0x42, 0xcf, 0x0b, 0x6c, 0x3a, 0xa1, 0x68, 0x8f, 0xa9, 0x6f,
+	0xa1, 0x60, 0x4f, 0xdd, 0x28, 0x2b, 0xf5, 0x57, 0xd0, 0x95, 0xf5, 0xbc, 0xe1, 0x93, 0x26, 0xb3,
+	0x1d, 0xfb, 0x59, 0xae, 0x94, 0xdf, 0x05, 0x8d, 0xfe, 0xbb, 0x02, 0xbd, 0xe7, 0xa1, 0x5d, 0xe8,
+	0xe0, 0xdb, 0x9c, 0xee, 0x23, 0xe8, 0x67, 0xc5, 0xa4, 0x17, 0x51, 0x3e, 0xb8, 0x72, 0xfb, 0x16,
+	0xbf, 0x84, 0xde, 0xbe, 0x18, 0xe3, 0xff, 0xbf, 0xc7, 0xfa, 0xaf, 0xb0, 0x26, 0x9e, 0x21, 0x06,
+	0x46, 0x41, 0xcc, 0x2c, 0x3c, 0xf2, 0x29, 0x4f, 0x16, 0x32, 0xda, 0x6f, 0xae, 0x91, 0x2a, 0xac,
+	0xb0, 0x74, 0x73, 0xd4, 0xe5, 0x2e, 0x4b, 0xc5, 0xad, 0x7f, 0x57, 0x60, 0x90, 0x65, 0x3e, 0x4d,
+	0x5f, 0x0f, 0x64, 0x07, 0xba, 0xa7, 0x26, 0x33, 0x3d, 0xe4, 0xc8, 0xe8, 0x2f, 0x48, 0xaa, 0x72,
+	0x68, 0xab, 0x73, 0xc0, 0x41, 0xf2, 0xc0, 0xd4, 0x97, 0xc8, 0x2e, 0xb4, 0xf3, 0x57, 0x16, 0xb9,
+	0x7f, 0xcd, 0x1b, 0xf1, 0x9a, 0x18, 0xdb, 0xd0, 0x16, 0x3f, 0x4f, 0x3b, 0x31, 0x3f, 0x27, 0x15,
+	0x66, 0xd7, 0xb8, 0x7f, 0x09, 0x4d, 0xf9, 0x06, 0x22, 0x6a, 0x21, 0x7f, 0xe9, 0x09, 0xa6, 0xad,
+	0x2f, 0x40, 0x24, 0x79, 0x44, 0xfe, 0xc6, 0x9e, 0xe9, 0xba, 0x77, 0x75, 0x7f, 0x0c, 0xcb, 0xa2,
+	0x7c, 0x32, 0xf7, 0x4b, 0x9c, 0xb9, 0xab, 0xf3, 0x40, 0xee, 0xfd, 0x39, 0x34, 0x92, 0xdd, 0x4a,
+	0x56, 0xe7, 0x76, 0xbc, 0xf4, 0x5d, 0xab, 0xd8, 0xfd, 0xf2, 0xe0, 0x72, 0xf7, 0x95, 0x2a, 0x2f,
+	0xad, 0x5a, 0x6d, 0x7d, 0x01, 0x52, 0xcc, 0x9d, 0x2c, 0x94, 0x52, 0xee, 0xc2, 0xc6, 0xd3, 0xd6,
+	0xe6, 0xf4, 0xc5, 0xdc, 0x72, 0x08, 0x4b, 0xb9, 0x4b, 0x4b, 0x42, 0x5b, 0x5f, 0x80, 0x14, 0x6e,
+	0xad, 0x29, 0x47, 0xaf, 0x14, 0xa0, 0x34, 0x8d, 0xd7, 0xf4, 0xfc, 0x11, 0x34, 0xf7, 0x4c, 0xdf,
+	0x42, 0xf7, 0x0e, 0x7c, 0xf9, 0x0a, 0x7a, 0x4f, 0x90, 0x9f, 0x8a, 0xff, 0x4f, 0x47, 0xfe, 0x24,
+	0xa8, 0x0c, 0xf1, 0x6e, 0xa1, 0xb0, 0x99, 0xb9, 0xbe, 0x44, 0xbe, 0x80, 0xf6, 0x13, 0xe4, 0x63,
+	0xeb, 0x1c, 0x3d, 0xb3, 0xd2, 0xbb, 0x6a, 0x98, 0x84, 0x7f, 0x6b, 0x9f, 0x46, 0x56, 0x30, 0x45,
+	0x56, 0x3d, 0x73, 0xd5, 0xfe, 0x67, 0x4d, 0xa1, 0x7a, 0xf8, 0xdf, 0x00, 0xdd, 0x0f, 0xda, 0x29,
+	0x20, 0x0e, 0x00, 0x00,
 }
@@ -43,7 +43,7 @@ func (m *ReadResourceRequest) Reset()         { *m = ReadResourceRequest{} }
 func (m *ReadResourceRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadResourceRequest) ProtoMessage()    {}
 func (*ReadResourceRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_5aa1dff965971124, []int{0}
+	return fileDescriptor_resource_dc027263233b0be2, []int{0}
 }
 func (m *ReadResourceRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadResourceRequest.Unmarshal(m, b)
@@ -125,7 +125,7 @@ func (m *ReadResourceResponse) Reset()         { *m = ReadResourceResponse{} }
 func (m *ReadResourceResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadResourceResponse) ProtoMessage()    {}
 func (*ReadResourceResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_5aa1dff965971124, []int{1}
+	return fileDescriptor_resource_dc027263233b0be2, []int{1}
 }
 func (m *ReadResourceResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadResourceResponse.Unmarshal(m, b)
@@ -161,24 +161,29 @@ func (m *ReadResourceResponse) GetProperties() *_struct.Struct {
 
 // RegisterResourceRequest contains information about a resource object that was newly allocated.
 type RegisterResourceRequest struct {
-	Type                 string          `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
-	Name                 string          `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
-	Parent               string          `protobuf:"bytes,3,opt,name=parent" json:"parent,omitempty"`
-	Custom               bool            `protobuf:"varint,4,opt,name=custom" json:"custom,omitempty"`
-	Object               *_struct.Struct `protobuf:"bytes,5,opt,name=object" json:"object,omitempty"`
-	Protect              bool            `protobuf:"varint,6,opt,name=protect" json:"protect,omitempty"`
-	Dependencies         []string        `protobuf:"bytes,7,rep,name=dependencies" json:"dependencies,omitempty"`
-	Provider             string          `protobuf:"bytes,8,opt,name=provider" json:"provider,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+	Type                    string          `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	Name                    string          `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Parent                  string          `protobuf:"bytes,3,opt,name=parent" json:"parent,omitempty"`
+	Custom                  bool            `protobuf:"varint,4,opt,name=custom" json:"custom,omitempty"`
+	Object                  *_struct.Struct `protobuf:"bytes,5,opt,name=object" json:"object,omitempty"`
+	Protect                 bool            `protobuf:"varint,6,opt,name=protect" json:"protect,omitempty"`
+	Dependencies            []string        `protobuf:"bytes,7,rep,name=dependencies" json:"dependencies,omitempty"`
+	Provider                string          `protobuf:"bytes,8,opt,name=provider" json:"provider,omitempty"`
+	SourcePosition          string          `protobuf:"bytes,9,opt,name=sourcePosition" json:"sourcePosition,omitempty"`
+	DependsOn               []string        `protobuf:"bytes,10,rep,name=dependsOn" json:"dependsOn,omitempty"`
+	AdditionalSecretOutputs []string        `protobuf:"bytes,11,rep,name=additionalSecretOutputs" json:"additionalSecretOutputs,omitempty"`
+	ReplaceOnChanges        []string        `protobuf:"bytes,12,rep,name=replaceOnChanges" json:"replaceOnChanges,omitempty"`
+	DeleteBeforeReplace     bool            `protobuf:"varint,13,opt,name=deleteBeforeReplace" json:"deleteBeforeReplace,omitempty"`
+	XXX_NoUnkeyedLiteral    struct{}        `json:"-"`
+	XXX_unrecognized        []byte          `json:"-"`
+	XXX_sizecache           int32           `json:"-"`
 }
 
 func (m *RegisterResourceRequest) Reset()         { *m = RegisterResourceRequest{} }
 func (m *RegisterResourceRequest) String() string { return proto.CompactTextString(m) }
 func (*RegisterResourceRequest) ProtoMessage()    {}
 func (*RegisterResourceRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_5aa1dff965971124, []int{2}
+	return fileDescriptor_resource_dc027263233b0be2, []int{2}
 }
 func (m *RegisterResourceRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceRequest.Unmarshal(m, b)
@@ -254,6 +259,41 @@ func (m *RegisterResourceRequest) GetProvider() string {
 	return ""
 }
 
+func (m *RegisterResourceRequest) GetSourcePosition() string {
+	if m != nil {
+		return m.SourcePosition
+	}
+	return ""
+}
+
+func (m *RegisterResourceRequest) GetDependsOn() []string {
+	if m != nil {
+		return m.DependsOn
+	}
+	return nil
+}
+
+func (m *RegisterResourceRequest) GetAdditionalSecretOutputs() []string {
+	if m != nil {
+		return m.AdditionalSecretOutputs
+	}
+	return nil
+}
+
+func (m *RegisterResourceRequest) GetReplaceOnChanges() []string {
+	if m != nil {
+		return m.ReplaceOnChanges
+	}
+	return nil
+}
+
+func (m *RegisterResourceRequest) GetDeleteBeforeReplace() bool {
+	if m != nil {
+		return m.DeleteBeforeReplace
+	}
+	return false
+}
+
 // RegisterResourceResponse is returned by the engine after a resource has finished being initialized.  It includes the
 // auto-assigned URN, the provider-assigned ID, and any other properties initialized by the engine.
 type RegisterResourceResponse struct {
@@ -271,7 +311,7 @@ func (m *RegisterResourceResponse) Reset()         { *m = RegisterResourceRespon
 func (m *RegisterResourceResponse) String() string { return proto.CompactTextString(m) }
 func (*RegisterResourceResponse) ProtoMessage()    {}
 func (*RegisterResourceResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_5aa1dff965971124, []int{3}
+	return fileDescriptor_resource_dc027263233b0be2, []int{3}
 }
 func (m *RegisterResourceResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceResponse.Unmarshal(m, b)
@@ -339,7 +379,7 @@ func (m *RegisterResourceOutputsRequest) Reset()         { *m = RegisterResource
 func (m *RegisterResourceOutputsRequest) String() string { return proto.CompactTextString(m) }
 func (*RegisterResourceOutputsRequest) ProtoMessage()    {}
 func (*RegisterResourceOutputsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_5aa1dff965971124, []int{4}
+	return fileDescriptor_resource_dc027263233b0be2, []int{4}
 }
 func (m *RegisterResourceOutputsRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceOutputsRequest.Unmarshal(m, b)
@@ -393,6 +433,7 @@ const _ = grpc.SupportPackageIsVersion4
 
 type ResourceMonitorClient interface {
 	Invoke(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error)
+	Call(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error)
 	ReadResource(ctx context.Context, in *ReadResourceRequest, opts ...grpc.CallOption) (*ReadResourceResponse, error)
 	RegisterResource(ctx context.Context, in *RegisterResourceRequest, opts ...grpc.CallOption) (*RegisterResourceResponse, error)
 	RegisterResourceOutputs(ctx context.Context, in *RegisterResourceOutputsRequest, opts ...grpc.CallOption) (*empty.Empty, error)
@@ -415,6 +456,15 @@ func (c *resourceMonitorClient) Invoke(ctx context.Context, in *InvokeRequest, o
 	return out, nil
 }
 
+func (c *resourceMonitorClient) Call(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error) {
+	out := new(InvokeResponse)
+	err := grpc.Invoke(ctx, "/pulumirpc.ResourceMonitor/Call", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *resourceMonitorClient) ReadResource(ctx context.Context, in *ReadResourceRequest, opts ...grpc.CallOption) (*ReadResourceResponse, error) {
 	out := new(ReadResourceResponse)
 	err := grpc.Invoke(ctx, "/pulumirpc.ResourceMonitor/ReadResource", in, out, c.cc, opts...)
@@ -446,6 +496,7 @@ func (c *resourceMonitorClient) RegisterResourceOutputs(ctx context.Context, in
 
 type ResourceMonitorServer interface {
 	Invoke(context.Context, *InvokeRequest) (*InvokeResponse, error)
+	Call(context.Context, *InvokeRequest) (*InvokeResponse, error)
 	ReadResource(context.Context, *ReadResourceRequest) (*ReadResourceResponse, error)
 	RegisterResource(context.Context, *RegisterResourceRequest) (*RegisterResourceResponse, error)
 	RegisterResourceOutputs(context.Context, *RegisterResourceOutputsRequest) (*empty.Empty, error)
@@ -473,6 +524,24 @@ func _ResourceMonitor_Invoke_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ResourceMonitor_Call_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvokeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceMonitorServer).Call(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pulumirpc.ResourceMonitor/Call",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceMonitorServer).Call(ctx, req.(*InvokeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ResourceMonitor_ReadResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ReadResourceRequest)
 	if err := dec(in); err != nil {
@@ -535,6 +604,10 @@ var _ResourceMonitor_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Invoke",
 			Handler:    _ResourceMonitor_Invoke_Handler,
 		},
+		{
+			MethodName: "Call",
+			Handler:    _ResourceMonitor_Call_Handler,
+		},
 		{
 			MethodName: "ReadResource",
 			Handler:    _ResourceMonitor_ReadResource_Handler,
@@ -552,39 +625,46 @@ var _ResourceMonitor_serviceDesc = grpc.ServiceDesc{
 	Metadata: "resource.proto",
 }
 
-func init() { proto.RegisterFile("resource.proto", fileDescriptor_resource_5aa1dff965971124) }
-
-var fileDescriptor_resource_5aa1dff965971124 = []byte{
-	// 491 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x8c, 0x94, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xc7, 0x6b, 0xbb, 0x38, 0xe9, 0x50, 0x85, 0x6a, 0x41, 0xad, 0x31, 0xa8, 0x54, 0xe6, 0x02,
-	0x17, 0x47, 0x94, 0x03, 0x47, 0x4e, 0x1c, 0x38, 0x20, 0x84, 0x39, 0x83, 0xe4, 0xd8, 0x43, 0x64,
-	0x48, 0xbc, 0xcb, 0x7e, 0x54, 0xea, 0xd3, 0xf0, 0x66, 0x9c, 0x78, 0x0c, 0x0e, 0x78, 0x77, 0xbd,
-	0x26, 0xfe, 0x68, 0xd3, 0x53, 0xe6, 0x6b, 0x67, 0xe7, 0xff, 0xf3, 0x6c, 0x60, 0xc1, 0x51, 0x50,
-	0xc5, 0x0b, 0x4c, 0x19, 0xa7, 0x92, 0x92, 0x23, 0xa6, 0x36, 0x6a, 0x5b, 0x71, 0x56, 0xc4, 0x4f,
-	0xd6, 0x94, 0xae, 0x37, 0xb8, 0x34, 0x89, 0x95, 0xfa, 0xb6, 0xc4, 0x2d, 0x93, 0xd7, 0xb6, 0x2e,
-	0x7e, 0x3a, 0x4c, 0x0a, 0xc9, 0x55, 0x21, 0xdb, 0xec, 0xa2, 0xf9, 0xb9, 0xaa, 0x4a, 0xe4, 0xd6,
-	0x4f, 0x7e, 0x7b, 0xf0, 0x30, 0xc3, 0xbc, 0xcc, 0xda, 0xcb, 0x32, 0xfc, 0xa9, 0x50, 0x48, 0xb2,
-	0x00, 0xbf, 0x2a, 0x23, 0xef, 0xc2, 0x7b, 0x71, 0x94, 0x35, 0x16, 0x21, 0x70, 0x28, 0xaf, 0x19,
-	0x46, 0xbe, 0x89, 0x18, 0x5b, 0xc7, 0xea, 0x7c, 0x8b, 0x51, 0x60, 0x63, 0xda, 0x26, 0xa7, 0x10,
-	0xb2, 0x9c, 0x63, 0x2d, 0xa3, 0x43, 0x13, 0x6d, 0x3d, 0xf2, 0x06, 0xa0, 0xb9, 0x90, 0x21, 0x97,
-	0x15, 0x8a, 0xe8, 0x5e, 0x93, 0xbb, 0x7f, 0x79, 0x96, 0xda, 0x51, 0x53, 0x37, 0x6a, 0xfa, 0xd9,
-	0x8c, 0x9a, 0xed, 0x94, 0x92, 0x04, 0x8e, 0x4b, 0x64, 0x58, 0x97, 0x58, 0x17, 0xfa, 0x68, 0x78,
-	0x11, 0x34, 0x6d, 0x7b, 0x31, 0x12, 0xc3, 0xdc, 0xc9, 0x8a, 0x66, 0xe6, 0xda, 0xce, 0x4f, 0x72,
-	0x78, 0xd4, 0xd7, 0x27, 0x18, 0xad, 0x05, 0x92, 0x13, 0x08, 0x14, 0xaf, 0x5b, 0x85, 0xda, 0x1c,
-	0x8c, 0xe8, 0xdf, 0x79, 0xc4, 0xe4, 0xaf, 0x07, 0x67, 0x19, 0xae, 0x2b, 0x21, 0x91, 0x0f, 0x39,
-	0x3a, 0x6e, 0xde, 0x04, 0x37, 0x7f, 0x92, 0x5b, 0xd0, 0xe3, 0xd6, 0xc4, 0x0b, 0x25, 0x24, 0xdd,
-	0x1a, 0x9e, 0xf3, 0xac, 0xf5, 0xc8, 0x12, 0x42, 0xba, 0xfa, 0x8e, 0x85, 0xdc, 0xc7, 0xb2, 0x2d,
-	0x23, 0x11, 0xcc, 0x74, 0x4a, 0x9f, 0x08, 0x4d, 0x27, 0xe7, 0x8e, 0x08, 0xcf, 0xf6, 0x10, 0x9e,
-	0x0f, 0x08, 0xff, 0xf2, 0x20, 0x1a, 0xcb, 0xbf, 0x11, 0xb3, 0xdd, 0x2c, 0xbf, 0xdb, 0xac, 0xff,
-	0x4a, 0x82, 0xbb, 0x29, 0x69, 0x90, 0x08, 0x99, 0xaf, 0x36, 0xe8, 0x90, 0x58, 0x4f, 0x2b, 0xb4,
-	0x96, 0xde, 0x2f, 0x2d, 0xc1, 0xb9, 0x09, 0xc2, 0xf9, 0x70, 0xc0, 0x8f, 0x4a, 0x32, 0x25, 0x85,
-	0xfb, 0x4c, 0xe3, 0x31, 0x5f, 0xc1, 0x8c, 0xda, 0x9a, 0x7d, 0xab, 0xe0, 0xea, 0x2e, 0xff, 0xf8,
-	0xf0, 0xc0, 0xf5, 0xff, 0x40, 0xeb, 0x4a, 0x52, 0x4e, 0xde, 0x42, 0xf8, 0xbe, 0xbe, 0xa2, 0x3f,
-	0x9a, 0xf1, 0xd2, 0xee, 0x01, 0xa7, 0x36, 0xd4, 0x5e, 0x1e, 0x3f, 0x9e, 0xc8, 0x58, 0x7c, 0xc9,
-	0x01, 0xf9, 0x04, 0xc7, 0xbb, 0xfb, 0x4b, 0xce, 0x77, 0x8a, 0x27, 0x1e, 0x6e, 0xfc, 0xec, 0xc6,
-	0x7c, 0xd7, 0xf2, 0x0b, 0x9c, 0x0c, 0x71, 0x90, 0xa4, 0x77, 0x6c, 0x72, 0x97, 0xe3, 0xe7, 0xb7,
-	0xd6, 0x74, 0xed, 0xbf, 0x8e, 0x5f, 0x43, 0x4b, 0x9b, 0xbc, 0xbc, 0xa5, 0x43, 0xff, 0x8b, 0xc4,
-	0xa7, 0x23, 0xdc, 0xef, 0xf4, 0x9f, 0x5c, 0x72, 0xb0, 0x0a, 0x4d, 0xe4, 0xf5, 0xbf, 0x00, 0x00,
-	0x00, 0xff, 0xff, 0xa3, 0x97, 0x3d, 0x93, 0x21, 0x05, 0x00, 0x00,
+func init() { proto.RegisterFile("resource.proto", fileDescriptor_resource_dc027263233b0be2) }
+
+var fileDescriptor_resource_dc027263233b0be2 = []byte{
+	// 594 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0xc1, 0x6e, 0xd4, 0x30,
+	0x10, 0x6d, 0x92, 0x92, 0xed, 0x4e, 0xcb, 0x52, 0xb9, 0x68, 0xd7, 0x84, 0xaa, 0x54, 0x41, 0x42,
+	0x85, 0x43, 0x0a, 0xe5, 0x00, 0x17, 0x84, 0x44, 0xc5, 0x81, 0x03, 0x2a, 0xa4, 0x67, 0x90, 0xb2,
+	0xc9, 0x74, 0x09, 0x64, 0x6d, 0x63, 0x3b, 0x95, 0xfa, 0x35, 0x1c, 0xf8, 0x2b, 0x0e, 0x7c, 0x0b,
+	0x8a, 0x9d, 0x6c, 0x9b, 0x6c, 0xb6, 0xad, 0xb8, 0x79, 0xde, 0xbc, 0x19, 0xcf, 0xbc, 0xf1, 0x24,
+	0x30, 0x92, 0xa8, 0x78, 0x29, 0x53, 0x8c, 0x84, 0xe4, 0x9a, 0x93, 0xa1, 0x28, 0x8b, 0x72, 0x9e,
+	0x4b, 0x91, 0x06, 0x0f, 0x67, 0x9c, 0xcf, 0x0a, 0x3c, 0x34, 0x8e, 0x69, 0x79, 0x76, 0x88, 0x73,
+	0xa1, 0x2f, 0x2c, 0x2f, 0xd8, 0xed, 0x3a, 0x95, 0x96, 0x65, 0xaa, 0x6b, 0xef, 0x48, 0x48, 0x7e,
+	0x9e, 0x67, 0x28, 0xad, 0x1d, 0xfe, 0x75, 0x60, 0x27, 0xc6, 0x24, 0x8b, 0xeb, 0xcb, 0x62, 0xfc,
+	0x59, 0xa2, 0xd2, 0x64, 0x04, 0x6e, 0x9e, 0x51, 0x67, 0xdf, 0x39, 0x18, 0xc6, 0x6e, 0x9e, 0x11,
+	0x02, 0xeb, 0xfa, 0x42, 0x20, 0x75, 0x0d, 0x62, 0xce, 0x15, 0xc6, 0x92, 0x39, 0x52, 0xcf, 0x62,
+	0xd5, 0x99, 0x8c, 0xc1, 0x17, 0x89, 0x44, 0xa6, 0xe9, 0xba, 0x41, 0x6b, 0x8b, 0xbc, 0x02, 0x10,
+	0x92, 0x0b, 0x94, 0x3a, 0x47, 0x45, 0xef, 0xec, 0x3b, 0x07, 0x9b, 0x47, 0x93, 0xc8, 0x96, 0x1a,
+	0x35, 0xa5, 0x46, 0xa7, 0xa6, 0xd4, 0xf8, 0x0a, 0x95, 0x84, 0xb0, 0x95, 0xa1, 0x40, 0x96, 0x21,
+	0x4b, 0xab, 0x50, 0x7f, 0xdf, 0x3b, 0x18, 0xc6, 0x2d, 0x8c, 0x04, 0xb0, 0xd1, 0xb4, 0x45, 0x07,
+	0xe6, 0xda, 0x85, 0x1d, 0x26, 0x70, 0xbf, 0xdd, 0x9f, 0x12, 0x9c, 0x29, 0x24, 0xdb, 0xe0, 0x95,
+	0x92, 0xd5, 0x1d, 0x56, 0xc7, 0x4e, 0x89, 0xee, 0xad, 0x4b, 0x0c, 0xff, 0x78, 0x30, 0x89, 0x71,
+	0x96, 0x2b, 0x8d, 0xb2, 0xab, 0x63, 0xa3, 0x9b, 0xd3, 0xa3, 0x9b, 0xdb, 0xab, 0x9b, 0xd7, 0xd2,
+	0x6d, 0x0c, 0x7e, 0x5a, 0x2a, 0xcd, 0xe7, 0x46, 0xcf, 0x8d, 0xb8, 0xb6, 0xc8, 0x21, 0xf8, 0x7c,
+	0xfa, 0x1d, 0x53, 0x7d, 0x93, 0x96, 0x35, 0x8d, 0x50, 0x18, 0x54, 0xae, 0x2a, 0xc2, 0x37, 0x99,
+	0x1a, 0x73, 0x49, 0xe1, 0xc1, 0x0d, 0x0a, 0x6f, 0xb4, 0x15, 0x26, 0x4f, 0x60, 0x64, 0x7b, 0xfe,
+	0xc4, 0x55, 0xae, 0x73, 0xce, 0xe8, 0xd0, 0x30, 0x3a, 0x28, 0xd9, 0x85, 0xa1, 0xcd, 0xa9, 0x4e,
+	0x18, 0x05, 0x73, 0xc9, 0x25, 0x40, 0x5e, 0xc3, 0x24, 0xc9, 0x32, 0xc3, 0x4c, 0x8a, 0x53, 0x4c,
+	0x25, 0xea, 0x93, 0x52, 0x8b, 0x52, 0x2b, 0xba, 0x69, 0xb8, 0xab, 0xdc, 0xe4, 0x19, 0x6c, 0x4b,
+	0x14, 0x45, 0x92, 0xe2, 0x09, 0x3b, 0xfe, 0x96, 0xb0, 0x19, 0x2a, 0xba, 0x65, 0x42, 0x96, 0x70,
+	0xf2, 0x1c, 0x76, 0x32, 0x2c, 0x50, 0xe3, 0x3b, 0x3c, 0xe3, 0x12, 0x63, 0xeb, 0xa7, 0x77, 0x8d,
+	0x22, 0x7d, 0xae, 0xf0, 0x97, 0x03, 0x74, 0x79, 0xb8, 0x2b, 0x1f, 0x91, 0xdd, 0x1b, 0x77, 0xb1,
+	0x37, 0x97, 0x73, 0xf2, 0x6e, 0x37, 0xa7, 0x31, 0xf8, 0x4a, 0x27, 0xd3, 0x02, 0x9b, 0x81, 0x5b,
+	0xab, 0x9a, 0x9f, 0x3d, 0x55, 0xdb, 0x53, 0x35, 0xd7, 0x98, 0x21, 0xc2, 0x5e, 0xb7, 0xc0, 0x5a,
+	0x9a, 0xe6, 0x11, 0x2e, 0x97, 0xf9, 0x02, 0x06, 0xbc, 0x56, 0xf7, 0x86, 0x87, 0xde, 0xf0, 0x8e,
+	0x7e, 0x7b, 0x70, 0xaf, 0xc9, 0xff, 0x91, 0xb3, 0x5c, 0x73, 0x49, 0xde, 0x82, 0xff, 0x81, 0x9d,
+	0xf3, 0x1f, 0x48, 0x68, 0xb4, 0xf8, 0x3c, 0x45, 0x16, 0xaa, 0x2f, 0x0f, 0x1e, 0xf4, 0x78, 0xac,
+	0x7c, 0xe1, 0x1a, 0x79, 0x03, 0xeb, 0xc7, 0x49, 0x51, 0xfc, 0x6f, 0xf8, 0x67, 0xd8, 0xba, 0xba,
+	0xdc, 0x64, 0xef, 0x0a, 0xb9, 0xe7, 0xab, 0x16, 0x3c, 0x5a, 0xe9, 0x5f, 0xa4, 0xfc, 0x02, 0xdb,
+	0x5d, 0x35, 0x49, 0xd8, 0x0a, 0xeb, 0x5d, 0xf4, 0xe0, 0xf1, 0xb5, 0x9c, 0x45, 0xfa, 0xaf, 0x30,
+	0x59, 0x31, 0x2c, 0xf2, 0xf4, 0x9a, 0x0c, 0xed, 0x81, 0x06, 0xe3, 0xa5, 0x69, 0xbd, 0xaf, 0xfe,
+	0x00, 0xe1, 0xda, 0xd4, 0x37, 0xc8, 0xcb, 0x7f, 0x03, 0x00, 0xac, 0xee, 0x24, 0x24, 0x3e, 0x06,
+	0x00, 0x00,
 }
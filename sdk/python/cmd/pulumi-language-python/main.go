@@ -31,6 +31,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"syscall"
 
@@ -40,6 +41,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/rpcutil"
 	"github.com/pulumi/pulumi/pkg/version"
+	"github.com/pulumi/pulumi/pkg/workspace"
 	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
 	"google.golang.org/grpc"
 )
@@ -50,6 +52,9 @@ const (
 
 	// The runtime expects the config object to be saved to this environment variable.
 	pulumiConfigVar = "PULUMI_CONFIG"
+
+	// The name of the directory, within a project's cache directory, that holds the project's virtualenv.
+	virtualenvDir = "venv"
 )
 
 // Launches the language host RPC endpoint, which in turn fires up an RPC server implementing the
@@ -152,7 +157,7 @@ func (host *pythonLanguageHost) Run(ctx context.Context, req *pulumirpc.RunReque
 		return nil, err
 	}
 
-	if logging.V(5) {
+	if logging.V(5).Enabled() {
 		commandStr := strings.Join(args, " ")
 		logging.V(5).Infoln("Language host launching process: ", host.exec, commandStr)
 	}
@@ -160,6 +165,9 @@ func (host *pythonLanguageHost) Run(ctx context.Context, req *pulumirpc.RunReque
 	// Now simply spawn a process to execute the requested program, wiring up stdout/stderr directly.
 	var errResult string
 	pythonCmd := os.Getenv("PULUMI_PYTHON_CMD")
+	if pythonCmd == "" {
+		pythonCmd = projectVirtualenvPython()
+	}
 	if pythonCmd == "" {
 		pythonCmd = "python"
 	}
@@ -191,6 +199,25 @@ func (host *pythonLanguageHost) Run(ctx context.Context, req *pulumirpc.RunReque
 	return &pulumirpc.RunResponse{Error: errResult}, nil
 }
 
+// projectVirtualenvPython returns the interpreter of the virtualenv in the project's cache directory, if there is one.
+// Keeping the virtualenv there, rather than in a shared location, means that each project of a monorepo gets its own
+// set of packages.
+func projectVirtualenvPython() string {
+	cacheDir := os.Getenv(workspace.ProjectCacheDirEnvVar)
+	if cacheDir == "" {
+		return ""
+	}
+
+	bin := filepath.Join(cacheDir, virtualenvDir, "bin", "python")
+	if runtime.GOOS == "windows" {
+		bin = filepath.Join(cacheDir, virtualenvDir, "Scripts", "python.exe")
+	}
+	if _, err := os.Stat(bin); err != nil {
+		return ""
+	}
+	return bin
+}
+
 // constructArguments constructs a command-line for `pulumi-language-python`
 // by enumerating all of the optional and non-optional arguments present
 // in a RunRequest.
@@ -0,0 +1 @@
+@python -u -m pulumi.dynamic %*
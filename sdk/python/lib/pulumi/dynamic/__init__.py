@@ -0,0 +1,168 @@
+# Copyright 2016-2018, Pulumi Corporation.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""
+Dynamic resources, whose CRUD operations are implemented inline by the Pulumi program itself.
+
+The implementation of a dynamic resource is a ResourceProvider object.  It is serialized, along with everything it
+references, and stored in the resource's `__provider` property.  The `pulumi-python` resource provider plugin
+deserializes it again to perform each operation, so a resource can always be deleted using the implementation with
+which it was created, even after the program's code has changed.
+"""
+from __future__ import absolute_import
+
+import base64
+
+import dill
+
+from ..resource import CustomResource
+
+PROVIDER_KEY = "__provider"
+"""The name of the property in which a dynamic resource's serialized provider is stored."""
+
+class CheckFailure(object):
+    """
+    CheckFailure represents a single failure in the result of a call to ResourceProvider.check.
+    """
+    def __init__(self, prop, reason):
+        self.property = prop
+        """The property that failed validation."""
+        self.reason = reason
+        """The reason that the property failed validation."""
+
+class CheckResult(object):
+    """
+    CheckResult represents the results of a call to ResourceProvider.check.
+    """
+    def __init__(self, inputs=None, failures=None):
+        self.inputs = inputs
+        """The inputs to use, if any."""
+        self.failures = failures
+        """Any validation failures that occurred, as a list of CheckFailures."""
+
+class DiffResult(object):
+    """
+    DiffResult represents the results of a call to ResourceProvider.diff.
+    """
+    def __init__(self, changes=None, replaces=None, delete_before_replace=None):
+        self.changes = changes
+        """True if this diff detected changes, False if it didn't, and None if it is unknown."""
+        self.replaces = replaces
+        """The names of any properties whose changes require the resource to be replaced."""
+        self.delete_before_replace = delete_before_replace
+        """True if the old resource must be deleted before its replacement is created."""
+
+class CreateResult(object):
+    """
+    CreateResult represents the results of a call to ResourceProvider.create.
+    """
+    def __init__(self, id_, outs=None):
+        self.id = id_
+        """The ID of the created resource."""
+        self.outs = outs
+        """Any properties that were computed during creation."""
+
+class ReadResult(object):
+    """
+    ReadResult represents the results of a call to ResourceProvider.read.
+    """
+    def __init__(self, id_=None, outs=None):
+        self.id = id_
+        """The ID of the resource that was read."""
+        self.outs = outs
+        """The current properties of the resource."""
+
+class UpdateResult(object):
+    """
+    UpdateResult represents the results of a call to ResourceProvider.update.
+    """
+    def __init__(self, outs=None):
+        self.outs = outs
+        """Any properties that were computed during updating."""
+
+class ResourceProvider(object):
+    """
+    ResourceProvider is the base class for the implementation of a dynamic resource's CRUD operations.  Only create
+    is required; subclasses override whichever of the other operations they support.
+    """
+    def check(self, _olds, news):
+        """
+        Check validates that the given property bag is valid for a resource of the given type.  It returns a
+        CheckResult.  The default implementation accepts the new inputs as-is.
+        """
+        return CheckResult(news, [])
+
+    def diff(self, _id, _olds, _news):
+        """
+        Diff checks what impacts a hypothetical update will have on the resource's properties.  It returns a
+        DiffResult.  The default implementation reports that changes are unknown, leaving the decision to the engine.
+        """
+        return DiffResult()
+
+    def create(self, inputs):
+        """
+        Create allocates a new instance of the provided resource and returns its unique ID afterwards.  It returns a
+        CreateResult.
+        """
+        raise NotImplementedError("dynamic providers must implement create")
+
+    def read(self, id_, props):
+        """
+        Read reads the current live state associated with a resource.  It returns a ReadResult.  The default
+        implementation returns the resource's existing state.
+        """
+        return ReadResult(id_, props)
+
+    def update(self, _id, _olds, news):
+        """
+        Update updates an existing resource with new values.  It returns an UpdateResult.  The default implementation
+        simply adopts the new inputs.
+        """
+        return UpdateResult(news)
+
+    def delete(self, _id, _props):
+        """
+        Delete tears down an existing resource with the given ID.  If it fails, the resource is assumed to still exist.
+        """
+        pass
+
+def serialize_provider(provider):
+    """
+    Serializes a ResourceProvider, including any code and values it captures, into a string.
+    """
+    return base64.b64encode(dill.dumps(provider, recurse=True)).decode("utf-8")
+
+def deserialize_provider(serialized):
+    """
+    Reconstitutes a ResourceProvider previously serialized by serialize_provider.
+    """
+    return dill.loads(base64.b64decode(serialized.encode("utf-8")))
+
+class Resource(CustomResource):
+    """
+    Resource represents a Pulumi resource that incorporates an inline implementation of its CRUD operations.
+    """
+    def __init__(self, provider, name, props=None, opts=None):
+        """
+        Creates a new dynamic resource.  provider is the ResourceProvider that implements the resource's CRUD
+        operations; props must not define the reserved property `__provider`.
+        """
+        if not isinstance(provider, ResourceProvider):
+            raise TypeError('Expected provider to be a ResourceProvider instance')
+        props = dict(props or {})
+        if PROVIDER_KEY in props:
+            raise TypeError('A dynamic resource must not define the %s key' % PROVIDER_KEY)
+        props[PROVIDER_KEY] = serialize_provider(provider)
+
+        CustomResource.__init__(self, "pulumi-python:dynamic:Resource", name, props, opts)
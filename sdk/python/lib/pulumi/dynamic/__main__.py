@@ -0,0 +1,139 @@
+# Copyright 2016-2018, Pulumi Corporation.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""
+The `pulumi-python` resource provider, which performs the CRUD operations of dynamic resources by deserializing and
+calling the ResourceProvider stored in each resource's state.
+
+Each of the RPC methods below operates in the same fashion: deserialize the dynamic provider for the resource, call
+its corresponding method, and convert the results.  If the provider itself has changed, Diff reports that the resource
+requires replacement without consulting it.  This allows the replacement to be created using the new provider while
+the old resource is deleted using the provider with which it was created.
+"""
+from __future__ import absolute_import
+
+import sys
+import time
+
+from concurrent import futures
+
+import grpc
+from google.protobuf import empty_pb2
+
+from . import PROVIDER_KEY, deserialize_provider
+from ..runtime.proto import plugin_pb2, provider_pb2, provider_pb2_grpc
+from ..runtime.rpc import deserialize_resource_props, serialize_resource_props
+
+def get_provider(props):
+    return deserialize_provider(props[PROVIDER_KEY])
+
+def including_provider(outs, props):
+    result = dict(outs or {})
+    result[PROVIDER_KEY] = props[PROVIDER_KEY]
+    return result
+
+class DynamicResourceProviderServicer(provider_pb2_grpc.ResourceProviderServicer):
+    """
+    DynamicResourceProviderServicer implements the resource provider gRPC interface for dynamic resources.
+    """
+    def Configure(self, request, context):
+        return empty_pb2.Empty()
+
+    def CheckAuth(self, request, context):
+        # Dynamic providers have no credentials of their own to verify.
+        return empty_pb2.Empty()
+
+    def Invoke(self, request, context):
+        raise Exception("unknown function %s" % request.tok)
+
+    def Check(self, request, context):
+        olds = deserialize_resource_props(request.olds)
+        news = deserialize_resource_props(request.news)
+        result = get_provider(news).check(olds, news)
+
+        inputs = including_provider(result.inputs, news)
+        failures = [provider_pb2.CheckFailure(property=f.property, reason=f.reason) for f in result.failures or []]
+        return provider_pb2.CheckResponse(inputs=serialize_resource_props(inputs), failures=failures)
+
+    def Diff(self, request, context):
+        olds = deserialize_resource_props(request.olds)
+        news = deserialize_resource_props(request.news)
+        if olds[PROVIDER_KEY] != news[PROVIDER_KEY]:
+            return provider_pb2.DiffResponse(replaces=[PROVIDER_KEY])
+
+        result = get_provider(olds).diff(request.id, olds, news)
+        if result.changes is True:
+            changes = provider_pb2.DiffResponse.DIFF_SOME
+        elif result.changes is False:
+            changes = provider_pb2.DiffResponse.DIFF_NONE
+        else:
+            changes = provider_pb2.DiffResponse.DIFF_UNKNOWN
+        return provider_pb2.DiffResponse(
+            replaces=result.replaces or [],
+            deleteBeforeReplace=bool(result.delete_before_replace),
+            changes=changes)
+
+    def Create(self, request, context):
+        props = deserialize_resource_props(request.properties)
+        result = get_provider(props).create(props)
+        outs = including_provider(result.outs, props)
+        return provider_pb2.CreateResponse(id=result.id, properties=serialize_resource_props(outs))
+
+    def Read(self, request, context):
+        props = deserialize_resource_props(request.properties)
+        result = get_provider(props).read(request.id, props)
+        # Propagate the provider so that the resource's CRUD operations continue to function after a refresh.
+        outs = including_provider(result.outs, props)
+        return provider_pb2.ReadResponse(id=result.id or "", properties=serialize_resource_props(outs))
+
+    def Update(self, request, context):
+        olds = deserialize_resource_props(request.olds)
+        news = deserialize_resource_props(request.news)
+        if olds[PROVIDER_KEY] != news[PROVIDER_KEY]:
+            raise Exception("changes to provider should require replacement")
+
+        result = get_provider(olds).update(request.id, olds, news)
+        outs = including_provider(result.outs, news)
+        return provider_pb2.UpdateResponse(properties=serialize_resource_props(outs))
+
+    def Delete(self, request, context):
+        props = deserialize_resource_props(request.properties)
+        get_provider(props).delete(request.id, props)
+        return empty_pb2.Empty()
+
+    def Cancel(self, request, context):
+        return empty_pb2.Empty()
+
+    def GetPluginInfo(self, request, context):
+        return plugin_pb2.PluginInfo()
+
+def main(args):
+    # The program requires a single argument: the address of the RPC endpoint for the engine.
+    if not args:
+        sys.stderr.write("fatal: Missing <engine> address\n")
+        sys.exit(-1)
+
+    server = grpc.server(futures.ThreadPoolExecutor(max_workers=4))
+    provider_pb2_grpc.add_ResourceProviderServicer_to_server(DynamicResourceProviderServicer(), server)
+    port = server.add_insecure_port("0.0.0.0:0")
+    server.start()
+
+    # Emit the port so the engine can connect, then serve until we are killed.
+    sys.stdout.write("%d\n" % port)
+    sys.stdout.flush()
+    while True:
+        time.sleep(60 * 60 * 24)
+
+if __name__ == "__main__":
+    main(sys.argv[1:])
@@ -2,6 +2,7 @@
 import grpc
 
 from google.protobuf import empty_pb2 as google_dot_protobuf_dot_empty__pb2
+from google.protobuf import struct_pb2 as google_dot_protobuf_dot_struct__pb2
 from . import plugin_pb2 as plugin__pb2
 from . import provider_pb2 as provider__pb2
 
@@ -17,16 +18,31 @@ class ResourceProviderStub(object):
     Args:
       channel: A grpc.Channel.
     """
+    self.Parameterize = channel.unary_unary(
+        '/pulumirpc.ResourceProvider/Parameterize',
+        request_serializer=google_dot_protobuf_dot_struct__pb2.Struct.SerializeToString,
+        response_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
+        )
     self.Configure = channel.unary_unary(
         '/pulumirpc.ResourceProvider/Configure',
         request_serializer=provider__pb2.ConfigureRequest.SerializeToString,
         response_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
         )
+    self.CheckAuth = channel.unary_unary(
+        '/pulumirpc.ResourceProvider/CheckAuth',
+        request_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
+        response_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
+        )
     self.Invoke = channel.unary_unary(
         '/pulumirpc.ResourceProvider/Invoke',
         request_serializer=provider__pb2.InvokeRequest.SerializeToString,
         response_deserializer=provider__pb2.InvokeResponse.FromString,
         )
+    self.Call = channel.unary_unary(
+        '/pulumirpc.ResourceProvider/Call',
+        request_serializer=provider__pb2.InvokeRequest.SerializeToString,
+        response_deserializer=provider__pb2.InvokeResponse.FromString,
+        )
     self.Check = channel.unary_unary(
         '/pulumirpc.ResourceProvider/Check',
         request_serializer=provider__pb2.CheckRequest.SerializeToString,
@@ -67,6 +83,16 @@ class ResourceProviderStub(object):
         request_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
         response_deserializer=plugin__pb2.PluginInfo.FromString,
         )
+    self.GetSchema = channel.unary_unary(
+        '/pulumirpc.ResourceProvider/GetSchema',
+        request_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
+        response_deserializer=google_dot_protobuf_dot_struct__pb2.Struct.FromString,
+        )
+    self.Discover = channel.unary_unary(
+        '/pulumirpc.ResourceProvider/Discover',
+        request_serializer=google_dot_protobuf_dot_struct__pb2.Struct.SerializeToString,
+        response_deserializer=google_dot_protobuf_dot_struct__pb2.Struct.FromString,
+        )
 
 
 class ResourceProviderServicer(object):
@@ -74,6 +100,16 @@ class ResourceProviderServicer(object):
   within a single package.  It is driven by the overall planning engine in response to resource diffs.
   """
 
+  def Parameterize(self, request, context):
+    """Parameterize parameterizes the provider before it is configured, e.g. with the version of the API it should
+    target or with endpoint information it should use to discover that version.  The parameters are recorded in the
+    state of the provider resource so that later operations reproduce the same behavior.  Providers that do not
+    accept parameters should return UNIMPLEMENTED.
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
   def Configure(self, request, context):
     """Configure configures the resource provider with "globals" that control its behavior.
     """
@@ -81,6 +117,15 @@ class ResourceProviderServicer(object):
     context.set_details('Method not implemented!')
     raise NotImplementedError('Method not implemented!')
 
+  def CheckAuth(self, request, context):
+    """CheckAuth verifies that the provider's configured credentials are valid and grant the access it needs.  It is
+    called after Configure and before any resource operations, so that problems can be reported up front.  Providers
+    that cannot perform such a check should return UNIMPLEMENTED.
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
   def Invoke(self, request, context):
     """Invoke dynamically executes a built-in function in the provider.
     """
@@ -88,6 +133,14 @@ class ResourceProviderServicer(object):
     context.set_details('Method not implemented!')
     raise NotImplementedError('Method not implemented!')
 
+  def Call(self, request, context):
+    """Call dynamically executes a method of an existing resource in the provider.  The resource is passed in
+    the `__self__` argument; its result participates in dependency tracking like any other output.
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
   def Check(self, request, context):
     """Check validates that the given property bag is valid for a resource of the given type and returns the inputs
     that should be passed to successive calls to Diff, Create, or Update for this resource. As a rule, the provider
@@ -116,7 +169,9 @@ class ResourceProviderServicer(object):
 
   def Read(self, request, context):
     """Read the current live state associated with a resource.  Enough state must be include in the inputs to uniquely
-    identify the resource; this is typically just the resource ID, but may also include some properties.
+    identify the resource; this is typically just the resource ID, but may also include some properties.  If the
+    request has a modifiedSince time, and the provider can tell that the resource has not been modified since then,
+    it may report that the resource is unmodified instead of reading it.
     """
     context.set_code(grpc.StatusCode.UNIMPLEMENTED)
     context.set_details('Method not implemented!')
@@ -150,19 +205,53 @@ class ResourceProviderServicer(object):
     context.set_details('Method not implemented!')
     raise NotImplementedError('Method not implemented!')
 
+  def GetSchema(self, request, context):
+    """GetSchema returns a JSON description of the resource and function types this provider implements, along with
+    the configuration it accepts.  Providers that cannot describe themselves should return UNIMPLEMENTED.
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def Discover(self, request, context):
+    """Discover enumerates the existing resources that the configured provider can see, so that they may be imported.
+    The request may restrict them with `types`, a list of resource types; `tags`, a map of tags that resources must
+    carry; `resourceGroup`; and `vpc`.  The response's `resources` list holds an object for each resource with its
+    `type`, `id`, a suggested `name`, and its `properties`, as they would be written in a program.  Providers that
+    cannot enumerate resources should return UNIMPLEMENTED.
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
 
 def add_ResourceProviderServicer_to_server(servicer, server):
   rpc_method_handlers = {
+      'Parameterize': grpc.unary_unary_rpc_method_handler(
+          servicer.Parameterize,
+          request_deserializer=google_dot_protobuf_dot_struct__pb2.Struct.FromString,
+          response_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
+      ),
       'Configure': grpc.unary_unary_rpc_method_handler(
           servicer.Configure,
           request_deserializer=provider__pb2.ConfigureRequest.FromString,
           response_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
       ),
+      'CheckAuth': grpc.unary_unary_rpc_method_handler(
+          servicer.CheckAuth,
+          request_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
+          response_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
+      ),
       'Invoke': grpc.unary_unary_rpc_method_handler(
           servicer.Invoke,
           request_deserializer=provider__pb2.InvokeRequest.FromString,
           response_serializer=provider__pb2.InvokeResponse.SerializeToString,
       ),
+      'Call': grpc.unary_unary_rpc_method_handler(
+          servicer.Call,
+          request_deserializer=provider__pb2.InvokeRequest.FromString,
+          response_serializer=provider__pb2.InvokeResponse.SerializeToString,
+      ),
       'Check': grpc.unary_unary_rpc_method_handler(
           servicer.Check,
           request_deserializer=provider__pb2.CheckRequest.FromString,
@@ -203,6 +292,16 @@ def add_ResourceProviderServicer_to_server(servicer, server):
           request_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
           response_serializer=plugin__pb2.PluginInfo.SerializeToString,
       ),
+      'GetSchema': grpc.unary_unary_rpc_method_handler(
+          servicer.GetSchema,
+          request_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
+          response_serializer=google_dot_protobuf_dot_struct__pb2.Struct.SerializeToString,
+      ),
+      'Discover': grpc.unary_unary_rpc_method_handler(
+          servicer.Discover,
+          request_deserializer=google_dot_protobuf_dot_struct__pb2.Struct.FromString,
+          response_serializer=google_dot_protobuf_dot_struct__pb2.Struct.SerializeToString,
+      ),
   }
   generic_handler = grpc.method_handlers_generic_handler(
       'pulumirpc.ResourceProvider', rpc_method_handlers)
@@ -70,6 +70,49 @@ def invoke(tok, args):
 
     return {}
 
+def call(tok, args, res):
+    """
+    Dynamically calls the method identified by tok on the existing resource res, which is implemented by a provider
+    plugin.  The input args is a dictionary of arbitrary values, and the return value contains a similar dictionary
+    returned by the method.
+    """
+
+    # The resource itself is passed in the __self__ argument.
+    callargs = {'__self__': {'urn': res.urn, 'id': getattr(res, 'id', None)}}
+    callargs.update(args or {})
+
+    # Ensure we have flushed all stdout/stderr, in case the RPC fails.
+    sys.stdout.flush()
+    sys.stderr.flush()
+
+    # Now perform the call.  This is synchronous and will return only after the operation completes.
+    monitor = get_monitor()
+    try:
+        resp = monitor.Call(provider_pb2.InvokeRequest(
+            tok=tok,
+            args=rpc.serialize_resource_props(callargs)))
+    except grpc.RpcError as exn:
+        # See the above comment on invoke for the justification for disabling
+        # this warning
+        # pylint: disable=no-member
+        if exn.code() == grpc.StatusCode.UNAVAILABLE:
+            wait_for_death()
+
+        # If the RPC otherwise failed, re-throw an exception with the message details - the contents
+        # are suitable for user presentation.
+        raise Exception(exn.details())
+
+    # If the call failed, raise an error.
+    if resp.failures:
+        raise Exception('call of %s failed: %s (%s)' % (tok, resp.failures[0].reason, resp.failures[0].property))
+
+    # Otherwise, return the output properties.
+    retobj = getattr(resp, 'return')
+    if retobj:
+        return rpc.deserialize_resource_props(retobj)
+
+    return {}
+
 class RegisterResourceResult(object):
     """
     RegisterResourceResult contains the assigned URN, the ID -- if applicable -- and the resulting resource
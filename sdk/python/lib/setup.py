@@ -30,6 +30,7 @@ setup(name='pulumi',
       install_requires=[
           'protobuf>=3.6.0',
           'grpcio>=1.9.1',
-          'six>=1.11.0'
+          'six>=1.11.0',
+          'dill>=0.2.8'
       ],
       zip_safe=False)
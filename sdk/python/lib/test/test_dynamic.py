@@ -0,0 +1,46 @@
+# Copyright 2016-2018, Pulumi Corporation.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+import unittest
+import six
+from pulumi.dynamic import CreateResult, ResourceProvider, deserialize_provider, serialize_provider
+
+
+class CounterProvider(ResourceProvider):
+    def __init__(self, start):
+        self.start = start
+
+    def create(self, inputs):
+        return CreateResult(str(self.start), {"count": self.start + inputs["increment"]})
+
+
+class DynamicTests(unittest.TestCase):
+    """
+    Tests that dynamic providers survive the round trip through a resource's state.
+    """
+    def test_round_trip(self):
+        serialized = serialize_provider(CounterProvider(41))
+        self.assertIsInstance(serialized, six.string_types)
+
+        provider = deserialize_provider(serialized)
+        result = provider.create({"increment": 1})
+        self.assertEqual(result.id, "41")
+        self.assertEqual(result.outs, {"count": 42})
+
+    def test_defaults(self):
+        provider = deserialize_provider(serialize_provider(ResourceProvider()))
+        self.assertEqual(provider.check({}, {"a": 1}).inputs, {"a": 1})
+        self.assertIsNone(provider.diff("id", {}, {}).changes)
+        self.assertEqual(provider.update("id", {}, {"a": 2}).outs, {"a": 2})
+        with self.assertRaises(NotImplementedError):
+            provider.create({})